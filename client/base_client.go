@@ -63,6 +63,10 @@ type baseClient struct {
 
 	security SecurityOption
 
+	// discoveryCache is the last saved discovery cache.
+	discoveryCacheMu sync.Mutex
+	discoveryCache   *discoveryCache
+
 	// Client option.
 	option *option
 }
@@ -117,6 +121,7 @@ func (c *baseClient) initRetry(f func() error) error {
 		if err = f(); err == nil {
 			return nil
 		}
+		c.option.observeRetry(c.ctx, getMembersMethod, i+1, err)
 		select {
 		case <-c.ctx.Done():
 			return err
@@ -252,10 +257,29 @@ func (c *baseClient) gcAllocatorLeaderAddr(curAllocatorMap map[string]*pdpb.Memb
 }
 
 func (c *baseClient) initClusterID() error {
+	clusterID, err := c.getClusterID(c.GetURLs())
+	if err != nil {
+		return err
+	}
+	if clusterID == 0 {
+		// All the configured URLs are unreachable, try the last known ones.
+		clusterID = c.initClusterIDFromDiscoveryCache()
+	}
+	// Failed to init the cluster ID.
+	if clusterID == 0 {
+		return errors.WithStack(errFailInitClusterID)
+	}
+	c.clusterID = clusterID
+	return nil
+}
+
+// getClusterID returns the cluster ID of the URLs, or 0 if all of them are
+// unreachable.
+func (c *baseClient) getClusterID(urls []string) (uint64, error) {
 	ctx, cancel := context.WithCancel(c.ctx)
 	defer cancel()
 	var clusterID uint64
-	for _, u := range c.GetURLs() {
+	for _, u := range urls {
 		members, err := c.getMembers(ctx, u, c.option.timeout)
 		if err != nil || members.GetHeader() == nil {
 			log.Warn("[pd] failed to get cluster id", zap.String("url", u), errs.ZapError(err))
@@ -270,15 +294,10 @@ func (c *baseClient) initClusterID() error {
 		})
 		// All URLs passed in should have the same cluster ID.
 		if members.GetHeader().GetClusterId() != clusterID {
-			return errors.WithStack(errUnmatchedClusterID)
+			return 0, errors.WithStack(errUnmatchedClusterID)
 		}
 	}
-	// Failed to init the cluster ID.
-	if clusterID == 0 {
-		return errors.WithStack(errFailInitClusterID)
-	}
-	c.clusterID = clusterID
-	return nil
+	return clusterID, nil
 }
 
 func (c *baseClient) updateMember() error {
@@ -321,6 +340,7 @@ func (c *baseClient) updateMember() error {
 		if err := c.switchLeader(members.GetLeader().GetClientUrls()); err != nil {
 			return err
 		}
+		c.saveDiscoveryCache()
 		c.scheduleCheckTSODispatcher()
 
 		// If `switchLeader` succeeds but `switchTSOAllocatorLeader` has an error,
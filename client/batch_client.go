@@ -0,0 +1,122 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+)
+
+// storeServiceName is the name of the PD gRPC service getting the stores in
+// batches, whose messages are encoded in JSON.
+const storeServiceName = "pd.Store"
+
+// maxBatchGetSize is the max number of the IDs in a batch get request served
+// by PD. The larger batches are split.
+const maxBatchGetSize = 10240
+
+type batchGetRegionsByIDRequest struct {
+	Header      *pdpb.RequestHeader `json:"header"`
+	RegionIDs   []uint64            `json:"region_ids"`
+	NeedBuckets bool                `json:"need_buckets,omitempty"`
+}
+
+type batchGetRegionsByIDResponse struct {
+	Header  *pdpb.ResponseHeader      `json:"header"`
+	Regions []*pdpb.GetRegionResponse `json:"regions"`
+}
+
+type batchGetStoresRequest struct {
+	Header   *pdpb.RequestHeader `json:"header"`
+	StoreIDs []uint64            `json:"store_ids"`
+}
+
+type batchGetStoresResponse struct {
+	Header *pdpb.ResponseHeader `json:"header"`
+	Stores []*metapb.Store      `json:"stores"`
+}
+
+// BatchGetRegionsByID gets the regions of the IDs in batches. The regions are
+// in the order of the IDs, and the ones not found are nil.
+func (c *client) BatchGetRegionsByID(ctx context.Context, regionIDs []uint64, opts ...GetRegionOption) ([]*Region, error) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("pdclient.BatchGetRegionsByID", opentracing.ChildOf(span.Context()))
+		defer span.Finish()
+	}
+	options := &GetRegionOp{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	regions := make([]*Region, 0, len(regionIDs))
+	for start := 0; start < len(regionIDs); start += maxBatchGetSize {
+		end := start + maxBatchGetSize
+		if end > len(regionIDs) {
+			end = len(regionIDs)
+		}
+		resp := &batchGetRegionsByIDResponse{}
+		req := &batchGetRegionsByIDRequest{Header: c.requestHeader(), RegionIDs: regionIDs[start:end], NeedBuckets: options.needBuckets}
+		if err := c.invokeJSON(ctx, regionServiceName, "BatchGetRegionsByID", req, func() *pdpb.ResponseHeader { return resp.Header }, resp,
+			cmdDurationBatchGetRegionsByID, cmdFailedDurationBatchGetRegionsByID); err != nil {
+			return nil, err
+		}
+		if len(resp.Regions) != end-start {
+			return nil, errors.Errorf("[pd] got %d regions of %d IDs", len(resp.Regions), end-start)
+		}
+		for _, r := range resp.Regions {
+			if r == nil {
+				regions = append(regions, nil)
+				continue
+			}
+			regions = append(regions, handleRegionResponse(r))
+		}
+	}
+	return regions, nil
+}
+
+// BatchGetStores gets the stores of the IDs in batches. The stores are in the
+// order of the IDs, and the ones not found or tombstone are nil.
+func (c *client) BatchGetStores(ctx context.Context, storeIDs []uint64) ([]*metapb.Store, error) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("pdclient.BatchGetStores", opentracing.ChildOf(span.Context()))
+		defer span.Finish()
+	}
+	stores := make([]*metapb.Store, 0, len(storeIDs))
+	for start := 0; start < len(storeIDs); start += maxBatchGetSize {
+		end := start + maxBatchGetSize
+		if end > len(storeIDs) {
+			end = len(storeIDs)
+		}
+		resp := &batchGetStoresResponse{}
+		req := &batchGetStoresRequest{Header: c.requestHeader(), StoreIDs: storeIDs[start:end]}
+		if err := c.invokeJSON(ctx, storeServiceName, "BatchGetStores", req, func() *pdpb.ResponseHeader { return resp.Header }, resp,
+			cmdDurationBatchGetStores, cmdFailedDurationBatchGetStores); err != nil {
+			return nil, err
+		}
+		if len(resp.Stores) != end-start {
+			return nil, errors.Errorf("[pd] got %d stores of %d IDs", len(resp.Stores), end-start)
+		}
+		for _, s := range resp.Stores {
+			if s.GetNodeState() == metapb.NodeState_Removed {
+				s = nil
+			}
+			stores = append(stores, s)
+		}
+	}
+	return stores, nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"context"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/client/errs"
+	"github.com/tikv/pd/client/grpcutil"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// bucketServiceName is the name of the PD gRPC service watching the buckets of
+// the regions, whose messages are encoded in JSON.
+const bucketServiceName = "pd.Bucket"
+
+// BucketChanges is a message of the bucket watch.
+type BucketChanges struct {
+	// Snapshot means the message has the buckets of all the regions in the
+	// range, which replace the ones the watcher knows. Otherwise, the message
+	// has the buckets of the regions whose bucket keys are changed.
+	Snapshot bool              `json:"snapshot,omitempty"`
+	Buckets  []*metapb.Buckets `json:"buckets,omitempty"`
+}
+
+// BucketClient watches the buckets of the regions, which is used to route the
+// requests by the buckets without getting the regions again.
+type BucketClient interface {
+	// WatchBuckets watches the buckets of the regions overlapping with
+	// [startKey, endKey), an empty endKey means no limit. The first message in
+	// the channel contains the buckets of all the regions in the range, and
+	// the later ones contain the changed buckets, whose versions are greater
+	// than the known ones. Nothing is sent if the region bucket is disabled.
+	WatchBuckets(ctx context.Context, startKey, endKey []byte) (chan *BucketChanges, error)
+}
+
+type watchBucketsRequest struct {
+	Header   *pdpb.RequestHeader `json:"header"`
+	StartKey []byte              `json:"start_key,omitempty"`
+	EndKey   []byte              `json:"end_key,omitempty"`
+}
+
+type watchBucketsResponse struct {
+	Header *pdpb.ResponseHeader `json:"header"`
+	BucketChanges
+}
+
+func bucketMethod(name string) string {
+	return "/" + bucketServiceName + "/" + name
+}
+
+// WatchBuckets watches the buckets of the regions in the range. The watch is
+// restarted from a new snapshot when the PD leader changes, and the channel is
+// closed when the context is done.
+func (c *client) WatchBuckets(ctx context.Context, startKey, endKey []byte) (chan *BucketChanges, error) {
+	req := &watchBucketsRequest{StartKey: startKey, EndKey: endKey}
+	stream, err := c.watchBuckets(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	bucketWatcherChan := make(chan *BucketChanges)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error("[pd] panic in bucket client `WatchBuckets`", zap.Any("error", r))
+			}
+		}()
+		defer close(bucketWatcherChan)
+		for {
+			resp := &watchBucketsResponse{}
+			err := stream.RecvMsg(resp)
+			if err == nil && resp.Header.GetError() != nil {
+				err = errors.Errorf("WatchBuckets failed: %s", resp.Header.GetError().String())
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				log.Warn("[pd] bucket watch is broken, watch again", errs.ZapError(err))
+				c.ScheduleCheckLeader()
+				if stream = c.rewatchBuckets(ctx, req); stream == nil {
+					return
+				}
+				continue
+			}
+			select {
+			case bucketWatcherChan <- &resp.BucketChanges:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return bucketWatcherChan, nil
+}
+
+func (c *client) watchBuckets(ctx context.Context, req *watchBucketsRequest) (grpc.ClientStream, error) {
+	cc, err := c.getOrCreateGRPCConn(c.GetLeaderAddr())
+	if err != nil {
+		return nil, err
+	}
+	desc := &grpc.StreamDesc{StreamName: "WatchBuckets", ServerStreams: true}
+	stream, err := cc.NewStream(ctx, desc, bucketMethod("WatchBuckets"), grpc.CallContentSubtype(grpcutil.JSONCodecName))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = c.requestHeader()
+	if err := stream.SendMsg(req); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// rewatchBuckets keeps watching the buckets until it succeeds, or returns nil
+// when the context is done.
+func (c *client) rewatchBuckets(ctx context.Context, req *watchBucketsRequest) grpc.ClientStream {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(retryInterval):
+		}
+		stream, err := c.watchBuckets(ctx, req)
+		if err == nil {
+			return stream
+		}
+		log.Warn("[pd] failed to watch the buckets", errs.ZapError(err))
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"sync"
+	"time"
+)
+
+type circuitState int
+
+const (
+	// circuitClosed means the requests are sent to the endpoint.
+	circuitClosed circuitState = iota
+	// circuitOpen means the endpoint fails too many times in a row, and the
+	// requests are not sent to it until the cooldown passes.
+	circuitOpen
+	// circuitHalfOpen means a probe request is sent to the endpoint, whose
+	// result decides whether to close or open the circuit again.
+	circuitHalfOpen
+)
+
+// circuitBreaker stops sending the requests to an endpoint which fails too
+// many times in a row. A nil circuitBreaker always allows the requests.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     circuitState
+	failures  int
+	// since is when the circuit is opened or the probe is sent.
+	since time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// allow returns whether a request can be sent to the endpoint. Once the cooldown
+// passes, only one probe request is allowed until its result is reported, or
+// the cooldown passes again in case the probe is never sent.
+func (cb *circuitBreaker) allow(now time.Time) bool {
+	if cb == nil {
+		return true
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.state == circuitClosed {
+		return true
+	}
+	if now.Sub(cb.since) < cb.cooldown {
+		return false
+	}
+	cb.state, cb.since = circuitHalfOpen, now
+	return true
+}
+
+// report records the result of a request sent to the endpoint. It returns true
+// if the circuit is opened by the failure.
+func (cb *circuitBreaker) report(now time.Time, success bool) bool {
+	if cb == nil {
+		return false
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if success {
+		cb.state, cb.failures = circuitClosed, 0
+		return false
+	}
+	cb.failures++
+	if cb.state == circuitHalfOpen || (cb.state == circuitClosed && cb.failures >= cb.threshold) {
+		cb.state, cb.since, cb.failures = circuitOpen, now, 0
+		return true
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	re := require.New(t)
+	var nilBreaker *circuitBreaker
+	re.True(nilBreaker.allow(time.Now()))
+	re.False(nilBreaker.report(time.Now(), false))
+
+	cb := newCircuitBreaker(3, time.Second)
+	now := time.Now()
+	re.True(cb.allow(now))
+	re.False(cb.report(now, false))
+	re.False(cb.report(now, false))
+	// A success resets the failures.
+	re.False(cb.report(now, true))
+	re.False(cb.report(now, false))
+	re.False(cb.report(now, false))
+	re.True(cb.allow(now))
+	re.True(cb.report(now, false))
+	re.False(cb.allow(now))
+	re.False(cb.allow(now.Add(time.Second / 2)))
+
+	// Only one probe is allowed after the cooldown.
+	now = now.Add(time.Second)
+	re.True(cb.allow(now))
+	re.False(cb.allow(now))
+	// The failed probe opens the circuit again.
+	re.True(cb.report(now, false))
+	re.False(cb.allow(now))
+	now = now.Add(time.Second)
+	re.True(cb.allow(now))
+	// The probe is lost, so another one is allowed after the cooldown.
+	re.False(cb.allow(now.Add(time.Second / 2)))
+	now = now.Add(time.Second)
+	re.True(cb.allow(now))
+	// The succeeded probe closes the circuit.
+	re.False(cb.report(now, true))
+	re.True(cb.allow(now))
+	re.True(cb.allow(now))
+}
@@ -86,15 +86,25 @@ type Client interface {
 	GetPrevRegion(ctx context.Context, key []byte, opts ...GetRegionOption) (*Region, error)
 	// GetRegionByID gets a region and its leader Peer from PD by id.
 	GetRegionByID(ctx context.Context, regionID uint64, opts ...GetRegionOption) (*Region, error)
+	// BatchGetRegionsByID gets the regions of the IDs in batches. The regions
+	// are in the order of the IDs, and the ones not found are nil.
+	BatchGetRegionsByID(ctx context.Context, regionIDs []uint64, opts ...GetRegionOption) ([]*Region, error)
 	// ScanRegion gets a list of regions, starts from the region that contains key.
 	// Limit limits the maximum number of regions returned.
 	// If a region has no leader, corresponding leader will be placed by a peer
 	// with empty value (PeerID is 0).
 	ScanRegions(ctx context.Context, key, endKey []byte, limit int) ([]*Region, error)
+	// ScanRegionsStream is like ScanRegions, but receives the regions in
+	// batches of at most batchSize regions, so scanning a large number of
+	// regions doesn't hold them all in memory.
+	ScanRegionsStream(ctx context.Context, key, endKey []byte, limit, batchSize int) (RegionStream, error)
 	// GetStore gets a store from PD by store id.
 	// The store may expire later. Caller is responsible for caching and taking care
 	// of store change.
 	GetStore(ctx context.Context, storeID uint64) (*metapb.Store, error)
+	// BatchGetStores gets the stores of the IDs in batches. The stores are in
+	// the order of the IDs, and the ones not found or tombstone are nil.
+	BatchGetStores(ctx context.Context, storeIDs []uint64) ([]*metapb.Store, error)
 	// GetAllStores gets all stores from pd.
 	// The store may expire later. Caller is responsible for caching and taking care
 	// of store change.
@@ -138,6 +148,10 @@ type Client interface {
 
 	// KeyspaceClient manages keyspace metadata.
 	KeyspaceClient
+	// RuleClient manages the placement rules and the region label rules.
+	RuleClient
+	// BucketClient watches the buckets of the regions.
+	BucketClient
 	// ResourceManagerClient manages resource group metadata and token assignment.
 	ResourceManagerClient
 	// TSOClient is the client of TSO service
@@ -199,9 +213,13 @@ type tsoRequest struct {
 	physical   int64
 	logical    int64
 	dcLocation string
+	// instrumentation observes the time waiting for the result if not nil.
+	instrumentation Instrumentation
 }
 
 type tsoBatchController struct {
+	// maxBatchSize is the capacity of the batch, while the size of each batch
+	// is also limited by the MaxTSOBatchSize option.
 	maxBatchSize int
 	// bestBatchSize is a dynamic size that changed based on the current batch effect.
 	bestBatchSize int
@@ -225,7 +243,15 @@ func newTSOBatchController(tsoRequestCh chan *tsoRequest, maxBatchSize int) *tso
 
 // fetchPendingRequests will start a new round of the batch collecting from the channel.
 // It returns true if everything goes well, otherwise false which means we should stop the service.
-func (tbc *tsoBatchController) fetchPendingRequests(ctx context.Context, maxBatchWaitInterval time.Duration) error {
+// If adaptive is false, it waits for maxBatchSize requests rather than the best batch size.
+func (tbc *tsoBatchController) fetchPendingRequests(
+	ctx context.Context, maxBatchSize int, maxBatchWaitInterval time.Duration, adaptive bool,
+) error {
+	maxBatchSize = tbc.limitBatchSize(maxBatchSize)
+	targetBatchSize := tbc.bestBatchSize
+	if !adaptive {
+		targetBatchSize = maxBatchSize
+	}
 	var firstTSORequest *tsoRequest
 	select {
 	case <-ctx.Done():
@@ -237,9 +263,9 @@ func (tbc *tsoBatchController) fetchPendingRequests(ctx context.Context, maxBatc
 	tbc.collectedRequestCount = 0
 	tbc.pushRequest(firstTSORequest)
 
-	// This loop is for trying best to collect more requests, so we use `tbc.maxBatchSize` here.
+	// This loop is for trying best to collect more requests, so we use `maxBatchSize` here.
 fetchPendingRequestsLoop:
-	for tbc.collectedRequestCount < tbc.maxBatchSize {
+	for tbc.collectedRequestCount < maxBatchSize {
 		select {
 		case tsoReq := <-tbc.tsoRequestCh:
 			tbc.pushRequest(tsoReq)
@@ -252,17 +278,17 @@ fetchPendingRequestsLoop:
 
 	// Check whether we should fetch more pending TSO requests from the channel.
 	// TODO: maybe consider the actual load that returns through a TSO response from PD server.
-	if tbc.collectedRequestCount >= tbc.maxBatchSize || maxBatchWaitInterval <= 0 {
+	if tbc.collectedRequestCount >= maxBatchSize || maxBatchWaitInterval <= 0 {
 		return nil
 	}
 
 	// Fetches more pending TSO requests from the channel.
-	// Try to collect `tbc.bestBatchSize` requests, or wait `maxBatchWaitInterval`
-	// when `tbc.collectedRequestCount` is less than the `tbc.bestBatchSize`.
-	if tbc.collectedRequestCount < tbc.bestBatchSize {
+	// Try to collect `targetBatchSize` requests, or wait `maxBatchWaitInterval`
+	// when `tbc.collectedRequestCount` is less than the `targetBatchSize`.
+	if tbc.collectedRequestCount < targetBatchSize {
 		after := time.NewTimer(maxBatchWaitInterval)
 		defer after.Stop()
-		for tbc.collectedRequestCount < tbc.bestBatchSize {
+		for tbc.collectedRequestCount < targetBatchSize {
 			select {
 			case tsoReq := <-tbc.tsoRequestCh:
 				tbc.pushRequest(tsoReq)
@@ -274,10 +300,10 @@ fetchPendingRequestsLoop:
 		}
 	}
 
-	// Do an additional non-block try. Here we test the length with `tbc.maxBatchSize` instead
-	// of `tbc.bestBatchSize` because trying best to fetch more requests is necessary so that
+	// Do an additional non-block try. Here we test the length with `maxBatchSize` instead
+	// of `targetBatchSize` because trying best to fetch more requests is necessary so that
 	// we can adjust the `tbc.bestBatchSize` dynamically later.
-	for tbc.collectedRequestCount < tbc.maxBatchSize {
+	for tbc.collectedRequestCount < maxBatchSize {
 		select {
 		case tsoReq := <-tbc.tsoRequestCh:
 			tbc.pushRequest(tsoReq)
@@ -299,15 +325,28 @@ func (tbc *tsoBatchController) getCollectedRequests() []*tsoRequest {
 	return tbc.collectedRequests[:tbc.collectedRequestCount]
 }
 
+// limitBatchSize limits the max batch size by the capacity, and the best batch
+// size by the max batch size.
+func (tbc *tsoBatchController) limitBatchSize(maxBatchSize int) int {
+	if maxBatchSize < 1 || maxBatchSize > tbc.maxBatchSize {
+		maxBatchSize = tbc.maxBatchSize
+	}
+	if tbc.bestBatchSize > maxBatchSize {
+		tbc.bestBatchSize = maxBatchSize
+	}
+	return maxBatchSize
+}
+
 // adjustBestBatchSize stabilizes the latency with the AIAD algorithm.
-func (tbc *tsoBatchController) adjustBestBatchSize() {
+func (tbc *tsoBatchController) adjustBestBatchSize(maxBatchSize int) {
+	maxBatchSize = tbc.limitBatchSize(maxBatchSize)
 	tsoBestBatchSize.Observe(float64(tbc.bestBatchSize))
 	length := tbc.collectedRequestCount
 	if length < tbc.bestBatchSize && tbc.bestBatchSize > 1 {
 		// Waits too long to collect requests, reduce the target batch size.
 		tbc.bestBatchSize--
 	} else if length > tbc.bestBatchSize+4 /* Hard-coded number, in order to make `tbc.bestBatchSize` stable */ &&
-		tbc.bestBatchSize < tbc.maxBatchSize {
+		tbc.bestBatchSize < maxBatchSize {
 		tbc.bestBatchSize++
 	}
 }
@@ -383,6 +422,84 @@ func WithMaxErrorRetry(count int) ClientOption {
 	}
 }
 
+// WithTSOBatchPolicy configures the client with the initial TSO batching
+// policy, which can be changed later by UpdateOption with MaxTSOBatchSize,
+// MaxTSOBatchWaitInterval and EnableTSOAdaptiveBatching. The invalid values
+// are ignored with a warning.
+func WithTSOBatchPolicy(maxBatchSize int, maxBatchWaitInterval time.Duration, adaptive bool) ClientOption {
+	return func(c *client) {
+		if err := c.option.setMaxTSOBatchSize(maxBatchSize); err != nil {
+			log.Warn("[pd] ignore the invalid TSO batch policy", errs.ZapError(err))
+		}
+		if err := c.option.setMaxTSOBatchWaitInterval(maxBatchWaitInterval); err != nil {
+			log.Warn("[pd] ignore the invalid TSO batch policy", errs.ZapError(err))
+		}
+		c.option.setEnableTSOAdaptiveBatching(adaptive)
+	}
+}
+
+// WithFollowerRead configures the client to send the read-only requests, i.e.
+// GetRegion, GetPrevRegion, GetRegionByID, ScanRegions, GetStore and
+// GetAllStores, to the PD followers first, which serve them with the data at
+// most maxStaleness old. The requests fall back to the leader if the followers
+// fail to serve them. It can be changed later by UpdateOption with
+// MaxFollowerReadStaleness.
+func WithFollowerRead(maxStaleness time.Duration) ClientOption {
+	return func(c *client) {
+		if err := c.option.setMaxFollowerReadStaleness(maxStaleness); err != nil {
+			log.Warn("[pd] ignore the invalid follower read option", errs.ZapError(err))
+		}
+	}
+}
+
+// WithCircuitBreaker configures the client to stop sending the idempotent
+// read-only requests to a PD member for the cooldown after it fails
+// failureThreshold times in a row, e.g. it's unreachable or too slow. Then a
+// probe request is sent to check whether it recovers. It takes effect with the
+// follower read or the hedging, which provide the other members to send the
+// requests to, and the leader is still tried last if the circuit of it is open.
+func WithCircuitBreaker(failureThreshold int, cooldown time.Duration) ClientOption {
+	return func(c *client) {
+		c.option.circuitBreakerThreshold = failureThreshold
+		c.option.circuitBreakerCooldown = cooldown
+	}
+}
+
+// WithHedging configures the client to send a hedged request to another PD
+// member if an idempotent read-only request gets no response after the delay,
+// and use the first successful response. The hedged request is served by a
+// follower if the follower read is enabled, or forwarded to the leader
+// otherwise, which bypasses a slow network between the client and the leader.
+func WithHedging(delay time.Duration) ClientOption {
+	return func(c *client) {
+		c.option.hedgingDelay = delay
+	}
+}
+
+// WithRetryPolicy configures the client to retry the failed requests with the
+// policy, which can be overridden for a call by ContextWithRetryPolicy. It
+// applies to the requests of the rule and the keyspace GC services, the batch
+// gets, the resumption of ScanRegionsStream, the connections of the TSO and
+// the resource manager streams, and the idempotent read-only requests, i.e.
+// GetRegion, GetPrevRegion, GetRegionByID, ScanRegions, GetStore and
+// GetAllStores. By default, the idempotent reads are not retried, and the
+// others are retried 6 times every 500ms.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *client) {
+		c.option.retryPolicy = &policy
+	}
+}
+
+// WithDiscoveryCache configures the client to persist the membership of PD to
+// the file, i.e. the member URLs and the leader, and to bootstrap with them if
+// all the configured endpoints are unreachable. The cached members are used
+// only if they belong to the same cluster as before.
+func WithDiscoveryCache(path string) ClientOption {
+	return func(c *client) {
+		c.option.discoveryCachePath = path
+	}
+}
+
 type client struct {
 	*baseClient
 	// tsoDispatcher is used to dispatch different TSO requests to
@@ -395,6 +512,12 @@ type client struct {
 
 	tokenDispatcher *tokenDispatcher
 
+	// addr -> *circuitBreaker
+	circuitBreakers sync.Map
+
+	// tsoFailover is nil if the TSO failover is not configured.
+	tsoFailover *tsoFailover
+
 	// For internal usage.
 	checkTSDeadlineCh    chan struct{}
 	leaderNetworkFailure int32
@@ -427,6 +550,10 @@ func NewClientWithContext(ctx context.Context, pdAddrs []string, security Securi
 	go c.tsLoop()
 	go c.tsCancelLoop()
 	go c.leaderCheckLoop()
+	if c.tsoFailover != nil {
+		c.wg.Add(1)
+		go c.tsoFailbackLoop()
+	}
 
 	return c, nil
 }
@@ -448,6 +575,28 @@ func (c *client) UpdateOption(option DynamicOption, value interface{}) error {
 			return errors.New("[pd] invalid value type for EnableTSOFollowerProxy option, it should be bool")
 		}
 		c.option.setEnableTSOFollowerProxy(enable)
+	case MaxTSOBatchSize:
+		size, ok := value.(int)
+		if !ok {
+			return errors.New("[pd] invalid value type for MaxTSOBatchSize option, it should be int")
+		}
+		if err := c.option.setMaxTSOBatchSize(size); err != nil {
+			return err
+		}
+	case EnableTSOAdaptiveBatching:
+		enable, ok := value.(bool)
+		if !ok {
+			return errors.New("[pd] invalid value type for EnableTSOAdaptiveBatching option, it should be bool")
+		}
+		c.option.setEnableTSOAdaptiveBatching(enable)
+	case MaxFollowerReadStaleness:
+		maxStaleness, ok := value.(time.Duration)
+		if !ok {
+			return errors.New("[pd] invalid value type for MaxFollowerReadStaleness option, it should be time.Duration")
+		}
+		if err := c.option.setMaxFollowerReadStaleness(maxStaleness); err != nil {
+			return err
+		}
 	default:
 		return errors.New("[pd] unsupported client option")
 	}
@@ -779,8 +928,10 @@ tsoBatchLoop:
 		default:
 		}
 		// Start to collect the TSO requests.
+		maxBatchSize := c.option.getMaxTSOBatchSize()
 		maxBatchWaitInterval := c.option.getMaxTSOBatchWaitInterval()
-		if err = tbc.fetchPendingRequests(dispatcherCtx, maxBatchWaitInterval); err != nil {
+		adaptive := c.option.getEnableTSOAdaptiveBatching()
+		if err = tbc.fetchPendingRequests(dispatcherCtx, maxBatchSize, maxBatchWaitInterval, adaptive); err != nil {
 			if err == context.Canceled {
 				log.Info("[pd] stop fetching the pending tso requests due to context canceled",
 					zap.String("dc-location", dc))
@@ -790,8 +941,8 @@ tsoBatchLoop:
 			}
 			return
 		}
-		if maxBatchWaitInterval >= 0 {
-			tbc.adjustBestBatchSize()
+		if adaptive {
+			tbc.adjustBestBatchSize(maxBatchSize)
 		}
 		streamLoopTimer.Reset(c.option.timeout)
 		// Choose a stream to send the TSO gRPC request.
@@ -957,8 +1108,9 @@ func (c *client) tryConnect(
 		})
 	}
 	// retry several times before falling back to the follower when the network problem happens
-
-	for i := 0; i < maxRetryTimes; i++ {
+	policy := c.option.getRetryPolicy(dispatcherCtx, defaultRetryPolicy)
+	var attempt int
+	for attempt = 1; ; attempt++ {
 		c.ScheduleCheckLeader()
 		cc, url = c.getAllocatorClientConnByDCLocation(dc)
 		cctx, cancel := context.WithCancel(dispatcherCtx)
@@ -984,14 +1136,16 @@ func (c *client) tryConnect(
 		}
 
 		cancel()
-		select {
-		case <-dispatcherCtx.Done():
+		if !policy.retryable(attempt, err, nil) {
+			break
+		}
+		c.option.observeRetry(dispatcherCtx, tsoMethod, attempt, err)
+		if !policy.wait(dispatcherCtx, attempt) {
 			return err
-		case <-time.After(retryInterval):
 		}
 	}
 
-	if networkErrNum == maxRetryTimes {
+	if networkErrNum == uint64(attempt) {
 		// encounter the network error
 		followerClient, addr := c.followerClient()
 		if followerClient != nil {
@@ -1170,6 +1324,9 @@ func (c *client) finishTSORequest(requests []*tsoRequest, physical, firstLogical
 func (c *client) Close() {
 	c.cancel()
 	c.wg.Wait()
+	if c.tsoFailover != nil {
+		c.tsoFailover.close()
+	}
 
 	c.tsoDispatcher.Range(func(_, dispatcherInterface interface{}) bool {
 		if dispatcherInterface != nil {
@@ -1226,6 +1383,168 @@ func (c *client) followerClient() (pdpb.PDClient, string) {
 	return nil, ""
 }
 
+// readTarget is a PD member to send an idempotent read-only request to.
+type readTarget struct {
+	addr string
+	cli  pdpb.PDClient
+	// maxStaleness is the staleness accepted by the follower read, 0 means the
+	// request is served or forwarded to the leader.
+	maxStaleness time.Duration
+}
+
+func (t *readTarget) context(ctx context.Context, leaderAddr string) context.Context {
+	if t.maxStaleness > 0 {
+		return grpcutil.BuildMaxStalenessContext(ctx, t.maxStaleness)
+	}
+	return grpcutil.BuildForwardContext(ctx, leaderAddr)
+}
+
+// getCircuitBreaker returns the circuit breaker of the member, which is nil if
+// the circuit breaker is disabled.
+func (c *client) getCircuitBreaker(addr string) *circuitBreaker {
+	if c.option.circuitBreakerThreshold <= 0 {
+		return nil
+	}
+	if cb, ok := c.circuitBreakers.Load(addr); ok {
+		return cb.(*circuitBreaker)
+	}
+	cb, _ := c.circuitBreakers.LoadOrStore(addr, newCircuitBreaker(c.option.circuitBreakerThreshold, c.option.circuitBreakerCooldown))
+	return cb.(*circuitBreaker)
+}
+
+// getReadTargets returns the members to send an idempotent read-only request to
+// in order. A random follower is tried before the leader if the follower read
+// is enabled, or after the leader as the hedged request if the hedging is
+// enabled. The members with the open circuits are skipped, except the leader.
+func (c *client) getReadTargets() []*readTarget {
+	now := time.Now()
+	leaderAddr := c.GetLeaderAddr()
+	leader := &readTarget{addr: leaderAddr, cli: c.getClient()}
+	maxStaleness := c.option.getMaxFollowerReadStaleness()
+	if maxStaleness <= 0 && c.option.hedgingDelay <= 0 {
+		return []*readTarget{leader}
+	}
+	var follower *readTarget
+	addrs := c.GetFollowerAddrs()
+	for _, i := range rand.Perm(len(addrs)) {
+		if !c.getCircuitBreaker(addrs[i]).allow(now) {
+			continue
+		}
+		if cc, err := c.getOrCreateGRPCConn(addrs[i]); err == nil {
+			follower = &readTarget{addr: addrs[i], cli: pdpb.NewPDClient(cc), maxStaleness: maxStaleness}
+			break
+		}
+	}
+	switch {
+	case follower == nil:
+		return []*readTarget{leader}
+	case maxStaleness > 0 || !c.getCircuitBreaker(leaderAddr).allow(now):
+		return []*readTarget{follower, leader}
+	default:
+		return []*readTarget{leader, follower}
+	}
+}
+
+// readIdempotent sends the idempotent read-only request of the method, and
+// retries it with the retry policy if it fails.
+func readIdempotent[T interface{ GetHeader() *pdpb.ResponseHeader }](
+	ctx context.Context, c *client, method string, read func(ctx context.Context, cli pdpb.PDClient) (T, error),
+) (T, error) {
+	var resp T
+	policy := c.option.getRetryPolicy(ctx, noRetryPolicy)
+	err := c.option.retry(ctx, method, policy, IsRetryableError, func() (err error) {
+		resp, err = readIdempotentOnce(ctx, c, read)
+		return err
+	})
+	return resp, err
+}
+
+// readIdempotentOnce sends the idempotent read-only request to the targets
+// returned by getReadTargets. The next target is tried if the previous one
+// fails, or gets no response after the hedging delay. It returns the first
+// successful response, or the result of the last target if all of them fail.
+func readIdempotentOnce[T interface{ GetHeader() *pdpb.ResponseHeader }](
+	ctx context.Context, c *client, read func(ctx context.Context, cli pdpb.PDClient) (T, error),
+) (T, error) {
+	targets := c.getReadTargets()
+	leaderAddr := c.GetLeaderAddr()
+	hedgingDelay := c.option.hedgingDelay
+	type result struct {
+		index int
+		resp  T
+		err   error
+	}
+	// The losers of the hedged requests are canceled.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan result, len(targets))
+	send := func(i int) {
+		target := targets[i]
+		attemptCtx, attemptCancel := ctx, context.CancelFunc(func() {})
+		if hedgingDelay <= 0 && i < len(targets)-1 {
+			// Leave the rest of the time to the next target in case this one is unavailable.
+			attemptCtx, attemptCancel = context.WithTimeout(ctx, c.option.timeout/2)
+		}
+		if i > 0 && hedgingDelay > 0 {
+			hedgedRequestsSent.Inc()
+		}
+		go func() {
+			defer attemptCancel()
+			resp, err := read(target.context(attemptCtx, leaderAddr), target.cli)
+			results <- result{index: i, resp: resp, err: err}
+		}()
+	}
+	var hedgingTimer <-chan time.Time
+	if hedgingDelay > 0 && len(targets) > 1 {
+		timer := time.NewTimer(hedgingDelay)
+		defer timer.Stop()
+		hedgingTimer = timer.C
+	}
+	send(0)
+	sent, done := 1, 0
+	var last result
+	for done < sent {
+		select {
+		case <-hedgingTimer:
+			if sent < len(targets) {
+				send(sent)
+				sent++
+			}
+			continue
+		case last = <-results:
+			done++
+		}
+		target := targets[last.index]
+		success := last.err == nil && last.resp.GetHeader().GetError() == nil
+		// The errors of the canceled losers are not the faults of the members.
+		if ctx.Err() == nil && c.getCircuitBreaker(target.addr).report(time.Now(), last.err == nil) {
+			circuitBreakerTrips.WithLabelValues(target.addr).Inc()
+			log.Warn("[pd] stop sending the requests to the member failing too many times", zap.String("addr", target.addr),
+				zap.Duration("cooldown", c.option.circuitBreakerCooldown), errs.ZapError(last.err))
+		}
+		if target.maxStaleness > 0 {
+			if success {
+				requestFollowerReadServed.Inc()
+			} else {
+				requestFollowerReadFallback.Inc()
+				log.Debug("[pd] fall back to the leader for the follower read", zap.String("follower", target.addr),
+					zap.String("header-error", last.resp.GetHeader().GetError().GetMessage()), errs.ZapError(last.err))
+			}
+		}
+		if success {
+			if last.index > 0 && hedgingDelay > 0 && done < sent {
+				hedgedRequestsWon.Inc()
+			}
+			return last.resp, nil
+		}
+		if sent < len(targets) {
+			send(sent)
+			sent++
+		}
+	}
+	return last.resp, last.err
+}
+
 func (c *client) getClient() pdpb.PDClient {
 	if c.option.enableForwarding && atomic.LoadInt32(&c.leaderNetworkFailure) == 1 {
 		followerClient, addr := c.followerClient()
@@ -1272,6 +1591,9 @@ var tsoReqPool = sync.Pool{
 }
 
 func (c *client) GetTSAsync(ctx context.Context) TSFuture {
+	if c.tsoFailover != nil {
+		return c.getTSWithFailover(ctx)
+	}
 	return c.GetLocalTSAsync(ctx, globalDCLocation)
 }
 
@@ -1285,6 +1607,7 @@ func (c *client) GetLocalTSAsync(ctx context.Context, dcLocation string) TSFutur
 	req.clientCtx = c.ctx
 	req.start = time.Now()
 	req.dcLocation = dcLocation
+	req.instrumentation = c.option.instrumentation
 	if err := c.dispatchRequest(dcLocation, req); err != nil {
 		// Wait for a while and try again
 		time.Sleep(50 * time.Millisecond)
@@ -1318,6 +1641,11 @@ func (req *tsoRequest) Wait() (physical int64, logical int64, err error) {
 	// takes too long for Wait() be called.
 	start := time.Now()
 	cmdDurationTSOAsyncWait.Observe(start.Sub(req.start).Seconds())
+	// The request is put back to the pool once it's done, so copy the fields here.
+	if instrumentation := req.instrumentation; instrumentation != nil {
+		ctx, dcLocation, reqStart := req.requestCtx, req.dcLocation, req.start
+		defer func() { instrumentation.ObserveTSOWait(ctx, dcLocation, time.Since(reqStart), err) }()
+	}
 	select {
 	case err = <-req.done:
 		err = errors.WithStack(err)
@@ -1383,8 +1711,9 @@ func (c *client) GetRegion(ctx context.Context, key []byte, opts ...GetRegionOpt
 		RegionKey:   key,
 		NeedBuckets: options.needBuckets,
 	}
-	ctx = grpcutil.BuildForwardContext(ctx, c.GetLeaderAddr())
-	resp, err := c.getClient().GetRegion(ctx, req)
+	resp, err := readIdempotent(ctx, c, "/pdpb.PD/GetRegion", func(ctx context.Context, cli pdpb.PDClient) (*pdpb.GetRegionResponse, error) {
+		return cli.GetRegion(ctx, req)
+	})
 	cancel()
 
 	if err = c.respForErr(cmdFailDurationGetRegion, start, err, resp.GetHeader()); err != nil {
@@ -1453,8 +1782,9 @@ func (c *client) GetPrevRegion(ctx context.Context, key []byte, opts ...GetRegio
 		RegionKey:   key,
 		NeedBuckets: options.needBuckets,
 	}
-	ctx = grpcutil.BuildForwardContext(ctx, c.GetLeaderAddr())
-	resp, err := c.getClient().GetPrevRegion(ctx, req)
+	resp, err := readIdempotent(ctx, c, "/pdpb.PD/GetPrevRegion", func(ctx context.Context, cli pdpb.PDClient) (*pdpb.GetRegionResponse, error) {
+		return cli.GetPrevRegion(ctx, req)
+	})
 	cancel()
 
 	if err = c.respForErr(cmdFailDurationGetPrevRegion, start, err, resp.GetHeader()); err != nil {
@@ -1481,8 +1811,9 @@ func (c *client) GetRegionByID(ctx context.Context, regionID uint64, opts ...Get
 		RegionId:    regionID,
 		NeedBuckets: options.needBuckets,
 	}
-	ctx = grpcutil.BuildForwardContext(ctx, c.GetLeaderAddr())
-	resp, err := c.getClient().GetRegionByID(ctx, req)
+	resp, err := readIdempotent(ctx, c, "/pdpb.PD/GetRegionByID", func(ctx context.Context, cli pdpb.PDClient) (*pdpb.GetRegionResponse, error) {
+		return cli.GetRegionByID(ctx, req)
+	})
 	cancel()
 
 	if err = c.respForErr(cmdFailedDurationGetRegionByID, start, err, resp.GetHeader()); err != nil {
@@ -1511,8 +1842,9 @@ func (c *client) ScanRegions(ctx context.Context, key, endKey []byte, limit int)
 		EndKey:   endKey,
 		Limit:    int32(limit),
 	}
-	scanCtx = grpcutil.BuildForwardContext(scanCtx, c.GetLeaderAddr())
-	resp, err := c.getClient().ScanRegions(scanCtx, req)
+	resp, err := readIdempotent(scanCtx, c, "/pdpb.PD/ScanRegions", func(ctx context.Context, cli pdpb.PDClient) (*pdpb.ScanRegionsResponse, error) {
+		return cli.ScanRegions(ctx, req)
+	})
 
 	if err = c.respForErr(cmdFailedDurationScanRegions, start, err, resp.GetHeader()); err != nil {
 		return nil, err
@@ -1562,8 +1894,9 @@ func (c *client) GetStore(ctx context.Context, storeID uint64) (*metapb.Store, e
 		Header:  c.requestHeader(),
 		StoreId: storeID,
 	}
-	ctx = grpcutil.BuildForwardContext(ctx, c.GetLeaderAddr())
-	resp, err := c.getClient().GetStore(ctx, req)
+	resp, err := readIdempotent(ctx, c, "/pdpb.PD/GetStore", func(ctx context.Context, cli pdpb.PDClient) (*pdpb.GetStoreResponse, error) {
+		return cli.GetStore(ctx, req)
+	})
 	cancel()
 
 	if err = c.respForErr(cmdFailedDurationGetStore, start, err, resp.GetHeader()); err != nil {
@@ -1602,8 +1935,9 @@ func (c *client) GetAllStores(ctx context.Context, opts ...GetStoreOption) ([]*m
 		Header:                 c.requestHeader(),
 		ExcludeTombstoneStores: options.excludeTombstone,
 	}
-	ctx = grpcutil.BuildForwardContext(ctx, c.GetLeaderAddr())
-	resp, err := c.getClient().GetAllStores(ctx, req)
+	resp, err := readIdempotent(ctx, c, "/pdpb.PD/GetAllStores", func(ctx context.Context, cli pdpb.PDClient) (*pdpb.GetAllStoresResponse, error) {
+		return cli.GetAllStores(ctx, req)
+	})
 	cancel()
 
 	if err = c.respForErr(cmdFailedDurationGetAllStores, start, err, resp.GetHeader()); err != nil {
@@ -130,3 +130,43 @@ func TestTsoRequestWait(t *testing.T) {
 	_, _, err = req.Wait()
 	re.ErrorIs(errors.Cause(err), context.Canceled)
 }
+
+func TestTSOBatchPolicy(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tbc := newTSOBatchController(make(chan *tsoRequest, 100), 100)
+	push := func(n int) {
+		for i := 0; i < n; i++ {
+			tbc.tsoRequestCh <- &tsoRequest{}
+		}
+	}
+
+	// The batch size is limited by the option.
+	push(20)
+	re.NoError(tbc.fetchPendingRequests(ctx, 5, 0, true))
+	re.Len(tbc.getCollectedRequests(), 5)
+	re.NoError(tbc.fetchPendingRequests(ctx, 0, 0, true))
+	re.Len(tbc.getCollectedRequests(), 15)
+
+	// The best batch size is limited by the max batch size.
+	tbc.adjustBestBatchSize(2)
+	re.LessOrEqual(tbc.bestBatchSize, 2)
+
+	// The adaptive batching waits for the best batch size only.
+	push(2)
+	start := time.Now()
+	re.NoError(tbc.fetchPendingRequests(ctx, 10, time.Second, true))
+	re.Len(tbc.getCollectedRequests(), 2)
+	re.Less(time.Since(start), time.Second)
+
+	// Otherwise, it waits for the max batch size or the max wait interval.
+	push(2)
+	start = time.Now()
+	re.NoError(tbc.fetchPendingRequests(ctx, 10, 50*time.Millisecond, false))
+	re.Len(tbc.getCollectedRequests(), 2)
+	re.GreaterOrEqual(time.Since(start), 50*time.Millisecond)
+	go push(10)
+	re.NoError(tbc.fetchPendingRequests(ctx, 10, time.Minute, false))
+	re.Len(tbc.getCollectedRequests(), 10)
+}
@@ -0,0 +1,33 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeBytes(t *testing.T) {
+	re := require.New(t)
+	key := "abcdefghijklmnopqrstuvwxyz"
+	for i := 0; i < len(key); i++ {
+		_, k, err := DecodeBytes(EncodeBytes([]byte(key[:i])))
+		re.NoError(err)
+		re.Equal(key[:i], string(k))
+	}
+	_, _, err := DecodeBytes([]byte("abc"))
+	re.Error(err)
+}
@@ -0,0 +1,135 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/client/errs"
+	"go.uber.org/zap"
+)
+
+// discoveryCache is the last known membership of the PD cluster persisted by
+// the client, which is used to bootstrap if the configured endpoints are
+// unreachable, e.g. they are all replaced or restarting.
+type discoveryCache struct {
+	ClusterID  uint64    `json:"cluster_id"`
+	Leader     string    `json:"leader,omitempty"`
+	URLs       []string  `json:"urls"`
+	UpdateTime time.Time `json:"update_time"`
+}
+
+func loadDiscoveryCache(path string) (*discoveryCache, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	cache := &discoveryCache{}
+	if err := json.Unmarshal(data, cache); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if cache.ClusterID == 0 || len(cache.URLs) == 0 {
+		return nil, errors.New("the discovery cache has no cluster id or urls")
+	}
+	return cache, nil
+}
+
+// save writes the cache to a temporary file and renames it to the path, so
+// the cache is never partially written.
+func (d *discoveryCache) save(path string) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.Rename(tmp, path))
+}
+
+// bootstrapURLs returns the URLs to bootstrap with, where the leader is the
+// first one since it's the most likely to be alive.
+func (d *discoveryCache) bootstrapURLs() []string {
+	urls := make([]string, 0, len(d.URLs)+1)
+	if d.Leader != "" {
+		urls = append(urls, d.Leader)
+	}
+	for _, u := range d.URLs {
+		if u != d.Leader {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// sameMembers returns whether the caches have the same membership regardless
+// of the update time.
+func (d *discoveryCache) sameMembers(o *discoveryCache) bool {
+	return d.ClusterID == o.ClusterID && d.Leader == o.Leader && reflect.DeepEqual(d.URLs, o.URLs)
+}
+
+// initClusterIDFromDiscoveryCache gets the cluster ID from the URLs in the
+// discovery cache, and uses them to bootstrap if they belong to the cached
+// cluster. It returns 0 if there is no cache or it fails.
+func (c *baseClient) initClusterIDFromDiscoveryCache() uint64 {
+	path := c.option.discoveryCachePath
+	if path == "" {
+		return 0
+	}
+	cache, err := loadDiscoveryCache(path)
+	if err != nil {
+		log.Warn("[pd] failed to load the discovery cache", zap.String("path", path), errs.ZapError(err))
+		return 0
+	}
+	urls := cache.bootstrapURLs()
+	clusterID, err := c.getClusterID(urls)
+	if err != nil || clusterID != cache.ClusterID {
+		log.Warn("[pd] failed to get cluster id from the discovery cache",
+			zap.Strings("urls", urls), zap.Uint64("cached-cluster-id", cache.ClusterID),
+			zap.Uint64("cluster-id", clusterID), errs.ZapError(err))
+		return 0
+	}
+	log.Warn("[pd] the configured urls are unreachable, bootstrap from the discovery cache",
+		zap.Strings("urls", urls), zap.Time("update-time", cache.UpdateTime))
+	c.urls.Store(urls)
+	return clusterID
+}
+
+// saveDiscoveryCache persists the current membership if the discovery cache is
+// enabled and the membership is changed since the last save.
+func (c *baseClient) saveDiscoveryCache() {
+	path := c.option.discoveryCachePath
+	if path == "" {
+		return
+	}
+	cache := &discoveryCache{ClusterID: c.clusterID, Leader: c.GetLeaderAddr(), URLs: c.GetURLs()}
+	c.discoveryCacheMu.Lock()
+	defer c.discoveryCacheMu.Unlock()
+	if c.discoveryCache != nil && c.discoveryCache.sameMembers(cache) {
+		return
+	}
+	cache.UpdateTime = time.Now()
+	if err := cache.save(path); err != nil {
+		log.Warn("[pd] failed to save the discovery cache", zap.String("path", path), errs.ZapError(err))
+		return
+	}
+	c.discoveryCache = cache
+}
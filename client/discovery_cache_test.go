@@ -0,0 +1,59 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoveryCache(t *testing.T) {
+	re := require.New(t)
+	path := filepath.Join(t.TempDir(), "pd-members.json")
+	_, err := loadDiscoveryCache(path)
+	re.Error(err)
+
+	cache := &discoveryCache{
+		ClusterID:  1,
+		Leader:     "http://127.0.0.1:2379",
+		URLs:       []string{"http://127.0.0.1:12379", "http://127.0.0.1:2379", "http://127.0.0.1:22379"},
+		UpdateTime: time.Now(),
+	}
+	re.NoError(cache.save(path))
+	loaded, err := loadDiscoveryCache(path)
+	re.NoError(err)
+	re.True(cache.sameMembers(loaded))
+	re.True(cache.UpdateTime.Equal(loaded.UpdateTime))
+	re.Equal([]string{"http://127.0.0.1:2379", "http://127.0.0.1:12379", "http://127.0.0.1:22379"}, loaded.bootstrapURLs())
+	_, err = os.Stat(path + ".tmp")
+	re.True(os.IsNotExist(err))
+
+	loaded.Leader = "http://127.0.0.1:12379"
+	re.False(cache.sameMembers(loaded))
+	loaded.Leader = ""
+	re.Equal(cache.URLs, loaded.bootstrapURLs())
+
+	// The invalid caches are not loaded.
+	re.NoError(os.WriteFile(path, []byte("{"), 0o600))
+	_, err = loadDiscoveryCache(path)
+	re.Error(err)
+	re.NoError((&discoveryCache{ClusterID: 1}).save(path))
+	_, err = loadDiscoveryCache(path)
+	re.Error(err)
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"crypto/tls"
 	"net/url"
+	"time"
 
 	"github.com/tikv/pd/client/errs"
 	"google.golang.org/grpc"
@@ -25,8 +26,13 @@ import (
 	"google.golang.org/grpc/metadata"
 )
 
-// ForwardMetadataKey is used to record the forwarded host of PD.
-const ForwardMetadataKey = "pd-forwarded-host"
+const (
+	// ForwardMetadataKey is used to record the forwarded host of PD.
+	ForwardMetadataKey = "pd-forwarded-host"
+	// MaxStalenessMetadataKey is used to allow a PD follower to serve the read-only
+	// request with its synced data, which is at most the max staleness old.
+	MaxStalenessMetadataKey = "pd-max-staleness"
+)
 
 // GetClientConn returns a gRPC client connection.
 // creates a client connection to the given target. By default, it's
@@ -64,3 +70,9 @@ func BuildForwardContext(ctx context.Context, addr string) context.Context {
 	md := metadata.Pairs(ForwardMetadataKey, addr)
 	return metadata.NewOutgoingContext(ctx, md)
 }
+
+// BuildMaxStalenessContext creates a context which allows a follower to serve the
+// request with data at most maxStaleness old. It is used in client side.
+func BuildMaxStalenessContext(ctx context.Context, maxStaleness time.Duration) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, MaxStalenessMetadataKey, maxStaleness.String())
+}
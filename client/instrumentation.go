@@ -0,0 +1,106 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// The full gRPC method names of the retried streams and initialization, which
+// are passed to Instrumentation.ObserveRetry.
+const (
+	getMembersMethod          = "/pdpb.PD/GetMembers"
+	tsoMethod                 = "/pdpb.PD/Tso"
+	acquireTokenBucketsMethod = "/resource_manager.ResourceManager/AcquireTokenBuckets"
+)
+
+// Instrumentation is the hooks to plug the tracing and the metrics exporters,
+// e.g. OpenTelemetry, into the client. The methods may be called concurrently.
+type Instrumentation interface {
+	// StartRPC is called before a unary RPC is sent to PD, where the method is
+	// the full gRPC method name, e.g. "/pdpb.PD/GetRegion". The RPC is sent with
+	// the returned context, and the returned function is called with the error
+	// of the RPC when it's done. Each attempt of a retried request is an RPC.
+	StartRPC(ctx context.Context, method string) (context.Context, func(err error))
+	// InjectTraceContext returns the trace context in the context to propagate
+	// to PD, e.g. the "traceparent" and "tracestate" of W3C Trace Context. They
+	// are sent as the gRPC metadata of the RPC, which is kept when a follower
+	// forwards the RPC to the leader.
+	InjectTraceContext(ctx context.Context) map[string]string
+	// ObserveTSOWait observes the time from a TSO request being issued to its
+	// result being returned by Wait.
+	ObserveTSOWait(ctx context.Context, dcLocation string, duration time.Duration, err error)
+	// ObserveRetry is called when the client retries the method after the
+	// attempt fails, e.g. when the PD leader is unreachable.
+	ObserveRetry(ctx context.Context, method string, attempt int, err error)
+}
+
+// NoopInstrumentation is an Instrumentation doing nothing. It can be embedded to
+// implement only a part of the hooks.
+type NoopInstrumentation struct{}
+
+// StartRPC implements Instrumentation.
+func (NoopInstrumentation) StartRPC(ctx context.Context, _ string) (context.Context, func(err error)) {
+	return ctx, func(error) {}
+}
+
+// InjectTraceContext implements Instrumentation.
+func (NoopInstrumentation) InjectTraceContext(context.Context) map[string]string {
+	return nil
+}
+
+// ObserveTSOWait implements Instrumentation.
+func (NoopInstrumentation) ObserveTSOWait(context.Context, string, time.Duration, error) {}
+
+// ObserveRetry implements Instrumentation.
+func (NoopInstrumentation) ObserveRetry(context.Context, string, int, error) {}
+
+// WithInstrumentation configures the client with the hooks of the telemetry.
+func WithInstrumentation(instrumentation Instrumentation) ClientOption {
+	return func(c *client) {
+		c.option.instrumentation = instrumentation
+		c.option.gRPCDialOptions = append(c.option.gRPCDialOptions,
+			grpc.WithChainUnaryInterceptor(newInstrumentationInterceptor(instrumentation)))
+	}
+}
+
+// newInstrumentationInterceptor returns the interceptor calling the hooks of the
+// unary RPCs, and propagating the trace context to PD.
+func newInstrumentationInterceptor(instrumentation Instrumentation) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, finish := instrumentation.StartRPC(ctx, method)
+		if traceContext := instrumentation.InjectTraceContext(ctx); len(traceContext) > 0 {
+			kv := make([]string, 0, len(traceContext)*2)
+			for k, v := range traceContext {
+				kv = append(kv, k, v)
+			}
+			ctx = metadata.AppendToOutgoingContext(ctx, kv...)
+		}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		finish(err)
+		return err
+	}
+}
+
+// observeRetry calls the retry hook if the instrumentation is configured.
+func (o *option) observeRetry(ctx context.Context, method string, attempt int, err error) {
+	if o.instrumentation != nil {
+		o.instrumentation.ObserveRetry(ctx, method, attempt, err)
+	}
+}
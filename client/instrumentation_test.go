@@ -0,0 +1,66 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pingcap/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type testInstrumentation struct {
+	NoopInstrumentation
+	methods []string
+	errs    []error
+}
+
+type testTraceKey struct{}
+
+func (i *testInstrumentation) StartRPC(ctx context.Context, method string) (context.Context, func(err error)) {
+	i.methods = append(i.methods, method)
+	return context.WithValue(ctx, testTraceKey{}, "00-trace-span-01"), func(err error) { i.errs = append(i.errs, err) }
+}
+
+func (*testInstrumentation) InjectTraceContext(ctx context.Context) map[string]string {
+	return map[string]string{"traceparent": ctx.Value(testTraceKey{}).(string)}
+}
+
+func TestInstrumentationInterceptor(t *testing.T) {
+	re := require.New(t)
+	instrumentation := &testInstrumentation{}
+	interceptor := newInstrumentationInterceptor(instrumentation)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "pd-forwarded-host", "leader")
+	invokeErr := errors.New("invoke error")
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, ok := metadata.FromOutgoingContext(ctx)
+		re.True(ok)
+		// The existing metadata is kept.
+		re.Equal([]string{"leader"}, md.Get("pd-forwarded-host"))
+		re.Equal([]string{"00-trace-span-01"}, md.Get("traceparent"))
+		return invokeErr
+	}
+	err := interceptor(ctx, "/pdpb.PD/GetRegion", nil, nil, nil, invoker)
+	re.Equal(invokeErr, err)
+	re.Equal([]string{"/pdpb.PD/GetRegion"}, instrumentation.methods)
+	re.Equal([]error{invokeErr}, instrumentation.errs)
+
+	// The option without the instrumentation does nothing.
+	o := newOption()
+	o.observeRetry(ctx, tsoMethod, 1, invokeErr)
+}
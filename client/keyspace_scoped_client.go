@@ -0,0 +1,321 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/kvproto/pkg/keyspacepb"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/tikv/pd/client/codec"
+)
+
+// keyspaceGCServiceName is the name of the PD gRPC service managing the GC
+// safepoints of the keyspaces, whose messages are encoded in JSON.
+const keyspaceGCServiceName = "pd.KeyspaceGC"
+
+// KeyMode is the mode of the keys in a keyspace, which is the first byte of
+// the keys in TiKV.
+type KeyMode byte
+
+const (
+	// KeyModeRaw is the mode of the keys written by RawKV.
+	KeyModeRaw KeyMode = 'r'
+	// KeyModeTxn is the mode of the keys written by TxnKV, e.g. TiDB.
+	KeyModeTxn KeyMode = 'x'
+)
+
+// KeyspaceScopedClient is a PD client bound to a keyspace. The keys passed to
+// and returned by it are the ones in the keyspace, i.e. without the prefix of
+// the keyspace and not encoded as the region keys, and the regions returned by
+// it are clipped to the key range of the keyspace.
+type KeyspaceScopedClient interface {
+	// GetKeyspace returns the metadata of the keyspace loaded when the client
+	// is created.
+	GetKeyspace() *keyspacepb.KeyspaceMeta
+	// GetTS gets a timestamp from the TSO serving the keyspace.
+	GetTS(ctx context.Context) (int64, int64, error)
+	// GetTSAsync gets a timestamp from the TSO serving the keyspace, without
+	// blocking the caller.
+	GetTSAsync(ctx context.Context) TSFuture
+	// GetRegion gets the region containing the key in the keyspace.
+	GetRegion(ctx context.Context, key []byte, opts ...GetRegionOption) (*Region, error)
+	// GetPrevRegion gets the previous region of the one containing the key in
+	// the keyspace, it returns nil if the region is the first one.
+	GetPrevRegion(ctx context.Context, key []byte, opts ...GetRegionOption) (*Region, error)
+	// GetRegionByID gets the region by its ID, it returns nil if the region
+	// doesn't overlap with the keyspace.
+	GetRegionByID(ctx context.Context, regionID uint64, opts ...GetRegionOption) (*Region, error)
+	// ScanRegions gets at most limit regions overlapping with [key, endKey) of
+	// the keyspace, where an empty endKey means the end of the keyspace.
+	ScanRegions(ctx context.Context, key, endKey []byte, limit int) ([]*Region, error)
+	// UpdateGCSafePoint updates the GC safepoint of the keyspace and returns the
+	// new one, which is not updated if the given one is less than the current one.
+	UpdateGCSafePoint(ctx context.Context, safePoint uint64) (uint64, error)
+	// UpdateServiceGCSafePoint updates the safepoint of the service in the
+	// keyspace and returns the min service safepoint of the keyspace. The
+	// service safepoint is removed if the ttl is not positive.
+	UpdateServiceGCSafePoint(ctx context.Context, serviceID string, ttl int64, safePoint uint64) (uint64, error)
+	// GetClient returns the client not bound to the keyspace, e.g. to get the
+	// stores.
+	GetClient() Client
+	// Close closes the client.
+	Close()
+}
+
+type updateKeyspaceGCSafePointRequest struct {
+	Header       *pdpb.RequestHeader `json:"header"`
+	KeyspaceName string              `json:"keyspace_name"`
+	SafePoint    uint64              `json:"safe_point"`
+}
+
+type updateKeyspaceGCSafePointResponse struct {
+	Header       *pdpb.ResponseHeader `json:"header"`
+	NewSafePoint uint64               `json:"new_safe_point"`
+}
+
+type updateKeyspaceServiceGCSafePointRequest struct {
+	Header       *pdpb.RequestHeader `json:"header"`
+	KeyspaceName string              `json:"keyspace_name"`
+	ServiceID    string              `json:"service_id"`
+	TTL          int64               `json:"ttl"`
+	SafePoint    uint64              `json:"safe_point"`
+}
+
+type updateKeyspaceServiceGCSafePointResponse struct {
+	Header       *pdpb.ResponseHeader `json:"header"`
+	ServiceID    string               `json:"service_id,omitempty"`
+	MinSafePoint uint64               `json:"min_safe_point"`
+}
+
+type keyspaceScopedClient struct {
+	*client
+	meta *keyspacepb.KeyspaceMeta
+	// prefix is the prefix of the keys in the keyspace.
+	prefix []byte
+	// lower and upper are the encoded region key range [lower, upper) of the
+	// keyspace.
+	lower, upper []byte
+}
+
+// NewKeyspaceScopedClient creates a PD client bound to the keyspace with the
+// name, whose keys are in the mode. The keyspace must be enabled.
+func NewKeyspaceScopedClient(
+	ctx context.Context, pdAddrs []string, security SecurityOption,
+	keyspaceName string, mode KeyMode, opts ...ClientOption,
+) (KeyspaceScopedClient, error) {
+	if mode != KeyModeRaw && mode != KeyModeTxn {
+		return nil, errors.Errorf("[pd] invalid key mode %q, should be %q or %q", mode, KeyModeRaw, KeyModeTxn)
+	}
+	cli, err := NewClientWithContext(ctx, pdAddrs, security, opts...)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := cli.LoadKeyspace(ctx, keyspaceName)
+	if err != nil {
+		cli.Close()
+		return nil, err
+	}
+	if meta.GetState() != keyspacepb.KeyspaceState_ENABLED {
+		cli.Close()
+		return nil, errors.Errorf("[pd] keyspace %s is %s", keyspaceName, meta.GetState())
+	}
+	return newKeyspaceScopedClient(cli.(*client), meta, mode), nil
+}
+
+func newKeyspaceScopedClient(cli *client, meta *keyspacepb.KeyspaceMeta, mode KeyMode) *keyspaceScopedClient {
+	prefix := make([]byte, 4)
+	binary.BigEndian.PutUint32(prefix, meta.GetId())
+	prefix[0] = byte(mode)
+	return &keyspaceScopedClient{
+		client: cli,
+		meta:   meta,
+		prefix: prefix,
+		lower:  codec.EncodeBytes(prefix),
+		upper:  codec.EncodeBytes(prefixNext(prefix)),
+	}
+}
+
+// prefixNext returns the smallest key greater than all the keys with the prefix.
+func prefixNext(prefix []byte) []byte {
+	next := append([]byte{}, prefix...)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			return next
+		}
+	}
+	// All the bytes are 0xFF, there is no greater key with the same length.
+	return append(append([]byte{}, prefix...), 0)
+}
+
+func (c *keyspaceScopedClient) GetKeyspace() *keyspacepb.KeyspaceMeta {
+	return c.meta
+}
+
+func (c *keyspaceScopedClient) GetClient() Client {
+	return c.client
+}
+
+func (c *keyspaceScopedClient) GetTS(ctx context.Context) (int64, int64, error) {
+	return c.client.GetTSWithinKeyspace(ctx, c.meta.GetId())
+}
+
+func (c *keyspaceScopedClient) GetTSAsync(ctx context.Context) TSFuture {
+	return c.client.GetTSWithinKeyspaceAsync(ctx, c.meta.GetId())
+}
+
+func (c *keyspaceScopedClient) GetRegion(ctx context.Context, key []byte, opts ...GetRegionOption) (*Region, error) {
+	region, err := c.client.GetRegion(ctx, c.encodeKey(key), opts...)
+	if err != nil {
+		return nil, err
+	}
+	return c.clipRegion(region)
+}
+
+func (c *keyspaceScopedClient) GetPrevRegion(ctx context.Context, key []byte, opts ...GetRegionOption) (*Region, error) {
+	region, err := c.client.GetPrevRegion(ctx, c.encodeKey(key), opts...)
+	if err != nil {
+		return nil, err
+	}
+	return c.clipRegion(region)
+}
+
+func (c *keyspaceScopedClient) GetRegionByID(ctx context.Context, regionID uint64, opts ...GetRegionOption) (*Region, error) {
+	region, err := c.client.GetRegionByID(ctx, regionID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return c.clipRegion(region)
+}
+
+func (c *keyspaceScopedClient) ScanRegions(ctx context.Context, key, endKey []byte, limit int) ([]*Region, error) {
+	encodedEndKey := c.upper
+	if len(endKey) > 0 {
+		encodedEndKey = c.encodeKey(endKey)
+	}
+	regions, err := c.client.ScanRegions(ctx, c.encodeKey(key), encodedEndKey, limit)
+	if err != nil {
+		return nil, err
+	}
+	clipped := make([]*Region, 0, len(regions))
+	for _, region := range regions {
+		region, err := c.clipRegion(region)
+		if err != nil {
+			return nil, err
+		}
+		if region != nil {
+			clipped = append(clipped, region)
+		}
+	}
+	return clipped, nil
+}
+
+func (c *keyspaceScopedClient) UpdateGCSafePoint(ctx context.Context, safePoint uint64) (uint64, error) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("keyspaceScopedClient.UpdateGCSafePoint", opentracing.ChildOf(span.Context()))
+		defer span.Finish()
+	}
+	req := &updateKeyspaceGCSafePointRequest{
+		Header:       c.requestHeader(),
+		KeyspaceName: c.meta.GetName(),
+		SafePoint:    safePoint,
+	}
+	resp := &updateKeyspaceGCSafePointResponse{}
+	if err := c.invokeJSON(ctx, keyspaceGCServiceName, "UpdateGCSafePoint", req, func() *pdpb.ResponseHeader { return resp.Header }, resp,
+		cmdDurationUpdateKeyspaceGCSafePoint, cmdFailedDurationUpdateKeyspaceGCSafePoint); err != nil {
+		return 0, err
+	}
+	return resp.NewSafePoint, nil
+}
+
+func (c *keyspaceScopedClient) UpdateServiceGCSafePoint(ctx context.Context, serviceID string, ttl int64, safePoint uint64) (uint64, error) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("keyspaceScopedClient.UpdateServiceGCSafePoint", opentracing.ChildOf(span.Context()))
+		defer span.Finish()
+	}
+	req := &updateKeyspaceServiceGCSafePointRequest{
+		Header:       c.requestHeader(),
+		KeyspaceName: c.meta.GetName(),
+		ServiceID:    serviceID,
+		TTL:          ttl,
+		SafePoint:    safePoint,
+	}
+	resp := &updateKeyspaceServiceGCSafePointResponse{}
+	if err := c.invokeJSON(ctx, keyspaceGCServiceName, "UpdateServiceGCSafePoint", req, func() *pdpb.ResponseHeader { return resp.Header }, resp,
+		cmdDurationUpdateKeyspaceServiceGCSafePoint, cmdFailedDurationUpdateKeyspaceServiceGCSafePoint); err != nil {
+		return 0, err
+	}
+	return resp.MinSafePoint, nil
+}
+
+// encodeKey returns the region key of the key in the keyspace.
+func (c *keyspaceScopedClient) encodeKey(key []byte) []byte {
+	return codec.EncodeBytes(append(append(make([]byte, 0, len(c.prefix)+len(key)), c.prefix...), key...))
+}
+
+// decodeKey returns the key in the keyspace of the region key, which is empty
+// if the region key is out of the keyspace, i.e. the start or the end of it.
+func (c *keyspaceScopedClient) decodeKey(key []byte) ([]byte, error) {
+	if len(key) == 0 || bytes.Compare(key, c.lower) <= 0 || bytes.Compare(key, c.upper) >= 0 {
+		return []byte{}, nil
+	}
+	rest, decoded, err := codec.DecodeBytes(key)
+	if err != nil {
+		return nil, errors.Annotatef(err, "[pd] invalid region key %x", key)
+	}
+	if len(rest) > 0 || !bytes.HasPrefix(decoded, c.prefix) {
+		return nil, errors.Errorf("[pd] region key %x is not in keyspace %d", key, c.meta.GetId())
+	}
+	return decoded[len(c.prefix):], nil
+}
+
+// clipRegion returns the copy of the region with the keys in the keyspace, or
+// nil if the region doesn't overlap with the keyspace.
+func (c *keyspaceScopedClient) clipRegion(region *Region) (*Region, error) {
+	if region == nil || region.Meta == nil {
+		return nil, nil
+	}
+	startKey, endKey := region.Meta.GetStartKey(), region.Meta.GetEndKey()
+	if (len(endKey) > 0 && bytes.Compare(endKey, c.lower) <= 0) || bytes.Compare(startKey, c.upper) >= 0 {
+		return nil, nil
+	}
+	meta := proto.Clone(region.Meta).(*metapb.Region)
+	var err error
+	if meta.StartKey, err = c.decodeKey(startKey); err != nil {
+		return nil, err
+	}
+	if meta.EndKey, err = c.decodeKey(endKey); err != nil {
+		return nil, err
+	}
+	clipped := *region
+	clipped.Meta = meta
+	if region.Buckets != nil {
+		buckets := proto.Clone(region.Buckets).(*metapb.Buckets)
+		for i, key := range buckets.Keys {
+			if buckets.Keys[i], err = c.decodeKey(key); err != nil {
+				return nil, err
+			}
+		}
+		clipped.Buckets = buckets
+	}
+	return &clipped, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"testing"
+
+	"github.com/pingcap/kvproto/pkg/keyspacepb"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/client/codec"
+)
+
+func TestPrefixNext(t *testing.T) {
+	re := require.New(t)
+	re.Equal([]byte{'x', 0, 0, 2}, prefixNext([]byte{'x', 0, 0, 1}))
+	re.Equal([]byte{'x', 0, 1, 0}, prefixNext([]byte{'x', 0, 0, 0xFF}))
+	re.Equal([]byte{'y', 0, 0, 0}, prefixNext([]byte{'x', 0xFF, 0xFF, 0xFF}))
+	re.Equal([]byte{0xFF, 0xFF, 0}, prefixNext([]byte{0xFF, 0xFF}))
+}
+
+func TestKeyspaceScopedRegion(t *testing.T) {
+	re := require.New(t)
+	c := newKeyspaceScopedClient(nil, &keyspacepb.KeyspaceMeta{Id: 258, Name: "ks"}, KeyModeTxn)
+	re.Equal(codec.EncodeBytes([]byte{'x', 0, 1, 2, 'a'}), c.encodeKey([]byte("a")))
+
+	region := func(start, end []byte) *Region {
+		return &Region{Meta: &metapb.Region{Id: 1, StartKey: start, EndKey: end}}
+	}
+	key := func(raw string) []byte { return c.encodeKey([]byte(raw)) }
+	prevKeyspace := codec.EncodeBytes([]byte{'x', 0, 1, 1, 'a'})
+	nextKeyspace := codec.EncodeBytes([]byte{'x', 0, 1, 3})
+
+	testCases := []struct {
+		region   *Region
+		overlap  bool
+		startKey string
+		endKey   string
+	}{
+		{region(key("a"), key("b")), true, "a", "b"},
+		{region(nil, nil), true, "", ""},
+		{region(c.lower, c.upper), true, "", ""},
+		{region(prevKeyspace, key("b")), true, "", "b"},
+		{region(key("b"), nextKeyspace), true, "b", ""},
+		{region(prevKeyspace, c.lower), false, "", ""},
+		{region(c.upper, nil), false, "", ""},
+	}
+	for _, tc := range testCases {
+		clipped, err := c.clipRegion(tc.region)
+		re.NoError(err)
+		if !tc.overlap {
+			re.Nil(clipped)
+			continue
+		}
+		re.Equal(tc.startKey, string(clipped.Meta.GetStartKey()))
+		re.Equal(tc.endKey, string(clipped.Meta.GetEndKey()))
+		re.Equal(uint64(1), clipped.Meta.GetId())
+	}
+	// The original region is not changed.
+	r := region(key("a"), key("b"))
+	_, err := c.clipRegion(r)
+	re.NoError(err)
+	re.Equal(key("a"), r.Meta.GetStartKey())
+
+	r = region(key("a"), key("c"))
+	r.Buckets = &metapb.Buckets{Keys: [][]byte{key("a"), key("b"), key("c")}}
+	clipped, err := c.clipRegion(r)
+	re.NoError(err)
+	re.Equal([][]byte{[]byte("a"), []byte("b"), []byte("c")}, clipped.Buckets.GetKeys())
+
+	// The keys not encoded as the region keys are invalid.
+	_, err = c.clipRegion(region(append(append([]byte{}, c.lower...), 'a'), nil))
+	re.Error(err)
+	clipped, err = c.clipRegion(nil)
+	re.NoError(err)
+	re.Nil(clipped)
+}
@@ -78,6 +78,30 @@ var (
 			Name:      "forwarded_status",
 			Help:      "The status to indicate if the request is forwarded",
 		}, []string{"host", "delegate"})
+
+	requestFollowerRead = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd_client",
+			Subsystem: "request",
+			Name:      "follower_read_total",
+			Help:      "Counter of the read-only requests sent to the followers, by whether they are served or fall back to the leader.",
+		}, []string{"result"})
+
+	circuitBreakerTrips = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd_client",
+			Subsystem: "request",
+			Name:      "circuit_breaker_trips_total",
+			Help:      "Counter of the circuit breakers opened for the endpoints failing too many times in a row.",
+		}, []string{"host"})
+
+	hedgedRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd_client",
+			Subsystem: "request",
+			Name:      "hedged_requests_total",
+			Help:      "Counter of the hedged requests, by whether they are sent or win the original ones.",
+		}, []string{"result"})
 )
 
 var (
@@ -101,6 +125,7 @@ var (
 	cmdDurationSplitAndScatterRegions   = cmdDuration.WithLabelValues("split_and_scatter_regions")
 	cmdDurationLoadKeyspace             = cmdDuration.WithLabelValues("load_keyspace")
 	cmdDurationUpdateKeyspaceState      = cmdDuration.WithLabelValues("update_keyspace_state")
+	cmdDurationRule                     = cmdDuration.WithLabelValues("rule")
 
 	cmdFailDurationGetRegion                  = cmdFailedDuration.WithLabelValues("get_region")
 	cmdFailDurationTSO                        = cmdFailedDuration.WithLabelValues("tso")
@@ -114,7 +139,24 @@ var (
 	cmdFailedDurationUpdateServiceGCSafePoint = cmdFailedDuration.WithLabelValues("update_service_gc_safe_point")
 	cmdFailedDurationLoadKeyspace             = cmdDuration.WithLabelValues("load_keyspace")
 	cmdFailedDurationUpdateKeyspaceState      = cmdDuration.WithLabelValues("update_keyspace_state")
+	cmdFailedDurationRule                     = cmdFailedDuration.WithLabelValues("rule")
 	requestDurationTSO                        = requestDuration.WithLabelValues("tso")
+
+	cmdDurationUpdateKeyspaceGCSafePoint              = cmdDuration.WithLabelValues("update_keyspace_gc_safe_point")
+	cmdDurationUpdateKeyspaceServiceGCSafePoint       = cmdDuration.WithLabelValues("update_keyspace_service_gc_safe_point")
+	cmdFailedDurationUpdateKeyspaceGCSafePoint        = cmdFailedDuration.WithLabelValues("update_keyspace_gc_safe_point")
+	cmdFailedDurationUpdateKeyspaceServiceGCSafePoint = cmdFailedDuration.WithLabelValues("update_keyspace_service_gc_safe_point")
+
+	cmdDurationBatchGetRegionsByID       = cmdDuration.WithLabelValues("batch_get_regions_by_id")
+	cmdDurationBatchGetStores            = cmdDuration.WithLabelValues("batch_get_stores")
+	cmdFailedDurationBatchGetRegionsByID = cmdFailedDuration.WithLabelValues("batch_get_regions_by_id")
+	cmdFailedDurationBatchGetStores      = cmdFailedDuration.WithLabelValues("batch_get_stores")
+
+	requestFollowerReadServed   = requestFollowerRead.WithLabelValues("served")
+	requestFollowerReadFallback = requestFollowerRead.WithLabelValues("fallback")
+
+	hedgedRequestsSent = hedgedRequests.WithLabelValues("sent")
+	hedgedRequestsWon  = hedgedRequests.WithLabelValues("won")
 )
 
 func init() {
@@ -125,4 +167,7 @@ func init() {
 	prometheus.MustRegister(tsoBatchSize)
 	prometheus.MustRegister(tsoBatchSendLatency)
 	prometheus.MustRegister(requestForwarded)
+	prometheus.MustRegister(requestFollowerRead)
+	prometheus.MustRegister(circuitBreakerTrips)
+	prometheus.MustRegister(hedgedRequests)
 }
@@ -23,10 +23,11 @@ import (
 )
 
 const (
-	defaultPDTimeout                             = 3 * time.Second
-	maxInitClusterRetries                        = 100
-	defaultMaxTSOBatchWaitInterval time.Duration = 0
-	defaultEnableTSOFollowerProxy                = false
+	defaultPDTimeout                               = 3 * time.Second
+	maxInitClusterRetries                          = 100
+	defaultMaxTSOBatchWaitInterval   time.Duration = 0
+	defaultEnableTSOFollowerProxy                  = false
+	defaultEnableTSOAdaptiveBatching               = true
 )
 
 // DynamicOption is used to distinguish the dynamic option type.
@@ -39,6 +40,18 @@ const (
 	// EnableTSOFollowerProxy is the TSO Follower Proxy option.
 	// It is stored as bool.
 	EnableTSOFollowerProxy
+	// MaxTSOBatchSize is the max number of the TSO requests in a batch.
+	// It is stored as int and should be between 1 and 10000.
+	MaxTSOBatchSize
+	// EnableTSOAdaptiveBatching is the option to adjust the target TSO batch
+	// size with the load. If it's disabled, the client waits for the max batch
+	// size or the max batch wait interval, which trades the tail latency for
+	// the throughput explicitly. It is stored as bool.
+	EnableTSOAdaptiveBatching
+	// MaxFollowerReadStaleness is the max staleness of the data accepted by the
+	// read-only requests, with which the requests are sent to the PD followers
+	// first. 0 means the follower read is disabled. It is stored as time.Duration.
+	MaxFollowerReadStaleness
 
 	dynamicOptionCount
 )
@@ -51,6 +64,21 @@ type option struct {
 	timeout          time.Duration
 	maxRetryTimes    int
 	enableForwarding bool
+	// circuitBreakerThreshold is the number of the failures in a row to stop
+	// sending the idempotent requests to an endpoint, 0 means disabled.
+	circuitBreakerThreshold int
+	circuitBreakerCooldown  time.Duration
+	// hedgingDelay is the delay to send a hedged idempotent request to another
+	// endpoint if there is no response yet, 0 means disabled.
+	hedgingDelay time.Duration
+	// instrumentation is the hooks of the telemetry, nil means disabled.
+	instrumentation Instrumentation
+	// retryPolicy is the policy to retry the failed requests, nil means the
+	// default one of each kind of the requests.
+	retryPolicy *RetryPolicy
+	// discoveryCachePath is the file persisting the membership, empty means
+	// disabled.
+	discoveryCachePath string
 
 	// Dynamic options.
 	dynamicOptions [dynamicOptionCount]atomic.Value
@@ -68,6 +96,9 @@ func newOption() *option {
 
 	co.dynamicOptions[MaxTSOBatchWaitInterval].Store(defaultMaxTSOBatchWaitInterval)
 	co.dynamicOptions[EnableTSOFollowerProxy].Store(defaultEnableTSOFollowerProxy)
+	co.dynamicOptions[MaxTSOBatchSize].Store(defaultMaxTSOBatchSize)
+	co.dynamicOptions[EnableTSOAdaptiveBatching].Store(defaultEnableTSOAdaptiveBatching)
+	co.dynamicOptions[MaxFollowerReadStaleness].Store(time.Duration(0))
 	return co
 }
 
@@ -105,3 +136,42 @@ func (o *option) setEnableTSOFollowerProxy(enable bool) {
 func (o *option) getEnableTSOFollowerProxy() bool {
 	return o.dynamicOptions[EnableTSOFollowerProxy].Load().(bool)
 }
+
+// setMaxTSOBatchSize sets the max TSO batch size option.
+// It only accepts the size between 1 and 10000.
+func (o *option) setMaxTSOBatchSize(size int) error {
+	if size < 1 || size > defaultMaxTSOBatchSize {
+		return errors.Errorf("[pd] invalid max TSO batch size, should be between 1 and %d", defaultMaxTSOBatchSize)
+	}
+	o.dynamicOptions[MaxTSOBatchSize].Store(size)
+	return nil
+}
+
+// getMaxTSOBatchSize gets the max TSO batch size option.
+func (o *option) getMaxTSOBatchSize() int {
+	return o.dynamicOptions[MaxTSOBatchSize].Load().(int)
+}
+
+// setEnableTSOAdaptiveBatching sets the TSO adaptive batching option.
+func (o *option) setEnableTSOAdaptiveBatching(enable bool) {
+	o.dynamicOptions[EnableTSOAdaptiveBatching].Store(enable)
+}
+
+// getEnableTSOAdaptiveBatching gets the TSO adaptive batching option.
+func (o *option) getEnableTSOAdaptiveBatching() bool {
+	return o.dynamicOptions[EnableTSOAdaptiveBatching].Load().(bool)
+}
+
+// setMaxFollowerReadStaleness sets the max follower read staleness option.
+func (o *option) setMaxFollowerReadStaleness(maxStaleness time.Duration) error {
+	if maxStaleness < 0 {
+		return errors.New("[pd] invalid max follower read staleness, should not be negative")
+	}
+	o.dynamicOptions[MaxFollowerReadStaleness].Store(maxStaleness)
+	return nil
+}
+
+// getMaxFollowerReadStaleness gets the max follower read staleness option.
+func (o *option) getMaxFollowerReadStaleness() time.Duration {
+	return o.dynamicOptions[MaxFollowerReadStaleness].Load().(time.Duration)
+}
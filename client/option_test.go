@@ -55,4 +55,20 @@ func TestDynamicOptionChange(t *testing.T) {
 	close(o.enableTSOFollowerProxyCh)
 	// Setting the same value should not notify the channel.
 	o.setEnableTSOFollowerProxy(expectBool)
+
+	re.Equal(defaultMaxTSOBatchSize, o.getMaxTSOBatchSize())
+	re.NotNil(o.setMaxTSOBatchSize(0))
+	re.NotNil(o.setMaxTSOBatchSize(defaultMaxTSOBatchSize + 1))
+	re.Equal(defaultMaxTSOBatchSize, o.getMaxTSOBatchSize())
+	re.NoError(o.setMaxTSOBatchSize(1))
+	re.Equal(1, o.getMaxTSOBatchSize())
+
+	re.Equal(defaultEnableTSOAdaptiveBatching, o.getEnableTSOAdaptiveBatching())
+	o.setEnableTSOAdaptiveBatching(false)
+	re.False(o.getEnableTSOAdaptiveBatching())
+
+	re.Zero(o.getMaxFollowerReadStaleness())
+	re.NotNil(o.setMaxFollowerReadStaleness(-time.Second))
+	re.NoError(o.setMaxFollowerReadStaleness(time.Minute))
+	re.Equal(time.Minute, o.getMaxFollowerReadStaleness())
 }
@@ -0,0 +1,185 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/tikv/pd/client/grpcutil"
+	"google.golang.org/grpc"
+)
+
+// regionServiceName is the name of the PD gRPC service scanning the regions in
+// a stream, whose messages are encoded in JSON.
+const regionServiceName = "pd.Region"
+
+// RegionStream receives the regions of a scan in batches.
+type RegionStream interface {
+	// Recv returns the next batch of the regions, or io.EOF after the last
+	// one.
+	Recv() ([]*Region, error)
+	// Close stops the scan. It must be called if the stream is not received
+	// until an error is returned.
+	Close()
+}
+
+type scanRegionsStreamRequest struct {
+	Header    *pdpb.RequestHeader `json:"header"`
+	StartKey  []byte              `json:"start_key,omitempty"`
+	EndKey    []byte              `json:"end_key,omitempty"`
+	Limit     int                 `json:"limit,omitempty"`
+	BatchSize int                 `json:"batch_size,omitempty"`
+}
+
+type scanRegionsStreamResponse struct {
+	Header  *pdpb.ResponseHeader `json:"header"`
+	Regions []*pdpb.Region       `json:"regions,omitempty"`
+}
+
+func regionMethod(name string) string {
+	return "/" + regionServiceName + "/" + name
+}
+
+// ScanRegionsStream is like ScanRegions, but receives the regions in batches
+// of at most batchSize regions, 0 means the default size of PD. The next
+// batch is not sent by PD until the previous one is received, so neither end
+// holds all the regions. If the stream is broken, e.g. the PD leader changes,
+// the scan is resumed from the end key of the last received region according
+// to the retry policy.
+func (c *client) ScanRegionsStream(ctx context.Context, key, endKey []byte, limit, batchSize int) (RegionStream, error) {
+	if limit < 0 || batchSize < 0 {
+		return nil, errors.New("limit and batch size must not be negative")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s := &regionStream{
+		c:      c,
+		ctx:    ctx,
+		cancel: cancel,
+		policy: c.option.getRetryPolicy(ctx, defaultRetryPolicy),
+		req:    &scanRegionsStreamRequest{StartKey: key, EndKey: endKey, Limit: limit, BatchSize: batchSize},
+	}
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		s.span = opentracing.StartSpan("pdclient.ScanRegionsStream", opentracing.ChildOf(span.Context()))
+	}
+	return s, nil
+}
+
+type regionStream struct {
+	c      *client
+	ctx    context.Context
+	cancel context.CancelFunc
+	span   opentracing.Span
+	policy RetryPolicy
+	// req is the request resuming the scan after the received regions.
+	req    *scanRegionsStreamRequest
+	stream grpc.ClientStream
+	done   bool
+}
+
+// Recv implements RegionStream.
+func (s *regionStream) Recv() ([]*Region, error) {
+	if s.done {
+		return nil, io.EOF
+	}
+	resp := &scanRegionsStreamResponse{}
+	var eof bool
+	err := s.c.option.retry(s.ctx, regionMethod("ScanRegions"), s.policy, isRetryableRuleError, func() error {
+		err := s.recvOnce(resp)
+		if err == io.EOF {
+			eof = true
+			return nil
+		}
+		if err != nil {
+			s.stream = nil
+			if isRetryableRuleError(err) {
+				s.c.ScheduleCheckLeader()
+			}
+		}
+		return err
+	})
+	if err == nil && resp.Header.GetError() != nil {
+		err = errors.Errorf("ScanRegions failed: %s", resp.Header.GetError().String())
+	}
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	if eof {
+		s.Close()
+		return nil, io.EOF
+	}
+	regions := handleRegionsResponse(&pdpb.ScanRegionsResponse{Regions: resp.Regions})
+	s.advance(regions)
+	return regions, nil
+}
+
+func (s *regionStream) recvOnce(resp *scanRegionsStreamResponse) error {
+	if s.stream == nil {
+		cc, err := s.c.getOrCreateGRPCConn(s.c.GetLeaderAddr())
+		if err != nil {
+			return err
+		}
+		desc := &grpc.StreamDesc{StreamName: "ScanRegions", ServerStreams: true}
+		stream, err := cc.NewStream(s.ctx, desc, regionMethod("ScanRegions"), grpc.CallContentSubtype(grpcutil.JSONCodecName))
+		if err != nil {
+			return err
+		}
+		s.req.Header = s.c.requestHeader()
+		if err := stream.SendMsg(s.req); err != nil {
+			return err
+		}
+		if err := stream.CloseSend(); err != nil {
+			return err
+		}
+		s.stream = stream
+	}
+	return s.stream.RecvMsg(resp)
+}
+
+// advance moves the start key of the request to the end of the received
+// regions, so the scan is resumed after them.
+func (s *regionStream) advance(regions []*Region) {
+	if len(regions) == 0 {
+		return
+	}
+	if s.req.Limit > 0 {
+		s.req.Limit -= len(regions)
+		if s.req.Limit <= 0 {
+			s.done = true
+		}
+	}
+	s.req.StartKey = regions[len(regions)-1].Meta.GetEndKey()
+	if len(s.req.StartKey) == 0 || (len(s.req.EndKey) > 0 && bytes.Compare(s.req.StartKey, s.req.EndKey) >= 0) {
+		s.done = true
+	}
+	if s.done {
+		s.Close()
+	}
+}
+
+// Close implements RegionStream.
+func (s *regionStream) Close() {
+	s.done = true
+	s.cancel()
+	if s.span != nil {
+		s.span.Finish()
+		s.span = nil
+	}
+}
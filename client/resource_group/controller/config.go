@@ -92,6 +92,14 @@ func DefaultRequestUnitConfig() *RequestUnitConfig {
 	}
 }
 
+// RUModel is the versioned RU model managed by the resource manager, which is
+// pushed to the clients with the same keys as RequestUnitConfig.
+type RUModel struct {
+	// Version is increased each time the model is updated on the server.
+	Version uint64 `json:"version"`
+	RequestUnitConfig
+}
+
 // Config is the configuration of the resource units, which gives the read/write request
 // units or request resource cost standards. It should be calculated by a given `RequestUnitConfig`
 // or `RequestResourceConfig`.
@@ -51,14 +51,28 @@ type ResourceGroupProvider interface {
 	AcquireTokenBuckets(ctx context.Context, request *rmpb.TokenBucketsRequest) ([]*rmpb.TokenBucketResponse, error)
 }
 
-var _ ResourceGroupKVInterceptor = (*ResourceGroupsController)(nil)
+// ResourceGroupRUInterceptor is used as quota limit controller for resource group
+// by the consumers calculating the RUs by themselves.
+type ResourceGroupRUInterceptor interface {
+	// AcquireRU waits until the resource group has enough tokens of the RUs, and consumes them.
+	AcquireRU(ctx context.Context, resourceGroupName string, readRU, writeRU RequestUnit) error
+	// ReportRU consumes the tokens of the RUs without waiting.
+	ReportRU(ctx context.Context, resourceGroupName string, readRU, writeRU RequestUnit) error
+}
+
+var (
+	_ ResourceGroupKVInterceptor = (*ResourceGroupsController)(nil)
+	_ ResourceGroupRUInterceptor = (*ResourceGroupsController)(nil)
+)
 
-// ResourceGroupsController impls ResourceGroupKVInterceptor.
+// ResourceGroupsController impls ResourceGroupKVInterceptor and ResourceGroupRUInterceptor.
 type ResourceGroupsController struct {
 	clientUniqueID   uint64
 	provider         ResourceGroupProvider
 	groupsController sync.Map
-	config           *Config
+	// config may be updated on the fly by UpdateRUModel.
+	config         atomic.Pointer[Config]
+	ruModelVersion atomic.Uint64
 
 	loopCtx    context.Context
 	loopCancel func()
@@ -97,15 +111,33 @@ func NewResourceGroupController(clientUniqueID uint64, provider ResourceGroupPro
 	} else {
 		config = DefaultConfig()
 	}
-	return &ResourceGroupsController{
+	c := &ResourceGroupsController{
 		clientUniqueID:        clientUniqueID,
 		provider:              provider,
-		config:                config,
 		lowTokenNotifyChan:    make(chan struct{}, 1),
 		tokenResponseChan:     make(chan []*rmpb.TokenBucketResponse, 1),
 		tokenBucketUpdateChan: make(chan *groupCostController, maxNotificationChanLen),
-		calculators:           []ResourceCalculator{newKVCalculator(config), newSQLCalculator(config)},
-	}, nil
+	}
+	c.config.Store(config)
+	c.calculators = []ResourceCalculator{newKVCalculator(&c.config), newSQLCalculator(&c.config)}
+	return c, nil
+}
+
+// UpdateRUModel applies the RU model, e.g. the one pushed by the resource
+// manager, to calculate the RU consumption of the following requests. The
+// models older than the applied one are ignored.
+func (c *ResourceGroupsController) UpdateRUModel(model *RUModel) {
+	for {
+		version := c.ruModelVersion.Load()
+		if model.Version < version {
+			return
+		}
+		if c.ruModelVersion.CompareAndSwap(version, model.Version) {
+			break
+		}
+	}
+	c.config.Store(generateConfig(&model.RequestUnitConfig))
+	log.Info("[resource group controller] update RU model", zap.Uint64("version", model.Version))
 }
 
 // Start starts ResourceGroupController service.
@@ -183,7 +215,7 @@ func (c *ResourceGroupsController) tryGetResourceGroup(ctx context.Context, name
 		return gc, nil
 	}
 	// Initialize the resource group controller.
-	gc, err := newGroupCostController(group, c.config, c.lowTokenNotifyChan, c.tokenBucketUpdateChan)
+	gc, err := newGroupCostController(group, &c.config, c.lowTokenNotifyChan, c.tokenBucketUpdateChan)
 	if err != nil {
 		return nil, err
 	}
@@ -333,9 +365,40 @@ func (c *ResourceGroupsController) OnResponse(_ context.Context, resourceGroupNa
 	return nil
 }
 
+// AcquireRU waits until the local token bucket of the resource group has the
+// read and write RUs, and consumes them. It's for the consumers calculating the
+// RUs by themselves instead of by the KV requests, e.g. the custom TiKV
+// clients. The controller must be started, which refills the token buckets
+// from the resource manager and reports the consumption in the background.
+func (c *ResourceGroupsController) AcquireRU(ctx context.Context, resourceGroupName string, readRU, writeRU RequestUnit) error {
+	if readRU < 0 || writeRU < 0 {
+		return errors.Errorf("the RUs must not be negative")
+	}
+	gc, err := c.tryGetResourceGroup(ctx, resourceGroupName)
+	if err != nil {
+		return errors.Errorf("failed to get the resource group %s", resourceGroupName)
+	}
+	return gc.acquireTokens(ctx, &rmpb.Consumption{RRU: float64(readRU), WRU: float64(writeRU)})
+}
+
+// ReportRU consumes the read and write RUs of the resource group without
+// waiting, e.g. the ones only known after a request is done. The local token
+// bucket may go into debt, which makes the following AcquireRU wait longer.
+func (c *ResourceGroupsController) ReportRU(ctx context.Context, resourceGroupName string, readRU, writeRU RequestUnit) error {
+	if readRU < 0 || writeRU < 0 {
+		return errors.Errorf("the RUs must not be negative")
+	}
+	gc, err := c.tryGetResourceGroup(ctx, resourceGroupName)
+	if err != nil {
+		return errors.Errorf("failed to get the resource group %s", resourceGroupName)
+	}
+	gc.removeTokens(&rmpb.Consumption{RRU: float64(readRU), WRU: float64(writeRU)})
+	return nil
+}
+
 type groupCostController struct {
 	*rmpb.ResourceGroup
-	mainCfg     *Config
+	mainCfg     *atomic.Pointer[Config]
 	calculators []ResourceCalculator
 	mode        rmpb.GroupMode
 
@@ -402,7 +465,7 @@ type tokenCounter struct {
 
 func newGroupCostController(
 	group *rmpb.ResourceGroup,
-	mainCfg *Config,
+	mainCfg *atomic.Pointer[Config],
 	lowRUNotifyChan chan struct{},
 	tokenBucketUpdateChan chan *groupCostController,
 ) (*groupCostController, error) {
@@ -803,6 +866,12 @@ func (gc *groupCostController) onRequestWait(
 	for _, calc := range gc.calculators {
 		calc.BeforeKVRequest(delta, info)
 	}
+	return gc.acquireTokens(ctx, delta)
+}
+
+// acquireTokens waits until the token buckets have the tokens of the
+// consumption, and consumes them.
+func (gc *groupCostController) acquireTokens(ctx context.Context, delta *rmpb.Consumption) (err error) {
 	now := time.Now()
 	if gc.burstable.Load() {
 		goto ret
@@ -849,6 +918,12 @@ func (gc *groupCostController) onResponse(req RequestInfo, resp ResponseInfo) {
 	for _, calc := range gc.calculators {
 		calc.AfterKVRequest(delta, req, resp)
 	}
+	gc.removeTokens(delta)
+}
+
+// removeTokens consumes the tokens of the consumption without waiting, which
+// may make the token buckets in debt.
+func (gc *groupCostController) removeTokens(delta *rmpb.Consumption) {
 	if gc.burstable.Load() {
 		goto ret
 	}
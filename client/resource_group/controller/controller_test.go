@@ -19,6 +19,8 @@
 package controller
 
 import (
+	"context"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -41,7 +43,9 @@ func TestGroupControlBurstable(t *testing.T) {
 	}
 	ch1 := make(chan struct{})
 	ch2 := make(chan *groupCostController)
-	gc, err := newGroupCostController(group, DefaultConfig(), ch1, ch2)
+	cfg := &atomic.Pointer[Config]{}
+	cfg.Store(DefaultConfig())
+	gc, err := newGroupCostController(group, cfg, ch1, ch2)
 	re.NoError(err)
 	gc.initRunState()
 	args := tokenBucketReconfigureArgs{
@@ -54,3 +58,73 @@ func TestGroupControlBurstable(t *testing.T) {
 	gc.updateAvgRequestResourcePerSec()
 	re.Equal(gc.burstable.Load(), true)
 }
+
+type testRequestInfo struct {
+	writeBytes uint64
+}
+
+func (r *testRequestInfo) IsWrite() bool {
+	return r.writeBytes > 0
+}
+
+func (r *testRequestInfo) WriteBytes() uint64 {
+	return r.writeBytes
+}
+
+func TestUpdateRUModel(t *testing.T) {
+	re := require.New(t)
+	c, err := NewResourceGroupController(1, nil, nil)
+	re.NoError(err)
+	req := &testRequestInfo{writeBytes: 1024}
+	consumption := &rmpb.Consumption{}
+	c.calculators[0].BeforeKVRequest(consumption, req)
+	re.Equal(defaultWriteBaseCost+1, consumption.WRU)
+
+	model := &RUModel{Version: 2, RequestUnitConfig: *DefaultRequestUnitConfig()}
+	model.WriteBaseCost = 3
+	c.UpdateRUModel(model)
+	consumption = &rmpb.Consumption{}
+	c.calculators[0].BeforeKVRequest(consumption, req)
+	re.Equal(4., consumption.WRU)
+
+	// The older models are ignored.
+	c.UpdateRUModel(&RUModel{Version: 1, RequestUnitConfig: *DefaultRequestUnitConfig()})
+	consumption = &rmpb.Consumption{}
+	c.calculators[0].BeforeKVRequest(consumption, req)
+	re.Equal(4., consumption.WRU)
+}
+
+func TestAcquireAndRemoveTokens(t *testing.T) {
+	re := require.New(t)
+	group := &rmpb.ResourceGroup{
+		Name: "test",
+		Mode: rmpb.GroupMode_RUMode,
+		RUSettings: &rmpb.GroupRequestUnitSettings{
+			RU: &rmpb.TokenBucket{
+				Settings: &rmpb.TokenLimitSettings{
+					FillRate: 1000,
+				},
+			},
+		},
+	}
+	cfg := &atomic.Pointer[Config]{}
+	cfg.Store(DefaultConfig())
+	gc, err := newGroupCostController(group, cfg, make(chan struct{}, 1), make(chan *groupCostController, 1))
+	re.NoError(err)
+	gc.initRunState()
+	limiter := gc.run.requestUnitTokens[rmpb.RequestUnitType_RU].limiter
+
+	re.NoError(gc.acquireTokens(context.Background(), &rmpb.Consumption{RRU: 100, WRU: 50}))
+	re.Equal(100., gc.mu.consumption.RRU)
+	re.Equal(50., gc.mu.consumption.WRU)
+	re.LessOrEqual(limiter.AvailableTokens(time.Now()), float64(initialRequestUnits-150))
+
+	// The tokens can be removed into debt, then the acquisitions wait.
+	gc.removeTokens(&rmpb.Consumption{WRU: 2 * initialRequestUnits})
+	re.Equal(50.+2*initialRequestUnits, gc.mu.consumption.WRU)
+	re.Less(limiter.AvailableTokens(time.Now()), 0.)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	re.Error(gc.acquireTokens(ctx, &rmpb.Consumption{RRU: 1}))
+	re.Equal(100., gc.mu.consumption.RRU)
+}
@@ -16,6 +16,7 @@ package controller
 
 import (
 	"context"
+	"sync/atomic"
 
 	rmpb "github.com/pingcap/kvproto/pkg/resource_manager"
 )
@@ -57,13 +58,14 @@ type ResourceCalculator interface {
 
 // KVCalculator is used to calculate the KV-side consumption.
 type KVCalculator struct {
-	*Config
+	// config may be updated on the fly, e.g. by the RU model of the server.
+	config *atomic.Pointer[Config]
 }
 
 var _ ResourceCalculator = (*KVCalculator)(nil)
 
-func newKVCalculator(cfg *Config) *KVCalculator {
-	return &KVCalculator{Config: cfg}
+func newKVCalculator(cfg *atomic.Pointer[Config]) *KVCalculator {
+	return &KVCalculator{config: cfg}
 }
 
 // Trickle ...
@@ -80,14 +82,15 @@ func (kc *KVCalculator) BeforeKVRequest(consumption *rmpb.Consumption, req Reque
 		consumption.KvReadRpcCount += 1
 		// Read bytes could not be known before the request is executed,
 		// so we only add the base cost here.
-		consumption.RRU += float64(kc.ReadBaseCost)
+		consumption.RRU += float64(kc.config.Load().ReadBaseCost)
 	}
 }
 
 func (kc *KVCalculator) calculateWriteCost(consumption *rmpb.Consumption, req RequestInfo) {
+	cfg := kc.config.Load()
 	writeBytes := float64(req.WriteBytes())
 	consumption.WriteBytes += writeBytes
-	consumption.WRU += float64(kc.WriteBaseCost) + float64(kc.WriteBytesCost)*writeBytes
+	consumption.WRU += float64(cfg.WriteBaseCost) + float64(cfg.WriteBytesCost)*writeBytes
 }
 
 // AfterKVRequest ...
@@ -107,30 +110,31 @@ func (kc *KVCalculator) AfterKVRequest(consumption *rmpb.Consumption, req Reques
 func (kc *KVCalculator) calculateReadCost(consumption *rmpb.Consumption, res ResponseInfo) {
 	readBytes := float64(res.ReadBytes())
 	consumption.ReadBytes += readBytes
-	consumption.RRU += float64(kc.ReadBytesCost) * readBytes
+	consumption.RRU += float64(kc.config.Load().ReadBytesCost) * readBytes
 }
 
 func (kc *KVCalculator) calculateCPUCost(consumption *rmpb.Consumption, res ResponseInfo) {
 	kvCPUMs := float64(res.KVCPUMs())
 	consumption.TotalCpuTimeMs += kvCPUMs
-	consumption.RRU += float64(kc.CPUMsCost) * kvCPUMs
+	consumption.RRU += float64(kc.config.Load().CPUMsCost) * kvCPUMs
 }
 
 func (kc *KVCalculator) payBackWriteCost(consumption *rmpb.Consumption, req RequestInfo) {
+	cfg := kc.config.Load()
 	writeBytes := float64(req.WriteBytes())
 	consumption.WriteBytes -= writeBytes
-	consumption.WRU -= float64(kc.WriteBaseCost) + float64(kc.WriteBytesCost)*writeBytes
+	consumption.WRU -= float64(cfg.WriteBaseCost) + float64(cfg.WriteBytesCost)*writeBytes
 }
 
 // SQLCalculator is used to calculate the SQL-side consumption.
 type SQLCalculator struct {
-	*Config
+	config *atomic.Pointer[Config]
 }
 
 var _ ResourceCalculator = (*SQLCalculator)(nil)
 
-func newSQLCalculator(cfg *Config) *SQLCalculator {
-	return &SQLCalculator{Config: cfg}
+func newSQLCalculator(cfg *atomic.Pointer[Config]) *SQLCalculator {
+	return &SQLCalculator{config: cfg}
 }
 
 // Trickle ...
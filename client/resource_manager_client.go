@@ -17,7 +17,6 @@ package pd
 import (
 	"context"
 	"strings"
-	"time"
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/pingcap/errors"
@@ -33,6 +32,8 @@ const (
 	add                     actionType = 0
 	modify                  actionType = 1
 	groupSettingsPathPrefix            = "resource_group/settings"
+	runawayPathPrefix                  = "resource_group/runaway"
+	ruModelPath                        = "resource_group/manager_settings/ru_model"
 	// errNotLeaderMsg is returned when the requested server is not the leader.
 	errNotLeaderMsg = "not leader"
 )
@@ -45,6 +46,8 @@ type ResourceManagerClient interface {
 	ModifyResourceGroup(ctx context.Context, metaGroup *rmpb.ResourceGroup) (string, error)
 	DeleteResourceGroup(ctx context.Context, resourceGroupName string) (string, error)
 	WatchResourceGroup(ctx context.Context, revision int64) (chan []*rmpb.ResourceGroup, error)
+	WatchRunawayRules(ctx context.Context, revision int64) (chan []GlobalConfigItem, error)
+	WatchRUModel(ctx context.Context, revision int64) (chan []GlobalConfigItem, error)
 	AcquireTokenBuckets(ctx context.Context, request *rmpb.TokenBucketsRequest) ([]*rmpb.TokenBucketResponse, error)
 }
 
@@ -187,6 +190,23 @@ func (c *client) WatchResourceGroup(ctx context.Context, revision int64) (chan [
 	return resourceGroupWatcherChan, err
 }
 
+// WatchRunawayRules watches the changes of the runaway query rules managed by
+// the resource manager. The names of the items are the storage keys, which are
+// under `resource_group/runaway/settings/{group}` for the runaway settings of
+// the groups and `resource_group/runaway/watches/{id}` for the watch items, and
+// the payloads are the rules in JSON.
+func (c *client) WatchRunawayRules(ctx context.Context, revision int64) (chan []GlobalConfigItem, error) {
+	return c.WatchGlobalConfig(ctx, runawayPathPrefix, revision)
+}
+
+// WatchRUModel watches the changes of the RU model managed by the resource
+// manager, which is under `resource_group/manager_settings/ru_model`. The
+// payload is the model in JSON, which can be decoded into `controller.RUModel`
+// and applied by `ResourceGroupsController.UpdateRUModel`.
+func (c *client) WatchRUModel(ctx context.Context, revision int64) (chan []GlobalConfigItem, error) {
+	return c.WatchGlobalConfig(ctx, ruModelPath, revision)
+}
+
 func (c *client) AcquireTokenBuckets(ctx context.Context, request *rmpb.TokenBucketsRequest) ([]*rmpb.TokenBucketResponse, error) {
 	req := &tokenRequest{
 		done:       make(chan error, 1),
@@ -348,7 +368,8 @@ func (c *client) tryResourceManagerConnect(ctx context.Context, connection *reso
 		err    error
 		stream rmpb.ResourceManager_AcquireTokenBucketsClient
 	)
-	for i := 0; i < maxRetryTimes; i++ {
+	policy := c.option.getRetryPolicy(ctx, defaultRetryPolicy)
+	for attempt := 1; ; attempt++ {
 		cctx, cancel := context.WithCancel(ctx)
 		stream, err = c.resourceManagerClient().AcquireTokenBuckets(cctx)
 		if err == nil && stream != nil {
@@ -358,13 +379,14 @@ func (c *client) tryResourceManagerConnect(ctx context.Context, connection *reso
 			return nil
 		}
 		cancel()
-		select {
-		case <-ctx.Done():
+		if !policy.retryable(attempt, err, nil) {
+			return err
+		}
+		c.option.observeRetry(ctx, acquireTokenBucketsMethod, attempt, err)
+		if !policy.wait(ctx, attempt) {
 			return err
-		case <-time.After(retryInterval):
 		}
 	}
-	return err
 }
 
 func (tbc *tokenBatchController) revokePendingTokenRequest(err error) {
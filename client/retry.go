@@ -0,0 +1,151 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/pingcap/errors"
+	"google.golang.org/grpc/status"
+)
+
+// RetryPolicy decides whether and when the client retries a failed request.
+type RetryPolicy struct {
+	// MaxAttempts is the max number of the attempts, including the first one.
+	// The request is not retried if it's less than 2.
+	MaxAttempts int
+	// Backoff returns the time to wait before the next attempt after the
+	// attempt-th one fails, which starts from 1. The request is retried
+	// immediately if it's nil.
+	Backoff func(attempt int) time.Duration
+	// Retryable returns whether the error is worth retrying. If it's nil, the
+	// default classification of each kind of the requests is used, e.g. the
+	// network errors and the leader changes are retried for the rule service.
+	Retryable func(err error) bool
+}
+
+// ConstantBackoff returns the backoff waiting for the same interval every time.
+func ConstantBackoff(interval time.Duration) func(attempt int) time.Duration {
+	return func(int) time.Duration { return interval }
+}
+
+// ExponentialBackoff returns the backoff doubling the interval every time from
+// base up to max. If jitter is true, the interval is randomized between the
+// half of it and itself to spread the retries of the clients.
+func ExponentialBackoff(base, max time.Duration, jitter bool) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		interval := base
+		for i := 1; i < attempt && interval < max; i++ {
+			interval *= 2
+		}
+		if interval > max {
+			interval = max
+		}
+		if jitter && interval > 1 {
+			interval = interval/2 + time.Duration(rand.Int63n(int64(interval/2)+1))
+		}
+		return interval
+	}
+}
+
+// IsRetryableError returns whether the error is caused by the network, e.g. the
+// PD member is unreachable or the request times out.
+func IsRetryableError(err error) bool {
+	if rpcErr, ok := status.FromError(errors.Cause(err)); ok {
+		return isNetworkError(rpcErr.Code())
+	}
+	return false
+}
+
+// defaultRetryPolicy is the policy of the rule service requests and the TSO and
+// the resource manager streams if the client is not configured with one.
+var defaultRetryPolicy = RetryPolicy{
+	MaxAttempts: maxRetryTimes,
+	Backoff:     ConstantBackoff(retryInterval),
+}
+
+// noRetryPolicy is the policy of the idempotent reads if the client is not
+// configured with one.
+var noRetryPolicy = RetryPolicy{MaxAttempts: 1}
+
+type retryPolicyKey struct{}
+
+// ContextWithRetryPolicy returns a context overriding the retry policy of the
+// client for the requests sent with it.
+func ContextWithRetryPolicy(ctx context.Context, policy RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+// getRetryPolicy returns the retry policy in the context if any, or the one of
+// the client if it's configured, or the default one otherwise.
+func (o *option) getRetryPolicy(ctx context.Context, defaultPolicy RetryPolicy) RetryPolicy {
+	if policy, ok := ctx.Value(retryPolicyKey{}).(RetryPolicy); ok {
+		return policy
+	}
+	if o.retryPolicy != nil {
+		return *o.retryPolicy
+	}
+	return defaultPolicy
+}
+
+// retryable returns whether the error should be retried after the attempt-th
+// attempt fails, where defaultRetryable is used if the policy has no
+// classification.
+func (p RetryPolicy) retryable(attempt int, err error, defaultRetryable func(error) bool) bool {
+	if attempt >= p.MaxAttempts {
+		return false
+	}
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return defaultRetryable == nil || defaultRetryable(err)
+}
+
+// wait waits for the backoff after the attempt-th attempt fails. It returns
+// false if the context is done.
+func (p RetryPolicy) wait(ctx context.Context, attempt int) bool {
+	var backoff time.Duration
+	if p.Backoff != nil {
+		backoff = p.Backoff(attempt)
+	}
+	if backoff <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// retry calls f until it succeeds, the error is not retryable, or the attempts
+// of the policy are used up. It returns the error of the last attempt.
+func (o *option) retry(ctx context.Context, method string, policy RetryPolicy, defaultRetryable func(error) bool, f func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := f()
+		if err == nil || !policy.retryable(attempt, err, defaultRetryable) {
+			return err
+		}
+		o.observeRetry(ctx, method, attempt, err)
+		if !policy.wait(ctx, attempt) {
+			return errors.WithStack(ctx.Err())
+		}
+	}
+}
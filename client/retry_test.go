@@ -0,0 +1,102 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	re := require.New(t)
+	backoff := ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond, false)
+	re.Equal(10*time.Millisecond, backoff(1))
+	re.Equal(20*time.Millisecond, backoff(2))
+	re.Equal(40*time.Millisecond, backoff(3))
+	re.Equal(50*time.Millisecond, backoff(4))
+	re.Equal(50*time.Millisecond, backoff(100))
+
+	backoff = ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond, true)
+	for i := 0; i < 100; i++ {
+		interval := backoff(2)
+		re.GreaterOrEqual(interval, 10*time.Millisecond)
+		re.LessOrEqual(interval, 20*time.Millisecond)
+	}
+	re.Equal(time.Second, ConstantBackoff(time.Second)(10))
+}
+
+func TestRetryPolicy(t *testing.T) {
+	re := require.New(t)
+	ctx := context.Background()
+	unavailable := status.New(codes.Unavailable, "unavailable").Err()
+	o := newOption()
+	run := func(ctx context.Context, defaultPolicy RetryPolicy, errs ...error) (int, error) {
+		attempts := 0
+		err := o.retry(ctx, tsoMethod, o.getRetryPolicy(ctx, defaultPolicy), IsRetryableError, func() error {
+			attempts++
+			if attempts > len(errs) {
+				return nil
+			}
+			return errs[attempts-1]
+		})
+		return attempts, err
+	}
+
+	// Not retried by default.
+	attempts, err := run(ctx, noRetryPolicy, unavailable)
+	re.Equal(1, attempts)
+	re.Equal(unavailable, err)
+
+	policy := RetryPolicy{MaxAttempts: 3}
+	o.retryPolicy = &policy
+	attempts, err = run(ctx, noRetryPolicy, unavailable, unavailable)
+	re.Equal(3, attempts)
+	re.NoError(err)
+	attempts, err = run(ctx, noRetryPolicy, unavailable, unavailable, unavailable, unavailable)
+	re.Equal(3, attempts)
+	re.Equal(unavailable, err)
+	// The error not caused by the network is not retried.
+	attempts, err = run(ctx, noRetryPolicy, errors.New("invalid"))
+	re.Equal(1, attempts)
+	re.Error(err)
+
+	// The classification of the policy overrides the default one.
+	policy.Retryable = func(error) bool { return true }
+	attempts, err = run(ctx, noRetryPolicy, errors.New("invalid"))
+	re.Equal(2, attempts)
+	re.NoError(err)
+
+	// The policy of the call overrides the one of the client.
+	callCtx := ContextWithRetryPolicy(ctx, RetryPolicy{MaxAttempts: 1})
+	attempts, err = run(callCtx, noRetryPolicy, unavailable)
+	re.Equal(1, attempts)
+	re.Equal(unavailable, err)
+
+	// The backoff is interrupted when the context is done.
+	callCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	callCtx = ContextWithRetryPolicy(callCtx, RetryPolicy{MaxAttempts: 3, Backoff: ConstantBackoff(time.Minute)})
+	start := time.Now()
+	attempts, err = run(callCtx, noRetryPolicy, unavailable, unavailable)
+	re.Equal(1, attempts)
+	re.ErrorIs(err, context.DeadlineExceeded)
+	re.Less(time.Since(start), time.Minute)
+}
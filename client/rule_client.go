@@ -0,0 +1,358 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tikv/pd/client/errs"
+	"github.com/tikv/pd/client/grpcutil"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// ruleServiceName is the name of the PD gRPC service managing the rules, whose
+// messages are encoded in JSON.
+const ruleServiceName = "pd.Rule"
+
+// LabelConstraint is used to filter the stores by the labels.
+type LabelConstraint struct {
+	Key string `json:"key,omitempty"`
+	// Op is one of "in", "notIn", "exists" and "notExists".
+	Op     string   `json:"op,omitempty"`
+	Values []string `json:"values,omitempty"`
+}
+
+// PlacementRule is the placement rule of a key range. The keys are encoded in
+// hex, and Version and CreateTimestamp are only set by PD.
+type PlacementRule struct {
+	GroupID     string `json:"group_id"`
+	ID          string `json:"id"`
+	Index       int    `json:"index,omitempty"`
+	Override    bool   `json:"override,omitempty"`
+	StartKeyHex string `json:"start_key"`
+	EndKeyHex   string `json:"end_key"`
+	// Role is one of "voter", "leader", "follower" and "learner".
+	Role             string            `json:"role"`
+	IsWitness        bool              `json:"is_witness"`
+	Count            int               `json:"count"`
+	LabelConstraints []LabelConstraint `json:"label_constraints,omitempty"`
+	LocationLabels   []string          `json:"location_labels,omitempty"`
+	IsolationLevel   string            `json:"isolation_level,omitempty"`
+	Version          uint64            `json:"version,omitempty"`
+	CreateTimestamp  uint64            `json:"create_timestamp,omitempty"`
+}
+
+// PlacementRuleKey is the key of a placement rule.
+type PlacementRuleKey struct {
+	GroupID string `json:"group_id"`
+	ID      string `json:"id"`
+}
+
+// RegionLabel is the label of a region.
+type RegionLabel struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	TTL     string `json:"ttl,omitempty"`
+	StartAt string `json:"start_at,omitempty"`
+}
+
+// LabelRule is the rule to assign labels to the regions. For the "key-range"
+// rule type, Data is a list of {"start_key": ..., "end_key": ...} with the keys
+// encoded in hex.
+type LabelRule struct {
+	ID       string        `json:"id"`
+	Index    int           `json:"index"`
+	Labels   []RegionLabel `json:"labels"`
+	RuleType string        `json:"rule_type"`
+	Data     interface{}   `json:"data"`
+}
+
+// LabelRulePatch sets and deletes the label rules in a batch.
+type LabelRulePatch struct {
+	SetRules    []*LabelRule `json:"sets"`
+	DeleteRules []string     `json:"deletes"`
+}
+
+// RuleChanges is a message of the rule watch.
+type RuleChanges struct {
+	// Snapshot means the message has all the rules, which replace the ones the
+	// watcher knows. Otherwise, the message has the changed and deleted rules.
+	Snapshot              bool               `json:"snapshot,omitempty"`
+	PlacementRules        []*PlacementRule   `json:"placement_rules,omitempty"`
+	DeletedPlacementRules []PlacementRuleKey `json:"deleted_placement_rules,omitempty"`
+	LabelRules            []*LabelRule       `json:"label_rules,omitempty"`
+	DeletedLabelRules     []string           `json:"deleted_label_rules,omitempty"`
+}
+
+// RuleClient manages the placement rules and the region label rules.
+type RuleClient interface {
+	// GetPlacementRules returns all the placement rules, or the ones of the
+	// group if it is not empty.
+	GetPlacementRules(ctx context.Context, groupID string) ([]*PlacementRule, error)
+	// SetPlacementRules creates or updates the placement rules atomically.
+	SetPlacementRules(ctx context.Context, rules []*PlacementRule) error
+	// DeletePlacementRule removes the placement rule.
+	DeletePlacementRule(ctx context.Context, groupID, id string) error
+	// GetLabelRules returns all the label rules, or the ones with the IDs.
+	GetLabelRules(ctx context.Context, ids ...string) ([]*LabelRule, error)
+	// PatchLabelRules sets and deletes the label rules in a batch.
+	PatchLabelRules(ctx context.Context, patch *LabelRulePatch) error
+	// WatchRules watches the rules. The first message in the channel contains
+	// all the rules, and the later ones contain the changes. The watch is
+	// restarted from a new snapshot when the PD leader changes, and the channel
+	// is closed when the context is done.
+	WatchRules(ctx context.Context) (chan *RuleChanges, error)
+}
+
+type getPlacementRulesRequest struct {
+	Header  *pdpb.RequestHeader `json:"header"`
+	GroupID string              `json:"group_id,omitempty"`
+}
+
+type getPlacementRulesResponse struct {
+	Header *pdpb.ResponseHeader `json:"header"`
+	Rules  []*PlacementRule     `json:"rules,omitempty"`
+}
+
+type setPlacementRulesRequest struct {
+	Header *pdpb.RequestHeader `json:"header"`
+	Rules  []*PlacementRule    `json:"rules"`
+}
+
+type deletePlacementRuleRequest struct {
+	Header *pdpb.RequestHeader `json:"header"`
+	PlacementRuleKey
+}
+
+type getLabelRulesRequest struct {
+	Header *pdpb.RequestHeader `json:"header"`
+	IDs    []string            `json:"ids,omitempty"`
+}
+
+type getLabelRulesResponse struct {
+	Header *pdpb.ResponseHeader `json:"header"`
+	Rules  []*LabelRule         `json:"rules,omitempty"`
+}
+
+type patchLabelRulesRequest struct {
+	Header *pdpb.RequestHeader `json:"header"`
+	*LabelRulePatch
+}
+
+type watchRulesRequest struct {
+	Header *pdpb.RequestHeader `json:"header"`
+}
+
+type watchRulesResponse struct {
+	Header *pdpb.ResponseHeader `json:"header"`
+	RuleChanges
+}
+
+// ruleResponse is the response of the rule service without the result.
+type ruleResponse struct {
+	Header *pdpb.ResponseHeader `json:"header"`
+}
+
+func ruleMethod(name string) string {
+	return "/" + ruleServiceName + "/" + name
+}
+
+// invokeRule calls the unary method of the rule service on the PD leader.
+func (c *client) invokeRule(ctx context.Context, name string, req interface{}, getHeader func() *pdpb.ResponseHeader, resp interface{}) error {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("ruleClient."+name, opentracing.ChildOf(span.Context()))
+		defer span.Finish()
+	}
+	return c.invokeJSON(ctx, ruleServiceName, name, req, getHeader, resp, cmdDurationRule, cmdFailedDurationRule)
+}
+
+// invokeJSON calls the unary method of the PD gRPC service whose messages are
+// encoded in JSON, e.g. the rule service, on the PD leader. It retries with
+// the retry policy if the leader is unreachable or changed.
+func (c *client) invokeJSON(
+	ctx context.Context, service, name string, req interface{}, getHeader func() *pdpb.ResponseHeader, resp interface{},
+	duration, failedDuration prometheus.Observer,
+) error {
+	start := time.Now()
+	defer func() { duration.Observe(time.Since(start).Seconds()) }()
+	method := "/" + service + "/" + name
+	policy := c.option.getRetryPolicy(ctx, defaultRetryPolicy)
+	err := c.option.retry(ctx, method, policy, isRetryableRuleError, func() error {
+		err := c.invokeJSONOnce(ctx, method, req, resp)
+		if err != nil && isRetryableRuleError(err) {
+			c.ScheduleCheckLeader()
+		}
+		return err
+	})
+	if err != nil {
+		failedDuration.Observe(time.Since(start).Seconds())
+		return err
+	}
+	if header := getHeader(); header.GetError() != nil {
+		failedDuration.Observe(time.Since(start).Seconds())
+		return errors.Errorf("%s failed: %s", name, header.GetError().String())
+	}
+	return nil
+}
+
+func (c *client) invokeJSONOnce(ctx context.Context, method string, req, resp interface{}) error {
+	cc, err := c.getOrCreateGRPCConn(c.GetLeaderAddr())
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.option.timeout)
+	defer cancel()
+	return cc.Invoke(ctx, method, req, resp, grpc.CallContentSubtype(grpcutil.JSONCodecName))
+}
+
+// isRetryableRuleError returns whether the request may succeed on the leader
+// after retrying.
+func isRetryableRuleError(err error) bool {
+	if rpcErr, ok := status.FromError(errors.Cause(err)); ok && isNetworkError(rpcErr.Code()) {
+		return true
+	}
+	return errs.ErrGRPCDial.Equal(err) || strings.Contains(err.Error(), errNotLeaderMsg)
+}
+
+// GetPlacementRules returns all the placement rules, or the ones of the group
+// if it is not empty.
+func (c *client) GetPlacementRules(ctx context.Context, groupID string) ([]*PlacementRule, error) {
+	resp := &getPlacementRulesResponse{}
+	req := &getPlacementRulesRequest{Header: c.requestHeader(), GroupID: groupID}
+	if err := c.invokeRule(ctx, "GetPlacementRules", req, func() *pdpb.ResponseHeader { return resp.Header }, resp); err != nil {
+		return nil, err
+	}
+	return resp.Rules, nil
+}
+
+// SetPlacementRules creates or updates the placement rules atomically.
+func (c *client) SetPlacementRules(ctx context.Context, rules []*PlacementRule) error {
+	resp := &ruleResponse{}
+	req := &setPlacementRulesRequest{Header: c.requestHeader(), Rules: rules}
+	return c.invokeRule(ctx, "SetPlacementRules", req, func() *pdpb.ResponseHeader { return resp.Header }, resp)
+}
+
+// DeletePlacementRule removes the placement rule.
+func (c *client) DeletePlacementRule(ctx context.Context, groupID, id string) error {
+	resp := &ruleResponse{}
+	req := &deletePlacementRuleRequest{Header: c.requestHeader(), PlacementRuleKey: PlacementRuleKey{GroupID: groupID, ID: id}}
+	return c.invokeRule(ctx, "DeletePlacementRule", req, func() *pdpb.ResponseHeader { return resp.Header }, resp)
+}
+
+// GetLabelRules returns all the label rules, or the ones with the IDs.
+func (c *client) GetLabelRules(ctx context.Context, ids ...string) ([]*LabelRule, error) {
+	resp := &getLabelRulesResponse{}
+	req := &getLabelRulesRequest{Header: c.requestHeader(), IDs: ids}
+	if err := c.invokeRule(ctx, "GetLabelRules", req, func() *pdpb.ResponseHeader { return resp.Header }, resp); err != nil {
+		return nil, err
+	}
+	return resp.Rules, nil
+}
+
+// PatchLabelRules sets and deletes the label rules in a batch.
+func (c *client) PatchLabelRules(ctx context.Context, patch *LabelRulePatch) error {
+	resp := &ruleResponse{}
+	req := &patchLabelRulesRequest{Header: c.requestHeader(), LabelRulePatch: patch}
+	return c.invokeRule(ctx, "PatchLabelRules", req, func() *pdpb.ResponseHeader { return resp.Header }, resp)
+}
+
+// WatchRules watches the rules. The first message in the channel contains all
+// the rules, and the later ones contain the changes. The watch is restarted
+// from a new snapshot when the PD leader changes, and the channel is closed
+// when the context is done.
+func (c *client) WatchRules(ctx context.Context) (chan *RuleChanges, error) {
+	stream, err := c.watchRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ruleWatcherChan := make(chan *RuleChanges)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error("[pd] panic in rule client `WatchRules`", zap.Any("error", r))
+			}
+		}()
+		defer close(ruleWatcherChan)
+		for {
+			resp := &watchRulesResponse{}
+			err := stream.RecvMsg(resp)
+			if err == nil && resp.Header.GetError() != nil {
+				err = errors.Errorf("WatchRules failed: %s", resp.Header.GetError().String())
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				log.Warn("[pd] rule watch is broken, watch again", errs.ZapError(err))
+				c.ScheduleCheckLeader()
+				if stream = c.rewatchRules(ctx); stream == nil {
+					return
+				}
+				continue
+			}
+			select {
+			case ruleWatcherChan <- &resp.RuleChanges:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ruleWatcherChan, nil
+}
+
+func (c *client) watchRules(ctx context.Context) (grpc.ClientStream, error) {
+	cc, err := c.getOrCreateGRPCConn(c.GetLeaderAddr())
+	if err != nil {
+		return nil, err
+	}
+	desc := &grpc.StreamDesc{StreamName: "WatchRules", ServerStreams: true}
+	stream, err := cc.NewStream(ctx, desc, ruleMethod("WatchRules"), grpc.CallContentSubtype(grpcutil.JSONCodecName))
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(&watchRulesRequest{Header: c.requestHeader()}); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// rewatchRules keeps watching the rules until it succeeds, or returns nil when
+// the context is done.
+func (c *client) rewatchRules(ctx context.Context) grpc.ClientStream {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(retryInterval):
+		}
+		stream, err := c.watchRules(ctx)
+		if err == nil {
+			return stream
+		}
+		log.Warn("[pd] failed to watch the rules", errs.ZapError(err))
+	}
+}
@@ -35,7 +35,7 @@ type TSOClient interface {
 // here is in a basic manner and only for testing and integration purpose -- no batching,
 // no async, no pooling, no forwarding, no retry and no deliberate error handling.
 func (c *client) GetTSWithinKeyspace(ctx context.Context, keyspaceID uint32) (physical int64, logical int64, err error) {
-	resp := c.GetTSAsync(ctx)
+	resp := c.GetTSWithinKeyspaceAsync(ctx, keyspaceID)
 	return resp.Wait()
 }
 
@@ -53,7 +53,8 @@ func (c *client) GetTSWithinKeyspaceAsync(ctx context.Context, keyspaceID uint32
 
 // GetLocalTSWithinKeyspaceAsync gets a local timestamp within the given keyspace from the TSO service,
 // without block the caller.
-// TODO: implement the following API
+// All the keyspaces share the TSO of the cluster before the TSO service is
+// split by the keyspace groups, so it's the same as GetLocalTSAsync.
 func (c *client) GetLocalTSWithinKeyspaceAsync(ctx context.Context, dcLocation string, keyspaceID uint32) TSFuture {
-	return nil
+	return c.GetLocalTSAsync(ctx, dcLocation)
 }
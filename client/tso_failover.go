@@ -0,0 +1,260 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/client/errs"
+	"go.uber.org/zap"
+)
+
+// tsoFailbackCheckInterval is the interval to check whether the primary
+// cluster recovers after failing over.
+var tsoFailbackCheckInterval = time.Second
+
+var (
+	errTSOClusterSwitched = errors.New("[pd] the TSO cluster is switched during the request")
+	errTSOCausality       = errors.New("[pd] the timestamp is not greater than the ones returned before switching the TSO cluster")
+)
+
+// tsoFailover fails the global TSO requests over to the secondary PD cluster
+// if the primary one keeps failing them, and back once it recovers.
+type tsoFailover struct {
+	addrs    []string
+	security SecurityOption
+	// unavailableTimeout is how long the primary cluster keeps failing the
+	// requests before failing over.
+	unavailableTimeout time.Duration
+
+	// secondaryMu guards the lazy creation of the secondary client.
+	secondaryMu sync.Mutex
+	secondary   Client
+
+	mu sync.Mutex
+	// onSecondary is whether the requests are sent to the secondary cluster.
+	onSecondary bool
+	// epoch is increased on each switch. The timestamps requested before a
+	// switch are rejected, since they are not ordered with the ones after it.
+	epoch uint64
+	// failingSince is when the primary cluster starts to fail the requests,
+	// zero means it's healthy.
+	failingSince time.Time
+	// floor is the largest timestamp returned before the last switch, and last
+	// is the largest one returned so far.
+	floorPhysical, floorLogical int64
+	lastPhysical, lastLogical   int64
+}
+
+// WithTSOFailover configures the client to get the global timestamps from the
+// secondary PD cluster of the addresses if the primary one keeps failing the
+// requests for unavailableTimeout, which is meant for the active-standby
+// deployments. Only GetTS and GetTSAsync fail over.
+//
+// It must be opted in explicitly, since the timestamps of the two clusters are
+// not ordered. After switching the cluster, a timestamp is returned only if it
+// is greater than all the ones the client returned before, otherwise the
+// request fails. So the timestamps of a client always increase, but the ones
+// of different clients are not ordered across the clusters. The secondary
+// cluster is expected to keep its TSO ahead of the primary one. The client
+// switches back once the primary cluster serves a timestamp greater than the
+// returned ones.
+func WithTSOFailover(secondaryAddrs []string, security SecurityOption, unavailableTimeout time.Duration) ClientOption {
+	return func(c *client) {
+		c.tsoFailover = &tsoFailover{
+			addrs:              secondaryAddrs,
+			security:           security,
+			unavailableTimeout: unavailableTimeout,
+		}
+	}
+}
+
+// state returns the current epoch and whether the requests are sent to the
+// secondary cluster.
+func (f *tsoFailover) state() (epoch uint64, onSecondary bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.epoch, f.onSecondary
+}
+
+// switchLocked switches the cluster, and the timestamps after it must be
+// greater than the returned ones.
+func (f *tsoFailover) switchLocked(onSecondary bool) {
+	f.onSecondary = onSecondary
+	f.epoch++
+	f.failingSince = time.Time{}
+	f.floorPhysical, f.floorLogical = f.lastPhysical, f.lastLogical
+}
+
+// primaryFailed records that the primary cluster fails a request of the epoch
+// at now, and fails over if it keeps failing for too long. It returns whether
+// the requests should be sent to the secondary cluster.
+func (f *tsoFailover) primaryFailed(epoch uint64, now time.Time) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if epoch != f.epoch || f.onSecondary {
+		return f.onSecondary
+	}
+	if f.failingSince.IsZero() {
+		f.failingSince = now
+	}
+	if now.Sub(f.failingSince) < f.unavailableTimeout {
+		return false
+	}
+	log.Warn("[pd] the primary cluster keeps failing the TSO requests, fail over to the secondary cluster",
+		zap.Strings("secondary-addrs", f.addrs), zap.Time("failing-since", f.failingSince))
+	f.switchLocked(true)
+	return true
+}
+
+// accept checks the timestamp got from the cluster of the epoch, and records
+// it as returned if it's valid.
+func (f *tsoFailover) accept(epoch uint64, physical, logical int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if epoch != f.epoch {
+		return errors.WithStack(errTSOClusterSwitched)
+	}
+	if tsLessEqual(physical, logical, f.floorPhysical, f.floorLogical) {
+		return errors.WithStack(errTSOCausality)
+	}
+	if !f.onSecondary {
+		f.failingSince = time.Time{}
+	}
+	if !tsLessEqual(physical, logical, f.lastPhysical, f.lastLogical) {
+		f.lastPhysical, f.lastLogical = physical, logical
+	}
+	return nil
+}
+
+// failBack switches back to the primary cluster if the timestamp it serves is
+// greater than the returned ones.
+func (f *tsoFailover) failBack(physical, logical int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.onSecondary {
+		return
+	}
+	if tsLessEqual(physical, logical, f.lastPhysical, f.lastLogical) {
+		log.Warn("[pd] the primary cluster recovers but its TSO is behind, keep using the secondary cluster",
+			zap.Int64("physical", physical), zap.Int64("last-physical", f.lastPhysical))
+		return
+	}
+	log.Info("[pd] the primary cluster recovers, fail back from the secondary cluster")
+	f.switchLocked(false)
+}
+
+// getSecondary returns the client of the secondary cluster, which is created
+// on the first failover.
+func (f *tsoFailover) getSecondary(ctx context.Context, timeout time.Duration) (Client, error) {
+	f.secondaryMu.Lock()
+	defer f.secondaryMu.Unlock()
+	if f.secondary != nil {
+		return f.secondary, nil
+	}
+	secondary, err := NewClientWithContext(ctx, f.addrs, f.security, WithCustomTimeoutOption(timeout), WithMaxErrorRetry(1))
+	if err != nil {
+		return nil, err
+	}
+	f.secondary = secondary
+	return secondary, nil
+}
+
+func (f *tsoFailover) close() {
+	f.secondaryMu.Lock()
+	defer f.secondaryMu.Unlock()
+	if f.secondary != nil {
+		f.secondary.Close()
+		f.secondary = nil
+	}
+}
+
+// failoverTSFuture is the future of a global TSO request sent to the cluster
+// of the epoch.
+type failoverTSFuture struct {
+	c           *client
+	ctx         context.Context
+	epoch       uint64
+	onSecondary bool
+	future      TSFuture
+}
+
+// errTSFuture is the future of a request failing before it's sent.
+type errTSFuture struct {
+	err error
+}
+
+func (f errTSFuture) Wait() (int64, int64, error) {
+	return 0, 0, f.err
+}
+
+func (c *client) getTSWithFailover(ctx context.Context) TSFuture {
+	epoch, onSecondary := c.tsoFailover.state()
+	future := &failoverTSFuture{c: c, ctx: ctx, epoch: epoch, onSecondary: onSecondary}
+	if !onSecondary {
+		future.future = c.GetLocalTSAsync(ctx, globalDCLocation)
+		return future
+	}
+	secondary, err := c.tsoFailover.getSecondary(c.ctx, c.option.timeout)
+	if err != nil {
+		return errTSFuture{err: err}
+	}
+	future.future = secondary.GetTSAsync(ctx)
+	return future
+}
+
+func (f *failoverTSFuture) Wait() (int64, int64, error) {
+	physical, logical, err := f.future.Wait()
+	if err != nil {
+		// The failures caused by the caller don't count.
+		if !f.onSecondary && f.ctx.Err() == nil && f.c.tsoFailover.primaryFailed(f.epoch, time.Now()) {
+			return f.c.getTSWithFailover(f.ctx).Wait()
+		}
+		return 0, 0, err
+	}
+	if err := f.c.tsoFailover.accept(f.epoch, physical, logical); err != nil {
+		return 0, 0, err
+	}
+	return physical, logical, nil
+}
+
+// tsoFailbackLoop switches back to the primary cluster once it recovers.
+func (c *client) tsoFailbackLoop() {
+	defer c.wg.Done()
+	ticker := time.NewTicker(tsoFailbackCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if _, onSecondary := c.tsoFailover.state(); !onSecondary {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(c.ctx, c.option.timeout)
+		physical, logical, err := c.GetLocalTSAsync(ctx, globalDCLocation).Wait()
+		cancel()
+		if err != nil {
+			log.Debug("[pd] the primary cluster is still unavailable", errs.ZapError(err))
+			continue
+		}
+		c.tsoFailover.failBack(physical, logical)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTSOFailover(t *testing.T) {
+	re := require.New(t)
+	f := &tsoFailover{unavailableTimeout: time.Second}
+	now := time.Now()
+	epoch, onSecondary := f.state()
+	re.False(onSecondary)
+	re.NoError(f.accept(epoch, 10, 1))
+
+	// The primary cluster fails over after failing for a while.
+	re.False(f.primaryFailed(epoch, now))
+	re.False(f.primaryFailed(epoch, now.Add(time.Second/2)))
+	// A success resets the failures.
+	re.NoError(f.accept(epoch, 10, 2))
+	re.False(f.primaryFailed(epoch, now.Add(time.Second)))
+	re.True(f.primaryFailed(epoch, now.Add(2*time.Second)))
+	re.True(f.primaryFailed(epoch, now.Add(3*time.Second)))
+	secondaryEpoch, onSecondary := f.state()
+	re.True(onSecondary)
+	re.NotEqual(epoch, secondaryEpoch)
+
+	// The timestamps requested from the primary cluster are rejected.
+	re.Equal(errTSOClusterSwitched, errors.Cause(f.accept(epoch, 20, 0)))
+	// The timestamps of the secondary cluster must be greater than the
+	// returned ones.
+	re.Equal(errTSOCausality, errors.Cause(f.accept(secondaryEpoch, 10, 2)))
+	re.Equal(errTSOCausality, errors.Cause(f.accept(secondaryEpoch, 9, 100)))
+	re.NoError(f.accept(secondaryEpoch, 10, 3))
+	re.NoError(f.accept(secondaryEpoch, 30, 0))
+	// The out-of-order responses in the same cluster are fine.
+	re.NoError(f.accept(secondaryEpoch, 20, 0))
+
+	// The primary cluster behind the returned timestamps is not used.
+	f.failBack(25, 0)
+	epoch, onSecondary = f.state()
+	re.True(onSecondary)
+	re.Equal(secondaryEpoch, epoch)
+	f.failBack(31, 0)
+	epoch, onSecondary = f.state()
+	re.False(onSecondary)
+	re.Equal(errTSOClusterSwitched, errors.Cause(f.accept(secondaryEpoch, 40, 0)))
+	re.Equal(errTSOCausality, errors.Cause(f.accept(epoch, 30, 0)))
+	re.NoError(f.accept(epoch, 31, 1))
+}
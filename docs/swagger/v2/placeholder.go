@@ -0,0 +1 @@
+package v2
@@ -15,6 +15,8 @@
 package audit
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -110,6 +112,48 @@ func TestLocalLogBackendUsingFile(t *testing.T) {
 	)
 }
 
+func TestSecurityLogger(t *testing.T) {
+	t.Parallel()
+	re := require.New(t)
+	received := make(chan []*SecurityEvent, 1)
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var events []*SecurityEvent
+		re.NoError(json.NewDecoder(r.Body).Decode(&events))
+		received <- events
+	}))
+	defer sink.Close()
+	f, err := os.CreateTemp("/tmp", "pd_tests")
+	re.NoError(err)
+	fname := f.Name()
+	f.Close()
+	defer os.Remove(fname)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger, err := NewSecurityLogger(ctx, log.FileLogConfig{Filename: fname}, sink.URL)
+	re.NoError(err)
+	logger.Log(&SecurityEvent{Type: SecurityEventAuthentication, Protocol: "HTTP", Method: http.MethodDelete, Path: "/pd/api/v1/store/1", Identity: "monitor", Role: "viewer", Reason: "permission denied"})
+	logger.Log(&SecurityEvent{Type: SecurityEventMutation, Protocol: "HTTP", Method: http.MethodDelete, Path: "/pd/api/v1/store/1", Identity: "tikv", Status: http.StatusOK})
+	events := <-received
+	re.Len(events, 2)
+	re.False(events[0].Allowed)
+	re.Equal("monitor", events[0].Identity)
+	re.Equal(http.StatusOK, events[1].Status)
+
+	b, err := os.ReadFile(fname)
+	re.NoError(err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	re.Len(lines, 2)
+	record := make(map[string]interface{})
+	re.NoError(json.Unmarshal([]byte(lines[0]), &record))
+	re.Equal("security audit", record["message"])
+	re.Equal("/pd/api/v1/store/1", record["path"])
+
+	// A nil logger discards the records.
+	var nilLogger *SecurityLogger
+	nilLogger.Log(&SecurityEvent{Type: SecurityEventMutation})
+}
+
 func BenchmarkLocalLogAuditUsingTerminal(b *testing.B) {
 	b.StopTimer()
 	backend := NewLocalLogBackend(true)
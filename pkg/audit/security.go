@@ -0,0 +1,191 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/utils/apiutil"
+	"github.com/tikv/pd/pkg/utils/logutil"
+	"go.uber.org/zap"
+)
+
+const (
+	// sinkBufferSize is the number of the records waiting to be sent to the sink,
+	// the new records are dropped once it is full.
+	sinkBufferSize = 4096
+	// sinkBatchSize is the max number of the records sent to the sink at once.
+	sinkBatchSize = 256
+	// sinkFlushInterval is the interval to send the records to the sink.
+	sinkFlushInterval = time.Second
+	sinkTimeout       = 10 * time.Second
+)
+
+// The types of the security events.
+const (
+	// SecurityEventAuthentication is a decision of the role-based access control.
+	SecurityEventAuthentication = "authentication"
+	// SecurityEventMutation is a call of the mutating API.
+	SecurityEventMutation = "mutation"
+	// SecurityEventNetwork is a request denied by the network ACLs.
+	SecurityEventNetwork = "network"
+)
+
+// SecurityEvent is a record of the security audit log.
+type SecurityEvent struct {
+	Type string `json:"type"`
+	// Time is the unix timestamp in milliseconds when the event happens.
+	Time int64 `json:"time"`
+	// Protocol is either HTTP or gRPC.
+	Protocol string `json:"protocol"`
+	// Method is the HTTP method or the full gRPC method.
+	Method string `json:"method"`
+	Path   string `json:"path,omitempty"`
+	// Identity is the authenticated identity, or the certificate CN if it is
+	// not authenticated.
+	Identity   string `json:"identity,omitempty"`
+	Role       string `json:"role,omitempty"`
+	RemoteAddr string `json:"remote_addr,omitempty"`
+	// Allowed is whether the request is allowed, only for the authentication.
+	Allowed bool `json:"allowed"`
+	// Status is the HTTP status code of the response, only for the mutation.
+	Status int    `json:"status,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+var securityEventDroppedCounter = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "pd",
+		Subsystem: "audit",
+		Name:      "security_events_dropped_total",
+		Help:      "Counter of the security audit records dropped by the remote sink.",
+	})
+
+func init() {
+	prometheus.MustRegister(securityEventDroppedCounter)
+}
+
+// SecurityLogger writes the security audit log, which is separate from the
+// operational log and has its own rotation, and sends the records to the
+// remote sink if any. A nil SecurityLogger discards all the records.
+type SecurityLogger struct {
+	logger *zap.Logger
+	// sink is the URL the records are posted to in batches as JSON arrays.
+	sink       string
+	sinkClient *http.Client
+	sinkCh     chan *SecurityEvent
+}
+
+// NewSecurityLogger creates a SecurityLogger writing the JSON records to the
+// file. The records are also sent to the sink in the background until the
+// context is canceled if the sink is not empty.
+func NewSecurityLogger(ctx context.Context, file log.FileLogConfig, sink string) (*SecurityLogger, error) {
+	logger, _, err := log.InitLogger(&log.Config{
+		Level:  "info",
+		Format: "json",
+		File:   file,
+	})
+	if err != nil {
+		return nil, err
+	}
+	l := &SecurityLogger{logger: logger, sink: sink}
+	if len(sink) > 0 {
+		l.sinkClient = &http.Client{Timeout: sinkTimeout}
+		l.sinkCh = make(chan *SecurityEvent, sinkBufferSize)
+		go l.runSink(ctx)
+	}
+	return l, nil
+}
+
+// Log records the security event.
+func (l *SecurityLogger) Log(event *SecurityEvent) {
+	if l == nil {
+		return
+	}
+	if event.Time == 0 {
+		event.Time = time.Now().UnixNano() / int64(time.Millisecond)
+	}
+	// The reason may carry the user data in the error message.
+	event.Reason = logutil.RedactString(event.Reason)
+	l.logger.Info("security audit",
+		zap.String("type", event.Type),
+		zap.String("protocol", event.Protocol),
+		zap.String("method", event.Method),
+		zap.String("path", event.Path),
+		zap.String("identity", event.Identity),
+		zap.String("role", event.Role),
+		zap.String("remote-addr", event.RemoteAddr),
+		zap.Bool("allowed", event.Allowed),
+		zap.Int("status", event.Status),
+		zap.String("reason", event.Reason),
+	)
+	if l.sinkCh == nil {
+		return
+	}
+	select {
+	case l.sinkCh <- event:
+	default:
+		securityEventDroppedCounter.Inc()
+	}
+}
+
+func (l *SecurityLogger) runSink(ctx context.Context) {
+	defer logutil.LogPanic()
+	ticker := time.NewTicker(sinkFlushInterval)
+	defer ticker.Stop()
+	batch := make([]*SecurityEvent, 0, sinkBatchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			l.flush(batch)
+			return
+		case event := <-l.sinkCh:
+			batch = append(batch, event)
+			if len(batch) < sinkBatchSize {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+		l.flush(batch)
+		batch = batch[:0]
+	}
+}
+
+func (l *SecurityLogger) flush(batch []*SecurityEvent) {
+	if len(batch) == 0 {
+		return
+	}
+	data, err := json.Marshal(batch)
+	if err != nil {
+		log.Warn("failed to marshal security audit records", errs.ZapError(errs.ErrJSONMarshal.Wrap(err).GenWithStackByCause()))
+		return
+	}
+	if err := apiutil.PostJSONIgnoreResp(l.sinkClient, l.sink, data); err != nil {
+		securityEventDroppedCounter.Add(float64(len(batch)))
+		log.Warn("failed to send security audit records to the sink",
+			zap.String("sink", l.sink),
+			zap.Int("count", len(batch)),
+			errs.ZapError(err))
+	}
+}
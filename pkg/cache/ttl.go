@@ -257,6 +257,11 @@ func (c *TTLString) PutWithTTL(key string, value interface{}, ttl time.Duration)
 	c.ttlCache.putWithTTL(key, value, ttl)
 }
 
+// Remove removes the key.
+func (c *TTLString) Remove(key string) {
+	c.ttlCache.remove(key)
+}
+
 // Pop one key/value that is not expired
 func (c *TTLString) Pop() (string, interface{}, bool) {
 	k, v, success := c.ttlCache.pop()
@@ -585,6 +585,16 @@ func (r *RegionInfo) GetKeysRead() uint64 {
 	return r.readKeys
 }
 
+// GetReadRate returns the read rate of the region.
+func (r *RegionInfo) GetReadRate() (bytesRate, keysRate float64) {
+	reportInterval := r.GetInterval()
+	interval := reportInterval.GetEndTimestamp() - reportInterval.GetStartTimestamp()
+	if interval >= statsReportMinInterval && interval <= statsReportMaxInterval {
+		return float64(r.readBytes) / float64(interval), float64(r.readKeys) / float64(interval)
+	}
+	return 0, 0
+}
+
 // GetWriteRate returns the write rate of the region.
 func (r *RegionInfo) GetWriteRate() (bytesRate, keysRate float64) {
 	reportInterval := r.GetInterval()
@@ -1580,6 +1590,129 @@ func (r *RegionsInfo) GetRangeHoles() [][]string {
 	return rangeHoles
 }
 
+// RegionInconsistencyType is the type of the inconsistency in the region metadata.
+type RegionInconsistencyType string
+
+const (
+	// RegionGap means a key range is not covered by any region.
+	RegionGap RegionInconsistencyType = "gap"
+	// RegionEpochMismatch means the region reported by stores has a different
+	// epoch from the one in the region tree.
+	RegionEpochMismatch RegionInconsistencyType = "epoch-mismatch"
+	// StoreRegionOverlap means a store holds the peers of two overlapping regions.
+	StoreRegionOverlap RegionInconsistencyType = "store-overlap"
+)
+
+// RegionInconsistency is an inconsistency found in the region metadata.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type RegionInconsistency struct {
+	Type      RegionInconsistencyType `json:"type"`
+	RegionIDs []uint64                `json:"region_ids,omitempty"`
+	StoreID   uint64                  `json:"store_id,omitempty"`
+	StartKey  string                  `json:"start_key"`
+	EndKey    string                  `json:"end_key"`
+}
+
+// CheckConsistency cross-checks the region tree against the regions reported
+// by each store, and returns the gaps and epoch mismatches found.
+func (r *RegionsInfo) CheckConsistency() []*RegionInconsistency {
+	// Take the snapshots under the locks, and check them without the locks
+	// to avoid blocking the heartbeats during the scan.
+	r.t.RLock()
+	regions := r.tree.scanRanges()
+	type epochPair struct{ sub, origin *RegionInfo }
+	epochs := make(map[uint64]epochPair, len(r.subRegions))
+	for id, sub := range r.subRegions {
+		pair := epochPair{sub: sub.RegionInfo}
+		if origin, ok := r.regions[id]; ok {
+			pair.origin = origin.RegionInfo
+		}
+		epochs[id] = pair
+	}
+	r.t.RUnlock()
+
+	r.st.RLock()
+	storeRegions := make(map[uint64][]*RegionInfo)
+	for _, trees := range []map[uint64]*regionTree{r.leaders, r.followers, r.learners} {
+		for storeID, tree := range trees {
+			storeRegions[storeID] = append(storeRegions[storeID], tree.scanRanges()...)
+		}
+	}
+	r.st.RUnlock()
+
+	var (
+		issues     []*RegionInconsistency
+		lastEndKey = []byte("")
+	)
+	// The region tree never holds overlapping regions, so only the gaps
+	// need to be checked.
+	for _, region := range regions {
+		startKey := region.GetStartKey()
+		if !bytes.Equal(lastEndKey, startKey) {
+			issues = append(issues, &RegionInconsistency{
+				Type:     RegionGap,
+				StartKey: HexRegionKeyStr(lastEndKey),
+				EndKey:   HexRegionKeyStr(startKey),
+			})
+		}
+		lastEndKey = region.GetEndKey()
+	}
+	if len(lastEndKey) > 0 {
+		issues = append(issues, &RegionInconsistency{
+			Type:     RegionGap,
+			StartKey: HexRegionKeyStr(lastEndKey),
+			EndKey:   "",
+		})
+	}
+
+	for id, pair := range epochs {
+		sub, origin := pair.sub, pair.origin
+		if origin != nil && sub.GetRegionEpoch().GetVersion() == origin.GetRegionEpoch().GetVersion() &&
+			sub.GetRegionEpoch().GetConfVer() == origin.GetRegionEpoch().GetConfVer() {
+			continue
+		}
+		issues = append(issues, &RegionInconsistency{
+			Type:      RegionEpochMismatch,
+			RegionIDs: []uint64{id},
+			StartKey:  HexRegionKeyStr(sub.GetStartKey()),
+			EndKey:    HexRegionKeyStr(sub.GetEndKey()),
+		})
+	}
+
+	for storeID, regions := range storeRegions {
+		sort.Slice(regions, func(i, j int) bool {
+			return bytes.Compare(regions[i].GetStartKey(), regions[j].GetStartKey()) < 0
+		})
+		// cover is the region with the largest end key scanned so far.
+		var cover *RegionInfo
+		for _, cur := range regions {
+			if cover != nil && cover.GetID() != cur.GetID() && keyLess(cur.GetStartKey(), cover.GetEndKey()) {
+				endKey := cover.GetEndKey()
+				if len(cur.GetEndKey()) > 0 && keyLess(cur.GetEndKey(), endKey) {
+					endKey = cur.GetEndKey()
+				}
+				issues = append(issues, &RegionInconsistency{
+					Type:      StoreRegionOverlap,
+					RegionIDs: []uint64{cover.GetID(), cur.GetID()},
+					StoreID:   storeID,
+					StartKey:  HexRegionKeyStr(cur.GetStartKey()),
+					EndKey:    HexRegionKeyStr(endKey),
+				})
+			}
+			if cover == nil || (len(cover.GetEndKey()) > 0 && keyLess(cover.GetEndKey(), cur.GetEndKey())) {
+				cover = cur
+			}
+		}
+	}
+	return issues
+}
+
+// keyLess returns whether the key is less than the end key, the empty end key
+// means the end of the key space.
+func keyLess(key, endKey []byte) bool {
+	return len(endKey) == 0 || bytes.Compare(key, endKey) < 0
+}
+
 // GetAverageRegionSize returns the average region approximate size.
 func (r *RegionsInfo) GetAverageRegionSize() int64 {
 	r.t.RLock()
@@ -262,7 +262,7 @@ func TestRegionRoundingFlow(t *testing.T) {
 	}
 }
 
-func TestRegionWriteRate(t *testing.T) {
+func TestRegionFlowRate(t *testing.T) {
 	re := require.New(t)
 	testCases := []struct {
 		bytes           uint64
@@ -281,10 +281,14 @@ func TestRegionWriteRate(t *testing.T) {
 		{10, 3, 500, 0, 0},
 	}
 	for _, testCase := range testCases {
-		r := NewRegionInfo(&metapb.Region{Id: 100}, nil, SetWrittenBytes(testCase.bytes), SetWrittenKeys(testCase.keys), SetReportInterval(0, testCase.interval))
+		r := NewRegionInfo(&metapb.Region{Id: 100}, nil, SetWrittenBytes(testCase.bytes), SetWrittenKeys(testCase.keys),
+			SetReadBytes(testCase.bytes), SetReadKeys(testCase.keys), SetReportInterval(0, testCase.interval))
 		bytesRate, keysRate := r.GetWriteRate()
 		re.Equal(testCase.expectBytesRate, bytesRate)
 		re.Equal(testCase.expectKeysRate, keysRate)
+		bytesRate, keysRate = r.GetReadRate()
+		re.Equal(testCase.expectBytesRate, bytesRate)
+		re.Equal(testCase.expectKeysRate, keysRate)
 	}
 }
 
@@ -536,6 +540,43 @@ func TestSetRegion(t *testing.T) {
 	re.Equal(float64(2), keysRate)
 }
 
+func TestCheckConsistency(t *testing.T) {
+	re := require.New(t)
+	regions := NewRegionsInfo()
+	for _, region := range []*RegionInfo{
+		NewTestRegionInfo(1, 1, []byte(""), []byte("a")),
+		NewTestRegionInfo(2, 1, []byte("a"), []byte("b")),
+		NewTestRegionInfo(3, 2, []byte("c"), []byte("")),
+	} {
+		origin, overlaps, rangeChanged := regions.SetRegion(region)
+		regions.UpdateSubTree(region, origin, overlaps, rangeChanged)
+	}
+	issues := regions.CheckConsistency()
+	re.Len(issues, 1)
+	re.Equal(RegionGap, issues[0].Type)
+	re.Equal(HexRegionKeyStr([]byte("b")), issues[0].StartKey)
+	re.Equal(HexRegionKeyStr([]byte("c")), issues[0].EndKey)
+
+	// Store 2 still reports a stale peer of a region which covers region 3.
+	stale := NewTestRegionInfo(4, 2, []byte("b"), []byte("d"))
+	regions.followers[2] = newRegionTree()
+	regions.followers[2].update(&regionItem{RegionInfo: stale}, false)
+	// Region 2 in the sub tree has a different epoch from the region tree.
+	regions.subRegions[2] = &regionItem{RegionInfo: regions.GetRegion(2).Clone(WithIncVersion())}
+	issues = regions.CheckConsistency()
+	re.Len(issues, 3)
+	types := make(map[RegionInconsistencyType]*RegionInconsistency)
+	for _, issue := range issues {
+		types[issue.Type] = issue
+	}
+	re.Equal([]uint64{2}, types[RegionEpochMismatch].RegionIDs)
+	storeOverlap := types[StoreRegionOverlap]
+	re.Equal(uint64(2), storeOverlap.StoreID)
+	re.Equal([]uint64{4, 3}, storeOverlap.RegionIDs)
+	re.Equal(HexRegionKeyStr([]byte("c")), storeOverlap.StartKey)
+	re.Equal(HexRegionKeyStr([]byte("d")), storeOverlap.EndKey)
+}
+
 func TestShouldRemoveFromSubTree(t *testing.T) {
 	re := require.New(t)
 	peer1 := &metapb.Peer{StoreId: uint64(1), Id: uint64(1)}
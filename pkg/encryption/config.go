@@ -15,6 +15,8 @@
 package encryption
 
 import (
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/pingcap/kvproto/pkg/encryptionpb"
@@ -42,6 +44,9 @@ type Config struct {
 	DataEncryptionMethod string `toml:"data-encryption-method" json:"data-encryption-method"`
 	// Specifies how often PD rotates data encryption key.
 	DataKeyRotationPeriod typeutil.Duration `toml:"data-key-rotation-period" json:"data-key-rotation-period"`
+	// Specifies how long the next data key is published before the scheduled rotation,
+	// so all PD members have loaded it once it is used. 0 means it is not published ahead.
+	DataKeyRotationOverlap typeutil.Duration `toml:"data-key-rotation-overlap" json:"data-key-rotation-overlap"`
 	// Specifies master key if encryption is enabled.
 	MasterKey MasterKeyConfig `toml:"master-key" json:"master-key"`
 }
@@ -67,6 +72,11 @@ func (c *Config) Adjust() error {
 			"negative data-key-rotation-period %d",
 			c.DataKeyRotationPeriod.Duration)
 	}
+	if c.DataKeyRotationOverlap.Duration < 0 || c.DataKeyRotationOverlap.Duration >= c.DataKeyRotationPeriod.Duration {
+		return errs.ErrEncryptionInvalidConfig.GenWithStack(
+			"data-key-rotation-overlap %s should be non-negative and less than data-key-rotation-period %s",
+			c.DataKeyRotationOverlap.Duration, c.DataKeyRotationPeriod.Duration)
+	}
 	if len(c.MasterKey.Type) == 0 {
 		c.MasterKey.Type = masterKeyTypePlaintext
 	} else if _, err := c.GetMasterKeyMeta(); err != nil {
@@ -75,6 +85,19 @@ func (c *Config) Adjust() error {
 	return nil
 }
 
+// ValidateFIPS checks the config for the FIPS mode. The AES-CTR methods are
+// FIPS approved, but the data keys should not be stored in plaintext once the
+// data is encrypted.
+func (c *Config) ValidateFIPS() error {
+	encrypted := len(c.DataEncryptionMethod) > 0 && c.DataEncryptionMethod != methodPlaintext
+	if encrypted && (len(c.MasterKey.Type) == 0 || c.MasterKey.Type == masterKeyTypePlaintext) {
+		return errs.ErrEncryptionInvalidConfig.GenWithStack(
+			"FIPS mode requires a kms or file master key when data encryption method is %s",
+			c.DataEncryptionMethod)
+	}
+	return nil
+}
+
 // GetMethod gets the encryption method.
 func (c *Config) GetMethod() (encryptionpb.EncryptionMethod, error) {
 	switch c.DataEncryptionMethod {
@@ -102,15 +125,12 @@ func (c *Config) GetMasterKeyMeta() (*encryptionpb.MasterKey, error) {
 			},
 		}, nil
 	case masterKeyTypeKMS:
+		kms, err := c.MasterKey.getKMSMeta()
+		if err != nil {
+			return nil, err
+		}
 		return &encryptionpb.MasterKey{
-			Backend: &encryptionpb.MasterKey_Kms{
-				Kms: &encryptionpb.MasterKeyKms{
-					Vendor:   kmsVendorAWS,
-					KeyId:    c.MasterKey.KmsKeyID,
-					Region:   c.MasterKey.KmsRegion,
-					Endpoint: c.MasterKey.KmsEndpoint,
-				},
-			},
+			Backend: &encryptionpb.MasterKey_Kms{Kms: kms},
 		}, nil
 	case masterKeyTypeFile:
 		return &encryptionpb.MasterKey{
@@ -137,12 +157,83 @@ type MasterKeyConfig struct {
 
 // MasterKeyKMSConfig defines a KMS master key config structure.
 type MasterKeyKMSConfig struct {
-	// KMS CMK key id.
+	// KMS vendor, one of "aws", "gcp", "azure" or "vault". Default is "aws".
+	KmsVendor string `toml:"vendor" json:"vendor"`
+	// KMS CMK key id. It is the resource name of the crypto key for GCP, and
+	// the key name for Azure Key Vault and Vault.
 	KmsKeyID string `toml:"key-id" json:"key-id"`
-	// KMS region of the CMK.
+	// KMS region of the CMK. Only used by AWS.
 	KmsRegion string `toml:"region" json:"region"`
-	// Custom endpoint to access KMS.
+	// Custom endpoint to access KMS. It is the vault URL for Azure Key Vault,
+	// and the address for Vault.
 	KmsEndpoint string `toml:"endpoint" json:"endpoint"`
+	// Mount path of the transit secrets engine of Vault. Default is "transit".
+	VaultMount string `toml:"vault-mount" json:"vault-mount"`
+
+	// The auth options below are not persisted with the master key, they are
+	// also used to access the previous master key of the same vendor.
+
+	// Service account key file of GCP. The GOOGLE_APPLICATION_CREDENTIALS
+	// environment variable is used if empty, then the metadata server.
+	GcpCredentialsFile string `toml:"gcp-credentials-file" json:"gcp-credentials-file"`
+	// Tenant id and client id of the Azure service principal, the
+	// AZURE_TENANT_ID and AZURE_CLIENT_ID environment variables are used if empty.
+	AzureTenantID string `toml:"azure-tenant-id" json:"azure-tenant-id"`
+	AzureClientID string `toml:"azure-client-id" json:"azure-client-id"`
+	// File of the Azure client secret. The AZURE_CLIENT_SECRET environment
+	// variable is used if empty, then the managed identity.
+	AzureClientSecretFile string `toml:"azure-client-secret-file" json:"azure-client-secret-file"`
+	// File of the Vault token. The VAULT_TOKEN environment variable is used if empty.
+	VaultTokenFile string `toml:"vault-token-file" json:"vault-token-file"`
+	// Namespace of Vault Enterprise. The VAULT_NAMESPACE environment variable is used if empty.
+	VaultNamespace string `toml:"vault-namespace" json:"vault-namespace"`
+}
+
+// getKMSMeta gets the metadata of the KMS master key.
+func (c *MasterKeyKMSConfig) getKMSMeta() (*encryptionpb.MasterKeyKms, error) {
+	meta := &encryptionpb.MasterKeyKms{
+		Vendor:   strings.ToUpper(c.KmsVendor),
+		KeyId:    c.KmsKeyID,
+		Region:   c.KmsRegion,
+		Endpoint: c.KmsEndpoint,
+	}
+	switch meta.Vendor {
+	case "", kmsVendorAWS:
+		meta.Vendor = kmsVendorAWS
+	case kmsVendorGCP:
+		if !strings.HasPrefix(meta.KeyId, "projects/") {
+			return nil, errs.ErrEncryptionInvalidConfig.GenWithStack(
+				"key-id of GCP KMS should be the resource name of the crypto key, got %s", meta.KeyId)
+		}
+	case kmsVendorAzure:
+		if len(meta.KeyId) == 0 || len(meta.Endpoint) == 0 {
+			return nil, errs.ErrEncryptionInvalidConfig.GenWithStack(
+				"key-id and endpoint are required by Azure Key Vault")
+		}
+		if len(c.AzureClientSecretFile) > 0 && (len(c.AzureTenantID) == 0 || len(c.AzureClientID) == 0) {
+			return nil, errs.ErrEncryptionInvalidConfig.GenWithStack(
+				"azure-client-secret-file requires azure-tenant-id and azure-client-id")
+		}
+	case kmsVendorVault:
+		if len(meta.KeyId) == 0 || len(meta.Endpoint) == 0 {
+			return nil, errs.ErrEncryptionInvalidConfig.GenWithStack(
+				"key-id and endpoint are required by Vault")
+		}
+		meta.Region = c.VaultMount
+		if len(meta.Region) == 0 {
+			meta.Region = defaultVaultTransitMount
+		}
+	default:
+		return nil, errs.ErrEncryptionInvalidConfig.GenWithStack(
+			"unsupported KMS vendor: %s", c.KmsVendor)
+	}
+	if meta.Vendor != kmsVendorAWS && len(meta.Endpoint) > 0 {
+		if _, err := url.ParseRequestURI(meta.Endpoint); err != nil {
+			return nil, errs.ErrEncryptionInvalidConfig.Wrap(err).GenWithStack(
+				"invalid KMS endpoint %s", meta.Endpoint)
+		}
+	}
+	return meta, nil
 }
 
 // MasterKeyFileConfig defines a file-based master key config structure.
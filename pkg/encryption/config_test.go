@@ -54,3 +54,65 @@ func TestAdjustInvalidMasterKeyType(t *testing.T) {
 	config := &Config{MasterKey: MasterKeyConfig{Type: "unknown"}}
 	re.NotNil(config.Adjust())
 }
+
+func TestAdjustInvalidRotationOverlap(t *testing.T) {
+	t.Parallel()
+	re := require.New(t)
+	config := &Config{
+		DataKeyRotationPeriod:  typeutil.NewDuration(time.Hour),
+		DataKeyRotationOverlap: typeutil.NewDuration(time.Hour),
+	}
+	re.NotNil(config.Adjust())
+	config.DataKeyRotationOverlap = typeutil.NewDuration(-time.Minute)
+	re.NotNil(config.Adjust())
+	config.DataKeyRotationOverlap = typeutil.NewDuration(10 * time.Minute)
+	re.NoError(config.Adjust())
+}
+
+func TestValidateFIPS(t *testing.T) {
+	t.Parallel()
+	re := require.New(t)
+	config := &Config{}
+	re.NoError(config.ValidateFIPS())
+	config.DataEncryptionMethod = methodAes256Ctr
+	re.Error(config.ValidateFIPS())
+	config.MasterKey.Type = masterKeyTypePlaintext
+	re.Error(config.ValidateFIPS())
+	config.MasterKey.Type = masterKeyTypeFile
+	re.NoError(config.ValidateFIPS())
+}
+
+func TestKMSVendor(t *testing.T) {
+	t.Parallel()
+	re := require.New(t)
+	config := &Config{MasterKey: MasterKeyConfig{Type: masterKeyTypeKMS}}
+	config.MasterKey.KmsKeyID = "key"
+	re.NoError(config.Adjust())
+	meta, err := config.GetMasterKeyMeta()
+	re.NoError(err)
+	re.Equal(kmsVendorAWS, meta.GetKms().Vendor)
+
+	config.MasterKey.KmsVendor = "gcp"
+	re.NotNil(config.Adjust())
+	config.MasterKey.KmsKeyID = "projects/p/locations/global/keyRings/r/cryptoKeys/k"
+	re.NoError(config.Adjust())
+
+	config.MasterKey.KmsVendor = "azure"
+	config.MasterKey.KmsKeyID = "key"
+	re.NotNil(config.Adjust())
+	config.MasterKey.KmsEndpoint = "https://pd.vault.azure.net"
+	re.NoError(config.Adjust())
+	config.MasterKey.AzureClientSecretFile = "/path/to/secret"
+	re.NotNil(config.Adjust())
+
+	config.MasterKey.KmsVendor = "vault"
+	config.MasterKey.KmsEndpoint = "http://127.0.0.1:8200"
+	re.NoError(config.Adjust())
+	meta, err = config.GetMasterKeyMeta()
+	re.NoError(err)
+	re.Equal(kmsVendorVault, meta.GetKms().Vendor)
+	re.Equal(defaultVaultTransitMount, meta.GetKms().Region)
+
+	config.MasterKey.KmsVendor = "unknown"
+	re.NotNil(config.Adjust())
+}
@@ -59,8 +59,12 @@ type Manager struct {
 	method encryptionpb.EncryptionMethod
 	// Time interval between data key rotation.
 	dataKeyRotationPeriod time.Duration
+	// How long the next data key is published before it becomes current.
+	dataKeyRotationOverlap time.Duration
 	// Metadata defines the master key to use.
 	masterKeyMeta *encryptionpb.MasterKey
+	// Auth options to access the KMS of the master key.
+	kmsAuth MasterKeyKMSConfig
 	// Helper methods. Tests can mock the helper to inject dependencies.
 	helper keyManagerHelper
 	// Mutex for updating keys. Used for both of LoadKeys() and rotateKeyIfNeeded().
@@ -72,6 +76,8 @@ type Manager struct {
 		leadership *election.Leadership
 		// Revision of keys loaded from etcd. Guarded by mu.
 		keysRevision int64
+		// Recent rotations by this PD node from the oldest. Guarded by mu.
+		rotations []*KeyRotation
 	}
 	// List of all encryption keys and current encryption key id,
 	// with type *encryptionpb.KeyDictionary. The content is read-only.
@@ -170,7 +176,12 @@ func NewManager(
 	etcdClient *clientv3.Client,
 	config *Config,
 ) (*Manager, error) {
-	return newKeyManagerImpl(etcdClient, config, defaultKeyManagerHelper())
+	helper := defaultKeyManagerHelper()
+	kmsAuth := config.MasterKey.MasterKeyKMSConfig
+	helper.newMasterKey = func(meta *encryptionpb.MasterKey, ciphertextKey []byte) (*MasterKey, error) {
+		return newMasterKey(meta, ciphertextKey, &kmsAuth)
+	}
+	return newKeyManagerImpl(etcdClient, config, helper)
 }
 
 // newKeyManager creates a new key manager, and allow tests to set a mocked keyManagerHelper.
@@ -188,11 +199,13 @@ func newKeyManagerImpl(
 		return nil, err
 	}
 	m := &Manager{
-		etcdClient:            etcdClient,
-		method:                method,
-		dataKeyRotationPeriod: config.DataKeyRotationPeriod.Duration,
-		masterKeyMeta:         masterKeyMeta,
-		helper:                helper,
+		etcdClient:             etcdClient,
+		method:                 method,
+		dataKeyRotationPeriod:  config.DataKeyRotationPeriod.Duration,
+		dataKeyRotationOverlap: config.DataKeyRotationOverlap.Duration,
+		masterKeyMeta:          masterKeyMeta,
+		kmsAuth:                config.MasterKey.MasterKeyKMSConfig,
+		helper:                 helper,
 	}
 	// Load encryption keys from storage.
 	err = m.loadKeys()
@@ -283,6 +296,7 @@ func (m *Manager) StartBackgroundLoop(ctx context.Context) {
 func (m *Manager) checkOnTick() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	m.updateKeyAgeMetric()
 	// Check data key rotation in case we are the PD leader.
 	err := m.rotateKeyIfNeeded(false /*forceUpdate*/)
 	if err != nil {
@@ -357,16 +371,26 @@ func (m *Manager) loadKeys() error {
 // Otherwise re-save all keys to finish master key rotation if forceUpdate = true.
 // Require mu lock to be held.
 func (m *Manager) rotateKeyIfNeeded(forceUpdate bool) error {
+	_, err := m.rotateKeyImpl(forceUpdate, false /*forceRotate*/)
+	return err
+}
+
+// rotateKeyImpl rotates the data key if it is needed, or immediately if
+// forceRotate = true. The next key is published ahead of the scheduled rotation
+// by the overlap window, so all the PD members have loaded it once it becomes
+// current. It returns the rotation, nil if the key is not rotated.
+// Require mu lock to be held.
+func (m *Manager) rotateKeyImpl(forceUpdate, forceRotate bool) (*KeyRotation, error) {
 	if m.mu.leadership == nil || !m.mu.leadership.Check() {
 		// We are not leader.
 		m.mu.leadership = nil
-		return nil
+		return nil, nil
 	}
 	m.helper.eventAfterLeaderCheckSuccess()
 	// Reload encryption keys in case we are not up-to-date.
 	keys, err := m.loadKeysImpl()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// Initialize if empty.
 	if keys == nil {
@@ -377,65 +401,108 @@ func (m *Manager) rotateKeyIfNeeded(forceUpdate bool) error {
 	if keys.Keys == nil {
 		keys.Keys = make(map[uint64]*encryptionpb.DataKey)
 	}
+	now := m.helper.now()
 	needUpdate := forceUpdate
+	var rotation *KeyRotation
 	if m.method == encryptionpb.EncryptionMethod_PLAINTEXT {
 		if keys.CurrentKeyId == disableEncryptionKeyID {
 			// Encryption is not enabled.
-			return nil
+			return nil, nil
 		}
 		keys.CurrentKeyId = disableEncryptionKeyID
 		needUpdate = true
 	} else {
-		needRotate := false
+		var (
+			reason    string
+			expiredAt time.Time
+		)
 		if keys.CurrentKeyId == disableEncryptionKeyID {
-			needRotate = true
+			reason = RotationEnabled
 		} else {
 			currentKey := keys.Keys[keys.CurrentKeyId]
 			if currentKey == nil {
-				return errs.ErrEncryptionCurrentKeyNotFound.GenWithStack("keyId = %d", keys.CurrentKeyId)
+				return nil, errs.ErrEncryptionCurrentKeyNotFound.GenWithStack("keyId = %d", keys.CurrentKeyId)
 			}
-			if currentKey.Method != m.method || currentKey.WasExposed ||
-				time.Unix(int64(currentKey.CreationTime), 0).
-					Add(m.dataKeyRotationPeriod).Before(m.helper.now()) {
-				needRotate = true
+			expiredAt = time.Unix(int64(currentKey.CreationTime), 0).Add(m.dataKeyRotationPeriod)
+			switch {
+			case forceRotate:
+				reason = RotationManual
+			case currentKey.Method != m.method:
+				reason = RotationMethodChanged
+			case currentKey.WasExposed:
+				reason = RotationExposed
+			case expiredAt.Before(now):
+				reason = RotationScheduled
 			}
 		}
-		if needRotate {
-			rotated := false
-			for attempt := 0; attempt < keyRotationRetryLimit; attempt += 1 {
-				keyID, key, err := NewDataKey(m.method, uint64(m.helper.now().Unix()))
+		nextKeyID, nextKey := getNextKey(keys, expiredAt)
+		switch {
+		case len(reason) > 0:
+			rotation = &KeyRotation{
+				Time:          now.Unix(),
+				Reason:        reason,
+				PreviousKeyID: keys.CurrentKeyId,
+			}
+			if reason == RotationScheduled && nextKey != nil && nextKey.Method == m.method {
+				// The next key has been published within the overlap window.
+				keys.CurrentKeyId = nextKeyID
+			} else {
+				// The unused next key is left in the keys, which is harmless.
+				keyID, err := m.addDataKey(keys, now)
 				if err != nil {
-					return nil
-				}
-				if keys.Keys[keyID] == nil {
-					keys.Keys[keyID] = key
-					keys.CurrentKeyId = keyID
-					rotated = true
-					log.Info("ready to create or rotate data encryption key", zap.Uint64("keyID", keyID))
-					break
+					return nil, err
 				}
-				// Duplicated key id. retry.
+				keys.CurrentKeyId = keyID
 			}
-			if !rotated {
-				log.Warn("failed to rotate keys. maximum attempts reached")
-				return errs.ErrEncryptionRotateDataKey.GenWithStack("maximum attempts reached")
+			rotation.KeyID = keys.CurrentKeyId
+			log.Info("ready to create or rotate data encryption key",
+				zap.Uint64("keyID", keys.CurrentKeyId),
+				zap.String("reason", reason))
+			needUpdate = true
+		case m.dataKeyRotationOverlap > 0 && nextKey == nil && !now.Before(expiredAt.Add(-m.dataKeyRotationOverlap)):
+			keyID, err := m.addDataKey(keys, expiredAt)
+			if err != nil {
+				return nil, err
 			}
+			log.Info("ready to publish the next data encryption key",
+				zap.Uint64("keyID", keyID),
+				zap.Time("rotate-at", expiredAt))
 			needUpdate = true
 		}
 	}
 	if !needUpdate {
-		return nil
+		return nil, nil
 	}
 	// Store updated keys in etcd.
 	err = saveKeys(m.mu.leadership, m.masterKeyMeta, keys, m.helper)
 	if err != nil {
 		m.helper.eventSaveKeysFailure()
 		log.Error("failed to save keys", errs.ZapError(err))
-		return err
+		return nil, err
+	}
+	if rotation != nil {
+		m.recordRotation(rotation)
 	}
 	// Reload keys.
 	_, err = m.loadKeysImpl()
-	return err
+	return rotation, err
+}
+
+// addDataKey generates a data key with a unique key id and adds it to the keys.
+func (m *Manager) addDataKey(keys *encryptionpb.KeyDictionary, creationTime time.Time) (uint64, error) {
+	for attempt := 0; attempt < keyRotationRetryLimit; attempt += 1 {
+		keyID, key, err := NewDataKey(m.method, uint64(creationTime.Unix()))
+		if err != nil {
+			return 0, err
+		}
+		if keys.Keys[keyID] == nil {
+			keys.Keys[keyID] = key
+			return keyID, nil
+		}
+		// Duplicated key id. retry.
+	}
+	log.Warn("failed to rotate keys. maximum attempts reached")
+	return 0, errs.ErrEncryptionRotateDataKey.GenWithStack("maximum attempts reached")
 }
 
 func (m *Manager) getKeys() *encryptionpb.KeyDictionary {
@@ -1112,6 +1112,116 @@ func TestKeyRotationConflict(t *testing.T) {
 	re.True(proto.Equal(storedKeys, keys))
 }
 
+func TestKeyRotationWithOverlap(t *testing.T) {
+	re := require.New(t)
+	// Initialize.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := newTestEtcd(t, re)
+	keyFile := newTestKeyFile(t, re)
+	leadership := newTestLeader(re, client)
+	// Setup helper
+	helper := defaultKeyManagerHelper()
+	// Mock time
+	mockNow := int64(1601679533)
+	helper.now = func() time.Time { return time.Unix(atomic.LoadInt64(&mockNow), 0) }
+	mockTick := make(chan time.Time)
+	helper.tick = func(ticker *time.Ticker) <-chan time.Time { return mockTick }
+	// Listen on watcher event
+	reloadEvent := make(chan struct{}, 10)
+	helper.eventAfterReloadByWatcher = func() {
+		var e struct{}
+		reloadEvent <- e
+	}
+	// Listen on ticker event
+	tickerEvent := make(chan struct{}, 10)
+	helper.eventAfterTicker = func() {
+		var e struct{}
+		tickerEvent <- e
+	}
+	// Update keys in etcd
+	masterKeyMeta := newTestMasterKey(keyFile)
+	keys := &encryptionpb.KeyDictionary{
+		CurrentKeyId: 123,
+		Keys: map[uint64]*encryptionpb.DataKey{
+			123: {
+				Key:          getTestDataKey(),
+				Method:       encryptionpb.EncryptionMethod_AES128_CTR,
+				CreationTime: uint64(1601679533),
+				WasExposed:   false,
+			},
+		},
+	}
+	err := saveKeys(leadership, masterKeyMeta, keys, defaultKeyManagerHelper())
+	re.NoError(err)
+	// Config with 100s rotation period and 30s overlap.
+	config := &Config{
+		DataEncryptionMethod:   "aes128-ctr",
+		DataKeyRotationPeriod:  typeutil.NewDuration(100 * time.Second),
+		DataKeyRotationOverlap: typeutil.NewDuration(30 * time.Second),
+		MasterKey: MasterKeyConfig{
+			Type: "file",
+			MasterKeyFileConfig: MasterKeyFileConfig{
+				FilePath: keyFile,
+			},
+		},
+	}
+	err = config.Adjust()
+	re.NoError(err)
+	// Create the key manager.
+	m, err := newKeyManagerImpl(client, config, helper)
+	re.NoError(err)
+	go m.StartBackgroundLoop(ctx)
+	err = m.SetLeadership(leadership)
+	re.NoError(err)
+	// The next key is not published before the overlap window.
+	re.True(proto.Equal(m.keys.Load().(*encryptionpb.KeyDictionary), keys))
+	// Advance time into the overlap window and trigger ticker.
+	atomic.AddInt64(&mockNow, int64(75))
+	mockTick <- time.Unix(atomic.LoadInt64(&mockNow), 0)
+	<-tickerEvent
+	<-reloadEvent
+	// The next key is published, but the current key is not rotated.
+	status := m.GetRotationStatus()
+	re.Equal(uint64(123), status.CurrentKeyID)
+	re.NotZero(status.NextKeyID)
+	re.Equal(int64(1601679533+100), status.NextRotationTime)
+	re.Len(status.Keys, 2)
+	re.Equal(DataKeyNext, status.Keys[0].State)
+	re.Equal(DataKeyCurrent, status.Keys[1].State)
+	re.Empty(status.Rotations)
+	nextKeyID := status.NextKeyID
+	// Advance time to the scheduled rotation.
+	atomic.AddInt64(&mockNow, int64(26))
+	mockTick <- time.Unix(atomic.LoadInt64(&mockNow), 0)
+	<-tickerEvent
+	<-reloadEvent
+	// The published key becomes current.
+	currentKeyID, currentKey, err := m.GetCurrentKey()
+	re.NoError(err)
+	re.Equal(nextKeyID, currentKeyID)
+	re.Equal(uint64(1601679533+100), currentKey.CreationTime)
+	status = m.GetRotationStatus()
+	re.Zero(status.NextKeyID)
+	re.Len(status.Rotations, 1)
+	re.Equal(&KeyRotation{
+		Time:          mockNow,
+		Reason:        RotationScheduled,
+		PreviousKeyID: 123,
+		KeyID:         nextKeyID,
+	}, status.Rotations[0])
+	// Rotate the key manually.
+	rotation, err := m.RotateKey()
+	re.NoError(err)
+	re.Equal(RotationManual, rotation.Reason)
+	re.Equal(nextKeyID, rotation.PreviousKeyID)
+	currentKeyID, _, err = m.GetCurrentKey()
+	re.NoError(err)
+	re.Equal(rotation.KeyID, currentKeyID)
+	re.Len(m.GetRotationStatus().Rotations, 2)
+	re.Len(m.GetRotationStatus().Keys, 3)
+}
+
 func newTestMasterKey(keyFile string) *encryptionpb.MasterKey {
 	return &encryptionpb.MasterKey{
 		Backend: &encryptionpb.MasterKey_File{
@@ -15,6 +15,8 @@
 package encryption
 
 import (
+	"crypto/rand"
+	"io"
 	"os"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -28,8 +30,11 @@ import (
 )
 
 const (
-	// We only support AWS KMS right now.
-	kmsVendorAWS = "AWS"
+	// The supported KMS vendors.
+	kmsVendorAWS   = "AWS"
+	kmsVendorGCP   = "GCP"
+	kmsVendorAzure = "AZURE"
+	kmsVendorVault = "VAULT"
 
 	// K8S IAM related environment variables.
 	envAwsRoleArn = "AWS_ROLE_ARN"
@@ -38,16 +43,95 @@ const (
 	envAwsRoleSessionName      = "AWS_ROLE_SESSION_NAME"
 )
 
+// kmsProvider generates and decrypts the master keys by a KMS.
+type kmsProvider interface {
+	// generateKey generates a master key, and returns it in both plaintext and ciphertext.
+	generateKey() (plaintext, ciphertext []byte, err error)
+	// decryptKey decrypts the ciphertext of a master key.
+	decryptKey(ciphertext []byte) ([]byte, error)
+	// healthCheck checks whether the KMS and the key are accessible.
+	healthCheck() error
+}
+
+// newKMSProvider creates the provider of the KMS vendor. The auth options are
+// from the config of PD, which may be nil to use the environment only.
+func newKMSProvider(config *encryptionpb.MasterKeyKms, auth *MasterKeyKMSConfig) (kmsProvider, error) {
+	if auth == nil {
+		auth = &MasterKeyKMSConfig{}
+	}
+	switch config.Vendor {
+	case kmsVendorAWS:
+		return newAwsKMS(config)
+	case kmsVendorGCP:
+		return newGcpKMS(config, auth)
+	case kmsVendorAzure:
+		return newAzureKMS(config, auth)
+	case kmsVendorVault:
+		return newVaultKMS(config, auth)
+	default:
+		return nil, errs.ErrEncryptionKMS.GenWithStack("unsupported KMS vendor: %s", config.Vendor)
+	}
+}
+
 func newMasterKeyFromKMS(
 	config *encryptionpb.MasterKeyKms,
 	ciphertextKey []byte,
-) (masterKey *MasterKey, err error) {
+	auth *MasterKeyKMSConfig,
+) (*MasterKey, error) {
 	if config == nil {
-		return nil, errs.ErrEncryptionNewMasterKey.GenWithStack("missing master key file config")
+		return nil, errs.ErrEncryptionNewMasterKey.GenWithStack("missing master key kms config")
 	}
-	if config.Vendor != kmsVendorAWS {
-		return nil, errs.ErrEncryptionKMS.GenWithStack("unsupported KMS vendor: %s", config.Vendor)
+	provider, err := newKMSProvider(config, auth)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertextKey) == 0 {
+		// Create a new data key.
+		key, ciphertext, err := provider.generateKey()
+		if err != nil {
+			return nil, err
+		}
+		if len(key) != masterKeyLength {
+			return nil, errs.ErrEncryptionKMS.GenWithStack(
+				"unexpected data key length generated from %s KMS, expected %d vs actual %d",
+				config.Vendor, masterKeyLength, len(key))
+		}
+		return &MasterKey{key: key, ciphertextKey: ciphertext}, nil
+	}
+	// Decrypt existing data key.
+	key, err := provider.decryptKey(ciphertextKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != masterKeyLength {
+		return nil, errs.ErrEncryptionKMS.GenWithStack(
+			"unexpected data key length decrypted from %s KMS, expected %d vs actual %d",
+			config.Vendor, masterKeyLength, len(key))
 	}
+	return &MasterKey{key: key, ciphertextKey: ciphertextKey}, nil
+}
+
+// generateKeyByEncrypt generates a master key locally, and encrypts it by the
+// KMS, for the vendors which don't generate the data keys.
+func generateKeyByEncrypt(encrypt func([]byte) ([]byte, error)) (plaintext, ciphertext []byte, err error) {
+	plaintext = make([]byte, masterKeyLength)
+	if _, err := io.ReadFull(rand.Reader, plaintext); err != nil {
+		return nil, nil, errs.ErrEncryptionKMS.Wrap(err).GenWithStack("fail to generate master key")
+	}
+	ciphertext, err = encrypt(plaintext)
+	if err != nil {
+		return nil, nil, err
+	}
+	return plaintext, ciphertext, nil
+}
+
+// awsKMS is the master key provider of AWS KMS.
+type awsKMS struct {
+	client *kms.KMS
+	keyID  string
+}
+
+func newAwsKMS(config *encryptionpb.MasterKeyKms) (*awsKMS, error) {
 	credentials, err := newAwsCredentials()
 	if err != nil {
 		return nil, err
@@ -61,48 +145,43 @@ func newMasterKeyFromKMS(
 		return nil, errs.ErrEncryptionKMS.Wrap(err).GenWithStack(
 			"fail to create AWS session to access KMS CMK")
 	}
-	client := kms.New(session)
-	if len(ciphertextKey) == 0 {
-		numberOfBytes := int64(masterKeyLength)
-		// Create a new data key.
-		output, err := client.GenerateDataKey(&kms.GenerateDataKeyInput{
-			KeyId:         &config.KeyId,
-			NumberOfBytes: &numberOfBytes,
-		})
-		if err != nil {
-			return nil, errs.ErrEncryptionKMS.Wrap(err).GenWithStack(
-				"fail to generate data key from AWS KMS")
-		}
-		if len(output.Plaintext) != masterKeyLength {
-			return nil, errs.ErrEncryptionKMS.GenWithStack(
-				"unexpected data key length generated from AWS KMS, expectd %d vs actual %d",
-				masterKeyLength, len(output.Plaintext))
-		}
-		masterKey = &MasterKey{
-			key:           output.Plaintext,
-			ciphertextKey: output.CiphertextBlob,
-		}
-	} else {
-		// Decrypt existing data key.
-		output, err := client.Decrypt(&kms.DecryptInput{
-			KeyId:          &config.KeyId,
-			CiphertextBlob: ciphertextKey,
-		})
-		if err != nil {
-			return nil, errs.ErrEncryptionKMS.Wrap(err).GenWithStack(
-				"fail to decrypt data key from AWS KMS")
-		}
-		if len(output.Plaintext) != masterKeyLength {
-			return nil, errs.ErrEncryptionKMS.GenWithStack(
-				"unexpected data key length decrypted from AWS KMS, expected %d vs actual %d",
-				masterKeyLength, len(output.Plaintext))
-		}
-		masterKey = &MasterKey{
-			key:           output.Plaintext,
-			ciphertextKey: ciphertextKey,
-		}
+	return &awsKMS{client: kms.New(session), keyID: config.KeyId}, nil
+}
+
+func (k *awsKMS) generateKey() (plaintext, ciphertext []byte, err error) {
+	numberOfBytes := int64(masterKeyLength)
+	output, err := k.client.GenerateDataKey(&kms.GenerateDataKeyInput{
+		KeyId:         &k.keyID,
+		NumberOfBytes: &numberOfBytes,
+	})
+	if err != nil {
+		return nil, nil, errs.ErrEncryptionKMS.Wrap(err).GenWithStack(
+			"fail to generate data key from AWS KMS")
+	}
+	return output.Plaintext, output.CiphertextBlob, nil
+}
+
+func (k *awsKMS) decryptKey(ciphertext []byte) ([]byte, error) {
+	output, err := k.client.Decrypt(&kms.DecryptInput{
+		KeyId:          &k.keyID,
+		CiphertextBlob: ciphertext,
+	})
+	if err != nil {
+		return nil, errs.ErrEncryptionKMS.Wrap(err).GenWithStack(
+			"fail to decrypt data key from AWS KMS")
+	}
+	return output.Plaintext, nil
+}
+
+func (k *awsKMS) healthCheck() error {
+	output, err := k.client.DescribeKey(&kms.DescribeKeyInput{KeyId: &k.keyID})
+	if err != nil {
+		return errs.ErrEncryptionKMS.Wrap(err).GenWithStack("fail to describe AWS KMS CMK")
+	}
+	if output.KeyMetadata != nil && output.KeyMetadata.Enabled != nil && !*output.KeyMetadata.Enabled {
+		return errs.ErrEncryptionKMS.GenWithStack("AWS KMS CMK %s is disabled", k.keyID)
 	}
-	return
+	return nil
 }
 
 func newAwsCredentials() (*credentials.Credentials, error) {
@@ -0,0 +1,200 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/pingcap/kvproto/pkg/encryptionpb"
+	"github.com/tikv/pd/pkg/errs"
+)
+
+const (
+	azureKeyVaultAPIVersion = "7.3"
+	azureKeyVaultResource   = "https://vault.azure.net"
+	azureWrapAlgorithm      = "RSA-OAEP-256"
+	// azureIMDSTokenURL is the token endpoint of the managed identity on Azure VMs.
+	azureIMDSTokenURL = "http://169.254.169.254/metadata/identity/oauth2/token"
+
+	// The standard environment variables of the Azure service principal.
+	envAzureTenantID     = "AZURE_TENANT_ID"
+	envAzureClientID     = "AZURE_CLIENT_ID"
+	envAzureClientSecret = "AZURE_CLIENT_SECRET"
+)
+
+// azureWrappedKey is the ciphertext of a master key wrapped by Azure Key Vault.
+// The id of the key version is kept to unwrap it after the key is rotated.
+type azureWrappedKey struct {
+	Kid   string `json:"kid"`
+	Value string `json:"value"`
+}
+
+// azureKMS is the master key provider of Azure Key Vault. The endpoint is the
+// URL of the vault, and the key id is the name of an RSA key, optionally
+// followed by "/{version}".
+type azureKMS struct {
+	vaultURL string
+	keyID    string
+	tokens   *tokenSource
+}
+
+func newAzureKMS(config *encryptionpb.MasterKeyKms, auth *MasterKeyKMSConfig) (*azureKMS, error) {
+	if len(config.Endpoint) == 0 || len(config.KeyId) == 0 {
+		return nil, errs.ErrEncryptionKMS.GenWithStack("Azure Key Vault requires both the vault URL and the key name")
+	}
+	tenantID := firstNonEmpty(auth.AzureTenantID, os.Getenv(envAzureTenantID))
+	clientID := firstNonEmpty(auth.AzureClientID, os.Getenv(envAzureClientID))
+	clientSecret := os.Getenv(envAzureClientSecret)
+	if len(auth.AzureClientSecretFile) > 0 {
+		data, err := os.ReadFile(auth.AzureClientSecretFile)
+		if err != nil {
+			return nil, errs.ErrEncryptionKMS.Wrap(err).GenWithStack(
+				"fail to read Azure client secret file %s", auth.AzureClientSecretFile)
+		}
+		clientSecret = strings.TrimSpace(string(data))
+	}
+	k := &azureKMS{
+		vaultURL: strings.TrimSuffix(config.Endpoint, "/"),
+		keyID:    strings.Trim(config.KeyId, "/"),
+	}
+	if len(clientSecret) > 0 {
+		if len(tenantID) == 0 || len(clientID) == 0 {
+			return nil, errs.ErrEncryptionKMS.GenWithStack("Azure client secret requires both the tenant id and the client id")
+		}
+		k.tokens = newTokenSource(func() (*oauthToken, error) {
+			return fetchOAuthToken("https://login.microsoftonline.com/"+url.PathEscape(tenantID)+"/oauth2/v2.0/token", url.Values{
+				"grant_type":    {"client_credentials"},
+				"client_id":     {clientID},
+				"client_secret": {clientSecret},
+				"scope":         {azureKeyVaultResource + "/.default"},
+			})
+		})
+	} else {
+		// Use the managed identity, the client id selects a user-assigned one.
+		k.tokens = newTokenSource(func() (*oauthToken, error) {
+			return fetchAzureManagedIdentityToken(clientID)
+		})
+	}
+	return k, nil
+}
+
+func (k *azureKMS) keyURL() string {
+	return k.vaultURL + "/keys/" + k.keyID
+}
+
+func (k *azureKMS) generateKey() (plaintext, ciphertext []byte, err error) {
+	return generateKeyByEncrypt(k.wrapKey)
+}
+
+func (k *azureKMS) wrapKey(plaintext []byte) ([]byte, error) {
+	token, err := k.tokens.accessToken()
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]string{
+		"alg":   azureWrapAlgorithm,
+		"value": base64.RawURLEncoding.EncodeToString(plaintext),
+	}
+	resp := &azureWrappedKey{}
+	if err := doKMSRequest(http.MethodPost, k.keyURL()+"/wrapkey?api-version="+azureKeyVaultAPIVersion,
+		bearerHeader(token), body, resp); err != nil {
+		return nil, errs.ErrEncryptionKMS.Wrap(err).GenWithStack("fail to wrap master key by Azure Key Vault")
+	}
+	ciphertext, err := json.Marshal(resp)
+	if err != nil {
+		return nil, errs.ErrJSONMarshal.Wrap(err).GenWithStackByCause()
+	}
+	return ciphertext, nil
+}
+
+func (k *azureKMS) decryptKey(ciphertext []byte) ([]byte, error) {
+	wrapped := &azureWrappedKey{}
+	if err := json.Unmarshal(ciphertext, wrapped); err != nil {
+		return nil, errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+	}
+	// The access token should only be sent to the configured vault.
+	if !strings.HasPrefix(wrapped.Kid, k.keyURL()+"/") && wrapped.Kid != k.keyURL() {
+		return nil, errs.ErrEncryptionKMS.GenWithStack("master key is wrapped by unexpected Azure key %s", wrapped.Kid)
+	}
+	token, err := k.tokens.accessToken()
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]string{"alg": azureWrapAlgorithm, "value": wrapped.Value}
+	var resp struct {
+		Value string `json:"value"`
+	}
+	if err := doKMSRequest(http.MethodPost, wrapped.Kid+"/unwrapkey?api-version="+azureKeyVaultAPIVersion,
+		bearerHeader(token), body, &resp); err != nil {
+		return nil, errs.ErrEncryptionKMS.Wrap(err).GenWithStack("fail to unwrap master key by Azure Key Vault")
+	}
+	plaintext, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(resp.Value, "="))
+	if err != nil {
+		return nil, errs.ErrEncryptionKMS.Wrap(err).GenWithStack("fail to decode master key unwrapped by Azure Key Vault")
+	}
+	return plaintext, nil
+}
+
+func (k *azureKMS) healthCheck() error {
+	token, err := k.tokens.accessToken()
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Attributes struct {
+			Enabled bool `json:"enabled"`
+		} `json:"attributes"`
+	}
+	if err := doKMSRequest(http.MethodGet, k.keyURL()+"?api-version="+azureKeyVaultAPIVersion,
+		bearerHeader(token), nil, &resp); err != nil {
+		return errs.ErrEncryptionKMS.Wrap(err).GenWithStack("fail to get Azure Key Vault key")
+	}
+	if !resp.Attributes.Enabled {
+		return errs.ErrEncryptionKMS.GenWithStack("Azure Key Vault key %s is disabled", k.keyID)
+	}
+	return nil
+}
+
+// fetchAzureManagedIdentityToken gets the access token of the managed identity
+// from the instance metadata service.
+func fetchAzureManagedIdentityToken(clientID string) (*oauthToken, error) {
+	query := url.Values{
+		"api-version": {"2018-02-01"},
+		"resource":    {azureKeyVaultResource},
+	}
+	if len(clientID) > 0 {
+		query.Set("client_id", clientID)
+	}
+	token := &oauthToken{}
+	header := http.Header{"Metadata": []string{"true"}}
+	if err := doKMSRequest(http.MethodGet, azureIMDSTokenURL+"?"+query.Encode(), header, nil, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if len(v) > 0 {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,210 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/encryptionpb"
+	"github.com/tikv/pd/pkg/errs"
+)
+
+const (
+	defaultGcpKMSEndpoint = "https://cloudkms.googleapis.com"
+	gcpKMSScope           = "https://www.googleapis.com/auth/cloudkms"
+	// gcpMetadataTokenURL is the token endpoint of the metadata server on GCE and GKE.
+	gcpMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+	// envGcpCredentials is the standard environment variable of the service account key file.
+	envGcpCredentials = "GOOGLE_APPLICATION_CREDENTIALS"
+	// gcpJWTLifetime is the lifetime of the JWT exchanged for the access token.
+	gcpJWTLifetime = time.Hour
+)
+
+// gcpKMS is the master key provider of Google Cloud KMS. The key id is the
+// resource name of the crypto key, i.e.
+// "projects/{project}/locations/{location}/keyRings/{key-ring}/cryptoKeys/{key}".
+type gcpKMS struct {
+	endpoint string
+	keyName  string
+	tokens   *tokenSource
+}
+
+func newGcpKMS(config *encryptionpb.MasterKeyKms, auth *MasterKeyKMSConfig) (*gcpKMS, error) {
+	if !strings.HasPrefix(config.KeyId, "projects/") {
+		return nil, errs.ErrEncryptionKMS.GenWithStack("invalid GCP KMS key name: %s", config.KeyId)
+	}
+	endpoint := config.Endpoint
+	if len(endpoint) == 0 {
+		endpoint = defaultGcpKMSEndpoint
+	}
+	credentialsFile := auth.GcpCredentialsFile
+	if len(credentialsFile) == 0 {
+		credentialsFile = os.Getenv(envGcpCredentials)
+	}
+	k := &gcpKMS{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		keyName:  config.KeyId,
+	}
+	if len(credentialsFile) > 0 {
+		key, err := loadGcpServiceAccountKey(credentialsFile)
+		if err != nil {
+			return nil, err
+		}
+		k.tokens = newTokenSource(key.fetchToken)
+	} else {
+		k.tokens = newTokenSource(fetchGcpMetadataToken)
+	}
+	return k, nil
+}
+
+func (k *gcpKMS) keyURL() string {
+	return k.endpoint + "/v1/" + k.keyName
+}
+
+func (k *gcpKMS) generateKey() (plaintext, ciphertext []byte, err error) {
+	return generateKeyByEncrypt(k.encrypt)
+}
+
+func (k *gcpKMS) encrypt(plaintext []byte) ([]byte, error) {
+	token, err := k.tokens.accessToken()
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Ciphertext []byte `json:"ciphertext"`
+	}
+	body := map[string][]byte{"plaintext": plaintext}
+	if err := doKMSRequest(http.MethodPost, k.keyURL()+":encrypt", bearerHeader(token), body, &resp); err != nil {
+		return nil, errs.ErrEncryptionKMS.Wrap(err).GenWithStack("fail to encrypt master key by GCP KMS")
+	}
+	return resp.Ciphertext, nil
+}
+
+func (k *gcpKMS) decryptKey(ciphertext []byte) ([]byte, error) {
+	token, err := k.tokens.accessToken()
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Plaintext []byte `json:"plaintext"`
+	}
+	body := map[string][]byte{"ciphertext": ciphertext}
+	if err := doKMSRequest(http.MethodPost, k.keyURL()+":decrypt", bearerHeader(token), body, &resp); err != nil {
+		return nil, errs.ErrEncryptionKMS.Wrap(err).GenWithStack("fail to decrypt master key by GCP KMS")
+	}
+	return resp.Plaintext, nil
+}
+
+func (k *gcpKMS) healthCheck() error {
+	token, err := k.tokens.accessToken()
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Primary *struct {
+			State string `json:"state"`
+		} `json:"primary"`
+	}
+	if err := doKMSRequest(http.MethodGet, k.keyURL(), bearerHeader(token), nil, &resp); err != nil {
+		return errs.ErrEncryptionKMS.Wrap(err).GenWithStack("fail to get GCP KMS key")
+	}
+	if resp.Primary == nil || resp.Primary.State != "ENABLED" {
+		return errs.ErrEncryptionKMS.GenWithStack("primary version of GCP KMS key %s is not enabled", k.keyName)
+	}
+	return nil
+}
+
+// gcpServiceAccountKey is the key file of a GCP service account.
+type gcpServiceAccountKey struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+
+	rsaKey *rsa.PrivateKey
+}
+
+func loadGcpServiceAccountKey(path string) (*gcpServiceAccountKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errs.ErrEncryptionKMS.Wrap(err).GenWithStack("fail to read GCP credentials file %s", path)
+	}
+	key := &gcpServiceAccountKey{}
+	if err := json.Unmarshal(data, key); err != nil {
+		return nil, errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+	}
+	if len(key.ClientEmail) == 0 || len(key.TokenURI) == 0 {
+		return nil, errs.ErrEncryptionKMS.GenWithStack("GCP credentials file %s is not a service account key", path)
+	}
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return nil, errs.ErrEncryptionKMS.GenWithStack("no private key in GCP credentials file %s", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errs.ErrEncryptionKMS.Wrap(err).GenWithStack("fail to parse private key in GCP credentials file %s", path)
+	}
+	rsaKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errs.ErrEncryptionKMS.GenWithStack("private key in GCP credentials file %s is not RSA", path)
+	}
+	key.rsaKey = rsaKey
+	return key, nil
+}
+
+// fetchToken exchanges a JWT signed by the service account for an access token.
+func (key *gcpServiceAccountKey) fetchToken() (*oauthToken, error) {
+	now := time.Now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   key.ClientEmail,
+		"scope": gcpKMSScope,
+		"aud":   key.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(gcpJWTLifetime).Unix(),
+	})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key.rsaKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return nil, errs.ErrEncryptionKMS.Wrap(err).GenWithStack("fail to sign JWT of GCP service account")
+	}
+	return fetchOAuthToken(key.TokenURI, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)},
+	})
+}
+
+// fetchGcpMetadataToken gets the access token of the default service account
+// from the metadata server.
+func fetchGcpMetadataToken() (*oauthToken, error) {
+	token := &oauthToken{}
+	header := http.Header{"Metadata-Flavor": []string{"Google"}}
+	if err := doKMSRequest(http.MethodGet, gcpMetadataTokenURL, header, nil, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/utils/syncutil"
+)
+
+const (
+	// kmsRequestTimeout is the timeout of a request to the KMS.
+	kmsRequestTimeout = 30 * time.Second
+	// tokenRefreshAhead is how long before it expires the access token is refreshed.
+	tokenRefreshAhead = time.Minute
+	// maxErrorBodyLength is the max length of the response body kept in the error.
+	maxErrorBodyLength = 512
+)
+
+var kmsHTTPClient = &http.Client{Timeout: kmsRequestTimeout}
+
+// doKMSRequest sends a request to the KMS with the JSON body, and decodes the
+// JSON response into out. The body and out are ignored if nil.
+func doKMSRequest(method, url string, header http.Header, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return errs.ErrJSONMarshal.Wrap(err).GenWithStackByCause()
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return errs.ErrEncryptionKMS.Wrap(err).GenWithStack("fail to create KMS request")
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return sendKMSRequest(req, out)
+}
+
+// sendKMSRequest sends the request and decodes the JSON response into out.
+func sendKMSRequest(req *http.Request, out interface{}) error {
+	resp, err := kmsHTTPClient.Do(req)
+	if err != nil {
+		return errs.ErrEncryptionKMS.Wrap(err).GenWithStack("fail to request %s", req.URL.Host)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyLength))
+		return errs.ErrEncryptionKMS.GenWithStack("request %s %s failed, status %d: %s",
+			req.Method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+	}
+	return nil
+}
+
+// oauthToken is the access token responded by an OAuth 2.0 token endpoint.
+type oauthToken struct {
+	AccessToken string `json:"access_token"`
+	// ExpiresIn is in seconds, some endpoints respond it as a string.
+	ExpiresIn json.Number `json:"expires_in"`
+}
+
+// fetchOAuthToken requests an access token by the form.
+func fetchOAuthToken(tokenURL string, form url.Values) (*oauthToken, error) {
+	req, err := http.NewRequest(http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errs.ErrEncryptionKMS.Wrap(err).GenWithStack("fail to create token request")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	token := &oauthToken{}
+	if err := sendKMSRequest(req, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// tokenSource caches the access token until it is about to expire.
+type tokenSource struct {
+	fetch func() (*oauthToken, error)
+
+	mu        syncutil.Mutex
+	token     string
+	expiredAt time.Time
+}
+
+func newTokenSource(fetch func() (*oauthToken, error)) *tokenSource {
+	return &tokenSource{fetch: fetch}
+}
+
+// accessToken returns the cached access token, or fetches a new one.
+func (s *tokenSource) accessToken() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if len(s.token) > 0 && now.Before(s.expiredAt) {
+		return s.token, nil
+	}
+	token, err := s.fetch()
+	if err != nil {
+		return "", err
+	}
+	if len(token.AccessToken) == 0 {
+		return "", errs.ErrEncryptionKMS.GenWithStack("empty access token")
+	}
+	expiresIn, err := token.ExpiresIn.Int64()
+	if err != nil {
+		// The token is used only once if its expiration is unknown.
+		expiresIn = 0
+	}
+	s.token = token.AccessToken
+	s.expiredAt = now.Add(time.Duration(expiresIn)*time.Second - tokenRefreshAhead)
+	return s.token, nil
+}
+
+func bearerHeader(token string) http.Header {
+	return http.Header{"Authorization": []string{"Bearer " + token}}
+}
@@ -0,0 +1,124 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/pingcap/kvproto/pkg/encryptionpb"
+	"github.com/tikv/pd/pkg/errs"
+)
+
+const (
+	defaultVaultTransitMount = "transit"
+
+	// The standard environment variables of the Vault client.
+	envVaultToken     = "VAULT_TOKEN"
+	envVaultNamespace = "VAULT_NAMESPACE"
+)
+
+// vaultKMS is the master key provider of the transit secrets engine of
+// HashiCorp Vault. The endpoint is the address of Vault, the key id is the name
+// of the transit key, and the region is the mount path of the engine.
+type vaultKMS struct {
+	address string
+	mount   string
+	keyName string
+	header  http.Header
+}
+
+func newVaultKMS(config *encryptionpb.MasterKeyKms, auth *MasterKeyKMSConfig) (*vaultKMS, error) {
+	if len(config.Endpoint) == 0 || len(config.KeyId) == 0 {
+		return nil, errs.ErrEncryptionKMS.GenWithStack("Vault requires both the address and the key name")
+	}
+	token := os.Getenv(envVaultToken)
+	if len(auth.VaultTokenFile) > 0 {
+		data, err := os.ReadFile(auth.VaultTokenFile)
+		if err != nil {
+			return nil, errs.ErrEncryptionKMS.Wrap(err).GenWithStack(
+				"fail to read Vault token file %s", auth.VaultTokenFile)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if len(token) == 0 {
+		return nil, errs.ErrEncryptionKMS.GenWithStack("missing Vault token")
+	}
+	mount := strings.Trim(config.Region, "/")
+	if len(mount) == 0 {
+		mount = defaultVaultTransitMount
+	}
+	header := http.Header{"X-Vault-Token": []string{token}}
+	if namespace := firstNonEmpty(auth.VaultNamespace, os.Getenv(envVaultNamespace)); len(namespace) > 0 {
+		header.Set("X-Vault-Namespace", namespace)
+	}
+	return &vaultKMS{
+		address: strings.TrimSuffix(config.Endpoint, "/"),
+		mount:   mount,
+		keyName: config.KeyId,
+		header:  header,
+	}, nil
+}
+
+func (k *vaultKMS) url(op string) string {
+	return k.address + "/v1/" + k.mount + "/" + op + "/" + k.keyName
+}
+
+func (k *vaultKMS) generateKey() (plaintext, ciphertext []byte, err error) {
+	return generateKeyByEncrypt(k.encrypt)
+}
+
+func (k *vaultKMS) encrypt(plaintext []byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	body := map[string][]byte{"plaintext": plaintext}
+	if err := doKMSRequest(http.MethodPost, k.url("encrypt"), k.header, body, &resp); err != nil {
+		return nil, errs.ErrEncryptionKMS.Wrap(err).GenWithStack("fail to encrypt master key by Vault")
+	}
+	return []byte(resp.Data.Ciphertext), nil
+}
+
+func (k *vaultKMS) decryptKey(ciphertext []byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Plaintext []byte `json:"plaintext"`
+		} `json:"data"`
+	}
+	body := map[string]string{"ciphertext": string(ciphertext)}
+	if err := doKMSRequest(http.MethodPost, k.url("decrypt"), k.header, body, &resp); err != nil {
+		return nil, errs.ErrEncryptionKMS.Wrap(err).GenWithStack("fail to decrypt master key by Vault")
+	}
+	return resp.Data.Plaintext, nil
+}
+
+func (k *vaultKMS) healthCheck() error {
+	var resp struct {
+		Data struct {
+			SupportsEncryption bool `json:"supports_encryption"`
+			SupportsDecryption bool `json:"supports_decryption"`
+		} `json:"data"`
+	}
+	if err := doKMSRequest(http.MethodGet, k.url("keys"), k.header, nil, &resp); err != nil {
+		return errs.ErrEncryptionKMS.Wrap(err).GenWithStack("fail to get Vault transit key")
+	}
+	if !resp.Data.SupportsEncryption || !resp.Data.SupportsDecryption {
+		return errs.ErrEncryptionKMS.GenWithStack("Vault transit key %s doesn't support encryption", k.keyName)
+	}
+	return nil
+}
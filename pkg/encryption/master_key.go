@@ -40,6 +40,12 @@ type MasterKey struct {
 // NewMasterKey obtains a master key from backend specified by given config.
 // The config may be altered to fill in metadata generated when initializing the master key.
 func NewMasterKey(config *encryptionpb.MasterKey, ciphertextKey []byte) (*MasterKey, error) {
+	return newMasterKey(config, ciphertextKey, nil)
+}
+
+// newMasterKey obtains a master key with the auth options of the KMS from the
+// config of PD, which may be nil to use the environment only.
+func newMasterKey(config *encryptionpb.MasterKey, ciphertextKey []byte, auth *MasterKeyKMSConfig) (*MasterKey, error) {
 	if config == nil {
 		return nil, errs.ErrEncryptionNewMasterKey.GenWithStack("master key config is empty")
 	}
@@ -52,11 +58,34 @@ func NewMasterKey(config *encryptionpb.MasterKey, ciphertextKey []byte) (*Master
 		return newMasterKeyFromFile(file)
 	}
 	if kms := config.GetKms(); kms != nil {
-		return newMasterKeyFromKMS(kms, ciphertextKey)
+		return newMasterKeyFromKMS(kms, ciphertextKey, auth)
 	}
 	return nil, errs.ErrEncryptionNewMasterKey.GenWithStack("unrecognized master key type")
 }
 
+// checkMasterKey checks whether the master key is accessible. The KMS is
+// checked without generating a master key.
+func checkMasterKey(config *encryptionpb.MasterKey, auth *MasterKeyKMSConfig) error {
+	if config == nil {
+		return errs.ErrEncryptionNewMasterKey.GenWithStack("master key config is empty")
+	}
+	if config.GetPlaintext() != nil {
+		return nil
+	}
+	if file := config.GetFile(); file != nil {
+		_, err := newMasterKeyFromFile(file)
+		return err
+	}
+	if kms := config.GetKms(); kms != nil {
+		provider, err := newKMSProvider(kms, auth)
+		if err != nil {
+			return err
+		}
+		return provider.healthCheck()
+	}
+	return errs.ErrEncryptionNewMasterKey.GenWithStack("unrecognized master key type")
+}
+
 // NewCustomMasterKeyForTest construct a master key instance from raw key and ciphertext key bytes.
 // Used for test only.
 func NewCustomMasterKeyForTest(key []byte, ciphertextKey []byte) *MasterKey {
@@ -0,0 +1,64 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/errs"
+	"go.uber.org/zap"
+)
+
+// MasterKeyHealth is the result of checking the master key.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type MasterKeyHealth struct {
+	// Type is one of "plaintext", "file" or "kms".
+	Type string `json:"type"`
+	// Vendor is the KMS vendor, empty if the type is not "kms".
+	Vendor  string `json:"vendor,omitempty"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+	// Latency is the milliseconds the check takes.
+	Latency int64 `json:"latency"`
+}
+
+// CheckMasterKey checks whether the configured master key is accessible, e.g.
+// the KMS is reachable with the credentials and the key is enabled.
+func (m *Manager) CheckMasterKey() *MasterKeyHealth {
+	health := &MasterKeyHealth{Type: masterKeyTypePlaintext}
+	switch {
+	case m.masterKeyMeta.GetFile() != nil:
+		health.Type = masterKeyTypeFile
+	case m.masterKeyMeta.GetKms() != nil:
+		health.Type = masterKeyTypeKMS
+		health.Vendor = m.masterKeyMeta.GetKms().Vendor
+	}
+	start := time.Now()
+	err := checkMasterKey(m.masterKeyMeta, &m.kmsAuth)
+	health.Latency = time.Since(start).Milliseconds()
+	health.Healthy = err == nil
+	if err != nil {
+		health.Error = err.Error()
+		masterKeyHealthGauge.WithLabelValues(health.Type, health.Vendor).Set(0)
+		log.Warn("master key is unhealthy",
+			zap.String("type", health.Type),
+			zap.String("vendor", health.Vendor),
+			errs.ZapError(err))
+		return health
+	}
+	masterKeyHealthGauge.WithLabelValues(health.Type, health.Vendor).Set(1)
+	return health
+}
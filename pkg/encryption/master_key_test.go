@@ -15,8 +15,13 @@
 package encryption
 
 import (
+	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/pingcap/kvproto/pkg/encryptionpb"
@@ -164,3 +169,57 @@ func TestNewFileMasterKey(t *testing.T) {
 	re.NoError(err)
 	re.Equal(key, hex.EncodeToString(masterKey.key))
 }
+
+func TestVaultMasterKey(t *testing.T) {
+	t.Parallel()
+	re := require.New(t)
+	// The mock transit engine "encrypts" by prefixing the plaintext.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var req map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		var data map[string]interface{}
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/pd":
+			data = map[string]interface{}{"ciphertext": "vault:v1:" + req["plaintext"]}
+		case "/v1/transit/decrypt/pd":
+			data = map[string]interface{}{"plaintext": strings.TrimPrefix(req["ciphertext"], "vault:v1:")}
+		case "/v1/transit/keys/pd":
+			data = map[string]interface{}{"supports_encryption": true, "supports_decryption": true}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+	tokenFile := dir + "/token"
+	re.NoError(os.WriteFile(tokenFile, []byte("token\n"), 0600))
+	auth := &MasterKeyKMSConfig{VaultTokenFile: tokenFile}
+	config := &encryptionpb.MasterKey{
+		Backend: &encryptionpb.MasterKey_Kms{
+			Kms: &encryptionpb.MasterKeyKms{
+				Vendor:   kmsVendorVault,
+				KeyId:    "pd",
+				Region:   defaultVaultTransitMount,
+				Endpoint: server.URL,
+			},
+		},
+	}
+	masterKey, err := newMasterKey(config, nil, auth)
+	re.NoError(err)
+	re.Len(masterKey.key, masterKeyLength)
+	re.Equal("vault:v1:"+base64.StdEncoding.EncodeToString(masterKey.key), string(masterKey.CiphertextKey()))
+
+	masterKey2, err := newMasterKey(config, masterKey.CiphertextKey(), auth)
+	re.NoError(err)
+	re.Equal(masterKey.key, masterKey2.key)
+	re.NoError(checkMasterKey(config, auth))
+
+	re.NoError(os.WriteFile(tokenFile, []byte("invalid"), 0600))
+	re.Error(checkMasterKey(config, auth))
+}
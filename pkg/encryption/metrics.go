@@ -0,0 +1,58 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	dataKeyUsageCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "encryption",
+			Name:      "data_key_usage_total",
+			Help:      "Counter of the data encryption key usages.",
+		}, []string{"key_id", "type"})
+
+	dataKeyRotationCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "encryption",
+			Name:      "data_key_rotations_total",
+			Help:      "Counter of the data encryption key rotations.",
+		}, []string{"reason"})
+
+	currentDataKeyAgeGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "encryption",
+			Name:      "current_data_key_age_seconds",
+			Help:      "How long the current data encryption key has been created.",
+		})
+
+	masterKeyHealthGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "encryption",
+			Name:      "master_key_healthy",
+			Help:      "Whether the master key is accessible in the last check, 1 for healthy.",
+		}, []string{"type", "vendor"})
+)
+
+func init() {
+	prometheus.MustRegister(dataKeyUsageCounter)
+	prometheus.MustRegister(dataKeyRotationCounter)
+	prometheus.MustRegister(currentDataKeyAgeGauge)
+	prometheus.MustRegister(masterKeyHealthGauge)
+}
@@ -82,6 +82,7 @@ func EncryptRegion(region *metapb.Region, keyManager KeyManager) (*metapb.Region
 		KeyId: keyID,
 		Iv:    iv,
 	}
+	dataKeyUsageCounter.WithLabelValues(keyIDLabel(keyID), "encrypt").Inc()
 	return outRegion, nil
 }
 
@@ -113,6 +114,7 @@ func DecryptRegion(region *metapb.Region, keyManager KeyManager) error {
 	if err != nil {
 		return err
 	}
+	dataKeyUsageCounter.WithLabelValues(keyIDLabel(region.EncryptionMeta.KeyId), "decrypt").Inc()
 	region.EncryptionMeta = nil
 	return nil
 }
@@ -0,0 +1,198 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/encryptionpb"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/utils/typeutil"
+)
+
+// maxKeyRotations is the number of the recent rotations kept in memory.
+const maxKeyRotations = 32
+
+// The reasons of the data key rotations.
+const (
+	// RotationEnabled means the encryption is enabled.
+	RotationEnabled = "enabled"
+	// RotationMethodChanged means the encryption method is changed.
+	RotationMethodChanged = "method-changed"
+	// RotationExposed means the current key was exposed.
+	RotationExposed = "exposed"
+	// RotationScheduled means the current key expires by the rotation period.
+	RotationScheduled = "scheduled"
+	// RotationManual means the rotation is triggered by the API.
+	RotationManual = "manual"
+)
+
+// The states of the data keys.
+const (
+	// DataKeyCurrent is the key to encrypt the new data.
+	DataKeyCurrent = "current"
+	// DataKeyNext is the key published within the overlap window, which becomes
+	// current at the scheduled rotation.
+	DataKeyNext = "next"
+	// DataKeyRetired is the key which only decrypts the existing data.
+	DataKeyRetired = "retired"
+)
+
+// KeyRotation is a rotation of the data key.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type KeyRotation struct {
+	// Time is the unix timestamp in seconds when the key is rotated.
+	Time          int64  `json:"time"`
+	Reason        string `json:"reason"`
+	PreviousKeyID uint64 `json:"previous_key_id"`
+	KeyID         uint64 `json:"key_id"`
+}
+
+// DataKeyInfo is the metadata of a data key, without the key itself.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type DataKeyInfo struct {
+	KeyID  uint64 `json:"key_id"`
+	Method string `json:"method"`
+	// CreationTime is the unix timestamp in seconds when the key is created, or
+	// becomes current for the next key.
+	CreationTime uint64 `json:"creation_time"`
+	WasExposed   bool   `json:"was_exposed"`
+	State        string `json:"state"`
+}
+
+// RotationStatus is the status of the data key rotation.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type RotationStatus struct {
+	Method          string            `json:"method"`
+	RotationPeriod  typeutil.Duration `json:"rotation_period"`
+	RotationOverlap typeutil.Duration `json:"rotation_overlap"`
+	// CurrentKeyID is 0 if the encryption is not enabled.
+	CurrentKeyID uint64 `json:"current_key_id"`
+	NextKeyID    uint64 `json:"next_key_id,omitempty"`
+	// NextRotationTime is the unix timestamp in seconds of the scheduled
+	// rotation, 0 if the encryption is not enabled.
+	NextRotationTime int64 `json:"next_rotation_time"`
+	// Keys are all the data keys from the newest.
+	Keys []*DataKeyInfo `json:"keys"`
+	// Rotations are the recent rotations by this PD node from the oldest, which
+	// are lost when the leader changes.
+	Rotations []*KeyRotation `json:"rotations"`
+}
+
+// getNextKey returns the next key published for the scheduled rotation at
+// expiredAt, nil if it is not published yet.
+func getNextKey(keys *encryptionpb.KeyDictionary, expiredAt time.Time) (uint64, *encryptionpb.DataKey) {
+	if keys.CurrentKeyId == disableEncryptionKeyID {
+		return 0, nil
+	}
+	for keyID, key := range keys.Keys {
+		if keyID != keys.CurrentKeyId && key.CreationTime == uint64(expiredAt.Unix()) {
+			return keyID, key
+		}
+	}
+	return 0, nil
+}
+
+// recordRotation records the rotation.
+// Require mu lock to be held.
+func (m *Manager) recordRotation(rotation *KeyRotation) {
+	m.mu.rotations = append(m.mu.rotations, rotation)
+	if len(m.mu.rotations) > maxKeyRotations {
+		m.mu.rotations = m.mu.rotations[len(m.mu.rotations)-maxKeyRotations:]
+	}
+	dataKeyRotationCounter.WithLabelValues(rotation.Reason).Inc()
+}
+
+// updateKeyAgeMetric updates the age of the current key.
+func (m *Manager) updateKeyAgeMetric() {
+	_, key, err := m.GetCurrentKey()
+	if err != nil || key == nil {
+		currentDataKeyAgeGauge.Set(0)
+		return
+	}
+	currentDataKeyAgeGauge.Set(m.helper.now().Sub(time.Unix(int64(key.CreationTime), 0)).Seconds())
+}
+
+// RotateKey rotates the data key immediately, instead of waiting for the
+// scheduled rotation. It only works on the PD leader with encryption enabled.
+func (m *Manager) RotateKey() (*KeyRotation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.method == encryptionpb.EncryptionMethod_PLAINTEXT {
+		return nil, errs.ErrEncryptionRotateDataKey.GenWithStack("encryption is not enabled")
+	}
+	if m.mu.leadership == nil || !m.mu.leadership.Check() {
+		return nil, errs.ErrEncryptionRotateDataKey.GenWithStack("not leader")
+	}
+	rotation, err := m.rotateKeyImpl(false /*forceUpdate*/, true /*forceRotate*/)
+	if err != nil {
+		return nil, err
+	}
+	if rotation == nil {
+		return nil, errs.ErrEncryptionRotateDataKey.GenWithStack("leader expired")
+	}
+	return rotation, nil
+}
+
+// GetRotationStatus returns the status of the data key rotation.
+func (m *Manager) GetRotationStatus() *RotationStatus {
+	status := &RotationStatus{
+		Method:          m.method.String(),
+		RotationPeriod:  typeutil.NewDuration(m.dataKeyRotationPeriod),
+		RotationOverlap: typeutil.NewDuration(m.dataKeyRotationOverlap),
+		Keys:            make([]*DataKeyInfo, 0),
+	}
+	m.mu.Lock()
+	status.Rotations = append([]*KeyRotation{}, m.mu.rotations...)
+	m.mu.Unlock()
+	keys := m.getKeys()
+	if keys == nil {
+		return status
+	}
+	status.CurrentKeyID = keys.CurrentKeyId
+	if current := keys.Keys[keys.CurrentKeyId]; current != nil {
+		expiredAt := time.Unix(int64(current.CreationTime), 0).Add(m.dataKeyRotationPeriod)
+		status.NextRotationTime = expiredAt.Unix()
+		status.NextKeyID, _ = getNextKey(keys, expiredAt)
+	}
+	for keyID, key := range keys.Keys {
+		info := &DataKeyInfo{
+			KeyID:        keyID,
+			Method:       key.Method.String(),
+			CreationTime: key.CreationTime,
+			WasExposed:   key.WasExposed,
+			State:        DataKeyRetired,
+		}
+		switch keyID {
+		case status.CurrentKeyID:
+			info.State = DataKeyCurrent
+		case status.NextKeyID:
+			if status.NextKeyID != 0 {
+				info.State = DataKeyNext
+			}
+		}
+		status.Keys = append(status.Keys, info)
+	}
+	sort.Slice(status.Keys, func(i, j int) bool {
+		return status.Keys[i].CreationTime > status.Keys[j].CreationTime
+	})
+	return status
+}
+
+func keyIDLabel(keyID uint64) string {
+	return strconv.FormatUint(keyID, 10)
+}
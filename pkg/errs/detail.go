@@ -0,0 +1,124 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/errors"
+)
+
+// UnknownComponent is the component of the errors which are not normalized.
+const UnknownComponent = "unknown"
+
+// retryableErrors are the errors which are expected to be gone after retrying,
+// e.g. the leader is changing or the request is timed out.
+var retryableErrors = map[errors.RFCErrorCode]struct{}{
+	ErrEtcdLeaderNotFound.RFCCode():      {},
+	ErrProxyTSOTimeout.RFCCode():         {},
+	ErrGenerateTimestamp.RFCCode():       {},
+	ErrLeaderNil.RFCCode():               {},
+	ErrServerNotStarted.RFCCode():        {},
+	ErrFollowerReadUnavailable.RFCCode(): {},
+	ErrEtcdTxnConflict.RFCCode():         {},
+	ErrJobManagerNotRunning.RFCCode():    {},
+	ErrClientGetLeader.RFCCode():         {},
+	ErrClientGetTSOTimeout.RFCCode():     {},
+	ErrGRPCSend.RFCCode():                {},
+	ErrGRPCRecv.RFCCode():                {},
+}
+
+// Detail is the machine-readable form of an error returned by the APIs.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type Detail struct {
+	// Code is the RFC code of the error, e.g. "PD:core:ErrStoreNotFound".
+	Code string `json:"code"`
+	// Component is the component part of the code, e.g. "core".
+	Component string `json:"component"`
+	// Retryable indicates whether the same request may succeed after retrying.
+	Retryable bool              `json:"retryable"`
+	Message   string            `json:"message"`
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+// withDetail attaches a key-value pair to the error.
+type withDetail struct {
+	error
+	key   string
+	value string
+}
+
+func (w *withDetail) Cause() error  { return w.error }
+func (w *withDetail) Unwrap() error { return w.error }
+
+// WithDetail attaches a key-value pair to the error, which is returned in the
+// details of the machine-readable form of the error.
+func WithDetail(err error, key string, value interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return &withDetail{error: err, key: key, value: fmt.Sprint(value)}
+}
+
+// Describe returns the machine-readable form of the error. The code is taken
+// from the outermost normalized error in the chain of the causes, and it is
+// empty if there is no normalized error, in which case the component is
+// UnknownComponent.
+func Describe(err error) *Detail {
+	if err == nil {
+		return nil
+	}
+	detail := &Detail{Component: UnknownComponent, Message: err.Error()}
+	for err != nil {
+		switch e := err.(type) {
+		case *withDetail:
+			if detail.Details == nil {
+				detail.Details = make(map[string]string)
+			}
+			// The outer one takes precedence.
+			if _, ok := detail.Details[e.key]; !ok {
+				detail.Details[e.key] = e.value
+			}
+		case *errors.Error:
+			if detail.Code == "" {
+				code := e.RFCCode()
+				detail.Code = string(code)
+				detail.Component = componentOf(detail.Code)
+				_, detail.Retryable = retryableErrors[code]
+			}
+		}
+		causer, ok := err.(interface{ Cause() error })
+		if !ok {
+			break
+		}
+		cause := causer.Cause()
+		if cause == err {
+			break
+		}
+		err = cause
+	}
+	return detail
+}
+
+// componentOf returns the component of the RFC code in the form of
+// "PD:<component>:<name>".
+func componentOf(code string) string {
+	parts := strings.Split(code, ":")
+	if len(parts) < 3 || parts[1] == "" {
+		return UnknownComponent
+	}
+	return parts[1]
+}
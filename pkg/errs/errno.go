@@ -155,13 +155,14 @@ var (
 
 // server errors
 var (
-	ErrServiceRegistered     = errors.Normalize("service with path [%s] already registered", errors.RFCCodeText("PD:server:ErrServiceRegistered"))
-	ErrAPIInformationInvalid = errors.Normalize("invalid api information, group %s version %s", errors.RFCCodeText("PD:server:ErrAPIInformationInvalid"))
-	ErrClientURLEmpty        = errors.Normalize("client url empty", errors.RFCCodeText("PD:server:ErrClientEmpty"))
-	ErrLeaderNil             = errors.Normalize("leader is nil", errors.RFCCodeText("PD:server:ErrLeaderNil"))
-	ErrCancelStartEtcd       = errors.Normalize("etcd start canceled", errors.RFCCodeText("PD:server:ErrCancelStartEtcd"))
-	ErrConfigItem            = errors.Normalize("cannot set invalid configuration", errors.RFCCodeText("PD:server:ErrConfiguration"))
-	ErrServerNotStarted      = errors.Normalize("server not started", errors.RFCCodeText("PD:server:ErrServerNotStarted"))
+	ErrServiceRegistered       = errors.Normalize("service with path [%s] already registered", errors.RFCCodeText("PD:server:ErrServiceRegistered"))
+	ErrAPIInformationInvalid   = errors.Normalize("invalid api information, group %s version %s", errors.RFCCodeText("PD:server:ErrAPIInformationInvalid"))
+	ErrClientURLEmpty          = errors.Normalize("client url empty", errors.RFCCodeText("PD:server:ErrClientEmpty"))
+	ErrLeaderNil               = errors.Normalize("leader is nil", errors.RFCCodeText("PD:server:ErrLeaderNil"))
+	ErrCancelStartEtcd         = errors.Normalize("etcd start canceled", errors.RFCCodeText("PD:server:ErrCancelStartEtcd"))
+	ErrConfigItem              = errors.Normalize("cannot set invalid configuration", errors.RFCCodeText("PD:server:ErrConfiguration"))
+	ErrServerNotStarted        = errors.Normalize("server not started", errors.RFCCodeText("PD:server:ErrServerNotStarted"))
+	ErrFollowerReadUnavailable = errors.Normalize("follower read is unavailable, %s", errors.RFCCodeText("PD:server:ErrFollowerReadUnavailable"))
 )
 
 // logutil errors
@@ -216,6 +217,8 @@ var (
 	ErrCloseEtcdClient   = errors.Normalize("close etcd client failed", errors.RFCCodeText("PD:etcd:ErrCloseEtcdClient"))
 	ErrEtcdMemberList    = errors.Normalize("etcd member list failed", errors.RFCCodeText("PD:etcd:ErrEtcdMemberList"))
 	ErrEtcdMemberRemove  = errors.Normalize("etcd remove member failed", errors.RFCCodeText("PD:etcd:ErrEtcdMemberRemove"))
+	ErrEtcdMemberPromote = errors.Normalize("etcd promote member failed", errors.RFCCodeText("PD:etcd:ErrEtcdMemberPromote"))
+	ErrEtcdAlarmList     = errors.Normalize("etcd alarm list failed", errors.RFCCodeText("PD:etcd:ErrEtcdAlarmList"))
 )
 
 // dashboard errors
@@ -349,3 +352,36 @@ var (
 	ErrProgressWrongStatus = errors.Normalize("progress status is wrong", errors.RFCCodeText("PD:progress:ErrProgressWrongStatus"))
 	ErrProgressNotFound    = errors.Normalize("no progress found for %s", errors.RFCCodeText("PD:progress:ErrProgressNotFound"))
 )
+
+// event errors
+var (
+	ErrEventCompacted     = errors.Normalize("the events after %s are compacted", errors.RFCCodeText("PD:event:ErrEventCompacted"))
+	ErrInvalidResumeToken = errors.Normalize("invalid resume token %s", errors.RFCCodeText("PD:event:ErrInvalidResumeToken"))
+)
+
+// job errors
+var (
+	ErrJobManagerNotRunning = errors.Normalize("job manager is not running on the leader", errors.RFCCodeText("PD:job:ErrJobManagerNotRunning"))
+	ErrJobNotFound          = errors.Normalize("job %d not found", errors.RFCCodeText("PD:job:ErrJobNotFound"))
+	ErrJobFinished          = errors.Normalize("job %d has finished", errors.RFCCodeText("PD:job:ErrJobFinished"))
+	ErrJobNotCancelable     = errors.Normalize("job %d can't be canceled", errors.RFCCodeText("PD:job:ErrJobNotCancelable"))
+)
+
+// global config errors
+var (
+	ErrGlobalConfigNotFound         = errors.Normalize("global config %s not found", errors.RFCCodeText("PD:globalconfig:ErrGlobalConfigNotFound"))
+	ErrGlobalConfigConflict         = errors.Normalize("global config %s has been modified at revision %d", errors.RFCCodeText("PD:globalconfig:ErrGlobalConfigConflict"))
+	ErrGlobalConfigCompacted        = errors.Normalize("global config revision %d has been compacted", errors.RFCCodeText("PD:globalconfig:ErrGlobalConfigCompacted"))
+	ErrInvalidGlobalConfigNamespace = errors.Normalize("invalid global config namespace %s", errors.RFCCodeText("PD:globalconfig:ErrInvalidGlobalConfigNamespace"))
+)
+
+// webhook errors
+var (
+	ErrWebhookNotFound = errors.Normalize("webhook %s not found", errors.RFCCodeText("PD:webhook:ErrWebhookNotFound"))
+	ErrInvalidWebhook  = errors.Normalize("invalid webhook, %s", errors.RFCCodeText("PD:webhook:ErrInvalidWebhook"))
+)
+
+// resource manager errors
+var (
+	ErrInvalidResourceGroupMigration = errors.Normalize("invalid resource group migration, %s", errors.RFCCodeText("PD:resourcemanager:ErrInvalidResourceGroupMigration"))
+)
@@ -143,3 +143,33 @@ func TestErrorWithStack(t *testing.T) {
 	re.GreaterOrEqual(idx2, -1)
 	re.Len(m2[idx2:], len(m1[idx1:]))
 }
+
+func TestDescribe(t *testing.T) {
+	t.Parallel()
+	re := require.New(t)
+	re.Nil(Describe(nil))
+
+	detail := Describe(WithDetail(ErrStoreNotFound.FastGenByArgs(1), "store-id", 1))
+	re.Equal("PD:core:ErrStoreNotFound", detail.Code)
+	re.Equal("core", detail.Component)
+	re.False(detail.Retryable)
+	re.Equal("1", detail.Details["store-id"])
+	re.Contains(detail.Message, "store 1 not found")
+
+	detail = Describe(errors.Annotate(ErrLeaderNil.FastGenByArgs(), "failed to get leader"))
+	re.Equal("PD:server:ErrLeaderNil", detail.Code)
+	re.Equal("server", detail.Component)
+	re.True(detail.Retryable)
+	re.Empty(detail.Details)
+
+	// The outermost normalized error is used.
+	_, err := strconv.ParseUint("-42", 10, 64)
+	detail = Describe(ErrStrconvParseUint.Wrap(ErrEtcdLeaderNotFound.Wrap(err)).GenWithStackByCause())
+	re.Equal("PD:strconv:ErrStrconvParseUint", detail.Code)
+	re.False(detail.Retryable)
+
+	detail = Describe(errors.New("test"))
+	re.Empty(detail.Code)
+	re.Equal(UnknownComponent, detail.Component)
+	re.Equal("test", detail.Message)
+}
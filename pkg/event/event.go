@@ -0,0 +1,201 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/utils/syncutil"
+)
+
+// Type is the type of a cluster event.
+type Type string
+
+// The types of the cluster events.
+const (
+	// StoreUp means a disconnected or down store sends heartbeats again.
+	StoreUp Type = "store-up"
+	// StoreDown means a store has not sent heartbeats for the max store down time.
+	StoreDown Type = "store-down"
+	// StoreStateChanged means the state or the node state of a store is changed.
+	StoreStateChanged Type = "store-state-changed"
+	// StoreSlowEvicted means the leaders are evicted from a store detected as slow.
+	StoreSlowEvicted Type = "store-slow-evicted"
+	// StoreSlowRecovered means a store evicted as slow is recovered.
+	StoreSlowRecovered Type = "store-slow-recovered"
+	// LeaderChanged means the leader of a region is transferred to another store.
+	LeaderChanged Type = "leader-changed"
+	// OperatorStarted means an operator is added and starts to run.
+	OperatorStarted Type = "operator-started"
+	// OperatorFinished means an operator ends, whose status tells how it ends.
+	OperatorFinished Type = "operator-finished"
+	// RuleChanged means a placement rule is created or updated.
+	RuleChanged Type = "rule-changed"
+	// RuleDeleted means a placement rule is deleted.
+	RuleDeleted Type = "rule-deleted"
+	// LabelRuleChanged means a region label rule is created or updated.
+	LabelRuleChanged Type = "label-rule-changed"
+	// LabelRuleDeleted means a region label rule is deleted.
+	LabelRuleDeleted Type = "label-rule-deleted"
+	// MemberLeaderChanged means a PD member becomes the leader.
+	MemberLeaderChanged Type = "member-leader-changed"
+	// GCBlocked means a service safepoint blocks GC longer than the threshold.
+	GCBlocked Type = "gc-blocked"
+	// GCSafePointExpired means the lease of a service safepoint expires.
+	GCSafePointExpired Type = "gc-safepoint-expired"
+	// BucketsChanged means the bucket keys of a region are changed, i.e. a
+	// store reports the buckets with a greater version.
+	BucketsChanged Type = "buckets-changed"
+)
+
+// Category returns the category of the type, which is one of "store",
+// "leader", "operator", "rule", "label", "member", "gc" and "buckets".
+func (t Type) Category() string {
+	return strings.SplitN(string(t), "-", 2)[0]
+}
+
+// Event is a cluster event.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type Event struct {
+	// ID is assigned by the hub in the ascending order.
+	ID       uint64    `json:"id"`
+	Type     Type      `json:"type"`
+	Time     time.Time `json:"time"`
+	StoreID  uint64    `json:"store_id,omitempty"`
+	RegionID uint64    `json:"region_id,omitempty"`
+	// Attributes are the details of the event, which vary with the type, e.g.
+	// the old and the new state of a store.
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// Hub keeps the recent events in a ring buffer, and notifies the subscribers of
+// the new events. The events are lost once the hub is recreated, so the epoch
+// of the hub is a part of the resume tokens. A nil Hub discards all events.
+type Hub struct {
+	syncutil.RWMutex
+	epoch int64
+	// events is the ring buffer, events[next%len(events)] is the oldest one if
+	// it is full.
+	events []*Event
+	nextID uint64
+	// notify is closed and replaced whenever an event is published.
+	notify chan struct{}
+}
+
+// NewHub creates a hub keeping at most capacity recent events.
+func NewHub(capacity int) *Hub {
+	return &Hub{
+		epoch:  time.Now().UnixNano(),
+		events: make([]*Event, capacity),
+		nextID: 1,
+		notify: make(chan struct{}),
+	}
+}
+
+// Publish assigns the id and the time to the event and publishes it.
+func (h *Hub) Publish(e *Event) {
+	if h == nil {
+		return
+	}
+	h.Lock()
+	defer h.Unlock()
+	e.ID = h.nextID
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	h.events[e.ID%uint64(len(h.events))] = e
+	h.nextID++
+	close(h.notify)
+	h.notify = make(chan struct{})
+}
+
+// ResumeToken returns the token to resume the events after the event.
+func (h *Hub) ResumeToken(e *Event) string {
+	return fmt.Sprintf("%d-%d", h.epoch, e.ID)
+}
+
+// Subscription iterates the events published to a hub.
+type Subscription struct {
+	hub    *Hub
+	lastID uint64
+}
+
+// Subscribe returns a subscription of the events after the resume token, or of
+// the new events if the token is empty. It fails if the token is from another
+// hub, or the events after it are no longer kept.
+func (h *Hub) Subscribe(token string) (*Subscription, error) {
+	h.RLock()
+	defer h.RUnlock()
+	sub := &Subscription{hub: h, lastID: h.nextID - 1}
+	if len(token) == 0 {
+		return sub, nil
+	}
+	parts := strings.SplitN(token, "-", 2)
+	if len(parts) != 2 {
+		return nil, errs.ErrInvalidResumeToken.FastGenByArgs(token)
+	}
+	epoch, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, errs.ErrInvalidResumeToken.FastGenByArgs(token)
+	}
+	lastID, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil || lastID >= h.nextID {
+		return nil, errs.ErrInvalidResumeToken.FastGenByArgs(token)
+	}
+	if epoch != h.epoch || !h.keptLocked(lastID+1) {
+		return nil, errs.ErrEventCompacted.FastGenByArgs(token)
+	}
+	sub.lastID = lastID
+	return sub, nil
+}
+
+// keptLocked returns whether the event with the id or any later one is still
+// in the ring buffer.
+func (h *Hub) keptLocked(id uint64) bool {
+	return id+uint64(len(h.events)) >= h.nextID
+}
+
+// Next waits and returns the events after the last returned ones. It fails if
+// the subscriber falls so far behind that the events are overwritten.
+func (s *Subscription) Next(ctx context.Context) ([]*Event, error) {
+	for {
+		s.hub.RLock()
+		notify := s.hub.notify
+		if s.lastID+1 < s.hub.nextID {
+			if !s.hub.keptLocked(s.lastID + 1) {
+				s.hub.RUnlock()
+				return nil, errs.ErrEventCompacted.FastGenByArgs(s.hub.ResumeToken(&Event{ID: s.lastID}))
+			}
+			events := make([]*Event, 0, s.hub.nextID-s.lastID-1)
+			for id := s.lastID + 1; id < s.hub.nextID; id++ {
+				events = append(events, s.hub.events[id%uint64(len(s.hub.events))])
+			}
+			s.lastID = s.hub.nextID - 1
+			s.hub.RUnlock()
+			return events, nil
+		}
+		s.hub.RUnlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-notify:
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/errs"
+)
+
+func TestHub(t *testing.T) {
+	t.Parallel()
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var nilHub *Hub
+	nilHub.Publish(&Event{Type: StoreUp})
+
+	hub := NewHub(3)
+	hub.Publish(&Event{Type: StoreUp, StoreID: 1})
+	sub, err := hub.Subscribe("")
+	re.NoError(err)
+	hub.Publish(&Event{Type: StoreDown, StoreID: 1})
+	hub.Publish(&Event{Type: RuleChanged})
+	events, err := sub.Next(ctx)
+	re.NoError(err)
+	re.Len(events, 2)
+	re.Equal(uint64(2), events[0].ID)
+	re.Equal(StoreDown, events[0].Type)
+	re.False(events[0].Time.IsZero())
+	re.Equal("rule", events[1].Type.Category())
+
+	// Next waits for the new events.
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		hub.Publish(&Event{Type: LeaderChanged, RegionID: 1})
+	}()
+	events, err = sub.Next(ctx)
+	re.NoError(err)
+	re.Len(events, 1)
+	re.Equal(LeaderChanged, events[0].Type)
+	timeoutCtx, timeoutCancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer timeoutCancel()
+	_, err = sub.Next(timeoutCtx)
+	re.Equal(context.DeadlineExceeded, err)
+
+	// Resume from the token.
+	sub, err = hub.Subscribe(hub.ResumeToken(&Event{ID: 2}))
+	re.NoError(err)
+	events, err = sub.Next(ctx)
+	re.NoError(err)
+	re.Len(events, 2)
+	re.Equal(uint64(3), events[0].ID)
+
+	// The event 1 is overwritten.
+	_, err = hub.Subscribe(hub.ResumeToken(&Event{ID: 0}))
+	re.True(errs.ErrEventCompacted.Equal(err))
+	_, err = hub.Subscribe(fmt.Sprintf("%d-1", hub.epoch+1))
+	re.True(errs.ErrEventCompacted.Equal(err))
+	for _, token := range []string{"abc", "1", fmt.Sprintf("%d-5", hub.epoch)} {
+		_, err = hub.Subscribe(token)
+		re.True(errs.ErrInvalidResumeToken.Equal(err))
+	}
+
+	// The subscriber falls behind.
+	for i := 0; i < 4; i++ {
+		hub.Publish(&Event{Type: OperatorStarted})
+	}
+	_, err = sub.Next(ctx)
+	re.True(errs.ErrEventCompacted.Equal(err))
+}
@@ -0,0 +1,236 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/utils/syncutil"
+	"go.uber.org/zap"
+)
+
+// Status is the status of a job.
+type Status string
+
+// The statuses of the jobs.
+const (
+	Running   Status = "running"
+	Succeeded Status = "succeeded"
+	Failed    Status = "failed"
+	Canceled  Status = "canceled"
+)
+
+// Job is the snapshot of an asynchronous admin job.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type Job struct {
+	// ID is allocated by the PD leader running the job. The jobs are not
+	// persisted, so the ID is only unique within a PD server, and a job can't
+	// be found on the new leader after the leadership changes.
+	ID         uint64      `json:"id"`
+	Type       string      `json:"type"`
+	Params     interface{} `json:"params,omitempty"`
+	Status     Status      `json:"status"`
+	Cancelable bool        `json:"cancelable"`
+	// Progress is the ratio of the finished work, from 0 to 1.
+	Progress   float64     `json:"progress"`
+	Message    string      `json:"message,omitempty"`
+	Result     interface{} `json:"result,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	CreateTime time.Time   `json:"create_time"`
+	FinishTime *time.Time  `json:"finish_time,omitempty"`
+}
+
+// Reporter reports the progress of a running job.
+type Reporter func(progress float64, message string)
+
+// Func runs a job until it is done or the context is canceled.
+type Func func(ctx context.Context, report Reporter) (result interface{}, err error)
+
+// Spec describes a job to be submitted.
+type Spec struct {
+	Type   string
+	Params interface{}
+	// Cancelable is false if the job can't be stopped safely once it starts,
+	// which only rejects the cancel requests. The context of the job is still
+	// canceled once the leadership is lost, so the job should leave a
+	// consistent state at any point, e.g. by making each step idempotent.
+	Cancelable bool
+	Run        Func
+}
+
+type entry struct {
+	job    Job
+	cancel context.CancelFunc
+}
+
+// Manager runs the jobs in the background on the PD leader. The jobs are kept
+// in memory, and are canceled once the leadership is lost. The finished jobs
+// can still be queried on the server after it loses the leadership, but the
+// clients are redirected to the new leader, which doesn't know them.
+type Manager struct {
+	syncutil.RWMutex
+	// ctx is the context of the current leadership, nil if it is never the leader.
+	ctx    context.Context
+	nextID uint64
+	jobs   map[uint64]*entry
+	// finished are the ids of the finished jobs in the finishing order, the
+	// oldest ones are removed once there are more than maxFinished.
+	finished    []uint64
+	maxFinished int
+}
+
+// NewManager creates a job manager keeping at most maxFinished finished jobs.
+func NewManager(maxFinished int) *Manager {
+	return &Manager{
+		nextID:      1,
+		jobs:        make(map[uint64]*entry),
+		maxFinished: maxFinished,
+	}
+}
+
+// OnLeader is the leader callback, the jobs submitted during the leadership
+// run with the context.
+func (m *Manager) OnLeader(ctx context.Context) {
+	m.Lock()
+	defer m.Unlock()
+	m.ctx = ctx
+}
+
+// Submit starts the job in the background, and returns it immediately.
+func (m *Manager) Submit(spec *Spec) (*Job, error) {
+	m.Lock()
+	defer m.Unlock()
+	if m.ctx == nil || m.ctx.Err() != nil {
+		return nil, errs.ErrJobManagerNotRunning.FastGenByArgs()
+	}
+	ctx, cancel := context.WithCancel(m.ctx)
+	e := &entry{
+		job: Job{
+			ID:         m.nextID,
+			Type:       spec.Type,
+			Params:     spec.Params,
+			Status:     Running,
+			Cancelable: spec.Cancelable,
+			CreateTime: time.Now(),
+		},
+		cancel: cancel,
+	}
+	m.nextID++
+	m.jobs[e.job.ID] = e
+	log.Info("job started", zap.Uint64("job-id", e.job.ID), zap.String("type", spec.Type), zap.Reflect("params", spec.Params))
+	report := func(progress float64, message string) {
+		m.Lock()
+		defer m.Unlock()
+		if e.job.Status == Running {
+			e.job.Progress, e.job.Message = progress, message
+		}
+	}
+	go func() {
+		result, err := run(ctx, spec, report)
+		m.finish(e, result, err, ctx.Err())
+	}()
+	job := e.job
+	return &job, nil
+}
+
+// run runs the job, and the panic fails the job rather than the PD server.
+func run(ctx context.Context, spec *Spec, report Reporter) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error("job panicked", zap.String("type", spec.Type), zap.Reflect("recover", r), zap.Stack("stack"))
+			result, err = nil, errors.Errorf("job panicked: %v", r)
+		}
+	}()
+	return spec.Run(ctx, report)
+}
+
+func (m *Manager) finish(e *entry, result interface{}, err, ctxErr error) {
+	m.Lock()
+	defer m.Unlock()
+	now := time.Now()
+	e.job.FinishTime = &now
+	e.job.Result = result
+	switch {
+	case err == nil:
+		e.job.Status, e.job.Progress = Succeeded, 1
+	case ctxErr != nil:
+		e.job.Status = Canceled
+	default:
+		e.job.Status = Failed
+	}
+	if err != nil {
+		e.job.Error = err.Error()
+	}
+	e.cancel()
+	log.Info("job finished", zap.Uint64("job-id", e.job.ID), zap.String("type", e.job.Type),
+		zap.String("status", string(e.job.Status)), zap.Error(err))
+	m.finished = append(m.finished, e.job.ID)
+	for len(m.finished) > m.maxFinished {
+		delete(m.jobs, m.finished[0])
+		m.finished = m.finished[1:]
+	}
+}
+
+// GetJob returns the job.
+func (m *Manager) GetJob(id uint64) (*Job, error) {
+	m.RLock()
+	defer m.RUnlock()
+	e, ok := m.jobs[id]
+	if !ok {
+		return nil, errs.ErrJobNotFound.FastGenByArgs(id)
+	}
+	job := e.job
+	return &job, nil
+}
+
+// GetJobs returns the jobs of the type in the status in the ascending order of
+// the ids. An empty type or status matches all the jobs.
+func (m *Manager) GetJobs(typ string, status Status) []*Job {
+	m.RLock()
+	defer m.RUnlock()
+	jobs := make([]*Job, 0, len(m.jobs))
+	for _, e := range m.jobs {
+		if (len(typ) == 0 || e.job.Type == typ) && (len(status) == 0 || e.job.Status == status) {
+			job := e.job
+			jobs = append(jobs, &job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID < jobs[j].ID })
+	return jobs
+}
+
+// CancelJob requests the running job to stop. The job is canceled once its
+// function returns.
+func (m *Manager) CancelJob(id uint64) error {
+	m.RLock()
+	defer m.RUnlock()
+	e, ok := m.jobs[id]
+	switch {
+	case !ok:
+		return errs.ErrJobNotFound.FastGenByArgs(id)
+	case e.job.Status != Running:
+		return errs.ErrJobFinished.FastGenByArgs(id)
+	case !e.job.Cancelable:
+		return errs.ErrJobNotCancelable.FastGenByArgs(id)
+	}
+	log.Info("cancel job", zap.Uint64("job-id", id), zap.String("type", e.job.Type))
+	e.cancel()
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/errs"
+)
+
+func waitJob(re *require.Assertions, m *Manager, id uint64, status Status) *Job {
+	var job *Job
+	re.Eventually(func() bool {
+		var err error
+		job, err = m.GetJob(id)
+		re.NoError(err)
+		return job.Status == status
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestJob(t *testing.T) {
+	re := require.New(t)
+	m := NewManager(2)
+	spec := &Spec{Type: "test", Run: func(context.Context, Reporter) (interface{}, error) { return nil, nil }}
+	_, err := m.Submit(spec)
+	re.True(errs.ErrJobManagerNotRunning.Equal(err))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m.OnLeader(ctx)
+
+	// succeeded
+	job, err := m.Submit(&Spec{Type: "test", Params: "p", Run: func(_ context.Context, report Reporter) (interface{}, error) {
+		report(0.5, "half")
+		return 42, nil
+	}})
+	re.NoError(err)
+	re.Equal(uint64(1), job.ID)
+	job = waitJob(re, m, job.ID, Succeeded)
+	re.Equal(1.0, job.Progress)
+	re.Equal(42, job.Result)
+	re.NotNil(job.FinishTime)
+	re.True(errs.ErrJobFinished.Equal(m.CancelJob(job.ID)))
+
+	// failed
+	job, err = m.Submit(&Spec{Type: "fail", Run: func(context.Context, Reporter) (interface{}, error) {
+		return nil, errors.New("boom")
+	}})
+	re.NoError(err)
+	job = waitJob(re, m, job.ID, Failed)
+	re.Equal("boom", job.Error)
+
+	// canceled
+	block := func(ctx context.Context, _ Reporter) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	job, err = m.Submit(&Spec{Type: "test", Cancelable: true, Run: block})
+	re.NoError(err)
+	re.Len(m.GetJobs("test", Running), 1)
+	re.NoError(m.CancelJob(job.ID))
+	waitJob(re, m, job.ID, Canceled)
+
+	// not cancelable
+	job, err = m.Submit(&Spec{Type: "test", Run: block})
+	re.NoError(err)
+	re.True(errs.ErrJobNotCancelable.Equal(m.CancelJob(job.ID)))
+
+	// the oldest finished jobs are removed
+	_, err = m.GetJob(1)
+	re.True(errs.ErrJobNotFound.Equal(err))
+	jobs := m.GetJobs("", "")
+	re.Len(jobs, 3)
+	re.Equal(uint64(2), jobs[0].ID)
+
+	// the running jobs are canceled once the leadership is lost
+	cancel()
+	job = waitJob(re, m, job.ID, Canceled)
+	re.Equal(context.Canceled.Error(), job.Error)
+	_, err = m.Submit(spec)
+	re.True(errs.ErrJobManagerNotRunning.Equal(err))
+}
+
+func TestJobPanic(t *testing.T) {
+	re := require.New(t)
+	m := NewManager(2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m.OnLeader(ctx)
+
+	// The panic fails the job only.
+	job, err := m.Submit(&Spec{Type: "panic", Run: func(context.Context, Reporter) (interface{}, error) {
+		panic("boom")
+	}})
+	re.NoError(err)
+	job = waitJob(re, m, job.ID, Failed)
+	re.Equal("job panicked: boom", job.Error)
+	re.Nil(job.Result)
+}
@@ -0,0 +1,191 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discovery
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pingcap/log"
+	bs "github.com/tikv/pd/pkg/basicserver"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/utils/syncutil"
+	"go.uber.org/zap"
+)
+
+// ForwardedByHeader is set by the forwarder with its name. The requests carrying
+// it are never forwarded again, which prevents the forwarding loop when the
+// microservice serves the same API.
+const ForwardedByHeader = "PD-Microservice-Forwarded-By"
+
+const (
+	// discoveryCacheTTL is how long the discovered addresses are reused.
+	discoveryCacheTTL = 3 * time.Second
+	// forwardRetryTimes is the number of the rounds to try all the discovered
+	// addresses, the addresses are discovered again before each retry.
+	forwardRetryTimes    = 3
+	forwardRetryInterval = 100 * time.Millisecond
+	// maxForwardBodySize is the max size of the forwarded request body, which
+	// is buffered in memory to be sent again on retry.
+	maxForwardBodySize = 16 << 20
+)
+
+// Forwarder forwards the HTTP requests to the microservice if it is deployed
+// independently, i.e. there are instances registered to the discovery. Otherwise
+// the requests are served locally, so the clients can keep using the same
+// endpoint regardless of the deployment mode.
+type Forwarder struct {
+	srv     bs.Server
+	service string
+
+	mu struct {
+		syncutil.Mutex
+		addrs      []string
+		discovered time.Time
+	}
+}
+
+// NewForwarder creates a forwarder of the microservice on the server. The etcd
+// client and the HTTP client of the server are only used after it starts.
+func NewForwarder(srv bs.Server, service string) *Forwarder {
+	return &Forwarder{srv: srv, service: service}
+}
+
+// Handler returns the handler forwarding the requests, or serving them by next
+// if the microservice is not deployed independently.
+func (f *Forwarder) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !f.Forward(w, r) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// Forward forwards the request to the microservice, and returns false without
+// writing the response if the request should be served locally.
+func (f *Forwarder) Forward(w http.ResponseWriter, r *http.Request) bool {
+	if len(r.Header.Get(ForwardedByHeader)) > 0 {
+		return false
+	}
+	addrs := f.getAddrs(false)
+	if len(addrs) == 0 {
+		return false
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxForwardBodySize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return true
+		}
+		http.Error(w, errs.ErrIORead.Wrap(err).GenWithStackByCause().Error(), http.StatusBadRequest)
+		return true
+	}
+	for i := 0; i < forwardRetryTimes; i++ {
+		if i > 0 {
+			select {
+			case <-r.Context().Done():
+				return true
+			case <-time.After(forwardRetryInterval):
+			}
+			addrs = f.getAddrs(true)
+		}
+		for _, addr := range addrs {
+			resp, err := f.do(r, addr, body)
+			if err != nil {
+				log.Warn("failed to forward request to microservice", zap.String("service", f.service),
+					zap.String("addr", addr), zap.String("path", r.URL.Path), errs.ZapError(errs.ErrSendRequest, err))
+				if !canRetry(r.Method, err) {
+					http.Error(w, "failed to forward request to "+f.service, http.StatusBadGateway)
+					return true
+				}
+				continue
+			}
+			writeResponse(w, resp)
+			return true
+		}
+	}
+	http.Error(w, "failed to forward request to "+f.service, http.StatusServiceUnavailable)
+	return true
+}
+
+// canRetry returns whether the failed request can be sent again. The requests
+// of the idempotent methods are always retried, while the others are retried
+// only if the connection was not established, i.e. they were never sent, so
+// the mutation is not applied twice.
+func canRetry(method string, err error) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func (f *Forwarder) do(r *http.Request, addr string, body []byte) (*http.Response, error) {
+	if !strings.Contains(addr, "://") {
+		addr = f.scheme() + "://" + addr
+	}
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, addr+r.URL.RequestURI(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = r.Header.Clone()
+	req.Header.Set(ForwardedByHeader, f.srv.Name())
+	return f.srv.GetHTTPClient().Do(req)
+}
+
+func (f *Forwarder) scheme() string {
+	if t, ok := f.srv.GetHTTPClient().Transport.(*http.Transport); ok && t.TLSClientConfig != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// getAddrs returns the addresses of the microservice instances, which are
+// discovered again if refresh is set or the cached ones are expired.
+func (f *Forwarder) getAddrs(refresh bool) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !refresh && time.Since(f.mu.discovered) < discoveryCacheTTL {
+		return f.mu.addrs
+	}
+	addrs, err := Discover(f.srv.GetClient(), f.service)
+	if err != nil {
+		// Keep using the cached addresses if the discovery is unavailable.
+		log.Warn("failed to discover microservice", zap.String("service", f.service), errs.ZapError(err))
+		return f.mu.addrs
+	}
+	f.mu.addrs, f.mu.discovered = addrs, time.Now()
+	return addrs
+}
+
+func writeResponse(w http.ResponseWriter, resp *http.Response) {
+	defer resp.Body.Close()
+	for k, vv := range resp.Header {
+		for _, v := range vv {
+			w.Header().Add(k, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		log.Error("failed to write forwarded response", errs.ZapError(errs.ErrWriteHTTPBody, err))
+	}
+}
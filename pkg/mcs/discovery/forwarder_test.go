@@ -0,0 +1,119 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discovery
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/member"
+	"github.com/tikv/pd/pkg/utils/etcdutil"
+	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/embed"
+)
+
+type mockServer struct {
+	cli *clientv3.Client
+}
+
+func (s *mockServer) Name() string                                   { return "pd1" }
+func (s *mockServer) Context() context.Context                       { return context.Background() }
+func (s *mockServer) Run() error                                     { return nil }
+func (s *mockServer) Close()                                         {}
+func (s *mockServer) GetClient() *clientv3.Client                    { return s.cli }
+func (s *mockServer) GetHTTPClient() *http.Client                    { return http.DefaultClient }
+func (s *mockServer) AddStartCallback(...func())                     {}
+func (s *mockServer) GetMember() *member.Member                      { return nil }
+func (s *mockServer) AddLeaderCallback(...func(ctx context.Context)) {}
+
+func TestForwarder(t *testing.T) {
+	re := require.New(t)
+	cfg := etcdutil.NewTestSingleConfig(t)
+	etcd, err := embed.StartEtcd(cfg)
+	re.NoError(err)
+	defer etcd.Close()
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints: []string{cfg.LCUrls[0].String()},
+	})
+	re.NoError(err)
+	defer client.Close()
+	<-etcd.Server.ReadyNotify()
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("From", r.Header.Get(ForwardedByHeader))
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(r.URL.RequestURI() + " " + string(body)))
+	}))
+	defer backend.Close()
+
+	f := NewForwarder(&mockServer{cli: client}, "test_service")
+	local := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("local"))
+	})
+	serve := func(forwardedBy string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/test?a=b", strings.NewReader("body"))
+		if len(forwardedBy) > 0 {
+			req.Header.Set(ForwardedByHeader, forwardedBy)
+		}
+		w := httptest.NewRecorder()
+		f.Handler(local).ServeHTTP(w, req)
+		return w
+	}
+
+	// The requests are served locally if the service is not deployed independently.
+	re.Equal("local", serve("").Body.String())
+
+	// The unavailable instance is skipped.
+	for _, addr := range []string{"127.0.0.1:1", strings.TrimPrefix(backend.URL, "http://")} {
+		sr := NewServiceRegister(context.Background(), client, "test_service", addr, addr, 10)
+		re.NoError(sr.Register())
+		defer sr.cancel()
+	}
+	f.mu.discovered = time.Time{}
+	w := serve("")
+	re.Equal(http.StatusCreated, w.Code)
+	re.Equal("/api/v1/test?a=b body", w.Body.String())
+	re.Equal("pd1", w.Header().Get("From"))
+
+	// The forwarded requests are never forwarded again.
+	re.Equal("local", serve("pd2").Body.String())
+
+	// The too large body is rejected.
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/test", strings.NewReader(strings.Repeat("a", maxForwardBodySize+1)))
+	w = httptest.NewRecorder()
+	f.Handler(local).ServeHTTP(w, req)
+	re.Equal(http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestCanRetry(t *testing.T) {
+	re := require.New(t)
+	dialErr := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	readErr := &net.OpError{Op: "read", Err: errors.New("connection reset by peer")}
+	re.True(canRetry(http.MethodGet, readErr))
+	re.True(canRetry(http.MethodDelete, readErr))
+	re.True(canRetry(http.MethodPost, &url.Error{Op: "Post", Err: dialErr}))
+	re.False(canRetry(http.MethodPost, &url.Error{Op: "Post", Err: readErr}))
+	re.False(canRetry(http.MethodPatch, io.ErrUnexpectedEOF))
+}
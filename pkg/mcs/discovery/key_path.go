@@ -21,6 +21,13 @@ const (
 	registryKey    = "registry"
 )
 
+// The names of the microservices registered to the discovery.
+const (
+	TSOServiceName             = "tso"
+	SchedulingServiceName      = "scheduling"
+	ResourceManagerServiceName = "resource_manager"
+)
+
 func registryPath(serviceName, serviceAddr string) string {
 	return path.Join(registryPrefix, serviceName, registryKey, serviceAddr)
 }
@@ -0,0 +1,160 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strconv"
+
+	"github.com/pingcap/errors"
+	"github.com/tikv/pd/server/schedule/placement"
+)
+
+const (
+	// affinityRuleGroupPrefix is used to prefix the placement rule group of a
+	// resource group's store affinity.
+	affinityRuleGroupPrefix = "resource-group-affinity-"
+	// affinityRuleGroupIndex makes the affinity override the default rules,
+	// while the keyspace placement templates still override the affinity.
+	affinityRuleGroupIndex = 40
+	// defaultAffinityReplicas is the default number of replicas of the data
+	// bound by the store affinity.
+	defaultAffinityReplicas = 3
+)
+
+var errNoRuleManager = errors.New("store affinity requires the resource manager running in PD with placement rules enabled")
+
+// AffinityKeyRange is a key range of the data of a resource group, in hex.
+type AffinityKeyRange struct {
+	StartKeyHex string `json:"start_key"`
+	EndKeyHex   string `json:"end_key"`
+}
+
+// StoreAffinity binds the data of a resource group to the stores matching the
+// label constraints, which is applied as the placement rules of the key ranges.
+type StoreAffinity struct {
+	LabelConstraints []placement.LabelConstraint `json:"label_constraints"`
+	KeyRanges        []*AffinityKeyRange         `json:"key_ranges"`
+	// Count is the number of replicas, defaultAffinityReplicas if it is zero.
+	Count int `json:"count,omitempty"`
+	// LeaderOnly only places the leaders on the bound stores, and the followers
+	// are placed on any store.
+	LeaderOnly     bool     `json:"leader_only,omitempty"`
+	LocationLabels []string `json:"location_labels,omitempty"`
+}
+
+// Validate checks whether the store affinity is valid. The placement rules are
+// checked by the rule manager when the affinity is applied.
+func (a *StoreAffinity) Validate() error {
+	if len(a.LabelConstraints) == 0 {
+		return errors.New("store affinity should contain at least one label constraint")
+	}
+	if len(a.KeyRanges) == 0 {
+		return errors.New("store affinity should contain at least one key range")
+	}
+	if a.Count < 0 || (a.LeaderOnly && a.Count == 1) {
+		return errors.Errorf("invalid replica count %d", a.Count)
+	}
+	for _, r := range a.KeyRanges {
+		if r == nil {
+			return errors.New("key range should not be empty")
+		}
+		start, err := hex.DecodeString(r.StartKeyHex)
+		if err != nil {
+			return errors.Errorf("invalid start key %s", r.StartKeyHex)
+		}
+		end, err := hex.DecodeString(r.EndKeyHex)
+		if err != nil {
+			return errors.Errorf("invalid end key %s", r.EndKeyHex)
+		}
+		if len(end) > 0 && bytes.Compare(start, end) >= 0 {
+			return errors.Errorf("start key %s should be less than end key %s", r.StartKeyHex, r.EndKeyHex)
+		}
+	}
+	return nil
+}
+
+// placementRuleManager applies the placement rules of the store affinities.
+type placementRuleManager interface {
+	SetGroupBundle(group placement.GroupBundle) error
+	DeleteGroupBundle(id string, regex bool) error
+}
+
+// ruleManagerProvider is implemented by the server running the scheduling, i.e.
+// the PD server when the resource manager is not deployed separately.
+type ruleManagerProvider interface {
+	GetRuleManager() *placement.RuleManager
+}
+
+func getAffinityRuleGroupID(name string) string {
+	return affinityRuleGroupPrefix + name
+}
+
+// makeAffinityRuleBundle makes the placement rules of the store affinity for
+// every key range of the resource group.
+func makeAffinityRuleBundle(name string, affinity *StoreAffinity) placement.GroupBundle {
+	bundle := placement.GroupBundle{
+		ID:       getAffinityRuleGroupID(name),
+		Index:    affinityRuleGroupIndex,
+		Override: true,
+	}
+	count := affinity.Count
+	if count == 0 {
+		count = defaultAffinityReplicas
+	}
+	for i, r := range affinity.KeyRanges {
+		id := strconv.Itoa(i)
+		rule := &placement.Rule{
+			GroupID:          bundle.ID,
+			ID:               id,
+			StartKeyHex:      r.StartKeyHex,
+			EndKeyHex:        r.EndKeyHex,
+			Role:             placement.Voter,
+			Count:            count,
+			LabelConstraints: affinity.LabelConstraints,
+			LocationLabels:   affinity.LocationLabels,
+		}
+		if !affinity.LeaderOnly {
+			bundle.Rules = append(bundle.Rules, rule)
+			continue
+		}
+		rule.ID, rule.Role, rule.Count = id+"-leader", placement.Leader, 1
+		bundle.Rules = append(bundle.Rules, rule, &placement.Rule{
+			GroupID:        bundle.ID,
+			ID:             id + "-follower",
+			StartKeyHex:    r.StartKeyHex,
+			EndKeyHex:      r.EndKeyHex,
+			Role:           placement.Follower,
+			Count:          count - 1,
+			LocationLabels: affinity.LocationLabels,
+		})
+	}
+	return bundle
+}
+
+// SetStoreAffinity sets the store affinity of the resource group, nil removes it.
+func (rg *ResourceGroup) SetStoreAffinity(affinity *StoreAffinity) {
+	rg.Lock()
+	defer rg.Unlock()
+	rg.StoreAffinity = affinity
+}
+
+// GetStoreAffinity returns the store affinity of the resource group.
+func (rg *ResourceGroup) GetStoreAffinity() *StoreAffinity {
+	rg.RLock()
+	defer rg.RUnlock()
+	return rg.StoreAffinity
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	rmpb "github.com/pingcap/kvproto/pkg/resource_manager"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/storage/kv"
+	"github.com/tikv/pd/server/schedule/placement"
+)
+
+type mockRuleManager struct {
+	bundles map[string]placement.GroupBundle
+}
+
+func (m *mockRuleManager) SetGroupBundle(group placement.GroupBundle) error {
+	m.bundles[group.ID] = group
+	return nil
+}
+
+func (m *mockRuleManager) DeleteGroupBundle(id string, _ bool) error {
+	delete(m.bundles, id)
+	return nil
+}
+
+func TestMakeAffinityRuleBundle(t *testing.T) {
+	re := require.New(t)
+	constraints := []placement.LabelConstraint{{Key: "tenant", Op: placement.In, Values: []string{"premium"}}}
+	for _, affinity := range []*StoreAffinity{
+		{KeyRanges: []*AffinityKeyRange{{StartKeyHex: "01"}}},
+		{LabelConstraints: constraints},
+		{LabelConstraints: constraints, KeyRanges: []*AffinityKeyRange{{StartKeyHex: "zz"}}},
+		{LabelConstraints: constraints, KeyRanges: []*AffinityKeyRange{{StartKeyHex: "02", EndKeyHex: "01"}}},
+		{LabelConstraints: constraints, KeyRanges: []*AffinityKeyRange{{StartKeyHex: "01"}}, Count: 1, LeaderOnly: true},
+	} {
+		re.Error(affinity.Validate())
+	}
+
+	affinity := &StoreAffinity{
+		LabelConstraints: constraints,
+		KeyRanges:        []*AffinityKeyRange{{StartKeyHex: "01", EndKeyHex: "02"}, {StartKeyHex: "03"}},
+	}
+	re.NoError(affinity.Validate())
+	bundle := makeAffinityRuleBundle("rg1", affinity)
+	re.Equal("resource-group-affinity-rg1", bundle.ID)
+	re.True(bundle.Override)
+	re.Len(bundle.Rules, 2)
+	for _, rule := range bundle.Rules {
+		re.Equal(placement.Voter, rule.Role)
+		re.Equal(defaultAffinityReplicas, rule.Count)
+		re.Equal(constraints, rule.LabelConstraints)
+	}
+	re.Equal("03", bundle.Rules[1].StartKeyHex)
+
+	// Only the leaders are bound to the stores.
+	affinity.LeaderOnly, affinity.Count = true, 5
+	bundle = makeAffinityRuleBundle("rg1", affinity)
+	re.Len(bundle.Rules, 4)
+	re.Equal(placement.Leader, bundle.Rules[0].Role)
+	re.Equal(1, bundle.Rules[0].Count)
+	re.Equal(constraints, bundle.Rules[0].LabelConstraints)
+	re.Equal(placement.Follower, bundle.Rules[1].Role)
+	re.Equal(4, bundle.Rules[1].Count)
+	re.Empty(bundle.Rules[1].LabelConstraints)
+}
+
+func TestResourceGroupStoreAffinity(t *testing.T) {
+	re := require.New(t)
+	storage := endpoint.NewStorageEndpoint(kv.NewMemoryKV(), nil)
+	m := newTestManager(storage)
+	re.NoError(m.AddResourceGroup(&ResourceGroup{Name: "test", Mode: rmpb.GroupMode_RUMode}))
+	affinity := &StoreAffinity{
+		LabelConstraints: []placement.LabelConstraint{{Key: "tenant", Op: placement.In, Values: []string{"premium"}}},
+		KeyRanges:        []*AffinityKeyRange{{StartKeyHex: "01", EndKeyHex: "02"}},
+	}
+	// The affinity can not be applied without the placement rules.
+	re.Error(m.SetResourceGroupStoreAffinity("test", affinity))
+	ruleManager := &mockRuleManager{bundles: make(map[string]placement.GroupBundle)}
+	m.ruleManager = func() placementRuleManager { return ruleManager }
+	re.Error(m.SetResourceGroupStoreAffinity("unknown", affinity))
+	re.NoError(m.SetResourceGroupStoreAffinity("test", affinity))
+	re.Contains(ruleManager.bundles, getAffinityRuleGroupID("test"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := newTestManager(storage)
+	reloaded.ruleManager = m.ruleManager
+	reloaded.Init(ctx)
+	re.Equal(affinity, reloaded.GetResourceGroup("test").StoreAffinity)
+	re.NoError(reloaded.SetResourceGroupStoreAffinity("test", nil))
+	re.Empty(ruleManager.bundles)
+	re.Nil(reloaded.GetResourceGroup("test").StoreAffinity)
+
+	// The rules are removed with the group.
+	re.NoError(reloaded.SetResourceGroupStoreAffinity("test", affinity))
+	re.NoError(reloaded.DeleteResourceGroup("test"))
+	re.Empty(ruleManager.bundles)
+}
@@ -16,12 +16,16 @@ package apis
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
 	rmpb "github.com/pingcap/kvproto/pkg/resource_manager"
+	"github.com/tikv/pd/pkg/errs"
 	rmserver "github.com/tikv/pd/pkg/mcs/resource_manager/server"
 	"github.com/tikv/pd/pkg/utils/apiutil"
 )
@@ -79,6 +83,43 @@ func (s *Service) RegisterRouter() {
 	configEndpoint.GET("/group/:name", s.getResourceGroup)
 	configEndpoint.GET("/groups", s.getResourceGroupList)
 	configEndpoint.DELETE("/group/:name", s.deleteResourceGroup)
+	configEndpoint.PUT("/group/:name/burst", s.putResourceGroupBurst)
+	configEndpoint.DELETE("/group/:name/burst", s.deleteResourceGroupBurst)
+	configEndpoint.PUT("/group/:name/background", s.putResourceGroupBackground)
+	configEndpoint.DELETE("/group/:name/background", s.deleteResourceGroupBackground)
+	configEndpoint.PUT("/group/:name/priority", s.putResourceGroupPriority)
+	configEndpoint.PUT("/group/:name/quotas", s.putResourceGroupQuotas)
+	configEndpoint.DELETE("/group/:name/quotas", s.deleteResourceGroupQuotas)
+	configEndpoint.GET("/borrow-pool", s.getBorrowPool)
+	configEndpoint.GET("/priority-scheduler", s.getPrioritySchedulerConfig)
+	configEndpoint.PUT("/priority-scheduler", s.putPrioritySchedulerConfig)
+	configEndpoint.GET("/templates", s.getResourceGroupTemplates)
+	configEndpoint.PUT("/template", s.putResourceGroupTemplate)
+	configEndpoint.DELETE("/template/:name", s.deleteResourceGroupTemplate)
+	configEndpoint.PUT("/group/:name/template", s.putResourceGroupInheritance)
+	configEndpoint.GET("/consumption-history", s.getConsumptionHistoryConfig)
+	configEndpoint.PUT("/consumption-history", s.putConsumptionHistoryConfig)
+	configEndpoint.GET("/group/:name/token-hint", s.getResourceGroupTokenHint)
+	configEndpoint.PUT("/group/:name/affinity", s.putResourceGroupAffinity)
+	configEndpoint.DELETE("/group/:name/affinity", s.deleteResourceGroupAffinity)
+	configEndpoint.GET("/group/:name/runaway", s.getResourceGroupRunaway)
+	configEndpoint.PUT("/group/:name/runaway", s.putResourceGroupRunaway)
+	configEndpoint.DELETE("/group/:name/runaway", s.deleteResourceGroupRunaway)
+	configEndpoint.GET("/runaway-watches", s.getRunawayWatches)
+	configEndpoint.POST("/runaway-watch", s.postRunawayWatch)
+	configEndpoint.DELETE("/runaway-watch/:id", s.deleteRunawayWatch)
+	configEndpoint.GET("/keyspace/:keyspace_id/groups", s.getKeyspaceResourceGroupList)
+	configEndpoint.GET("/groups/export", s.exportResourceGroups)
+	configEndpoint.POST("/groups/import", s.importResourceGroups)
+	configEndpoint.GET("/ru-model", s.getRUModel)
+	configEndpoint.PUT("/ru-model", s.putRUModel)
+	configEndpoint.GET("/ru-model/history", s.getRUModelHistory)
+	configEndpoint.POST("/ru-model/preview", s.previewRUModel)
+	configEndpoint.POST("/keyspace/:keyspace_id/migrate", s.migrateResourceGroupsToKeyspace)
+	consumptionEndpoint := s.baseEndpoint.Group("/consumption")
+	consumptionEndpoint.POST("", s.postConsumption)
+	consumptionEndpoint.GET("", s.getConsumption)
+	consumptionEndpoint.GET("/history", s.getConsumptionHistory)
 }
 
 func (s *Service) handler() http.Handler {
@@ -161,3 +202,622 @@ func (s *Service) deleteResourceGroup(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, "Success!")
 }
+
+// @Summary set the burst settings of a resource group.
+// @Param name string true "groupName"
+// @Param burst body of "BurstSettings", json format.
+// @Success 200 "set successfully"
+// @Failure 400 {object} error
+// @Failure 500 {object} error
+// @Router /config/group/{name}/burst [PUT]
+func (s *Service) putResourceGroupBurst(c *gin.Context) {
+	var burst rmserver.BurstSettings
+	if err := c.ShouldBindJSON(&burst); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := burst.Validate(); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.manager.SetResourceGroupBurstSettings(c.Param("name"), &burst); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, "Success!")
+}
+
+// @Summary remove the burst settings of a resource group.
+// @Param name string true "groupName"
+// @Success 200 "removed successfully"
+// @Failure 500 {object} error
+// @Router /config/group/{name}/burst [DELETE]
+func (s *Service) deleteResourceGroupBurst(c *gin.Context) {
+	if err := s.manager.SetResourceGroupBurstSettings(c.Param("name"), nil); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, "Success!")
+}
+
+// @Summary set the settings of the background tasks of a resource group.
+// @Param name string true "groupName"
+// @Param background body of "BackgroundSettings", json format.
+// @Success 200 "set successfully"
+// @Failure 400 {object} error
+// @Failure 500 {object} error
+// @Router /config/group/{name}/background [PUT]
+func (s *Service) putResourceGroupBackground(c *gin.Context) {
+	var settings rmserver.BackgroundSettings
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := settings.Validate(); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.manager.SetResourceGroupBackgroundSettings(c.Param("name"), &settings); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, "Success!")
+}
+
+// @Summary remove the settings of the background tasks of a resource group.
+// @Param name string true "groupName"
+// @Success 200 "removed successfully"
+// @Failure 500 {object} error
+// @Router /config/group/{name}/background [DELETE]
+func (s *Service) deleteResourceGroupBackground(c *gin.Context) {
+	if err := s.manager.SetResourceGroupBackgroundSettings(c.Param("name"), nil); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, "Success!")
+}
+
+// @Summary get the number of tokens in the pool shared by resource groups to borrow.
+// @Success 200 {object} float64
+// @Router /config/borrow-pool [GET]
+func (s *Service) getBorrowPool(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"tokens": s.manager.GetBorrowPool().GetTokens()})
+}
+
+// @Summary set the priority of a resource group.
+// @Param name string true "groupName"
+// @Param priority body of "priority", json format.
+// @Success 200 "set successfully"
+// @Failure 400 {object} error
+// @Failure 500 {object} error
+// @Router /config/group/{name}/priority [PUT]
+func (s *Service) putResourceGroupPriority(c *gin.Context) {
+	var input struct {
+		Priority uint32 `json:"priority"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.manager.SetResourceGroupPriority(c.Param("name"), input.Priority); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, "Success!")
+}
+
+// @Summary set the RU quotas in the calendar windows of a resource group.
+// @Param name string true "groupName"
+// @Param quotas body of "[]RUQuota", json format.
+// @Success 200 "set successfully"
+// @Failure 400 {object} error
+// @Failure 500 {object} error
+// @Router /config/group/{name}/quotas [PUT]
+func (s *Service) putResourceGroupQuotas(c *gin.Context) {
+	var quotas []*rmserver.RUQuota
+	if err := c.ShouldBindJSON(&quotas); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.manager.SetResourceGroupRUQuotas(c.Param("name"), quotas); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, "Success!")
+}
+
+// @Summary remove the RU quotas of a resource group.
+// @Param name string true "groupName"
+// @Success 200 "removed successfully"
+// @Failure 500 {object} error
+// @Router /config/group/{name}/quotas [DELETE]
+func (s *Service) deleteResourceGroupQuotas(c *gin.Context) {
+	if err := s.manager.SetResourceGroupRUQuotas(c.Param("name"), nil); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, "Success!")
+}
+
+// @Summary get the config of the priority scheduler.
+// @Success 200 {object} rmserver.PrioritySchedulerConfig
+// @Router /config/priority-scheduler [GET]
+func (s *Service) getPrioritySchedulerConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, s.manager.GetPrioritySchedulerConfig())
+}
+
+// @Summary update the config of the priority scheduler.
+// @Param config body of "PrioritySchedulerConfig", json format.
+// @Success 200 "updated successfully"
+// @Failure 400 {object} error
+// @Failure 500 {object} error
+// @Router /config/priority-scheduler [PUT]
+func (s *Service) putPrioritySchedulerConfig(c *gin.Context) {
+	var config rmserver.PrioritySchedulerConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := config.Validate(); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.manager.SetPrioritySchedulerConfig(config); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, "Success!")
+}
+
+// @Summary get all resource group templates.
+// @Success 200 {array} ResourceGroupTemplate
+// @Router /config/templates [GET]
+func (s *Service) getResourceGroupTemplates(c *gin.Context) {
+	c.JSON(http.StatusOK, s.manager.GetResourceGroupTemplates())
+}
+
+// @Summary create or update a resource group template, and apply it to the inheriting groups.
+// @Param template body of "ResourceGroupTemplate", json format.
+// @Success 200 "put successfully"
+// @Failure 400 {object} error
+// @Failure 500 {object} error
+// @Router /config/template [PUT]
+func (s *Service) putResourceGroupTemplate(c *gin.Context) {
+	var template rmserver.ResourceGroupTemplate
+	if err := c.ShouldBindJSON(&template); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := template.Validate(); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.manager.PutResourceGroupTemplate(&template); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, "Success!")
+}
+
+// @Summary delete a resource group template not inherited by any group.
+// @Param name string true "templateName"
+// @Success 200 "deleted successfully"
+// @Failure 500 {object} error
+// @Router /config/template/{name} [DELETE]
+func (s *Service) deleteResourceGroupTemplate(c *gin.Context) {
+	if err := s.manager.DeleteResourceGroupTemplate(c.Param("name")); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, "Success!")
+}
+
+// @Summary set the template a resource group inherits from, empty template stops inheriting.
+// @Param name string true "groupName"
+// @Param inheritance body of "{template, overrides}", json format.
+// @Success 200 "set successfully"
+// @Failure 400 {object} error
+// @Failure 500 {object} error
+// @Router /config/group/{name}/template [PUT]
+func (s *Service) putResourceGroupInheritance(c *gin.Context) {
+	var input struct {
+		Template  string                     `json:"template"`
+		Overrides *rmserver.TemplateSettings `json:"overrides"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.manager.SetResourceGroupTemplate(c.Param("name"), input.Template, input.Overrides); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, "Success!")
+}
+
+// @Summary report the RU consumption of resource groups on stores.
+// @Param records body of "[]ConsumptionRecord", json format.
+// @Success 200 "reported successfully"
+// @Failure 400 {object} error
+// @Router /consumption [POST]
+func (s *Service) postConsumption(c *gin.Context) {
+	var records []*rmserver.ConsumptionRecord
+	if err := c.ShouldBindJSON(&records); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.manager.ReportConsumption(records); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, "Success!")
+}
+
+// @Summary get the aggregated RU consumption of resource groups on stores.
+// @Param group query string false "groupName"
+// @Param store_id query integer false "storeID"
+// @Success 200 {array} ConsumptionRecord
+// @Failure 400 {object} error
+// @Router /consumption [GET]
+func (s *Service) getConsumption(c *gin.Context) {
+	var storeID uint64
+	if storeIDStr := c.Query("store_id"); len(storeIDStr) > 0 {
+		var err error
+		if storeID, err = strconv.ParseUint(storeIDStr, 10, 64); err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	c.JSON(http.StatusOK, s.manager.QueryConsumption(c.Query("group"), storeID))
+}
+
+// @Summary get the config of the RU consumption history.
+// @Success 200 {object} rmserver.ConsumptionHistoryConfig
+// @Router /config/consumption-history [GET]
+func (s *Service) getConsumptionHistoryConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, s.manager.GetConsumptionHistoryConfig())
+}
+
+// @Summary update the config of the RU consumption history.
+// @Param config body of "ConsumptionHistoryConfig", json format.
+// @Success 200 "updated successfully"
+// @Failure 400 {object} error
+// @Failure 500 {object} error
+// @Router /config/consumption-history [PUT]
+func (s *Service) putConsumptionHistoryConfig(c *gin.Context) {
+	var config rmserver.ConsumptionHistoryConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := config.Validate(); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.manager.SetConsumptionHistoryConfig(config); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, "Success!")
+}
+
+// @Summary get the RU consumption history of resource groups by minute, or summed up by group.
+// @Param group query string false "groupName"
+// @Param start query integer false "unix timestamp in seconds, 0 by default"
+// @Param end query integer false "unix timestamp in seconds, now by default"
+// @Param sum query boolean false "sum up by group and sort by the total RU"
+// @Success 200 {array} ConsumptionHistoryRecord
+// @Failure 400 {object} error
+// @Failure 500 {object} error
+// @Router /consumption/history [GET]
+func (s *Service) getConsumptionHistory(c *gin.Context) {
+	start, end := time.Unix(0, 0), time.Now()
+	parseTime := func(key string, t *time.Time) error {
+		if str := c.Query(key); len(str) > 0 {
+			sec, err := strconv.ParseInt(str, 10, 64)
+			if err != nil || sec < 0 {
+				return fmt.Errorf("invalid %s: %s", key, str)
+			}
+			*t = time.Unix(sec, 0)
+		}
+		return nil
+	}
+	if err := parseTime("start", &start); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := parseTime("end", &end); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	var (
+		records []*rmserver.ConsumptionHistoryRecord
+		err     error
+	)
+	if c.Query("sum") == "true" {
+		if len(c.Query("group")) > 0 {
+			c.String(http.StatusBadRequest, "group should not be specified to sum up")
+			return
+		}
+		records, err = s.manager.SumConsumptionHistory(start, end)
+	} else {
+		records, err = s.manager.QueryConsumptionHistory(c.Query("group"), start, end)
+	}
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, records)
+}
+
+// @Summary get the load-aware hint of the token requests of a resource group.
+// @Param name string true "groupName"
+// @Success 200 {object} rmserver.TokenHint
+// @Failure 404 {object} error
+// @Router /config/group/{name}/token-hint [GET]
+func (s *Service) getResourceGroupTokenHint(c *gin.Context) {
+	group := s.manager.GetMutableResourceGroup(c.Param("name"))
+	if group == nil {
+		c.String(http.StatusNotFound, "resource group not found")
+		return
+	}
+	c.JSON(http.StatusOK, group.GetTokenHint())
+}
+
+// @Summary bind the data of a resource group to the stores matching the label constraints.
+// @Param name string true "groupName"
+// @Param affinity body of "StoreAffinity", json format.
+// @Success 200 "set successfully"
+// @Failure 400 {object} error
+// @Failure 500 {object} error
+// @Router /config/group/{name}/affinity [PUT]
+func (s *Service) putResourceGroupAffinity(c *gin.Context) {
+	var affinity rmserver.StoreAffinity
+	if err := c.ShouldBindJSON(&affinity); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := affinity.Validate(); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.manager.SetResourceGroupStoreAffinity(c.Param("name"), &affinity); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, "Success!")
+}
+
+// @Summary unbind the data of a resource group from the stores.
+// @Param name string true "groupName"
+// @Success 200 "removed successfully"
+// @Failure 500 {object} error
+// @Router /config/group/{name}/affinity [DELETE]
+func (s *Service) deleteResourceGroupAffinity(c *gin.Context) {
+	if err := s.manager.SetResourceGroupStoreAffinity(c.Param("name"), nil); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, "Success!")
+}
+
+// @Summary get the runaway settings of a resource group.
+// @Param name string true "groupName"
+// @Success 200 {object} rmserver.RunawaySettings
+// @Failure 404 {object} error
+// @Router /config/group/{name}/runaway [GET]
+func (s *Service) getResourceGroupRunaway(c *gin.Context) {
+	settings := s.manager.GetResourceGroupRunawaySettings(c.Param("name"))
+	if settings == nil {
+		c.String(http.StatusNotFound, "runaway settings not found")
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// @Summary set the runaway settings of a resource group.
+// @Param name string true "groupName"
+// @Param runaway body of "RunawaySettings", json format.
+// @Success 200 "set successfully"
+// @Failure 400 {object} error
+// @Failure 500 {object} error
+// @Router /config/group/{name}/runaway [PUT]
+func (s *Service) putResourceGroupRunaway(c *gin.Context) {
+	var settings rmserver.RunawaySettings
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := settings.Validate(); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.manager.SetResourceGroupRunawaySettings(c.Param("name"), &settings); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, "Success!")
+}
+
+// @Summary remove the runaway settings of a resource group.
+// @Param name string true "groupName"
+// @Success 200 "removed successfully"
+// @Failure 500 {object} error
+// @Router /config/group/{name}/runaway [DELETE]
+func (s *Service) deleteResourceGroupRunaway(c *gin.Context) {
+	if err := s.manager.SetResourceGroupRunawaySettings(c.Param("name"), nil); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, "Success!")
+}
+
+// @Summary get the unexpired runaway watch items.
+// @Param group query string false "groupName"
+// @Success 200 {array} RunawayWatchItem
+// @Router /config/runaway-watches [GET]
+func (s *Service) getRunawayWatches(c *gin.Context) {
+	c.JSON(http.StatusOK, s.manager.GetRunawayWatches(c.Query("group")))
+}
+
+// @Summary add a runaway watch item, the ID is allocated by the server.
+// @Param item body of "RunawayWatchItem", json format.
+// @Success 200 {object} RunawayWatchItem
+// @Failure 400 {object} error
+// @Failure 500 {object} error
+// @Router /config/runaway-watch [POST]
+func (s *Service) postRunawayWatch(c *gin.Context) {
+	var item rmserver.RunawayWatchItem
+	if err := c.ShouldBindJSON(&item); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	added, err := s.manager.AddRunawayWatch(&item)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, added)
+}
+
+// @Summary delete a runaway watch item by ID.
+// @Param id integer true "watchID"
+// @Success 200 "deleted successfully"
+// @Failure 400 {object} error
+// @Failure 500 {object} error
+// @Router /config/runaway-watch/{id} [DELETE]
+func (s *Service) deleteRunawayWatch(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.manager.DeleteRunawayWatch(id); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, "Success!")
+}
+
+// @Summary get the resource groups in a keyspace.
+// @Param keyspace_id string true "keyspaceID"
+// @Success 200 {array} ResourceGroup
+// @Router /config/keyspace/{keyspace_id}/groups [GET]
+func (s *Service) getKeyspaceResourceGroupList(c *gin.Context) {
+	c.JSON(http.StatusOK, s.manager.GetKeyspaceResourceGroupList(c.Param("keyspace_id")))
+}
+
+// @Summary move the global resource groups into a keyspace, all the global groups are moved if no names are given.
+// @Param keyspace_id string true "keyspaceID"
+// @Param names body array false "groupNames", json format.
+// @Success 200 {array} string
+// @Failure 400 {object} error
+// @Failure 500 {object} error
+// @Router /config/keyspace/{keyspace_id}/migrate [POST]
+func (s *Service) migrateResourceGroupsToKeyspace(c *gin.Context) {
+	var names []string
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&names); err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	migrated, err := s.manager.MigrateResourceGroupsToKeyspace(c.Param("keyspace_id"), names)
+	if err != nil {
+		if errs.ErrInvalidResourceGroupMigration.Equal(err) {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, migrated)
+}
+
+// @Summary export the definitions of all the resource groups and the templates as a document.
+// @Success 200 {object} ResourceGroupsDocument
+// @Router /config/groups/export [GET]
+func (s *Service) exportResourceGroups(c *gin.Context) {
+	c.JSON(http.StatusOK, s.manager.ExportResourceGroups())
+}
+
+// @Summary import the resource groups from a document, and return the changes.
+// @Param doc body of "ResourceGroupsDocument", json format.
+// @Param dry_run query bool false "only preview the changes"
+// @Param prune query bool false "remove the resource groups not in the document"
+// @Success 200 {object} ResourceGroupsDiff
+// @Failure 400 {object} error
+// @Failure 500 {object} error
+// @Router /config/groups/import [POST]
+func (s *Service) importResourceGroups(c *gin.Context) {
+	var doc rmserver.ResourceGroupsDocument
+	if err := c.ShouldBindJSON(&doc); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	dryRun, prune := c.Query("dry_run") == "true", c.Query("prune") == "true"
+	diff, err := s.manager.ImportResourceGroups(&doc, prune, dryRun)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, diff)
+}
+
+// @Summary get the current RU model.
+// @Success 200 {object} RUModel
+// @Router /config/ru-model [GET]
+func (s *Service) getRUModel(c *gin.Context) {
+	c.JSON(http.StatusOK, s.manager.GetRUModel())
+}
+
+// @Summary update the RU model as a new version, which is pushed to the clients.
+// @Param model body of "RUModel", json format.
+// @Success 200 "updated successfully"
+// @Failure 400 {object} error
+// @Failure 500 {object} error
+// @Router /config/ru-model [PUT]
+func (s *Service) putRUModel(c *gin.Context) {
+	var model rmserver.RUModel
+	if err := c.ShouldBindJSON(&model); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.manager.SetRUModel(&model); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, "Success!")
+}
+
+// @Summary get the previous RU models from the newest.
+// @Success 200 {array} RUModel
+// @Failure 500 {object} error
+// @Router /config/ru-model/history [GET]
+func (s *Service) getRUModelHistory(c *gin.Context) {
+	history, err := s.manager.GetRUModelHistory()
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+// @Summary preview how the recent workload would have been priced by the RU model.
+// @Param model body of "RUModel", json format.
+// @Success 200 {array} RUModelPreview
+// @Failure 400 {object} error
+// @Router /config/ru-model/preview [POST]
+func (s *Service) previewRUModel(c *gin.Context) {
+	var model rmserver.RUModel
+	if err := c.ShouldBindJSON(&model); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	previews, err := s.manager.PreviewRUModel(&model)
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, previews)
+}
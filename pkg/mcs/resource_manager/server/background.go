@@ -0,0 +1,110 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,g
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"strings"
+
+	"github.com/pingcap/errors"
+	rmpb "github.com/pingcap/kvproto/pkg/resource_manager"
+)
+
+// backgroundTaskSeparator separates the resource group name and the background
+// task type in the name of the token bucket request of a background task, e.g.
+// "rg1$br", since the protocol doesn't carry the task type.
+const backgroundTaskSeparator = "$"
+
+// defaultDeprioritizedRatio is the default ratio of the tokens granted to the
+// background tasks while the foreground is throttled.
+const defaultDeprioritizedRatio = 0.1
+
+// The background task types.
+const (
+	BackgroundTaskBR        = "br"
+	BackgroundTaskLightning = "lightning"
+	BackgroundTaskAnalyze   = "analyze"
+	BackgroundTaskDDL       = "ddl"
+)
+
+var backgroundTaskTypes = map[string]struct{}{
+	BackgroundTaskBR:        {},
+	BackgroundTaskLightning: {},
+	BackgroundTaskAnalyze:   {},
+	BackgroundTaskDDL:       {},
+}
+
+// BackgroundTaskGroupName returns the name used by the token bucket requests of
+// the background task of the resource group.
+func BackgroundTaskGroupName(groupName, taskType string) string {
+	return groupName + backgroundTaskSeparator + taskType
+}
+
+// parseBackgroundTaskGroupName parses the resource group name and the background
+// task type from the name of a token bucket request. The task type is empty for
+// the foreground requests.
+func parseBackgroundTaskGroupName(name string) (groupName, taskType string) {
+	if i := strings.LastIndex(name, backgroundTaskSeparator); i >= 0 {
+		return name[:i], name[i+len(backgroundTaskSeparator):]
+	}
+	return name, ""
+}
+
+// BackgroundSettings is the settings of the background tasks of a resource group.
+// The background tasks of the classified types consume a separate RU budget, and
+// they are deprioritized while the foreground is throttled, i.e. the foreground
+// RU tokens are exhausted and loaned, which implies the foreground latency
+// degrades. The background tasks of other types consume the foreground budget.
+type BackgroundSettings struct {
+	// TaskTypes are the classified background task types.
+	TaskTypes []string `json:"task_types"`
+	// Settings is the settings of the background RU budget.
+	Settings *rmpb.TokenLimitSettings `json:"settings,omitempty"`
+	// DeprioritizedRatio is the ratio of the requested tokens granted to the
+	// background tasks while the foreground is throttled.
+	// Zero means defaultDeprioritizedRatio.
+	DeprioritizedRatio float64 `json:"deprioritized_ratio,omitempty"`
+}
+
+// Validate checks whether the background settings are valid.
+func (s *BackgroundSettings) Validate() error {
+	if len(s.TaskTypes) == 0 {
+		return errors.New("background task types should not be empty")
+	}
+	for _, taskType := range s.TaskTypes {
+		if _, ok := backgroundTaskTypes[taskType]; !ok {
+			return errors.Errorf("unknown background task type %s", taskType)
+		}
+	}
+	if s.DeprioritizedRatio < 0 || s.DeprioritizedRatio > 1 {
+		return errors.Errorf("invalid deprioritized ratio %v, it should be in [0,1]", s.DeprioritizedRatio)
+	}
+	return nil
+}
+
+func (s *BackgroundSettings) classify(taskType string) bool {
+	for _, t := range s.TaskTypes {
+		if t == taskType {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *BackgroundSettings) getDeprioritizedRatio() float64 {
+	if s.DeprioritizedRatio == 0 {
+		return defaultDeprioritizedRatio
+	}
+	return s.DeprioritizedRatio
+}
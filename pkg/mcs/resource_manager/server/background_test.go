@@ -0,0 +1,99 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	rmpb "github.com/pingcap/kvproto/pkg/resource_manager"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBackgroundTaskGroupName(t *testing.T) {
+	re := require.New(t)
+	groupName, taskType := parseBackgroundTaskGroupName(BackgroundTaskGroupName("test", BackgroundTaskBR))
+	re.Equal("test", groupName)
+	re.Equal(BackgroundTaskBR, taskType)
+	groupName, taskType = parseBackgroundTaskGroupName("test")
+	re.Equal("test", groupName)
+	re.Empty(taskType)
+
+	rg := &ResourceGroup{Name: BackgroundTaskGroupName("test", BackgroundTaskDDL), Mode: rmpb.GroupMode_RUMode}
+	re.Error(rg.CheckAndInit())
+}
+
+func TestValidateBackgroundSettings(t *testing.T) {
+	re := require.New(t)
+	for _, settings := range []*BackgroundSettings{
+		{},
+		{TaskTypes: []string{"unknown"}},
+		{TaskTypes: []string{BackgroundTaskBR}, DeprioritizedRatio: -0.1},
+		{TaskTypes: []string{BackgroundTaskBR}, DeprioritizedRatio: 1.1},
+	} {
+		re.Error(settings.Validate())
+	}
+	settings := &BackgroundSettings{
+		TaskTypes:          []string{BackgroundTaskBR, BackgroundTaskLightning, BackgroundTaskAnalyze, BackgroundTaskDDL},
+		DeprioritizedRatio: 1,
+	}
+	re.NoError(settings.Validate())
+	re.True(settings.classify(BackgroundTaskDDL))
+	re.False(settings.classify("unknown"))
+	re.Equal(defaultDeprioritizedRatio, (&BackgroundSettings{}).getDeprioritizedRatio())
+}
+
+func TestRequestBackgroundRU(t *testing.T) {
+	re := require.New(t)
+	rg := &ResourceGroup{
+		Name: "test",
+		Mode: rmpb.GroupMode_RUMode,
+		RUSettings: NewRequestUnitSettings(&rmpb.TokenBucket{
+			Settings: &rmpb.TokenLimitSettings{FillRate: 1000, BurstLimit: 200000},
+		}),
+	}
+	re.NoError(rg.CheckAndInit())
+	re.NoError(rg.SetBackgroundSettings(&BackgroundSettings{
+		TaskTypes:          []string{BackgroundTaskBR},
+		Settings:           &rmpb.TokenLimitSettings{FillRate: 500},
+		DeprioritizedRatio: 0.5,
+	}))
+	targetPeriodMs := uint64(time.Second / time.Millisecond)
+	now := time.Now()
+
+	// The classified background task consumes the background budget.
+	tb := rg.RequestBackgroundRU(now, BackgroundTaskBR, 1000, targetPeriodMs)
+	re.Equal(1000., tb.GrantedTokens.Tokens)
+	re.False(rg.RUSettings.RU.Initialized)
+	re.Equal(defaultInitialTokens-1000., rg.RUSettings.Background.Tokens)
+	re.Equal(uint64(500), rg.RUSettings.Background.Settings.FillRate)
+	// Others consume the foreground budget.
+	tb = rg.RequestBackgroundRU(now, BackgroundTaskDDL, 1000, targetPeriodMs)
+	re.Equal(1000., tb.GrantedTokens.Tokens)
+	re.Equal(defaultInitialTokens-1000., rg.RUSettings.RU.Tokens)
+
+	// The background task is deprioritized while the foreground is throttled.
+	rg.RequestRU(now, defaultInitialTokens, targetPeriodMs)
+	re.Less(rg.RUSettings.RU.Tokens, 0.)
+	tb = rg.RequestBackgroundRU(now, BackgroundTaskBR, 1000, targetPeriodMs)
+	re.Equal(500., tb.GrantedTokens.Tokens)
+	re.Equal(defaultInitialTokens-1500., rg.RUSettings.Background.Tokens)
+
+	// The background settings are kept in the extended settings and copies.
+	extended := rg.GetExtendedSettings()
+	re.Equal([]string{BackgroundTaskBR}, extended.Background.TaskTypes)
+	re.Equal(uint64(500), extended.Background.Settings.FillRate)
+	copied := rg.Copy()
+	re.Equal(rg.RUSettings.BackgroundSettings, copied.RUSettings.BackgroundSettings)
+	re.Equal(rg.RUSettings.Background.Tokens, copied.RUSettings.Background.Tokens)
+
+	// The background tasks consume the foreground budget after removing the settings.
+	re.NoError(rg.SetBackgroundSettings(nil))
+	re.Nil(rg.RUSettings.Background)
+	re.Nil(rg.GetExtendedSettings().Background)
+	foregroundTokens := rg.RUSettings.RU.Tokens
+	rg.RequestBackgroundRU(now, BackgroundTaskBR, 1000, targetPeriodMs)
+	re.Less(rg.RUSettings.RU.Tokens, foregroundTokens)
+
+	rawGroup := &ResourceGroup{Name: "raw", Mode: rmpb.GroupMode_RawMode}
+	re.NoError(rawGroup.CheckAndInit())
+	re.Error(rawGroup.SetBackgroundSettings(&BackgroundSettings{TaskTypes: []string{BackgroundTaskBR}}))
+}
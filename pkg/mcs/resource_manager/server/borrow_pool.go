@@ -0,0 +1,65 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,g
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"math"
+	"sync"
+)
+
+// defaultBorrowPoolCapacity is the default max number of tokens in the borrow pool.
+const defaultBorrowPoolCapacity = 10 * defaultInitialTokens
+
+// BorrowPool is the pool shared by all the resource groups. The tokens filled
+// beyond the burst limits and credits of the idle groups are donated to the
+// pool, and the groups enabling borrowing may borrow them when their own tokens
+// are exhausted, so the spiky groups are not throttled while the cluster is
+// underutilized. The tokens in the pool are not persisted.
+type BorrowPool struct {
+	sync.Mutex
+	capacity float64
+	tokens   float64
+}
+
+// NewBorrowPool creates a new BorrowPool holding at most capacity tokens.
+func NewBorrowPool(capacity float64) *BorrowPool {
+	return &BorrowPool{capacity: capacity}
+}
+
+// GetTokens returns the number of tokens in the pool.
+func (p *BorrowPool) GetTokens() float64 {
+	p.Lock()
+	defer p.Unlock()
+	return p.tokens
+}
+
+func (p *BorrowPool) donate(tokens float64) {
+	p.Lock()
+	defer p.Unlock()
+	p.tokens = math.Min(p.tokens+tokens, p.capacity)
+}
+
+// borrow takes at most the needed tokens from the pool and returns the number of
+// tokens taken.
+func (p *BorrowPool) borrow(neededTokens float64) float64 {
+	p.Lock()
+	defer p.Unlock()
+	borrowed := math.Min(p.tokens, neededTokens)
+	if borrowed <= 0 {
+		return 0
+	}
+	p.tokens -= borrowed
+	return borrowed
+}
@@ -0,0 +1,129 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,g
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/pingcap/errors"
+)
+
+// unknownStoreID is the store ID of the consumption not reported with its store,
+// e.g. the consumption reported by the token bucket requests.
+const unknownStoreID uint64 = 0
+
+// ConsumptionRecord is the RU consumption of a resource group on a store.
+type ConsumptionRecord struct {
+	Group   string `json:"group"`
+	StoreID uint64 `json:"store_id"`
+	// StatementType is the type of the statements consuming the RU, empty if not reported.
+	StatementType string  `json:"statement_type,omitempty"`
+	RRU           float64 `json:"rru"`
+	WRU           float64 `json:"wru"`
+}
+
+func (r *ConsumptionRecord) validate() error {
+	if len(r.Group) == 0 {
+		return errors.New("resource group name should not be empty")
+	}
+	for _, ru := range []float64{r.RRU, r.WRU} {
+		if ru < 0 || math.IsNaN(ru) || math.IsInf(ru, 0) {
+			return errors.Errorf("invalid RU consumption %v, it should be a finite non-negative number", ru)
+		}
+	}
+	return nil
+}
+
+type consumptionKey struct {
+	group         string
+	storeID       uint64
+	statementType string
+}
+
+// ConsumptionAggregator aggregates the RU consumption by resource group, store
+// and statement type since the resource manager starts.
+type ConsumptionAggregator struct {
+	sync.RWMutex
+	records map[consumptionKey]*ConsumptionRecord
+}
+
+// NewConsumptionAggregator creates a new ConsumptionAggregator.
+func NewConsumptionAggregator() *ConsumptionAggregator {
+	return &ConsumptionAggregator{records: make(map[consumptionKey]*ConsumptionRecord)}
+}
+
+// add aggregates the consumption and updates the metrics.
+func (a *ConsumptionAggregator) add(record *ConsumptionRecord) {
+	a.Lock()
+	defer a.Unlock()
+	key := consumptionKey{record.Group, record.StoreID, record.StatementType}
+	aggregated, ok := a.records[key]
+	if !ok {
+		aggregated = &ConsumptionRecord{Group: record.Group, StoreID: record.StoreID, StatementType: record.StatementType}
+		a.records[key] = aggregated
+	}
+	aggregated.RRU += record.RRU
+	aggregated.WRU += record.WRU
+	store := strconv.FormatUint(record.StoreID, 10)
+	if record.RRU > 0 {
+		storeRequestUnitCost.WithLabelValues(record.Group, store, record.StatementType, readTypeLabel).Add(record.RRU)
+	}
+	if record.WRU > 0 {
+		storeRequestUnitCost.WithLabelValues(record.Group, store, record.StatementType, writeTypeLabel).Add(record.WRU)
+	}
+}
+
+// Query returns the aggregated consumption of the resource group on the store,
+// sorted by group, store and statement type. Empty group or zero store ID
+// matches any.
+func (a *ConsumptionAggregator) Query(group string, storeID uint64) []*ConsumptionRecord {
+	a.RLock()
+	res := make([]*ConsumptionRecord, 0, len(a.records))
+	for key, record := range a.records {
+		if (len(group) == 0 || key.group == group) && (storeID == unknownStoreID || key.storeID == storeID) {
+			copied := *record
+			res = append(res, &copied)
+		}
+	}
+	a.RUnlock()
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Group != res[j].Group {
+			return res[i].Group < res[j].Group
+		}
+		if res[i].StoreID != res[j].StoreID {
+			return res[i].StoreID < res[j].StoreID
+		}
+		return res[i].StatementType < res[j].StatementType
+	})
+	return res
+}
+
+// remove removes the consumption and the metrics of the resource group.
+func (a *ConsumptionAggregator) remove(group string) {
+	a.Lock()
+	defer a.Unlock()
+	for key := range a.records {
+		if key.group != group {
+			continue
+		}
+		delete(a.records, key)
+		store := strconv.FormatUint(key.storeID, 10)
+		storeRequestUnitCost.DeleteLabelValues(group, store, key.statementType, readTypeLabel)
+		storeRequestUnitCost.DeleteLabelValues(group, store, key.statementType, writeTypeLabel)
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,g
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/utils/typeutil"
+)
+
+const (
+	// consumptionHistorySettingsName is the name to persist the history config.
+	consumptionHistorySettingsName = "consumption_history"
+	// consumptionHistoryGranularity is the time granularity of the RU consumption history.
+	consumptionHistoryGranularity = time.Minute
+	// defaultConsumptionHistoryRetention is the default time to keep the RU consumption history.
+	defaultConsumptionHistoryRetention = 7 * 24 * time.Hour
+	// consumptionHistoryGCInterval is the interval to remove the expired RU consumption history.
+	consumptionHistoryGCInterval = time.Hour
+)
+
+// ConsumptionHistoryConfig is the config of the ConsumptionHistory.
+type ConsumptionHistoryConfig struct {
+	// Retention is how long the RU consumption history is kept.
+	Retention typeutil.Duration `json:"retention"`
+}
+
+// Validate checks whether the config is valid.
+func (c *ConsumptionHistoryConfig) Validate() error {
+	if c.Retention.Duration < consumptionHistoryGranularity {
+		return errors.Errorf("invalid retention %s, it should be at least %s", c.Retention.Duration, consumptionHistoryGranularity)
+	}
+	return nil
+}
+
+// ConsumptionHistoryRecord is the RU consumption of a resource group in a
+// minute, or in a time range if it is summed up.
+type ConsumptionHistoryRecord struct {
+	Group string `json:"group"`
+	// Minute is the unix timestamp in seconds of the start of the minute.
+	Minute int64   `json:"minute"`
+	RRU    float64 `json:"rru"`
+	WRU    float64 `json:"wru"`
+}
+
+type pendingConsumptionHistory struct {
+	ConsumptionHistoryRecord
+	// merged is whether the consumption persisted in the same minute before, e.g.
+	// by the previous leader, has been merged into the record.
+	merged bool
+}
+
+// ConsumptionHistory records the RU consumption of the resource groups by
+// minute. The consumption is persisted periodically and kept for the retention.
+type ConsumptionHistory struct {
+	sync.Mutex
+	config ConsumptionHistoryConfig
+	// pending is the consumption by group and minute not persisted yet, or in
+	// the current minute.
+	pending map[string]map[int64]*pendingConsumptionHistory
+	lastGC  time.Time
+}
+
+// NewConsumptionHistory creates a new ConsumptionHistory.
+func NewConsumptionHistory(config ConsumptionHistoryConfig) *ConsumptionHistory {
+	return &ConsumptionHistory{
+		config:  config,
+		pending: make(map[string]map[int64]*pendingConsumptionHistory),
+	}
+}
+
+// GetConfig returns the config of the history.
+func (h *ConsumptionHistory) GetConfig() ConsumptionHistoryConfig {
+	h.Lock()
+	defer h.Unlock()
+	return h.config
+}
+
+// SetConfig sets the config of the history.
+func (h *ConsumptionHistory) SetConfig(config ConsumptionHistoryConfig) {
+	h.Lock()
+	defer h.Unlock()
+	h.config = config
+}
+
+func toMinute(t time.Time) int64 {
+	return t.Truncate(consumptionHistoryGranularity).Unix()
+}
+
+// add records the consumption of the resource group at now.
+func (h *ConsumptionHistory) add(now time.Time, group string, rru, wru float64) {
+	if rru == 0 && wru == 0 {
+		return
+	}
+	h.Lock()
+	defer h.Unlock()
+	minutes, ok := h.pending[group]
+	if !ok {
+		minutes = make(map[int64]*pendingConsumptionHistory)
+		h.pending[group] = minutes
+	}
+	minute := toMinute(now)
+	record, ok := minutes[minute]
+	if !ok {
+		record = &pendingConsumptionHistory{ConsumptionHistoryRecord: ConsumptionHistoryRecord{Group: group, Minute: minute}}
+		minutes[minute] = record
+	}
+	record.RRU += rru
+	record.WRU += wru
+}
+
+// flush persists the pending consumption. The consumption of the past minutes
+// is dropped from memory once persisted, while the current minute is kept to
+// accumulate more.
+func (h *ConsumptionHistory) flush(now time.Time, storage endpoint.ResourceGroupStorage) error {
+	h.Lock()
+	defer h.Unlock()
+	current := toMinute(now)
+	for group, minutes := range h.pending {
+		for minute, record := range minutes {
+			if !record.merged {
+				if err := storage.LoadResourceGroupConsumptionHistory(group, minute, minute+1, func(value []byte) error {
+					persisted := &ConsumptionHistoryRecord{}
+					if err := json.Unmarshal(value, persisted); err != nil {
+						return errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+					}
+					record.RRU += persisted.RRU
+					record.WRU += persisted.WRU
+					return nil
+				}); err != nil {
+					return err
+				}
+				record.merged = true
+			}
+			if err := storage.SaveResourceGroupConsumptionHistory(group, minute, record.ConsumptionHistoryRecord); err != nil {
+				return err
+			}
+			if minute < current {
+				delete(minutes, minute)
+			}
+		}
+		if len(minutes) == 0 {
+			delete(h.pending, group)
+		}
+	}
+	return nil
+}
+
+// gc removes the consumption of the resource groups beyond the retention. It
+// only runs once in the GC interval.
+func (h *ConsumptionHistory) gc(now time.Time, storage endpoint.ResourceGroupStorage, groups []string) error {
+	h.Lock()
+	defer h.Unlock()
+	if now.Sub(h.lastGC) < consumptionHistoryGCInterval {
+		return nil
+	}
+	expired := toMinute(now.Add(-h.config.Retention.Duration))
+	for _, group := range groups {
+		if err := storage.RemoveResourceGroupConsumptionHistoryBefore(group, expired); err != nil {
+			return err
+		}
+	}
+	h.lastGC = now
+	return nil
+}
+
+// remove removes all the consumption of the resource group.
+func (h *ConsumptionHistory) remove(storage endpoint.ResourceGroupStorage, group string) error {
+	h.Lock()
+	defer h.Unlock()
+	delete(h.pending, group)
+	return storage.RemoveResourceGroupConsumptionHistoryBefore(group, math.MaxInt64)
+}
+
+// loadConsumptionHistory returns the persisted consumption of the resource group
+// in the minutes of [start, end), in the order of time.
+func loadConsumptionHistory(storage endpoint.ResourceGroupStorage, group string, start, end time.Time) ([]*ConsumptionHistoryRecord, error) {
+	res := make([]*ConsumptionHistoryRecord, 0)
+	err := storage.LoadResourceGroupConsumptionHistory(group, toMinute(start), toMinute(end), func(value []byte) error {
+		record := &ConsumptionHistoryRecord{}
+		if err := json.Unmarshal(value, record); err != nil {
+			return errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+		}
+		res = append(res, record)
+		return nil
+	})
+	return res, err
+}
+
+// sumConsumptionHistory sums up the consumption of each resource group, sorted
+// by the total RU in descending order.
+func sumConsumptionHistory(records []*ConsumptionHistoryRecord, start time.Time) []*ConsumptionHistoryRecord {
+	sums := make(map[string]*ConsumptionHistoryRecord)
+	res := make([]*ConsumptionHistoryRecord, 0)
+	for _, record := range records {
+		sum, ok := sums[record.Group]
+		if !ok {
+			sum = &ConsumptionHistoryRecord{Group: record.Group, Minute: toMinute(start)}
+			sums[record.Group] = sum
+			res = append(res, sum)
+		}
+		sum.RRU += record.RRU
+		sum.WRU += record.WRU
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].RRU+res[i].WRU > res[j].RRU+res[j].WRU
+	})
+	return res
+}
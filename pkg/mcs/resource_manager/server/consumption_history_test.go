@@ -0,0 +1,107 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	rmpb "github.com/pingcap/kvproto/pkg/resource_manager"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/storage/kv"
+	"github.com/tikv/pd/pkg/utils/typeutil"
+)
+
+func TestConsumptionHistory(t *testing.T) {
+	re := require.New(t)
+	storage := endpoint.NewStorageEndpoint(kv.NewMemoryKV(), nil)
+	h := NewConsumptionHistory(ConsumptionHistoryConfig{Retention: typeutil.NewDuration(time.Hour)})
+	start := time.Unix(3600, 0)
+	h.add(start, "rg1", 1, 2)
+	h.add(start.Add(30*time.Second), "rg1", 3, 0)
+	h.add(start.Add(time.Minute), "rg1", 5, 0)
+	h.add(start.Add(time.Minute), "rg2", 0, 0)
+	// The consumption in the current minute is persisted but kept in memory.
+	re.NoError(h.flush(start.Add(time.Minute), storage))
+	records, err := loadConsumptionHistory(storage, "rg1", start, start.Add(time.Hour))
+	re.NoError(err)
+	re.Equal([]*ConsumptionHistoryRecord{
+		{Group: "rg1", Minute: 3600, RRU: 4, WRU: 2},
+		{Group: "rg1", Minute: 3660, RRU: 5},
+	}, records)
+	re.Len(h.pending["rg1"], 1)
+	re.NotContains(h.pending, "rg2")
+	h.add(start.Add(time.Minute), "rg1", 1, 1)
+	re.NoError(h.flush(start.Add(2*time.Minute), storage))
+	re.Empty(h.pending)
+	records, err = loadConsumptionHistory(storage, "rg1", start.Add(time.Minute), start.Add(2*time.Minute))
+	re.NoError(err)
+	re.Equal([]*ConsumptionHistoryRecord{{Group: "rg1", Minute: 3660, RRU: 6, WRU: 1}}, records)
+
+	// The consumption persisted by the previous leader is merged only once.
+	h = NewConsumptionHistory(ConsumptionHistoryConfig{Retention: typeutil.NewDuration(time.Hour)})
+	h.add(start.Add(time.Minute), "rg1", 1, 0)
+	re.NoError(h.flush(start.Add(time.Minute), storage))
+	h.add(start.Add(time.Minute), "rg1", 1, 0)
+	re.NoError(h.flush(start.Add(time.Minute), storage))
+	records, err = loadConsumptionHistory(storage, "rg1", start.Add(time.Minute), start.Add(2*time.Minute))
+	re.NoError(err)
+	re.Equal([]*ConsumptionHistoryRecord{{Group: "rg1", Minute: 3660, RRU: 8, WRU: 1}}, records)
+
+	// The expired consumption is removed at most once in the GC interval.
+	re.NoError(h.gc(start.Add(time.Hour+time.Minute), storage, []string{"rg1"}))
+	records, err = loadConsumptionHistory(storage, "rg1", start, start.Add(time.Hour))
+	re.NoError(err)
+	re.Len(records, 1)
+	re.NoError(h.gc(start.Add(time.Hour+2*time.Minute), storage, []string{"rg1"}))
+	records, err = loadConsumptionHistory(storage, "rg1", start, start.Add(time.Hour))
+	re.NoError(err)
+	re.Len(records, 1)
+
+	re.NoError(h.remove(storage, "rg1"))
+	records, err = loadConsumptionHistory(storage, "rg1", start, start.Add(time.Hour))
+	re.NoError(err)
+	re.Empty(records)
+}
+
+func TestQueryConsumptionHistory(t *testing.T) {
+	re := require.New(t)
+	m := newTestManager(endpoint.NewStorageEndpoint(kv.NewMemoryKV(), nil))
+	for _, name := range []string{"rg1", "rg2", "rg3"} {
+		re.NoError(m.AddResourceGroup(&ResourceGroup{Name: name, Mode: rmpb.GroupMode_RUMode}))
+	}
+	start := time.Unix(3600, 0)
+	m.consumptionHistory.add(start, "rg1", 1, 0)
+	m.consumptionHistory.add(start.Add(time.Minute), "rg1", 1, 1)
+	m.consumptionHistory.add(start, "rg2", 5, 0)
+	m.consumptionHistory.add(start.Add(2*time.Minute), "rg3", 100, 0)
+	m.persistConsumptionHistory(start.Add(3 * time.Minute))
+
+	records, err := m.QueryConsumptionHistory("", start, start.Add(2*time.Minute))
+	re.NoError(err)
+	re.Equal([]*ConsumptionHistoryRecord{
+		{Group: "rg1", Minute: 3600, RRU: 1},
+		{Group: "rg1", Minute: 3660, RRU: 1, WRU: 1},
+		{Group: "rg2", Minute: 3600, RRU: 5},
+	}, records)
+	records, err = m.QueryConsumptionHistory("rg1", start.Add(time.Minute), start.Add(time.Hour))
+	re.NoError(err)
+	re.Equal([]*ConsumptionHistoryRecord{{Group: "rg1", Minute: 3660, RRU: 1, WRU: 1}}, records)
+
+	records, err = m.SumConsumptionHistory(start, start.Add(time.Hour))
+	re.NoError(err)
+	re.Equal([]*ConsumptionHistoryRecord{
+		{Group: "rg3", Minute: 3600, RRU: 100},
+		{Group: "rg2", Minute: 3600, RRU: 5},
+		{Group: "rg1", Minute: 3600, RRU: 2, WRU: 1},
+	}, records)
+
+	// The history is removed with the group.
+	re.NoError(m.DeleteResourceGroup("rg3"))
+	records, err = m.QueryConsumptionHistory("rg3", start, start.Add(time.Hour))
+	re.NoError(err)
+	re.Empty(records)
+
+	re.Error(m.SetConsumptionHistoryConfig(ConsumptionHistoryConfig{Retention: typeutil.NewDuration(time.Second)}))
+	re.NoError(m.SetConsumptionHistoryConfig(ConsumptionHistoryConfig{Retention: typeutil.NewDuration(24 * time.Hour)}))
+	re.Equal(24*time.Hour, m.GetConsumptionHistoryConfig().Retention.Duration)
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"math"
+	"testing"
+
+	rmpb "github.com/pingcap/kvproto/pkg/resource_manager"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/storage/kv"
+)
+
+func TestConsumptionAggregator(t *testing.T) {
+	re := require.New(t)
+	a := NewConsumptionAggregator()
+	a.add(&ConsumptionRecord{Group: "rg2", StoreID: 1, RRU: 10})
+	a.add(&ConsumptionRecord{Group: "rg1", StoreID: 2, StatementType: "select", RRU: 10, WRU: 1})
+	a.add(&ConsumptionRecord{Group: "rg1", StoreID: 1, StatementType: "select", RRU: 10})
+	a.add(&ConsumptionRecord{Group: "rg1", StoreID: 1, StatementType: "insert", WRU: 20})
+	a.add(&ConsumptionRecord{Group: "rg1", StoreID: 1, StatementType: "select", RRU: 5, WRU: 2})
+
+	records := a.Query("", unknownStoreID)
+	re.Equal([]*ConsumptionRecord{
+		{Group: "rg1", StoreID: 1, StatementType: "insert", WRU: 20},
+		{Group: "rg1", StoreID: 1, StatementType: "select", RRU: 15, WRU: 2},
+		{Group: "rg1", StoreID: 2, StatementType: "select", RRU: 10, WRU: 1},
+		{Group: "rg2", StoreID: 1, RRU: 10},
+	}, records)
+	re.Len(a.Query("rg1", unknownStoreID), 3)
+	re.Len(a.Query("", 1), 3)
+	re.Len(a.Query("rg1", 2), 1)
+	re.Empty(a.Query("rg3", unknownStoreID))
+	// The query result is a copy.
+	records[0].WRU = 0
+	re.Equal(20., a.Query("rg1", 1)[0].WRU)
+
+	a.remove("rg1")
+	re.Empty(a.Query("rg1", unknownStoreID))
+	re.Len(a.Query("", unknownStoreID), 1)
+}
+
+func TestReportConsumption(t *testing.T) {
+	re := require.New(t)
+	m := newTestManager(endpoint.NewStorageEndpoint(kv.NewMemoryKV(), nil))
+	re.NoError(m.AddResourceGroup(&ResourceGroup{Name: "test", Mode: rmpb.GroupMode_RUMode}))
+	for _, records := range [][]*ConsumptionRecord{
+		{{Group: "test", StoreID: 1, RRU: 1}, {Group: "unknown", StoreID: 1, RRU: 1}},
+		{{Group: "test", StoreID: 1, RRU: -1}},
+		{{Group: "test", StoreID: 1, WRU: math.NaN()}},
+		{{StoreID: 1, RRU: 1}},
+	} {
+		re.Error(m.ReportConsumption(records))
+	}
+	// The invalid records are all rejected.
+	re.Empty(m.QueryConsumption("", unknownStoreID))
+
+	re.NoError(m.ReportConsumption([]*ConsumptionRecord{
+		{Group: "test", StoreID: 1, StatementType: "select", RRU: 1},
+		{Group: "test", StoreID: 2, WRU: 2},
+	}))
+	re.Equal([]*ConsumptionRecord{{Group: "test", StoreID: 2, WRU: 2}}, m.QueryConsumption("test", 2))
+	re.Len(m.QueryConsumption("test", unknownStoreID), 2)
+	re.NoError(m.DeleteResourceGroup("test"))
+	re.Empty(m.QueryConsumption("test", unknownStoreID))
+}
@@ -0,0 +1,309 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/pingcap/errors"
+	rmpb "github.com/pingcap/kvproto/pkg/resource_manager"
+	"github.com/pingcap/log"
+	"go.uber.org/zap"
+)
+
+// ResourceGroupsDocumentVersion is the version of the exported document.
+const ResourceGroupsDocumentVersion = 1
+
+// The actions of the changes made by an import.
+const (
+	ImportActionAdd    = "add"
+	ImportActionModify = "modify"
+	ImportActionRemove = "remove"
+)
+
+// ResourceGroupDefinition is the definition of a resource group without the
+// running states, e.g. the tokens and the quota usages.
+type ResourceGroupDefinition struct {
+	Group    *rmpb.ResourceGroup    `json:"group"`
+	Extended *GroupExtendedSettings `json:"extended,omitempty"`
+	Runaway  *RunawaySettings       `json:"runaway,omitempty"`
+}
+
+// ResourceGroupsDocument contains all the resource group definitions and the
+// templates they inherit from, to reproduce them in another cluster.
+type ResourceGroupsDocument struct {
+	Version   int                        `json:"version"`
+	Templates []*ResourceGroupTemplate   `json:"templates,omitempty"`
+	Groups    []*ResourceGroupDefinition `json:"groups"`
+}
+
+// ResourceGroupChange is a change of a resource group made by an import.
+type ResourceGroupChange struct {
+	Name   string                   `json:"name"`
+	Action string                   `json:"action"`
+	Before *ResourceGroupDefinition `json:"before,omitempty"`
+	After  *ResourceGroupDefinition `json:"after,omitempty"`
+}
+
+// TemplateChange is a change of a resource group template made by an import.
+type TemplateChange struct {
+	Name   string                 `json:"name"`
+	Action string                 `json:"action"`
+	Before *ResourceGroupTemplate `json:"before,omitempty"`
+	After  *ResourceGroupTemplate `json:"after,omitempty"`
+}
+
+// ResourceGroupsDiff is the difference between the current resource groups and
+// an imported document.
+type ResourceGroupsDiff struct {
+	Templates []*TemplateChange      `json:"templates,omitempty"`
+	Groups    []*ResourceGroupChange `json:"groups,omitempty"`
+}
+
+// getDefinition returns the definition of the resource group.
+func (rg *ResourceGroup) getDefinition() *ResourceGroupDefinition {
+	group := rg.IntoProtoResourceGroup()
+	// Drop the tokens, which are the states.
+	for _, bucket := range []*rmpb.TokenBucket{
+		group.GetRUSettings().GetRU(),
+		group.GetRawResourceSettings().GetCpu(),
+		group.GetRawResourceSettings().GetIoRead(),
+		group.GetRawResourceSettings().GetIoWrite(),
+	} {
+		if bucket != nil {
+			bucket.Tokens = 0
+		}
+	}
+	return &ResourceGroupDefinition{Group: group, Extended: rg.GetExtendedSettings()}
+}
+
+// ExportResourceGroups returns the definitions of all the resource groups and
+// the templates.
+func (m *Manager) ExportResourceGroups() *ResourceGroupsDocument {
+	doc := &ResourceGroupsDocument{
+		Version:   ResourceGroupsDocumentVersion,
+		Templates: m.GetResourceGroupTemplates(),
+		Groups:    make([]*ResourceGroupDefinition, 0),
+	}
+	for _, group := range m.GetResourceGroupList() {
+		def := group.getDefinition()
+		def.Runaway = m.runawayRules.GetSettings(group.Name)
+		doc.Groups = append(doc.Groups, def)
+	}
+	return doc
+}
+
+// buildResourceGroup validates the definition and builds the resource group
+// with its store affinity unset, which is applied by the placement rules.
+func buildResourceGroup(def *ResourceGroupDefinition) (*ResourceGroup, error) {
+	if def == nil || def.Group == nil {
+		return nil, errors.New("resource group definition should not be empty")
+	}
+	group := FromProtoResourceGroup(def.Group)
+	if err := group.CheckAndInit(); err != nil {
+		return nil, errors.Wrapf(err, "resource group %s", def.Group.Name)
+	}
+	if def.Extended != nil {
+		if err := def.Extended.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "resource group %s", def.Group.Name)
+		}
+		extended := *def.Extended
+		extended.StoreAffinity = nil
+		group.SetExtendedSettingsIntoResourceGroup(&extended)
+	}
+	if def.Runaway != nil {
+		if err := def.Runaway.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "resource group %s", def.Group.Name)
+		}
+	}
+	return group, nil
+}
+
+// normalizeDefinition returns the definition as it is exported after imported,
+// so the definitions can be compared.
+func normalizeDefinition(group *ResourceGroup, def *ResourceGroupDefinition) *ResourceGroupDefinition {
+	res := group.getDefinition()
+	if def.Extended != nil {
+		res.Extended.StoreAffinity = def.Extended.StoreAffinity
+	}
+	res.Runaway = def.Runaway
+	return res
+}
+
+func equalDefinitions(a, b interface{}) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+// DiffResourceGroups validates the document and returns the changes to import
+// it. The groups not in the document are removed only if prune is set. The
+// templates are never removed since they may be inherited.
+func (m *Manager) DiffResourceGroups(doc *ResourceGroupsDocument, prune bool) (*ResourceGroupsDiff, error) {
+	if doc.Version != ResourceGroupsDocumentVersion {
+		return nil, errors.Errorf("unsupported document version %d, it should be %d", doc.Version, ResourceGroupsDocumentVersion)
+	}
+	current := m.ExportResourceGroups()
+	diff := &ResourceGroupsDiff{}
+
+	templates := make(map[string]*ResourceGroupTemplate, len(current.Templates)+len(doc.Templates))
+	for _, template := range current.Templates {
+		templates[template.Name] = template
+	}
+	imported := make(map[string]struct{}, len(doc.Templates))
+	for _, template := range doc.Templates {
+		if template == nil {
+			return nil, errors.New("template should not be empty")
+		}
+		if err := template.Validate(); err != nil {
+			return nil, err
+		}
+		if _, ok := imported[template.Name]; ok {
+			return nil, errors.Errorf("duplicated template %s", template.Name)
+		}
+		imported[template.Name] = struct{}{}
+		before, ok := templates[template.Name]
+		switch {
+		case !ok:
+			diff.Templates = append(diff.Templates, &TemplateChange{Name: template.Name, Action: ImportActionAdd, After: template})
+		case !reflect.DeepEqual(before, template):
+			diff.Templates = append(diff.Templates, &TemplateChange{Name: template.Name, Action: ImportActionModify, Before: before, After: template})
+		}
+		templates[template.Name] = template
+	}
+
+	groups := make(map[string]*ResourceGroupDefinition, len(current.Groups))
+	for _, def := range current.Groups {
+		groups[def.Group.Name] = def
+	}
+	names := make(map[string]struct{}, len(doc.Groups))
+	for _, def := range doc.Groups {
+		group, err := buildResourceGroup(def)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := names[group.Name]; ok {
+			return nil, errors.Errorf("duplicated resource group %s", group.Name)
+		}
+		names[group.Name] = struct{}{}
+		if len(group.Template) > 0 {
+			if _, ok := templates[group.Template]; !ok {
+				return nil, errors.Errorf("template %s of resource group %s does not exist", group.Template, group.Name)
+			}
+		}
+		after := normalizeDefinition(group, def)
+		before, ok := groups[group.Name]
+		switch {
+		case !ok:
+			diff.Groups = append(diff.Groups, &ResourceGroupChange{Name: group.Name, Action: ImportActionAdd, After: after})
+		case before.Group.Mode != after.Group.Mode:
+			return nil, errors.Errorf("resource group %s can not be reconfigured in a different mode", group.Name)
+		case !equalDefinitions(before, after):
+			diff.Groups = append(diff.Groups, &ResourceGroupChange{Name: group.Name, Action: ImportActionModify, Before: before, After: after})
+		}
+	}
+	if prune {
+		for _, def := range current.Groups {
+			if _, ok := names[def.Group.Name]; !ok {
+				diff.Groups = append(diff.Groups, &ResourceGroupChange{Name: def.Group.Name, Action: ImportActionRemove, Before: def})
+			}
+		}
+	}
+	sort.SliceStable(diff.Groups, func(i, j int) bool {
+		return diff.Groups[i].Name < diff.Groups[j].Name
+	})
+	return diff, nil
+}
+
+// ImportResourceGroups applies the changes to import the document and returns
+// them, nothing is changed if dryRun is set.
+func (m *Manager) ImportResourceGroups(doc *ResourceGroupsDocument, prune, dryRun bool) (*ResourceGroupsDiff, error) {
+	diff, err := m.DiffResourceGroups(doc, prune)
+	if err != nil || dryRun {
+		return diff, err
+	}
+	for _, change := range diff.Templates {
+		if err := m.PutResourceGroupTemplate(change.After); err != nil {
+			return nil, err
+		}
+	}
+	for _, change := range diff.Groups {
+		switch change.Action {
+		case ImportActionAdd:
+			err = m.addResourceGroupDefinition(change.After)
+		case ImportActionModify:
+			err = m.modifyResourceGroupDefinition(change.After)
+		case ImportActionRemove:
+			err = m.DeleteResourceGroup(change.Name)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to %s resource group %s", change.Action, change.Name)
+		}
+	}
+	log.Info("resource groups imported", zap.Int("template-changes", len(diff.Templates)), zap.Int("group-changes", len(diff.Groups)))
+	return diff, nil
+}
+
+func (m *Manager) addResourceGroupDefinition(def *ResourceGroupDefinition) error {
+	group, err := buildResourceGroup(def)
+	if err != nil {
+		return err
+	}
+	if err := m.AddResourceGroup(group); err != nil {
+		return err
+	}
+	if err := group.persistExtendedSettings(m.storage); err != nil {
+		return err
+	}
+	if affinity := def.Extended.StoreAffinity; affinity != nil {
+		if err := m.SetResourceGroupStoreAffinity(group.Name, affinity); err != nil {
+			return err
+		}
+	}
+	if def.Runaway != nil {
+		return m.SetResourceGroupRunawaySettings(group.Name, def.Runaway)
+	}
+	return nil
+}
+
+func (m *Manager) modifyResourceGroupDefinition(def *ResourceGroupDefinition) error {
+	if err := m.ModifyResourceGroup(def.Group); err != nil {
+		return err
+	}
+	curGroup := m.GetMutableResourceGroup(def.Group.Name)
+	if curGroup == nil {
+		return errors.New("not exists the group")
+	}
+	extended := *def.Extended
+	extended.StoreAffinity = curGroup.GetStoreAffinity()
+	curGroup.Lock()
+	curGroup.SetExtendedSettingsIntoResourceGroup(&extended)
+	curGroup.Unlock()
+	if err := curGroup.persistExtendedSettings(m.storage); err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(extended.StoreAffinity, def.Extended.StoreAffinity) {
+		if err := m.SetResourceGroupStoreAffinity(curGroup.Name, def.Extended.StoreAffinity); err != nil {
+			return err
+		}
+	}
+	if !reflect.DeepEqual(m.runawayRules.GetSettings(curGroup.Name), def.Runaway) {
+		return m.SetResourceGroupRunawaySettings(curGroup.Name, def.Runaway)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	rmpb "github.com/pingcap/kvproto/pkg/resource_manager"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/storage/kv"
+)
+
+func TestExportImportResourceGroups(t *testing.T) {
+	re := require.New(t)
+	src := newTestManager(endpoint.NewStorageEndpoint(kv.NewMemoryKV(), nil))
+	fillRate := uint64(1000)
+	re.NoError(src.PutResourceGroupTemplate(&ResourceGroupTemplate{Name: "t1", Settings: TemplateSettings{FillRate: &fillRate}}))
+	for _, name := range []string{"rg1", "rg2"} {
+		re.NoError(src.AddResourceGroup(&ResourceGroup{
+			Name: name,
+			Mode: rmpb.GroupMode_RUMode,
+			RUSettings: NewRequestUnitSettings(&rmpb.TokenBucket{
+				Settings: &rmpb.TokenLimitSettings{FillRate: 100, MaxTokens: 1000},
+				Tokens:   500,
+			}),
+		}))
+	}
+	re.NoError(src.SetResourceGroupTemplate("rg1", "t1", nil))
+	re.NoError(src.SetResourceGroupPriority("rg2", MaxPriority))
+	settings := &RunawaySettings{ExecElapsedTimeMs: 1000, Action: RunawayActionKill}
+	re.NoError(src.SetResourceGroupRunawaySettings("rg2", settings))
+
+	// The document is portable and contains no states.
+	data, err := json.Marshal(src.ExportResourceGroups())
+	re.NoError(err)
+	doc := &ResourceGroupsDocument{}
+	re.NoError(json.Unmarshal(data, doc))
+	re.Len(doc.Templates, 1)
+	re.Len(doc.Groups, 2)
+	for _, def := range doc.Groups {
+		re.Zero(def.Group.RUSettings.RU.Tokens)
+	}
+
+	dst := newTestManager(endpoint.NewStorageEndpoint(kv.NewMemoryKV(), nil))
+	re.NoError(dst.AddResourceGroup(&ResourceGroup{Name: "rg3", Mode: rmpb.GroupMode_RUMode}))
+	diff, err := dst.ImportResourceGroups(doc, true, true)
+	re.NoError(err)
+	re.Len(diff.Templates, 1)
+	re.Equal(ImportActionAdd, diff.Templates[0].Action)
+	re.Len(diff.Groups, 3)
+	re.Equal(ImportActionAdd, diff.Groups[0].Action)
+	re.Equal(ImportActionAdd, diff.Groups[1].Action)
+	re.Equal("rg3", diff.Groups[2].Name)
+	re.Equal(ImportActionRemove, diff.Groups[2].Action)
+	// Nothing is changed by the dry run.
+	re.Empty(dst.GetResourceGroupTemplates())
+	re.Nil(dst.GetResourceGroup("rg1"))
+
+	_, err = dst.ImportResourceGroups(doc, true, false)
+	re.NoError(err)
+	re.Nil(dst.GetResourceGroup("rg3"))
+	re.Equal("t1", dst.GetResourceGroup("rg1").Template)
+	re.Equal(MaxPriority, dst.GetResourceGroup("rg2").GetPriority())
+	re.Equal(settings, dst.GetResourceGroupRunawaySettings("rg2"))
+	diff, err = dst.DiffResourceGroups(doc, true)
+	re.NoError(err)
+	re.Empty(diff.Templates)
+	re.Empty(diff.Groups)
+
+	// The modified groups are reconfigured.
+	doc.Groups[1].Group.RUSettings.RU.Settings.FillRate = 200
+	doc.Groups[1].Runaway = nil
+	diff, err = dst.ImportResourceGroups(doc, false, false)
+	re.NoError(err)
+	re.Len(diff.Groups, 1)
+	re.Equal(ImportActionModify, diff.Groups[0].Action)
+	re.Equal(uint64(200), dst.GetResourceGroup("rg2").RUSettings.RU.Settings.FillRate)
+	re.Nil(dst.GetResourceGroupRunawaySettings("rg2"))
+
+	// The invalid documents are rejected.
+	for _, invalid := range []*ResourceGroupsDocument{
+		{Version: ResourceGroupsDocumentVersion + 1},
+		{Version: ResourceGroupsDocumentVersion, Groups: []*ResourceGroupDefinition{{}}},
+		{Version: ResourceGroupsDocumentVersion, Groups: []*ResourceGroupDefinition{doc.Groups[0], doc.Groups[0]}},
+		{Version: ResourceGroupsDocumentVersion, Groups: []*ResourceGroupDefinition{
+			{Group: &rmpb.ResourceGroup{Name: "rg4", Mode: rmpb.GroupMode_RUMode}, Extended: &GroupExtendedSettings{Template: "unknown"}},
+		}},
+		{Version: ResourceGroupsDocumentVersion, Groups: []*ResourceGroupDefinition{
+			{Group: &rmpb.ResourceGroup{Name: "rg2", Mode: rmpb.GroupMode_RawMode}},
+		}},
+	} {
+		_, err = dst.ImportResourceGroups(invalid, false, false)
+		re.Error(err)
+	}
+}
@@ -24,7 +24,9 @@ import (
 	rmpb "github.com/pingcap/kvproto/pkg/resource_manager"
 	"github.com/pingcap/log"
 	bs "github.com/tikv/pd/pkg/basicserver"
+	"github.com/tikv/pd/pkg/mcs/discovery"
 	"github.com/tikv/pd/pkg/mcs/registry"
+	"github.com/tikv/pd/pkg/rbac"
 	"github.com/tikv/pd/pkg/utils/apiutil"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -55,17 +57,34 @@ func (d dummyRestService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 type Service struct {
 	ctx     context.Context
 	manager *Manager
+	// forwarder forwards the REST requests to the independent resource manager.
+	forwarder *discovery.Forwarder
+	// authorizer checks the role of the mutating requests, nil if the server
+	// doesn't support the role-based access control.
+	authorizer grpcAuthorizer
 	// settings
 }
 
+// grpcAuthorizer is implemented by the server which authorizes the gRPC
+// requests by the role-based access control, i.e. the PD server when the
+// resource manager is not deployed separately.
+type grpcAuthorizer interface {
+	AuthorizeGRPC(ctx context.Context, required rbac.Role) error
+}
+
 // NewService creates a new resource manager service.
 func NewService(svr bs.Server) registry.RegistrableService {
 	manager := NewManager(svr)
 
-	return &Service{
-		ctx:     svr.Context(),
-		manager: manager,
+	s := &Service{
+		ctx:       svr.Context(),
+		manager:   manager,
+		forwarder: discovery.NewForwarder(svr, discovery.ResourceManagerServiceName),
+	}
+	if authorizer, ok := svr.(grpcAuthorizer); ok {
+		s.authorizer = authorizer
 	}
+	return s
 }
 
 // RegisterGRPCService registers the service to gRPC server.
@@ -76,7 +95,7 @@ func (s *Service) RegisterGRPCService(g *grpc.Server) {
 // RegisterRESTHandler registers the service to REST server.
 func (s *Service) RegisterRESTHandler(userDefineHandlers map[string]http.Handler) {
 	handler, group := SetUpRestHandler(s)
-	apiutil.RegisterUserDefinedHandlers(userDefineHandlers, &group, handler)
+	apiutil.RegisterUserDefinedHandlers(userDefineHandlers, &group, s.forwarder.Handler(handler))
 }
 
 // GetManager returns the resource manager.
@@ -91,6 +110,18 @@ func (s *Service) checkLeader() error {
 	return nil
 }
 
+// checkMutation checks the leader and the operator role of the request which
+// changes the resource groups.
+func (s *Service) checkMutation(ctx context.Context) error {
+	if err := s.checkLeader(); err != nil {
+		return err
+	}
+	if s.authorizer != nil {
+		return s.authorizer.AuthorizeGRPC(ctx, rbac.RoleOperator)
+	}
+	return nil
+}
+
 // GetResourceGroup implements ResourceManagerServer.GetResourceGroup.
 func (s *Service) GetResourceGroup(ctx context.Context, req *rmpb.GetResourceGroupRequest) (*rmpb.GetResourceGroupResponse, error) {
 	if err := s.checkLeader(); err != nil {
@@ -122,7 +153,7 @@ func (s *Service) ListResourceGroups(ctx context.Context, req *rmpb.ListResource
 
 // AddResourceGroup implements ResourceManagerServer.AddResourceGroup.
 func (s *Service) AddResourceGroup(ctx context.Context, req *rmpb.PutResourceGroupRequest) (*rmpb.PutResourceGroupResponse, error) {
-	if err := s.checkLeader(); err != nil {
+	if err := s.checkMutation(ctx); err != nil {
 		return nil, err
 	}
 	rg := FromProtoResourceGroup(req.GetGroup())
@@ -135,7 +166,7 @@ func (s *Service) AddResourceGroup(ctx context.Context, req *rmpb.PutResourceGro
 
 // DeleteResourceGroup implements ResourceManagerServer.DeleteResourceGroup.
 func (s *Service) DeleteResourceGroup(ctx context.Context, req *rmpb.DeleteResourceGroupRequest) (*rmpb.DeleteResourceGroupResponse, error) {
-	if err := s.checkLeader(); err != nil {
+	if err := s.checkMutation(ctx); err != nil {
 		return nil, err
 	}
 	err := s.manager.DeleteResourceGroup(req.ResourceGroupName)
@@ -147,7 +178,7 @@ func (s *Service) DeleteResourceGroup(ctx context.Context, req *rmpb.DeleteResou
 
 // ModifyResourceGroup implements ResourceManagerServer.ModifyResourceGroup.
 func (s *Service) ModifyResourceGroup(ctx context.Context, req *rmpb.PutResourceGroupRequest) (*rmpb.PutResourceGroupResponse, error) {
-	if err := s.checkLeader(); err != nil {
+	if err := s.checkMutation(ctx); err != nil {
 		return nil, err
 	}
 	err := s.manager.ModifyResourceGroup(req.GetGroup())
@@ -178,7 +209,8 @@ func (s *Service) AcquireTokenBuckets(stream rmpb.ResourceManager_AcquireTokenBu
 		targetPeriodMs := request.GetTargetRequestPeriodMs()
 		resps := &rmpb.TokenBucketsResponse{}
 		for _, req := range request.Requests {
-			resourceGroupName := req.GetResourceGroupName()
+			// The background tasks request tokens with their task types in the name.
+			resourceGroupName, taskType := parseBackgroundTaskGroupName(req.GetResourceGroupName())
 			// Get the resource group from manager to acquire token buckets.
 			rg := s.manager.GetMutableResourceGroup(resourceGroupName)
 			if rg == nil {
@@ -192,14 +224,29 @@ func (s *Service) AcquireTokenBuckets(stream rmpb.ResourceManager_AcquireTokenBu
 			}{resourceGroupName, req.GetConsumptionSinceLastRequest()}
 			now := time.Now()
 			resp := &rmpb.TokenBucketResponse{
-				ResourceGroupName: rg.Name,
+				ResourceGroupName: req.GetResourceGroupName(),
 			}
 			switch rg.Mode {
 			case rmpb.GroupMode_RUMode:
 				var tokens *rmpb.GrantedRUTokenBucket
 				for _, re := range req.GetRuItems().GetRequestRU() {
 					if re.Type == rmpb.RequestUnitType_RU {
-						tokens = rg.RequestRU(now, re.Value, targetPeriodMs)
+						neededTokens, periodMs := re.Value, targetPeriodMs
+						if len(taskType) == 0 {
+							// Grant the tokens for the period suggested by the load of the group.
+							neededTokens, periodMs = rg.adaptTokenRequest(now, neededTokens, periodMs)
+						}
+						// Admit the request by the priority of the group first.
+						admitted := s.manager.admitRU(now, rg, neededTokens)
+						if len(taskType) > 0 {
+							tokens = rg.RequestBackgroundRU(now, taskType, admitted, periodMs)
+						} else {
+							tokens = rg.RequestRU(now, admitted, periodMs)
+						}
+						// Slow down the client if the request is not fully admitted.
+						if tokens != nil && admitted < neededTokens && tokens.TrickleTimeMs < int64(periodMs) {
+							tokens.TrickleTimeMs = int64(periodMs)
+						}
 					}
 					resp.GrantedRUTokens = append(resp.GrantedRUTokens, tokens)
 				}
@@ -0,0 +1,174 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/errs"
+	"go.uber.org/zap"
+)
+
+// keyspaceSeparator separates the keyspace ID and the resource group name in
+// the full name of a keyspace-scoped resource group, e.g. "1:default", since
+// the protocol doesn't carry the keyspace. The ID rather than the name is used,
+// so the groups stay in the keyspace after it is renamed. The groups whose
+// names have no keyspace are in the global namespace.
+const keyspaceSeparator = ":"
+
+// KeyspaceResourceGroupName returns the full name of the resource group in the
+// keyspace, empty keyspace ID means the global namespace.
+func KeyspaceResourceGroupName(keyspaceID, groupName string) string {
+	if len(keyspaceID) == 0 {
+		return groupName
+	}
+	return keyspaceID + keyspaceSeparator + groupName
+}
+
+// SplitKeyspaceResourceGroupName splits the full name of a resource group into
+// the keyspace ID and the group name. The keyspace ID is empty for the global
+// groups.
+func SplitKeyspaceResourceGroupName(name string) (keyspaceID, groupName string) {
+	if i := strings.Index(name, keyspaceSeparator); i >= 0 {
+		return name[:i], name[i+len(keyspaceSeparator):]
+	}
+	return "", name
+}
+
+// validateKeyspaceID checks the keyspace ID is a uint32 in the canonical
+// decimal form, so a keyspace has only one namespace.
+func validateKeyspaceID(keyspaceID string) error {
+	id, err := strconv.ParseUint(keyspaceID, 10, 32)
+	if err != nil || strconv.FormatUint(id, 10) != keyspaceID {
+		return errors.Errorf("invalid keyspace id %s, it should be a decimal uint32", keyspaceID)
+	}
+	return nil
+}
+
+// GetKeyspaceResourceGroupList returns copies of the resource groups in the
+// keyspace, empty keyspace ID means the global namespace.
+func (m *Manager) GetKeyspaceResourceGroupList(keyspaceID string) []*ResourceGroup {
+	groups := m.GetResourceGroupList()
+	res := make([]*ResourceGroup, 0, len(groups))
+	for _, group := range groups {
+		if ks, _ := SplitKeyspaceResourceGroupName(group.Name); ks == keyspaceID {
+			res = append(res, group)
+		}
+	}
+	return res
+}
+
+// MigrateResourceGroupsToKeyspace moves the global resource groups into the
+// keyspace with their settings, states and rules, and returns the new names.
+// All the global groups are moved if names is empty. The RU consumption
+// history of the moved groups is not kept. The invalid input is reported by
+// errs.ErrInvalidResourceGroupMigration.
+func (m *Manager) MigrateResourceGroupsToKeyspace(keyspaceID string, names []string) ([]string, error) {
+	if err := validateKeyspaceID(keyspaceID); err != nil {
+		return nil, errs.ErrInvalidResourceGroupMigration.FastGenByArgs(err.Error())
+	}
+	if len(names) == 0 {
+		for _, group := range m.GetKeyspaceResourceGroupList("") {
+			names = append(names, group.Name)
+		}
+	}
+	m.RLock()
+	for _, name := range names {
+		if ks, _ := SplitKeyspaceResourceGroupName(name); len(ks) > 0 {
+			m.RUnlock()
+			return nil, errs.ErrInvalidResourceGroupMigration.FastGenByArgs(
+				fmt.Sprintf("resource group %s is already in keyspace %s", name, ks))
+		}
+		if _, ok := m.groups[name]; !ok {
+			m.RUnlock()
+			return nil, errs.ErrInvalidResourceGroupMigration.FastGenByArgs(
+				fmt.Sprintf("resource group %s does not exist", name))
+		}
+		if _, ok := m.groups[KeyspaceResourceGroupName(keyspaceID, name)]; ok {
+			m.RUnlock()
+			return nil, errs.ErrInvalidResourceGroupMigration.FastGenByArgs(
+				fmt.Sprintf("resource group %s already exists in keyspace %s", name, keyspaceID))
+		}
+	}
+	m.RUnlock()
+	migrated := make([]string, 0, len(names))
+	for _, name := range names {
+		newName := KeyspaceResourceGroupName(keyspaceID, name)
+		if err := m.renameResourceGroup(name, newName); err != nil {
+			return migrated, err
+		}
+		migrated = append(migrated, newName)
+	}
+	return migrated, nil
+}
+
+// renameResourceGroup persists the resource group with the new name before
+// removing the old one, so the group is never lost.
+func (m *Manager) renameResourceGroup(oldName, newName string) error {
+	group := m.GetMutableResourceGroup(oldName)
+	if group == nil {
+		return errors.Errorf("resource group %s does not exist", oldName)
+	}
+	if affinity := group.GetStoreAffinity(); affinity != nil {
+		ruleManager := m.getRuleManager()
+		if ruleManager == nil {
+			return errNoRuleManager
+		}
+		if err := ruleManager.SetGroupBundle(makeAffinityRuleBundle(newName, affinity)); err != nil {
+			return err
+		}
+		if err := ruleManager.DeleteGroupBundle(getAffinityRuleGroupID(oldName), false); err != nil {
+			return err
+		}
+	}
+	if err := m.runawayRules.rename(m.storage, oldName, newName); err != nil {
+		return err
+	}
+	group.Lock()
+	group.Name = newName
+	group.Unlock()
+	if err := group.persistSettings(m.storage); err != nil {
+		return err
+	}
+	if err := group.persistStates(m.storage); err != nil {
+		return err
+	}
+	if err := group.persistExtendedSettings(m.storage); err != nil {
+		return err
+	}
+	m.Lock()
+	m.groups[newName] = group
+	delete(m.groups, oldName)
+	m.Unlock()
+	if err := m.storage.DeleteResourceGroupSetting(oldName); err != nil {
+		return err
+	}
+	if err := m.storage.DeleteResourceGroupStates(oldName); err != nil {
+		return err
+	}
+	if err := m.storage.DeleteResourceGroupExtendedSettings(oldName); err != nil {
+		return err
+	}
+	if err := m.consumptionHistory.remove(m.storage, oldName); err != nil {
+		return err
+	}
+	m.consumption.remove(oldName)
+	log.Info("resource group renamed", zap.String("old-name", oldName), zap.String("new-name", newName))
+	return nil
+}
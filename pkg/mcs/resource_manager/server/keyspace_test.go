@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	rmpb "github.com/pingcap/kvproto/pkg/resource_manager"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/storage/kv"
+)
+
+func TestKeyspaceResourceGroupName(t *testing.T) {
+	re := require.New(t)
+	re.Equal("default", KeyspaceResourceGroupName("", "default"))
+	re.Equal("1:default", KeyspaceResourceGroupName("1", "default"))
+	keyspaceID, name := SplitKeyspaceResourceGroupName("1:default")
+	re.Equal("1", keyspaceID)
+	re.Equal("default", name)
+	keyspaceID, name = SplitKeyspaceResourceGroupName("default")
+	re.Empty(keyspaceID)
+	re.Equal("default", name)
+
+	for _, name := range []string{"1:", ":default", "ks1:default", "01:default", "4294967296:default", "1:rg:1", "1:rg$1"} {
+		rg := &ResourceGroup{Name: name, Mode: rmpb.GroupMode_RUMode}
+		re.Error(rg.CheckAndInit(), name)
+	}
+}
+
+func TestMigrateResourceGroupsToKeyspace(t *testing.T) {
+	re := require.New(t)
+	storage := endpoint.NewStorageEndpoint(kv.NewMemoryKV(), nil)
+	m := newTestManager(storage)
+	// The groups with the same name are independent in different keyspaces.
+	for _, name := range []string{"default", "rg1", "1:default", "2:default"} {
+		re.NoError(m.AddResourceGroup(&ResourceGroup{Name: name, Mode: rmpb.GroupMode_RUMode}))
+	}
+	re.Len(m.GetKeyspaceResourceGroupList(""), 2)
+	re.Len(m.GetKeyspaceResourceGroupList("1"), 1)
+	settings := &RunawaySettings{ExecElapsedTimeMs: 1000, Action: RunawayActionKill}
+	re.NoError(m.SetResourceGroupRunawaySettings("rg1", settings))
+
+	// The keyspace is namespaced by the ID, which is never changed by renaming.
+	_, err := m.MigrateResourceGroupsToKeyspace("ks1", nil)
+	re.True(errs.ErrInvalidResourceGroupMigration.Equal(err))
+	_, err = m.MigrateResourceGroupsToKeyspace("1", []string{"unknown"})
+	re.True(errs.ErrInvalidResourceGroupMigration.Equal(err))
+	_, err = m.MigrateResourceGroupsToKeyspace("1", []string{"default"})
+	re.Error(err)
+	_, err = m.MigrateResourceGroupsToKeyspace("3", []string{"1:default"})
+	re.Error(err)
+	migrated, err := m.MigrateResourceGroupsToKeyspace("1", []string{"rg1"})
+	re.NoError(err)
+	re.Equal([]string{"1:rg1"}, migrated)
+	re.Nil(m.GetResourceGroup("rg1"))
+	re.NotNil(m.GetResourceGroup("1:rg1"))
+	re.Equal(settings, m.GetResourceGroupRunawaySettings("1:rg1"))
+
+	// All the global groups are migrated by default.
+	migrated, err = m.MigrateResourceGroupsToKeyspace("3", nil)
+	re.NoError(err)
+	re.Equal([]string{"3:default"}, migrated)
+	re.Empty(m.GetKeyspaceResourceGroupList(""))
+
+	// The groups are reloaded with the new names.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := newTestManager(storage)
+	reloaded.Init(ctx)
+	re.Nil(reloaded.GetResourceGroup("rg1"))
+	re.NotNil(reloaded.GetResourceGroup("1:rg1"))
+	re.NotNil(reloaded.GetResourceGroup("3:default"))
+	re.Len(reloaded.GetKeyspaceResourceGroupList("1"), 2)
+	re.Equal(settings, reloaded.GetResourceGroupRunawaySettings("1:rg1"))
+	re.Nil(reloaded.GetResourceGroupRunawaySettings("rg1"))
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/tikv/pd/pkg/member"
 	"github.com/tikv/pd/pkg/storage/endpoint"
 	"github.com/tikv/pd/pkg/storage/kv"
+	"github.com/tikv/pd/pkg/utils/typeutil"
 	"go.uber.org/zap"
 )
 
@@ -38,9 +39,28 @@ const defaultConsumptionChanSize = 1024
 // Manager is the manager of resource group.
 type Manager struct {
 	sync.RWMutex
-	member  *member.Member
-	groups  map[string]*ResourceGroup
-	storage endpoint.ResourceGroupStorage
+	member *member.Member
+	groups map[string]*ResourceGroup
+	// templates are the resource group templates by name.
+	templates map[string]*ResourceGroupTemplate
+	storage   endpoint.ResourceGroupStorage
+	// borrowPool is shared by the resource groups to borrow tokens from.
+	borrowPool *BorrowPool
+	// priorityScheduler admits the token requests by the group priorities.
+	priorityScheduler *PriorityScheduler
+	// consumption aggregates the RU consumption by group and store.
+	consumption *ConsumptionAggregator
+	// consumptionHistory records the RU consumption by group and minute.
+	consumptionHistory *ConsumptionHistory
+	// ruleManager applies the store affinities of the groups, nil if the
+	// placement rules are not available.
+	ruleManager func() placementRuleManager
+	// runawayRules are the runaway query rules of the groups enforced by the clients.
+	runawayRules *RunawayRules
+	// ruModel is the RU model used by the clients to price the requests.
+	ruModel *RUModel
+	// workload records the recent workload to preview the RU models.
+	workload *workloadRecorder
 	// consumptionChan is used to send the consumption
 	// info to the background metrics flusher.
 	consumptionDispatcher chan struct {
@@ -52,13 +72,33 @@ type Manager struct {
 // NewManager returns a new Manager.
 func NewManager(srv bs.Server) *Manager {
 	m := &Manager{
-		member: &member.Member{},
-		groups: make(map[string]*ResourceGroup),
+		member:     &member.Member{},
+		groups:     make(map[string]*ResourceGroup),
+		templates:  make(map[string]*ResourceGroupTemplate),
+		borrowPool: NewBorrowPool(defaultBorrowPoolCapacity),
+		priorityScheduler: NewPriorityScheduler(PrioritySchedulerConfig{
+			MinShareRatio: defaultMinShareRatio,
+		}),
+		consumption: NewConsumptionAggregator(),
+		consumptionHistory: NewConsumptionHistory(ConsumptionHistoryConfig{
+			Retention: typeutil.NewDuration(defaultConsumptionHistoryRetention),
+		}),
+		runawayRules: NewRunawayRules(),
+		ruModel:      DefaultRUModel(),
+		workload:     newWorkloadRecorder(),
 		consumptionDispatcher: make(chan struct {
 			resourceGroupName string
 			*rmpb.Consumption
 		}, defaultConsumptionChanSize),
 	}
+	if provider, ok := srv.(ruleManagerProvider); ok {
+		m.ruleManager = func() placementRuleManager {
+			if ruleManager := provider.GetRuleManager(); ruleManager != nil {
+				return ruleManager
+			}
+			return nil
+		}
+	}
 	// The first initialization after the server is started.
 	srv.AddStartCallback(func() {
 		log.Info("resource group manager starts to initialize", zap.String("name", srv.Name()))
@@ -97,6 +137,43 @@ func (m *Manager) Init(ctx context.Context) {
 		}
 	}
 	m.storage.LoadResourceGroupStates(tokenHandler)
+	extendedSettingsHandler := func(k, v string) {
+		settings := &GroupExtendedSettings{}
+		if err := json.Unmarshal([]byte(v), settings); err != nil {
+			log.Error("err", zap.Error(err), zap.String("k", k), zap.String("v", v))
+			panic(err)
+		}
+		if group, ok := m.groups[k]; ok {
+			group.SetExtendedSettingsIntoResourceGroup(settings)
+		}
+	}
+	m.storage.LoadResourceGroupExtendedSettings(extendedSettingsHandler)
+	for _, group := range m.groups {
+		group.setBorrowPool(m.borrowPool)
+	}
+	schedulerConfig := PrioritySchedulerConfig{MinShareRatio: defaultMinShareRatio}
+	if _, err := m.storage.LoadResourceManagerSettings(prioritySchedulerSettingsName, &schedulerConfig); err != nil {
+		log.Error("failed to load the priority scheduler config", zap.Error(err))
+	}
+	m.priorityScheduler.SetConfig(schedulerConfig)
+	templates := make(map[string]*ResourceGroupTemplate)
+	if _, err := m.storage.LoadResourceManagerSettings(templatesSettingsName, &templates); err != nil {
+		log.Error("failed to load the resource group templates", zap.Error(err))
+	}
+	m.templates = templates
+	historyConfig := ConsumptionHistoryConfig{Retention: typeutil.NewDuration(defaultConsumptionHistoryRetention)}
+	if _, err := m.storage.LoadResourceManagerSettings(consumptionHistorySettingsName, &historyConfig); err != nil {
+		log.Error("failed to load the consumption history config", zap.Error(err))
+	}
+	m.consumptionHistory.SetConfig(historyConfig)
+	if err := m.runawayRules.load(m.storage); err != nil {
+		log.Error("failed to load the runaway rules", zap.Error(err))
+	}
+	ruModel := DefaultRUModel()
+	if _, err := m.storage.LoadResourceManagerSettings(ruModelSettingsName, ruModel); err != nil {
+		log.Error("failed to load the RU model", zap.Error(err))
+	}
+	m.ruModel = ruModel
 	// Start the background metrics flusher.
 	go m.backgroundMetricsFlush(ctx)
 	go m.persistLoop(ctx)
@@ -123,6 +200,7 @@ func (m *Manager) AddResourceGroup(group *ResourceGroup) error {
 	if err := group.persistStates(m.storage); err != nil {
 		return err
 	}
+	group.setBorrowPool(m.borrowPool)
 	m.groups[group.Name] = group
 	return nil
 }
@@ -146,14 +224,396 @@ func (m *Manager) ModifyResourceGroup(group *rmpb.ResourceGroup) error {
 	return curGroup.persistSettings(m.storage)
 }
 
+// SetResourceGroupBurstSettings sets the burst settings of an existing resource
+// group, nil removes them.
+func (m *Manager) SetResourceGroupBurstSettings(name string, burst *BurstSettings) error {
+	m.RLock()
+	curGroup, ok := m.groups[name]
+	m.RUnlock()
+	if !ok {
+		return errors.New("not exists the group")
+	}
+	if err := curGroup.SetBurstSettings(burst); err != nil {
+		return err
+	}
+	return curGroup.persistExtendedSettings(m.storage)
+}
+
+// SetResourceGroupBackgroundSettings sets the settings of the background tasks
+// of an existing resource group, nil removes them.
+func (m *Manager) SetResourceGroupBackgroundSettings(name string, settings *BackgroundSettings) error {
+	m.RLock()
+	curGroup, ok := m.groups[name]
+	m.RUnlock()
+	if !ok {
+		return errors.New("not exists the group")
+	}
+	if err := curGroup.SetBackgroundSettings(settings); err != nil {
+		return err
+	}
+	return curGroup.persistExtendedSettings(m.storage)
+}
+
+// SetResourceGroupPriority sets the priority of an existing resource group.
+func (m *Manager) SetResourceGroupPriority(name string, priority uint32) error {
+	m.RLock()
+	curGroup, ok := m.groups[name]
+	m.RUnlock()
+	if !ok {
+		return errors.New("not exists the group")
+	}
+	if err := curGroup.SetPriority(priority); err != nil {
+		return err
+	}
+	return curGroup.persistExtendedSettings(m.storage)
+}
+
+// SetResourceGroupRUQuotas sets the RU quotas in the calendar windows of an
+// existing resource group, nil removes them.
+func (m *Manager) SetResourceGroupRUQuotas(name string, quotas []*RUQuota) error {
+	m.RLock()
+	curGroup, ok := m.groups[name]
+	m.RUnlock()
+	if !ok {
+		return errors.New("not exists the group")
+	}
+	if err := curGroup.SetRUQuotas(quotas); err != nil {
+		return err
+	}
+	return curGroup.persistExtendedSettings(m.storage)
+}
+
+// GetPrioritySchedulerConfig returns the config of the priority scheduler.
+func (m *Manager) GetPrioritySchedulerConfig() PrioritySchedulerConfig {
+	return m.priorityScheduler.GetConfig()
+}
+
+// SetPrioritySchedulerConfig validates, persists and applies the config of the priority scheduler.
+func (m *Manager) SetPrioritySchedulerConfig(config PrioritySchedulerConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	if err := m.storage.SaveResourceManagerSettings(prioritySchedulerSettingsName, config); err != nil {
+		return err
+	}
+	m.priorityScheduler.SetConfig(config)
+	return nil
+}
+
+// admitRU returns the RU tokens admitted for the request of the resource group
+// by its priority.
+func (m *Manager) admitRU(now time.Time, rg *ResourceGroup, neededTokens float64) float64 {
+	return m.priorityScheduler.admit(now, rg.GetPriority(), neededTokens)
+}
+
+// ReportConsumption aggregates the RU consumption of the existing resource
+// groups on the stores. The records are all rejected if any of them is invalid.
+func (m *Manager) ReportConsumption(records []*ConsumptionRecord) error {
+	m.RLock()
+	for _, record := range records {
+		if err := record.validate(); err != nil {
+			m.RUnlock()
+			return err
+		}
+		if _, ok := m.groups[record.Group]; !ok {
+			m.RUnlock()
+			return errors.Errorf("resource group %s does not exist", record.Group)
+		}
+	}
+	m.RUnlock()
+	for _, record := range records {
+		m.consumption.add(record)
+	}
+	return nil
+}
+
+// QueryConsumption returns the aggregated RU consumption of the resource group
+// on the store. Empty group or zero store ID matches any.
+func (m *Manager) QueryConsumption(group string, storeID uint64) []*ConsumptionRecord {
+	return m.consumption.Query(group, storeID)
+}
+
+// GetConsumptionHistoryConfig returns the config of the RU consumption history.
+func (m *Manager) GetConsumptionHistoryConfig() ConsumptionHistoryConfig {
+	return m.consumptionHistory.GetConfig()
+}
+
+// SetConsumptionHistoryConfig validates, persists and applies the config of the RU consumption history.
+func (m *Manager) SetConsumptionHistoryConfig(config ConsumptionHistoryConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	if err := m.storage.SaveResourceManagerSettings(consumptionHistorySettingsName, config); err != nil {
+		return err
+	}
+	m.consumptionHistory.SetConfig(config)
+	return nil
+}
+
+// QueryConsumptionHistory returns the persisted RU consumption of the resource
+// group by minute in [start, end), sorted by group and time. Empty group matches
+// any. The consumption is persisted periodically, so the latest minutes may lag.
+func (m *Manager) QueryConsumptionHistory(group string, start, end time.Time) ([]*ConsumptionHistoryRecord, error) {
+	groups := []string{group}
+	if len(group) == 0 {
+		groups = m.getResourceGroupNames()
+	}
+	res := make([]*ConsumptionHistoryRecord, 0)
+	for _, name := range groups {
+		records, err := loadConsumptionHistory(m.storage, name, start, end)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, records...)
+	}
+	return res, nil
+}
+
+// SumConsumptionHistory returns the persisted RU consumption of each resource
+// group in [start, end), sorted by the total RU in descending order.
+func (m *Manager) SumConsumptionHistory(start, end time.Time) ([]*ConsumptionHistoryRecord, error) {
+	records, err := m.QueryConsumptionHistory("", start, end)
+	if err != nil {
+		return nil, err
+	}
+	return sumConsumptionHistory(records, start), nil
+}
+
+// PutResourceGroupTemplate creates or updates a resource group template, and
+// applies it to all the groups inheriting it.
+func (m *Manager) PutResourceGroupTemplate(template *ResourceGroupTemplate) error {
+	if err := template.Validate(); err != nil {
+		return err
+	}
+	m.Lock()
+	templates := make(map[string]*ResourceGroupTemplate, len(m.templates)+1)
+	for name, t := range m.templates {
+		templates[name] = t
+	}
+	templates[template.Name] = template
+	if err := m.storage.SaveResourceManagerSettings(templatesSettingsName, templates); err != nil {
+		m.Unlock()
+		return err
+	}
+	m.templates = templates
+	groups := make([]*ResourceGroup, 0)
+	for _, group := range m.groups {
+		if templateName, _ := group.getTemplate(); templateName == template.Name {
+			groups = append(groups, group)
+		}
+	}
+	m.Unlock()
+	for _, group := range groups {
+		_, overrides := group.getTemplate()
+		if err := m.applyTemplate(group, template, overrides); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteResourceGroupTemplate deletes a resource group template which is not
+// inherited by any group.
+func (m *Manager) DeleteResourceGroupTemplate(name string) error {
+	m.Lock()
+	defer m.Unlock()
+	if _, ok := m.templates[name]; !ok {
+		return errors.New("not exists the template")
+	}
+	for _, group := range m.groups {
+		if templateName, _ := group.getTemplate(); templateName == name {
+			return errors.Errorf("the template is inherited by resource group %s", group.Name)
+		}
+	}
+	templates := make(map[string]*ResourceGroupTemplate, len(m.templates))
+	for n, t := range m.templates {
+		if n != name {
+			templates[n] = t
+		}
+	}
+	if err := m.storage.SaveResourceManagerSettings(templatesSettingsName, templates); err != nil {
+		return err
+	}
+	m.templates = templates
+	return nil
+}
+
+// GetResourceGroupTemplates returns all the resource group templates sorted by name.
+func (m *Manager) GetResourceGroupTemplates() []*ResourceGroupTemplate {
+	m.RLock()
+	res := make([]*ResourceGroupTemplate, 0, len(m.templates))
+	for _, template := range m.templates {
+		res = append(res, template)
+	}
+	m.RUnlock()
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+// SetResourceGroupTemplate makes the resource group inherit from the template
+// with the overrides. Empty template name stops inheriting, and the inherited
+// settings are kept.
+func (m *Manager) SetResourceGroupTemplate(name, templateName string, overrides *TemplateSettings) error {
+	if overrides != nil {
+		if err := overrides.Validate(); err != nil {
+			return err
+		}
+	}
+	m.RLock()
+	curGroup, ok := m.groups[name]
+	template, templateOK := m.templates[templateName]
+	m.RUnlock()
+	if !ok {
+		return errors.New("not exists the group")
+	}
+	if len(templateName) == 0 {
+		curGroup.detachTemplate()
+		return curGroup.persistExtendedSettings(m.storage)
+	}
+	if !templateOK {
+		return errors.New("not exists the template")
+	}
+	return m.applyTemplate(curGroup, template, overrides)
+}
+
+func (m *Manager) applyTemplate(group *ResourceGroup, template *ResourceGroupTemplate, overrides *TemplateSettings) error {
+	if err := group.applyTemplate(template, overrides); err != nil {
+		return err
+	}
+	if err := group.persistSettings(m.storage); err != nil {
+		return err
+	}
+	return group.persistExtendedSettings(m.storage)
+}
+
+// SetResourceGroupStoreAffinity binds the data of an existing resource group to
+// the stores by applying the placement rules, nil removes the binding.
+func (m *Manager) SetResourceGroupStoreAffinity(name string, affinity *StoreAffinity) error {
+	if affinity != nil {
+		if err := affinity.Validate(); err != nil {
+			return err
+		}
+	}
+	m.RLock()
+	curGroup, ok := m.groups[name]
+	m.RUnlock()
+	if !ok {
+		return errors.New("not exists the group")
+	}
+	ruleManager := m.getRuleManager()
+	if ruleManager == nil {
+		return errNoRuleManager
+	}
+	var err error
+	if affinity != nil {
+		err = ruleManager.SetGroupBundle(makeAffinityRuleBundle(name, affinity))
+	} else {
+		err = ruleManager.DeleteGroupBundle(getAffinityRuleGroupID(name), false)
+	}
+	if err != nil {
+		return err
+	}
+	curGroup.SetStoreAffinity(affinity)
+	log.Info("resource group store affinity updated", zap.String("name", name), zap.Any("affinity", affinity))
+	return curGroup.persistExtendedSettings(m.storage)
+}
+
+func (m *Manager) getRuleManager() placementRuleManager {
+	if m.ruleManager == nil {
+		return nil
+	}
+	return m.ruleManager()
+}
+
+// SetResourceGroupRunawaySettings sets the runaway settings of an existing
+// resource group, nil removes them.
+func (m *Manager) SetResourceGroupRunawaySettings(name string, settings *RunawaySettings) error {
+	if settings != nil {
+		if err := settings.Validate(); err != nil {
+			return err
+		}
+	}
+	m.RLock()
+	_, ok := m.groups[name]
+	m.RUnlock()
+	if !ok {
+		return errors.New("not exists the group")
+	}
+	return m.runawayRules.setSettings(m.storage, name, settings)
+}
+
+// GetResourceGroupRunawaySettings returns the runaway settings of the resource group.
+func (m *Manager) GetResourceGroupRunawaySettings(name string) *RunawaySettings {
+	return m.runawayRules.GetSettings(name)
+}
+
+// AddRunawayWatch adds a watch item to an existing resource group, and returns
+// the item with its allocated ID. The start time is now if it is not set.
+func (m *Manager) AddRunawayWatch(item *RunawayWatchItem) (*RunawayWatchItem, error) {
+	if item.StartTime == 0 {
+		item.StartTime = time.Now().UnixMilli()
+	}
+	if err := item.Validate(); err != nil {
+		return nil, err
+	}
+	m.RLock()
+	_, ok := m.groups[item.ResourceGroup]
+	m.RUnlock()
+	if !ok {
+		return nil, errors.New("not exists the group")
+	}
+	if err := m.runawayRules.addWatch(m.storage, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// DeleteRunawayWatch deletes a watch item by its ID.
+func (m *Manager) DeleteRunawayWatch(id uint64) error {
+	return m.runawayRules.deleteWatch(m.storage, id)
+}
+
+// GetRunawayWatches returns the unexpired watch items of the resource group.
+// Empty group matches any.
+func (m *Manager) GetRunawayWatches(group string) []*RunawayWatchItem {
+	return m.runawayRules.GetWatches(time.Now(), group)
+}
+
+// GetBorrowPool returns the pool shared by the resource groups to borrow tokens from.
+func (m *Manager) GetBorrowPool() *BorrowPool {
+	return m.borrowPool
+}
+
 // DeleteResourceGroup deletes a resource group.
 func (m *Manager) DeleteResourceGroup(name string) error {
+	if group := m.GetMutableResourceGroup(name); group != nil && group.GetStoreAffinity() != nil {
+		// Unbind the data from the stores first, otherwise the rules are left behind.
+		ruleManager := m.getRuleManager()
+		if ruleManager == nil {
+			return errNoRuleManager
+		}
+		if err := ruleManager.DeleteGroupBundle(getAffinityRuleGroupID(name), false); err != nil {
+			return err
+		}
+	}
 	if err := m.storage.DeleteResourceGroupSetting(name); err != nil {
 		return err
 	}
+	if err := m.storage.DeleteResourceGroupExtendedSettings(name); err != nil {
+		return err
+	}
+	if err := m.consumptionHistory.remove(m.storage, name); err != nil {
+		return err
+	}
+	if err := m.runawayRules.gc(time.Now(), m.storage, name); err != nil {
+		return err
+	}
 	m.Lock()
 	delete(m.groups, name)
 	m.Unlock()
+	m.consumption.remove(name)
 	return nil
 }
 
@@ -204,17 +664,36 @@ func (m *Manager) persistLoop(ctx context.Context) {
 			return
 		case <-ticker.C:
 			m.persistResourceGroupRunningState()
+			m.persistConsumptionHistory(time.Now())
+			if err := m.runawayRules.gc(time.Now(), m.storage); err != nil {
+				log.Error("failed to remove the expired runaway watches", zap.Error(err))
+			}
 		}
 	}
 }
 
-func (m *Manager) persistResourceGroupRunningState() {
+func (m *Manager) getResourceGroupNames() []string {
 	m.RLock()
-	keys := make([]string, 0, len(m.groups))
-	for k := range m.groups {
-		keys = append(keys, k)
+	defer m.RUnlock()
+	names := make([]string, 0, len(m.groups))
+	for name := range m.groups {
+		names = append(names, name)
 	}
-	m.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+func (m *Manager) persistConsumptionHistory(now time.Time) {
+	if err := m.consumptionHistory.flush(now, m.storage); err != nil {
+		log.Error("failed to persist the consumption history", zap.Error(err))
+	}
+	if err := m.consumptionHistory.gc(now, m.storage, m.getResourceGroupNames()); err != nil {
+		log.Error("failed to remove the expired consumption history", zap.Error(err))
+	}
+}
+
+func (m *Manager) persistResourceGroupRunningState() {
+	keys := m.getResourceGroupNames()
 	for idx := 0; idx < len(keys); idx++ {
 		m.RLock()
 		group, ok := m.groups[keys[idx]]
@@ -247,6 +726,15 @@ func (m *Manager) backgroundMetricsFlush(ctx context.Context) {
 				readRequestCountMetrics  = requestCount.WithLabelValues(name, readTypeLabel)
 				writeRequestCountMetrics = requestCount.WithLabelValues(name, writeTypeLabel)
 			)
+			// The consumption reported by the token bucket requests is not attributed to any store.
+			m.consumption.add(&ConsumptionRecord{
+				Group:   name,
+				StoreID: unknownStoreID,
+				RRU:     consumption.RRU,
+				WRU:     consumption.WRU,
+			})
+			m.consumptionHistory.add(time.Now(), name, consumption.RRU, consumption.WRU)
+			m.workload.add(time.Now(), name, consumption)
 			// RU info.
 			if consumption.RRU != 0 {
 				rruMetrics.Observe(consumption.RRU)
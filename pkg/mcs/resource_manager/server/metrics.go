@@ -24,6 +24,8 @@ const (
 	resourceSubsystem      = "resource"
 	resourceGroupNameLabel = "name"
 	typeLabel              = "type"
+	storeLabel             = "store"
+	statementTypeLabel     = "statement_type"
 	readTypeLabel          = "read"
 	writeTypeLabel         = "write"
 )
@@ -87,6 +89,15 @@ var (
 			Name:      "request_count",
 			Help:      "The number of read/write requests for all resource groups.",
 		}, []string{resourceGroupNameLabel, typeLabel})
+
+	// storeRequestUnitCost is the RU consumption broken down by resource group and store.
+	storeRequestUnitCost = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: ruSubsystem,
+			Name:      "store_request_unit",
+			Help:      "Counter of the request unit cost for all resource groups on each store.",
+		}, []string{resourceGroupNameLabel, storeLabel, statementTypeLabel, typeLabel})
 )
 
 func init() {
@@ -97,4 +108,5 @@ func init() {
 	prometheus.MustRegister(kvCPUCost)
 	prometheus.MustRegister(sqlCPUCost)
 	prometheus.MustRegister(requestCount)
+	prometheus.MustRegister(storeRequestUnitCost)
 }
@@ -0,0 +1,153 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,g
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pingcap/errors"
+)
+
+// The priorities of resource groups, the higher the more important.
+const (
+	MinPriority     uint32 = 1
+	DefaultPriority uint32 = 8
+	MaxPriority     uint32 = 16
+)
+
+const (
+	// prioritySchedulerSettingsName is the name to persist the scheduler config.
+	prioritySchedulerSettingsName = "priority_scheduler"
+	// defaultMinShareRatio is the default ratio of the needed tokens always
+	// admitted, which protects the low priority groups from starvation.
+	defaultMinShareRatio = 0.1
+	// demandDecayWindow is the time window in which the demands decay to 1/e.
+	demandDecayWindow = time.Second
+)
+
+// PrioritySchedulerConfig is the config of the PriorityScheduler.
+type PrioritySchedulerConfig struct {
+	// RUCapacity is the RU per second supplied by the cluster.
+	// Zero disables the priority-based admission.
+	RUCapacity float64 `json:"ru_capacity"`
+	// MinShareRatio is the ratio of the needed tokens admitted for the groups
+	// of any priority even if the supply is exhausted.
+	MinShareRatio float64 `json:"min_share_ratio"`
+}
+
+// Validate checks whether the config is valid.
+func (c *PrioritySchedulerConfig) Validate() error {
+	if c.RUCapacity < 0 || math.IsNaN(c.RUCapacity) || math.IsInf(c.RUCapacity, 0) {
+		return errors.Errorf("invalid RU capacity %v, it should be a finite non-negative number", c.RUCapacity)
+	}
+	if c.MinShareRatio < 0 || c.MinShareRatio > 1 {
+		return errors.Errorf("invalid min share ratio %v, it should be in [0,1]", c.MinShareRatio)
+	}
+	return nil
+}
+
+func validatePriority(priority uint32) error {
+	if priority < MinPriority || priority > MaxPriority {
+		return errors.Errorf("invalid priority %d, it should be in [%d,%d]", priority, MinPriority, MaxPriority)
+	}
+	return nil
+}
+
+// PriorityScheduler admits the token requests of the resource groups by their
+// priorities when the RU supply of the cluster is constrained. The supply is
+// modeled as a token bucket filled at the RU capacity. A request can only use
+// the supply beyond the recent demands of the higher priority groups, so they
+// get tokens first. And a min share of each request is always admitted to
+// avoid starving the low priority groups.
+type PriorityScheduler struct {
+	sync.Mutex
+	config     PrioritySchedulerConfig
+	tokens     float64
+	lastUpdate time.Time
+	// demands are the recently requested tokens of each priority, which decay
+	// exponentially over time.
+	demands map[uint32]float64
+}
+
+// NewPriorityScheduler creates a new PriorityScheduler with the config.
+func NewPriorityScheduler(config PrioritySchedulerConfig) *PriorityScheduler {
+	return &PriorityScheduler{
+		config:  config,
+		demands: make(map[uint32]float64),
+	}
+}
+
+// GetConfig returns the config of the scheduler.
+func (s *PriorityScheduler) GetConfig() PrioritySchedulerConfig {
+	s.Lock()
+	defer s.Unlock()
+	return s.config
+}
+
+// SetConfig updates the config of the scheduler.
+func (s *PriorityScheduler) SetConfig(config PrioritySchedulerConfig) {
+	s.Lock()
+	defer s.Unlock()
+	s.config = config
+	s.tokens = math.Min(s.tokens, config.RUCapacity)
+}
+
+// admit returns the tokens admitted for the request of the priority.
+func (s *PriorityScheduler) admit(now time.Time, priority uint32, neededTokens float64) float64 {
+	s.Lock()
+	defer s.Unlock()
+	if s.config.RUCapacity <= 0 || neededTokens <= 0 {
+		return neededTokens
+	}
+	s.update(now)
+	var reserved float64
+	for p, demand := range s.demands {
+		if p > priority {
+			reserved += demand
+		}
+	}
+	s.demands[priority] += neededTokens
+	admitted := math.Min(neededTokens, math.Max(s.tokens-reserved, 0))
+	admitted = math.Max(admitted, neededTokens*s.config.MinShareRatio)
+	s.tokens -= admitted
+	return admitted
+}
+
+// update refills the tokens and decays the demands.
+func (s *PriorityScheduler) update(now time.Time) {
+	// The supply is full at first.
+	if s.lastUpdate.IsZero() {
+		s.tokens = s.config.RUCapacity
+		s.lastUpdate = now
+		return
+	}
+	delta := now.Sub(s.lastUpdate)
+	if delta <= 0 {
+		return
+	}
+	s.lastUpdate = now
+	// The supply is accumulated at most one second.
+	s.tokens = math.Min(s.tokens+s.config.RUCapacity*delta.Seconds(), s.config.RUCapacity)
+	decay := math.Exp(-delta.Seconds() / demandDecayWindow.Seconds())
+	for p, demand := range s.demands {
+		if demand *= decay; demand < 1 {
+			delete(s.demands, p)
+		} else {
+			s.demands[p] = demand
+		}
+	}
+}
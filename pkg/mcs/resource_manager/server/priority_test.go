@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	rmpb "github.com/pingcap/kvproto/pkg/resource_manager"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/storage/kv"
+)
+
+func TestPrioritySchedulerAdmit(t *testing.T) {
+	re := require.New(t)
+	s := NewPriorityScheduler(PrioritySchedulerConfig{RUCapacity: 1000, MinShareRatio: 0.1})
+	now := time.Now()
+	re.Equal(600., s.admit(now, MaxPriority, 600))
+	// The lower priority groups only get the min share while the supply is
+	// reserved for the higher priority.
+	re.Equal(50., s.admit(now, MinPriority, 500))
+	re.Equal(10., s.admit(now, DefaultPriority, 100))
+	// The higher priority group is not affected by the lower ones.
+	re.Equal(340., s.admit(now, MaxPriority, 400))
+	re.Zero(s.tokens)
+
+	// The supply is refilled and the demands decay over time.
+	now = now.Add(10 * time.Second)
+	re.Equal(500., s.admit(now, MinPriority, 500))
+	re.LessOrEqual(math.Abs(s.tokens-500), 1e-7)
+	re.NotContains(s.demands, MaxPriority)
+
+	// The admission is disabled without the RU capacity.
+	s.SetConfig(PrioritySchedulerConfig{})
+	re.Equal(1e9, s.admit(now, MinPriority, 1e9))
+	re.Equal(0., s.tokens)
+}
+
+func TestValidatePriority(t *testing.T) {
+	re := require.New(t)
+	re.Error(validatePriority(MinPriority - 1))
+	re.Error(validatePriority(MaxPriority + 1))
+	re.NoError(validatePriority(DefaultPriority))
+	for _, config := range []PrioritySchedulerConfig{
+		{RUCapacity: -1},
+		{RUCapacity: math.Inf(1)},
+		{MinShareRatio: -0.1},
+		{MinShareRatio: 1.1},
+	} {
+		re.Error(config.Validate())
+	}
+	re.NoError((&PrioritySchedulerConfig{RUCapacity: 1000, MinShareRatio: 1}).Validate())
+}
+
+func TestResourceGroupPriority(t *testing.T) {
+	re := require.New(t)
+	storage := endpoint.NewStorageEndpoint(kv.NewMemoryKV(), nil)
+	m := newTestManager(storage)
+	re.NoError(m.AddResourceGroup(&ResourceGroup{Name: "test", Mode: rmpb.GroupMode_RUMode}))
+	re.Equal(DefaultPriority, m.GetMutableResourceGroup("test").GetPriority())
+	re.Error(m.SetResourceGroupPriority("test", MaxPriority+1))
+	re.Error(m.SetResourceGroupPriority("unknown", MaxPriority))
+	re.NoError(m.SetResourceGroupPriority("test", MaxPriority))
+	re.Error(m.SetPrioritySchedulerConfig(PrioritySchedulerConfig{RUCapacity: -1}))
+	config := PrioritySchedulerConfig{RUCapacity: 1000, MinShareRatio: 0.2}
+	re.NoError(m.SetPrioritySchedulerConfig(config))
+
+	// The priority and the scheduler config are persisted and reloaded.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := newTestManager(storage)
+	reloaded.Init(ctx)
+	group := reloaded.GetMutableResourceGroup("test")
+	re.Equal(MaxPriority, group.GetPriority())
+	re.Equal(MaxPriority, reloaded.GetResourceGroup("test").GetPriority())
+	re.Equal(config, reloaded.GetPrioritySchedulerConfig())
+	re.Equal(500., reloaded.admitRU(time.Now(), group, 500))
+}
@@ -0,0 +1,168 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,g
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"math"
+	"time"
+
+	"github.com/pingcap/errors"
+	rmpb "github.com/pingcap/kvproto/pkg/resource_manager"
+)
+
+// The calendar windows of the RU quotas, which are aligned in UTC.
+const (
+	QuotaWindowHour = "hour"
+	QuotaWindowDay  = "day"
+)
+
+// RUQuota limits the RU granted to a resource group in each calendar window,
+// in addition to the fill rate of its token bucket.
+type RUQuota struct {
+	Window string  `json:"window"`
+	MaxRU  float64 `json:"max_ru"`
+}
+
+// Validate checks whether the quota is valid.
+func (q *RUQuota) Validate() error {
+	if q.Window != QuotaWindowHour && q.Window != QuotaWindowDay {
+		return errors.Errorf("invalid quota window %s, it should be %s or %s", q.Window, QuotaWindowHour, QuotaWindowDay)
+	}
+	if q.MaxRU < 0 || math.IsNaN(q.MaxRU) || math.IsInf(q.MaxRU, 0) {
+		return errors.Errorf("invalid max RU %v, it should be a finite non-negative number", q.MaxRU)
+	}
+	return nil
+}
+
+func validateRUQuotas(quotas []*RUQuota) error {
+	windows := make(map[string]struct{}, len(quotas))
+	for _, quota := range quotas {
+		if quota == nil {
+			return errors.New("quota should not be empty")
+		}
+		if err := quota.Validate(); err != nil {
+			return err
+		}
+		if _, ok := windows[quota.Window]; ok {
+			return errors.Errorf("duplicated quota window %s", quota.Window)
+		}
+		windows[quota.Window] = struct{}{}
+	}
+	return nil
+}
+
+// getWindowStart returns the start of the calendar window containing now.
+func getWindowStart(window string, now time.Time) time.Time {
+	now = now.UTC()
+	if window == QuotaWindowDay {
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return now.Truncate(time.Hour)
+}
+
+// RUQuotaUsage is the RU granted to a resource group in the current window of a quota.
+type RUQuotaUsage struct {
+	Window string `json:"window"`
+	// Start is the unix timestamp in seconds of the start of the window.
+	Start int64   `json:"start"`
+	Used  float64 `json:"used"`
+}
+
+// getQuotaUsage returns the usage of the quota window containing now, and
+// resets the usage of the past windows.
+func (settings *RequestUnitSettings) getQuotaUsage(window string, now time.Time) *RUQuotaUsage {
+	start := getWindowStart(window, now).Unix()
+	for _, usage := range settings.QuotaUsages {
+		if usage.Window == window {
+			if usage.Start != start {
+				usage.Start, usage.Used = start, 0
+			}
+			return usage
+		}
+	}
+	usage := &RUQuotaUsage{Window: window, Start: start}
+	settings.QuotaUsages = append(settings.QuotaUsages, usage)
+	return usage
+}
+
+// getRemainingQuota returns the RU which can still be granted in the current
+// windows, and whether there is any quota.
+func (settings *RequestUnitSettings) getRemainingQuota(now time.Time) (float64, bool) {
+	remaining := math.Inf(1)
+	for _, quota := range settings.Quotas {
+		remaining = math.Min(remaining, quota.MaxRU-settings.getQuotaUsage(quota.Window, now).Used)
+	}
+	return math.Max(remaining, 0), len(settings.Quotas) > 0
+}
+
+// requestWithinQuotas requests the tokens from the bucket, and limits the
+// granted tokens by the remaining RU quotas.
+func (settings *RequestUnitSettings) requestWithinQuotas(
+	bucket *GroupTokenBucket,
+	now time.Time,
+	neededTokens float64,
+	targetPeriodMs uint64,
+) *rmpb.GrantedRUTokenBucket {
+	remaining, limited := settings.getRemainingQuota(now)
+	if !limited {
+		tb, trickleTimeMs := bucket.request(now, neededTokens, targetPeriodMs)
+		return &rmpb.GrantedRUTokenBucket{GrantedTokens: tb, TrickleTimeMs: trickleTimeMs}
+	}
+	tb, trickleTimeMs := bucket.request(now, math.Min(neededTokens, remaining), targetPeriodMs)
+	// The reserved tokens granted by the bucket may still exceed the quota.
+	tb.Tokens = math.Min(tb.Tokens, remaining)
+	// The client does not limit the unlimited bucket, so the quota can not be enforced with it.
+	if tb.Settings.BurstLimit < 0 {
+		tb.Settings.BurstLimit = 0
+	}
+	// Slow down the client if the quota is exhausted.
+	if tb.Tokens < neededTokens && trickleTimeMs < int64(targetPeriodMs) {
+		trickleTimeMs = int64(targetPeriodMs)
+	}
+	for _, quota := range settings.Quotas {
+		settings.getQuotaUsage(quota.Window, now).Used += tb.Tokens
+	}
+	return &rmpb.GrantedRUTokenBucket{GrantedTokens: tb, TrickleTimeMs: trickleTimeMs}
+}
+
+// SetRUQuotas sets the RU quotas of the resource group, nil removes them. The
+// usages of the kept windows are not reset.
+func (rg *ResourceGroup) SetRUQuotas(quotas []*RUQuota) error {
+	if err := validateRUQuotas(quotas); err != nil {
+		return err
+	}
+	rg.Lock()
+	defer rg.Unlock()
+	if rg.Mode != rmpb.GroupMode_RUMode || rg.RUSettings == nil {
+		return errors.New("only support RU quotas in RU mode")
+	}
+	rg.setRUQuotas(quotas)
+	return nil
+}
+
+func (rg *ResourceGroup) setRUQuotas(quotas []*RUQuota) {
+	settings := rg.RUSettings
+	settings.Quotas = quotas
+	usages := make([]*RUQuotaUsage, 0, len(settings.QuotaUsages))
+	for _, usage := range settings.QuotaUsages {
+		for _, quota := range quotas {
+			if quota.Window == usage.Window {
+				usages = append(usages, usage)
+				break
+			}
+		}
+	}
+	settings.QuotaUsages = usages
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	rmpb "github.com/pingcap/kvproto/pkg/resource_manager"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/storage/kv"
+)
+
+func TestValidateRUQuotas(t *testing.T) {
+	re := require.New(t)
+	for _, quotas := range [][]*RUQuota{
+		{nil},
+		{{Window: "week", MaxRU: 1}},
+		{{Window: QuotaWindowDay, MaxRU: -1}},
+		{{Window: QuotaWindowDay, MaxRU: math.NaN()}},
+		{{Window: QuotaWindowDay, MaxRU: 1}, {Window: QuotaWindowDay, MaxRU: 2}},
+	} {
+		re.Error(validateRUQuotas(quotas))
+	}
+	re.NoError(validateRUQuotas(nil))
+	re.NoError(validateRUQuotas([]*RUQuota{{Window: QuotaWindowHour, MaxRU: 1}, {Window: QuotaWindowDay}}))
+
+	now := time.Date(2023, 3, 4, 5, 6, 7, 0, time.FixedZone("UTC+8", 8*3600))
+	re.Equal(time.Date(2023, 3, 3, 21, 0, 0, 0, time.UTC), getWindowStart(QuotaWindowHour, now))
+	re.Equal(time.Date(2023, 3, 3, 0, 0, 0, 0, time.UTC), getWindowStart(QuotaWindowDay, now))
+}
+
+func TestRequestRUWithinQuotas(t *testing.T) {
+	re := require.New(t)
+	rg := &ResourceGroup{Name: "test", Mode: rmpb.GroupMode_RUMode, RUSettings: NewRequestUnitSettings(&rmpb.TokenBucket{
+		Settings: &rmpb.TokenLimitSettings{FillRate: 10000},
+	})}
+	re.NoError(rg.CheckAndInit())
+	re.NoError(rg.SetRUQuotas([]*RUQuota{{Window: QuotaWindowHour, MaxRU: 100}, {Window: QuotaWindowDay, MaxRU: 150}}))
+	now := time.Date(2023, 3, 4, 5, 6, 7, 0, time.UTC)
+	tokens := rg.RequestRU(now, 60, 1000)
+	re.Equal(60., tokens.GrantedTokens.GetTokens())
+	re.Zero(tokens.TrickleTimeMs)
+	// The request is limited by the hourly quota, and the client is slowed down.
+	tokens = rg.RequestRU(now, 60, 1000)
+	re.Equal(40., tokens.GrantedTokens.GetTokens())
+	re.Equal(int64(1000), tokens.TrickleTimeMs)
+	re.Zero(rg.RequestRU(now, 60, 1000).GrantedTokens.GetTokens())
+
+	// The hourly usage is reset in the next hour, while the daily usage is not.
+	now = now.Add(time.Hour)
+	re.Equal(50., rg.RequestRU(now, 60, 1000).GrantedTokens.GetTokens())
+	re.Equal([]*RUQuotaUsage{
+		{Window: QuotaWindowHour, Start: getWindowStart(QuotaWindowHour, now).Unix(), Used: 50},
+		{Window: QuotaWindowDay, Start: getWindowStart(QuotaWindowDay, now).Unix(), Used: 150},
+	}, rg.GetGroupStates().QuotaUsages)
+	now = now.Add(24 * time.Hour)
+	re.Equal(60., rg.RequestRU(now, 60, 1000).GrantedTokens.GetTokens())
+
+	// The unlimited bucket is limited by the quota.
+	re.NoError(rg.PatchSettings(&rmpb.ResourceGroup{Name: "test", Mode: rmpb.GroupMode_RUMode, RUSettings: &rmpb.GroupRequestUnitSettings{
+		RU: &rmpb.TokenBucket{Settings: &rmpb.TokenLimitSettings{FillRate: 10000, BurstLimit: -1}},
+	}}))
+	tokens = rg.RequestRU(now, 1000, 1000)
+	re.Equal(40., tokens.GrantedTokens.GetTokens())
+	re.Zero(tokens.GrantedTokens.GetSettings().GetBurstLimit())
+	// Removing a quota drops its usage.
+	re.NoError(rg.SetRUQuotas([]*RUQuota{{Window: QuotaWindowDay, MaxRU: 1000}}))
+	re.Len(rg.GetGroupStates().QuotaUsages, 1)
+	re.NoError(rg.SetRUQuotas(nil))
+	re.Empty(rg.GetGroupStates().QuotaUsages)
+	tokens = rg.RequestRU(now, 1000, 1000)
+	re.Equal(1000., tokens.GrantedTokens.GetTokens())
+	re.Equal(int64(-1), tokens.GrantedTokens.GetSettings().GetBurstLimit())
+
+	rawGroup := &ResourceGroup{Name: "raw", Mode: rmpb.GroupMode_RawMode}
+	re.NoError(rawGroup.CheckAndInit())
+	re.Error(rawGroup.SetRUQuotas([]*RUQuota{{Window: QuotaWindowDay, MaxRU: 1}}))
+}
+
+func TestResourceGroupRUQuotas(t *testing.T) {
+	re := require.New(t)
+	storage := endpoint.NewStorageEndpoint(kv.NewMemoryKV(), nil)
+	m := newTestManager(storage)
+	re.NoError(m.AddResourceGroup(&ResourceGroup{Name: "test", Mode: rmpb.GroupMode_RUMode, RUSettings: NewRequestUnitSettings(&rmpb.TokenBucket{
+		Settings: &rmpb.TokenLimitSettings{FillRate: 10000},
+	})}))
+	quotas := []*RUQuota{{Window: QuotaWindowDay, MaxRU: 100}}
+	re.Error(m.SetResourceGroupRUQuotas("unknown", quotas))
+	re.Error(m.SetResourceGroupRUQuotas("test", []*RUQuota{{Window: "week"}}))
+	re.NoError(m.SetResourceGroupRUQuotas("test", quotas))
+	group := m.GetMutableResourceGroup("test")
+	re.Equal(30., group.RequestRU(time.Now(), 30, 1000).GrantedTokens.GetTokens())
+	m.persistResourceGroupRunningState()
+
+	// The quotas and their usages are persisted and reloaded.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := newTestManager(storage)
+	reloaded.Init(ctx)
+	copied := reloaded.GetResourceGroup("test")
+	re.Equal(quotas, copied.RUSettings.Quotas)
+	re.Len(copied.RUSettings.QuotaUsages, 1)
+	re.Equal(30., copied.RUSettings.QuotaUsages[0].Used)
+	re.Equal(70., reloaded.GetMutableResourceGroup("test").RequestRU(time.Now(), 100, 1000).GrantedTokens.GetTokens())
+}
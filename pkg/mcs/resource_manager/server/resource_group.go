@@ -17,9 +17,11 @@ package server
 
 import (
 	"encoding/json"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/gogo/protobuf/proto"
 	"github.com/pingcap/errors"
 	rmpb "github.com/pingcap/kvproto/pkg/resource_manager"
 	"github.com/pingcap/log"
@@ -32,15 +34,33 @@ type ResourceGroup struct {
 	sync.RWMutex
 	Name string         `json:"name"`
 	Mode rmpb.GroupMode `json:"mode"`
+	// Priority is the priority to admit the token requests, zero means DefaultPriority.
+	Priority uint32 `json:"priority,omitempty"`
+	// Template is the name of the template the group inherits from, empty if none.
+	Template string `json:"template,omitempty"`
+	// TemplateOverrides overrides the settings inherited from the template.
+	TemplateOverrides *TemplateSettings `json:"template_overrides,omitempty"`
+	// StoreAffinity binds the data of the group to the stores, nil if not bound.
+	StoreAffinity *StoreAffinity `json:"store_affinity,omitempty"`
 	// RU settings
 	RUSettings *RequestUnitSettings `json:"r_u_settings,omitempty"`
 	// raw resource settings
 	RawResourceSettings *RawResourceSettings `json:"raw_resource_settings,omitempty"`
+	// demand tracks the RU demand of the token requests.
+	demand tokenDemand
 }
 
 // RequestUnitSettings is the definition of the RU settings.
 type RequestUnitSettings struct {
 	RU GroupTokenBucket `json:"ru,omitempty"`
+	// BackgroundSettings is the settings of the background tasks, nil if not configured.
+	BackgroundSettings *BackgroundSettings `json:"background_settings,omitempty"`
+	// Background is the RU budget of the classified background tasks.
+	Background *GroupTokenBucket `json:"background,omitempty"`
+	// Quotas limit the RU granted in the calendar windows.
+	Quotas []*RUQuota `json:"quotas,omitempty"`
+	// QuotaUsages are the RU granted in the current windows of the quotas.
+	QuotaUsages []*RUQuotaUsage `json:"quota_usages,omitempty"`
 }
 
 // NewRequestUnitSettings creates a new RequestUnitSettings with the given token bucket.
@@ -95,9 +115,18 @@ func (rg *ResourceGroup) Copy() *ResourceGroup {
 // CheckAndInit checks the validity of the resource group and initializes the default values if not setting.
 // Only used to initialize the resource group when creating.
 func (rg *ResourceGroup) CheckAndInit() error {
-	if len(rg.Name) == 0 || len(rg.Name) > 32 {
+	keyspaceID, name := SplitKeyspaceResourceGroupName(rg.Name)
+	if len(name) == 0 || len(name) > 32 {
 		return errors.New("invalid resource group name, the length should be in [1,32]")
 	}
+	if strings.Contains(name, backgroundTaskSeparator) || strings.Contains(name, keyspaceSeparator) {
+		return errors.Errorf("invalid resource group name, it should not contain %s or %s", backgroundTaskSeparator, keyspaceSeparator)
+	}
+	if strings.Contains(rg.Name, keyspaceSeparator) {
+		if err := validateKeyspaceID(keyspaceID); err != nil {
+			return err
+		}
+	}
 	switch rg.Mode {
 	case rmpb.GroupMode_RUMode:
 		if rg.RUSettings == nil {
@@ -180,8 +209,32 @@ func (rg *ResourceGroup) RequestRU(
 	if rg.RUSettings == nil || rg.RUSettings.RU.Settings == nil {
 		return nil
 	}
-	tb, trickleTimeMs := rg.RUSettings.RU.request(now, neededTokens, targetPeriodMs)
-	return &rmpb.GrantedRUTokenBucket{GrantedTokens: tb, TrickleTimeMs: trickleTimeMs}
+	return rg.RUSettings.requestWithinQuotas(&rg.RUSettings.RU, now, neededTokens, targetPeriodMs)
+}
+
+// RequestBackgroundRU requests the RU of the background task of the resource group.
+// The classified background tasks consume the background budget, and only a part
+// of the needed tokens are requested while the foreground is throttled. Others
+// consume the foreground budget like RequestRU.
+func (rg *ResourceGroup) RequestBackgroundRU(
+	now time.Time,
+	taskType string,
+	neededTokens float64,
+	targetPeriodMs uint64,
+) *rmpb.GrantedRUTokenBucket {
+	rg.Lock()
+	defer rg.Unlock()
+	if rg.RUSettings == nil || rg.RUSettings.RU.Settings == nil {
+		return nil
+	}
+	bucket := &rg.RUSettings.RU
+	if settings := rg.RUSettings.BackgroundSettings; settings != nil && settings.classify(taskType) {
+		if bucket.Initialized && bucket.Tokens < 0 {
+			neededTokens *= settings.getDeprioritizedRatio()
+		}
+		bucket = rg.RUSettings.Background
+	}
+	return rg.RUSettings.requestWithinQuotas(bucket, now, neededTokens, targetPeriodMs)
 }
 
 // IntoProtoResourceGroup converts a ResourceGroup to a rmpb.ResourceGroup.
@@ -228,6 +281,8 @@ type GroupStates struct {
 	CPU     *GroupTokenBucketState `json:"cpu,omitempty"`
 	IORead  *GroupTokenBucketState `json:"io_read,omitempty"`
 	IOWrite *GroupTokenBucketState `json:"io_write,omitempty"`
+	// RU quota usages
+	QuotaUsages []*RUQuotaUsage `json:"quota_usages,omitempty"`
 }
 
 // GetGroupStates get the token set of ResourceGroup.
@@ -239,6 +294,10 @@ func (rg *ResourceGroup) GetGroupStates() *GroupStates {
 		tokens := &GroupStates{
 			RU: rg.RUSettings.RU.GroupTokenBucketState.Clone(),
 		}
+		for _, usage := range rg.RUSettings.QuotaUsages {
+			copied := *usage
+			tokens.QuotaUsages = append(tokens.QuotaUsages, &copied)
+		}
 		return tokens
 	case rmpb.GroupMode_RawMode: // Raw mode
 		tokens := &GroupStates{
@@ -258,6 +317,7 @@ func (rg *ResourceGroup) SetStatesIntoResourceGroup(states *GroupStates) {
 		if state := states.RU; state != nil {
 			rg.RUSettings.RU.GroupTokenBucketState = *state
 		}
+		rg.RUSettings.QuotaUsages = states.QuotaUsages
 	case rmpb.GroupMode_RawMode:
 		if state := states.CPU; state != nil {
 			rg.RawResourceSettings.CPU.GroupTokenBucketState = *state
@@ -276,3 +336,192 @@ func (rg *ResourceGroup) persistStates(storage endpoint.ResourceGroupStorage) er
 	states := rg.GetGroupStates()
 	return storage.SaveResourceGroupStates(rg.Name, states)
 }
+
+// GroupExtendedSettings is the settings of a resource group which are not in
+// the protocol, so they are only known by the server and persisted separately.
+type GroupExtendedSettings struct {
+	// Burst is the burst settings of the RU token bucket.
+	Burst *BurstSettings `json:"burst,omitempty"`
+	// Background is the settings of the background tasks.
+	Background *BackgroundSettings `json:"background,omitempty"`
+	// Priority is the priority of the resource group.
+	Priority uint32 `json:"priority,omitempty"`
+	// Template is the name of the template the group inherits from.
+	Template string `json:"template,omitempty"`
+	// TemplateOverrides overrides the settings inherited from the template.
+	TemplateOverrides *TemplateSettings `json:"template_overrides,omitempty"`
+	// Quotas are the RU quotas in the calendar windows.
+	Quotas []*RUQuota `json:"quotas,omitempty"`
+	// StoreAffinity binds the data of the group to the stores.
+	StoreAffinity *StoreAffinity `json:"store_affinity,omitempty"`
+}
+
+// Validate checks whether the extended settings are valid. Whether the template
+// exists is checked by the manager.
+func (s *GroupExtendedSettings) Validate() error {
+	if s.Burst != nil {
+		if err := s.Burst.Validate(); err != nil {
+			return err
+		}
+	}
+	if s.Background != nil {
+		if err := s.Background.Validate(); err != nil {
+			return err
+		}
+	}
+	if s.Priority != 0 {
+		if err := validatePriority(s.Priority); err != nil {
+			return err
+		}
+	}
+	if s.TemplateOverrides != nil {
+		if err := s.TemplateOverrides.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := validateRUQuotas(s.Quotas); err != nil {
+		return err
+	}
+	if s.StoreAffinity != nil {
+		return s.StoreAffinity.Validate()
+	}
+	return nil
+}
+
+// GetExtendedSettings returns the extended settings of ResourceGroup.
+func (rg *ResourceGroup) GetExtendedSettings() *GroupExtendedSettings {
+	rg.RLock()
+	defer rg.RUnlock()
+	settings := &GroupExtendedSettings{
+		Priority:          rg.Priority,
+		Template:          rg.Template,
+		TemplateOverrides: rg.TemplateOverrides,
+		StoreAffinity:     rg.StoreAffinity,
+	}
+	if rg.Mode != rmpb.GroupMode_RUMode {
+		return settings
+	}
+	if rg.RUSettings.RU.Burst != nil {
+		burst := *rg.RUSettings.RU.Burst
+		settings.Burst = &burst
+	}
+	if background := rg.RUSettings.BackgroundSettings; background != nil {
+		settings.Background = &BackgroundSettings{
+			TaskTypes:          append([]string(nil), background.TaskTypes...),
+			DeprioritizedRatio: background.DeprioritizedRatio,
+		}
+		if background.Settings != nil {
+			settings.Background.Settings = proto.Clone(background.Settings).(*rmpb.TokenLimitSettings)
+		}
+	}
+	for _, quota := range rg.RUSettings.Quotas {
+		copied := *quota
+		settings.Quotas = append(settings.Quotas, &copied)
+	}
+	return settings
+}
+
+// SetExtendedSettingsIntoResourceGroup updates the extended settings of resource group.
+func (rg *ResourceGroup) SetExtendedSettingsIntoResourceGroup(settings *GroupExtendedSettings) {
+	rg.Priority = settings.Priority
+	rg.Template = settings.Template
+	rg.TemplateOverrides = settings.TemplateOverrides
+	rg.StoreAffinity = settings.StoreAffinity
+	if rg.Mode == rmpb.GroupMode_RUMode && rg.RUSettings != nil {
+		rg.RUSettings.RU.Burst = settings.Burst
+		rg.setBackgroundSettings(settings.Background)
+		rg.setRUQuotas(settings.Quotas)
+	}
+}
+
+// SetBackgroundSettings sets the settings of the background tasks, nil removes them.
+func (rg *ResourceGroup) SetBackgroundSettings(settings *BackgroundSettings) error {
+	if settings != nil {
+		if err := settings.Validate(); err != nil {
+			return err
+		}
+	}
+	rg.Lock()
+	defer rg.Unlock()
+	if rg.Mode != rmpb.GroupMode_RUMode || rg.RUSettings == nil {
+		return errors.New("only support background settings in RU mode")
+	}
+	rg.setBackgroundSettings(settings)
+	return nil
+}
+
+func (rg *ResourceGroup) setBackgroundSettings(settings *BackgroundSettings) {
+	ruSettings := rg.RUSettings
+	ruSettings.BackgroundSettings = settings
+	if settings == nil {
+		ruSettings.Background = nil
+		return
+	}
+	limit := &rmpb.TokenLimitSettings{}
+	if settings.Settings != nil {
+		limit = proto.Clone(settings.Settings).(*rmpb.TokenLimitSettings)
+	}
+	if ruSettings.Background == nil {
+		ruSettings.Background = &GroupTokenBucket{Settings: limit}
+		return
+	}
+	ruSettings.Background.patch(&rmpb.TokenBucket{Settings: limit})
+}
+
+// SetBurstSettings sets the burst settings of the RU token bucket, nil removes them.
+func (rg *ResourceGroup) SetBurstSettings(burst *BurstSettings) error {
+	if burst != nil {
+		if err := burst.Validate(); err != nil {
+			return err
+		}
+	}
+	rg.Lock()
+	defer rg.Unlock()
+	if rg.Mode != rmpb.GroupMode_RUMode || rg.RUSettings == nil {
+		return errors.New("only support burst settings in RU mode")
+	}
+	rg.RUSettings.RU.Burst = burst
+	// Drop the credits exceeding the new limit.
+	if burst == nil {
+		rg.RUSettings.RU.Credit = 0
+	} else if rg.RUSettings.RU.Credit > burst.MaxCredit {
+		rg.RUSettings.RU.Credit = burst.MaxCredit
+	}
+	return nil
+}
+
+// GetPriority returns the priority of the resource group.
+func (rg *ResourceGroup) GetPriority() uint32 {
+	rg.RLock()
+	defer rg.RUnlock()
+	if rg.Priority == 0 {
+		return DefaultPriority
+	}
+	return rg.Priority
+}
+
+// SetPriority sets the priority of the resource group.
+func (rg *ResourceGroup) SetPriority(priority uint32) error {
+	if err := validatePriority(priority); err != nil {
+		return err
+	}
+	rg.Lock()
+	defer rg.Unlock()
+	rg.Priority = priority
+	return nil
+}
+
+// setBorrowPool sets the shared pool which the token buckets borrow tokens from.
+func (rg *ResourceGroup) setBorrowPool(pool *BorrowPool) {
+	rg.Lock()
+	defer rg.Unlock()
+	if rg.RUSettings != nil {
+		rg.RUSettings.RU.borrowPool = pool
+	}
+}
+
+// persistExtendedSettings persists the resource group extended settings.
+func (rg *ResourceGroup) persistExtendedSettings(storage endpoint.ResourceGroupStorage) error {
+	settings := rg.GetExtendedSettings()
+	return storage.SaveResourceGroupExtendedSettings(rg.Name, settings)
+}
@@ -1,11 +1,15 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 
 	rmpb "github.com/pingcap/kvproto/pkg/resource_manager"
 	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/storage/kv"
+	"github.com/tikv/pd/pkg/utils/typeutil"
 )
 
 func TestPatchResourceGroup(t *testing.T) {
@@ -62,3 +66,64 @@ func TestPatchResourceGroup(t *testing.T) {
 		re.Equal(ca.expectJSONString, string(res))
 	}
 }
+
+func TestResourceGroupBurstSettings(t *testing.T) {
+	re := require.New(t)
+	rg := &ResourceGroup{Name: "test", Mode: rmpb.GroupMode_RUMode}
+	re.NoError(rg.CheckAndInit())
+	re.Error(rg.SetBurstSettings(&BurstSettings{MaxCredit: -1}))
+	re.NoError(rg.SetBurstSettings(&BurstSettings{MaxCredit: 1000, EnableBorrow: true}))
+	re.Equal(&GroupExtendedSettings{Burst: &BurstSettings{MaxCredit: 1000, EnableBorrow: true}}, rg.GetExtendedSettings())
+	res, err := json.Marshal(rg.Copy())
+	re.NoError(err)
+	re.Equal(`{"name":"test","mode":1,"r_u_settings":{"ru":{"burst":{"max_credit":1000,"enable_borrow":true},"state":{"initialized":false}}}}`, string(res))
+	// The credits exceeding the new max credit are dropped.
+	rg.RUSettings.RU.Credit = 800
+	re.NoError(rg.SetBurstSettings(&BurstSettings{MaxCredit: 500}))
+	re.Equal(500., rg.RUSettings.RU.Credit)
+	re.NoError(rg.SetBurstSettings(nil))
+	re.Equal(0., rg.RUSettings.RU.Credit)
+	re.Nil(rg.GetExtendedSettings().Burst)
+
+	rawGroup := &ResourceGroup{Name: "raw", Mode: rmpb.GroupMode_RawMode}
+	re.NoError(rawGroup.CheckAndInit())
+	re.Error(rawGroup.SetBurstSettings(&BurstSettings{MaxCredit: 1000}))
+
+	// The burst settings are persisted and reloaded.
+	storage := endpoint.NewStorageEndpoint(kv.NewMemoryKV(), nil)
+	m := newTestManager(storage)
+	re.NoError(m.AddResourceGroup(&ResourceGroup{Name: "test", Mode: rmpb.GroupMode_RUMode}))
+	re.Error(m.SetResourceGroupBurstSettings("unknown", &BurstSettings{MaxCredit: 1000}))
+	re.NoError(m.SetResourceGroupBurstSettings("test", &BurstSettings{MaxCredit: 1000, EnableBorrow: true}))
+	re.Same(m.borrowPool, m.GetMutableResourceGroup("test").RUSettings.RU.borrowPool)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := newTestManager(storage)
+	reloaded.Init(ctx)
+	group := reloaded.GetMutableResourceGroup("test")
+	re.NotNil(group)
+	re.Equal(&BurstSettings{MaxCredit: 1000, EnableBorrow: true}, group.RUSettings.RU.Burst)
+	re.Same(reloaded.borrowPool, group.RUSettings.RU.borrowPool)
+	re.NoError(reloaded.DeleteResourceGroup("test"))
+	re.NoError(storage.LoadResourceGroupExtendedSettings(func(k, v string) {
+		re.Fail("extended settings should be deleted", k)
+	}))
+}
+
+func newTestManager(storage endpoint.ResourceGroupStorage) *Manager {
+	return &Manager{
+		groups:            make(map[string]*ResourceGroup),
+		templates:         make(map[string]*ResourceGroupTemplate),
+		storage:           storage,
+		borrowPool:        NewBorrowPool(defaultBorrowPoolCapacity),
+		priorityScheduler: NewPriorityScheduler(PrioritySchedulerConfig{MinShareRatio: defaultMinShareRatio}),
+		consumption:       NewConsumptionAggregator(),
+		consumptionHistory: NewConsumptionHistory(ConsumptionHistoryConfig{
+			Retention: typeutil.NewDuration(defaultConsumptionHistoryRetention),
+		}),
+		runawayRules: NewRunawayRules(),
+		ruModel:      DefaultRUModel(),
+		workload:     newWorkloadRecorder(),
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pingcap/errors"
+	rmpb "github.com/pingcap/kvproto/pkg/resource_manager"
+)
+
+const (
+	// ruModelSettingsName is watched by the clients, so the other settings
+	// should not be prefixed by it.
+	ruModelSettingsName        = "ru_model"
+	ruModelHistorySettingsName = "versioned_ru_models"
+	// maxRUModelHistory is the number of the previous RU models kept.
+	maxRUModelHistory = 16
+	// ruWorkloadWindow is how long the workload is kept to preview the RU models.
+	ruWorkloadWindow = time.Hour
+)
+
+// RUModel is the coefficients to price the requests in RU. It's calculated by
+// the clients, so the JSON keys are the same as the RU config of the clients.
+type RUModel struct {
+	// Version is increased by the server each time the model is updated, zero
+	// means the default model.
+	Version          uint64  `json:"version"`
+	ReadBaseCost     float64 `json:"read-base-cost"`
+	ReadCostPerByte  float64 `json:"read-cost-per-byte"`
+	WriteBaseCost    float64 `json:"write-base-cost"`
+	WriteCostPerByte float64 `json:"write-cost-per-byte"`
+	CPUMsCost        float64 `json:"read-cpu-ms-cost"`
+	// UpdateTime is the unix timestamp in seconds when the model is updated.
+	UpdateTime int64 `json:"update-time,omitempty"`
+}
+
+// DefaultRUModel returns the default RU model of the clients.
+func DefaultRUModel() *RUModel {
+	return &RUModel{
+		ReadBaseCost:     0.25,
+		ReadCostPerByte:  1. / (64 * 1024),
+		WriteBaseCost:    1.5,
+		WriteCostPerByte: 1. / 1024,
+		CPUMsCost:        1. / 3,
+	}
+}
+
+// Validate checks whether the RU model is valid.
+func (m *RUModel) Validate() error {
+	for _, cost := range []float64{m.ReadBaseCost, m.ReadCostPerByte, m.WriteBaseCost, m.WriteCostPerByte, m.CPUMsCost} {
+		if cost < 0 || math.IsNaN(cost) || math.IsInf(cost, 0) {
+			return errors.Errorf("invalid cost %v, it should be a finite non-negative number", cost)
+		}
+	}
+	if m.ReadBaseCost == 0 && m.ReadCostPerByte == 0 && m.CPUMsCost == 0 {
+		return errors.New("the read requests should not be free")
+	}
+	if m.WriteBaseCost == 0 && m.WriteCostPerByte == 0 {
+		return errors.New("the write requests should not be free")
+	}
+	return nil
+}
+
+// price returns the RU of the workload priced by the model, in the same way as
+// the clients.
+func (m *RUModel) price(w *Workload) (rru, wru float64) {
+	rru = w.ReadRequests*m.ReadBaseCost + w.ReadBytes*m.ReadCostPerByte + w.KVCPUMs*m.CPUMsCost
+	wru = w.WriteRequests*m.WriteBaseCost + w.WriteBytes*m.WriteCostPerByte
+	return rru, wru
+}
+
+// Workload is the resource consumption of the requests reported by the clients.
+type Workload struct {
+	ReadRequests  float64 `json:"read_requests"`
+	WriteRequests float64 `json:"write_requests"`
+	ReadBytes     float64 `json:"read_bytes"`
+	WriteBytes    float64 `json:"write_bytes"`
+	KVCPUMs       float64 `json:"kv_cpu_ms"`
+	// RRU and WRU are consumed by the requests as reported.
+	RRU float64 `json:"rru"`
+	WRU float64 `json:"wru"`
+}
+
+func (w *Workload) add(o *Workload) {
+	w.ReadRequests += o.ReadRequests
+	w.WriteRequests += o.WriteRequests
+	w.ReadBytes += o.ReadBytes
+	w.WriteBytes += o.WriteBytes
+	w.KVCPUMs += o.KVCPUMs
+	w.RRU += o.RRU
+	w.WRU += o.WRU
+}
+
+func workloadFromConsumption(c *rmpb.Consumption) *Workload {
+	return &Workload{
+		ReadRequests:  c.KvReadRpcCount,
+		WriteRequests: c.KvWriteRpcCount,
+		ReadBytes:     c.ReadBytes,
+		WriteBytes:    c.WriteBytes,
+		KVCPUMs:       c.TotalCpuTimeMs - c.SqlLayerCpuTimeMs,
+		RRU:           c.RRU,
+		WRU:           c.WRU,
+	}
+}
+
+// workloadRecorder keeps the recent workload of the resource groups by minute
+// in memory, so it's lost when the leader changes.
+type workloadRecorder struct {
+	sync.Mutex
+	// minutes are the workload by the start of the minute and the group.
+	minutes map[int64]map[string]*Workload
+}
+
+func newWorkloadRecorder() *workloadRecorder {
+	return &workloadRecorder{minutes: make(map[int64]map[string]*Workload)}
+}
+
+func (r *workloadRecorder) add(now time.Time, group string, c *rmpb.Consumption) {
+	minute := now.Truncate(time.Minute).Unix()
+	r.Lock()
+	defer r.Unlock()
+	groups, ok := r.minutes[minute]
+	if !ok {
+		groups = make(map[string]*Workload)
+		r.minutes[minute] = groups
+	}
+	w, ok := groups[group]
+	if !ok {
+		w = &Workload{}
+		groups[group] = w
+	}
+	w.add(workloadFromConsumption(c))
+}
+
+// get returns the workload within the window by group, and removes the
+// workload out of it.
+func (r *workloadRecorder) get(now time.Time) map[string]*Workload {
+	start := now.Add(-ruWorkloadWindow).Unix()
+	r.Lock()
+	defer r.Unlock()
+	res := make(map[string]*Workload)
+	for minute, groups := range r.minutes {
+		if minute < start {
+			delete(r.minutes, minute)
+			continue
+		}
+		for group, w := range groups {
+			if _, ok := res[group]; !ok {
+				res[group] = &Workload{}
+			}
+			res[group].add(w)
+		}
+	}
+	return res
+}
+
+// RUModelPreview is how a resource group's recent workload would have been
+// priced by an RU model compared with the current one.
+type RUModelPreview struct {
+	Group    string    `json:"group"`
+	Workload *Workload `json:"workload"`
+	// CurrentRRU and CurrentWRU are priced by the current model, which may be
+	// different from the reported RU, e.g. the SQL CPU is not included.
+	CurrentRRU float64 `json:"current_rru"`
+	CurrentWRU float64 `json:"current_wru"`
+	PreviewRRU float64 `json:"preview_rru"`
+	PreviewWRU float64 `json:"preview_wru"`
+	// Ratio is the total RU priced by the previewed model divided by the
+	// current one, zero if the current one is zero.
+	Ratio float64 `json:"ratio"`
+}
+
+// GetRUModel returns the current RU model.
+func (m *Manager) GetRUModel() *RUModel {
+	m.RLock()
+	defer m.RUnlock()
+	model := *m.ruModel
+	return &model
+}
+
+// GetRUModelHistory returns the previous RU models from the newest.
+func (m *Manager) GetRUModelHistory() ([]*RUModel, error) {
+	history := make([]*RUModel, 0)
+	if _, err := m.storage.LoadResourceManagerSettings(ruModelHistorySettingsName, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// SetRUModel validates and persists the RU model as a new version, which is
+// pushed to the clients. The previous models are kept to roll back.
+func (m *Manager) SetRUModel(model *RUModel) error {
+	if err := model.Validate(); err != nil {
+		return err
+	}
+	history, err := m.GetRUModelHistory()
+	if err != nil {
+		return err
+	}
+	m.Lock()
+	defer m.Unlock()
+	current := m.ruModel
+	history = append([]*RUModel{current}, history...)
+	if len(history) > maxRUModelHistory {
+		history = history[:maxRUModelHistory]
+	}
+	updated := *model
+	updated.Version = current.Version + 1
+	updated.UpdateTime = time.Now().Unix()
+	if err := m.storage.SaveResourceManagerSettings(ruModelHistorySettingsName, history); err != nil {
+		return err
+	}
+	if err := m.storage.SaveResourceManagerSettings(ruModelSettingsName, &updated); err != nil {
+		return err
+	}
+	m.ruModel = &updated
+	return nil
+}
+
+// PreviewRUModel returns how the recent workload would have been priced by the
+// RU model, sorted by the group names.
+func (m *Manager) PreviewRUModel(model *RUModel) ([]*RUModelPreview, error) {
+	if err := model.Validate(); err != nil {
+		return nil, err
+	}
+	current := m.GetRUModel()
+	res := make([]*RUModelPreview, 0)
+	for group, w := range m.workload.get(time.Now()) {
+		preview := &RUModelPreview{Group: group, Workload: w}
+		preview.CurrentRRU, preview.CurrentWRU = current.price(w)
+		preview.PreviewRRU, preview.PreviewWRU = model.price(w)
+		if total := preview.CurrentRRU + preview.CurrentWRU; total > 0 {
+			preview.Ratio = (preview.PreviewRRU + preview.PreviewWRU) / total
+		}
+		res = append(res, preview)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Group < res[j].Group
+	})
+	return res, nil
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	rmpb "github.com/pingcap/kvproto/pkg/resource_manager"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/storage/kv"
+)
+
+func TestRUModel(t *testing.T) {
+	re := require.New(t)
+	re.NoError(DefaultRUModel().Validate())
+	for _, model := range []*RUModel{
+		{ReadBaseCost: -1, WriteBaseCost: 1},
+		{ReadBaseCost: 1},
+		{WriteBaseCost: 1},
+	} {
+		re.Error(model.Validate())
+	}
+
+	storage := endpoint.NewStorageEndpoint(kv.NewMemoryKV(), nil)
+	m := newTestManager(storage)
+	re.Equal(uint64(0), m.GetRUModel().Version)
+	model := DefaultRUModel()
+	model.WriteBaseCost = 3
+	re.NoError(m.SetRUModel(model))
+	re.NoError(m.SetRUModel(model))
+	re.Equal(uint64(2), m.GetRUModel().Version)
+	re.Equal(3., m.GetRUModel().WriteBaseCost)
+	history, err := m.GetRUModelHistory()
+	re.NoError(err)
+	re.Len(history, 2)
+	re.Equal(uint64(1), history[0].Version)
+	re.Equal(uint64(0), history[1].Version)
+
+	// The model is reloaded.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := newTestManager(storage)
+	reloaded.Init(ctx)
+	re.Equal(m.GetRUModel(), reloaded.GetRUModel())
+}
+
+func TestPreviewRUModel(t *testing.T) {
+	re := require.New(t)
+	m := newTestManager(endpoint.NewStorageEndpoint(kv.NewMemoryKV(), nil))
+	now := time.Now()
+	consumption := &rmpb.Consumption{
+		RRU:             10,
+		WRU:             20,
+		KvReadRpcCount:  4,
+		KvWriteRpcCount: 2,
+		ReadBytes:       64 * 1024,
+		WriteBytes:      1024,
+		TotalCpuTimeMs:  6,
+	}
+	m.workload.add(now, "rg1", consumption)
+	m.workload.add(now.Add(-time.Minute), "rg1", consumption)
+	// The workload out of the window is not previewed.
+	m.workload.add(now.Add(-2*ruWorkloadWindow), "rg2", consumption)
+
+	model := DefaultRUModel()
+	model.WriteBaseCost, model.WriteCostPerByte = 3, 2./1024
+	previews, err := m.PreviewRUModel(model)
+	re.NoError(err)
+	re.Len(previews, 1)
+	preview := previews[0]
+	re.Equal("rg1", preview.Group)
+	re.Equal(8., preview.Workload.ReadRequests)
+	re.Equal(40., preview.Workload.WRU)
+	// 8*0.25 + 2 + 12/3
+	re.InDelta(8., preview.CurrentRRU, 1e-9)
+	re.InDelta(8., preview.PreviewRRU, 1e-9)
+	// 4*1.5 + 2
+	re.InDelta(8., preview.CurrentWRU, 1e-9)
+	// 4*3 + 4
+	re.InDelta(16., preview.PreviewWRU, 1e-9)
+	re.InDelta(1.5, preview.Ratio, 1e-9)
+	re.Len(m.workload.minutes, 2)
+
+	_, err = m.PreviewRUModel(&RUModel{})
+	re.Error(err)
+}
@@ -0,0 +1,323 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"go.uber.org/zap"
+)
+
+// The actions taken on the runaway queries.
+const (
+	RunawayActionDryRun   = "dryrun"
+	RunawayActionCoolDown = "cooldown"
+	RunawayActionKill     = "kill"
+)
+
+// The types to match the watched queries.
+const (
+	RunawayWatchExact   = "exact"
+	RunawayWatchSimilar = "similar"
+	RunawayWatchPlan    = "plan"
+)
+
+func validateRunawayAction(action string) error {
+	switch action {
+	case RunawayActionDryRun, RunawayActionCoolDown, RunawayActionKill:
+		return nil
+	}
+	return errors.Errorf("invalid runaway action %s, it should be one of %s, %s and %s",
+		action, RunawayActionDryRun, RunawayActionCoolDown, RunawayActionKill)
+}
+
+func validateRunawayWatchType(typ string) error {
+	switch typ {
+	case RunawayWatchExact, RunawayWatchSimilar, RunawayWatchPlan:
+		return nil
+	}
+	return errors.Errorf("invalid runaway watch type %s, it should be one of %s, %s and %s",
+		typ, RunawayWatchExact, RunawayWatchSimilar, RunawayWatchPlan)
+}
+
+// RunawayWatchSettings is how the queries identified as runaway are watched.
+type RunawayWatchSettings struct {
+	Type string `json:"type"`
+	// LastingDurationMs is how long the queries are watched, 0 means forever.
+	LastingDurationMs int64 `json:"lasting_duration_ms"`
+}
+
+// RunawaySettings is the rule to identify the runaway queries of a resource
+// group and the action taken on them. The clients enforce the rule.
+type RunawaySettings struct {
+	// ExecElapsedTimeMs is the execution time for a query to be identified as runaway.
+	ExecElapsedTimeMs uint64                `json:"exec_elapsed_time_ms"`
+	Action            string                `json:"action"`
+	Watch             *RunawayWatchSettings `json:"watch,omitempty"`
+}
+
+// Validate checks whether the runaway settings are valid.
+func (s *RunawaySettings) Validate() error {
+	if s.ExecElapsedTimeMs == 0 {
+		return errors.New("exec elapsed time should be positive")
+	}
+	if err := validateRunawayAction(s.Action); err != nil {
+		return err
+	}
+	if s.Watch != nil {
+		if err := validateRunawayWatchType(s.Watch.Type); err != nil {
+			return err
+		}
+		if s.Watch.LastingDurationMs < 0 {
+			return errors.New("watch lasting duration should not be negative")
+		}
+	}
+	return nil
+}
+
+// RunawayWatchItem watches the queries matching the key of a resource group,
+// and takes the action on them until it expires.
+type RunawayWatchItem struct {
+	// ID is allocated by the server when the item is added.
+	ID            uint64 `json:"id"`
+	ResourceGroup string `json:"resource_group"`
+	// Key is the SQL text, SQL digest or plan digest by the watch type.
+	Key    string `json:"key"`
+	Type   string `json:"type"`
+	Action string `json:"action"`
+	// Source is where the item comes from, e.g. the address of the client
+	// which identifies the runaway query, or manual.
+	Source string `json:"source,omitempty"`
+	// StartTime and EndTime are unix timestamps in milliseconds, zero EndTime
+	// means the item never expires.
+	StartTime int64 `json:"start_time"`
+	EndTime   int64 `json:"end_time,omitempty"`
+}
+
+// Validate checks whether the watch item is valid.
+func (item *RunawayWatchItem) Validate() error {
+	if len(item.ResourceGroup) == 0 {
+		return errors.New("resource group should not be empty")
+	}
+	if len(item.Key) == 0 {
+		return errors.New("watch key should not be empty")
+	}
+	if err := validateRunawayWatchType(item.Type); err != nil {
+		return err
+	}
+	if err := validateRunawayAction(item.Action); err != nil {
+		return err
+	}
+	if item.EndTime != 0 && item.EndTime <= item.StartTime {
+		return errors.New("end time should be after the start time")
+	}
+	return nil
+}
+
+func (item *RunawayWatchItem) expired(now time.Time) bool {
+	return item.EndTime != 0 && item.EndTime <= now.UnixMilli()
+}
+
+// RunawayRules manages the runaway settings and the watch items of the resource
+// groups. They are persisted under the same prefix, which the clients watch to
+// get the changes pushed, so the rules are consistent in the cluster.
+type RunawayRules struct {
+	sync.RWMutex
+	settings map[string]*RunawaySettings
+	watches  map[uint64]*RunawayWatchItem
+	maxID    uint64
+}
+
+// NewRunawayRules returns a new RunawayRules.
+func NewRunawayRules() *RunawayRules {
+	return &RunawayRules{
+		settings: make(map[string]*RunawaySettings),
+		watches:  make(map[uint64]*RunawayWatchItem),
+	}
+}
+
+// load reloads the rules from the storage.
+func (r *RunawayRules) load(storage endpoint.ResourceGroupStorage) error {
+	settings := make(map[string]*RunawaySettings)
+	watches := make(map[uint64]*RunawayWatchItem)
+	var (
+		maxID  uint64
+		errOut error
+	)
+	if err := storage.LoadRunawaySettings(func(k, v string) {
+		s := &RunawaySettings{}
+		if err := json.Unmarshal([]byte(v), s); err != nil {
+			errOut = errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+			return
+		}
+		settings[k] = s
+	}); err != nil {
+		return err
+	}
+	if err := storage.LoadRunawayWatches(func(k, v string) {
+		item := &RunawayWatchItem{}
+		if err := json.Unmarshal([]byte(v), item); err != nil {
+			errOut = errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+			return
+		}
+		watches[item.ID] = item
+		if item.ID > maxID {
+			maxID = item.ID
+		}
+	}); err != nil {
+		return err
+	}
+	if errOut != nil {
+		return errOut
+	}
+	r.Lock()
+	defer r.Unlock()
+	r.settings, r.watches, r.maxID = settings, watches, maxID
+	return nil
+}
+
+// GetSettings returns the runaway settings of the resource group, nil if not set.
+func (r *RunawayRules) GetSettings(group string) *RunawaySettings {
+	r.RLock()
+	defer r.RUnlock()
+	return r.settings[group]
+}
+
+func (r *RunawayRules) setSettings(storage endpoint.ResourceGroupStorage, group string, settings *RunawaySettings) error {
+	r.Lock()
+	defer r.Unlock()
+	if settings == nil {
+		if err := storage.DeleteRunawaySettings(group); err != nil {
+			return err
+		}
+		delete(r.settings, group)
+		log.Info("runaway settings removed", zap.String("resource-group", group))
+		return nil
+	}
+	if err := storage.SaveRunawaySettings(group, settings); err != nil {
+		return err
+	}
+	r.settings[group] = settings
+	log.Info("runaway settings updated", zap.String("resource-group", group), zap.Any("settings", settings))
+	return nil
+}
+
+// addWatch allocates the ID of the watch item and persists it.
+func (r *RunawayRules) addWatch(storage endpoint.ResourceGroupStorage, item *RunawayWatchItem) error {
+	r.Lock()
+	defer r.Unlock()
+	item.ID = r.maxID + 1
+	if err := storage.SaveRunawayWatch(item.ID, item); err != nil {
+		return err
+	}
+	r.maxID = item.ID
+	r.watches[item.ID] = item
+	log.Info("runaway watch added", zap.Any("item", item))
+	return nil
+}
+
+func (r *RunawayRules) deleteWatch(storage endpoint.ResourceGroupStorage, id uint64) error {
+	r.Lock()
+	defer r.Unlock()
+	item, ok := r.watches[id]
+	if !ok {
+		return errors.Errorf("runaway watch %d does not exist", id)
+	}
+	if err := storage.DeleteRunawayWatch(id); err != nil {
+		return err
+	}
+	delete(r.watches, id)
+	log.Info("runaway watch removed", zap.Any("item", item))
+	return nil
+}
+
+// GetWatches returns the unexpired watch items of the resource group sorted by
+// ID. Empty group matches any.
+func (r *RunawayRules) GetWatches(now time.Time, group string) []*RunawayWatchItem {
+	r.RLock()
+	defer r.RUnlock()
+	res := make([]*RunawayWatchItem, 0)
+	for _, item := range r.watches {
+		if (len(group) == 0 || item.ResourceGroup == group) && !item.expired(now) {
+			res = append(res, item)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].ID < res[j].ID
+	})
+	return res
+}
+
+// rename moves the rules of the resource group to the new name.
+func (r *RunawayRules) rename(storage endpoint.ResourceGroupStorage, oldName, newName string) error {
+	r.Lock()
+	defer r.Unlock()
+	if settings, ok := r.settings[oldName]; ok {
+		if err := storage.SaveRunawaySettings(newName, settings); err != nil {
+			return err
+		}
+		if err := storage.DeleteRunawaySettings(oldName); err != nil {
+			return err
+		}
+		r.settings[newName] = settings
+		delete(r.settings, oldName)
+	}
+	for _, item := range r.watches {
+		if item.ResourceGroup != oldName {
+			continue
+		}
+		renamed := *item
+		renamed.ResourceGroup = newName
+		if err := storage.SaveRunawayWatch(item.ID, &renamed); err != nil {
+			return err
+		}
+		r.watches[item.ID] = &renamed
+	}
+	return nil
+}
+
+// gc removes the expired watch items, and all the rules of the removed groups
+// if any.
+func (r *RunawayRules) gc(now time.Time, storage endpoint.ResourceGroupStorage, removedGroups ...string) error {
+	r.Lock()
+	defer r.Unlock()
+	removed := make(map[string]struct{}, len(removedGroups))
+	for _, group := range removedGroups {
+		removed[group] = struct{}{}
+		if _, ok := r.settings[group]; ok {
+			if err := storage.DeleteRunawaySettings(group); err != nil {
+				return err
+			}
+			delete(r.settings, group)
+		}
+	}
+	for id, item := range r.watches {
+		if _, ok := removed[item.ResourceGroup]; !ok && !item.expired(now) {
+			continue
+		}
+		if err := storage.DeleteRunawayWatch(id); err != nil {
+			return err
+		}
+		delete(r.watches, id)
+	}
+	return nil
+}
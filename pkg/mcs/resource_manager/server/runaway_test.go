@@ -0,0 +1,79 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/storage/kv"
+)
+
+func TestValidateRunawayRules(t *testing.T) {
+	re := require.New(t)
+	for _, settings := range []*RunawaySettings{
+		{Action: RunawayActionKill},
+		{ExecElapsedTimeMs: 1000, Action: "stop"},
+		{ExecElapsedTimeMs: 1000, Action: RunawayActionKill, Watch: &RunawayWatchSettings{Type: "fuzzy"}},
+		{ExecElapsedTimeMs: 1000, Action: RunawayActionKill, Watch: &RunawayWatchSettings{Type: RunawayWatchExact, LastingDurationMs: -1}},
+	} {
+		re.Error(settings.Validate())
+	}
+	re.NoError((&RunawaySettings{ExecElapsedTimeMs: 1000, Action: RunawayActionCoolDown,
+		Watch: &RunawayWatchSettings{Type: RunawayWatchSimilar}}).Validate())
+
+	for _, item := range []*RunawayWatchItem{
+		{Key: "select 1", Type: RunawayWatchExact, Action: RunawayActionKill},
+		{ResourceGroup: "rg1", Type: RunawayWatchExact, Action: RunawayActionKill},
+		{ResourceGroup: "rg1", Key: "select 1", Type: RunawayWatchExact, Action: "stop"},
+		{ResourceGroup: "rg1", Key: "select 1", Type: RunawayWatchExact, Action: RunawayActionKill, StartTime: 2, EndTime: 1},
+	} {
+		re.Error(item.Validate())
+	}
+}
+
+func TestRunawayRules(t *testing.T) {
+	re := require.New(t)
+	storage := endpoint.NewStorageEndpoint(kv.NewMemoryKV(), nil)
+	r := NewRunawayRules()
+	settings := &RunawaySettings{ExecElapsedTimeMs: 1000, Action: RunawayActionKill}
+	re.NoError(r.setSettings(storage, "rg1", settings))
+	now := time.Unix(100, 0)
+	item1 := &RunawayWatchItem{ResourceGroup: "rg1", Key: "d1", Type: RunawayWatchSimilar, Action: RunawayActionKill,
+		StartTime: now.UnixMilli(), EndTime: now.Add(time.Minute).UnixMilli()}
+	item2 := &RunawayWatchItem{ResourceGroup: "rg2", Key: "d2", Type: RunawayWatchPlan, Action: RunawayActionCoolDown,
+		StartTime: now.UnixMilli()}
+	re.NoError(r.addWatch(storage, item1))
+	re.NoError(r.addWatch(storage, item2))
+	re.Equal(uint64(1), item1.ID)
+	re.Equal(uint64(2), item2.ID)
+	re.Equal([]*RunawayWatchItem{item1, item2}, r.GetWatches(now, ""))
+	re.Equal([]*RunawayWatchItem{item2}, r.GetWatches(now, "rg2"))
+	re.Equal([]*RunawayWatchItem{item2}, r.GetWatches(now.Add(time.Minute), ""))
+
+	// The rules are reloaded from the storage, and the IDs keep increasing.
+	r = NewRunawayRules()
+	re.NoError(r.load(storage))
+	re.Equal(settings, r.GetSettings("rg1"))
+	re.Equal([]*RunawayWatchItem{item1, item2}, r.GetWatches(now, ""))
+	item3 := &RunawayWatchItem{ResourceGroup: "rg1", Key: "d3", Type: RunawayWatchExact, Action: RunawayActionDryRun}
+	re.NoError(r.addWatch(storage, item3))
+	re.Equal(uint64(3), item3.ID)
+	re.NoError(r.deleteWatch(storage, item3.ID))
+	re.Error(r.deleteWatch(storage, item3.ID))
+
+	// The expired items and the rules of the removed groups are removed.
+	re.NoError(r.gc(now.Add(time.Minute), storage, "rg2"))
+	re.Empty(r.GetWatches(now, ""))
+	re.NotNil(r.GetSettings("rg1"))
+	re.NoError(r.gc(now, storage, "rg1"))
+	re.Nil(r.GetSettings("rg1"))
+	r = NewRunawayRules()
+	re.NoError(r.load(storage))
+	re.Nil(r.GetSettings("rg1"))
+	re.Empty(r.GetWatches(now, ""))
+
+	re.NoError(r.setSettings(storage, "rg1", settings))
+	re.NoError(r.setSettings(storage, "rg1", nil))
+	re.Nil(r.GetSettings("rg1"))
+}
@@ -0,0 +1,138 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,g
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"github.com/gogo/protobuf/proto"
+	"github.com/pingcap/errors"
+	rmpb "github.com/pingcap/kvproto/pkg/resource_manager"
+)
+
+// templatesSettingsName is the name to persist the resource group templates.
+const templatesSettingsName = "templates"
+
+// TemplateSettings is the settings a resource group inherits from its template.
+// The nil or zero fields are not inherited.
+type TemplateSettings struct {
+	FillRate   *uint64        `json:"fill_rate,omitempty"`
+	BurstLimit *int64         `json:"burst_limit,omitempty"`
+	Burst      *BurstSettings `json:"burst,omitempty"`
+	Priority   uint32         `json:"priority,omitempty"`
+}
+
+// Validate checks whether the template settings are valid.
+func (s *TemplateSettings) Validate() error {
+	if s.Burst != nil {
+		if err := s.Burst.Validate(); err != nil {
+			return err
+		}
+	}
+	if s.Priority != 0 {
+		return validatePriority(s.Priority)
+	}
+	return nil
+}
+
+// merge returns the settings overridden by the overrides.
+func (s *TemplateSettings) merge(overrides *TemplateSettings) *TemplateSettings {
+	merged := *s
+	if overrides == nil {
+		return &merged
+	}
+	if overrides.FillRate != nil {
+		merged.FillRate = overrides.FillRate
+	}
+	if overrides.BurstLimit != nil {
+		merged.BurstLimit = overrides.BurstLimit
+	}
+	if overrides.Burst != nil {
+		merged.Burst = overrides.Burst
+	}
+	if overrides.Priority != 0 {
+		merged.Priority = overrides.Priority
+	}
+	return &merged
+}
+
+// ResourceGroupTemplate is the template of the resource groups, its changes are
+// applied to all the groups inheriting it.
+type ResourceGroupTemplate struct {
+	Name     string           `json:"name"`
+	Settings TemplateSettings `json:"settings"`
+}
+
+// Validate checks whether the template is valid.
+func (t *ResourceGroupTemplate) Validate() error {
+	if len(t.Name) == 0 {
+		return errors.New("template name should not be empty")
+	}
+	return t.Settings.Validate()
+}
+
+// applyTemplate applies the settings inherited from the template with the
+// overrides of the resource group.
+func (rg *ResourceGroup) applyTemplate(template *ResourceGroupTemplate, overrides *TemplateSettings) error {
+	rg.Lock()
+	defer rg.Unlock()
+	if rg.Mode != rmpb.GroupMode_RUMode || rg.RUSettings == nil {
+		return errors.New("only support template in RU mode")
+	}
+	rg.Template = template.Name
+	rg.TemplateOverrides = overrides
+	settings := template.Settings.merge(overrides)
+	bucket := &rg.RUSettings.RU
+	if settings.FillRate != nil || settings.BurstLimit != nil {
+		limit := &rmpb.TokenLimitSettings{}
+		if bucket.Settings != nil {
+			limit = proto.Clone(bucket.Settings).(*rmpb.TokenLimitSettings)
+		}
+		if settings.FillRate != nil {
+			limit.FillRate = *settings.FillRate
+		}
+		if settings.BurstLimit != nil {
+			limit.BurstLimit = *settings.BurstLimit
+		}
+		if !proto.Equal(limit, bucket.Settings) {
+			bucket.patch(&rmpb.TokenBucket{Settings: limit})
+		}
+	}
+	if settings.Burst != nil {
+		burst := *settings.Burst
+		bucket.Burst = &burst
+		if bucket.Credit > burst.MaxCredit {
+			bucket.Credit = burst.MaxCredit
+		}
+	}
+	if settings.Priority != 0 {
+		rg.Priority = settings.Priority
+	}
+	return nil
+}
+
+// getTemplate returns the template name and the overrides of the resource group.
+func (rg *ResourceGroup) getTemplate() (string, *TemplateSettings) {
+	rg.RLock()
+	defer rg.RUnlock()
+	return rg.Template, rg.TemplateOverrides
+}
+
+// detachTemplate stops the resource group inheriting from its template, the
+// inherited settings are kept.
+func (rg *ResourceGroup) detachTemplate() {
+	rg.Lock()
+	defer rg.Unlock()
+	rg.Template = ""
+	rg.TemplateOverrides = nil
+}
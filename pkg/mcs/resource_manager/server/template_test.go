@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	rmpb "github.com/pingcap/kvproto/pkg/resource_manager"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/storage/kv"
+)
+
+func TestMergeTemplateSettings(t *testing.T) {
+	re := require.New(t)
+	fillRate, overriddenFillRate, burstLimit := uint64(1000), uint64(2000), int64(5000)
+	settings := &TemplateSettings{FillRate: &fillRate, BurstLimit: &burstLimit, Priority: DefaultPriority}
+	re.Equal(settings, settings.merge(nil))
+	merged := settings.merge(&TemplateSettings{FillRate: &overriddenFillRate, Burst: &BurstSettings{MaxCredit: 100}})
+	re.Equal(overriddenFillRate, *merged.FillRate)
+	re.Equal(burstLimit, *merged.BurstLimit)
+	re.Equal(100., merged.Burst.MaxCredit)
+	re.Equal(DefaultPriority, merged.Priority)
+	// The template is not modified.
+	re.Equal(fillRate, *settings.FillRate)
+	re.Nil(settings.Burst)
+
+	re.Error((&ResourceGroupTemplate{}).Validate())
+	re.Error((&ResourceGroupTemplate{Name: "t", Settings: TemplateSettings{Priority: MaxPriority + 1}}).Validate())
+	re.Error((&ResourceGroupTemplate{Name: "t", Settings: TemplateSettings{Burst: &BurstSettings{MaxCredit: -1}}}).Validate())
+	re.NoError((&ResourceGroupTemplate{Name: "t", Settings: *settings}).Validate())
+}
+
+func TestResourceGroupTemplate(t *testing.T) {
+	re := require.New(t)
+	storage := endpoint.NewStorageEndpoint(kv.NewMemoryKV(), nil)
+	m := newTestManager(storage)
+	for _, name := range []string{"rg1", "rg2", "rg3"} {
+		re.NoError(m.AddResourceGroup(&ResourceGroup{
+			Name:       name,
+			Mode:       rmpb.GroupMode_RUMode,
+			RUSettings: NewRequestUnitSettings(&rmpb.TokenBucket{Settings: &rmpb.TokenLimitSettings{FillRate: 100, MaxTokens: 1000}}),
+		}))
+	}
+	fillRate, burstLimit, overriddenFillRate := uint64(1000), int64(5000), uint64(3000)
+	template := &ResourceGroupTemplate{
+		Name:     "default",
+		Settings: TemplateSettings{FillRate: &fillRate, BurstLimit: &burstLimit, Priority: MaxPriority},
+	}
+	re.Error(m.SetResourceGroupTemplate("rg1", "default", nil))
+	re.NoError(m.PutResourceGroupTemplate(template))
+	re.Error(m.SetResourceGroupTemplate("unknown", "default", nil))
+	re.NoError(m.SetResourceGroupTemplate("rg1", "default", nil))
+	re.NoError(m.SetResourceGroupTemplate("rg2", "default", &TemplateSettings{FillRate: &overriddenFillRate}))
+
+	check := func(m *Manager, name string, fillRate uint64, burstLimit int64, priority uint32) {
+		group := m.GetResourceGroup(name)
+		re.Equal(fillRate, group.RUSettings.RU.Settings.FillRate)
+		re.Equal(burstLimit, group.RUSettings.RU.Settings.BurstLimit)
+		// The settings not inherited are kept.
+		re.Equal(1000., group.RUSettings.RU.Settings.MaxTokens)
+		re.Equal(priority, group.GetPriority())
+	}
+	check(m, "rg1", 1000, 5000, MaxPriority)
+	check(m, "rg2", 3000, 5000, MaxPriority)
+	check(m, "rg3", 100, 0, DefaultPriority)
+
+	// The changes of the template are applied to the inheriting groups.
+	fillRate, burstLimit = 2000, 10000
+	template = &ResourceGroupTemplate{
+		Name:     "default",
+		Settings: TemplateSettings{FillRate: &fillRate, BurstLimit: &burstLimit, Burst: &BurstSettings{MaxCredit: 100}},
+	}
+	re.NoError(m.PutResourceGroupTemplate(template))
+	check(m, "rg1", 2000, 10000, MaxPriority)
+	check(m, "rg2", 3000, 10000, MaxPriority)
+	check(m, "rg3", 100, 0, DefaultPriority)
+	re.Equal(100., m.GetResourceGroup("rg1").RUSettings.RU.Burst.MaxCredit)
+	re.Len(m.GetResourceGroupTemplates(), 1)
+	re.Error(m.DeleteResourceGroupTemplate("default"))
+
+	// The templates and inheritance are persisted and reloaded.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := newTestManager(storage)
+	reloaded.Init(ctx)
+	re.Equal(m.GetResourceGroupTemplates(), reloaded.GetResourceGroupTemplates())
+	check(reloaded, "rg1", 2000, 10000, MaxPriority)
+	check(reloaded, "rg2", 3000, 10000, MaxPriority)
+	re.Equal("default", reloaded.GetResourceGroup("rg2").Template)
+	re.Equal(overriddenFillRate, *reloaded.GetResourceGroup("rg2").TemplateOverrides.FillRate)
+
+	// The inherited settings are kept after stopping inheriting.
+	re.NoError(reloaded.SetResourceGroupTemplate("rg1", "", nil))
+	re.NoError(reloaded.SetResourceGroupTemplate("rg2", "", nil))
+	re.Empty(reloaded.GetResourceGroup("rg1").Template)
+	check(reloaded, "rg1", 2000, 10000, MaxPriority)
+	re.NoError(reloaded.DeleteResourceGroupTemplate("default"))
+	re.Error(reloaded.DeleteResourceGroupTemplate("default"))
+	re.Empty(reloaded.GetResourceGroupTemplates())
+}
@@ -90,3 +90,70 @@ func TestGroupTokenBucketRequest(t *testing.T) {
 	re.LessOrEqual(math.Abs(tb.Tokens-19000), 1e-7)
 	re.Equal(trickle, int64(time.Second)*10/int64(time.Millisecond))
 }
+
+func TestGroupTokenBucketBurstCredit(t *testing.T) {
+	re := require.New(t)
+	gtb := NewGroupTokenBucket(&rmpb.TokenBucket{
+		Settings: &rmpb.TokenLimitSettings{
+			FillRate:   1000,
+			BurstLimit: 2000,
+		},
+	})
+	gtb.Burst = &BurstSettings{MaxCredit: 5000}
+	pool := NewBorrowPool(3000)
+	gtb.borrowPool = pool
+	targetPeriodMs := uint64(time.Second / time.Millisecond)
+
+	// The initial tokens beyond the burst limit are saved as credits, and the rest are donated.
+	time1 := time.Now()
+	gtb.request(time1, 0, targetPeriodMs)
+	re.Equal(2000., gtb.Tokens)
+	re.Equal(5000., gtb.Credit)
+	re.Equal(3000., pool.GetTokens())
+	// The credits make up the shortage without loaning.
+	tb, trickle := gtb.request(time1, 4000, targetPeriodMs)
+	re.Equal(4000., tb.Tokens)
+	re.Equal(int64(0), trickle)
+	re.Equal(0., gtb.Tokens)
+	re.Equal(3000., gtb.Credit)
+
+	// The credits are accumulated while idle, up to the max credit.
+	time2 := time1.Add(10 * time.Second)
+	gtb.request(time2, 0, targetPeriodMs)
+	re.Equal(2000., gtb.Tokens)
+	re.Equal(5000., gtb.Credit)
+	// Loan after the credits are exhausted if borrowing is disabled.
+	tb, _ = gtb.request(time2, 9000, targetPeriodMs)
+	re.Equal(9000., tb.Tokens)
+	re.Equal(0., gtb.Credit)
+	re.Equal(-2000., gtb.Tokens)
+	re.Equal(3000., pool.GetTokens())
+
+	// Borrow from the pool to pay the loan and the need.
+	gtb.Burst.EnableBorrow = true
+	tb, trickle = gtb.request(time2, 1000, targetPeriodMs)
+	re.Equal(1000., tb.Tokens)
+	re.Equal(int64(0), trickle)
+	re.Equal(0., gtb.Tokens)
+	re.Equal(0., pool.GetTokens())
+
+	// No credits without the burst settings.
+	gtb.Burst = nil
+	time3 := time2.Add(10 * time.Second)
+	gtb.request(time3, 0, targetPeriodMs)
+	re.Equal(2000., gtb.Tokens)
+	re.Equal(0., gtb.Credit)
+	re.Equal(3000., pool.GetTokens())
+}
+
+func TestBorrowPool(t *testing.T) {
+	re := require.New(t)
+	pool := NewBorrowPool(1000)
+	re.Equal(0., pool.borrow(100))
+	pool.donate(600)
+	pool.donate(600)
+	re.Equal(1000., pool.GetTokens())
+	re.Equal(300., pool.borrow(300))
+	re.Equal(700., pool.borrow(1000))
+	re.Equal(0., pool.GetTokens())
+}
@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"github.com/gogo/protobuf/proto"
+	"github.com/pingcap/errors"
 	rmpb "github.com/pingcap/kvproto/pkg/resource_manager"
 )
 
@@ -41,8 +42,33 @@ type GroupTokenBucket struct {
 	//   - If b < 0, that means the limiter is unlimited capacity and fillrate(r) is ignored, can be seen as r == Inf (burst within a unlimited capacity).
 	//   - If b > 0, that means the limiter is limited capacity.
 	// MaxTokens limits the number of tokens that can be accumulated
-	Settings              *rmpb.TokenLimitSettings `json:"settings,omitempty"`
+	Settings *rmpb.TokenLimitSettings `json:"settings,omitempty"`
+	// Burst is the burst settings which are not in the protocol, they are persisted
+	// as the extended settings of the resource group.
+	Burst                 *BurstSettings `json:"burst,omitempty"`
 	GroupTokenBucketState `json:"state,omitempty"`
+	// borrowPool is the shared pool to borrow tokens from if Burst enables borrowing.
+	borrowPool *BorrowPool
+}
+
+// BurstSettings is the settings of the burst credits and borrowing of a token bucket.
+type BurstSettings struct {
+	// MaxCredit limits the burst credits, which are the tokens filled beyond the
+	// burst limit while the group is idle. Zero means no credit is accumulated.
+	// The credits only take effect when the burst limit is positive, otherwise
+	// the tokens are never limited by the burst limit.
+	MaxCredit float64 `json:"max_credit,omitempty"`
+	// EnableBorrow enables borrowing tokens from the shared pool before loaning
+	// when the tokens and credits are exhausted.
+	EnableBorrow bool `json:"enable_borrow,omitempty"`
+}
+
+// Validate checks whether the burst settings are valid.
+func (s *BurstSettings) Validate() error {
+	if s.MaxCredit < 0 || math.IsNaN(s.MaxCredit) || math.IsInf(s.MaxCredit, 0) {
+		return errors.Errorf("invalid max credit %v, it should be a finite non-negative number", s.MaxCredit)
+	}
+	return nil
 }
 
 // GroupTokenBucketState is the running state of TokenBucket.
@@ -50,6 +76,8 @@ type GroupTokenBucketState struct {
 	Tokens      float64    `json:"tokens,omitempty"`
 	LastUpdate  *time.Time `json:"last_update,omitempty"`
 	Initialized bool       `json:"initialized"`
+	// Credit is the burst credits accumulated while the group is idle.
+	Credit float64 `json:"credit,omitempty"`
 	// settingChanged is used to avoid that the number of tokens returned is jitter because of changing fill rate.
 	settingChanged bool
 }
@@ -60,6 +88,7 @@ func (s *GroupTokenBucketState) Clone() *GroupTokenBucketState {
 		Tokens:      s.Tokens,
 		LastUpdate:  s.LastUpdate,
 		Initialized: s.Initialized,
+		Credit:      s.Credit,
 	}
 }
 
@@ -131,6 +160,9 @@ func (t *GroupTokenBucket) request(now time.Time, neededTokens float64, targetPe
 	t.settingChanged = false
 	if t.Settings.BurstLimit != 0 {
 		if burst := float64(t.Settings.BurstLimit); t.Tokens > burst {
+			if burst > 0 {
+				t.saveOverflow(t.Tokens - burst)
+			}
 			t.Tokens = burst
 		}
 	}
@@ -146,6 +178,8 @@ func (t *GroupTokenBucket) request(now time.Time, neededTokens float64, targetPe
 	if neededTokens <= 0 {
 		return &res, 0
 	}
+	// Make up the shortage with the burst credits and the borrowed tokens before loaning.
+	t.makeUpShortage(now, neededTokens)
 	// If the current tokens can directly meet the requirement, returns the need token
 	if t.Tokens >= neededTokens {
 		t.Tokens -= neededTokens
@@ -235,3 +269,35 @@ func (t *GroupTokenBucket) request(now time.Time, neededTokens float64, targetPe
 	}
 	return &res, trickleDuration.Milliseconds()
 }
+
+// saveOverflow saves the tokens overflowing the burst limit as burst credits,
+// and donates the rest to the shared pool.
+func (t *GroupTokenBucket) saveOverflow(overflow float64) {
+	if t.Burst != nil && t.Burst.MaxCredit > t.Credit {
+		saved := math.Min(overflow, t.Burst.MaxCredit-t.Credit)
+		t.Credit += saved
+		overflow -= saved
+	}
+	if t.borrowPool != nil && overflow > 0 {
+		t.borrowPool.donate(overflow)
+	}
+}
+
+// makeUpShortage makes up the shortage of tokens for the need with the burst
+// credits first, and then the tokens borrowed from the shared pool.
+func (t *GroupTokenBucket) makeUpShortage(now time.Time, neededTokens float64) {
+	if t.Burst == nil || t.Tokens >= neededTokens {
+		return
+	}
+	// The shortage includes the loan if the tokens are negative.
+	shortage := neededTokens - t.Tokens
+	if t.Credit > 0 {
+		used := math.Min(t.Credit, shortage)
+		t.Credit -= used
+		t.Tokens += used
+		shortage -= used
+	}
+	if shortage > 0 && t.Burst.EnableBorrow && t.borrowPool != nil {
+		t.Tokens += t.borrowPool.borrow(shortage)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// The trends of the RU demand of a resource group.
+const (
+	TokenTrendRising  = "rising"
+	TokenTrendStable  = "stable"
+	TokenTrendFalling = "falling"
+)
+
+const (
+	// demandSampleInterval is the minimal interval to sample the RU demand.
+	demandSampleInterval = time.Second
+	// shortDemandFactor and longDemandFactor are the weights of the history in
+	// the short-term and long-term moving averages of the RU demand.
+	shortDemandFactor = 0.5
+	longDemandFactor  = 0.9
+	// trendThreshold is the relative difference between the short-term and the
+	// long-term demand to be a trend.
+	trendThreshold = 0.2
+	// lowUtilization is the utilization of the fill rate below which the period
+	// is extended while the demand is not rising.
+	lowUtilization = 0.5
+	// maxPeriodFactor limits the suggested period in times of the target period.
+	maxPeriodFactor = 4
+)
+
+// TokenHint is the load-aware hint of the token requests of a resource group.
+// The protocol doesn't carry the hint, so the suggested period is applied by
+// granting the tokens for it, and the clients follow it with the trickle time
+// of the grants and the notifications of the low tokens.
+type TokenHint struct {
+	Trend string `json:"trend"`
+	// DemandRate is the short-term moving average of the requested RU per second.
+	DemandRate float64 `json:"demand_rate"`
+	// Utilization is the demand rate divided by the fill rate, zero if the
+	// fill rate is not limited.
+	Utilization float64 `json:"utilization"`
+	// PeriodFactor is the suggested request period in times of the target period
+	// of the clients.
+	PeriodFactor float64 `json:"period_factor"`
+}
+
+// tokenDemand tracks the RU demand of a resource group by the token requests.
+type tokenDemand struct {
+	sync.Mutex
+	shortRate float64
+	longRate  float64
+	// pending is the RU requested since the last sample.
+	pending    float64
+	lastSample time.Time
+}
+
+// observe records the RU requested by a token request.
+func (d *tokenDemand) observe(now time.Time, neededTokens float64) {
+	d.Lock()
+	defer d.Unlock()
+	if d.lastSample.IsZero() {
+		d.lastSample = now
+	}
+	d.pending += neededTokens
+	elapsed := now.Sub(d.lastSample)
+	if elapsed < demandSampleInterval {
+		return
+	}
+	rate := d.pending / elapsed.Seconds()
+	d.shortRate = shortDemandFactor*d.shortRate + (1-shortDemandFactor)*rate
+	d.longRate = longDemandFactor*d.longRate + (1-longDemandFactor)*rate
+	d.pending, d.lastSample = 0, now
+}
+
+// hint returns the hint by the demand and the fill rate, zero fill rate means
+// the tokens are not limited.
+func (d *tokenDemand) hint(fillRate uint64) *TokenHint {
+	d.Lock()
+	defer d.Unlock()
+	hint := &TokenHint{Trend: TokenTrendStable, DemandRate: d.shortRate, PeriodFactor: 1}
+	switch {
+	case d.shortRate > d.longRate*(1+trendThreshold):
+		hint.Trend = TokenTrendRising
+	case d.shortRate < d.longRate*(1-trendThreshold):
+		hint.Trend = TokenTrendFalling
+	}
+	if fillRate == 0 {
+		return hint
+	}
+	hint.Utilization = d.shortRate / float64(fillRate)
+	switch {
+	case hint.Utilization > 1 && hint.Trend != TokenTrendFalling:
+		// Back off in proportion to the overload, the clients would be throttled anyway.
+		hint.PeriodFactor = math.Min(hint.Utilization, maxPeriodFactor)
+	case hint.Utilization < lowUtilization && hint.Trend != TokenTrendRising:
+		hint.PeriodFactor = 2
+	}
+	return hint
+}
+
+// GetTokenHint returns the load-aware hint of the RU token requests.
+func (rg *ResourceGroup) GetTokenHint() *TokenHint {
+	rg.RLock()
+	defer rg.RUnlock()
+	var fillRate uint64
+	if rg.RUSettings != nil && rg.RUSettings.RU.Settings != nil && rg.RUSettings.RU.Settings.BurstLimit >= 0 {
+		fillRate = rg.RUSettings.RU.Settings.FillRate
+	}
+	return rg.demand.hint(fillRate)
+}
+
+// adaptTokenRequest observes the RU demand of the request, and returns the
+// needed tokens and the target period adapted to the hint.
+func (rg *ResourceGroup) adaptTokenRequest(now time.Time, neededTokens float64, targetPeriodMs uint64) (float64, uint64) {
+	rg.demand.observe(now, neededTokens)
+	factor := rg.GetTokenHint().PeriodFactor
+	if factor == 1 || targetPeriodMs == 0 {
+		return neededTokens, targetPeriodMs
+	}
+	return neededTokens * factor, uint64(float64(targetPeriodMs) * factor)
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	rmpb "github.com/pingcap/kvproto/pkg/resource_manager"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenHint(t *testing.T) {
+	re := require.New(t)
+	d := &tokenDemand{}
+	now := time.Unix(100, 0)
+	hint := d.hint(100)
+	re.Equal(TokenTrendStable, hint.Trend)
+	re.Equal(2., hint.PeriodFactor)
+
+	// The demand is sampled at most once a second.
+	d.observe(now, 400)
+	d.observe(now.Add(500*time.Millisecond), 400)
+	re.Zero(d.shortRate)
+	d.observe(now.Add(time.Second), 400)
+	re.Equal(600., d.shortRate)
+	re.InDelta(120., d.longRate, 1e-9)
+	hint = d.hint(100)
+	re.Equal(TokenTrendRising, hint.Trend)
+	re.Equal(6., hint.Utilization)
+	re.Equal(float64(maxPeriodFactor), hint.PeriodFactor)
+	hint = d.hint(400)
+	re.Equal(1.5, hint.PeriodFactor)
+	re.Equal(1., d.hint(0).PeriodFactor)
+
+	// The period is not extended for the falling demand.
+	for i := 2; i < 10; i++ {
+		d.observe(now.Add(time.Duration(i)*time.Second), 0)
+	}
+	hint = d.hint(1)
+	re.Equal(TokenTrendFalling, hint.Trend)
+	re.Equal(1., hint.PeriodFactor)
+}
+
+func TestAdaptTokenRequest(t *testing.T) {
+	re := require.New(t)
+	rg := &ResourceGroup{Name: "test", Mode: rmpb.GroupMode_RUMode, RUSettings: NewRequestUnitSettings(&rmpb.TokenBucket{
+		Settings: &rmpb.TokenLimitSettings{FillRate: 1000},
+	})}
+	re.NoError(rg.CheckAndInit())
+	now := time.Unix(100, 0)
+	// The idle group is granted the tokens for a longer period.
+	needed, period := rg.adaptTokenRequest(now, 100, 1000)
+	re.Equal(200., needed)
+	re.Equal(uint64(2000), period)
+	needed, period = rg.adaptTokenRequest(now.Add(time.Second), 100, 1000)
+	re.Equal(100., needed)
+	re.Equal(uint64(1000), period)
+	re.Equal(TokenTrendRising, rg.GetTokenHint().Trend)
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/pingcap/kvproto/pkg/pdpb"
@@ -142,9 +143,10 @@ func (s *Service) Tso(stream tsopb.TSO_TsoServer) error {
 		count := request.GetCount()
 		ts, err := s.tsoAllocatorManager.HandleTSORequest(request.GetDcLocation(), count)
 		if err != nil {
-			return status.Errorf(codes.Unknown, err.Error())
+			return grpcutil.StatusError(streamCtx, err)
 		}
 		tsoHandleDuration.Observe(time.Since(start).Seconds())
+		keyspaceTSOCounter.WithLabelValues(strconv.FormatUint(uint64(request.GetHeader().GetKeyspaceId()), 10)).Add(float64(count))
 		response := &tsopb.TsoResponse{
 			Header:    s.header(),
 			Timestamp: &ts,
@@ -72,12 +72,21 @@ var (
 			Help:      "Bucketed histogram of processing time (s) of handled tso requests.",
 			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 13),
 		})
+
+	keyspaceTSOCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "server",
+			Name:      "keyspace_tso_total",
+			Help:      "Counter of the timestamps allocated for each keyspace.",
+		}, []string{"keyspace_id"})
 )
 
 func init() {
 	prometheus.MustRegister(timeJumpBackCounter)
 	prometheus.MustRegister(metadataGauge)
 	prometheus.MustRegister(serverInfo)
+	prometheus.MustRegister(keyspaceTSOCounter)
 	prometheus.MustRegister(tsoProxyHandleDuration)
 	prometheus.MustRegister(tsoProxyBatchSize)
 	prometheus.MustRegister(tsoHandleDuration)
@@ -0,0 +1,100 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/tikv/pd/pkg/utils/syncutil"
+)
+
+// CampaignBackoff decides when a member campaigns for the PD leader. It backs
+// off the campaign with jitter after failures, and stops the member from
+// campaigning for a cooldown if it keeps losing the leadership, which is
+// usually caused by a marginal member with an unstable network or disk.
+type CampaignBackoff struct {
+	syncutil.Mutex
+	failures      int
+	lostTimes     []time.Time
+	cooldownUntil time.Time
+}
+
+// NewCampaignBackoff creates a new CampaignBackoff.
+func NewCampaignBackoff() *CampaignBackoff {
+	return &CampaignBackoff{}
+}
+
+// NextBackoff records a failed campaign and returns how long to wait before
+// the next one. The backoff grows exponentially from base up to max, and a
+// random jitter of up to half of it is subtracted so that the members do not
+// campaign at the same time.
+func (b *CampaignBackoff) NextBackoff(base, max time.Duration) time.Duration {
+	b.Lock()
+	defer b.Unlock()
+	if max < base {
+		max = base
+	}
+	backoff := base
+	for i := 0; i < b.failures && backoff < max; i++ {
+		backoff *= 2
+	}
+	if backoff > max {
+		backoff = max
+	}
+	b.failures++
+	if jitter := int64(backoff / 2); jitter > 0 {
+		backoff -= time.Duration(rand.Int63n(jitter + 1))
+	}
+	return backoff
+}
+
+// Reset resets the backoff after a successful campaign.
+func (b *CampaignBackoff) Reset() {
+	b.Lock()
+	defer b.Unlock()
+	b.failures = 0
+}
+
+// OnLeadershipLost records the member loses the leadership. It returns true
+// and starts the cooldown if the member has lost the leadership threshold
+// times within the window. A zero threshold disables the flapping detection.
+func (b *CampaignBackoff) OnLeadershipLost(now time.Time, threshold int, window, cooldown time.Duration) bool {
+	b.Lock()
+	defer b.Unlock()
+	b.lostTimes = append(b.lostTimes, now)
+	i := 0
+	for i < len(b.lostTimes) && now.Sub(b.lostTimes[i]) > window {
+		i++
+	}
+	b.lostTimes = b.lostTimes[i:]
+	if threshold <= 0 || len(b.lostTimes) < threshold {
+		return false
+	}
+	b.lostTimes = nil
+	b.cooldownUntil = now.Add(cooldown)
+	return true
+}
+
+// CooldownRemaining returns how long the member should still stay away from
+// the campaign, it is 0 if the member is not in the cooldown.
+func (b *CampaignBackoff) CooldownRemaining(now time.Time) time.Duration {
+	b.Lock()
+	defer b.Unlock()
+	if remaining := b.cooldownUntil.Sub(now); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCampaignBackoff(t *testing.T) {
+	re := require.New(t)
+	b := NewCampaignBackoff()
+	base, max := 100*time.Millisecond, time.Second
+	for _, expected := range []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	} {
+		backoff := b.NextBackoff(base, max)
+		re.LessOrEqual(backoff, expected)
+		re.GreaterOrEqual(backoff, expected/2)
+	}
+	b.Reset()
+	re.LessOrEqual(b.NextBackoff(base, max), base)
+	// The max backoff is never less than the base.
+	b.Reset()
+	re.GreaterOrEqual(b.NextBackoff(base, 0), base/2)
+}
+
+func TestLeaderFlapping(t *testing.T) {
+	re := require.New(t)
+	b := NewCampaignBackoff()
+	window, cooldown := time.Minute, 5*time.Minute
+	now := time.Now()
+	re.Zero(b.CooldownRemaining(now))
+
+	// Losing the leadership out of the window is not flapping.
+	re.False(b.OnLeadershipLost(now, 3, window, cooldown))
+	re.False(b.OnLeadershipLost(now.Add(2*time.Minute), 3, window, cooldown))
+	re.False(b.OnLeadershipLost(now.Add(4*time.Minute), 3, window, cooldown))
+	re.Zero(b.CooldownRemaining(now.Add(4 * time.Minute)))
+
+	now = now.Add(4 * time.Minute)
+	re.False(b.OnLeadershipLost(now.Add(10*time.Second), 3, window, cooldown))
+	re.True(b.OnLeadershipLost(now.Add(20*time.Second), 3, window, cooldown))
+	now = now.Add(20 * time.Second)
+	re.Equal(cooldown, b.CooldownRemaining(now))
+	re.Equal(time.Minute, b.CooldownRemaining(now.Add(4*time.Minute)))
+	re.Zero(b.CooldownRemaining(now.Add(cooldown)))
+
+	// The history is cleared after the cooldown starts.
+	re.False(b.OnLeadershipLost(now.Add(cooldown), 3, window, cooldown))
+
+	// The flapping detection is disabled.
+	b = NewCampaignBackoff()
+	for i := 0; i < 10; i++ {
+		re.False(b.OnLeadershipLost(now, 0, window, cooldown))
+	}
+	re.Zero(b.CooldownRemaining(now))
+}
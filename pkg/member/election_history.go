@@ -0,0 +1,107 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/utils/typeutil"
+	"go.uber.org/zap"
+)
+
+// electionHistoryRetention is how long the election events are kept.
+const electionHistoryRetention = 30 * 24 * time.Hour
+
+// ElectionEventType is the type of the election event.
+type ElectionEventType string
+
+const (
+	// ElectionEventElected means the member is elected as the leader.
+	ElectionEventElected ElectionEventType = "elected"
+	// ElectionEventLost means the member loses the leadership.
+	ElectionEventLost ElectionEventType = "lost"
+)
+
+// ElectionEvent is a leadership change of a service, such as the PD leader
+// or the primary of a microservice.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type ElectionEvent struct {
+	Service  string            `json:"service"`
+	Type     ElectionEventType `json:"type"`
+	Name     string            `json:"name"`
+	MemberID uint64            `json:"member_id"`
+	// Reason is why the member loses the leadership.
+	Reason string    `json:"reason,omitempty"`
+	Time   time.Time `json:"time"`
+	// NoLeaderDuration is how long the service has no leader before the member
+	// is elected. It is zero if the member observes no leaderless period.
+	NoLeaderDuration typeutil.Duration `json:"no_leader_duration"`
+}
+
+// ElectionHook is called with the election events of the member. It is called
+// synchronously in the election loop, so it should not block.
+type ElectionHook func(event *ElectionEvent)
+
+// ElectionHistory records the election events persistently.
+type ElectionHistory struct {
+	storage endpoint.ElectionHistoryStorage
+}
+
+// NewElectionHistory creates a new ElectionHistory.
+func NewElectionHistory(storage endpoint.ElectionHistoryStorage) *ElectionHistory {
+	return &ElectionHistory{storage: storage}
+}
+
+// Record saves the election event and removes the expired ones. The event may
+// not be saved if the member can not access the storage, e.g. it loses the
+// leadership due to the network partition.
+func (h *ElectionHistory) Record(event *ElectionEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if err := h.storage.SaveElectionEvent(event.Time.UnixNano(), event); err != nil {
+		log.Warn("failed to save the election event",
+			zap.String("service", event.Service),
+			zap.String("type", string(event.Type)),
+			zap.String("name", event.Name),
+			errs.ZapError(err))
+		return
+	}
+	if err := h.storage.RemoveElectionEventsBefore(event.Time.Add(-electionHistoryRetention).UnixNano()); err != nil {
+		log.Warn("failed to remove the expired election events", errs.ZapError(err))
+	}
+}
+
+// Load loads at most limit election events happened in [start, end), in the
+// order of time. There is no limit if limit is 0.
+func (h *ElectionHistory) Load(start, end time.Time, limit int) ([]*ElectionEvent, error) {
+	events := make([]*ElectionEvent, 0)
+	err := h.storage.LoadElectionEvents(start.UnixNano(), end.UnixNano(), limit, func(value []byte) error {
+		event := &ElectionEvent{}
+		if err := json.Unmarshal(value, event); err != nil {
+			return errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+		}
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
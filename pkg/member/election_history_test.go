@@ -0,0 +1,70 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/storage"
+	"github.com/tikv/pd/pkg/utils/typeutil"
+)
+
+func TestElectionHistory(t *testing.T) {
+	re := require.New(t)
+	h := NewElectionHistory(storage.NewStorageWithMemoryBackend())
+	now := time.Now()
+	for i := 3; i > 0; i-- {
+		h.Record(&ElectionEvent{Service: "pd", Type: ElectionEventLost, Name: "pd1", Reason: "lease expired", Time: now.Add(-time.Duration(i) * time.Hour)})
+		h.Record(&ElectionEvent{Service: "pd", Type: ElectionEventElected, Name: "pd2", Time: now.Add(-time.Duration(i)*time.Hour + time.Second),
+			NoLeaderDuration: typeutil.NewDuration(time.Second)})
+	}
+
+	events, err := h.Load(time.Unix(0, 0), now, 0)
+	re.NoError(err)
+	re.Len(events, 6)
+	for i, event := range events {
+		if i%2 == 0 {
+			re.Equal(ElectionEventLost, event.Type)
+			re.Equal("lease expired", event.Reason)
+		} else {
+			re.Equal(ElectionEventElected, event.Type)
+			re.Equal(time.Second, event.NoLeaderDuration.Duration)
+		}
+		if i > 0 {
+			re.True(events[i-1].Time.Before(event.Time))
+		}
+	}
+	events, err = h.Load(time.Unix(0, 0), now, 3)
+	re.NoError(err)
+	re.Len(events, 3)
+	events, err = h.Load(now.Add(-90*time.Minute), now, 0)
+	re.NoError(err)
+	re.Len(events, 2)
+
+	// The expired events are removed.
+	h.Record(&ElectionEvent{Service: "pd", Type: ElectionEventElected, Name: "pd1", Time: now.Add(electionHistoryRetention - 90*time.Minute)})
+	events, err = h.Load(time.Unix(0, 0), now.Add(electionHistoryRetention), 0)
+	re.NoError(err)
+	re.Len(events, 3)
+	re.Equal("pd1", events[2].Name)
+	// The time is set if it is absent.
+	h.Record(&ElectionEvent{Service: "pd", Type: ElectionEventLost, Name: "pd1"})
+	events, err = h.Load(now, now.Add(time.Minute), 0)
+	re.NoError(err)
+	re.Len(events, 1)
+	re.False(events[0].Time.IsZero())
+}
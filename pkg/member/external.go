@@ -0,0 +1,116 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"hash/fnv"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/utils/etcdutil"
+	"github.com/tikv/pd/pkg/utils/logutil"
+	"go.etcd.io/etcd/clientv3"
+	"go.uber.org/zap"
+)
+
+const (
+	// externalMemberTTL is the TTL in seconds of the registration of a member
+	// with the external etcd.
+	externalMemberTTL = 10
+	// externalMemberRetryInterval is the interval to retry the registration.
+	externalMemberRetryInterval = time.Second
+)
+
+// ExternalMemberID generates the member ID of a PD server running against
+// the external etcd, which is derived from the unique name of the server.
+func ExternalMemberID(name string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return h.Sum64()
+}
+
+// IsExternalEtcd returns whether the member runs against the external etcd.
+func (m *Member) IsExternalEtcd() bool {
+	return m.etcd == nil
+}
+
+func (m *Member) getExternalMemberPrefix() string {
+	return path.Join(m.rootPath, "external_member") + "/"
+}
+
+// RegisterExternalMember registers the member with the external etcd, so it
+// can be listed by the others since the etcd members are not PD members. The
+// registration is kept alive until the context is canceled.
+func (m *Member) RegisterExternalMember(ctx context.Context) {
+	go func() {
+		defer logutil.LogPanic()
+		for {
+			if err := m.registerExternalMember(ctx); err != nil {
+				log.Warn("failed to register the member with the external etcd", errs.ZapError(err))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(externalMemberRetryInterval):
+			}
+		}
+	}()
+}
+
+func (m *Member) registerExternalMember(ctx context.Context) error {
+	resp, err := m.client.Grant(ctx, externalMemberTTL)
+	if err != nil {
+		return errs.ErrEtcdGrantLease.Wrap(err).GenWithStackByCause()
+	}
+	key := m.getExternalMemberPrefix() + strconv.FormatUint(m.id, 10)
+	if _, err := m.client.Put(ctx, key, m.memberValue, clientv3.WithLease(resp.ID)); err != nil {
+		return errs.ErrEtcdKVPut.Wrap(err).GenWithStackByCause()
+	}
+	ch, err := m.client.KeepAlive(ctx, resp.ID)
+	if err != nil {
+		return errs.ErrEtcdGrantLease.Wrap(err).GenWithStackByCause()
+	}
+	log.Info("member is registered with the external etcd", zap.String("key", key))
+	for range ch {
+		// Drain the responses until the keepalive stops.
+	}
+	if ctx.Err() == nil {
+		return errs.ErrEtcdGrantLease.FastGenByArgs()
+	}
+	return nil
+}
+
+// GetExternalMembers returns the members registered with the external etcd.
+func (m *Member) GetExternalMembers() ([]*pdpb.Member, error) {
+	prefix := m.getExternalMemberPrefix()
+	resp, err := etcdutil.EtcdKVGet(m.client, prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+	members := make([]*pdpb.Member, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		member := &pdpb.Member{}
+		if err := member.Unmarshal(kv.Value); err != nil {
+			return nil, errs.ErrProtoUnmarshal.Wrap(err).GenWithStackByCause()
+		}
+		members = append(members, member)
+	}
+	return members, nil
+}
@@ -0,0 +1,159 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/storage/kv"
+	"github.com/tikv/pd/pkg/utils/etcdutil"
+	"go.etcd.io/etcd/clientv3"
+	"go.uber.org/zap"
+)
+
+// maxLeaderApplyLag is the max number of the committed but not applied raft
+// entries of a member which is ready to be the leader.
+const maxLeaderApplyLag = 1000
+
+// leaderRank is used to compare the members to be the leader. The member in
+// the zones of the leader affinity is preferred, and then the one with the
+// higher leader priority.
+type leaderRank struct {
+	affinity bool
+	priority int
+}
+
+func (r leaderRank) higherThan(other leaderRank) bool {
+	if r.affinity != other.affinity {
+		return r.affinity
+	}
+	return r.priority > other.priority
+}
+
+func (m *Member) getLeaderRank(id uint64, affinityZones []string) (leaderRank, error) {
+	priority, err := m.GetMemberLeaderPriority(id)
+	if err != nil {
+		return leaderRank{}, err
+	}
+	rank := leaderRank{priority: priority}
+	if len(affinityZones) == 0 {
+		return rank, nil
+	}
+	zone, err := m.GetMemberZone(id)
+	if err != nil {
+		return leaderRank{}, err
+	}
+	for _, z := range affinityZones {
+		if zone != "" && zone == z {
+			rank.affinity = true
+			break
+		}
+	}
+	return rank, nil
+}
+
+// isReadyToLead checks whether the member has applied the most of the
+// committed raft entries, a lagging member is not healthy to be the leader.
+func (m *Member) isReadyToLead() bool {
+	if m.IsExternalEtcd() {
+		return true
+	}
+	server := m.etcd.Server
+	committed, applied := server.CommittedIndex(), server.AppliedIndex()
+	if committed > applied+maxLeaderApplyLag {
+		log.Warn("member is lagging behind, skip transferring leader to it",
+			zap.Uint64("committed-index", committed), zap.Uint64("applied-index", applied))
+		return false
+	}
+	return true
+}
+
+func (m *Member) getLeaderAffinityPath() string {
+	return path.Join(m.rootPath, "member/leader_affinity")
+}
+
+// SetLeaderAffinity saves the zones where the leader is preferred to be. The
+// leader affinity is cleared if the zones are empty.
+func (m *Member) SetLeaderAffinity(zones []string) error {
+	key := m.getLeaderAffinityPath()
+	op := clientv3.OpDelete(key)
+	if len(zones) > 0 {
+		value, err := json.Marshal(zones)
+		if err != nil {
+			return errs.ErrJSONMarshal.Wrap(err).GenWithStackByCause()
+		}
+		op = clientv3.OpPut(key, string(value))
+	}
+	res, err := m.leadership.LeaderTxn().Then(op).Commit()
+	if err != nil {
+		return errs.ErrEtcdTxnInternal.Wrap(err).GenWithStackByCause()
+	}
+	if !res.Succeeded {
+		log.Error("save leader affinity failed, maybe not pd leader")
+		return errs.ErrEtcdTxnConflict.FastGenByArgs()
+	}
+	return nil
+}
+
+// GetLeaderAffinity loads the zones where the leader is preferred to be.
+func (m *Member) GetLeaderAffinity() ([]string, error) {
+	res, err := etcdutil.EtcdKVGet(m.client, m.getLeaderAffinityPath())
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Kvs) == 0 {
+		return nil, nil
+	}
+	var zones []string
+	if err := json.Unmarshal(res.Kvs[0].Value, &zones); err != nil {
+		return nil, errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+	}
+	return zones, nil
+}
+
+func (m *Member) getMemberZonePath(id uint64) string {
+	return path.Join(m.rootPath, fmt.Sprintf("member/%d/zone", id))
+}
+
+// GetMemberZone loads a member's zone, it is empty if the zone label is not set.
+func (m *Member) GetMemberZone(id uint64) (string, error) {
+	res, err := etcdutil.EtcdKVGet(m.client, m.getMemberZonePath(id))
+	if err != nil {
+		return "", err
+	}
+	if len(res.Kvs) == 0 {
+		return "", nil
+	}
+	return string(res.Kvs[0].Value), nil
+}
+
+// SetMemberZone saves a member's zone.
+func (m *Member) SetMemberZone(id uint64, zone string) error {
+	key := m.getMemberZonePath(id)
+	txn := kv.NewSlowLogTxn(m.client)
+	res, err := txn.Then(clientv3.OpPut(key, zone)).Commit()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if !res.Succeeded {
+		return errors.New("failed to save zone")
+	}
+	return nil
+}
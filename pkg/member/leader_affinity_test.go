@@ -0,0 +1,40 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaderRank(t *testing.T) {
+	re := require.New(t)
+	testCases := []struct {
+		rank, other leaderRank
+		higher      bool
+	}{
+		{leaderRank{priority: 1}, leaderRank{priority: 0}, true},
+		{leaderRank{priority: 0}, leaderRank{priority: 0}, false},
+		{leaderRank{priority: 0}, leaderRank{priority: 1}, false},
+		{leaderRank{affinity: true}, leaderRank{priority: 100}, true},
+		{leaderRank{priority: 100}, leaderRank{affinity: true}, false},
+		{leaderRank{affinity: true, priority: 2}, leaderRank{affinity: true, priority: 1}, true},
+		{leaderRank{affinity: true, priority: 1}, leaderRank{affinity: true, priority: 1}, false},
+	}
+	for i, tc := range testCases {
+		re.Equal(tc.higher, tc.rank.higherThan(tc.other), i)
+	}
+}
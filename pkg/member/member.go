@@ -199,23 +199,29 @@ func (m *Member) ResetLeader() {
 	m.unsetLeader()
 }
 
-// CheckPriority checks whether the etcd leader should be moved according to the priority.
+// CheckPriority checks whether the etcd leader should be moved according to the
+// leader affinity and priority. The PD leader always follows the etcd leader.
 func (m *Member) CheckPriority(ctx context.Context) {
 	etcdLeader := m.GetEtcdLeader()
 	if etcdLeader == m.ID() || etcdLeader == 0 {
 		return
 	}
-	myPriority, err := m.GetMemberLeaderPriority(m.ID())
+	affinityZones, err := m.GetLeaderAffinity()
+	if err != nil {
+		log.Error("failed to load leader affinity", errs.ZapError(err))
+		return
+	}
+	myRank, err := m.getLeaderRank(m.ID(), affinityZones)
 	if err != nil {
 		log.Error("failed to load leader priority", errs.ZapError(err))
 		return
 	}
-	leaderPriority, err := m.GetMemberLeaderPriority(etcdLeader)
+	leaderRank, err := m.getLeaderRank(etcdLeader, affinityZones)
 	if err != nil {
 		log.Error("failed to load etcd leader priority", errs.ZapError(err))
 		return
 	}
-	if myPriority > leaderPriority {
+	if myRank.higherThan(leaderRank) && m.isReadyToLead() {
 		err := m.MoveEtcdLeader(ctx, etcdLeader, m.ID())
 		if err != nil {
 			log.Error("failed to transfer etcd leader", errs.ZapError(err))
@@ -229,6 +235,9 @@ func (m *Member) CheckPriority(ctx context.Context) {
 
 // MoveEtcdLeader tries to transfer etcd leader.
 func (m *Member) MoveEtcdLeader(ctx context.Context, old, new uint64) error {
+	if m.IsExternalEtcd() {
+		return errs.ErrEtcdMoveLeader.FastGenByArgs()
+	}
 	moveCtx, cancel := context.WithTimeout(ctx, moveLeaderTimeout)
 	defer cancel()
 	err := m.etcd.Server.MoveLeader(moveCtx, old, new)
@@ -238,8 +247,13 @@ func (m *Member) MoveEtcdLeader(ctx context.Context, old, new uint64) error {
 	return nil
 }
 
-// GetEtcdLeader returns the etcd leader ID.
+// GetEtcdLeader returns the etcd leader ID. With the external etcd, the etcd
+// leader is not a PD member, so every member regards itself as the etcd leader
+// to campaign for the PD leader.
 func (m *Member) GetEtcdLeader() uint64 {
+	if m.IsExternalEtcd() {
+		return m.id
+	}
 	return m.etcd.Server.Lead()
 }
 
@@ -272,6 +286,9 @@ func (m *Member) MemberInfo(advertiseClientUrls, advertisePeerUrls, name string,
 // other pd-servers can campaign.
 func (m *Member) ResignEtcdLeader(ctx context.Context, from string, nextEtcdLeader string) error {
 	log.Info("try to resign etcd leader to next pd-server", zap.String("from", from), zap.String("to", nextEtcdLeader))
+	if m.IsExternalEtcd() {
+		return errors.New("the etcd leader can not be resigned with the external etcd")
+	}
 	// Determine next etcd leader candidates.
 	var etcdLeaderIDs []uint64
 	res, err := etcdutil.ListEtcdMembers(m.client)
@@ -279,16 +296,19 @@ func (m *Member) ResignEtcdLeader(ctx context.Context, from string, nextEtcdLead
 		return err
 	}
 
-	// Do nothing when I am the only member of cluster.
-	if len(res.Members) == 1 && res.Members[0].ID == m.id && nextEtcdLeader == "" {
-		return nil
-	}
-
 	for _, member := range res.Members {
+		// A witness joins as an etcd learner, which can not be the etcd leader.
+		if member.GetIsLearner() {
+			continue
+		}
 		if (nextEtcdLeader == "" && member.ID != m.id) || (nextEtcdLeader != "" && member.Name == nextEtcdLeader) {
 			etcdLeaderIDs = append(etcdLeaderIDs, member.GetID())
 		}
 	}
+	// Do nothing when I am the only voter of cluster.
+	if len(etcdLeaderIDs) == 0 && nextEtcdLeader == "" {
+		return nil
+	}
 	if len(etcdLeaderIDs) == 0 {
 		return errors.New("no valid pd to transfer etcd leader")
 	}
@@ -467,7 +487,38 @@ func (m *Member) SetMemberGitHash(id uint64, gitHash string) error {
 	return nil
 }
 
+func (m *Member) getMemberWitnessPath(id uint64) string {
+	return path.Join(m.rootPath, fmt.Sprintf("member/%d/witness", id))
+}
+
+// IsMemberWitness checks whether a member is a witness, which never campaigns
+// for the PD leader, so it should stay an etcd learner.
+func (m *Member) IsMemberWitness(id uint64) (bool, error) {
+	res, err := etcdutil.EtcdKVGet(m.client, m.getMemberWitnessPath(id))
+	if err != nil {
+		return false, err
+	}
+	return len(res.Kvs) > 0 && string(res.Kvs[0].Value) == "true", nil
+}
+
+// SetMemberWitness saves whether a member is a witness.
+func (m *Member) SetMemberWitness(id uint64, witness bool) error {
+	key := m.getMemberWitnessPath(id)
+	txn := kv.NewSlowLogTxn(m.client)
+	res, err := txn.Then(clientv3.OpPut(key, strconv.FormatBool(witness))).Commit()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if !res.Succeeded {
+		return errors.New("failed to save witness")
+	}
+	return nil
+}
+
 // Close gracefully shuts down all servers/listeners.
 func (m *Member) Close() {
+	if m.IsExternalEtcd() {
+		return
+	}
 	m.Etcd().Close()
 }
@@ -86,3 +86,9 @@ func (e *EMA) Set(n float64) {
 func (e *EMA) GetInstantaneous() float64 {
 	return e.instantaneous
 }
+
+// Clone returns a copy of EMA.
+func (e *EMA) Clone() MovingAvg {
+	ema := *e
+	return &ema
+}
@@ -71,3 +71,15 @@ func (h *HMA) Set(n float64) {
 func (h *HMA) GetInstantaneous() float64 {
 	return h.wma[1].GetInstantaneous()
 }
+
+// Clone returns a copy of HMA
+func (h *HMA) Clone() MovingAvg {
+	wma := make([]*WMA, len(h.wma))
+	for i := range h.wma {
+		wma[i] = h.wma[i].clone()
+	}
+	return &HMA{
+		wma:  wma,
+		size: h.size,
+	}
+}
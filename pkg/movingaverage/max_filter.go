@@ -68,3 +68,14 @@ func (r *MaxFilter) GetInstantaneous() float64 {
 	}
 	return r.records[(r.count-1)%r.size]
 }
+
+// Clone returns a copy of MaxFilter
+func (r *MaxFilter) Clone() MovingAvg {
+	records := make([]float64, len(r.records))
+	copy(records, r.records)
+	return &MaxFilter{
+		records: records,
+		size:    r.size,
+		count:   r.count,
+	}
+}
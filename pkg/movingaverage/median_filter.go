@@ -83,7 +83,7 @@ func (r *MedianFilter) GetInstantaneous() float64 {
 }
 
 // Clone returns a copy of MedianFilter
-func (r *MedianFilter) Clone() *MedianFilter {
+func (r *MedianFilter) Clone() MovingAvg {
 	records := make([]float64, len(r.records))
 	copy(records, r.records)
 	return &MedianFilter{
@@ -27,4 +27,6 @@ type MovingAvg interface {
 	Reset()
 	// Set = Reset + Add
 	Set(data float64)
+	// Clone returns a copy of the moving average.
+	Clone() MovingAvg
 }
@@ -71,6 +71,16 @@ func checkInstantaneous(re *require.Assertions, ma MovingAvg) {
 	re.Equal(value, ma.GetInstantaneous())
 }
 
+// checkClone checks the clone is not affected by the original one.
+func checkClone(re *require.Assertions, ma MovingAvg, data []float64, expected []float64) {
+	half := len(data) / 2
+	ma.Reset()
+	checkAdd(re, ma, data[:half], expected[:half])
+	clone := ma.Clone()
+	checkAdd(re, ma, data[half:], expected[half:])
+	checkAdd(re, clone, data[half:], expected[half:])
+}
+
 func TestMedianFilter(t *testing.T) {
 	t.Parallel()
 	re := require.New(t)
@@ -119,5 +129,23 @@ func TestMovingAvg(t *testing.T) {
 		checkAdd(re, testCase.ma, data, testCase.expected)
 		checkSet(re, testCase.ma, data, testCase.expected)
 		checkInstantaneous(re, testCase.ma)
+		checkClone(re, testCase.ma, data, testCase.expected)
 	}
 }
+
+func TestTimeEMA(t *testing.T) {
+	t.Parallel()
+	re := require.New(t)
+	interval := 10 * time.Second
+	tm := NewTimeEMA(1, 0.9, interval)
+	for i := 0; i < 10; i++ {
+		tm.Add(100, interval)
+	}
+	re.Equal(10.0, tm.Get())
+
+	// The clone should not be affected by the original one.
+	clone := tm.Clone()
+	tm.Add(1000, interval)
+	re.InDelta(91.0, tm.Get(), 1e-6)
+	re.Equal(10.0, clone.Get())
+}
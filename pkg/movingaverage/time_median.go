@@ -20,9 +20,10 @@ import "time"
 // Size of MedianFilter should be larger than double size of AvgOverTime to denoisy.
 // Delay is aotSize * mfSize * reportInterval/4
 // and the min filled period is aotSize * reportInterval, which is not related with mfSize
+// The MedianFilter can be replaced by an EMA, see NewTimeEMA.
 type TimeMedian struct {
 	aot *AvgOverTime
-	mf  *MedianFilter
+	mf  MovingAvg
 }
 
 // NewTimeMedian returns a TimeMedian with given size.
@@ -33,6 +34,15 @@ func NewTimeMedian(aotSize, mfSize int, reportInterval time.Duration) *TimeMedia
 	}
 }
 
+// NewTimeEMA returns a TimeMedian which smooths the averages over time with
+// an EMA of the given decay instead of a MedianFilter.
+func NewTimeEMA(aotSize int, decay float64, reportInterval time.Duration) *TimeMedian {
+	return &TimeMedian{
+		aot: NewAvgOverTime(time.Duration(aotSize) * reportInterval),
+		mf:  NewEMA(decay),
+	}
+}
+
 // Get returns change rate in the median of the several intervals.
 func (t *TimeMedian) Get() float64 {
 	return t.mf.Get()
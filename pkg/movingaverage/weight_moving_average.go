@@ -84,3 +84,20 @@ func (w *WMA) GetInstantaneous() float64 {
 	}
 	return w.records[(w.count-1)%w.size]
 }
+
+// Clone returns a copy of WMA
+func (w *WMA) Clone() MovingAvg {
+	return w.clone()
+}
+
+func (w *WMA) clone() *WMA {
+	records := make([]float64, len(w.records))
+	copy(records, w.records)
+	return &WMA{
+		records: records,
+		size:    w.size,
+		count:   w.count,
+		score:   w.score,
+		sum:     w.sum,
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationHeader = "Authorization"
+	// authorizationMetadataKey is the gRPC metadata key of the bearer token.
+	authorizationMetadataKey = "authorization"
+	bearerPrefix             = "Bearer "
+	// sessionPath is the HTTP API to log in and log out, which any known
+	// identity may call.
+	sessionPath = "/pd/api/v1/sessions"
+)
+
+var (
+	// publicPaths are the HTTP APIs open to everyone, e.g. the health checks
+	// of the load balancers.
+	publicPaths = map[string]struct{}{
+		"/pd/ping":           {},
+		"/pd/health":         {},
+		"/pd/api/v1/ping":    {},
+		"/pd/api/v1/health":  {},
+		"/pd/api/v1/version": {},
+	}
+	// adminPathPrefixes are the HTTP APIs which require the admin role.
+	adminPathPrefixes = []string{
+		"/pd/api/v1/rbac/bindings",
+		"/pd/api/v1/rbac/tokens",
+		"/pd/api/v1/rbac/grpc-policies",
+		"/pd/api/v1/rbac/network-acls",
+		"/pd/api/v1/rbac/sessions",
+		"/pd/api/v1/debug",
+		"/pd/api/v1/encryption",
+	}
+	// adminMutationPathPrefixes are the HTTP APIs which require the admin role
+	// to change, and the viewer role to read.
+	adminMutationPathPrefixes = []string{
+		"/pd/api/v1/config",
+		"/pd/api/v1/admin",
+		"/pd/api/v1/members",
+		"/pd/api/v1/leader",
+		"/pd/api/v1/service-middleware",
+		"/pd/api/v1/plugin",
+		"/pd/api/v1/security",
+		"/pd/api/v2/webhooks",
+	}
+)
+
+// IsPublicHTTP checks whether the HTTP request is open to everyone.
+func IsPublicHTTP(r *http.Request) bool {
+	_, ok := publicPaths[r.URL.Path]
+	return ok && r.Method == http.MethodGet
+}
+
+// RequiredRoleForHTTP returns the role required by the HTTP request. Reading
+// requires the viewer role, changing requires the operator role, except the
+// APIs managing PD itself which require the admin role, and the sessions which
+// only require the viewer role.
+func RequiredRoleForHTTP(r *http.Request) Role {
+	for _, prefix := range adminPathPrefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return RoleAdmin
+		}
+	}
+	if r.URL.Path == sessionPath {
+		return RoleViewer
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return RoleViewer
+	}
+	for _, prefix := range adminMutationPathPrefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return RoleAdmin
+		}
+	}
+	return RoleOperator
+}
+
+// HTTPCredential returns the credential of the HTTP request.
+func HTTPCredential(r *http.Request) Credential {
+	cred := Credential{
+		Token: parseBearerToken(r.Header.Get(authorizationHeader)),
+		Path:  r.URL.Path,
+	}
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+		cred.Cert = r.TLS.VerifiedChains[0][0]
+		cred.CN = cred.Cert.Subject.CommonName
+	}
+	return cred
+}
+
+// GRPCCredential returns the credential of the gRPC request.
+func GRPCCredential(ctx context.Context) Credential {
+	var cred Credential
+	cred.Path, _ = grpc.Method(ctx)
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(authorizationMetadataKey); len(values) > 0 {
+			cred.Token = parseBearerToken(values[0])
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.VerifiedChains) > 0 && len(info.State.VerifiedChains[0]) > 0 {
+			cred.Cert = info.State.VerifiedChains[0][0]
+			cred.CN = cred.Cert.Subject.CommonName
+		}
+	}
+	return cred
+}
+
+// HTTPStatus returns the HTTP status code of the authorization error.
+func HTTPStatus(err error) int {
+	switch errors.Cause(err) {
+	case ErrUnauthenticated:
+		return http.StatusUnauthorized
+	case ErrPermissionDenied:
+		return http.StatusForbidden
+	default:
+		return http.StatusServiceUnavailable
+	}
+}
+
+// GRPCError converts the authorization error to the gRPC status error.
+func GRPCError(err error) error {
+	switch errors.Cause(err) {
+	case ErrUnauthenticated:
+		return status.Error(codes.Unauthenticated, err.Error())
+	case ErrPermissionDenied:
+		return status.Error(codes.PermissionDenied, err.Error())
+	default:
+		return status.Error(codes.Unavailable, err.Error())
+	}
+}
+
+func parseBearerToken(value string) string {
+	if !strings.HasPrefix(value, bearerPrefix) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(value, bearerPrefix))
+}
@@ -0,0 +1,186 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"crypto/x509"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"go.uber.org/zap"
+)
+
+// The kinds of the subjects of the gRPC policies.
+const (
+	subjectCN    = "cn"
+	subjectDNS   = "dns"
+	subjectURI   = "uri"
+	subjectIP    = "ip"
+	subjectEmail = "email"
+	// allMethods allows all the gRPC methods.
+	allMethods = "*"
+)
+
+// validateGRPCPolicy checks the subjects and the methods of the policy.
+func validateGRPCPolicy(policy *endpoint.GRPCPolicy) error {
+	if len(policy.Name) == 0 {
+		return errors.New("name of gRPC policy should not be empty")
+	}
+	if len(policy.Subjects) == 0 || len(policy.Methods) == 0 {
+		return errors.New("gRPC policy should have at least one subject and one method")
+	}
+	for _, subject := range policy.Subjects {
+		kind, pattern, ok := strings.Cut(subject, ":")
+		switch {
+		case !ok || len(pattern) == 0:
+			return errors.Errorf("invalid subject %s, should be {kind}:{value}", subject)
+		case kind != subjectCN && kind != subjectDNS && kind != subjectURI && kind != subjectIP && kind != subjectEmail:
+			return errors.Errorf("unknown kind %s of subject %s", kind, subject)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errors.Errorf("invalid pattern of subject %s", subject)
+		}
+	}
+	for _, method := range policy.Methods {
+		if method == allMethods {
+			continue
+		}
+		if !strings.HasPrefix(method, "/") {
+			return errors.Errorf("invalid method %s, should be a full gRPC method like /pdpb.PD/GetRegion", method)
+		}
+		if _, err := path.Match(method, ""); err != nil {
+			return errors.Errorf("invalid pattern of method %s", method)
+		}
+	}
+	return nil
+}
+
+// matchSubject checks whether the certificate matches the subject.
+func matchSubject(subject string, cert *x509.Certificate) bool {
+	kind, pattern, _ := strings.Cut(subject, ":")
+	var values []string
+	switch kind {
+	case subjectCN:
+		values = []string{cert.Subject.CommonName}
+	case subjectDNS:
+		values = cert.DNSNames
+	case subjectURI:
+		for _, uri := range cert.URIs {
+			values = append(values, uri.String())
+		}
+	case subjectIP:
+		for _, ip := range cert.IPAddresses {
+			values = append(values, ip.String())
+		}
+	case subjectEmail:
+		values = cert.EmailAddresses
+	}
+	for _, value := range values {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func matchMethod(pattern, method string) bool {
+	if pattern == allMethods {
+		return true
+	}
+	ok, _ := path.Match(pattern, method)
+	return ok
+}
+
+// AuthorizeGRPC checks whether the client certificate of the credential is
+// allowed to call the gRPC method in the path of the credential. A certificate
+// matching some policies may only call the methods of them. A certificate
+// matching no policy is allowed unless defaultDeny, so the policies narrow
+// down what cert-allowed-cn allows.
+func (m *Manager) AuthorizeGRPC(cred Credential, defaultDeny bool, now time.Time) error {
+	m.maybeReload(now)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	matched := false
+	if cred.Cert != nil {
+		for _, policy := range m.grpcPolicies {
+			if !policyMatchesCert(policy, cred.Cert) {
+				continue
+			}
+			matched = true
+			for _, method := range policy.Methods {
+				if matchMethod(method, cred.Path) {
+					return nil
+				}
+			}
+		}
+	}
+	if !matched && !defaultDeny {
+		return nil
+	}
+	rbacDeniedCounter.WithLabelValues("grpc-policy").Inc()
+	if cred.Cert == nil {
+		return errors.Annotatef(ErrUnauthenticated, "no client certificate to call %s", cred.Path)
+	}
+	return errors.Annotatef(ErrPermissionDenied, "certificate %s is not allowed to call %s", cred.Cert.Subject.CommonName, cred.Path)
+}
+
+func policyMatchesCert(policy *endpoint.GRPCPolicy, cert *x509.Certificate) bool {
+	for _, subject := range policy.Subjects {
+		if matchSubject(subject, cert) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetGRPCPolicies returns all the gRPC policies.
+func (m *Manager) GetGRPCPolicies() ([]*endpoint.GRPCPolicy, error) {
+	return m.store.LoadAllGRPCPolicies()
+}
+
+// SetGRPCPolicy creates or replaces the gRPC policy.
+func (m *Manager) SetGRPCPolicy(policy *endpoint.GRPCPolicy, now time.Time) error {
+	if err := validateGRPCPolicy(policy); err != nil {
+		return err
+	}
+	policy.UpdatedAt = now.Unix()
+	if err := m.store.SaveGRPCPolicy(policy); err != nil {
+		return err
+	}
+	if err := m.Reload(now); err != nil {
+		return err
+	}
+	log.Info("gRPC policy updated",
+		zap.String("name", policy.Name),
+		zap.Strings("subjects", policy.Subjects),
+		zap.Strings("methods", policy.Methods))
+	return nil
+}
+
+// RemoveGRPCPolicy removes the gRPC policy.
+func (m *Manager) RemoveGRPCPolicy(name string, now time.Time) error {
+	if err := m.store.RemoveGRPCPolicy(name); err != nil {
+		return err
+	}
+	if err := m.Reload(now); err != nil {
+		return err
+	}
+	log.Info("gRPC policy removed", zap.String("name", name))
+	return nil
+}
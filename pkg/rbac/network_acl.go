@@ -0,0 +1,162 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"go.uber.org/zap"
+)
+
+// The listeners of the network ACLs.
+const (
+	ListenerHTTP = "http"
+	ListenerGRPC = "grpc"
+)
+
+// networkACL is a network ACL with the parsed CIDRs.
+type networkACL struct {
+	*endpoint.NetworkACL
+	allow []*net.IPNet
+	deny  []*net.IPNet
+}
+
+func newNetworkACL(acl *endpoint.NetworkACL) (*networkACL, error) {
+	if len(acl.Name) == 0 {
+		return nil, errors.New("name of network ACL should not be empty")
+	}
+	if acl.Listener != "" && acl.Listener != ListenerHTTP && acl.Listener != ListenerGRPC {
+		return nil, errors.Errorf("unknown listener %s of network ACL, should be http or grpc", acl.Listener)
+	}
+	if len(acl.Allow) == 0 && len(acl.Deny) == 0 {
+		return nil, errors.New("network ACL should have at least one allowed or denied CIDR")
+	}
+	res := &networkACL{NetworkACL: acl}
+	var err error
+	if res.allow, err = parseCIDRs(acl.Allow); err != nil {
+		return nil, err
+	}
+	if res.deny, err = parseCIDRs(acl.Deny); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// parseCIDRs parses the CIDRs, where a single IP is also accepted.
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	res := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, errors.Errorf("invalid IP %s", cidr)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			res = append(res, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, errors.Errorf("invalid CIDR %s", cidr)
+		}
+		res = append(res, ipNet)
+	}
+	return res, nil
+}
+
+func containsIP(ipNets []*net.IPNet, ip net.IP) bool {
+	for _, ipNet := range ipNets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckNetworkACL checks whether the source address of the request to the path
+// on the listener is allowed by the network ACLs, where the path is the HTTP
+// path or the full gRPC method. The request should be allowed by all the ACLs
+// of its listener and its API class. The requests from the loopback addresses
+// are always allowed, so the ACLs locking out the operators can be fixed on
+// the PD hosts.
+func (m *Manager) CheckNetworkACL(listener, path, remoteAddr string, now time.Time) error {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	m.maybeReload(now)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, acl := range m.networkACLs {
+		if (acl.Listener != "" && acl.Listener != listener) || !strings.HasPrefix(path, acl.APIClass) {
+			continue
+		}
+		if ip == nil || containsIP(acl.deny, ip) || (len(acl.allow) > 0 && !containsIP(acl.allow, ip)) {
+			rbacDeniedCounter.WithLabelValues("network-acl").Inc()
+			return errors.Annotatef(ErrPermissionDenied, "%s is denied by network ACL %s", host, acl.Name)
+		}
+	}
+	return nil
+}
+
+// GetNetworkACLs returns all the network ACLs.
+func (m *Manager) GetNetworkACLs() ([]*endpoint.NetworkACL, error) {
+	return m.store.LoadAllNetworkACLs()
+}
+
+// SetNetworkACL creates or replaces the network ACL.
+func (m *Manager) SetNetworkACL(acl *endpoint.NetworkACL, now time.Time) error {
+	if _, err := newNetworkACL(acl); err != nil {
+		return err
+	}
+	acl.UpdatedAt = now.Unix()
+	if err := m.store.SaveNetworkACL(acl); err != nil {
+		return err
+	}
+	if err := m.Reload(now); err != nil {
+		return err
+	}
+	log.Info("network ACL updated",
+		zap.String("name", acl.Name),
+		zap.String("listener", acl.Listener),
+		zap.String("api-class", acl.APIClass),
+		zap.Strings("allow", acl.Allow),
+		zap.Strings("deny", acl.Deny))
+	return nil
+}
+
+// RemoveNetworkACL removes the network ACL.
+func (m *Manager) RemoveNetworkACL(name string, now time.Time) error {
+	if err := m.store.RemoveNetworkACL(name); err != nil {
+		return err
+	}
+	if err := m.Reload(now); err != nil {
+		return err
+	}
+	log.Info("network ACL removed", zap.String("name", name))
+	return nil
+}
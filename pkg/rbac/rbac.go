@@ -0,0 +1,330 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/utils/syncutil"
+	"go.uber.org/zap"
+)
+
+// Role is a set of the permissions on the PD APIs. A role covers all the
+// permissions of the lower roles.
+type Role string
+
+// The roles from the lowest.
+const (
+	// RoleViewer can only read, e.g. the monitoring systems.
+	RoleViewer Role = "viewer"
+	// RoleOperator can also change the cluster, e.g. delete stores or add operators.
+	RoleOperator Role = "operator"
+	// RoleAdmin can also change the config of PD and the role bindings.
+	RoleAdmin Role = "admin"
+)
+
+// policyCacheTTL is how long the role bindings are cached. Every PD member
+// authorizes the requests it receives, so the bindings changed by the leader
+// take effect on the followers once the cache expires.
+const policyCacheTTL = 10 * time.Second
+
+var roleLevels = map[Role]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+var (
+	// ErrUnauthenticated is returned when the identity of the request is unknown.
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrPermissionDenied is returned when the role of the identity does not cover the request.
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// IsValid checks whether the role is one of the known roles.
+func (r Role) IsValid() bool {
+	_, ok := roleLevels[r]
+	return ok
+}
+
+// Covers checks whether the role has all the permissions of the required role.
+func (r Role) Covers(required Role) bool {
+	return r.IsValid() && roleLevels[r] >= roleLevels[required]
+}
+
+// Credential is what a request presents to prove its identity.
+type Credential struct {
+	// CN is the common name of the verified client certificate.
+	CN string
+	// Token is the bearer token.
+	Token string
+	// Path is the HTTP path or the full gRPC method of the request, which is
+	// checked by the scopes of the API tokens limited to a path prefix.
+	Path string
+	// Cert is the verified client certificate, which is checked by the gRPC
+	// policies.
+	Cert *x509.Certificate
+}
+
+// Identity is the authenticated identity of a request.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type Identity struct {
+	Name string `json:"name"`
+	// Role is empty if no scope of the API token covers the request.
+	Role Role `json:"role"`
+	// TokenID is the id of the API token, empty if the identity is not authenticated by one.
+	TokenID string `json:"token_id,omitempty"`
+	// SessionID is the id of the admin session, empty if the identity is not
+	// authenticated by a session token.
+	SessionID string `json:"session_id,omitempty"`
+}
+
+// HashToken returns the hex encoded SHA-256 of the token, which is stored
+// instead of the token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Manager authorizes the requests by the role bindings and the API tokens
+// stored in etcd.
+type Manager struct {
+	store endpoint.RBACStorage
+	// adminCN are the CNs always bound to the admin role, so the role bindings
+	// can be managed before any is created.
+	adminCN map[string]struct{}
+	// tokenMaxTTL is the max ttl of the API tokens, no limit if it is zero.
+	tokenMaxTTL time.Duration
+	// sessionTTL is how long the admin sessions last.
+	sessionTTL time.Duration
+	// maxSessions is the max active sessions of an identity, no limit if it is zero.
+	maxSessions int
+
+	// issueMu makes the API tokens and the sessions issued one by one.
+	issueMu  syncutil.Mutex
+	mu       syncutil.RWMutex
+	loadedAt time.Time
+	byCN     map[string]*endpoint.RoleBinding
+	byToken  map[string]*endpoint.RoleBinding
+	// tokens are the records of the API tokens by id.
+	tokens map[string]*endpoint.APIToken
+	// tokenKey is the key signing the API tokens, nil if no token is issued.
+	tokenKey []byte
+	// grpcPolicies are the policies of the gRPC methods.
+	grpcPolicies []*endpoint.GRPCPolicy
+	// networkACLs are the ACLs of the source IPs.
+	networkACLs []*networkACL
+	// sessions are the admin sessions by the hashes of their tokens.
+	sessions map[string]*endpoint.AdminSession
+}
+
+// NewManager creates a Manager with the CNs always bound to the admin role.
+func NewManager(store endpoint.RBACStorage, adminCN []string, tokenMaxTTL, sessionTTL time.Duration, maxSessions int) *Manager {
+	m := &Manager{
+		store:       store,
+		adminCN:     make(map[string]struct{}, len(adminCN)),
+		tokenMaxTTL: tokenMaxTTL,
+		sessionTTL:  sessionTTL,
+		maxSessions: maxSessions,
+		byCN:        make(map[string]*endpoint.RoleBinding),
+		byToken:     make(map[string]*endpoint.RoleBinding),
+		tokens:      make(map[string]*endpoint.APIToken),
+		sessions:    make(map[string]*endpoint.AdminSession),
+	}
+	for _, cn := range adminCN {
+		m.adminCN[cn] = struct{}{}
+	}
+	return m
+}
+
+// Authorize authenticates the credential, and checks whether its role covers
+// the required role.
+func (m *Manager) Authorize(cred Credential, required Role, now time.Time) (*Identity, error) {
+	identity := m.Authenticate(cred, now)
+	if identity == nil {
+		rbacDeniedCounter.WithLabelValues("unauthenticated").Inc()
+		return nil, errors.WithStack(ErrUnauthenticated)
+	}
+	if !identity.Role.Covers(required) {
+		rbacDeniedCounter.WithLabelValues("permission-denied").Inc()
+		return identity, errors.Annotatef(ErrPermissionDenied, "%s with role %s requires role %s", identity.Name, identity.Role, required)
+	}
+	return identity, nil
+}
+
+// Authenticate returns the identity of the credential, nil if it is unknown.
+// The token takes precedence over the certificate.
+func (m *Manager) Authenticate(cred Credential, now time.Time) *Identity {
+	m.maybeReload(now)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if isSignedToken(cred.Token) {
+		return m.verifyToken(cred, now)
+	}
+	if isSessionToken(cred.Token) {
+		return m.verifySession(cred.Token, now)
+	}
+	if len(cred.Token) > 0 {
+		if binding, ok := m.byToken[HashToken(cred.Token)]; ok {
+			return &Identity{Name: binding.Name, Role: Role(binding.Role)}
+		}
+		return nil
+	}
+	if len(cred.CN) == 0 {
+		return nil
+	}
+	if _, ok := m.adminCN[cred.CN]; ok {
+		return &Identity{Name: cred.CN, Role: RoleAdmin}
+	}
+	if binding, ok := m.byCN[cred.CN]; ok {
+		return &Identity{Name: binding.Name, Role: Role(binding.Role)}
+	}
+	return nil
+}
+
+// GetRoleBindings returns all the role bindings.
+func (m *Manager) GetRoleBindings() ([]*endpoint.RoleBinding, error) {
+	return m.store.LoadAllRoleBindings()
+}
+
+// SetRoleBinding creates or replaces the role binding of the identity with
+// the certificate CN or the bearer token.
+func (m *Manager) SetRoleBinding(name string, role Role, cn, token string, now time.Time) (*endpoint.RoleBinding, error) {
+	if len(name) == 0 {
+		return nil, errors.New("name of role binding should not be empty")
+	}
+	if !role.IsValid() {
+		return nil, errors.Errorf("invalid role %s", role)
+	}
+	if (len(cn) == 0) == (len(token) == 0) {
+		return nil, errors.New("role binding should have either a cn or a token")
+	}
+	binding := &endpoint.RoleBinding{
+		Name:      name,
+		Role:      string(role),
+		CN:        cn,
+		CreatedAt: now.Unix(),
+	}
+	if len(token) > 0 {
+		binding.TokenHash = HashToken(token)
+	}
+	if err := m.store.SaveRoleBinding(binding); err != nil {
+		return nil, err
+	}
+	if err := m.Reload(now); err != nil {
+		return nil, err
+	}
+	log.Info("role binding updated", zap.String("name", name), zap.String("role", string(role)), zap.String("cn", cn))
+	return binding, nil
+}
+
+// RemoveRoleBinding removes the role binding.
+func (m *Manager) RemoveRoleBinding(name string, now time.Time) error {
+	if err := m.store.RemoveRoleBinding(name); err != nil {
+		return err
+	}
+	if err := m.Reload(now); err != nil {
+		return err
+	}
+	log.Info("role binding removed", zap.String("name", name))
+	return nil
+}
+
+// Reload loads the role bindings, the API tokens, the gRPC policies, the
+// network ACLs and the admin sessions from the storage.
+func (m *Manager) Reload(now time.Time) error {
+	bindings, err := m.store.LoadAllRoleBindings()
+	if err != nil {
+		return err
+	}
+	tokens, err := m.store.LoadAllAPITokens()
+	if err != nil {
+		return err
+	}
+	encodedKey, err := m.store.LoadAPITokenKey()
+	if err != nil {
+		return err
+	}
+	tokenKey, err := hex.DecodeString(encodedKey)
+	if err != nil {
+		return errors.Annotate(err, "invalid API token key")
+	}
+	grpcPolicies, err := m.store.LoadAllGRPCPolicies()
+	if err != nil {
+		return err
+	}
+	acls, err := m.store.LoadAllNetworkACLs()
+	if err != nil {
+		return err
+	}
+	sessions, err := m.store.LoadAllAdminSessions()
+	if err != nil {
+		return err
+	}
+	networkACLs := make([]*networkACL, 0, len(acls))
+	for _, acl := range acls {
+		parsed, err := newNetworkACL(acl)
+		if err != nil {
+			return errors.Annotatef(err, "invalid network ACL %s", acl.Name)
+		}
+		networkACLs = append(networkACLs, parsed)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.byCN = make(map[string]*endpoint.RoleBinding)
+	m.byToken = make(map[string]*endpoint.RoleBinding)
+	for _, binding := range bindings {
+		if len(binding.CN) > 0 {
+			m.byCN[binding.CN] = binding
+		}
+		if len(binding.TokenHash) > 0 {
+			m.byToken[binding.TokenHash] = binding
+		}
+	}
+	m.tokens = make(map[string]*endpoint.APIToken, len(tokens))
+	for _, token := range tokens {
+		m.tokens[token.ID] = token
+	}
+	m.tokenKey = tokenKey
+	m.grpcPolicies = grpcPolicies
+	m.networkACLs = networkACLs
+	m.sessions = make(map[string]*endpoint.AdminSession, len(sessions))
+	for _, session := range sessions {
+		m.sessions[session.TokenHash] = session
+	}
+	m.loadedAt = now
+	return nil
+}
+
+// maybeReload reloads the role bindings once the cache expires. The cached
+// bindings are kept if it fails.
+func (m *Manager) maybeReload(now time.Time) {
+	m.mu.RLock()
+	expired := now.Sub(m.loadedAt) >= policyCacheTTL
+	m.mu.RUnlock()
+	if !expired {
+		return
+	}
+	if err := m.Reload(now); err != nil {
+		log.Warn("failed to reload role bindings", errs.ZapError(err))
+	}
+}
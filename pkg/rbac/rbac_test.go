@@ -0,0 +1,295 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/storage"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+)
+
+func TestRoleCovers(t *testing.T) {
+	re := require.New(t)
+	re.True(RoleAdmin.Covers(RoleOperator))
+	re.True(RoleOperator.Covers(RoleOperator))
+	re.True(RoleOperator.Covers(RoleViewer))
+	re.False(RoleViewer.Covers(RoleOperator))
+	re.False(Role("root").Covers(RoleViewer))
+}
+
+func TestAuthorize(t *testing.T) {
+	re := require.New(t)
+	now := time.Now()
+	manager := NewManager(storage.NewStorageWithMemoryBackend(), []string{"pd-server"}, 0, 0, 0)
+	// The binding should have either a cn or a token.
+	_, err := manager.SetRoleBinding("monitor", RoleViewer, "", "", now)
+	re.Error(err)
+	_, err = manager.SetRoleBinding("monitor", RoleViewer, "monitor", "secret", now)
+	re.Error(err)
+	_, err = manager.SetRoleBinding("monitor", Role("root"), "", "secret", now)
+	re.Error(err)
+
+	binding, err := manager.SetRoleBinding("monitor", RoleViewer, "", "secret", now)
+	re.NoError(err)
+	re.Equal(HashToken("secret"), binding.TokenHash)
+	_, err = manager.SetRoleBinding("tikv", RoleOperator, "tikv", "", now)
+	re.NoError(err)
+
+	identity, err := manager.Authorize(Credential{Token: "secret"}, RoleViewer, now)
+	re.NoError(err)
+	re.Equal(&Identity{Name: "monitor", Role: RoleViewer}, identity)
+	// The monitor can't delete stores.
+	_, err = manager.Authorize(Credential{Token: "secret"}, RoleOperator, now)
+	re.Equal(ErrPermissionDenied, errors.Cause(err))
+	_, err = manager.Authorize(Credential{Token: "wrong"}, RoleViewer, now)
+	re.Equal(ErrUnauthenticated, errors.Cause(err))
+	_, err = manager.Authorize(Credential{CN: "tikv"}, RoleOperator, now)
+	re.NoError(err)
+	_, err = manager.Authorize(Credential{CN: "tikv"}, RoleAdmin, now)
+	re.Equal(ErrPermissionDenied, errors.Cause(err))
+	// The admin CN is bound to the admin role without a binding.
+	identity, err = manager.Authorize(Credential{CN: "pd-server"}, RoleAdmin, now)
+	re.NoError(err)
+	re.Equal(RoleAdmin, identity.Role)
+	_, err = manager.Authorize(Credential{}, RoleViewer, now)
+	re.Equal(ErrUnauthenticated, errors.Cause(err))
+
+	re.NoError(manager.RemoveRoleBinding("tikv", now))
+	_, err = manager.Authorize(Credential{CN: "tikv"}, RoleViewer, now)
+	re.Equal(ErrUnauthenticated, errors.Cause(err))
+	bindings, err := manager.GetRoleBindings()
+	re.NoError(err)
+	re.Len(bindings, 1)
+
+	// The bindings changed by another member take effect once the cache expires.
+	other := NewManager(manager.store, nil, 0, 0, 0)
+	_, err = other.SetRoleBinding("tikv", RoleOperator, "tikv", "", now)
+	re.NoError(err)
+	_, err = manager.Authorize(Credential{CN: "tikv"}, RoleOperator, now)
+	re.Error(err)
+	_, err = manager.Authorize(Credential{CN: "tikv"}, RoleOperator, now.Add(policyCacheTTL))
+	re.NoError(err)
+}
+
+func TestRequiredRoleForHTTP(t *testing.T) {
+	re := require.New(t)
+	testCases := []struct {
+		method   string
+		path     string
+		expected Role
+	}{
+		{http.MethodGet, "/pd/api/v1/stores", RoleViewer},
+		{http.MethodDelete, "/pd/api/v1/store/1", RoleOperator},
+		{http.MethodPost, "/pd/api/v1/operators", RoleOperator},
+		{http.MethodGet, "/pd/api/v1/config", RoleViewer},
+		{http.MethodPost, "/pd/api/v1/config", RoleAdmin},
+		{http.MethodGet, "/pd/api/v1/rbac/bindings", RoleAdmin},
+		{http.MethodGet, "/pd/api/v1/debug/pprof/profile", RoleAdmin},
+		{http.MethodGet, "/pd/api/v1/rbac/whoami", RoleViewer},
+		{http.MethodGet, "/pd/api/v1/security/certs", RoleViewer},
+		{http.MethodPost, "/pd/api/v1/security/certs/renew", RoleAdmin},
+	}
+	for _, testCase := range testCases {
+		req := httptest.NewRequest(testCase.method, testCase.path, nil)
+		re.Equal(testCase.expected, RequiredRoleForHTTP(req), testCase.path)
+	}
+	re.True(IsPublicHTTP(httptest.NewRequest(http.MethodGet, "/pd/api/v1/health", nil)))
+	re.False(IsPublicHTTP(httptest.NewRequest(http.MethodGet, "/pd/api/v1/stores", nil)))
+
+	req := httptest.NewRequest(http.MethodGet, "/pd/api/v1/stores", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	re.Equal(Credential{Token: "secret", Path: "/pd/api/v1/stores"}, HTTPCredential(req))
+}
+
+func TestAPIToken(t *testing.T) {
+	re := require.New(t)
+	now := time.Now()
+	manager := NewManager(storage.NewStorageWithMemoryBackend(), nil, 24*time.Hour, 0, 0)
+	_, _, err := manager.IssueToken("ci", []string{"root"}, time.Hour, now)
+	re.Error(err)
+	_, _, err = manager.IssueToken("ci", []string{"operator:pd/api/v1/operators"}, time.Hour, now)
+	re.Error(err)
+	_, _, err = manager.IssueToken("ci", []string{string(RoleViewer)}, 48*time.Hour, now)
+	re.Error(err)
+
+	token, record, err := manager.IssueToken("ci", []string{"viewer", "operator:/pd/api/v1/operators"}, time.Hour, now)
+	re.NoError(err)
+	re.Equal(now.Add(time.Hour).Unix(), record.ExpiresAt)
+	identity, err := manager.Authorize(Credential{Token: token, Path: "/pd/api/v1/operators"}, RoleOperator, now)
+	re.NoError(err)
+	re.Equal(&Identity{Name: "ci", Role: RoleOperator, TokenID: record.ID}, identity)
+	// The operator scope is limited to the operators.
+	_, err = manager.Authorize(Credential{Token: token, Path: "/pd/api/v1/store/1"}, RoleOperator, now)
+	re.Equal(ErrPermissionDenied, errors.Cause(err))
+	_, err = manager.Authorize(Credential{Token: token, Path: "/pd/api/v1/stores"}, RoleViewer, now)
+	re.NoError(err)
+	// The tampered or expired token is rejected.
+	_, err = manager.Authorize(Credential{Token: token + "x", Path: "/pd/api/v1/stores"}, RoleViewer, now)
+	re.Equal(ErrUnauthenticated, errors.Cause(err))
+	_, err = manager.Authorize(Credential{Token: token, Path: "/pd/api/v1/stores"}, RoleViewer, now.Add(time.Hour))
+	re.Equal(ErrUnauthenticated, errors.Cause(err))
+
+	// The token verified by another member is revoked once the cache expires.
+	other := NewManager(manager.store, nil, 0, 0, 0)
+	_, err = other.Authorize(Credential{Token: token, Path: "/pd/api/v1/stores"}, RoleViewer, now)
+	re.NoError(err)
+	tokens, err := manager.GetAPITokens(now)
+	re.NoError(err)
+	re.Len(tokens, 1)
+	re.NoError(manager.RevokeToken(record.ID, now))
+	_, err = manager.Authorize(Credential{Token: token, Path: "/pd/api/v1/stores"}, RoleViewer, now)
+	re.Equal(ErrUnauthenticated, errors.Cause(err))
+	_, err = other.Authorize(Credential{Token: token, Path: "/pd/api/v1/stores"}, RoleViewer, now.Add(policyCacheTTL))
+	re.Equal(ErrUnauthenticated, errors.Cause(err))
+}
+
+func TestSession(t *testing.T) {
+	re := require.New(t)
+	now := time.Now()
+	manager := NewManager(storage.NewStorageWithMemoryBackend(), []string{"pd-server"}, 0, time.Hour, 2)
+	_, err := manager.SetRoleBinding("alice", RoleOperator, "alice", "", now)
+	re.NoError(err)
+	_, _, err = manager.Login(Credential{CN: "bob"}, "pd-ctl", "10.0.0.1:1234", now)
+	re.Equal(ErrUnauthenticated, errors.Cause(err))
+
+	token, session, err := manager.Login(Credential{CN: "alice"}, "pd-ctl", "10.0.0.1:1234", now)
+	re.NoError(err)
+	re.Equal(string(RoleOperator), session.Role)
+	re.Equal(now.Add(time.Hour).Unix(), session.ExpiresAt)
+	identity, err := manager.Authorize(Credential{Token: token}, RoleOperator, now)
+	re.NoError(err)
+	re.Equal(&Identity{Name: "alice", Role: RoleOperator, SessionID: session.ID}, identity)
+	// A session can't log in another one.
+	_, _, err = manager.Login(Credential{Token: token}, "pd-ctl", "", now)
+	re.Error(err)
+	// The concurrent sessions of an identity are limited.
+	_, other, err := manager.Login(Credential{CN: "alice"}, "dashboard", "10.0.0.2:1234", now)
+	re.NoError(err)
+	_, _, err = manager.Login(Credential{CN: "alice"}, "pd-ctl", "10.0.0.1:1234", now)
+	re.Equal(ErrTooManySessions, errors.Cause(err))
+	_, _, err = manager.Login(Credential{CN: "pd-server"}, "pd-ctl", "", now)
+	re.NoError(err)
+
+	// The killed session is kept in the revocation list until it expires.
+	re.NoError(manager.KillSession(other.ID, "pd-server", now))
+	_, _, err = manager.Login(Credential{CN: "alice"}, "pd-ctl", "10.0.0.1:1234", now)
+	re.NoError(err)
+	sessions, err := manager.GetSessions(false, now)
+	re.NoError(err)
+	re.Len(sessions, 3)
+	sessions, err = manager.GetSessions(true, now)
+	re.NoError(err)
+	re.Len(sessions, 4)
+	re.Error(manager.KillSession("unknown", "pd-server", now))
+
+	// The session is revoked by logging out, or expires.
+	re.NoError(manager.Logout(token, now))
+	_, err = manager.Authorize(Credential{Token: token}, RoleViewer, now)
+	re.Equal(ErrUnauthenticated, errors.Cause(err))
+	re.Error(manager.Logout(token, now))
+	sessions, err = manager.GetSessions(true, now.Add(time.Hour))
+	re.NoError(err)
+	re.Empty(sessions)
+}
+
+func TestGRPCPolicy(t *testing.T) {
+	re := require.New(t)
+	now := time.Now()
+	manager := NewManager(storage.NewStorageWithMemoryBackend(), nil, 0, 0, 0)
+	re.Error(manager.SetGRPCPolicy(&endpoint.GRPCPolicy{Name: "cdc", Subjects: []string{"cdc"}, Methods: []string{"*"}}, now))
+	re.Error(manager.SetGRPCPolicy(&endpoint.GRPCPolicy{Name: "cdc", Subjects: []string{"sn:cdc"}, Methods: []string{"*"}}, now))
+	re.Error(manager.SetGRPCPolicy(&endpoint.GRPCPolicy{Name: "cdc", Subjects: []string{"cn:cdc"}, Methods: []string{"pdpb.PD/GetRegion"}}, now))
+	re.NoError(manager.SetGRPCPolicy(&endpoint.GRPCPolicy{
+		Name:     "cdc",
+		Subjects: []string{"cn:cdc-*", "dns:*.cdc.svc"},
+		Methods:  []string{"/pdpb.PD/Watch*", "/pdpb.PD/GetMembers"},
+	}, now))
+	re.NoError(manager.SetGRPCPolicy(&endpoint.GRPCPolicy{Name: "tikv", Subjects: []string{"uri:spiffe://cluster/tikv/*"}, Methods: []string{"*"}}, now))
+
+	cdc := &x509.Certificate{Subject: pkix.Name{CommonName: "cdc-0"}}
+	re.NoError(manager.AuthorizeGRPC(Credential{Cert: cdc, Path: "/pdpb.PD/WatchGlobalConfig"}, false, now))
+	err := manager.AuthorizeGRPC(Credential{Cert: cdc, Path: "/pdpb.PD/UpdateGCSafePoint"}, false, now)
+	re.Equal(ErrPermissionDenied, errors.Cause(err))
+	cdc = &x509.Certificate{Subject: pkix.Name{CommonName: "changefeed"}, DNSNames: []string{"cdc-1.cdc.svc"}}
+	re.NoError(manager.AuthorizeGRPC(Credential{Cert: cdc, Path: "/pdpb.PD/GetMembers"}, false, now))
+	tikvURI, err := url.Parse("spiffe://cluster/tikv/tikv-0")
+	re.NoError(err)
+	tikv := &x509.Certificate{Subject: pkix.Name{CommonName: "tikv"}, URIs: []*url.URL{tikvURI}}
+	re.NoError(manager.AuthorizeGRPC(Credential{Cert: tikv, Path: "/pdpb.PD/StoreHeartbeat"}, true, now))
+
+	// The certificates matching no policy are only denied by default-deny.
+	other := &x509.Certificate{Subject: pkix.Name{CommonName: "tidb"}, IPAddresses: []net.IP{net.ParseIP("10.0.0.1")}}
+	re.NoError(manager.AuthorizeGRPC(Credential{Cert: other, Path: "/pdpb.PD/GetRegion"}, false, now))
+	err = manager.AuthorizeGRPC(Credential{Cert: other, Path: "/pdpb.PD/GetRegion"}, true, now)
+	re.Equal(ErrPermissionDenied, errors.Cause(err))
+	err = manager.AuthorizeGRPC(Credential{Path: "/pdpb.PD/GetRegion"}, true, now)
+	re.Equal(ErrUnauthenticated, errors.Cause(err))
+	re.NoError(manager.SetGRPCPolicy(&endpoint.GRPCPolicy{Name: "tidb", Subjects: []string{"ip:10.0.0.*"}, Methods: []string{"/pdpb.PD/GetRegion"}}, now))
+	re.NoError(manager.AuthorizeGRPC(Credential{Cert: other, Path: "/pdpb.PD/GetRegion"}, true, now))
+
+	policies, err := manager.GetGRPCPolicies()
+	re.NoError(err)
+	re.Len(policies, 3)
+	re.NoError(manager.RemoveGRPCPolicy("cdc", now))
+	re.NoError(manager.AuthorizeGRPC(Credential{Cert: cdc, Path: "/pdpb.PD/UpdateGCSafePoint"}, false, now))
+}
+
+func TestNetworkACL(t *testing.T) {
+	re := require.New(t)
+	now := time.Now()
+	manager := NewManager(storage.NewStorageWithMemoryBackend(), nil, 0, 0, 0)
+	re.Error(manager.SetNetworkACL(&endpoint.NetworkACL{Name: "admin"}, now))
+	re.Error(manager.SetNetworkACL(&endpoint.NetworkACL{Name: "admin", Listener: "peer", Allow: []string{"10.0.0.0/8"}}, now))
+	re.Error(manager.SetNetworkACL(&endpoint.NetworkACL{Name: "admin", Allow: []string{"10.0.0.0/33"}}, now))
+	re.NoError(manager.SetNetworkACL(&endpoint.NetworkACL{
+		Name:     "admin",
+		Listener: ListenerHTTP,
+		APIClass: "/pd/api/v1/admin",
+		Allow:    []string{"10.0.0.0/8"},
+		Deny:     []string{"10.0.0.1"},
+	}, now))
+	re.NoError(manager.SetNetworkACL(&endpoint.NetworkACL{Name: "blocked", Deny: []string{"192.168.0.0/16", "fd00::/8"}}, now))
+
+	re.NoError(manager.CheckNetworkACL(ListenerHTTP, "/pd/api/v1/admin/cache/regions", "10.0.0.2:1234", now))
+	err := manager.CheckNetworkACL(ListenerHTTP, "/pd/api/v1/admin/cache/regions", "10.0.0.1:1234", now)
+	re.Equal(ErrPermissionDenied, errors.Cause(err))
+	err = manager.CheckNetworkACL(ListenerHTTP, "/pd/api/v1/admin/cache/regions", "172.16.0.1:1234", now)
+	re.Equal(ErrPermissionDenied, errors.Cause(err))
+	// The ACL is limited to the listener and the API class.
+	re.NoError(manager.CheckNetworkACL(ListenerHTTP, "/pd/api/v1/stores", "172.16.0.1:1234", now))
+	re.NoError(manager.CheckNetworkACL(ListenerGRPC, "/pd/api/v1/admin/cache/regions", "172.16.0.1:1234", now))
+	err = manager.CheckNetworkACL(ListenerGRPC, "/pdpb.PD/GetRegion", "192.168.1.1:1234", now)
+	re.Equal(ErrPermissionDenied, errors.Cause(err))
+	err = manager.CheckNetworkACL(ListenerGRPC, "/pdpb.PD/GetRegion", "[fd00::1]:1234", now)
+	re.Equal(ErrPermissionDenied, errors.Cause(err))
+	// The loopback addresses are always allowed.
+	re.NoError(manager.CheckNetworkACL(ListenerHTTP, "/pd/api/v1/admin/cache/regions", "127.0.0.1:1234", now))
+
+	acls, err := manager.GetNetworkACLs()
+	re.NoError(err)
+	re.Len(acls, 2)
+	re.NoError(manager.RemoveNetworkACL("blocked", now))
+	re.NoError(manager.CheckNetworkACL(ListenerGRPC, "/pdpb.PD/GetRegion", "192.168.1.1:1234", now))
+}
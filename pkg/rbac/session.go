@@ -0,0 +1,214 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"go.uber.org/zap"
+)
+
+const (
+	// sessionTokenPrefix is the prefix of the session tokens, which tells them
+	// from the tokens bound to a role and the API tokens.
+	sessionTokenPrefix = "pds_"
+	sessionIDLength    = 16
+	sessionTokenLength = 32
+)
+
+// ErrTooManySessions is returned when the identity has too many active sessions.
+var ErrTooManySessions = errors.New("too many active sessions")
+
+// isSessionToken checks whether the bearer token is a session token.
+func isSessionToken(token string) bool {
+	return strings.HasPrefix(token, sessionTokenPrefix)
+}
+
+// isActiveSession checks whether the session is neither expired nor revoked.
+func isActiveSession(session *endpoint.AdminSession, now time.Time) bool {
+	return session.RevokedAt == 0 && session.ExpiresAt > now.Unix()
+}
+
+// verifySession returns the identity of the session token, nil if the session
+// is unknown, expired or revoked. Require mu to be held.
+func (m *Manager) verifySession(token string, now time.Time) *Identity {
+	session, ok := m.sessions[HashToken(token)]
+	if !ok || !isActiveSession(session, now) {
+		return nil
+	}
+	return &Identity{Name: session.Name, Role: Role(session.Role), SessionID: session.ID}
+}
+
+// Login creates a session for the identity of the credential, which is either
+// a client certificate or a token bound to a role. The session has the role of
+// the identity when it logs in, and expires after the session ttl. The session
+// token is returned only once, PD only keeps its hash.
+func (m *Manager) Login(cred Credential, client, remoteAddr string, now time.Time) (string, *endpoint.AdminSession, error) {
+	if isSignedToken(cred.Token) || isSessionToken(cred.Token) {
+		return "", nil, errors.New("session should be logged in by a client certificate or a token bound to a role")
+	}
+	identity := m.Authenticate(cred, now)
+	if identity == nil {
+		rbacDeniedCounter.WithLabelValues("unauthenticated").Inc()
+		return "", nil, errors.WithStack(ErrUnauthenticated)
+	}
+	if m.sessionTTL <= 0 {
+		return "", nil, errors.Errorf("invalid session ttl %s", m.sessionTTL)
+	}
+	m.issueMu.Lock()
+	defer m.issueMu.Unlock()
+	if err := m.removeExpiredSessions(now); err != nil {
+		return "", nil, err
+	}
+	sessions, err := m.store.LoadAllAdminSessions()
+	if err != nil {
+		return "", nil, err
+	}
+	active := 0
+	for _, session := range sessions {
+		if session.Name == identity.Name && isActiveSession(session, now) {
+			active++
+		}
+	}
+	if m.maxSessions > 0 && active >= m.maxSessions {
+		rbacDeniedCounter.WithLabelValues("too-many-sessions").Inc()
+		return "", nil, errors.Annotatef(ErrTooManySessions, "%s has %d active sessions, the limit is %d", identity.Name, active, m.maxSessions)
+	}
+	id, err := randomHex(sessionIDLength)
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := randomHex(sessionTokenLength)
+	if err != nil {
+		return "", nil, err
+	}
+	token := sessionTokenPrefix + secret
+	session := &endpoint.AdminSession{
+		ID:         id,
+		Name:       identity.Name,
+		Role:       string(identity.Role),
+		Client:     client,
+		RemoteAddr: remoteAddr,
+		TokenHash:  HashToken(token),
+		CreatedAt:  now.Unix(),
+		ExpiresAt:  now.Add(m.sessionTTL).Unix(),
+	}
+	if err := m.store.SaveAdminSession(session); err != nil {
+		return "", nil, err
+	}
+	if err := m.Reload(now); err != nil {
+		return "", nil, err
+	}
+	log.Info("admin session logged in",
+		zap.String("id", session.ID),
+		zap.String("name", session.Name),
+		zap.String("role", session.Role),
+		zap.String("client", client),
+		zap.String("remote-addr", remoteAddr),
+		zap.Int64("expires-at", session.ExpiresAt))
+	return token, session, nil
+}
+
+// Logout revokes the session of the session token.
+func (m *Manager) Logout(token string, now time.Time) error {
+	if !isSessionToken(token) {
+		return errors.WithStack(ErrUnauthenticated)
+	}
+	m.maybeReload(now)
+	m.mu.RLock()
+	identity := m.verifySession(token, now)
+	m.mu.RUnlock()
+	if identity == nil {
+		return errors.WithStack(ErrUnauthenticated)
+	}
+	return m.KillSession(identity.SessionID, identity.Name, now)
+}
+
+// GetSessions returns the unexpired sessions from the earliest created. The
+// revoked ones are included only if it is required.
+func (m *Manager) GetSessions(includeRevoked bool, now time.Time) ([]*endpoint.AdminSession, error) {
+	sessions, err := m.store.LoadAllAdminSessions()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*endpoint.AdminSession, 0, len(sessions))
+	for _, session := range sessions {
+		if session.ExpiresAt <= now.Unix() || (session.RevokedAt > 0 && !includeRevoked) {
+			continue
+		}
+		res = append(res, session)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].CreatedAt < res[j].CreatedAt })
+	return res, nil
+}
+
+// KillSession revokes the session. The record is kept until the session
+// expires, so the revocation is visible.
+func (m *Manager) KillSession(id, revokedBy string, now time.Time) error {
+	m.issueMu.Lock()
+	defer m.issueMu.Unlock()
+	sessions, err := m.store.LoadAllAdminSessions()
+	if err != nil {
+		return err
+	}
+	var session *endpoint.AdminSession
+	for _, s := range sessions {
+		if s.ID == id {
+			session = s
+			break
+		}
+	}
+	if session == nil || session.ExpiresAt <= now.Unix() {
+		return errors.Errorf("admin session %s not found", id)
+	}
+	if session.RevokedAt > 0 {
+		return nil
+	}
+	session.RevokedAt, session.RevokedBy = now.Unix(), revokedBy
+	if err := m.store.SaveAdminSession(session); err != nil {
+		return err
+	}
+	if err := m.Reload(now); err != nil {
+		return err
+	}
+	log.Info("admin session revoked",
+		zap.String("id", id),
+		zap.String("name", session.Name),
+		zap.String("revoked-by", revokedBy))
+	return nil
+}
+
+// removeExpiredSessions removes the expired sessions, including the revoked
+// ones. Require issueMu to be held.
+func (m *Manager) removeExpiredSessions(now time.Time) error {
+	sessions, err := m.store.LoadAllAdminSessions()
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if session.ExpiresAt > now.Unix() {
+			continue
+		}
+		if err := m.store.RemoveAdminSession(session.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,268 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"go.uber.org/zap"
+)
+
+const (
+	// tokenIssuer is the issuer of the API tokens signed by PD.
+	tokenIssuer = "pd"
+	// tokenKeyLength is the length of the HMAC key signing the API tokens.
+	tokenKeyLength = 32
+	tokenIDLength  = 16
+)
+
+// tokenHeader is the encoded JWT header of the API tokens, which are signed by
+// HMAC SHA-256.
+var tokenHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// tokenClaims are the JWT claims of an API token.
+type tokenClaims struct {
+	ID        string `json:"jti"`
+	Subject   string `json:"sub"`
+	Issuer    string `json:"iss"`
+	Scope     string `json:"scope"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// parseScope parses a scope in the form of "{role}" or "{role}:{path-prefix}".
+func parseScope(scope string) (role Role, prefix string, err error) {
+	parts := strings.SplitN(scope, ":", 2)
+	role = Role(parts[0])
+	if !role.IsValid() {
+		return "", "", errors.Errorf("invalid role %s in scope %s", parts[0], scope)
+	}
+	if len(parts) == 2 {
+		prefix = parts[1]
+		if !strings.HasPrefix(prefix, "/") {
+			return "", "", errors.Errorf("path prefix of scope %s should start with /", scope)
+		}
+	}
+	return role, prefix, nil
+}
+
+// scopeRole returns the highest role of the scopes which cover the path, which
+// is the HTTP path or the full gRPC method, e.g. "/pdpb.PD/ScatterRegion".
+func scopeRole(scopes []string, path string) Role {
+	var res Role
+	for _, scope := range scopes {
+		role, prefix, err := parseScope(scope)
+		if err != nil || !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		if !res.IsValid() || roleLevels[role] > roleLevels[res] {
+			res = role
+		}
+	}
+	return res
+}
+
+// isSignedToken checks whether the bearer token is a JWT instead of a token
+// bound to a role.
+func isSignedToken(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+func signToken(key []byte, unsigned string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verifyToken returns the identity of the signed token, nil if the token is
+// invalid, expired or revoked. Require mu to be held.
+func (m *Manager) verifyToken(cred Credential, now time.Time) *Identity {
+	if len(m.tokenKey) == 0 {
+		return nil
+	}
+	parts := strings.Split(cred.Token, ".")
+	if parts[0] != tokenHeader {
+		return nil
+	}
+	signature := signToken(m.tokenKey, parts[0]+"."+parts[1])
+	if !hmac.Equal([]byte(signature), []byte(parts[2])) {
+		return nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil
+	}
+	claims := &tokenClaims{}
+	if err := json.Unmarshal(data, claims); err != nil || claims.Issuer != tokenIssuer {
+		return nil
+	}
+	// The record is removed once the token is revoked.
+	token, ok := m.tokens[claims.ID]
+	if !ok || token.ExpiresAt <= now.Unix() || claims.ExpiresAt <= now.Unix() {
+		return nil
+	}
+	return &Identity{Name: token.Name, Role: scopeRole(token.Scopes, cred.Path), TokenID: token.ID}
+}
+
+// IssueToken issues an API token with the scopes, which expires after the ttl.
+// The token itself is returned only once, PD only keeps its record.
+func (m *Manager) IssueToken(name string, scopes []string, ttl time.Duration, now time.Time) (string, *endpoint.APIToken, error) {
+	if len(name) == 0 {
+		return "", nil, errors.New("name of API token should not be empty")
+	}
+	if len(scopes) == 0 {
+		return "", nil, errors.New("API token should have at least one scope")
+	}
+	for _, scope := range scopes {
+		if _, _, err := parseScope(scope); err != nil {
+			return "", nil, err
+		}
+	}
+	if ttl <= 0 || (m.tokenMaxTTL > 0 && ttl > m.tokenMaxTTL) {
+		return "", nil, errors.Errorf("invalid ttl %s of API token, should be positive and not exceed %s", ttl, m.tokenMaxTTL)
+	}
+	m.issueMu.Lock()
+	defer m.issueMu.Unlock()
+	key, err := m.loadOrCreateTokenKey()
+	if err != nil {
+		return "", nil, err
+	}
+	id, err := randomHex(tokenIDLength)
+	if err != nil {
+		return "", nil, err
+	}
+	record := &endpoint.APIToken{
+		ID:        id,
+		Name:      name,
+		Scopes:    scopes,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	}
+	claims, err := json.Marshal(&tokenClaims{
+		ID:        record.ID,
+		Subject:   record.Name,
+		Issuer:    tokenIssuer,
+		Scope:     strings.Join(scopes, " "),
+		IssuedAt:  record.IssuedAt,
+		ExpiresAt: record.ExpiresAt,
+	})
+	if err != nil {
+		return "", nil, errs.ErrJSONMarshal.Wrap(err).GenWithStackByCause()
+	}
+	if err := m.removeExpiredTokens(now); err != nil {
+		return "", nil, err
+	}
+	if err := m.store.SaveAPIToken(record); err != nil {
+		return "", nil, err
+	}
+	if err := m.Reload(now); err != nil {
+		return "", nil, err
+	}
+	unsigned := tokenHeader + "." + base64.RawURLEncoding.EncodeToString(claims)
+	log.Info("API token issued",
+		zap.String("id", record.ID),
+		zap.String("name", name),
+		zap.Strings("scopes", scopes),
+		zap.Int64("expires-at", record.ExpiresAt))
+	return unsigned + "." + signToken(key, unsigned), record, nil
+}
+
+// GetAPITokens returns the records of the unexpired API tokens, from the
+// earliest issued.
+func (m *Manager) GetAPITokens(now time.Time) ([]*endpoint.APIToken, error) {
+	tokens, err := m.store.LoadAllAPITokens()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*endpoint.APIToken, 0, len(tokens))
+	for _, token := range tokens {
+		if token.ExpiresAt > now.Unix() {
+			res = append(res, token)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].IssuedAt < res[j].IssuedAt })
+	return res, nil
+}
+
+// RevokeToken revokes the API token by removing its record.
+func (m *Manager) RevokeToken(id string, now time.Time) error {
+	if err := m.store.RemoveAPIToken(id); err != nil {
+		return err
+	}
+	if err := m.Reload(now); err != nil {
+		return err
+	}
+	log.Info("API token revoked", zap.String("id", id))
+	return nil
+}
+
+func (m *Manager) removeExpiredTokens(now time.Time) error {
+	tokens, err := m.store.LoadAllAPITokens()
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if token.ExpiresAt > now.Unix() {
+			continue
+		}
+		if err := m.store.RemoveAPIToken(token.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadOrCreateTokenKey loads the key signing the API tokens, which is created
+// when the first token is issued. Require issueMu to be held.
+func (m *Manager) loadOrCreateTokenKey() ([]byte, error) {
+	encoded, err := m.store.LoadAPITokenKey()
+	if err != nil {
+		return nil, err
+	}
+	if len(encoded) == 0 {
+		if encoded, err = randomHex(tokenKeyLength); err != nil {
+			return nil, err
+		}
+		if err := m.store.SaveAPITokenKey(encoded); err != nil {
+			return nil, err
+		}
+	}
+	key, err := hex.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Annotate(err, "invalid API token key")
+	}
+	return key, nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoint
+
+import (
+	"encoding/json"
+
+	"github.com/tikv/pd/pkg/errs"
+)
+
+// ElectionHistoryStorage defines the storage operations on the election events.
+// The events are keyed by the unix nano timestamps they happened at.
+type ElectionHistoryStorage interface {
+	SaveElectionEvent(ts int64, event interface{}) error
+	LoadElectionEvents(start, end int64, limit int, f func(value []byte) error) error
+	RemoveElectionEventsBefore(ts int64) error
+}
+
+var _ ElectionHistoryStorage = (*StorageEndpoint)(nil)
+
+// SaveElectionEvent stores the marshallable election event happened at ts.
+func (se *StorageEndpoint) SaveElectionEvent(ts int64, event interface{}) error {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return errs.ErrJSONMarshal.Wrap(err).GenWithStackByCause()
+	}
+	return se.Save(ElectionEventPath(ts), string(value))
+}
+
+// LoadElectionEvents loads at most limit election events happened in [start, end),
+// in the order of time. There is no limit if limit is 0.
+func (se *StorageEndpoint) LoadElectionEvents(start, end int64, limit int, f func(value []byte) error) error {
+	_, values, err := se.LoadRange(ElectionEventPath(start), ElectionEventPath(end), limit)
+	if err != nil {
+		return err
+	}
+	for _, value := range values {
+		if err := f([]byte(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveElectionEventsBefore removes the election events happened before ts.
+func (se *StorageEndpoint) RemoveElectionEventsBefore(ts int64) error {
+	keys, _, err := se.LoadRange(ElectionEventPath(0), ElectionEventPath(ts), 0)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := se.Remove(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -35,17 +35,39 @@ type KeyspaceGCSafePoint struct {
 	SafePoint uint64 `json:"safe_point,omitempty"`
 }
 
+// GCBarrier blocks the GC of a key-space, or a key range of it, from passing
+// its safepoint until it is removed or expired.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type GCBarrier struct {
+	BarrierID string `json:"barrier_id"`
+	SafePoint uint64 `json:"safe_point"`
+	// StartKey and EndKey are the hex encoded key range in the key-space blocked
+	// by the barrier, the whole key-space is blocked if they are empty.
+	StartKey string `json:"start_key,omitempty"`
+	EndKey   string `json:"end_key,omitempty"`
+	// ExpiredAt is the unix timestamp in seconds when the barrier expires,
+	// math.MaxInt64 means it never expires.
+	ExpiredAt int64 `json:"expired_at"`
+	CreatedAt int64 `json:"created_at"`
+}
+
 // KeyspaceGCSafePointStorage defines the storage operations on Keyspaces' safe points
 type KeyspaceGCSafePointStorage interface {
 	// Service safe point interfaces.
 	SaveServiceSafePoint(spaceID string, ssp *ServiceSafePoint) error
 	LoadServiceSafePoint(spaceID, serviceID string) (*ServiceSafePoint, error)
 	LoadMinServiceSafePoint(spaceID string, now time.Time) (*ServiceSafePoint, error)
+	LoadAllServiceSafePoints(spaceID string, now time.Time) ([]*ServiceSafePoint, error)
 	RemoveServiceSafePoint(spaceID, serviceID string) error
 	// GC safe point interfaces.
 	SaveKeyspaceGCSafePoint(spaceID string, safePoint uint64) error
 	LoadKeyspaceGCSafePoint(spaceID string) (uint64, error)
 	LoadAllKeyspaceGCSafePoints(withGCSafePoint bool) ([]*KeyspaceGCSafePoint, error)
+	// GC barrier interfaces.
+	SaveGCBarrier(spaceID string, barrier *GCBarrier) error
+	LoadGCBarrier(spaceID, barrierID string) (*GCBarrier, error)
+	LoadAllGCBarriers(spaceID string) ([]*GCBarrier, error)
+	RemoveGCBarrier(spaceID, barrierID string) error
 }
 
 var _ KeyspaceGCSafePointStorage = (*StorageEndpoint)(nil)
@@ -139,6 +161,28 @@ func (se *StorageEndpoint) LoadMinServiceSafePoint(spaceID string, now time.Time
 	return min, nil
 }
 
+// LoadAllServiceSafePoints returns all the unexpired service safe points of the given key-space.
+func (se *StorageEndpoint) LoadAllServiceSafePoints(spaceID string, now time.Time) ([]*ServiceSafePoint, error) {
+	prefix := KeyspaceServiceSafePointPrefix(spaceID)
+	prefixEnd := clientv3.GetPrefixRangeEnd(prefix)
+	_, values, err := se.LoadRange(prefix, prefixEnd, 0)
+	if err != nil {
+		return nil, err
+	}
+	ssps := make([]*ServiceSafePoint, 0, len(values))
+	for _, value := range values {
+		ssp := &ServiceSafePoint{}
+		if err := json.Unmarshal([]byte(value), ssp); err != nil {
+			return nil, err
+		}
+		if ssp.ExpiredAt < now.Unix() {
+			continue
+		}
+		ssps = append(ssps, ssp)
+	}
+	return ssps, nil
+}
+
 // RemoveServiceSafePoint removes target ServiceSafePoint
 func (se *StorageEndpoint) RemoveServiceSafePoint(spaceID, serviceID string) error {
 	key := KeyspaceServiceSafePointPath(spaceID, serviceID)
@@ -196,3 +240,52 @@ func (se *StorageEndpoint) LoadAllKeyspaceGCSafePoints(withGCSafePoint bool) ([]
 	}
 	return safePoints, nil
 }
+
+// SaveGCBarrier saves the GC barrier of the given key-space.
+func (se *StorageEndpoint) SaveGCBarrier(spaceID string, barrier *GCBarrier) error {
+	if barrier.BarrierID == "" {
+		return errors.New("id of GC barrier cannot be empty")
+	}
+	value, err := json.Marshal(barrier)
+	if err != nil {
+		return errs.ErrJSONMarshal.Wrap(err).GenWithStackByCause()
+	}
+	return se.Save(KeyspaceGCBarrierPath(spaceID, barrier.BarrierID), string(value))
+}
+
+// LoadGCBarrier reads the GC barrier of the given key-space, nil if it does not exist.
+func (se *StorageEndpoint) LoadGCBarrier(spaceID, barrierID string) (*GCBarrier, error) {
+	value, err := se.Load(KeyspaceGCBarrierPath(spaceID, barrierID))
+	if err != nil || value == "" {
+		return nil, err
+	}
+	barrier := &GCBarrier{}
+	if err := json.Unmarshal([]byte(value), barrier); err != nil {
+		return nil, errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+	}
+	return barrier, nil
+}
+
+// LoadAllGCBarriers returns all the GC barriers of the given key-space, including the expired ones.
+func (se *StorageEndpoint) LoadAllGCBarriers(spaceID string) ([]*GCBarrier, error) {
+	prefix := KeyspaceGCBarrierPrefix(spaceID)
+	prefixEnd := clientv3.GetPrefixRangeEnd(prefix)
+	_, values, err := se.LoadRange(prefix, prefixEnd, 0)
+	if err != nil {
+		return nil, err
+	}
+	barriers := make([]*GCBarrier, 0, len(values))
+	for _, value := range values {
+		barrier := &GCBarrier{}
+		if err := json.Unmarshal([]byte(value), barrier); err != nil {
+			return nil, errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+		}
+		barriers = append(barriers, barrier)
+	}
+	return barriers, nil
+}
+
+// RemoveGCBarrier removes the GC barrier of the given key-space.
+func (se *StorageEndpoint) RemoveGCBarrier(spaceID, barrierID string) error {
+	return se.Remove(KeyspaceGCBarrierPath(spaceID, barrierID))
+}
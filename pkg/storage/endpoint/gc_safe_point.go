@@ -32,6 +32,37 @@ type ServiceSafePoint struct {
 	ServiceID string `json:"service_id"`
 	ExpiredAt int64  `json:"expired_at"`
 	SafePoint uint64 `json:"safe_point"`
+	// Owner is the holder of the lease, which must be the same to renew or
+	// release it. It's empty if the safepoint is not leased.
+	Owner string `json:"owner,omitempty"`
+	// LeaseTTL is the seconds the lease lasts after each renewal.
+	LeaseTTL int64 `json:"lease_ttl,omitempty"`
+	// CreatedAt and RenewedAt are the unix timestamps in seconds when the lease
+	// is acquired and renewed.
+	CreatedAt int64 `json:"created_at,omitempty"`
+	RenewedAt int64 `json:"renewed_at,omitempty"`
+}
+
+// IsLeased returns whether the safepoint is held by a lease.
+func (ssp *ServiceSafePoint) IsLeased() bool {
+	return len(ssp.Owner) > 0
+}
+
+// GCPause pauses the advancement of the GC safepoint until it ends.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type GCPause struct {
+	Reason string `json:"reason"`
+	// StartedAt and EndAt are the unix timestamps in seconds when the pause
+	// starts and ends, math.MaxInt64 means it never ends.
+	StartedAt int64 `json:"started_at"`
+	EndAt     int64 `json:"end_at"`
+	// Forced means the pause is longer than the max pause duration.
+	Forced bool `json:"forced,omitempty"`
+}
+
+// IsActive returns whether the pause has not ended.
+func (p *GCPause) IsActive(now time.Time) bool {
+	return p.EndAt > now.Unix()
 }
 
 // GCSafePointStorage defines the storage operations on the GC safe point.
@@ -40,8 +71,12 @@ type GCSafePointStorage interface {
 	SaveGCSafePoint(safePoint uint64) error
 	LoadMinServiceGCSafePoint(now time.Time) (*ServiceSafePoint, error)
 	LoadAllServiceGCSafePoints() ([]*ServiceSafePoint, error)
+	LoadServiceGCSafePoint(serviceID string) (*ServiceSafePoint, error)
 	SaveServiceGCSafePoint(ssp *ServiceSafePoint) error
 	RemoveServiceGCSafePoint(serviceID string) error
+	LoadGCPause() (*GCPause, error)
+	SaveGCPause(pause *GCPause) error
+	RemoveGCPause() error
 }
 
 var _ GCSafePointStorage = (*StorageEndpoint)(nil)
@@ -159,6 +194,20 @@ func (se *StorageEndpoint) LoadAllServiceGCSafePoints() ([]*ServiceSafePoint, er
 	return ssps, nil
 }
 
+// LoadServiceGCSafePoint returns the GC safepoint of the service, nil if it
+// does not exist.
+func (se *StorageEndpoint) LoadServiceGCSafePoint(serviceID string) (*ServiceSafePoint, error) {
+	value, err := se.Load(gcSafePointServicePath(serviceID))
+	if err != nil || value == "" {
+		return nil, err
+	}
+	ssp := &ServiceSafePoint{}
+	if err := json.Unmarshal([]byte(value), ssp); err != nil {
+		return nil, errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+	}
+	return ssp, nil
+}
+
 // SaveServiceGCSafePoint saves a GC safepoint for the service
 func (se *StorageEndpoint) SaveServiceGCSafePoint(ssp *ServiceSafePoint) error {
 	if ssp.ServiceID == "" {
@@ -186,3 +235,30 @@ func (se *StorageEndpoint) RemoveServiceGCSafePoint(serviceID string) error {
 	key := gcSafePointServicePath(serviceID)
 	return se.Remove(key)
 }
+
+// LoadGCPause loads the pause of the GC safepoint, nil if it does not exist.
+func (se *StorageEndpoint) LoadGCPause() (*GCPause, error) {
+	value, err := se.Load(gcPausePath())
+	if err != nil || value == "" {
+		return nil, err
+	}
+	pause := &GCPause{}
+	if err := json.Unmarshal([]byte(value), pause); err != nil {
+		return nil, errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+	}
+	return pause, nil
+}
+
+// SaveGCPause saves the pause of the GC safepoint.
+func (se *StorageEndpoint) SaveGCPause(pause *GCPause) error {
+	value, err := json.Marshal(pause)
+	if err != nil {
+		return errs.ErrJSONMarshal.Wrap(err).GenWithStackByCause()
+	}
+	return se.Save(gcPausePath(), string(value))
+}
+
+// RemoveGCPause removes the pause of the GC safepoint.
+func (se *StorageEndpoint) RemoveGCPause() error {
+	return se.Remove(gcPausePath())
+}
@@ -32,19 +32,42 @@ const (
 	regionLabelPath            = "region_label"
 	replicationPath            = "replication_mode"
 	customScheduleConfigPath   = "scheduler_config"
+	schedulingHandoffPath      = "scheduling_handoff"
+	electionHistoryPath        = "election_history"
 	gcWorkerServiceSafePointID = "gc_worker"
 	minResolvedTS              = "min_resolved_ts"
 	externalTimeStamp          = "external_timestamp"
 	keyspaceSafePointPrefix    = "keyspaces/gc_safepoint"
 	keyspaceGCSafePointSuffix  = "gc"
+	keyspaceGCBarrierPrefix    = "keyspaces/gc_barrier"
 	keyspacePrefix             = "keyspaces"
 	keyspaceMetaInfix          = "meta"
 	keyspaceIDInfix            = "id"
+	keyspaceQuotaInfix         = "quota"
+	keyspaceAliasInfix         = "alias"
+	keyspacePlacementInfix     = "placement"
 	keyspaceAllocID            = "alloc_id"
+	rbacBindingPrefix          = "rbac/binding"
+	rbacTokenPrefix            = "rbac/token"
+	rbacTokenKeyPath           = "rbac/token_key"
+	rbacGRPCPolicyPrefix       = "rbac/grpc_policy"
+	rbacNetworkACLPrefix       = "rbac/network_acl"
+	rbacSessionPrefix          = "rbac/session"
+	webhookPrefix              = "webhook"
 	regionPathPrefix           = "raft/r"
 	// resource group storage endpoint has prefix `resource_group`
 	resourceGroupSettingsPath = "settings"
 	resourceGroupStatesPath   = "states"
+	// resourceGroupExtendedSettingsPath is the path of the settings only known by the server.
+	resourceGroupExtendedSettingsPath = "extended_settings"
+	// resourceManagerSettingsPath is the path of the cluster-level settings of the resource manager.
+	resourceManagerSettingsPath = "manager_settings"
+	// resourceGroupConsumptionHistoryPath is the path of the per-minute RU consumption of the resource groups.
+	resourceGroupConsumptionHistoryPath = "consumption_history"
+	// runawayPath is the path of the runaway query rules, which is watched by the clients.
+	runawayPath          = "runaway"
+	runawaySettingsInfix = "settings"
+	runawayWatchesInfix  = "watches"
 	// tso storage endpoint has prefix `tso`
 	microserviceKey = "microservice"
 	tsoServiceKey   = "tso"
@@ -121,6 +144,31 @@ func resourceGroupStateKeyPath(groupName string) string {
 	return path.Join(resourceGroupStatesPath, groupName)
 }
 
+func resourceGroupExtendedSettingsKeyPath(groupName string) string {
+	return path.Join(resourceGroupExtendedSettingsPath, groupName)
+}
+
+func resourceManagerSettingsKeyPath(name string) string {
+	return path.Join(resourceManagerSettingsPath, name)
+}
+
+func runawaySettingsKeyPath(groupName string) string {
+	return path.Join(runawayPath, runawaySettingsInfix, groupName)
+}
+
+func runawayWatchKeyPath(id uint64) string {
+	return path.Join(runawayPath, runawayWatchesInfix, fmt.Sprintf("%020d", id))
+}
+
+func resourceGroupConsumptionHistoryKeyPath(groupName string, minute int64) string {
+	return path.Join(resourceGroupConsumptionHistoryPath, groupName, fmt.Sprintf("%020d", minute))
+}
+
+// ElectionEventPath returns the path to save the election event happened at the given unix nano timestamp.
+func ElectionEventPath(ts int64) string {
+	return path.Join(electionHistoryPath, fmt.Sprintf("%020d", ts))
+}
+
 func ruleKeyPath(ruleKey string) string {
 	return path.Join(rulesPath, ruleKey)
 }
@@ -141,6 +189,10 @@ func gcSafePointPath() string {
 	return path.Join(gcPath, "safe_point")
 }
 
+func gcPausePath() string {
+	return path.Join(gcPath, "pause")
+}
+
 // GCSafePointServicePrefixPath returns the GC safe point service key path prefix.
 func GCSafePointServicePrefixPath() string {
 	return path.Join(gcSafePointPath(), "service") + "/"
@@ -178,6 +230,96 @@ func KeyspaceServiceSafePointPath(spaceID, serviceID string) string {
 	return path.Join(KeyspaceServiceSafePointPrefix(spaceID), serviceID)
 }
 
+// KeyspaceGCBarrierPrefix returns the prefix of the GC barriers of the given key-space.
+// Prefix: /keyspaces/gc_barrier/{space_id}/
+func KeyspaceGCBarrierPrefix(spaceID string) string {
+	return path.Join(keyspaceGCBarrierPrefix, spaceID) + "/"
+}
+
+// KeyspaceGCBarrierPath returns the path of the given GC barrier.
+// Path: /keyspaces/gc_barrier/{space_id}/{barrier_id}
+func KeyspaceGCBarrierPath(spaceID, barrierID string) string {
+	return path.Join(KeyspaceGCBarrierPrefix(spaceID), barrierID)
+}
+
+// RBACBindingPrefix returns the prefix of the role bindings.
+// Prefix: /rbac/binding/
+func RBACBindingPrefix() string {
+	return rbacBindingPrefix + "/"
+}
+
+// RBACBindingPath returns the path of the given role binding.
+// Path: /rbac/binding/{name}
+func RBACBindingPath(name string) string {
+	return path.Join(rbacBindingPrefix, name)
+}
+
+// RBACTokenPrefix returns the prefix of the issued API tokens.
+// Prefix: /rbac/token/
+func RBACTokenPrefix() string {
+	return rbacTokenPrefix + "/"
+}
+
+// RBACTokenPath returns the path of the given API token.
+// Path: /rbac/token/{id}
+func RBACTokenPath(id string) string {
+	return path.Join(rbacTokenPrefix, id)
+}
+
+// RBACTokenKeyPath returns the path of the key signing the API tokens.
+// Path: /rbac/token_key
+func RBACTokenKeyPath() string {
+	return rbacTokenKeyPath
+}
+
+// RBACGRPCPolicyPrefix returns the prefix of the gRPC policies.
+// Prefix: /rbac/grpc_policy/
+func RBACGRPCPolicyPrefix() string {
+	return rbacGRPCPolicyPrefix + "/"
+}
+
+// RBACGRPCPolicyPath returns the path of the given gRPC policy.
+// Path: /rbac/grpc_policy/{name}
+func RBACGRPCPolicyPath(name string) string {
+	return path.Join(rbacGRPCPolicyPrefix, name)
+}
+
+// RBACNetworkACLPrefix returns the prefix of the network ACLs.
+// Prefix: /rbac/network_acl/
+func RBACNetworkACLPrefix() string {
+	return rbacNetworkACLPrefix + "/"
+}
+
+// RBACNetworkACLPath returns the path of the given network ACL.
+// Path: /rbac/network_acl/{name}
+func RBACNetworkACLPath(name string) string {
+	return path.Join(rbacNetworkACLPrefix, name)
+}
+
+// RBACSessionPrefix returns the prefix of the admin sessions.
+// Prefix: /rbac/session/
+func RBACSessionPrefix() string {
+	return rbacSessionPrefix + "/"
+}
+
+// RBACSessionPath returns the path of the given admin session.
+// Path: /rbac/session/{id}
+func RBACSessionPath(id string) string {
+	return path.Join(rbacSessionPrefix, id)
+}
+
+// WebhookPrefix returns the prefix of the webhooks.
+// Prefix: /webhook/
+func WebhookPrefix() string {
+	return webhookPrefix + "/"
+}
+
+// WebhookPath returns the path of the given webhook.
+// Path: /webhook/{id}
+func WebhookPath(id string) string {
+	return path.Join(webhookPrefix, id)
+}
+
 // KeyspaceSafePointPrefix returns prefix for all key-spaces' safe points.
 // Path: /keyspaces/gc_safepoint/
 func KeyspaceSafePointPrefix() string {
@@ -203,12 +345,36 @@ func KeyspaceMetaPath(spaceID uint32) string {
 	return path.Join(KeyspaceMetaPrefix(), idStr)
 }
 
+// KeyspaceQuotaPrefix returns the prefix of keyspaces' quotas.
+// Prefix: keyspaces/quota/
+func KeyspaceQuotaPrefix() string {
+	return path.Join(keyspacePrefix, keyspaceQuotaInfix) + "/"
+}
+
+// KeyspaceQuotaPath returns the path to the given keyspace's quota.
+// Path: keyspaces/quota/{space_id}
+func KeyspaceQuotaPath(spaceID uint32) string {
+	return path.Join(KeyspaceQuotaPrefix(), encodeKeyspaceID(spaceID))
+}
+
+// KeyspacePlacementPath returns the path to the given keyspace's placement template.
+// Path: keyspaces/placement/{space_id}
+func KeyspacePlacementPath(spaceID uint32) string {
+	return path.Join(keyspacePrefix, keyspacePlacementInfix, encodeKeyspaceID(spaceID))
+}
+
 // KeyspaceIDPath returns the path to keyspace id from the given name.
 // Path: keyspaces/id/{name}
 func KeyspaceIDPath(name string) string {
 	return path.Join(keyspacePrefix, keyspaceIDInfix, name)
 }
 
+// KeyspaceAliasPath returns the path to the keyspace alias of the given name.
+// Path: keyspaces/alias/{name}
+func KeyspaceAliasPath(name string) string {
+	return path.Join(keyspacePrefix, keyspaceAliasInfix, name)
+}
+
 // KeyspaceIDAlloc returns the path of the keyspace id's persistent window boundary.
 // Path: keyspaces/alloc_id
 func KeyspaceIDAlloc() string {
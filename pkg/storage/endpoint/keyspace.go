@@ -16,7 +16,9 @@ package endpoint
 
 import (
 	"context"
+	"encoding/json"
 	"strconv"
+	"strings"
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/pingcap/kvproto/pkg/keyspacepb"
@@ -40,8 +42,21 @@ type KeyspaceStorage interface {
 	LoadKeyspaceMeta(txn kv.Txn, id uint32) (*keyspacepb.KeyspaceMeta, error)
 	SaveKeyspaceID(txn kv.Txn, id uint32, name string) error
 	LoadKeyspaceID(txn kv.Txn, name string) (bool, uint32, error)
+	RemoveKeyspaceID(txn kv.Txn, name string) error
+	// The alias is the old name of a renamed keyspace.
+	SaveKeyspaceAlias(txn kv.Txn, name string, alias interface{}) error
+	LoadKeyspaceAlias(txn kv.Txn, name string, alias interface{}) (bool, error)
+	RemoveKeyspaceAlias(txn kv.Txn, name string) error
 	// LoadRangeKeyspace loads no more than limit keyspaces starting at startID.
 	LoadRangeKeyspace(startID uint32, limit int) ([]*keyspacepb.KeyspaceMeta, error)
+	SaveKeyspaceQuota(id uint32, quota interface{}) error
+	LoadKeyspaceQuota(id uint32, quota interface{}) (bool, error)
+	RemoveKeyspaceQuota(id uint32) error
+	// LoadKeyspaceQuotas calls f with the id and the marshaled quota of every keyspace which has a quota.
+	LoadKeyspaceQuotas(f func(id uint32, value []byte) error) error
+	SaveKeyspacePlacement(id uint32, template interface{}) error
+	LoadKeyspacePlacement(id uint32, template interface{}) (bool, error)
+	RemoveKeyspacePlacement(id uint32) error
 	RunInTxn(ctx context.Context, f func(txn kv.Txn) error) error
 }
 
@@ -97,6 +112,38 @@ func (se *StorageEndpoint) LoadKeyspaceID(txn kv.Txn, name string) (bool, uint32
 	return true, uint32(id64), nil
 }
 
+// RemoveKeyspaceID removes the keyspace ID specified by keyspace name.
+func (se *StorageEndpoint) RemoveKeyspaceID(txn kv.Txn, name string) error {
+	return txn.Remove(KeyspaceIDPath(name))
+}
+
+// SaveKeyspaceAlias stores the marshallable alias of the given name.
+func (se *StorageEndpoint) SaveKeyspaceAlias(txn kv.Txn, name string, alias interface{}) error {
+	value, err := json.Marshal(alias)
+	if err != nil {
+		return errs.ErrJSONMarshal.Wrap(err).GenWithStackByCause()
+	}
+	return txn.Save(KeyspaceAliasPath(name), string(value))
+}
+
+// LoadKeyspaceAlias loads the alias of the given name into the given struct.
+// It returns false if the alias does not exist.
+func (se *StorageEndpoint) LoadKeyspaceAlias(txn kv.Txn, name string, alias interface{}) (bool, error) {
+	value, err := txn.Load(KeyspaceAliasPath(name))
+	if err != nil || value == "" {
+		return false, err
+	}
+	if err := json.Unmarshal([]byte(value), alias); err != nil {
+		return false, errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+	}
+	return true, nil
+}
+
+// RemoveKeyspaceAlias removes the alias of the given name.
+func (se *StorageEndpoint) RemoveKeyspaceAlias(txn kv.Txn, name string) error {
+	return txn.Remove(KeyspaceAliasPath(name))
+}
+
 // RunInTxn runs the given function in a transaction.
 func (se *StorageEndpoint) RunInTxn(ctx context.Context, f func(txn kv.Txn) error) error {
 	return se.Base.RunInTxn(ctx, f)
@@ -124,3 +171,74 @@ func (se *StorageEndpoint) LoadRangeKeyspace(startID uint32, limit int) ([]*keys
 	}
 	return keyspaces, nil
 }
+
+// SaveKeyspaceQuota stores the marshallable quota of the keyspace.
+func (se *StorageEndpoint) SaveKeyspaceQuota(id uint32, quota interface{}) error {
+	value, err := json.Marshal(quota)
+	if err != nil {
+		return errs.ErrJSONMarshal.Wrap(err).GenWithStackByCause()
+	}
+	return se.Save(KeyspaceQuotaPath(id), string(value))
+}
+
+// LoadKeyspaceQuota loads the quota of the keyspace then unmarshal it to quota.
+func (se *StorageEndpoint) LoadKeyspaceQuota(id uint32, quota interface{}) (bool, error) {
+	value, err := se.Load(KeyspaceQuotaPath(id))
+	if err != nil || value == "" {
+		return false, err
+	}
+	if err := json.Unmarshal([]byte(value), quota); err != nil {
+		return false, errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+	}
+	return true, nil
+}
+
+// RemoveKeyspaceQuota removes the quota of the keyspace.
+func (se *StorageEndpoint) RemoveKeyspaceQuota(id uint32) error {
+	return se.Remove(KeyspaceQuotaPath(id))
+}
+
+// LoadKeyspaceQuotas loads the quotas of all keyspaces.
+func (se *StorageEndpoint) LoadKeyspaceQuotas(f func(id uint32, value []byte) error) error {
+	prefix := KeyspaceQuotaPrefix()
+	keys, values, err := se.LoadRange(prefix, clientv3.GetPrefixRangeEnd(prefix), 0)
+	if err != nil {
+		return err
+	}
+	for i, key := range keys {
+		id, err := strconv.ParseUint(strings.TrimPrefix(key, prefix), SpaceIDBase, spaceIDBitSizeMax)
+		if err != nil {
+			return errs.ErrStrconvParseUint.Wrap(err).GenWithStackByCause()
+		}
+		if err := f(uint32(id), []byte(values[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveKeyspacePlacement stores the marshallable placement template of the keyspace.
+func (se *StorageEndpoint) SaveKeyspacePlacement(id uint32, template interface{}) error {
+	value, err := json.Marshal(template)
+	if err != nil {
+		return errs.ErrJSONMarshal.Wrap(err).GenWithStackByCause()
+	}
+	return se.Save(KeyspacePlacementPath(id), string(value))
+}
+
+// LoadKeyspacePlacement loads the placement template of the keyspace then unmarshal it to template.
+func (se *StorageEndpoint) LoadKeyspacePlacement(id uint32, template interface{}) (bool, error) {
+	value, err := se.Load(KeyspacePlacementPath(id))
+	if err != nil || value == "" {
+		return false, err
+	}
+	if err := json.Unmarshal([]byte(value), template); err != nil {
+		return false, errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+	}
+	return true, nil
+}
+
+// RemoveKeyspacePlacement removes the placement template of the keyspace.
+func (se *StorageEndpoint) RemoveKeyspacePlacement(id uint32) error {
+	return se.Remove(KeyspacePlacementPath(id))
+}
@@ -0,0 +1,320 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoint
+
+import (
+	"encoding/json"
+
+	"github.com/pingcap/errors"
+	"github.com/tikv/pd/pkg/errs"
+	"go.etcd.io/etcd/clientv3"
+)
+
+// RoleBinding binds an identity, which is a client certificate or a bearer
+// token, to a role of the PD APIs.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type RoleBinding struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+	// CN is the common name of the client certificate of the identity.
+	CN string `json:"cn,omitempty"`
+	// TokenHash is the hex encoded SHA-256 of the bearer token of the identity.
+	// The token itself is never stored.
+	TokenHash string `json:"token_hash,omitempty"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// APIToken is the record of an issued API token, which is signed by PD and
+// valid until it expires or the record is removed.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type APIToken struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Scopes are the roles granted to the token, each is "{role}" or
+	// "{role}:{path-prefix}" to limit the role to the APIs under the prefix.
+	Scopes    []string `json:"scopes"`
+	IssuedAt  int64    `json:"issued_at"`
+	ExpiresAt int64    `json:"expires_at"`
+}
+
+// GRPCPolicy allows the client certificates matching its subjects to call the
+// gRPC methods.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type GRPCPolicy struct {
+	Name string `json:"name"`
+	// Subjects match the client certificate, each is "cn:{common-name}",
+	// "dns:{dns-san}", "uri:{uri-san}", "ip:{ip-san}" or "email:{email-san}".
+	// The value can be a glob pattern, e.g. "dns:*.cdc.svc".
+	Subjects []string `json:"subjects"`
+	// Methods are the full gRPC methods allowed, e.g. "/pdpb.PD/GetRegion".
+	// The method can be a glob pattern, e.g. "/pdpb.PD/Watch*", and "*" allows
+	// all the methods.
+	Methods   []string `json:"methods"`
+	UpdatedAt int64    `json:"updated_at"`
+}
+
+// NetworkACL allows or denies the source IPs of the requests to a class of the
+// APIs on a listener.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type NetworkACL struct {
+	Name string `json:"name"`
+	// Listener is either "http" or "grpc", empty for both.
+	Listener string `json:"listener,omitempty"`
+	// APIClass is the prefix of the HTTP paths or the full gRPC methods of the
+	// APIs, e.g. "/pd/api/v1/admin" or "/pdpb.PD/", empty for all the APIs.
+	APIClass string `json:"api_class,omitempty"`
+	// Allow are the CIDRs allowed, empty to allow all the IPs not denied.
+	Allow []string `json:"allow,omitempty"`
+	// Deny are the CIDRs denied, which take precedence over the allowed ones.
+	Deny      []string `json:"deny,omitempty"`
+	UpdatedAt int64    `json:"updated_at"`
+}
+
+// AdminSession is a login session of an interactive tool, e.g. pd-ctl or the
+// dashboard, which is valid until it expires or is revoked. The revoked sessions
+// are kept until they expire, which make up the revocation list.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type AdminSession struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+	// Client is the tool which logs in, e.g. "pd-ctl".
+	Client     string `json:"client,omitempty"`
+	RemoteAddr string `json:"remote_addr,omitempty"`
+	// TokenHash is the hex encoded SHA-256 of the session token. The token
+	// itself is never stored.
+	TokenHash string `json:"token_hash,omitempty"`
+	CreatedAt int64  `json:"created_at"`
+	ExpiresAt int64  `json:"expires_at"`
+	// RevokedAt is zero if the session is not revoked.
+	RevokedAt int64 `json:"revoked_at,omitempty"`
+	// RevokedBy is the identity which revokes the session.
+	RevokedBy string `json:"revoked_by,omitempty"`
+}
+
+// RBACStorage defines the storage operations on the role bindings, the API
+// tokens, the gRPC policies, the network ACLs and the admin sessions.
+type RBACStorage interface {
+	SaveRoleBinding(binding *RoleBinding) error
+	LoadAllRoleBindings() ([]*RoleBinding, error)
+	RemoveRoleBinding(name string) error
+	SaveAPIToken(token *APIToken) error
+	LoadAllAPITokens() ([]*APIToken, error)
+	RemoveAPIToken(id string) error
+	SaveAPITokenKey(key string) error
+	LoadAPITokenKey() (string, error)
+	SaveGRPCPolicy(policy *GRPCPolicy) error
+	LoadAllGRPCPolicies() ([]*GRPCPolicy, error)
+	RemoveGRPCPolicy(name string) error
+	SaveNetworkACL(acl *NetworkACL) error
+	LoadAllNetworkACLs() ([]*NetworkACL, error)
+	RemoveNetworkACL(name string) error
+	SaveAdminSession(session *AdminSession) error
+	LoadAllAdminSessions() ([]*AdminSession, error)
+	RemoveAdminSession(id string) error
+}
+
+var _ RBACStorage = (*StorageEndpoint)(nil)
+
+// SaveRoleBinding saves the role binding.
+func (se *StorageEndpoint) SaveRoleBinding(binding *RoleBinding) error {
+	if binding.Name == "" {
+		return errors.New("name of role binding cannot be empty")
+	}
+	value, err := json.Marshal(binding)
+	if err != nil {
+		return errs.ErrJSONMarshal.Wrap(err).GenWithStackByCause()
+	}
+	return se.Save(RBACBindingPath(binding.Name), string(value))
+}
+
+// LoadAllRoleBindings returns all the role bindings.
+func (se *StorageEndpoint) LoadAllRoleBindings() ([]*RoleBinding, error) {
+	prefix := RBACBindingPrefix()
+	prefixEnd := clientv3.GetPrefixRangeEnd(prefix)
+	_, values, err := se.LoadRange(prefix, prefixEnd, 0)
+	if err != nil {
+		return nil, err
+	}
+	bindings := make([]*RoleBinding, 0, len(values))
+	for _, value := range values {
+		binding := &RoleBinding{}
+		if err := json.Unmarshal([]byte(value), binding); err != nil {
+			return nil, errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+		}
+		bindings = append(bindings, binding)
+	}
+	return bindings, nil
+}
+
+// RemoveRoleBinding removes the role binding.
+func (se *StorageEndpoint) RemoveRoleBinding(name string) error {
+	return se.Remove(RBACBindingPath(name))
+}
+
+// SaveAPIToken saves the record of the API token.
+func (se *StorageEndpoint) SaveAPIToken(token *APIToken) error {
+	if token.ID == "" {
+		return errors.New("id of API token cannot be empty")
+	}
+	value, err := json.Marshal(token)
+	if err != nil {
+		return errs.ErrJSONMarshal.Wrap(err).GenWithStackByCause()
+	}
+	return se.Save(RBACTokenPath(token.ID), string(value))
+}
+
+// LoadAllAPITokens returns the records of all the API tokens.
+func (se *StorageEndpoint) LoadAllAPITokens() ([]*APIToken, error) {
+	prefix := RBACTokenPrefix()
+	prefixEnd := clientv3.GetPrefixRangeEnd(prefix)
+	_, values, err := se.LoadRange(prefix, prefixEnd, 0)
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]*APIToken, 0, len(values))
+	for _, value := range values {
+		token := &APIToken{}
+		if err := json.Unmarshal([]byte(value), token); err != nil {
+			return nil, errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+// RemoveAPIToken removes the record of the API token, which revokes it.
+func (se *StorageEndpoint) RemoveAPIToken(id string) error {
+	return se.Remove(RBACTokenPath(id))
+}
+
+// SaveAPITokenKey saves the hex encoded key signing the API tokens.
+func (se *StorageEndpoint) SaveAPITokenKey(key string) error {
+	return se.Save(RBACTokenKeyPath(), key)
+}
+
+// LoadAPITokenKey returns the hex encoded key signing the API tokens, empty if
+// no token has been issued.
+func (se *StorageEndpoint) LoadAPITokenKey() (string, error) {
+	return se.Load(RBACTokenKeyPath())
+}
+
+// SaveGRPCPolicy saves the gRPC policy.
+func (se *StorageEndpoint) SaveGRPCPolicy(policy *GRPCPolicy) error {
+	if policy.Name == "" {
+		return errors.New("name of gRPC policy cannot be empty")
+	}
+	value, err := json.Marshal(policy)
+	if err != nil {
+		return errs.ErrJSONMarshal.Wrap(err).GenWithStackByCause()
+	}
+	return se.Save(RBACGRPCPolicyPath(policy.Name), string(value))
+}
+
+// LoadAllGRPCPolicies returns all the gRPC policies.
+func (se *StorageEndpoint) LoadAllGRPCPolicies() ([]*GRPCPolicy, error) {
+	prefix := RBACGRPCPolicyPrefix()
+	prefixEnd := clientv3.GetPrefixRangeEnd(prefix)
+	_, values, err := se.LoadRange(prefix, prefixEnd, 0)
+	if err != nil {
+		return nil, err
+	}
+	policies := make([]*GRPCPolicy, 0, len(values))
+	for _, value := range values {
+		policy := &GRPCPolicy{}
+		if err := json.Unmarshal([]byte(value), policy); err != nil {
+			return nil, errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// RemoveGRPCPolicy removes the gRPC policy.
+func (se *StorageEndpoint) RemoveGRPCPolicy(name string) error {
+	return se.Remove(RBACGRPCPolicyPath(name))
+}
+
+// SaveNetworkACL saves the network ACL.
+func (se *StorageEndpoint) SaveNetworkACL(acl *NetworkACL) error {
+	if acl.Name == "" {
+		return errors.New("name of network ACL cannot be empty")
+	}
+	value, err := json.Marshal(acl)
+	if err != nil {
+		return errs.ErrJSONMarshal.Wrap(err).GenWithStackByCause()
+	}
+	return se.Save(RBACNetworkACLPath(acl.Name), string(value))
+}
+
+// LoadAllNetworkACLs returns all the network ACLs.
+func (se *StorageEndpoint) LoadAllNetworkACLs() ([]*NetworkACL, error) {
+	prefix := RBACNetworkACLPrefix()
+	prefixEnd := clientv3.GetPrefixRangeEnd(prefix)
+	_, values, err := se.LoadRange(prefix, prefixEnd, 0)
+	if err != nil {
+		return nil, err
+	}
+	acls := make([]*NetworkACL, 0, len(values))
+	for _, value := range values {
+		acl := &NetworkACL{}
+		if err := json.Unmarshal([]byte(value), acl); err != nil {
+			return nil, errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+		}
+		acls = append(acls, acl)
+	}
+	return acls, nil
+}
+
+// RemoveNetworkACL removes the network ACL.
+func (se *StorageEndpoint) RemoveNetworkACL(name string) error {
+	return se.Remove(RBACNetworkACLPath(name))
+}
+
+// SaveAdminSession saves the admin session.
+func (se *StorageEndpoint) SaveAdminSession(session *AdminSession) error {
+	if session.ID == "" {
+		return errors.New("id of admin session cannot be empty")
+	}
+	value, err := json.Marshal(session)
+	if err != nil {
+		return errs.ErrJSONMarshal.Wrap(err).GenWithStackByCause()
+	}
+	return se.Save(RBACSessionPath(session.ID), string(value))
+}
+
+// LoadAllAdminSessions returns all the admin sessions.
+func (se *StorageEndpoint) LoadAllAdminSessions() ([]*AdminSession, error) {
+	prefix := RBACSessionPrefix()
+	prefixEnd := clientv3.GetPrefixRangeEnd(prefix)
+	_, values, err := se.LoadRange(prefix, prefixEnd, 0)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]*AdminSession, 0, len(values))
+	for _, value := range values {
+		session := &AdminSession{}
+		if err := json.Unmarshal([]byte(value), session); err != nil {
+			return nil, errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// RemoveAdminSession removes the admin session.
+func (se *StorageEndpoint) RemoveAdminSession(id string) error {
+	return se.Remove(RBACSessionPath(id))
+}
@@ -15,7 +15,11 @@
 package endpoint
 
 import (
+	"encoding/json"
+	"path"
+
 	"github.com/gogo/protobuf/proto"
+	"github.com/tikv/pd/pkg/errs"
 )
 
 // ResourceGroupStorage defines the storage operations on the resource group.
@@ -26,6 +30,20 @@ type ResourceGroupStorage interface {
 	LoadResourceGroupStates(f func(k, v string)) error
 	SaveResourceGroupStates(name string, obj interface{}) error
 	DeleteResourceGroupStates(name string) error
+	LoadResourceGroupExtendedSettings(f func(k, v string)) error
+	SaveResourceGroupExtendedSettings(name string, obj interface{}) error
+	DeleteResourceGroupExtendedSettings(name string) error
+	LoadResourceManagerSettings(name string, obj interface{}) (bool, error)
+	SaveResourceManagerSettings(name string, obj interface{}) error
+	SaveResourceGroupConsumptionHistory(name string, minute int64, obj interface{}) error
+	LoadResourceGroupConsumptionHistory(name string, start, end int64, f func(value []byte) error) error
+	RemoveResourceGroupConsumptionHistoryBefore(name string, minute int64) error
+	LoadRunawaySettings(f func(k, v string)) error
+	SaveRunawaySettings(name string, obj interface{}) error
+	DeleteRunawaySettings(name string) error
+	LoadRunawayWatches(f func(k, v string)) error
+	SaveRunawayWatch(id uint64, obj interface{}) error
+	DeleteRunawayWatch(id uint64) error
 }
 
 var _ ResourceGroupStorage = (*StorageEndpoint)(nil)
@@ -59,3 +77,101 @@ func (se *StorageEndpoint) DeleteResourceGroupStates(name string) error {
 func (se *StorageEndpoint) LoadResourceGroupStates(f func(k, v string)) error {
 	return se.loadRangeByPrefix(resourceGroupStatesPath+"/", f)
 }
+
+// SaveResourceGroupExtendedSettings stores the extended settings of a resource group to storage.
+func (se *StorageEndpoint) SaveResourceGroupExtendedSettings(name string, obj interface{}) error {
+	return se.saveJSON(resourceGroupExtendedSettingsKeyPath(name), obj)
+}
+
+// DeleteResourceGroupExtendedSettings removes the extended settings of a resource group from storage.
+func (se *StorageEndpoint) DeleteResourceGroupExtendedSettings(name string) error {
+	return se.Remove(resourceGroupExtendedSettingsKeyPath(name))
+}
+
+// LoadResourceGroupExtendedSettings loads the extended settings of all resource groups from storage.
+func (se *StorageEndpoint) LoadResourceGroupExtendedSettings(f func(k, v string)) error {
+	return se.loadRangeByPrefix(resourceGroupExtendedSettingsPath+"/", f)
+}
+
+// SaveResourceManagerSettings stores the cluster-level settings of the resource manager to storage.
+func (se *StorageEndpoint) SaveResourceManagerSettings(name string, obj interface{}) error {
+	return se.saveJSON(resourceManagerSettingsKeyPath(name), obj)
+}
+
+// LoadResourceManagerSettings loads the cluster-level settings of the resource manager from storage.
+func (se *StorageEndpoint) LoadResourceManagerSettings(name string, obj interface{}) (bool, error) {
+	value, err := se.Load(resourceManagerSettingsKeyPath(name))
+	if err != nil || value == "" {
+		return false, err
+	}
+	if err := json.Unmarshal([]byte(value), obj); err != nil {
+		return false, errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+	}
+	return true, nil
+}
+
+// SaveResourceGroupConsumptionHistory stores the RU consumption of a resource group
+// in the minute, which is the unix timestamp in seconds of the start of the minute.
+func (se *StorageEndpoint) SaveResourceGroupConsumptionHistory(name string, minute int64, obj interface{}) error {
+	return se.saveJSON(resourceGroupConsumptionHistoryKeyPath(name, minute), obj)
+}
+
+// LoadResourceGroupConsumptionHistory loads the RU consumption of a resource group
+// in the minutes of [start, end), in the order of time.
+func (se *StorageEndpoint) LoadResourceGroupConsumptionHistory(name string, start, end int64, f func(value []byte) error) error {
+	_, values, err := se.LoadRange(resourceGroupConsumptionHistoryKeyPath(name, start), resourceGroupConsumptionHistoryKeyPath(name, end), 0)
+	if err != nil {
+		return err
+	}
+	for _, value := range values {
+		if err := f([]byte(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveResourceGroupConsumptionHistoryBefore removes the RU consumption of a
+// resource group in the minutes before the given one.
+func (se *StorageEndpoint) RemoveResourceGroupConsumptionHistoryBefore(name string, minute int64) error {
+	keys, _, err := se.LoadRange(resourceGroupConsumptionHistoryKeyPath(name, 0), resourceGroupConsumptionHistoryKeyPath(name, minute), 0)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := se.Remove(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveRunawaySettings stores the runaway settings of a resource group to storage.
+func (se *StorageEndpoint) SaveRunawaySettings(name string, obj interface{}) error {
+	return se.saveJSON(runawaySettingsKeyPath(name), obj)
+}
+
+// DeleteRunawaySettings removes the runaway settings of a resource group from storage.
+func (se *StorageEndpoint) DeleteRunawaySettings(name string) error {
+	return se.Remove(runawaySettingsKeyPath(name))
+}
+
+// LoadRunawaySettings loads the runaway settings of all resource groups from storage.
+func (se *StorageEndpoint) LoadRunawaySettings(f func(k, v string)) error {
+	return se.loadRangeByPrefix(path.Join(runawayPath, runawaySettingsInfix)+"/", f)
+}
+
+// SaveRunawayWatch stores a runaway watch item to storage.
+func (se *StorageEndpoint) SaveRunawayWatch(id uint64, obj interface{}) error {
+	return se.saveJSON(runawayWatchKeyPath(id), obj)
+}
+
+// DeleteRunawayWatch removes a runaway watch item from storage.
+func (se *StorageEndpoint) DeleteRunawayWatch(id uint64) error {
+	return se.Remove(runawayWatchKeyPath(id))
+}
+
+// LoadRunawayWatches loads all runaway watch items from storage.
+func (se *StorageEndpoint) LoadRunawayWatches(f func(k, v string)) error {
+	return se.loadRangeByPrefix(path.Join(runawayPath, runawayWatchesInfix)+"/", f)
+}
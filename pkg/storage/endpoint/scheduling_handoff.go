@@ -0,0 +1,57 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoint
+
+import (
+	"encoding/json"
+
+	"github.com/tikv/pd/pkg/errs"
+)
+
+// SchedulingHandoffStorage defines the storage operations on the scheduling
+// state handed over from the resigned PD leader.
+type SchedulingHandoffStorage interface {
+	LoadSchedulingHandoff(handoff interface{}) (bool, error)
+	SaveSchedulingHandoff(handoff interface{}) error
+	RemoveSchedulingHandoff() error
+}
+
+var _ SchedulingHandoffStorage = (*StorageEndpoint)(nil)
+
+// LoadSchedulingHandoff loads the scheduling handoff then unmarshal it to handoff.
+func (se *StorageEndpoint) LoadSchedulingHandoff(handoff interface{}) (bool, error) {
+	value, err := se.Load(schedulingHandoffPath)
+	if err != nil || value == "" {
+		return false, err
+	}
+	if err := json.Unmarshal([]byte(value), handoff); err != nil {
+		return false, errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+	}
+	return true, nil
+}
+
+// SaveSchedulingHandoff stores the marshallable scheduling handoff.
+func (se *StorageEndpoint) SaveSchedulingHandoff(handoff interface{}) error {
+	value, err := json.Marshal(handoff)
+	if err != nil {
+		return errs.ErrJSONMarshal.Wrap(err).GenWithStackByCause()
+	}
+	return se.Save(schedulingHandoffPath, string(value))
+}
+
+// RemoveSchedulingHandoff removes the scheduling handoff.
+func (se *StorageEndpoint) RemoveSchedulingHandoff() error {
+	return se.Remove(schedulingHandoffPath)
+}
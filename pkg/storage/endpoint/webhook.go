@@ -0,0 +1,105 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoint
+
+import (
+	"encoding/json"
+
+	"github.com/pingcap/errors"
+	"github.com/tikv/pd/pkg/errs"
+	"go.etcd.io/etcd/clientv3"
+)
+
+// Webhook is a URL called on the cluster events which pass its filters.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type Webhook struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// Secret signs the requests with HMAC-SHA256, empty to not sign them.
+	Secret string `json:"secret,omitempty"`
+	// EventTypes are the types or the categories of the events to be sent,
+	// e.g. "store-down" or "operator", empty for all the events.
+	EventTypes []string `json:"event_types,omitempty"`
+	// StoreIDs are the stores of the events to be sent, empty for the events
+	// of all the stores and the ones of no store.
+	StoreIDs []uint64 `json:"store_ids,omitempty"`
+	// Attributes filter the events by the attributes. An event is sent if each
+	// of the attributes equals one of the values, e.g. {"status": ["TIMEOUT",
+	// "CANCEL"]} for the failed operators.
+	Attributes map[string][]string `json:"attributes,omitempty"`
+	// MaxRetries is the max times to retry a failed delivery.
+	MaxRetries int   `json:"max_retries"`
+	CreatedAt  int64 `json:"created_at"`
+	UpdatedAt  int64 `json:"updated_at"`
+}
+
+// WebhookStorage defines the storage operations on the webhooks.
+type WebhookStorage interface {
+	SaveWebhook(webhook *Webhook) error
+	LoadWebhook(id string) (*Webhook, error)
+	LoadAllWebhooks() ([]*Webhook, error)
+	RemoveWebhook(id string) error
+}
+
+var _ WebhookStorage = (*StorageEndpoint)(nil)
+
+// SaveWebhook saves the webhook.
+func (se *StorageEndpoint) SaveWebhook(webhook *Webhook) error {
+	if webhook.ID == "" {
+		return errors.New("id of webhook cannot be empty")
+	}
+	value, err := json.Marshal(webhook)
+	if err != nil {
+		return errs.ErrJSONMarshal.Wrap(err).GenWithStackByCause()
+	}
+	return se.Save(WebhookPath(webhook.ID), string(value))
+}
+
+// LoadWebhook returns the webhook, or nil if it does not exist.
+func (se *StorageEndpoint) LoadWebhook(id string) (*Webhook, error) {
+	value, err := se.Load(WebhookPath(id))
+	if err != nil || value == "" {
+		return nil, err
+	}
+	webhook := &Webhook{}
+	if err := json.Unmarshal([]byte(value), webhook); err != nil {
+		return nil, errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+	}
+	return webhook, nil
+}
+
+// LoadAllWebhooks returns all the webhooks.
+func (se *StorageEndpoint) LoadAllWebhooks() ([]*Webhook, error) {
+	prefix := WebhookPrefix()
+	prefixEnd := clientv3.GetPrefixRangeEnd(prefix)
+	_, values, err := se.LoadRange(prefix, prefixEnd, 0)
+	if err != nil {
+		return nil, err
+	}
+	webhooks := make([]*Webhook, 0, len(values))
+	for _, value := range values {
+		webhook := &Webhook{}
+		if err := json.Unmarshal([]byte(value), webhook); err != nil {
+			return nil, errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, nil
+}
+
+// RemoveWebhook removes the webhook.
+func (se *StorageEndpoint) RemoveWebhook(id string) error {
+	return se.Remove(WebhookPath(id))
+}
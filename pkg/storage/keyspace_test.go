@@ -69,6 +69,67 @@ func TestSaveLoadKeyspace(t *testing.T) {
 	re.NoError(err)
 }
 
+func TestSaveLoadKeyspaceAlias(t *testing.T) {
+	re := require.New(t)
+	storage := NewStorageWithMemoryBackend()
+	type alias struct {
+		ID       uint32 `json:"id"`
+		ExpireAt int64  `json:"expire_at"`
+	}
+	expected := &alias{ID: 10, ExpireAt: 100}
+	err := storage.RunInTxn(context.TODO(), func(txn kv.Txn) error {
+		re.NoError(storage.SaveKeyspaceID(txn, 10, "old"))
+		re.NoError(storage.RemoveKeyspaceID(txn, "old"))
+		re.NoError(storage.SaveKeyspaceAlias(txn, "old", expected))
+		return nil
+	})
+	re.NoError(err)
+	err = storage.RunInTxn(context.TODO(), func(txn kv.Txn) error {
+		loaded, _, err := storage.LoadKeyspaceID(txn, "old")
+		re.NoError(err)
+		re.False(loaded)
+		actual := &alias{}
+		loaded, err = storage.LoadKeyspaceAlias(txn, "old", actual)
+		re.NoError(err)
+		re.True(loaded)
+		re.Equal(expected, actual)
+		re.NoError(storage.RemoveKeyspaceAlias(txn, "old"))
+		return nil
+	})
+	re.NoError(err)
+	err = storage.RunInTxn(context.TODO(), func(txn kv.Txn) error {
+		loaded, err := storage.LoadKeyspaceAlias(txn, "old", &alias{})
+		re.NoError(err)
+		re.False(loaded)
+		return nil
+	})
+	re.NoError(err)
+}
+
+func TestSaveLoadKeyspacePlacement(t *testing.T) {
+	re := require.New(t)
+	storage := NewStorageWithMemoryBackend()
+	type template struct {
+		Rules []string `json:"rules"`
+	}
+	actual := &template{}
+	loaded, err := storage.LoadKeyspacePlacement(10, actual)
+	re.NoError(err)
+	re.False(loaded)
+
+	expected := &template{Rules: []string{"voter", "learner"}}
+	re.NoError(storage.SaveKeyspacePlacement(10, expected))
+	loaded, err = storage.LoadKeyspacePlacement(10, actual)
+	re.NoError(err)
+	re.True(loaded)
+	re.Equal(expected, actual)
+
+	re.NoError(storage.RemoveKeyspacePlacement(10))
+	loaded, err = storage.LoadKeyspacePlacement(10, &template{})
+	re.NoError(err)
+	re.False(loaded)
+}
+
 func TestLoadRangeKeyspaces(t *testing.T) {
 	re := require.New(t)
 	storage := NewStorageWithMemoryBackend()
@@ -34,6 +34,8 @@ type Storage interface {
 	kv.Base
 	endpoint.ServiceMiddlewareStorage
 	endpoint.ConfigStorage
+	endpoint.SchedulingHandoffStorage
+	endpoint.ElectionHistoryStorage
 	endpoint.MetaStorage
 	endpoint.RuleStorage
 	endpoint.ReplicationStatusStorage
@@ -44,6 +46,8 @@ type Storage interface {
 	endpoint.KeyspaceStorage
 	endpoint.ResourceGroupStorage
 	endpoint.TSOStorage
+	endpoint.RBACStorage
+	endpoint.WebhookStorage
 }
 
 // NewStorageWithMemoryBackend creates a new storage with memory backend.
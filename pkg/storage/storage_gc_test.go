@@ -124,6 +124,27 @@ func TestLoadMinServiceSafePoint(t *testing.T) {
 	re.NoError(failpoint.Disable("github.com/tikv/pd/pkg/storage/endpoint/removeExpiredKeys"))
 }
 
+func TestLoadAllServiceSafePoints(t *testing.T) {
+	re := require.New(t)
+	storage := NewStorageWithMemoryBackend()
+	testSpaceID, testSafePoints := testServiceSafePoints()
+	for i := range testSpaceID {
+		re.NoError(storage.SaveServiceSafePoint(testSpaceID[i], testSafePoints[i]))
+	}
+	now := time.Now()
+	expired := &endpoint.ServiceSafePoint{ServiceID: "expired", ExpiredAt: now.Unix() - 1, SafePoint: 0}
+	re.NoError(storage.SaveServiceSafePoint("keySpace1", expired))
+	// The service safe points are isolated by key-spaces, and the expired ones are skipped.
+	for _, spaceID := range []string{"keySpace1", "keySpace2", "keySpace3"} {
+		ssps, err := storage.LoadAllServiceSafePoints(spaceID, now)
+		re.NoError(err)
+		re.Equal(testSafePoints[:3], ssps)
+	}
+	ssps, err := storage.LoadAllServiceSafePoints("keySpace4", now)
+	re.NoError(err)
+	re.Empty(ssps)
+}
+
 func TestRemoveServiceSafePoint(t *testing.T) {
 	re := require.New(t)
 	storage := NewStorageWithMemoryBackend()
@@ -21,9 +21,16 @@ import (
 	"net/http"
 
 	httpSwagger "github.com/swaggo/http-swagger"
+	"github.com/swaggo/swag"
 	_ "github.com/tikv/pd/docs/swagger"
+	_ "github.com/tikv/pd/docs/swagger/v2"
 )
 
 func handler() http.Handler {
 	return httpSwagger.Handler()
 }
+
+// ReadDoc returns the OpenAPI spec of the named API instance.
+func ReadDoc(name string) (string, error) {
+	return swag.ReadDoc(name)
+}
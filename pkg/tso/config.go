@@ -23,6 +23,7 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/tikv/pd/pkg/encryption"
 	"github.com/tikv/pd/pkg/utils/grpcutil"
+	"github.com/tikv/pd/pkg/utils/logutil"
 	"github.com/tikv/pd/pkg/utils/metricutil"
 	"github.com/tikv/pd/pkg/utils/typeutil"
 	"go.uber.org/zap"
@@ -106,9 +107,11 @@ func (c *Config) configFromFile(path string) (*toml.MetaData, error) {
 // SecurityConfig indicates the security configuration for pd server
 type SecurityConfig struct {
 	grpcutil.TLSConfig
-	// RedactInfoLog indicates that whether enabling redact log
-	RedactInfoLog bool              `toml:"redact-info-log" json:"redact-info-log"`
-	Encryption    encryption.Config `toml:"encryption" json:"encryption"`
+	// RedactInfoLog is how the user data is redacted in the logs, the API
+	// error messages and the audit records. The boolean values are accepted
+	// for compatibility.
+	RedactInfoLog logutil.RedactMode `toml:"redact-info-log" json:"redact-info-log"`
+	Encryption    encryption.Config  `toml:"encryption" json:"encryption"`
 }
 
 func adjustCommandlineString(flagSet *pflag.FlagSet, v *string, name string) {
@@ -16,8 +16,11 @@ package apiutil
 
 import (
 	"bytes"
+	"compress/gzip"
 	"io"
+	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -68,3 +71,47 @@ func TestJsonRespondErrorBadInput(t *testing.T) {
 		re.Equal(400, result.StatusCode)
 	}
 }
+
+func TestConditionalHandler(t *testing.T) {
+	t.Parallel()
+	re := require.New(t)
+	body := strings.Repeat("region", minCompressSize)
+	handler := NewConditionalHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+
+	// The response is compressed if gzip is accepted.
+	req := httptest.NewRequest(http.MethodGet, "/regions", nil)
+	req.Header.Set("Accept-Encoding", "deflate, gzip;q=0.8")
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, req)
+	re.Equal(http.StatusOK, response.Code)
+	re.Equal("gzip", response.Header().Get("Content-Encoding"))
+	etag := response.Header().Get("ETag")
+	re.NotEmpty(etag)
+	reader, err := gzip.NewReader(response.Body)
+	re.NoError(err)
+	data, err := io.ReadAll(reader)
+	re.NoError(err)
+	re.Equal(body, string(data))
+
+	// The response is not modified if the ETag matches.
+	req = httptest.NewRequest(http.MethodGet, "/regions", nil)
+	req.Header.Set("If-None-Match", `"other", `+etag)
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, req)
+	re.Equal(http.StatusNotModified, response.Code)
+	re.Empty(response.Body.Bytes())
+
+	// The response is not compressed if gzip is refused.
+	req = httptest.NewRequest(http.MethodGet, "/regions", nil)
+	req.Header.Set("Accept-Encoding", "gzip;q=0")
+	req.Header.Set("If-None-Match", `"other"`)
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, req)
+	re.Equal(http.StatusOK, response.Code)
+	re.Empty(response.Header().Get("Content-Encoding"))
+	re.Equal(etag, response.Header().Get("ETag"))
+	re.Equal(body, response.Body.String())
+}
@@ -0,0 +1,132 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// minCompressSize is the min size of the response body to be compressed, the
+// smaller ones are not worth compressing.
+const minCompressSize = 1024
+
+// responseBuffer buffers the whole response, so that it can be compressed or
+// replaced with 304 Not Modified after the handler returns.
+type responseBuffer struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *responseBuffer) Header() http.Header {
+	return b.header
+}
+
+func (b *responseBuffer) Write(data []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(data)
+}
+
+func (b *responseBuffer) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+// NewConditionalHandler wraps the handler of a heavyweight GET API, whose
+// responses support the conditional requests by ETag and the compression by
+// gzip. See WriteConditionalResponse for details.
+func NewConditionalHandler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		buf := &responseBuffer{header: make(http.Header)}
+		handler.ServeHTTP(buf, r)
+		for key, values := range buf.header {
+			w.Header()[key] = values
+		}
+		if buf.status == 0 {
+			buf.status = http.StatusOK
+		}
+		WriteConditionalResponse(w, r, buf.status, buf.body.Bytes())
+	})
+}
+
+// WriteConditionalResponse writes the whole response of the GET request, whose
+// headers are already set. The successful response is tagged by a weak ETag
+// computed from the body, and is replaced with 304 Not Modified if the ETag
+// matches If-None-Match. The body is compressed by gzip if it is accepted by
+// the client and the body is large enough.
+func WriteConditionalResponse(w http.ResponseWriter, r *http.Request, status int, body []byte) {
+	header := w.Header()
+	if status == http.StatusOK {
+		sum := sha256.Sum256(body)
+		etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+		header.Set("ETag", etag)
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	header.Add("Vary", "Accept-Encoding")
+	if len(body) >= minCompressSize && acceptsGzip(r.Header.Get("Accept-Encoding")) {
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		if _, err := writer.Write(body); err == nil && writer.Close() == nil {
+			header.Set("Content-Encoding", "gzip")
+			header.Del("Content-Length")
+			body = buf.Bytes()
+		}
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+// etagMatches returns whether the If-None-Match header matches the ETag by the
+// weak comparison.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsGzip returns whether gzip is acceptable by the Accept-Encoding header.
+func acceptsGzip(acceptEncoding string) bool {
+	for _, coding := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(coding), ";")
+		name = strings.TrimSpace(name)
+		if name != "gzip" && name != "*" {
+			continue
+		}
+		q := strings.ReplaceAll(strings.TrimSpace(params), " ", "")
+		return q != "q=0" && q != "q=0.0" && q != "q=0.00" && q != "q=0.000"
+	}
+	return false
+}
@@ -15,12 +15,16 @@
 package serverapi
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/pingcap/log"
 	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/mcs/discovery"
 	"github.com/tikv/pd/pkg/slice"
 	"github.com/tikv/pd/pkg/utils/apiutil"
 	"github.com/tikv/pd/server"
@@ -32,6 +36,11 @@ import (
 const (
 	RedirectorHeader    = "PD-Redirector"
 	AllowFollowerHandle = "PD-Allow-follower-handle"
+	// MaxStalenessHeader allows a follower to serve the read-only request from its
+	// synced cache if the cache is not staler than the given duration, e.g. "5s".
+	MaxStalenessHeader = "PD-Max-Staleness"
+	// StalenessHeader reports the staleness of the data served by a follower.
+	StalenessHeader = "PD-Staleness"
 )
 
 const (
@@ -80,16 +89,78 @@ func IsServiceAllowed(s *server.Server, group apiutil.APIServiceGroup) bool {
 
 type redirector struct {
 	s *server.Server
+
+	followerReadable func(r *http.Request) bool
+	microservices    []microserviceForwarder
+}
+
+type microserviceForwarder struct {
+	pathPrefix string
+	*discovery.Forwarder
+}
+
+// RedirectorOption defines the option of the redirector.
+type RedirectorOption func(*redirector)
+
+// WithFollowerRead allows a follower to serve the requests which are readable from
+// its synced cache and carry the MaxStalenessHeader.
+func WithFollowerRead(readable func(r *http.Request) bool) RedirectorOption {
+	return func(h *redirector) {
+		h.followerReadable = readable
+	}
+}
+
+// MicroserviceRoute routes the requests under the path prefix to the microservice.
+type MicroserviceRoute struct {
+	PathPrefix string
+	Service    string
+}
+
+// WithMicroserviceForward forwards the requests matching the routes to the
+// microservices if they are deployed independently, and the requests are served
+// by PD itself otherwise.
+func WithMicroserviceForward(routes ...MicroserviceRoute) RedirectorOption {
+	return func(h *redirector) {
+		forwarders := make(map[string]*discovery.Forwarder)
+		for _, route := range routes {
+			forwarder, ok := forwarders[route.Service]
+			if !ok {
+				forwarder = discovery.NewForwarder(h.s, route.Service)
+				forwarders[route.Service] = forwarder
+			}
+			h.microservices = append(h.microservices, microserviceForwarder{pathPrefix: route.PathPrefix, Forwarder: forwarder})
+		}
+	}
 }
 
 // NewRedirector redirects request to the leader if needs to be handled in the leader.
-func NewRedirector(s *server.Server) negroni.Handler {
-	return &redirector{s: s}
+func NewRedirector(s *server.Server, opts ...RedirectorOption) negroni.Handler {
+	h := &redirector{s: s}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+type followerReadCtxKey struct{}
+
+// IsFollowerRead returns whether the request is served from the follower's synced cache.
+func IsFollowerRead(ctx context.Context) bool {
+	return ctx.Value(followerReadCtxKey{}) != nil
 }
 
 func (h *redirector) ServeHTTP(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if !h.s.IsClosed() && h.forwardToMicroservice(w, r) {
+		return
+	}
 	allowFollowerHandle := len(r.Header.Get(AllowFollowerHandle)) > 0
 	isLeader := h.s.GetMember().IsLeader()
+	if !h.s.IsClosed() && !isLeader {
+		if followerRead, ok := h.checkFollowerRead(w, r); ok {
+			next(w, followerRead)
+			return
+		}
+	}
 	if !h.s.IsClosed() && (allowFollowerHandle || isLeader) {
 		next(w, r)
 		return
@@ -124,6 +195,37 @@ func (h *redirector) ServeHTTP(w http.ResponseWriter, r *http.Request, next http
 	NewCustomReverseProxies(client, urls).ServeHTTP(w, r)
 }
 
+// forwardToMicroservice forwards the request to the microservice owning it, and
+// returns false if the request should be handled by PD.
+func (h *redirector) forwardToMicroservice(w http.ResponseWriter, r *http.Request) bool {
+	for _, m := range h.microservices {
+		if strings.HasPrefix(r.URL.Path, m.pathPrefix) {
+			return m.Forward(w, r)
+		}
+	}
+	return false
+}
+
+// checkFollowerRead checks whether the follower can serve the request from its
+// synced cache. If so, it returns the request marked as a follower read.
+func (h *redirector) checkFollowerRead(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	value := r.Header.Get(MaxStalenessHeader)
+	if len(value) == 0 || r.Method != http.MethodGet || h.followerReadable == nil || !h.followerReadable(r) {
+		return r, false
+	}
+	maxStaleness, err := time.ParseDuration(value)
+	if err != nil || maxStaleness < 0 {
+		return r, false
+	}
+	staleness, err := h.s.CheckFollowerRead(maxStaleness)
+	if err != nil {
+		log.Debug("follower read is unavailable, redirect to the leader", zap.String("server", h.s.Name()), errs.ZapError(err))
+		return r, false
+	}
+	w.Header().Set(StalenessHeader, staleness.String())
+	return r.WithContext(context.WithValue(r.Context(), followerReadCtxKey{}, struct{}{})), true
+}
+
 type customReverseProxies struct {
 	urls   []url.URL
 	client *http.Client
@@ -0,0 +1,161 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+)
+
+// The schemes of the secret references in the configuration. A value without
+// any of them is used as it is.
+const (
+	// SecretSchemeFile refers to a file holding the secret, e.g. "file:///etc/pd/password".
+	SecretSchemeFile = "file://"
+	// SecretSchemeEnv refers to an environment variable, e.g. "env://ETCD_PASSWORD".
+	SecretSchemeEnv = "env://"
+	// SecretSchemeVault refers to a field of a Vault secret, e.g.
+	// "vault://secret/data/pd#password". The Vault server is given by the
+	// environment variables VAULT_ADDR, VAULT_TOKEN and VAULT_NAMESPACE.
+	SecretSchemeVault = "vault://"
+)
+
+const (
+	vaultSecretTimeout = 10 * time.Second
+	// secretFilePerm is the permission of the files holding the resolved secrets.
+	secretFilePerm = 0o600
+)
+
+// IsSecretRef returns whether the value refers to an external secret.
+func IsSecretRef(value string) bool {
+	return strings.HasPrefix(value, SecretSchemeFile) ||
+		strings.HasPrefix(value, SecretSchemeEnv) ||
+		strings.HasPrefix(value, SecretSchemeVault)
+}
+
+// ResolveSecret resolves the value if it refers to an external secret. The
+// trailing newlines of the secret are trimmed.
+func ResolveSecret(value string) (string, error) {
+	var (
+		secret []byte
+		err    error
+	)
+	switch {
+	case strings.HasPrefix(value, SecretSchemeFile):
+		secret, err = os.ReadFile(strings.TrimPrefix(value, SecretSchemeFile))
+		err = errors.WithStack(err)
+	case strings.HasPrefix(value, SecretSchemeEnv):
+		name := strings.TrimPrefix(value, SecretSchemeEnv)
+		env, ok := os.LookupEnv(name)
+		if !ok {
+			return "", errors.Errorf("environment variable %s of the secret is not set", name)
+		}
+		secret = []byte(env)
+	case strings.HasPrefix(value, SecretSchemeVault):
+		secret, err = readVaultSecret(strings.TrimPrefix(value, SecretSchemeVault))
+	default:
+		return value, nil
+	}
+	if err != nil {
+		return "", errors.Annotatef(err, "failed to resolve the secret %s", value)
+	}
+	return strings.TrimRight(string(secret), "\r\n"), nil
+}
+
+// ResolveSecretPath resolves the path if it refers to an external secret, for
+// the configurations of the files, e.g. the TLS key. The path of a file
+// reference is returned as it is, the other secrets are written into the files
+// under the dir readable by the owner only, whose paths are returned.
+func ResolveSecretPath(value, dir string) (string, error) {
+	if strings.HasPrefix(value, SecretSchemeFile) {
+		return strings.TrimPrefix(value, SecretSchemeFile), nil
+	}
+	if !IsSecretRef(value) {
+		return value, nil
+	}
+	secret, err := ResolveSecret(value)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", errors.WithStack(err)
+	}
+	// The name is derived from the reference, so the file is replaced instead
+	// of piling up when PD restarts.
+	sum := sha256.Sum256([]byte(value))
+	path := filepath.Join(dir, "secret-"+hex.EncodeToString(sum[:8]))
+	if err := os.WriteFile(path, []byte(secret+"\n"), secretFilePerm); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return path, nil
+}
+
+// readVaultSecret reads the field of the secret at the path, e.g.
+// "secret/data/pd#password". Both the KV version 1 and 2 are supported.
+func readVaultSecret(ref string) ([]byte, error) {
+	path, field, ok := strings.Cut(ref, "#")
+	if !ok || len(path) == 0 || len(field) == 0 {
+		return nil, errors.New("the Vault secret should be in the form of vault://{path}#{field}")
+	}
+	addr, token := os.Getenv("VAULT_ADDR"), os.Getenv("VAULT_TOKEN")
+	if len(addr) == 0 || len(token) == 0 {
+		return nil, errors.New("VAULT_ADDR and VAULT_TOKEN should be set to read the Vault secret")
+	}
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(addr, "/")+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := os.Getenv("VAULT_NAMESPACE"); len(ns) > 0 {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+	resp, err := (&http.Client{Timeout: vaultSecretTimeout}).Do(req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to read the Vault secret %s, status %d: %s", path, resp.StatusCode, body)
+	}
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	data := secret.Data
+	// The KV version 2 nests the fields in data.data.
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+	value, ok := data[field].(string)
+	if !ok {
+		return nil, errors.Errorf("field %s of the Vault secret %s is not found", field, path)
+	}
+	return []byte(value), nil
+}
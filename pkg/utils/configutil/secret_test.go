@@ -0,0 +1,81 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveSecret(t *testing.T) {
+	re := require.New(t)
+	dir := t.TempDir()
+	value, err := ResolveSecret("plain")
+	re.NoError(err)
+	re.Equal("plain", value)
+
+	file := filepath.Join(dir, "password")
+	re.NoError(os.WriteFile(file, []byte("from-file\n"), 0o600))
+	value, err = ResolveSecret(SecretSchemeFile + file)
+	re.NoError(err)
+	re.Equal("from-file", value)
+	_, err = ResolveSecret(SecretSchemeFile + filepath.Join(dir, "missing"))
+	re.Error(err)
+
+	t.Setenv("PD_TEST_SECRET", "from-env")
+	value, err = ResolveSecret("env://PD_TEST_SECRET")
+	re.NoError(err)
+	re.Equal("from-env", value)
+	_, err = ResolveSecret("env://PD_TEST_SECRET_MISSING")
+	re.Error(err)
+
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" || r.URL.Path != "/v1/secret/data/pd" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"password":"from-vault"}}}`))
+	}))
+	defer vault.Close()
+	t.Setenv("VAULT_ADDR", vault.URL)
+	t.Setenv("VAULT_TOKEN", "root")
+	value, err = ResolveSecret("vault://secret/data/pd#password")
+	re.NoError(err)
+	re.Equal("from-vault", value)
+	_, err = ResolveSecret("vault://secret/data/pd#user")
+	re.Error(err)
+	_, err = ResolveSecret("vault://secret/data/other#password")
+	re.Error(err)
+	_, err = ResolveSecret("vault://secret/data/pd")
+	re.Error(err)
+
+	// The secret of a path is written into a file readable by the owner only.
+	path, err := ResolveSecretPath(SecretSchemeFile+file, dir)
+	re.NoError(err)
+	re.Equal(file, path)
+	path, err = ResolveSecretPath("env://PD_TEST_SECRET", filepath.Join(dir, "secrets"))
+	re.NoError(err)
+	data, err := os.ReadFile(path)
+	re.NoError(err)
+	re.Equal("from-env\n", string(data))
+	info, err := os.Stat(path)
+	re.NoError(err)
+	re.Equal(os.FileMode(0o600), info.Mode().Perm())
+}
@@ -93,6 +93,14 @@ func AddEtcdMember(client *clientv3.Client, urls []string) (*clientv3.MemberAddR
 	return addResp, errors.WithStack(err)
 }
 
+// AddEtcdLearner adds an etcd member as a learner, which does not vote.
+func AddEtcdLearner(client *clientv3.Client, urls []string) (*clientv3.MemberAddResponse, error) {
+	ctx, cancel := context.WithTimeout(client.Ctx(), DefaultRequestTimeout)
+	addResp, err := client.MemberAddAsLearner(ctx, urls)
+	cancel()
+	return addResp, errors.WithStack(err)
+}
+
 // ListEtcdMembers returns a list of internal etcd members.
 func ListEtcdMembers(client *clientv3.Client) (*clientv3.MemberListResponse, error) {
 	ctx, cancel := context.WithTimeout(client.Ctx(), DefaultRequestTimeout)
@@ -115,6 +123,29 @@ func RemoveEtcdMember(client *clientv3.Client, id uint64) (*clientv3.MemberRemov
 	return rmResp, nil
 }
 
+// PromoteEtcdLearner promotes a learner by the given id to a voter. It fails if
+// the learner is not in sync with the leader yet.
+func PromoteEtcdLearner(client *clientv3.Client, id uint64) (*clientv3.MemberPromoteResponse, error) {
+	ctx, cancel := context.WithTimeout(client.Ctx(), DefaultRequestTimeout)
+	promoteResp, err := client.MemberPromote(ctx, id)
+	cancel()
+	if err != nil {
+		return promoteResp, errs.ErrEtcdMemberPromote.Wrap(err).GenWithStackByCause()
+	}
+	return promoteResp, nil
+}
+
+// ListEtcdAlarms returns the alarms raised by the etcd members, e.g. running out of space.
+func ListEtcdAlarms(client *clientv3.Client) (*clientv3.AlarmResponse, error) {
+	ctx, cancel := context.WithTimeout(client.Ctx(), DefaultRequestTimeout)
+	alarmResp, err := client.AlarmList(ctx)
+	cancel()
+	if err != nil {
+		return alarmResp, errs.ErrEtcdAlarmList.Wrap(err).GenWithStackByCause()
+	}
+	return alarmResp, nil
+}
+
 // EtcdKVGet returns the etcd GetResponse by given key or key prefix
 func EtcdKVGet(c *clientv3.Client, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
 	ctx, cancel := context.WithTimeout(c.Ctx(), DefaultRequestTimeout)
@@ -208,9 +239,13 @@ func NewTestSingleConfig(t *testing.T) *embed.Config {
 	return cfg
 }
 
-// CreateClients creates etcd v3 client and http client.
-func CreateClients(tlsConfig *tls.Config, acUrls []url.URL) (*clientv3.Client, *http.Client, error) {
-	endpoints := []string{acUrls[0].String()}
+// CreateClients creates etcd v3 client and http client. The username and the
+// password are only needed if the authentication of etcd is enabled.
+func CreateClients(tlsConfig *tls.Config, acUrls []url.URL, username, password string) (*clientv3.Client, *http.Client, error) {
+	endpoints := make([]string, 0, len(acUrls))
+	for _, u := range acUrls {
+		endpoints = append(endpoints, u.String())
+	}
 	lgc := zap.NewProductionConfig()
 	lgc.Encoding = log.ZapEncodingName
 	client, err := clientv3.New(clientv3.Config{
@@ -218,6 +253,8 @@ func CreateClients(tlsConfig *tls.Config, acUrls []url.URL) (*clientv3.Client, *
 		DialTimeout: defaultEtcdClientTimeout,
 		TLS:         tlsConfig,
 		LogConfig:   &lgc,
+		Username:    username,
+		Password:    password,
 	})
 	if err != nil {
 		return nil, nil, errs.ErrNewEtcdClient.Wrap(err).GenWithStackByCause()
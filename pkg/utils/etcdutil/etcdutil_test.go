@@ -108,6 +108,51 @@ func TestMemberHelpers(t *testing.T) {
 	re.Equal(uint64(etcd1.Server.ID()), listResp3.Members[0].ID)
 }
 
+func TestLearnerHelpers(t *testing.T) {
+	t.Parallel()
+	re := require.New(t)
+	cfg1 := NewTestSingleConfig(t)
+	etcd1, err := embed.StartEtcd(cfg1)
+	re.NoError(err)
+	defer etcd1.Close()
+	client1, err := clientv3.New(clientv3.Config{
+		Endpoints: []string{cfg1.LCUrls[0].String()},
+	})
+	re.NoError(err)
+	defer client1.Close()
+	<-etcd1.Server.ReadyNotify()
+
+	alarmResp, err := ListEtcdAlarms(client1)
+	re.NoError(err)
+	re.Empty(alarmResp.Alarms)
+
+	cfg2 := NewTestSingleConfig(t)
+	cfg2.Name = "etcd2"
+	cfg2.InitialCluster = cfg1.InitialCluster + fmt.Sprintf(",%s=%s", cfg2.Name, &cfg2.LPUrls[0])
+	cfg2.ClusterState = embed.ClusterStateFlagExisting
+	addResp, err := AddEtcdLearner(client1, []string{cfg2.LPUrls[0].String()})
+	re.NoError(err)
+	re.True(addResp.Member.IsLearner)
+	// The learner can not be promoted before it starts.
+	_, err = PromoteEtcdLearner(client1, addResp.Member.ID)
+	re.Error(err)
+
+	etcd2, err := embed.StartEtcd(cfg2)
+	re.NoError(err)
+	defer etcd2.Close()
+	<-etcd2.Server.ReadyNotify()
+	re.Eventually(func() bool {
+		_, err = PromoteEtcdLearner(client1, addResp.Member.ID)
+		return err == nil
+	}, 10*time.Second, 100*time.Millisecond)
+	listResp, err := ListEtcdMembers(client1)
+	re.NoError(err)
+	re.Len(listResp.Members, 2)
+	for _, m := range listResp.Members {
+		re.False(m.IsLearner)
+	}
+}
+
 func TestEtcdKVGet(t *testing.T) {
 	t.Parallel()
 	re := require.New(t)
@@ -0,0 +1,130 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcutil
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/tikv/pd/pkg/errs"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// ErrorCodeMetadataKey is the trailer key of the code of the error.
+	ErrorCodeMetadataKey = "pd-error-code"
+	// ErrorComponentMetadataKey is the trailer key of the component of the error.
+	ErrorComponentMetadataKey = "pd-error-component"
+	// ErrorRetryableMetadataKey is the trailer key of whether the request is retryable.
+	ErrorRetryableMetadataKey = "pd-error-retryable"
+	// ErrorDetailMetadataKeyPrefix is the trailer key prefix of the details of the error.
+	ErrorDetailMetadataKeyPrefix = "pd-error-detail-"
+)
+
+// DescribeError returns the machine-readable form of the error returned by
+// the gRPC APIs. The code of the gRPC status, e.g. "Unavailable", is used if
+// the error has no RFC code.
+func DescribeError(err error) *errs.Detail {
+	detail := errs.Describe(err)
+	if detail == nil {
+		return nil
+	}
+	if s, ok := status.FromError(err); ok {
+		detail.Message = s.Message()
+		detail.Retryable = detail.Retryable || isRetryableCode(s.Code())
+		if detail.Code == "" {
+			detail.Code = s.Code().String()
+		}
+	}
+	if detail.Code == "" {
+		detail.Code = codes.Unknown.String()
+	}
+	return detail
+}
+
+// StatusError converts the error to a gRPC status error, and sets the
+// machine-readable form of it into the trailer of the call in the context.
+// The status error is returned as it is.
+func StatusError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	detail := DescribeError(err)
+	// The trailer can't be set if the context is not from the gRPC server.
+	_ = grpc.SetTrailer(ctx, errorMetadata(detail))
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	code := codes.Unknown
+	if detail.Retryable {
+		code = codes.Unavailable
+	}
+	return status.Error(code, err.Error())
+}
+
+// ParseError parses the machine-readable form of the error from the trailer
+// received with the error. The result is described by the status of the error
+// only if the trailer doesn't contain it, e.g. the server is an old version.
+func ParseError(trailer metadata.MD, err error) *errs.Detail {
+	if err == nil {
+		return nil
+	}
+	detail := DescribeError(err)
+	code := trailer.Get(ErrorCodeMetadataKey)
+	if len(code) == 0 {
+		return detail
+	}
+	detail.Code = code[0]
+	if component := trailer.Get(ErrorComponentMetadataKey); len(component) > 0 {
+		detail.Component = component[0]
+	}
+	if retryable := trailer.Get(ErrorRetryableMetadataKey); len(retryable) > 0 {
+		detail.Retryable, _ = strconv.ParseBool(retryable[0])
+	}
+	for key, values := range trailer {
+		if !strings.HasPrefix(key, ErrorDetailMetadataKeyPrefix) || len(values) == 0 {
+			continue
+		}
+		if detail.Details == nil {
+			detail.Details = make(map[string]string)
+		}
+		detail.Details[strings.TrimPrefix(key, ErrorDetailMetadataKeyPrefix)] = values[0]
+	}
+	return detail
+}
+
+func errorMetadata(detail *errs.Detail) metadata.MD {
+	md := metadata.Pairs(
+		ErrorCodeMetadataKey, detail.Code,
+		ErrorComponentMetadataKey, detail.Component,
+		ErrorRetryableMetadataKey, strconv.FormatBool(detail.Retryable),
+	)
+	for key, value := range detail.Details {
+		md.Set(ErrorDetailMetadataKeyPrefix+key, value)
+	}
+	return md
+}
+
+func isRetryableCode(code codes.Code) bool {
+	switch code {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	}
+	return false
+}
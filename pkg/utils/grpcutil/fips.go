@@ -0,0 +1,103 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+
+	"github.com/pingcap/errors"
+)
+
+// fipsMinRSABits is the min size of the RSA keys approved by FIPS 186-4.
+const fipsMinRSABits = 2048
+
+// FIPSCipherSuites are the FIPS approved cipher suites of TLS 1.2.
+var FIPSCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
+}
+
+// FIPSCurves are the FIPS approved curves of the key exchange.
+var FIPSCurves = []tls.CurveID{tls.CurveP256, tls.CurveP384, tls.CurveP521}
+
+// IsFIPSBuild returns whether PD is built with the FIPS validated BoringCrypto
+// module, where only the FIPS approved TLS settings are allowed.
+func IsFIPSBuild() bool {
+	return fipsBuild
+}
+
+// IsFIPS returns whether the FIPS mode is enabled by the config or the build.
+func (s TLSConfig) IsFIPS() bool {
+	return s.FIPSMode || fipsBuild
+}
+
+// ApplyFIPS restricts the TLS config to TLS 1.2 with the FIPS approved cipher
+// suites and curves. TLS 1.3 is disabled since its cipher suites can't be
+// configured in Go.
+func ApplyFIPS(cfg *tls.Config) {
+	cfg.MinVersion = tls.VersionTLS12
+	cfg.MaxVersion = tls.VersionTLS12
+	cfg.CipherSuites = FIPSCipherSuites
+	cfg.CurvePreferences = FIPSCurves
+}
+
+// ValidateFIPS checks the TLS settings for the FIPS mode. TLS should be enabled,
+// and the certificate should have an RSA key of at least 2048 bits or an ECDSA
+// key on a FIPS approved curve.
+func (s TLSConfig) ValidateFIPS() error {
+	if !s.IsFIPS() {
+		return nil
+	}
+	if len(s.CertPath) == 0 || len(s.KeyPath) == 0 {
+		return errors.New("FIPS mode requires TLS, cert-path and key-path should be set")
+	}
+	data, err := os.ReadFile(s.CertPath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return errors.Errorf("failed to decode the certificate %s", s.CertPath)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < fipsMinRSABits {
+			return errors.Errorf("FIPS mode requires RSA keys of at least %d bits, the key of %s has %d bits", fipsMinRSABits, s.CertPath, key.N.BitLen())
+		}
+	case *ecdsa.PublicKey:
+		switch key.Curve {
+		case elliptic.P256(), elliptic.P384(), elliptic.P521():
+		default:
+			return errors.Errorf("FIPS mode requires ECDSA keys on P-256, P-384 or P-521, the key of %s is on %s", s.CertPath, key.Curve.Params().Name)
+		}
+	default:
+		return errors.Errorf("FIPS mode requires RSA or ECDSA keys, the key of %s is %T", s.CertPath, key)
+	}
+	return nil
+}
@@ -19,6 +19,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"net/url"
+	"time"
 
 	"github.com/pingcap/log"
 	"github.com/tikv/pd/pkg/errs"
@@ -28,8 +29,15 @@ import (
 	"google.golang.org/grpc/metadata"
 )
 
-// ForwardMetadataKey is used to record the forwarded host of PD.
-const ForwardMetadataKey = "pd-forwarded-host"
+const (
+	// ForwardMetadataKey is used to record the forwarded host of PD.
+	ForwardMetadataKey = "pd-forwarded-host"
+	// MaxStalenessMetadataKey is used to allow a PD follower to serve the read-only
+	// request from its synced cache if the cache is not staler than the given duration.
+	MaxStalenessMetadataKey = "pd-max-staleness"
+	// StalenessMetadataKey is used to report the staleness of the data served by a PD follower.
+	StalenessMetadataKey = "pd-staleness"
+)
 
 // TLSConfig is the configuration for supporting tls.
 type TLSConfig struct {
@@ -41,6 +49,9 @@ type TLSConfig struct {
 	KeyPath string `toml:"key-path" json:"key-path"`
 	// CertAllowedCN is a CN which must be provided by a client
 	CertAllowedCN []string `toml:"cert-allowed-cn" json:"cert-allowed-cn"`
+	// FIPSMode restricts the TLS versions, the cipher suites and the curves to
+	// the FIPS approved ones. It is always enabled by the boringcrypto build.
+	FIPSMode bool `toml:"fips-mode" json:"fips-mode"`
 
 	SSLCABytes   []byte
 	SSLCertBytes []byte
@@ -49,6 +60,17 @@ type TLSConfig struct {
 
 // ToTLSConfig generates tls config.
 func (s TLSConfig) ToTLSConfig() (*tls.Config, error) {
+	tlsConfig, err := s.toTLSConfig()
+	if err != nil || tlsConfig == nil {
+		return tlsConfig, err
+	}
+	if s.IsFIPS() {
+		ApplyFIPS(tlsConfig)
+	}
+	return tlsConfig, nil
+}
+
+func (s TLSConfig) toTLSConfig() (*tls.Config, error) {
 	if len(s.SSLCABytes) != 0 || len(s.SSLCertBytes) != 0 || len(s.SSLKEYBytes) != 0 {
 		cert, err := tls.X509KeyPair(s.SSLCertBytes, s.SSLKEYBytes)
 		if err != nil {
@@ -160,3 +182,28 @@ func GetForwardedHost(ctx context.Context) string {
 	}
 	return ""
 }
+
+// BuildMaxStalenessContext creates a context which allows a follower to serve the
+// request with data at most maxStaleness old.
+// It is used in client side.
+func BuildMaxStalenessContext(ctx context.Context, maxStaleness time.Duration) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, MaxStalenessMetadataKey, maxStaleness.String())
+}
+
+// GetMaxStaleness returns the max staleness in metadata. The second return value
+// is false if the request does not allow the follower to serve it.
+func GetMaxStaleness(ctx context.Context) (time.Duration, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return 0, false
+	}
+	t, ok := md[MaxStalenessMetadataKey]
+	if !ok || len(t) == 0 {
+		return 0, false
+	}
+	maxStaleness, err := time.ParseDuration(t[0])
+	if err != nil || maxStaleness < 0 {
+		return 0, false
+	}
+	return maxStaleness, true
+}
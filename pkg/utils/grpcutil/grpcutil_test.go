@@ -1,12 +1,20 @@
 package grpcutil
 
 import (
+	"context"
+	"crypto/tls"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/pingcap/errors"
 	"github.com/stretchr/testify/require"
 	"github.com/tikv/pd/pkg/errs"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 func loadTLSContent(re *require.Assertions, caPath, certPath, keyPath string) (caData, certData, keyData []byte) {
@@ -51,3 +59,90 @@ func TestToTLSConfig(t *testing.T) {
 	_, err = tlsConfig.ToTLSConfig()
 	re.True(errors.ErrorEqual(err, errs.ErrCryptoAppendCertsFromPEM))
 }
+
+func TestFIPS(t *testing.T) {
+	t.Parallel()
+	re := require.New(t)
+	tlsConfig := TLSConfig{
+		KeyPath:  "../../../tests/client/cert/pd-server-key.pem",
+		CertPath: "../../../tests/client/cert/pd-server.pem",
+		CAPath:   "../../../tests/client/cert/ca.pem",
+		FIPSMode: true,
+	}
+	re.NoError(tlsConfig.ValidateFIPS())
+	cfg, err := tlsConfig.ToTLSConfig()
+	re.NoError(err)
+	re.Equal(uint16(tls.VersionTLS12), cfg.MinVersion)
+	re.Equal(uint16(tls.VersionTLS12), cfg.MaxVersion)
+	re.Equal(FIPSCipherSuites, cfg.CipherSuites)
+	re.Equal(FIPSCurves, cfg.CurvePreferences)
+
+	// TLS should be enabled in the FIPS mode.
+	re.Error(TLSConfig{FIPSMode: true}.ValidateFIPS())
+	tlsConfig.CertPath = "../../../tests/client/cert/not-exist.pem"
+	re.Error(tlsConfig.ValidateFIPS())
+}
+
+func TestMaxStaleness(t *testing.T) {
+	t.Parallel()
+	re := require.New(t)
+	_, ok := GetMaxStaleness(context.Background())
+	re.False(ok)
+
+	// The server side sees the outgoing metadata of the client as incoming.
+	outgoing := BuildMaxStalenessContext(context.Background(), 3*time.Second)
+	md, ok := metadata.FromOutgoingContext(outgoing)
+	re.True(ok)
+	maxStaleness, ok := GetMaxStaleness(metadata.NewIncomingContext(context.Background(), md))
+	re.True(ok)
+	re.Equal(3*time.Second, maxStaleness)
+
+	for _, v := range []string{"", "abc", "-1s"} {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MaxStalenessMetadataKey, v))
+		_, ok = GetMaxStaleness(ctx)
+		re.False(ok)
+	}
+}
+
+func TestRegisterHealthAndReflection(t *testing.T) {
+	t.Parallel()
+	re := require.New(t)
+	gs := grpc.NewServer()
+	re.True(RegisterHealthAndReflection(gs, health.NewServer()))
+	services := gs.GetServiceInfo()
+	re.Contains(services, healthServiceName)
+	re.Contains(services, reflectionServiceName)
+	// The registered services are kept.
+	re.False(RegisterHealthAndReflection(gs, health.NewServer()))
+}
+
+func TestStatusError(t *testing.T) {
+	t.Parallel()
+	re := require.New(t)
+	re.NoError(StatusError(context.Background(), nil))
+
+	err := StatusError(context.Background(), errs.WithDetail(errs.ErrLeaderNil.FastGenByArgs(), "member-id", 1))
+	s, ok := status.FromError(err)
+	re.True(ok)
+	re.Equal(codes.Unavailable, s.Code())
+	detail := ParseError(errorMetadata(DescribeError(errs.WithDetail(errs.ErrLeaderNil.FastGenByArgs(), "member-id", 1))), err)
+	re.Equal("PD:server:ErrLeaderNil", detail.Code)
+	re.Equal("server", detail.Component)
+	re.True(detail.Retryable)
+	re.Equal("1", detail.Details["member-id"])
+
+	// The status error is returned as it is.
+	statusErr := status.Error(codes.FailedPrecondition, "mismatch cluster id")
+	re.Equal(statusErr, StatusError(context.Background(), statusErr))
+	detail = ParseError(nil, statusErr)
+	re.Equal(codes.FailedPrecondition.String(), detail.Code)
+	re.Equal(errs.UnknownComponent, detail.Component)
+	re.False(detail.Retryable)
+	re.Equal("mismatch cluster id", detail.Message)
+
+	err = StatusError(context.Background(), errors.New("test"))
+	s, ok = status.FromError(err)
+	re.True(ok)
+	re.Equal(codes.Unknown, s.Code())
+	re.Equal(codes.Unknown.String(), ParseError(nil, err).Code)
+}
@@ -0,0 +1,43 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcutil
+
+import (
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+const (
+	healthServiceName     = "grpc.health.v1.Health"
+	reflectionServiceName = "grpc.reflection.v1alpha.ServerReflection"
+)
+
+// RegisterHealthAndReflection registers the standard health service backed by hs
+// and the server reflection service to the gRPC server, so the common tools such
+// as grpcurl and the gRPC probes of Kubernetes work with it. The services which
+// are registered by others are kept, e.g. the health service of the embedded
+// etcd, and it returns false if hs is not registered.
+func RegisterHealthAndReflection(gs *grpc.Server, hs healthpb.HealthServer) bool {
+	services := gs.GetServiceInfo()
+	if _, ok := services[reflectionServiceName]; !ok {
+		reflection.Register(gs)
+	}
+	if _, ok := services[healthServiceName]; ok {
+		return false
+	}
+	healthpb.RegisterHealthServer(gs, hs)
+	return true
+}
@@ -18,8 +18,13 @@ import (
 	"bytes"
 	"encoding/hex"
 	"fmt"
+	"math/big"
 )
 
+// maxSplitPadding is the max number of the bytes appended to the keys when the
+// range is too small to be split.
+const maxSplitPadding = 8
+
 // BuildKeyRangeKey build key for a keyRange
 func BuildKeyRangeKey(startKey, endKey []byte) string {
 	return fmt.Sprintf("%s-%s", hex.EncodeToString(startKey), hex.EncodeToString(endKey))
@@ -40,3 +45,41 @@ func MinKey(a, b []byte) []byte {
 	}
 	return a
 }
+
+// SplitRange returns the n-1 keys splitting the range [startKey, endKey) into n
+// ranges evenly, which treats the keys as big-endian numbers. The keys are
+// padded with zeros if the range is too small, and nil is returned if it is
+// still too small to be split.
+func SplitRange(startKey, endKey []byte, n int) [][]byte {
+	if n <= 1 || bytes.Compare(startKey, endKey) >= 0 {
+		return nil
+	}
+	length := len(startKey)
+	if len(endKey) > length {
+		length = len(endKey)
+	}
+	for padding := 0; padding <= maxSplitPadding; padding++ {
+		size := length + padding
+		start := new(big.Int).SetBytes(padKey(startKey, size))
+		end := new(big.Int).SetBytes(padKey(endKey, size))
+		step := end.Sub(end, start).Div(end, big.NewInt(int64(n)))
+		if step.Sign() == 0 {
+			continue
+		}
+		keys := make([][]byte, 0, n-1)
+		key := start
+		for i := 1; i < n; i++ {
+			key.Add(key, step)
+			keys = append(keys, key.FillBytes(make([]byte, size)))
+		}
+		return keys
+	}
+	return nil
+}
+
+// padKey appends zeros to the key until it is of the size.
+func padKey(key []byte, size int) []byte {
+	res := make([]byte, size)
+	copy(res, key)
+	return res
+}
@@ -28,3 +28,23 @@ func TestKeyUtil(t *testing.T) {
 	key := BuildKeyRangeKey(startKey, endKey)
 	re.Equal("61-62", key)
 }
+
+func TestSplitRange(t *testing.T) {
+	t.Parallel()
+	re := require.New(t)
+	keys := SplitRange([]byte("a"), []byte("e"), 4)
+	re.Equal([][]byte{[]byte("b"), []byte("c"), []byte("d")}, keys)
+	// The keys are padded if the range is too small.
+	keys = SplitRange([]byte("a"), []byte("b"), 2)
+	re.Equal([][]byte{{'a', 0x80}}, keys)
+	keys = SplitRange([]byte("t1"), []byte("t1\x00\x01"), 3)
+	re.Len(keys, 2)
+	prev := []byte("t1")
+	for _, key := range append(keys, []byte("t1\x00\x01")) {
+		re.Less(string(prev), string(key))
+		prev = key
+	}
+	re.Nil(SplitRange([]byte("b"), []byte("a"), 2))
+	re.Nil(SplitRange([]byte("a"), []byte("b"), 1))
+	re.Nil(SplitRange([]byte("a"), []byte("a\x00"), 2))
+}
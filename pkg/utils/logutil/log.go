@@ -15,10 +15,15 @@
 package logutil
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync/atomic"
 
+	"github.com/pingcap/errors"
 	"github.com/pingcap/log"
 	"github.com/tikv/pd/pkg/errs"
 	"go.uber.org/zap"
@@ -67,15 +72,15 @@ func StringToZapLogLevel(level string) zapcore.Level {
 }
 
 // SetupLogger setup the logger.
-func SetupLogger(logConfig log.Config, logger **zap.Logger, logProps **log.ZapProperties, enabled ...bool) error {
+func SetupLogger(logConfig log.Config, logger **zap.Logger, logProps **log.ZapProperties, redact ...RedactMode) error {
 	lg, p, err := log.InitLogger(&logConfig, zap.AddStacktrace(zapcore.FatalLevel))
 	if err != nil {
 		return errs.ErrInitLogger.Wrap(err).FastGenWithCause()
 	}
 	*logger = lg
 	*logProps = p
-	if len(enabled) > 0 {
-		SetRedactLog(enabled[0])
+	if len(redact) > 0 {
+		SetRedactMode(redact[0])
 	}
 	return nil
 }
@@ -88,22 +93,103 @@ func LogPanic() {
 	}
 }
 
+// RedactMode is how the user data, e.g. the region keys, is redacted in the
+// logs, the API error messages and the audit records.
+type RedactMode string
+
+// The redact modes.
+const (
+	// RedactOff keeps the user data.
+	RedactOff RedactMode = "off"
+	// RedactMark wraps the user data with the markers ‹ and ›, so it can be
+	// found and removed by the tools before the logs are shared.
+	RedactMark RedactMode = "mark"
+	// RedactRemove replaces the user data with "?".
+	RedactRemove RedactMode = "remove"
+	// RedactHash replaces the user data with its hash, so the same data can
+	// still be correlated across the records.
+	RedactHash RedactMode = "hash"
+)
+
+const (
+	redactMarkLeft  = "‹"
+	redactMarkRight = "›"
+	// redactHashLen is the length of the hex encoded hash of the user data.
+	redactHashLen = 16
+)
+
+var redactMarkEscaper = strings.NewReplacer(redactMarkLeft, redactMarkLeft+redactMarkLeft, redactMarkRight, redactMarkRight+redactMarkRight)
+
+// ParseRedactMode parses the redact mode. The boolean values are also accepted
+// for compatibility, where true is RedactRemove and false is RedactOff.
+func ParseRedactMode(s string) (RedactMode, error) {
+	switch mode := RedactMode(strings.ToLower(s)); mode {
+	case RedactOff, RedactMark, RedactRemove, RedactHash:
+		return mode, nil
+	case "", "false":
+		return RedactOff, nil
+	case "true":
+		return RedactRemove, nil
+	}
+	return RedactOff, errors.Errorf("invalid redact mode %s, should be one of off, mark, remove and hash", s)
+}
+
+// UnmarshalTOML implements toml.Unmarshaler, which accepts a boolean or a mode.
+func (m *RedactMode) UnmarshalTOML(data interface{}) error {
+	var err error
+	switch v := data.(type) {
+	case bool:
+		*m, err = ParseRedactMode(strconv.FormatBool(v))
+	case string:
+		*m, err = ParseRedactMode(v)
+	default:
+		err = errors.Errorf("invalid redact mode %v", data)
+	}
+	return err
+}
+
+// UnmarshalJSON implements json.Unmarshaler, which accepts a boolean or a mode.
+func (m *RedactMode) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+	}
+	return m.UnmarshalTOML(v)
+}
+
 var (
-	enabledRedactLog atomic.Value
+	redactMode atomic.Value
 )
 
 func init() {
-	SetRedactLog(false)
+	SetRedactMode(RedactOff)
+}
+
+// GetRedactMode returns the redact mode.
+func GetRedactMode() RedactMode {
+	return redactMode.Load().(RedactMode)
+}
+
+// SetRedactMode sets the redact mode. An empty mode is RedactOff.
+func SetRedactMode(mode RedactMode) {
+	if len(mode) == 0 {
+		mode = RedactOff
+	}
+	redactMode.Store(mode)
 }
 
 // IsRedactLogEnabled indicates whether the log desensitization is enabled
 func IsRedactLogEnabled() bool {
-	return enabledRedactLog.Load().(bool)
+	return GetRedactMode() != RedactOff
 }
 
-// SetRedactLog sets enabledRedactLog
+// SetRedactLog sets the redact mode to RedactRemove if enabled, otherwise RedactOff.
 func SetRedactLog(enabled bool) {
-	enabledRedactLog.Store(enabled)
+	if enabled {
+		SetRedactMode(RedactRemove)
+	} else {
+		SetRedactMode(RedactOff)
+	}
 }
 
 // ZapRedactByteString receives []byte argument and return omitted information zap.Field if redact log enabled
@@ -123,32 +209,47 @@ func ZapRedactStringer(key string, arg fmt.Stringer) zap.Field {
 
 // RedactBytes receives []byte argument and return omitted information if redact log enabled
 func RedactBytes(arg []byte) []byte {
-	if IsRedactLogEnabled() {
-		return []byte("?")
+	if mode := GetRedactMode(); mode != RedactOff {
+		return []byte(redact(mode, string(arg)))
 	}
 	return arg
 }
 
 // RedactString receives string argument and return omitted information if redact log enabled
 func RedactString(arg string) string {
-	if IsRedactLogEnabled() {
-		return "?"
-	}
-	return arg
+	return redact(GetRedactMode(), arg)
 }
 
 // RedactStringer receives stringer argument and return omitted information if redact log enabled
 func RedactStringer(arg fmt.Stringer) fmt.Stringer {
-	if IsRedactLogEnabled() {
-		return stringer{}
+	if mode := GetRedactMode(); mode != RedactOff {
+		return stringer{mode: mode, arg: arg}
+	}
+	return arg
+}
+
+func redact(mode RedactMode, arg string) string {
+	switch mode {
+	case RedactMark:
+		return redactMarkLeft + redactMarkEscaper.Replace(arg) + redactMarkRight
+	case RedactRemove:
+		return "?"
+	case RedactHash:
+		sum := sha256.Sum256([]byte(arg))
+		return hex.EncodeToString(sum[:])[:redactHashLen]
 	}
 	return arg
 }
 
 type stringer struct {
+	mode RedactMode
+	arg  fmt.Stringer
 }
 
 // String implement fmt.Stringer
 func (s stringer) String() string {
-	return "?"
+	if s.mode == RedactRemove {
+		return "?"
+	}
+	return redact(s.mode, s.arg.String())
 }
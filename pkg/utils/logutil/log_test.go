@@ -84,3 +84,50 @@ func TestRedactLog(t *testing.T) {
 		}
 	}
 }
+
+func TestRedactMode(t *testing.T) {
+	re := require.New(t)
+	defer SetRedactMode(RedactOff)
+	for _, s := range []string{"", "false", "off", "OFF"} {
+		mode, err := ParseRedactMode(s)
+		re.NoError(err)
+		re.Equal(RedactOff, mode)
+	}
+	mode, err := ParseRedactMode("true")
+	re.NoError(err)
+	re.Equal(RedactRemove, mode)
+	_, err = ParseRedactMode("marker")
+	re.Error(err)
+	var m RedactMode
+	re.NoError(m.UnmarshalJSON([]byte("true")))
+	re.Equal(RedactRemove, m)
+	re.NoError(m.UnmarshalJSON([]byte(`"mark"`)))
+	re.Equal(RedactMark, m)
+	re.Error(m.UnmarshalJSON([]byte("1")))
+
+	SetRedactMode(RedactMark)
+	re.True(IsRedactLogEnabled())
+	re.Equal("‹foo›", RedactString("foo"))
+	re.Equal("‹a‹‹b›››", RedactString("a‹b›"))
+	re.Equal([]byte("‹foo›"), RedactBytes([]byte("foo")))
+	re.Equal("‹foo›", RedactStringer(testStringer("foo")).String())
+
+	SetRedactMode(RedactHash)
+	hash := RedactString("foo")
+	re.Len(hash, redactHashLen)
+	re.Equal(hash, RedactString("foo"))
+	re.NotEqual(hash, RedactString("bar"))
+	re.Equal(hash, RedactStringer(testStringer("foo")).String())
+
+	SetRedactMode(RedactRemove)
+	re.Equal("?", RedactStringer(testStringer("foo")).String())
+	SetRedactMode(RedactOff)
+	re.False(IsRedactLogEnabled())
+	re.Equal("foo", RedactStringer(testStringer("foo")).String())
+}
+
+type testStringer string
+
+func (s testStringer) String() string {
+	return string(s)
+}
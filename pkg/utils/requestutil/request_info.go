@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/tikv/pd/pkg/utils/apiutil"
+	"github.com/tikv/pd/pkg/utils/logutil"
 )
 
 // RequestInfo holds service information from http.Request
@@ -38,7 +39,8 @@ type RequestInfo struct {
 
 func (info *RequestInfo) String() string {
 	s := fmt.Sprintf("{ServiceLabel:%s, Method:%s, Component:%s, IP:%s, StartTime:%s, URLParam:%s, BodyParam:%s}",
-		info.ServiceLabel, info.Method, info.Component, info.IP, time.Unix(info.StartTimeStamp, 0), info.URLParam, info.BodyParam)
+		info.ServiceLabel, info.Method, info.Component, info.IP, time.Unix(info.StartTimeStamp, 0),
+		logutil.RedactString(info.URLParam), logutil.RedactString(info.BodyParam))
 	return s
 }
 
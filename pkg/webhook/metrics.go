@@ -0,0 +1,40 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	webhookDeliveryCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "webhook",
+			Name:      "deliveries_total",
+			Help:      "Counter of the events delivered, failed to deliver and dropped by the webhooks.",
+		}, []string{"webhook", "result"})
+
+	webhookRetryCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "webhook",
+			Name:      "retries_total",
+			Help:      "Counter of the retries to deliver the events to the webhooks.",
+		}, []string{"webhook"})
+)
+
+func init() {
+	prometheus.MustRegister(webhookDeliveryCounter)
+	prometheus.MustRegister(webhookRetryCounter)
+}
@@ -0,0 +1,447 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/event"
+	"github.com/tikv/pd/pkg/slice"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/utils/logutil"
+	"github.com/tikv/pd/pkg/utils/syncutil"
+	"go.uber.org/zap"
+)
+
+// The headers of the requests to the webhooks, whose bodies are the events.
+const (
+	// EventHeader is the type of the event.
+	EventHeader = "X-PD-Event"
+	// DeliveryHeader is the unique ID of the event, which is the same across
+	// the retries, so the receivers can drop the duplicated ones.
+	DeliveryHeader = "X-PD-Delivery"
+	// SignatureHeader is "sha256={signature}", where the signature is returned
+	// by Sign. It is absent if the webhook has no secret.
+	SignatureHeader = "X-PD-Signature"
+)
+
+const (
+	// DefaultMaxRetries is the max retries of a webhook if it is not given.
+	DefaultMaxRetries = 3
+	// maxRetriesLimit is the upper bound of the max retries of a webhook.
+	maxRetriesLimit = 10
+	// deliveryTimeout is the timeout of each request to a webhook.
+	deliveryTimeout = 10 * time.Second
+	minRetryBackoff = time.Second
+	maxRetryBackoff = 30 * time.Second
+	// queueSize is the max number of the events waiting to be sent to a
+	// webhook, the new events are dropped once it is full.
+	queueSize = 1024
+	// hubCheckInterval is the interval to check whether the cluster is started
+	// or restarted with a new event hub.
+	hubCheckInterval = time.Second
+)
+
+// Status is the delivery status of a webhook on the current leader.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type Status struct {
+	Delivered uint64 `json:"delivered"`
+	Failed    uint64 `json:"failed"`
+	// Dropped is the number of the events dropped since too many events are
+	// waiting to be sent.
+	Dropped         uint64     `json:"dropped"`
+	LastDeliveredAt *time.Time `json:"last_delivered_at,omitempty"`
+	LastFailedAt    *time.Time `json:"last_failed_at,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the body with the secret, which
+// the receivers use to verify the requests.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type delivery struct {
+	id    string
+	event *event.Event
+}
+
+// worker sends the events to a webhook one by one.
+type worker struct {
+	syncutil.Mutex
+	webhook *endpoint.Webhook
+	status  Status
+	queue   chan *delivery
+	cancel  context.CancelFunc
+}
+
+// Manager keeps the webhooks, and calls them on the cluster events when the
+// server is the leader. The events not sent yet are lost once the leader changes.
+type Manager struct {
+	syncutil.Mutex
+	storage endpoint.WebhookStorage
+	// getHub returns the event hub of the running cluster, nil if the cluster
+	// is not running.
+	getHub func() *event.Hub
+	client *http.Client
+	// ctx is the context of the current leadership, nil if it is never the leader.
+	ctx     context.Context
+	workers map[string]*worker
+}
+
+// NewManager creates a webhook manager sending the events from the hub returned
+// by getHub.
+func NewManager(storage endpoint.WebhookStorage, getHub func() *event.Hub) *Manager {
+	return &Manager{
+		storage: storage,
+		getHub:  getHub,
+		client:  &http.Client{Timeout: deliveryTimeout},
+		workers: make(map[string]*worker),
+	}
+}
+
+// OnLeader is the leader callback, which loads the webhooks and sends the
+// events to them during the leadership.
+func (m *Manager) OnLeader(ctx context.Context) {
+	webhooks, err := m.storage.LoadAllWebhooks()
+	if err != nil {
+		log.Error("failed to load the webhooks", errs.ZapError(err))
+	}
+	m.Lock()
+	defer m.Unlock()
+	m.ctx = ctx
+	m.workers = make(map[string]*worker)
+	for _, webhook := range webhooks {
+		m.startWorkerLocked(webhook)
+	}
+	go m.dispatch(ctx)
+}
+
+func (m *Manager) isLeaderLocked() bool {
+	return m.ctx != nil && m.ctx.Err() == nil
+}
+
+func (m *Manager) startWorkerLocked(webhook *endpoint.Webhook) {
+	ctx, cancel := context.WithCancel(m.ctx)
+	w := &worker{webhook: webhook, queue: make(chan *delivery, queueSize), cancel: cancel}
+	m.workers[webhook.ID] = w
+	go m.runWorker(ctx, w)
+}
+
+// SaveWebhook creates or replaces the webhook.
+func (m *Manager) SaveWebhook(webhook *endpoint.Webhook) (*endpoint.Webhook, error) {
+	if err := validate(webhook); err != nil {
+		return nil, err
+	}
+	m.Lock()
+	defer m.Unlock()
+	old, err := m.storage.LoadWebhook(webhook.ID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	webhook.CreatedAt, webhook.UpdatedAt = now, now
+	if old != nil {
+		webhook.CreatedAt = old.CreatedAt
+	}
+	if err := m.storage.SaveWebhook(webhook); err != nil {
+		return nil, err
+	}
+	if m.isLeaderLocked() {
+		if w, ok := m.workers[webhook.ID]; ok {
+			w.Lock()
+			w.webhook = webhook
+			w.Unlock()
+		} else {
+			m.startWorkerLocked(webhook)
+		}
+	}
+	log.Info("webhook saved", zap.String("id", webhook.ID), zap.String("url", webhook.URL), zap.Strings("event-types", webhook.EventTypes))
+	return webhook, nil
+}
+
+// RemoveWebhook removes the webhook, the events not sent yet are dropped.
+func (m *Manager) RemoveWebhook(id string) error {
+	m.Lock()
+	defer m.Unlock()
+	old, err := m.storage.LoadWebhook(id)
+	if err != nil {
+		return err
+	}
+	if old == nil {
+		return errs.ErrWebhookNotFound.FastGenByArgs(id)
+	}
+	if err := m.storage.RemoveWebhook(id); err != nil {
+		return err
+	}
+	if w, ok := m.workers[id]; ok {
+		w.cancel()
+		delete(m.workers, id)
+	}
+	log.Info("webhook removed", zap.String("id", id))
+	return nil
+}
+
+// GetWebhook returns the webhook.
+func (m *Manager) GetWebhook(id string) (*endpoint.Webhook, error) {
+	webhook, err := m.storage.LoadWebhook(id)
+	if err != nil {
+		return nil, err
+	}
+	if webhook == nil {
+		return nil, errs.ErrWebhookNotFound.FastGenByArgs(id)
+	}
+	return webhook, nil
+}
+
+// GetWebhooks returns all the webhooks sorted by the IDs.
+func (m *Manager) GetWebhooks() ([]*endpoint.Webhook, error) {
+	webhooks, err := m.storage.LoadAllWebhooks()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+	return webhooks, nil
+}
+
+// GetStatus returns the delivery status of the webhook, or nil if the server
+// is not the leader or the webhook does not exist.
+func (m *Manager) GetStatus(id string) *Status {
+	m.Lock()
+	defer m.Unlock()
+	w, ok := m.workers[id]
+	if !ok || !m.isLeaderLocked() {
+		return nil
+	}
+	w.Lock()
+	defer w.Unlock()
+	status := w.status
+	return &status
+}
+
+func validate(webhook *endpoint.Webhook) error {
+	if webhook.ID == "" || strings.Contains(webhook.ID, "/") {
+		return errs.ErrInvalidWebhook.FastGenByArgs(fmt.Sprintf("invalid id %q", webhook.ID))
+	}
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errs.ErrInvalidWebhook.FastGenByArgs(fmt.Sprintf("invalid url %q", webhook.URL))
+	}
+	if webhook.MaxRetries < 0 || webhook.MaxRetries > maxRetriesLimit {
+		return errs.ErrInvalidWebhook.FastGenByArgs(fmt.Sprintf("max retries must be in [0, %d]", maxRetriesLimit))
+	}
+	for _, typ := range webhook.EventTypes {
+		if typ == "" {
+			return errs.ErrInvalidWebhook.FastGenByArgs("empty event type")
+		}
+	}
+	return nil
+}
+
+// match returns whether the event passes the filters of the webhook.
+func match(webhook *endpoint.Webhook, e *event.Event) bool {
+	if len(webhook.EventTypes) > 0 && !slice.Contains(webhook.EventTypes, string(e.Type)) &&
+		!slice.Contains(webhook.EventTypes, e.Type.Category()) {
+		return false
+	}
+	if len(webhook.StoreIDs) > 0 && !slice.Contains(webhook.StoreIDs, e.StoreID) {
+		return false
+	}
+	for key, values := range webhook.Attributes {
+		value, ok := e.Attributes[key]
+		if !ok || !slice.Contains(values, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// dispatch sends the events of the running cluster to the matched webhooks
+// until the leadership is lost.
+func (m *Manager) dispatch(ctx context.Context) {
+	defer logutil.LogPanic()
+	var (
+		hub *event.Hub
+		sub *event.Subscription
+	)
+	for {
+		if h := m.getHub(); h != hub {
+			hub, sub = h, nil
+			if hub != nil {
+				sub = subscribeAll(hub)
+			}
+		}
+		if sub == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(hubCheckInterval):
+			}
+			continue
+		}
+		// Wake up from time to time to check whether the cluster is restarted.
+		nextCtx, cancel := context.WithTimeout(ctx, hubCheckInterval)
+		events, err := sub.Next(nextCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			if errs.ErrEventCompacted.Equal(err) {
+				log.Warn("the webhooks fall behind the cluster events, some events are skipped", errs.ZapError(err))
+				sub, _ = hub.Subscribe("")
+			}
+			continue
+		}
+		m.enqueue(hub, events)
+	}
+}
+
+// subscribeAll subscribes the events since the hub is created, so the ones
+// published before the hub is noticed are also sent.
+func subscribeAll(hub *event.Hub) *event.Subscription {
+	sub, err := hub.Subscribe(hub.ResumeToken(&event.Event{}))
+	if err != nil {
+		// The earliest events are no longer kept.
+		sub, _ = hub.Subscribe("")
+	}
+	return sub
+}
+
+func (m *Manager) enqueue(hub *event.Hub, events []*event.Event) {
+	m.Lock()
+	defer m.Unlock()
+	for _, e := range events {
+		d := &delivery{id: hub.ResumeToken(e), event: e}
+		for id, w := range m.workers {
+			w.Lock()
+			matched := match(w.webhook, e)
+			w.Unlock()
+			if !matched {
+				continue
+			}
+			select {
+			case w.queue <- d:
+			default:
+				w.Lock()
+				w.status.Dropped++
+				w.Unlock()
+				webhookDeliveryCounter.WithLabelValues(id, "dropped").Inc()
+			}
+		}
+	}
+}
+
+func (m *Manager) runWorker(ctx context.Context, w *worker) {
+	defer logutil.LogPanic()
+	for {
+		var d *delivery
+		select {
+		case <-ctx.Done():
+			return
+		case d = <-w.queue:
+		}
+		w.Lock()
+		webhook := w.webhook
+		w.Unlock()
+		err := m.send(ctx, webhook, d)
+		if ctx.Err() != nil {
+			return
+		}
+		now := time.Now()
+		w.Lock()
+		if err == nil {
+			w.status.Delivered++
+			w.status.LastDeliveredAt = &now
+		} else {
+			w.status.Failed++
+			w.status.LastFailedAt = &now
+			w.status.LastError = err.Error()
+		}
+		w.Unlock()
+		if err != nil {
+			webhookDeliveryCounter.WithLabelValues(webhook.ID, "failed").Inc()
+			log.Warn("failed to send the event to the webhook",
+				zap.String("id", webhook.ID),
+				zap.String("url", webhook.URL),
+				zap.String("delivery", d.id),
+				errs.ZapError(err))
+			continue
+		}
+		webhookDeliveryCounter.WithLabelValues(webhook.ID, "delivered").Inc()
+	}
+}
+
+// send posts the event to the webhook, and retries with backoff if it fails.
+func (m *Manager) send(ctx context.Context, webhook *endpoint.Webhook, d *delivery) error {
+	body, err := json.Marshal(d.event)
+	if err != nil {
+		return errs.ErrJSONMarshal.Wrap(err).GenWithStackByCause()
+	}
+	backoff := minRetryBackoff
+	for i := 0; ; i++ {
+		err = m.post(ctx, webhook, d, body)
+		if err == nil || i >= webhook.MaxRetries {
+			return err
+		}
+		webhookRetryCounter.WithLabelValues(webhook.ID).Inc()
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
+
+func (m *Manager) post(ctx context.Context, webhook *endpoint.Webhook, d *delivery, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return errs.ErrNewHTTPRequest.Wrap(err).GenWithStackByCause()
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(d.event.Type))
+	req.Header.Set(DeliveryHeader, d.id)
+	if len(webhook.Secret) > 0 {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(webhook.Secret, body))
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return errs.ErrSendRequest.Wrap(err).GenWithStackByCause()
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return errors.Errorf("webhook responds %s", resp.Status)
+	}
+	return nil
+}
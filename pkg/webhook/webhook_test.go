@@ -0,0 +1,151 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/event"
+	"github.com/tikv/pd/pkg/storage"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/utils/syncutil"
+)
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+type receiver struct {
+	syncutil.Mutex
+	// failures is the number of the requests to fail before succeeding.
+	failures int
+	requests []*receivedRequest
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.Lock()
+	defer r.Unlock()
+	r.requests = append(r.requests, &receivedRequest{header: req.Header, body: body})
+	if r.failures > 0 {
+		r.failures--
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (r *receiver) received() []*receivedRequest {
+	r.Lock()
+	defer r.Unlock()
+	return append([]*receivedRequest{}, r.requests...)
+}
+
+func TestMatch(t *testing.T) {
+	re := require.New(t)
+	webhook := &endpoint.Webhook{}
+	storeDown := &event.Event{Type: event.StoreDown, StoreID: 1}
+	re.True(match(webhook, storeDown))
+	webhook.EventTypes = []string{"store"}
+	re.True(match(webhook, storeDown))
+	webhook.EventTypes = []string{"store-up", "operator-finished"}
+	re.False(match(webhook, storeDown))
+	webhook.EventTypes = []string{"store-down"}
+	webhook.StoreIDs = []uint64{2}
+	re.False(match(webhook, storeDown))
+	webhook.StoreIDs = []uint64{1, 2}
+	re.True(match(webhook, storeDown))
+
+	webhook = &endpoint.Webhook{Attributes: map[string][]string{"status": {"TIMEOUT", "CANCEL"}}}
+	re.False(match(webhook, storeDown))
+	re.True(match(webhook, &event.Event{Type: event.OperatorFinished, Attributes: map[string]string{"status": "TIMEOUT"}}))
+	re.False(match(webhook, &event.Event{Type: event.OperatorFinished, Attributes: map[string]string{"status": "SUCCESS"}}))
+}
+
+func TestManager(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stores, operators := &receiver{}, &receiver{failures: 1}
+	storeServer, operatorServer := httptest.NewServer(stores), httptest.NewServer(operators)
+	defer storeServer.Close()
+	defer operatorServer.Close()
+
+	hub := event.NewHub(16)
+	manager := NewManager(storage.NewStorageWithMemoryBackend(), func() *event.Hub { return hub })
+	for _, webhook := range []*endpoint.Webhook{
+		{ID: "", URL: storeServer.URL},
+		{ID: "a/b", URL: storeServer.URL},
+		{ID: "store", URL: "ftp://127.0.0.1"},
+		{ID: "store", URL: storeServer.URL, MaxRetries: maxRetriesLimit + 1},
+	} {
+		_, err := manager.SaveWebhook(webhook)
+		re.Error(err)
+	}
+	_, err := manager.SaveWebhook(&endpoint.Webhook{ID: "store", URL: storeServer.URL, Secret: "secret", EventTypes: []string{"store"}})
+	re.NoError(err)
+	// The events published before the hub is noticed are also sent.
+	hub.Publish(&event.Event{Type: event.StoreDown, StoreID: 1})
+	manager.OnLeader(ctx)
+	_, err = manager.SaveWebhook(&endpoint.Webhook{
+		ID:         "operator",
+		URL:        operatorServer.URL,
+		Attributes: map[string][]string{"status": {"TIMEOUT"}},
+		MaxRetries: 1,
+	})
+	re.NoError(err)
+	webhooks, err := manager.GetWebhooks()
+	re.NoError(err)
+	re.Len(webhooks, 2)
+	re.Equal("operator", webhooks[0].ID)
+	re.Equal("store", webhooks[1].ID)
+
+	re.Eventually(func() bool { return len(stores.received()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	request := stores.received()[0]
+	re.Equal(string(event.StoreDown), request.header.Get(EventHeader))
+	re.Equal("sha256="+Sign("secret", request.body), request.header.Get(SignatureHeader))
+	re.NotEmpty(request.header.Get(DeliveryHeader))
+	re.Contains(string(request.body), `"store_id":1`)
+
+	// The failed delivery is retried with the same delivery ID.
+	hub.Publish(&event.Event{Type: event.OperatorFinished, RegionID: 2, Attributes: map[string]string{"status": "SUCCESS"}})
+	hub.Publish(&event.Event{Type: event.OperatorFinished, RegionID: 3, Attributes: map[string]string{"status": "TIMEOUT"}})
+	re.Eventually(func() bool {
+		status := manager.GetStatus("operator")
+		return status != nil && status.Delivered == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	requests := operators.received()
+	re.Len(requests, 2)
+	re.Equal(requests[0].header.Get(DeliveryHeader), requests[1].header.Get(DeliveryHeader))
+	re.Empty(requests[1].header.Get(SignatureHeader))
+	re.Contains(string(requests[1].body), `"region_id":3`)
+	status := manager.GetStatus("operator")
+	re.Zero(status.Failed)
+	re.NotNil(status.LastDeliveredAt)
+
+	re.NoError(manager.RemoveWebhook("store"))
+	re.Error(manager.RemoveWebhook("store"))
+	re.Nil(manager.GetStatus("store"))
+	_, err = manager.GetWebhook("store")
+	re.Error(err)
+	hub.Publish(&event.Event{Type: event.StoreUp, StoreID: 1})
+	time.Sleep(100 * time.Millisecond)
+	re.Len(stores.received(), 1)
+}
@@ -0,0 +1,64 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/pingcap/errors"
+	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/certmonitor"
+	"github.com/unrolled/render"
+)
+
+type certHandler struct {
+	svr *server.Server
+	rd  *render.Render
+}
+
+func newCertHandler(svr *server.Server, rd *render.Render) *certHandler {
+	return &certHandler{
+		svr: svr,
+		rd:  rd,
+	}
+}
+
+// @Tags     security
+// @Summary  Get the expiry of the TLS certificates and the recent renewals of the PD serving the request. Set the PD-Allow-follower-handle header to get the ones of a follower.
+// @Produce  json
+// @Success  200  {object}  certmonitor.Status
+// @Router   /security/certs [get]
+func (h *certHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	h.rd.JSON(w, http.StatusOK, h.svr.GetCertMonitor().GetStatus())
+}
+
+// @Tags     security
+// @Summary  Run the certificate renew command of the PD serving the request immediately.
+// @Produce  json
+// @Success  200  {object}  certmonitor.Renewal
+// @Failure  400  {string}  string  "The renew command is not configured."
+// @Router   /security/certs/renew [post]
+func (h *certHandler) Renew(w http.ResponseWriter, r *http.Request) {
+	renewal, err := h.svr.GetCertMonitor().Renew(r.Context())
+	if err != nil {
+		if errors.Cause(err) == certmonitor.ErrRenewDisabled {
+			h.rd.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, renewal)
+}
@@ -17,6 +17,7 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"testing"
 	"time"
 
@@ -315,6 +316,38 @@ func (suite *configTestSuite) TestConfigPDServer() {
 	suite.Equal(int(3), sc.FlowRoundByDigit)
 	suite.Equal(typeutil.NewDuration(time.Second), sc.MinResolvedTSPersistenceInterval)
 	suite.Equal(24*time.Hour, sc.MaxResetTSGap.Duration)
+	suite.Equal(int64(0), sc.LeaderLease)
+	suite.Equal(200*time.Millisecond, sc.ElectionRetryInterval.Duration)
+
+	ms = map[string]interface{}{
+		"pd-server.leader-lease":            5,
+		"pd-server.election-retry-interval": "500ms",
+	}
+	postData, err = json.Marshal(ms)
+	suite.NoError(err)
+	suite.NoError(tu.CheckPostJSON(testDialClient, addrPost, postData, tu.StatusOK(re)))
+	sc = &config.PDServerConfig{}
+	suite.NoError(tu.ReadGetJSON(re, testDialClient, addrGet, sc))
+	suite.Equal(int64(5), sc.LeaderLease)
+	suite.Equal(500*time.Millisecond, sc.ElectionRetryInterval.Duration)
+
+	for _, ms := range []map[string]interface{}{
+		{"pd-server.leader-lease": 3600},
+		{"pd-server.leader-lease": 1},
+		{"pd-server.election-retry-interval": "1ms"},
+	} {
+		postData, err = json.Marshal(ms)
+		suite.NoError(err)
+		suite.NoError(tu.CheckPostJSON(testDialClient, addrPost, postData, tu.Status(re, http.StatusBadRequest)))
+	}
+
+	ms = map[string]interface{}{
+		"pd-server.leader-lease":            0,
+		"pd-server.election-retry-interval": "200ms",
+	}
+	postData, err = json.Marshal(ms)
+	suite.NoError(err)
+	suite.NoError(tu.CheckPostJSON(testDialClient, addrPost, postData, tu.StatusOK(re)))
 }
 
 var ttlConfig = map[string]interface{}{
@@ -0,0 +1,73 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/tikv/pd/server"
+	"github.com/unrolled/render"
+)
+
+type encryptionHandler struct {
+	svr *server.Server
+	rd  *render.Render
+}
+
+func newEncryptionHandler(svr *server.Server, rd *render.Render) *encryptionHandler {
+	return &encryptionHandler{
+		svr: svr,
+		rd:  rd,
+	}
+}
+
+// @Tags     encryption
+// @Summary  Get the data encryption keys and the recent rotations. The keys themselves are not returned.
+// @Produce  json
+// @Success  200  {object}  encryption.RotationStatus
+// @Router   /encryption/keys [get]
+func (h *encryptionHandler) GetRotationStatus(w http.ResponseWriter, r *http.Request) {
+	h.rd.JSON(w, http.StatusOK, h.svr.GetEncryptionKeyManager().GetRotationStatus())
+}
+
+// @Tags     encryption
+// @Summary  Rotate the data encryption key immediately.
+// @Produce  json
+// @Success  200  {object}  encryption.KeyRotation
+// @Failure  400  {string}  string  "The encryption is not enabled."
+// @Router   /encryption/keys/rotate [post]
+func (h *encryptionHandler) RotateKey(w http.ResponseWriter, r *http.Request) {
+	rotation, err := h.svr.GetEncryptionKeyManager().RotateKey()
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, rotation)
+}
+
+// @Tags     encryption
+// @Summary  Check whether the master key is accessible, e.g. the KMS is reachable and the key is enabled.
+// @Produce  json
+// @Success  200  {object}  encryption.MasterKeyHealth
+// @Failure  503  {object}  encryption.MasterKeyHealth  "The master key is not accessible."
+// @Router   /encryption/master-key/health [get]
+func (h *encryptionHandler) CheckMasterKey(w http.ResponseWriter, r *http.Request) {
+	health := h.svr.GetEncryptionKeyManager().CheckMasterKey()
+	if !health.Healthy {
+		h.rd.JSON(w, http.StatusServiceUnavailable, health)
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, health)
+}
@@ -19,6 +19,8 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/pingcap/errors"
@@ -28,8 +30,14 @@ import (
 	"github.com/tikv/pd/pkg/slice"
 	"github.com/tikv/pd/pkg/utils/apiutil"
 	"github.com/tikv/pd/pkg/utils/etcdutil"
+	"github.com/tikv/pd/pkg/versioninfo"
 	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/cluster"
 	"github.com/unrolled/render"
+	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/embed"
+	"go.etcd.io/etcd/etcdserver/etcdserverpb"
+	"go.etcd.io/etcd/pkg/types"
 	"go.uber.org/zap"
 )
 
@@ -110,6 +118,16 @@ func getMembers(svr *server.Server) (*pdpb.GetMembersResponse, error) {
 	return members, nil
 }
 
+// checkEmbeddedEtcd checks the members are managed by the embedded etcd, the
+// members of the external etcd are not PD members and can not be changed.
+func (h *memberHandler) checkEmbeddedEtcd(w http.ResponseWriter) bool {
+	if h.svr.GetMember().IsExternalEtcd() {
+		h.rd.JSON(w, http.StatusBadRequest, "the members of the external etcd can not be changed by PD")
+		return false
+	}
+	return true
+}
+
 // @Tags     member
 // @Summary  Remove a PD server from the cluster.
 // @Param    name  path  string  true  "PD server name"
@@ -120,6 +138,9 @@ func getMembers(svr *server.Server) (*pdpb.GetMembersResponse, error) {
 // @Failure  500  {string}  string  "PD server failed to proceed the request."
 // @Router   /members/name/{name} [delete]
 func (h *memberHandler) DeleteMemberByName(w http.ResponseWriter, r *http.Request) {
+	if !h.checkEmbeddedEtcd(w) {
+		return
+	}
 	client := h.svr.GetClient()
 
 	// Get etcd ID by name.
@@ -130,9 +151,10 @@ func (h *memberHandler) DeleteMemberByName(w http.ResponseWriter, r *http.Reques
 		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	var member *etcdserverpb.Member
 	for _, m := range listResp.Members {
 		if name == m.Name {
-			id = m.ID
+			id, member = m.ID, m
 			break
 		}
 	}
@@ -140,6 +162,10 @@ func (h *memberHandler) DeleteMemberByName(w http.ResponseWriter, r *http.Reques
 		h.rd.JSON(w, http.StatusNotFound, fmt.Sprintf("not found, pd: %s", name))
 		return
 	}
+	if err := h.checkRemoveMember(r, member, listResp.Members); err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	// Delete config.
 	err = h.svr.GetMember().DeleteMemberLeaderPriority(id)
@@ -173,12 +199,29 @@ func (h *memberHandler) DeleteMemberByName(w http.ResponseWriter, r *http.Reques
 // @Failure  500  {string}  string  "PD server failed to proceed the request."
 // @Router   /members/id/{id} [delete]
 func (h *memberHandler) DeleteMemberByID(w http.ResponseWriter, r *http.Request) {
+	if !h.checkEmbeddedEtcd(w) {
+		return
+	}
 	idStr := mux.Vars(r)["id"]
 	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
 		h.rd.JSON(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	listResp, err := etcdutil.ListEtcdMembers(h.svr.GetClient())
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, m := range listResp.Members {
+		if m.ID != id {
+			continue
+		}
+		if err := h.checkRemoveMember(r, m, listResp.Members); err != nil {
+			h.rd.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
 
 	// Delete config.
 	err = h.svr.GetMember().DeleteMemberLeaderPriority(id)
@@ -256,6 +299,437 @@ func (h *memberHandler) SetMemberPropertyByName(w http.ResponseWriter, r *http.R
 	h.rd.JSON(w, http.StatusOK, "success")
 }
 
+// MemberReplacementInput is the input to replace a failed member.
+type MemberReplacementInput struct {
+	// PeerUrls are the peer URLs of the replacement, the ones of the failed
+	// member are used if it is empty.
+	PeerUrls []string `json:"peer-urls"`
+	// Force replaces the member even if it is still healthy.
+	Force bool `json:"force"`
+}
+
+// MemberReplacement is the guide to start the replacement of a failed member.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type MemberReplacement struct {
+	Name            string   `json:"name"`
+	RemovedMemberID uint64   `json:"removed_member_id"`
+	NewMemberID     uint64   `json:"new_member_id"`
+	PeerUrls        []string `json:"peer_urls"`
+	// InitialCluster and InitialClusterState are the configs to start the
+	// replacement with an empty data directory.
+	InitialCluster      string `json:"initial_cluster"`
+	InitialClusterState string `json:"initial_cluster_state"`
+}
+
+// @Tags     member
+// @Summary  Replace a permanently failed PD member, e.g. its disk is lost. The failed member is removed from the cluster, and a new member with the same name is added, which should be started with an empty data directory and the returned initial cluster configs. It is rejected if the quorum can not be kept after the replacement.
+// @Accept   json
+// @Param    name  path  string                  true   "PD server name"
+// @Param    body  body  MemberReplacementInput  false  "The replacement options"
+// @Produce  json
+// @Success  200  {object}  MemberReplacement
+// @Failure  400  {string}  string  "The input is invalid or the replacement is unsafe."
+// @Failure  404  {string}  string  "The member does not exist."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /members/name/{name}/replace [post]
+func (h *memberHandler) ReplaceMember(w http.ResponseWriter, r *http.Request) {
+	if !h.checkEmbeddedEtcd(w) {
+		return
+	}
+	var input MemberReplacementInput
+	if r.ContentLength != 0 {
+		if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &input); err != nil {
+			return
+		}
+	}
+	client := h.svr.GetClient()
+	listResp, err := etcdutil.ListEtcdMembers(client)
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	name := mux.Vars(r)["name"]
+	var failed *etcdserverpb.Member
+	for _, m := range listResp.Members {
+		if m.Name == name {
+			failed = m
+			break
+		}
+	}
+	if failed == nil {
+		h.rd.JSON(w, http.StatusNotFound, fmt.Sprintf("not found, pd: %s", name))
+		return
+	}
+	if failed.ID == h.svr.GetMember().GetEtcdLeader() || failed.ID == h.svr.GetLeader().GetMemberId() {
+		h.rd.JSON(w, http.StatusBadRequest, "the leader can not be replaced, please resign it first")
+		return
+	}
+
+	members, err := cluster.GetMembers(client)
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	healthMembers := cluster.CheckHealth(h.svr.GetHTTPClient(), members)
+	if _, ok := healthMembers[failed.ID]; ok && !input.Force {
+		h.rd.JSON(w, http.StatusBadRequest, fmt.Sprintf("pd %s is still healthy", name))
+		return
+	}
+	if !failed.IsLearner {
+		var voters, healthyVoters int
+		for _, m := range listResp.Members {
+			if m.IsLearner {
+				continue
+			}
+			voters++
+			if _, ok := healthMembers[m.ID]; ok && m.ID != failed.ID {
+				healthyVoters++
+			}
+		}
+		// The failed voter is replaced by a new voter, which is counted in the
+		// quorum at once but can't vote until it starts and catches up, so the
+		// other healthy voters must form the quorum of the same size.
+		if healthyVoters < voters/2+1 {
+			h.rd.JSON(w, http.StatusBadRequest, fmt.Sprintf(
+				"only %d of %d voters are healthy, the quorum can not be kept", healthyVoters, voters))
+			return
+		}
+	}
+
+	peerUrls := input.PeerUrls
+	if len(peerUrls) == 0 {
+		peerUrls = failed.PeerURLs
+	}
+	if err := h.svr.GetMember().DeleteMemberLeaderPriority(failed.ID); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := h.svr.GetMember().DeleteMemberDCLocationInfo(failed.ID); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if _, err := etcdutil.RemoveEtcdMember(client, failed.ID); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	log.Info("failed member is removed", zap.String("name", name), zap.Uint64("member-id", failed.ID))
+
+	var addResp *clientv3.MemberAddResponse
+	if failed.IsLearner {
+		addResp, err = etcdutil.AddEtcdLearner(client, peerUrls)
+	} else {
+		addResp, err = etcdutil.AddEtcdMember(client, peerUrls)
+	}
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError,
+			fmt.Sprintf("pd %s is removed, but failed to add the replacement: %s", name, err.Error()))
+		return
+	}
+	log.Info("replacement member is added", zap.String("name", name), zap.Uint64("member-id", addResp.Member.ID))
+
+	h.rd.JSON(w, http.StatusOK, &MemberReplacement{
+		Name:                name,
+		RemovedMemberID:     failed.ID,
+		NewMemberID:         addResp.Member.ID,
+		PeerUrls:            peerUrls,
+		InitialCluster:      buildInitialCluster(addResp, name),
+		InitialClusterState: embed.ClusterStateFlagExisting,
+	})
+}
+
+// buildInitialCluster returns the initial cluster config to start the added member with the given name.
+func buildInitialCluster(addResp *clientv3.MemberAddResponse, name string) string {
+	var initialCluster []string
+	for _, m := range addResp.Members {
+		memberName := m.Name
+		if m.ID == addResp.Member.ID {
+			memberName = name
+		}
+		for _, u := range m.PeerURLs {
+			initialCluster = append(initialCluster, fmt.Sprintf("%s=%s", memberName, u))
+		}
+	}
+	return strings.Join(initialCluster, ",")
+}
+
+// checkQuorum checks the healthy voters can still form the quorum after the voter
+// of removedID is removed and the learner of promotedID is promoted.
+func checkQuorum(members []*etcdserverpb.Member, healthMembers map[uint64]*pdpb.Member, removedID, promotedID uint64) error {
+	var voters, healthyVoters int
+	for _, m := range members {
+		if m.ID == removedID || (m.IsLearner && m.ID != promotedID) {
+			continue
+		}
+		voters++
+		if _, ok := healthMembers[m.ID]; ok {
+			healthyVoters++
+		}
+	}
+	if healthyVoters < voters/2+1 {
+		return errors.Errorf("only %d of %d voters are healthy after the change, the quorum can not be kept", healthyVoters, voters)
+	}
+	return nil
+}
+
+// checkRemoveMember checks the quorum can be kept after the member is removed.
+// It is skipped if the removal is forced, e.g. to remove a member when the quorum is already lost.
+func (h *memberHandler) checkRemoveMember(r *http.Request, member *etcdserverpb.Member, members []*etcdserverpb.Member) error {
+	if force, _ := strconv.ParseBool(r.URL.Query().Get("force")); force || member.IsLearner {
+		return nil
+	}
+	pdMembers, err := cluster.GetMembers(h.svr.GetClient())
+	if err != nil {
+		return err
+	}
+	return checkQuorum(members, cluster.CheckHealth(h.svr.GetHTTPClient(), pdMembers), member.ID, 0)
+}
+
+// checkNoSpaceAlarm checks no etcd member runs out of space, the member changes
+// are rejected in that case since the new member can not catch up.
+func (h *memberHandler) checkNoSpaceAlarm() error {
+	alarmResp, err := etcdutil.ListEtcdAlarms(h.svr.GetClient())
+	if err != nil {
+		return err
+	}
+	for _, alarm := range alarmResp.Alarms {
+		if alarm.Alarm == etcdserverpb.AlarmType_NOSPACE {
+			return errors.Errorf("member %x runs out of space", alarm.MemberID)
+		}
+	}
+	return nil
+}
+
+// MemberAdditionInput is the input to add a PD member as a learner.
+type MemberAdditionInput struct {
+	Name     string   `json:"name"`
+	PeerUrls []string `json:"peer-urls"`
+}
+
+// MemberAddition is the guide to start the added learner.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type MemberAddition struct {
+	Name     string   `json:"name"`
+	MemberID uint64   `json:"member_id"`
+	PeerUrls []string `json:"peer_urls"`
+	// InitialCluster and InitialClusterState are the configs to start the
+	// learner with an empty data directory.
+	InitialCluster      string `json:"initial_cluster"`
+	InitialClusterState string `json:"initial_cluster_state"`
+}
+
+// @Tags     member
+// @Summary  Add a PD member as a learner, which does not vote and does not affect the quorum. It should be started with an empty data directory and the returned initial cluster configs, and be promoted to a voter after it catches up.
+// @Accept   json
+// @Param    body  body  MemberAdditionInput  true  "The member to add"
+// @Produce  json
+// @Success  200  {object}  MemberAddition
+// @Failure  400  {string}  string  "The input is invalid or the addition is unsafe."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /members/learner [post]
+func (h *memberHandler) AddLearner(w http.ResponseWriter, r *http.Request) {
+	if !h.checkEmbeddedEtcd(w) {
+		return
+	}
+	var input MemberAdditionInput
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &input); err != nil {
+		return
+	}
+	if len(input.Name) == 0 || len(input.PeerUrls) == 0 {
+		h.rd.JSON(w, http.StatusBadRequest, "the name and peer urls are required")
+		return
+	}
+	if _, err := types.NewURLs(input.PeerUrls); err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	client := h.svr.GetClient()
+	listResp, err := etcdutil.ListEtcdMembers(client)
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, m := range listResp.Members {
+		if m.Name == input.Name {
+			h.rd.JSON(w, http.StatusBadRequest, fmt.Sprintf("pd %s already exists", input.Name))
+			return
+		}
+		for _, u := range m.PeerURLs {
+			if slice.AnyOf(input.PeerUrls, func(i int) bool { return input.PeerUrls[i] == u }) {
+				h.rd.JSON(w, http.StatusBadRequest, fmt.Sprintf("peer url %s is used by member %x", u, m.ID))
+				return
+			}
+		}
+	}
+	if err := h.checkNoSpaceAlarm(); err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	addResp, err := etcdutil.AddEtcdLearner(client, input.PeerUrls)
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	log.Info("learner member is added", zap.String("name", input.Name), zap.Uint64("member-id", addResp.Member.ID))
+	h.rd.JSON(w, http.StatusOK, &MemberAddition{
+		Name:                input.Name,
+		MemberID:            addResp.Member.ID,
+		PeerUrls:            input.PeerUrls,
+		InitialCluster:      buildInitialCluster(addResp, input.Name),
+		InitialClusterState: embed.ClusterStateFlagExisting,
+	})
+}
+
+// @Tags     member
+// @Summary  Promote a learner PD member to a voter. It is rejected if the learner is a witness, is unhealthy, runs an older version than the leader, or has not caught up with the leader yet.
+// @Param    name  path  string  true  "PD server name"
+// @Produce  json
+// @Success  200  {string}  string  "The learner is promoted."
+// @Failure  400  {string}  string  "The promotion is unsafe."
+// @Failure  404  {string}  string  "The member does not exist."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /members/name/{name}/promote [post]
+func (h *memberHandler) PromoteLearner(w http.ResponseWriter, r *http.Request) {
+	if !h.checkEmbeddedEtcd(w) {
+		return
+	}
+	client := h.svr.GetClient()
+	listResp, err := etcdutil.ListEtcdMembers(client)
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	name := mux.Vars(r)["name"]
+	var learner *etcdserverpb.Member
+	for _, m := range listResp.Members {
+		if m.Name == name {
+			learner = m
+			break
+		}
+	}
+	if learner == nil {
+		h.rd.JSON(w, http.StatusNotFound, fmt.Sprintf("not found, pd: %s", name))
+		return
+	}
+	if !learner.IsLearner {
+		h.rd.JSON(w, http.StatusBadRequest, fmt.Sprintf("pd %s is not a learner", name))
+		return
+	}
+	// A promoted witness may become the etcd leader, but it never campaigns
+	// for the PD leader, so the cluster may be left without one.
+	witness, err := h.svr.GetMember().IsMemberWitness(learner.ID)
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if witness {
+		h.rd.JSON(w, http.StatusBadRequest, fmt.Sprintf("pd %s is a witness", name))
+		return
+	}
+	if err := h.checkNoSpaceAlarm(); err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	members, err := cluster.GetMembers(client)
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	healthMembers := cluster.CheckHealth(h.svr.GetHTTPClient(), members)
+	if _, ok := healthMembers[learner.ID]; !ok {
+		h.rd.JSON(w, http.StatusBadRequest, fmt.Sprintf("pd %s is unhealthy", name))
+		return
+	}
+	if err := checkLearnerVersion(h.svr, learner.ID); err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := checkQuorum(listResp.Members, healthMembers, 0, learner.ID); err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// etcd rejects the promotion if the learner has not caught up with the leader.
+	if _, err := etcdutil.PromoteEtcdLearner(client, learner.ID); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	log.Info("learner member is promoted", zap.String("name", name), zap.Uint64("member-id", learner.ID))
+	h.rd.JSON(w, http.StatusOK, fmt.Sprintf("promoted, pd: %s", name))
+}
+
+// checkLearnerVersion checks the learner does not run an older version than the
+// leader, which may downgrade the cluster once it becomes the leader.
+func checkLearnerVersion(svr *server.Server, learnerID uint64) error {
+	learnerVersion, err := svr.GetMember().GetMemberBinaryVersion(learnerID)
+	if err != nil {
+		return err
+	}
+	if learnerVersion == versioninfo.PDReleaseVersion {
+		return nil
+	}
+	lv, err := versioninfo.ParseVersion(learnerVersion)
+	if err != nil {
+		return errors.Errorf("unknown version %q of the learner", learnerVersion)
+	}
+	v, err := versioninfo.ParseVersion(versioninfo.PDReleaseVersion)
+	if err != nil {
+		return errors.Errorf("unknown version %q of the leader", versioninfo.PDReleaseVersion)
+	}
+	if lv.LessThan(*v) {
+		return errors.Errorf("the learner runs version %s, which is older than the leader %s", lv, v)
+	}
+	return nil
+}
+
+// LeaderAffinity is the zones where the PD leader is preferred to be.
+type LeaderAffinity struct {
+	Zones []string `json:"zones"`
+}
+
+// @Tags     member
+// @Summary  Get the zones where the PD leader is preferred to be.
+// @Produce  json
+// @Success  200  {object}  LeaderAffinity
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /members/leader-affinity [get]
+func (h *memberHandler) GetLeaderAffinity(w http.ResponseWriter, r *http.Request) {
+	zones, err := h.svr.GetMember().GetLeaderAffinity()
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, &LeaderAffinity{Zones: zones})
+}
+
+// @Tags     member
+// @Summary  Set the zones where the PD leader is preferred to be. The zone of a PD member is its `zone` label. The leader is transferred to a healthy member in the zones automatically, and the leader priority takes effect among the members in the same place. Empty zones clear the affinity.
+// @Accept   json
+// @Param    body  body  LeaderAffinity  true  "The zones"
+// @Produce  json
+// @Success  200  {string}  string  "The leader affinity is updated."
+// @Failure  400  {string}  string  "The input is invalid."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /members/leader-affinity [post]
+func (h *memberHandler) SetLeaderAffinity(w http.ResponseWriter, r *http.Request) {
+	var input LeaderAffinity
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &input); err != nil {
+		return
+	}
+	for _, zone := range input.Zones {
+		if zone == "" {
+			h.rd.JSON(w, http.StatusBadRequest, "zone should not be empty")
+			return
+		}
+	}
+	if err := h.svr.GetMember().SetLeaderAffinity(input.Zones); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, "The leader affinity is updated.")
+}
+
 type leaderHandler struct {
 	svr *server.Server
 	rd  *render.Render
@@ -309,3 +783,50 @@ func (h *leaderHandler) TransferLeader(w http.ResponseWriter, r *http.Request) {
 
 	h.rd.JSON(w, http.StatusOK, "The transfer command is submitted.")
 }
+
+// @Tags     leader
+// @Summary  Get the election events of the PD leader happened in [start, end).
+// @Param    start  query  integer  false  "Start time in unix seconds, 0 by default"
+// @Param    end    query  integer  false  "End time in unix seconds, now by default"
+// @Param    limit  query  integer  false  "Max number of the events, no limit by default"
+// @Produce  json
+// @Success  200  {array}   member.ElectionEvent
+// @Failure  400  {string}  string  "The input is invalid."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /leader/history [get]
+func (h *leaderHandler) GetElectionHistory(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	start, end := time.Unix(0, 0), time.Now()
+	parseTime := func(key string, t *time.Time) error {
+		if s := query.Get(key); s != "" {
+			sec, err := strconv.ParseInt(s, 10, 64)
+			if err != nil || sec < 0 {
+				return errors.Errorf("invalid %s: %s", key, s)
+			}
+			*t = time.Unix(sec, 0)
+		}
+		return nil
+	}
+	if err := parseTime("start", &start); err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := parseTime("end", &end); err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var limit int
+	if s := query.Get("limit"); s != "" {
+		var err error
+		if limit, err = strconv.Atoi(s); err != nil || limit < 0 {
+			h.rd.JSON(w, http.StatusBadRequest, fmt.Sprintf("invalid limit: %s", s))
+			return
+		}
+	}
+	events, err := h.svr.GetElectionHistory().Load(start, end, limit)
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, events)
+}
@@ -24,12 +24,17 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"github.com/tikv/pd/pkg/member"
+	"github.com/tikv/pd/pkg/utils/apiutil"
+	tu "github.com/tikv/pd/pkg/utils/testutil"
 	"github.com/tikv/pd/server"
 	"github.com/tikv/pd/server/config"
+	"go.etcd.io/etcd/etcdserver/etcdserverpb"
 )
 
 type memberTestSuite struct {
@@ -141,6 +146,118 @@ func (suite *memberTestSuite) TestChangeLeaderPeerUrls() {
 	suite.changeLeaderPeerUrls(leader, id, peerUrls)
 }
 
+func (suite *memberTestSuite) TestLeaderAffinity() {
+	re := suite.Require()
+	addr := suite.cfgs[rand.Intn(len(suite.cfgs))].ClientUrls + apiPrefix + "/api/v1/members/leader-affinity"
+	affinity := &LeaderAffinity{}
+	re.NoError(tu.ReadGetJSON(re, testDialClient, addr, affinity))
+	re.Empty(affinity.Zones)
+
+	// No member is in dc-2, so the leader is not transferred.
+	data, err := json.Marshal(&LeaderAffinity{Zones: []string{"dc-2"}})
+	re.NoError(err)
+	re.NoError(tu.CheckPostJSON(testDialClient, addr, data, tu.StatusOK(re)))
+	re.NoError(tu.ReadGetJSON(re, testDialClient, addr, affinity))
+	re.Equal([]string{"dc-2"}, affinity.Zones)
+
+	data, err = json.Marshal(&LeaderAffinity{Zones: []string{""}})
+	re.NoError(err)
+	re.NoError(tu.CheckPostJSON(testDialClient, addr, data, tu.Status(re, http.StatusBadRequest)))
+
+	data, err = json.Marshal(&LeaderAffinity{})
+	re.NoError(err)
+	re.NoError(tu.CheckPostJSON(testDialClient, addr, data, tu.StatusOK(re)))
+	affinity = &LeaderAffinity{}
+	re.NoError(tu.ReadGetJSON(re, testDialClient, addr, affinity))
+	re.Empty(affinity.Zones)
+}
+
+func (suite *memberTestSuite) TestReplaceMember() {
+	re := suite.Require()
+	leader := suite.servers[0].GetLeader()
+	prefix := suite.cfgs[0].ClientUrls + apiPrefix + "/api/v1/members/name/"
+	re.NoError(tu.CheckPostJSON(testDialClient, prefix+"unknown/replace", nil, tu.Status(re, http.StatusNotFound)))
+	re.NoError(tu.CheckPostJSON(testDialClient, prefix+leader.GetName()+"/replace", nil,
+		tu.Status(re, http.StatusBadRequest), tu.StringContain(re, "leader")))
+	for _, cfg := range suite.cfgs {
+		if cfg.Name == leader.GetName() {
+			continue
+		}
+		// All the members are healthy.
+		re.NoError(tu.CheckPostJSON(testDialClient, prefix+cfg.Name+"/replace", nil,
+			tu.Status(re, http.StatusBadRequest), tu.StringContain(re, "healthy")))
+	}
+}
+
+func (suite *memberTestSuite) TestLearner() {
+	re := suite.Require()
+	leader := suite.servers[0].GetLeader()
+	prefix := suite.cfgs[0].ClientUrls + apiPrefix + "/api/v1/members"
+	postLearner := func(input *MemberAdditionInput, checkOpts ...func([]byte, int)) {
+		data, err := json.Marshal(input)
+		re.NoError(err)
+		re.NoError(tu.CheckPostJSON(testDialClient, prefix+"/learner", data, checkOpts...))
+	}
+	postLearner(&MemberAdditionInput{Name: "learner"}, tu.Status(re, http.StatusBadRequest))
+	postLearner(&MemberAdditionInput{Name: "learner", PeerUrls: []string{"127.0.0.1"}}, tu.Status(re, http.StatusBadRequest))
+	postLearner(&MemberAdditionInput{Name: leader.GetName(), PeerUrls: []string{"http://127.0.0.1:1"}},
+		tu.Status(re, http.StatusBadRequest), tu.StringContain(re, "already exists"))
+	postLearner(&MemberAdditionInput{Name: "learner", PeerUrls: leader.GetPeerUrls()},
+		tu.Status(re, http.StatusBadRequest), tu.StringContain(re, "is used by"))
+
+	re.NoError(tu.CheckPostJSON(testDialClient, prefix+"/name/unknown/promote", nil, tu.Status(re, http.StatusNotFound)))
+	re.NoError(tu.CheckPostJSON(testDialClient, prefix+"/name/"+leader.GetName()+"/promote", nil,
+		tu.Status(re, http.StatusBadRequest), tu.StringContain(re, "not a learner")))
+
+	var addition MemberAddition
+	data, err := json.Marshal(&MemberAdditionInput{Name: "learner", PeerUrls: []string{"http://127.0.0.1:1"}})
+	re.NoError(err)
+	re.NoError(tu.CheckPostJSON(testDialClient, prefix+"/learner", data, tu.StatusOK(re), tu.ExtractJSON(re, &addition)))
+	re.Equal("learner", addition.Name)
+	re.Contains(addition.InitialCluster, "learner=http://127.0.0.1:1")
+	re.Equal("existing", addition.InitialClusterState)
+	// Removing the learner never breaks the quorum.
+	code, err := apiutil.DoDelete(testDialClient, fmt.Sprintf("%s/id/%d", prefix, addition.MemberID))
+	re.NoError(err)
+	re.Equal(http.StatusOK, code)
+}
+
+func TestCheckQuorum(t *testing.T) {
+	re := require.New(t)
+	members := []*etcdserverpb.Member{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4, IsLearner: true}}
+	healthy := map[uint64]*pdpb.Member{1: {}, 2: {}, 4: {}}
+	re.NoError(checkQuorum(members, healthy, 0, 0))
+	// Removing a healthy voter leaves 1 of the 2 voters healthy.
+	re.Error(checkQuorum(members, healthy, 1, 0))
+	// Removing the unhealthy voter is safe.
+	re.NoError(checkQuorum(members, healthy, 3, 0))
+	// Removing a learner does not change the voters.
+	re.NoError(checkQuorum(members, healthy, 4, 0))
+	// Promoting the healthy learner keeps the quorum.
+	re.NoError(checkQuorum(members, healthy, 0, 4))
+	delete(healthy, 2)
+	re.Error(checkQuorum(members, healthy, 0, 0))
+	re.Error(checkQuorum(members, healthy, 0, 4))
+}
+
+func (suite *memberTestSuite) TestElectionHistory() {
+	re := suite.Require()
+	leader := suite.servers[0].GetLeader()
+	addr := suite.cfgs[rand.Intn(len(suite.cfgs))].ClientUrls + apiPrefix + "/api/v1/leader/history"
+	var events []*member.ElectionEvent
+	re.NoError(tu.ReadGetJSON(re, testDialClient, addr, &events))
+	re.NotEmpty(events)
+	re.Equal(member.ElectionEventElected, events[len(events)-1].Type)
+	re.Equal(leader.GetName(), events[len(events)-1].Name)
+	re.NoError(tu.ReadGetJSON(re, testDialClient, addr+"?limit=1", &events))
+	re.Len(events, 1)
+	re.NoError(tu.ReadGetJSON(re, testDialClient, fmt.Sprintf("%s?start=%d", addr, time.Now().Add(time.Hour).Unix()), &events))
+	re.Empty(events)
+	for _, query := range []string{"start=a", "end=-1", "limit=-1"} {
+		re.NoError(tu.CheckGetJSON(testDialClient, addr+"?"+query, nil, tu.Status(re, http.StatusBadRequest)))
+	}
+}
+
 func (suite *memberTestSuite) changeLeaderPeerUrls(leader *pdpb.Member, id uint64, urls []string) {
 	data := map[string][]string{"peerURLs": urls}
 	postData, err := json.Marshal(data)
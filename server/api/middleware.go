@@ -17,11 +17,14 @@ package api
 import (
 	"context"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/pingcap/failpoint"
 	"github.com/tikv/pd/pkg/audit"
 	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/rbac"
+	"github.com/tikv/pd/pkg/utils/apiutil/serverapi"
 	"github.com/tikv/pd/pkg/utils/requestutil"
 	"github.com/tikv/pd/server"
 	"github.com/tikv/pd/server/cluster"
@@ -91,6 +94,9 @@ func newClusterMiddleware(s *server.Server) clusterMiddleware {
 func (m clusterMiddleware) Middleware(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		rc := m.s.GetRaftCluster()
+		if rc == nil && serverapi.IsFollowerRead(r.Context()) {
+			rc = m.s.GetFollowerReadCluster()
+		}
 		if rc == nil {
 			m.rd.JSON(w, http.StatusInternalServerError, errs.ErrNotBootstrapped.FastGenByArgs().Error())
 			return
@@ -177,10 +183,53 @@ func (s *rateLimitMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request,
 
 	// There is no need to check whether rateLimiter is nil. CreateServer ensures that it is created
 	rateLimiter := s.svr.GetServiceRateLimiter()
-	if rateLimiter.Allow(requestInfo.ServiceLabel) {
-		defer rateLimiter.Release(requestInfo.ServiceLabel)
-		next(w, r)
-	} else {
+	if !rateLimiter.Allow(requestInfo.ServiceLabel) {
 		http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return
+	}
+	defer rateLimiter.Release(requestInfo.ServiceLabel)
+	// The APIs in the allow list, e.g. updating the rate limit config, are not
+	// limited by the identities either.
+	if !rateLimiter.IsInAllowList(requestInfo.ServiceLabel) {
+		identityLimiter := s.svr.GetIdentityRateLimiter()
+		identity := s.rateLimitIdentity(r, requestInfo.IP)
+		if !identityLimiter.Allow(identity) {
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			return
+		}
+		defer identityLimiter.Release(identity)
+	}
+	next(w, r)
+}
+
+// The kinds of the caller identities limited by the rate limit middleware.
+const (
+	rateLimitIdentityCN    = "cn:"
+	rateLimitIdentityToken = "token:"
+	rateLimitIdentityIP    = "ip:"
+)
+
+// rateLimitIdentity returns the identity of the caller, which is the subject of
+// the bearer token, the CN of the client certificate or the source IP in order.
+// The requests forwarded by the followers carry the certificates of the followers.
+func (s *rateLimitMiddleware) rateLimitIdentity(r *http.Request, ip string) string {
+	cred := rbac.HTTPCredential(r)
+	if manager := s.svr.GetRBACManager(); manager != nil && len(cred.Token) > 0 {
+		if identity := manager.Authenticate(cred, time.Now()); identity != nil {
+			return rateLimitIdentityToken + identity.Name
+		}
+	}
+	if len(cred.CN) > 0 {
+		return rateLimitIdentityCN + cred.CN
+	}
+	return rateLimitIdentityIP + ip
+}
+
+func isRateLimitIdentity(identity string) bool {
+	for _, prefix := range []string{rateLimitIdentityCN, rateLimitIdentityToken, rateLimitIdentityIP} {
+		if strings.HasPrefix(identity, prefix) && len(identity) > len(prefix) {
+			return true
+		}
 	}
+	return false
 }
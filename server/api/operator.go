@@ -371,6 +371,28 @@ func (h *operatorHandler) GetOperatorRecords(w http.ResponseWriter, r *http.Requ
 	h.r.JSON(w, http.StatusOK, records)
 }
 
+// @Tags     operator
+// @Summary  lists the traces of the recent operators of a Region, including the running one.
+// @Param    region_id  path  int  true  "A Region's Id"
+// @Produce  json
+// @Success  200  {array}   operator.OpTrace
+// @Failure  400  {string}  string  "The input is invalid."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /operators/{region_id}/traces [get]
+func (h *operatorHandler) GetOperatorTraces(w http.ResponseWriter, r *http.Request) {
+	regionID, err := strconv.ParseUint(mux.Vars(r)["region_id"], 10, 64)
+	if err != nil {
+		h.r.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	traces, err := h.Handler.GetOperatorTraces(regionID)
+	if err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, traces)
+}
+
 func parseStoreIDsAndPeerRole(ids interface{}, roles interface{}) (map[uint64]placement.PeerRoleType, bool) {
 	items, ok := ids.([]interface{})
 	if !ok {
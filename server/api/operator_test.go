@@ -115,6 +115,19 @@ func (suite *operatorTestSuite) TestAddRemovePeer() {
 	records = mustReadURL(re, recordURL)
 	suite.Contains(records, "admin-remove-peer {rm peer: store [2]}")
 
+	// Both the removed operators are traced.
+	var traces []*pdoperator.OpTrace
+	err = tu.ReadGetJSON(re, testDialClient, fmt.Sprintf("%s/operators/%d/traces", suite.urlPrefix, region.GetId()), &traces)
+	suite.NoError(err)
+	reasons := make(map[string]string)
+	for _, trace := range traces {
+		if trace.Status == "Canceled" {
+			reasons[trace.Desc] = trace.Reason
+		}
+	}
+	suite.Equal("removed manually", reasons["admin-add-peer"])
+	suite.Equal("removed manually", reasons["admin-remove-peer"])
+
 	mustPutStore(re, suite.svr, 4, metapb.StoreState_Up, metapb.NodeState_Serving, nil)
 	err = tu.CheckPostJSON(testDialClient, fmt.Sprintf("%s/operators", suite.urlPrefix), []byte(`{"name":"add-learner", "region_id": 1, "store_id": 4}`), tu.StatusOK(re))
 	suite.NoError(err)
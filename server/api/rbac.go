@@ -0,0 +1,433 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pingcap/errors"
+	"github.com/tikv/pd/pkg/rbac"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/utils/apiutil"
+	"github.com/tikv/pd/server"
+	"github.com/unrolled/render"
+)
+
+type rbacHandler struct {
+	svr *server.Server
+	rd  *render.Render
+}
+
+func newRBACHandler(svr *server.Server, rd *render.Render) *rbacHandler {
+	return &rbacHandler{
+		svr: svr,
+		rd:  rd,
+	}
+}
+
+// roleBindingInput is the input to bind an identity to a role. The identity is
+// either the CN of a client certificate or a bearer token.
+type roleBindingInput struct {
+	Role  string `json:"role"`
+	CN    string `json:"cn,omitempty"`
+	Token string `json:"token,omitempty"`
+}
+
+// @Tags     rbac
+// @Summary  Get all the role bindings. The hashes of the tokens are not returned.
+// @Produce  json
+// @Success  200  {array}   endpoint.RoleBinding
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /rbac/bindings [get]
+func (h *rbacHandler) GetRoleBindings(w http.ResponseWriter, r *http.Request) {
+	bindings, err := h.svr.GetRBACManager().GetRoleBindings()
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, binding := range bindings {
+		binding.TokenHash = ""
+	}
+	h.rd.JSON(w, http.StatusOK, bindings)
+}
+
+// @Tags     rbac
+// @Summary  Bind the identity with the certificate CN or the bearer token to a role, the previous binding with the same name is replaced.
+// @Accept   json
+// @Param    name  path  string            true  "The name of the role binding"
+// @Param    body  body  roleBindingInput  true  "The role and the identity"
+// @Produce  json
+// @Success  200  {object}  endpoint.RoleBinding
+// @Failure  400  {string}  string  "The input is invalid."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /rbac/bindings/{name} [put]
+func (h *rbacHandler) SetRoleBinding(w http.ResponseWriter, r *http.Request) {
+	var input roleBindingInput
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &input); err != nil {
+		return
+	}
+	binding, err := h.svr.GetRBACManager().SetRoleBinding(mux.Vars(r)["name"], rbac.Role(input.Role), input.CN, input.Token, time.Now())
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	res := *binding
+	res.TokenHash = ""
+	h.rd.JSON(w, http.StatusOK, &res)
+}
+
+// @Tags     rbac
+// @Summary  Remove the role binding.
+// @Param    name  path  string  true  "The name of the role binding"
+// @Produce  json
+// @Success  200  {string}  string  "Remove the role binding successfully."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /rbac/bindings/{name} [delete]
+func (h *rbacHandler) RemoveRoleBinding(w http.ResponseWriter, r *http.Request) {
+	if err := h.svr.GetRBACManager().RemoveRoleBinding(mux.Vars(r)["name"], time.Now()); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, "Remove the role binding successfully.")
+}
+
+// apiTokenInput is the input to issue an API token.
+type apiTokenInput struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// TTL is a duration string, e.g. "24h".
+	TTL string `json:"ttl"`
+}
+
+// issuedAPIToken is the issued API token with its record. The token is
+// returned only once.
+type issuedAPIToken struct {
+	Token string `json:"token"`
+	*endpoint.APIToken
+}
+
+// @Tags     rbac
+// @Summary  Get the records of the unexpired API tokens. The tokens themselves are not returned.
+// @Produce  json
+// @Success  200  {array}   endpoint.APIToken
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /rbac/tokens [get]
+func (h *rbacHandler) GetAPITokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.svr.GetRBACManager().GetAPITokens(time.Now())
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, tokens)
+}
+
+// @Tags     rbac
+// @Summary  Issue an API token signed by PD, which is sent as a bearer token. A scope is "{role}" or "{role}:{path-prefix}" to limit the role to the HTTP paths or the gRPC methods under the prefix.
+// @Accept   json
+// @Param    body  body  apiTokenInput  true  "The name, the scopes and the ttl of the token"
+// @Produce  json
+// @Success  200  {object}  issuedAPIToken
+// @Failure  400  {string}  string  "The input is invalid."
+// @Router   /rbac/tokens [post]
+func (h *rbacHandler) IssueAPIToken(w http.ResponseWriter, r *http.Request) {
+	var input apiTokenInput
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &input); err != nil {
+		return
+	}
+	ttl, err := time.ParseDuration(input.TTL)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	token, record, err := h.svr.GetRBACManager().IssueToken(input.Name, input.Scopes, ttl, time.Now())
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, &issuedAPIToken{Token: token, APIToken: record})
+}
+
+// @Tags     rbac
+// @Summary  Revoke the API token.
+// @Param    id  path  string  true  "The id of the API token"
+// @Produce  json
+// @Success  200  {string}  string  "Revoke the API token successfully."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /rbac/tokens/{id} [delete]
+func (h *rbacHandler) RevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	if err := h.svr.GetRBACManager().RevokeToken(mux.Vars(r)["id"], time.Now()); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, "Revoke the API token successfully.")
+}
+
+// grpcPolicyInput maps the certificates to the gRPC methods they may call.
+// The subjects are in the form of "{kind}:{pattern}", where the kind is one of
+// "cn", "dns", "uri", "ip" and "email". The methods are the full gRPC methods,
+// which may be patterns, e.g. "/pdpb.PD/Watch*", or "*" for all the methods.
+type grpcPolicyInput struct {
+	Subjects []string `json:"subjects"`
+	Methods  []string `json:"methods"`
+}
+
+// @Tags     rbac
+// @Summary  Get all the gRPC policies.
+// @Produce  json
+// @Success  200  {array}   endpoint.GRPCPolicy
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /rbac/grpc-policies [get]
+func (h *rbacHandler) GetGRPCPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.svr.GetRBACManager().GetGRPCPolicies()
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, policies)
+}
+
+// @Tags     rbac
+// @Summary  Set the gRPC methods the matched certificates may call, the previous policy with the same name is replaced.
+// @Accept   json
+// @Param    name  path  string           true  "The name of the gRPC policy"
+// @Param    body  body  grpcPolicyInput  true  "The subjects and the methods"
+// @Produce  json
+// @Success  200  {object}  endpoint.GRPCPolicy
+// @Failure  400  {string}  string  "The input is invalid."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /rbac/grpc-policies/{name} [put]
+func (h *rbacHandler) SetGRPCPolicy(w http.ResponseWriter, r *http.Request) {
+	var input grpcPolicyInput
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &input); err != nil {
+		return
+	}
+	policy := &endpoint.GRPCPolicy{
+		Name:     mux.Vars(r)["name"],
+		Subjects: input.Subjects,
+		Methods:  input.Methods,
+	}
+	if err := h.svr.GetRBACManager().SetGRPCPolicy(policy, time.Now()); err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, policy)
+}
+
+// @Tags     rbac
+// @Summary  Remove the gRPC policy.
+// @Param    name  path  string  true  "The name of the gRPC policy"
+// @Produce  json
+// @Success  200  {string}  string  "Remove the gRPC policy successfully."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /rbac/grpc-policies/{name} [delete]
+func (h *rbacHandler) RemoveGRPCPolicy(w http.ResponseWriter, r *http.Request) {
+	if err := h.svr.GetRBACManager().RemoveGRPCPolicy(mux.Vars(r)["name"], time.Now()); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, "Remove the gRPC policy successfully.")
+}
+
+// networkACLInput allows or denies the CIDRs to call the APIs on a listener,
+// which is "http", "grpc" or empty for both. The API class is the prefix of
+// the HTTP paths or the full gRPC methods, empty for all the APIs.
+type networkACLInput struct {
+	Listener string   `json:"listener,omitempty"`
+	APIClass string   `json:"api_class,omitempty"`
+	Allow    []string `json:"allow,omitempty"`
+	Deny     []string `json:"deny,omitempty"`
+}
+
+// @Tags     rbac
+// @Summary  Get all the network ACLs.
+// @Produce  json
+// @Success  200  {array}   endpoint.NetworkACL
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /rbac/network-acls [get]
+func (h *rbacHandler) GetNetworkACLs(w http.ResponseWriter, r *http.Request) {
+	acls, err := h.svr.GetRBACManager().GetNetworkACLs()
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, acls)
+}
+
+// @Tags     rbac
+// @Summary  Set the CIDRs allowed and denied to call the APIs, the previous network ACL with the same name is replaced.
+// @Accept   json
+// @Param    name  path  string           true  "The name of the network ACL"
+// @Param    body  body  networkACLInput  true  "The listener, the API class and the CIDRs"
+// @Produce  json
+// @Success  200  {object}  endpoint.NetworkACL
+// @Failure  400  {string}  string  "The input is invalid."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /rbac/network-acls/{name} [put]
+func (h *rbacHandler) SetNetworkACL(w http.ResponseWriter, r *http.Request) {
+	var input networkACLInput
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &input); err != nil {
+		return
+	}
+	acl := &endpoint.NetworkACL{
+		Name:     mux.Vars(r)["name"],
+		Listener: input.Listener,
+		APIClass: input.APIClass,
+		Allow:    input.Allow,
+		Deny:     input.Deny,
+	}
+	if err := h.svr.GetRBACManager().SetNetworkACL(acl, time.Now()); err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, acl)
+}
+
+// @Tags     rbac
+// @Summary  Remove the network ACL.
+// @Param    name  path  string  true  "The name of the network ACL"
+// @Produce  json
+// @Success  200  {string}  string  "Remove the network ACL successfully."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /rbac/network-acls/{name} [delete]
+func (h *rbacHandler) RemoveNetworkACL(w http.ResponseWriter, r *http.Request) {
+	if err := h.svr.GetRBACManager().RemoveNetworkACL(mux.Vars(r)["name"], time.Now()); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, "Remove the network ACL successfully.")
+}
+
+// issuedSession is the created admin session with its token. The token is
+// returned only once.
+type issuedSession struct {
+	Token string `json:"token"`
+	*endpoint.AdminSession
+}
+
+// @Tags     rbac
+// @Summary  Log in with a client certificate or a bound token, and create an admin session, whose token is sent as a bearer token afterwards.
+// @Param    client  query  string  false  "The tool which logs in, the component signature of the request by default"
+// @Produce  json
+// @Success  200  {object}  issuedSession
+// @Failure  400  {string}  string  "RBAC is not enabled or the credential is invalid."
+// @Failure  401  {string}  string  "The identity is unknown."
+// @Failure  429  {string}  string  "The identity has too many active sessions."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /sessions [post]
+func (h *rbacHandler) Login(w http.ResponseWriter, r *http.Request) {
+	if !h.svr.IsRBACEnabled() {
+		h.rd.JSON(w, http.StatusBadRequest, "RBAC is not enabled")
+		return
+	}
+	client := r.URL.Query().Get("client")
+	if len(client) == 0 {
+		client = apiutil.GetComponentNameOnHTTP(r)
+	}
+	token, session, err := h.svr.GetRBACManager().Login(rbac.HTTPCredential(r), client, r.RemoteAddr, time.Now())
+	if err != nil {
+		switch errors.Cause(err) {
+		case rbac.ErrUnauthenticated:
+			h.rd.JSON(w, http.StatusUnauthorized, err.Error())
+		case rbac.ErrTooManySessions:
+			h.rd.JSON(w, http.StatusTooManyRequests, err.Error())
+		default:
+			h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+	res := *session
+	res.TokenHash = ""
+	h.rd.JSON(w, http.StatusOK, &issuedSession{Token: token, AdminSession: &res})
+}
+
+// @Tags     rbac
+// @Summary  Log out, and revoke the admin session of the session token.
+// @Produce  json
+// @Success  200  {string}  string  "Log out successfully."
+// @Failure  401  {string}  string  "The request does not carry an active session token."
+// @Router   /sessions [delete]
+func (h *rbacHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if err := h.svr.GetRBACManager().Logout(rbac.HTTPCredential(r).Token, time.Now()); err != nil {
+		h.rd.JSON(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, "Log out successfully.")
+}
+
+// @Tags     rbac
+// @Summary  Get the unexpired admin sessions, which are the identities currently administering the cluster. The tokens are not returned.
+// @Param    revoked  query  bool  false  "Whether to include the revoked sessions"
+// @Produce  json
+// @Success  200  {array}   endpoint.AdminSession
+// @Failure  400  {string}  string  "The input is invalid."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /rbac/sessions [get]
+func (h *rbacHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	var includeRevoked bool
+	if value := r.URL.Query().Get("revoked"); len(value) > 0 {
+		var err error
+		if includeRevoked, err = strconv.ParseBool(value); err != nil {
+			h.rd.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	sessions, err := h.svr.GetRBACManager().GetSessions(includeRevoked, time.Now())
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, session := range sessions {
+		session.TokenHash = ""
+	}
+	h.rd.JSON(w, http.StatusOK, sessions)
+}
+
+// @Tags     rbac
+// @Summary  Kill the admin session, which is revoked immediately on the member serving the request, and on the others once their caches expire.
+// @Param    id  path  string  true  "The id of the admin session"
+// @Produce  json
+// @Success  200  {string}  string  "Kill the admin session successfully."
+// @Failure  400  {string}  string  "The session is not found."
+// @Router   /rbac/sessions/{id} [delete]
+func (h *rbacHandler) KillSession(w http.ResponseWriter, r *http.Request) {
+	manager := h.svr.GetRBACManager()
+	var revokedBy string
+	if identity := manager.Authenticate(rbac.HTTPCredential(r), time.Now()); identity != nil {
+		revokedBy = identity.Name
+	}
+	if err := manager.KillSession(mux.Vars(r)["id"], revokedBy, time.Now()); err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, "Kill the admin session successfully.")
+}
+
+// @Tags     rbac
+// @Summary  Get the identity and the role of the request.
+// @Produce  json
+// @Success  200  {object}  rbac.Identity
+// @Failure  401  {string}  string  "The identity is unknown."
+// @Router   /rbac/whoami [get]
+func (h *rbacHandler) WhoAmI(w http.ResponseWriter, r *http.Request) {
+	identity := h.svr.GetRBACManager().Authenticate(rbac.HTTPCredential(r), time.Now())
+	if identity == nil {
+		h.rd.JSON(w, http.StatusUnauthorized, rbac.ErrUnauthenticated.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, identity)
+}
@@ -22,6 +22,7 @@ import (
 	"net/url"
 	"sort"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/pingcap/failpoint"
@@ -33,6 +34,7 @@ import (
 	"github.com/tikv/pd/pkg/utils/apiutil"
 	"github.com/tikv/pd/pkg/utils/typeutil"
 	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/cluster"
 	"github.com/tikv/pd/server/schedule/filter"
 	"github.com/tikv/pd/server/statistics"
 	"github.com/unrolled/render"
@@ -983,6 +985,72 @@ func (h *regionsHandler) SplitRegions(w http.ResponseWriter, r *http.Request) {
 	h.rd.JSON(w, http.StatusOK, &s)
 }
 
+// @Tags     region
+// @Summary  List the region split events reported recently. At most 1024 latest records are kept in memory. The trigger reason of the splits not triggered by PD is unknown, because TiKV does not report it.
+// @Param    from       query  integer  false  "Unix timestamp in seconds, only the splits reported after it are returned"
+// @Param    region_id  query  integer  false  "Only the splits related to the region are returned"
+// @Param    limit      query  integer  false  "Only the latest limit records are returned"
+// @Produce  json
+// @Success  200  {array}   cluster.SplitRecord
+// @Failure  400  {string}  string  "The input is invalid."
+// @Router   /regions/split/records [get]
+func (h *regionsHandler) GetSplitRecords(w http.ResponseWriter, r *http.Request) {
+	rc := getCluster(r)
+	query := r.URL.Query()
+	var from time.Time
+	if fromStr := query.Get("from"); fromStr != "" {
+		fromInt, err := strconv.ParseInt(fromStr, 10, 64)
+		if err != nil {
+			h.rd.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		from = time.Unix(fromInt, 0)
+	}
+	var regionID uint64
+	if idStr := query.Get("region_id"); idStr != "" {
+		id, err := strconv.ParseUint(idStr, 10, 64)
+		if err != nil {
+			h.rd.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		regionID = id
+	}
+	limit := cluster.DefaultSplitRecordLimit
+	if limitStr := query.Get("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l <= 0 {
+			h.rd.JSON(w, http.StatusBadRequest, "limit should be a positive integer")
+			return
+		}
+		limit = l
+	}
+	h.rd.JSON(w, http.StatusOK, rc.GetSplitRecords(from, regionID, limit))
+}
+
+// @Tags     region
+// @Summary  Get the report of the last region consistency audit, which cross-checks the region metadata against the ranges reported by stores. The audit runs every 10 minutes.
+// @Produce  json
+// @Success  200  {object}  cluster.RegionConsistencyReport
+// @Failure  404  {string}  string  "The audit has not run yet."
+// @Router   /regions/consistency [get]
+func (h *regionsHandler) GetRegionConsistencyReport(w http.ResponseWriter, r *http.Request) {
+	report := getCluster(r).GetRegionConsistencyReport()
+	if report == nil {
+		h.rd.JSON(w, http.StatusNotFound, "the region consistency audit has not run yet")
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, report)
+}
+
+// @Tags     region
+// @Summary  Run the region consistency audit immediately. The proposed repair operators are not created automatically, they can be created by the operator API.
+// @Produce  json
+// @Success  200  {object}  cluster.RegionConsistencyReport
+// @Router   /regions/consistency [post]
+func (h *regionsHandler) AuditRegionConsistency(w http.ResponseWriter, r *http.Request) {
+	h.rd.JSON(w, http.StatusOK, getCluster(r).AuditRegionConsistency())
+}
+
 // RegionHeap implements heap.Interface, used for selecting top n regions.
 type RegionHeap struct {
 	regions []*core.RegionInfo
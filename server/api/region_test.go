@@ -34,6 +34,7 @@ import (
 	"github.com/tikv/pd/pkg/core"
 	tu "github.com/tikv/pd/pkg/utils/testutil"
 	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/cluster"
 	"github.com/tikv/pd/server/schedule/placement"
 )
 
@@ -389,6 +390,60 @@ func (suite *regionTestSuite) TestSplitRegions() {
 	suite.NoError(err)
 }
 
+func (suite *regionTestSuite) TestSplitRecords() {
+	re := suite.Require()
+	rc := suite.svr.GetRaftCluster()
+	left := &metapb.Region{Id: 701, StartKey: []byte("x"), EndKey: []byte("y")}
+	right := &metapb.Region{Id: 700, StartKey: []byte("y"), EndKey: []byte("z")}
+	_, err := rc.HandleReportSplit(&pdpb.ReportSplitRequest{Left: left, Right: right})
+	re.NoError(err)
+
+	var records []*cluster.SplitRecord
+	url := fmt.Sprintf("%s/regions/split/records?region_id=%d", suite.urlPrefix, 701)
+	re.NoError(tu.ReadGetJSON(re, testDialClient, url, &records))
+	re.Len(records, 1)
+	re.Equal(uint64(700), records[0].SourceRegionID)
+	re.Equal([]uint64{701}, records[0].NewRegionIDs)
+	re.Nil(records[0].AskTime)
+
+	url = fmt.Sprintf("%s/regions/split/records?region_id=%d&limit=1&from=%d", suite.urlPrefix, 700, 0)
+	re.NoError(tu.ReadGetJSON(re, testDialClient, url, &records))
+	re.Len(records, 1)
+
+	for _, query := range []string{"from=abc", "region_id=-1", "limit=0", "limit=abc"} {
+		url = fmt.Sprintf("%s/regions/split/records?%s", suite.urlPrefix, query)
+		re.NoError(tu.CheckGetJSON(testDialClient, url, nil, tu.Status(re, http.StatusBadRequest)))
+	}
+}
+
+func (suite *regionTestSuite) TestRegionConsistency() {
+	re := suite.Require()
+	// Store 999 does not exist.
+	region := core.NewTestRegionInfo(801, 999, []byte("consistency-a"), []byte("consistency-b"))
+	mustRegionHeartbeat(re, suite.svr, region)
+
+	url := fmt.Sprintf("%s/regions/consistency", suite.urlPrefix)
+	report := &cluster.RegionConsistencyReport{}
+	re.NoError(tu.CheckPostJSON(testDialClient, url, nil, tu.StatusOK(re), tu.ExtractJSON(re, report)))
+	checkReport := func(report *cluster.RegionConsistencyReport) {
+		var found bool
+		for _, issue := range report.Issues {
+			if issue.Type != cluster.RegionUnknownStore || issue.RegionIDs[0] != 801 {
+				continue
+			}
+			found = true
+			re.Equal(uint64(999), issue.StoreID)
+			re.Equal(&cluster.RepairOperator{Name: "remove-peer", RegionID: 801, StoreID: 999}, issue.Repair)
+		}
+		re.True(found)
+	}
+	checkReport(report)
+
+	report = &cluster.RegionConsistencyReport{}
+	re.NoError(tu.ReadGetJSON(re, testDialClient, url, report))
+	checkReport(report)
+}
+
 func (suite *regionTestSuite) checkTopRegions(url string, regionIDs []uint64) {
 	regions := &RegionsInfo{}
 	err := tu.ReadGetJSON(suite.Require(), testDialClient, url, regions)
@@ -19,6 +19,7 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/tikv/pd/pkg/mcs/discovery"
 	"github.com/tikv/pd/pkg/utils/apiutil"
 	"github.com/tikv/pd/pkg/utils/apiutil/serverapi"
 	"github.com/tikv/pd/server"
@@ -27,6 +28,18 @@ import (
 
 const apiPrefix = "/pd"
 
+// microserviceRoutes are the APIs owned by the microservices, which are forwarded
+// to them in the microservice mode.
+var microserviceRoutes = []serverapi.MicroserviceRoute{
+	{PathPrefix: apiPrefix + "/api/v1/admin/reset-ts", Service: discovery.TSOServiceName},
+	{PathPrefix: apiPrefix + "/api/v1/tso/", Service: discovery.TSOServiceName},
+	{PathPrefix: apiPrefix + "/api/v1/operators", Service: discovery.SchedulingServiceName},
+	{PathPrefix: apiPrefix + "/api/v1/checker/", Service: discovery.SchedulingServiceName},
+	{PathPrefix: apiPrefix + "/api/v1/schedulers", Service: discovery.SchedulingServiceName},
+	{PathPrefix: apiPrefix + "/api/v1/scheduler-config", Service: discovery.SchedulingServiceName},
+	{PathPrefix: apiPrefix + "/api/v1/hotspot/", Service: discovery.SchedulingServiceName},
+}
+
 // NewHandler creates a HTTP handler for API.
 func NewHandler(ctx context.Context, svr *server.Server) (http.Handler, apiutil.APIServiceGroup, error) {
 	group := apiutil.APIServiceGroup{
@@ -37,7 +50,13 @@ func NewHandler(ctx context.Context, svr *server.Server) (http.Handler, apiutil.
 	r := createRouter(apiPrefix, svr)
 	router.PathPrefix(apiPrefix).Handler(negroni.New(
 		serverapi.NewRuntimeServiceValidator(svr, group),
-		serverapi.NewRedirector(svr),
+		serverapi.NewRedirector(svr,
+			serverapi.WithFollowerRead(func(req *http.Request) bool {
+				var match mux.RouteMatch
+				return r.Match(req, &match) && match.Route != nil && svr.IsFollowerReadable(match.Route.GetName())
+			}),
+			serverapi.WithMicroserviceForward(microserviceRoutes...),
+		),
 		negroni.Wrap(r)),
 	)
 
@@ -16,10 +16,15 @@ package api
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/pingcap/errors"
 	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/utils/apiutil"
+	"github.com/tikv/pd/pkg/utils/typeutil"
 	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/gc"
 	"github.com/unrolled/render"
 )
 
@@ -84,3 +89,209 @@ func (h *serviceGCSafepointHandler) DeleteGCSafePoint(w http.ResponseWriter, r *
 	}
 	h.rd.JSON(w, http.StatusOK, "Delete service GC safepoint successfully.")
 }
+
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type serviceSafePointLeaseInput struct {
+	ServiceID string `json:"service_id"`
+	Owner     string `json:"owner"`
+	SafePoint uint64 `json:"safe_point"`
+	// TTL is the seconds the lease lasts after each renewal, only used to acquire.
+	TTL int64 `json:"ttl"`
+}
+
+func (h *serviceGCSafepointHandler) respondLeaseError(w http.ResponseWriter, err error) {
+	switch errors.Cause(err) {
+	case gc.ErrLeaseNotFound:
+		h.rd.JSON(w, http.StatusNotFound, err.Error())
+	case gc.ErrLeaseHeldByOthers:
+		h.rd.JSON(w, http.StatusConflict, err.Error())
+	default:
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// @Tags     service_gc_safepoint
+// @Summary  Acquire the lease of a service GC safepoint, which must be renewed before it expires.
+// @Accept   json
+// @Param    body  body  serviceSafePointLeaseInput  true  "The service, the owner, the safepoint and the TTL in seconds"
+// @Produce  json
+// @Success  200  {object}  endpoint.ServiceSafePoint
+// @Failure  400  {string}  string  "The input is invalid."
+// @Failure  409  {string}  string  "The lease is held by another owner."
+// @Router   /gc/safepoint/lease [post]
+func (h *serviceGCSafepointHandler) AcquireLease(w http.ResponseWriter, r *http.Request) {
+	var input serviceSafePointLeaseInput
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &input); err != nil {
+		return
+	}
+	lease, err := h.svr.GetGCSafePointManager().AcquireServiceSafePointLease(input.ServiceID, input.Owner, input.SafePoint, input.TTL, time.Now())
+	if err != nil {
+		h.respondLeaseError(w, err)
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, lease)
+}
+
+// @Tags     service_gc_safepoint
+// @Summary  Renew the lease of a service GC safepoint and advance its safepoint.
+// @Accept   json
+// @Param    service_id  path  string                      true  "Service ID"
+// @Param    body        body  serviceSafePointLeaseInput  true  "The owner and the safepoint, which is unchanged if it's zero"
+// @Produce  json
+// @Success  200  {object}  endpoint.ServiceSafePoint
+// @Failure  400  {string}  string  "The input is invalid."
+// @Failure  404  {string}  string  "The lease does not exist or has expired."
+// @Failure  409  {string}  string  "The lease is held by another owner."
+// @Router   /gc/safepoint/lease/{service_id} [put]
+func (h *serviceGCSafepointHandler) RenewLease(w http.ResponseWriter, r *http.Request) {
+	var input serviceSafePointLeaseInput
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &input); err != nil {
+		return
+	}
+	serviceID := mux.Vars(r)["service_id"]
+	lease, err := h.svr.GetGCSafePointManager().RenewServiceSafePointLease(serviceID, input.Owner, input.SafePoint, time.Now())
+	if err != nil {
+		h.respondLeaseError(w, err)
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, lease)
+}
+
+// @Tags     service_gc_safepoint
+// @Summary  Release the lease of a service GC safepoint.
+// @Param    service_id  path   string  true  "Service ID"
+// @Param    owner       query  string  true  "The owner of the lease"
+// @Produce  json
+// @Success  200  {string}  string  "Release service GC safepoint lease successfully."
+// @Failure  404  {string}  string  "The lease does not exist or has expired."
+// @Failure  409  {string}  string  "The lease is held by another owner."
+// @Router   /gc/safepoint/lease/{service_id} [delete]
+func (h *serviceGCSafepointHandler) ReleaseLease(w http.ResponseWriter, r *http.Request) {
+	serviceID := mux.Vars(r)["service_id"]
+	owner := r.URL.Query().Get("owner")
+	if err := h.svr.GetGCSafePointManager().ReleaseServiceSafePointLease(serviceID, owner, time.Now()); err != nil {
+		h.respondLeaseError(w, err)
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, "Release service GC safepoint lease successfully.")
+}
+
+// @Tags     service_gc_safepoint
+// @Summary  Get the recent events of the service GC safepoints blocking GC or with the leases expired.
+// @Produce  json
+// @Success  200  {array}  gc.SafePointEvent
+// @Router   /gc/safepoint/events [get]
+func (h *serviceGCSafepointHandler) GetEvents(w http.ResponseWriter, r *http.Request) {
+	h.rd.JSON(w, http.StatusOK, h.svr.GetServiceSafePointChecker().GetEvents())
+}
+
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type gcBlockersReport struct {
+	*gc.GCBlockers
+	Keyspaces []*gc.KeyspaceGCBlockers `json:"keyspaces"`
+}
+
+// @Tags     service_gc_safepoint
+// @Summary  Explain which service safepoints hold back the GC safepoint and the GC safepoints of the keyspaces, with their ages and owners.
+// @Produce  json
+// @Success  200  {object}  gcBlockersReport
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /gc/blockers [get]
+func (h *serviceGCSafepointHandler) GetGCBlockers(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	blockers, err := h.svr.GetGCSafePointManager().GetGCBlockers(now)
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	keyspaces, err := h.svr.GetKeyspaceSafePointManager().GetGCBlockers(now)
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, &gcBlockersReport{GCBlockers: blockers, Keyspaces: keyspaces})
+}
+
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type gcPauseInput struct {
+	// Duration is how long GC is paused, e.g. "2h". It's indefinite if omitted.
+	Duration typeutil.Duration `json:"duration"`
+	Reason   string            `json:"reason"`
+	// Force allows an indefinite pause or one longer than the max pause duration.
+	Force bool `json:"force"`
+}
+
+// @Tags     service_gc_safepoint
+// @Summary  Get the pause of the GC safepoint, null if GC is not paused.
+// @Produce  json
+// @Success  200  {object}  endpoint.GCPause
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /gc/pause [get]
+func (h *serviceGCSafepointHandler) GetGCPause(w http.ResponseWriter, r *http.Request) {
+	pause, err := h.svr.GetGCSafePointManager().GetGCPause(time.Now())
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, pause)
+}
+
+// @Tags     service_gc_safepoint
+// @Summary  Pause the GC safepoint for a bounded duration, after which GC resumes automatically.
+// @Accept   json
+// @Param    body  body  gcPauseInput  true  "The duration and the reason of the pause"
+// @Produce  json
+// @Success  200  {object}  endpoint.GCPause
+// @Failure  400  {string}  string  "The input is invalid, or the pause is too long without being forced."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /gc/pause [post]
+func (h *serviceGCSafepointHandler) PauseGC(w http.ResponseWriter, r *http.Request) {
+	var input gcPauseInput
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &input); err != nil {
+		return
+	}
+	pause, err := h.svr.GetGCSafePointManager().PauseGC(input.Duration.Duration, input.Reason, input.Force, time.Now())
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, pause)
+}
+
+// @Tags     service_gc_safepoint
+// @Summary  Resume the GC safepoint.
+// @Produce  json
+// @Success  200  {string}  string  "Resume GC successfully."
+// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Router   /gc/pause [delete]
+func (h *serviceGCSafepointHandler) ResumeGC(w http.ResponseWriter, r *http.Request) {
+	if err := h.svr.GetGCSafePointManager().ResumeGC(); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, "Resume GC successfully.")
+}
+
+// @Tags     service_gc_safepoint
+// @Summary  Get the status of the GC controller and its recent rounds.
+// @Produce  json
+// @Success  200  {object}  gc.ControllerStatus
+// @Router   /gc/controller [get]
+func (h *serviceGCSafepointHandler) GetGCControllerStatus(w http.ResponseWriter, r *http.Request) {
+	h.rd.JSON(w, http.StatusOK, h.svr.GetGCController().GetStatus())
+}
+
+// @Tags     service_gc_safepoint
+// @Summary  Advance the GC safepoint by the GC controller immediately.
+// @Produce  json
+// @Success  200  {object}  gc.GCRound
+// @Failure  400  {string}  string  "The GC controller is not enabled."
+// @Router   /gc/controller/run [post]
+func (h *serviceGCSafepointHandler) RunGCController(w http.ResponseWriter, r *http.Request) {
+	round, err := h.svr.GetGCController().RunRound()
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, round)
+}
@@ -150,6 +150,7 @@ func (h *serviceMiddlewareHandler) SetRatelimitConfig(w http.ResponseWriter, r *
 		return
 	}
 	var serviceLabel string
+	limiterKey, updateLimiter := "limiter-config", h.svr.UpdateServiceRateLimiter
 	switch typeStr {
 	case "label":
 		serviceLabel, ok = input["label"].(string)
@@ -173,15 +174,28 @@ func (h *serviceMiddlewareHandler) SetRatelimitConfig(w http.ResponseWriter, r *
 			h.rd.JSON(w, http.StatusBadRequest, "There is no label matched.")
 			return
 		}
+	case "identity":
+		// The identity is limited across all the APIs, so it is keyed by the
+		// identity instead of the service label.
+		serviceLabel, _ = input["identity"].(string)
+		if !isRateLimitIdentity(serviceLabel) {
+			h.rd.JSON(w, http.StatusBadRequest, "The identity is invalid, should be cn:{CN}, token:{subject} or ip:{IP}.")
+			return
+		}
+		limiterKey, updateLimiter = "identity-limiter-config", h.svr.UpdateIdentityRateLimiter
 	default:
 		h.rd.JSON(w, http.StatusBadRequest, "The type is invalid.")
 		return
 	}
-	if h.svr.IsInRateLimitAllowList(serviceLabel) {
+	if typeStr != "identity" && h.svr.IsInRateLimitAllowList(serviceLabel) {
 		h.rd.JSON(w, http.StatusBadRequest, "This service is in allow list whose config can not be changed.")
 		return
 	}
-	cfg := h.svr.GetRateLimitConfig().LimiterConfig[serviceLabel]
+	rateLimitCfg := h.svr.GetRateLimitConfig()
+	cfg := rateLimitCfg.LimiterConfig[serviceLabel]
+	if typeStr == "identity" {
+		cfg = rateLimitCfg.IdentityLimiterConfig[serviceLabel]
+	}
 	// update concurrency limiter
 	concurrencyUpdatedFlag := "Concurrency limiter is not changed."
 	concurrencyFloat, okc := input["concurrency"].(float64)
@@ -204,7 +218,7 @@ func (h *serviceMiddlewareHandler) SetRatelimitConfig(w http.ResponseWriter, r *
 	if !okc && !okq {
 		h.rd.JSON(w, http.StatusOK, "No changed.")
 	} else {
-		status := h.svr.UpdateServiceRateLimiter(serviceLabel, ratelimit.UpdateDimensionConfig(&cfg))
+		status := updateLimiter(serviceLabel, ratelimit.UpdateDimensionConfig(&cfg))
 		switch {
 		case status&ratelimit.QPSChanged != 0:
 			qpsRateUpdatedFlag = "QPS rate limiter is changed."
@@ -217,11 +231,12 @@ func (h *serviceMiddlewareHandler) SetRatelimitConfig(w http.ResponseWriter, r *
 		case status&ratelimit.ConcurrencyDeleted != 0:
 			concurrencyUpdatedFlag = "Concurrency limiter is deleted."
 		}
-		err := h.svr.UpdateRateLimitConfig("limiter-config", serviceLabel, cfg)
+		err := h.svr.UpdateRateLimitConfig(limiterKey, serviceLabel, cfg)
 		if err != nil {
 			h.rd.JSON(w, http.StatusInternalServerError, err.Error())
 		} else {
-			result := rateLimitResult{concurrencyUpdatedFlag, qpsRateUpdatedFlag, h.svr.GetServiceMiddlewareConfig().RateLimitConfig.LimiterConfig}
+			rateLimitCfg = &h.svr.GetServiceMiddlewareConfig().RateLimitConfig
+			result := rateLimitResult{concurrencyUpdatedFlag, qpsRateUpdatedFlag, rateLimitCfg.LimiterConfig, rateLimitCfg.IdentityLimiterConfig}
 			h.rd.JSON(w, http.StatusOK, result)
 		}
 	}
@@ -231,4 +246,5 @@ type rateLimitResult struct {
 	ConcurrencyUpdatedFlag string                               `json:"concurrency"`
 	QPSRateUpdatedFlag     string                               `json:"qps"`
 	LimiterConfig          map[string]ratelimit.DimensionConfig `json:"limiter-config"`
+	IdentityLimiterConfig  map[string]ratelimit.DimensionConfig `json:"identity-limiter-config,omitempty"`
 }
@@ -26,6 +26,7 @@ import (
 	tu "github.com/tikv/pd/pkg/utils/testutil"
 	"github.com/tikv/pd/server"
 	"github.com/tikv/pd/server/config"
+	"golang.org/x/time/rate"
 )
 
 type auditMiddlewareTestSuite struct {
@@ -272,6 +273,32 @@ func (suite *rateLimitConfigTestSuite) TestUpdateRateLimitConfig() {
 	suite.Equal(uint64(100), result.LimiterConfig["Profile"].ConcurrencyLimit)
 	suite.NoError(err)
 
+	// change identity
+	input = make(map[string]interface{})
+	input["type"] = "identity"
+	input["identity"] = "robot"
+	input["qps"] = 10
+	jsonBody, err = json.Marshal(input)
+	suite.NoError(err)
+	err = tu.CheckPostJSON(testDialClient, urlPrefix, jsonBody,
+		tu.StatusNotOK(re), tu.StringContain(re, "The identity is invalid"))
+	suite.NoError(err)
+	input["identity"] = "cn:robot"
+	jsonBody, err = json.Marshal(input)
+	suite.NoError(err)
+	result = rateLimitResult{}
+	err = tu.CheckPostJSON(testDialClient, urlPrefix, jsonBody,
+		tu.StatusOK(re), tu.StringContain(re, "QPS rate limiter is changed."),
+		tu.ExtractJSON(re, &result),
+	)
+	suite.NoError(err)
+	suite.Equal(10., result.IdentityLimiterConfig["cn:robot"].QPS)
+	suite.Equal(10, result.IdentityLimiterConfig["cn:robot"].QPSBurst)
+	suite.Equal(100., result.LimiterConfig["Profile"].QPS)
+	qps, burst := suite.svr.GetIdentityRateLimiter().GetQPSLimiterStatus("cn:robot")
+	suite.Equal(rate.Limit(10), qps)
+	suite.Equal(10, burst)
+
 	limiter := suite.svr.GetServiceRateLimiter()
 	limiter.Update("SetRatelimitConfig", ratelimit.AddLabelAllowList())
 
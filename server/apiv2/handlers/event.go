@@ -0,0 +1,164 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/event"
+	"github.com/tikv/pd/server/apiv2/middlewares"
+	"github.com/tikv/pd/server/cluster"
+)
+
+// eventKeepaliveInterval is the interval of the comments sent to keep the idle
+// event stream alive through the proxies.
+const eventKeepaliveInterval = 30 * time.Second
+
+// RegisterEvent registers the cluster event related handlers to router paths.
+func RegisterEvent(r *gin.RouterGroup) {
+	router := r.Group("events")
+	router.Use(middlewares.BootstrapChecker())
+	router.GET("", WatchEvents)
+}
+
+// eventFilter is the conditions the streamed events must satisfy.
+type eventFilter struct {
+	types      map[event.Type]struct{}
+	categories map[string]struct{}
+	storeID    uint64
+	regionID   uint64
+}
+
+func parseEventFilter(c *gin.Context) (*eventFilter, bool) {
+	filter := &eventFilter{types: make(map[event.Type]struct{}), categories: make(map[string]struct{})}
+	for _, typ := range c.QueryArray("type") {
+		filter.types[event.Type(typ)] = struct{}{}
+	}
+	for _, category := range c.QueryArray("category") {
+		filter.categories[category] = struct{}{}
+	}
+	for name, id := range map[string]*uint64{"store_id": &filter.storeID, "region_id": &filter.regionID} {
+		if value := c.Query(name); len(value) > 0 {
+			var err error
+			if *id, err = strconv.ParseUint(value, 10, 64); err != nil {
+				middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid "+name[:len(name)-3]+" id: "+value)
+				return nil, false
+			}
+		}
+	}
+	return filter, true
+}
+
+func (f *eventFilter) match(e *event.Event) bool {
+	if len(f.types) > 0 {
+		if _, ok := f.types[e.Type]; !ok {
+			return false
+		}
+	}
+	if len(f.categories) > 0 {
+		if _, ok := f.categories[e.Type.Category()]; !ok {
+			return false
+		}
+	}
+	return (f.storeID == 0 || e.StoreID == f.storeID) && (f.regionID == 0 || e.RegionID == f.regionID)
+}
+
+// WatchEvents streams the cluster events.
+// @Tags     events
+// @Summary  Stream the cluster events matching the filter as server-sent events, whose names are the event types and ids are the resume tokens.
+// @Param    type           query   []string  false  "Only the events of the types"  collectionFormat(multi)
+// @Param    category       query   []string  false  "Only the events of the categories, i.e. store, leader, operator, rule, label, member, gc and buckets"  collectionFormat(multi)
+// @Param    store_id       query   integer   false  "Only the events of the store"
+// @Param    region_id      query   integer   false  "Only the events of the region"
+// @Param    resume_token   query   string    false  "Resume the events after the token, the same as the Last-Event-ID header"
+// @Param    Last-Event-ID  header  string    false  "Resume the events after the token"
+// @Produce  text/event-stream
+// @Success  200  {object}  event.Event
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  410  {object}  middlewares.ErrorResponse  "The events after the resume token are no longer kept."
+// @Router   /events [get]
+func WatchEvents(c *gin.Context) {
+	hub := c.MustGet("cluster").(*cluster.RaftCluster).GetEventHub()
+	filter, ok := parseEventFilter(c)
+	if !ok {
+		return
+	}
+	token := c.GetHeader("Last-Event-ID")
+	if len(token) == 0 {
+		token = c.Query("resume_token")
+	}
+	sub, err := hub.Subscribe(token)
+	if err != nil {
+		if errs.ErrEventCompacted.Equal(err) {
+			middlewares.AbortWithError(c, http.StatusGone, err)
+			return
+		}
+		middlewares.AbortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+	ctx := c.Request.Context()
+	for {
+		nextCtx, cancel := context.WithTimeout(ctx, eventKeepaliveInterval)
+		events, err := sub.Next(nextCtx)
+		cancel()
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err == context.DeadlineExceeded:
+			if _, err = io.WriteString(c.Writer, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case err != nil:
+			// Tell the client why the stream ends, it should reload the states of the cluster.
+			fmt.Fprintf(c.Writer, "event: error\ndata: %q\n\n", err.Error())
+			c.Writer.Flush()
+			return
+		default:
+			for _, e := range events {
+				if !filter.match(e) {
+					continue
+				}
+				if err = writeClusterEvent(c.Writer, hub.ResumeToken(e), e); err != nil {
+					return
+				}
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// writeClusterEvent writes the cluster event as a server-sent event.
+func writeClusterEvent(w io.Writer, token string, e *event.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", token, e.Type, data)
+	return err
+}
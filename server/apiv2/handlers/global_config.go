@@ -0,0 +1,308 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/apiv2/middlewares"
+	"github.com/tikv/pd/server/globalconfig"
+)
+
+const (
+	defaultGlobalConfigHistoryLimit = 10
+	maxGlobalConfigHistoryLimit     = 1000
+)
+
+// RegisterGlobalConfig registers the global config related handlers to router paths.
+func RegisterGlobalConfig(r *gin.RouterGroup) {
+	router := r.Group("global-config/:namespace")
+	router.GET("/items", LoadGlobalConfigItems)
+	router.GET("/items/:name", LoadGlobalConfigItem)
+	router.GET("/items/:name/history", LoadGlobalConfigHistory)
+	router.PUT("/items/:name", PutGlobalConfigItem)
+	router.DELETE("/items/:name", DeleteGlobalConfigItem)
+	router.GET("/watch", watchGlobalConfig)
+}
+
+// GlobalConfigItems represents the items of a namespace loaded at the revision.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type GlobalConfigItems struct {
+	Items    []*globalconfig.Item `json:"items"`
+	Revision int64                `json:"revision"`
+}
+
+// PutGlobalConfigParams represents parameters needed when saving a global config item.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type PutGlobalConfigParams struct {
+	Value string `json:"value"`
+	// ExpectedRevision makes the write a compare-and-swap, which succeeds only if
+	// the item is modified at the revision, and 0 means the item must not exist.
+	ExpectedRevision *int64 `json:"expected_revision,omitempty"`
+}
+
+// LoadGlobalConfigItems returns all the items in the namespace.
+// @Tags     global-config
+// @Summary  Load all the global config items in the namespace.
+// @Param    namespace  path  string  true  "The namespace"
+// @Produce  json
+// @Success  200  {object}  GlobalConfigItems
+// @Failure  400  {object}  middlewares.ErrorResponse  "The namespace is invalid."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /global-config/{namespace}/items [get]
+func LoadGlobalConfigItems(c *gin.Context) {
+	store := c.MustGet("server").(*server.Server).GetGlobalConfigStore()
+	items, revision, err := store.LoadAll(c.Request.Context(), c.Param("namespace"))
+	if err != nil {
+		abortWithGlobalConfigError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, &GlobalConfigItems{Items: items, Revision: revision})
+}
+
+// LoadGlobalConfigItem returns the item.
+// @Tags     global-config
+// @Summary  Load the global config item, at the revision if it is given.
+// @Param    namespace  path   string   true   "The namespace"
+// @Param    name       path   string   true   "The name of the item"
+// @Param    revision   query  integer  false  "The revision to read at"
+// @Produce  json
+// @Success  200  {object}  globalconfig.Item
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  404  {object}  middlewares.ErrorResponse  "The item does not exist."
+// @Failure  410  {object}  middlewares.ErrorResponse  "The revision has been compacted."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /global-config/{namespace}/items/{name} [get]
+func LoadGlobalConfigItem(c *gin.Context) {
+	var revision int64
+	if value := c.Query("revision"); len(value) > 0 {
+		var err error
+		revision, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || revision <= 0 {
+			middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid revision: "+value)
+			return
+		}
+	}
+	store := c.MustGet("server").(*server.Server).GetGlobalConfigStore()
+	item, err := store.Load(c.Request.Context(), c.Param("namespace"), c.Param("name"), revision)
+	if err != nil {
+		abortWithGlobalConfigError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, item)
+}
+
+// LoadGlobalConfigHistory returns the history of the item.
+// @Tags     global-config
+// @Summary  Load the versions of the global config item from the latest one, the compacted versions are not available.
+// @Param    namespace  path   string   true   "The namespace"
+// @Param    name       path   string   true   "The name of the item"
+// @Param    limit      query  integer  false  "The max number of the versions"  default(10)
+// @Produce  json
+// @Success  200  {array}   globalconfig.Item
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  404  {object}  middlewares.ErrorResponse  "The item does not exist."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /global-config/{namespace}/items/{name}/history [get]
+func LoadGlobalConfigHistory(c *gin.Context) {
+	limit := defaultGlobalConfigHistoryLimit
+	if value := c.Query("limit"); len(value) > 0 {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid limit: "+value)
+			return
+		}
+		if limit > maxGlobalConfigHistoryLimit {
+			limit = maxGlobalConfigHistoryLimit
+		}
+	}
+	store := c.MustGet("server").(*server.Server).GetGlobalConfigStore()
+	items, err := store.History(c.Request.Context(), c.Param("namespace"), c.Param("name"), limit)
+	if err != nil {
+		abortWithGlobalConfigError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, items)
+}
+
+// PutGlobalConfigItem saves the item.
+// @Tags     global-config
+// @Summary  Save the global config item, which is a compare-and-swap if the expected revision is given.
+// @Param    namespace  path  string                 true  "The namespace"
+// @Param    name       path  string                 true  "The name of the item"
+// @Param    body       body  PutGlobalConfigParams  true  "The value and the expected revision"
+// @Produce  json
+// @Success  200  {object}  globalconfig.Item
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  409  {object}  middlewares.ErrorResponse  "The item has been modified."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /global-config/{namespace}/items/{name} [put]
+func PutGlobalConfigItem(c *gin.Context) {
+	params := &PutGlobalConfigParams{}
+	if err := c.BindJSON(params); err != nil {
+		middlewares.AbortWithError(c, http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause())
+		return
+	}
+	expectedRevision := globalconfig.AnyRevision
+	if params.ExpectedRevision != nil {
+		if *params.ExpectedRevision < 0 {
+			middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid expected revision")
+			return
+		}
+		expectedRevision = *params.ExpectedRevision
+	}
+	store := c.MustGet("server").(*server.Server).GetGlobalConfigStore()
+	item, err := store.Put(c.Request.Context(), c.Param("namespace"), c.Param("name"), params.Value, expectedRevision)
+	if err != nil {
+		abortWithGlobalConfigError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, item)
+}
+
+// DeleteGlobalConfigItem deletes the item.
+// @Tags     global-config
+// @Summary  Delete the global config item, which is a compare-and-swap if the expected revision is given.
+// @Param    namespace          path   string   true   "The namespace"
+// @Param    name               path   string   true   "The name of the item"
+// @Param    expected_revision  query  integer  false  "The revision the item is expected to be modified at"
+// @Success  200
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  404  {object}  middlewares.ErrorResponse  "The item does not exist."
+// @Failure  409  {object}  middlewares.ErrorResponse  "The item has been modified."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /global-config/{namespace}/items/{name} [delete]
+func DeleteGlobalConfigItem(c *gin.Context) {
+	expectedRevision := globalconfig.AnyRevision
+	if value := c.Query("expected_revision"); len(value) > 0 {
+		var err error
+		expectedRevision, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || expectedRevision < 0 {
+			middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid expected revision: "+value)
+			return
+		}
+	}
+	store := c.MustGet("server").(*server.Server).GetGlobalConfigStore()
+	if _, err := store.Delete(c.Request.Context(), c.Param("namespace"), c.Param("name"), expectedRevision); err != nil {
+		abortWithGlobalConfigError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// watchGlobalConfig streams the changes in the namespace as server-sent events,
+// whose names are the change types and data are the changed items. The event
+// ids are the etcd revisions of the changes. A client resuming with the
+// Last-Event-ID header receives the changes since that revision again, so the
+// changes are delivered at least once, and the revision query works the same.
+// Otherwise, it receives all the existing items as snapshot events first, and
+// only the last snapshot event has an id.
+func watchGlobalConfig(c *gin.Context) {
+	store := c.MustGet("server").(*server.Server).GetGlobalConfigStore()
+	ctx := c.Request.Context()
+	namespace := c.Param("namespace")
+	var (
+		snapshot      []*globalconfig.Item
+		startRevision int64
+		err           error
+	)
+	start := c.GetHeader("Last-Event-ID")
+	if len(start) == 0 {
+		start = c.Query("revision")
+	}
+	if len(start) > 0 {
+		startRevision, err = strconv.ParseInt(start, 10, 64)
+		if err != nil || startRevision <= 0 {
+			middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid start revision: "+start)
+			return
+		}
+	} else {
+		var revision int64
+		snapshot, revision, err = store.LoadAll(ctx, namespace)
+		if err != nil {
+			abortWithGlobalConfigError(c, err)
+			return
+		}
+		startRevision = revision + 1
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+	for i, item := range snapshot {
+		var id int64
+		if i == len(snapshot)-1 {
+			id = startRevision - 1
+		}
+		if err = writeGlobalConfigEvent(c.Writer, id, snapshotEvent, item); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+	err = store.Watch(ctx, namespace, startRevision, func(changes []*globalconfig.Change) error {
+		for _, change := range changes {
+			if err := writeGlobalConfigEvent(c.Writer, change.Item.Revision, string(change.Type), change.Item); err != nil {
+				return err
+			}
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil {
+		// Tell the client why the stream ends, it may resume or reload all items.
+		fmt.Fprintf(c.Writer, "event: error\ndata: %q\n\n", err.Error())
+		c.Writer.Flush()
+	}
+}
+
+// writeGlobalConfigEvent writes the item as a server-sent event. The id is
+// omitted if it is zero.
+func writeGlobalConfigEvent(w io.Writer, id int64, event string, item *globalconfig.Item) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	if id > 0 {
+		if _, err = fmt.Fprintf(w, "id: %d\n", id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
+
+func abortWithGlobalConfigError(c *gin.Context, err error) {
+	switch {
+	case errs.ErrInvalidGlobalConfigNamespace.Equal(err):
+		middlewares.AbortWithError(c, http.StatusBadRequest, err)
+	case errs.ErrGlobalConfigNotFound.Equal(err):
+		middlewares.AbortWithError(c, http.StatusNotFound, err)
+	case errs.ErrGlobalConfigConflict.Equal(err):
+		middlewares.AbortWithError(c, http.StatusConflict, err)
+	case errs.ErrGlobalConfigCompacted.Equal(err):
+		middlewares.AbortWithError(c, http.StatusGone, err)
+	default:
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/job"
+	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/apiv2/middlewares"
+	"github.com/tikv/pd/server/cluster"
+)
+
+// RegisterJob registers the asynchronous admin job related handlers to router paths.
+func RegisterJob(r *gin.RouterGroup) {
+	router := r.Group("jobs")
+	router.Use(middlewares.BootstrapChecker())
+	router.GET("", GetJobs)
+	router.POST("", CreateJob)
+	router.GET("/:id", GetJob)
+	router.DELETE("/:id", CancelJob)
+}
+
+// CreateJobParams represents parameters needed when creating a job.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type CreateJobParams struct {
+	// Type is one of "scatter", "range-scatter", "drain", "unsafe-recovery", "metadata-compaction" and "rule-bundle".
+	Type string `json:"type"`
+	// Params are the parameters of the job, whose format depends on the type.
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// jobBuilder parses the parameters and builds the job to be submitted.
+type jobBuilder func(svr *server.Server, rc *cluster.RaftCluster, params json.RawMessage) (*job.Spec, error)
+
+var jobBuilders = map[string]jobBuilder{
+	scatterJobType:            newScatterJob,
+	rangeScatterJobType:       newRangeScatterJob,
+	drainJobType:              newDrainJob,
+	unsafeRecoveryJobType:     newUnsafeRecoveryJob,
+	metadataCompactionJobType: newMetadataCompactionJob,
+	ruleBundleJobType:         newRuleBundleJob,
+}
+
+// CreateJob creates an asynchronous admin job.
+// @Tags     jobs
+// @Summary  Start an asynchronous admin job, and return it without waiting for the job.
+// @Param    body  body  CreateJobParams  true  "The type and the parameters of the job"
+// @Produce  json
+// @Success  202  {object}  job.Job
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /jobs [post]
+func CreateJob(c *gin.Context) {
+	params := &CreateJobParams{}
+	if err := c.BindJSON(params); err != nil {
+		middlewares.AbortWithError(c, http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause())
+		return
+	}
+	build, ok := jobBuilders[params.Type]
+	if !ok {
+		middlewares.AbortWithMessage(c, http.StatusBadRequest, "unknown job type "+params.Type)
+		return
+	}
+	svr := c.MustGet("server").(*server.Server)
+	spec, err := build(svr, c.MustGet("cluster").(*cluster.RaftCluster), params.Params)
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	res, err := svr.GetJobManager().Submit(spec)
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.IndentedJSON(http.StatusAccepted, res)
+}
+
+// GetJobs returns the jobs.
+// @Tags     jobs
+// @Summary  Get the running jobs and the recently finished jobs.
+// @Param    type    query  string  false  "Filter by the type of the jobs"
+// @Param    status  query  string  false  "Filter by the status of the jobs"  Enums(running, succeeded, failed, canceled)
+// @Produce  json
+// @Success  200  {array}  job.Job
+// @Router   /jobs [get]
+func GetJobs(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	c.IndentedJSON(http.StatusOK, svr.GetJobManager().GetJobs(c.Query("type"), job.Status(c.Query("status"))))
+}
+
+// GetJob returns the job.
+// @Tags     jobs
+// @Summary  Get the status, the progress and the result of the job.
+// @Param    id  path  integer  true  "The id of the job"
+// @Produce  json
+// @Success  200  {object}  job.Job
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  404  {object}  middlewares.ErrorResponse  "The job does not exist."
+// @Router   /jobs/{id} [get]
+func GetJob(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid job id: "+c.Param("id"))
+		return
+	}
+	res, err := c.MustGet("server").(*server.Server).GetJobManager().GetJob(id)
+	if err != nil {
+		abortWithJobError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// CancelJob cancels the job.
+// @Tags     jobs
+// @Summary  Request the running job to stop, the job is canceled once it stops.
+// @Param    id  path  integer  true  "The id of the job"
+// @Success  202
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid, or the job can't be canceled."
+// @Failure  404  {object}  middlewares.ErrorResponse  "The job does not exist."
+// @Failure  409  {object}  middlewares.ErrorResponse  "The job has finished."
+// @Router   /jobs/{id} [delete]
+func CancelJob(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid job id: "+c.Param("id"))
+		return
+	}
+	if err := c.MustGet("server").(*server.Server).GetJobManager().CancelJob(id); err != nil {
+		abortWithJobError(c, err)
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+func abortWithJobError(c *gin.Context, err error) {
+	switch {
+	case errs.ErrJobNotFound.Equal(err):
+		middlewares.AbortWithError(c, http.StatusNotFound, err)
+	case errs.ErrJobFinished.Equal(err):
+		middlewares.AbortWithError(c, http.StatusConflict, err)
+	case errs.ErrJobNotCancelable.Equal(err):
+		middlewares.AbortWithError(c, http.StatusBadRequest, err)
+	default:
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+	}
+}
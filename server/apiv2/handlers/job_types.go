@@ -0,0 +1,501 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/job"
+	"github.com/tikv/pd/pkg/utils/etcdutil"
+	"github.com/tikv/pd/pkg/utils/keyutil"
+	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/cluster"
+	"github.com/tikv/pd/server/schedule/placement"
+	"go.etcd.io/etcd/clientv3"
+	"go.uber.org/zap"
+)
+
+const (
+	scatterJobType            = "scatter"
+	rangeScatterJobType       = "range-scatter"
+	drainJobType              = "drain"
+	unsafeRecoveryJobType     = "unsafe-recovery"
+	metadataCompactionJobType = "metadata-compaction"
+	ruleBundleJobType         = "rule-bundle"
+
+	// scatterJobBatchSize is the number of the regions scattered in a batch,
+	// the job can be canceled between the batches.
+	scatterJobBatchSize          = 128
+	defaultScatterRetryLimit     = 5
+	maxRangeScatterRegions       = 10000
+	defaultUnsafeRecoveryTimeout = 600
+	// jobPollInterval is the interval to check the progress of the jobs
+	// waiting for the cluster.
+	jobPollInterval = time.Second
+)
+
+// ScatterJobParams represents parameters needed by the scatter job.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type ScatterJobParams struct {
+	// StartKey and EndKey are the hex encoded key range of the regions, empty for no limit.
+	StartKey string `json:"start_key,omitempty"`
+	EndKey   string `json:"end_key,omitempty"`
+	// Group scatters the regions in the group level instead of the cluster level.
+	Group string `json:"group,omitempty"`
+	// RetryLimit is the retry times of a region failed to be scattered, 5 if it is 0.
+	RetryLimit int `json:"retry_limit,omitempty"`
+}
+
+// ScatterJobResult is the result of the scatter job.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type ScatterJobResult struct {
+	Regions   int               `json:"regions"`
+	Operators int               `json:"operators"`
+	Failures  map[uint64]string `json:"failures,omitempty"`
+}
+
+// RangeScatterJobParams represents parameters needed by the range scatter job,
+// which splits the key range into regions and then scatters them.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type RangeScatterJobParams struct {
+	// StartKey and EndKey are the hex encoded key range of the regions, which can't be empty.
+	StartKey string `json:"start_key"`
+	EndKey   string `json:"end_key"`
+	// SplitKeys are the hex encoded keys in the range to split at.
+	SplitKeys []string `json:"split_keys,omitempty"`
+	// Regions is the number of the regions the range is split into evenly if
+	// SplitKeys is empty, the range is only split at its boundaries if it is 0.
+	Regions int `json:"regions,omitempty"`
+	// Group scatters the regions in the group level instead of the cluster level.
+	Group string `json:"group,omitempty"`
+	// RetryLimit is the retry times of a region failed to be split or scattered, 5 if it is 0.
+	RetryLimit int `json:"retry_limit,omitempty"`
+}
+
+// RangeScatterJobResult is the result of the range scatter job.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type RangeScatterJobResult struct {
+	// SplitPercentage is the percentage of the split keys processed.
+	SplitPercentage int      `json:"split_percentage"`
+	NewRegions      []uint64 `json:"new_regions,omitempty"`
+	ScatterJobResult
+}
+
+// DrainJobParams represents parameters needed by the drain job.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type DrainJobParams struct {
+	StoreIDs []uint64 `json:"store_ids"`
+	// Force means the drained stores are physically destroyed.
+	Force bool `json:"force,omitempty"`
+}
+
+// UnsafeRecoveryJobParams represents parameters needed by the unsafe recovery job.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type UnsafeRecoveryJobParams struct {
+	// StoreIDs are the failed stores, which are ignored if AutoDetect is set.
+	StoreIDs   []uint64 `json:"store_ids,omitempty"`
+	AutoDetect bool     `json:"auto_detect,omitempty"`
+	// Timeout is the seconds the recovery can take, 600 if it is 0.
+	Timeout uint64 `json:"timeout,omitempty"`
+}
+
+// RuleBundleJobParams represents parameters needed by the rule bundle job,
+// which applies the rule bundles and waits for the regions to satisfy them.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type RuleBundleJobParams struct {
+	Bundles []placement.GroupBundle `json:"bundles"`
+	// Partial keeps the groups not in the bundles, otherwise they are removed.
+	Partial bool `json:"partial,omitempty"`
+}
+
+// RuleBundleJobResult is the result of the rule bundle job.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type RuleBundleJobResult struct {
+	// Regions is the number of the regions affected by the bundles.
+	Regions int `json:"regions"`
+}
+
+// MetadataCompactionJobParams represents parameters needed by the metadata compaction job.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type MetadataCompactionJobParams struct {
+	// Revision is the etcd revision to compact to, the current revision if it is 0.
+	Revision int64 `json:"revision,omitempty"`
+	// Physical waits until the compacted revisions are removed from the backend.
+	Physical bool `json:"physical,omitempty"`
+}
+
+// MetadataCompactionJobResult is the result of the metadata compaction job.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type MetadataCompactionJobResult struct {
+	Revision int64 `json:"revision"`
+}
+
+func parseJobParams(raw json.RawMessage, params interface{}) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, params); err != nil {
+		return errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+	}
+	return nil
+}
+
+func newScatterJob(_ *server.Server, rc *cluster.RaftCluster, raw json.RawMessage) (*job.Spec, error) {
+	params := &ScatterJobParams{}
+	if err := parseJobParams(raw, params); err != nil {
+		return nil, err
+	}
+	startKey, err := hex.DecodeString(params.StartKey)
+	if err != nil {
+		return nil, errors.New("invalid start key: " + params.StartKey)
+	}
+	endKey, err := hex.DecodeString(params.EndKey)
+	if err != nil {
+		return nil, errors.New("invalid end key: " + params.EndKey)
+	}
+	retryLimit := params.RetryLimit
+	if retryLimit <= 0 {
+		retryLimit = defaultScatterRetryLimit
+	}
+	run := func(ctx context.Context, report job.Reporter) (interface{}, error) {
+		result := &ScatterJobResult{Failures: make(map[uint64]string)}
+		err := scatterRange(ctx, rc, startKey, endKey, params.Group, retryLimit, result, func(done, total int) {
+			report(float64(done)/float64(total), fmt.Sprintf("scattered %d/%d regions", done, total))
+		})
+		return result, err
+	}
+	return &job.Spec{Type: scatterJobType, Params: params, Cancelable: true, Run: run}, nil
+}
+
+// scatterRange scatters the regions in the key range batch by batch, and reports
+// the number of the scattered regions after each batch.
+func scatterRange(ctx context.Context, rc *cluster.RaftCluster, startKey, endKey []byte, group string, retryLimit int,
+	result *ScatterJobResult, report func(done, total int)) error {
+	regions := rc.ScanRegions(startKey, endKey, 0)
+	ids := make([]uint64, 0, len(regions))
+	for _, region := range regions {
+		ids = append(ids, region.GetID())
+	}
+	for start := 0; start < len(ids); start += scatterJobBatchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		end := start + scatterJobBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		opsCount, failures, err := rc.GetRegionScatter().ScatterRegionsByID(ids[start:end], group, retryLimit)
+		if err != nil {
+			return err
+		}
+		result.Regions += end - start
+		result.Operators += opsCount
+		for id, err := range failures {
+			result.Failures[id] = err.Error()
+		}
+		report(end, len(ids))
+	}
+	return nil
+}
+
+func newRangeScatterJob(_ *server.Server, rc *cluster.RaftCluster, raw json.RawMessage) (*job.Spec, error) {
+	params := &RangeScatterJobParams{}
+	if err := parseJobParams(raw, params); err != nil {
+		return nil, err
+	}
+	startKey, err := hex.DecodeString(params.StartKey)
+	if err != nil {
+		return nil, errors.New("invalid start key: " + params.StartKey)
+	}
+	endKey, err := hex.DecodeString(params.EndKey)
+	if err != nil {
+		return nil, errors.New("invalid end key: " + params.EndKey)
+	}
+	if len(startKey) == 0 || len(endKey) == 0 || bytes.Compare(startKey, endKey) >= 0 {
+		return nil, errors.New("invalid key range")
+	}
+	if params.Regions < 0 || params.Regions > maxRangeScatterRegions {
+		return nil, errors.Errorf("invalid regions: %d", params.Regions)
+	}
+	// The boundaries of the range are split first, so that the regions in the
+	// range don't contain any key out of it.
+	splitKeys := [][]byte{startKey}
+	if len(params.SplitKeys) > 0 {
+		for _, value := range params.SplitKeys {
+			key, err := hex.DecodeString(value)
+			if err != nil {
+				return nil, errors.New("invalid split key: " + value)
+			}
+			if bytes.Compare(key, startKey) <= 0 || bytes.Compare(key, endKey) >= 0 {
+				return nil, errors.New("split key out of range: " + value)
+			}
+			splitKeys = append(splitKeys, key)
+		}
+	} else if params.Regions > 1 {
+		keys := keyutil.SplitRange(startKey, endKey, params.Regions)
+		if keys == nil {
+			return nil, errors.Errorf("key range is too small to be split into %d regions", params.Regions)
+		}
+		splitKeys = append(splitKeys, keys...)
+	}
+	splitKeys = append(splitKeys, endKey)
+	retryLimit := params.RetryLimit
+	if retryLimit <= 0 {
+		retryLimit = defaultScatterRetryLimit
+	}
+	run := func(ctx context.Context, report job.Reporter) (interface{}, error) {
+		result := &RangeScatterJobResult{ScatterJobResult: ScatterJobResult{Failures: make(map[uint64]string)}}
+		// Only the keys not at the boundaries of the regions need to be split.
+		keys := make([][]byte, 0, len(splitKeys))
+		for _, key := range splitKeys {
+			if region := rc.GetRegionByKey(key); region == nil || !bytes.Equal(region.GetStartKey(), key) {
+				keys = append(keys, key)
+			}
+		}
+		result.SplitPercentage = 100
+		if len(keys) > 0 {
+			report(0, fmt.Sprintf("splitting %d keys", len(keys)))
+			result.SplitPercentage, result.NewRegions = rc.GetRegionSplitter().SplitRegions(ctx, keys, retryLimit)
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+		}
+		// The split takes the first half of the progress.
+		report(0.5, fmt.Sprintf("%d%% of the keys split", result.SplitPercentage))
+		err := scatterRange(ctx, rc, startKey, endKey, params.Group, retryLimit, &result.ScatterJobResult, func(done, total int) {
+			report(0.5+0.5*float64(done)/float64(total), fmt.Sprintf("scattered %d/%d regions", done, total))
+		})
+		return result, err
+	}
+	return &job.Spec{Type: rangeScatterJobType, Params: params, Cancelable: true, Run: run}, nil
+}
+
+func newDrainJob(_ *server.Server, rc *cluster.RaftCluster, raw json.RawMessage) (*job.Spec, error) {
+	params := &DrainJobParams{}
+	if err := parseJobParams(raw, params); err != nil {
+		return nil, err
+	}
+	if len(params.StoreIDs) == 0 {
+		return nil, errors.New("no store specified")
+	}
+	for _, id := range params.StoreIDs {
+		if rc.GetStore(id) == nil {
+			return nil, errs.ErrStoreNotFound.FastGenByArgs(id)
+		}
+	}
+	run := func(ctx context.Context, report job.Reporter) (interface{}, error) {
+		total := 0
+		for _, id := range params.StoreIDs {
+			if store := rc.GetStore(id); store != nil && store.IsRemoved() {
+				continue
+			}
+			if err := rc.RemoveStore(id, params.Force); err != nil {
+				return nil, err
+			}
+			total += rc.GetStoreRegionCount(id)
+		}
+		ticker := time.NewTicker(jobPollInterval)
+		defer ticker.Stop()
+		for {
+			remaining, removed := 0, 0
+			for _, id := range params.StoreIDs {
+				if store := rc.GetStore(id); store == nil || store.IsRemoved() {
+					removed++
+					continue
+				}
+				remaining += rc.GetStoreRegionCount(id)
+			}
+			if removed == len(params.StoreIDs) {
+				return nil, nil
+			}
+			progress := 0.0
+			if total > 0 && remaining < total {
+				progress = float64(total-remaining) / float64(total)
+			}
+			report(progress, fmt.Sprintf("%d/%d stores removed, %d regions remaining", removed, len(params.StoreIDs), remaining))
+			select {
+			case <-ctx.Done():
+				undrainStores(rc, params.StoreIDs)
+				return nil, ctx.Err()
+			case <-ticker.C:
+			}
+		}
+	}
+	return &job.Spec{Type: drainJobType, Params: params, Cancelable: true, Run: run}, nil
+}
+
+// undrainStores brings the stores not removed yet back to up.
+func undrainStores(rc *cluster.RaftCluster, storeIDs []uint64) {
+	for _, id := range storeIDs {
+		store := rc.GetStore(id)
+		if store == nil || store.IsRemoved() || store.IsPhysicallyDestroyed() {
+			continue
+		}
+		if err := rc.UpStore(id); err != nil {
+			log.Warn("failed to undrain store", zap.Uint64("store-id", id), errs.ZapError(err))
+		}
+	}
+}
+
+func newUnsafeRecoveryJob(_ *server.Server, rc *cluster.RaftCluster, raw json.RawMessage) (*job.Spec, error) {
+	params := &UnsafeRecoveryJobParams{}
+	if err := parseJobParams(raw, params); err != nil {
+		return nil, err
+	}
+	if !params.AutoDetect && len(params.StoreIDs) == 0 {
+		return nil, errors.New("no store specified")
+	}
+	timeout := params.Timeout
+	if timeout == 0 {
+		timeout = defaultUnsafeRecoveryTimeout
+	}
+	run := func(ctx context.Context, report job.Reporter) (interface{}, error) {
+		stores := make(map[uint64]struct{}, len(params.StoreIDs))
+		if !params.AutoDetect {
+			for _, id := range params.StoreIDs {
+				stores[id] = struct{}{}
+			}
+		}
+		controller := rc.GetUnsafeRecoveryController()
+		if err := controller.RemoveFailedStores(stores, timeout, params.AutoDetect); err != nil {
+			return nil, err
+		}
+		ticker := time.NewTicker(jobPollInterval)
+		defer ticker.Stop()
+		for {
+			done, err := controller.Result()
+			output := controller.Show()
+			if done {
+				return output, err
+			}
+			if len(output) > 0 {
+				report(controller.GetProgress(), output[len(output)-1].Info)
+			}
+			select {
+			case <-ctx.Done():
+				return output, ctx.Err()
+			case <-ticker.C:
+			}
+		}
+	}
+	// The unsafe recovery can't be stopped once it starts.
+	return &job.Spec{Type: unsafeRecoveryJobType, Params: params, Run: run}, nil
+}
+
+func newMetadataCompactionJob(svr *server.Server, _ *cluster.RaftCluster, raw json.RawMessage) (*job.Spec, error) {
+	params := &MetadataCompactionJobParams{}
+	if err := parseJobParams(raw, params); err != nil {
+		return nil, err
+	}
+	if params.Revision < 0 {
+		return nil, errors.New("invalid revision")
+	}
+	run := func(ctx context.Context, report job.Reporter) (interface{}, error) {
+		client := svr.GetClient()
+		revision := params.Revision
+		if revision == 0 {
+			resp, err := etcdutil.EtcdKVGet(client, svr.GetMember().GetLeaderPath())
+			if err != nil {
+				return nil, err
+			}
+			revision = resp.Header.GetRevision()
+		}
+		report(0, fmt.Sprintf("compacting to revision %d", revision))
+		var opts []clientv3.CompactOption
+		if params.Physical {
+			opts = append(opts, clientv3.WithCompactPhysical())
+		}
+		if _, err := client.Compact(ctx, revision, opts...); err != nil {
+			return nil, err
+		}
+		return &MetadataCompactionJobResult{Revision: revision}, nil
+	}
+	// The compaction can't be stopped once etcd accepts it.
+	return &job.Spec{Type: metadataCompactionJobType, Params: params, Run: run}, nil
+}
+
+func newRuleBundleJob(svr *server.Server, rc *cluster.RaftCluster, raw json.RawMessage) (*job.Spec, error) {
+	if !rc.GetOpts().IsPlacementRulesEnabled() {
+		return nil, errors.New("placement rules feature is disabled")
+	}
+	params := &RuleBundleJobParams{}
+	if err := parseJobParams(raw, params); err != nil {
+		return nil, err
+	}
+	// The regions in the whole key space may be affected if the groups not in
+	// the bundles are removed.
+	ranges := [][2][]byte{{nil, nil}}
+	if params.Partial {
+		ranges = ranges[:0]
+		for _, bundle := range params.Bundles {
+			for _, rule := range bundle.Rules {
+				startKey, err := hex.DecodeString(rule.StartKeyHex)
+				if err != nil {
+					return nil, errors.New("invalid start key: " + rule.StartKeyHex)
+				}
+				endKey, err := hex.DecodeString(rule.EndKeyHex)
+				if err != nil {
+					return nil, errors.New("invalid end key: " + rule.EndKeyHex)
+				}
+				ranges = append(ranges, [2][]byte{startKey, endKey})
+			}
+		}
+	}
+	run := func(ctx context.Context, report job.Reporter) (interface{}, error) {
+		report(0, "applying the rule bundles")
+		ruleManager := rc.GetRuleManager()
+		if err := ruleManager.SetKeyType(svr.GetConfig().PDServerCfg.KeyType).
+			SetAllGroupBundles(params.Bundles, !params.Partial); err != nil {
+			return nil, err
+		}
+		ticker := time.NewTicker(jobPollInterval)
+		defer ticker.Stop()
+		for {
+			checked := make(map[uint64]struct{})
+			unsatisfied := 0
+			for _, r := range ranges {
+				for _, region := range rc.ScanRegions(r[0], r[1], 0) {
+					if _, ok := checked[region.GetID()]; ok {
+						continue
+					}
+					checked[region.GetID()] = struct{}{}
+					if !ruleManager.FitRegion(rc, region).IsSatisfied() {
+						unsatisfied++
+					}
+				}
+			}
+			if unsatisfied == 0 {
+				return &RuleBundleJobResult{Regions: len(checked)}, nil
+			}
+			report(float64(len(checked)-unsatisfied)/float64(len(checked)),
+				fmt.Sprintf("waiting for the regions to satisfy the rules, %d/%d satisfied", len(checked)-unsatisfied, len(checked)))
+			select {
+			case <-ctx.Done():
+				// The rules are kept even if the job is canceled.
+				return &RuleBundleJobResult{Regions: len(checked)}, ctx.Err()
+			case <-ticker.C:
+			}
+		}
+	}
+	return &job.Spec{Type: ruleBundleJobType, Params: params, Cancelable: true, Run: run}, nil
+}
@@ -36,9 +36,29 @@ func RegisterKeyspace(r *gin.RouterGroup) {
 	router.Use(middlewares.BootstrapChecker())
 	router.POST("", CreateKeyspace)
 	router.GET("", LoadAllKeyspaces)
+	router.POST("/batch", CreateKeyspaces)
+	router.PATCH("/batch/config", UpdateKeyspacesConfig)
+	router.PUT("/batch/state", UpdateKeyspacesState)
 	router.GET("/:name", LoadKeyspace)
 	router.PATCH("/:name/config", UpdateKeyspaceConfig)
 	router.PUT("/:name/state", UpdateKeyspaceState)
+	router.POST("/:name/rename", RenameKeyspace)
+	router.POST("/:name/archive", ArchiveKeyspace)
+	router.POST("/:name/restore", RestoreKeyspace)
+	router.GET("/:name/quota", GetKeyspaceQuota)
+	router.PUT("/:name/quota", SetKeyspaceQuota)
+	router.GET("/:name/usage", GetKeyspaceUsage)
+	router.GET("/:name/placement", GetKeyspacePlacement)
+	router.PUT("/:name/placement", SetKeyspacePlacement)
+	router.DELETE("/:name/placement", RemoveKeyspacePlacement)
+	router.GET("/:name/gc/safepoint", GetKeyspaceGCSafePoint)
+	router.PUT("/:name/gc/safepoint", UpdateKeyspaceGCSafePoint)
+	router.PUT("/:name/gc/service_safepoint", UpdateKeyspaceServiceGCSafePoint)
+	router.DELETE("/:name/gc/service_safepoint/:service_id", DeleteKeyspaceServiceGCSafePoint)
+	router.GET("/:name/gc/barriers", LoadKeyspaceGCBarriers)
+	router.GET("/:name/gc/barriers/:barrier_id", LoadKeyspaceGCBarrier)
+	router.PUT("/:name/gc/barriers/:barrier_id", SetKeyspaceGCBarrier)
+	router.DELETE("/:name/gc/barriers/:barrier_id", DeleteKeyspaceGCBarrier)
 	router.GET("/id/:id", LoadKeyspaceByID)
 }
 
@@ -47,6 +67,10 @@ func RegisterKeyspace(r *gin.RouterGroup) {
 type CreateKeyspaceParams struct {
 	Name   string            `json:"name"`
 	Config map[string]string `json:"config"`
+	// PreSplit is the number of regions each key range of the keyspace is split into.
+	PreSplit int `json:"pre_split,omitempty"`
+	// ScatterPolicy is one of "none", "keyspace" and "global".
+	ScatterPolicy string `json:"scatter_policy,omitempty"`
 }
 
 // CreateKeyspace creates keyspace according to given input.
@@ -55,8 +79,8 @@ type CreateKeyspaceParams struct {
 // @Param    body  body  CreateKeyspaceParams  true  "Create keyspace parameters"
 // @Produce  json
 // @Success  200  {object}  KeyspaceMeta
-// @Failure  400  {string}  string  "The input is invalid."
-// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
 // @Router   /keyspaces [post]
 func CreateKeyspace(c *gin.Context) {
 	svr := c.MustGet("server").(*server.Server)
@@ -64,17 +88,19 @@ func CreateKeyspace(c *gin.Context) {
 	createParams := &CreateKeyspaceParams{}
 	err := c.BindJSON(createParams)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause())
+		middlewares.AbortWithError(c, http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause())
 		return
 	}
 	req := &keyspace.CreateKeyspaceRequest{
-		Name:   createParams.Name,
-		Config: createParams.Config,
-		Now:    time.Now().Unix(),
+		Name:          createParams.Name,
+		Config:        createParams.Config,
+		Now:           time.Now().Unix(),
+		PreSplit:      createParams.PreSplit,
+		ScatterPolicy: keyspace.ScatterPolicy(createParams.ScatterPolicy),
 	}
 	meta, err := manager.CreateKeyspace(req)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
 		return
 	}
 	c.IndentedJSON(http.StatusOK, &KeyspaceMeta{meta})
@@ -86,7 +112,7 @@ func CreateKeyspace(c *gin.Context) {
 // @Param    name  path  string  true  "Keyspace Name"
 // @Produce  json
 // @Success  200  {object}  KeyspaceMeta
-// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
 // @Router   /keyspaces/{name} [get]
 func LoadKeyspace(c *gin.Context) {
 	svr := c.MustGet("server").(*server.Server)
@@ -94,7 +120,7 @@ func LoadKeyspace(c *gin.Context) {
 	name := c.Param("name")
 	meta, err := manager.LoadKeyspace(name)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
 		return
 	}
 	c.IndentedJSON(http.StatusOK, &KeyspaceMeta{meta})
@@ -106,19 +132,19 @@ func LoadKeyspace(c *gin.Context) {
 // @Param id path string true "Keyspace id"
 // @Produce json
 // @Success 200 {object} KeyspaceMeta
-// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Failure 500 {object} middlewares.ErrorResponse "PD server failed to proceed the request."
 // @Router /keyspaces/id/{id} [get]
 func LoadKeyspaceByID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil || id == 0 {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, "invalid keyspace id")
+		middlewares.AbortWithMessage(c, http.StatusInternalServerError, "invalid keyspace id")
 		return
 	}
 	svr := c.MustGet("server").(*server.Server)
 	manager := svr.GetKeyspaceManager()
 	meta, err := manager.LoadKeyspaceByID(uint32(id))
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
 		return
 	}
 	c.IndentedJSON(http.StatusOK, &KeyspaceMeta{meta})
@@ -171,26 +197,32 @@ type LoadAllKeyspacesResponse struct {
 }
 
 // LoadAllKeyspaces loads range of keyspaces.
+// With watch set, it streams the keyspace changes as server-sent events instead.
 // @Tags     keyspaces
 // @Summary  list keyspaces.
 // @Param    page_token  query  string  false  "page token"
 // @Param    limit       query  string  false  "maximum number of results to return"
+// @Param    watch       query  bool    false  "watch keyspace changes as server-sent events"
 // @Produce  json
 // @Success  200  {object}  LoadAllKeyspacesResponse
-// @Failure  400  {string}  string  "The input is invalid."
-// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
 // @Router   /keyspaces [get]
 func LoadAllKeyspaces(c *gin.Context) {
+	if watch, _ := strconv.ParseBool(c.Query("watch")); watch {
+		watchKeyspaces(c)
+		return
+	}
 	svr := c.MustGet("server").(*server.Server)
 	manager := svr.GetKeyspaceManager()
 	scanStart, scanLimit, err := parseLoadAllQuery(c)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		middlewares.AbortWithError(c, http.StatusBadRequest, err)
 		return
 	}
 	scanned, err := manager.LoadRangeKeyspace(scanStart, scanLimit)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
 		return
 	}
 	resp := &LoadAllKeyspacesResponse{}
@@ -236,8 +268,8 @@ type UpdateConfigParams struct {
 // @Param    body  body  UpdateConfigParams  true  "Update keyspace parameters"
 // @Produce  json
 // @Success  200  {object}  KeyspaceMeta
-// @Failure  400  {string}  string  "The input is invalid."
-// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
 // Router /keyspaces/{name}/config [patch]
 func UpdateKeyspaceConfig(c *gin.Context) {
 	svr := c.MustGet("server").(*server.Server)
@@ -246,13 +278,13 @@ func UpdateKeyspaceConfig(c *gin.Context) {
 	configParams := &UpdateConfigParams{}
 	err := c.BindJSON(configParams)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause())
+		middlewares.AbortWithError(c, http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause())
 		return
 	}
 	mutations := getMutations(configParams.Config)
 	meta, err := manager.UpdateKeyspaceConfig(name, mutations)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
 		return
 	}
 	c.IndentedJSON(http.StatusOK, &KeyspaceMeta{meta})
@@ -291,8 +323,8 @@ type UpdateStateParam struct {
 // @Param    body  body  UpdateStateParam  true  "New state for the keyspace"
 // @Produce  json
 // @Success  200  {object}  KeyspaceMeta
-// @Failure  400  {string}  string  "The input is invalid."
-// @Failure  500  {string}  string  "PD server failed to proceed the request."
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
 // Router /keyspaces/{name}/state [put]
 func UpdateKeyspaceState(c *gin.Context) {
 	svr := c.MustGet("server").(*server.Server)
@@ -301,22 +333,230 @@ func UpdateKeyspaceState(c *gin.Context) {
 	param := &UpdateStateParam{}
 	err := c.BindJSON(param)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause())
+		middlewares.AbortWithError(c, http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause())
 		return
 	}
 	targetState, ok := keyspacepb.KeyspaceState_value[strings.ToUpper(param.State)]
 	if !ok {
-		c.AbortWithStatusJSON(http.StatusBadRequest, errors.Errorf("unknown target state: %s", param.State))
+		middlewares.AbortWithError(c, http.StatusBadRequest, errors.Errorf("unknown target state: %s", param.State))
 		return
 	}
 	meta, err := manager.UpdateKeyspaceState(name, keyspacepb.KeyspaceState(targetState), time.Now().Unix())
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, &KeyspaceMeta{meta})
+}
+
+// RenameParams represents parameters needed to rename a keyspace.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type RenameParams struct {
+	NewName string `json:"new_name"`
+}
+
+// RenameKeyspace renames the target keyspace. The old name is kept as an alias
+// of the keyspace for a grace period.
+// @Tags     keyspaces
+// @Summary  Rename keyspace.
+// @Param    name  path  string        true  "Keyspace Name"
+// @Param    body  body  RenameParams  true  "New name for the keyspace"
+// @Produce  json
+// @Success  200  {object}  KeyspaceMeta
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /keyspaces/{name}/rename [post]
+func RenameKeyspace(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	manager := svr.GetKeyspaceManager()
+	param := &RenameParams{}
+	if err := c.BindJSON(param); err != nil {
+		middlewares.AbortWithError(c, http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause())
+		return
+	}
+	meta, err := manager.RenameKeyspace(c.Param("name"), param.NewName, time.Now().Unix())
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, &KeyspaceMeta{meta})
+}
+
+// ArchiveKeyspace archives the target disabled keyspace.
+// @Tags     keyspaces
+// @Summary  Archive keyspace.
+// @Param    name  path  string  true  "Keyspace Name"
+// @Produce  json
+// @Success  200  {object}  KeyspaceMeta
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /keyspaces/{name}/archive [post]
+func ArchiveKeyspace(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	manager := svr.GetKeyspaceManager()
+	meta, err := manager.ArchiveKeyspace(c.Param("name"), time.Now().Unix())
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
 		return
 	}
 	c.IndentedJSON(http.StatusOK, &KeyspaceMeta{meta})
 }
 
+// RestoreKeyspace restores the target archived keyspace to the disabled state.
+// @Tags     keyspaces
+// @Summary  Restore archived keyspace.
+// @Param    name  path  string  true  "Keyspace Name"
+// @Produce  json
+// @Success  200  {object}  KeyspaceMeta
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /keyspaces/{name}/restore [post]
+func RestoreKeyspace(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	manager := svr.GetKeyspaceManager()
+	meta, err := manager.RestoreKeyspace(c.Param("name"), time.Now().Unix())
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, &KeyspaceMeta{meta})
+}
+
+// GetKeyspaceQuota returns the quota of the target keyspace and the resources used by it.
+// @Tags     keyspaces
+// @Summary  Get keyspace quota and usage.
+// @Param    name  path  string  true  "Keyspace Name"
+// @Produce  json
+// @Success  200  {object}  keyspace.QuotaUsage
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /keyspaces/{name}/quota [get]
+func GetKeyspaceQuota(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	manager := svr.GetKeyspaceManager()
+	usage, err := manager.GetKeyspaceQuotaUsage(c.Param("name"))
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, usage)
+}
+
+// SetKeyspaceQuota sets the quota of the target keyspace. A zero limit means unlimited.
+// @Tags     keyspaces
+// @Summary  Set keyspace quota.
+// @Param    name  path  string          true  "Keyspace Name"
+// @Param    body  body  keyspace.Quota  true  "New quota for the keyspace"
+// @Produce  json
+// @Success  200  {object}  keyspace.QuotaUsage
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /keyspaces/{name}/quota [put]
+func SetKeyspaceQuota(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	manager := svr.GetKeyspaceManager()
+	name := c.Param("name")
+	quota := &keyspace.Quota{}
+	if err := c.BindJSON(quota); err != nil {
+		middlewares.AbortWithError(c, http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause())
+		return
+	}
+	if err := manager.SetKeyspaceQuota(name, quota); err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	usage, err := manager.GetKeyspaceQuotaUsage(name)
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, usage)
+}
+
+// GetKeyspaceUsage returns the resources used by the target keyspace.
+// @Tags     keyspaces
+// @Summary  Get keyspace usage.
+// @Param    name  path  string  true  "Keyspace Name"
+// @Produce  json
+// @Success  200  {object}  keyspace.Usage
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /keyspaces/{name}/usage [get]
+func GetKeyspaceUsage(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	manager := svr.GetKeyspaceManager()
+	usage, err := manager.GetKeyspaceUsage(c.Param("name"))
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, usage)
+}
+
+// GetKeyspacePlacement returns the default placement template of the target keyspace.
+// @Tags     keyspaces
+// @Summary  Get keyspace placement template.
+// @Param    name  path  string  true  "Keyspace Name"
+// @Produce  json
+// @Success  200  {object}  keyspace.PlacementTemplate
+// @Failure  404  {object}  middlewares.ErrorResponse  "The keyspace has no placement template."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /keyspaces/{name}/placement [get]
+func GetKeyspacePlacement(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	manager := svr.GetKeyspaceManager()
+	template, err := manager.GetPlacementTemplate(c.Param("name"))
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if template == nil {
+		middlewares.AbortWithMessage(c, http.StatusNotFound, "keyspace placement template not found")
+		return
+	}
+	c.IndentedJSON(http.StatusOK, template)
+}
+
+// SetKeyspacePlacement sets the default placement template of the target keyspace,
+// which applies to all the key ranges of the keyspace.
+// @Tags     keyspaces
+// @Summary  Set keyspace placement template.
+// @Param    name  path  string                      true  "Keyspace Name"
+// @Param    body  body  keyspace.PlacementTemplate  true  "New placement template for the keyspace"
+// @Produce  json
+// @Success  200  {object}  keyspace.PlacementTemplate
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /keyspaces/{name}/placement [put]
+func SetKeyspacePlacement(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	manager := svr.GetKeyspaceManager()
+	template := &keyspace.PlacementTemplate{}
+	if err := c.BindJSON(template); err != nil {
+		middlewares.AbortWithError(c, http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause())
+		return
+	}
+	if err := manager.SetPlacementTemplate(c.Param("name"), template); err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, template)
+}
+
+// RemoveKeyspacePlacement removes the default placement template of the target keyspace.
+// @Tags     keyspaces
+// @Summary  Remove keyspace placement template.
+// @Param    name  path  string  true  "Keyspace Name"
+// @Produce  json
+// @Success  200  {string}  string  "Remove placement template successfully."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /keyspaces/{name}/placement [delete]
+func RemoveKeyspacePlacement(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	manager := svr.GetKeyspaceManager()
+	if err := manager.RemovePlacementTemplate(c.Param("name")); err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, "Remove placement template successfully.")
+}
+
 // KeyspaceMeta wraps keyspacepb.KeyspaceMeta to provide custom JSON marshal.
 type KeyspaceMeta struct {
 	*keyspacepb.KeyspaceMeta
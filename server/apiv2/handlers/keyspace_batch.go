@@ -0,0 +1,191 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/kvproto/pkg/keyspacepb"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/apiv2/middlewares"
+	"github.com/tikv/pd/server/keyspace"
+)
+
+// BatchCreateKeyspaceParams represents parameters needed to create keyspaces in batch.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type BatchCreateKeyspaceParams struct {
+	Keyspaces []*CreateKeyspaceParams `json:"keyspaces"`
+}
+
+// BatchUpdateConfigParams represents parameters needed to modify the configs of keyspaces in batch.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type BatchUpdateConfigParams struct {
+	Keyspaces []*BatchUpdateConfigItem `json:"keyspaces"`
+}
+
+// BatchUpdateConfigItem is the JSON merge patch of a keyspace's config in a batch.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type BatchUpdateConfigItem struct {
+	Name   string             `json:"name"`
+	Config map[string]*string `json:"config"`
+}
+
+// BatchUpdateStateParams represents parameters needed to modify the state of keyspaces in batch.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type BatchUpdateStateParams struct {
+	Names []string `json:"names"`
+	State string   `json:"state"`
+}
+
+// BatchResult is the result of a keyspace in a batch request.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type BatchResult struct {
+	Name     string        `json:"name"`
+	Keyspace *KeyspaceMeta `json:"keyspace,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// BatchResponse represents response given by the batch requests, whose results
+// are in the same order as the keyspaces in the request.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type BatchResponse struct {
+	Results []*BatchResult `json:"results"`
+}
+
+func newBatchResponse(results []*keyspace.BatchResult) *BatchResponse {
+	resp := &BatchResponse{Results: make([]*BatchResult, len(results))}
+	for i, result := range results {
+		resp.Results[i] = &BatchResult{Name: result.Name}
+		if result.Err != nil {
+			resp.Results[i].Error = result.Err.Error()
+		} else {
+			resp.Results[i].Keyspace = &KeyspaceMeta{result.Keyspace}
+		}
+	}
+	return resp
+}
+
+// CreateKeyspaces creates keyspaces in batch. A keyspace failing to be created
+// does not prevent others from being created.
+// @Tags     keyspaces
+// @Summary  Create keyspaces in batch.
+// @Param    body  body  BatchCreateKeyspaceParams  true  "Create keyspaces parameters"
+// @Produce  json
+// @Success  200  {object}  BatchResponse
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /keyspaces/batch [post]
+func CreateKeyspaces(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	manager := svr.GetKeyspaceManager()
+	params := &BatchCreateKeyspaceParams{}
+	if err := c.BindJSON(params); err != nil {
+		middlewares.AbortWithError(c, http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause())
+		return
+	}
+	now := time.Now().Unix()
+	requests := make([]*keyspace.CreateKeyspaceRequest, len(params.Keyspaces))
+	for i, createParams := range params.Keyspaces {
+		if createParams == nil {
+			middlewares.AbortWithMessage(c, http.StatusBadRequest, "keyspace parameters should not be null")
+			return
+		}
+		requests[i] = &keyspace.CreateKeyspaceRequest{
+			Name:          createParams.Name,
+			Config:        createParams.Config,
+			Now:           now,
+			PreSplit:      createParams.PreSplit,
+			ScatterPolicy: keyspace.ScatterPolicy(createParams.ScatterPolicy),
+		}
+	}
+	results, err := manager.CreateKeyspaces(requests)
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, newBatchResponse(results))
+}
+
+// UpdateKeyspacesConfig updates the configs of keyspaces in batch.
+// Each config uses JSON Merge Patch like UpdateKeyspaceConfig.
+// @Tags     keyspaces
+// @Summary  Update keyspaces config in batch.
+// @Param    body  body  BatchUpdateConfigParams  true  "Update keyspaces config parameters"
+// @Produce  json
+// @Success  200  {object}  BatchResponse
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /keyspaces/batch/config [patch]
+func UpdateKeyspacesConfig(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	manager := svr.GetKeyspaceManager()
+	params := &BatchUpdateConfigParams{}
+	if err := c.BindJSON(params); err != nil {
+		middlewares.AbortWithError(c, http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause())
+		return
+	}
+	requests := make([]*keyspace.UpdateConfigRequest, len(params.Keyspaces))
+	for i, item := range params.Keyspaces {
+		if item == nil {
+			middlewares.AbortWithMessage(c, http.StatusBadRequest, "keyspace parameters should not be null")
+			return
+		}
+		requests[i] = &keyspace.UpdateConfigRequest{
+			Name:      item.Name,
+			Mutations: getMutations(item.Config),
+		}
+	}
+	results, err := manager.UpdateKeyspacesConfig(requests)
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, newBatchResponse(results))
+}
+
+// UpdateKeyspacesState updates the state of keyspaces in batch.
+// @Tags     keyspaces
+// @Summary  Update keyspaces state in batch.
+// @Param    body  body  BatchUpdateStateParams  true  "Keyspace names and their new state"
+// @Produce  json
+// @Success  200  {object}  BatchResponse
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /keyspaces/batch/state [put]
+func UpdateKeyspacesState(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	manager := svr.GetKeyspaceManager()
+	params := &BatchUpdateStateParams{}
+	if err := c.BindJSON(params); err != nil {
+		middlewares.AbortWithError(c, http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause())
+		return
+	}
+	targetState, ok := keyspacepb.KeyspaceState_value[strings.ToUpper(params.State)]
+	if !ok {
+		middlewares.AbortWithError(c, http.StatusBadRequest, errors.Errorf("unknown target state: %s", params.State))
+		return
+	}
+	results, err := manager.UpdateKeyspacesState(params.Names, keyspacepb.KeyspaceState(targetState), time.Now().Unix())
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, newBatchResponse(results))
+}
@@ -0,0 +1,321 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pingcap/errors"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/apiv2/middlewares"
+)
+
+// KeyspaceGCSafePoint is the GC safepoint and the service safepoints of a keyspace.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type KeyspaceGCSafePoint struct {
+	KeyspaceID          uint32                       `json:"keyspace_id"`
+	GCSafePoint         uint64                       `json:"gc_safe_point"`
+	ServiceGCSafePoints []*endpoint.ServiceSafePoint `json:"service_gc_safe_points"`
+}
+
+// UpdateGCSafePointParams represents parameters needed to update the GC safepoint of a keyspace.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type UpdateGCSafePointParams struct {
+	SafePoint uint64 `json:"safe_point"`
+}
+
+// UpdateServiceGCSafePointParams represents parameters needed to update a service safepoint of a keyspace.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type UpdateServiceGCSafePointParams struct {
+	ServiceID string `json:"service_id"`
+	SafePoint uint64 `json:"safe_point"`
+	// TTL is the time to live of the service safepoint in seconds.
+	TTL int64 `json:"ttl"`
+}
+
+// SetGCBarrierParams represents parameters needed to set a GC barrier of a keyspace.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type SetGCBarrierParams struct {
+	SafePoint uint64 `json:"safe_point"`
+	// StartKey and EndKey are the hex encoded key range in the keyspace blocked
+	// by the barrier, the whole keyspace is blocked if they are empty.
+	StartKey string `json:"start_key,omitempty"`
+	EndKey   string `json:"end_key,omitempty"`
+	// TTL is the time to live of the barrier in seconds, it never expires if
+	// the TTL is not positive.
+	TTL int64 `json:"ttl,omitempty"`
+}
+
+// UpdateServiceGCSafePointResponse is the result of updating a service safepoint of a keyspace.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type UpdateServiceGCSafePointResponse struct {
+	Updated               bool                       `json:"updated"`
+	MinServiceGCSafePoint *endpoint.ServiceSafePoint `json:"min_service_gc_safe_point,omitempty"`
+}
+
+func loadKeyspaceGCSafePoint(svr *server.Server, id uint32) (*KeyspaceGCSafePoint, error) {
+	manager := svr.GetKeyspaceSafePointManager()
+	gcSafePoint, err := manager.LoadGCSafePoint(id)
+	if err != nil {
+		return nil, err
+	}
+	ssps, err := manager.LoadAllServiceGCSafePoints(id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &KeyspaceGCSafePoint{
+		KeyspaceID:          id,
+		GCSafePoint:         gcSafePoint,
+		ServiceGCSafePoints: ssps,
+	}, nil
+}
+
+// GetKeyspaceGCSafePoint returns the GC safepoint and the service safepoints of the target keyspace.
+// @Tags     keyspaces
+// @Summary  Get keyspace GC safepoints.
+// @Param    name  path  string  true  "Keyspace Name"
+// @Produce  json
+// @Success  200  {object}  KeyspaceGCSafePoint
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /keyspaces/{name}/gc/safepoint [get]
+func GetKeyspaceGCSafePoint(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	meta, err := svr.GetKeyspaceManager().LoadKeyspace(c.Param("name"))
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	safePoint, err := loadKeyspaceGCSafePoint(svr, meta.GetId())
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, safePoint)
+}
+
+// UpdateKeyspaceGCSafePoint updates the GC safepoint of the target keyspace. The GC
+// safepoint is not changed if the new one is not greater than the current one.
+// @Tags     keyspaces
+// @Summary  Update keyspace GC safepoint.
+// @Param    name  path  string                   true  "Keyspace Name"
+// @Param    body  body  UpdateGCSafePointParams  true  "New GC safepoint for the keyspace"
+// @Produce  json
+// @Success  200  {object}  KeyspaceGCSafePoint
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /keyspaces/{name}/gc/safepoint [put]
+func UpdateKeyspaceGCSafePoint(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	param := &UpdateGCSafePointParams{}
+	if err := c.BindJSON(param); err != nil {
+		middlewares.AbortWithError(c, http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause())
+		return
+	}
+	meta, err := svr.GetKeyspaceManager().LoadKeyspace(c.Param("name"))
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if _, err := svr.GetKeyspaceSafePointManager().UpdateGCSafePoint(meta.GetId(), param.SafePoint); err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	safePoint, err := loadKeyspaceGCSafePoint(svr, meta.GetId())
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, safePoint)
+}
+
+// UpdateKeyspaceServiceGCSafePoint updates a service safepoint of the target keyspace.
+// The service safepoint is not changed if it is less than the min service safepoint
+// or the GC safepoint of the keyspace, or the ttl is not positive.
+// @Tags     keyspaces
+// @Summary  Update keyspace service GC safepoint.
+// @Param    name  path  string                          true  "Keyspace Name"
+// @Param    body  body  UpdateServiceGCSafePointParams  true  "New service GC safepoint for the keyspace"
+// @Produce  json
+// @Success  200  {object}  UpdateServiceGCSafePointResponse
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /keyspaces/{name}/gc/service_safepoint [put]
+func UpdateKeyspaceServiceGCSafePoint(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	param := &UpdateServiceGCSafePointParams{}
+	if err := c.BindJSON(param); err != nil {
+		middlewares.AbortWithError(c, http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause())
+		return
+	}
+	if len(param.ServiceID) == 0 {
+		middlewares.AbortWithError(c, http.StatusBadRequest, errors.New("service id of service safepoint cannot be empty"))
+		return
+	}
+	meta, err := svr.GetKeyspaceManager().LoadKeyspace(c.Param("name"))
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	min, updated, err := svr.GetKeyspaceSafePointManager().UpdateServiceGCSafePoint(
+		meta.GetId(), param.ServiceID, param.SafePoint, param.TTL, time.Now())
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, &UpdateServiceGCSafePointResponse{
+		Updated:               updated,
+		MinServiceGCSafePoint: min,
+	})
+}
+
+// DeleteKeyspaceServiceGCSafePoint removes a service safepoint of the target keyspace.
+// @Tags     keyspaces
+// @Summary  Delete keyspace service GC safepoint.
+// @Param    name        path  string  true  "Keyspace Name"
+// @Param    service_id  path  string  true  "Service ID"
+// @Produce  json
+// @Success  200  {string}  string  "Delete service GC safepoint successfully."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /keyspaces/{name}/gc/service_safepoint/{service_id} [delete]
+func DeleteKeyspaceServiceGCSafePoint(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	meta, err := svr.GetKeyspaceManager().LoadKeyspace(c.Param("name"))
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if err := svr.GetKeyspaceSafePointManager().RemoveServiceGCSafePoint(meta.GetId(), c.Param("service_id")); err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, "Delete service GC safepoint successfully.")
+}
+
+// LoadKeyspaceGCBarriers returns the unexpired GC barriers of the target keyspace.
+// @Tags     keyspaces
+// @Summary  List keyspace GC barriers.
+// @Param    name  path  string  true  "Keyspace Name"
+// @Produce  json
+// @Success  200  {array}   endpoint.GCBarrier
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /keyspaces/{name}/gc/barriers [get]
+func LoadKeyspaceGCBarriers(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	meta, err := svr.GetKeyspaceManager().LoadKeyspace(c.Param("name"))
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	barriers, err := svr.GetKeyspaceSafePointManager().LoadAllGCBarriers(meta.GetId(), time.Now())
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, barriers)
+}
+
+// LoadKeyspaceGCBarrier returns a GC barrier of the target keyspace.
+// @Tags     keyspaces
+// @Summary  Get keyspace GC barrier.
+// @Param    name        path  string  true  "Keyspace Name"
+// @Param    barrier_id  path  string  true  "Barrier ID"
+// @Produce  json
+// @Success  200  {object}  endpoint.GCBarrier
+// @Failure  404  {object}  middlewares.ErrorResponse  "The barrier does not exist or has expired."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /keyspaces/{name}/gc/barriers/{barrier_id} [get]
+func LoadKeyspaceGCBarrier(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	meta, err := svr.GetKeyspaceManager().LoadKeyspace(c.Param("name"))
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	barrier, err := svr.GetKeyspaceSafePointManager().GetGCBarrier(meta.GetId(), c.Param("barrier_id"), time.Now())
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if barrier == nil {
+		middlewares.AbortWithMessage(c, http.StatusNotFound, "GC barrier does not exist or has expired.")
+		return
+	}
+	c.IndentedJSON(http.StatusOK, barrier)
+}
+
+// SetKeyspaceGCBarrier creates or updates a GC barrier of the target keyspace, which
+// blocks the GC of the keyspace from passing its safepoint, and never blocks the
+// other keyspaces.
+// @Tags     keyspaces
+// @Summary  Set keyspace GC barrier.
+// @Param    name        path  string              true  "Keyspace Name"
+// @Param    barrier_id  path  string              true  "Barrier ID"
+// @Param    body        body  SetGCBarrierParams  true  "The safepoint and the scope of the barrier"
+// @Produce  json
+// @Success  200  {object}  endpoint.GCBarrier
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /keyspaces/{name}/gc/barriers/{barrier_id} [put]
+func SetKeyspaceGCBarrier(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	param := &SetGCBarrierParams{}
+	if err := c.BindJSON(param); err != nil {
+		middlewares.AbortWithError(c, http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause())
+		return
+	}
+	meta, err := svr.GetKeyspaceManager().LoadKeyspace(c.Param("name"))
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	barrier, err := svr.GetKeyspaceSafePointManager().SetGCBarrier(meta.GetId(), &endpoint.GCBarrier{
+		BarrierID: c.Param("barrier_id"),
+		SafePoint: param.SafePoint,
+		StartKey:  param.StartKey,
+		EndKey:    param.EndKey,
+	}, param.TTL, time.Now())
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, barrier)
+}
+
+// DeleteKeyspaceGCBarrier removes a GC barrier of the target keyspace.
+// @Tags     keyspaces
+// @Summary  Delete keyspace GC barrier.
+// @Param    name        path  string  true  "Keyspace Name"
+// @Param    barrier_id  path  string  true  "Barrier ID"
+// @Produce  json
+// @Success  200  {string}  string  "Delete GC barrier successfully."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /keyspaces/{name}/gc/barriers/{barrier_id} [delete]
+func DeleteKeyspaceGCBarrier(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	meta, err := svr.GetKeyspaceManager().LoadKeyspace(c.Param("name"))
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if err := svr.GetKeyspaceSafePointManager().RemoveGCBarrier(meta.GetId(), c.Param("barrier_id")); err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, "Delete GC barrier successfully.")
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pingcap/kvproto/pkg/keyspacepb"
+	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/apiv2/middlewares"
+	"github.com/tikv/pd/server/keyspace"
+)
+
+// snapshotEvent is the event name of the existing keyspaces sent before the changes.
+const snapshotEvent = "snapshot"
+
+// watchKeyspaces streams the keyspace changes as server-sent events, whose
+// names are the change types and data are the changed keyspaces. The event ids
+// are the etcd revisions of the changes. A client resuming with the
+// Last-Event-ID header receives the changes since that revision again, so the
+// changes are delivered at least once. Otherwise, it receives all the existing
+// keyspaces as snapshot events first, and only the last snapshot event has an
+// id, so an interrupted snapshot is sent from scratch on resuming.
+func watchKeyspaces(c *gin.Context) {
+	svr := c.MustGet("server").(*server.Server)
+	watcher := svr.GetKeyspaceWatcher()
+	ctx := c.Request.Context()
+	var (
+		snapshot      []*keyspacepb.KeyspaceMeta
+		startRevision int64
+		err           error
+	)
+	if lastEventID := c.GetHeader("Last-Event-ID"); lastEventID != "" {
+		startRevision, err = strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || startRevision <= 0 {
+			middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid Last-Event-ID: "+lastEventID)
+			return
+		}
+	} else {
+		var revision int64
+		snapshot, revision, err = watcher.LoadAll(ctx)
+		if err != nil {
+			middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+		startRevision = revision + 1
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+	for i, meta := range snapshot {
+		var id int64
+		if i == len(snapshot)-1 {
+			id = startRevision - 1
+		}
+		if err = writeKeyspaceEvent(c.Writer, id, snapshotEvent, meta); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+	err = watcher.Watch(ctx, startRevision, func(changes []*keyspace.Change) error {
+		for _, change := range changes {
+			if err := writeKeyspaceEvent(c.Writer, change.Revision, string(change.Type), change.Keyspace); err != nil {
+				return err
+			}
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil {
+		// Tell the client why the stream ends, it may resume or reload all keyspaces.
+		fmt.Fprintf(c.Writer, "event: error\ndata: %q\n\n", err.Error())
+		c.Writer.Flush()
+	}
+}
+
+// writeKeyspaceEvent writes the keyspace as a server-sent event. The id is
+// omitted if it is zero.
+func writeKeyspaceEvent(w io.Writer, id int64, event string, meta *keyspacepb.KeyspaceMeta) error {
+	data, err := json.Marshal(&KeyspaceMeta{meta})
+	if err != nil {
+		return err
+	}
+	if id > 0 {
+		if _, err = fmt.Fprintf(w, "id: %d\n", id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
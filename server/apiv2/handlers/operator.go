@@ -0,0 +1,218 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pingcap/errors"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/apiv2/middlewares"
+	"github.com/tikv/pd/server/schedule/operator"
+)
+
+// RegisterOperator registers the operator related handlers to router paths.
+func RegisterOperator(r *gin.RouterGroup) {
+	router := r.Group("operators")
+	router.Use(middlewares.BootstrapChecker())
+	router.GET("", GetOperators)
+	router.POST("", CreateOperator)
+	router.GET("/:region_id", GetOperator)
+	router.DELETE("/:region_id", DeleteOperator)
+}
+
+// Operator is a running or finished operator of a region.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type Operator struct {
+	RegionID   uint64    `json:"region_id"`
+	Desc       string    `json:"desc"`
+	Kind       string    `json:"kind"`
+	Status     string    `json:"status"`
+	CreateTime time.Time `json:"create_time"`
+	Steps      []string  `json:"steps"`
+}
+
+// CreateOperatorParams represents parameters needed when creating an operator.
+// Which fields are required depends on the name of the operator.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type CreateOperatorParams struct {
+	// Name is one of "transfer-leader", "transfer-peer", "add-peer", "remove-peer",
+	// "merge-region", "split-region" and "scatter-region".
+	Name           string   `json:"name"`
+	RegionID       uint64   `json:"region_id"`
+	StoreID        uint64   `json:"store_id,omitempty"`
+	FromStoreID    uint64   `json:"from_store_id,omitempty"`
+	ToStoreID      uint64   `json:"to_store_id,omitempty"`
+	TargetRegionID uint64   `json:"target_region_id,omitempty"`
+	Policy         string   `json:"policy,omitempty"`
+	Keys           []string `json:"keys,omitempty"`
+	Group          string   `json:"group,omitempty"`
+}
+
+func newOperator(op *operator.Operator, status string) *Operator {
+	steps := make([]string, 0, op.Len())
+	for i := 0; i < op.Len(); i++ {
+		steps = append(steps, op.Step(i).String())
+	}
+	return &Operator{
+		RegionID:   op.RegionID(),
+		Desc:       op.Desc(),
+		Kind:       op.Kind().String(),
+		Status:     status,
+		CreateTime: op.GetCreateTime(),
+		Steps:      steps,
+	}
+}
+
+// GetOperators returns the running operators.
+// @Tags     operators
+// @Summary  Get the running operators.
+// @Param    kind  query  string  false  "The kind of the operators"  Enums(admin, leader, region)
+// @Produce  json
+// @Success  200  {array}   Operator
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /operators [get]
+func GetOperators(c *gin.Context) {
+	handler := c.MustGet("server").(*server.Server).GetHandler()
+	var (
+		ops []*operator.Operator
+		err error
+	)
+	switch kind := c.Query("kind"); kind {
+	case "":
+		ops, err = handler.GetOperators()
+	case "admin":
+		ops, err = handler.GetOperatorsOfKind(operator.OpAdmin)
+	case "leader":
+		ops, err = handler.GetOperatorsOfKind(operator.OpLeader)
+	case "region":
+		ops, err = handler.GetOperatorsOfKind(operator.OpRegion)
+	default:
+		middlewares.AbortWithMessage(c, http.StatusBadRequest, fmt.Sprintf("unknown operator kind %q", kind))
+		return
+	}
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	res := make([]*Operator, 0, len(ops))
+	for _, op := range ops {
+		res = append(res, newOperator(op, operator.OpStatusToString(op.Status())))
+	}
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// GetOperator returns the operator of the region.
+// @Tags     operators
+// @Summary  Get the running or the last finished operator of the region.
+// @Param    region_id  path  integer  true  "The id of the region"
+// @Produce  json
+// @Success  200  {object}  Operator
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  404  {object}  middlewares.ErrorResponse  "The operator does not exist."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /operators/{region_id} [get]
+func GetOperator(c *gin.Context) {
+	regionID, ok := parseIDParam(c, "region_id")
+	if !ok {
+		return
+	}
+	op, err := c.MustGet("server").(*server.Server).GetHandler().GetOperatorStatus(regionID)
+	if err != nil {
+		abortWithOperatorError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, newOperator(op.Operator, op.Status.String()))
+}
+
+// DeleteOperator cancels the operator of the region.
+// @Tags     operators
+// @Summary  Cancel the running operator of the region.
+// @Param    region_id  path  integer  true  "The id of the region"
+// @Success  200
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  404  {object}  middlewares.ErrorResponse  "The operator does not exist."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /operators/{region_id} [delete]
+func DeleteOperator(c *gin.Context) {
+	regionID, ok := parseIDParam(c, "region_id")
+	if !ok {
+		return
+	}
+	if err := c.MustGet("server").(*server.Server).GetHandler().RemoveOperator(regionID); err != nil {
+		abortWithOperatorError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// CreateOperator creates an operator.
+// @Tags     operators
+// @Summary  Create an operator.
+// @Param    body  body  CreateOperatorParams  true  "The name and the arguments of the operator"
+// @Success  200
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /operators [post]
+func CreateOperator(c *gin.Context) {
+	params := &CreateOperatorParams{}
+	if err := c.BindJSON(params); err != nil {
+		middlewares.AbortWithError(c, http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause())
+		return
+	}
+	if params.RegionID == 0 {
+		middlewares.AbortWithMessage(c, http.StatusBadRequest, "missing region id")
+		return
+	}
+	handler := c.MustGet("server").(*server.Server).GetHandler()
+	var err error
+	switch params.Name {
+	case "transfer-leader":
+		err = handler.AddTransferLeaderOperator(params.RegionID, params.StoreID)
+	case "transfer-peer":
+		err = handler.AddTransferPeerOperator(params.RegionID, params.FromStoreID, params.ToStoreID)
+	case "add-peer":
+		err = handler.AddAddPeerOperator(params.RegionID, params.StoreID)
+	case "remove-peer":
+		err = handler.AddRemovePeerOperator(params.RegionID, params.StoreID)
+	case "merge-region":
+		err = handler.AddMergeRegionOperator(params.RegionID, params.TargetRegionID)
+	case "split-region":
+		err = handler.AddSplitRegionOperator(params.RegionID, params.Policy, params.Keys)
+	case "scatter-region":
+		err = handler.AddScatterRegionOperator(params.RegionID, params.Group)
+	default:
+		middlewares.AbortWithMessage(c, http.StatusBadRequest, fmt.Sprintf("unknown operator %q", params.Name))
+		return
+	}
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func abortWithOperatorError(c *gin.Context, err error) {
+	if errors.ErrorEqual(err, server.ErrOperatorNotFound) {
+		middlewares.AbortWithError(c, http.StatusNotFound, err)
+		return
+	}
+	middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+}
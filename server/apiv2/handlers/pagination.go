@@ -0,0 +1,159 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tikv/pd/pkg/slice"
+	"github.com/tikv/pd/server/apiv2/middlewares"
+)
+
+// pageCursor is the position after which the next page starts. It is opaque to
+// the clients, who should only pass the cursor returned by the last page.
+type pageCursor struct {
+	// Sort is the field the list is sorted by, the cursor can't be used with
+	// another sort.
+	Sort string `json:"s,omitempty"`
+	// Key is the hex encoded key of the next page if the list is in key order.
+	Key string `json:"k,omitempty"`
+	sortKey
+}
+
+func (c *pageCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodePageCursor(s string) (*pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	cursor := &pageCursor{}
+	if err := json.Unmarshal(data, cursor); err != nil {
+		return nil, err
+	}
+	return cursor, nil
+}
+
+// sortKey is the position of an item in a sorted list. The items are ordered by
+// the value, and then by the id in the ascending order.
+type sortKey struct {
+	Value float64 `json:"v,omitempty"`
+	ID    uint64  `json:"id,omitempty"`
+}
+
+func (k sortKey) before(other sortKey, desc bool) bool {
+	if k.Value != other.Value {
+		return (k.Value < other.Value) != desc
+	}
+	return k.ID < other.ID
+}
+
+// listOptions is the order and the page of a list specified by the query.
+type listOptions struct {
+	sortBy string
+	desc   bool
+	// limit is the max number of the items in a page, 0 means no limit.
+	limit  int
+	cursor *pageCursor
+}
+
+// parseListOptions parses the `sort`, `order`, `limit` and `cursor` parameters in
+// the query, and aborts the request if any of them is invalid. The sort must be
+// one of the sortFields, or the defaultSort if it is unset. An empty defaultSort
+// means the natural order of the list.
+func parseListOptions(c *gin.Context, sortFields []string, defaultSort string, defaultLimit, maxLimit int) (*listOptions, bool) {
+	opts := &listOptions{sortBy: c.Query("sort"), limit: defaultLimit}
+	if len(opts.sortBy) == 0 {
+		opts.sortBy = defaultSort
+	}
+	if opts.sortBy != defaultSort && !slice.Contains(sortFields, opts.sortBy) {
+		middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid sort: "+opts.sortBy)
+		return nil, false
+	}
+	switch order := c.Query("order"); order {
+	case "", "asc":
+	case "desc":
+		opts.desc = true
+	default:
+		middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid order: "+order)
+		return nil, false
+	}
+	if value := c.Query("limit"); len(value) > 0 {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid limit: "+value)
+			return nil, false
+		}
+		opts.limit = limit
+	}
+	if maxLimit > 0 && (opts.limit == 0 || opts.limit > maxLimit) {
+		opts.limit = maxLimit
+	}
+	if value := c.Query("cursor"); len(value) > 0 {
+		cursor, err := decodePageCursor(value)
+		if err != nil || cursor.Sort != opts.sortBy {
+			middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid cursor: "+value)
+			return nil, false
+		}
+		opts.cursor = cursor
+	}
+	return opts, true
+}
+
+// sortedPage sorts the items by their sort keys, and returns the page after the
+// cursor with the cursor of the next page, which is empty for the last page.
+func sortedPage[T any](items []T, key func(T) sortKey, opts *listOptions) ([]T, string) {
+	keys := make([]sortKey, len(items))
+	for i, item := range items {
+		keys[i] = key(item)
+	}
+	sort.Sort(&sortedItems[T]{items: items, keys: keys, desc: opts.desc})
+	start := 0
+	if opts.cursor != nil {
+		start = sort.Search(len(keys), func(i int) bool { return opts.cursor.sortKey.before(keys[i], opts.desc) })
+	}
+	end := len(items)
+	if opts.limit > 0 && start+opts.limit < end {
+		end = start + opts.limit
+	}
+	if end == len(items) {
+		return items[start:end], ""
+	}
+	next := &pageCursor{Sort: opts.sortBy, sortKey: keys[end-1]}
+	return items[start:end], next.encode()
+}
+
+type sortedItems[T any] struct {
+	items []T
+	keys  []sortKey
+	desc  bool
+}
+
+func (s *sortedItems[T]) Len() int { return len(s.items) }
+
+func (s *sortedItems[T]) Less(i, j int) bool { return s.keys[i].before(s.keys[j], s.desc) }
+
+func (s *sortedItems[T]) Swap(i, j int) {
+	s.items[i], s.items[j] = s.items[j], s.items[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
@@ -0,0 +1,298 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tikv/pd/pkg/core"
+	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/apiv2/middlewares"
+	"github.com/tikv/pd/server/cluster"
+)
+
+const (
+	defaultRegionLimit = 16
+	maxRegionLimit     = 10240
+)
+
+// RegisterRegion registers the region related handlers to router paths.
+func RegisterRegion(r *gin.RouterGroup) {
+	router := r.Group("regions")
+	router.Use(middlewares.BootstrapChecker())
+	router.GET("", middlewares.ConditionalGet(), ScanRegions)
+	router.GET("/problems", middlewares.ConditionalGet(), GetRegionProblems)
+	router.GET("/:id", GetRegion)
+}
+
+// RegionPeer is a peer of a region.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type RegionPeer struct {
+	ID      uint64 `json:"id"`
+	StoreID uint64 `json:"store_id"`
+	// Role is one of "Voter", "Learner", "IncomingVoter" and "DemotingVoter".
+	Role      string `json:"role"`
+	IsWitness bool   `json:"is_witness,omitempty"`
+	IsDown    bool   `json:"is_down,omitempty"`
+	IsPending bool   `json:"is_pending,omitempty"`
+}
+
+// Region is a region with its statistics. The keys are hex encoded.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type Region struct {
+	ID       uint64        `json:"id"`
+	StartKey string        `json:"start_key"`
+	EndKey   string        `json:"end_key"`
+	ConfVer  uint64        `json:"conf_ver"`
+	Version  uint64        `json:"version"`
+	Peers    []*RegionPeer `json:"peers"`
+	// LeaderID is the id of the leader peer, zero if the leader is unknown.
+	LeaderID        uint64 `json:"leader_id"`
+	ApproximateSize int64  `json:"approximate_size"`
+	ApproximateKeys int64  `json:"approximate_keys"`
+	WrittenBytes    uint64 `json:"written_bytes"`
+	ReadBytes       uint64 `json:"read_bytes"`
+}
+
+func newRegion(region *core.RegionInfo) *Region {
+	res := &Region{
+		ID:              region.GetID(),
+		StartKey:        hex.EncodeToString(region.GetStartKey()),
+		EndKey:          hex.EncodeToString(region.GetEndKey()),
+		ConfVer:         region.GetRegionEpoch().GetConfVer(),
+		Version:         region.GetRegionEpoch().GetVersion(),
+		Peers:           make([]*RegionPeer, 0, len(region.GetPeers())),
+		LeaderID:        region.GetLeader().GetId(),
+		ApproximateSize: region.GetApproximateSize(),
+		ApproximateKeys: region.GetApproximateKeys(),
+		WrittenBytes:    region.GetBytesWritten(),
+		ReadBytes:       region.GetBytesRead(),
+	}
+	down := make(map[uint64]struct{}, len(region.GetDownPeers()))
+	for _, peer := range region.GetDownPeers() {
+		down[peer.GetPeer().GetId()] = struct{}{}
+	}
+	pending := make(map[uint64]struct{}, len(region.GetPendingPeers()))
+	for _, peer := range region.GetPendingPeers() {
+		pending[peer.GetId()] = struct{}{}
+	}
+	for _, peer := range region.GetPeers() {
+		_, isDown := down[peer.GetId()]
+		_, isPending := pending[peer.GetId()]
+		res.Peers = append(res.Peers, &RegionPeer{
+			ID:        peer.GetId(),
+			StoreID:   peer.GetStoreId(),
+			Role:      peer.GetRole().String(),
+			IsWitness: peer.GetIsWitness(),
+			IsDown:    isDown,
+			IsPending: isPending,
+		})
+	}
+	return res
+}
+
+// regionSortFields are the fields the regions can be sorted by, the regions are
+// in the key order if unsorted.
+var regionSortFields = []string{"size", "keys", "written_bytes", "read_bytes"}
+
+func regionSortKey(sortBy string) func(*core.RegionInfo) sortKey {
+	return func(region *core.RegionInfo) sortKey {
+		key := sortKey{ID: region.GetID()}
+		switch sortBy {
+		case "size":
+			key.Value = float64(region.GetApproximateSize())
+		case "keys":
+			key.Value = float64(region.GetApproximateKeys())
+		case "written_bytes":
+			key.Value = float64(region.GetBytesWritten())
+		case "read_bytes":
+			key.Value = float64(region.GetBytesRead())
+		}
+		return key
+	}
+}
+
+// regionFilter is the conditions the listed regions must satisfy.
+type regionFilter struct {
+	storeID          uint64
+	peerState        string
+	minSize, maxSize *float64
+	minKeys, maxKeys *float64
+}
+
+// parseRegionFilter parses the filter in the query, and aborts the request if
+// it is invalid.
+func parseRegionFilter(c *gin.Context) (*regionFilter, bool) {
+	filter := &regionFilter{peerState: c.Query("peer_state")}
+	if value := c.Query("store_id"); len(value) > 0 {
+		storeID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid store id: "+value)
+			return nil, false
+		}
+		filter.storeID = storeID
+	}
+	switch filter.peerState {
+	case "", "down", "pending":
+	default:
+		middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid peer state: "+filter.peerState)
+		return nil, false
+	}
+	for name, bound := range map[string]**float64{
+		"min_size": &filter.minSize,
+		"max_size": &filter.maxSize,
+		"min_keys": &filter.minKeys,
+		"max_keys": &filter.maxKeys,
+	} {
+		value, ok, err := parseFloatQuery(c, name)
+		if err != nil {
+			middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid "+strings.ReplaceAll(name, "_", " ")+": "+c.Query(name))
+			return nil, false
+		}
+		if ok {
+			*bound = &value
+		}
+	}
+	return filter, true
+}
+
+func (f *regionFilter) match(region *core.RegionInfo) bool {
+	if f.storeID != 0 && region.GetStorePeer(f.storeID) == nil {
+		return false
+	}
+	switch f.peerState {
+	case "down":
+		if len(region.GetDownPeers()) == 0 {
+			return false
+		}
+	case "pending":
+		if len(region.GetPendingPeers()) == 0 {
+			return false
+		}
+	}
+	return inRange(float64(region.GetApproximateSize()), f.minSize, f.maxSize) &&
+		inRange(float64(region.GetApproximateKeys()), f.minKeys, f.maxKeys)
+}
+
+// ScanRegionsResponse is a page of the regions in a key range.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type ScanRegionsResponse struct {
+	Regions []*Region `json:"regions"`
+	// NextCursor is the cursor of the next page, empty if it is the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+	// Count is the number of all the regions in the cluster.
+	Count int `json:"count"`
+}
+
+// ScanRegions returns the regions in the key range.
+// @Tags     regions
+// @Summary  Get the regions in the key range matching the filter, page by page.
+// @Param    start_key   query  string   false  "The hex encoded start key, empty for the first region"
+// @Param    end_key     query  string   false  "The hex encoded end key, empty for no limit"
+// @Param    store_id    query  integer  false  "Only the regions with a peer in the store"
+// @Param    peer_state  query  string   false  "Only the regions with a peer in the state"  Enums(down, pending)
+// @Param    min_size    query  number   false  "Only the regions whose approximate size in MiB is not less than it"
+// @Param    max_size    query  number   false  "Only the regions whose approximate size in MiB is not greater than it"
+// @Param    min_keys    query  number   false  "Only the regions whose approximate keys is not less than it"
+// @Param    max_keys    query  number   false  "Only the regions whose approximate keys is not greater than it"
+// @Param    sort        query  string   false  "The field to sort by, the key order if unset"  Enums(size, keys, written_bytes, read_bytes)
+// @Param    order       query  string   false  "The order of the sort, asc by default"  Enums(asc, desc)
+// @Param    limit       query  integer  false  "The max number of the regions, 16 by default and 10240 at most"
+// @Param    cursor      query  string   false  "The next_cursor of the last page"
+// @Produce  json
+// @Success  200  {object}  ScanRegionsResponse
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Router   /regions [get]
+func ScanRegions(c *gin.Context) {
+	rc := c.MustGet("cluster").(*cluster.RaftCluster)
+	startKey, err := hex.DecodeString(c.Query("start_key"))
+	if err != nil {
+		middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid start key: "+c.Query("start_key"))
+		return
+	}
+	endKey, err := hex.DecodeString(c.Query("end_key"))
+	if err != nil {
+		middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid end key: "+c.Query("end_key"))
+		return
+	}
+	filter, ok := parseRegionFilter(c)
+	if !ok {
+		return
+	}
+	opts, ok := parseListOptions(c, regionSortFields, "", defaultRegionLimit, maxRegionLimit)
+	if !ok {
+		return
+	}
+	// The pages in the key order start from the end key of the last page.
+	if opts.cursor != nil && len(opts.sortBy) == 0 {
+		if startKey, err = hex.DecodeString(opts.cursor.Key); err != nil {
+			middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid cursor: "+c.Query("cursor"))
+			return
+		}
+	}
+	res := &ScanRegionsResponse{Count: rc.GetRegionCount()}
+	var regions []*core.RegionInfo
+	rc.GetBasicCluster().ScanRangeWithIterator(startKey, func(region *core.RegionInfo) bool {
+		if len(endKey) > 0 && bytes.Compare(region.GetStartKey(), endKey) >= 0 {
+			return false
+		}
+		if filter.match(region) {
+			regions = append(regions, region)
+		}
+		// The sorted pages need all the regions in the range.
+		return len(opts.sortBy) > 0 || len(regions) < opts.limit
+	})
+	if len(opts.sortBy) > 0 {
+		regions, res.NextCursor = sortedPage(regions, regionSortKey(opts.sortBy), opts)
+	} else if len(regions) == opts.limit {
+		next := regions[len(regions)-1].GetEndKey()
+		if len(next) > 0 && (len(endKey) == 0 || bytes.Compare(next, endKey) < 0) {
+			res.NextCursor = (&pageCursor{Key: hex.EncodeToString(next)}).encode()
+		}
+	}
+	res.Regions = make([]*Region, 0, len(regions))
+	for _, region := range regions {
+		res.Regions = append(res.Regions, newRegion(region))
+	}
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// GetRegion returns the region.
+// @Tags     regions
+// @Summary  Get the region.
+// @Param    id  path  integer  true  "Region id"
+// @Produce  json
+// @Success  200  {object}  Region
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  404  {object}  middlewares.ErrorResponse  "The region does not exist."
+// @Router   /regions/{id} [get]
+func GetRegion(c *gin.Context) {
+	rc := c.MustGet("cluster").(*cluster.RaftCluster)
+	regionID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	region := rc.GetRegion(regionID)
+	if region == nil {
+		middlewares.AbortWithError(c, http.StatusNotFound, server.ErrRegionNotFound(regionID))
+		return
+	}
+	c.IndentedJSON(http.StatusOK, newRegion(region))
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tikv/pd/pkg/core"
+	"github.com/tikv/pd/server/apiv2/middlewares"
+	"github.com/tikv/pd/server/cluster"
+	"github.com/tikv/pd/server/statistics"
+)
+
+const (
+	defaultRegionProblemSample = 10
+	maxRegionProblemSample     = 1000
+)
+
+// regionProblemTypes are the problems in the report. The miss-peer and extra-peer
+// regions are the ones violating the replica count of the placement rules, and
+// the offline-peer regions are the ones with a peer in a removing store.
+var regionProblemTypes = []struct {
+	name    string
+	typ     statistics.RegionStatisticType
+	offline bool
+}{
+	{name: "down-peer", typ: statistics.DownPeer},
+	{name: "pending-peer", typ: statistics.PendingPeer},
+	{name: "offline-peer", typ: statistics.OfflinePeer, offline: true},
+	{name: "miss-peer", typ: statistics.MissPeer},
+	{name: "extra-peer", typ: statistics.ExtraPeer},
+	{name: "oversized-region", typ: statistics.OversizedRegion},
+	{name: "empty-region", typ: statistics.EmptyRegion},
+}
+
+// RegionProblem is the regions with a kind of problem.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type RegionProblem struct {
+	Count int `json:"count"`
+	// Samples are the regions with the smallest ids among them.
+	Samples []*Region `json:"samples"`
+}
+
+// RegionProblemsReport is the report of the problem regions in the cluster.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type RegionProblemsReport struct {
+	// Problems are keyed by the problem type, such as "down-peer" and "empty-region".
+	Problems map[string]*RegionProblem `json:"problems"`
+	// Total is the number of the distinct regions with any problem.
+	Total int `json:"total"`
+	// RegionCount is the number of all the regions in the cluster.
+	RegionCount int `json:"region_count"`
+}
+
+// GetRegionProblems returns the report of the problem regions.
+// @Tags     regions
+// @Summary  Get the counts and samples of the down-peer, pending-peer, offline-peer, miss-peer, extra-peer, oversized and empty regions.
+// @Param    sample  query  integer  false  "The max number of the samples of each problem, 10 by default and 1000 at most"
+// @Produce  json
+// @Success  200  {object}  RegionProblemsReport
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Router   /regions/problems [get]
+func GetRegionProblems(c *gin.Context) {
+	rc := c.MustGet("cluster").(*cluster.RaftCluster)
+	sample := defaultRegionProblemSample
+	if value := c.Query("sample"); len(value) > 0 {
+		var err error
+		sample, err = strconv.Atoi(value)
+		if err != nil || sample < 0 {
+			middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid sample: "+value)
+			return
+		}
+		if sample > maxRegionProblemSample {
+			sample = maxRegionProblemSample
+		}
+	}
+	res := &RegionProblemsReport{
+		Problems:    make(map[string]*RegionProblem, len(regionProblemTypes)),
+		RegionCount: rc.GetRegionCount(),
+	}
+	problemRegions := make(map[uint64]struct{})
+	for _, problem := range regionProblemTypes {
+		var regions []*core.RegionInfo
+		if problem.offline {
+			regions = rc.GetOfflineRegionStatsByType(problem.typ)
+		} else {
+			regions = rc.GetRegionStatsByType(problem.typ)
+		}
+		sort.Slice(regions, func(i, j int) bool { return regions[i].GetID() < regions[j].GetID() })
+		report := &RegionProblem{Count: len(regions), Samples: make([]*Region, 0, sample)}
+		for i, region := range regions {
+			problemRegions[region.GetID()] = struct{}{}
+			if i < sample {
+				report.Samples = append(report.Samples, newRegion(region))
+			}
+		}
+		res.Problems[problem.name] = report
+	}
+	res.Total = len(problemRegions)
+	c.IndentedJSON(http.StatusOK, res)
+}
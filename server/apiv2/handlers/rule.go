@@ -0,0 +1,158 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/apiv2/middlewares"
+	"github.com/tikv/pd/server/cluster"
+	"github.com/tikv/pd/server/schedule/placement"
+)
+
+// RegisterRule registers the placement rule related handlers to router paths.
+func RegisterRule(r *gin.RouterGroup) {
+	router := r.Group("rules")
+	router.Use(middlewares.BootstrapChecker(), placementRulesChecker())
+	router.GET("", middlewares.ConditionalGet(), GetRules)
+	router.GET("/:group/:id", GetRule)
+	router.PUT("/:group/:id", SetRule)
+	router.DELETE("/:group/:id", DeleteRule)
+}
+
+// placementRulesChecker aborts the request if the placement rules feature is disabled.
+func placementRulesChecker() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rc := c.MustGet("cluster").(*cluster.RaftCluster)
+		if !rc.GetOpts().IsPlacementRulesEnabled() {
+			middlewares.AbortWithMessage(c, http.StatusPreconditionFailed, "placement rules feature is disabled")
+			return
+		}
+		c.Next()
+	}
+}
+
+// GetRules returns the placement rules.
+// @Tags     rules
+// @Summary  Get all placement rules, or the rules of the group.
+// @Param    group  query  string  false  "The group of the rules"
+// @Produce  json
+// @Success  200  {array}   placement.Rule
+// @Failure  412  {object}  middlewares.ErrorResponse  "Placement rules feature is disabled."
+// @Router   /rules [get]
+func GetRules(c *gin.Context) {
+	manager := c.MustGet("cluster").(*cluster.RaftCluster).GetRuleManager()
+	var rules []*placement.Rule
+	if group := c.Query("group"); len(group) > 0 {
+		rules = manager.GetRulesByGroup(group)
+	} else {
+		rules = manager.GetAllRules()
+	}
+	if rules == nil {
+		rules = []*placement.Rule{}
+	}
+	c.IndentedJSON(http.StatusOK, rules)
+}
+
+// GetRule returns the placement rule.
+// @Tags     rules
+// @Summary  Get the placement rule.
+// @Param    group  path  string  true  "The group of the rule"
+// @Param    id     path  string  true  "The id of the rule"
+// @Produce  json
+// @Success  200  {object}  placement.Rule
+// @Failure  404  {object}  middlewares.ErrorResponse  "The rule does not exist."
+// @Failure  412  {object}  middlewares.ErrorResponse  "Placement rules feature is disabled."
+// @Router   /rules/{group}/{id} [get]
+func GetRule(c *gin.Context) {
+	rule := c.MustGet("cluster").(*cluster.RaftCluster).GetRuleManager().GetRule(c.Param("group"), c.Param("id"))
+	if rule == nil {
+		middlewares.AbortWithMessage(c, http.StatusNotFound, "rule not found")
+		return
+	}
+	c.IndentedJSON(http.StatusOK, rule)
+}
+
+// SetRule creates or updates the placement rule.
+// @Tags     rules
+// @Summary  Create or update the placement rule, the group and the id in the body are overridden by the path.
+// @Param    group  path  string          true  "The group of the rule"
+// @Param    id     path  string          true  "The id of the rule"
+// @Param    body   body  placement.Rule  true  "The placement rule"
+// @Produce  json
+// @Success  200  {object}  placement.Rule
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  412  {object}  middlewares.ErrorResponse  "Placement rules feature is disabled."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /rules/{group}/{id} [put]
+func SetRule(c *gin.Context) {
+	rule := &placement.Rule{}
+	if err := c.BindJSON(rule); err != nil {
+		middlewares.AbortWithError(c, http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause())
+		return
+	}
+	rule.GroupID, rule.ID = c.Param("group"), c.Param("id")
+	svr := c.MustGet("server").(*server.Server)
+	rc := c.MustGet("cluster").(*cluster.RaftCluster)
+	oldRule := rc.GetRuleManager().GetRule(rule.GroupID, rule.ID)
+	// Keep the replication config in sync with the default rule.
+	if rule.GroupID == "pd" && rule.ID == "default" {
+		cfg := svr.GetReplicationConfig().Clone()
+		cfg.MaxReplicas = uint64(rule.Count)
+		if err := svr.SetReplicationConfig(*cfg); err != nil {
+			middlewares.AbortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if err := rc.GetRuleManager().SetKeyType(svr.GetConfig().PDServerCfg.KeyType).SetRule(rule); err != nil {
+		if errs.ErrRuleContent.Equal(err) || errs.ErrHexDecodingString.Equal(err) {
+			middlewares.AbortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	rc.AddSuspectKeyRange(rule.StartKey, rule.EndKey)
+	if oldRule != nil {
+		rc.AddSuspectKeyRange(oldRule.StartKey, oldRule.EndKey)
+	}
+	c.IndentedJSON(http.StatusOK, rule)
+}
+
+// DeleteRule removes the placement rule.
+// @Tags     rules
+// @Summary  Remove the placement rule.
+// @Param    group  path  string  true  "The group of the rule"
+// @Param    id     path  string  true  "The id of the rule"
+// @Success  200
+// @Failure  412  {object}  middlewares.ErrorResponse  "Placement rules feature is disabled."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /rules/{group}/{id} [delete]
+func DeleteRule(c *gin.Context) {
+	rc := c.MustGet("cluster").(*cluster.RaftCluster)
+	group, id := c.Param("group"), c.Param("id")
+	rule := rc.GetRuleManager().GetRule(group, id)
+	if err := rc.GetRuleManager().DeleteRule(group, id); err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if rule != nil {
+		rc.AddSuspectKeyRange(rule.StartKey, rule.EndKey)
+	}
+	c.Status(http.StatusOK)
+}
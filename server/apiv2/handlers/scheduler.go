@@ -0,0 +1,199 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pingcap/errors"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/apiv2/middlewares"
+)
+
+// RegisterScheduler registers the scheduler related handlers to router paths.
+func RegisterScheduler(r *gin.RouterGroup) {
+	router := r.Group("schedulers")
+	router.Use(middlewares.BootstrapChecker())
+	router.GET("", GetSchedulers)
+	router.POST("", CreateScheduler)
+	router.DELETE("/:name", DeleteScheduler)
+	router.PUT("/:name/pause", PauseScheduler)
+}
+
+// Scheduler is a running scheduler.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type Scheduler struct {
+	Name     string `json:"name"`
+	Paused   bool   `json:"paused"`
+	Disabled bool   `json:"disabled"`
+}
+
+// CreateSchedulerParams represents parameters needed when creating a scheduler.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type CreateSchedulerParams struct {
+	// Type is the type of the scheduler, e.g. "balance-leader" or "evict-leader".
+	Type string `json:"type"`
+	// Args are the arguments of the scheduler, e.g. the store id of "evict-leader".
+	Args []string `json:"args,omitempty"`
+}
+
+// PauseSchedulerParams represents parameters needed when pausing a scheduler.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type PauseSchedulerParams struct {
+	// Delay is the seconds the scheduler is paused, 0 resumes it.
+	Delay int64 `json:"delay"`
+}
+
+// GetSchedulers returns the schedulers.
+// @Tags     schedulers
+// @Summary  Get the running schedulers.
+// @Produce  json
+// @Success  200  {array}   Scheduler
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /schedulers [get]
+func GetSchedulers(c *gin.Context) {
+	res, err := loadSchedulers(c.MustGet("server").(*server.Server).GetHandler())
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// CreateScheduler creates a scheduler.
+// @Tags     schedulers
+// @Summary  Create a scheduler, and return the running schedulers.
+// @Param    body  body  CreateSchedulerParams  true  "The type and the arguments of the scheduler"
+// @Produce  json
+// @Success  200  {array}   Scheduler
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /schedulers [post]
+func CreateScheduler(c *gin.Context) {
+	params := &CreateSchedulerParams{}
+	if err := c.BindJSON(params); err != nil {
+		middlewares.AbortWithError(c, http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause())
+		return
+	}
+	if len(params.Type) == 0 {
+		middlewares.AbortWithMessage(c, http.StatusBadRequest, "type of scheduler should not be empty")
+		return
+	}
+	handler := c.MustGet("server").(*server.Server).GetHandler()
+	if err := handler.AddScheduler(params.Type, params.Args...); err != nil {
+		if errors.ErrorEqual(err, errs.ErrSchedulerExisted.FastGenByArgs()) {
+			middlewares.AbortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	res, err := loadSchedulers(handler)
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// DeleteScheduler removes the scheduler.
+// @Tags     schedulers
+// @Summary  Remove the scheduler.
+// @Param    name  path  string  true  "The name of the scheduler"
+// @Success  200
+// @Failure  404  {object}  middlewares.ErrorResponse  "The scheduler does not exist."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /schedulers/{name} [delete]
+func DeleteScheduler(c *gin.Context) {
+	handler := c.MustGet("server").(*server.Server).GetHandler()
+	if err := handler.RemoveScheduler(c.Param("name")); err != nil {
+		abortWithSchedulerError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// PauseScheduler pauses or resumes the scheduler.
+// @Tags     schedulers
+// @Summary  Pause the scheduler for the delay seconds, or resume it if the delay is 0.
+// @Param    name  path  string                true  "The name of the scheduler"
+// @Param    body  body  PauseSchedulerParams  true  "The seconds to pause"
+// @Produce  json
+// @Success  200  {object}  Scheduler
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  404  {object}  middlewares.ErrorResponse  "The scheduler does not exist."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /schedulers/{name}/pause [put]
+func PauseScheduler(c *gin.Context) {
+	params := &PauseSchedulerParams{}
+	if err := c.BindJSON(params); err != nil {
+		middlewares.AbortWithError(c, http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause())
+		return
+	}
+	if params.Delay < 0 {
+		middlewares.AbortWithMessage(c, http.StatusBadRequest, "delay should not be negative")
+		return
+	}
+	name := c.Param("name")
+	handler := c.MustGet("server").(*server.Server).GetHandler()
+	if err := handler.PauseOrResumeScheduler(name, params.Delay); err != nil {
+		abortWithSchedulerError(c, err)
+		return
+	}
+	res, err := loadScheduler(handler, name)
+	if err != nil {
+		abortWithSchedulerError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+func loadScheduler(handler *server.Handler, name string) (*Scheduler, error) {
+	paused, err := handler.IsSchedulerPaused(name)
+	if err != nil {
+		return nil, err
+	}
+	disabled, err := handler.IsSchedulerDisabled(name)
+	if err != nil {
+		return nil, err
+	}
+	return &Scheduler{Name: name, Paused: paused, Disabled: disabled}, nil
+}
+
+func loadSchedulers(handler *server.Handler) ([]*Scheduler, error) {
+	names, err := handler.GetSchedulers()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*Scheduler, 0, len(names))
+	for _, name := range names {
+		scheduler, err := loadScheduler(handler, name)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, scheduler)
+	}
+	return res, nil
+}
+
+func abortWithSchedulerError(c *gin.Context, err error) {
+	if errors.ErrorEqual(err, errs.ErrSchedulerNotFound.FastGenByArgs()) {
+		middlewares.AbortWithError(c, http.StatusNotFound, err)
+		return
+	}
+	middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+}
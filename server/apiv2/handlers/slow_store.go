@@ -0,0 +1,140 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/tikv/pd/pkg/core"
+	"github.com/tikv/pd/server/apiv2/middlewares"
+	"github.com/tikv/pd/server/cluster"
+	"github.com/tikv/pd/server/schedulers"
+)
+
+// The detection states of the slow stores.
+const (
+	slowStoreStateNormal = "normal"
+	// slowStoreStateSlowing means the latency of the store is rising while its
+	// throughput is falling, it may be evicted by the slow-trend detector.
+	slowStoreStateSlowing = "slowing"
+	// slowStoreStateSlow means the slow score of the store reaches 80, it may
+	// be evicted by the slow-score detector once the score reaches 100.
+	slowStoreStateSlow    = "slow"
+	slowStoreStateEvicted = "evicted"
+
+	// slowTrendEpsilon is the same as the one used by the slow-trend detector.
+	slowTrendEpsilon = 1e-9
+)
+
+// slowStoreDetectors are the schedulers of the slow store detectors.
+var slowStoreDetectors = map[string]string{
+	cluster.SlowStoreDetectorScore: schedulers.EvictSlowStoreName,
+	cluster.SlowStoreDetectorTrend: schedulers.EvictSlowTrendName,
+}
+
+// SlowStoreStatus is the slow store detection status of a store.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type SlowStoreStatus struct {
+	StoreID uint64 `json:"store_id"`
+	Address string `json:"address"`
+	// SlowScore is reported by TiKV, which ranges from 1 to 100.
+	SlowScore uint64 `json:"slow_score"`
+	// SlowTrend is the latency and throughput trend reported by TiKV.
+	SlowTrend *pdpb.SlowTrend `json:"slow_trend,omitempty"`
+	// State is one of "normal", "slowing", "slow" and "evicted".
+	State string `json:"state"`
+	// EvictedBy are the detectors evicting the leaders from the store.
+	EvictedBy []string `json:"evicted_by,omitempty"`
+}
+
+// SlowStoresResponse is the slow store detection results.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type SlowStoresResponse struct {
+	// Detectors are the enabled detectors, which are "slow-score" and "slow-trend".
+	Detectors []string           `json:"detectors"`
+	Stores    []*SlowStoreStatus `json:"stores"`
+	// Evictions are the recent evictions since the current PD leader starts
+	// to serve, from the oldest to the latest.
+	Evictions []*cluster.SlowStoreEviction `json:"evictions"`
+}
+
+func newSlowStoreStatus(store *core.StoreInfo) *SlowStoreStatus {
+	res := &SlowStoreStatus{
+		StoreID:   store.GetID(),
+		Address:   store.GetAddress(),
+		SlowScore: store.GetSlowScore(),
+		SlowTrend: store.GetSlowTrend(),
+		State:     slowStoreStateNormal,
+	}
+	if store.EvictedAsSlowStore() {
+		res.EvictedBy = append(res.EvictedBy, cluster.SlowStoreDetectorScore)
+	}
+	if store.IsEvictedAsSlowTrend() {
+		res.EvictedBy = append(res.EvictedBy, cluster.SlowStoreDetectorTrend)
+	}
+	switch {
+	case len(res.EvictedBy) > 0:
+		res.State = slowStoreStateEvicted
+	case store.IsSlow():
+		res.State = slowStoreStateSlow
+	case res.SlowTrend != nil && res.SlowTrend.CauseRate > slowTrendEpsilon && res.SlowTrend.ResultRate < -slowTrendEpsilon:
+		res.State = slowStoreStateSlowing
+	}
+	return res
+}
+
+// GetSlowStores returns the slow store detection results.
+// @Tags     stores
+// @Summary  Get the slow scores, the slow trends and the detection states of the stores not removed, and the recent slow store evictions.
+// @Param    store_id  query  integer  false  "Only the results of the store"
+// @Produce  json
+// @Success  200  {object}  SlowStoresResponse
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Router   /stores/slow [get]
+func GetSlowStores(c *gin.Context) {
+	rc := c.MustGet("cluster").(*cluster.RaftCluster)
+	var storeID uint64
+	if value := c.Query("store_id"); len(value) > 0 {
+		var err error
+		if storeID, err = strconv.ParseUint(value, 10, 64); err != nil {
+			middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid store id: "+value)
+			return
+		}
+	}
+	res := &SlowStoresResponse{
+		Detectors: make([]string, 0, len(slowStoreDetectors)),
+		Stores:    make([]*SlowStoreStatus, 0),
+		Evictions: rc.GetSlowStoreEvictions(storeID),
+	}
+	for detector, scheduler := range slowStoreDetectors {
+		// It fails if the scheduler is not added.
+		if disabled, err := rc.IsSchedulerDisabled(scheduler); err == nil && !disabled {
+			res.Detectors = append(res.Detectors, detector)
+		}
+	}
+	sort.Strings(res.Detectors)
+	for _, store := range rc.GetStores() {
+		if store.IsRemoved() || (storeID != 0 && store.GetID() != storeID) {
+			continue
+		}
+		res.Stores = append(res.Stores, newSlowStoreStatus(store))
+	}
+	sort.Slice(res.Stores, func(i, j int) bool { return res.Stores[i].StoreID < res.Stores[j].StoreID })
+	c.IndentedJSON(http.StatusOK, res)
+}
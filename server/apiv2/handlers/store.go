@@ -0,0 +1,367 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/tikv/pd/pkg/core"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/server/apiv2/middlewares"
+	"github.com/tikv/pd/server/cluster"
+	"github.com/tikv/pd/server/config"
+)
+
+// RegisterStore registers the store related handlers to router paths.
+func RegisterStore(r *gin.RouterGroup) {
+	router := r.Group("stores")
+	router.Use(middlewares.BootstrapChecker())
+	router.GET("", GetStores)
+	router.POST("/batch", UpdateStores)
+	router.GET("/slow", GetSlowStores)
+	router.GET("/:id", GetStore)
+	router.DELETE("/:id", DeleteStore)
+	router.PATCH("/:id/labels", UpdateStoreLabels)
+}
+
+// Store is a TiKV or TiFlash store with its status.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type Store struct {
+	ID            uint64            `json:"id"`
+	Address       string            `json:"address"`
+	StatusAddress string            `json:"status_address,omitempty"`
+	Version       string            `json:"version,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	// State is one of "Up", "Offline" and "Tombstone".
+	State string `json:"state"`
+	// NodeState is one of "Preparing", "Serving", "Removing" and "Removed".
+	NodeState    string  `json:"node_state"`
+	Disconnected bool    `json:"disconnected,omitempty"`
+	Capacity     uint64  `json:"capacity"`
+	Available    uint64  `json:"available"`
+	UsedSize     uint64  `json:"used_size"`
+	LeaderCount  int     `json:"leader_count"`
+	LeaderWeight float64 `json:"leader_weight"`
+	LeaderScore  float64 `json:"leader_score"`
+	RegionCount  int     `json:"region_count"`
+	RegionWeight float64 `json:"region_weight"`
+	RegionScore  float64 `json:"region_score"`
+	SlowScore    uint64  `json:"slow_score"`
+	// LastHeartbeat is the unix timestamp in seconds of the last heartbeat.
+	LastHeartbeat int64 `json:"last_heartbeat"`
+}
+
+func newStore(store *core.StoreInfo, opt *config.PersistOptions) *Store {
+	res := &Store{
+		ID:            store.GetID(),
+		Address:       store.GetAddress(),
+		StatusAddress: store.GetStatusAddress(),
+		Version:       store.GetVersion(),
+		State:         store.GetState().String(),
+		NodeState:     store.GetNodeState().String(),
+		Disconnected:  store.IsDisconnected(),
+		Capacity:      store.GetCapacity(),
+		Available:     store.GetAvailable(),
+		UsedSize:      store.GetUsedSize(),
+		LeaderCount:   store.GetLeaderCount(),
+		LeaderWeight:  store.GetLeaderWeight(),
+		LeaderScore:   store.LeaderScore(opt.GetLeaderSchedulePolicy(), 0),
+		RegionCount:   store.GetRegionCount(),
+		RegionWeight:  store.GetRegionWeight(),
+		RegionScore:   store.RegionScore(opt.GetRegionScoreFormulaVersion(), opt.GetHighSpaceRatio(), opt.GetLowSpaceRatio(), 0),
+		SlowScore:     store.GetSlowScore(),
+		LastHeartbeat: store.GetLastHeartbeatTS().Unix(),
+	}
+	if labels := store.GetLabels(); len(labels) > 0 {
+		res.Labels = make(map[string]string, len(labels))
+		for _, label := range labels {
+			res.Labels[label.GetKey()] = label.GetValue()
+		}
+	}
+	return res
+}
+
+// storeSortFields are the fields the stores can be sorted by.
+var storeSortFields = []string{"id", "capacity", "available", "used_size", "leader_count", "leader_score",
+	"region_count", "region_score", "slow_score", "last_heartbeat"}
+
+func storeSortKey(sortBy string) func(*Store) sortKey {
+	return func(s *Store) sortKey {
+		key := sortKey{ID: s.ID}
+		switch sortBy {
+		case "capacity":
+			key.Value = float64(s.Capacity)
+		case "available":
+			key.Value = float64(s.Available)
+		case "used_size":
+			key.Value = float64(s.UsedSize)
+		case "leader_count":
+			key.Value = float64(s.LeaderCount)
+		case "leader_score":
+			key.Value = s.LeaderScore
+		case "region_count":
+			key.Value = float64(s.RegionCount)
+		case "region_score":
+			key.Value = s.RegionScore
+		case "slow_score":
+			key.Value = float64(s.SlowScore)
+		case "last_heartbeat":
+			key.Value = float64(s.LastHeartbeat)
+		}
+		return key
+	}
+}
+
+// storeFilter is the conditions the listed stores must satisfy.
+type storeFilter struct {
+	state                          string
+	labels                         map[string]string
+	minLeaderScore, maxLeaderScore *float64
+	minRegionScore, maxRegionScore *float64
+}
+
+// parseStoreFilter parses the filter in the query, and aborts the request if
+// it is invalid.
+func parseStoreFilter(c *gin.Context) (*storeFilter, bool) {
+	filter := &storeFilter{state: c.Query("state"), labels: make(map[string]string)}
+	for _, label := range c.QueryArray("label") {
+		kv := strings.SplitN(label, ":", 2)
+		if len(kv) != 2 || len(kv[0]) == 0 {
+			middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid label: "+label)
+			return nil, false
+		}
+		filter.labels[kv[0]] = kv[1]
+	}
+	for name, bound := range map[string]**float64{
+		"min_leader_score": &filter.minLeaderScore,
+		"max_leader_score": &filter.maxLeaderScore,
+		"min_region_score": &filter.minRegionScore,
+		"max_region_score": &filter.maxRegionScore,
+	} {
+		value, ok, err := parseFloatQuery(c, name)
+		if err != nil {
+			middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid "+strings.ReplaceAll(name, "_", " ")+": "+c.Query(name))
+			return nil, false
+		}
+		if ok {
+			*bound = &value
+		}
+	}
+	return filter, true
+}
+
+func (f *storeFilter) match(s *Store) bool {
+	if len(f.state) > 0 && !strings.EqualFold(s.State, f.state) {
+		return false
+	}
+	for k, v := range f.labels {
+		if value, ok := s.Labels[k]; !ok || value != v {
+			return false
+		}
+	}
+	return inRange(s.LeaderScore, f.minLeaderScore, f.maxLeaderScore) &&
+		inRange(s.RegionScore, f.minRegionScore, f.maxRegionScore)
+}
+
+// StoresResponse is a page of the stores.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type StoresResponse struct {
+	Stores []*Store `json:"stores"`
+	// NextCursor is the cursor of the next page, empty if it is the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+	// Count is the number of the stores matching the filter.
+	Count int `json:"count"`
+}
+
+// GetStores returns the stores.
+// @Tags     stores
+// @Summary  Get the stores matching the filter, page by page.
+// @Param    state             query  string    false  "Only the stores in the state"  Enums(Up, Offline, Tombstone)
+// @Param    label             query  []string  false  "Only the stores with the label, in the form of key:value"  collectionFormat(multi)
+// @Param    min_leader_score  query  number    false  "Only the stores whose leader score is not less than it"
+// @Param    max_leader_score  query  number    false  "Only the stores whose leader score is not greater than it"
+// @Param    min_region_score  query  number    false  "Only the stores whose region score is not less than it"
+// @Param    max_region_score  query  number    false  "Only the stores whose region score is not greater than it"
+// @Param    sort              query  string    false  "The field to sort by, id by default"  Enums(id, capacity, available, used_size, leader_count, leader_score, region_count, region_score, slow_score, last_heartbeat)
+// @Param    order             query  string    false  "The order of the sort, asc by default"  Enums(asc, desc)
+// @Param    limit             query  integer   false  "The max number of the stores, no limit by default"
+// @Param    cursor            query  string    false  "The next_cursor of the last page"
+// @Produce  json
+// @Success  200  {object}  StoresResponse
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Router   /stores [get]
+func GetStores(c *gin.Context) {
+	rc := c.MustGet("cluster").(*cluster.RaftCluster)
+	filter, ok := parseStoreFilter(c)
+	if !ok {
+		return
+	}
+	opts, ok := parseListOptions(c, storeSortFields, "id", 0, 0)
+	if !ok {
+		return
+	}
+	stores := rc.GetStores()
+	matched := make([]*Store, 0, len(stores))
+	for _, store := range stores {
+		if s := newStore(store, rc.GetOpts()); filter.match(s) {
+			matched = append(matched, s)
+		}
+	}
+	res := &StoresResponse{Count: len(matched)}
+	res.Stores, res.NextCursor = sortedPage(matched, storeSortKey(opts.sortBy), opts)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// GetStore returns the store.
+// @Tags     stores
+// @Summary  Get the store.
+// @Param    id  path  integer  true  "Store id"
+// @Produce  json
+// @Success  200  {object}  Store
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  404  {object}  middlewares.ErrorResponse  "The store does not exist."
+// @Router   /stores/{id} [get]
+func GetStore(c *gin.Context) {
+	rc := c.MustGet("cluster").(*cluster.RaftCluster)
+	storeID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	store := rc.GetStore(storeID)
+	if store == nil {
+		abortWithStoreError(c, storeID, errs.ErrStoreNotFound.FastGenByArgs(storeID))
+		return
+	}
+	c.IndentedJSON(http.StatusOK, newStore(store, rc.GetOpts()))
+}
+
+// DeleteStore marks the store as offline.
+// @Tags     stores
+// @Summary  Mark the store as offline, whose regions are moved to the other stores before it becomes tombstone.
+// @Param    id     path   integer  true   "Store id"
+// @Param    force  query  bool      false  "Whether the store is physically destroyed"
+// @Produce  json
+// @Success  200  {object}  Store
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  404  {object}  middlewares.ErrorResponse  "The store does not exist."
+// @Failure  410  {object}  middlewares.ErrorResponse  "The store has been removed."
+// @Router   /stores/{id} [delete]
+func DeleteStore(c *gin.Context) {
+	rc := c.MustGet("cluster").(*cluster.RaftCluster)
+	storeID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	force, err := parseBoolQuery(c, "force")
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := rc.RemoveStore(storeID, force); err != nil {
+		abortWithStoreError(c, storeID, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, newStore(rc.GetStore(storeID), rc.GetOpts()))
+}
+
+// UpdateStoreLabels updates the labels of the store.
+// @Tags     stores
+// @Summary  Update the labels of the store, the labels not in the body are kept.
+// @Param    id    path  integer            true  "Store id"
+// @Param    body  body  map[string]string  true  "The labels"
+// @Produce  json
+// @Success  200  {object}  Store
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  404  {object}  middlewares.ErrorResponse  "The store does not exist."
+// @Router   /stores/{id}/labels [patch]
+func UpdateStoreLabels(c *gin.Context) {
+	rc := c.MustGet("cluster").(*cluster.RaftCluster)
+	storeID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	input := make(map[string]string)
+	if err := c.BindJSON(&input); err != nil {
+		middlewares.AbortWithError(c, http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause())
+		return
+	}
+	labels := make([]*metapb.StoreLabel, 0, len(input))
+	for k, v := range input {
+		labels = append(labels, &metapb.StoreLabel{Key: k, Value: v})
+	}
+	if err := config.ValidateLabels(labels); err != nil {
+		middlewares.AbortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := rc.UpdateStoreLabels(storeID, labels, false); err != nil {
+		abortWithStoreError(c, storeID, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, newStore(rc.GetStore(storeID), rc.GetOpts()))
+}
+
+func abortWithStoreError(c *gin.Context, storeID uint64, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.ErrorEqual(err, errs.ErrStoreNotFound.FastGenByArgs(storeID)):
+		status = http.StatusNotFound
+	case errors.ErrorEqual(err, errs.ErrStoreRemoved.FastGenByArgs(storeID)):
+		status = http.StatusGone
+	}
+	middlewares.AbortWithError(c, status, errs.WithDetail(err, "store-id", storeID))
+}
+
+// parseIDParam parses the id in the path, and aborts the request if it is invalid.
+func parseIDParam(c *gin.Context, name string) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil {
+		middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid "+strings.ReplaceAll(name, "_", " ")+": "+c.Param(name))
+		return 0, false
+	}
+	return id, true
+}
+
+// parseFloatQuery parses the float query parameter, ok is false if it is unset.
+func parseFloatQuery(c *gin.Context, name string) (value float64, ok bool, err error) {
+	s, ok := c.GetQuery(name)
+	if !ok || len(s) == 0 {
+		return 0, false, nil
+	}
+	value, err = strconv.ParseFloat(s, 64)
+	return value, err == nil, err
+}
+
+// inRange returns whether the value is in the range, whose bounds are unlimited if nil.
+func inRange(value float64, min, max *float64) bool {
+	return (min == nil || value >= *min) && (max == nil || value <= *max)
+}
+
+// parseBoolQuery parses the bool query parameter, which is false if unset.
+func parseBoolQuery(c *gin.Context, name string) (bool, error) {
+	value, ok := c.GetQuery(name)
+	if !ok || len(value) == 0 {
+		return false, nil
+	}
+	res, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errors.Errorf("invalid %s: %s", name, value)
+	}
+	return res, nil
+}
@@ -0,0 +1,231 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/tikv/pd/pkg/core"
+	"github.com/tikv/pd/pkg/core/storelimit"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/slice"
+	"github.com/tikv/pd/server/apiv2/middlewares"
+	"github.com/tikv/pd/server/cluster"
+	"github.com/tikv/pd/server/config"
+)
+
+// The operations can be applied to the stores in batch.
+const (
+	storeOpSetLabels = "set-labels"
+	storeOpSetLimit  = "set-limit"
+	storeOpSetWeight = "set-weight"
+	storeOpDrain     = "drain"
+)
+
+// StoreSelector selects the stores a batch operation is applied to. A store is
+// selected if it satisfies all the conditions. The tombstone stores are only
+// selected if the state is "Tombstone".
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type StoreSelector struct {
+	// All selects all the stores if there is no other condition, it avoids
+	// applying an operation to all the stores by mistake.
+	All    bool              `json:"all,omitempty"`
+	IDs    []uint64          `json:"ids,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+	// State is one of "Up", "Offline" and "Tombstone".
+	State string `json:"state,omitempty"`
+}
+
+func (s *StoreSelector) isEmpty() bool {
+	return len(s.IDs) == 0 && len(s.Labels) == 0 && len(s.State) == 0
+}
+
+func (s *StoreSelector) match(store *core.StoreInfo) bool {
+	if len(s.IDs) > 0 && !slice.Contains(s.IDs, store.GetID()) {
+		return false
+	}
+	if len(s.State) > 0 {
+		if !strings.EqualFold(store.GetState().String(), s.State) {
+			return false
+		}
+	} else if store.IsRemoved() {
+		return false
+	}
+	for k, v := range s.Labels {
+		if store.GetLabelValue(k) != v {
+			return false
+		}
+	}
+	return true
+}
+
+// BatchStoreParams represents parameters needed to apply an operation to the
+// selected stores in batch.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type BatchStoreParams struct {
+	Selector *StoreSelector `json:"selector"`
+	// Operation is one of "set-labels", "set-limit", "set-weight" and "drain".
+	Operation string `json:"operation"`
+	// Labels are merged into the labels of the stores by "set-labels".
+	Labels map[string]string `json:"labels,omitempty"`
+	// LimitType is "add-peer" or "remove-peer" for "set-limit", both of them if empty.
+	LimitType string `json:"limit_type,omitempty"`
+	// Rate is the number of the operators per minute for "set-limit".
+	Rate float64 `json:"rate,omitempty"`
+	// LeaderWeight and RegionWeight are set by "set-weight", the unset one is kept.
+	LeaderWeight *float64 `json:"leader_weight,omitempty"`
+	RegionWeight *float64 `json:"region_weight,omitempty"`
+	// Force means the drained stores are physically destroyed.
+	Force bool `json:"force,omitempty"`
+	// DryRun only returns the selected stores without applying the operation.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// StoreBatchResult is the result of a store in a batch request.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type StoreBatchResult struct {
+	StoreID uint64 `json:"store_id"`
+	Store   *Store `json:"store,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// StoreBatchResponse represents response given by the store batch requests,
+// whose results are in the ascending order of the store ids.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type StoreBatchResponse struct {
+	Results []*StoreBatchResult `json:"results"`
+}
+
+// UpdateStores applies an operation to the selected stores in batch. A store
+// failing to be updated does not prevent others from being updated.
+// @Tags     stores
+// @Summary  Apply an operation to the selected stores in batch.
+// @Param    body  body  BatchStoreParams  true  "The selector and the operation"
+// @Produce  json
+// @Success  200  {object}  StoreBatchResponse
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Router   /stores/batch [post]
+func UpdateStores(c *gin.Context) {
+	rc := c.MustGet("cluster").(*cluster.RaftCluster)
+	params := &BatchStoreParams{}
+	if err := c.BindJSON(params); err != nil {
+		middlewares.AbortWithError(c, http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause())
+		return
+	}
+	if params.Selector == nil || (params.Selector.isEmpty() && !params.Selector.All) {
+		middlewares.AbortWithMessage(c, http.StatusBadRequest, "selector should not be empty, use all to select all the stores")
+		return
+	}
+	apply, err := newStoreOperation(rc, params)
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	var stores []*core.StoreInfo
+	for _, store := range rc.GetStores() {
+		if params.Selector.match(store) {
+			stores = append(stores, store)
+		}
+	}
+	sort.Slice(stores, func(i, j int) bool { return stores[i].GetID() < stores[j].GetID() })
+	resp := &StoreBatchResponse{Results: make([]*StoreBatchResult, 0, len(stores))}
+	for _, store := range stores {
+		result := &StoreBatchResult{StoreID: store.GetID()}
+		var err error
+		if !params.DryRun {
+			err = apply(store.GetID())
+		}
+		if err != nil {
+			result.Error = err.Error()
+		} else if store = rc.GetStore(store.GetID()); store != nil {
+			result.Store = newStore(store, rc.GetOpts())
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	c.IndentedJSON(http.StatusOK, resp)
+}
+
+// newStoreOperation validates the parameters of the operation, and returns the
+// function applying it to a store.
+func newStoreOperation(rc *cluster.RaftCluster, params *BatchStoreParams) (func(storeID uint64) error, error) {
+	switch params.Operation {
+	case storeOpSetLabels:
+		if len(params.Labels) == 0 {
+			return nil, errors.New("labels should not be empty")
+		}
+		labels := make([]*metapb.StoreLabel, 0, len(params.Labels))
+		for k, v := range params.Labels {
+			labels = append(labels, &metapb.StoreLabel{Key: k, Value: v})
+		}
+		if err := config.ValidateLabels(labels); err != nil {
+			return nil, err
+		}
+		return func(storeID uint64) error {
+			return rc.UpdateStoreLabels(storeID, labels, false)
+		}, nil
+	case storeOpSetLimit:
+		if params.Rate <= 0 {
+			return nil, errors.New("rate should be larger than 0")
+		}
+		types := []storelimit.Type{storelimit.AddPeer, storelimit.RemovePeer}
+		if len(params.LimitType) > 0 {
+			typ, ok := storelimit.TypeNameValue[params.LimitType]
+			if !ok {
+				return nil, errors.New("unknown limit type " + params.LimitType)
+			}
+			types = []storelimit.Type{typ}
+		}
+		return func(storeID uint64) error {
+			for _, typ := range types {
+				if err := rc.SetStoreLimit(storeID, typ, params.Rate); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, nil
+	case storeOpSetWeight:
+		if params.LeaderWeight == nil && params.RegionWeight == nil {
+			return nil, errors.New("leader weight and region weight should not be both unset")
+		}
+		if (params.LeaderWeight != nil && *params.LeaderWeight < 0) || (params.RegionWeight != nil && *params.RegionWeight < 0) {
+			return nil, errors.New("weight should not be negative")
+		}
+		return func(storeID uint64) error {
+			store := rc.GetStore(storeID)
+			if store == nil {
+				return errs.ErrStoreNotFound.FastGenByArgs(storeID)
+			}
+			leaderWeight, regionWeight := store.GetLeaderWeight(), store.GetRegionWeight()
+			if params.LeaderWeight != nil {
+				leaderWeight = *params.LeaderWeight
+			}
+			if params.RegionWeight != nil {
+				regionWeight = *params.RegionWeight
+			}
+			return rc.SetStoreWeight(storeID, leaderWeight, regionWeight)
+		}, nil
+	case storeOpDrain:
+		return func(storeID uint64) error {
+			return rc.RemoveStore(storeID, params.Force)
+		}, nil
+	default:
+		return nil, errors.New("unknown operation " + params.Operation)
+	}
+}
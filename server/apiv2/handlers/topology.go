@@ -0,0 +1,146 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tikv/pd/server/apiv2/middlewares"
+	"github.com/tikv/pd/server/cluster"
+)
+
+// defaultTopologyLevels are the levels of the topology if the location labels
+// are not configured.
+var defaultTopologyLevels = []string{"zone", "rack", "host"}
+
+// RegisterTopology registers the cluster topology related handlers to router paths.
+func RegisterTopology(r *gin.RouterGroup) {
+	router := r.Group("topology")
+	router.Use(middlewares.BootstrapChecker())
+	router.GET("", GetTopology)
+}
+
+// TopologyNode is a node in the topology tree, which contains the stores with
+// the same labels from the top level to its level.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type TopologyNode struct {
+	// Label is the location label of the level, empty for the root.
+	Label string `json:"label,omitempty"`
+	// Value is the value of the label, empty if the stores don't have the label.
+	Value      string `json:"value,omitempty"`
+	StoreCount int    `json:"store_count"`
+	Capacity   uint64 `json:"capacity"`
+	Available  uint64 `json:"available"`
+	// LeaderCount and RegionCount are the sums of the stores in the node.
+	LeaderCount int `json:"leader_count"`
+	RegionCount int `json:"region_count"`
+	// Versions are the store counts of each version.
+	Versions map[string]int `json:"versions"`
+	// Children are the nodes of the next level sorted by the value.
+	Children []*TopologyNode `json:"children,omitempty"`
+	// Stores are the stores sorted by the id, which are only in the nodes of the last level.
+	Stores []*Store `json:"stores,omitempty"`
+
+	children map[string]*TopologyNode
+}
+
+// Topology is the labeled topology of the stores in the cluster.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type Topology struct {
+	// Levels are the location labels from the top level to the bottom level.
+	Levels []string      `json:"levels"`
+	Root   *TopologyNode `json:"root"`
+}
+
+func newTopologyNode(label, value string) *TopologyNode {
+	return &TopologyNode{
+		Label:    label,
+		Value:    value,
+		Versions: make(map[string]int),
+		children: make(map[string]*TopologyNode),
+	}
+}
+
+func (n *TopologyNode) add(store *Store) {
+	n.StoreCount++
+	n.Capacity += store.Capacity
+	n.Available += store.Available
+	n.LeaderCount += store.LeaderCount
+	n.RegionCount += store.RegionCount
+	n.Versions[store.Version]++
+}
+
+// child returns the child node with the value, which is created if not exists.
+func (n *TopologyNode) child(label, value string) *TopologyNode {
+	child, ok := n.children[value]
+	if !ok {
+		child = newTopologyNode(label, value)
+		n.children[value] = child
+		n.Children = append(n.Children, child)
+	}
+	return child
+}
+
+func (n *TopologyNode) sort() {
+	sort.Slice(n.Children, func(i, j int) bool { return n.Children[i].Value < n.Children[j].Value })
+	sort.Slice(n.Stores, func(i, j int) bool { return n.Stores[i].ID < n.Stores[j].ID })
+	for _, child := range n.Children {
+		child.sort()
+	}
+}
+
+// GetTopology returns the topology of the stores.
+// @Tags     topology
+// @Summary  Get the topology tree of the stores not removed, which is built from the store labels level by level.
+// @Param    levels  query  string  false  "The comma separated labels of the levels, the location labels by default, or zone,rack,host if they are not configured"
+// @Produce  json
+// @Success  200  {object}  Topology
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Router   /topology [get]
+func GetTopology(c *gin.Context) {
+	rc := c.MustGet("cluster").(*cluster.RaftCluster)
+	levels := rc.GetOpts().GetLocationLabels()
+	if value := c.Query("levels"); len(value) > 0 {
+		levels = strings.Split(value, ",")
+		for _, level := range levels {
+			if len(level) == 0 {
+				middlewares.AbortWithMessage(c, http.StatusBadRequest, "invalid levels: "+value)
+				return
+			}
+		}
+	}
+	if len(levels) == 0 {
+		levels = defaultTopologyLevels
+	}
+	res := &Topology{Levels: levels, Root: newTopologyNode("", "")}
+	for _, info := range rc.GetStores() {
+		if info.IsRemoved() {
+			continue
+		}
+		store := newStore(info, rc.GetOpts())
+		node := res.Root
+		node.add(store)
+		for _, level := range levels {
+			node = node.child(level, store.Labels[level])
+			node.add(store)
+		}
+		node.Stores = append(node.Stores, store)
+	}
+	res.Root.sort()
+	c.IndentedJSON(http.StatusOK, res)
+}
@@ -0,0 +1,176 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/webhook"
+	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/apiv2/middlewares"
+)
+
+// RegisterWebhook registers the webhook related handlers to router paths.
+func RegisterWebhook(r *gin.RouterGroup) {
+	router := r.Group("webhooks")
+	router.GET("", GetWebhooks)
+	router.GET("/:id", GetWebhook)
+	router.PUT("/:id", PutWebhook)
+	router.DELETE("/:id", DeleteWebhook)
+}
+
+// Webhook is a webhook with its delivery status, the secret is never returned.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type Webhook struct {
+	*endpoint.Webhook
+	// Signed is true if the requests are signed with the secret.
+	Signed bool `json:"signed"`
+	// Status is the delivery status on the leader, nil if the leader is not ready.
+	Status *webhook.Status `json:"status,omitempty"`
+}
+
+// PutWebhookParams represents parameters needed when saving a webhook.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type PutWebhookParams struct {
+	URL string `json:"url"`
+	// Secret signs the requests with HMAC-SHA256 in the X-PD-Signature header,
+	// empty to not sign them.
+	Secret string `json:"secret,omitempty"`
+	// EventTypes are the types or the categories of the events to be sent,
+	// e.g. "store-down" or "operator", empty for all the events.
+	EventTypes []string `json:"event_types,omitempty"`
+	// StoreIDs are the stores of the events to be sent, empty for all.
+	StoreIDs []uint64 `json:"store_ids,omitempty"`
+	// Attributes are the values of the attributes of the events to be sent,
+	// e.g. {"status": ["TIMEOUT", "CANCEL"]} for the failed operators.
+	Attributes map[string][]string `json:"attributes,omitempty"`
+	// MaxRetries is the max times to retry a failed delivery, 3 by default.
+	MaxRetries *int `json:"max_retries,omitempty"`
+}
+
+func newWebhook(manager *webhook.Manager, w *endpoint.Webhook) *Webhook {
+	signed := len(w.Secret) > 0
+	redacted := *w
+	redacted.Secret = ""
+	return &Webhook{Webhook: &redacted, Signed: signed, Status: manager.GetStatus(w.ID)}
+}
+
+// GetWebhooks returns all the webhooks.
+// @Tags     webhooks
+// @Summary  Get all the webhooks with the delivery status.
+// @Produce  json
+// @Success  200  {array}   Webhook
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /webhooks [get]
+func GetWebhooks(c *gin.Context) {
+	manager := c.MustGet("server").(*server.Server).GetWebhookManager()
+	webhooks, err := manager.GetWebhooks()
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	res := make([]*Webhook, 0, len(webhooks))
+	for _, w := range webhooks {
+		res = append(res, newWebhook(manager, w))
+	}
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// GetWebhook returns the webhook.
+// @Tags     webhooks
+// @Summary  Get the webhook with the delivery status.
+// @Param    id  path  string  true  "The webhook ID"
+// @Produce  json
+// @Success  200  {object}  Webhook
+// @Failure  404  {object}  middlewares.ErrorResponse  "The webhook does not exist."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /webhooks/{id} [get]
+func GetWebhook(c *gin.Context) {
+	manager := c.MustGet("server").(*server.Server).GetWebhookManager()
+	w, err := manager.GetWebhook(c.Param("id"))
+	if err != nil {
+		abortWithWebhookError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, newWebhook(manager, w))
+}
+
+// PutWebhook creates or replaces the webhook.
+// @Tags     webhooks
+// @Summary  Create or replace the webhook, which is called with the cluster events passing the filters.
+// @Param    id    path  string            true  "The webhook ID"
+// @Param    body  body  PutWebhookParams  true  "The URL, the secret and the filters"
+// @Produce  json
+// @Success  200  {object}  Webhook
+// @Failure  400  {object}  middlewares.ErrorResponse  "The input is invalid."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /webhooks/{id} [put]
+func PutWebhook(c *gin.Context) {
+	params := &PutWebhookParams{}
+	if err := c.BindJSON(params); err != nil {
+		middlewares.AbortWithError(c, http.StatusBadRequest, errs.ErrBindJSON.Wrap(err).GenWithStackByCause())
+		return
+	}
+	w := &endpoint.Webhook{
+		ID:         c.Param("id"),
+		URL:        params.URL,
+		Secret:     params.Secret,
+		EventTypes: params.EventTypes,
+		StoreIDs:   params.StoreIDs,
+		Attributes: params.Attributes,
+		MaxRetries: webhook.DefaultMaxRetries,
+	}
+	if params.MaxRetries != nil {
+		w.MaxRetries = *params.MaxRetries
+	}
+	manager := c.MustGet("server").(*server.Server).GetWebhookManager()
+	w, err := manager.SaveWebhook(w)
+	if err != nil {
+		abortWithWebhookError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, newWebhook(manager, w))
+}
+
+// DeleteWebhook removes the webhook.
+// @Tags     webhooks
+// @Summary  Remove the webhook, the events not sent yet are dropped.
+// @Param    id  path  string  true  "The webhook ID"
+// @Success  200
+// @Failure  404  {object}  middlewares.ErrorResponse  "The webhook does not exist."
+// @Failure  500  {object}  middlewares.ErrorResponse  "PD server failed to proceed the request."
+// @Router   /webhooks/{id} [delete]
+func DeleteWebhook(c *gin.Context) {
+	manager := c.MustGet("server").(*server.Server).GetWebhookManager()
+	if err := manager.RemoveWebhook(c.Param("id")); err != nil {
+		abortWithWebhookError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func abortWithWebhookError(c *gin.Context, err error) {
+	switch {
+	case errs.ErrInvalidWebhook.Equal(err):
+		middlewares.AbortWithError(c, http.StatusBadRequest, err)
+	case errs.ErrWebhookNotFound.Equal(err):
+		middlewares.AbortWithError(c, http.StatusNotFound, err)
+	default:
+		middlewares.AbortWithError(c, http.StatusInternalServerError, err)
+	}
+}
@@ -28,7 +28,7 @@ func BootstrapChecker() gin.HandlerFunc {
 		svr := c.MustGet("server").(*server.Server)
 		rc := svr.GetRaftCluster()
 		if rc == nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, errs.ErrNotBootstrapped.FastGenByArgs().Error())
+			AbortWithError(c, http.StatusInternalServerError, errs.ErrNotBootstrapped.FastGenByArgs())
 			return
 		}
 		c.Set("cluster", rc)
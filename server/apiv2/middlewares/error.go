@@ -0,0 +1,58 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tikv/pd/pkg/errs"
+)
+
+// ErrorResponse is the envelope of the errors returned by the v2 APIs. The
+// code is the RFC code of the error, e.g. "PD:keyspace:ErrKeyspaceNotFound",
+// or the HTTP status text if the error has no code.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type ErrorResponse errs.Detail
+
+// AbortWithError aborts the request with the error in the envelope.
+func AbortWithError(c *gin.Context, status int, err error) {
+	detail := errs.Describe(err)
+	if detail.Code == "" {
+		detail.Code = http.StatusText(status)
+	}
+	detail.Retryable = detail.Retryable || isRetryableStatus(status)
+	c.AbortWithStatusJSON(status, (*ErrorResponse)(detail))
+}
+
+// AbortWithMessage aborts the request with the message in the envelope.
+func AbortWithMessage(c *gin.Context, status int, message string) {
+	c.AbortWithStatusJSON(status, &ErrorResponse{
+		Code:      http.StatusText(status),
+		Component: errs.UnknownComponent,
+		Retryable: isRetryableStatus(status),
+		Message:   message,
+	})
+}
+
+// isRetryableStatus returns whether the request failed with the status may
+// succeed after retrying.
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,210 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tikv/pd/pkg/cache"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/rbac"
+	"github.com/tikv/pd/pkg/utils/syncutil"
+)
+
+const (
+	// IdempotencyKeyHeader is the header of the key generated by the client for
+	// a mutating request. The requests with the same key are applied only once,
+	// and the retried ones get the response of the first one.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set in the responses replayed from the first request.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	// DefaultIdempotencyRetention is the time the responses are kept for the retried requests.
+	DefaultIdempotencyRetention = time.Hour
+
+	maxIdempotencyKeyLength = 255
+	idempotencyGCInterval   = time.Minute
+	// maxIdempotencyBodySize is the max size of the request body with an
+	// idempotency key, which is buffered in memory to be fingerprinted.
+	maxIdempotencyBodySize = 16 << 20
+	// maxIdempotencyEntries is the max number of the kept responses, the new
+	// keys are rejected until the old ones expire.
+	maxIdempotencyEntries = 10000
+)
+
+// idempotentResponse is the response of the first request with an idempotency key.
+type idempotentResponse struct {
+	// fingerprint identifies the request, the key can't be reused by another request.
+	fingerprint string
+	// finished is false if the first request is still being processed.
+	finished    bool
+	status      int
+	contentType string
+	body        []byte
+}
+
+// IdempotencyCache keeps the responses of the requests with idempotency keys
+// in memory, so the keys are not deduplicated across the PD leaders.
+type IdempotencyCache struct {
+	mu        syncutil.Mutex
+	responses *cache.TTLString
+	retention time.Duration
+}
+
+// NewIdempotencyCache creates a cache keeping the responses for the retention.
+func NewIdempotencyCache(ctx context.Context, retention time.Duration) *IdempotencyCache {
+	return &IdempotencyCache{
+		responses: cache.NewStringTTL(ctx, idempotencyGCInterval, retention),
+		retention: retention,
+	}
+}
+
+// begin returns the response of the key if it exists, otherwise it marks the
+// request with the fingerprint as being processed. It returns false if the
+// cache is full.
+func (c *IdempotencyCache) begin(key, fingerprint string) (*idempotentResponse, bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if v, ok := c.responses.Get(key); ok {
+		resp := *v.(*idempotentResponse)
+		return &resp, true, true
+	}
+	if c.responses.Len() >= maxIdempotencyEntries {
+		return nil, false, false
+	}
+	c.responses.PutWithTTL(key, &idempotentResponse{fingerprint: fingerprint}, c.retention)
+	return nil, false, true
+}
+
+// finish records the response of the key. The server errors are not kept, so
+// that the requests can be retried.
+func (c *IdempotencyCache) finish(key, fingerprint string, status int, contentType string, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if status >= http.StatusInternalServerError {
+		c.responses.Remove(key)
+		return
+	}
+	c.responses.PutWithTTL(key, &idempotentResponse{
+		fingerprint: fingerprint,
+		finished:    true,
+		status:      status,
+		contentType: contentType,
+		body:        body,
+	}, c.retention)
+}
+
+// recordingWriter keeps a copy of the response body.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// idempotencyScope returns the identity of the caller, so the same key sent by
+// different callers never replays the response of one to the other.
+func idempotencyScope(r *http.Request) string {
+	cred := rbac.HTTPCredential(r)
+	var token string
+	if len(cred.Token) > 0 {
+		token = rbac.HashToken(cred.Token)
+	}
+	return cred.CN + "/" + token
+}
+
+// Idempotency is a middleware to deduplicate the mutating requests with the
+// same idempotency key from the same caller. The key can't be reused by a
+// request with another method, path or body, and the retried request fails if
+// the first one is still being processed.
+func Idempotency(responses *IdempotencyCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if len(key) == 0 || !isMutatingMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			AbortWithMessage(c, http.StatusBadRequest, "idempotency key is too long")
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotencyBodySize))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				AbortWithMessage(c, http.StatusRequestEntityTooLarge, err.Error())
+				return
+			}
+			AbortWithError(c, http.StatusBadRequest, errs.ErrIORead.Wrap(err).GenWithStackByCause())
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		sum := sha256.Sum256(body)
+		fingerprint := c.Request.Method + " " + c.Request.URL.RequestURI() + " " + hex.EncodeToString(sum[:])
+		cacheKey := idempotencyScope(c.Request) + "/" + key
+
+		resp, exist, ok := responses.begin(cacheKey, fingerprint)
+		switch {
+		case !ok:
+			AbortWithMessage(c, http.StatusTooManyRequests, "too many idempotency keys are being kept, retry later")
+			return
+		case !exist:
+		case resp.fingerprint != fingerprint:
+			AbortWithMessage(c, http.StatusUnprocessableEntity, "idempotency key is used by another request: "+key)
+			return
+		case !resp.finished:
+			AbortWithMessage(c, http.StatusConflict, "request with the idempotency key is being processed: "+key)
+			return
+		default:
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(resp.status, resp.contentType, resp.body)
+			c.Abort()
+			return
+		}
+
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		status := http.StatusInternalServerError
+		// The key is released if the handler panics.
+		defer func() {
+			responses.finish(cacheKey, fingerprint, status, writer.Header().Get("Content-Type"), writer.body.Bytes())
+		}()
+		c.Next()
+		status = writer.Status()
+	}
+}
@@ -40,7 +40,7 @@ func Redirector() gin.HandlerFunc {
 		// Prevent more than one redirection.
 		if name := c.Request.Header.Get(serverapi.RedirectorHeader); len(name) != 0 {
 			log.Error("redirect but server is not leader", zap.String("from", name), zap.String("server", svr.Name()), errs.ZapError(errs.ErrRedirect))
-			c.AbortWithStatusJSON(http.StatusInternalServerError, errs.ErrRedirect.FastGenByArgs().Error())
+			AbortWithError(c, http.StatusInternalServerError, errs.ErrRedirect.FastGenByArgs())
 			return
 		}
 
@@ -48,7 +48,7 @@ func Redirector() gin.HandlerFunc {
 
 		leader := svr.GetMember().GetLeader()
 		if leader == nil {
-			c.AbortWithStatusJSON(http.StatusServiceUnavailable, errs.ErrLeaderNil.FastGenByArgs().Error())
+			AbortWithError(c, http.StatusServiceUnavailable, errs.ErrLeaderNil.FastGenByArgs())
 			return
 		}
 		clientUrls := leader.GetClientUrls()
@@ -56,7 +56,7 @@ func Redirector() gin.HandlerFunc {
 		for _, item := range clientUrls {
 			u, err := url.Parse(item)
 			if err != nil {
-				c.AbortWithStatusJSON(http.StatusInternalServerError, errs.ErrURLParse.Wrap(err).GenWithStackByCause().Error())
+				AbortWithError(c, http.StatusInternalServerError, errs.ErrURLParse.Wrap(err).GenWithStackByCause())
 				return
 			}
 
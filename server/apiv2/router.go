@@ -21,6 +21,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"github.com/tikv/pd/pkg/swaggerserver"
 	"github.com/tikv/pd/pkg/utils/apiutil"
 	"github.com/tikv/pd/server"
 	"github.com/tikv/pd/server/apiv2/handlers"
@@ -48,7 +49,7 @@ const apiV2Prefix = "/pd/api/v2/"
 // @license.name   Apache 2.0
 // @license.url    http://www.apache.org/licenses/LICENSE-2.0.html
 // @BasePath       /pd/api/v2
-func NewV2Handler(_ context.Context, svr *server.Server) (http.Handler, apiutil.APIServiceGroup, error) {
+func NewV2Handler(ctx context.Context, svr *server.Server) (http.Handler, apiutil.APIServiceGroup, error) {
 	once.Do(func() {
 		// See https://github.com/pingcap/tidb-dashboard/blob/f8ecb64e3d63f4ed91c3dca7a04362418ade01d8/pkg/apiserver/apiserver.go#L84
 		// These global modification will be effective only for the first invoke.
@@ -61,7 +62,30 @@ func NewV2Handler(_ context.Context, svr *server.Server) (http.Handler, apiutil.
 		c.Next()
 	})
 	router.Use(middlewares.Redirector())
+	router.Use(middlewares.Idempotency(middlewares.NewIdempotencyCache(ctx, middlewares.DefaultIdempotencyRetention)))
 	root := router.Group(apiV2Prefix)
+	root.GET("openapi.json", getOpenAPISpec)
 	handlers.RegisterKeyspace(root)
+	handlers.RegisterStore(root)
+	handlers.RegisterTopology(root)
+	handlers.RegisterRegion(root)
+	handlers.RegisterScheduler(root)
+	handlers.RegisterOperator(root)
+	handlers.RegisterRule(root)
+	handlers.RegisterEvent(root)
+	handlers.RegisterJob(root)
+	handlers.RegisterGlobalConfig(root)
+	handlers.RegisterWebhook(root)
 	return router, group, nil
 }
+
+// getOpenAPISpec returns the OpenAPI spec of the v2 API, which is only available
+// when PD is built with `SWAGGER=1`.
+func getOpenAPISpec(c *gin.Context) {
+	doc, err := swaggerserver.ReadDoc("v2")
+	if err != nil {
+		middlewares.AbortWithError(c, http.StatusNotFound, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(doc))
+}
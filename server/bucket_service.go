@@ -0,0 +1,183 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/tikv/pd/pkg/core"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/event"
+	"github.com/tikv/pd/pkg/utils/grpcutil"
+	"github.com/tikv/pd/server/cluster"
+	"google.golang.org/grpc"
+)
+
+// BucketServiceName is the name of the gRPC service watching the buckets of
+// the regions. Like the rule service, kvproto doesn't define the service, so
+// its messages are the Go structs below encoded in JSON.
+const BucketServiceName = "pd.Bucket"
+
+// WatchBucketsRequest is the request of WatchBuckets.
+type WatchBucketsRequest struct {
+	Header *pdpb.RequestHeader `json:"header"`
+	// StartKey and EndKey limit the buckets to the ones of the regions
+	// overlapping with [StartKey, EndKey), an empty EndKey means no limit.
+	StartKey []byte `json:"start_key,omitempty"`
+	EndKey   []byte `json:"end_key,omitempty"`
+}
+
+// WatchBucketsResponse is a message of the WatchBuckets stream.
+type WatchBucketsResponse struct {
+	Header *pdpb.ResponseHeader `json:"header"`
+	// Snapshot means the message has the buckets of all the regions in the
+	// range, which replace the ones the watcher knows. Otherwise, the message
+	// has the changed buckets.
+	Snapshot bool              `json:"snapshot,omitempty"`
+	Buckets  []*metapb.Buckets `json:"buckets,omitempty"`
+}
+
+// BucketServer wraps GrpcServer to provide the bucket service.
+type BucketServer struct {
+	*GrpcServer
+}
+
+// RegisterBucketServer registers the bucket service to the gRPC server.
+func RegisterBucketServer(gs *grpc.Server, srv *BucketServer) {
+	gs.RegisterService(&bucketServiceDesc, srv)
+}
+
+var bucketServiceDesc = grpc.ServiceDesc{
+	ServiceName: BucketServiceName,
+	HandlerType: (*interface{})(nil),
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName: "WatchBuckets",
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				request := &WatchBucketsRequest{}
+				if err := stream.RecvMsg(request); err != nil {
+					return err
+				}
+				return srv.(*BucketServer).WatchBuckets(request, stream)
+			},
+			ServerStreams: true,
+		},
+	},
+}
+
+// WatchBuckets sends the buckets of the regions in the range as the first
+// message, and then the buckets whose keys are changed. It sends all the
+// buckets again if it falls too far behind the changes. No bucket is sent if
+// the region bucket is disabled.
+func (s *BucketServer) WatchBuckets(request *WatchBucketsRequest, stream grpc.ServerStream) error {
+	ctx := stream.Context()
+	if err := s.checkPolicy(ctx); err != nil {
+		return grpcutil.StatusError(ctx, err)
+	}
+	if err := s.validateRequest(request.Header); err != nil {
+		return grpcutil.StatusError(ctx, err)
+	}
+	rc := s.GetRaftCluster()
+	if rc == nil {
+		return stream.SendMsg(&WatchBucketsResponse{Header: s.notBootstrappedHeader()})
+	}
+	var (
+		sub *event.Subscription
+		err error
+	)
+	for {
+		if sub == nil {
+			if sub, err = rc.GetEventHub().Subscribe(""); err != nil {
+				return grpcutil.StatusError(ctx, err)
+			}
+			if err = stream.SendMsg(s.snapshotBuckets(rc, request)); err != nil {
+				return err
+			}
+		}
+		nextCtx, cancel := context.WithTimeout(ctx, watchRulesCheckInterval)
+		var events []*event.Event
+		events, err = sub.Next(nextCtx)
+		cancel()
+		switch {
+		case err == nil:
+			if resp := s.changedBuckets(rc, request, events); resp != nil {
+				if err := stream.SendMsg(resp); err != nil {
+					return err
+				}
+			}
+		case errs.ErrEventCompacted.Equal(err):
+			sub = nil
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case s.IsClosed() || s.GetRaftCluster() != rc:
+			// The events of the cluster end once it stops, so the watcher
+			// should watch the new leader.
+			return grpcutil.StatusError(ctx, ErrNotLeader)
+		}
+	}
+}
+
+func (s *BucketServer) snapshotBuckets(rc *cluster.RaftCluster, request *WatchBucketsRequest) *WatchBucketsResponse {
+	resp := &WatchBucketsResponse{Header: s.header(), Snapshot: true}
+	if !rc.GetStoreConfig().IsEnableRegionBucket() {
+		return resp
+	}
+	for _, region := range rc.ScanRegions(request.StartKey, request.EndKey, 0) {
+		if buckets := region.GetBuckets(); buckets != nil {
+			resp.Buckets = append(resp.Buckets, buckets)
+		}
+	}
+	return resp
+}
+
+// changedBuckets returns the current buckets of the regions in the range
+// changed by the events, or nil if no bucket is changed.
+func (s *BucketServer) changedBuckets(rc *cluster.RaftCluster, request *WatchBucketsRequest, events []*event.Event) *WatchBucketsResponse {
+	if !rc.GetStoreConfig().IsEnableRegionBucket() {
+		return nil
+	}
+	resp := &WatchBucketsResponse{Header: s.header()}
+	changed := make(map[uint64]struct{})
+	for _, e := range events {
+		if e.Type != event.BucketsChanged {
+			continue
+		}
+		if _, ok := changed[e.RegionID]; ok {
+			continue
+		}
+		changed[e.RegionID] = struct{}{}
+		region := rc.GetRegion(e.RegionID)
+		if region == nil || !overlapsRange(region, request.StartKey, request.EndKey) {
+			continue
+		}
+		if buckets := region.GetBuckets(); buckets != nil {
+			resp.Buckets = append(resp.Buckets, buckets)
+		}
+	}
+	if len(resp.Buckets) == 0 {
+		return nil
+	}
+	return resp
+}
+
+// overlapsRange returns whether the region overlaps with [startKey, endKey),
+// where an empty endKey means no limit.
+func overlapsRange(region *core.RegionInfo, startKey, endKey []byte) bool {
+	return (len(endKey) == 0 || bytes.Compare(region.GetStartKey(), endKey) < 0) &&
+		(len(region.GetEndKey()) == 0 || bytes.Compare(region.GetEndKey(), startKey) > 0)
+}
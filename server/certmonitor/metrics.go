@@ -0,0 +1,49 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certmonitor
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	certRemainingGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "security",
+			Name:      "cert_remaining_seconds",
+			Help:      "How long before the TLS certificate expires, negative if it has expired.",
+		}, []string{"kind", "path", "serial_number"})
+
+	certReadFailureCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "security",
+			Name:      "cert_read_failures_total",
+			Help:      "Counter of the failures to read the TLS certificate files.",
+		}, []string{"kind"})
+
+	certRenewalCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "security",
+			Name:      "cert_renewals_total",
+			Help:      "Counter of the runs of the certificate renew command.",
+		}, []string{"result"})
+)
+
+func init() {
+	prometheus.MustRegister(certRemainingGauge)
+	prometheus.MustRegister(certReadFailureCounter)
+	prometheus.MustRegister(certRenewalCounter)
+}
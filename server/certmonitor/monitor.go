@@ -0,0 +1,403 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certmonitor
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/utils/apiutil"
+	"github.com/tikv/pd/pkg/utils/logutil"
+	"github.com/tikv/pd/pkg/utils/syncutil"
+	"github.com/tikv/pd/server/config"
+	"go.uber.org/zap"
+)
+
+const (
+	// webhookTimeout is the timeout to notify the webhook.
+	webhookTimeout = 10 * time.Second
+	// maxRenewals is the number of the recent renewals kept in memory.
+	maxRenewals = 32
+	// maxRenewOutput is the max length of the output of the renew command kept.
+	maxRenewOutput = 4096
+)
+
+// The kinds of the monitored certificate files.
+const (
+	// CertKindServer is the certificate of PD, set by cert-path.
+	CertKindServer = "cert"
+	// CertKindCA is the trusted CA certificates, set by cacert-path.
+	CertKindCA = "ca"
+	// CertKindDashboardTiDB is the client certificate of the dashboard to TiDB.
+	CertKindDashboardTiDB = "dashboard-tidb-cert"
+	// CertKindDashboardTiDBCA is the CA certificates of the dashboard to TiDB.
+	CertKindDashboardTiDBCA = "dashboard-tidb-ca"
+)
+
+// The environment variables passed to the renew command.
+const (
+	envCertPath   = "PD_CERT_PATH"
+	envKeyPath    = "PD_KEY_PATH"
+	envCACertPath = "PD_CACERT_PATH"
+	// envExpiring is the comma separated paths of the expiring certificates.
+	envExpiring = "PD_EXPIRING_CERTS"
+)
+
+// ErrRenewDisabled is returned when no renew command is configured.
+var ErrRenewDisabled = errors.New("certificate renew command is not configured")
+
+// CertStatus is the status of a certificate in a monitored file.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type CertStatus struct {
+	Kind         string `json:"kind"`
+	Path         string `json:"path"`
+	Subject      string `json:"subject,omitempty"`
+	Issuer       string `json:"issuer,omitempty"`
+	SerialNumber string `json:"serial_number,omitempty"`
+	// NotBefore and NotAfter are the unix timestamps in seconds.
+	NotBefore int64 `json:"not_before,omitempty"`
+	NotAfter  int64 `json:"not_after,omitempty"`
+	// Remaining is the seconds before it expires, negative if it has expired.
+	Remaining int64 `json:"remaining"`
+	// Expiring means it expires within the renew-before duration.
+	Expiring bool `json:"expiring"`
+	// Error is the failure to read the file.
+	Error string `json:"error,omitempty"`
+}
+
+// Renewal is a run of the renew command.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type Renewal struct {
+	// Time is the unix timestamp in seconds when the command starts.
+	Time int64 `json:"time"`
+	// Manual means it is triggered by the API.
+	Manual bool `json:"manual,omitempty"`
+	// Expiring are the paths of the expiring certificates.
+	Expiring []string `json:"expiring,omitempty"`
+	Output   string   `json:"output,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// Status is the status of the certificate monitor.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type Status struct {
+	// CheckTime is the unix timestamp in seconds of the last check.
+	CheckTime int64         `json:"check_time"`
+	Certs     []*CertStatus `json:"certs"`
+	// Renewals are the recent renewals from the oldest.
+	Renewals []*Renewal `json:"renewals"`
+}
+
+type certFile struct {
+	kind string
+	path string
+}
+
+// Monitor checks the expiry of the TLS certificates of PD periodically,
+// exposes the remaining time as metrics, and renews the certificates by the
+// renew command before they expire.
+type Monitor struct {
+	config   config.CertMonitorConfig
+	security *config.SecurityConfig
+	files    []certFile
+	// webhookClient notifies the webhook.
+	webhookClient *http.Client
+
+	// runMu makes the checks run one by one.
+	runMu syncutil.Mutex
+	mu    syncutil.Mutex
+	// certs are the certificates in the last check.
+	certs     []*CertStatus
+	checkTime int64
+	// renewals are the recent renewals from the oldest.
+	renewals []*Renewal
+	// notified are the serial numbers of the expiring certificates already
+	// notified, so each certificate is notified once.
+	notified map[string]struct{}
+}
+
+// NewMonitor creates a certificate monitor for the certificates in the config.
+func NewMonitor(cfg *config.Config) *Monitor {
+	m := &Monitor{
+		config:        cfg.Security.CertMonitor,
+		security:      &cfg.Security,
+		webhookClient: &http.Client{Timeout: webhookTimeout},
+		notified:      make(map[string]struct{}),
+	}
+	for _, f := range []certFile{
+		{CertKindServer, cfg.Security.CertPath},
+		{CertKindCA, cfg.Security.CAPath},
+		{CertKindDashboardTiDB, cfg.Dashboard.TiDBCertPath},
+		{CertKindDashboardTiDBCA, cfg.Dashboard.TiDBCAPath},
+	} {
+		if len(f.path) > 0 {
+			m.files = append(m.files, f)
+		}
+	}
+	return m
+}
+
+// StartMonitor checks the certificates in the background until the context is
+// canceled. It does nothing if no certificate is configured.
+func (m *Monitor) StartMonitor(ctx context.Context) {
+	if len(m.files) == 0 {
+		return
+	}
+	go m.runMonitor(ctx)
+}
+
+func (m *Monitor) runMonitor(ctx context.Context) {
+	defer logutil.LogPanic()
+	ticker := time.NewTicker(m.config.CheckInterval.Duration)
+	defer ticker.Stop()
+	for {
+		m.check(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetStatus returns the certificates in the last check and the recent renewals.
+// The renewals are kept in memory, so they are lost when PD restarts.
+func (m *Monitor) GetStatus() *Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return &Status{
+		CheckTime: m.checkTime,
+		Certs:     append([]*CertStatus{}, m.certs...),
+		Renewals:  append([]*Renewal{}, m.renewals...),
+	}
+}
+
+// Renew runs the renew command immediately, and checks the certificates again.
+func (m *Monitor) Renew(ctx context.Context) (*Renewal, error) {
+	if len(m.config.RenewCommand) == 0 {
+		return nil, errors.WithStack(ErrRenewDisabled)
+	}
+	m.runMu.Lock()
+	defer m.runMu.Unlock()
+	now := time.Now()
+	renewal := m.renew(ctx, expiringPaths(m.readCerts(now)), true, now)
+	m.updateCerts(m.readCerts(time.Now()), time.Now())
+	return renewal, nil
+}
+
+// check reads the certificates, and renews them if any is expiring.
+func (m *Monitor) check(ctx context.Context, now time.Time) {
+	m.runMu.Lock()
+	defer m.runMu.Unlock()
+	certs := m.readCerts(now)
+	expiring := expiringPaths(certs)
+	if len(expiring) > 0 && len(m.config.RenewCommand) > 0 {
+		renewal := m.renew(ctx, expiring, false, now)
+		if len(renewal.Error) > 0 {
+			m.notify(&Notification{Type: NotificationRenewFailed, Certs: certs, Renewal: renewal, Time: now.Unix()})
+		}
+		// The renewed certificates are read again.
+		now = time.Now()
+		certs = m.readCerts(now)
+	}
+	m.updateCerts(certs, now)
+	// Notify the certificates which are still expiring after the renewal.
+	var unnotified []*CertStatus
+	for _, cert := range certs {
+		if !cert.Expiring {
+			continue
+		}
+		key := cert.Path + "/" + cert.SerialNumber
+		if _, ok := m.notified[key]; !ok {
+			m.notified[key] = struct{}{}
+			unnotified = append(unnotified, cert)
+		}
+	}
+	if len(unnotified) > 0 {
+		m.notify(&Notification{Type: NotificationExpiring, Certs: unnotified, Time: now.Unix()})
+	}
+}
+
+func (m *Monitor) updateCerts(certs []*CertStatus, now time.Time) {
+	certRemainingGauge.Reset()
+	for _, cert := range certs {
+		if len(cert.Error) > 0 {
+			certReadFailureCounter.WithLabelValues(cert.Kind).Inc()
+			continue
+		}
+		certRemainingGauge.WithLabelValues(cert.Kind, cert.Path, cert.SerialNumber).Set(float64(cert.Remaining))
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.certs, m.checkTime = certs, now.Unix()
+}
+
+// readCerts reads all the certificates in the monitored files.
+func (m *Monitor) readCerts(now time.Time) []*CertStatus {
+	var res []*CertStatus
+	for _, f := range m.files {
+		certs, err := readCertFile(f.path)
+		if err != nil {
+			log.Warn("failed to read certificate file",
+				zap.String("kind", f.kind),
+				zap.String("path", f.path),
+				errs.ZapError(err))
+			res = append(res, &CertStatus{Kind: f.kind, Path: f.path, Error: err.Error()})
+			continue
+		}
+		for _, cert := range certs {
+			remaining := cert.NotAfter.Sub(now)
+			res = append(res, &CertStatus{
+				Kind:         f.kind,
+				Path:         f.path,
+				Subject:      cert.Subject.String(),
+				Issuer:       cert.Issuer.String(),
+				SerialNumber: cert.SerialNumber.Text(16),
+				NotBefore:    cert.NotBefore.Unix(),
+				NotAfter:     cert.NotAfter.Unix(),
+				Remaining:    int64(remaining.Seconds()),
+				Expiring:     remaining <= m.config.RenewBefore.Duration,
+			})
+		}
+	}
+	return res
+}
+
+// readCertFile parses all the PEM encoded certificates in the file.
+func readCertFile(path string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.Annotatef(err, "failed to parse certificate in %s", path)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.Errorf("no certificate in %s", path)
+	}
+	return certs, nil
+}
+
+func expiringPaths(certs []*CertStatus) []string {
+	var paths []string
+	seen := make(map[string]struct{})
+	for _, cert := range certs {
+		if _, ok := seen[cert.Path]; ok || !cert.Expiring {
+			continue
+		}
+		seen[cert.Path] = struct{}{}
+		paths = append(paths, cert.Path)
+	}
+	return paths
+}
+
+// renew runs the renew command with the paths of the certificates in the
+// environment variables.
+func (m *Monitor) renew(ctx context.Context, expiring []string, manual bool, now time.Time) *Renewal {
+	renewal := &Renewal{Time: now.Unix(), Manual: manual, Expiring: expiring}
+	ctx, cancel := context.WithTimeout(ctx, m.config.RenewTimeout.Duration)
+	defer cancel()
+	// #nosec G204
+	cmd := exec.CommandContext(ctx, m.config.RenewCommand[0], m.config.RenewCommand[1:]...)
+	cmd.Env = append(os.Environ(),
+		envCertPath+"="+m.security.CertPath,
+		envKeyPath+"="+m.security.KeyPath,
+		envCACertPath+"="+m.security.CAPath,
+		envExpiring+"="+strings.Join(expiring, ","),
+	)
+	var output bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &output, &output
+	err := cmd.Run()
+	renewal.Output = output.String()
+	if len(renewal.Output) > maxRenewOutput {
+		renewal.Output = renewal.Output[len(renewal.Output)-maxRenewOutput:]
+	}
+	if err != nil {
+		renewal.Error = err.Error()
+		certRenewalCounter.WithLabelValues("failure").Inc()
+		log.Error("failed to renew certificates",
+			zap.Strings("expiring", expiring),
+			zap.String("output", renewal.Output),
+			errs.ZapError(err))
+	} else {
+		certRenewalCounter.WithLabelValues("success").Inc()
+		log.Info("renewed certificates", zap.Strings("expiring", expiring), zap.Bool("manual", manual))
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.renewals = append(m.renewals, renewal)
+	if len(m.renewals) > maxRenewals {
+		m.renewals = m.renewals[len(m.renewals)-maxRenewals:]
+	}
+	return renewal
+}
+
+// The types of the notifications.
+const (
+	// NotificationExpiring means the certificates expire within renew-before.
+	NotificationExpiring = "expiring"
+	// NotificationRenewFailed means the renew command fails.
+	NotificationRenewFailed = "renew_failed"
+)
+
+// Notification is posted to the webhook as JSON.
+type Notification struct {
+	Type    string        `json:"type"`
+	Certs   []*CertStatus `json:"certs"`
+	Renewal *Renewal      `json:"renewal,omitempty"`
+	// Time is the unix timestamp in seconds.
+	Time int64 `json:"time"`
+}
+
+func (m *Monitor) notify(n *Notification) {
+	log.Warn("certificates may expire", zap.String("type", n.Type), zap.Int("count", len(n.Certs)))
+	if len(m.config.Webhook) == 0 {
+		return
+	}
+	data, err := json.Marshal(n)
+	if err != nil {
+		log.Warn("failed to marshal certificate notification", errs.ZapError(errs.ErrJSONMarshal.Wrap(err).GenWithStackByCause()))
+		return
+	}
+	if err := apiutil.PostJSONIgnoreResp(m.webhookClient, m.config.Webhook, data); err != nil {
+		log.Warn("failed to notify the certificate monitor webhook",
+			zap.String("webhook", m.config.Webhook),
+			errs.ZapError(err))
+	}
+}
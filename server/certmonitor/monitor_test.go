@@ -0,0 +1,101 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certmonitor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/utils/typeutil"
+	"github.com/tikv/pd/server/config"
+)
+
+func writeCert(re *require.Assertions, path string, serial int64, notAfter time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	re.NoError(err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "pd"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	re.NoError(err)
+	re.NoError(os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+}
+
+func TestMonitor(t *testing.T) {
+	re := require.New(t)
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "pd.pem")
+	now := time.Now()
+	writeCert(re, certPath, 1, now.Add(90*24*time.Hour))
+
+	cfg := config.NewConfig()
+	cfg.Security.CertPath = certPath
+	cfg.Security.CertMonitor = config.CertMonitorConfig{
+		CheckInterval: typeutil.NewDuration(time.Hour),
+		RenewBefore:   typeutil.NewDuration(30 * 24 * time.Hour),
+		RenewTimeout:  typeutil.NewDuration(time.Minute),
+	}
+	monitor := NewMonitor(cfg)
+	_, err := monitor.Renew(context.Background())
+	re.Equal(ErrRenewDisabled, errors.Cause(err))
+
+	monitor.check(context.Background(), now)
+	status := monitor.GetStatus()
+	re.Len(status.Certs, 1)
+	re.Equal(CertKindServer, status.Certs[0].Kind)
+	re.Equal("CN=pd", status.Certs[0].Subject)
+	re.False(status.Certs[0].Expiring)
+	re.InDelta(int64(90*24*time.Hour.Seconds()), status.Certs[0].Remaining, 1)
+
+	// The expiring certificate is renewed by the command.
+	writeCert(re, certPath, 2, now.Add(24*time.Hour))
+	monitor.config.RenewCommand = []string{"sh", "-c", "echo $" + envExpiring}
+	monitor.check(context.Background(), now)
+	status = monitor.GetStatus()
+	re.Len(status.Renewals, 1)
+	re.Empty(status.Renewals[0].Error)
+	re.Equal([]string{certPath}, status.Renewals[0].Expiring)
+	re.Contains(status.Renewals[0].Output, certPath)
+	re.True(status.Certs[0].Expiring)
+
+	// The failure of the command is recorded.
+	monitor.config.RenewCommand = []string{"sh", "-c", "exit 1"}
+	renewal, err := monitor.Renew(context.Background())
+	re.NoError(err)
+	re.NotEmpty(renewal.Error)
+	re.Len(monitor.GetStatus().Renewals, 2)
+
+	// The file without a certificate is reported.
+	re.NoError(os.WriteFile(certPath, []byte("invalid"), 0600))
+	monitor.check(context.Background(), now)
+	status = monitor.GetStatus()
+	re.Len(status.Certs, 1)
+	re.NotEmpty(status.Certs[0].Error)
+}
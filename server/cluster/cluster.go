@@ -34,6 +34,7 @@ import (
 	"github.com/tikv/pd/pkg/core"
 	"github.com/tikv/pd/pkg/core/storelimit"
 	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/event"
 	"github.com/tikv/pd/pkg/gctuner"
 	"github.com/tikv/pd/pkg/id"
 	"github.com/tikv/pd/pkg/memory"
@@ -85,6 +86,7 @@ const (
 	updateStoreStatsInterval     = 9 * time.Millisecond
 	clientTimeout                = 3 * time.Second
 	defaultChangedRegionsLimit   = 10000
+	defaultEventHubCapacity      = 10000
 	gcTombstoreInterval          = 30 * 24 * time.Hour
 	// persistLimitRetryTimes is used to reduce the probability of the persistent error
 	// since the once the store is add or remove, we shouldn't return an error even if the store limit is failed to persist.
@@ -154,6 +156,15 @@ type RaftCluster struct {
 	progressManager          *progress.Manager
 	regionSyncer             *syncer.RegionSyncer
 	changedRegions           chan *core.RegionInfo
+	splitRecorder            *splitRecorder
+	slowStoreRecorder        *slowStoreRecorder
+	regionAuditor            regionAuditor
+	eventHub                 *event.Hub
+	// downStores are the stores reported down, only accessed by checkStores.
+	downStores map[uint64]struct{}
+	// smoothingSource is the schedule config which the flow smoothing windows
+	// are applied from, only accessed with the cluster lock held.
+	smoothingSource *config.ScheduleConfig
 }
 
 // Status saves some state information.
@@ -176,6 +187,18 @@ func NewRaftCluster(ctx context.Context, clusterID uint64, regionSyncer *syncer.
 	}
 }
 
+// NewFollowerCluster creates a cluster which is never started. It only serves the
+// read-only queries of regions and stores from the cache synced from the leader.
+func NewFollowerCluster(ctx context.Context, clusterID uint64, opt *config.PersistOptions, storage storage.Storage, basicCluster *core.BasicCluster) *RaftCluster {
+	return &RaftCluster{
+		serverCtx: ctx,
+		clusterID: clusterID,
+		core:      basicCluster,
+		opt:       opt,
+		storage:   storage,
+	}
+}
+
 // GetStoreConfig returns the store config.
 func (c *RaftCluster) GetStoreConfig() *config.StoreConfig {
 	return c.storeConfigManager.GetStoreConfig()
@@ -241,6 +264,10 @@ func (c *RaftCluster) InitCluster(
 	c.changedRegions = make(chan *core.RegionInfo, defaultChangedRegionsLimit)
 	c.prevStoreLimit = make(map[uint64]map[storelimit.Type]float64)
 	c.unsafeRecoveryController = newUnsafeRecoveryController(c)
+	c.splitRecorder = newSplitRecorder(c.ctx, DefaultSplitRecordLimit)
+	c.slowStoreRecorder = newSlowStoreRecorder(DefaultSlowStoreEvictionLimit)
+	c.eventHub = event.NewHub(defaultEventHubCapacity)
+	c.downStores = make(map[uint64]struct{})
 }
 
 // Start starts a cluster.
@@ -263,6 +290,7 @@ func (c *RaftCluster) Start(s Server) error {
 	}
 
 	c.ruleManager = placement.NewRuleManager(c.storage, c, c.GetOpts())
+	c.ruleManager.SetEventHub(c.eventHub)
 	if c.opt.IsPlacementRulesEnabled() {
 		err = c.ruleManager.Initialize(c.opt.GetMaxReplicas(), c.opt.GetLocationLabels())
 		if err != nil {
@@ -274,6 +302,7 @@ func (c *RaftCluster) Start(s Server) error {
 	if err != nil {
 		return err
 	}
+	c.regionLabeler.SetEventHub(c.eventHub)
 
 	c.replicationMode, err = replication.NewReplicationModeManager(s.GetConfig().ReplicationMode, c.storage, cluster, s)
 	if err != nil {
@@ -288,7 +317,7 @@ func (c *RaftCluster) Start(s Server) error {
 		log.Error("load external timestamp meets error", zap.Error(err))
 	}
 
-	c.wg.Add(10)
+	c.wg.Add(11)
 	go c.runCoordinator()
 	go c.runMetricsCollectionJob()
 	go c.runNodeStateCheckJob()
@@ -299,6 +328,7 @@ func (c *RaftCluster) Start(s Server) error {
 	go c.runSyncConfig()
 	go c.runUpdateStoreStats()
 	go c.startGCTuner()
+	go c.runRegionAuditJob()
 
 	c.running.Store(true)
 	return nil
@@ -732,6 +762,11 @@ func (c *RaftCluster) SetStorage(s storage.Storage) {
 	c.storage = s
 }
 
+// GetEventHub returns the hub of the cluster events.
+func (c *RaftCluster) GetEventHub() *event.Hub {
+	return c.eventHub
+}
+
 // GetOpts returns cluster's configuration.
 // There is no need a lock since it won't changed.
 func (c *RaftCluster) GetOpts() *config.PersistOptions {
@@ -782,6 +817,24 @@ func (c *RaftCluster) ClearSuspectKeyRanges() {
 	c.coordinator.checkers.ClearSuspectKeyRanges()
 }
 
+// updateFlowSmoothingConfig applies the flow smoothing windows in the schedule
+// config to the statistics if the schedule config is changed. The schedule
+// config is always replaced rather than modified in place, so it is enough
+// to compare the pointers.
+func (c *RaftCluster) updateFlowSmoothingConfig() {
+	cfg := c.opt.GetScheduleConfig()
+	if cfg == c.smoothingSource {
+		return
+	}
+	c.smoothingSource = cfg
+	c.hotStat.SetFlowSmoothingConfig(statistics.FlowSmoothingConfig{
+		AotSize:           c.opt.GetFlowAotSize(),
+		StoreWindowSize:   c.opt.GetStoreFlowWindowSize(),
+		HotPeerWindowSize: c.opt.GetHotPeerFlowWindowSize(),
+		Decay:             c.opt.GetFlowSmoothingDecay(),
+	})
+}
+
 // HandleStoreHeartbeat updates the store status.
 func (c *RaftCluster) HandleStoreHeartbeat(heartbeat *pdpb.StoreHeartbeatRequest, resp *pdpb.StoreHeartbeatResponse) error {
 	stats := heartbeat.GetStats()
@@ -823,6 +876,7 @@ func (c *RaftCluster) HandleStoreHeartbeat(heartbeat *pdpb.StoreHeartbeatRequest
 		statistics.UpdateStoreHeartbeatMetrics(store)
 	}
 	c.core.PutStore(newStore)
+	c.updateFlowSmoothingConfig()
 	c.hotStat.Observe(storeID, newStore.GetStoreStats())
 	c.hotStat.FilterUnhealthyStore(c)
 	reportInterval := stats.GetInterval()
@@ -890,6 +944,13 @@ func (c *RaftCluster) processReportBuckets(buckets *metapb.Buckets) error {
 			time.Sleep(500 * time.Millisecond)
 		})
 		if ok := region.UpdateBuckets(buckets, old); ok {
+			c.eventHub.Publish(&event.Event{
+				Type:     event.BucketsChanged,
+				RegionID: buckets.GetRegionId(),
+				Attributes: map[string]string{
+					"version": strconv.FormatUint(buckets.GetVersion(), 10),
+				},
+			})
 			return nil
 		}
 	}
@@ -952,6 +1013,17 @@ func (c *RaftCluster) processRegionHeartbeat(region *core.RegionInfo) error {
 		if overlaps, err = c.core.AtomicCheckAndPutRegion(region); err != nil {
 			return err
 		}
+		if from, to := origin.GetLeader().GetStoreId(), region.GetLeader().GetStoreId(); from != 0 && to != 0 && from != to {
+			c.eventHub.Publish(&event.Event{
+				Type:     event.LeaderChanged,
+				StoreID:  to,
+				RegionID: region.GetID(),
+				Attributes: map[string]string{
+					"from_store": strconv.FormatUint(from, 10),
+					"to_store":   strconv.FormatUint(to, 10),
+				},
+			})
+		}
 
 		for _, item := range overlaps {
 			if c.regionStats != nil {
@@ -1469,23 +1541,59 @@ func (c *RaftCluster) ResumeLeaderTransfer(storeID uint64) {
 // SlowStoreEvicted marks a store as a slow store and prevents transferring
 // leader to the store
 func (c *RaftCluster) SlowStoreEvicted(storeID uint64) error {
-	return c.core.SlowStoreEvicted(storeID)
+	if err := c.core.SlowStoreEvicted(storeID); err != nil {
+		return err
+	}
+	c.onSlowStoreEvicted(SlowStoreDetectorScore, storeID)
+	return nil
 }
 
 // SlowTrendEvicted marks a store as a slow store by trend and prevents transferring
 // leader to the store
 func (c *RaftCluster) SlowTrendEvicted(storeID uint64) error {
-	return c.core.SlowTrendEvicted(storeID)
+	if err := c.core.SlowTrendEvicted(storeID); err != nil {
+		return err
+	}
+	c.onSlowStoreEvicted(SlowStoreDetectorTrend, storeID)
+	return nil
 }
 
 // SlowTrendRecovered cleans the evicted by slow trend state of a store.
 func (c *RaftCluster) SlowTrendRecovered(storeID uint64) {
 	c.core.SlowTrendRecovered(storeID)
+	c.onSlowStoreRecovered(SlowStoreDetectorTrend, storeID)
 }
 
 // SlowStoreRecovered cleans the evicted state of a store.
 func (c *RaftCluster) SlowStoreRecovered(storeID uint64) {
 	c.core.SlowStoreRecovered(storeID)
+	c.onSlowStoreRecovered(SlowStoreDetectorScore, storeID)
+}
+
+func (c *RaftCluster) onSlowStoreEvicted(detector string, storeID uint64) {
+	store := c.GetStore(storeID)
+	c.slowStoreRecorder.recordEvicted(detector, storeID, store.GetSlowScore(), store.GetSlowTrend())
+	c.eventHub.Publish(&event.Event{
+		Type:       event.StoreSlowEvicted,
+		StoreID:    storeID,
+		Attributes: map[string]string{"detector": detector},
+	})
+}
+
+func (c *RaftCluster) onSlowStoreRecovered(detector string, storeID uint64) {
+	if c.slowStoreRecorder.recordRecovered(detector, storeID) {
+		c.eventHub.Publish(&event.Event{
+			Type:       event.StoreSlowRecovered,
+			StoreID:    storeID,
+			Attributes: map[string]string{"detector": detector},
+		})
+	}
+}
+
+// GetSlowStoreEvictions returns the recent slow store evictions from the oldest
+// to the latest. If storeID is not zero, only the evictions of the store are returned.
+func (c *RaftCluster) GetSlowStoreEvictions(storeID uint64) []*SlowStoreEviction {
+	return c.slowStoreRecorder.getRecords(storeID)
 }
 
 // NeedAwakenAllRegionsInStore checks whether we should do AwakenRegions operation.
@@ -1617,7 +1725,19 @@ func (c *RaftCluster) putStoreLocked(store *core.StoreInfo) error {
 			return err
 		}
 	}
+	old := c.core.GetStore(store.GetID())
 	c.core.PutStore(store)
+	if old == nil || old.GetState() != store.GetState() || old.GetNodeState() != store.GetNodeState() {
+		attributes := map[string]string{
+			"state":      store.GetState().String(),
+			"node_state": store.GetNodeState().String(),
+		}
+		if old != nil {
+			attributes["old_state"] = old.GetState().String()
+			attributes["old_node_state"] = old.GetNodeState().String()
+		}
+		c.eventHub.Publish(&event.Event{Type: event.StoreStateChanged, StoreID: store.GetID(), Attributes: attributes})
+	}
 	c.hotStat.GetOrCreateRollingStoreStats(store.GetID())
 	return nil
 }
@@ -1630,6 +1750,7 @@ func (c *RaftCluster) checkStores() {
 	for _, store := range stores {
 		// the store has already been tombstone
 		if store.IsRemoved() {
+			delete(c.downStores, store.GetID())
 			if store.DownTime() > gcTombstoreInterval {
 				err := c.deleteStore(store)
 				if err != nil {
@@ -1645,6 +1766,7 @@ func (c *RaftCluster) checkStores() {
 		}
 
 		storeID := store.GetID()
+		c.checkStoreDown(store)
 		if store.IsPreparing() {
 			if store.GetUptime() >= c.opt.GetMaxStorePreparingTime() || c.GetRegionCount() < core.InitClusterRegionThreshold {
 				if err := c.ReadyToServe(storeID); err != nil {
@@ -1709,6 +1831,24 @@ func (c *RaftCluster) checkStores() {
 	}
 }
 
+// checkStoreDown publishes the event if the store becomes down or up.
+func (c *RaftCluster) checkStoreDown(store *core.StoreInfo) {
+	_, reported := c.downStores[store.GetID()]
+	isDown := store.DownTime() > c.opt.GetMaxStoreDownTime()
+	switch {
+	case isDown && !reported:
+		c.downStores[store.GetID()] = struct{}{}
+		c.eventHub.Publish(&event.Event{
+			Type:       event.StoreDown,
+			StoreID:    store.GetID(),
+			Attributes: map[string]string{"down_time": store.DownTime().String()},
+		})
+	case !isDown && reported:
+		delete(c.downStores, store.GetID())
+		c.eventHub.Publish(&event.Event{Type: event.StoreUp, StoreID: store.GetID()})
+	}
+}
+
 func (c *RaftCluster) getThreshold(stores []*core.StoreInfo, store *core.StoreInfo) float64 {
 	start := time.Now()
 	if !c.opt.IsPlacementRulesEnabled() {
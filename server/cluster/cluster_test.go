@@ -344,6 +344,40 @@ func TestSetOfflineStoreWithEvictLeader(t *testing.T) {
 	re.NoError(cluster.RemoveStore(3, false))
 }
 
+func TestSlowStoreEvictions(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, opt, err := newTestScheduleConfig()
+	re.NoError(err)
+	cluster := newTestRaftCluster(ctx, mockid.NewIDAllocator(), opt, storage.NewStorageWithMemoryBackend(), core.NewBasicCluster())
+	for _, store := range newTestStores(2, "2.0.0") {
+		re.NoError(cluster.PutStore(store.GetMeta()))
+	}
+
+	re.NoError(cluster.SlowStoreEvicted(1))
+	// Evicting the evicted store again fails and is not recorded.
+	re.Error(cluster.SlowStoreEvicted(1))
+	re.NoError(cluster.SlowTrendEvicted(2))
+	evictions := cluster.GetSlowStoreEvictions(0)
+	re.Len(evictions, 2)
+	re.Equal(uint64(1), evictions[0].StoreID)
+	re.Equal(SlowStoreDetectorScore, evictions[0].Detector)
+	re.Nil(evictions[0].EndTime)
+	re.Equal(SlowStoreDetectorTrend, evictions[1].Detector)
+
+	// Only the evicted store is recovered.
+	cluster.SlowStoreRecovered(1)
+	cluster.SlowStoreRecovered(2)
+	evictions = cluster.GetSlowStoreEvictions(1)
+	re.Len(evictions, 1)
+	re.NotNil(evictions[0].EndTime)
+	evictions = cluster.GetSlowStoreEvictions(2)
+	re.Len(evictions, 1)
+	re.Nil(evictions[0].EndTime)
+}
+
 func TestForceBuryStore(t *testing.T) {
 	re := require.New(t)
 	ctx, cancel := context.WithCancel(context.Background())
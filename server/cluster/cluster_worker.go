@@ -16,6 +16,7 @@ package cluster
 
 import (
 	"bytes"
+	"time"
 
 	"github.com/pingcap/errors"
 	"github.com/pingcap/kvproto/pkg/metapb"
@@ -27,6 +28,7 @@ import (
 	"github.com/tikv/pd/pkg/utils/typeutil"
 	"github.com/tikv/pd/pkg/versioninfo"
 	"github.com/tikv/pd/server/schedule"
+	"github.com/tikv/pd/server/schedule/operator"
 	"github.com/tikv/pd/server/statistics/buckets"
 	"go.uber.org/zap"
 )
@@ -76,6 +78,9 @@ func (c *RaftCluster) HandleAskSplit(request *pdpb.AskSplitRequest) (*pdpb.AskSp
 		c.GetMergeChecker().RecordRegionSplit([]uint64{reqRegion.GetId(), newRegionID})
 	}
 
+	reason, policy := c.getSplitReason(reqRegion.GetId())
+	c.splitRecorder.recordAsk(SplitEventSplit, reason, policy, reqRegion.GetId(), []uint64{newRegionID})
+
 	split := &pdpb.AskSplitResponse{
 		NewRegionId: newRegionID,
 		NewPeerIds:  peerIDs,
@@ -145,6 +150,9 @@ func (c *RaftCluster) HandleAskBatchSplit(request *pdpb.AskBatchSplitRequest) (*
 		log.Info("alloc ids for region split", zap.Uint64("region-id", newRegionID), zap.Uint64s("peer-ids", peerIDs))
 	}
 
+	reason, policy := c.getSplitReason(reqRegion.GetId())
+	c.splitRecorder.recordAsk(SplitEventBatchSplit, reason, policy, reqRegion.GetId(), append([]uint64(nil), recordRegions...))
+
 	recordRegions = append(recordRegions, reqRegion.GetId())
 	if versioninfo.IsFeatureSupported(c.GetOpts().GetClusterVersion(), versioninfo.RegionMerge) {
 		// Disable merge the regions in a period of time.
@@ -161,6 +169,32 @@ func (c *RaftCluster) HandleAskBatchSplit(request *pdpb.AskBatchSplitRequest) (*
 	return resp, nil
 }
 
+// getSplitReason returns the description and the check policy of the split
+// operator of the region. If the split is not triggered by PD, it returns
+// SplitReasonUnknown and an empty policy.
+func (c *RaftCluster) getSplitReason(regionID uint64) (reason, policy string) {
+	if c.coordinator == nil {
+		return SplitReasonUnknown, ""
+	}
+	op := c.coordinator.opController.GetOperator(regionID)
+	if op == nil || op.Kind()&operator.OpSplit == 0 {
+		return SplitReasonUnknown, ""
+	}
+	for i := 0; i < op.Len(); i++ {
+		if step, ok := op.Step(i).(operator.SplitRegion); ok {
+			policy = step.Policy.String()
+			break
+		}
+	}
+	return op.Desc(), policy
+}
+
+// GetSplitRecords returns at most limit split records reported since from.
+// If regionID is not zero, only the records related to the region are returned.
+func (c *RaftCluster) GetSplitRecords(from time.Time, regionID uint64, limit int) []*SplitRecord {
+	return c.splitRecorder.getRecords(from, regionID, limit)
+}
+
 func (c *RaftCluster) checkSplitRegion(left *metapb.Region, right *metapb.Region) error {
 	if left == nil || right == nil {
 		return errors.New("invalid split region")
@@ -213,6 +247,7 @@ func (c *RaftCluster) HandleReportSplit(request *pdpb.ReportSplitRequest) (*pdpb
 	originRegion := typeutil.DeepClone(right, core.RegionFactory)
 	originRegion.RegionEpoch = nil
 	originRegion.StartKey = left.GetStartKey()
+	c.splitRecorder.recordReport(SplitEventSplit, []uint64{left.GetId(), right.GetId()})
 	log.Info("region split, generate new region",
 		zap.Uint64("region-id", originRegion.GetId()),
 		logutil.ZapRedactStringer("region-meta", core.RegionToHexMeta(left)))
@@ -231,6 +266,11 @@ func (c *RaftCluster) HandleBatchReportSplit(request *pdpb.ReportBatchSplitReque
 			errs.ZapError(err))
 		return nil, err
 	}
+	regionIDs := make([]uint64, 0, len(regions))
+	for _, region := range regions {
+		regionIDs = append(regionIDs, region.GetId())
+	}
+	c.splitRecorder.recordReport(SplitEventBatchSplit, regionIDs)
 	last := len(regions) - 1
 	originRegion := typeutil.DeepClone(regions[last], core.RegionFactory)
 	hrm = core.RegionsToHexMeta(regions[:last])
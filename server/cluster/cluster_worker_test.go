@@ -17,6 +17,7 @@ package cluster
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
@@ -59,3 +60,94 @@ func TestReportBatchSplit(t *testing.T) {
 	_, err = cluster.HandleBatchReportSplit(&pdpb.ReportBatchSplitRequest{Regions: regions})
 	re.NoError(err)
 }
+
+func TestSplitRecords(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, opt, err := newTestScheduleConfig()
+	re.NoError(err)
+	cluster := newTestRaftCluster(ctx, mockid.NewIDAllocator(), opt, storage.NewStorageWithMemoryBackend(), core.NewBasicCluster())
+	cluster.coordinator = newCoordinator(ctx, cluster, nil)
+	peer := &metapb.Peer{Id: 11, StoreId: 1}
+	origin := &metapb.Region{
+		Id:          10,
+		StartKey:    []byte("a"),
+		EndKey:      []byte("c"),
+		Peers:       []*metapb.Peer{peer},
+		RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+	}
+	re.NoError(cluster.putRegion(core.NewRegionInfo(origin, peer)))
+
+	// The retried ask should not overwrite the previous one.
+	resp1, err := cluster.HandleAskSplit(&pdpb.AskSplitRequest{Region: origin})
+	re.NoError(err)
+	time.Sleep(10 * time.Millisecond)
+	resp2, err := cluster.HandleAskSplit(&pdpb.AskSplitRequest{Region: origin})
+	re.NoError(err)
+	re.NotEqual(resp1.GetNewRegionId(), resp2.GetNewRegionId())
+
+	// The report uses the IDs of the first ask.
+	left := &metapb.Region{Id: resp1.GetNewRegionId(), StartKey: []byte("a"), EndKey: []byte("b")}
+	right := &metapb.Region{Id: origin.GetId(), StartKey: []byte("b"), EndKey: []byte("c")}
+	_, err = cluster.HandleReportSplit(&pdpb.ReportSplitRequest{Left: left, Right: right})
+	re.NoError(err)
+	records := cluster.GetSplitRecords(time.Time{}, 0, 0)
+	re.Len(records, 1)
+	re.Equal(SplitEventSplit, records[0].Type)
+	re.Equal(SplitReasonUnknown, records[0].Reason)
+	re.Equal(origin.GetId(), records[0].SourceRegionID)
+	re.Equal([]uint64{resp1.GetNewRegionId()}, records[0].NewRegionIDs)
+	re.NotNil(records[0].AskTime)
+	re.NotNil(records[0].Duration)
+	re.Greater(*records[0].Duration, int64(0))
+
+	// The report without a matched ask uses the last region as the source.
+	regions := []*metapb.Region{
+		{Id: 21, StartKey: []byte("c"), EndKey: []byte("d")},
+		{Id: 22, StartKey: []byte("d"), EndKey: []byte("e")},
+		{Id: 20, StartKey: []byte("e"), EndKey: []byte("f")},
+	}
+	_, err = cluster.HandleBatchReportSplit(&pdpb.ReportBatchSplitRequest{Regions: regions})
+	re.NoError(err)
+	records = cluster.GetSplitRecords(time.Time{}, 0, 0)
+	re.Len(records, 2)
+	re.Equal(SplitEventBatchSplit, records[1].Type)
+	re.Equal(SplitReasonUnknown, records[1].Reason)
+	re.Equal(uint64(20), records[1].SourceRegionID)
+	re.Equal([]uint64{21, 22}, records[1].NewRegionIDs)
+	re.Nil(records[1].AskTime)
+	re.Nil(records[1].Duration)
+
+	// Filter by region ID, time and limit.
+	records = cluster.GetSplitRecords(time.Time{}, 22, 0)
+	re.Len(records, 1)
+	re.Equal(uint64(20), records[0].SourceRegionID)
+	records = cluster.GetSplitRecords(time.Time{}, origin.GetId(), 0)
+	re.Len(records, 1)
+	re.Equal(origin.GetId(), records[0].SourceRegionID)
+	re.Empty(cluster.GetSplitRecords(time.Time{}, 100, 0))
+	re.Empty(cluster.GetSplitRecords(time.Now().Add(time.Minute), 0, 0))
+	re.Len(cluster.GetSplitRecords(time.Now().Add(-time.Minute), 0, 0), 2)
+	records = cluster.GetSplitRecords(time.Time{}, 0, 1)
+	re.Len(records, 1)
+	re.Equal(uint64(20), records[0].SourceRegionID)
+}
+
+func TestSplitRecordsLimit(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	limit := 10
+	recorder := newSplitRecorder(ctx, limit)
+	for i := 1; i <= limit*2; i++ {
+		recorder.recordReport(SplitEventSplit, []uint64{uint64(i * 2), uint64(i*2 + 1)})
+	}
+	records := recorder.getRecords(time.Time{}, 0, 0)
+	re.Len(records, limit)
+	// The oldest records are evicted.
+	re.Equal(uint64(limit*2+3), records[0].SourceRegionID)
+	re.Equal(uint64(limit*4+1), records[limit-1].SourceRegionID)
+}
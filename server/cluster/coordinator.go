@@ -87,6 +87,7 @@ type coordinator struct {
 func newCoordinator(ctx context.Context, cluster *RaftCluster, hbStreams *hbstream.HeartbeatStreams) *coordinator {
 	ctx, cancel := context.WithCancel(ctx)
 	opController := schedule.NewOperatorController(ctx, cluster, hbStreams)
+	opController.SetEventHub(cluster.eventHub)
 	schedulers := make(map[string]*scheduleController)
 	return &coordinator{
 		ctx:               ctx,
@@ -416,6 +417,9 @@ func (c *coordinator) run() {
 		log.Error("cannot persist schedule config", errs.ZapError(err))
 	}
 
+	// Continues the scheduling handed over by the previous leader.
+	c.restoreSchedulingHandoff()
+
 	c.wg.Add(3)
 	// Starts to patrol regions.
 	go c.patrolRegions()
@@ -978,6 +978,60 @@ func TestRestart(t *testing.T) {
 	waitPromoteLearner(re, stream, region, 3)
 }
 
+func TestSchedulingHandoff(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tc, co, cleanup := prepare(func(cfg *config.ScheduleConfig) {
+		// Turn off balance, we test add replica only.
+		cfg.LeaderScheduleLimit = 0
+		cfg.RegionScheduleLimit = 0
+	}, nil, func(co *coordinator) { co.run() }, re)
+	hbStreams := co.hbStreams
+	defer cleanup()
+
+	re.NoError(tc.addRegionStore(1, 1))
+	re.NoError(tc.addRegionStore(2, 2))
+	re.NoError(tc.addLeaderRegion(1, 1))
+	region := tc.GetRegion(1)
+	co.prepareChecker.collect(region)
+
+	// The leader resigns after adding the learner on store 2.
+	stream := mockhbstream.NewHeartbeatStream()
+	re.NoError(dispatchHeartbeat(co, region, stream))
+	region = waitAddLearner(re, stream, region, 2)
+	op := co.opController.GetOperator(1)
+	re.NotNil(op)
+	re.NoError(co.pauseOrResumeScheduler(schedulers.BalanceLeaderName, 60))
+	re.NoError(co.saveSchedulingHandoff())
+	co.stop()
+	co.wg.Wait()
+
+	// The new leader continues the operator.
+	co = newCoordinator(ctx, tc.RaftCluster, hbStreams)
+	re.NoError(co.cluster.putRegion(region.Clone()))
+	co.prepareChecker.collect(region)
+	co.run()
+	restored := co.opController.GetOperator(1)
+	re.NotNil(restored)
+	re.NotSame(op, restored)
+	re.Equal(op.Desc(), restored.Desc())
+	re.Equal(op.Step(0).String(), restored.Step(0).String())
+	paused, err := co.isSchedulerPaused(schedulers.BalanceLeaderName)
+	re.NoError(err)
+	re.True(paused)
+	re.NoError(dispatchHeartbeat(co, region, stream))
+	waitPromoteLearner(re, stream, region, 2)
+
+	// The handoff is removed after it is restored.
+	ok, err := tc.storage.LoadSchedulingHandoff(&schedulingHandoff{})
+	re.NoError(err)
+	re.False(ok)
+	co.stop()
+	co.wg.Wait()
+}
+
 func TestPauseScheduler(t *testing.T) {
 	re := require.New(t)
 
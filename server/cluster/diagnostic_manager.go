@@ -142,6 +142,7 @@ func (d *diagnosticRecorder) getLastResult() *DiagnosticResult {
 	}
 
 	var resStr string
+	var stores map[uint64]string
 	firstStatus := items[0].Value.(*DiagnosticResult).Status
 	if firstStatus == pending || firstStatus == normal {
 		wa := movingaverage.NewWeightAllocator(length, 3)
@@ -157,7 +158,7 @@ func (d *diagnosticRecorder) getLastResult() *DiagnosticResult {
 			}
 		}
 		statusCounter := make(map[plan.Status]uint64)
-		for _, store := range counter {
+		for storeID, store := range counter {
 			max := 0.
 			curStat := *plan.NewStatus(plan.StatusOK)
 			for stat, c := range store {
@@ -167,6 +168,10 @@ func (d *diagnosticRecorder) getLastResult() *DiagnosticResult {
 				}
 			}
 			statusCounter[curStat] += 1
+			if stores == nil {
+				stores = make(map[uint64]string)
+			}
+			stores[storeID] = curStat.String()
 		}
 		if len(statusCounter) > 0 {
 			for k, v := range statusCounter {
@@ -182,6 +187,7 @@ func (d *diagnosticRecorder) getLastResult() *DiagnosticResult {
 		Status:    firstStatus,
 		Summary:   resStr,
 		Timestamp: uint64(time.Now().Unix()),
+		Stores:    stores,
 	}
 }
 
@@ -232,6 +238,8 @@ type DiagnosticResult struct {
 	Status    string `json:"status"`
 	Summary   string `json:"summary"`
 	Timestamp uint64 `json:"timestamp"`
+	// Stores is the most frequent status of each store in the recent results.
+	Stores map[uint64]string `json:"stores,omitempty"`
 
 	StoreStatus map[uint64]plan.Status `json:"-"`
 }
@@ -128,6 +128,39 @@ var (
 			Help:      "The ETA of corresponding action",
 		}, []string{"address", "store", "action"})
 
+	splitDurationHist = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "pd",
+			Subsystem: "cluster",
+			Name:      "split_duration_seconds",
+			Help:      "Bucketed histogram of the time cost from asking split to reporting split.",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 14),
+		}, []string{"type"})
+
+	splitEventCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "cluster",
+			Name:      "split_event",
+			Help:      "Counter of the reported split events, result is matched if the split is paired with its ask.",
+		}, []string{"type", "result"})
+
+	slowStoreEvictionCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "cluster",
+			Name:      "slow_store_eviction",
+			Help:      "Counter of the slow store evictions and recoveries by each detector.",
+		}, []string{"detector", "event"})
+
+	regionInconsistencyGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "cluster",
+			Name:      "region_inconsistency",
+			Help:      "The number of region metadata inconsistencies found by the last audit.",
+		}, []string{"type"})
+
 	storeSyncConfigEvent = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: "pd",
@@ -152,4 +185,8 @@ func init() {
 	prometheus.MustRegister(storesETAGauge)
 	prometheus.MustRegister(storeSyncConfigEvent)
 	prometheus.MustRegister(updateStoreStatsGauge)
+	prometheus.MustRegister(splitDurationHist)
+	prometheus.MustRegister(splitEventCounter)
+	prometheus.MustRegister(slowStoreEvictionCounter)
+	prometheus.MustRegister(regionInconsistencyGauge)
 }
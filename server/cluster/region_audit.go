@@ -0,0 +1,163 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/core"
+	"github.com/tikv/pd/pkg/utils/logutil"
+	"github.com/tikv/pd/pkg/utils/syncutil"
+	"go.uber.org/zap"
+)
+
+const (
+	// regionAuditInterval is the interval to run the region consistency audit.
+	regionAuditInterval = 10 * time.Minute
+
+	// RegionUnknownStore means a region has a peer on a store which does not
+	// exist or has been tombstone.
+	RegionUnknownStore core.RegionInconsistencyType = "unknown-store"
+
+	removePeerOperator = "remove-peer"
+)
+
+var regionInconsistencyTypes = []core.RegionInconsistencyType{
+	core.RegionGap,
+	core.RegionEpochMismatch,
+	core.StoreRegionOverlap,
+	RegionUnknownStore,
+}
+
+// RepairOperator is the operator proposed to repair an inconsistency. It is
+// the same as the input of the operator API, so it can be created directly.
+type RepairOperator struct {
+	Name     string `json:"name"`
+	RegionID uint64 `json:"region_id"`
+	StoreID  uint64 `json:"store_id,omitempty"`
+}
+
+// RegionConsistencyIssue is an inconsistency found by the audit, with the
+// proposed operator to repair it. Repair is nil if the inconsistency can not
+// be repaired by an operator, e.g. a range gap needs the unsafe recovery.
+type RegionConsistencyIssue struct {
+	*core.RegionInconsistency
+	Repair *RepairOperator `json:"repair,omitempty"`
+}
+
+// RegionConsistencyReport is the result of a region consistency audit.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type RegionConsistencyReport struct {
+	AuditTime time.Time                 `json:"audit_time"`
+	Issues    []*RegionConsistencyIssue `json:"issues"`
+}
+
+type regionAuditor struct {
+	syncutil.RWMutex
+	report *RegionConsistencyReport
+}
+
+func (c *RaftCluster) runRegionAuditJob() {
+	defer logutil.LogPanic()
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(regionAuditInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			log.Info("region audit job has been stopped")
+			return
+		case <-ticker.C:
+			c.AuditRegionConsistency()
+		}
+	}
+}
+
+// AuditRegionConsistency cross-checks the region metadata against the ranges
+// reported by stores, and proposes the operators to repair what it finds.
+func (c *RaftCluster) AuditRegionConsistency() *RegionConsistencyReport {
+	report := &RegionConsistencyReport{AuditTime: time.Now()}
+	for _, inconsistency := range c.core.CheckConsistency() {
+		report.Issues = append(report.Issues, &RegionConsistencyIssue{
+			RegionInconsistency: inconsistency,
+			Repair:              c.proposeRepair(inconsistency),
+		})
+	}
+	for _, region := range c.GetRegions() {
+		for _, peer := range region.GetPeers() {
+			store := c.GetStore(peer.GetStoreId())
+			if store != nil && !store.IsRemoved() {
+				continue
+			}
+			report.Issues = append(report.Issues, &RegionConsistencyIssue{
+				RegionInconsistency: &core.RegionInconsistency{
+					Type:      RegionUnknownStore,
+					RegionIDs: []uint64{region.GetID()},
+					StoreID:   peer.GetStoreId(),
+					StartKey:  core.HexRegionKeyStr(region.GetStartKey()),
+					EndKey:    core.HexRegionKeyStr(region.GetEndKey()),
+				},
+				Repair: &RepairOperator{Name: removePeerOperator, RegionID: region.GetID(), StoreID: peer.GetStoreId()},
+			})
+		}
+	}
+
+	counts := make(map[core.RegionInconsistencyType]int)
+	for _, issue := range report.Issues {
+		counts[issue.Type]++
+	}
+	for _, typ := range regionInconsistencyTypes {
+		regionInconsistencyGauge.WithLabelValues(string(typ)).Set(float64(counts[typ]))
+	}
+	if len(report.Issues) > 0 {
+		log.Warn("region metadata inconsistency is found", zap.Int("count", len(report.Issues)))
+	}
+
+	c.regionAuditor.Lock()
+	defer c.regionAuditor.Unlock()
+	c.regionAuditor.report = report
+	return report
+}
+
+// GetRegionConsistencyReport returns the report of the last region
+// consistency audit, it is nil if the audit has not run yet.
+func (c *RaftCluster) GetRegionConsistencyReport() *RegionConsistencyReport {
+	c.regionAuditor.RLock()
+	defer c.regionAuditor.RUnlock()
+	return c.regionAuditor.report
+}
+
+// proposeRepair proposes to remove the stale peer if a store holds the peers
+// of two overlapping regions, which is the one of the older region.
+func (c *RaftCluster) proposeRepair(inconsistency *core.RegionInconsistency) *RepairOperator {
+	if inconsistency.Type != core.StoreRegionOverlap || len(inconsistency.RegionIDs) != 2 {
+		return nil
+	}
+	storeID := inconsistency.StoreID
+	first, second := c.GetRegion(inconsistency.RegionIDs[0]), c.GetRegion(inconsistency.RegionIDs[1])
+	if first == nil || second == nil || first.GetStorePeer(storeID) == nil || second.GetStorePeer(storeID) == nil {
+		// The stale peer is only in the cache, it will be cleaned by the
+		// next heartbeat of the region.
+		return nil
+	}
+	stale := first
+	if second.GetRegionEpoch().GetVersion() < first.GetRegionEpoch().GetVersion() {
+		stale = second
+	}
+	return &RepairOperator{Name: removePeerOperator, RegionID: stale.GetID(), StoreID: storeID}
+}
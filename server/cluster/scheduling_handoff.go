@@ -0,0 +1,140 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/server/schedule/operator"
+	"go.uber.org/zap"
+)
+
+// schedulingHandoffExpireTime is the max age of the scheduling handoff to be
+// restored, the new leader needs to wait for the region heartbeats before
+// restoring, and the older state is not worth restoring.
+const schedulingHandoffExpireTime = 10 * time.Minute
+
+// pausedScheduler is the pause state of a scheduler.
+type pausedScheduler struct {
+	DelayAt    int64 `json:"delay_at"`
+	DelayUntil int64 `json:"delay_until"`
+}
+
+// schedulingHandoff is the in-flight scheduling state handed over from the
+// resigned PD leader, so that the new leader can continue the long-running
+// operators rather than canceling and recomputing them.
+type schedulingHandoff struct {
+	SaveTime         time.Time                   `json:"save_time"`
+	Operators        []*operator.Snapshot        `json:"operators"`
+	PausedSchedulers map[string]*pausedScheduler `json:"paused_schedulers"`
+}
+
+// SaveSchedulingHandoff saves the in-flight scheduling state for the next PD
+// leader. It should be called before the leader resigns.
+func (c *RaftCluster) SaveSchedulingHandoff() error {
+	c.RLock()
+	defer c.RUnlock()
+	if !c.running.Load() {
+		return nil
+	}
+	return c.coordinator.saveSchedulingHandoff()
+}
+
+func (c *coordinator) saveSchedulingHandoff() error {
+	handoff := &schedulingHandoff{
+		SaveTime:         time.Now(),
+		PausedSchedulers: make(map[string]*pausedScheduler),
+	}
+	for _, op := range c.opController.GetOperators() {
+		if op.IsEnd() {
+			continue
+		}
+		snapshot, err := op.Snapshot()
+		if err != nil {
+			log.Warn("skip handing over operator", zap.Uint64("region-id", op.RegionID()), errs.ZapError(err))
+			continue
+		}
+		handoff.Operators = append(handoff.Operators, snapshot)
+	}
+	c.RLock()
+	for name, sc := range c.schedulers {
+		if sc.IsPaused() {
+			handoff.PausedSchedulers[name] = &pausedScheduler{
+				DelayAt:    atomic.LoadInt64(&sc.delayAt),
+				DelayUntil: atomic.LoadInt64(&sc.delayUntil),
+			}
+		}
+	}
+	c.RUnlock()
+	if err := c.cluster.storage.SaveSchedulingHandoff(handoff); err != nil {
+		return err
+	}
+	log.Info("scheduling state is handed over",
+		zap.Int("operators", len(handoff.Operators)),
+		zap.Int("paused-schedulers", len(handoff.PausedSchedulers)))
+	return nil
+}
+
+// restoreSchedulingHandoff restores the scheduling state handed over by the
+// previous PD leader. It is called after the schedulers are added and the
+// region heartbeats are collected, and the handoff is removed afterwards.
+func (c *coordinator) restoreSchedulingHandoff() {
+	handoff := &schedulingHandoff{}
+	ok, err := c.cluster.storage.LoadSchedulingHandoff(handoff)
+	if err != nil {
+		log.Error("failed to load scheduling handoff", errs.ZapError(err))
+		return
+	}
+	if !ok {
+		return
+	}
+	if err := c.cluster.storage.RemoveSchedulingHandoff(); err != nil {
+		log.Error("failed to remove scheduling handoff", errs.ZapError(err))
+		return
+	}
+	if time.Since(handoff.SaveTime) > schedulingHandoffExpireTime {
+		log.Info("scheduling handoff is expired", zap.Time("save-time", handoff.SaveTime))
+		return
+	}
+
+	now := time.Now().Unix()
+	c.RLock()
+	for name, paused := range handoff.PausedSchedulers {
+		sc, ok := c.schedulers[name]
+		if !ok || paused.DelayUntil <= now {
+			continue
+		}
+		atomic.StoreInt64(&sc.delayAt, paused.DelayAt)
+		atomic.StoreInt64(&sc.delayUntil, paused.DelayUntil)
+	}
+	c.RUnlock()
+
+	ops := make([]*operator.Operator, 0, len(handoff.Operators))
+	for _, snapshot := range handoff.Operators {
+		op, err := snapshot.Restore()
+		if err != nil {
+			log.Warn("failed to restore operator", zap.Uint64("region-id", snapshot.RegionID), errs.ZapError(err))
+			continue
+		}
+		ops = append(ops, op)
+	}
+	restored := c.opController.RestoreOperator(ops...)
+	log.Info("scheduling state is restored",
+		zap.Int("operators", restored),
+		zap.Int("handed-over-operators", len(handoff.Operators)))
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/tikv/pd/pkg/cache"
+	"github.com/tikv/pd/pkg/utils/syncutil"
+)
+
+const (
+	// SlowStoreDetectorScore is the detector evicting the store whose slow
+	// score reported by TiKV is too high.
+	SlowStoreDetectorScore = "slow-score"
+	// SlowStoreDetectorTrend is the detector evicting the store whose latency
+	// trend is much slower than the other stores.
+	SlowStoreDetectorTrend = "slow-trend"
+	// DefaultSlowStoreEvictionLimit is the max number of slow store evictions kept in memory.
+	DefaultSlowStoreEvictionLimit = 256
+)
+
+// SlowStoreEviction is the record of evicting the leaders from a slow store.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type SlowStoreEviction struct {
+	StoreID  uint64 `json:"store_id"`
+	Detector string `json:"detector"`
+	// SlowScore and SlowTrend are the statistics of the store when it is evicted.
+	SlowScore uint64          `json:"slow_score"`
+	SlowTrend *pdpb.SlowTrend `json:"slow_trend,omitempty"`
+	StartTime time.Time       `json:"start_time"`
+	// EndTime is nil if the store is still evicted.
+	EndTime *time.Time `json:"end_time,omitempty"`
+}
+
+type slowStoreKey struct {
+	storeID  uint64
+	detector string
+}
+
+// slowStoreRecorder records the slow store evictions of the current leader.
+type slowStoreRecorder struct {
+	mu      syncutil.Mutex
+	records *cache.FIFO
+	seq     uint64
+	// evicting are the records of the stores which are still evicted.
+	evicting map[slowStoreKey]*SlowStoreEviction
+}
+
+func newSlowStoreRecorder(limit int) *slowStoreRecorder {
+	return &slowStoreRecorder{
+		records:  cache.NewFIFO(limit),
+		evicting: make(map[slowStoreKey]*SlowStoreEviction),
+	}
+}
+
+// recordEvicted starts a record of the store evicted by the detector.
+func (r *slowStoreRecorder) recordEvicted(detector string, storeID, slowScore uint64, slowTrend *pdpb.SlowTrend) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := slowStoreKey{storeID: storeID, detector: detector}
+	if _, ok := r.evicting[key]; ok {
+		return
+	}
+	record := &SlowStoreEviction{
+		StoreID:   storeID,
+		Detector:  detector,
+		SlowScore: slowScore,
+		SlowTrend: slowTrend,
+		StartTime: time.Now(),
+	}
+	r.evicting[key] = record
+	r.seq++
+	r.records.Put(r.seq, record)
+	slowStoreEvictionCounter.WithLabelValues(detector, "evict").Inc()
+}
+
+// recordRecovered finishes the record of the store evicted by the detector,
+// it returns false if the store is not evicted by the detector.
+func (r *slowStoreRecorder) recordRecovered(detector string, storeID uint64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := slowStoreKey{storeID: storeID, detector: detector}
+	record, ok := r.evicting[key]
+	if !ok {
+		return false
+	}
+	delete(r.evicting, key)
+	now := time.Now()
+	record.EndTime = &now
+	slowStoreEvictionCounter.WithLabelValues(detector, "recover").Inc()
+	return true
+}
+
+// getRecords returns the copies of the records from the oldest to the latest.
+// If storeID is not zero, only the records of the store are returned.
+func (r *slowStoreRecorder) getRecords(storeID uint64) []*SlowStoreEviction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	elems := r.records.Elems()
+	records := make([]*SlowStoreEviction, 0, len(elems))
+	for _, elem := range elems {
+		record := *elem.Value.(*SlowStoreEviction)
+		if storeID != 0 && record.StoreID != storeID {
+			continue
+		}
+		records = append(records, &record)
+	}
+	return records
+}
@@ -0,0 +1,175 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"time"
+
+	"github.com/tikv/pd/pkg/cache"
+	"github.com/tikv/pd/pkg/utils/syncutil"
+)
+
+const (
+	// SplitReasonUnknown means the split is not triggered by a PD operator.
+	// TiKV does not report why it splits a region, so the reason is unknown.
+	SplitReasonUnknown = "unknown"
+
+	splitPendingGCInterval = time.Minute
+	// splitPendingTTL is the max time to wait for the report of an asked split.
+	splitPendingTTL = 10 * time.Minute
+	// DefaultSplitRecordLimit is the max number of finished split records kept in memory.
+	DefaultSplitRecordLimit = 1024
+)
+
+// SplitEventType is the type of a split event.
+type SplitEventType string
+
+const (
+	// SplitEventSplit is the event type of a single split.
+	SplitEventSplit SplitEventType = "split"
+	// SplitEventBatchSplit is the event type of a batch split.
+	SplitEventBatchSplit SplitEventType = "batch-split"
+)
+
+// SplitRecord is the record of a region split.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type SplitRecord struct {
+	Type SplitEventType `json:"type"`
+	// Reason is the description of the PD operator which triggers the split,
+	// or SplitReasonUnknown if the split is not triggered by PD.
+	Reason string `json:"reason"`
+	// Policy is the check policy of the PD split operator, if any.
+	Policy         string   `json:"policy,omitempty"`
+	SourceRegionID uint64   `json:"source_region_id"`
+	NewRegionIDs   []uint64 `json:"new_region_ids"`
+	// AskTime and Duration are nil if the ask request is not handled by the
+	// current leader, because the split can not be paired with its ask then.
+	AskTime    *time.Time `json:"ask_time,omitempty"`
+	ReportTime time.Time  `json:"report_time"`
+	// Duration is the time cost from asking to reporting, in milliseconds.
+	Duration *int64 `json:"duration_ms,omitempty"`
+}
+
+// splitRecorder records the split events reported by TiKV.
+type splitRecorder struct {
+	// mu makes sure that a pending split is paired with only one report.
+	mu syncutil.Mutex
+	// pending keeps the asked splits which are waiting for the report, keyed
+	// by each of the allocated new region IDs. So every retried ask of the same
+	// source region is kept separately and the report is paired with the ask
+	// whose IDs are really used.
+	pending *cache.TTLUint64
+	records *cache.FIFO
+	seq     uint64
+}
+
+func newSplitRecorder(ctx context.Context, limit int) *splitRecorder {
+	return &splitRecorder{
+		pending: cache.NewIDTTL(ctx, splitPendingGCInterval, splitPendingTTL),
+		records: cache.NewFIFO(limit),
+	}
+}
+
+// recordAsk records the IDs allocated for a split.
+func (r *splitRecorder) recordAsk(typ SplitEventType, reason, policy string, sourceRegionID uint64, newRegionIDs []uint64) {
+	askTime := time.Now()
+	record := &SplitRecord{
+		Type:           typ,
+		Reason:         reason,
+		Policy:         policy,
+		SourceRegionID: sourceRegionID,
+		NewRegionIDs:   newRegionIDs,
+		AskTime:        &askTime,
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range newRegionIDs {
+		r.pending.Put(id, record)
+	}
+}
+
+// recordReport finishes the split record which matches one of the reported regions.
+func (r *splitRecorder) recordReport(typ SplitEventType, regionIDs []uint64) {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var record *SplitRecord
+	for _, id := range regionIDs {
+		if v, ok := r.pending.Get(id); ok {
+			record = v.(*SplitRecord)
+			break
+		}
+	}
+	if record != nil {
+		for _, id := range record.NewRegionIDs {
+			r.pending.Remove(id)
+		}
+		cost := now.Sub(*record.AskTime)
+		duration := cost.Milliseconds()
+		record.Duration = &duration
+		splitDurationHist.WithLabelValues(string(record.Type)).Observe(cost.Seconds())
+		splitEventCounter.WithLabelValues(string(record.Type), "matched").Inc()
+	} else {
+		// The ask request may be handled by the previous leader.
+		record = &SplitRecord{Type: typ, Reason: SplitReasonUnknown}
+		if len(regionIDs) > 0 {
+			// The origin region keeps the right-most range by default.
+			record.SourceRegionID = regionIDs[len(regionIDs)-1]
+			record.NewRegionIDs = append([]uint64(nil), regionIDs[:len(regionIDs)-1]...)
+		}
+		splitEventCounter.WithLabelValues(string(typ), "unmatched").Inc()
+	}
+	record.ReportTime = now
+	r.seq++
+	r.records.Put(r.seq, record)
+}
+
+// getRecords returns at most limit split records reported since from, the
+// latest ones are kept if there are more. If regionID is not zero, only the
+// records related to the region are returned. If limit is not positive, all
+// the matched records are returned.
+func (r *splitRecorder) getRecords(from time.Time, regionID uint64, limit int) []*SplitRecord {
+	// The records are not modified after being put into the FIFO.
+	elems := r.records.Elems()
+	records := make([]*SplitRecord, 0, len(elems))
+	for _, elem := range elems {
+		record := elem.Value.(*SplitRecord)
+		if record.ReportTime.Before(from) {
+			continue
+		}
+		if regionID != 0 && !record.relatedTo(regionID) {
+			continue
+		}
+		records = append(records, record)
+	}
+	if limit > 0 && len(records) > limit {
+		records = records[len(records)-limit:]
+	}
+	return records
+}
+
+func (r *SplitRecord) relatedTo(regionID uint64) bool {
+	if r.SourceRegionID == regionID {
+		return true
+	}
+	for _, id := range r.NewRegionIDs {
+		if id == regionID {
+			return true
+		}
+	}
+	return false
+}
@@ -228,6 +228,22 @@ func (u *unsafeRecoveryController) Show() []StageOutput {
 	return status
 }
 
+// Result returns whether the current unsafe recovery is done, and the error if it failed.
+func (u *unsafeRecoveryController) Result() (bool, error) {
+	u.Lock()
+	defer u.Unlock()
+
+	u.checkTimeout()
+	switch u.stage {
+	case finished:
+		return true, nil
+	case failed:
+		return true, u.err
+	default:
+		return false, nil
+	}
+}
+
 func (u *unsafeRecoveryController) getReportStatus() StageOutput {
 	var status StageOutput
 	status.Time = time.Now().Format("2006-01-02 15:04:05.000")
@@ -459,6 +475,17 @@ func (u *unsafeRecoveryController) GetStage() unsafeRecoveryStage {
 	return u.stage
 }
 
+// GetProgress returns the ratio of the passed stages, which is a rough
+// estimation since some stages may be skipped.
+func (u *unsafeRecoveryController) GetProgress() float64 {
+	u.RLock()
+	defer u.RUnlock()
+	if u.stage >= finished {
+		return 1
+	}
+	return float64(u.stage) / float64(finished)
+}
+
 func (u *unsafeRecoveryController) changeStage(stage unsafeRecoveryStage) {
 	u.stage = stage
 
@@ -32,6 +32,7 @@ import (
 	"github.com/tikv/pd/pkg/errs"
 	"github.com/tikv/pd/pkg/utils/configutil"
 	"github.com/tikv/pd/pkg/utils/grpcutil"
+	"github.com/tikv/pd/pkg/utils/logutil"
 	"github.com/tikv/pd/pkg/utils/metricutil"
 	"github.com/tikv/pd/pkg/utils/syncutil"
 	"github.com/tikv/pd/pkg/utils/typeutil"
@@ -67,6 +68,11 @@ type Config struct {
 	// Join to an existing pd cluster, a string of endpoints.
 	Join string `toml:"join" json:"join"`
 
+	// Witness indicates that the PD server joins the cluster as a non-voting
+	// member. It serves the read APIs and syncs regions from the leader, but
+	// never campaigns for the leader, so it does not affect the quorum latency.
+	Witness bool `toml:"witness" json:"witness"`
+
 	// LeaderLease time, if leader doesn't update its TTL
 	// in etcd after lease time, etcd will expire the leader key
 	// and other servers can campaign the leader again.
@@ -158,6 +164,12 @@ type Config struct {
 	ReplicationMode ReplicationModeConfig `toml:"replication-mode" json:"replication-mode"`
 
 	Keyspace KeyspaceConfig `toml:"keyspace" json:"keyspace"`
+
+	GC GCConfig `toml:"gc" json:"gc"`
+
+	Standby StandbyConfig `toml:"standby" json:"standby"`
+
+	ExternalEtcd ExternalEtcdConfig `toml:"external-etcd" json:"external-etcd"`
 }
 
 // NewConfig creates a new config.
@@ -216,6 +228,25 @@ const (
 
 	defaultDRWaitStoreTimeout = time.Minute
 
+	defaultStandbyRetryInterval = 5 * time.Second
+
+	defaultKeyspaceQuotaCheckInterval  = time.Minute
+	defaultKeyspaceUsageReportInterval = time.Minute
+	defaultKeyspaceAliasGracePeriod    = 24 * time.Hour
+
+	defaultGCSafePointCheckInterval  = time.Minute
+	defaultGCSafePointBlockThreshold = 24 * time.Hour
+	defaultGCMaxPauseDuration        = 24 * time.Hour
+	defaultGCLifeTime                = 10 * time.Minute
+	defaultGCRunInterval             = 10 * time.Minute
+
+	defaultCertCheckInterval = time.Hour
+	defaultCertRenewBefore   = 30 * 24 * time.Hour
+	defaultCertRenewTimeout  = 5 * time.Minute
+
+	defaultRBACTokenMaxTTL = 30 * 24 * time.Hour
+	defaultRBACSessionTTL  = 8 * time.Hour
+
 	defaultTSOSaveInterval = time.Duration(defaultLeaderLease) * time.Second
 	// defaultTSOUpdatePhysicalInterval is the default value of the config `TSOUpdatePhysicalInterval`.
 	defaultTSOUpdatePhysicalInterval = 50 * time.Millisecond
@@ -236,6 +267,21 @@ const (
 	defaultGCTunerThreshold           = 0.6
 	minGCTunerThreshold               = 0
 	maxGCTunerThreshold               = 0.9
+
+	// minLeaderLease is the same as the default lease, a shorter lease makes
+	// the leader lost on a short stall, e.g. a GC pause or a slow disk.
+	minLeaderLease               = int64(3)
+	maxLeaderLease               = int64(60)
+	defaultElectionRetryInterval = 200 * time.Millisecond
+	minElectionRetryInterval     = 50 * time.Millisecond
+	maxElectionRetryInterval     = 10 * time.Second
+	defaultElectionMaxBackoff    = 5 * time.Second
+	maxElectionMaxBackoff        = time.Minute
+
+	defaultLeaderFlappingThreshold = 3
+	defaultLeaderFlappingWindow    = 10 * time.Minute
+	defaultLeaderFlappingCooldown  = 5 * time.Minute
+	maxLeaderFlappingCooldown      = time.Hour
 )
 
 // Special keys for Labels
@@ -419,6 +465,12 @@ func (c *Config) Validate() error {
 	if c.Join != "" && c.InitialCluster != "" {
 		return errors.New("-initial-cluster and -join can not be provided at the same time")
 	}
+	if c.Witness && c.Join == "" {
+		return errors.New("witness can only join an existing cluster")
+	}
+	if c.ExternalEtcd.IsEnabled() && (c.Join != "" || c.Witness) {
+		return errors.New("join and witness can not be used with the external etcd")
+	}
 	dataDir, err := filepath.Abs(c.DataDir)
 	if err != nil {
 		return errors.WithStack(err)
@@ -434,10 +486,65 @@ func (c *Config) Validate() error {
 	if !strings.HasPrefix(rel, "..") {
 		return errors.New("log directory shouldn't be the subdirectory of data directory")
 	}
+	if err := c.ExternalEtcd.validate(); err != nil {
+		return err
+	}
+	if err := c.Keyspace.validate(); err != nil {
+		return err
+	}
+	if err := c.GC.validate(); err != nil {
+		return err
+	}
+	if err := c.Standby.validate(); err != nil {
+		return err
+	}
+	if err := c.Security.validate(); err != nil {
+		return err
+	}
 
 	return nil
 }
 
+// resolveSecrets resolves the configurations referring to the external secrets
+// by file://, env:// or vault://, so the secrets are not written in the config
+// file. The secrets of the file configurations are written into the secrets
+// directory under the data directory.
+func (c *Config) resolveSecrets() error {
+	dir := filepath.Join(c.DataDir, "secrets")
+	kms := &c.Security.Encryption.MasterKey.MasterKeyKMSConfig
+	for _, path := range []*string{
+		&c.Security.CAPath,
+		&c.Security.CertPath,
+		&c.Security.KeyPath,
+		&c.Security.Encryption.MasterKey.FilePath,
+		&kms.GcpCredentialsFile,
+		&kms.AzureClientSecretFile,
+		&kms.VaultTokenFile,
+	} {
+		resolved, err := configutil.ResolveSecretPath(*path, dir)
+		if err != nil {
+			return err
+		}
+		*path = resolved
+	}
+	for _, value := range []*string{
+		&kms.KmsKeyID,
+		&kms.KmsRegion,
+		&kms.KmsEndpoint,
+		&kms.AzureTenantID,
+		&kms.AzureClientID,
+		&c.ExternalEtcd.Username,
+		&c.ExternalEtcd.Password,
+	} {
+		resolved, err := configutil.ResolveSecret(*value)
+		if err != nil {
+			return err
+		}
+		*value = resolved
+	}
+	return nil
+}
+
 // Adjust is used to adjust the PD configurations.
 func (c *Config) Adjust(meta *toml.MetaData, reloading bool) error {
 	configMetaData := configutil.NewConfigMetadata(meta)
@@ -455,6 +562,10 @@ func (c *Config) Adjust(meta *toml.MetaData, reloading bool) error {
 	adjustString(&c.DataDir, fmt.Sprintf("default.%s", c.Name))
 	adjustPath(&c.DataDir)
 
+	if err := c.resolveSecrets(); err != nil {
+		return err
+	}
+
 	if err := c.Validate(); err != nil {
 		return err
 	}
@@ -542,8 +653,18 @@ func (c *Config) Adjust(meta *toml.MetaData, reloading bool) error {
 
 	c.ReplicationMode.adjust(configMetaData.Child("replication-mode"))
 
+	c.Keyspace.adjust()
+
+	c.GC.adjust()
+
+	c.Standby.adjust()
+
 	c.Security.Encryption.Adjust()
 
+	c.Security.RBAC.adjust()
+
+	c.Security.CertMonitor.adjust()
+
 	if len(c.Log.Format) == 0 {
 		c.Log.Format = defaultLogFormat
 	}
@@ -722,6 +843,20 @@ type ScheduleConfig struct {
 	// SlowStoreEvictingAffectedStoreRatioThreshold is the affected ratio threshold when judging a store is slow
 	// A store's slowness must affected more than `store-count * SlowStoreEvictingAffectedStoreRatioThreshold` to trigger evicting.
 	SlowStoreEvictingAffectedStoreRatioThreshold float64 `toml:"slow-store-evicting-affected-store-ratio-threshold" json:"slow-store-evicting-affected-store-ratio-threshold,omitempty"`
+
+	// FlowAotSize is the number of report intervals which the store and hot peer flow is averaged over
+	// before being smoothed. 0 means using the default value.
+	FlowAotSize int `toml:"flow-aot-size" json:"flow-aot-size,omitempty"`
+	// StoreFlowWindowSize is the window size of the median filter which smooths the store flow.
+	// 0 means using the default value.
+	StoreFlowWindowSize int `toml:"store-flow-window-size" json:"store-flow-window-size,omitempty"`
+	// HotPeerFlowWindowSize is the window size of the median filter which smooths the hot peer flow.
+	// 0 means using the default value.
+	HotPeerFlowWindowSize int `toml:"hot-peer-flow-window-size" json:"hot-peer-flow-window-size,omitempty"`
+	// FlowSmoothingDecay is the decay of the exponential moving average which replaces the median
+	// filters of the store and hot peer flow if it is in (0, 1). A larger decay reacts to bursts faster.
+	// 0 means using the median filters.
+	FlowSmoothingDecay float64 `toml:"flow-smoothing-decay" json:"flow-smoothing-decay,omitempty"`
 }
 
 // Clone returns a cloned scheduling configuration.
@@ -778,6 +913,16 @@ const (
 	defaultSlowStoreEvictingAffectedStoreRatioThreshold = 0.3
 )
 
+const (
+	defaultFlowAotSize           = 1
+	defaultStoreFlowWindowSize   = 5
+	defaultHotPeerFlowWindowSize = 5
+	// maxFlowAotSize and maxFlowWindowSize are the upper bounds of the flow smoothing windows,
+	// larger windows hide the sustained hotspots for too long.
+	maxFlowAotSize    = 10
+	maxFlowWindowSize = 60
+)
+
 func (c *ScheduleConfig) adjust(meta *configutil.ConfigMetaData, reloading bool) error {
 	if !meta.IsDefined("max-snapshot-count") {
 		adjustUint64(&c.MaxSnapshotCount, defaultMaxSnapshotCount)
@@ -958,6 +1103,18 @@ func (c *ScheduleConfig) Validate() error {
 	if c.SlowStoreEvictingAffectedStoreRatioThreshold == 0 {
 		return errors.Errorf("slow-store-evicting-affected-store-ratio-threshold is not set")
 	}
+	if c.FlowAotSize < 0 || c.FlowAotSize > maxFlowAotSize {
+		return errors.Errorf("flow-aot-size should be between 0 and %d", maxFlowAotSize)
+	}
+	if c.StoreFlowWindowSize < 0 || c.StoreFlowWindowSize > maxFlowWindowSize {
+		return errors.Errorf("store-flow-window-size should be between 0 and %d", maxFlowWindowSize)
+	}
+	if c.HotPeerFlowWindowSize < 0 || c.HotPeerFlowWindowSize > maxFlowWindowSize {
+		return errors.Errorf("hot-peer-flow-window-size should be between 0 and %d", maxFlowWindowSize)
+	}
+	if c.FlowSmoothingDecay < 0 || c.FlowSmoothingDecay >= 1 {
+		return errors.New("flow-smoothing-decay should be in [0, 1)")
+	}
 	return nil
 }
 
@@ -1130,6 +1287,23 @@ type PDServerConfig struct {
 	EnableGOGCTuner bool `toml:"enable-gogc-tuner" json:"enable-gogc-tuner,string"`
 	// GCTunerThreshold is the threshold of GC tuner.
 	GCTunerThreshold float64 `toml:"gc-tuner-threshold" json:"gc-tuner-threshold"`
+	// LeaderLease is the lease of the PD leader in seconds, which takes effect
+	// at the next campaign. 0 means using the `lease` of the server config.
+	LeaderLease int64 `toml:"leader-lease" json:"leader-lease"`
+	// ElectionRetryInterval is the interval to retry the PD leader campaign.
+	ElectionRetryInterval typeutil.Duration `toml:"election-retry-interval" json:"election-retry-interval"`
+	// ElectionMaxBackoff is the max interval to retry the PD leader campaign
+	// after it fails continuously.
+	ElectionMaxBackoff typeutil.Duration `toml:"election-max-backoff" json:"election-max-backoff"`
+	// LeaderFlappingThreshold is the number of times a member loses the
+	// leadership involuntarily, e.g. the lease expires, within
+	// LeaderFlappingWindow to stop campaigning for LeaderFlappingCooldown. The
+	// leader transfers are not counted. 0 means disabling the flapping detection.
+	LeaderFlappingThreshold int `toml:"leader-flapping-threshold" json:"leader-flapping-threshold"`
+	// LeaderFlappingWindow is the window to detect the leadership flapping.
+	LeaderFlappingWindow typeutil.Duration `toml:"leader-flapping-window" json:"leader-flapping-window"`
+	// LeaderFlappingCooldown is how long a flapping member stops campaigning.
+	LeaderFlappingCooldown typeutil.Duration `toml:"leader-flapping-cooldown" json:"leader-flapping-cooldown"`
 }
 
 func (c *PDServerConfig) adjust(meta *configutil.ConfigMetaData) error {
@@ -1182,6 +1356,13 @@ func (c *PDServerConfig) adjust(meta *configutil.ConfigMetaData) error {
 	} else if c.GCTunerThreshold > maxGCTunerThreshold {
 		c.GCTunerThreshold = maxGCTunerThreshold
 	}
+	adjustDuration(&c.ElectionRetryInterval, defaultElectionRetryInterval)
+	adjustDuration(&c.ElectionMaxBackoff, defaultElectionMaxBackoff)
+	if !meta.IsDefined("leader-flapping-threshold") {
+		c.LeaderFlappingThreshold = defaultLeaderFlappingThreshold
+	}
+	adjustDuration(&c.LeaderFlappingWindow, defaultLeaderFlappingWindow)
+	adjustDuration(&c.LeaderFlappingCooldown, defaultLeaderFlappingCooldown)
 	c.migrateConfigurationFromFile(meta)
 	return c.Validate()
 }
@@ -1202,6 +1383,38 @@ func (c *PDServerConfig) migrateConfigurationFromFile(meta *configutil.ConfigMet
 	return nil
 }
 
+// GetElectionRetryInterval returns the interval to retry the PD leader campaign.
+func (c *PDServerConfig) GetElectionRetryInterval() time.Duration {
+	if c.ElectionRetryInterval.Duration <= 0 {
+		return defaultElectionRetryInterval
+	}
+	return c.ElectionRetryInterval.Duration
+}
+
+// GetElectionMaxBackoff returns the max interval to retry the PD leader campaign.
+func (c *PDServerConfig) GetElectionMaxBackoff() time.Duration {
+	if c.ElectionMaxBackoff.Duration <= 0 {
+		return defaultElectionMaxBackoff
+	}
+	return c.ElectionMaxBackoff.Duration
+}
+
+// GetLeaderFlappingWindow returns the window to detect the leadership flapping.
+func (c *PDServerConfig) GetLeaderFlappingWindow() time.Duration {
+	if c.LeaderFlappingWindow.Duration <= 0 {
+		return defaultLeaderFlappingWindow
+	}
+	return c.LeaderFlappingWindow.Duration
+}
+
+// GetLeaderFlappingCooldown returns how long a flapping member stops campaigning.
+func (c *PDServerConfig) GetLeaderFlappingCooldown() time.Duration {
+	if c.LeaderFlappingCooldown.Duration <= 0 {
+		return defaultLeaderFlappingCooldown
+	}
+	return c.LeaderFlappingCooldown.Duration
+}
+
 // MigrateDeprecatedFlags updates new flags according to deprecated flags.
 func (c *PDServerConfig) MigrateDeprecatedFlags() {
 	if !c.TraceRegionFlow {
@@ -1245,6 +1458,32 @@ func (c *PDServerConfig) Validate() error {
 	if c.GCTunerThreshold < minGCTunerThreshold || c.GCTunerThreshold > maxGCTunerThreshold {
 		return errors.New(fmt.Sprintf("gc-tuner-threshold should between %v and %v", minGCTunerThreshold, maxGCTunerThreshold))
 	}
+	if c.LeaderLease != 0 && (c.LeaderLease < minLeaderLease || c.LeaderLease > maxLeaderLease) {
+		return errors.New(fmt.Sprintf("leader-lease should be 0 or between %v and %v", minLeaderLease, maxLeaderLease))
+	}
+	// The lease should outlive several campaign retries, otherwise it expires
+	// before the leader is elected.
+	if c.LeaderLease != 0 && time.Duration(c.LeaderLease)*time.Second < 3*c.GetElectionRetryInterval() {
+		return errors.New("leader-lease should be at least 3 times of election-retry-interval")
+	}
+	// The zero value is kept for the config persisted by the old version.
+	if c.ElectionRetryInterval.Duration != 0 &&
+		(c.ElectionRetryInterval.Duration < minElectionRetryInterval || c.ElectionRetryInterval.Duration > maxElectionRetryInterval) {
+		return errors.New(fmt.Sprintf("election-retry-interval should between %v and %v", minElectionRetryInterval, maxElectionRetryInterval))
+	}
+	if c.ElectionMaxBackoff.Duration != 0 &&
+		(c.ElectionMaxBackoff.Duration < c.GetElectionRetryInterval() || c.ElectionMaxBackoff.Duration > maxElectionMaxBackoff) {
+		return errors.New(fmt.Sprintf("election-max-backoff should between election-retry-interval and %v", maxElectionMaxBackoff))
+	}
+	if c.LeaderFlappingThreshold < 0 {
+		return errors.New("leader-flapping-threshold should not be negative")
+	}
+	if c.LeaderFlappingWindow.Duration < 0 {
+		return errors.New("leader-flapping-window should not be negative")
+	}
+	if c.LeaderFlappingCooldown.Duration < 0 || c.LeaderFlappingCooldown.Duration > maxLeaderFlappingCooldown {
+		return errors.New(fmt.Sprintf("leader-flapping-cooldown should between 0 and %v", maxLeaderFlappingCooldown))
+	}
 
 	return nil
 }
@@ -1327,6 +1566,12 @@ func (c *Config) GenEmbedEtcdConfig() (*embed.Config, error) {
 	cfg.PeerTLSInfo.CertFile = c.Security.CertPath
 	cfg.PeerTLSInfo.KeyFile = c.Security.KeyPath
 	cfg.PeerTLSInfo.AllowedCN = allowedCN
+	// Only the TLS 1.2 cipher suites can be restricted here, the embedded etcd
+	// excludes TLS 1.3 only with the boringcrypto build.
+	if c.Security.IsFIPS() {
+		cfg.ClientTLSInfo.CipherSuites = grpcutil.FIPSCipherSuites
+		cfg.PeerTLSInfo.CipherSuites = grpcutil.FIPSCipherSuites
+	}
 	cfg.ForceNewCluster = c.ForceNewCluster
 	cfg.ZapLoggerBuilder = embed.NewZapCoreLoggerBuilder(c.Logger, c.Logger.Core(), c.LogProps.Syncer)
 	cfg.EnableGRPCGateway = c.EnableGRPCGateway
@@ -1441,13 +1686,341 @@ func (c *DRAutoSyncReplicationConfig) adjust(meta *configutil.ConfigMetaData) {
 // SecurityConfig indicates the security configuration for pd server
 type SecurityConfig struct {
 	grpcutil.TLSConfig
-	// RedactInfoLog indicates that whether enabling redact log
-	RedactInfoLog bool              `toml:"redact-info-log" json:"redact-info-log"`
-	Encryption    encryption.Config `toml:"encryption" json:"encryption"`
+	// RedactInfoLog is how the user data is redacted in the logs, the API
+	// error messages and the audit records. The boolean values are accepted
+	// for compatibility.
+	RedactInfoLog logutil.RedactMode `toml:"redact-info-log" json:"redact-info-log"`
+	Encryption    encryption.Config  `toml:"encryption" json:"encryption"`
+	// RBAC is the role-based access control of the PD APIs.
+	RBAC RBACConfig `toml:"rbac" json:"rbac"`
+	// AuditLog is the security audit log.
+	AuditLog AuditLogConfig `toml:"audit-log" json:"audit-log"`
+	// CertMonitor monitors the expiry of the TLS certificates.
+	CertMonitor CertMonitorConfig `toml:"cert-monitor" json:"cert-monitor"`
+	// GRPCPolicy authorizes the gRPC methods by the client certificates.
+	GRPCPolicy GRPCPolicyConfig `toml:"grpc-policy" json:"grpc-policy"`
+	// NetworkACL allows or denies the source IPs of the requests.
+	NetworkACL NetworkACLConfig `toml:"network-acl" json:"network-acl"`
+}
+
+func (c *SecurityConfig) validate() error {
+	if c.IsFIPS() {
+		if err := c.TLSConfig.ValidateFIPS(); err != nil {
+			return err
+		}
+		if err := c.Encryption.ValidateFIPS(); err != nil {
+			return err
+		}
+	}
+	if err := c.RBAC.validate(&c.TLSConfig); err != nil {
+		return err
+	}
+	if err := c.GRPCPolicy.validate(&c.TLSConfig); err != nil {
+		return err
+	}
+	if err := c.AuditLog.validate(); err != nil {
+		return err
+	}
+	return c.CertMonitor.validate()
+}
+
+// CertMonitorConfig is the configuration for monitoring the expiry of the TLS
+// certificates of PD. The certificates are renewed by an external command
+// before they expire, which may run an ACME client or a SPIFFE helper. The
+// renewed files are loaded by the new connections without restarting PD.
+type CertMonitorConfig struct {
+	// CheckInterval is the interval to check the certificates.
+	CheckInterval typeutil.Duration `toml:"check-interval" json:"check-interval"`
+	// RenewBefore is how long before a certificate expires it is renewed and
+	// notified.
+	RenewBefore typeutil.Duration `toml:"renew-before" json:"renew-before"`
+	// RenewCommand is the command and its arguments to renew the certificates,
+	// which is run without a shell. No renewal is done if it is empty.
+	RenewCommand []string `toml:"renew-command" json:"renew-command"`
+	// RenewTimeout is the timeout of the renew command.
+	RenewTimeout typeutil.Duration `toml:"renew-timeout" json:"renew-timeout"`
+	// Webhook is the URL notified by a POST request when a certificate is about
+	// to expire or fails to renew. No notification is sent if it is empty.
+	Webhook string `toml:"webhook" json:"webhook"`
+}
+
+func (c *CertMonitorConfig) adjust() {
+	adjustDuration(&c.CheckInterval, defaultCertCheckInterval)
+	adjustDuration(&c.RenewBefore, defaultCertRenewBefore)
+	adjustDuration(&c.RenewTimeout, defaultCertRenewTimeout)
+}
+
+func (c *CertMonitorConfig) validate() error {
+	if len(c.Webhook) > 0 {
+		if _, err := url.ParseRequestURI(c.Webhook); err != nil {
+			return errors.Errorf("invalid certificate monitor webhook %s: %v", c.Webhook, err)
+		}
+	}
+	if len(c.RenewCommand) > 0 && len(c.RenewCommand[0]) == 0 {
+		return errors.New("the certificate renew command should not be empty")
+	}
+	return nil
+}
+
+// AuditLogConfig is the configuration for the security audit log, which records
+// the authentication decisions and the mutating API calls as JSON, separate
+// from the operational log.
+type AuditLogConfig struct {
+	// File is the audit log file with its own rotation. The security audit
+	// log is disabled if the filename is empty.
+	File log.FileLogConfig `toml:"file" json:"file"`
+	// Sink is the URL the audit records are also posted to in batches as JSON
+	// arrays. No record is sent if it is empty.
+	Sink string `toml:"sink" json:"sink"`
+}
+
+// IsEnabled returns whether the security audit log is enabled.
+func (c *AuditLogConfig) IsEnabled() bool {
+	return len(c.File.Filename) > 0
+}
+
+func (c *AuditLogConfig) validate() error {
+	if len(c.Sink) == 0 {
+		return nil
+	}
+	if !c.IsEnabled() {
+		return errors.New("the security audit log sink requires the audit log file")
+	}
+	if _, err := url.ParseRequestURI(c.Sink); err != nil {
+		return errors.Errorf("invalid security audit log sink %s: %v", c.Sink, err)
+	}
+	return nil
+}
+
+// GRPCPolicyConfig is the configuration for authorizing the gRPC methods of PD
+// by the policies stored in etcd, which map the CNs and the SANs of the client
+// certificates to the allowed methods. It extends cert-allowed-cn, which only
+// decides whether a certificate may connect.
+type GRPCPolicyConfig struct {
+	// Enable enables the gRPC policies.
+	Enable bool `toml:"enable" json:"enable"`
+	// DefaultDeny denies the certificates matching no policy. The certificates
+	// of the PD members and the stores should then be matched by a policy.
+	DefaultDeny bool `toml:"default-deny" json:"default-deny"`
+}
+
+func (c *GRPCPolicyConfig) validate(tls *grpcutil.TLSConfig) error {
+	if c.Enable && len(tls.CAPath) == 0 {
+		return errors.New("gRPC policies require the client certificates to be verified, cacert-path should be set")
+	}
+	return nil
+}
+
+// NetworkACLConfig is the configuration for the network ACLs, which allow or
+// deny the source IPs of the HTTP and gRPC requests by the CIDRs per listener
+// and per class of the APIs. The ACLs are stored in etcd, so they take effect
+// on all the members without restarting PD.
+type NetworkACLConfig struct {
+	// Enable enables the network ACLs.
+	Enable bool `toml:"enable" json:"enable"`
+}
+
+// RBACConfig is the configuration for the role-based access control of the
+// HTTP APIs and the admin gRPC APIs. The identity of a request is the CN of
+// its client certificate or its bearer token, bound to a role by the role
+// bindings stored in etcd.
+type RBACConfig struct {
+	// Enable enables the role-based access control.
+	Enable bool `toml:"enable" json:"enable"`
+	// AdminCN are the CNs always bound to the admin role, which manage the role
+	// bindings. The CNs of the PD members should be included, since the
+	// requests forwarded to the leader carry their certificates.
+	AdminCN []string `toml:"admin-cn" json:"admin-cn"`
+	// TokenMaxTTL is the max ttl of the API tokens issued by PD.
+	TokenMaxTTL typeutil.Duration `toml:"token-max-ttl" json:"token-max-ttl"`
+	// SessionTTL is how long the admin sessions of the interactive tools last.
+	SessionTTL typeutil.Duration `toml:"session-ttl" json:"session-ttl"`
+	// MaxSessions is the max active admin sessions of an identity, no limit if
+	// it is zero.
+	MaxSessions int `toml:"max-sessions" json:"max-sessions"`
+}
+
+func (c *RBACConfig) adjust() {
+	adjustDuration(&c.TokenMaxTTL, defaultRBACTokenMaxTTL)
+	adjustDuration(&c.SessionTTL, defaultRBACSessionTTL)
+}
+
+func (c *RBACConfig) validate(tls *grpcutil.TLSConfig) error {
+	if !c.Enable {
+		return nil
+	}
+	if len(tls.CAPath) == 0 {
+		return errors.New("RBAC requires the client certificates to be verified, cacert-path should be set")
+	}
+	if len(c.AdminCN) == 0 {
+		return errors.New("RBAC requires admin-cn to manage the role bindings")
+	}
+	if c.MaxSessions < 0 {
+		return errors.Errorf("invalid max-sessions %d, should not be negative", c.MaxSessions)
+	}
+	return nil
 }
 
 // KeyspaceConfig is the configuration for keyspace management.
 type KeyspaceConfig struct {
 	// PreAlloc contains the keyspace to be allocated during keyspace manager initialization.
 	PreAlloc []string `toml:"pre-alloc" json:"pre-alloc"`
+	// QuotaCheckInterval is the interval to check the keyspace quotas against
+	// the region statistics.
+	QuotaCheckInterval typeutil.Duration `toml:"quota-check-interval" json:"quota-check-interval"`
+	// QuotaWebhook is the URL notified by a POST request when a keyspace
+	// exceeds its quota. No notification is sent if it is empty.
+	QuotaWebhook string `toml:"quota-webhook" json:"quota-webhook"`
+	// UsageReportInterval is the interval to update the keyspace usage metrics.
+	UsageReportInterval typeutil.Duration `toml:"usage-report-interval" json:"usage-report-interval"`
+	// AliasGracePeriod is how long the old name of a renamed keyspace still
+	// refers to the keyspace.
+	AliasGracePeriod typeutil.Duration `toml:"alias-grace-period" json:"alias-grace-period"`
+	// ArchiveStoreLabel is the store label in the form of "key=value" to place
+	// the data of the archived keyspaces, e.g. "tier=cold". The placement of the
+	// archived keyspaces is unchanged if it is empty.
+	ArchiveStoreLabel string `toml:"archive-store-label" json:"archive-store-label"`
+}
+
+// GetArchiveStoreLabel returns the key and the value of the store label to
+// place the data of the archived keyspaces.
+func (c *KeyspaceConfig) GetArchiveStoreLabel() (key, value string, ok bool) {
+	if len(c.ArchiveStoreLabel) == 0 {
+		return "", "", false
+	}
+	kv := strings.SplitN(c.ArchiveStoreLabel, "=", 2)
+	if len(kv) != 2 || len(kv[0]) == 0 || len(kv[1]) == 0 {
+		return "", "", false
+	}
+	return kv[0], kv[1], true
+}
+
+func (c *KeyspaceConfig) adjust() {
+	adjustDuration(&c.QuotaCheckInterval, defaultKeyspaceQuotaCheckInterval)
+	adjustDuration(&c.UsageReportInterval, defaultKeyspaceUsageReportInterval)
+	adjustDuration(&c.AliasGracePeriod, defaultKeyspaceAliasGracePeriod)
+}
+
+func (c *KeyspaceConfig) validate() error {
+	if len(c.QuotaWebhook) > 0 {
+		if _, err := url.ParseRequestURI(c.QuotaWebhook); err != nil {
+			return errors.Errorf("invalid keyspace quota webhook %s: %v", c.QuotaWebhook, err)
+		}
+	}
+	if _, _, ok := c.GetArchiveStoreLabel(); len(c.ArchiveStoreLabel) > 0 && !ok {
+		return errors.Errorf("invalid keyspace archive store label %s, should be in the form of key=value", c.ArchiveStoreLabel)
+	}
+	return nil
+}
+
+// GCConfig is the configuration for the GC safepoints.
+type GCConfig struct {
+	// SafePointCheckInterval is the interval to check the service safepoints.
+	SafePointCheckInterval typeutil.Duration `toml:"safe-point-check-interval" json:"safe-point-check-interval"`
+	// SafePointBlockThreshold is how long a service safepoint can stay behind
+	// the current time before it is reported as blocking GC.
+	SafePointBlockThreshold typeutil.Duration `toml:"safe-point-block-threshold" json:"safe-point-block-threshold"`
+	// SafePointWebhook is the URL notified by a POST request when a service
+	// safepoint blocks GC or its lease expires. No notification is sent if it
+	// is empty.
+	SafePointWebhook string `toml:"safe-point-webhook" json:"safe-point-webhook"`
+	// MaxPauseDuration is the longest the GC safepoint can be paused without
+	// being forced.
+	MaxPauseDuration typeutil.Duration `toml:"max-pause-duration" json:"max-pause-duration"`
+	// SafePointMaxAdvanceRate is the max seconds the GC safepoint can advance
+	// per second, so a long stuck safepoint catches up gradually instead of
+	// triggering a burst of GC load. The unused advancement accrues for at most
+	// 10 minutes. It's unlimited if it is zero, otherwise it should be greater
+	// than 1 to catch up.
+	SafePointMaxAdvanceRate float64 `toml:"safe-point-max-advance-rate" json:"safe-point-max-advance-rate"`
+	// EnableController makes PD advance the GC safepoint by itself, for the
+	// clusters without TiDB. It should not be enabled when the GC worker of TiDB
+	// is running.
+	EnableController bool `toml:"enable-controller" json:"enable-controller"`
+	// LifeTime is how long the data versions are kept by the GC controller.
+	LifeTime typeutil.Duration `toml:"life-time" json:"life-time"`
+	// RunInterval is the interval the GC controller advances the GC safepoint.
+	RunInterval typeutil.Duration `toml:"run-interval" json:"run-interval"`
+}
+
+func (c *GCConfig) adjust() {
+	adjustDuration(&c.SafePointCheckInterval, defaultGCSafePointCheckInterval)
+	adjustDuration(&c.SafePointBlockThreshold, defaultGCSafePointBlockThreshold)
+	adjustDuration(&c.MaxPauseDuration, defaultGCMaxPauseDuration)
+	adjustDuration(&c.LifeTime, defaultGCLifeTime)
+	adjustDuration(&c.RunInterval, defaultGCRunInterval)
+}
+
+func (c *GCConfig) validate() error {
+	if len(c.SafePointWebhook) > 0 {
+		if _, err := url.ParseRequestURI(c.SafePointWebhook); err != nil {
+			return errors.Errorf("invalid GC safe point webhook %s: %v", c.SafePointWebhook, err)
+		}
+	}
+	if c.SafePointMaxAdvanceRate != 0 && !(c.SafePointMaxAdvanceRate > 1) {
+		return errors.Errorf("invalid GC safe point max advance rate %v, should be 0 or greater than 1", c.SafePointMaxAdvanceRate)
+	}
+	// The zero durations are adjusted to the defaults.
+	if c.LifeTime.Duration < 0 {
+		return errors.Errorf("invalid GC life time %v, should be positive", c.LifeTime)
+	}
+	if c.RunInterval.Duration < 0 {
+		return errors.Errorf("invalid GC run interval %v, should be positive", c.RunInterval)
+	}
+	return nil
+}
+
+// StandbyConfig is the configuration for replicating the metadata to a standby PD cluster.
+type StandbyConfig struct {
+	// Endpoints are the client URLs of the standby PD cluster. The replication
+	// is disabled if it is empty. The security config of the primary cluster
+	// is used to connect the standby one.
+	Endpoints []string `toml:"endpoints" json:"endpoints"`
+	// RetryInterval is the interval to retry after the replication is broken.
+	RetryInterval typeutil.Duration `toml:"retry-interval" json:"retry-interval"`
+}
+
+// IsEnabled returns whether the replication to the standby cluster is enabled.
+func (c *StandbyConfig) IsEnabled() bool {
+	return len(c.Endpoints) > 0
+}
+
+func (c *StandbyConfig) adjust() {
+	adjustDuration(&c.RetryInterval, defaultStandbyRetryInterval)
+}
+
+func (c *StandbyConfig) validate() error {
+	for _, ep := range c.Endpoints {
+		if _, err := url.Parse(ep); err != nil {
+			return errors.Errorf("invalid standby endpoint %s: %v", ep, err)
+		}
+	}
+	return nil
+}
+
+// ExternalEtcdConfig is the configuration for running PD against an external
+// etcd cluster instead of the embedded one.
+type ExternalEtcdConfig struct {
+	// Endpoints are the client URLs of the external etcd cluster. The embedded
+	// etcd is used if it is empty. The security config of PD is used to
+	// connect the external etcd.
+	Endpoints []string `toml:"endpoints" json:"endpoints"`
+	// Username and Password authenticate PD to the external etcd cluster if
+	// its authentication is enabled. The password is not exported by the API.
+	Username string `toml:"username" json:"username"`
+	Password string `toml:"password" json:"-"`
+}
+
+// IsEnabled returns whether PD runs against an external etcd cluster.
+func (c *ExternalEtcdConfig) IsEnabled() bool {
+	return len(c.Endpoints) > 0
+}
+
+func (c *ExternalEtcdConfig) validate() error {
+	for _, ep := range c.Endpoints {
+		if _, err := url.Parse(ep); err != nil {
+			return errors.Errorf("invalid external etcd endpoint %s: %v", ep, err)
+		}
+	}
+	return nil
 }
@@ -28,12 +28,31 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/tikv/pd/pkg/storage"
 	"github.com/tikv/pd/pkg/utils/configutil"
+	"github.com/tikv/pd/pkg/utils/logutil"
+	"github.com/tikv/pd/pkg/utils/typeutil"
 )
 
 func TestSecurity(t *testing.T) {
 	re := require.New(t)
 	cfg := NewConfig()
-	re.False(cfg.Security.RedactInfoLog)
+	re.Empty(cfg.Security.RedactInfoLog)
+
+	// The boolean values are accepted for compatibility.
+	for cfgData, mode := range map[string]logutil.RedactMode{
+		"redact-info-log = true":     logutil.RedactRemove,
+		"redact-info-log = false":    logutil.RedactOff,
+		`redact-info-log = "mark"`:   logutil.RedactMark,
+		`redact-info-log = "hash"`:   logutil.RedactHash,
+		`redact-info-log = "remove"`: logutil.RedactRemove,
+	} {
+		cfg = NewConfig()
+		_, err := toml.Decode("[security]\n"+cfgData, &cfg)
+		re.NoError(err)
+		re.Equal(mode, cfg.Security.RedactInfoLog)
+	}
+	_, err := toml.Decode(`[security]
+redact-info-log = "unknown"`, &cfg)
+	re.Error(err)
 }
 
 func TestTLS(t *testing.T) {
@@ -139,6 +158,37 @@ func TestValidation(t *testing.T) {
 
 	cfg.Log.File.Filename = path.Join(cfg.DataDir, "test")
 	re.Error(cfg.Validate())
+	cfg.Log.File.Filename = ""
+	cfg.Witness = true
+	re.Error(cfg.Validate())
+	initialCluster := cfg.InitialCluster
+	cfg.InitialCluster, cfg.Join = "", "http://127.0.0.1:2379"
+	re.NoError(cfg.Validate())
+	cfg.Witness, cfg.Join, cfg.InitialCluster = false, "", initialCluster
+	cfg.ExternalEtcd.Endpoints = []string{"http://127.0.0.1:2379"}
+	re.NoError(cfg.Validate())
+	cfg.InitialCluster, cfg.Join = "", "http://127.0.0.1:2379"
+	re.Error(cfg.Validate())
+	cfg.Join, cfg.InitialCluster = "", initialCluster
+	cfg.ExternalEtcd.Endpoints = []string{"http://%zz"}
+	re.Error(cfg.Validate())
+	cfg.ExternalEtcd.Endpoints = nil
+	cfg.Keyspace.ArchiveStoreLabel = "tier"
+	re.Error(cfg.Validate())
+	cfg.Keyspace.ArchiveStoreLabel = "tier=cold"
+	re.NoError(cfg.Validate())
+	key, value, ok := cfg.Keyspace.GetArchiveStoreLabel()
+	re.True(ok)
+	re.Equal("tier", key)
+	re.Equal("cold", value)
+	cfg.Keyspace.ArchiveStoreLabel = ""
+	cfg.GC.RunInterval = typeutil.NewDuration(-time.Minute)
+	re.Error(cfg.Validate())
+	cfg.GC.RunInterval = typeutil.NewDuration(time.Minute)
+	cfg.GC.LifeTime = typeutil.NewDuration(-time.Minute)
+	re.Error(cfg.Validate())
+	cfg.GC.LifeTime = typeutil.NewDuration(time.Minute)
+	re.NoError(cfg.Validate())
 
 	// check schedule config
 	cfg.Schedule.HighSpaceRatio = -0.1
@@ -153,6 +203,22 @@ func TestValidation(t *testing.T) {
 	re.NoError(cfg.Schedule.Validate())
 	cfg.Schedule.TolerantSizeRatio = -0.6
 	re.Error(cfg.Schedule.Validate())
+	cfg.Schedule.TolerantSizeRatio = 0
+	re.NoError(cfg.Schedule.Validate())
+	// check flow smoothing windows
+	cfg.Schedule.FlowAotSize = maxFlowAotSize + 1
+	re.Error(cfg.Schedule.Validate())
+	cfg.Schedule.FlowAotSize = 2
+	cfg.Schedule.StoreFlowWindowSize = -1
+	re.Error(cfg.Schedule.Validate())
+	cfg.Schedule.StoreFlowWindowSize = 10
+	cfg.Schedule.HotPeerFlowWindowSize = maxFlowWindowSize + 1
+	re.Error(cfg.Schedule.Validate())
+	cfg.Schedule.HotPeerFlowWindowSize = 10
+	cfg.Schedule.FlowSmoothingDecay = 1
+	re.Error(cfg.Schedule.Validate())
+	cfg.Schedule.FlowSmoothingDecay = 0.3
+	re.NoError(cfg.Schedule.Validate())
 	// check quota
 	re.Equal(defaultQuotaBackendBytes, cfg.QuotaBackendBytes)
 	// check request bytes
@@ -472,6 +538,31 @@ wait-store-timeout = "120s"
 	re.Equal("majority", cfg.ReplicationMode.ReplicationMode)
 }
 
+func TestStandbyConfig(t *testing.T) {
+	re := require.New(t)
+	registerDefaultSchedulers()
+	cfgData := `
+[standby]
+endpoints = ["http://127.0.0.1:12379"]
+`
+	cfg := NewConfig()
+	meta, err := toml.Decode(cfgData, &cfg)
+	re.NoError(err)
+	err = cfg.Adjust(&meta, false)
+	re.NoError(err)
+	re.True(cfg.Standby.IsEnabled())
+	re.Equal([]string{"http://127.0.0.1:12379"}, cfg.Standby.Endpoints)
+	re.Equal(defaultStandbyRetryInterval, cfg.Standby.RetryInterval.Duration)
+
+	cfg.Standby.Endpoints = []string{"http://[::1"}
+	re.Error(cfg.Standby.validate())
+
+	cfg = NewConfig()
+	err = cfg.Adjust(nil, false)
+	re.NoError(err)
+	re.False(cfg.Standby.IsEnabled())
+}
+
 func TestHotHistoryRegionConfig(t *testing.T) {
 	re := require.New(t)
 	registerDefaultSchedulers()
@@ -535,3 +626,133 @@ func registerDefaultSchedulers() {
 		RegisterScheduler(d.Type)
 	}
 }
+
+func TestLeaderElectionConfig(t *testing.T) {
+	re := require.New(t)
+	registerDefaultSchedulers()
+	cfg := NewConfig()
+	meta, err := toml.Decode("", &cfg)
+	re.NoError(err)
+	re.NoError(cfg.Adjust(&meta, false))
+	re.Equal(int64(0), cfg.PDServerCfg.LeaderLease)
+	re.Equal(defaultElectionRetryInterval, cfg.PDServerCfg.GetElectionRetryInterval())
+	re.Equal(defaultElectionMaxBackoff, cfg.PDServerCfg.GetElectionMaxBackoff())
+	re.Equal(defaultLeaderFlappingThreshold, cfg.PDServerCfg.LeaderFlappingThreshold)
+	re.Equal(defaultLeaderFlappingWindow, cfg.PDServerCfg.GetLeaderFlappingWindow())
+	re.Equal(defaultLeaderFlappingCooldown, cfg.PDServerCfg.GetLeaderFlappingCooldown())
+
+	tests := []struct {
+		cfgData string
+		hasErr  bool
+	}{
+		{
+			`
+[pd-server]
+leader-lease = 10
+election-retry-interval = "1s"
+`,
+			false,
+		},
+		{
+			`
+[pd-server]
+leader-lease = -1
+`,
+			true,
+		},
+		{
+			`
+[pd-server]
+leader-lease = 61
+`,
+			true,
+		},
+		{
+			`
+[pd-server]
+leader-lease = 1
+`,
+			true,
+		},
+		{
+			`
+[pd-server]
+leader-lease = 3
+election-retry-interval = "2s"
+`,
+			true,
+		},
+		{
+			`
+[pd-server]
+election-retry-interval = "10ms"
+`,
+			true,
+		},
+		{
+			`
+[pd-server]
+election-retry-interval = "1m"
+`,
+			true,
+		},
+		{
+			`
+[pd-server]
+election-retry-interval = "1s"
+election-max-backoff = "30s"
+leader-flapping-threshold = 0
+leader-flapping-window = "1m"
+leader-flapping-cooldown = "10m"
+`,
+			false,
+		},
+		{
+			`
+[pd-server]
+election-retry-interval = "1s"
+election-max-backoff = "500ms"
+`,
+			true,
+		},
+		{
+			`
+[pd-server]
+election-max-backoff = "2m"
+`,
+			true,
+		},
+		{
+			`
+[pd-server]
+leader-flapping-threshold = -1
+`,
+			true,
+		},
+		{
+			`
+[pd-server]
+leader-flapping-cooldown = "2h"
+`,
+			true,
+		},
+	}
+	for _, test := range tests {
+		cfg := NewConfig()
+		meta, err := toml.Decode(test.cfgData, &cfg)
+		re.NoError(err)
+		err = cfg.Adjust(&meta, false)
+		re.Equal(test.hasErr, err != nil, test.cfgData)
+	}
+
+	// The config persisted by the old version has no retry interval.
+	pdServerCfg := &PDServerConfig{
+		DashboardAddress:           "auto",
+		KeyType:                    defaultKeyType,
+		ServerMemoryLimitGCTrigger: defaultServerMemoryLimitGCTrigger,
+	}
+	re.NoError(pdServerCfg.Validate())
+	re.Equal(defaultElectionRetryInterval, pdServerCfg.GetElectionRetryInterval())
+	re.Equal(defaultElectionMaxBackoff, pdServerCfg.GetElectionMaxBackoff())
+	re.Zero(pdServerCfg.LeaderFlappingThreshold)
+}
@@ -601,6 +601,38 @@ func (o *PersistOptions) GetMaxMovableHotPeerSize() int64 {
 	return size
 }
 
+// GetFlowAotSize returns the number of report intervals which the flow is averaged over.
+func (o *PersistOptions) GetFlowAotSize() int {
+	size := o.GetScheduleConfig().FlowAotSize
+	if size <= 0 {
+		size = defaultFlowAotSize
+	}
+	return size
+}
+
+// GetStoreFlowWindowSize returns the window size of the median filter of the store flow.
+func (o *PersistOptions) GetStoreFlowWindowSize() int {
+	size := o.GetScheduleConfig().StoreFlowWindowSize
+	if size <= 0 {
+		size = defaultStoreFlowWindowSize
+	}
+	return size
+}
+
+// GetHotPeerFlowWindowSize returns the window size of the median filter of the hot peer flow.
+func (o *PersistOptions) GetHotPeerFlowWindowSize() int {
+	size := o.GetScheduleConfig().HotPeerFlowWindowSize
+	if size <= 0 {
+		size = defaultHotPeerFlowWindowSize
+	}
+	return size
+}
+
+// GetFlowSmoothingDecay returns the decay of the EMA which smooths the flow, 0 means using median filters.
+func (o *PersistOptions) GetFlowSmoothingDecay() float64 {
+	return o.GetScheduleConfig().FlowSmoothingDecay
+}
+
 // IsDebugMetricsEnabled returns if debug metrics is enabled.
 func (o *PersistOptions) IsDebugMetricsEnabled() bool {
 	return o.GetScheduleConfig().EnableDebugMetrics
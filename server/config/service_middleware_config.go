@@ -33,8 +33,9 @@ func NewServiceMiddlewareConfig() *ServiceMiddlewareConfig {
 		EnableAudit: defaultEnableAuditMiddleware,
 	}
 	ratelimit := RateLimitConfig{
-		EnableRateLimit: defaultEnableRateLimitMiddleware,
-		LimiterConfig:   make(map[string]ratelimit.DimensionConfig),
+		EnableRateLimit:       defaultEnableRateLimitMiddleware,
+		LimiterConfig:         make(map[string]ratelimit.DimensionConfig),
+		IdentityLimiterConfig: make(map[string]ratelimit.DimensionConfig),
 	}
 	cfg := &ServiceMiddlewareConfig{
 		AuditConfig:     audit,
@@ -67,6 +68,10 @@ type RateLimitConfig struct {
 	EnableRateLimit bool `json:"enable-rate-limit,string"`
 	// RateLimitConfig is the config of rate limit middleware
 	LimiterConfig map[string]ratelimit.DimensionConfig `json:"limiter-config"`
+	// IdentityLimiterConfig is the config of the rate limit for each caller
+	// identity, which is "cn:{certificate CN}", "token:{token subject}" or
+	// "ip:{source IP}". It limits all the APIs called by the identity together.
+	IdentityLimiterConfig map[string]ratelimit.DimensionConfig `json:"identity-limiter-config"`
 }
 
 // Clone returns a cloned rate limit config.
@@ -0,0 +1,169 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"crypto/tls"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/soheilhy/cmux"
+	"github.com/tikv/pd/pkg/member"
+	"go.etcd.io/etcd/embed"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+const (
+	externalServeReadHeaderTimeout = 5 * time.Second
+	// grpcOverheadBytes is the same as the embedded etcd, which is added to
+	// the max request bytes as the max size of the received gRPC messages.
+	grpcOverheadBytes = 512 * 1024
+)
+
+// externalServe serves the gRPC and HTTP services on the client URLs, which
+// are served by the embedded etcd if the external etcd is not used.
+type externalServe struct {
+	grpcServer *grpc.Server
+	httpServer *http.Server
+	// tlsServers serve both the gRPC and HTTP requests on the listeners with TLS.
+	tlsServers []*http.Server
+	listeners  []net.Listener
+}
+
+// startExternalEtcd connects to the external etcd cluster instead of starting
+// the embedded one, all the election, storage and watch go through it.
+func (s *Server) startExternalEtcd() error {
+	var err error
+	s.client, s.httpClient, err = startClient(s.cfg)
+	if err != nil {
+		return err
+	}
+	if err = s.startExternalServe(); err != nil {
+		return err
+	}
+	s.member = member.NewMember(nil, s.client, member.ExternalMemberID(s.Name()))
+	log.Info("pd server runs against the external etcd", zap.Strings("endpoints", s.cfg.ExternalEtcd.Endpoints))
+	return nil
+}
+
+func (s *Server) startExternalServe() error {
+	tlsConfig, err := s.cfg.Security.ToTLSConfig()
+	if err != nil {
+		return err
+	}
+	grpcServer := grpc.NewServer(externalGRPCServerOptions(s.etcdCfg)...)
+	s.etcdCfg.ServiceRegister(grpcServer)
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	for path, handler := range s.etcdCfg.UserHandlers {
+		mux.Handle(path, handler)
+	}
+	serve := &externalServe{
+		grpcServer: grpcServer,
+		httpServer: &http.Server{Handler: mux, ReadHeaderTimeout: externalServeReadHeaderTimeout},
+	}
+	s.externalServe = serve
+
+	for _, u := range s.etcdCfg.LCUrls {
+		l, err := net.Listen("tcp", u.Host)
+		if err != nil {
+			s.stopExternalServe()
+			return errors.WithStack(err)
+		}
+		serve.listeners = append(serve.listeners, l)
+		log.Info("serving client requests", zap.String("address", u.String()))
+		// The same as the embedded etcd, the gRPC requests are split by cmux
+		// without TLS, otherwise they are dispatched by the HTTP server.
+		if tlsConfig == nil {
+			m := cmux.New(l)
+			grpcL := m.MatchWithWriters(cmux.HTTP2MatchHeaderFieldSendSettings("content-type", "application/grpc"))
+			httpL := m.Match(cmux.Any())
+			go grpcServer.Serve(grpcL)
+			go serve.httpServer.Serve(httpL)
+			go m.Serve()
+			continue
+		}
+		serverTLSConfig := tlsConfig.Clone()
+		serverTLSConfig.ClientCAs = tlsConfig.RootCAs
+		serverTLSConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		serverTLSConfig.NextProtos = []string{"h2", "http/1.1"}
+		tlsServer := &http.Server{
+			Handler:           grpcHandlerFunc(grpcServer, mux),
+			TLSConfig:         serverTLSConfig,
+			ReadHeaderTimeout: externalServeReadHeaderTimeout,
+		}
+		serve.tlsServers = append(serve.tlsServers, tlsServer)
+		go tlsServer.ServeTLS(l, "", "")
+	}
+	return nil
+}
+
+// externalGRPCServerOptions returns the same message size and keepalive
+// options as the gRPC server of the embedded etcd.
+func externalGRPCServerOptions(cfg *embed.Config) []grpc.ServerOption {
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(int(cfg.MaxRequestBytes + grpcOverheadBytes)),
+		grpc.MaxSendMsgSize(math.MaxInt32),
+		grpc.MaxConcurrentStreams(cfg.MaxConcurrentStreams),
+	}
+	if cfg.GRPCKeepAliveMinTime > 0 {
+		opts = append(opts, grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             cfg.GRPCKeepAliveMinTime,
+			PermitWithoutStream: false,
+		}))
+	}
+	if cfg.GRPCKeepAliveInterval > 0 && cfg.GRPCKeepAliveTimeout > 0 {
+		opts = append(opts, grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    cfg.GRPCKeepAliveInterval,
+			Timeout: cfg.GRPCKeepAliveTimeout,
+		}))
+	}
+	return opts
+}
+
+func (s *Server) stopExternalServe() {
+	if s.externalServe == nil {
+		return
+	}
+	s.externalServe.grpcServer.Stop()
+	s.externalServe.httpServer.Close()
+	for _, tlsServer := range s.externalServe.tlsServers {
+		tlsServer.Close()
+	}
+	for _, l := range s.externalServe.listeners {
+		l.Close()
+	}
+	s.externalServe = nil
+}
+
+// grpcHandlerFunc dispatches the gRPC requests to the gRPC server and the
+// others to the HTTP handler.
+func grpcHandlerFunc(grpcServer *grpc.Server, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 && strings.Contains(r.Header.Get("Content-Type"), "application/grpc") {
+			grpcServer.ServeHTTP(w, r)
+		} else {
+			handler.ServeHTTP(w, r)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/utils/logutil"
+	"github.com/tikv/pd/server/cluster"
+)
+
+// followerRefreshInterval is the interval for a follower to refresh the configs
+// and stores, which are not synced by the region syncer.
+const followerRefreshInterval = 5 * time.Second
+
+// CheckFollowerRead checks whether the server can serve a read-only request from
+// its synced cache, given the max staleness accepted by the caller. It returns how
+// stale the cache may be. The leader always serves the request with no staleness.
+func (s *Server) CheckFollowerRead(maxStaleness time.Duration) (time.Duration, error) {
+	if s.IsClosed() {
+		return 0, errs.ErrFollowerReadUnavailable.FastGenByArgs("server is closed")
+	}
+	if s.member.IsLeader() {
+		return 0, nil
+	}
+	if !s.persistOptions.IsUseRegionStorage() {
+		return 0, errs.ErrFollowerReadUnavailable.FastGenByArgs("region storage is disabled")
+	}
+	lastSync := s.cluster.GetRegionSyncer().GetLastSyncTime()
+	lastRefresh := atomic.LoadInt64(&s.lastFollowerRefresh)
+	if lastSync.IsZero() || lastRefresh == 0 {
+		return 0, errs.ErrFollowerReadUnavailable.FastGenByArgs("not synced with the leader yet")
+	}
+	if refreshTime := time.Unix(0, lastRefresh); refreshTime.Before(lastSync) {
+		lastSync = refreshTime
+	}
+	staleness := time.Since(lastSync)
+	if staleness > maxStaleness {
+		return 0, errs.ErrFollowerReadUnavailable.FastGenByArgs(fmt.Sprintf("staleness %s exceeds %s", staleness, maxStaleness))
+	}
+	return staleness, nil
+}
+
+// GetFollowerReadCluster returns the cluster serving the follower reads. Only the
+// read-only queries of regions and stores are valid on it.
+func (s *Server) GetFollowerReadCluster() *cluster.RaftCluster {
+	return s.followerCluster
+}
+
+// SetFollowerReadable marks the service as readable from a follower's synced cache.
+func (s *Server) SetFollowerReadable(serviceLabel string) {
+	s.followerReadableServices[serviceLabel] = struct{}{}
+}
+
+// IsFollowerReadable returns whether the service is readable from a follower's synced cache.
+func (s *Server) IsFollowerReadable(serviceLabel string) bool {
+	_, ok := s.followerReadableServices[serviceLabel]
+	return ok
+}
+
+// startFollowerRefresh keeps the configs and stores of a follower up to date until
+// the returned function is called.
+func (s *Server) startFollowerRefresh() func() {
+	ctx, cancel := context.WithCancel(s.serverLoopCtx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer logutil.LogPanic()
+		defer wg.Done()
+		ticker := time.NewTicker(followerRefreshInterval)
+		defer ticker.Stop()
+		for {
+			if err := s.refreshFollowerCache(); err != nil {
+				log.Warn("failed to refresh the follower cache", errs.ZapError(err))
+			} else {
+				atomic.StoreInt64(&s.lastFollowerRefresh, time.Now().UnixNano())
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		wg.Wait()
+		atomic.StoreInt64(&s.lastFollowerRefresh, 0)
+	}
+}
+
+func (s *Server) refreshFollowerCache() error {
+	if err := s.persistOptions.Reload(s.storage); err != nil {
+		return err
+	}
+	return s.storage.LoadStores(s.basicCluster.PutStore)
+}
@@ -0,0 +1,178 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"go.uber.org/zap"
+)
+
+// gcBarrierServicePrefix prefixes the ID of a barrier taken as a service safepoint.
+const gcBarrierServicePrefix = "gc_barrier/"
+
+func validateGCBarrier(barrier *endpoint.GCBarrier) error {
+	if len(barrier.BarrierID) == 0 || strings.Contains(barrier.BarrierID, "/") {
+		return errors.Errorf("invalid GC barrier id %q", barrier.BarrierID)
+	}
+	startKey, err := hex.DecodeString(barrier.StartKey)
+	if err != nil {
+		return errors.Errorf("start key %s of GC barrier should be hex encoded", barrier.StartKey)
+	}
+	endKey, err := hex.DecodeString(barrier.EndKey)
+	if err != nil {
+		return errors.Errorf("end key %s of GC barrier should be hex encoded", barrier.EndKey)
+	}
+	if len(endKey) > 0 && bytes.Compare(startKey, endKey) >= 0 {
+		return errors.New("start key of GC barrier should be less than the end key")
+	}
+	return nil
+}
+
+// SetGCBarrier creates or updates a barrier of the keyspace, which blocks the GC
+// safepoint of the keyspace from passing it. The barrier never expires if ttl is
+// not positive. The barriers of the key ranges are enforced at the keyspace
+// level, so the other keyspaces are never blocked.
+func (manager *KeyspaceSafePointManager) SetGCBarrier(spaceID uint32, barrier *endpoint.GCBarrier, ttl int64, now time.Time) (*endpoint.GCBarrier, error) {
+	if err := validateGCBarrier(barrier); err != nil {
+		return nil, err
+	}
+	// The GC safepoint can't be updated until the barrier is set.
+	manager.gcLock.Lock(spaceID)
+	defer manager.gcLock.Unlock(spaceID)
+	manager.serviceGCLock.Lock(spaceID)
+	defer manager.serviceGCLock.Unlock(spaceID)
+	id := encodeSpaceID(spaceID)
+	gcSafePoint, err := manager.store.LoadKeyspaceGCSafePoint(id)
+	if err != nil {
+		return nil, err
+	}
+	if barrier.SafePoint < gcSafePoint {
+		return nil, errors.Errorf("safepoint %d of GC barrier is less than the GC safepoint %d", barrier.SafePoint, gcSafePoint)
+	}
+	current, err := manager.store.LoadGCBarrier(id, barrier.BarrierID)
+	if err != nil {
+		return nil, err
+	}
+	res := *barrier
+	res.CreatedAt = now.Unix()
+	if current != nil && current.ExpiredAt >= now.Unix() {
+		res.CreatedAt = current.CreatedAt
+	}
+	res.ExpiredAt = math.MaxInt64
+	if ttl > 0 {
+		res.ExpiredAt = leaseExpiredAt(now, ttl)
+	}
+	if err := manager.store.SaveGCBarrier(id, &res); err != nil {
+		return nil, err
+	}
+	log.Info("keyspace GC barrier set",
+		zap.Uint32("keyspace-id", spaceID),
+		zap.String("barrier-id", res.BarrierID),
+		zap.Uint64("safepoint", res.SafePoint),
+		zap.String("start-key", res.StartKey),
+		zap.String("end-key", res.EndKey),
+		zap.Int64("expired-at", res.ExpiredAt))
+	return &res, nil
+}
+
+// GetGCBarrier returns the barrier of the keyspace, nil if it does not exist or has expired.
+func (manager *KeyspaceSafePointManager) GetGCBarrier(spaceID uint32, barrierID string, now time.Time) (*endpoint.GCBarrier, error) {
+	barrier, err := manager.store.LoadGCBarrier(encodeSpaceID(spaceID), barrierID)
+	if err != nil || barrier == nil || barrier.ExpiredAt < now.Unix() {
+		return nil, err
+	}
+	return barrier, nil
+}
+
+// LoadAllGCBarriers returns all the unexpired barriers of the keyspace, the
+// expired ones are removed.
+func (manager *KeyspaceSafePointManager) LoadAllGCBarriers(spaceID uint32, now time.Time) ([]*endpoint.GCBarrier, error) {
+	id := encodeSpaceID(spaceID)
+	barriers, err := manager.store.LoadAllGCBarriers(id)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*endpoint.GCBarrier, 0, len(barriers))
+	for _, barrier := range barriers {
+		if barrier.ExpiredAt >= now.Unix() {
+			res = append(res, barrier)
+			continue
+		}
+		if err := manager.store.RemoveGCBarrier(id, barrier.BarrierID); err != nil {
+			log.Warn("failed to remove the expired keyspace GC barrier",
+				zap.Uint32("keyspace-id", spaceID),
+				zap.String("barrier-id", barrier.BarrierID),
+				errs.ZapError(err))
+		}
+	}
+	return res, nil
+}
+
+// RemoveGCBarrier removes the barrier of the keyspace.
+func (manager *KeyspaceSafePointManager) RemoveGCBarrier(spaceID uint32, barrierID string) error {
+	manager.serviceGCLock.Lock(spaceID)
+	defer manager.serviceGCLock.Unlock(spaceID)
+	if err := manager.store.RemoveGCBarrier(encodeSpaceID(spaceID), barrierID); err != nil {
+		return err
+	}
+	log.Info("keyspace GC barrier removed", zap.Uint32("keyspace-id", spaceID), zap.String("barrier-id", barrierID))
+	return nil
+}
+
+// loadGCBarrierSafePoints returns the unexpired barriers of the keyspace as the
+// service safepoints, so they hold back the GC safepoint in the same way.
+func (manager *KeyspaceSafePointManager) loadGCBarrierSafePoints(spaceID uint32, now time.Time) ([]*endpoint.ServiceSafePoint, error) {
+	barriers, err := manager.LoadAllGCBarriers(spaceID, now)
+	if err != nil {
+		return nil, err
+	}
+	ssps := make([]*endpoint.ServiceSafePoint, 0, len(barriers))
+	for _, barrier := range barriers {
+		ssps = append(ssps, &endpoint.ServiceSafePoint{
+			ServiceID: gcBarrierServicePrefix + barrier.BarrierID,
+			ExpiredAt: barrier.ExpiredAt,
+			SafePoint: barrier.SafePoint,
+		})
+	}
+	return ssps, nil
+}
+
+// loadMinServiceGCSafePoint returns the min of the service safepoints and the
+// barriers of the keyspace, nil if there is none.
+func (manager *KeyspaceSafePointManager) loadMinServiceGCSafePoint(spaceID uint32, now time.Time) (*endpoint.ServiceSafePoint, error) {
+	min, err := manager.store.LoadMinServiceSafePoint(encodeSpaceID(spaceID), now)
+	if err != nil {
+		return nil, err
+	}
+	barriers, err := manager.loadGCBarrierSafePoints(spaceID, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, barrier := range barriers {
+		if min == nil || barrier.SafePoint < min.SafePoint {
+			min = barrier
+		}
+	}
+	return min, nil
+}
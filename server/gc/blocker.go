@@ -0,0 +1,169 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+)
+
+// The kinds of the GC blockers.
+const (
+	// GCBlockerService is a service safepoint, e.g. of CDC or BR.
+	GCBlockerService = "service"
+	// GCBlockerGCWorker is the safepoint of the GC worker, which is held back
+	// by the GC life time or the oldest running transaction.
+	GCBlockerGCWorker = "gc_worker"
+	// GCBlockerBarrier is a GC barrier of a keyspace.
+	GCBlockerBarrier = "barrier"
+)
+
+// GCBlocker is a service safepoint or a barrier which may hold back the GC
+// safepoint. The ServiceID is the barrier ID for a barrier.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type GCBlocker struct {
+	Kind      string `json:"kind"`
+	ServiceID string `json:"service_id"`
+	// Owner is the holder of the lease, empty if the safepoint is not leased.
+	Owner     string `json:"owner,omitempty"`
+	SafePoint uint64 `json:"safe_point"`
+	// Age is the seconds the safepoint is behind the current time.
+	Age       int64 `json:"age"`
+	ExpiredAt int64 `json:"expired_at"`
+}
+
+// GCBlockers explains what holds back the GC safepoint.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type GCBlockers struct {
+	GCSafePoint uint64 `json:"gc_safe_point"`
+	// Blocker is the min service safepoint, which the GC safepoint can't exceed.
+	// It's nil if there is no service safepoint.
+	Blocker *GCBlocker `json:"blocker,omitempty"`
+	// Blockers are all the unexpired service safepoints from the oldest, the
+	// next one blocks GC once the previous one advances or is removed.
+	Blockers []*GCBlocker `json:"blockers"`
+	// Pause stops the GC safepoint from advancing, nil if GC is not paused.
+	Pause *endpoint.GCPause `json:"pause,omitempty"`
+}
+
+// KeyspaceGCBlockers explains what holds back the GC safepoint of a keyspace
+// which runs GC by itself.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type KeyspaceGCBlockers struct {
+	KeyspaceID uint32 `json:"keyspace_id"`
+	GCBlockers
+}
+
+func newGCBlocker(ssp *endpoint.ServiceSafePoint, now time.Time) *GCBlocker {
+	blocker := &GCBlocker{
+		Kind:      GCBlockerService,
+		ServiceID: ssp.ServiceID,
+		Owner:     ssp.Owner,
+		SafePoint: ssp.SafePoint,
+		Age:       int64(safePointAge(ssp.SafePoint, now).Seconds()),
+		ExpiredAt: ssp.ExpiredAt,
+	}
+	switch {
+	case ssp.ServiceID == gcWorkerServiceID:
+		blocker.Kind = GCBlockerGCWorker
+	case strings.HasPrefix(ssp.ServiceID, gcBarrierServicePrefix):
+		blocker.Kind = GCBlockerBarrier
+		blocker.ServiceID = strings.TrimPrefix(ssp.ServiceID, gcBarrierServicePrefix)
+	}
+	return blocker
+}
+
+// newGCBlockers sorts the unexpired service safepoints from the oldest. The
+// GC worker is put after the services with the same safepoint, since it only
+// follows them.
+func newGCBlockers(gcSafePoint uint64, ssps []*endpoint.ServiceSafePoint, now time.Time) GCBlockers {
+	res := GCBlockers{GCSafePoint: gcSafePoint, Blockers: make([]*GCBlocker, 0, len(ssps))}
+	for _, ssp := range ssps {
+		if ssp.ExpiredAt < now.Unix() {
+			continue
+		}
+		res.Blockers = append(res.Blockers, newGCBlocker(ssp, now))
+	}
+	sort.SliceStable(res.Blockers, func(i, j int) bool {
+		if res.Blockers[i].SafePoint != res.Blockers[j].SafePoint {
+			return res.Blockers[i].SafePoint < res.Blockers[j].SafePoint
+		}
+		return res.Blockers[i].Kind == GCBlockerService && res.Blockers[j].Kind != GCBlockerService
+	})
+	if len(res.Blockers) > 0 {
+		res.Blocker = res.Blockers[0]
+	}
+	return res
+}
+
+// GetGCBlockers returns what holds back the GC safepoint.
+func (manager *SafePointManager) GetGCBlockers(now time.Time) (*GCBlockers, error) {
+	gcSafePoint, err := manager.store.LoadGCSafePoint()
+	if err != nil {
+		return nil, err
+	}
+	ssps, err := manager.store.LoadAllServiceGCSafePoints()
+	if err != nil {
+		return nil, err
+	}
+	res := newGCBlockers(gcSafePoint, ssps, now)
+	if res.Pause, err = manager.GetGCPause(now); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GetGCBlockers returns what holds back the GC safepoints of the keyspaces
+// which run GC by themselves, from the one held back the most. The barriers are
+// also the blockers.
+func (manager *KeyspaceSafePointManager) GetGCBlockers(now time.Time) ([]*KeyspaceGCBlockers, error) {
+	safePoints, err := manager.store.LoadAllKeyspaceGCSafePoints(true)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*KeyspaceGCBlockers, 0, len(safePoints))
+	for _, safePoint := range safePoints {
+		id, err := strconv.ParseUint(safePoint.SpaceID, endpoint.SpaceIDBase, 32)
+		if err != nil {
+			return nil, errs.ErrStrconvParseUint.Wrap(err).GenWithStackByArgs()
+		}
+		ssps, err := manager.store.LoadAllServiceSafePoints(safePoint.SpaceID, now)
+		if err != nil {
+			return nil, err
+		}
+		barriers, err := manager.loadGCBarrierSafePoints(uint32(id), now)
+		if err != nil {
+			return nil, err
+		}
+		ssps = append(ssps, barriers...)
+		res = append(res, &KeyspaceGCBlockers{
+			KeyspaceID: uint32(id),
+			GCBlockers: newGCBlockers(safePoint.SafePoint, ssps, now),
+		})
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		a, b := res[i].Blocker, res[j].Blocker
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		return a.SafePoint < b.SafePoint
+	})
+	return res, nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/storage/kv"
+	"github.com/tikv/pd/pkg/utils/tsoutil"
+	"github.com/tikv/pd/server/config"
+)
+
+func TestGetGCBlockers(t *testing.T) {
+	re := require.New(t)
+	storage := endpoint.NewStorageEndpoint(kv.NewMemoryKV(), nil)
+	manager := NewSafePointManager(storage, config.GCConfig{})
+	now := time.Now()
+	hourAgo := tsoutil.ComposeTS(now.Add(-time.Hour).UnixNano()/int64(time.Millisecond), 0)
+
+	// The GC worker is put after the services with the same safepoint.
+	_, _, err := manager.UpdateServiceGCSafePoint(gcWorkerServiceID, hourAgo, math.MaxInt64, now)
+	re.NoError(err)
+	_, err = manager.AcquireServiceSafePointLease("cdc", "changefeed-1", hourAgo, 600, now)
+	re.NoError(err)
+	_, _, err = manager.UpdateServiceGCSafePoint("br", hourAgo+1, 600, now)
+	re.NoError(err)
+	_, _, err = manager.UpdateServiceGCSafePoint("expired", hourAgo, 1, now.Add(-time.Minute))
+	re.NoError(err)
+	_, _, err = manager.UpdateGCSafePoint(10)
+	re.NoError(err)
+
+	blockers, err := manager.GetGCBlockers(now)
+	re.NoError(err)
+	re.Equal(uint64(10), blockers.GCSafePoint)
+	re.Len(blockers.Blockers, 3)
+	re.Equal("cdc", blockers.Blocker.ServiceID)
+	re.Equal(GCBlockerService, blockers.Blocker.Kind)
+	re.Equal("changefeed-1", blockers.Blocker.Owner)
+	re.InDelta(int64(time.Hour.Seconds()), blockers.Blocker.Age, 1)
+	re.Equal(GCBlockerGCWorker, blockers.Blockers[1].Kind)
+	re.Equal("br", blockers.Blockers[2].ServiceID)
+
+	keyspaceManager := NewKeyspaceSafePointManager(storage)
+	for id, safePoint := range map[uint32]uint64{1: hourAgo + 2, 2: hourAgo + 1} {
+		_, err = keyspaceManager.UpdateGCSafePoint(id, 1)
+		re.NoError(err)
+		_, _, err = keyspaceManager.UpdateServiceGCSafePoint(id, "br", safePoint, 600, now)
+		re.NoError(err)
+	}
+	_, err = keyspaceManager.UpdateGCSafePoint(3, 1)
+	re.NoError(err)
+	keyspaces, err := keyspaceManager.GetGCBlockers(now)
+	re.NoError(err)
+	re.Len(keyspaces, 3)
+	re.Equal(uint32(2), keyspaces[0].KeyspaceID)
+	re.Equal(uint32(1), keyspaces[1].KeyspaceID)
+	re.Equal(uint64(1), keyspaces[1].GCSafePoint)
+	re.Equal(uint32(3), keyspaces[2].KeyspaceID)
+	re.Nil(keyspaces[2].Blocker)
+}
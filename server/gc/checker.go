@@ -0,0 +1,247 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/event"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/utils/apiutil"
+	"github.com/tikv/pd/pkg/utils/logutil"
+	"github.com/tikv/pd/pkg/utils/syncutil"
+	"github.com/tikv/pd/pkg/utils/tsoutil"
+	"github.com/tikv/pd/server/config"
+	"go.uber.org/zap"
+)
+
+const (
+	// webhookTimeout is the timeout to notify the safepoint webhook.
+	webhookTimeout = 10 * time.Second
+	// maxSafePointEvents is the number of the recent events kept in memory.
+	maxSafePointEvents = 256
+)
+
+// The types of the service safepoint events.
+const (
+	// SafePointEventBlocking means the safepoint stays behind longer than the threshold.
+	SafePointEventBlocking = "blocking"
+	// SafePointEventExpired means the lease of the safepoint expired without renewal.
+	SafePointEventExpired = "expired"
+)
+
+// SafePointEvent is a notification about a service safepoint.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type SafePointEvent struct {
+	Type      string `json:"type"`
+	ServiceID string `json:"service_id"`
+	Owner     string `json:"owner,omitempty"`
+	SafePoint uint64 `json:"safe_point"`
+	// Age is the seconds the safepoint is behind the time of the event.
+	Age int64 `json:"age"`
+	// Time is the unix timestamp in seconds when the event happens.
+	Time int64 `json:"time"`
+}
+
+// ServiceSafePointChecker checks the service safepoints in the background, and
+// notifies the ones blocking GC and the leases expired, so the forgotten
+// safepoints are surfaced.
+type ServiceSafePointChecker struct {
+	manager *SafePointManager
+	config  config.GCConfig
+	// webhookClient notifies the safepoint webhook.
+	webhookClient *http.Client
+	// publish publishes the events as the cluster events, nil if not set.
+	publish func(*event.Event)
+
+	mu syncutil.Mutex
+	// leases are the live leases in the last check, to find the expired ones
+	// removed afterwards.
+	leases map[string]*endpoint.ServiceSafePoint
+	// blocking are the services blocking GC in the last check.
+	blocking map[string]struct{}
+	// events are the recent events from the oldest.
+	events []*SafePointEvent
+}
+
+// NewServiceSafePointChecker creates a ServiceSafePointChecker with the config of the manager.
+func NewServiceSafePointChecker(manager *SafePointManager) *ServiceSafePointChecker {
+	return &ServiceSafePointChecker{
+		manager:       manager,
+		config:        manager.config,
+		webhookClient: &http.Client{Timeout: webhookTimeout},
+		leases:        make(map[string]*endpoint.ServiceSafePoint),
+		blocking:      make(map[string]struct{}),
+	}
+}
+
+// SetEventPublisher sets the function to publish the events as the cluster events.
+func (c *ServiceSafePointChecker) SetEventPublisher(publish func(*event.Event)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.publish = publish
+}
+
+// StartChecker starts checking the service safepoints in the background, which
+// is stopped once the context is canceled. It is called when the server becomes leader.
+func (c *ServiceSafePointChecker) StartChecker(ctx context.Context) {
+	go c.runChecker(ctx)
+}
+
+func (c *ServiceSafePointChecker) runChecker(ctx context.Context) {
+	defer logutil.LogPanic()
+	defer c.resetState()
+	ticker := time.NewTicker(c.config.SafePointCheckInterval.Duration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := c.check(time.Now()); err != nil {
+			log.Warn("failed to check service safepoints", errs.ZapError(err))
+		}
+	}
+}
+
+// GetEvents returns the recent events from the oldest. The events are kept in
+// memory, so they are lost when the leader changes.
+func (c *ServiceSafePointChecker) GetEvents() []*SafePointEvent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*SafePointEvent{}, c.events...)
+}
+
+// check updates the ages of the service safepoints, and notifies the ones which
+// just start blocking GC and the leases which just expire.
+func (c *ServiceSafePointChecker) check(now time.Time) error {
+	ssps, err := c.manager.store.LoadAllServiceGCSafePoints()
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	serviceSafePointAgeGauge.Reset()
+	leases := make(map[string]*endpoint.ServiceSafePoint)
+	blocking := make(map[string]struct{})
+	for _, ssp := range ssps {
+		if ssp.ServiceID == gcWorkerServiceID {
+			continue
+		}
+		age := safePointAge(ssp.SafePoint, now)
+		if ssp.ExpiredAt < now.Unix() {
+			if _, ok := c.leases[ssp.ServiceID]; ok {
+				c.notify(ssp, SafePointEventExpired, age, now)
+				delete(c.leases, ssp.ServiceID)
+			}
+			continue
+		}
+		if ssp.IsLeased() {
+			leases[ssp.ServiceID] = ssp
+		}
+		serviceSafePointAgeGauge.WithLabelValues(ssp.ServiceID).Set(age.Seconds())
+		if age <= c.config.SafePointBlockThreshold.Duration {
+			continue
+		}
+		blocking[ssp.ServiceID] = struct{}{}
+		if _, ok := c.blocking[ssp.ServiceID]; !ok {
+			c.notify(ssp, SafePointEventBlocking, age, now)
+		}
+	}
+	// The lease removed after it expires is also notified, the others are released.
+	for id, lease := range c.leases {
+		if _, ok := leases[id]; !ok && lease.ExpiredAt < now.Unix() {
+			c.notify(lease, SafePointEventExpired, safePointAge(lease.SafePoint, now), now)
+		}
+	}
+	c.leases, c.blocking = leases, blocking
+	return nil
+}
+
+func safePointAge(safePoint uint64, now time.Time) time.Duration {
+	physical, _ := tsoutil.ParseTS(safePoint)
+	return now.Sub(physical)
+}
+
+func (c *ServiceSafePointChecker) notify(ssp *endpoint.ServiceSafePoint, typ string, age time.Duration, now time.Time) {
+	event := &SafePointEvent{
+		Type:      typ,
+		ServiceID: ssp.ServiceID,
+		Owner:     ssp.Owner,
+		SafePoint: ssp.SafePoint,
+		Age:       int64(age.Seconds()),
+		Time:      now.Unix(),
+	}
+	c.events = append(c.events, event)
+	if len(c.events) > maxSafePointEvents {
+		c.events = c.events[len(c.events)-maxSafePointEvents:]
+	}
+	safePointEventCounter.WithLabelValues(ssp.ServiceID, typ).Inc()
+	log.Warn("service safepoint may block GC",
+		zap.String("event", typ),
+		zap.String("service-id", ssp.ServiceID),
+		zap.String("owner", ssp.Owner),
+		zap.Uint64("safepoint", ssp.SafePoint),
+		zap.Duration("age", age),
+	)
+	if c.publish != nil {
+		c.publish(newClusterEvent(event))
+	}
+	if len(c.config.SafePointWebhook) == 0 {
+		return
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Warn("failed to marshal service safepoint event", errs.ZapError(errs.ErrJSONMarshal.Wrap(err).GenWithStackByCause()))
+		return
+	}
+	if err := apiutil.PostJSONIgnoreResp(c.webhookClient, c.config.SafePointWebhook, data); err != nil {
+		log.Warn("failed to notify the service safepoint webhook",
+			zap.String("webhook", c.config.SafePointWebhook),
+			errs.ZapError(err),
+		)
+	}
+}
+
+func newClusterEvent(e *SafePointEvent) *event.Event {
+	typ := event.GCBlocked
+	if e.Type == SafePointEventExpired {
+		typ = event.GCSafePointExpired
+	}
+	attributes := map[string]string{
+		"service_id": e.ServiceID,
+		"safe_point": strconv.FormatUint(e.SafePoint, 10),
+		"age":        strconv.FormatInt(e.Age, 10),
+	}
+	if len(e.Owner) > 0 {
+		attributes["owner"] = e.Owner
+	}
+	return &event.Event{Type: typ, Time: time.Unix(e.Time, 0), Attributes: attributes}
+}
+
+func (c *ServiceSafePointChecker) resetState() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.leases = make(map[string]*endpoint.ServiceSafePoint)
+	c.blocking = make(map[string]struct{})
+	serviceSafePointAgeGauge.Reset()
+}
@@ -0,0 +1,186 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/utils/logutil"
+	"github.com/tikv/pd/pkg/utils/syncutil"
+	"github.com/tikv/pd/pkg/utils/tsoutil"
+	"github.com/tikv/pd/pkg/utils/typeutil"
+	"github.com/tikv/pd/server/config"
+	"go.uber.org/zap"
+)
+
+// maxGCRounds is the number of the recent rounds kept in memory.
+const maxGCRounds = 32
+
+// ErrControllerDisabled is returned when the GC controller is not enabled.
+var ErrControllerDisabled = errors.New("GC controller is not enabled")
+
+// GCRound is a round of the GC controller advancing the GC safepoint.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type GCRound struct {
+	// StartTime is the unix timestamp in seconds when the round starts.
+	StartTime int64 `json:"start_time"`
+	// Target is the safepoint by the GC life time.
+	Target uint64 `json:"target"`
+	// Blocker is the service safepoint holding back the target, empty if none.
+	Blocker      string `json:"blocker,omitempty"`
+	OldSafePoint uint64 `json:"old_safe_point"`
+	SafePoint    uint64 `json:"safe_point"`
+	// Limited means the safepoint is paused or limited by the advancement rate.
+	Limited bool   `json:"limited,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ControllerStatus is the status of the GC controller.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type ControllerStatus struct {
+	Enabled     bool              `json:"enabled"`
+	LifeTime    typeutil.Duration `json:"life_time"`
+	RunInterval typeutil.Duration `json:"run_interval"`
+	// Rounds are the recent rounds from the oldest.
+	Rounds []*GCRound `json:"rounds"`
+}
+
+// Controller advances the GC safepoint on behalf of the GC worker of TiDB, so
+// the clusters without TiDB, e.g. the raw KV clusters, get managed GC. The GC
+// is not dispatched to the stores by PD, the stores collect the garbage by the
+// GC safepoint they pull from PD, i.e. the GC compaction filter of TiKV. So the
+// progress is tracked by the advancement of the GC safepoint rather than per
+// store. It does not resolve the locks, so it should not be used by the
+// transactional clusters whose locks are resolved by TiDB.
+type Controller struct {
+	manager *SafePointManager
+	config  config.GCConfig
+	// getTS returns a timestamp from the TSO.
+	getTS func() (uint64, error)
+
+	// runMu makes the rounds run one by one.
+	runMu syncutil.Mutex
+	mu    syncutil.Mutex
+	// rounds are the recent rounds from the oldest.
+	rounds []*GCRound
+}
+
+// NewController creates a GC controller with the config of the manager.
+func NewController(manager *SafePointManager, getTS func() (uint64, error)) *Controller {
+	return &Controller{
+		manager: manager,
+		config:  manager.config,
+		getTS:   getTS,
+	}
+}
+
+// StartController starts advancing the GC safepoint in the background if the
+// controller is enabled, which is stopped once the context is canceled. It is
+// called when the server becomes leader.
+func (c *Controller) StartController(ctx context.Context) {
+	if !c.config.EnableController {
+		return
+	}
+	go c.runController(ctx)
+}
+
+func (c *Controller) runController(ctx context.Context) {
+	defer logutil.LogPanic()
+	ticker := time.NewTicker(c.config.RunInterval.Duration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		c.runRound()
+	}
+}
+
+// RunRound advances the GC safepoint immediately.
+func (c *Controller) RunRound() (*GCRound, error) {
+	if !c.config.EnableController {
+		return nil, errors.WithStack(ErrControllerDisabled)
+	}
+	return c.runRound(), nil
+}
+
+// GetStatus returns the status of the controller. The rounds are kept in memory,
+// so they are lost when the leader changes.
+func (c *Controller) GetStatus() *ControllerStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &ControllerStatus{
+		Enabled:     c.config.EnableController,
+		LifeTime:    c.config.LifeTime,
+		RunInterval: c.config.RunInterval,
+		Rounds:      append([]*GCRound{}, c.rounds...),
+	}
+}
+
+func (c *Controller) runRound() *GCRound {
+	c.runMu.Lock()
+	defer c.runMu.Unlock()
+	round := &GCRound{StartTime: time.Now().Unix()}
+	if err := c.advance(round); err != nil {
+		round.Error = err.Error()
+		log.Warn("failed to advance GC safepoint", errs.ZapError(err))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rounds = append(c.rounds, round)
+	if len(c.rounds) > maxGCRounds {
+		c.rounds = c.rounds[len(c.rounds)-maxGCRounds:]
+	}
+	return round
+}
+
+// advance moves the safepoint of the GC worker to the target by the life time,
+// and then the GC safepoint to the min service safepoint.
+func (c *Controller) advance(round *GCRound) error {
+	ts, err := c.getTS()
+	if err != nil {
+		return err
+	}
+	now, _ := tsoutil.ParseTS(ts)
+	round.Target = tsoutil.ComposeTS(now.Add(-c.config.LifeTime.Duration).UnixNano()/int64(time.Millisecond), 0)
+	min, _, err := c.manager.UpdateServiceGCSafePoint(gcWorkerServiceID, round.Target, math.MaxInt64, now)
+	if err != nil {
+		return err
+	}
+	safePoint := round.Target
+	if min.ServiceID != gcWorkerServiceID && min.SafePoint < safePoint {
+		safePoint, round.Blocker = min.SafePoint, min.ServiceID
+	}
+	round.OldSafePoint, round.SafePoint, err = c.manager.UpdateGCSafePoint(safePoint)
+	if err != nil {
+		return err
+	}
+	round.Limited = round.SafePoint < safePoint
+	safePointLagGauge.Set(safePointAge(round.SafePoint, now).Seconds())
+	log.Info("GC controller advanced GC safepoint",
+		zap.Uint64("target", round.Target),
+		zap.String("blocker", round.Blocker),
+		zap.Uint64("old-safe-point", round.OldSafePoint),
+		zap.Uint64("safe-point", round.SafePoint),
+		zap.Bool("limited", round.Limited))
+	return nil
+}
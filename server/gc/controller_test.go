@@ -0,0 +1,75 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/utils/tsoutil"
+	"github.com/tikv/pd/pkg/utils/typeutil"
+	"github.com/tikv/pd/server/config"
+)
+
+func TestGCController(t *testing.T) {
+	re := require.New(t)
+	now := time.Now()
+	toTS := func(t time.Time) uint64 {
+		return tsoutil.ComposeTS(t.UnixNano()/int64(time.Millisecond), 0)
+	}
+	getTS := func() (uint64, error) {
+		return toTS(now), nil
+	}
+	disabled := NewController(NewSafePointManager(newGCStorage(), config.GCConfig{}), getTS)
+	_, err := disabled.RunRound()
+	re.Equal(ErrControllerDisabled, errors.Cause(err))
+
+	manager := NewSafePointManager(newGCStorage(), config.GCConfig{
+		EnableController: true,
+		LifeTime:         typeutil.NewDuration(10 * time.Minute),
+	})
+	controller := NewController(manager, getTS)
+	round, err := controller.RunRound()
+	re.NoError(err)
+	re.Empty(round.Error)
+	re.Equal(toTS(now.Add(-10*time.Minute)), round.Target)
+	re.Equal(round.Target, round.SafePoint)
+	re.Empty(round.Blocker)
+
+	// The service safepoint holds back the GC safepoint.
+	blocked := toTS(now.Add(-5 * time.Minute))
+	_, err = manager.AcquireServiceSafePointLease("br", "backup-1", blocked, 3600, now)
+	re.NoError(err)
+	now = now.Add(time.Hour)
+	round, err = controller.RunRound()
+	re.NoError(err)
+	re.Equal("br", round.Blocker)
+	re.Equal(blocked, round.SafePoint)
+
+	// The pause limits the GC safepoint.
+	re.NoError(manager.ReleaseServiceSafePointLease("br", "backup-1", time.Now()))
+	_, err = manager.PauseGC(time.Hour, "investigation", false, time.Now())
+	re.NoError(err)
+	round, err = controller.RunRound()
+	re.NoError(err)
+	re.True(round.Limited)
+	re.Equal(blocked, round.SafePoint)
+
+	status := controller.GetStatus()
+	re.True(status.Enabled)
+	re.Len(status.Rounds, 3)
+}
@@ -0,0 +1,135 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/utils/syncutil"
+)
+
+// KeyspaceSafePointManager is the manager for safePoint of GC and services of
+// keyspaces. The safePoints of each keyspace are isolated from the others, so
+// a stale service safePoint of one keyspace does not block the GC of the others.
+type KeyspaceSafePointManager struct {
+	gcLock        *syncutil.LockGroup
+	serviceGCLock *syncutil.LockGroup
+	store         endpoint.KeyspaceGCSafePointStorage
+}
+
+// NewKeyspaceSafePointManager creates a KeyspaceSafePointManager of GC and services.
+func NewKeyspaceSafePointManager(store endpoint.KeyspaceGCSafePointStorage) *KeyspaceSafePointManager {
+	return &KeyspaceSafePointManager{
+		gcLock:        syncutil.NewLockGroup(syncutil.WithHash(keyspaceIDHash)),
+		serviceGCLock: syncutil.NewLockGroup(syncutil.WithHash(keyspaceIDHash)),
+		store:         store,
+	}
+}
+
+// keyspaceIDHash limits the number of the locks in the lock groups to 256.
+func keyspaceIDHash(id uint32) uint32 {
+	return id & 0xFF
+}
+
+func encodeSpaceID(spaceID uint32) string {
+	return strconv.FormatUint(uint64(spaceID), endpoint.SpaceIDBase)
+}
+
+// LoadGCSafePoint loads current GC safe point of the keyspace from storage.
+func (manager *KeyspaceSafePointManager) LoadGCSafePoint(spaceID uint32) (uint64, error) {
+	return manager.store.LoadKeyspaceGCSafePoint(encodeSpaceID(spaceID))
+}
+
+// UpdateGCSafePoint updates the safepoint of the keyspace if it is greater than
+// the previous one, it returns the old safepoint in the storage. The safepoint
+// is limited by the barriers of the keyspace.
+func (manager *KeyspaceSafePointManager) UpdateGCSafePoint(spaceID uint32, newSafePoint uint64) (oldSafePoint uint64, err error) {
+	manager.gcLock.Lock(spaceID)
+	defer manager.gcLock.Unlock(spaceID)
+	oldSafePoint, err = manager.store.LoadKeyspaceGCSafePoint(encodeSpaceID(spaceID))
+	if err != nil {
+		return
+	}
+	// The GC safepoint can't pass the barriers.
+	barriers, err := manager.loadGCBarrierSafePoints(spaceID, time.Now())
+	if err != nil {
+		return
+	}
+	for _, barrier := range barriers {
+		if barrier.SafePoint < newSafePoint {
+			newSafePoint = barrier.SafePoint
+		}
+	}
+	if oldSafePoint >= newSafePoint {
+		return
+	}
+	err = manager.store.SaveKeyspaceGCSafePoint(encodeSpaceID(spaceID), newSafePoint)
+	return
+}
+
+// LoadMinServiceGCSafePoint returns the minimum service safepoint of the keyspace,
+// including the barriers. It returns nil if the keyspace has no unexpired service
+// safepoint or barrier.
+func (manager *KeyspaceSafePointManager) LoadMinServiceGCSafePoint(spaceID uint32, now time.Time) (*endpoint.ServiceSafePoint, error) {
+	return manager.loadMinServiceGCSafePoint(spaceID, now)
+}
+
+// LoadAllServiceGCSafePoints returns all the unexpired service safepoints of the keyspace.
+func (manager *KeyspaceSafePointManager) LoadAllServiceGCSafePoints(spaceID uint32, now time.Time) ([]*endpoint.ServiceSafePoint, error) {
+	return manager.store.LoadAllServiceSafePoints(encodeSpaceID(spaceID), now)
+}
+
+// UpdateServiceGCSafePoint updates the safepoint for a specific service of the keyspace.
+// The safepoint is not updated if it is less than the min service safepoint or the GC
+// safepoint of the keyspace, since the data before them may have been collected.
+func (manager *KeyspaceSafePointManager) UpdateServiceGCSafePoint(spaceID uint32, serviceID string, newSafePoint uint64, ttl int64, now time.Time) (minServiceSafePoint *endpoint.ServiceSafePoint, updated bool, err error) {
+	manager.serviceGCLock.Lock(spaceID)
+	defer manager.serviceGCLock.Unlock(spaceID)
+	id := encodeSpaceID(spaceID)
+	minServiceSafePoint, err = manager.loadMinServiceGCSafePoint(spaceID, now)
+	if err != nil || ttl <= 0 || (minServiceSafePoint != nil && newSafePoint < minServiceSafePoint.SafePoint) {
+		return minServiceSafePoint, false, err
+	}
+	gcSafePoint, err := manager.store.LoadKeyspaceGCSafePoint(id)
+	if err != nil || newSafePoint < gcSafePoint {
+		return minServiceSafePoint, false, err
+	}
+
+	ssp := &endpoint.ServiceSafePoint{
+		ServiceID: serviceID,
+		ExpiredAt: now.Unix() + ttl,
+		SafePoint: newSafePoint,
+	}
+	if math.MaxInt64-now.Unix() <= ttl {
+		ssp.ExpiredAt = math.MaxInt64
+	}
+	if err := manager.store.SaveServiceSafePoint(id, ssp); err != nil {
+		return nil, false, err
+	}
+
+	// The min safePoint may be changed by the update, load it again.
+	minServiceSafePoint, err = manager.loadMinServiceGCSafePoint(spaceID, now)
+	return minServiceSafePoint, true, err
+}
+
+// RemoveServiceGCSafePoint removes the safepoint of a specific service of the keyspace.
+func (manager *KeyspaceSafePointManager) RemoveServiceGCSafePoint(spaceID uint32, serviceID string) error {
+	manager.serviceGCLock.Lock(spaceID)
+	defer manager.serviceGCLock.Unlock(spaceID)
+	return manager.store.RemoveServiceSafePoint(encodeSpaceID(spaceID), serviceID)
+}
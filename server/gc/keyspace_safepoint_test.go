@@ -0,0 +1,165 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/storage/kv"
+)
+
+func newKeyspaceSafePointManager() *KeyspaceSafePointManager {
+	return NewKeyspaceSafePointManager(endpoint.NewStorageEndpoint(kv.NewMemoryKV(), nil))
+}
+
+func TestKeyspaceGCSafePointUpdate(t *testing.T) {
+	re := require.New(t)
+	manager := newKeyspaceSafePointManager()
+	for spaceID := uint32(1); spaceID <= 3; spaceID++ {
+		safePoint, err := manager.LoadGCSafePoint(spaceID)
+		re.NoError(err)
+		re.Zero(safePoint)
+		oldSafePoint, err := manager.UpdateGCSafePoint(spaceID, uint64(spaceID)*10)
+		re.NoError(err)
+		re.Zero(oldSafePoint)
+	}
+	// The GC safepoints of the keyspaces are isolated.
+	for spaceID := uint32(1); spaceID <= 3; spaceID++ {
+		safePoint, err := manager.LoadGCSafePoint(spaceID)
+		re.NoError(err)
+		re.Equal(uint64(spaceID)*10, safePoint)
+	}
+	// Update with a smaller value should not change the safepoint.
+	oldSafePoint, err := manager.UpdateGCSafePoint(3, 5)
+	re.NoError(err)
+	re.Equal(uint64(30), oldSafePoint)
+	safePoint, err := manager.LoadGCSafePoint(3)
+	re.NoError(err)
+	re.Equal(uint64(30), safePoint)
+}
+
+func TestKeyspaceServiceGCSafePointUpdate(t *testing.T) {
+	re := require.New(t)
+	manager := newKeyspaceSafePointManager()
+	now := time.Now()
+	// A stale service safepoint of keyspace 1 does not block keyspace 2.
+	min, updated, err := manager.UpdateServiceGCSafePoint(1, "cdc", 10, 1000, now)
+	re.NoError(err)
+	re.True(updated)
+	re.Equal("cdc", min.ServiceID)
+	min, updated, err = manager.UpdateServiceGCSafePoint(2, "br", 100, 1000, now)
+	re.NoError(err)
+	re.True(updated)
+	re.Equal(uint64(100), min.SafePoint)
+	min, err = manager.LoadMinServiceGCSafePoint(2, now)
+	re.NoError(err)
+	re.Equal("br", min.ServiceID)
+
+	// The value shouldn't be updated if it is smaller than the min safepoint.
+	_, updated, err = manager.UpdateServiceGCSafePoint(2, "cdc", 50, 1000, now)
+	re.NoError(err)
+	re.False(updated)
+	// The value shouldn't be updated with a non-positive ttl.
+	_, updated, err = manager.UpdateServiceGCSafePoint(2, "cdc", 200, 0, now)
+	re.NoError(err)
+	re.False(updated)
+	// The value shouldn't be updated if it is smaller than the GC safepoint.
+	_, err = manager.UpdateGCSafePoint(3, 500)
+	re.NoError(err)
+	_, updated, err = manager.UpdateServiceGCSafePoint(3, "br", 400, 1000, now)
+	re.NoError(err)
+	re.False(updated)
+
+	min, updated, err = manager.UpdateServiceGCSafePoint(2, "cdc", 200, 1000, now)
+	re.NoError(err)
+	re.True(updated)
+	re.Equal("br", min.ServiceID)
+	ssps, err := manager.LoadAllServiceGCSafePoints(2, now)
+	re.NoError(err)
+	re.Len(ssps, 2)
+
+	re.NoError(manager.RemoveServiceGCSafePoint(2, "br"))
+	min, err = manager.LoadMinServiceGCSafePoint(2, now)
+	re.NoError(err)
+	re.Equal("cdc", min.ServiceID)
+	// Keyspace 1 is not affected.
+	ssps, err = manager.LoadAllServiceGCSafePoints(1, now)
+	re.NoError(err)
+	re.Len(ssps, 1)
+	re.Equal(uint64(10), ssps[0].SafePoint)
+}
+
+func TestKeyspaceGCBarrier(t *testing.T) {
+	re := require.New(t)
+	manager := newKeyspaceSafePointManager()
+	now := time.Now()
+	_, err := manager.UpdateGCSafePoint(1, 10)
+	re.NoError(err)
+	for _, invalid := range []*endpoint.GCBarrier{
+		{BarrierID: "", SafePoint: 20},
+		{BarrierID: "a/b", SafePoint: 20},
+		{BarrierID: "pitr", SafePoint: 20, StartKey: "zz"},
+		{BarrierID: "pitr", SafePoint: 20, StartKey: "02", EndKey: "01"},
+		// The data before the GC safepoint may have been collected.
+		{BarrierID: "pitr", SafePoint: 5},
+	} {
+		_, err = manager.SetGCBarrier(1, invalid, 0, now)
+		re.Error(err)
+	}
+	barrier, err := manager.SetGCBarrier(1, &endpoint.GCBarrier{BarrierID: "pitr", SafePoint: 20, StartKey: "01", EndKey: "02"}, 0, now)
+	re.NoError(err)
+	re.Equal(now.Unix(), barrier.CreatedAt)
+	_, err = manager.SetGCBarrier(1, &endpoint.GCBarrier{BarrierID: "backup", SafePoint: 30}, 10, now)
+	re.NoError(err)
+
+	// The barriers block the GC of keyspace 1 only.
+	_, err = manager.UpdateGCSafePoint(1, 100)
+	re.NoError(err)
+	safePoint, err := manager.LoadGCSafePoint(1)
+	re.NoError(err)
+	re.Equal(uint64(20), safePoint)
+	_, err = manager.UpdateGCSafePoint(2, 100)
+	re.NoError(err)
+	safePoint, err = manager.LoadGCSafePoint(2)
+	re.NoError(err)
+	re.Equal(uint64(100), safePoint)
+	min, err := manager.LoadMinServiceGCSafePoint(1, now)
+	re.NoError(err)
+	re.Equal(gcBarrierServicePrefix+"pitr", min.ServiceID)
+	blockers, err := manager.GetGCBlockers(now)
+	re.NoError(err)
+	re.Equal(GCBlockerBarrier, blockers[0].Blocker.Kind)
+	re.Equal("pitr", blockers[0].Blocker.ServiceID)
+
+	re.NoError(manager.RemoveGCBarrier(1, "pitr"))
+	barrier, err = manager.GetGCBarrier(1, "pitr", now)
+	re.NoError(err)
+	re.Nil(barrier)
+	barriers, err := manager.LoadAllGCBarriers(1, now)
+	re.NoError(err)
+	re.Len(barriers, 1)
+	// The remaining barrier still blocks GC until it expires.
+	_, err = manager.UpdateGCSafePoint(1, 100)
+	re.NoError(err)
+	safePoint, err = manager.LoadGCSafePoint(1)
+	re.NoError(err)
+	re.Equal(uint64(30), safePoint)
+	barriers, err = manager.LoadAllGCBarriers(1, now.Add(time.Minute))
+	re.NoError(err)
+	re.Empty(barriers)
+}
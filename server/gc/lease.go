@@ -0,0 +1,148 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"math"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"go.uber.org/zap"
+)
+
+// gcWorkerServiceID is the service safepoint of the GC itself, which can't be leased.
+const gcWorkerServiceID = "gc_worker"
+
+var (
+	// ErrLeaseNotFound is returned when the lease does not exist or has expired.
+	ErrLeaseNotFound = errors.New("service safepoint lease does not exist or has expired")
+	// ErrLeaseHeldByOthers is returned when the lease is held by another owner.
+	ErrLeaseHeldByOthers = errors.New("service safepoint lease is held by another owner")
+)
+
+func leaseExpiredAt(now time.Time, ttl int64) int64 {
+	if math.MaxInt64-now.Unix() <= ttl {
+		return math.MaxInt64
+	}
+	return now.Unix() + ttl
+}
+
+// AcquireServiceSafePointLease leases the safepoint of the service to the owner
+// for ttl seconds. The owner must renew the lease before it expires, otherwise
+// the safepoint is removed and no longer blocks GC. A live lease can only be
+// acquired again by the same owner.
+func (manager *SafePointManager) AcquireServiceSafePointLease(serviceID, owner string, safePoint uint64, ttl int64, now time.Time) (*endpoint.ServiceSafePoint, error) {
+	if len(serviceID) == 0 || serviceID == gcWorkerServiceID {
+		return nil, errors.Errorf("service safepoint %q can not be leased", serviceID)
+	}
+	if len(owner) == 0 {
+		return nil, errors.New("owner of the lease should not be empty")
+	}
+	if ttl <= 0 {
+		return nil, errors.New("TTL of the lease should be positive")
+	}
+	manager.serviceGCLock.Lock()
+	defer manager.serviceGCLock.Unlock()
+	current, err := manager.store.LoadServiceGCSafePoint(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	alive := current != nil && current.ExpiredAt >= now.Unix()
+	if alive && current.IsLeased() && current.Owner != owner {
+		return nil, errors.WithStack(ErrLeaseHeldByOthers)
+	}
+	min, err := manager.store.LoadMinServiceGCSafePoint(now)
+	if err != nil {
+		return nil, err
+	}
+	if safePoint < min.SafePoint {
+		return nil, errors.Errorf("safepoint %d is less than the min service safepoint %d of %s", safePoint, min.SafePoint, min.ServiceID)
+	}
+	lease := &endpoint.ServiceSafePoint{
+		ServiceID: serviceID,
+		ExpiredAt: leaseExpiredAt(now, ttl),
+		SafePoint: safePoint,
+		Owner:     owner,
+		LeaseTTL:  ttl,
+		CreatedAt: now.Unix(),
+		RenewedAt: now.Unix(),
+	}
+	if alive && current.Owner == owner {
+		lease.CreatedAt = current.CreatedAt
+	}
+	if err := manager.store.SaveServiceGCSafePoint(lease); err != nil {
+		return nil, err
+	}
+	log.Info("service safepoint lease acquired",
+		zap.String("service-id", serviceID),
+		zap.String("owner", owner),
+		zap.Uint64("safepoint", safePoint),
+		zap.Int64("ttl", ttl))
+	return lease, nil
+}
+
+// RenewServiceSafePointLease extends the lease by its TTL and advances its
+// safepoint. The safepoint is unchanged if it's zero, and can't move backwards.
+func (manager *SafePointManager) RenewServiceSafePointLease(serviceID, owner string, safePoint uint64, now time.Time) (*endpoint.ServiceSafePoint, error) {
+	manager.serviceGCLock.Lock()
+	defer manager.serviceGCLock.Unlock()
+	lease, err := manager.loadLease(serviceID, owner, now)
+	if err != nil {
+		return nil, err
+	}
+	if safePoint != 0 {
+		if safePoint < lease.SafePoint {
+			return nil, errors.Errorf("safepoint %d is less than the leased safepoint %d", safePoint, lease.SafePoint)
+		}
+		lease.SafePoint = safePoint
+	}
+	lease.RenewedAt = now.Unix()
+	lease.ExpiredAt = leaseExpiredAt(now, lease.LeaseTTL)
+	if err := manager.store.SaveServiceGCSafePoint(lease); err != nil {
+		return nil, err
+	}
+	return lease, nil
+}
+
+// ReleaseServiceSafePointLease removes the leased safepoint of the service.
+func (manager *SafePointManager) ReleaseServiceSafePointLease(serviceID, owner string, now time.Time) error {
+	manager.serviceGCLock.Lock()
+	defer manager.serviceGCLock.Unlock()
+	if _, err := manager.loadLease(serviceID, owner, now); err != nil {
+		return err
+	}
+	if err := manager.store.RemoveServiceGCSafePoint(serviceID); err != nil {
+		return err
+	}
+	log.Info("service safepoint lease released", zap.String("service-id", serviceID), zap.String("owner", owner))
+	return nil
+}
+
+// loadLease loads the live lease of the service held by the owner.
+func (manager *SafePointManager) loadLease(serviceID, owner string, now time.Time) (*endpoint.ServiceSafePoint, error) {
+	lease, err := manager.store.LoadServiceGCSafePoint(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if lease == nil || !lease.IsLeased() || lease.ExpiredAt < now.Unix() {
+		return nil, errors.WithStack(ErrLeaseNotFound)
+	}
+	if lease.Owner != owner {
+		return nil, errors.WithStack(ErrLeaseHeldByOthers)
+	}
+	return lease, nil
+}
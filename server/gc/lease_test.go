@@ -0,0 +1,120 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/utils/tsoutil"
+	"github.com/tikv/pd/pkg/utils/typeutil"
+	"github.com/tikv/pd/server/config"
+)
+
+func TestServiceSafePointLease(t *testing.T) {
+	re := require.New(t)
+	manager := NewSafePointManager(newGCStorage(), config.GCConfig{})
+	now := time.Now()
+
+	_, err := manager.AcquireServiceSafePointLease(gcWorkerServiceID, "owner1", 10, 10, now)
+	re.Error(err)
+	_, err = manager.AcquireServiceSafePointLease("cdc", "", 10, 10, now)
+	re.Error(err)
+	lease, err := manager.AcquireServiceSafePointLease("cdc", "owner1", 10, 10, now)
+	re.NoError(err)
+	re.Equal(now.Unix()+10, lease.ExpiredAt)
+	_, err = manager.AcquireServiceSafePointLease("cdc", "owner2", 10, 10, now)
+	re.Equal(ErrLeaseHeldByOthers, errors.Cause(err))
+
+	// The lease is renewed by its owner.
+	later := now.Add(5 * time.Second)
+	_, err = manager.RenewServiceSafePointLease("cdc", "owner2", 20, later)
+	re.Equal(ErrLeaseHeldByOthers, errors.Cause(err))
+	_, err = manager.RenewServiceSafePointLease("cdc", "owner1", 5, later)
+	re.Error(err)
+	lease, err = manager.RenewServiceSafePointLease("cdc", "owner1", 0, later)
+	re.NoError(err)
+	re.Equal(uint64(10), lease.SafePoint)
+	re.Equal(later.Unix()+10, lease.ExpiredAt)
+	re.Equal(now.Unix(), lease.CreatedAt)
+	re.Equal(later.Unix(), lease.RenewedAt)
+
+	// The expired lease can't be renewed but can be acquired by others.
+	expired := later.Add(time.Minute)
+	_, err = manager.RenewServiceSafePointLease("cdc", "owner1", 20, expired)
+	re.Equal(ErrLeaseNotFound, errors.Cause(err))
+	lease, err = manager.AcquireServiceSafePointLease("cdc", "owner2", 20, 10, expired)
+	re.NoError(err)
+	re.Equal(expired.Unix(), lease.CreatedAt)
+
+	// The legacy update keeps the lease.
+	_, updated, err := manager.UpdateServiceGCSafePoint("cdc", 30, 10, expired)
+	re.NoError(err)
+	re.True(updated)
+	ssp, err := manager.store.LoadServiceGCSafePoint("cdc")
+	re.NoError(err)
+	re.Equal("owner2", ssp.Owner)
+
+	re.Equal(ErrLeaseHeldByOthers, errors.Cause(manager.ReleaseServiceSafePointLease("cdc", "owner1", expired)))
+	re.NoError(manager.ReleaseServiceSafePointLease("cdc", "owner2", expired))
+	ssp, err = manager.store.LoadServiceGCSafePoint("cdc")
+	re.NoError(err)
+	re.Nil(ssp)
+}
+
+func TestServiceSafePointChecker(t *testing.T) {
+	re := require.New(t)
+	manager := NewSafePointManager(newGCStorage(), config.GCConfig{
+		SafePointBlockThreshold: typeutil.NewDuration(time.Hour),
+	})
+	checker := NewServiceSafePointChecker(manager)
+	now := time.Now()
+	safePoint := func(t time.Time) uint64 {
+		return tsoutil.ComposeTS(t.UnixNano()/int64(time.Millisecond), 0)
+	}
+	_, err := manager.AcquireServiceSafePointLease("cdc", "owner1", safePoint(now.Add(-2*time.Hour)), 600, now)
+	re.NoError(err)
+	_, err = manager.AcquireServiceSafePointLease("br", "owner2", safePoint(now), 60, now)
+	re.NoError(err)
+	_, err = manager.AcquireServiceSafePointLease("lightning", "owner3", safePoint(now), 600, now)
+	re.NoError(err)
+
+	re.NoError(checker.check(now))
+	events := checker.GetEvents()
+	re.Len(events, 1)
+	re.Equal(SafePointEventBlocking, events[0].Type)
+	re.Equal("cdc", events[0].ServiceID)
+	re.Equal("owner1", events[0].Owner)
+	// The blocking safepoint is notified only once.
+	re.NoError(checker.check(now))
+	re.Len(checker.GetEvents(), 1)
+
+	// The lease released before it expires is not notified, the expired ones
+	// are notified once no matter whether they are removed.
+	re.NoError(manager.ReleaseServiceSafePointLease("lightning", "owner3", now))
+	expired := now.Add(2 * time.Minute)
+	_, err = manager.store.LoadMinServiceGCSafePoint(expired)
+	re.NoError(err)
+	_, err = manager.AcquireServiceSafePointLease("cdc", "owner1", safePoint(expired), 60, expired)
+	re.NoError(err)
+	re.NoError(checker.check(expired))
+	re.NoError(checker.check(expired))
+	events = checker.GetEvents()
+	re.Len(events, 2)
+	re.Equal(SafePointEventExpired, events[1].Type)
+	re.Equal("br", events[1].ServiceID)
+}
@@ -0,0 +1,58 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	serviceSafePointAgeGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "gc",
+			Name:      "service_safe_point_age_seconds",
+			Help:      "How long the service safepoint is behind the current time.",
+		}, []string{"service"})
+
+	safePointEventCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "gc",
+			Name:      "safe_point_events_total",
+			Help:      "Counter of the service safepoint events.",
+		}, []string{"service", "type"})
+
+	safePointLimitedCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "gc",
+			Name:      "safe_point_limited_total",
+			Help:      "Counter of the times the GC safepoint advancement is limited by the rate.",
+		})
+
+	safePointLagGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "gc",
+			Name:      "safe_point_lag_seconds",
+			Help:      "How long the GC safepoint advanced by the GC controller is behind the current time.",
+		})
+)
+
+func init() {
+	prometheus.MustRegister(serviceSafePointAgeGauge)
+	prometheus.MustRegister(safePointEventCounter)
+	prometheus.MustRegister(safePointLimitedCounter)
+	prometheus.MustRegister(safePointLagGauge)
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"math"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"go.uber.org/zap"
+)
+
+// PauseGC stops the GC safepoint from advancing for the duration, after which
+// GC resumes automatically. The pause is indefinite if the duration is zero.
+// An indefinite pause or one longer than the max pause duration is rejected
+// unless it is forced. The previous pause is replaced.
+func (manager *SafePointManager) PauseGC(duration time.Duration, reason string, force bool, now time.Time) (*endpoint.GCPause, error) {
+	if len(reason) == 0 {
+		return nil, errors.New("reason of the GC pause should not be empty")
+	}
+	if duration < 0 {
+		return nil, errors.Errorf("invalid GC pause duration %s", duration)
+	}
+	if duration == 0 && !force {
+		return nil, errors.New("indefinite GC pause should be forced")
+	}
+	maxDuration := manager.config.MaxPauseDuration.Duration
+	if maxDuration > 0 && duration > maxDuration && !force {
+		return nil, errors.Errorf("GC pause duration %s exceeds the max pause duration %s, it should be forced", duration, maxDuration)
+	}
+	pause := &endpoint.GCPause{
+		Reason:    reason,
+		StartedAt: now.Unix(),
+		EndAt:     math.MaxInt64,
+		Forced:    force && (duration == 0 || (maxDuration > 0 && duration > maxDuration)),
+	}
+	if duration > 0 {
+		pause.EndAt = leaseExpiredAt(now, int64(duration.Seconds()))
+	}
+	manager.gcLock.Lock()
+	defer manager.gcLock.Unlock()
+	if err := manager.store.SaveGCPause(pause); err != nil {
+		return nil, err
+	}
+	log.Warn("GC safepoint paused",
+		zap.String("reason", reason),
+		zap.Duration("duration", duration),
+		zap.Int64("end-at", pause.EndAt),
+		zap.Bool("forced", pause.Forced))
+	return pause, nil
+}
+
+// ResumeGC ends the pause of the GC safepoint.
+func (manager *SafePointManager) ResumeGC() error {
+	manager.gcLock.Lock()
+	defer manager.gcLock.Unlock()
+	if err := manager.store.RemoveGCPause(); err != nil {
+		return err
+	}
+	log.Info("GC safepoint resumed")
+	return nil
+}
+
+// GetGCPause returns the pause of the GC safepoint, nil if GC is not paused.
+func (manager *SafePointManager) GetGCPause(now time.Time) (*endpoint.GCPause, error) {
+	pause, err := manager.store.LoadGCPause()
+	if err != nil || pause == nil || !pause.IsActive(now) {
+		return nil, err
+	}
+	return pause, nil
+}
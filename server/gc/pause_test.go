@@ -0,0 +1,65 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/utils/typeutil"
+	"github.com/tikv/pd/server/config"
+)
+
+func TestPauseGC(t *testing.T) {
+	re := require.New(t)
+	manager := NewSafePointManager(newGCStorage(), config.GCConfig{
+		MaxPauseDuration: typeutil.NewDuration(time.Hour),
+	})
+	now := time.Now()
+	_, err := manager.PauseGC(time.Minute, "", false, now)
+	re.Error(err)
+	// The long or indefinite pauses should be forced.
+	_, err = manager.PauseGC(2*time.Hour, "backup", false, now)
+	re.Error(err)
+	_, err = manager.PauseGC(0, "investigation", false, now)
+	re.Error(err)
+	pause, err := manager.PauseGC(0, "investigation", true, now)
+	re.NoError(err)
+	re.True(pause.Forced)
+	re.Equal(int64(math.MaxInt64), pause.EndAt)
+
+	pause, err = manager.PauseGC(time.Minute, "backup", false, now)
+	re.NoError(err)
+	re.False(pause.Forced)
+	re.Equal(now.Unix()+60, pause.EndAt)
+	oldSafePoint, newSafePoint, err := manager.UpdateGCSafePoint(10)
+	re.NoError(err)
+	re.Zero(oldSafePoint)
+	re.Zero(newSafePoint)
+	pause, err = manager.GetGCPause(now)
+	re.NoError(err)
+	re.Equal("backup", pause.Reason)
+	// GC resumes automatically after the pause ends.
+	pause, err = manager.GetGCPause(now.Add(time.Minute))
+	re.NoError(err)
+	re.Nil(pause)
+
+	re.NoError(manager.ResumeGC())
+	_, newSafePoint, err = manager.UpdateGCSafePoint(10)
+	re.NoError(err)
+	re.Equal(uint64(10), newSafePoint)
+}
@@ -18,8 +18,12 @@ import (
 	"math"
 	"time"
 
+	"github.com/pingcap/log"
 	"github.com/tikv/pd/pkg/storage/endpoint"
 	"github.com/tikv/pd/pkg/utils/syncutil"
+	"github.com/tikv/pd/pkg/utils/tsoutil"
+	"github.com/tikv/pd/server/config"
+	"go.uber.org/zap"
 )
 
 // SafePointManager is the manager for safePoint of GC and services.
@@ -27,11 +31,25 @@ type SafePointManager struct {
 	gcLock        syncutil.Mutex
 	serviceGCLock syncutil.Mutex
 	store         endpoint.GCSafePointStorage
+	config        config.GCConfig
+	// advanceCredit is how far the GC safepoint can advance now. It accrues by
+	// the max advancement rate since lastAccrue, up to the burst of
+	// safePointAdvanceWindow. They are protected by gcLock.
+	advanceCredit time.Duration
+	lastAccrue    time.Time
 }
 
+// safePointAdvanceWindow is how long the advancement credit of the GC safepoint
+// can accrue, so a safepoint stuck for hours can't jump at once. It should
+// cover the interval the GC worker updates the safepoint, which is 10 minutes
+// in TiDB by default.
+const safePointAdvanceWindow = 10 * time.Minute
+
 // NewSafePointManager creates a SafePointManager of GC and services.
-func NewSafePointManager(store endpoint.GCSafePointStorage) *SafePointManager {
-	return &SafePointManager{store: store}
+func NewSafePointManager(store endpoint.GCSafePointStorage, config config.GCConfig) *SafePointManager {
+	manager := &SafePointManager{store: store, config: config, lastAccrue: time.Now()}
+	manager.advanceCredit = manager.maxAdvanceCredit()
+	return manager
 }
 
 // LoadGCSafePoint loads current GC safe point from storage.
@@ -40,20 +58,69 @@ func (manager *SafePointManager) LoadGCSafePoint() (uint64, error) {
 }
 
 // UpdateGCSafePoint updates the safepoint if it is greater than the previous one
-// it returns the old safepoint in the storage.
-func (manager *SafePointManager) UpdateGCSafePoint(newSafePoint uint64) (oldSafePoint uint64, err error) {
+// and GC is not paused, it returns the old safepoint in the storage and the new
+// one, which may be less than the target to limit the advancement rate.
+func (manager *SafePointManager) UpdateGCSafePoint(target uint64) (oldSafePoint, newSafePoint uint64, err error) {
 	manager.gcLock.Lock()
 	defer manager.gcLock.Unlock()
 	// TODO: cache the safepoint in the storage.
 	oldSafePoint, err = manager.store.LoadGCSafePoint()
-	if err != nil {
-		return
+	if err != nil || oldSafePoint >= target {
+		return oldSafePoint, oldSafePoint, err
+	}
+	now := time.Now()
+	pause, err := manager.store.LoadGCPause()
+	if err != nil || (pause != nil && pause.IsActive(now)) {
+		return oldSafePoint, oldSafePoint, err
+	}
+	newSafePoint, advance := manager.limitAdvance(oldSafePoint, target, now)
+	if newSafePoint <= oldSafePoint {
+		return oldSafePoint, oldSafePoint, nil
 	}
-	if oldSafePoint >= newSafePoint {
-		return
+	if err = manager.store.SaveGCSafePoint(newSafePoint); err != nil {
+		return oldSafePoint, oldSafePoint, err
 	}
-	err = manager.store.SaveGCSafePoint(newSafePoint)
-	return
+	manager.advanceCredit -= advance
+	return oldSafePoint, newSafePoint, nil
+}
+
+// maxAdvanceCredit returns the burst of the advancement credit, zero if the
+// advancement rate is not limited.
+func (manager *SafePointManager) maxAdvanceCredit() time.Duration {
+	return time.Duration(float64(safePointAdvanceWindow) * manager.config.SafePointMaxAdvanceRate)
+}
+
+// limitAdvance limits how far the GC safepoint advances by the accrued credit,
+// so the GC load of a sudden long jump is spread out. It returns the new
+// safepoint and the credit it consumes. The initial advancement from zero is
+// not limited.
+func (manager *SafePointManager) limitAdvance(oldSafePoint, target uint64, now time.Time) (uint64, time.Duration) {
+	rate := manager.config.SafePointMaxAdvanceRate
+	if rate <= 0 || oldSafePoint == 0 {
+		return target, 0
+	}
+	// Cap the credit before converting it back, it may overflow after a long
+	// stall.
+	maxCredit := manager.maxAdvanceCredit()
+	if accrued := float64(now.Sub(manager.lastAccrue)) * rate; accrued >= float64(maxCredit-manager.advanceCredit) {
+		manager.advanceCredit = maxCredit
+	} else if accrued > 0 {
+		manager.advanceCredit += time.Duration(accrued)
+	}
+	manager.lastAccrue = now
+	oldTime, _ := tsoutil.ParseTS(oldSafePoint)
+	targetTime, _ := tsoutil.ParseTS(target)
+	if advance := targetTime.Sub(oldTime); advance <= manager.advanceCredit {
+		return target, advance
+	}
+	maxAdvance := manager.advanceCredit
+	limited := tsoutil.ComposeTS(oldTime.Add(maxAdvance).UnixNano()/int64(time.Millisecond), 0)
+	safePointLimitedCounter.Inc()
+	log.Info("GC safepoint advancement is limited",
+		zap.Uint64("target", target),
+		zap.Uint64("limited", limited),
+		zap.Duration("max-advance", maxAdvance))
+	return limited, maxAdvance
 }
 
 // UpdateServiceGCSafePoint update the safepoint for a specific service.
@@ -73,6 +140,14 @@ func (manager *SafePointManager) UpdateServiceGCSafePoint(serviceID string, newS
 	if math.MaxInt64-now.Unix() <= ttl {
 		ssp.ExpiredAt = math.MaxInt64
 	}
+	// Keep the lease if the leased safepoint is updated in the legacy way.
+	current, err := manager.store.LoadServiceGCSafePoint(serviceID)
+	if err != nil {
+		return nil, false, err
+	}
+	if current != nil && current.IsLeased() {
+		ssp.Owner, ssp.LeaseTTL, ssp.CreatedAt, ssp.RenewedAt = current.Owner, ttl, current.CreatedAt, now.Unix()
+	}
 	if err := manager.store.SaveServiceGCSafePoint(ssp); err != nil {
 		return nil, false, err
 	}
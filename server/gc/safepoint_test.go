@@ -23,6 +23,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/tikv/pd/pkg/storage/endpoint"
 	"github.com/tikv/pd/pkg/storage/kv"
+	"github.com/tikv/pd/pkg/utils/tsoutil"
+	"github.com/tikv/pd/server/config"
 )
 
 func newGCStorage() endpoint.GCSafePointStorage {
@@ -30,7 +32,7 @@ func newGCStorage() endpoint.GCSafePointStorage {
 }
 
 func TestGCSafePointUpdateSequentially(t *testing.T) {
-	gcSafePointManager := NewSafePointManager(newGCStorage())
+	gcSafePointManager := NewSafePointManager(newGCStorage(), config.GCConfig{})
 	re := require.New(t)
 	curSafePoint := uint64(0)
 	// update gc safePoint with asc value.
@@ -40,7 +42,7 @@ func TestGCSafePointUpdateSequentially(t *testing.T) {
 		re.Equal(curSafePoint, safePoint)
 		previousSafePoint := curSafePoint
 		curSafePoint = uint64(id)
-		oldSafePoint, err := gcSafePointManager.UpdateGCSafePoint(curSafePoint)
+		oldSafePoint, _, err := gcSafePointManager.UpdateGCSafePoint(curSafePoint)
 		re.NoError(err)
 		re.Equal(previousSafePoint, oldSafePoint)
 	}
@@ -49,7 +51,7 @@ func TestGCSafePointUpdateSequentially(t *testing.T) {
 	re.NoError(err)
 	re.Equal(curSafePoint, safePoint)
 	// update with smaller value should be failed.
-	oldSafePoint, err := gcSafePointManager.UpdateGCSafePoint(safePoint - 5)
+	oldSafePoint, _, err := gcSafePointManager.UpdateGCSafePoint(safePoint - 5)
 	re.NoError(err)
 	re.Equal(safePoint, oldSafePoint)
 	curSafePoint, err = gcSafePointManager.LoadGCSafePoint()
@@ -59,7 +61,7 @@ func TestGCSafePointUpdateSequentially(t *testing.T) {
 }
 
 func TestGCSafePointUpdateCurrently(t *testing.T) {
-	gcSafePointManager := NewSafePointManager(newGCStorage())
+	gcSafePointManager := NewSafePointManager(newGCStorage(), config.GCConfig{})
 	maxSafePoint := uint64(1000)
 	wg := sync.WaitGroup{}
 	re := require.New(t)
@@ -69,7 +71,7 @@ func TestGCSafePointUpdateCurrently(t *testing.T) {
 		wg.Add(1)
 		go func(step uint64) {
 			for safePoint := step; safePoint <= maxSafePoint; safePoint += step {
-				_, err := gcSafePointManager.UpdateGCSafePoint(safePoint)
+				_, _, err := gcSafePointManager.UpdateGCSafePoint(safePoint)
 				re.NoError(err)
 			}
 			wg.Done()
@@ -81,9 +83,51 @@ func TestGCSafePointUpdateCurrently(t *testing.T) {
 	re.Equal(maxSafePoint, safePoint)
 }
 
+func TestGCSafePointAdvanceRate(t *testing.T) {
+	re := require.New(t)
+	manager := NewSafePointManager(newGCStorage(), config.GCConfig{SafePointMaxAdvanceRate: 10})
+	now := time.Now()
+	toTS := func(t time.Time) uint64 {
+		return tsoutil.ComposeTS(t.UnixNano()/int64(time.Millisecond), 0)
+	}
+	// The initial advancement is not limited.
+	start := toTS(now.Add(-10 * time.Hour))
+	_, newSafePoint, err := manager.UpdateGCSafePoint(start)
+	re.NoError(err)
+	re.Equal(start, newSafePoint)
+
+	// The safepoint stuck for hours only advances by the burst, which is 10
+	// minutes of credit at the rate.
+	manager.lastAccrue = time.Now().Add(-5 * time.Hour)
+	_, newSafePoint, err = manager.UpdateGCSafePoint(toTS(now))
+	re.NoError(err)
+	physical, _ := tsoutil.ParseTS(newSafePoint)
+	re.InDelta(float64(100*time.Minute), float64(physical.Sub(now.Add(-10*time.Hour))), float64(time.Second))
+	safePoint, err := manager.LoadGCSafePoint()
+	re.NoError(err)
+	re.Equal(newSafePoint, safePoint)
+
+	// Then it advances 10 minutes per minute.
+	last := physical
+	manager.lastAccrue = time.Now().Add(-time.Minute)
+	_, newSafePoint, err = manager.UpdateGCSafePoint(toTS(now))
+	re.NoError(err)
+	physical, _ = tsoutil.ParseTS(newSafePoint)
+	re.InDelta(float64(10*time.Minute), float64(physical.Sub(last)), float64(time.Second))
+
+	// The target within the credit is not changed, and the rest of the credit
+	// is kept.
+	manager.lastAccrue = time.Now().Add(-time.Minute)
+	target := toTS(physical.Add(time.Minute))
+	_, newSafePoint, err = manager.UpdateGCSafePoint(target)
+	re.NoError(err)
+	re.Equal(target, newSafePoint)
+	re.InDelta(float64(9*time.Minute), float64(manager.advanceCredit), float64(time.Second))
+}
+
 func TestServiceGCSafePointUpdate(t *testing.T) {
 	re := require.New(t)
-	manager := NewSafePointManager(newGCStorage())
+	manager := NewSafePointManager(newGCStorage(), config.GCConfig{})
 	gcworkerServiceID := "gc_worker"
 	cdcServiceID := "cdc"
 	brServiceID := "br"
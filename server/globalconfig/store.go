@@ -0,0 +1,280 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package globalconfig
+
+import (
+	"context"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/tikv/pd/pkg/errs"
+	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/etcdserver/api/v3rpc/rpctypes"
+	"go.etcd.io/etcd/mvcc/mvccpb"
+)
+
+// RootPath is the etcd path of the global config. It is kept for the
+// compatibility of CDC, which loads the global config without a namespace.
+const RootPath = "/global/config/"
+
+// AnyRevision makes a write unconditional, while the revision 0 means the item
+// must not exist.
+const AnyRevision int64 = -1
+
+var (
+	namespacePattern = regexp.MustCompile(`^[a-zA-Z0-9_.\-]+$`)
+	errWatchClosed   = errors.New("global config watch channel is closed")
+)
+
+// Item is an item of the global config.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type Item struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	// Revision is the etcd revision the item is modified at, which is compared
+	// by the compare-and-swap writes.
+	Revision int64 `json:"revision"`
+	// Version is the number of the modifications since the item is created.
+	Version int64 `json:"version,omitempty"`
+}
+
+// ChangeType is the type of a global config change.
+type ChangeType string
+
+const (
+	// ChangePut means the item is created or updated.
+	ChangePut ChangeType = "put"
+	// ChangeDelete means the item is deleted, and the change carries its last value.
+	ChangeDelete ChangeType = "delete"
+)
+
+// Change is a change of the global config.
+type Change struct {
+	Type ChangeType
+	// Item is the item after the change, or the deleted item whose revision is
+	// the one it is deleted at.
+	Item *Item
+}
+
+// Store stores the global config items in namespaces, each of which is a path
+// under the root path, so the gRPC global config API can access them with the
+// path of the namespace.
+type Store struct {
+	client *clientv3.Client
+}
+
+// NewStore creates a Store of the global config.
+func NewStore(client *clientv3.Client) *Store {
+	return &Store{client: client}
+}
+
+// NamespacePath returns the etcd path of the namespace.
+func NamespacePath(namespace string) string {
+	return path.Join(RootPath, namespace)
+}
+
+func validateNamespace(namespace string) error {
+	if !namespacePattern.MatchString(namespace) {
+		return errs.ErrInvalidGlobalConfigNamespace.FastGenByArgs(namespace)
+	}
+	return nil
+}
+
+func itemPath(namespace, name string) string {
+	return path.Join(RootPath, namespace, name)
+}
+
+func makeItem(namespace string, kv *mvccpb.KeyValue) *Item {
+	return &Item{
+		Name:     strings.TrimPrefix(string(kv.Key), NamespacePath(namespace)+"/"),
+		Value:    string(kv.Value),
+		Revision: kv.ModRevision,
+		Version:  kv.Version,
+	}
+}
+
+// wrapGetError converts the error of reading at a compacted revision.
+func wrapGetError(err error, revision int64) error {
+	if errors.Cause(err) == rpctypes.ErrCompacted {
+		return errs.ErrGlobalConfigCompacted.FastGenByArgs(revision)
+	}
+	return errs.ErrEtcdKVGet.Wrap(err).GenWithStackByCause()
+}
+
+// Load loads the item at the revision, or the latest one if the revision is 0.
+func (s *Store) Load(ctx context.Context, namespace, name string, revision int64) (*Item, error) {
+	if err := validateNamespace(namespace); err != nil {
+		return nil, err
+	}
+	var opts []clientv3.OpOption
+	if revision > 0 {
+		opts = append(opts, clientv3.WithRev(revision))
+	}
+	resp, err := s.client.Get(ctx, itemPath(namespace, name), opts...)
+	if err != nil {
+		return nil, wrapGetError(err, revision)
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, errs.ErrGlobalConfigNotFound.FastGenByArgs(name)
+	}
+	return makeItem(namespace, resp.Kvs[0]), nil
+}
+
+// LoadAll loads all the items in the namespace and returns the revision they
+// are loaded at. Watching from the next revision gets all the changes after it.
+func (s *Store) LoadAll(ctx context.Context, namespace string) ([]*Item, int64, error) {
+	if err := validateNamespace(namespace); err != nil {
+		return nil, 0, err
+	}
+	resp, err := s.client.Get(ctx, NamespacePath(namespace)+"/", clientv3.WithPrefix())
+	if err != nil {
+		return nil, 0, errs.ErrEtcdKVGet.Wrap(err).GenWithStackByCause()
+	}
+	items := make([]*Item, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		items = append(items, makeItem(namespace, kv))
+	}
+	return items, resp.Header.GetRevision(), nil
+}
+
+// History returns at most limit versions of the item from the latest to the
+// oldest one since it is created. The versions before the compacted revision
+// are not available.
+func (s *Store) History(ctx context.Context, namespace, name string, limit int) ([]*Item, error) {
+	item, err := s.Load(ctx, namespace, name, 0)
+	if err != nil {
+		return nil, err
+	}
+	items := []*Item{item}
+	for len(items) < limit && item.Version > 1 {
+		item, err = s.Load(ctx, namespace, name, item.Revision-1)
+		if errs.ErrGlobalConfigCompacted.Equal(err) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Put saves the item if its revision is the expected one, and returns the saved
+// item. If the item has been modified, it returns the current item with the
+// conflict error. The expected revision 0 means the item must not exist, and
+// AnyRevision saves the item unconditionally.
+func (s *Store) Put(ctx context.Context, namespace, name, value string, expectedRevision int64) (*Item, error) {
+	if err := validateNamespace(namespace); err != nil {
+		return nil, err
+	}
+	key := itemPath(namespace, name)
+	txn := s.client.Txn(ctx)
+	if expectedRevision != AnyRevision {
+		txn = txn.If(clientv3.Compare(clientv3.ModRevision(key), "=", expectedRevision))
+	}
+	resp, err := txn.Then(clientv3.OpPut(key, value), clientv3.OpGet(key)).Else(clientv3.OpGet(key)).Commit()
+	if err != nil {
+		return nil, errs.ErrEtcdTxnInternal.Wrap(err).GenWithStackByCause()
+	}
+	if !resp.Succeeded {
+		return s.conflict(namespace, name, resp.Responses[0].GetResponseRange().GetKvs())
+	}
+	return makeItem(namespace, resp.Responses[1].GetResponseRange().GetKvs()[0]), nil
+}
+
+// Delete deletes the item if its revision is the expected one. If the item has
+// been modified, it returns the current item with the conflict error.
+func (s *Store) Delete(ctx context.Context, namespace, name string, expectedRevision int64) (*Item, error) {
+	if err := validateNamespace(namespace); err != nil {
+		return nil, err
+	}
+	key := itemPath(namespace, name)
+	txn := s.client.Txn(ctx)
+	if expectedRevision != AnyRevision {
+		txn = txn.If(clientv3.Compare(clientv3.ModRevision(key), "=", expectedRevision))
+	}
+	resp, err := txn.Then(clientv3.OpDelete(key)).Else(clientv3.OpGet(key)).Commit()
+	if err != nil {
+		return nil, errs.ErrEtcdTxnInternal.Wrap(err).GenWithStackByCause()
+	}
+	if !resp.Succeeded {
+		return s.conflict(namespace, name, resp.Responses[0].GetResponseRange().GetKvs())
+	}
+	if resp.Responses[0].GetResponseDeleteRange().GetDeleted() == 0 {
+		return nil, errs.ErrGlobalConfigNotFound.FastGenByArgs(name)
+	}
+	return nil, nil
+}
+
+func (s *Store) conflict(namespace, name string, kvs []*mvccpb.KeyValue) (*Item, error) {
+	if len(kvs) == 0 {
+		return nil, errs.ErrGlobalConfigConflict.FastGenByArgs(name, 0)
+	}
+	item := makeItem(namespace, kvs[0])
+	return item, errs.ErrGlobalConfigConflict.FastGenByArgs(name, item.Revision)
+}
+
+// Watch calls f with the changes in the namespace since the start revision,
+// until ctx is done or any error occurs. The changes passed to each call of f
+// are in the order of their revisions. It returns error if the start revision
+// is compacted.
+func (s *Store) Watch(ctx context.Context, namespace string, startRevision int64, f func(changes []*Change) error) error {
+	if err := validateNamespace(namespace); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	watchChan := s.client.Watch(clientv3.WithRequireLeader(ctx), NamespacePath(namespace)+"/",
+		clientv3.WithPrefix(), clientv3.WithRev(startRevision), clientv3.WithPrevKV())
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case resp, ok := <-watchChan:
+			if !ok {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return errWatchClosed
+			}
+			if resp.CompactRevision > 0 {
+				return errs.ErrGlobalConfigCompacted.FastGenByArgs(startRevision)
+			}
+			if err := resp.Err(); err != nil {
+				return errs.ErrEtcdWatcherCancel.Wrap(err).GenWithStackByCause()
+			}
+			changes := make([]*Change, 0, len(resp.Events))
+			for _, event := range resp.Events {
+				change := &Change{Type: ChangePut, Item: makeItem(namespace, event.Kv)}
+				if event.Type == clientv3.EventTypeDelete {
+					change.Type = ChangeDelete
+					if event.PrevKv != nil {
+						change.Item = makeItem(namespace, event.PrevKv)
+					}
+					change.Item.Revision = event.Kv.ModRevision
+				}
+				changes = append(changes, change)
+			}
+			if len(changes) == 0 {
+				continue
+			}
+			if err := f(changes); err != nil {
+				return err
+			}
+		}
+	}
+}
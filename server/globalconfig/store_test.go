@@ -0,0 +1,115 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package globalconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/utils/etcdutil"
+	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/embed"
+)
+
+func TestStore(t *testing.T) {
+	re := require.New(t)
+	cfg := etcdutil.NewTestSingleConfig(t)
+	etcd, err := embed.StartEtcd(cfg)
+	re.NoError(err)
+	defer etcd.Close()
+	<-etcd.Server.ReadyNotify()
+	client, err := clientv3.New(clientv3.Config{Endpoints: []string{cfg.LCUrls[0].String()}})
+	re.NoError(err)
+	defer client.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := NewStore(client)
+
+	_, err = store.Put(ctx, "a/b", "k", "v", AnyRevision)
+	re.True(errs.ErrInvalidGlobalConfigNamespace.Equal(err))
+
+	// Compare-and-swap.
+	item, err := store.Put(ctx, "cdc", "k", "v1", 0)
+	re.NoError(err)
+	re.Equal("k", item.Name)
+	re.Equal(int64(1), item.Version)
+	_, err = store.Put(ctx, "cdc", "k", "v2", 0)
+	re.True(errs.ErrGlobalConfigConflict.Equal(err))
+	current, err := store.Put(ctx, "cdc", "k", "v2", item.Revision+100)
+	re.True(errs.ErrGlobalConfigConflict.Equal(err))
+	re.Equal(item, current)
+	item, err = store.Put(ctx, "cdc", "k", "v2", item.Revision)
+	re.NoError(err)
+	re.Equal("v2", item.Value)
+	_, err = store.Put(ctx, "cdc", "k", "v3", AnyRevision)
+	re.NoError(err)
+	// The namespaces are isolated.
+	_, err = store.Put(ctx, "cdc2", "k", "other", AnyRevision)
+	re.NoError(err)
+
+	// Revision history.
+	history, err := store.History(ctx, "cdc", "k", 10)
+	re.NoError(err)
+	re.Len(history, 3)
+	re.Equal("v3", history[0].Value)
+	re.Equal("v1", history[2].Value)
+	history, err = store.History(ctx, "cdc", "k", 2)
+	re.NoError(err)
+	re.Len(history, 2)
+	old, err := store.Load(ctx, "cdc", "k", item.Revision)
+	re.NoError(err)
+	re.Equal("v2", old.Value)
+
+	items, revision, err := store.LoadAll(ctx, "cdc")
+	re.NoError(err)
+	re.Len(items, 1)
+	re.Equal("v3", items[0].Value)
+
+	// Watch from the next revision.
+	changes := make(chan *Change, 10)
+	go store.Watch(ctx, "cdc", revision+1, func(cs []*Change) error {
+		for _, c := range cs {
+			changes <- c
+		}
+		return nil
+	})
+	_, err = store.Put(ctx, "cdc", "k2", "v", AnyRevision)
+	re.NoError(err)
+	_, err = store.Delete(ctx, "cdc", "k", items[0].Revision-1)
+	re.True(errs.ErrGlobalConfigConflict.Equal(err))
+	_, err = store.Delete(ctx, "cdc", "k", items[0].Revision)
+	re.NoError(err)
+	_, err = store.Delete(ctx, "cdc", "k", AnyRevision)
+	re.True(errs.ErrGlobalConfigNotFound.Equal(err))
+	for _, expected := range []struct {
+		typ   ChangeType
+		name  string
+		value string
+	}{{ChangePut, "k2", "v"}, {ChangeDelete, "k", "v3"}} {
+		select {
+		case change := <-changes:
+			re.Equal(expected.typ, change.Type)
+			re.Equal(expected.name, change.Item.Name)
+			re.Equal(expected.value, change.Item.Value)
+		case <-time.After(5 * time.Second):
+			re.FailNow("no change")
+		}
+	}
+	_, err = store.Load(ctx, "cdc", "k", 0)
+	re.True(errs.ErrGlobalConfigNotFound.Equal(err))
+}
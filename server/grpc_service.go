@@ -28,8 +28,10 @@ import (
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/audit"
 	"github.com/tikv/pd/pkg/core"
 	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/rbac"
 	"github.com/tikv/pd/pkg/storage/endpoint"
 	"github.com/tikv/pd/pkg/storage/kv"
 	"github.com/tikv/pd/pkg/tso"
@@ -38,10 +40,13 @@ import (
 	"github.com/tikv/pd/pkg/utils/tsoutil"
 	"github.com/tikv/pd/pkg/versioninfo"
 	"github.com/tikv/pd/server/cluster"
+	"github.com/tikv/pd/server/globalconfig"
 	"go.etcd.io/etcd/clientv3"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
@@ -88,6 +93,84 @@ func (s *GrpcServer) unaryMiddleware(ctx context.Context, header *pdpb.RequestHe
 	return nil, nil
 }
 
+// followerReadMiddleware is like unaryMiddleware, but allows a follower to serve the
+// read-only request from its synced cache if the client accepts the staleness. It
+// returns the cluster to serve the request, which is nil if the cluster is not bootstrapped.
+func (s *GrpcServer) followerReadMiddleware(ctx context.Context, header *pdpb.RequestHeader, fn forwardFn) (interface{}, *cluster.RaftCluster, error) {
+	if maxStaleness, ok := grpcutil.GetMaxStaleness(ctx); ok && !s.member.IsLeader() && s.isLocalRequest(grpcutil.GetForwardedHost(ctx)) {
+		// Fall back to the leader if the follower is too stale.
+		if staleness, err := s.CheckFollowerRead(maxStaleness); err == nil {
+			if header.GetClusterId() != s.clusterID {
+				return nil, nil, status.Errorf(codes.FailedPrecondition, "mismatch cluster id, need %d but got %d", s.clusterID, header.GetClusterId())
+			}
+			if err := grpc.SetHeader(ctx, metadata.Pairs(grpcutil.StalenessMetadataKey, staleness.String())); err != nil {
+				log.Warn("failed to set the staleness header", errs.ZapError(err))
+			}
+			return nil, s.GetFollowerReadCluster(), nil
+		}
+	}
+	rsp, err := s.unaryMiddleware(ctx, header, fn)
+	if err != nil || rsp != nil {
+		return rsp, nil, err
+	}
+	return nil, s.GetRaftCluster(), nil
+}
+
+// AuthorizeGRPC checks whether the gRPC request has the required role if the
+// role-based access control is enabled. It is called by the mutating methods,
+// including the ones of the services registered to the server, so the calls
+// are recorded into the security audit log.
+func (s *Server) AuthorizeGRPC(ctx context.Context, required rbac.Role) error {
+	method, _ := grpc.Method(ctx)
+	var remoteAddr string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		remoteAddr = p.Addr.String()
+	}
+	cred := rbac.GRPCCredential(ctx)
+	var identity *rbac.Identity
+	if s.IsRBACEnabled() {
+		var err error
+		identity, err = s.Authorize(cred, required)
+		event := newSecurityEvent(audit.SecurityEventAuthentication, "gRPC", method, cred, identity, err)
+		event.RemoteAddr = remoteAddr
+		s.securityLogger.Log(event)
+		if err != nil {
+			log.Warn("gRPC request is denied", zap.String("method", method), zap.String("remote-addr", remoteAddr), errs.ZapError(err))
+			return rbac.GRPCError(err)
+		}
+	}
+	event := newSecurityEvent(audit.SecurityEventMutation, "gRPC", method, cred, identity, nil)
+	event.RemoteAddr = remoteAddr
+	s.securityLogger.Log(event)
+	return nil
+}
+
+// checkPolicy checks whether the source address of the request is allowed by
+// the network ACLs and the client certificate is allowed to call the method by
+// the gRPC policies. Only the denials are logged, since every gRPC request is
+// checked.
+func (s *GrpcServer) checkPolicy(ctx context.Context) error {
+	var remoteAddr string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		remoteAddr = p.Addr.String()
+	}
+	cred := rbac.GRPCCredential(ctx)
+	typ := audit.SecurityEventNetwork
+	err := s.CheckNetworkACL(rbac.ListenerGRPC, cred.Path, remoteAddr)
+	if err == nil {
+		typ = audit.SecurityEventAuthentication
+		err = s.AuthorizeGRPCPolicy(cred)
+	}
+	if err == nil {
+		return nil
+	}
+	event := newSecurityEvent(typ, "gRPC", cred.Path, cred, nil, err)
+	event.RemoteAddr = remoteAddr
+	s.securityLogger.Log(event)
+	log.Warn("gRPC request is denied by policy", zap.String("method", cred.Path), zap.String("remote-addr", remoteAddr), errs.ZapError(err))
+	return rbac.GRPCError(err)
+}
+
 func (s *GrpcServer) wrapErrorToHeader(errorType pdpb.ErrorType, message string) *pdpb.ResponseHeader {
 	return s.errorHeader(&pdpb.Error{
 		Type:    errorType,
@@ -96,7 +179,10 @@ func (s *GrpcServer) wrapErrorToHeader(errorType pdpb.ErrorType, message string)
 }
 
 // GetMembers implements gRPC PDServer.
-func (s *GrpcServer) GetMembers(context.Context, *pdpb.GetMembersRequest) (*pdpb.GetMembersResponse, error) {
+func (s *GrpcServer) GetMembers(ctx context.Context, _ *pdpb.GetMembersRequest) (*pdpb.GetMembersResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	// Here we purposely do not check the cluster ID because the client does not know the correct cluster ID
 	// at startup and needs to get the cluster ID with the first request (i.e. GetMembers).
 	members, err := s.Server.GetMembers()
@@ -142,6 +228,9 @@ func (s *GrpcServer) GetMembers(context.Context, *pdpb.GetMembersRequest) (*pdpb
 
 // Tso implements gRPC PDServer.
 func (s *GrpcServer) Tso(stream pdpb.PD_TsoServer) error {
+	if err := s.checkPolicy(stream.Context()); err != nil {
+		return err
+	}
 	var (
 		doneCh chan struct{}
 		errCh  chan error
@@ -192,7 +281,7 @@ func (s *GrpcServer) Tso(stream pdpb.PD_TsoServer) error {
 		count := request.GetCount()
 		ts, err := s.tsoAllocatorManager.HandleTSORequest(request.GetDcLocation(), count)
 		if err != nil {
-			return status.Errorf(codes.Unknown, err.Error())
+			return grpcutil.StatusError(stream.Context(), err)
 		}
 		tsoHandleDuration.Observe(time.Since(start).Seconds())
 		response := &pdpb.TsoResponse{
@@ -384,11 +473,14 @@ func watchTSDeadline(ctx context.Context, tsDeadlineCh <-chan deadline) {
 
 // Bootstrap implements gRPC PDServer.
 func (s *GrpcServer) Bootstrap(ctx context.Context, request *pdpb.BootstrapRequest) (*pdpb.BootstrapResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
 		return pdpb.NewPDClient(client).Bootstrap(ctx, request)
 	}
 	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	} else if rsp != nil {
 		return rsp.(*pdpb.BootstrapResponse), nil
 	}
@@ -417,11 +509,14 @@ func (s *GrpcServer) Bootstrap(ctx context.Context, request *pdpb.BootstrapReque
 
 // IsBootstrapped implements gRPC PDServer.
 func (s *GrpcServer) IsBootstrapped(ctx context.Context, request *pdpb.IsBootstrappedRequest) (*pdpb.IsBootstrappedResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
 		return pdpb.NewPDClient(client).IsBootstrapped(ctx, request)
 	}
 	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	} else if rsp != nil {
 		return rsp.(*pdpb.IsBootstrappedResponse), err
 	}
@@ -435,11 +530,14 @@ func (s *GrpcServer) IsBootstrapped(ctx context.Context, request *pdpb.IsBootstr
 
 // AllocID implements gRPC PDServer.
 func (s *GrpcServer) AllocID(ctx context.Context, request *pdpb.AllocIDRequest) (*pdpb.AllocIDResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
 		return pdpb.NewPDClient(client).AllocID(ctx, request)
 	}
 	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	} else if rsp != nil {
 		return rsp.(*pdpb.AllocIDResponse), err
 	}
@@ -460,6 +558,9 @@ func (s *GrpcServer) AllocID(ctx context.Context, request *pdpb.AllocIDRequest)
 
 // IsSnapshotRecovering implements gRPC PDServer.
 func (s *GrpcServer) IsSnapshotRecovering(ctx context.Context, request *pdpb.IsSnapshotRecoveringRequest) (*pdpb.IsSnapshotRecoveringResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	// recovering mark is stored in etcd directly, there's no need to forward.
 	marked, err := s.Server.IsSnapshotRecovering(ctx)
 	if err != nil {
@@ -475,16 +576,19 @@ func (s *GrpcServer) IsSnapshotRecovering(ctx context.Context, request *pdpb.IsS
 
 // GetStore implements gRPC PDServer.
 func (s *GrpcServer) GetStore(ctx context.Context, request *pdpb.GetStoreRequest) (*pdpb.GetStoreResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
 		return pdpb.NewPDClient(client).GetStore(ctx, request)
 	}
-	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
-		return nil, err
-	} else if rsp != nil {
-		return rsp.(*pdpb.GetStoreResponse), err
+	rsp, rc, err := s.followerReadMiddleware(ctx, request.GetHeader(), fn)
+	if err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
+	if rsp != nil {
+		return rsp.(*pdpb.GetStoreResponse), nil
 	}
-
-	rc := s.GetRaftCluster()
 	if rc == nil {
 		return &pdpb.GetStoreResponse{Header: s.notBootstrappedHeader()}, nil
 	}
@@ -521,11 +625,14 @@ func checkStore(rc *cluster.RaftCluster, storeID uint64) *pdpb.Error {
 
 // PutStore implements gRPC PDServer.
 func (s *GrpcServer) PutStore(ctx context.Context, request *pdpb.PutStoreRequest) (*pdpb.PutStoreResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
 		return pdpb.NewPDClient(client).PutStore(ctx, request)
 	}
 	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	} else if rsp != nil {
 		return rsp.(*pdpb.PutStoreResponse), err
 	}
@@ -567,16 +674,19 @@ func (s *GrpcServer) PutStore(ctx context.Context, request *pdpb.PutStoreRequest
 
 // GetAllStores implements gRPC PDServer.
 func (s *GrpcServer) GetAllStores(ctx context.Context, request *pdpb.GetAllStoresRequest) (*pdpb.GetAllStoresResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
 		return pdpb.NewPDClient(client).GetAllStores(ctx, request)
 	}
-	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
-		return nil, err
-	} else if rsp != nil {
-		return rsp.(*pdpb.GetAllStoresResponse), err
+	rsp, rc, err := s.followerReadMiddleware(ctx, request.GetHeader(), fn)
+	if err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
+	if rsp != nil {
+		return rsp.(*pdpb.GetAllStoresResponse), nil
 	}
-
-	rc := s.GetRaftCluster()
 	if rc == nil {
 		return &pdpb.GetAllStoresResponse{Header: s.notBootstrappedHeader()}, nil
 	}
@@ -601,11 +711,14 @@ func (s *GrpcServer) GetAllStores(ctx context.Context, request *pdpb.GetAllStore
 
 // StoreHeartbeat implements gRPC PDServer.
 func (s *GrpcServer) StoreHeartbeat(ctx context.Context, request *pdpb.StoreHeartbeatRequest) (*pdpb.StoreHeartbeatResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
 		return pdpb.NewPDClient(client).StoreHeartbeat(ctx, request)
 	}
 	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	} else if rsp != nil {
 		return rsp.(*pdpb.StoreHeartbeatResponse), err
 	}
@@ -741,6 +854,9 @@ func (s *heartbeatServer) Recv() (*pdpb.RegionHeartbeatRequest, error) {
 
 // ReportBuckets implements gRPC PDServer
 func (s *GrpcServer) ReportBuckets(stream pdpb.PD_ReportBucketsServer) error {
+	if err := s.checkPolicy(stream.Context()); err != nil {
+		return err
+	}
 	var (
 		server            = &bucketHeartbeatServer{stream: stream}
 		forwardStream     pdpb.PD_ReportBucketsClient
@@ -835,6 +951,9 @@ func (s *GrpcServer) ReportBuckets(stream pdpb.PD_ReportBucketsServer) error {
 
 // RegionHeartbeat implements gRPC PDServer.
 func (s *GrpcServer) RegionHeartbeat(stream pdpb.PD_RegionHeartbeatServer) error {
+	if err := s.checkPolicy(stream.Context()); err != nil {
+		return err
+	}
 	var (
 		server            = &heartbeatServer{stream: stream}
 		flowRoundOption   = core.WithFlowRoundByDigit(s.persistOptions.GetPDServerConfig().FlowRoundByDigit)
@@ -963,16 +1082,19 @@ func (s *GrpcServer) RegionHeartbeat(stream pdpb.PD_RegionHeartbeatServer) error
 
 // GetRegion implements gRPC PDServer.
 func (s *GrpcServer) GetRegion(ctx context.Context, request *pdpb.GetRegionRequest) (*pdpb.GetRegionResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
 		return pdpb.NewPDClient(client).GetRegion(ctx, request)
 	}
-	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
-		return nil, err
-	} else if rsp != nil {
+	rsp, rc, err := s.followerReadMiddleware(ctx, request.GetHeader(), fn)
+	if err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
+	if rsp != nil {
 		return rsp.(*pdpb.GetRegionResponse), nil
 	}
-
-	rc := s.GetRaftCluster()
 	if rc == nil {
 		return &pdpb.GetRegionResponse{Header: s.notBootstrappedHeader()}, nil
 	}
@@ -981,7 +1103,8 @@ func (s *GrpcServer) GetRegion(ctx context.Context, request *pdpb.GetRegionReque
 		return &pdpb.GetRegionResponse{Header: s.header()}, nil
 	}
 	var buckets *metapb.Buckets
-	if rc.GetStoreConfig().IsEnableRegionBucket() && request.GetNeedBuckets() {
+	// The follower read cluster is not running and does not know the store config.
+	if rc.IsRunning() && rc.GetStoreConfig().IsEnableRegionBucket() && request.GetNeedBuckets() {
 		buckets = region.GetBuckets()
 	}
 	return &pdpb.GetRegionResponse{
@@ -996,16 +1119,19 @@ func (s *GrpcServer) GetRegion(ctx context.Context, request *pdpb.GetRegionReque
 
 // GetPrevRegion implements gRPC PDServer
 func (s *GrpcServer) GetPrevRegion(ctx context.Context, request *pdpb.GetRegionRequest) (*pdpb.GetRegionResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
 		return pdpb.NewPDClient(client).GetPrevRegion(ctx, request)
 	}
-	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
-		return nil, err
-	} else if rsp != nil {
-		return rsp.(*pdpb.GetRegionResponse), err
+	rsp, rc, err := s.followerReadMiddleware(ctx, request.GetHeader(), fn)
+	if err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
+	if rsp != nil {
+		return rsp.(*pdpb.GetRegionResponse), nil
 	}
-
-	rc := s.GetRaftCluster()
 	if rc == nil {
 		return &pdpb.GetRegionResponse{Header: s.notBootstrappedHeader()}, nil
 	}
@@ -1015,7 +1141,8 @@ func (s *GrpcServer) GetPrevRegion(ctx context.Context, request *pdpb.GetRegionR
 		return &pdpb.GetRegionResponse{Header: s.header()}, nil
 	}
 	var buckets *metapb.Buckets
-	if rc.GetStoreConfig().IsEnableRegionBucket() && request.GetNeedBuckets() {
+	// The follower read cluster is not running and does not know the store config.
+	if rc.IsRunning() && rc.GetStoreConfig().IsEnableRegionBucket() && request.GetNeedBuckets() {
 		buckets = region.GetBuckets()
 	}
 	return &pdpb.GetRegionResponse{
@@ -1030,16 +1157,19 @@ func (s *GrpcServer) GetPrevRegion(ctx context.Context, request *pdpb.GetRegionR
 
 // GetRegionByID implements gRPC PDServer.
 func (s *GrpcServer) GetRegionByID(ctx context.Context, request *pdpb.GetRegionByIDRequest) (*pdpb.GetRegionResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
 		return pdpb.NewPDClient(client).GetRegionByID(ctx, request)
 	}
-	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
-		return nil, err
-	} else if rsp != nil {
-		return rsp.(*pdpb.GetRegionResponse), err
+	rsp, rc, err := s.followerReadMiddleware(ctx, request.GetHeader(), fn)
+	if err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
+	if rsp != nil {
+		return rsp.(*pdpb.GetRegionResponse), nil
 	}
-
-	rc := s.GetRaftCluster()
 	if rc == nil {
 		return &pdpb.GetRegionResponse{Header: s.notBootstrappedHeader()}, nil
 	}
@@ -1048,7 +1178,8 @@ func (s *GrpcServer) GetRegionByID(ctx context.Context, request *pdpb.GetRegionB
 		return &pdpb.GetRegionResponse{Header: s.header()}, nil
 	}
 	var buckets *metapb.Buckets
-	if rc.GetStoreConfig().IsEnableRegionBucket() && request.GetNeedBuckets() {
+	// The follower read cluster is not running and does not know the store config.
+	if rc.IsRunning() && rc.GetStoreConfig().IsEnableRegionBucket() && request.GetNeedBuckets() {
 		buckets = region.GetBuckets()
 	}
 	return &pdpb.GetRegionResponse{
@@ -1063,16 +1194,19 @@ func (s *GrpcServer) GetRegionByID(ctx context.Context, request *pdpb.GetRegionB
 
 // ScanRegions implements gRPC PDServer.
 func (s *GrpcServer) ScanRegions(ctx context.Context, request *pdpb.ScanRegionsRequest) (*pdpb.ScanRegionsResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
 		return pdpb.NewPDClient(client).ScanRegions(ctx, request)
 	}
-	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
-		return nil, err
-	} else if rsp != nil {
+	rsp, rc, err := s.followerReadMiddleware(ctx, request.GetHeader(), fn)
+	if err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
+	if rsp != nil {
 		return rsp.(*pdpb.ScanRegionsResponse), nil
 	}
-
-	rc := s.GetRaftCluster()
 	if rc == nil {
 		return &pdpb.ScanRegionsResponse{Header: s.notBootstrappedHeader()}, nil
 	}
@@ -1098,11 +1232,14 @@ func (s *GrpcServer) ScanRegions(ctx context.Context, request *pdpb.ScanRegionsR
 
 // AskSplit implements gRPC PDServer.
 func (s *GrpcServer) AskSplit(ctx context.Context, request *pdpb.AskSplitRequest) (*pdpb.AskSplitResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
 		return pdpb.NewPDClient(client).AskSplit(ctx, request)
 	}
 	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	} else if rsp != nil {
 		return rsp.(*pdpb.AskSplitResponse), err
 	}
@@ -1136,11 +1273,14 @@ func (s *GrpcServer) AskSplit(ctx context.Context, request *pdpb.AskSplitRequest
 
 // AskBatchSplit implements gRPC PDServer.
 func (s *GrpcServer) AskBatchSplit(ctx context.Context, request *pdpb.AskBatchSplitRequest) (*pdpb.AskBatchSplitResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
 		return pdpb.NewPDClient(client).AskBatchSplit(ctx, request)
 	}
 	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	} else if rsp != nil {
 		return rsp.(*pdpb.AskBatchSplitResponse), err
 	}
@@ -1178,11 +1318,14 @@ func (s *GrpcServer) AskBatchSplit(ctx context.Context, request *pdpb.AskBatchSp
 
 // ReportSplit implements gRPC PDServer.
 func (s *GrpcServer) ReportSplit(ctx context.Context, request *pdpb.ReportSplitRequest) (*pdpb.ReportSplitResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
 		return pdpb.NewPDClient(client).ReportSplit(ctx, request)
 	}
 	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	} else if rsp != nil {
 		return rsp.(*pdpb.ReportSplitResponse), err
 	}
@@ -1205,11 +1348,14 @@ func (s *GrpcServer) ReportSplit(ctx context.Context, request *pdpb.ReportSplitR
 
 // ReportBatchSplit implements gRPC PDServer.
 func (s *GrpcServer) ReportBatchSplit(ctx context.Context, request *pdpb.ReportBatchSplitRequest) (*pdpb.ReportBatchSplitResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
 		return pdpb.NewPDClient(client).ReportBatchSplit(ctx, request)
 	}
 	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	} else if rsp != nil {
 		return rsp.(*pdpb.ReportBatchSplitResponse), err
 	}
@@ -1234,11 +1380,14 @@ func (s *GrpcServer) ReportBatchSplit(ctx context.Context, request *pdpb.ReportB
 
 // GetClusterConfig implements gRPC PDServer.
 func (s *GrpcServer) GetClusterConfig(ctx context.Context, request *pdpb.GetClusterConfigRequest) (*pdpb.GetClusterConfigResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
 		return pdpb.NewPDClient(client).GetClusterConfig(ctx, request)
 	}
 	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	} else if rsp != nil {
 		return rsp.(*pdpb.GetClusterConfigResponse), err
 	}
@@ -1255,11 +1404,17 @@ func (s *GrpcServer) GetClusterConfig(ctx context.Context, request *pdpb.GetClus
 
 // PutClusterConfig implements gRPC PDServer.
 func (s *GrpcServer) PutClusterConfig(ctx context.Context, request *pdpb.PutClusterConfigRequest) (*pdpb.PutClusterConfigResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
+	if err := s.AuthorizeGRPC(ctx, rbac.RoleAdmin); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
 		return pdpb.NewPDClient(client).PutClusterConfig(ctx, request)
 	}
 	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	} else if rsp != nil {
 		return rsp.(*pdpb.PutClusterConfigResponse), err
 	}
@@ -1285,11 +1440,17 @@ func (s *GrpcServer) PutClusterConfig(ctx context.Context, request *pdpb.PutClus
 
 // ScatterRegion implements gRPC PDServer.
 func (s *GrpcServer) ScatterRegion(ctx context.Context, request *pdpb.ScatterRegionRequest) (*pdpb.ScatterRegionResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
+	if err := s.AuthorizeGRPC(ctx, rbac.RoleOperator); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
 		return pdpb.NewPDClient(client).ScatterRegion(ctx, request)
 	}
 	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	} else if rsp != nil {
 		return rsp.(*pdpb.ScatterRegionResponse), err
 	}
@@ -1302,7 +1463,7 @@ func (s *GrpcServer) ScatterRegion(ctx context.Context, request *pdpb.ScatterReg
 	if len(request.GetRegionsId()) > 0 {
 		percentage, err := scatterRegions(rc, request.GetRegionsId(), request.GetGroup(), int(request.GetRetryLimit()))
 		if err != nil {
-			return nil, err
+			return nil, grpcutil.StatusError(ctx, err)
 		}
 		return &pdpb.ScatterRegionResponse{
 			Header:             s.header(),
@@ -1325,7 +1486,7 @@ func (s *GrpcServer) ScatterRegion(ctx context.Context, request *pdpb.ScatterReg
 
 	op, err := rc.GetRegionScatter().Scatter(region, request.GetGroup())
 	if err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	}
 	if op != nil {
 		rc.GetOperatorController().AddOperator(op)
@@ -1339,11 +1500,14 @@ func (s *GrpcServer) ScatterRegion(ctx context.Context, request *pdpb.ScatterReg
 
 // GetGCSafePoint implements gRPC PDServer.
 func (s *GrpcServer) GetGCSafePoint(ctx context.Context, request *pdpb.GetGCSafePointRequest) (*pdpb.GetGCSafePointResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
 		return pdpb.NewPDClient(client).GetGCSafePoint(ctx, request)
 	}
 	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	} else if rsp != nil {
 		return rsp.(*pdpb.GetGCSafePointResponse), err
 	}
@@ -1355,7 +1519,7 @@ func (s *GrpcServer) GetGCSafePoint(ctx context.Context, request *pdpb.GetGCSafe
 
 	safePoint, err := s.gcSafePointManager.LoadGCSafePoint()
 	if err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	}
 
 	return &pdpb.GetGCSafePointResponse{
@@ -1366,6 +1530,9 @@ func (s *GrpcServer) GetGCSafePoint(ctx context.Context, request *pdpb.GetGCSafe
 
 // SyncRegions syncs the regions.
 func (s *GrpcServer) SyncRegions(stream pdpb.PD_SyncRegionsServer) error {
+	if err := s.checkPolicy(stream.Context()); err != nil {
+		return err
+	}
 	if s.IsClosed() || s.cluster == nil {
 		return ErrNotStarted
 	}
@@ -1378,11 +1545,17 @@ func (s *GrpcServer) SyncRegions(stream pdpb.PD_SyncRegionsServer) error {
 
 // UpdateGCSafePoint implements gRPC PDServer.
 func (s *GrpcServer) UpdateGCSafePoint(ctx context.Context, request *pdpb.UpdateGCSafePointRequest) (*pdpb.UpdateGCSafePointResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
+	if err := s.AuthorizeGRPC(ctx, rbac.RoleOperator); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
 		return pdpb.NewPDClient(client).UpdateGCSafePoint(ctx, request)
 	}
 	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	} else if rsp != nil {
 		return rsp.(*pdpb.UpdateGCSafePointResponse), err
 	}
@@ -1392,20 +1565,18 @@ func (s *GrpcServer) UpdateGCSafePoint(ctx context.Context, request *pdpb.Update
 		return &pdpb.UpdateGCSafePointResponse{Header: s.notBootstrappedHeader()}, nil
 	}
 
-	newSafePoint := request.GetSafePoint()
-	oldSafePoint, err := s.gcSafePointManager.UpdateGCSafePoint(newSafePoint)
+	oldSafePoint, newSafePoint, err := s.gcSafePointManager.UpdateGCSafePoint(request.GetSafePoint())
 	if err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	}
 
 	if newSafePoint > oldSafePoint {
 		log.Info("updated gc safe point",
 			zap.Uint64("safe-point", newSafePoint))
-	} else if newSafePoint < oldSafePoint {
+	} else if request.GetSafePoint() < oldSafePoint {
 		log.Warn("trying to update gc safe point",
 			zap.Uint64("old-safe-point", oldSafePoint),
-			zap.Uint64("new-safe-point", newSafePoint))
-		newSafePoint = oldSafePoint
+			zap.Uint64("new-safe-point", request.GetSafePoint()))
 	}
 
 	return &pdpb.UpdateGCSafePointResponse{
@@ -1416,11 +1587,17 @@ func (s *GrpcServer) UpdateGCSafePoint(ctx context.Context, request *pdpb.Update
 
 // UpdateServiceGCSafePoint update the safepoint for specific service
 func (s *GrpcServer) UpdateServiceGCSafePoint(ctx context.Context, request *pdpb.UpdateServiceGCSafePointRequest) (*pdpb.UpdateServiceGCSafePointResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
+	if err := s.AuthorizeGRPC(ctx, rbac.RoleOperator); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
 		return pdpb.NewPDClient(client).UpdateServiceGCSafePoint(ctx, request)
 	}
 	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	} else if rsp != nil {
 		return rsp.(*pdpb.UpdateServiceGCSafePointResponse), err
 	}
@@ -1432,19 +1609,19 @@ func (s *GrpcServer) UpdateServiceGCSafePoint(ctx context.Context, request *pdpb
 	var storage endpoint.GCSafePointStorage = s.storage
 	if request.TTL <= 0 {
 		if err := storage.RemoveServiceGCSafePoint(string(request.ServiceId)); err != nil {
-			return nil, err
+			return nil, grpcutil.StatusError(ctx, err)
 		}
 	}
 
 	nowTSO, err := s.tsoAllocatorManager.HandleTSORequest(tso.GlobalDCLocation, 1)
 	if err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	}
 	now, _ := tsoutil.ParseTimestamp(nowTSO)
 	serviceID := string(request.ServiceId)
 	min, updated, err := s.gcSafePointManager.UpdateServiceGCSafePoint(serviceID, request.GetSafePoint(), request.GetTTL(), now)
 	if err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	}
 	if updated {
 		log.Info("update service GC safe point",
@@ -1462,11 +1639,14 @@ func (s *GrpcServer) UpdateServiceGCSafePoint(ctx context.Context, request *pdpb
 
 // GetOperator gets information about the operator belonging to the specify region.
 func (s *GrpcServer) GetOperator(ctx context.Context, request *pdpb.GetOperatorRequest) (*pdpb.GetOperatorResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
 		return pdpb.NewPDClient(client).GetOperator(ctx, request)
 	}
 	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	} else if rsp != nil {
 		return rsp.(*pdpb.GetOperatorResponse), err
 	}
@@ -1549,9 +1729,12 @@ var mockLocalAllocatorLeaderChangeFlag = false
 
 // SyncMaxTS will check whether MaxTS is the biggest one among all Local TSOs this PD is holding when skipCheck is set,
 // and write it into all Local TSO Allocators then if it's indeed the biggest one.
-func (s *GrpcServer) SyncMaxTS(_ context.Context, request *pdpb.SyncMaxTSRequest) (*pdpb.SyncMaxTSResponse, error) {
+func (s *GrpcServer) SyncMaxTS(ctx context.Context, request *pdpb.SyncMaxTSRequest) (*pdpb.SyncMaxTSResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	if err := s.validateInternalRequest(request.GetHeader(), true); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	}
 	tsoAllocatorManager := s.GetTSOAllocatorManager()
 	// There is no dc-location found in this server, return err.
@@ -1647,11 +1830,17 @@ func (s *GrpcServer) SyncMaxTS(_ context.Context, request *pdpb.SyncMaxTSRequest
 
 // SplitRegions split regions by the given split keys
 func (s *GrpcServer) SplitRegions(ctx context.Context, request *pdpb.SplitRegionsRequest) (*pdpb.SplitRegionsResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
+	if err := s.AuthorizeGRPC(ctx, rbac.RoleOperator); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
 		return pdpb.NewPDClient(client).SplitRegions(ctx, request)
 	}
 	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	} else if rsp != nil {
 		return rsp.(*pdpb.SplitRegionsResponse), err
 	}
@@ -1672,11 +1861,17 @@ func (s *GrpcServer) SplitRegions(ctx context.Context, request *pdpb.SplitRegion
 // Only regions which splited successfully will be scattered.
 // scatterFinishedPercentage indicates the percentage of successfully splited regions that are scattered.
 func (s *GrpcServer) SplitAndScatterRegions(ctx context.Context, request *pdpb.SplitAndScatterRegionsRequest) (*pdpb.SplitAndScatterRegionsResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
+	if err := s.AuthorizeGRPC(ctx, rbac.RoleOperator); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	fn := func(ctx context.Context, client *grpc.ClientConn) (interface{}, error) {
 		return pdpb.NewPDClient(client).SplitAndScatterRegions(ctx, request)
 	}
 	if rsp, err := s.unaryMiddleware(ctx, request.GetHeader(), fn); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	} else if rsp != nil {
 		return rsp.(*pdpb.SplitAndScatterRegionsResponse), err
 	}
@@ -1684,7 +1879,7 @@ func (s *GrpcServer) SplitAndScatterRegions(ctx context.Context, request *pdpb.S
 	splitFinishedPercentage, newRegionIDs := rc.GetRegionSplitter().SplitRegions(ctx, request.GetSplitKeys(), int(request.GetRetryLimit()))
 	scatterFinishedPercentage, err := scatterRegions(rc, newRegionIDs, request.GetGroup(), int(request.GetRetryLimit()))
 	if err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	}
 	return &pdpb.SplitAndScatterRegionsResponse{
 		Header:                    s.header(),
@@ -1716,9 +1911,12 @@ func scatterRegions(cluster *cluster.RaftCluster, regionsID []uint64, group stri
 
 // GetDCLocationInfo gets the dc-location info of the given dc-location from PD leader's TSO allocator manager.
 func (s *GrpcServer) GetDCLocationInfo(ctx context.Context, request *pdpb.GetDCLocationInfoRequest) (*pdpb.GetDCLocationInfoResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	var err error
 	if err = s.validateInternalRequest(request.GetHeader(), false); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	}
 	if !s.member.IsLeader() {
 		return nil, ErrNotLeader
@@ -1870,16 +2068,19 @@ func checkStream(streamCtx context.Context, cancel context.CancelFunc, done chan
 	<-done
 }
 
-// for CDC compatibility, we need to initialize config path to `globalConfigPath`
-const globalConfigPath = "/global/config/"
-
 // StoreGlobalConfig store global config into etcd by transaction
 // Since item value needs to support marshal of different struct types,
 // it should be set to `Payload bytes` instead of `Value string`
-func (s *GrpcServer) StoreGlobalConfig(_ context.Context, request *pdpb.StoreGlobalConfigRequest) (*pdpb.StoreGlobalConfigResponse, error) {
+func (s *GrpcServer) StoreGlobalConfig(ctx context.Context, request *pdpb.StoreGlobalConfigRequest) (*pdpb.StoreGlobalConfigResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
+	if err := s.AuthorizeGRPC(ctx, rbac.RoleOperator); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	configPath := request.GetConfigPath()
 	if configPath == "" {
-		configPath = globalConfigPath
+		configPath = globalconfig.RootPath
 	}
 	ops := make([]clientv3.Op, len(request.Changes))
 	for i, item := range request.Changes {
@@ -1911,9 +2112,12 @@ func (s *GrpcServer) StoreGlobalConfig(_ context.Context, request *pdpb.StoreGlo
 // - `Names` iteratively get value from `ConfigPath/Name` but not care about revision
 // - `ConfigPath` if `Names` is nil can get all values and revision of current path
 func (s *GrpcServer) LoadGlobalConfig(ctx context.Context, request *pdpb.LoadGlobalConfigRequest) (*pdpb.LoadGlobalConfigResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	configPath := request.GetConfigPath()
 	if configPath == "" {
-		configPath = globalConfigPath
+		configPath = globalconfig.RootPath
 	}
 	// Since item value needs to support marshal of different struct types,
 	// it should be set to `Payload bytes` instead of `Value string`.
@@ -1947,11 +2151,14 @@ func (s *GrpcServer) LoadGlobalConfig(ctx context.Context, request *pdpb.LoadGlo
 // by Etcd.Watch() as long as the context has not been canceled or timed out.
 // Watch on revision which greater than or equal to the required revision.
 func (s *GrpcServer) WatchGlobalConfig(req *pdpb.WatchGlobalConfigRequest, server pdpb.PD_WatchGlobalConfigServer) error {
+	if err := s.checkPolicy(server.Context()); err != nil {
+		return err
+	}
 	ctx, cancel := context.WithCancel(s.Context())
 	defer cancel()
 	configPath := req.GetConfigPath()
 	if configPath == "" {
-		configPath = globalConfigPath
+		configPath = globalconfig.RootPath
 	}
 	revision := req.GetRevision()
 	// If the revision is compacted, will meet required revision has been compacted error.
@@ -2032,18 +2239,21 @@ func (s *GrpcServer) handleDamagedStore(stats *pdpb.StoreStats) {
 
 // ReportMinResolvedTS implements gRPC PDServer.
 func (s *GrpcServer) ReportMinResolvedTS(ctx context.Context, request *pdpb.ReportMinResolvedTsRequest) (*pdpb.ReportMinResolvedTsResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	forwardedHost := grpcutil.GetForwardedHost(ctx)
 	if !s.isLocalRequest(forwardedHost) {
 		client, err := s.getDelegateClient(ctx, forwardedHost)
 		if err != nil {
-			return nil, err
+			return nil, grpcutil.StatusError(ctx, err)
 		}
 		ctx = grpcutil.ResetForwardContext(ctx)
 		return pdpb.NewPDClient(client).ReportMinResolvedTS(ctx, request)
 	}
 
 	if err := s.validateRequest(request.GetHeader()); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	}
 
 	rc := s.GetRaftCluster()
@@ -2054,7 +2264,7 @@ func (s *GrpcServer) ReportMinResolvedTS(ctx context.Context, request *pdpb.Repo
 	storeID := request.GetStoreId()
 	minResolvedTS := request.GetMinResolvedTs()
 	if err := rc.SetMinResolvedTS(storeID, minResolvedTS); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	}
 	log.Debug("updated min resolved-ts",
 		zap.Uint64("store", storeID),
@@ -2066,18 +2276,24 @@ func (s *GrpcServer) ReportMinResolvedTS(ctx context.Context, request *pdpb.Repo
 
 // SetExternalTimestamp implements gRPC PDServer.
 func (s *GrpcServer) SetExternalTimestamp(ctx context.Context, request *pdpb.SetExternalTimestampRequest) (*pdpb.SetExternalTimestampResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
+	if err := s.AuthorizeGRPC(ctx, rbac.RoleOperator); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	forwardedHost := grpcutil.GetForwardedHost(ctx)
 	if !s.isLocalRequest(forwardedHost) {
 		client, err := s.getDelegateClient(ctx, forwardedHost)
 		if err != nil {
-			return nil, err
+			return nil, grpcutil.StatusError(ctx, err)
 		}
 		ctx = grpcutil.ResetForwardContext(ctx)
 		return pdpb.NewPDClient(client).SetExternalTimestamp(ctx, request)
 	}
 
 	if err := s.validateRequest(request.GetHeader()); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	}
 
 	timestamp := request.GetTimestamp()
@@ -2093,18 +2309,21 @@ func (s *GrpcServer) SetExternalTimestamp(ctx context.Context, request *pdpb.Set
 
 // GetExternalTimestamp implements gRPC PDServer.
 func (s *GrpcServer) GetExternalTimestamp(ctx context.Context, request *pdpb.GetExternalTimestampRequest) (*pdpb.GetExternalTimestampResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	forwardedHost := grpcutil.GetForwardedHost(ctx)
 	if !s.isLocalRequest(forwardedHost) {
 		client, err := s.getDelegateClient(ctx, forwardedHost)
 		if err != nil {
-			return nil, err
+			return nil, grpcutil.StatusError(ctx, err)
 		}
 		ctx = grpcutil.ResetForwardContext(ctx)
 		return pdpb.NewPDClient(client).GetExternalTimestamp(ctx, request)
 	}
 
 	if err := s.validateRequest(request.GetHeader()); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	}
 
 	timestamp := s.GetExternalTS()
@@ -421,7 +421,7 @@ func (h *Handler) RemoveOperator(regionID uint64) error {
 		return ErrOperatorNotFound
 	}
 
-	_ = c.RemoveOperator(op)
+	_ = c.RemoveOperator(op, zap.String("reason", "removed manually"))
 	return nil
 }
 
@@ -495,6 +495,15 @@ func (h *Handler) GetRecords(from time.Time) ([]*operator.OpRecord, error) {
 	return records, nil
 }
 
+// GetOperatorTraces returns the traces of the recent operators of the region.
+func (h *Handler) GetOperatorTraces(regionID uint64) ([]*operator.OpTrace, error) {
+	c, err := h.GetOperatorController()
+	if err != nil {
+		return nil, err
+	}
+	return c.GetOperatorTraces(regionID), nil
+}
+
 // SetAllStoresLimit is used to set limit of all stores.
 func (h *Handler) SetAllStoresLimit(ratePerMin float64, limitType storelimit.Type) error {
 	c, err := h.GetRaftCluster()
@@ -158,7 +158,11 @@ func PrepareJoinCluster(cfg *config.Config) error {
 	// - A deleted PD joins to previous cluster.
 	{
 		// First adds member through the API
-		addResp, err = etcdutil.AddEtcdMember(client, []string{cfg.AdvertisePeerUrls})
+		if cfg.Witness {
+			addResp, err = etcdutil.AddEtcdLearner(client, []string{cfg.AdvertisePeerUrls})
+		} else {
+			addResp, err = etcdutil.AddEtcdMember(client, []string{cfg.AdvertisePeerUrls})
+		}
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,146 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyspace
+
+import (
+	"encoding/hex"
+	"strconv"
+
+	"github.com/pingcap/failpoint"
+	"github.com/pingcap/kvproto/pkg/keyspacepb"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/server/schedule/labeler"
+	"github.com/tikv/pd/server/schedule/placement"
+	"go.uber.org/zap"
+)
+
+const (
+	// archiveRuleGroupPrefix is used to prefix the placement rule group of an archived keyspace.
+	archiveRuleGroupPrefix = "keyspace-archive-"
+	// archiveRuleGroupIndex makes the archive rules override the default ones.
+	archiveRuleGroupIndex = 100
+	// archiveLabelIDSuffix is used to suffix the region label rule of an archived keyspace.
+	archiveLabelIDSuffix = "/archive"
+)
+
+// ArchiveKeyspace archives the disabled keyspace. The data of an archived keyspace
+// is placed on the stores with the archive store label, and its regions are
+// only scheduled to fix the replicas.
+func (manager *Manager) ArchiveKeyspace(name string, now int64) (*keyspacepb.KeyspaceMeta, error) {
+	return manager.UpdateKeyspaceState(name, keyspacepb.KeyspaceState_ARCHIVED, now)
+}
+
+// RestoreKeyspace restores the archived keyspace to the disabled state, and
+// recovers the placement and the scheduling of its data.
+func (manager *Manager) RestoreKeyspace(name string, now int64) (*keyspacepb.KeyspaceMeta, error) {
+	return manager.UpdateKeyspaceState(name, keyspacepb.KeyspaceState_DISABLED, now)
+}
+
+// onStateUpdated applies or removes the archive policy of the keyspace after its
+// state is updated. The archive policy is applied every time the keyspace is set
+// to archived, so a failed archiving can be retried.
+func (manager *Manager) onStateUpdated(id uint32, oldState, newState keyspacepb.KeyspaceState) error {
+	switch {
+	case newState == keyspacepb.KeyspaceState_ARCHIVED:
+		return manager.applyArchivePolicy(id)
+	case oldState == keyspacepb.KeyspaceState_ARCHIVED:
+		return manager.removeArchivePolicy(id)
+	}
+	return nil
+}
+
+func (manager *Manager) applyArchivePolicy(id uint32) error {
+	failpoint.Inject("skipArchivePolicy", func() {
+		failpoint.Return(nil)
+	})
+	if err := manager.rc.GetRegionLabeler().SetLabelRule(makeArchiveLabelRule(id)); err != nil {
+		return err
+	}
+	if key, value, ok := manager.config.GetArchiveStoreLabel(); ok {
+		opts := manager.rc.GetOpts()
+		if !opts.IsPlacementRulesEnabled() {
+			log.Warn("[keyspace] placement rules are disabled, the archived keyspace is not moved",
+				zap.Uint32("keyspaceID", id))
+		} else if err := manager.rc.GetRuleManager().SetGroupBundle(
+			makeArchiveRuleBundle(id, key, value, opts.GetMaxReplicas(), opts.GetLocationLabels())); err != nil {
+			return err
+		}
+	}
+	log.Info("[keyspace] applied archive policy for keyspace", zap.Uint32("keyspaceID", id))
+	return nil
+}
+
+func (manager *Manager) removeArchivePolicy(id uint32) error {
+	failpoint.Inject("skipArchivePolicy", func() {
+		failpoint.Return(nil)
+	})
+	if err := manager.rc.GetRuleManager().DeleteGroupBundle(getArchiveRuleGroupID(id), false); err != nil {
+		return err
+	}
+	labelRuleID := getRegionLabelID(id) + archiveLabelIDSuffix
+	if manager.rc.GetRegionLabeler().GetLabelRule(labelRuleID) != nil {
+		if err := manager.rc.GetRegionLabeler().DeleteLabelRule(labelRuleID); err != nil {
+			return err
+		}
+	}
+	log.Info("[keyspace] removed archive policy for keyspace", zap.Uint32("keyspaceID", id))
+	return nil
+}
+
+func getArchiveRuleGroupID(id uint32) string {
+	return archiveRuleGroupPrefix + strconv.FormatUint(uint64(id), endpoint.SpaceIDBase)
+}
+
+// makeArchiveLabelRule makes the label rule to deprioritize the scheduling of the archived keyspace.
+func makeArchiveLabelRule(id uint32) *labeler.LabelRule {
+	return &labeler.LabelRule{
+		ID:    getRegionLabelID(id) + archiveLabelIDSuffix,
+		Index: 0,
+		Labels: []labeler.RegionLabel{
+			{
+				Key:   labeler.ScheduleOptionLabel,
+				Value: labeler.ScheduleOptionValueLow,
+			},
+		},
+		RuleType: labeler.KeyRange,
+		Data:     makeKeyRanges(id),
+	}
+}
+
+// makeArchiveRuleBundle makes the placement rules to place all the replicas of
+// the archived keyspace on the stores with the given label.
+func makeArchiveRuleBundle(id uint32, key, value string, count int, locationLabels []string) placement.GroupBundle {
+	bundle := placement.GroupBundle{
+		ID:       getArchiveRuleGroupID(id),
+		Index:    archiveRuleGroupIndex,
+		Override: true,
+	}
+	for i, bound := range makeKeyBounds(id) {
+		bundle.Rules = append(bundle.Rules, &placement.Rule{
+			GroupID:     bundle.ID,
+			ID:          keyBoundNames[i],
+			StartKeyHex: hex.EncodeToString(bound[0]),
+			EndKeyHex:   hex.EncodeToString(bound[1]),
+			Role:        placement.Voter,
+			Count:       count,
+			LabelConstraints: []placement.LabelConstraint{
+				{Key: key, Op: placement.In, Values: []string{value}},
+			},
+			LocationLabels: locationLabels,
+		})
+	}
+	return bundle
+}
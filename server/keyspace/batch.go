@@ -0,0 +1,291 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyspace
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/kvproto/pkg/keyspacepb"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/storage/kv"
+	"go.uber.org/zap"
+)
+
+// MaxBatchSize limits the keyspaces in a batch request, so all the operations
+// of a batch fit in a single etcd transaction, whose default `max-txn-ops` is 128.
+const MaxBatchSize = 32
+
+var errDuplicatedInBatch = errors.New("keyspace appears more than once in the batch")
+
+// BatchResult is the result of a keyspace in a batch request.
+type BatchResult struct {
+	// Name is the name of the keyspace in the request.
+	Name string
+	// Keyspace is the created or updated keyspace, nil if Err is not nil.
+	Keyspace *keyspacepb.KeyspaceMeta
+	// Err is the reason why the keyspace is not created or updated.
+	Err error
+}
+
+// UpdateConfigRequest represents the config mutations of a keyspace in a batch.
+type UpdateConfigRequest struct {
+	Name      string
+	Mutations []*Mutation
+}
+
+func validateBatchSize(size int) error {
+	if size > MaxBatchSize {
+		return errors.Errorf("batch size %d exceeds the limit %d", size, MaxBatchSize)
+	}
+	return nil
+}
+
+// CreateKeyspaces creates the keyspaces in a single transaction. An invalid or
+// existing keyspace fails alone with the error in its result, while the others
+// are still created. It returns error only if the whole batch fails.
+func (manager *Manager) CreateKeyspaces(requests []*CreateKeyspaceRequest) ([]*BatchResult, error) {
+	if err := validateBatchSize(len(requests)); err != nil {
+		return nil, err
+	}
+	results := make([]*BatchResult, len(requests))
+	keyspaces := make([]*keyspacepb.KeyspaceMeta, len(requests))
+	names := make(map[string]struct{}, len(requests))
+	ids := make([]uint32, 0, len(requests))
+	for i, request := range requests {
+		results[i] = &BatchResult{Name: request.Name}
+		if err := validateName(request.Name); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if err := validatePreSplit(request.PreSplit, request.ScatterPolicy); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if _, ok := names[request.Name]; ok {
+			results[i].Err = errDuplicatedInBatch
+			continue
+		}
+		names[request.Name] = struct{}{}
+		newID, err := manager.allocID()
+		if err != nil {
+			return nil, err
+		}
+		if err = manager.splitKeyspaceRegion(newID); err != nil {
+			return nil, err
+		}
+		keyspaces[i] = &keyspacepb.KeyspaceMeta{
+			Id:             newID,
+			Name:           request.Name,
+			State:          keyspacepb.KeyspaceState_ENABLED,
+			CreatedAt:      request.Now,
+			StateChangedAt: request.Now,
+			Config:         request.Config,
+		}
+		ids = append(ids, newID)
+	}
+
+	unlock := manager.lockKeyspaces(ids)
+	defer unlock()
+	err := manager.store.RunInTxn(manager.ctx, func(txn kv.Txn) error {
+		now := time.Now().Unix()
+		for i, keyspace := range keyspaces {
+			if keyspace == nil {
+				continue
+			}
+			err := manager.saveNewKeyspaceInTxn(txn, keyspace, now)
+			if err == ErrKeyspaceExists {
+				results[i].Err = err
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			results[i].Keyspace = keyspace
+		}
+		return nil
+	})
+	if err != nil {
+		log.Warn("[keyspace] failed to create keyspaces in batch",
+			zap.Int("batch-size", len(requests)),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	created := 0
+	for i, result := range results {
+		if result.Keyspace == nil {
+			continue
+		}
+		created++
+		manager.preSplitKeyspace(result.Keyspace.GetId(), requests[i].PreSplit, requests[i].ScatterPolicy)
+	}
+	log.Info("[keyspace] keyspaces created in batch",
+		zap.Int("batch-size", len(requests)),
+		zap.Int("created", created),
+	)
+	return results, nil
+}
+
+// UpdateKeyspacesConfig changes the config of the keyspaces in a single transaction.
+// A keyspace failing to update fails alone with the error in its result. It
+// returns error only if the whole batch fails.
+func (manager *Manager) UpdateKeyspacesConfig(requests []*UpdateConfigRequest) ([]*BatchResult, error) {
+	names := make([]string, len(requests))
+	for i, request := range requests {
+		names[i] = request.Name
+	}
+	results, err := manager.updateKeyspaces(names, func(i int, meta *keyspacepb.KeyspaceMeta) error {
+		return applyMutations(meta, requests[i].Mutations)
+	})
+	if err != nil {
+		log.Warn("[keyspace] failed to update keyspaces config in batch",
+			zap.Int("batch-size", len(requests)),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	log.Info("[keyspace] keyspaces config updated in batch", zap.Int("batch-size", len(requests)))
+	return results, nil
+}
+
+// UpdateKeyspacesState updates the keyspaces to the given state in a single
+// transaction. A keyspace failing to update fails alone with the error in its
+// result. It returns error only if the whole batch fails.
+func (manager *Manager) UpdateKeyspacesState(names []string, newState keyspacepb.KeyspaceState, now int64) ([]*BatchResult, error) {
+	oldStates := make([]keyspacepb.KeyspaceState, len(names))
+	results, err := manager.updateKeyspaces(names, func(i int, meta *keyspacepb.KeyspaceMeta) error {
+		// Changing the state of default keyspace is not allowed.
+		if meta.GetId() == DefaultKeyspaceID {
+			return errModifyDefault
+		}
+		oldStates[i] = meta.GetState()
+		return updateKeyspaceState(meta, newState, now)
+	})
+	if err != nil {
+		log.Warn("[keyspace] failed to update keyspaces state in batch",
+			zap.Int("batch-size", len(names)),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	for i, result := range results {
+		if result.Keyspace == nil {
+			continue
+		}
+		if err := manager.onStateUpdated(result.Keyspace.GetId(), oldStates[i], newState); err != nil {
+			result.Keyspace, result.Err = nil, err
+		}
+	}
+	log.Info("[keyspace] keyspaces state updated in batch",
+		zap.Int("batch-size", len(names)),
+		zap.String("new state", newState.String()),
+	)
+	return results, nil
+}
+
+// updateKeyspaces applies update to the keyspaces of the given names in a single
+// transaction, where i is the index of the keyspace in names. A keyspace whose
+// update returns error is not saved.
+func (manager *Manager) updateKeyspaces(names []string, update func(i int, meta *keyspacepb.KeyspaceMeta) error) ([]*BatchResult, error) {
+	if err := validateBatchSize(len(names)); err != nil {
+		return nil, err
+	}
+	results := make([]*BatchResult, len(names))
+	for i, name := range names {
+		results[i] = &BatchResult{Name: name}
+	}
+	err := manager.store.RunInTxn(manager.ctx, func(txn kv.Txn) error {
+		// Resolve all the keyspace IDs first to lock them at once.
+		now := time.Now().Unix()
+		keyspaceIDs := make([]uint32, len(names))
+		loadedIDs := make(map[uint32]struct{}, len(names))
+		for i, result := range results {
+			loaded, id, err := manager.loadKeyspaceID(txn, result.Name, now)
+			if err != nil {
+				return err
+			}
+			if !loaded {
+				result.Err = ErrKeyspaceNotFound
+				continue
+			}
+			if _, ok := loadedIDs[id]; ok {
+				result.Err = errDuplicatedInBatch
+				continue
+			}
+			loadedIDs[id] = struct{}{}
+			keyspaceIDs[i] = id
+		}
+		ids := make([]uint32, 0, len(loadedIDs))
+		for id := range loadedIDs {
+			ids = append(ids, id)
+		}
+		unlock := manager.lockKeyspaces(ids)
+		defer unlock()
+		for i, result := range results {
+			if result.Err != nil {
+				continue
+			}
+			meta, err := manager.store.LoadKeyspaceMeta(txn, keyspaceIDs[i])
+			if err != nil {
+				return err
+			}
+			if meta == nil {
+				result.Err = ErrKeyspaceNotFound
+				continue
+			}
+			meta.Id = keyspaceIDs[i]
+			if err = update(i, meta); err != nil {
+				result.Err = err
+				continue
+			}
+			if err = manager.store.SaveKeyspaceMeta(txn, meta); err != nil {
+				return err
+			}
+			result.Keyspace = meta
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// lockKeyspaces locks the metaLock of all the given keyspaces and returns the
+// function to unlock them. The locks are acquired in the order of their hashes,
+// so locking keyspaces sharing the same hash or in concurrent batches never
+// deadlocks.
+func (manager *Manager) lockKeyspaces(ids []uint32) func() {
+	hashed := make(map[uint32]uint32, len(ids))
+	for _, id := range ids {
+		hashed[keyspaceIDHash(id)] = id
+	}
+	locked := make([]uint32, 0, len(hashed))
+	for hash := range hashed {
+		locked = append(locked, hash)
+	}
+	sort.Slice(locked, func(i, j int) bool { return locked[i] < locked[j] })
+	for i, hash := range locked {
+		locked[i] = hashed[hash]
+		manager.metaLock.Lock(locked[i])
+	}
+	return func() {
+		for _, id := range locked {
+			manager.metaLock.Unlock(id)
+		}
+	}
+}
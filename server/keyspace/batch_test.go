@@ -0,0 +1,168 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyspace
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/keyspacepb"
+)
+
+func (suite *keyspaceTestSuite) TestCreateKeyspaces() {
+	re := suite.Require()
+	manager := suite.manager
+	now := time.Now().Unix()
+	_, err := manager.CreateKeyspace(&CreateKeyspaceRequest{Name: "existing", Now: now})
+	re.NoError(err)
+
+	requests := []*CreateKeyspaceRequest{
+		{Name: "batch_1", Now: now, Config: map[string]string{testConfig1: "100"}},
+		{Name: "illegal name", Now: now},
+		{Name: "existing", Now: now},
+		{Name: "batch_2", Now: now, PreSplit: maxPreSplit + 1},
+		{Name: "batch_1", Now: now},
+		{Name: "batch_3", Now: now},
+	}
+	results, err := manager.CreateKeyspaces(requests)
+	re.NoError(err)
+	re.Len(results, len(requests))
+	for i, result := range results {
+		re.Equal(requests[i].Name, result.Name)
+	}
+	re.NoError(results[0].Err)
+	re.Error(results[1].Err)
+	re.ErrorIs(results[2].Err, ErrKeyspaceExists)
+	re.Error(results[3].Err)
+	re.ErrorIs(results[4].Err, errDuplicatedInBatch)
+	re.NoError(results[5].Err)
+	for _, i := range []int{0, 5} {
+		re.Equal(keyspacepb.KeyspaceState_ENABLED, results[i].Keyspace.GetState())
+		loaded, err := manager.LoadKeyspace(requests[i].Name)
+		re.NoError(err)
+		re.Equal(results[i].Keyspace.GetId(), loaded.GetId())
+		re.Equal(results[i].Keyspace.GetConfig(), loaded.GetConfig())
+	}
+	re.Equal("100", results[0].Keyspace.GetConfig()[testConfig1])
+	for _, i := range []int{1, 2, 3, 4} {
+		re.Nil(results[i].Keyspace)
+	}
+	_, err = manager.LoadKeyspace("batch_2")
+	re.ErrorIs(err, ErrKeyspaceNotFound)
+
+	requests = make([]*CreateKeyspaceRequest, MaxBatchSize+1)
+	for i := range requests {
+		requests[i] = &CreateKeyspaceRequest{Name: fmt.Sprintf("too_many_%d", i), Now: now}
+	}
+	_, err = manager.CreateKeyspaces(requests)
+	re.Error(err)
+	_, err = manager.LoadKeyspace("too_many_0")
+	re.ErrorIs(err, ErrKeyspaceNotFound)
+}
+
+func (suite *keyspaceTestSuite) TestUpdateKeyspacesConfig() {
+	re := suite.Require()
+	manager := suite.manager
+	now := time.Now().Unix()
+	for _, name := range []string{"config_1", "config_2", "config_3"} {
+		_, err := manager.CreateKeyspace(&CreateKeyspaceRequest{Name: name, Now: now, Config: map[string]string{testConfig1: "100"}})
+		re.NoError(err)
+	}
+	_, err := manager.UpdateKeyspaceState("config_3", keyspacepb.KeyspaceState_DISABLED, now)
+	re.NoError(err)
+	_, err = manager.UpdateKeyspaceState("config_3", keyspacepb.KeyspaceState_ARCHIVED, now)
+	re.NoError(err)
+
+	results, err := manager.UpdateKeyspacesConfig([]*UpdateConfigRequest{
+		{Name: "config_1", Mutations: []*Mutation{{Op: OpPut, Key: testConfig2, Value: "200"}}},
+		{Name: "config_2", Mutations: []*Mutation{{Op: OpDel, Key: testConfig1}}},
+		{Name: "config_3", Mutations: []*Mutation{{Op: OpPut, Key: testConfig2, Value: "200"}}},
+		{Name: "unknown", Mutations: []*Mutation{{Op: OpPut, Key: testConfig2, Value: "200"}}},
+		{Name: "config_1", Mutations: []*Mutation{{Op: OpDel, Key: testConfig1}}},
+	})
+	re.NoError(err)
+	re.NoError(results[0].Err)
+	re.Equal(map[string]string{testConfig1: "100", testConfig2: "200"}, results[0].Keyspace.GetConfig())
+	re.NoError(results[1].Err)
+	re.Empty(results[1].Keyspace.GetConfig())
+	// Archived keyspace is not allowed to change its config.
+	re.Error(results[2].Err)
+	re.ErrorIs(results[3].Err, ErrKeyspaceNotFound)
+	re.ErrorIs(results[4].Err, errDuplicatedInBatch)
+	loaded, err := manager.LoadKeyspace("config_1")
+	re.NoError(err)
+	re.Equal(results[0].Keyspace.GetConfig(), loaded.GetConfig())
+	loaded, err = manager.LoadKeyspace("config_2")
+	re.NoError(err)
+	re.Empty(loaded.GetConfig())
+	loaded, err = manager.LoadKeyspace("config_3")
+	re.NoError(err)
+	re.Equal(map[string]string{testConfig1: "100"}, loaded.GetConfig())
+}
+
+func (suite *keyspaceTestSuite) TestUpdateKeyspacesState() {
+	re := suite.Require()
+	manager := suite.manager
+	now := time.Now().Unix()
+	for _, name := range []string{"state_1", "state_2"} {
+		_, err := manager.CreateKeyspace(&CreateKeyspaceRequest{Name: name, Now: now})
+		re.NoError(err)
+	}
+	results, err := manager.UpdateKeyspacesState(
+		[]string{"state_1", DefaultKeyspaceName, "unknown", "state_2"}, keyspacepb.KeyspaceState_DISABLED, now+1)
+	re.NoError(err)
+	re.ErrorIs(results[1].Err, errModifyDefault)
+	re.ErrorIs(results[2].Err, ErrKeyspaceNotFound)
+	for _, i := range []int{0, 3} {
+		re.NoError(results[i].Err)
+		re.Equal(keyspacepb.KeyspaceState_DISABLED, results[i].Keyspace.GetState())
+		re.Equal(now+1, results[i].Keyspace.GetStateChangedAt())
+	}
+	// Illegal state transition fails alone.
+	_, err = manager.UpdateKeyspaceState("state_2", keyspacepb.KeyspaceState_ENABLED, now)
+	re.NoError(err)
+	results, err = manager.UpdateKeyspacesState([]string{"state_1", "state_2"}, keyspacepb.KeyspaceState_ARCHIVED, now+2)
+	re.NoError(err)
+	re.NoError(results[0].Err)
+	re.Equal(keyspacepb.KeyspaceState_ARCHIVED, results[0].Keyspace.GetState())
+	re.Error(results[1].Err)
+	loaded, err := manager.LoadKeyspace("state_2")
+	re.NoError(err)
+	re.Equal(keyspacepb.KeyspaceState_ENABLED, loaded.GetState())
+}
+
+func (suite *keyspaceTestSuite) TestLockKeyspaces() {
+	re := suite.Require()
+	manager := suite.manager
+	// Keyspaces sharing the same hash are only locked once, and concurrent
+	// batches with different orders do not deadlock.
+	ids := []uint32{1, 257, 2, 513, 3}
+	reversed := []uint32{3, 513, 2, 257, 1}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			manager.lockKeyspaces(ids)()
+		}()
+		go func() {
+			defer wg.Done()
+			manager.lockKeyspaces(reversed)()
+		}()
+	}
+	wg.Wait()
+	re.NotNil(manager.lockKeyspaces(nil))
+}
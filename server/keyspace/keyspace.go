@@ -16,6 +16,7 @@ package keyspace
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	"github.com/pingcap/errors"
@@ -46,6 +47,8 @@ const (
 	regionLabelIDPrefix = "keyspaces/"
 	// regionLabelKey is the key for keyspace id in keyspace region label.
 	regionLabelKey = "id"
+	// quotaWebhookTimeout is the timeout to notify the quota webhook.
+	quotaWebhookTimeout = 10 * time.Second
 )
 
 // Manager manages keyspace related data.
@@ -63,6 +66,11 @@ type Manager struct {
 	ctx context.Context
 	// config is the configurations of the manager.
 	config config.KeyspaceConfig
+	// webhookClient notifies the quota webhook.
+	webhookClient *http.Client
+	quotaMu       syncutil.Mutex
+	// exceededQuotas are the keyspaces which exceed their quotas in the last check.
+	exceededQuotas map[uint32]struct{}
 }
 
 // CreateKeyspaceRequest represents necessary arguments to create a keyspace.
@@ -73,6 +81,11 @@ type CreateKeyspaceRequest struct {
 	Config map[string]string
 	// Now is the timestamp used to record creation time.
 	Now int64
+	// PreSplit is the number of regions each key range of the keyspace is split
+	// into at creation. The key ranges are not split further if it is less than 2.
+	PreSplit int
+	// ScatterPolicy is how the regions of the keyspace are scattered at creation.
+	ScatterPolicy ScatterPolicy
 }
 
 // NewKeyspaceManager creates a Manager of keyspace related data.
@@ -82,12 +95,14 @@ func NewKeyspaceManager(store endpoint.KeyspaceStorage,
 	config config.KeyspaceConfig,
 ) *Manager {
 	return &Manager{
-		metaLock:    syncutil.NewLockGroup(syncutil.WithHash(keyspaceIDHash)),
-		idAllocator: idAllocator,
-		store:       store,
-		rc:          rc,
-		ctx:         context.TODO(),
-		config:      config,
+		metaLock:       syncutil.NewLockGroup(syncutil.WithHash(keyspaceIDHash)),
+		idAllocator:    idAllocator,
+		store:          store,
+		rc:             rc,
+		ctx:            context.TODO(),
+		config:         config,
+		webhookClient:  &http.Client{Timeout: quotaWebhookTimeout},
+		exceededQuotas: make(map[uint32]struct{}),
 	}
 }
 
@@ -133,6 +148,9 @@ func (manager *Manager) CreateKeyspace(request *CreateKeyspaceRequest) (*keyspac
 	if err := validateName(request.Name); err != nil {
 		return nil, err
 	}
+	if err := validatePreSplit(request.PreSplit, request.ScatterPolicy); err != nil {
+		return nil, err
+	}
 	// Allocate new keyspaceID.
 	newID, err := manager.allocID()
 	if err != nil {
@@ -165,6 +183,7 @@ func (manager *Manager) CreateKeyspace(request *CreateKeyspaceRequest) (*keyspac
 		zap.Uint32("ID", keyspace.GetId()),
 		zap.String("name", keyspace.GetName()),
 	)
+	manager.preSplitKeyspace(newID, request.PreSplit, request.ScatterPolicy)
 	return keyspace, nil
 }
 
@@ -173,32 +192,40 @@ func (manager *Manager) saveNewKeyspace(keyspace *keyspacepb.KeyspaceMeta) error
 	defer manager.metaLock.Unlock(keyspace.Id)
 
 	return manager.store.RunInTxn(manager.ctx, func(txn kv.Txn) error {
-		// Save keyspace ID.
-		// Check if keyspace with that name already exists.
-		nameExists, _, err := manager.store.LoadKeyspaceID(txn, keyspace.Name)
-		if err != nil {
-			return err
-		}
-		if nameExists {
-			return ErrKeyspaceExists
-		}
-		err = manager.store.SaveKeyspaceID(txn, keyspace.Id, keyspace.Name)
-		if err != nil {
-			return err
-		}
-		// Save keyspace meta.
-		// Check if keyspace with that id already exists.
-		loadedMeta, err := manager.store.LoadKeyspaceMeta(txn, keyspace.Id)
-		if err != nil {
-			return err
-		}
-		if loadedMeta != nil {
-			return ErrKeyspaceExists
-		}
-		return manager.store.SaveKeyspaceMeta(txn, keyspace)
+		return manager.saveNewKeyspaceInTxn(txn, keyspace, time.Now().Unix())
 	})
 }
 
+// saveNewKeyspaceInTxn saves the keyspace in the transaction. Nothing is saved
+// if it returns ErrKeyspaceExists.
+func (manager *Manager) saveNewKeyspaceInTxn(txn kv.Txn, keyspace *keyspacepb.KeyspaceMeta, now int64) error {
+	// Check if keyspace with that name already exists.
+	nameExists, _, err := manager.loadKeyspaceID(txn, keyspace.Name, now)
+	if err != nil {
+		return err
+	}
+	if nameExists {
+		return ErrKeyspaceExists
+	}
+	// Check if keyspace with that id already exists.
+	loadedMeta, err := manager.store.LoadKeyspaceMeta(txn, keyspace.Id)
+	if err != nil {
+		return err
+	}
+	if loadedMeta != nil {
+		return ErrKeyspaceExists
+	}
+	// Remove the expired alias with that name if any.
+	if err = manager.store.RemoveKeyspaceAlias(txn, keyspace.Name); err != nil {
+		return err
+	}
+	// Save keyspace ID and meta.
+	if err = manager.store.SaveKeyspaceID(txn, keyspace.Id, keyspace.Name); err != nil {
+		return err
+	}
+	return manager.store.SaveKeyspaceMeta(txn, keyspace)
+}
+
 // splitKeyspaceRegion add keyspace's boundaries to region label. The corresponding
 // region will then be split by Coordinator's patrolRegion.
 func (manager *Manager) splitKeyspaceRegion(id uint32) error {
@@ -226,7 +253,7 @@ func (manager *Manager) splitKeyspaceRegion(id uint32) error {
 func (manager *Manager) LoadKeyspace(name string) (*keyspacepb.KeyspaceMeta, error) {
 	var meta *keyspacepb.KeyspaceMeta
 	err := manager.store.RunInTxn(manager.ctx, func(txn kv.Txn) error {
-		loaded, id, err := manager.store.LoadKeyspaceID(txn, name)
+		loaded, id, err := manager.loadKeyspaceID(txn, name, time.Now().Unix())
 		if err != nil {
 			return err
 		}
@@ -294,7 +321,7 @@ func (manager *Manager) UpdateKeyspaceConfig(name string, mutations []*Mutation)
 	var meta *keyspacepb.KeyspaceMeta
 	err := manager.store.RunInTxn(manager.ctx, func(txn kv.Txn) error {
 		// First get KeyspaceID from Name.
-		loaded, id, err := manager.store.LoadKeyspaceID(txn, name)
+		loaded, id, err := manager.loadKeyspaceID(txn, name, time.Now().Unix())
 		if err != nil {
 			return err
 		}
@@ -311,24 +338,8 @@ func (manager *Manager) UpdateKeyspaceConfig(name string, mutations []*Mutation)
 		if meta == nil {
 			return ErrKeyspaceNotFound
 		}
-		// Only keyspace with state listed in allowChangeConfig are allowed to change their config.
-		if !slice.Contains(allowChangeConfig, meta.GetState()) {
-			return errors.Errorf("cannot change config for keyspace with state %s", meta.GetState().String())
-		}
-		// Initialize meta's config map if it's nil.
-		if meta.GetConfig() == nil {
-			meta.Config = map[string]string{}
-		}
-		// Update keyspace config according to mutations.
-		for _, mutation := range mutations {
-			switch mutation.Op {
-			case OpPut:
-				meta.Config[mutation.Key] = mutation.Value
-			case OpDel:
-				delete(meta.Config, mutation.Key)
-			default:
-				return errIllegalOperation
-			}
+		if err = applyMutations(meta, mutations); err != nil {
+			return err
 		}
 		// Save the updated keyspace meta.
 		return manager.store.SaveKeyspaceMeta(txn, meta)
@@ -350,6 +361,30 @@ func (manager *Manager) UpdateKeyspaceConfig(name string, mutations []*Mutation)
 	return meta, nil
 }
 
+// applyMutations changes the keyspace config in the order specified in mutations.
+func applyMutations(meta *keyspacepb.KeyspaceMeta, mutations []*Mutation) error {
+	// Only keyspace with state listed in allowChangeConfig are allowed to change their config.
+	if !slice.Contains(allowChangeConfig, meta.GetState()) {
+		return errors.Errorf("cannot change config for keyspace with state %s", meta.GetState().String())
+	}
+	// Initialize meta's config map if it's nil.
+	if meta.GetConfig() == nil {
+		meta.Config = map[string]string{}
+	}
+	// Update keyspace config according to mutations.
+	for _, mutation := range mutations {
+		switch mutation.Op {
+		case OpPut:
+			meta.Config[mutation.Key] = mutation.Value
+		case OpDel:
+			delete(meta.Config, mutation.Key)
+		default:
+			return errIllegalOperation
+		}
+	}
+	return nil
+}
+
 // UpdateKeyspaceState updates target keyspace to the given state if it's not already in that state.
 // It returns error if saving failed, operation not allowed, or if keyspace not exists.
 func (manager *Manager) UpdateKeyspaceState(name string, newState keyspacepb.KeyspaceState, now int64) (*keyspacepb.KeyspaceMeta, error) {
@@ -360,10 +395,13 @@ func (manager *Manager) UpdateKeyspaceState(name string, newState keyspacepb.Key
 		)
 		return nil, errModifyDefault
 	}
-	var meta *keyspacepb.KeyspaceMeta
+	var (
+		meta     *keyspacepb.KeyspaceMeta
+		oldState keyspacepb.KeyspaceState
+	)
 	err := manager.store.RunInTxn(manager.ctx, func(txn kv.Txn) error {
 		// First get KeyspaceID from Name.
-		loaded, id, err := manager.store.LoadKeyspaceID(txn, name)
+		loaded, id, err := manager.loadKeyspaceID(txn, name, time.Now().Unix())
 		if err != nil {
 			return err
 		}
@@ -381,11 +419,15 @@ func (manager *Manager) UpdateKeyspaceState(name string, newState keyspacepb.Key
 			return ErrKeyspaceNotFound
 		}
 		// Update keyspace meta.
+		oldState = meta.GetState()
 		if err = updateKeyspaceState(meta, newState, now); err != nil {
 			return err
 		}
 		return manager.store.SaveKeyspaceMeta(txn, meta)
 	})
+	if err == nil {
+		err = manager.onStateUpdated(meta.GetId(), oldState, newState)
+	}
 	if err != nil {
 		log.Warn("[keyspace] failed to update keyspace config",
 			zap.Uint32("ID", meta.GetId()),
@@ -412,8 +454,11 @@ func (manager *Manager) UpdateKeyspaceStateByID(id uint32, newState keyspacepb.K
 		)
 		return nil, errModifyDefault
 	}
-	var meta *keyspacepb.KeyspaceMeta
-	var err error
+	var (
+		meta     *keyspacepb.KeyspaceMeta
+		oldState keyspacepb.KeyspaceState
+		err      error
+	)
 	err = manager.store.RunInTxn(manager.ctx, func(txn kv.Txn) error {
 		manager.metaLock.Lock(id)
 		defer manager.metaLock.Unlock(id)
@@ -426,11 +471,15 @@ func (manager *Manager) UpdateKeyspaceStateByID(id uint32, newState keyspacepb.K
 			return ErrKeyspaceNotFound
 		}
 		// Update keyspace meta.
+		oldState = meta.GetState()
 		if err = updateKeyspaceState(meta, newState, now); err != nil {
 			return err
 		}
 		return manager.store.SaveKeyspaceMeta(txn, meta)
 	})
+	if err == nil {
+		err = manager.onStateUpdated(meta.GetId(), oldState, newState)
+	}
 	if err != nil {
 		log.Warn("[keyspace] failed to update keyspace config",
 			zap.Uint32("ID", meta.GetId()),
@@ -56,9 +56,11 @@ func (suite *keyspaceTestSuite) SetupTest() {
 
 func (suite *keyspaceTestSuite) SetupSuite() {
 	suite.NoError(failpoint.Enable("github.com/tikv/pd/server/keyspace/skipSplitRegion", "return(true)"))
+	suite.NoError(failpoint.Enable("github.com/tikv/pd/server/keyspace/skipArchivePolicy", "return(true)"))
 }
 func (suite *keyspaceTestSuite) TearDownSuite() {
 	suite.NoError(failpoint.Disable("github.com/tikv/pd/server/keyspace/skipSplitRegion"))
+	suite.NoError(failpoint.Disable("github.com/tikv/pd/server/keyspace/skipArchivePolicy"))
 }
 
 func makeCreateKeyspaceRequests(count int) []*CreateKeyspaceRequest {
@@ -180,7 +182,7 @@ func (suite *keyspaceTestSuite) TestUpdateKeyspaceState() {
 		re.NoError(err)
 		re.Equal(updated.State, keyspacepb.KeyspaceState_ARCHIVED)
 		re.Equal(updated.StateChangedAt, newTime)
-		// Changing state of an ARCHIVED keyspace is not allowed.
+		// Enabling an ARCHIVED keyspace is not allowed.
 		_, err = manager.UpdateKeyspaceState(createRequest.Name, keyspacepb.KeyspaceState_ENABLED, newTime)
 		re.Error(err)
 		// Changing state of DEFAULT keyspace is not allowed.
@@ -189,6 +191,42 @@ func (suite *keyspaceTestSuite) TestUpdateKeyspaceState() {
 	}
 }
 
+func (suite *keyspaceTestSuite) TestArchiveKeyspace() {
+	re := suite.Require()
+	manager := suite.manager
+	requests := makeCreateKeyspaceRequests(3)
+	for _, createRequest := range requests {
+		_, err := manager.CreateKeyspace(createRequest)
+		re.NoError(err)
+		now := time.Now().Unix()
+		// Archiving an ENABLED keyspace is not allowed.
+		_, err = manager.ArchiveKeyspace(createRequest.Name, now)
+		re.Error(err)
+		_, err = manager.UpdateKeyspaceState(createRequest.Name, keyspacepb.KeyspaceState_DISABLED, now)
+		re.NoError(err)
+		archived, err := manager.ArchiveKeyspace(createRequest.Name, now+1)
+		re.NoError(err)
+		re.Equal(keyspacepb.KeyspaceState_ARCHIVED, archived.State)
+		re.Equal(now+1, archived.StateChangedAt)
+		// Archiving an ARCHIVED keyspace is allowed and does not update StateChangedAt.
+		archived, err = manager.ArchiveKeyspace(createRequest.Name, now+2)
+		re.NoError(err)
+		re.Equal(now+1, archived.StateChangedAt)
+		// Restoring an ARCHIVED keyspace makes it DISABLED.
+		restored, err := manager.RestoreKeyspace(createRequest.Name, now+3)
+		re.NoError(err)
+		re.Equal(keyspacepb.KeyspaceState_DISABLED, restored.State)
+		re.Equal(now+3, restored.StateChangedAt)
+		// The restored keyspace can be enabled again.
+		enabled, err := manager.UpdateKeyspaceState(createRequest.Name, keyspacepb.KeyspaceState_ENABLED, now+4)
+		re.NoError(err)
+		re.Equal(keyspacepb.KeyspaceState_ENABLED, enabled.State)
+	}
+	// Archiving a non-existing keyspace is not allowed.
+	_, err := manager.ArchiveKeyspace("not_exist", time.Now().Unix())
+	re.ErrorIs(err, ErrKeyspaceNotFound)
+}
+
 func (suite *keyspaceTestSuite) TestLoadRangeKeyspace() {
 	re := suite.Require()
 	manager := suite.manager
@@ -0,0 +1,49 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyspace
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	quotaUsageGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "keyspace",
+			Name:      "quota_usage_ratio",
+			Help:      "The ratio of the resources used by the keyspace to its quota.",
+		}, []string{"keyspace", "type"})
+
+	quotaExceededCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "keyspace",
+			Name:      "quota_exceeded_total",
+			Help:      "Counter of the times the keyspace exceeds its quota.",
+		}, []string{"keyspace"})
+
+	usageGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "keyspace",
+			Name:      "usage",
+			Help:      "The resources used by the keyspace.",
+		}, []string{"keyspace", "type"})
+)
+
+func init() {
+	prometheus.MustRegister(quotaUsageGauge)
+	prometheus.MustRegister(quotaExceededCounter)
+	prometheus.MustRegister(usageGauge)
+}
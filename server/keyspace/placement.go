@@ -0,0 +1,178 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyspace
+
+import (
+	"encoding/hex"
+	"strconv"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/server/schedule/placement"
+	"go.uber.org/zap"
+)
+
+const (
+	// placementRuleGroupPrefix is used to prefix the placement rule group of a keyspace's template.
+	placementRuleGroupPrefix = "keyspace-placement-"
+	// placementRuleGroupIndex makes the template override the default rules, while
+	// the archive rules still override the template.
+	placementRuleGroupIndex = 50
+)
+
+// PlacementRule is a placement rule of a keyspace's template. It is applied to
+// all the key ranges of the keyspace.
+type PlacementRule struct {
+	ID               string                      `json:"id"`
+	Role             placement.PeerRoleType      `json:"role"`
+	IsWitness        bool                        `json:"is_witness,omitempty"`
+	Count            int                         `json:"count"`
+	LabelConstraints []placement.LabelConstraint `json:"label_constraints,omitempty"`
+	LocationLabels   []string                    `json:"location_labels,omitempty"`
+	IsolationLevel   string                      `json:"isolation_level,omitempty"`
+}
+
+// PlacementTemplate is the default placement of a keyspace, which replaces the
+// default placement rules on all the key ranges of the keyspace.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type PlacementTemplate struct {
+	Rules []*PlacementRule `json:"rules"`
+}
+
+// validate checks the rule IDs of the template. The rules themselves are
+// checked by the rule manager when the template is applied.
+func (t *PlacementTemplate) validate() error {
+	if len(t.Rules) == 0 {
+		return errors.New("placement template should contain at least one rule")
+	}
+	ids := make(map[string]struct{}, len(t.Rules))
+	for _, rule := range t.Rules {
+		if rule == nil || rule.ID == "" {
+			return errors.New("placement template rule should have an ID")
+		}
+		if _, ok := ids[rule.ID]; ok {
+			return errors.Errorf("duplicated placement template rule %s", rule.ID)
+		}
+		ids[rule.ID] = struct{}{}
+	}
+	return nil
+}
+
+// SetPlacementTemplate sets the default placement of the keyspace. An existing
+// template of the keyspace is replaced.
+func (manager *Manager) SetPlacementTemplate(name string, template *PlacementTemplate) error {
+	if err := template.validate(); err != nil {
+		return err
+	}
+	meta, err := manager.LoadKeyspace(name)
+	if err != nil {
+		return err
+	}
+	if err := manager.checkPlacementRules(); err != nil {
+		return err
+	}
+	if err := manager.rc.GetRuleManager().SetGroupBundle(makePlacementRuleBundle(meta.GetId(), template)); err != nil {
+		return err
+	}
+	if err := manager.store.SaveKeyspacePlacement(meta.GetId(), template); err != nil {
+		return err
+	}
+	log.Info("[keyspace] keyspace placement template updated",
+		zap.Uint32("ID", meta.GetId()),
+		zap.String("name", name),
+		zap.Int("rules", len(template.Rules)),
+	)
+	return nil
+}
+
+// GetPlacementTemplate returns the default placement of the keyspace, or nil if
+// the keyspace has no template.
+func (manager *Manager) GetPlacementTemplate(name string) (*PlacementTemplate, error) {
+	meta, err := manager.LoadKeyspace(name)
+	if err != nil {
+		return nil, err
+	}
+	template := &PlacementTemplate{}
+	loaded, err := manager.store.LoadKeyspacePlacement(meta.GetId(), template)
+	if err != nil || !loaded {
+		return nil, err
+	}
+	return template, nil
+}
+
+// RemovePlacementTemplate removes the default placement of the keyspace, so its
+// key ranges follow the default placement rules again.
+func (manager *Manager) RemovePlacementTemplate(name string) error {
+	meta, err := manager.LoadKeyspace(name)
+	if err != nil {
+		return err
+	}
+	if err := manager.checkPlacementRules(); err != nil {
+		return err
+	}
+	if err := manager.rc.GetRuleManager().DeleteGroupBundle(getPlacementRuleGroupID(meta.GetId()), false); err != nil {
+		return err
+	}
+	if err := manager.store.RemoveKeyspacePlacement(meta.GetId()); err != nil {
+		return err
+	}
+	log.Info("[keyspace] keyspace placement template removed",
+		zap.Uint32("ID", meta.GetId()),
+		zap.String("name", name),
+	)
+	return nil
+}
+
+func (manager *Manager) checkPlacementRules() error {
+	if manager.rc == nil || !manager.rc.IsRunning() {
+		return errClusterNotReady
+	}
+	if !manager.rc.GetOpts().IsPlacementRulesEnabled() {
+		return errNoPlacementRules
+	}
+	return nil
+}
+
+func getPlacementRuleGroupID(id uint32) string {
+	return placementRuleGroupPrefix + strconv.FormatUint(uint64(id), endpoint.SpaceIDBase)
+}
+
+// makePlacementRuleBundle makes the placement rules of the template for every
+// key range of the keyspace.
+func makePlacementRuleBundle(id uint32, template *PlacementTemplate) placement.GroupBundle {
+	bundle := placement.GroupBundle{
+		ID:       getPlacementRuleGroupID(id),
+		Index:    placementRuleGroupIndex,
+		Override: true,
+	}
+	for i, bound := range makeKeyBounds(id) {
+		for _, rule := range template.Rules {
+			bundle.Rules = append(bundle.Rules, &placement.Rule{
+				GroupID:          bundle.ID,
+				ID:               rule.ID + "-" + keyBoundNames[i],
+				StartKeyHex:      hex.EncodeToString(bound[0]),
+				EndKeyHex:        hex.EncodeToString(bound[1]),
+				Role:             rule.Role,
+				IsWitness:        rule.IsWitness,
+				Count:            rule.Count,
+				LabelConstraints: rule.LabelConstraints,
+				LocationLabels:   rule.LocationLabels,
+				IsolationLevel:   rule.IsolationLevel,
+			})
+		}
+	}
+	return bundle
+}
@@ -0,0 +1,86 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyspace
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/server/schedule/placement"
+)
+
+func TestMakePlacementRuleBundle(t *testing.T) {
+	re := require.New(t)
+	template := &PlacementTemplate{Rules: []*PlacementRule{
+		{
+			ID:               "voters",
+			Role:             placement.Voter,
+			Count:            3,
+			LabelConstraints: []placement.LabelConstraint{{Key: "tenant", Op: placement.In, Values: []string{"a"}}},
+			LocationLabels:   []string{"zone"},
+		},
+		{ID: "learners", Role: placement.Learner, Count: 1},
+	}}
+	bundle := makePlacementRuleBundle(4242, template)
+	re.Equal("keyspace-placement-4242", bundle.ID)
+	re.Equal(placementRuleGroupIndex, bundle.Index)
+	re.Less(bundle.Index, archiveRuleGroupIndex)
+	re.True(bundle.Override)
+	re.Len(bundle.Rules, 4)
+	bounds := makeKeyBounds(4242)
+	for i, rule := range bundle.Rules {
+		bound, origin := bounds[i/2], template.Rules[i%2]
+		re.Equal(bundle.ID, rule.GroupID)
+		re.Equal(origin.ID+"-"+keyBoundNames[i/2], rule.ID)
+		re.Equal(hex.EncodeToString(bound[0]), rule.StartKeyHex)
+		re.Equal(hex.EncodeToString(bound[1]), rule.EndKeyHex)
+		re.Equal(origin.Role, rule.Role)
+		re.Equal(origin.Count, rule.Count)
+		re.Equal(origin.LabelConstraints, rule.LabelConstraints)
+		re.Equal(origin.LocationLabels, rule.LocationLabels)
+	}
+}
+
+func TestValidatePlacementTemplate(t *testing.T) {
+	re := require.New(t)
+	re.NoError((&PlacementTemplate{Rules: []*PlacementRule{{ID: "a"}, {ID: "b"}}}).validate())
+	re.Error((&PlacementTemplate{}).validate())
+	re.Error((&PlacementTemplate{Rules: []*PlacementRule{nil}}).validate())
+	re.Error((&PlacementTemplate{Rules: []*PlacementRule{{ID: ""}}}).validate())
+	re.Error((&PlacementTemplate{Rules: []*PlacementRule{{ID: "a"}, {ID: "a"}}}).validate())
+}
+
+func (suite *keyspaceTestSuite) TestPlacementTemplate() {
+	re := suite.Require()
+	manager := suite.manager
+	_, err := manager.CreateKeyspace(&CreateKeyspaceRequest{Name: "placement", Now: time.Now().Unix()})
+	re.NoError(err)
+	template, err := manager.GetPlacementTemplate("placement")
+	re.NoError(err)
+	re.Nil(template)
+	// The template can not be applied without the raft cluster.
+	template = &PlacementTemplate{Rules: []*PlacementRule{{ID: "voters", Role: placement.Voter, Count: 3}}}
+	re.ErrorIs(manager.SetPlacementTemplate("placement", template), errClusterNotReady)
+	template, err = manager.GetPlacementTemplate("placement")
+	re.NoError(err)
+	re.Nil(template)
+	re.ErrorIs(manager.RemovePlacementTemplate("placement"), errClusterNotReady)
+
+	_, err = manager.GetPlacementTemplate("unknown")
+	re.ErrorIs(err, ErrKeyspaceNotFound)
+	re.Error(manager.SetPlacementTemplate("unknown", &PlacementTemplate{}))
+}
@@ -0,0 +1,131 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyspace
+
+import (
+	"encoding/binary"
+	"strconv"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/codec"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/utils/logutil"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxPreSplit is the max number of regions a key range of the keyspace can
+	// be pre-split into, as the split keys differ in the first byte of the user key.
+	maxPreSplit = 256
+	// preSplitRetryLimit is the retry limit of splitting and scattering the regions.
+	preSplitRetryLimit = 5
+	// scatterGroupPrefix is used to prefix the scatter group of a keyspace.
+	scatterGroupPrefix = "keyspace-"
+)
+
+// ScatterPolicy is how the pre-split regions of a new keyspace are scattered.
+type ScatterPolicy string
+
+const (
+	// ScatterNone does not scatter the regions.
+	ScatterNone ScatterPolicy = "none"
+	// ScatterKeyspace scatters the regions in a group of the keyspace, so the
+	// regions of the keyspace are evenly distributed among the stores.
+	ScatterKeyspace ScatterPolicy = "keyspace"
+	// ScatterGlobal scatters the regions in the default group, which balances
+	// them with all the regions scattered in the default group.
+	ScatterGlobal ScatterPolicy = "global"
+)
+
+func validatePreSplit(preSplit int, policy ScatterPolicy) error {
+	if preSplit < 0 || preSplit > maxPreSplit {
+		return errors.Errorf("illegal pre-split %d, should be in [0, %d]", preSplit, maxPreSplit)
+	}
+	switch policy {
+	case "", ScatterNone, ScatterKeyspace, ScatterGlobal:
+		return nil
+	default:
+		return errors.Errorf("unknown scatter policy %s", policy)
+	}
+}
+
+// makePreSplitKeys returns the keys to split each key range of the keyspace into
+// preSplit regions evenly by the first byte of the user key, including the
+// boundaries of the key ranges.
+func makePreSplitKeys(id uint32, preSplit int) [][]byte {
+	keyspaceIDBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(keyspaceIDBytes, id)
+	bounds := makeKeyBounds(id)
+	var keys [][]byte
+	for i, mode := range []byte{'r', 'x'} {
+		keys = append(keys, bounds[i][0])
+		for j := 1; j < preSplit; j++ {
+			key := append([]byte{mode}, keyspaceIDBytes[1:]...)
+			keys = append(keys, codec.EncodeBytes(append(key, byte(j*maxPreSplit/preSplit))))
+		}
+		keys = append(keys, bounds[i][1])
+	}
+	return keys
+}
+
+func getScatterGroup(id uint32, policy ScatterPolicy) string {
+	if policy == ScatterKeyspace {
+		return scatterGroupPrefix + strconv.FormatUint(uint64(id), endpoint.SpaceIDBase)
+	}
+	return ""
+}
+
+// preSplitKeyspace splits the key ranges of the new keyspace and scatters the
+// split regions in the background, so the keyspace does not start with a single
+// hot region. It is skipped if the raft cluster is not running.
+func (manager *Manager) preSplitKeyspace(id uint32, preSplit int, policy ScatterPolicy) {
+	if preSplit < 2 && (policy == "" || policy == ScatterNone) {
+		return
+	}
+	if manager.rc == nil || !manager.rc.IsRunning() {
+		log.Warn("[keyspace] skip pre-splitting keyspace as the cluster is not running",
+			zap.Uint32("keyspaceID", id))
+		return
+	}
+	go func() {
+		defer logutil.LogPanic()
+		percentage, regionIDs := manager.rc.GetRegionSplitter().SplitRegions(
+			manager.ctx, makePreSplitKeys(id, preSplit), preSplitRetryLimit)
+		log.Info("[keyspace] pre-split keyspace",
+			zap.Uint32("keyspaceID", id),
+			zap.Int("pre-split", preSplit),
+			zap.Int("finished-percentage", percentage),
+			zap.Int("new-regions", len(regionIDs)))
+		if policy == "" || policy == ScatterNone {
+			return
+		}
+		group := getScatterGroup(id, policy)
+		for _, bound := range makeKeyBounds(id) {
+			opsCount, failures, err := manager.rc.GetRegionScatter().ScatterRegionsByRange(
+				bound[0], bound[1], group, preSplitRetryLimit)
+			if err != nil {
+				log.Warn("[keyspace] failed to scatter keyspace regions",
+					zap.Uint32("keyspaceID", id), zap.Error(err))
+				continue
+			}
+			log.Info("[keyspace] scattered keyspace regions",
+				zap.Uint32("keyspaceID", id),
+				zap.String("group", group),
+				zap.Int("operators", opsCount),
+				zap.Int("failures", len(failures)))
+		}
+	}()
+}
@@ -0,0 +1,82 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyspace
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/codec"
+)
+
+func TestMakePreSplitKeys(t *testing.T) {
+	re := require.New(t)
+	bounds := makeKeyBounds(4242)
+	// Only the boundaries are split without pre-split.
+	re.Equal([][]byte{bounds[0][0], bounds[0][1], bounds[1][0], bounds[1][1]}, makePreSplitKeys(4242, 0))
+	re.Equal(makePreSplitKeys(4242, 0), makePreSplitKeys(4242, 1))
+
+	keys := makePreSplitKeys(4242, 4)
+	re.Len(keys, 10)
+	re.Equal([][]byte{
+		bounds[0][0],
+		codec.EncodeBytes([]byte{'r', 0, 0x10, 0x92, 0x40}),
+		codec.EncodeBytes([]byte{'r', 0, 0x10, 0x92, 0x80}),
+		codec.EncodeBytes([]byte{'r', 0, 0x10, 0x92, 0xc0}),
+		bounds[0][1],
+	}, keys[:5])
+	// The keys are sorted and within the key ranges of the keyspace.
+	for i := 1; i < len(keys); i++ {
+		re.Less(bytes.Compare(keys[i-1], keys[i]), 0)
+	}
+	re.Len(makePreSplitKeys(4242, maxPreSplit), 2*(maxPreSplit+1))
+}
+
+func TestValidatePreSplit(t *testing.T) {
+	re := require.New(t)
+	re.NoError(validatePreSplit(0, ""))
+	re.NoError(validatePreSplit(maxPreSplit, ScatterKeyspace))
+	re.NoError(validatePreSplit(16, ScatterGlobal))
+	re.NoError(validatePreSplit(16, ScatterNone))
+	re.Error(validatePreSplit(-1, ""))
+	re.Error(validatePreSplit(maxPreSplit+1, ""))
+	re.Error(validatePreSplit(16, "unknown"))
+	re.Equal("keyspace-4242", getScatterGroup(4242, ScatterKeyspace))
+	re.Empty(getScatterGroup(4242, ScatterGlobal))
+}
+
+func (suite *keyspaceTestSuite) TestCreateKeyspaceWithPreSplit() {
+	re := suite.Require()
+	manager := suite.manager
+	_, err := manager.CreateKeyspace(&CreateKeyspaceRequest{
+		Name:     "illegal_pre_split",
+		Now:      time.Now().Unix(),
+		PreSplit: maxPreSplit + 1,
+	})
+	re.Error(err)
+	_, err = manager.LoadKeyspace("illegal_pre_split")
+	re.ErrorIs(err, ErrKeyspaceNotFound)
+	// Pre-splitting is skipped without the raft cluster.
+	created, err := manager.CreateKeyspace(&CreateKeyspaceRequest{
+		Name:          "pre_split",
+		Now:           time.Now().Unix(),
+		PreSplit:      16,
+		ScatterPolicy: ScatterKeyspace,
+	})
+	re.NoError(err)
+	re.Equal("pre_split", created.Name)
+}
@@ -0,0 +1,229 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyspace
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/keyspacepb"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/storage/kv"
+	"github.com/tikv/pd/pkg/utils/apiutil"
+	"github.com/tikv/pd/pkg/utils/logutil"
+	"github.com/tikv/pd/server/statistics"
+	"go.uber.org/zap"
+)
+
+// Quota limits the resources used by a keyspace. A zero limit means unlimited.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type Quota struct {
+	// MaxRegions is the max number of regions of the keyspace.
+	MaxRegions uint64 `json:"max_regions"`
+	// MaxApproximateSize is the max approximate size of the keyspace in MiB.
+	MaxApproximateSize uint64 `json:"max_approximate_size"`
+}
+
+// IsUnlimited returns whether the quota limits nothing.
+func (q *Quota) IsUnlimited() bool {
+	return q.MaxRegions == 0 && q.MaxApproximateSize == 0
+}
+
+// QuotaUsage is the quota of a keyspace and the resources used by it, which
+// are counted from the region statistics.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type QuotaUsage struct {
+	KeyspaceID uint32 `json:"keyspace_id"`
+	Name       string `json:"name"`
+	Quota      Quota  `json:"quota"`
+	Regions    uint64 `json:"regions"`
+	// ApproximateSize is the approximate size of the keyspace in MiB.
+	ApproximateSize uint64 `json:"approximate_size"`
+	Exceeded        bool   `json:"exceeded"`
+}
+
+func (u *QuotaUsage) update(stats *statistics.RegionStats) {
+	u.Regions += uint64(stats.Count)
+	if stats.StorageSize > 0 {
+		u.ApproximateSize += uint64(stats.StorageSize)
+	}
+	u.Exceeded = (u.Quota.MaxRegions > 0 && u.Regions > u.Quota.MaxRegions) ||
+		(u.Quota.MaxApproximateSize > 0 && u.ApproximateSize > u.Quota.MaxApproximateSize)
+}
+
+// regionStatsGetter returns the statistics of the regions in [startKey, endKey).
+type regionStatsGetter func(startKey, endKey []byte) *statistics.RegionStats
+
+// SetKeyspaceQuota sets the quota of the keyspace. The quota is removed if it is unlimited.
+func (manager *Manager) SetKeyspaceQuota(name string, quota *Quota) error {
+	meta, err := manager.LoadKeyspace(name)
+	if err != nil {
+		return err
+	}
+	if quota.IsUnlimited() {
+		err = manager.store.RemoveKeyspaceQuota(meta.GetId())
+	} else {
+		err = manager.store.SaveKeyspaceQuota(meta.GetId(), quota)
+	}
+	if err != nil {
+		return err
+	}
+	log.Info("[keyspace] keyspace quota updated",
+		zap.Uint32("ID", meta.GetId()),
+		zap.String("name", name),
+		zap.Uint64("max-regions", quota.MaxRegions),
+		zap.Uint64("max-approximate-size", quota.MaxApproximateSize),
+	)
+	return nil
+}
+
+// GetKeyspaceQuotaUsage returns the quota of the keyspace and the resources used
+// by it. The usage is only counted on the leader with the raft cluster running.
+func (manager *Manager) GetKeyspaceQuotaUsage(name string) (*QuotaUsage, error) {
+	meta, err := manager.LoadKeyspace(name)
+	if err != nil {
+		return nil, err
+	}
+	usage := &QuotaUsage{KeyspaceID: meta.GetId(), Name: meta.GetName()}
+	if _, err := manager.store.LoadKeyspaceQuota(meta.GetId(), &usage.Quota); err != nil {
+		return nil, err
+	}
+	if manager.rc != nil && manager.rc.IsRunning() {
+		for _, bound := range makeKeyBounds(meta.GetId()) {
+			usage.update(manager.rc.GetRegionStats(bound[0], bound[1]))
+		}
+	}
+	return usage, nil
+}
+
+// StartQuotaChecker starts checking the keyspace quotas in the background, which
+// is stopped once the context is canceled. It is called when the server becomes leader.
+func (manager *Manager) StartQuotaChecker(ctx context.Context) {
+	go manager.runQuotaChecker(ctx)
+}
+
+func (manager *Manager) runQuotaChecker(ctx context.Context) {
+	defer logutil.LogPanic()
+	defer manager.resetQuotaState()
+	ticker := time.NewTicker(manager.config.QuotaCheckInterval.Duration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		// The raft cluster is created after the leader callbacks are called.
+		if manager.rc == nil || !manager.rc.IsRunning() {
+			continue
+		}
+		if _, err := manager.checkQuotas(manager.rc.GetRegionStats); err != nil {
+			log.Warn("[keyspace] failed to check keyspace quotas", errs.ZapError(err))
+		}
+	}
+}
+
+// checkQuotas counts the resources used by the keyspaces with quotas, and
+// signals the keyspaces which just exceed their quotas.
+func (manager *Manager) checkQuotas(getStats regionStatsGetter) ([]*QuotaUsage, error) {
+	var usages []*QuotaUsage
+	err := manager.store.LoadKeyspaceQuotas(func(id uint32, value []byte) error {
+		usage := &QuotaUsage{KeyspaceID: id}
+		if err := json.Unmarshal(value, &usage.Quota); err != nil {
+			return errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+		}
+		usages = append(usages, usage)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	manager.quotaMu.Lock()
+	defer manager.quotaMu.Unlock()
+	exceeded := make(map[uint32]struct{})
+	for _, usage := range usages {
+		var meta *keyspacepb.KeyspaceMeta
+		err := manager.store.RunInTxn(manager.ctx, func(txn kv.Txn) (err error) {
+			meta, err = manager.store.LoadKeyspaceMeta(txn, usage.KeyspaceID)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		// The quota of a removed keyspace is ignored.
+		if meta == nil {
+			continue
+		}
+		usage.Name = meta.GetName()
+		for _, bound := range makeKeyBounds(usage.KeyspaceID) {
+			usage.update(getStats(bound[0], bound[1]))
+		}
+		setQuotaMetrics(usage)
+		if !usage.Exceeded {
+			continue
+		}
+		exceeded[usage.KeyspaceID] = struct{}{}
+		if _, ok := manager.exceededQuotas[usage.KeyspaceID]; !ok {
+			manager.onQuotaExceeded(usage)
+		}
+	}
+	manager.exceededQuotas = exceeded
+	return usages, nil
+}
+
+func (manager *Manager) onQuotaExceeded(usage *QuotaUsage) {
+	quotaExceededCounter.WithLabelValues(usage.Name).Inc()
+	log.Warn("[keyspace] keyspace exceeds its quota",
+		zap.Uint32("ID", usage.KeyspaceID),
+		zap.String("name", usage.Name),
+		zap.Uint64("regions", usage.Regions),
+		zap.Uint64("approximate-size", usage.ApproximateSize),
+		zap.Uint64("max-regions", usage.Quota.MaxRegions),
+		zap.Uint64("max-approximate-size", usage.Quota.MaxApproximateSize),
+	)
+	if len(manager.config.QuotaWebhook) == 0 {
+		return
+	}
+	data, err := json.Marshal(usage)
+	if err != nil {
+		log.Warn("[keyspace] failed to marshal keyspace quota usage", errs.ZapError(errs.ErrJSONMarshal.Wrap(err).GenWithStackByCause()))
+		return
+	}
+	if err := apiutil.PostJSONIgnoreResp(manager.webhookClient, manager.config.QuotaWebhook, data); err != nil {
+		log.Warn("[keyspace] failed to notify the keyspace quota webhook",
+			zap.String("webhook", manager.config.QuotaWebhook),
+			errs.ZapError(err),
+		)
+	}
+}
+
+func (manager *Manager) resetQuotaState() {
+	manager.quotaMu.Lock()
+	defer manager.quotaMu.Unlock()
+	manager.exceededQuotas = make(map[uint32]struct{})
+	quotaUsageGauge.Reset()
+}
+
+func setQuotaMetrics(usage *QuotaUsage) {
+	if usage.Quota.MaxRegions > 0 {
+		quotaUsageGauge.WithLabelValues(usage.Name, "regions").Set(float64(usage.Regions) / float64(usage.Quota.MaxRegions))
+	}
+	if usage.Quota.MaxApproximateSize > 0 {
+		quotaUsageGauge.WithLabelValues(usage.Name, "approximate-size").Set(float64(usage.ApproximateSize) / float64(usage.Quota.MaxApproximateSize))
+	}
+}
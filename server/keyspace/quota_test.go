@@ -0,0 +1,122 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyspace
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/tikv/pd/server/statistics"
+)
+
+func (suite *keyspaceTestSuite) TestKeyspaceQuota() {
+	re := suite.Require()
+	manager := suite.manager
+	created, err := manager.CreateKeyspace(&CreateKeyspaceRequest{Name: "quota_keyspace", Now: time.Now().Unix()})
+	re.NoError(err)
+
+	usage, err := manager.GetKeyspaceQuotaUsage(created.Name)
+	re.NoError(err)
+	re.True(usage.Quota.IsUnlimited())
+	re.Equal(created.Id, usage.KeyspaceID)
+
+	quota := &Quota{MaxRegions: 10, MaxApproximateSize: 100}
+	re.NoError(manager.SetKeyspaceQuota(created.Name, quota))
+	usage, err = manager.GetKeyspaceQuotaUsage(created.Name)
+	re.NoError(err)
+	re.Equal(*quota, usage.Quota)
+	// The usage is not counted without the raft cluster.
+	re.Zero(usage.Regions)
+	re.False(usage.Exceeded)
+
+	re.NoError(manager.SetKeyspaceQuota(created.Name, &Quota{}))
+	usage, err = manager.GetKeyspaceQuotaUsage(created.Name)
+	re.NoError(err)
+	re.True(usage.Quota.IsUnlimited())
+	re.Error(manager.SetKeyspaceQuota("unknown", quota))
+}
+
+func (suite *keyspaceTestSuite) TestCheckQuotas() {
+	re := suite.Require()
+	manager := suite.manager
+	notified := make(chan *QuotaUsage, 10)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		re.NoError(err)
+		usage := &QuotaUsage{}
+		re.NoError(json.Unmarshal(data, usage))
+		notified <- usage
+	}))
+	defer webhook.Close()
+	manager.config.QuotaWebhook = webhook.URL
+
+	keyspaces := make(map[uint32]string)
+	for _, name := range []string{"regions_limited", "size_limited", "unlimited"} {
+		created, err := manager.CreateKeyspace(&CreateKeyspaceRequest{Name: name, Now: time.Now().Unix()})
+		re.NoError(err)
+		keyspaces[created.Id] = name
+	}
+	re.NoError(manager.SetKeyspaceQuota("regions_limited", &Quota{MaxRegions: 10}))
+	re.NoError(manager.SetKeyspaceQuota("size_limited", &Quota{MaxApproximateSize: 100}))
+
+	// Every keyspace has 3 regions of 30MiB in both raw and txn mode.
+	getStats := func(startKey, endKey []byte) *statistics.RegionStats {
+		for id := range keyspaces {
+			for _, bound := range makeKeyBounds(id) {
+				if bytes.Equal(bound[0], startKey) && bytes.Equal(bound[1], endKey) {
+					return &statistics.RegionStats{Count: 3, StorageSize: 30}
+				}
+			}
+		}
+		return &statistics.RegionStats{}
+	}
+	usages, err := manager.checkQuotas(getStats)
+	re.NoError(err)
+	re.Len(usages, 2)
+	for _, usage := range usages {
+		re.Equal(keyspaces[usage.KeyspaceID], usage.Name)
+		re.Equal(uint64(6), usage.Regions)
+		re.Equal(uint64(60), usage.ApproximateSize)
+		re.False(usage.Exceeded)
+	}
+	re.Empty(notified)
+
+	// Only the keyspace exceeding its quota is notified, and it is notified once.
+	re.NoError(manager.SetKeyspaceQuota("size_limited", &Quota{MaxApproximateSize: 50}))
+	for i := 0; i < 2; i++ {
+		usages, err = manager.checkQuotas(getStats)
+		re.NoError(err)
+		re.Len(usages, 2)
+	}
+	re.Len(notified, 1)
+	usage := <-notified
+	re.Equal("size_limited", usage.Name)
+	re.True(usage.Exceeded)
+
+	// It is notified again after it recovers and exceeds again.
+	re.NoError(manager.SetKeyspaceQuota("size_limited", &Quota{}))
+	usages, err = manager.checkQuotas(getStats)
+	re.NoError(err)
+	re.Len(usages, 1)
+	re.NoError(manager.SetKeyspaceQuota("size_limited", &Quota{MaxRegions: 5}))
+	_, err = manager.checkQuotas(getStats)
+	re.NoError(err)
+	re.Len(notified, 1)
+	re.Equal("size_limited", (<-notified).Name)
+}
@@ -0,0 +1,139 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyspace
+
+import (
+	"github.com/pingcap/kvproto/pkg/keyspacepb"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/storage/kv"
+	"go.uber.org/zap"
+)
+
+// Alias is the old name of a renamed keyspace, which still refers to the
+// keyspace until it expires.
+type Alias struct {
+	ID       uint32 `json:"id"`
+	ExpireAt int64  `json:"expire_at"`
+}
+
+func (a *Alias) isExpired(now int64) bool {
+	return a.ExpireAt <= now
+}
+
+// loadKeyspaceID loads the ID of the keyspace with the given name, or the
+// keyspace the unexpired alias refers to.
+func (manager *Manager) loadKeyspaceID(txn kv.Txn, name string, now int64) (bool, uint32, error) {
+	loaded, id, err := manager.store.LoadKeyspaceID(txn, name)
+	if err != nil || loaded {
+		return loaded, id, err
+	}
+	alias := &Alias{}
+	loaded, err = manager.store.LoadKeyspaceAlias(txn, name, alias)
+	if err != nil || !loaded || alias.isExpired(now) {
+		return false, 0, err
+	}
+	return true, alias.ID, nil
+}
+
+// RenameKeyspace renames the keyspace, and keeps the old name as an alias of the
+// keyspace for the configured grace period. The new name can not be the name or
+// the unexpired alias of another keyspace.
+func (manager *Manager) RenameKeyspace(name, newName string, now int64) (*keyspacepb.KeyspaceMeta, error) {
+	if name == DefaultKeyspaceName {
+		log.Warn("[keyspace] failed to rename keyspace",
+			zap.Uint32("ID", DefaultKeyspaceID),
+			zap.String("name", DefaultKeyspaceName),
+			zap.Error(errRenameDefault),
+		)
+		return nil, errRenameDefault
+	}
+	if err := validateName(newName); err != nil {
+		return nil, err
+	}
+	var meta *keyspacepb.KeyspaceMeta
+	err := manager.store.RunInTxn(manager.ctx, func(txn kv.Txn) error {
+		loaded, id, err := manager.store.LoadKeyspaceID(txn, name)
+		if err != nil {
+			return err
+		}
+		if !loaded {
+			return ErrKeyspaceNotFound
+		}
+		manager.metaLock.Lock(id)
+		defer manager.metaLock.Unlock(id)
+		meta, err = manager.store.LoadKeyspaceMeta(txn, id)
+		if err != nil {
+			return err
+		}
+		if meta == nil {
+			return ErrKeyspaceNotFound
+		}
+		if name == newName {
+			return nil
+		}
+		// Check the new name is not used by another keyspace.
+		loaded, _, err = manager.store.LoadKeyspaceID(txn, newName)
+		if err != nil {
+			return err
+		}
+		if loaded {
+			return ErrKeyspaceExists
+		}
+		alias := &Alias{}
+		loaded, err = manager.store.LoadKeyspaceAlias(txn, newName, alias)
+		if err != nil {
+			return err
+		}
+		if loaded {
+			// Renaming back to an unexpired alias of the keyspace itself is allowed.
+			if !alias.isExpired(now) && alias.ID != id {
+				return ErrKeyspaceExists
+			}
+			if err = manager.store.RemoveKeyspaceAlias(txn, newName); err != nil {
+				return err
+			}
+		}
+		// Update the name index and keep the old name as an alias.
+		meta.Name = newName
+		if err = manager.store.SaveKeyspaceMeta(txn, meta); err != nil {
+			return err
+		}
+		if err = manager.store.RemoveKeyspaceID(txn, name); err != nil {
+			return err
+		}
+		if err = manager.store.SaveKeyspaceID(txn, id, newName); err != nil {
+			return err
+		}
+		return manager.store.SaveKeyspaceAlias(txn, name, &Alias{
+			ID:       id,
+			ExpireAt: now + int64(manager.config.AliasGracePeriod.Seconds()),
+		})
+	})
+	if err != nil {
+		log.Warn("[keyspace] failed to rename keyspace",
+			zap.String("name", name),
+			zap.String("new-name", newName),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	log.Info("[keyspace] keyspace renamed",
+		zap.Uint32("ID", meta.GetId()),
+		zap.String("old-name", name),
+		zap.String("new-name", newName),
+		zap.Duration("alias-grace-period", manager.config.AliasGracePeriod.Duration),
+	)
+	return meta, nil
+}
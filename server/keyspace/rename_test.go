@@ -0,0 +1,78 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyspace
+
+import (
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/keyspacepb"
+)
+
+func (suite *keyspaceTestSuite) TestRenameKeyspace() {
+	re := suite.Require()
+	manager := suite.manager
+	manager.config.AliasGracePeriod.Duration = time.Hour
+	created, err := manager.CreateKeyspace(&CreateKeyspaceRequest{Name: "old_name", Now: time.Now().Unix()})
+	re.NoError(err)
+	other, err := manager.CreateKeyspace(&CreateKeyspaceRequest{Name: "other", Now: time.Now().Unix()})
+	re.NoError(err)
+
+	now := time.Now().Unix()
+	renamed, err := manager.RenameKeyspace("old_name", "new_name", now)
+	re.NoError(err)
+	re.Equal(created.Id, renamed.Id)
+	re.Equal("new_name", renamed.Name)
+	// Both the new name and the alias refer to the keyspace.
+	for _, name := range []string{"new_name", "old_name"} {
+		loaded, err := manager.LoadKeyspace(name)
+		re.NoError(err)
+		re.Equal(renamed, loaded)
+	}
+	loaded, err := manager.LoadKeyspaceByID(created.Id)
+	re.NoError(err)
+	re.Equal("new_name", loaded.Name)
+	// The alias can be used to update the keyspace.
+	updated, err := manager.UpdateKeyspaceState("old_name", keyspacepb.KeyspaceState_DISABLED, now)
+	re.NoError(err)
+	re.Equal("new_name", updated.Name)
+	// The unexpired alias can not be used by other keyspaces.
+	_, err = manager.CreateKeyspace(&CreateKeyspaceRequest{Name: "old_name", Now: now})
+	re.ErrorIs(err, ErrKeyspaceExists)
+	_, err = manager.RenameKeyspace("other", "old_name", now)
+	re.ErrorIs(err, ErrKeyspaceExists)
+	_, err = manager.RenameKeyspace("other", "new_name", now)
+	re.ErrorIs(err, ErrKeyspaceExists)
+	// The keyspace can only be renamed by its current name.
+	_, err = manager.RenameKeyspace("old_name", "another_name", now)
+	re.ErrorIs(err, ErrKeyspaceNotFound)
+	// The expired alias can be reused.
+	_, err = manager.RenameKeyspace("other", "old_name", now+int64(time.Hour.Seconds()))
+	re.NoError(err)
+	loaded, err = manager.LoadKeyspace("old_name")
+	re.NoError(err)
+	re.Equal(other.Id, loaded.Id)
+
+	// Renaming back to its own unexpired alias is allowed.
+	_, err = manager.RenameKeyspace("new_name", "newer_name", now)
+	re.NoError(err)
+	renamed, err = manager.RenameKeyspace("newer_name", "new_name", now)
+	re.NoError(err)
+	re.Equal("new_name", renamed.Name)
+
+	_, err = manager.RenameKeyspace(DefaultKeyspaceName, "renamed_default", now)
+	re.Error(err)
+	_, err = manager.RenameKeyspace("new_name", "illegal/name", now)
+	re.Error(err)
+}
@@ -0,0 +1,151 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyspace
+
+import (
+	"context"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/keyspacepb"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/core"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/utils/logutil"
+)
+
+// usageScanBatch is the number of keyspaces loaded at a time when reporting usages.
+const usageScanBatch = 100
+
+// Usage is the resources used by a keyspace, which are counted from its regions.
+// A region across several keyspaces is counted in each of them.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type Usage struct {
+	KeyspaceID uint32 `json:"keyspace_id"`
+	Name       string `json:"name"`
+	Regions    uint64 `json:"regions"`
+	// ApproximateSize is the approximate size of the keyspace in MiB.
+	ApproximateSize uint64 `json:"approximate_size"`
+	ApproximateKeys uint64 `json:"approximate_keys"`
+	// The flows are the rates per second reported by the region leaders.
+	ReadBytesRate  float64 `json:"read_bytes_rate"`
+	ReadKeysRate   float64 `json:"read_keys_rate"`
+	WriteBytesRate float64 `json:"write_bytes_rate"`
+	WriteKeysRate  float64 `json:"write_keys_rate"`
+}
+
+func (u *Usage) observe(region *core.RegionInfo) {
+	u.Regions++
+	if size := region.GetApproximateSize(); size > 0 {
+		u.ApproximateSize += uint64(size)
+	}
+	if keys := region.GetApproximateKeys(); keys > 0 {
+		u.ApproximateKeys += uint64(keys)
+	}
+	readBytes, readKeys := region.GetReadRate()
+	writeBytes, writeKeys := region.GetWriteRate()
+	u.ReadBytesRate += readBytes
+	u.ReadKeysRate += readKeys
+	u.WriteBytesRate += writeBytes
+	u.WriteKeysRate += writeKeys
+}
+
+// regionScanner returns the regions overlapped with [startKey, endKey).
+type regionScanner func(startKey, endKey []byte) []*core.RegionInfo
+
+func countUsage(meta *keyspacepb.KeyspaceMeta, scan regionScanner) *Usage {
+	usage := &Usage{KeyspaceID: meta.GetId(), Name: meta.GetName()}
+	for _, bound := range makeKeyBounds(meta.GetId()) {
+		for _, region := range scan(bound[0], bound[1]) {
+			usage.observe(region)
+		}
+	}
+	return usage
+}
+
+func (manager *Manager) scanRegions(startKey, endKey []byte) []*core.RegionInfo {
+	return manager.rc.ScanRegions(startKey, endKey, -1)
+}
+
+// GetKeyspaceUsage returns the resources used by the keyspace. The usage is only
+// counted on the leader with the raft cluster running.
+func (manager *Manager) GetKeyspaceUsage(name string) (*Usage, error) {
+	meta, err := manager.LoadKeyspace(name)
+	if err != nil {
+		return nil, err
+	}
+	if manager.rc == nil || !manager.rc.IsRunning() {
+		return &Usage{KeyspaceID: meta.GetId(), Name: meta.GetName()}, nil
+	}
+	return countUsage(meta, manager.scanRegions), nil
+}
+
+// StartUsageReporter starts updating the keyspace usage metrics in the background,
+// which is stopped once the context is canceled. It is called when the server becomes leader.
+func (manager *Manager) StartUsageReporter(ctx context.Context) {
+	go manager.runUsageReporter(ctx)
+}
+
+func (manager *Manager) runUsageReporter(ctx context.Context) {
+	defer logutil.LogPanic()
+	defer usageGauge.Reset()
+	ticker := time.NewTicker(manager.config.UsageReportInterval.Duration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		// The raft cluster is created after the leader callbacks are called.
+		if manager.rc == nil || !manager.rc.IsRunning() {
+			continue
+		}
+		if err := manager.reportUsages(manager.scanRegions); err != nil {
+			log.Warn("[keyspace] failed to report keyspace usages", errs.ZapError(err))
+		}
+	}
+}
+
+// reportUsages updates the usage metrics of all the keyspaces except the tombstone ones.
+func (manager *Manager) reportUsages(scan regionScanner) error {
+	usageGauge.Reset()
+	var startID uint32
+	for {
+		metas, err := manager.LoadRangeKeyspace(startID, usageScanBatch)
+		if err != nil {
+			return err
+		}
+		for _, meta := range metas {
+			if meta.GetState() == keyspacepb.KeyspaceState_TOMBSTONE {
+				continue
+			}
+			setUsageMetrics(countUsage(meta, scan))
+		}
+		if len(metas) < usageScanBatch {
+			return nil
+		}
+		startID = metas[len(metas)-1].GetId() + 1
+	}
+}
+
+func setUsageMetrics(usage *Usage) {
+	usageGauge.WithLabelValues(usage.Name, "regions").Set(float64(usage.Regions))
+	usageGauge.WithLabelValues(usage.Name, "approximate_size").Set(float64(usage.ApproximateSize))
+	usageGauge.WithLabelValues(usage.Name, "approximate_keys").Set(float64(usage.ApproximateKeys))
+	usageGauge.WithLabelValues(usage.Name, "read_bytes_rate").Set(usage.ReadBytesRate)
+	usageGauge.WithLabelValues(usage.Name, "read_keys_rate").Set(usage.ReadKeysRate)
+	usageGauge.WithLabelValues(usage.Name, "write_bytes_rate").Set(usage.WriteBytesRate)
+	usageGauge.WithLabelValues(usage.Name, "write_keys_rate").Set(usage.WriteKeysRate)
+}
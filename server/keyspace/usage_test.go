@@ -0,0 +1,77 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyspace
+
+import (
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/keyspacepb"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/tikv/pd/pkg/core"
+)
+
+func (suite *keyspaceTestSuite) TestKeyspaceUsage() {
+	re := suite.Require()
+	manager := suite.manager
+	created, err := manager.CreateKeyspace(&CreateKeyspaceRequest{Name: "usage_keyspace", Now: time.Now().Unix()})
+	re.NoError(err)
+	// The usage is not counted without the raft cluster.
+	usage, err := manager.GetKeyspaceUsage(created.Name)
+	re.NoError(err)
+	re.Equal(created.Id, usage.KeyspaceID)
+	re.Zero(usage.Regions)
+	_, err = manager.GetKeyspaceUsage("unknown")
+	re.Error(err)
+
+	// Every keyspace has 2 regions in both raw and txn mode.
+	scan := func(startKey, endKey []byte) []*core.RegionInfo {
+		regions := make([]*core.RegionInfo, 0, 2)
+		for i := uint64(0); i < 2; i++ {
+			regions = append(regions, core.NewTestRegionInfo(i, 1, startKey, endKey,
+				core.SetApproximateSize(10), core.SetApproximateKeys(100),
+				core.SetReadBytes(50), core.SetReadKeys(5),
+				core.SetWrittenBytes(100), core.SetWrittenKeys(10),
+				core.SetReportInterval(0, 10)))
+		}
+		return regions
+	}
+	usage = countUsage(created, scan)
+	re.Equal(created.Name, usage.Name)
+	re.Equal(uint64(4), usage.Regions)
+	re.Equal(uint64(40), usage.ApproximateSize)
+	re.Equal(uint64(400), usage.ApproximateKeys)
+	re.Equal(float64(20), usage.ReadBytesRate)
+	re.Equal(float64(2), usage.ReadKeysRate)
+	re.Equal(float64(40), usage.WriteBytesRate)
+	re.Equal(float64(4), usage.WriteKeysRate)
+
+	// The usages of the tombstone keyspaces are not reported.
+	tombstone, err := manager.CreateKeyspace(&CreateKeyspaceRequest{Name: "tombstone_keyspace", Now: time.Now().Unix()})
+	re.NoError(err)
+	now := time.Now().Unix()
+	for _, state := range []keyspacepb.KeyspaceState{
+		keyspacepb.KeyspaceState_DISABLED,
+		keyspacepb.KeyspaceState_ARCHIVED,
+		keyspacepb.KeyspaceState_TOMBSTONE,
+	} {
+		_, err = manager.UpdateKeyspaceState(tombstone.Name, state, now)
+		re.NoError(err)
+	}
+	re.NoError(manager.reportUsages(scan))
+	re.Equal(float64(4), testutil.ToFloat64(usageGauge.WithLabelValues(created.Name, "regions")))
+	re.Equal(float64(40), testutil.ToFloat64(usageGauge.WithLabelValues(DefaultKeyspaceName, "write_bytes_rate")))
+	re.Zero(testutil.ToFloat64(usageGauge.WithLabelValues(tombstone.Name, "regions")))
+	usageGauge.Reset()
+}
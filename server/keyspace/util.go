@@ -41,14 +41,20 @@ var (
 	// Used when creating a new keyspace.
 	ErrKeyspaceExists   = errors.New("keyspace already exists")
 	errModifyDefault    = errors.New("cannot modify default keyspace's state")
+	errRenameDefault    = errors.New("cannot rename default keyspace")
 	errIllegalOperation = errors.New("unknown operation")
+	errClusterNotReady  = errors.New("raft cluster is not running")
+	errNoPlacementRules = errors.New("placement rules feature is disabled")
+
+	// keyBoundNames are the names of the raw and txn key ranges returned by makeKeyBounds.
+	keyBoundNames = [2]string{"raw", "txn"}
 
 	// stateTransitionTable lists all allowed next state for the given current state.
 	// Note that transit from any state to itself is allowed for idempotence.
 	stateTransitionTable = map[keyspacepb.KeyspaceState][]keyspacepb.KeyspaceState{
 		keyspacepb.KeyspaceState_ENABLED:   {keyspacepb.KeyspaceState_ENABLED, keyspacepb.KeyspaceState_DISABLED},
 		keyspacepb.KeyspaceState_DISABLED:  {keyspacepb.KeyspaceState_DISABLED, keyspacepb.KeyspaceState_ENABLED, keyspacepb.KeyspaceState_ARCHIVED},
-		keyspacepb.KeyspaceState_ARCHIVED:  {keyspacepb.KeyspaceState_ARCHIVED, keyspacepb.KeyspaceState_TOMBSTONE},
+		keyspacepb.KeyspaceState_ARCHIVED:  {keyspacepb.KeyspaceState_ARCHIVED, keyspacepb.KeyspaceState_DISABLED, keyspacepb.KeyspaceState_TOMBSTONE},
 		keyspacepb.KeyspaceState_TOMBSTONE: {keyspacepb.KeyspaceState_TOMBSTONE},
 	}
 	// Only keyspaces in the state specified by allowChangeConfig are allowed to change their config.
@@ -107,24 +113,30 @@ func keyspaceIDHash(id uint32) uint32 {
 // These repeated bound will not cause any problem, as repetitive bound will be ignored during rangeListBuild,
 // but provides guard against hole in keyspace allocations should it occur.
 func makeKeyRanges(id uint32) []interface{} {
+	ranges := make([]interface{}, 0, 2)
+	for _, bound := range makeKeyBounds(id) {
+		ranges = append(ranges, map[string]interface{}{
+			"start_key": hex.EncodeToString(bound[0]),
+			"end_key":   hex.EncodeToString(bound[1]),
+		})
+	}
+	return ranges
+}
+
+// makeKeyBounds returns the encoded [start, end) key ranges of the keyspace in raw and txn mode.
+func makeKeyBounds(id uint32) [][2][]byte {
 	keyspaceIDBytes := make([]byte, 4)
 	nextKeyspaceIDBytes := make([]byte, 4)
 	binary.BigEndian.PutUint32(keyspaceIDBytes, id)
 	binary.BigEndian.PutUint32(nextKeyspaceIDBytes, id+1)
-	rawLeftBound := hex.EncodeToString(codec.EncodeBytes(append([]byte{'r'}, keyspaceIDBytes[1:]...)))
-	rawRightBound := hex.EncodeToString(codec.EncodeBytes(append([]byte{'r'}, nextKeyspaceIDBytes[1:]...)))
-	txnLeftBound := hex.EncodeToString(codec.EncodeBytes(append([]byte{'x'}, keyspaceIDBytes[1:]...)))
-	txnRightBound := hex.EncodeToString(codec.EncodeBytes(append([]byte{'x'}, nextKeyspaceIDBytes[1:]...)))
-	return []interface{}{
-		map[string]interface{}{
-			"start_key": rawLeftBound,
-			"end_key":   rawRightBound,
-		},
-		map[string]interface{}{
-			"start_key": txnLeftBound,
-			"end_key":   txnRightBound,
-		},
+	bounds := make([][2][]byte, 0, 2)
+	for _, mode := range []byte{'r', 'x'} {
+		bounds = append(bounds, [2][]byte{
+			codec.EncodeBytes(append([]byte{mode}, keyspaceIDBytes[1:]...)),
+			codec.EncodeBytes(append([]byte{mode}, nextKeyspaceIDBytes[1:]...)),
+		})
 	}
+	return bounds
 }
 
 // getRegionLabelID returns the region label id of the target keyspace.
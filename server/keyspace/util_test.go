@@ -123,3 +123,31 @@ func TestMakeLabelRule(t *testing.T) {
 		re.Equal(testCase.expectedLabelRule, makeLabelRule(testCase.id))
 	}
 }
+
+func TestMakeArchiveRules(t *testing.T) {
+	re := require.New(t)
+	labelRule := makeArchiveLabelRule(4242)
+	re.Equal("keyspaces/4242/archive", labelRule.ID)
+	re.Equal([]labeler.RegionLabel{{Key: labeler.ScheduleOptionLabel, Value: labeler.ScheduleOptionValueLow}}, labelRule.Labels)
+	re.Equal(makeLabelRule(4242).Data, labelRule.Data)
+
+	bundle := makeArchiveRuleBundle(4242, "tier", "cold", 3, []string{"zone", "host"})
+	re.Equal("keyspace-archive-4242", bundle.ID)
+	re.True(bundle.Override)
+	re.Len(bundle.Rules, 2)
+	expectedKeys := [][2][]byte{
+		{codec.EncodeBytes([]byte{'r', 0, 0x10, 0x92}), codec.EncodeBytes([]byte{'r', 0, 0x10, 0x93})},
+		{codec.EncodeBytes([]byte{'x', 0, 0x10, 0x92}), codec.EncodeBytes([]byte{'x', 0, 0x10, 0x93})},
+	}
+	for i, rule := range bundle.Rules {
+		re.Equal(bundle.ID, rule.GroupID)
+		re.Equal(keyBoundNames[i], rule.ID)
+		re.Equal(hex.EncodeToString(expectedKeys[i][0]), rule.StartKeyHex)
+		re.Equal(hex.EncodeToString(expectedKeys[i][1]), rule.EndKeyHex)
+		re.Equal(3, rule.Count)
+		re.Len(rule.LabelConstraints, 1)
+		re.Equal("tier", rule.LabelConstraints[0].Key)
+		re.Equal([]string{"cold"}, rule.LabelConstraints[0].Values)
+		re.Equal([]string{"zone", "host"}, rule.LocationLabels)
+	}
+}
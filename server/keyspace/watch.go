@@ -0,0 +1,159 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyspace
+
+import (
+	"context"
+	"path"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/kvproto/pkg/keyspacepb"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"go.etcd.io/etcd/clientv3"
+)
+
+// ChangeType is the type of a keyspace metadata change.
+type ChangeType string
+
+const (
+	// ChangeCreate means the keyspace is created.
+	ChangeCreate ChangeType = "create"
+	// ChangeState means the state of the keyspace is updated.
+	ChangeState ChangeType = "state"
+	// ChangeConfig means the config of the keyspace is updated.
+	ChangeConfig ChangeType = "config"
+	// ChangeRename means the keyspace is renamed.
+	ChangeRename ChangeType = "rename"
+)
+
+var errWatchClosed = errors.New("keyspace watch channel is closed")
+
+// Change is a change of keyspace metadata.
+type Change struct {
+	Type     ChangeType
+	Keyspace *keyspacepb.KeyspaceMeta
+	// Revision is the etcd revision of the change. The changes saved in the
+	// same transaction share the same revision.
+	Revision int64
+}
+
+// Watcher watches the keyspace metadata changes in etcd.
+type Watcher struct {
+	client *clientv3.Client
+	// prefix is the etcd key prefix of the keyspace metadata.
+	prefix string
+}
+
+// NewWatcher creates a Watcher of the keyspace metadata under the root path.
+func NewWatcher(client *clientv3.Client, rootPath string) *Watcher {
+	return &Watcher{
+		client: client,
+		prefix: path.Join(rootPath, endpoint.KeyspaceMetaPrefix()),
+	}
+}
+
+// LoadAll loads all the keyspaces and returns the revision they are loaded at.
+// Watching from the next revision gets all the changes after the load.
+func (w *Watcher) LoadAll(ctx context.Context) ([]*keyspacepb.KeyspaceMeta, int64, error) {
+	resp, err := w.client.Get(ctx, w.prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, 0, errs.ErrEtcdKVGet.Wrap(err).GenWithStackByCause()
+	}
+	metas := make([]*keyspacepb.KeyspaceMeta, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		meta := &keyspacepb.KeyspaceMeta{}
+		if err = proto.Unmarshal(kv.Value, meta); err != nil {
+			return nil, 0, errs.ErrProtoUnmarshal.Wrap(err).GenWithStackByCause()
+		}
+		metas = append(metas, meta)
+	}
+	return metas, resp.Header.GetRevision(), nil
+}
+
+// Watch calls f with the keyspace changes since the start revision, until ctx
+// is done or any error occurs. The changes passed to each call of f are in the
+// order of their revisions. It returns error if the start revision is compacted.
+func (w *Watcher) Watch(ctx context.Context, startRevision int64, f func(changes []*Change) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	watchChan := w.client.Watch(clientv3.WithRequireLeader(ctx), w.prefix,
+		clientv3.WithPrefix(), clientv3.WithRev(startRevision), clientv3.WithPrevKV())
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case resp, ok := <-watchChan:
+			if !ok {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return errWatchClosed
+			}
+			if err := resp.Err(); err != nil {
+				return errs.ErrEtcdWatcherCancel.Wrap(err).GenWithStackByCause()
+			}
+			changes := make([]*Change, 0, len(resp.Events))
+			for _, event := range resp.Events {
+				// Keyspace metadata is never deleted.
+				if event.Type != clientv3.EventTypePut {
+					continue
+				}
+				var prevValue []byte
+				if event.PrevKv != nil {
+					prevValue = event.PrevKv.Value
+				}
+				change, err := makeChange(event.Kv.Value, prevValue, event.Kv.ModRevision)
+				if err != nil {
+					return err
+				}
+				changes = append(changes, change)
+			}
+			if len(changes) == 0 {
+				continue
+			}
+			if err := f(changes); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// makeChange makes the change of the keyspace from its new and previous metadata.
+// The keyspace is created if it has no previous metadata.
+func makeChange(value, prevValue []byte, revision int64) (*Change, error) {
+	meta := &keyspacepb.KeyspaceMeta{}
+	if err := proto.Unmarshal(value, meta); err != nil {
+		return nil, errs.ErrProtoUnmarshal.Wrap(err).GenWithStackByCause()
+	}
+	change := &Change{Type: ChangeCreate, Keyspace: meta, Revision: revision}
+	if len(prevValue) == 0 {
+		return change, nil
+	}
+	prev := &keyspacepb.KeyspaceMeta{}
+	if err := proto.Unmarshal(prevValue, prev); err != nil {
+		return nil, errs.ErrProtoUnmarshal.Wrap(err).GenWithStackByCause()
+	}
+	switch {
+	case prev.GetName() != meta.GetName():
+		change.Type = ChangeRename
+	case prev.GetState() != meta.GetState():
+		change.Type = ChangeState
+	default:
+		change.Type = ChangeConfig
+	}
+	return change, nil
+}
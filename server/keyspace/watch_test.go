@@ -0,0 +1,131 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyspace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pingcap/failpoint"
+	"github.com/pingcap/kvproto/pkg/keyspacepb"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/mock/mockid"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/storage/kv"
+	"github.com/tikv/pd/pkg/utils/etcdutil"
+	"github.com/tikv/pd/pkg/utils/typeutil"
+	"github.com/tikv/pd/server/config"
+	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/embed"
+)
+
+func TestMakeChange(t *testing.T) {
+	re := require.New(t)
+	marshal := func(meta *keyspacepb.KeyspaceMeta) []byte {
+		value, err := proto.Marshal(meta)
+		re.NoError(err)
+		return value
+	}
+	prev := &keyspacepb.KeyspaceMeta{Id: 1, Name: "ks", State: keyspacepb.KeyspaceState_ENABLED}
+	change, err := makeChange(marshal(prev), nil, 10)
+	re.NoError(err)
+	re.Equal(ChangeCreate, change.Type)
+	re.Equal(int64(10), change.Revision)
+	re.Equal("ks", change.Keyspace.GetName())
+
+	for _, testCase := range []struct {
+		meta     *keyspacepb.KeyspaceMeta
+		expected ChangeType
+	}{
+		{&keyspacepb.KeyspaceMeta{Id: 1, Name: "ks", State: keyspacepb.KeyspaceState_DISABLED}, ChangeState},
+		{&keyspacepb.KeyspaceMeta{Id: 1, Name: "ks", State: keyspacepb.KeyspaceState_ENABLED, Config: map[string]string{"a": "b"}}, ChangeConfig},
+		{&keyspacepb.KeyspaceMeta{Id: 1, Name: "new_ks", State: keyspacepb.KeyspaceState_ENABLED}, ChangeRename},
+	} {
+		change, err = makeChange(marshal(testCase.meta), marshal(prev), 11)
+		re.NoError(err)
+		re.Equal(testCase.expected, change.Type)
+	}
+	_, err = makeChange([]byte("invalid"), nil, 12)
+	re.Error(err)
+}
+
+func TestWatcher(t *testing.T) {
+	re := require.New(t)
+	re.NoError(failpoint.Enable("github.com/tikv/pd/server/keyspace/skipSplitRegion", "return(true)"))
+	defer func() {
+		re.NoError(failpoint.Disable("github.com/tikv/pd/server/keyspace/skipSplitRegion"))
+	}()
+	cfg := etcdutil.NewTestSingleConfig(t)
+	etcd, err := embed.StartEtcd(cfg)
+	re.NoError(err)
+	defer etcd.Close()
+	<-etcd.Server.ReadyNotify()
+	client, err := clientv3.New(clientv3.Config{Endpoints: []string{cfg.LCUrls[0].String()}})
+	re.NoError(err)
+	defer client.Close()
+
+	rootPath := "/pd/0"
+	store := endpoint.NewStorageEndpoint(kv.NewEtcdKVBase(client, rootPath), nil)
+	manager := NewKeyspaceManager(store, nil, mockid.NewIDAllocator(), config.KeyspaceConfig{AliasGracePeriod: typeutil.NewDuration(time.Hour)})
+	re.NoError(manager.Bootstrap())
+	watcher := NewWatcher(client, rootPath)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	metas, revision, err := watcher.LoadAll(ctx)
+	re.NoError(err)
+	re.Len(metas, 1)
+	re.Equal(DefaultKeyspaceName, metas[0].GetName())
+
+	now := time.Now().Unix()
+	_, err = manager.CreateKeyspace(&CreateKeyspaceRequest{Name: "watch", Now: now})
+	re.NoError(err)
+	_, err = manager.UpdateKeyspaceConfig("watch", []*Mutation{{Op: OpPut, Key: testConfig1, Value: "100"}})
+	re.NoError(err)
+	_, err = manager.UpdateKeyspaceState("watch", keyspacepb.KeyspaceState_DISABLED, now)
+	re.NoError(err)
+	_, err = manager.RenameKeyspace("watch", "watched", now)
+	re.NoError(err)
+
+	// The changes after the load are watched in order.
+	var changes []*Change
+	watchCtx, watchCancel := context.WithCancel(ctx)
+	err = watcher.Watch(watchCtx, revision+1, func(watched []*Change) error {
+		changes = append(changes, watched...)
+		if len(changes) >= 4 {
+			watchCancel()
+		}
+		return nil
+	})
+	re.NoError(err)
+	re.Len(changes, 4)
+	for i, expected := range []ChangeType{ChangeCreate, ChangeConfig, ChangeState, ChangeRename} {
+		re.Equal(expected, changes[i].Type)
+		re.Greater(changes[i].Revision, revision)
+		if i > 0 {
+			re.Greater(changes[i].Revision, changes[i-1].Revision)
+		}
+	}
+	re.Equal("watched", changes[3].Keyspace.GetName())
+	re.Equal(keyspacepb.KeyspaceState_DISABLED, changes[3].Keyspace.GetState())
+
+	// The error of the callback stops watching.
+	err = watcher.Watch(ctx, revision+1, func([]*Change) error {
+		return errWatchClosed
+	})
+	re.ErrorIs(err, errWatchClosed)
+}
@@ -0,0 +1,201 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/rbac"
+	"github.com/tikv/pd/pkg/utils/grpcutil"
+	"github.com/tikv/pd/server/keyspace"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// KeyspaceGCServiceName is the name of the gRPC service managing the GC
+// safepoints of the keyspaces. Like the rule service, kvproto doesn't define
+// the service, so its messages are the Go structs below encoded in JSON.
+const KeyspaceGCServiceName = "pd.KeyspaceGC"
+
+// UpdateKeyspaceGCSafePointRequest is the request of UpdateGCSafePoint.
+type UpdateKeyspaceGCSafePointRequest struct {
+	Header       *pdpb.RequestHeader `json:"header"`
+	KeyspaceName string              `json:"keyspace_name"`
+	SafePoint    uint64              `json:"safe_point"`
+}
+
+// UpdateKeyspaceGCSafePointResponse is the response of UpdateGCSafePoint.
+type UpdateKeyspaceGCSafePointResponse struct {
+	Header       *pdpb.ResponseHeader `json:"header"`
+	NewSafePoint uint64               `json:"new_safe_point"`
+}
+
+// UpdateKeyspaceServiceGCSafePointRequest is the request of UpdateServiceGCSafePoint.
+type UpdateKeyspaceServiceGCSafePointRequest struct {
+	Header       *pdpb.RequestHeader `json:"header"`
+	KeyspaceName string              `json:"keyspace_name"`
+	ServiceID    string              `json:"service_id"`
+	// TTL is the time to live of the service safepoint in seconds, the service
+	// safepoint is removed if it is not positive.
+	TTL       int64  `json:"ttl"`
+	SafePoint uint64 `json:"safe_point"`
+}
+
+// UpdateKeyspaceServiceGCSafePointResponse is the response of UpdateServiceGCSafePoint.
+type UpdateKeyspaceServiceGCSafePointResponse struct {
+	Header *pdpb.ResponseHeader `json:"header"`
+	// ServiceID is the service of the min service safepoint, which is empty if
+	// there is no service safepoint and MinSafePoint is the GC safepoint.
+	ServiceID    string `json:"service_id,omitempty"`
+	MinSafePoint uint64 `json:"min_safe_point"`
+}
+
+// KeyspaceGCServer wraps GrpcServer to provide the keyspace GC service.
+type KeyspaceGCServer struct {
+	*GrpcServer
+}
+
+// RegisterKeyspaceGCServer registers the keyspace GC service to the gRPC server.
+func RegisterKeyspaceGCServer(gs *grpc.Server, srv *KeyspaceGCServer) {
+	gs.RegisterService(&keyspaceGCServiceDesc, srv)
+}
+
+var keyspaceGCServiceDesc = grpc.ServiceDesc{
+	ServiceName: KeyspaceGCServiceName,
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "UpdateGCSafePoint",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				request := &UpdateKeyspaceGCSafePointRequest{}
+				if err := dec(request); err != nil {
+					return nil, err
+				}
+				return srv.(*KeyspaceGCServer).UpdateGCSafePoint(ctx, request)
+			},
+		},
+		{
+			MethodName: "UpdateServiceGCSafePoint",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				request := &UpdateKeyspaceServiceGCSafePointRequest{}
+				if err := dec(request); err != nil {
+					return nil, err
+				}
+				return srv.(*KeyspaceGCServer).UpdateServiceGCSafePoint(ctx, request)
+			},
+		},
+	},
+}
+
+// checkRequest checks the policy, the permission and the header of the
+// request, and returns the ID of the keyspace, or the error header if the
+// keyspace can't be found.
+func (s *KeyspaceGCServer) checkRequest(ctx context.Context, header *pdpb.RequestHeader, keyspaceName string) (uint32, *pdpb.ResponseHeader, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return 0, nil, grpcutil.StatusError(ctx, err)
+	}
+	if err := s.AuthorizeGRPC(ctx, rbac.RoleOperator); err != nil {
+		return 0, nil, grpcutil.StatusError(ctx, err)
+	}
+	if err := s.validateRequest(header); err != nil {
+		return 0, nil, grpcutil.StatusError(ctx, err)
+	}
+	if s.GetRaftCluster() == nil {
+		return 0, s.notBootstrappedHeader(), nil
+	}
+	meta, err := s.GetKeyspaceManager().LoadKeyspace(keyspaceName)
+	if err != nil {
+		if err == keyspace.ErrKeyspaceNotFound {
+			return 0, s.wrapErrorToHeader(pdpb.ErrorType_ENTRY_NOT_FOUND, err.Error()), nil
+		}
+		return 0, s.wrapErrorToHeader(pdpb.ErrorType_UNKNOWN, err.Error()), nil
+	}
+	return meta.GetId(), nil, nil
+}
+
+// UpdateGCSafePoint updates the GC safepoint of the keyspace if the new one is
+// greater than the current one, and returns the GC safepoint after updating.
+func (s *KeyspaceGCServer) UpdateGCSafePoint(ctx context.Context, request *UpdateKeyspaceGCSafePointRequest) (*UpdateKeyspaceGCSafePointResponse, error) {
+	id, header, err := s.checkRequest(ctx, request.Header, request.KeyspaceName)
+	if err != nil {
+		return nil, err
+	}
+	if header != nil {
+		return &UpdateKeyspaceGCSafePointResponse{Header: header}, nil
+	}
+	manager := s.GetKeyspaceSafePointManager()
+	oldSafePoint, err := manager.UpdateGCSafePoint(id, request.SafePoint)
+	if err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
+	// The new safepoint may be limited by the barriers of the keyspace.
+	newSafePoint, err := manager.LoadGCSafePoint(id)
+	if err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
+	if newSafePoint > oldSafePoint {
+		log.Info("updated keyspace gc safe point",
+			zap.String("keyspace", request.KeyspaceName),
+			zap.Uint64("safe-point", newSafePoint))
+	}
+	return &UpdateKeyspaceGCSafePointResponse{Header: s.header(), NewSafePoint: newSafePoint}, nil
+}
+
+// UpdateServiceGCSafePoint updates the safepoint of the service in the
+// keyspace, and returns the min service safepoint of the keyspace.
+func (s *KeyspaceGCServer) UpdateServiceGCSafePoint(ctx context.Context, request *UpdateKeyspaceServiceGCSafePointRequest) (*UpdateKeyspaceServiceGCSafePointResponse, error) {
+	id, header, err := s.checkRequest(ctx, request.Header, request.KeyspaceName)
+	if err != nil {
+		return nil, err
+	}
+	if header != nil {
+		return &UpdateKeyspaceServiceGCSafePointResponse{Header: header}, nil
+	}
+	if len(request.ServiceID) == 0 {
+		return &UpdateKeyspaceServiceGCSafePointResponse{Header: s.invalidValue("service id of service safepoint cannot be empty")}, nil
+	}
+	manager := s.GetKeyspaceSafePointManager()
+	if request.TTL <= 0 {
+		if err := manager.RemoveServiceGCSafePoint(id, request.ServiceID); err != nil {
+			return nil, grpcutil.StatusError(ctx, err)
+		}
+	}
+	min, updated, err := manager.UpdateServiceGCSafePoint(id, request.ServiceID, request.SafePoint, request.TTL, time.Now())
+	if err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
+	if updated {
+		log.Info("update keyspace service GC safe point",
+			zap.String("keyspace", request.KeyspaceName),
+			zap.String("service-id", request.ServiceID),
+			zap.Int64("ttl", request.TTL),
+			zap.Uint64("safepoint", request.SafePoint))
+	}
+	if min == nil {
+		gcSafePoint, err := manager.LoadGCSafePoint(id)
+		if err != nil {
+			return nil, grpcutil.StatusError(ctx, err)
+		}
+		return &UpdateKeyspaceServiceGCSafePointResponse{Header: s.header(), MinSafePoint: gcSafePoint}, nil
+	}
+	return &UpdateKeyspaceServiceGCSafePointResponse{
+		Header:       s.header(),
+		ServiceID:    min.ServiceID,
+		MinSafePoint: min.SafePoint,
+	}, nil
+}
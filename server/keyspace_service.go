@@ -16,15 +16,13 @@ package server
 
 import (
 	"context"
-	"path"
 	"time"
 
-	"github.com/gogo/protobuf/proto"
 	"github.com/pingcap/kvproto/pkg/keyspacepb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
-	"github.com/tikv/pd/pkg/storage/endpoint"
+	"github.com/tikv/pd/pkg/rbac"
+	"github.com/tikv/pd/pkg/utils/grpcutil"
 	"github.com/tikv/pd/server/keyspace"
-	"go.etcd.io/etcd/clientv3"
 )
 
 // KeyspaceServer wraps GrpcServer to provide keyspace service.
@@ -48,9 +46,12 @@ func (s *KeyspaceServer) getErrorHeader(err error) *pdpb.ResponseHeader {
 // Request must specify keyspace name.
 // On Error, keyspaceMeta in response will be nil,
 // error information will be encoded in response header with corresponding error type.
-func (s *KeyspaceServer) LoadKeyspace(_ context.Context, request *keyspacepb.LoadKeyspaceRequest) (*keyspacepb.LoadKeyspaceResponse, error) {
+func (s *KeyspaceServer) LoadKeyspace(ctx context.Context, request *keyspacepb.LoadKeyspaceRequest) (*keyspacepb.LoadKeyspaceResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	if err := s.validateRequest(request.GetHeader()); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	}
 	rc := s.GetRaftCluster()
 	if rc == nil {
@@ -71,6 +72,9 @@ func (s *KeyspaceServer) LoadKeyspace(_ context.Context, request *keyspacepb.Loa
 // WatchKeyspaces captures and sends keyspace metadata changes to the client via gRPC stream.
 // Note: It sends all existing keyspaces as it's first package to the client.
 func (s *KeyspaceServer) WatchKeyspaces(request *keyspacepb.WatchKeyspacesRequest, stream keyspacepb.Keyspace_WatchKeyspacesServer) error {
+	if err := s.checkPolicy(stream.Context()); err != nil {
+		return err
+	}
 	if err := s.validateRequest(request.GetHeader()); err != nil {
 		return err
 	}
@@ -79,59 +83,44 @@ func (s *KeyspaceServer) WatchKeyspaces(request *keyspacepb.WatchKeyspacesReques
 		return stream.Send(&keyspacepb.WatchKeyspacesResponse{Header: s.notBootstrappedHeader()})
 	}
 
-	ctx, cancel := context.WithCancel(s.Context())
+	ctx, cancel := context.WithCancel(stream.Context())
 	defer cancel()
-
-	err := s.sendAllKeyspaceMeta(ctx, stream)
-	if err != nil {
-		return err
-	}
-	watchChan := s.client.Watch(ctx, path.Join(s.rootPath, endpoint.KeyspaceMetaPrefix()), clientv3.WithPrefix())
-	for {
+	go func() {
+		// Stop watching when the server is closed.
 		select {
 		case <-ctx.Done():
-			return nil
-		case res := <-watchChan:
-			keyspaces := make([]*keyspacepb.KeyspaceMeta, 0, len(res.Events))
-			for _, event := range res.Events {
-				if event.Type != clientv3.EventTypePut {
-					continue
-				}
-				meta := &keyspacepb.KeyspaceMeta{}
-				if err = proto.Unmarshal(event.Kv.Value, meta); err != nil {
-					return err
-				}
-				keyspaces = append(keyspaces, meta)
-			}
-			if len(keyspaces) > 0 {
-				if err = stream.Send(&keyspacepb.WatchKeyspacesResponse{Header: s.header(), Keyspaces: keyspaces}); err != nil {
-					return err
-				}
-			}
+		case <-s.Context().Done():
+			cancel()
 		}
-	}
-}
+	}()
 
-func (s *KeyspaceServer) sendAllKeyspaceMeta(ctx context.Context, stream keyspacepb.Keyspace_WatchKeyspacesServer) error {
-	getResp, err := s.client.Get(ctx, path.Join(s.rootPath, endpoint.KeyspaceMetaPrefix()), clientv3.WithPrefix())
+	watcher := s.GetKeyspaceWatcher()
+	metas, revision, err := watcher.LoadAll(ctx)
 	if err != nil {
 		return err
 	}
-	metas := make([]*keyspacepb.KeyspaceMeta, getResp.Count)
-	for i, kv := range getResp.Kvs {
-		meta := &keyspacepb.KeyspaceMeta{}
-		if err = proto.Unmarshal(kv.Value, meta); err != nil {
-			return err
-		}
-		metas[i] = meta
+	if err = stream.Send(&keyspacepb.WatchKeyspacesResponse{Header: s.header(), Keyspaces: metas}); err != nil {
+		return err
 	}
-	return stream.Send(&keyspacepb.WatchKeyspacesResponse{Header: s.header(), Keyspaces: metas})
+	return watcher.Watch(ctx, revision+1, func(changes []*keyspace.Change) error {
+		keyspaces := make([]*keyspacepb.KeyspaceMeta, 0, len(changes))
+		for _, change := range changes {
+			keyspaces = append(keyspaces, change.Keyspace)
+		}
+		return stream.Send(&keyspacepb.WatchKeyspacesResponse{Header: s.header(), Keyspaces: keyspaces})
+	})
 }
 
 // UpdateKeyspaceState updates the state of keyspace specified in the request.
-func (s *KeyspaceServer) UpdateKeyspaceState(_ context.Context, request *keyspacepb.UpdateKeyspaceStateRequest) (*keyspacepb.UpdateKeyspaceStateResponse, error) {
+func (s *KeyspaceServer) UpdateKeyspaceState(ctx context.Context, request *keyspacepb.UpdateKeyspaceStateRequest) (*keyspacepb.UpdateKeyspaceStateResponse, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
+	if err := s.AuthorizeGRPC(ctx, rbac.RoleOperator); err != nil {
+		return nil, grpcutil.StatusError(ctx, err)
+	}
 	if err := s.validateRequest(request.GetHeader()); err != nil {
-		return nil, err
+		return nil, grpcutil.StatusError(ctx, err)
 	}
 	rc := s.GetRaftCluster()
 	if rc == nil {
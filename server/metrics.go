@@ -136,6 +136,23 @@ var (
 			Help:      "Indicate the pd server info, and the value is the start timestamp (s).",
 		}, []string{"version", "hash"})
 
+	electionCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "server",
+			Name:      "election_total",
+			Help:      "Counter of the PD leader election events.",
+		}, []string{"type"})
+
+	leaderTenureHistogram = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "pd",
+			Subsystem: "server",
+			Name:      "leader_tenure_seconds",
+			Help:      "Bucketed histogram of how long (s) the member holds the PD leadership.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 20), // 1s ~ 6days
+		})
+
 	serviceAuditHistogram = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Namespace: "pd",
@@ -162,4 +179,6 @@ func init() {
 	prometheus.MustRegister(bucketReportLatency)
 	prometheus.MustRegister(serviceAuditHistogram)
 	prometheus.MustRegister(bucketReportInterval)
+	prometheus.MustRegister(electionCounter)
+	prometheus.MustRegister(leaderTenureHistogram)
 }
@@ -0,0 +1,230 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/tikv/pd/pkg/utils/grpcutil"
+	"github.com/tikv/pd/server/cluster"
+	"google.golang.org/grpc"
+)
+
+// RegionServiceName is the name of the gRPC service scanning the regions in a
+// stream and getting the regions in batches. Like the rule service, kvproto
+// doesn't define the service, so its messages are the Go structs below encoded
+// in JSON.
+const RegionServiceName = "pd.Region"
+
+const (
+	defaultScanRegionsBatchSize = 1024
+	maxScanRegionsBatchSize     = 10240
+	// maxBatchGetSize is the max number of the IDs in a batch get request.
+	maxBatchGetSize = 10240
+)
+
+// ScanRegionsStreamRequest is the request of ScanRegions.
+type ScanRegionsStreamRequest struct {
+	Header   *pdpb.RequestHeader `json:"header"`
+	StartKey []byte              `json:"start_key,omitempty"`
+	// EndKey is exclusive, an empty EndKey means no limit.
+	EndKey []byte `json:"end_key,omitempty"`
+	// Limit is the max number of the regions in the stream, 0 means no limit.
+	Limit int `json:"limit,omitempty"`
+	// BatchSize is the max number of the regions in a message, 0 means the
+	// default one.
+	BatchSize int `json:"batch_size,omitempty"`
+}
+
+// ScanRegionsStreamResponse is a message of the ScanRegions stream.
+type ScanRegionsStreamResponse struct {
+	Header  *pdpb.ResponseHeader `json:"header"`
+	Regions []*pdpb.Region       `json:"regions,omitempty"`
+}
+
+// BatchGetRegionsByIDRequest is the request of BatchGetRegionsByID.
+type BatchGetRegionsByIDRequest struct {
+	Header      *pdpb.RequestHeader `json:"header"`
+	RegionIDs   []uint64            `json:"region_ids"`
+	NeedBuckets bool                `json:"need_buckets,omitempty"`
+}
+
+// BatchGetRegionsByIDResponse is the response of BatchGetRegionsByID.
+type BatchGetRegionsByIDResponse struct {
+	Header *pdpb.ResponseHeader `json:"header"`
+	// Regions are in the order of the IDs in the request, and the ones not
+	// found are nil. Their headers are not set.
+	Regions []*pdpb.GetRegionResponse `json:"regions"`
+}
+
+// RegionServer wraps GrpcServer to provide the region service.
+type RegionServer struct {
+	*GrpcServer
+}
+
+// RegisterRegionServer registers the region service to the gRPC server.
+func RegisterRegionServer(gs *grpc.Server, srv *RegionServer) {
+	gs.RegisterService(&regionServiceDesc, srv)
+}
+
+var regionServiceDesc = grpc.ServiceDesc{
+	ServiceName: RegionServiceName,
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "BatchGetRegionsByID",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				request := &BatchGetRegionsByIDRequest{}
+				if err := dec(request); err != nil {
+					return nil, err
+				}
+				return srv.(*RegionServer).BatchGetRegionsByID(ctx, request)
+			},
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName: "ScanRegions",
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				request := &ScanRegionsStreamRequest{}
+				if err := stream.RecvMsg(request); err != nil {
+					return err
+				}
+				return srv.(*RegionServer).ScanRegions(request, stream)
+			},
+			ServerStreams: true,
+		},
+	},
+}
+
+// checkClusterRequest checks the policy and the header of the request, and
+// returns the cluster, or the error header if it is not bootstrapped.
+func (s *GrpcServer) checkClusterRequest(ctx context.Context, header *pdpb.RequestHeader) (*cluster.RaftCluster, *pdpb.ResponseHeader, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, nil, grpcutil.StatusError(ctx, err)
+	}
+	if err := s.validateRequest(header); err != nil {
+		return nil, nil, grpcutil.StatusError(ctx, err)
+	}
+	rc := s.GetRaftCluster()
+	if rc == nil {
+		return nil, s.notBootstrappedHeader(), nil
+	}
+	return rc, nil, nil
+}
+
+// BatchGetRegionsByID returns the regions of the IDs, which saves the round
+// trips of getting a large number of regions one by one.
+func (s *RegionServer) BatchGetRegionsByID(ctx context.Context, request *BatchGetRegionsByIDRequest) (*BatchGetRegionsByIDResponse, error) {
+	rc, header, err := s.checkClusterRequest(ctx, request.Header)
+	if err != nil {
+		return nil, err
+	}
+	if header != nil {
+		return &BatchGetRegionsByIDResponse{Header: header}, nil
+	}
+	if len(request.RegionIDs) > maxBatchGetSize {
+		return &BatchGetRegionsByIDResponse{Header: s.invalidValue(fmt.Sprintf("too many region IDs, the max is %d", maxBatchGetSize))}, nil
+	}
+	needBuckets := request.NeedBuckets && rc.GetStoreConfig().IsEnableRegionBucket()
+	resp := &BatchGetRegionsByIDResponse{Header: s.header(), Regions: make([]*pdpb.GetRegionResponse, 0, len(request.RegionIDs))}
+	for _, id := range request.RegionIDs {
+		region := rc.GetRegion(id)
+		if region == nil {
+			resp.Regions = append(resp.Regions, nil)
+			continue
+		}
+		var buckets *metapb.Buckets
+		if needBuckets {
+			buckets = region.GetBuckets()
+		}
+		resp.Regions = append(resp.Regions, &pdpb.GetRegionResponse{
+			Region:       region.GetMeta(),
+			Leader:       region.GetLeader(),
+			DownPeers:    region.GetDownPeers(),
+			PendingPeers: region.GetPendingPeers(),
+			Buckets:      buckets,
+		})
+	}
+	return resp, nil
+}
+
+// ScanRegions sends the regions in the range in batches, and ends the stream
+// after the last one. Only a batch is held at a time and the stream is flow
+// controlled, so a slow receiver slows down the scan instead of buffering the
+// regions. The batches are not a consistent snapshot, i.e. the regions may
+// overlap or miss some keys if they are split or merged during the scan.
+func (s *RegionServer) ScanRegions(request *ScanRegionsStreamRequest, stream grpc.ServerStream) error {
+	ctx := stream.Context()
+	rc, header, err := s.checkClusterRequest(ctx, request.Header)
+	if err != nil {
+		return err
+	}
+	if header != nil {
+		return stream.SendMsg(&ScanRegionsStreamResponse{Header: header})
+	}
+	if request.Limit < 0 || request.BatchSize < 0 {
+		return stream.SendMsg(&ScanRegionsStreamResponse{Header: s.invalidValue("limit and batch size must not be negative")})
+	}
+	batchSize := request.BatchSize
+	if batchSize == 0 {
+		batchSize = defaultScanRegionsBatchSize
+	} else if batchSize > maxScanRegionsBatchSize {
+		batchSize = maxScanRegionsBatchSize
+	}
+	key, remaining := request.StartKey, request.Limit
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if s.IsClosed() || s.GetRaftCluster() != rc {
+			return grpcutil.StatusError(ctx, ErrNotLeader)
+		}
+		limit := batchSize
+		if request.Limit > 0 && remaining < limit {
+			limit = remaining
+		}
+		regions := rc.ScanRegions(key, request.EndKey, limit)
+		if len(regions) == 0 {
+			return nil
+		}
+		resp := &ScanRegionsStreamResponse{Header: s.header(), Regions: make([]*pdpb.Region, 0, len(regions))}
+		for _, r := range regions {
+			leader := r.GetLeader()
+			if leader == nil {
+				leader = &metapb.Peer{}
+			}
+			resp.Regions = append(resp.Regions, &pdpb.Region{
+				Region:       r.GetMeta(),
+				Leader:       leader,
+				DownPeers:    r.GetDownPeers(),
+				PendingPeers: r.GetPendingPeers(),
+			})
+		}
+		if err := stream.SendMsg(resp); err != nil {
+			return err
+		}
+		remaining -= len(regions)
+		key = regions[len(regions)-1].GetEndKey()
+		if len(regions) < limit || len(key) == 0 || (request.Limit > 0 && remaining <= 0) ||
+			(len(request.EndKey) > 0 && bytes.Compare(key, request.EndKey) >= 0) {
+			return nil
+		}
+	}
+}
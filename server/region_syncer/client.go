@@ -53,6 +53,25 @@ func (s *RegionSyncer) reset() {
 		s.mu.clientCancel()
 	}
 	s.mu.clientCancel, s.mu.clientCtx = nil, nil
+	s.mu.lastSyncTime = time.Time{}
+}
+
+// GetLastSyncTime returns the last time the region syncer receives a response
+// from the leader, which bounds how stale the synced regions are. It returns
+// zero time if the syncer is not syncing with the leader.
+func (s *RegionSyncer) GetLastSyncTime() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.mu.lastSyncTime
+}
+
+func (s *RegionSyncer) updateLastSyncTime(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Avoid marking a stopped syncer as synced.
+	if ctx.Err() == nil {
+		s.mu.lastSyncTime = time.Now()
+	}
 }
 
 func (s *RegionSyncer) establish(ctx context.Context, addr string) (*grpc.ClientConn, error) {
@@ -234,6 +253,7 @@ func (s *RegionSyncer) StartSyncWithLeader(addr string) {
 						_ = regionStorage.DeleteRegion(old.GetMeta())
 					}
 				}
+				s.updateLastSyncTime(ctx)
 			}
 		}
 	}()
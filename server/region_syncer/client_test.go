@@ -80,6 +80,33 @@ func TestErrorCode(t *testing.T) {
 	re.Equal(codes.Canceled, ev.Code())
 }
 
+func TestLastSyncTime(t *testing.T) {
+	re := require.New(t)
+	tempDir := t.TempDir()
+	rs, err := storage.NewStorageWithLevelDBBackend(context.Background(), tempDir, nil)
+	re.NoError(err)
+	server := &mockServer{
+		ctx:     context.Background(),
+		storage: storage.NewCoreStorage(storage.NewStorageWithMemoryBackend(), rs),
+		bc:      core.NewBasicCluster(),
+	}
+	rc := NewRegionSyncer(server)
+	re.True(rc.GetLastSyncTime().IsZero())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	start := time.Now()
+	rc.updateLastSyncTime(ctx)
+	re.False(rc.GetLastSyncTime().Before(start))
+	// A canceled syncer does not refresh the sync time.
+	cancel()
+	last := rc.GetLastSyncTime()
+	rc.updateLastSyncTime(ctx)
+	re.Equal(last, rc.GetLastSyncTime())
+	// Stopping the syncer forgets the sync time.
+	rc.StopSyncWithLeader()
+	re.True(rc.GetLastSyncTime().IsZero())
+}
+
 type mockServer struct {
 	ctx            context.Context
 	member, leader *pdpb.Member
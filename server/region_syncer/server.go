@@ -78,6 +78,8 @@ type RegionSyncer struct {
 		streams      map[string]ServerStream
 		clientCtx    context.Context
 		clientCancel context.CancelFunc
+		// lastSyncTime is the last time a response is received from the leader.
+		lastSyncTime time.Time
 	}
 	server    Server
 	wg        sync.WaitGroup
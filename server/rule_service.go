@@ -0,0 +1,448 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/event"
+	"github.com/tikv/pd/pkg/rbac"
+	"github.com/tikv/pd/pkg/utils/grpcutil"
+	"github.com/tikv/pd/server/cluster"
+	"github.com/tikv/pd/server/schedule/labeler"
+	"github.com/tikv/pd/server/schedule/placement"
+	"google.golang.org/grpc"
+)
+
+// RuleServiceName is the name of the gRPC service managing the placement rules
+// and the region label rules. kvproto doesn't define the service, so its
+// messages are the Go structs below encoded in JSON, and the clients call it
+// with the grpcutil.JSONCodecName content subtype.
+const RuleServiceName = "pd.Rule"
+
+// watchRulesCheckInterval is the interval to check whether the server is still
+// the leader of the cluster while watching the rules.
+const watchRulesCheckInterval = time.Second
+
+// RuleKey is the key of a placement rule.
+type RuleKey struct {
+	GroupID string `json:"group_id"`
+	ID      string `json:"id"`
+}
+
+// GetPlacementRulesRequest is the request of GetPlacementRules.
+type GetPlacementRulesRequest struct {
+	Header *pdpb.RequestHeader `json:"header"`
+	// GroupID limits the rules to the group if it is not empty.
+	GroupID string `json:"group_id,omitempty"`
+}
+
+// GetPlacementRulesResponse is the response of GetPlacementRules.
+type GetPlacementRulesResponse struct {
+	Header *pdpb.ResponseHeader `json:"header"`
+	Rules  []*placement.Rule    `json:"rules,omitempty"`
+}
+
+// SetPlacementRulesRequest is the request of SetPlacementRules.
+type SetPlacementRulesRequest struct {
+	Header *pdpb.RequestHeader `json:"header"`
+	// Rules are created or updated atomically.
+	Rules []*placement.Rule `json:"rules"`
+}
+
+// SetPlacementRulesResponse is the response of SetPlacementRules.
+type SetPlacementRulesResponse struct {
+	Header *pdpb.ResponseHeader `json:"header"`
+}
+
+// DeletePlacementRuleRequest is the request of DeletePlacementRule.
+type DeletePlacementRuleRequest struct {
+	Header *pdpb.RequestHeader `json:"header"`
+	RuleKey
+}
+
+// DeletePlacementRuleResponse is the response of DeletePlacementRule.
+type DeletePlacementRuleResponse struct {
+	Header *pdpb.ResponseHeader `json:"header"`
+}
+
+// GetLabelRulesRequest is the request of GetLabelRules.
+type GetLabelRulesRequest struct {
+	Header *pdpb.RequestHeader `json:"header"`
+	// IDs limits the rules to the ones with the IDs if it is not empty.
+	IDs []string `json:"ids,omitempty"`
+}
+
+// GetLabelRulesResponse is the response of GetLabelRules.
+type GetLabelRulesResponse struct {
+	Header *pdpb.ResponseHeader `json:"header"`
+	Rules  []*labeler.LabelRule `json:"rules,omitempty"`
+}
+
+// PatchLabelRulesRequest is the request of PatchLabelRules.
+type PatchLabelRulesRequest struct {
+	Header *pdpb.RequestHeader `json:"header"`
+	labeler.LabelRulePatch
+}
+
+// PatchLabelRulesResponse is the response of PatchLabelRules.
+type PatchLabelRulesResponse struct {
+	Header *pdpb.ResponseHeader `json:"header"`
+}
+
+// WatchRulesRequest is the request of WatchRules.
+type WatchRulesRequest struct {
+	Header *pdpb.RequestHeader `json:"header"`
+}
+
+// WatchRulesResponse is a message of the WatchRules stream.
+type WatchRulesResponse struct {
+	Header *pdpb.ResponseHeader `json:"header"`
+	// Snapshot means the message has all the rules, which replace the ones the
+	// watcher knows. Otherwise, the message has the changed and deleted rules.
+	Snapshot              bool                 `json:"snapshot,omitempty"`
+	PlacementRules        []*placement.Rule    `json:"placement_rules,omitempty"`
+	DeletedPlacementRules []RuleKey            `json:"deleted_placement_rules,omitempty"`
+	LabelRules            []*labeler.LabelRule `json:"label_rules,omitempty"`
+	DeletedLabelRules     []string             `json:"deleted_label_rules,omitempty"`
+}
+
+// RuleServer wraps GrpcServer to provide the rule service.
+type RuleServer struct {
+	*GrpcServer
+}
+
+// RegisterRuleServer registers the rule service to the gRPC server.
+func RegisterRuleServer(gs *grpc.Server, srv *RuleServer) {
+	gs.RegisterService(&ruleServiceDesc, srv)
+}
+
+var ruleServiceDesc = grpc.ServiceDesc{
+	ServiceName: RuleServiceName,
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		unaryRuleMethod("GetPlacementRules", func(s *RuleServer, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+			request := &GetPlacementRulesRequest{}
+			if err := dec(request); err != nil {
+				return nil, err
+			}
+			return s.GetPlacementRules(ctx, request)
+		}),
+		unaryRuleMethod("SetPlacementRules", func(s *RuleServer, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+			request := &SetPlacementRulesRequest{}
+			if err := dec(request); err != nil {
+				return nil, err
+			}
+			return s.SetPlacementRules(ctx, request)
+		}),
+		unaryRuleMethod("DeletePlacementRule", func(s *RuleServer, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+			request := &DeletePlacementRuleRequest{}
+			if err := dec(request); err != nil {
+				return nil, err
+			}
+			return s.DeletePlacementRule(ctx, request)
+		}),
+		unaryRuleMethod("GetLabelRules", func(s *RuleServer, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+			request := &GetLabelRulesRequest{}
+			if err := dec(request); err != nil {
+				return nil, err
+			}
+			return s.GetLabelRules(ctx, request)
+		}),
+		unaryRuleMethod("PatchLabelRules", func(s *RuleServer, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+			request := &PatchLabelRulesRequest{}
+			if err := dec(request); err != nil {
+				return nil, err
+			}
+			return s.PatchLabelRules(ctx, request)
+		}),
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName: "WatchRules",
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				request := &WatchRulesRequest{}
+				if err := stream.RecvMsg(request); err != nil {
+					return err
+				}
+				return srv.(*RuleServer).WatchRules(request, stream)
+			},
+			ServerStreams: true,
+		},
+	},
+}
+
+// unaryRuleMethod builds the description of a unary method of the rule
+// service. The call decodes the request and calls the method of the server.
+// The unary interceptors are not supported, since PD doesn't install any.
+func unaryRuleMethod(name string, call func(s *RuleServer, ctx context.Context, dec func(interface{}) error) (interface{}, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+			return call(srv.(*RuleServer), ctx, dec)
+		},
+	}
+}
+
+// checkRequest checks the policy and the header of the request, and returns the
+// cluster, or the error header if the cluster can't serve the rules. The
+// mutations also require the operator role.
+func (s *RuleServer) checkRequest(ctx context.Context, header *pdpb.RequestHeader, placementRules, mutation bool) (*cluster.RaftCluster, *pdpb.ResponseHeader, error) {
+	if err := s.checkPolicy(ctx); err != nil {
+		return nil, nil, grpcutil.StatusError(ctx, err)
+	}
+	if mutation {
+		if err := s.AuthorizeGRPC(ctx, rbac.RoleOperator); err != nil {
+			return nil, nil, grpcutil.StatusError(ctx, err)
+		}
+	}
+	if err := s.validateRequest(header); err != nil {
+		return nil, nil, grpcutil.StatusError(ctx, err)
+	}
+	rc := s.GetRaftCluster()
+	if rc == nil {
+		return nil, s.notBootstrappedHeader(), nil
+	}
+	if placementRules && !rc.GetOpts().IsPlacementRulesEnabled() {
+		return nil, s.wrapErrorToHeader(pdpb.ErrorType_UNKNOWN, "placement rules feature is disabled"), nil
+	}
+	return rc, nil, nil
+}
+
+// getRuleErrorHeader returns the error header of the error from the rule
+// manager or the region labeler.
+func (s *RuleServer) getRuleErrorHeader(err error) *pdpb.ResponseHeader {
+	switch {
+	case errs.ErrRuleContent.Equal(err), errs.ErrHexDecodingString.Equal(err),
+		errs.ErrBuildRuleList.Equal(err), errs.ErrRegionRuleContent.Equal(err):
+		return s.invalidValue(err.Error())
+	case errs.ErrRegionRuleNotFound.Equal(err):
+		return s.wrapErrorToHeader(pdpb.ErrorType_ENTRY_NOT_FOUND, err.Error())
+	default:
+		return s.wrapErrorToHeader(pdpb.ErrorType_UNKNOWN, err.Error())
+	}
+}
+
+// GetPlacementRules returns the placement rules, or the ones of a group.
+func (s *RuleServer) GetPlacementRules(ctx context.Context, request *GetPlacementRulesRequest) (*GetPlacementRulesResponse, error) {
+	rc, header, err := s.checkRequest(ctx, request.Header, true, false)
+	if err != nil {
+		return nil, err
+	}
+	if header != nil {
+		return &GetPlacementRulesResponse{Header: header}, nil
+	}
+	var rules []*placement.Rule
+	if len(request.GroupID) > 0 {
+		rules = rc.GetRuleManager().GetRulesByGroup(request.GroupID)
+	} else {
+		rules = rc.GetRuleManager().GetAllRules()
+	}
+	return &GetPlacementRulesResponse{Header: s.header(), Rules: rules}, nil
+}
+
+// SetPlacementRules creates or updates the placement rules atomically.
+func (s *RuleServer) SetPlacementRules(ctx context.Context, request *SetPlacementRulesRequest) (*SetPlacementRulesResponse, error) {
+	rc, header, err := s.checkRequest(ctx, request.Header, true, true)
+	if err != nil {
+		return nil, err
+	}
+	if header != nil {
+		return &SetPlacementRulesResponse{Header: header}, nil
+	}
+	for _, rule := range request.Rules {
+		if rule == nil {
+			return &SetPlacementRulesResponse{Header: s.invalidValue("nil placement rule")}, nil
+		}
+		// Keep the replication config in sync with the default rule.
+		if rule.GroupID == "pd" && rule.ID == "default" {
+			cfg := s.GetReplicationConfig().Clone()
+			cfg.MaxReplicas = uint64(rule.Count)
+			if err := s.SetReplicationConfig(*cfg); err != nil {
+				return &SetPlacementRulesResponse{Header: s.invalidValue(err.Error())}, nil
+			}
+		}
+	}
+	manager := rc.GetRuleManager()
+	oldRules := make([]*placement.Rule, 0, len(request.Rules))
+	for _, rule := range request.Rules {
+		if old := manager.GetRule(rule.GroupID, rule.ID); old != nil {
+			oldRules = append(oldRules, old)
+		}
+	}
+	if err := manager.SetKeyType(s.GetConfig().PDServerCfg.KeyType).SetRules(request.Rules); err != nil {
+		return &SetPlacementRulesResponse{Header: s.getRuleErrorHeader(err)}, nil
+	}
+	for _, rule := range append(oldRules, request.Rules...) {
+		rc.AddSuspectKeyRange(rule.StartKey, rule.EndKey)
+	}
+	return &SetPlacementRulesResponse{Header: s.header()}, nil
+}
+
+// DeletePlacementRule removes the placement rule.
+func (s *RuleServer) DeletePlacementRule(ctx context.Context, request *DeletePlacementRuleRequest) (*DeletePlacementRuleResponse, error) {
+	rc, header, err := s.checkRequest(ctx, request.Header, true, true)
+	if err != nil {
+		return nil, err
+	}
+	if header != nil {
+		return &DeletePlacementRuleResponse{Header: header}, nil
+	}
+	manager := rc.GetRuleManager()
+	rule := manager.GetRule(request.GroupID, request.ID)
+	if err := manager.DeleteRule(request.GroupID, request.ID); err != nil {
+		return &DeletePlacementRuleResponse{Header: s.getRuleErrorHeader(err)}, nil
+	}
+	if rule != nil {
+		rc.AddSuspectKeyRange(rule.StartKey, rule.EndKey)
+	}
+	return &DeletePlacementRuleResponse{Header: s.header()}, nil
+}
+
+// GetLabelRules returns the region label rules, or the ones with the IDs.
+func (s *RuleServer) GetLabelRules(ctx context.Context, request *GetLabelRulesRequest) (*GetLabelRulesResponse, error) {
+	rc, header, err := s.checkRequest(ctx, request.Header, false, false)
+	if err != nil {
+		return nil, err
+	}
+	if header != nil {
+		return &GetLabelRulesResponse{Header: header}, nil
+	}
+	if len(request.IDs) == 0 {
+		return &GetLabelRulesResponse{Header: s.header(), Rules: rc.GetRegionLabeler().GetAllLabelRules()}, nil
+	}
+	rules, err := rc.GetRegionLabeler().GetLabelRules(request.IDs)
+	if err != nil {
+		return &GetLabelRulesResponse{Header: s.getRuleErrorHeader(err)}, nil
+	}
+	return &GetLabelRulesResponse{Header: s.header(), Rules: rules}, nil
+}
+
+// PatchLabelRules sets and deletes the region label rules in a batch.
+func (s *RuleServer) PatchLabelRules(ctx context.Context, request *PatchLabelRulesRequest) (*PatchLabelRulesResponse, error) {
+	rc, header, err := s.checkRequest(ctx, request.Header, false, true)
+	if err != nil {
+		return nil, err
+	}
+	if header != nil {
+		return &PatchLabelRulesResponse{Header: header}, nil
+	}
+	for _, rule := range request.SetRules {
+		if rule == nil {
+			return &PatchLabelRulesResponse{Header: s.invalidValue("nil label rule")}, nil
+		}
+	}
+	if err := rc.GetRegionLabeler().Patch(request.LabelRulePatch); err != nil {
+		return &PatchLabelRulesResponse{Header: s.getRuleErrorHeader(err)}, nil
+	}
+	return &PatchLabelRulesResponse{Header: s.header()}, nil
+}
+
+// WatchRules sends all the placement rules and the region label rules as the
+// first message, and then the changes of them. It sends all the rules again if
+// it falls too far behind the changes.
+func (s *RuleServer) WatchRules(request *WatchRulesRequest, stream grpc.ServerStream) error {
+	ctx := stream.Context()
+	rc, header, err := s.checkRequest(ctx, request.Header, false, false)
+	if err != nil {
+		return err
+	}
+	if header != nil {
+		return stream.SendMsg(&WatchRulesResponse{Header: header})
+	}
+	var sub *event.Subscription
+	for {
+		if sub == nil {
+			if sub, err = rc.GetEventHub().Subscribe(""); err != nil {
+				return grpcutil.StatusError(ctx, err)
+			}
+			if err = stream.SendMsg(s.snapshotRules(rc)); err != nil {
+				return err
+			}
+		}
+		nextCtx, cancel := context.WithTimeout(ctx, watchRulesCheckInterval)
+		var events []*event.Event
+		events, err = sub.Next(nextCtx)
+		cancel()
+		switch {
+		case err == nil:
+			if resp := s.changedRules(rc, events); resp != nil {
+				if err := stream.SendMsg(resp); err != nil {
+					return err
+				}
+			}
+		case errs.ErrEventCompacted.Equal(err):
+			sub = nil
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case s.IsClosed() || s.GetRaftCluster() != rc:
+			// The events of the cluster end once it stops, so the watcher
+			// should watch the new leader.
+			return grpcutil.StatusError(ctx, ErrNotLeader)
+		}
+	}
+}
+
+func (s *RuleServer) snapshotRules(rc *cluster.RaftCluster) *WatchRulesResponse {
+	resp := &WatchRulesResponse{
+		Header:     s.header(),
+		Snapshot:   true,
+		LabelRules: rc.GetRegionLabeler().GetAllLabelRules(),
+	}
+	if rc.GetOpts().IsPlacementRulesEnabled() {
+		resp.PlacementRules = rc.GetRuleManager().GetAllRules()
+	}
+	return resp
+}
+
+// changedRules returns the current state of the rules changed by the events,
+// or nil if no rule is changed.
+func (s *RuleServer) changedRules(rc *cluster.RaftCluster, events []*event.Event) *WatchRulesResponse {
+	resp := &WatchRulesResponse{Header: s.header()}
+	placementRules, labelRules := make(map[RuleKey]struct{}), make(map[string]struct{})
+	for _, e := range events {
+		switch e.Type {
+		case event.RuleChanged, event.RuleDeleted:
+			key := RuleKey{GroupID: e.Attributes["group"], ID: e.Attributes["id"]}
+			if _, ok := placementRules[key]; ok {
+				continue
+			}
+			placementRules[key] = struct{}{}
+			if rule := rc.GetRuleManager().GetRule(key.GroupID, key.ID); rule != nil {
+				resp.PlacementRules = append(resp.PlacementRules, rule)
+			} else {
+				resp.DeletedPlacementRules = append(resp.DeletedPlacementRules, key)
+			}
+		case event.LabelRuleChanged, event.LabelRuleDeleted:
+			id := e.Attributes["id"]
+			if _, ok := labelRules[id]; ok {
+				continue
+			}
+			labelRules[id] = struct{}{}
+			if rule := rc.GetRegionLabeler().GetLabelRule(id); rule != nil {
+				resp.LabelRules = append(resp.LabelRules, rule)
+			} else {
+				resp.DeletedLabelRules = append(resp.DeletedLabelRules, id)
+			}
+		}
+	}
+	if len(placementRules) == 0 && len(labelRules) == 0 {
+		return nil
+	}
+	return resp
+}
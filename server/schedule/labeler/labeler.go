@@ -23,6 +23,7 @@ import (
 	"github.com/pingcap/log"
 	"github.com/tikv/pd/pkg/core"
 	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/event"
 	"github.com/tikv/pd/pkg/storage/endpoint"
 	"github.com/tikv/pd/pkg/utils/syncutil"
 	"github.com/tikv/pd/server/schedule/rangelist"
@@ -37,6 +38,7 @@ type RegionLabeler struct {
 	rangeList  rangelist.List // sorted LabelRules of the type `KeyRange`
 	ctx        context.Context
 	minExpire  *time.Time
+	eventHub   *event.Hub
 }
 
 // NewRegionLabeler creates a Labeler instance.
@@ -55,6 +57,22 @@ func NewRegionLabeler(ctx context.Context, storage endpoint.RuleStorage, gcInter
 	return l, nil
 }
 
+// SetEventHub sets the hub the label rule change events are published to.
+func (l *RegionLabeler) SetEventHub(hub *event.Hub) {
+	l.Lock()
+	defer l.Unlock()
+	l.eventHub = hub
+}
+
+// publishLocked publishes the change event of the label rule.
+func (l *RegionLabeler) publishLocked(id string, deleted bool) {
+	typ := event.LabelRuleChanged
+	if deleted {
+		typ = event.LabelRuleDeleted
+	}
+	l.eventHub.Publish(&event.Event{Type: typ, Attributes: map[string]string{"id": id}})
+}
+
 func (l *RegionLabeler) doGC(gcInterval time.Duration) {
 	ticker := time.NewTicker(gcInterval)
 	defer ticker.Stop()
@@ -95,6 +113,7 @@ func (l *RegionLabeler) checkAndClearExpiredLabels() {
 		if err != nil {
 			log.Error("failed to save rule expired label rule", zap.String("rule-key", key), zap.Error(err))
 		}
+		l.publishLocked(key, len(rule.Labels) == 0)
 	}
 	if deleted {
 		l.buildRangeList()
@@ -195,9 +214,11 @@ func (l *RegionLabeler) getAndCheckRule(id string, now time.Time) *LabelRule {
 	if len(rule.Labels) == 0 {
 		l.storage.DeleteRegionRule(id)
 		delete(l.labelRules, id)
+		l.publishLocked(id, true)
 		return nil
 	}
 	l.storage.SaveRegionRule(id, rule)
+	l.publishLocked(id, false)
 	return rule
 }
 
@@ -213,6 +234,7 @@ func (l *RegionLabeler) SetLabelRule(rule *LabelRule) error {
 	}
 	l.labelRules[rule.ID] = rule
 	l.buildRangeList()
+	l.publishLocked(rule.ID, false)
 	return nil
 }
 
@@ -228,6 +250,7 @@ func (l *RegionLabeler) DeleteLabelRule(id string) error {
 	}
 	delete(l.labelRules, id)
 	l.buildRangeList()
+	l.publishLocked(id, true)
 	return nil
 }
 
@@ -262,6 +285,12 @@ func (l *RegionLabeler) Patch(patch LabelRulePatch) error {
 		l.labelRules[rule.ID] = rule
 	}
 	l.buildRangeList()
+	for _, key := range patch.DeleteRules {
+		l.publishLocked(key, true)
+	}
+	for _, rule := range patch.SetRules {
+		l.publishLocked(rule.ID, false)
+	}
 	return nil
 }
 
@@ -294,10 +323,16 @@ func (l *RegionLabeler) GetRegionLabel(region *core.RegionInfo, key string) stri
 
 // ScheduleDisabled returns true if the region is lablelld with schedule-disabled.
 func (l *RegionLabeler) ScheduleDisabled(region *core.RegionInfo) bool {
-	v := l.GetRegionLabel(region, scheduleOptionLabel)
+	v := l.GetRegionLabel(region, ScheduleOptionLabel)
 	return strings.EqualFold(v, scheduleOptioonValueDeny)
 }
 
+// ScheduleDeprioritized returns true if the region is labelled with low schedule priority.
+func (l *RegionLabeler) ScheduleDeprioritized(region *core.RegionInfo) bool {
+	v := l.GetRegionLabel(region, ScheduleOptionLabel)
+	return strings.EqualFold(v, ScheduleOptionValueLow)
+}
+
 // GetRegionLabels returns the labels of the region.
 // For each key, the label with max rule index will be returned.
 func (l *RegionLabeler) GetRegionLabels(region *core.RegionInfo) []*RegionLabel {
@@ -26,6 +26,7 @@ import (
 	"github.com/pingcap/failpoint"
 	"github.com/stretchr/testify/require"
 	"github.com/tikv/pd/pkg/core"
+	"github.com/tikv/pd/pkg/event"
 	"github.com/tikv/pd/pkg/storage/endpoint"
 	"github.com/tikv/pd/pkg/storage/kv"
 )
@@ -134,6 +135,40 @@ func TestGetSetRule(t *testing.T) {
 	}
 }
 
+func TestPublishEvents(t *testing.T) {
+	re := require.New(t)
+	store := endpoint.NewStorageEndpoint(kv.NewMemoryKV(), nil)
+	labeler, err := NewRegionLabeler(context.Background(), store, time.Hour)
+	re.NoError(err)
+	hub := event.NewHub(16)
+	labeler.SetEventHub(hub)
+	sub, err := hub.Subscribe("")
+	re.NoError(err)
+
+	re.NoError(labeler.SetLabelRule(&LabelRule{ID: "rule1", Labels: []RegionLabel{{Key: "k1", Value: "v1"}}, RuleType: "key-range", Data: makeKeyRanges("1234", "5678")}))
+	re.NoError(labeler.Patch(LabelRulePatch{
+		SetRules:    []*LabelRule{{ID: "rule2", Labels: []RegionLabel{{Key: "k2", Value: "v2"}}, RuleType: "key-range", Data: makeKeyRanges("ab12", "cd12")}},
+		DeleteRules: []string{"rule1"},
+	}))
+	re.NoError(labeler.DeleteLabelRule("rule2"))
+	events, err := sub.Next(context.Background())
+	re.NoError(err)
+	re.Len(events, 4)
+	expected := []struct {
+		typ event.Type
+		id  string
+	}{
+		{event.LabelRuleChanged, "rule1"},
+		{event.LabelRuleDeleted, "rule1"},
+		{event.LabelRuleChanged, "rule2"},
+		{event.LabelRuleDeleted, "rule2"},
+	}
+	for i, e := range events {
+		re.Equal(expected[i].typ, e.Type)
+		re.Equal(expected[i].id, e.Attributes["id"])
+	}
+}
+
 func TestIndex(t *testing.T) {
 	re := require.New(t)
 	store := endpoint.NewStorageEndpoint(kv.NewMemoryKV(), nil)
@@ -262,6 +297,36 @@ func TestKeyRange(t *testing.T) {
 	}
 }
 
+func TestScheduleOption(t *testing.T) {
+	re := require.New(t)
+	store := endpoint.NewStorageEndpoint(kv.NewMemoryKV(), nil)
+	labeler, err := NewRegionLabeler(context.Background(), store, time.Millisecond*10)
+	re.NoError(err)
+	rules := []*LabelRule{
+		{ID: "deny", Labels: []RegionLabel{{Key: ScheduleOptionLabel, Value: "deny"}}, RuleType: "key-range", Data: makeKeyRanges("1234", "5678")},
+		{ID: "low", Labels: []RegionLabel{{Key: ScheduleOptionLabel, Value: ScheduleOptionValueLow}}, RuleType: "key-range", Data: makeKeyRanges("ab12", "cd12")},
+	}
+	for _, r := range rules {
+		re.NoError(labeler.SetLabelRule(r))
+	}
+	testCases := []struct {
+		start, end    string
+		disabled      bool
+		deprioritized bool
+	}{
+		{"1234", "5678", true, false},
+		{"ab12", "cd12", false, true},
+		{"ffee", "ffff", false, false},
+	}
+	for _, testCase := range testCases {
+		start, _ := hex.DecodeString(testCase.start)
+		end, _ := hex.DecodeString(testCase.end)
+		region := core.NewTestRegionInfo(1, 1, start, end)
+		re.Equal(testCase.disabled, labeler.ScheduleDisabled(region))
+		re.Equal(testCase.deprioritized, labeler.ScheduleDeprioritized(region))
+	}
+}
+
 func TestLabelerRuleTTL(t *testing.T) {
 	re := require.New(t)
 	store := endpoint.NewStorageEndpoint(kv.NewMemoryKV(), nil)
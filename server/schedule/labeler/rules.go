@@ -54,8 +54,12 @@ const (
 )
 
 const (
-	scheduleOptionLabel      = "schedule"
+	// ScheduleOptionLabel is the label key to control the scheduling of the labeled regions.
+	ScheduleOptionLabel      = "schedule"
 	scheduleOptioonValueDeny = "deny"
+	// ScheduleOptionValueLow deprioritizes the scheduling of the labeled regions,
+	// only the operators to fix the replicas, merge or split them are allowed.
+	ScheduleOptionValueLow = "low"
 )
 
 // KeyRangeRule contains the start key and end key of the LabelRule.
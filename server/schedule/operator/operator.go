@@ -420,6 +420,84 @@ func (o *Operator) Record(finishTime time.Time) *OpRecord {
 	return record
 }
 
+// OpStepTrace is the trace of an operator step.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type OpStepTrace struct {
+	Step string `json:"step"`
+	// Status is one of "finished", "running", "stopped" and "pending". A step
+	// is stopped if the operator is ended before the step finishes.
+	Status     string     `json:"status"`
+	StartTime  *time.Time `json:"start_time,omitempty"`
+	FinishTime *time.Time `json:"finish_time,omitempty"`
+}
+
+// OpTrace is the trace of an operator, which shows how it goes through its lifecycle.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type OpTrace struct {
+	RegionID        uint64            `json:"region_id"`
+	Desc            string            `json:"desc"`
+	Brief           string            `json:"brief"`
+	Kind            string            `json:"kind"`
+	Status          string            `json:"status"`
+	Reason          string            `json:"reason,omitempty"`
+	AdditionalInfos map[string]string `json:"additional_infos,omitempty"`
+	CreateTime      time.Time         `json:"create_time"`
+	StartTime       *time.Time        `json:"start_time,omitempty"`
+	FinishTime      *time.Time        `json:"finish_time,omitempty"`
+	Steps           []OpStepTrace     `json:"steps"`
+}
+
+// Trace transfers the operator to OpTrace. The reason explains why the
+// operator is ended, if it isn't finished successfully.
+func (o *Operator) Trace(reason string) *OpTrace {
+	trace := &OpTrace{
+		RegionID:        o.regionID,
+		Desc:            o.desc,
+		Brief:           o.brief,
+		Kind:            o.kind.String(),
+		Status:          OpStatusToString(o.Status()),
+		Reason:          reason,
+		AdditionalInfos: make(map[string]string, len(o.AdditionalInfos)),
+		CreateTime:      o.GetCreateTime(),
+		Steps:           make([]OpStepTrace, len(o.steps)),
+	}
+	for k, v := range o.AdditionalInfos {
+		trace.AdditionalInfos[k] = v
+	}
+	if o.IsEnd() {
+		finishTime := o.status.ReachTime()
+		trace.FinishTime = &finishTime
+	}
+	var start time.Time
+	if o.HasStarted() {
+		start = o.GetStartTime()
+		trace.StartTime = &start
+	}
+	for i, step := range o.steps {
+		trace.Steps[i] = OpStepTrace{Step: step.String(), Status: "pending"}
+		if start.IsZero() {
+			continue
+		}
+		stepStart := start
+		trace.Steps[i].StartTime = &stepStart
+		if finish := atomic.LoadInt64(&o.stepsTime[i]); finish != 0 {
+			stepFinish := time.Unix(0, finish)
+			trace.Steps[i].Status = "finished"
+			trace.Steps[i].FinishTime = &stepFinish
+			start = stepFinish
+			continue
+		}
+		if trace.FinishTime != nil {
+			trace.Steps[i].Status = "stopped"
+			trace.Steps[i].FinishTime = trace.FinishTime
+		} else {
+			trace.Steps[i].Status = "running"
+		}
+		start = time.Time{}
+	}
+	return trace
+}
+
 // GetAdditionalInfo returns additional info with string
 func (o *Operator) GetAdditionalInfo() string {
 	if len(o.AdditionalInfos) != 0 {
@@ -487,3 +487,46 @@ func (suite *operatorTestSuite) TestRecord() {
 	suite.Equal(now, ob.FinishTime)
 	suite.Greater(ob.duration.Seconds(), time.Second.Seconds())
 }
+
+func (suite *operatorTestSuite) TestTrace() {
+	steps := []OpStep{
+		AddPeer{ToStore: 1, PeerID: 1},
+		TransferLeader{FromStore: 2, ToStore: 1},
+		RemovePeer{FromStore: 2},
+	}
+	op := suite.newTestOperator(1, OpLeader|OpRegion, steps...)
+	op.AdditionalInfos["reason"] = "test"
+	trace := op.Trace("")
+	suite.Equal("Created", trace.Status)
+	suite.Nil(trace.StartTime)
+	suite.Equal("test", trace.AdditionalInfos["reason"])
+	suite.Len(trace.Steps, 3)
+	for _, step := range trace.Steps {
+		suite.Equal("pending", step.Status)
+		suite.Nil(step.StartTime)
+	}
+
+	// The first step is finished.
+	suite.True(op.Start())
+	region := suite.newTestRegion(1, 2, [2]uint64{1, 1}, [2]uint64{2, 2})
+	suite.Equal(steps[1], op.Check(region))
+	trace = op.Trace("")
+	suite.NotNil(trace.StartTime)
+	suite.Nil(trace.FinishTime)
+	suite.Equal("finished", trace.Steps[0].Status)
+	suite.Equal(*trace.StartTime, *trace.Steps[0].StartTime)
+	suite.Equal("running", trace.Steps[1].Status)
+	suite.Equal(*trace.Steps[0].FinishTime, *trace.Steps[1].StartTime)
+	suite.Nil(trace.Steps[1].FinishTime)
+	suite.Equal("pending", trace.Steps[2].Status)
+
+	// The running step is stopped once the operator is canceled.
+	suite.True(op.Cancel())
+	trace = op.Trace("stale")
+	suite.Equal("Canceled", trace.Status)
+	suite.Equal("stale", trace.Reason)
+	suite.NotNil(trace.FinishTime)
+	suite.Equal("stopped", trace.Steps[1].Status)
+	suite.Equal(*trace.FinishTime, *trace.Steps[1].FinishTime)
+	suite.Equal("pending", trace.Steps[2].Status)
+}
@@ -0,0 +1,129 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/tikv/pd/pkg/core"
+	"github.com/tikv/pd/pkg/errs"
+)
+
+// stepTypes is used to decode the steps of a snapshot by the type name.
+var stepTypes = make(map[string]reflect.Type)
+
+func init() {
+	for _, step := range []OpStep{
+		TransferLeader{},
+		AddPeer{},
+		AddLearner{},
+		PromoteLearner{},
+		RemovePeer{},
+		MergeRegion{},
+		SplitRegion{},
+		ChangePeerV2Enter{},
+		ChangePeerV2Leave{},
+		BecomeWitness{},
+		BecomeNonWitness{},
+		BatchSwitchWitness{},
+	} {
+		typ := reflect.TypeOf(step)
+		stepTypes[typ.Name()] = typ
+	}
+}
+
+// StepSnapshot is the serializable form of an OpStep.
+type StepSnapshot struct {
+	Type string          `json:"type"`
+	Step json.RawMessage `json:"step"`
+}
+
+// Snapshot is the serializable state of an operator, which is used to hand
+// the in-flight operators over to the new PD leader.
+type Snapshot struct {
+	Desc            string              `json:"desc"`
+	Brief           string              `json:"brief"`
+	RegionID        uint64              `json:"region_id"`
+	RegionEpoch     *metapb.RegionEpoch `json:"region_epoch"`
+	Kind            OpKind              `json:"kind"`
+	Level           core.PriorityLevel  `json:"level"`
+	ApproximateSize int64               `json:"approximate_size"`
+	Steps           []*StepSnapshot     `json:"steps"`
+	CurrentStep     int32               `json:"current_step"`
+	AdditionalInfos map[string]string   `json:"additional_infos,omitempty"`
+}
+
+// Snapshot returns the serializable state of the operator.
+func (o *Operator) Snapshot() (*Snapshot, error) {
+	s := &Snapshot{
+		Desc:            o.desc,
+		Brief:           o.brief,
+		RegionID:        o.regionID,
+		RegionEpoch:     o.regionEpoch,
+		Kind:            o.kind,
+		Level:           o.level,
+		ApproximateSize: o.ApproximateSize,
+		CurrentStep:     atomic.LoadInt32(&o.currentStep),
+		AdditionalInfos: o.AdditionalInfos,
+	}
+	for _, step := range o.steps {
+		typ := reflect.TypeOf(step)
+		if _, ok := stepTypes[typ.Name()]; !ok {
+			return nil, errors.Errorf("unsupported operator step %s", typ.Name())
+		}
+		data, err := json.Marshal(step)
+		if err != nil {
+			return nil, errs.ErrJSONMarshal.Wrap(err).GenWithStackByCause()
+		}
+		s.Steps = append(s.Steps, &StepSnapshot{Type: typ.Name(), Step: data})
+	}
+	return s, nil
+}
+
+// Restore creates the operator from the snapshot. The restored operator is
+// not started, and the steps before the current one are treated as finished.
+func (s *Snapshot) Restore() (*Operator, error) {
+	steps := make([]OpStep, 0, len(s.Steps))
+	for _, stepSnapshot := range s.Steps {
+		typ, ok := stepTypes[stepSnapshot.Type]
+		if !ok {
+			return nil, errors.Errorf("unsupported operator step %s", stepSnapshot.Type)
+		}
+		step := reflect.New(typ)
+		if err := json.Unmarshal(stepSnapshot.Step, step.Interface()); err != nil {
+			return nil, errs.ErrJSONUnmarshal.Wrap(err).GenWithStackByCause()
+		}
+		steps = append(steps, step.Elem().Interface().(OpStep))
+	}
+	if s.CurrentStep < 0 || int(s.CurrentStep) > len(steps) {
+		return nil, errors.Errorf("invalid current step %d of %d steps", s.CurrentStep, len(steps))
+	}
+	op := NewOperator(s.Desc, s.Brief, s.RegionID, s.RegionEpoch, s.Kind, s.ApproximateSize, steps...)
+	op.SetPriorityLevel(s.Level)
+	for k, v := range s.AdditionalInfos {
+		op.AdditionalInfos[k] = v
+	}
+	now := time.Now().UnixNano()
+	for i := 0; i < int(s.CurrentStep); i++ {
+		op.stepsTime[i] = now
+	}
+	op.currentStep = s.CurrentStep
+	return op, nil
+}
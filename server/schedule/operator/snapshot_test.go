@@ -0,0 +1,86 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/core"
+)
+
+func TestSnapshot(t *testing.T) {
+	re := require.New(t)
+	steps := []OpStep{
+		AddLearner{ToStore: 4, PeerID: 4, IsLightWeight: true},
+		ChangePeerV2Enter{
+			PromoteLearners: []PromoteLearner{{ToStore: 4, PeerID: 4}},
+			DemoteVoters:    []DemoteVoter{{ToStore: 1, PeerID: 1}},
+		},
+		ChangePeerV2Leave{
+			PromoteLearners: []PromoteLearner{{ToStore: 4, PeerID: 4}},
+			DemoteVoters:    []DemoteVoter{{ToStore: 1, PeerID: 1}},
+		},
+		TransferLeader{FromStore: 1, ToStore: 2, ToStores: []uint64{2, 3}},
+		RemovePeer{FromStore: 1, PeerID: 1},
+		SplitRegion{StartKey: []byte("a"), EndKey: []byte("z"), Policy: pdpb.CheckPolicy_USEKEY, SplitKeys: [][]byte{[]byte("m")}},
+		MergeRegion{FromRegion: &metapb.Region{Id: 1}, ToRegion: &metapb.Region{Id: 2}, IsPassive: true},
+		BatchSwitchWitness{ToWitnesses: []BecomeWitness{{PeerID: 2, StoreID: 2}}},
+	}
+	epoch := &metapb.RegionEpoch{ConfVer: 3, Version: 5}
+	op := NewOperator("test", "test", 1, epoch, OpRegion|OpLeader, 10, steps...)
+	op.SetPriorityLevel(core.High)
+	op.AdditionalInfos["key"] = "value"
+	atomic.StoreInt32(&op.currentStep, 2)
+
+	snapshot, err := op.Snapshot()
+	re.NoError(err)
+	data, err := json.Marshal(snapshot)
+	re.NoError(err)
+	snapshot = &Snapshot{}
+	re.NoError(json.Unmarshal(data, snapshot))
+
+	restored, err := snapshot.Restore()
+	re.NoError(err)
+	re.Equal(CREATED, restored.Status())
+	re.Equal(op.Desc(), restored.Desc())
+	re.Equal(op.brief, restored.brief)
+	re.Equal(op.RegionID(), restored.RegionID())
+	re.Equal(epoch.String(), restored.RegionEpoch().String())
+	re.Equal(op.Kind(), restored.Kind())
+	re.Equal(core.High, restored.GetPriorityLevel())
+	re.Equal(op.ApproximateSize, restored.ApproximateSize)
+	re.Equal("value", restored.AdditionalInfos["key"])
+	re.Equal(op.timeout, restored.timeout)
+	re.Equal(int32(2), atomic.LoadInt32(&restored.currentStep))
+	re.NotZero(restored.stepsTime[1])
+	re.Zero(restored.stepsTime[2])
+	re.Equal(len(steps), restored.Len())
+	for i := range steps {
+		re.Equal(steps[i].String(), restored.Step(i).String())
+	}
+
+	snapshot.Steps[0].Type = "Unknown"
+	_, err = snapshot.Restore()
+	re.Error(err)
+	snapshot.Steps[0].Type = "AddLearner"
+	snapshot.CurrentStep = int32(len(steps) + 1)
+	_, err = snapshot.Restore()
+	re.Error(err)
+}
@@ -28,12 +28,14 @@ import (
 	"github.com/tikv/pd/pkg/core"
 	"github.com/tikv/pd/pkg/core/storelimit"
 	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/event"
 	"github.com/tikv/pd/pkg/utils/syncutil"
 	"github.com/tikv/pd/pkg/versioninfo"
 	"github.com/tikv/pd/server/schedule/hbstream"
 	"github.com/tikv/pd/server/schedule/labeler"
 	"github.com/tikv/pd/server/schedule/operator"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 // The source of dispatched region.
@@ -64,9 +66,11 @@ type OperatorController struct {
 	fastOperators   *cache.TTLUint64
 	counts          map[operator.OpKind]uint64
 	opRecords       *OperatorRecords
+	opTraces        *OperatorTraces
 	wop             WaitingOperator
 	wopStatus       *WaitingOperatorStatus
 	opNotifierQueue operatorQueue
+	eventHub        *event.Hub
 }
 
 // NewOperatorController creates a OperatorController.
@@ -79,12 +83,19 @@ func NewOperatorController(ctx context.Context, cluster Cluster, hbStreams *hbst
 		fastOperators:   cache.NewIDTTL(ctx, time.Minute, FastOperatorFinishTime),
 		counts:          make(map[operator.OpKind]uint64),
 		opRecords:       NewOperatorRecords(ctx),
+		opTraces:        NewOperatorTraces(ctx),
 		wop:             NewRandBuckets(),
 		wopStatus:       NewWaitingOperatorStatus(),
 		opNotifierQueue: make(operatorQueue, 0),
 	}
 }
 
+// SetEventHub sets the hub the operator lifecycle events are published to. It
+// should be called before the controller is used.
+func (oc *OperatorController) SetEventHub(hub *event.Hub) {
+	oc.eventHub = hub
+}
+
 // Ctx returns a context which will be canceled once RaftCluster is stopped.
 // For now, it is only used to control the lifetime of TTL cache in schedulers.
 func (oc *OperatorController) Ctx() context.Context {
@@ -222,7 +233,7 @@ func (oc *OperatorController) pollNeedDispatchRegion() (r *core.RegionInfo, next
 				zap.Stringer("operator", op))
 			operatorCounter.WithLabelValues(op.Desc(), "disappear").Inc()
 		}
-		oc.buryOperator(op)
+		oc.buryOperator(op, zap.String("reason", "region disappeared"))
 		return nil, true
 	}
 	step := op.Check(r)
@@ -283,13 +294,13 @@ func (oc *OperatorController) AddWaitingOperator(ops ...*operator.Operator) int
 		}
 		if !oc.checkAddOperator(false, op) {
 			_ = op.Cancel()
-			oc.buryOperator(op)
+			oc.buryOperator(op, zap.String("reason", "rejected by the admission check"))
 			if isMerge {
 				// Merge operation have two operators, cancel them all
 				i++
 				next := ops[i]
 				_ = next.Cancel()
-				oc.buryOperator(next)
+				oc.buryOperator(next, zap.String("reason", "rejected by the admission check"))
 			}
 			continue
 		}
@@ -326,7 +337,7 @@ func (oc *OperatorController) AddOperator(ops ...*operator.Operator) bool {
 	if oc.exceedStoreLimitLocked(ops...) || !oc.checkAddOperator(false, ops...) {
 		for _, op := range ops {
 			_ = op.Cancel()
-			oc.buryOperator(op)
+			oc.buryOperator(op, zap.String("reason", "exceeded the store limit or rejected by the admission check"))
 		}
 		return false
 	}
@@ -338,6 +349,75 @@ func (oc *OperatorController) AddOperator(ops ...*operator.Operator) bool {
 	return true
 }
 
+// RestoreOperator adds the in-flight operators handed over by the previous
+// PD leader. The operators are checked as the promoted waiting operators,
+// except that the region epoch may have been changed by the finished steps,
+// so an operator is restored only if the changes of the confver are made by
+// itself. The merge operators are restored only in pairs. It returns the
+// number of restored operators.
+func (oc *OperatorController) RestoreOperator(ops ...*operator.Operator) int {
+	oc.Lock()
+	defer oc.Unlock()
+	restored := 0
+	for _, group := range pairRestoringOperators(ops) {
+		if oc.exceedStoreLimitLocked(group...) || !oc.checkOperatorLocked(true, true, group...) {
+			log.Info("skip restoring operator", zap.Uint64("region-id", group[0].RegionID()),
+				zap.String("reason", "exceeded the store limit or rejected by the admission check"))
+			continue
+		}
+		for _, op := range group {
+			if !oc.addOperatorLocked(op) {
+				break
+			}
+			operatorCounter.WithLabelValues(op.Desc(), "restore").Inc()
+			restored++
+		}
+	}
+	return restored
+}
+
+// pairRestoringOperators groups the operators to be restored together. The
+// merge operators of the source and the target regions are paired, and the
+// orphan ones are dropped.
+func pairRestoringOperators(ops []*operator.Operator) [][]*operator.Operator {
+	groups := make([][]*operator.Operator, 0, len(ops))
+	merges := make(map[[2]uint64]*operator.Operator)
+	for _, op := range ops {
+		if op.Kind()&operator.OpMerge == 0 {
+			groups = append(groups, []*operator.Operator{op})
+			continue
+		}
+		var (
+			step operator.MergeRegion
+			ok   bool
+		)
+		if op.Len() > 0 {
+			step, ok = op.Step(op.Len() - 1).(operator.MergeRegion)
+		}
+		if !ok {
+			log.Warn("merge operator without merge step, skip restoring operator", zap.Uint64("region-id", op.RegionID()))
+			continue
+		}
+		key := [2]uint64{step.FromRegion.GetId(), step.ToRegion.GetId()}
+		pair, ok := merges[key]
+		if !ok {
+			merges[key] = op
+			continue
+		}
+		delete(merges, key)
+		// The operator of the source region goes first as it is created.
+		if step.IsPassive {
+			groups = append(groups, []*operator.Operator{pair, op})
+		} else {
+			groups = append(groups, []*operator.Operator{op, pair})
+		}
+	}
+	for _, op := range merges {
+		log.Info("orphan merge operator, skip restoring operator", zap.Uint64("region-id", op.RegionID()))
+	}
+	return groups
+}
+
 // PromoteWaitingOperator promotes operators from waiting operators.
 func (oc *OperatorController) PromoteWaitingOperator() {
 	oc.Lock()
@@ -355,7 +435,7 @@ func (oc *OperatorController) PromoteWaitingOperator() {
 			for _, op := range ops {
 				operatorWaitCounter.WithLabelValues(op.Desc(), "promote-canceled").Inc()
 				_ = op.Cancel()
-				oc.buryOperator(op)
+				oc.buryOperator(op, zap.String("reason", "exceeded the store limit or rejected by the admission check when promoted"))
 			}
 			oc.wopStatus.ops[ops[0].Desc()]--
 			continue
@@ -379,6 +459,12 @@ func (oc *OperatorController) PromoteWaitingOperator() {
 // - Exceed the max number of waiting operators
 // - At least one operator is expired.
 func (oc *OperatorController) checkAddOperator(isPromoting bool, ops ...*operator.Operator) bool {
+	return oc.checkOperatorLocked(isPromoting, false, ops...)
+}
+
+// checkOperatorLocked is checkAddOperator, and the restored operators are
+// allowed to have changed the confver by their finished steps.
+func (oc *OperatorController) checkOperatorLocked(isPromoting, isRestoring bool, ops ...*operator.Operator) bool {
 	for _, op := range ops {
 		region := oc.cluster.GetRegion(op.RegionID())
 		if region == nil {
@@ -387,8 +473,7 @@ func (oc *OperatorController) checkAddOperator(isPromoting bool, ops ...*operato
 			operatorWaitCounter.WithLabelValues(op.Desc(), "not-found").Inc()
 			return false
 		}
-		if region.GetRegionEpoch().GetVersion() != op.RegionEpoch().GetVersion() ||
-			region.GetRegionEpoch().GetConfVer() != op.RegionEpoch().GetConfVer() {
+		if !isEpochMatched(op, region, isRestoring) {
 			log.Debug("region epoch not match, cancel add operator",
 				zap.Uint64("region-id", op.RegionID()),
 				zap.Reflect("old", region.GetRegionEpoch()),
@@ -430,6 +515,11 @@ func (oc *OperatorController) checkAddOperator(isPromoting bool, ops ...*operato
 				operatorWaitCounter.WithLabelValues(op.Desc(), "schedule-disabled").Inc()
 				return false
 			}
+			if l.ScheduleDeprioritized(region) && op.Kind()&(operator.OpReplica|operator.OpMerge|operator.OpSplit) == 0 {
+				log.Debug("schedule deprioritized", zap.Uint64("region-id", op.RegionID()))
+				operatorWaitCounter.WithLabelValues(op.Desc(), "schedule-deprioritized").Inc()
+				return false
+			}
 		}
 	}
 	expired := false
@@ -442,6 +532,21 @@ func (oc *OperatorController) checkAddOperator(isPromoting bool, ops ...*operato
 	return !expired
 }
 
+// isEpochMatched checks whether the region epoch is the one the operator is
+// created on. The restored operator may have changed the confver by the
+// finished steps.
+func isEpochMatched(op *operator.Operator, region *core.RegionInfo, isRestoring bool) bool {
+	origin, latest := op.RegionEpoch(), region.GetRegionEpoch()
+	if latest.GetVersion() != origin.GetVersion() {
+		return false
+	}
+	if !isRestoring {
+		return latest.GetConfVer() == origin.GetConfVer()
+	}
+	return latest.GetConfVer() >= origin.GetConfVer() &&
+		latest.GetConfVer()-origin.GetConfVer() <= op.ConfVerChanged(region)
+}
+
 func isHigherPriorityOperator(new, old *operator.Operator) bool {
 	return new.GetPriorityLevel() > old.GetPriorityLevel()
 }
@@ -459,7 +564,7 @@ func (oc *OperatorController) addOperatorLocked(op *operator.Operator) bool {
 	if old, ok := oc.operators[regionID]; ok {
 		_ = oc.removeOperatorLocked(old)
 		_ = old.Replace()
-		oc.buryOperator(old)
+		oc.buryOperator(old, zap.String("reason", "replaced by "+op.Desc()))
 	}
 
 	if !op.Start() {
@@ -505,6 +610,7 @@ func (oc *OperatorController) addOperatorLocked(op *operator.Operator) bool {
 
 	heap.Push(&oc.opNotifierQueue, &operatorWithTime{op: op, time: oc.getNextPushOperatorTime(step, time.Now())})
 	operatorCounter.WithLabelValues(op.Desc(), "create").Inc()
+	oc.publishOperatorEvent(event.OperatorStarted, op)
 	for _, counter := range op.Counters {
 		counter.Inc()
 	}
@@ -607,6 +713,20 @@ func (oc *OperatorController) buryOperator(op *operator.Operator, extraFields ..
 	}
 
 	oc.opRecords.Put(op)
+	oc.opTraces.Put(op.Trace(reasonOf(extraFields)))
+	oc.publishOperatorEvent(event.OperatorFinished, op)
+}
+
+func (oc *OperatorController) publishOperatorEvent(typ event.Type, op *operator.Operator) {
+	oc.eventHub.Publish(&event.Event{
+		Type:     typ,
+		RegionID: op.RegionID(),
+		Attributes: map[string]string{
+			"desc":   op.Desc(),
+			"kind":   op.Kind().String(),
+			"status": operator.OpStatusToString(op.Status()),
+		},
+	})
 }
 
 // GetOperatorStatus gets the operator and its status with the specify id.
@@ -619,6 +739,26 @@ func (oc *OperatorController) GetOperatorStatus(id uint64) *OperatorWithStatus {
 	return oc.opRecords.Get(id)
 }
 
+// reasonOf returns the reason in the log fields of the removed operator.
+func reasonOf(fields []zap.Field) string {
+	for _, field := range fields {
+		if field.Key == "reason" && field.Type == zapcore.StringType {
+			return field.String
+		}
+	}
+	return ""
+}
+
+// GetOperatorTraces gets the traces of the recent operators of the region,
+// from the oldest to the latest, including the running one.
+func (oc *OperatorController) GetOperatorTraces(regionID uint64) []*operator.OpTrace {
+	traces := oc.opTraces.Get(regionID)
+	if op := oc.GetOperator(regionID); op != nil {
+		traces = append(traces, op.Trace(""))
+	}
+	return traces
+}
+
 // GetOperator gets a operator from the given region.
 func (oc *OperatorController) GetOperator(regionID uint64) *operator.Operator {
 	oc.RLock()
@@ -822,6 +962,49 @@ func (o *OperatorRecords) Put(op *operator.Operator) {
 	o.ttl.Put(id, record)
 }
 
+// OperatorTraces remains the traces of the recent operators of each region for a while.
+type OperatorTraces struct {
+	syncutil.Mutex
+	ttl *cache.TTLUint64
+}
+
+const (
+	operatorTraceRemainTime   = time.Hour
+	maxOperatorTracesOfRegion = 10
+)
+
+// NewOperatorTraces returns a OperatorTraces.
+func NewOperatorTraces(ctx context.Context) *OperatorTraces {
+	return &OperatorTraces{
+		ttl: cache.NewIDTTL(ctx, time.Minute, operatorTraceRemainTime),
+	}
+}
+
+// Get gets the traces of the region, from the oldest to the latest.
+func (o *OperatorTraces) Get(regionID uint64) []*operator.OpTrace {
+	o.Lock()
+	defer o.Unlock()
+	v, exist := o.ttl.Get(regionID)
+	if !exist {
+		return nil
+	}
+	return append([]*operator.OpTrace(nil), v.([]*operator.OpTrace)...)
+}
+
+// Put puts the trace, and drops the oldest one if there are too many traces of the region.
+func (o *OperatorTraces) Put(trace *operator.OpTrace) {
+	o.Lock()
+	defer o.Unlock()
+	var traces []*operator.OpTrace
+	if v, exist := o.ttl.Get(trace.RegionID); exist {
+		traces = v.([]*operator.OpTrace)
+	}
+	if len(traces) >= maxOperatorTracesOfRegion {
+		traces = traces[len(traces)-maxOperatorTracesOfRegion+1:]
+	}
+	o.ttl.Put(trace.RegionID, append(traces, trace))
+}
+
 // ExceedStoreLimit returns true if the store exceeds the cost limit after adding the operator. Otherwise, returns false.
 func (oc *OperatorController) ExceedStoreLimit(ops ...*operator.Operator) bool {
 	oc.Lock()
@@ -34,6 +34,7 @@ import (
 	"github.com/tikv/pd/server/schedule/hbstream"
 	"github.com/tikv/pd/server/schedule/labeler"
 	"github.com/tikv/pd/server/schedule/operator"
+	"go.uber.org/zap"
 )
 
 type operatorControllerTestSuite struct {
@@ -134,6 +135,39 @@ func (suite *operatorControllerTestSuite) TestOperatorStatus() {
 	suite.Equal(pdpb.OperatorStatus_SUCCESS, oc.GetOperatorStatus(2).Status)
 }
 
+func (suite *operatorControllerTestSuite) TestOperatorTraces() {
+	opt := config.NewTestOptions()
+	tc := mockcluster.NewCluster(suite.ctx, opt)
+	stream := hbstream.NewTestHeartbeatStreams(suite.ctx, tc.ID, tc, false /* no need to run */)
+	oc := NewOperatorController(suite.ctx, tc, stream)
+	tc.AddLeaderStore(1, 2)
+	tc.AddLeaderStore(2, 0)
+	tc.AddLeaderRegion(1, 1, 2)
+	steps := []operator.OpStep{
+		operator.RemovePeer{FromStore: 2},
+	}
+	for i := 0; i < maxOperatorTracesOfRegion+1; i++ {
+		op := operator.NewTestOperator(1, &metapb.RegionEpoch{}, operator.OpRegion, steps...)
+		op.SetDesc(fmt.Sprintf("test-%d", i))
+		suite.True(op.Start())
+		oc.SetOperator(op)
+		suite.True(oc.RemoveOperator(op, zap.String("reason", "test")))
+	}
+	op := operator.NewTestOperator(1, &metapb.RegionEpoch{}, operator.OpRegion, steps...)
+	suite.True(op.Start())
+	oc.SetOperator(op)
+
+	// The oldest trace is dropped, and the running operator is the last one.
+	traces := oc.GetOperatorTraces(1)
+	suite.Len(traces, maxOperatorTracesOfRegion+1)
+	suite.Equal("test-1", traces[0].Desc)
+	suite.Equal("Canceled", traces[0].Status)
+	suite.Equal("test", traces[0].Reason)
+	suite.Equal("Started", traces[maxOperatorTracesOfRegion].Status)
+	suite.Nil(traces[maxOperatorTracesOfRegion].FinishTime)
+	suite.Empty(oc.GetOperatorTraces(2))
+}
+
 func (suite *operatorControllerTestSuite) TestFastFailOperator() {
 	opt := config.NewTestOptions()
 	tc := mockcluster.NewCluster(suite.ctx, opt)
@@ -485,6 +519,67 @@ func (suite *operatorControllerTestSuite) TestDispatchOutdatedRegion() {
 	suite.Equal(3, stream.MsgLength())
 }
 
+func (suite *operatorControllerTestSuite) TestRestoreOperator() {
+	cluster := mockcluster.NewCluster(suite.ctx, config.NewTestOptions())
+	stream := hbstream.NewTestHeartbeatStreams(suite.ctx, cluster.ID, cluster, false /* no need to run */)
+	controller := NewOperatorController(suite.ctx, cluster, stream)
+	cluster.AddLeaderStore(1, 1)
+	cluster.AddLeaderStore(2, 0)
+	cluster.AddLeaderStore(3, 0)
+
+	// The learner has been added by the previous leader.
+	region := cluster.MockRegionInfo(1, 1, []uint64{2}, []uint64{3}, &metapb.RegionEpoch{ConfVer: 2, Version: 1})
+	cluster.PutRegion(region)
+	learner := region.GetStoreLearner(3)
+	newOperator := func(epoch *metapb.RegionEpoch) *operator.Operator {
+		op := operator.NewTestOperator(1, epoch, operator.OpRegion,
+			operator.AddLearner{ToStore: 3, PeerID: learner.GetId()},
+			operator.PromoteLearner{ToStore: 3, PeerID: learner.GetId()},
+		)
+		snapshot, err := op.Snapshot()
+		suite.NoError(err)
+		snapshot.CurrentStep = 1
+		op, err = snapshot.Restore()
+		suite.NoError(err)
+		return op
+	}
+
+	// The operator can not be added since the epoch has been changed.
+	suite.False(controller.AddOperator(newOperator(&metapb.RegionEpoch{ConfVer: 1, Version: 1})))
+	// The confver is changed by others.
+	suite.Equal(0, controller.RestoreOperator(newOperator(&metapb.RegionEpoch{ConfVer: 0, Version: 1})))
+	// The region has been split or merged.
+	suite.Equal(0, controller.RestoreOperator(newOperator(&metapb.RegionEpoch{ConfVer: 1, Version: 0})))
+	// The region is not found.
+	op := operator.NewTestOperator(2, &metapb.RegionEpoch{}, operator.OpRegion, operator.RemovePeer{FromStore: 1})
+	suite.Equal(0, controller.RestoreOperator(op))
+
+	op = newOperator(&metapb.RegionEpoch{ConfVer: 1, Version: 1})
+	suite.Equal(1, controller.RestoreOperator(op))
+	suite.Equal(operator.STARTED, op.Status())
+	suite.Equal(op, controller.GetOperator(1))
+	// The learner is promoted directly.
+	suite.Equal(1, stream.MsgLength())
+	// The region already has an operator.
+	suite.Equal(0, controller.RestoreOperator(newOperator(&metapb.RegionEpoch{ConfVer: 1, Version: 1})))
+
+	// The merge operators are restored in pairs.
+	source := newRegionInfo(4, "1a", "1b", 1, 1, []uint64{104, 1}, []uint64{104, 1})
+	target := newRegionInfo(5, "0a", "0b", 1, 1, []uint64{105, 1}, []uint64{105, 1})
+	cluster.PutRegion(source)
+	cluster.PutRegion(target)
+	ops, err := operator.CreateMergeRegionOperator("merge-region", cluster, source, target, operator.OpMerge)
+	suite.NoError(err)
+	suite.Equal(0, controller.RestoreOperator(ops[1]))
+	cluster.PutRegion(target.Clone(core.WithIncVersion()))
+	suite.Equal(0, controller.RestoreOperator(ops[1], ops[0]))
+	suite.Nil(controller.GetOperator(4))
+	cluster.PutRegion(target)
+	suite.Equal(2, controller.RestoreOperator(ops[1], ops[0]))
+	suite.Equal(ops[0], controller.GetOperator(4))
+	suite.Equal(ops[1], controller.GetOperator(5))
+}
+
 func (suite *operatorControllerTestSuite) TestCalcInfluence() {
 	cluster := mockcluster.NewCluster(suite.ctx, config.NewTestOptions())
 	stream := hbstream.NewTestHeartbeatStreams(suite.ctx, cluster.ID, cluster, false /* no need to run */)
@@ -28,6 +28,7 @@ import (
 	"github.com/tikv/pd/pkg/codec"
 	"github.com/tikv/pd/pkg/core"
 	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/event"
 	"github.com/tikv/pd/pkg/slice"
 	"github.com/tikv/pd/pkg/storage/endpoint"
 	"github.com/tikv/pd/pkg/utils/syncutil"
@@ -50,6 +51,7 @@ type RuleManager struct {
 	storeSetInformer core.StoreSetInformer
 	cache            *RegionRuleFitCacheManager
 	opt              *config.PersistOptions
+	eventHub         *event.Hub
 }
 
 // NewRuleManager creates a RuleManager instance.
@@ -63,6 +65,12 @@ func NewRuleManager(storage endpoint.RuleStorage, storeSetInformer core.StoreSet
 	}
 }
 
+// SetEventHub sets the hub the rule change events are published to. It should
+// be called before the manager is used.
+func (m *RuleManager) SetEventHub(hub *event.Hub) {
+	m.eventHub = hub
+}
+
 // Initialize loads rules from storage. If Placement Rules feature is never enabled, it creates default rule that is
 // compatible with previous configuration.
 func (m *RuleManager) Initialize(maxReplica int, locationLabels []string) error {
@@ -403,6 +411,13 @@ func (m *RuleManager) tryCommitPatch(patch *ruleConfigPatch) error {
 	// update in-memory state
 	patch.commit()
 	m.ruleList = ruleList
+	for key, r := range patch.mut.rules {
+		typ := event.RuleChanged
+		if r == nil {
+			typ = event.RuleDeleted
+		}
+		m.eventHub.Publish(&event.Event{Type: typ, Attributes: map[string]string{"group": key[0], "id": key[1]}})
+	}
 	return nil
 }
 
@@ -23,6 +23,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -43,12 +44,15 @@ import (
 	"github.com/tikv/pd/pkg/core"
 	"github.com/tikv/pd/pkg/encryption"
 	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/event"
 	"github.com/tikv/pd/pkg/id"
+	"github.com/tikv/pd/pkg/job"
 	"github.com/tikv/pd/pkg/mcs/registry"
 	rm_server "github.com/tikv/pd/pkg/mcs/resource_manager/server"
 	_ "github.com/tikv/pd/pkg/mcs/resource_manager/server/apis/v1" // init API group
 	"github.com/tikv/pd/pkg/member"
 	"github.com/tikv/pd/pkg/ratelimit"
+	"github.com/tikv/pd/pkg/rbac"
 	"github.com/tikv/pd/pkg/storage"
 	"github.com/tikv/pd/pkg/storage/endpoint"
 	"github.com/tikv/pd/pkg/storage/kv"
@@ -62,19 +66,25 @@ import (
 	"github.com/tikv/pd/pkg/utils/tsoutil"
 	"github.com/tikv/pd/pkg/utils/typeutil"
 	"github.com/tikv/pd/pkg/versioninfo"
+	"github.com/tikv/pd/pkg/webhook"
+	"github.com/tikv/pd/server/certmonitor"
 	"github.com/tikv/pd/server/cluster"
 	"github.com/tikv/pd/server/config"
 	"github.com/tikv/pd/server/gc"
+	"github.com/tikv/pd/server/globalconfig"
 	"github.com/tikv/pd/server/keyspace"
 	syncer "github.com/tikv/pd/server/region_syncer"
 	"github.com/tikv/pd/server/schedule"
 	"github.com/tikv/pd/server/schedule/hbstream"
 	"github.com/tikv/pd/server/schedule/placement"
+	"github.com/tikv/pd/server/standby"
 	"go.etcd.io/etcd/clientv3"
 	"go.etcd.io/etcd/embed"
 	"go.etcd.io/etcd/pkg/types"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 const (
@@ -89,8 +99,16 @@ const (
 	idAllocLabel = "idalloc"
 
 	recoveringMarkPath = "cluster/markers/snapshot-recovering"
+
+	// maxFinishedJobs is the number of the finished admin jobs kept in memory.
+	maxFinishedJobs = 100
 )
 
+// leaderGRPCServices are the gRPC services reported as serving by the health
+// service only when the server is the ready leader. The overall status, whose
+// service name is empty, is serving once the server starts.
+var leaderGRPCServices = []string{"pdpb.PD", "keyspacepb.Keyspace", RuleServiceName, "resource_manager.ResourceManager"}
+
 // EtcdStartTimeout the timeout of the startup etcd.
 var EtcdStartTimeout = time.Minute * 5
 
@@ -126,7 +144,8 @@ type Server struct {
 	serverLoopWg     sync.WaitGroup
 
 	// for PD leader election.
-	member *member.Member
+	member          *member.Member
+	campaignBackoff *member.CampaignBackoff
 	// etcd client
 	client *clientv3.Client
 	// http client
@@ -145,14 +164,45 @@ type Server struct {
 	storage storage.Storage
 	// safepoint manager
 	gcSafePointManager *gc.SafePointManager
+	// serviceSafePointChecker notifies the service safepoints blocking GC.
+	serviceSafePointChecker *gc.ServiceSafePointChecker
+	// gcController advances the GC safepoint for the clusters without TiDB.
+	gcController *gc.Controller
+	// keyspace safepoint manager
+	keyspaceSafePointManager *gc.KeyspaceSafePointManager
+	// rbacManager authorizes the requests by the role bindings.
+	rbacManager *rbac.Manager
+	// securityLogger writes the security audit log, nil if it is disabled.
+	securityLogger *audit.SecurityLogger
+	// certMonitor checks the expiry of the TLS certificates.
+	certMonitor *certmonitor.Monitor
 	// keyspace manager
 	keyspaceManager *keyspace.Manager
+	// keyspace watcher
+	keyspaceWatcher *keyspace.Watcher
+	// standby replicator
+	standbyReplicator *standby.Replicator
+	// healthServer reports the serving status through the standard gRPC health service.
+	healthServer *health.Server
+	// globalConfigStore stores the global config in namespaces.
+	globalConfigStore *globalconfig.Store
+	// jobManager runs the asynchronous admin jobs on the leader.
+	jobManager *job.Manager
+	// webhookManager calls the webhooks on the cluster events.
+	webhookManager *webhook.Manager
+	// externalServe serves the client requests with the external etcd.
+	externalServe *externalServe
 	// for basicCluster operation.
 	basicCluster *core.BasicCluster
 	// for tso.
 	tsoAllocatorManager *tso.AllocatorManager
 	// for raft cluster
 	cluster *cluster.RaftCluster
+	// followerCluster is never started, it serves the follower reads from the synced cache.
+	followerCluster *cluster.RaftCluster
+	// lastFollowerRefresh is the last time in nanoseconds the follower refreshes its
+	// configs and stores, or 0 if the follower cache is not being refreshed.
+	lastFollowerRefresh int64
 	// For async region heartbeat.
 	hbStreams *hbstream.HeartbeatStreams
 	// Zap logger
@@ -166,6 +216,13 @@ type Server struct {
 	leaderCallbacks []func(context.Context)
 	// closeCallbacks will be called before the server is closed.
 	closeCallbacks []func()
+	// electionHooks will be called with the election events of the server.
+	electionHooks []member.ElectionHook
+
+	electionHistory *member.ElectionHistory
+	// noLeaderSince is the time the server observes the PD leader is gone, or
+	// zero if there is a PD leader. It is only accessed in the leader loop.
+	noLeaderSince time.Time
 
 	// hot region history info storage
 	hotRegionStorage *storage.HotRegionStorage
@@ -176,10 +233,14 @@ type Server struct {
 	tsoDispatcher sync.Map /* Store as map[string]chan *tsoRequest */
 
 	serviceRateLimiter *ratelimit.Limiter
-	serviceLabels      map[string][]apiutil.AccessPath
-	apiServiceLabelMap map[apiutil.AccessPath]string
+	// identityRateLimiter limits the APIs by the caller identities.
+	identityRateLimiter *ratelimit.Limiter
+	serviceLabels       map[string][]apiutil.AccessPath
+	apiServiceLabelMap  map[apiutil.AccessPath]string
 
 	serviceAuditBackendLabels map[string]*audit.BackendLabels
+	// followerReadableServices are the services which can be served by a follower.
+	followerReadableServices map[string]struct{}
 
 	auditBackends []audit.Backend
 
@@ -201,19 +262,30 @@ func CreateServer(ctx context.Context, cfg *config.Config, legacyServiceBuilders
 		serviceMiddlewareCfg:            serviceMiddlewareCfg,
 		serviceMiddlewarePersistOptions: config.NewServiceMiddlewarePersistOptions(serviceMiddlewareCfg),
 		member:                          &member.Member{},
+		campaignBackoff:                 member.NewCampaignBackoff(),
 		ctx:                             ctx,
 		startTimestamp:                  time.Now().Unix(),
 		DiagnosticsServer:               sysutil.NewDiagnosticsServer(cfg.Log.File.Filename),
 	}
 	s.handler = newHandler(s)
+	s.certMonitor = certmonitor.NewMonitor(cfg)
 
 	// create audit backend
 	s.auditBackends = []audit.Backend{
 		audit.NewLocalLogBackend(true),
 		audit.NewPrometheusHistogramBackend(serviceAuditHistogram, false),
 	}
+	if cfg.Security.AuditLog.IsEnabled() {
+		securityLogger, err := audit.NewSecurityLogger(ctx, cfg.Security.AuditLog.File, cfg.Security.AuditLog.Sink)
+		if err != nil {
+			return nil, err
+		}
+		s.securityLogger = securityLogger
+	}
 	s.serviceRateLimiter = ratelimit.NewLimiter()
+	s.identityRateLimiter = ratelimit.NewLimiter()
 	s.serviceAuditBackendLabels = make(map[string]*audit.BackendLabels)
+	s.followerReadableServices = make(map[string]struct{})
 	s.serviceLabels = make(map[string][]apiutil.AccessPath)
 	s.apiServiceLabelMap = make(map[apiutil.AccessPath]string)
 
@@ -238,13 +310,24 @@ func CreateServer(ctx context.Context, cfg *config.Config, legacyServiceBuilders
 	// Register the micro services REST path.
 	s.registry.InstallAllRESTHandler(s, etcdCfg.UserHandlers)
 
+	s.healthServer = health.NewServer()
+	s.healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	s.setLeaderServingStatus(healthpb.HealthCheckResponse_NOT_SERVING)
 	etcdCfg.ServiceRegister = func(gs *grpc.Server) {
 		grpcServer := &GrpcServer{Server: s}
 		pdpb.RegisterPDServer(gs, grpcServer)
 		keyspacepb.RegisterKeyspaceServer(gs, &KeyspaceServer{GrpcServer: grpcServer})
+		RegisterRuleServer(gs, &RuleServer{GrpcServer: grpcServer})
+		RegisterKeyspaceGCServer(gs, &KeyspaceGCServer{GrpcServer: grpcServer})
+		RegisterBucketServer(gs, &BucketServer{GrpcServer: grpcServer})
+		RegisterRegionServer(gs, &RegionServer{GrpcServer: grpcServer})
+		RegisterStoreServer(gs, &StoreServer{GrpcServer: grpcServer})
 		diagnosticspb.RegisterDiagnosticsServer(gs, s)
 		// Register the micro services GRPC service.
 		s.registry.InstallAllGRPCServices(s, gs)
+		if !grpcutil.RegisterHealthAndReflection(gs, s.healthServer) {
+			log.Info("the gRPC health service is provided by etcd")
+		}
 	}
 
 	s.etcdCfg = etcdCfg
@@ -288,6 +371,11 @@ func (s *Server) startEtcd(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	// A learner only serves the serializable reads, so a member added as a learner
+	// connects to the voting members until it is promoted.
+	if etcd.Server.IsLearner() {
+		s.client.SetEndpoints(voterClientURLs(etcd)...)
+	}
 
 	// update advertise peer urls.
 	etcdMembers, err := etcdutil.ListEtcdMembers(s.client)
@@ -311,6 +399,35 @@ func (s *Server) startEtcd(ctx context.Context) error {
 	return nil
 }
 
+// voterClientURLs returns the client URLs of the voting members in the local
+// view of the etcd membership.
+func voterClientURLs(etcd *embed.Etcd) []string {
+	var voterURLs []string
+	for _, m := range etcd.Server.Cluster().Members() {
+		if !m.IsLearner {
+			voterURLs = append(voterURLs, m.ClientURLs...)
+		}
+	}
+	sort.Strings(voterURLs)
+	return voterURLs
+}
+
+// refreshWitnessEndpoints points the client of the witness to the current
+// voting members, so it keeps working after the members it joins are removed.
+func (s *Server) refreshWitnessEndpoints() {
+	voterURLs := voterClientURLs(s.member.Etcd())
+	if len(voterURLs) == 0 {
+		return
+	}
+	endpoints := append([]string(nil), s.client.Endpoints()...)
+	sort.Strings(endpoints)
+	if strings.Join(endpoints, ",") == strings.Join(voterURLs, ",") {
+		return
+	}
+	log.Info("update the endpoints of the witness", zap.Strings("from", endpoints), zap.Strings("to", voterURLs))
+	s.client.SetEndpoints(voterURLs...)
+}
+
 func startClient(cfg *config.Config) (*clientv3.Client, *http.Client, error) {
 	tlsConfig, err := cfg.Security.ToTLSConfig()
 	if err != nil {
@@ -320,7 +437,22 @@ func startClient(cfg *config.Config) (*clientv3.Client, *http.Client, error) {
 	if err != nil {
 		return nil, nil, err
 	}
-	return etcdutil.CreateClients(tlsConfig, etcdCfg.ACUrls)
+	acUrls := etcdCfg.ACUrls
+	switch {
+	case cfg.ExternalEtcd.IsEnabled():
+		acUrls, err = types.NewURLs(cfg.ExternalEtcd.Endpoints)
+		if err != nil {
+			return nil, nil, errs.ErrEtcdURLMap.Wrap(err).GenWithStackByCause()
+		}
+	case cfg.Witness:
+		// A learner only serves the serializable reads, so a witness connects
+		// to the voting members it joins.
+		acUrls, err = types.NewURLs(strings.Split(cfg.Join, ","))
+		if err != nil {
+			return nil, nil, errs.ErrEtcdURLMap.Wrap(err).GenWithStackByCause()
+		}
+	}
+	return etcdutil.CreateClients(tlsConfig, acUrls, cfg.ExternalEtcd.Username, cfg.ExternalEtcd.Password)
 }
 
 // AddStartCallback adds a callback in the startServer phase.
@@ -343,7 +475,12 @@ func (s *Server) startServer(ctx context.Context) error {
 	s.member.MemberInfo(s.cfg.AdvertiseClientUrls, s.cfg.AdvertisePeerUrls, s.Name(), s.rootPath)
 	s.member.SetMemberDeployPath(s.member.ID())
 	s.member.SetMemberBinaryVersion(s.member.ID(), versioninfo.PDReleaseVersion)
+	s.member.SetMemberZone(s.member.ID(), s.cfg.Labels[config.ZoneLabel])
 	s.member.SetMemberGitHash(s.member.ID(), versioninfo.PDGitHash)
+	s.member.SetMemberWitness(s.member.ID(), s.cfg.Witness)
+	if s.member.IsExternalEtcd() {
+		s.member.RegisterExternalMember(ctx)
+	}
 	s.idAllocator = id.NewAllocator(&id.AllocatorParams{
 		Client:    s.client,
 		RootPath:  s.rootPath,
@@ -378,9 +515,25 @@ func (s *Server) startServer(ctx context.Context) error {
 	}
 	defaultStorage := storage.NewStorageWithEtcdBackend(s.client, s.rootPath)
 	s.storage = storage.NewCoreStorage(defaultStorage, regionStorage)
-	s.gcSafePointManager = gc.NewSafePointManager(s.storage)
+	s.gcSafePointManager = gc.NewSafePointManager(s.storage, s.cfg.GC)
+	s.serviceSafePointChecker = gc.NewServiceSafePointChecker(s.gcSafePointManager)
+	s.serviceSafePointChecker.SetEventPublisher(s.publishEvent)
+	s.AddLeaderCallback(s.serviceSafePointChecker.StartChecker)
+	s.gcController = gc.NewController(s.gcSafePointManager, func() (uint64, error) {
+		ts, err := s.tsoAllocatorManager.HandleTSORequest(tso.GlobalDCLocation, 1)
+		if err != nil {
+			return 0, err
+		}
+		return tsoutil.GenerateTS(&ts), nil
+	})
+	s.AddLeaderCallback(s.gcController.StartController)
+	s.keyspaceSafePointManager = gc.NewKeyspaceSafePointManager(s.storage)
+	rbacCfg := &s.cfg.Security.RBAC
+	s.rbacManager = rbac.NewManager(s.storage, rbacCfg.AdminCN, rbacCfg.TokenMaxTTL.Duration, rbacCfg.SessionTTL.Duration, rbacCfg.MaxSessions)
+	s.electionHistory = member.NewElectionHistory(s.storage)
 	s.basicCluster = core.NewBasicCluster()
 	s.cluster = cluster.NewRaftCluster(ctx, s.clusterID, syncer.NewRegionSyncer(s), s.client, s.httpClient)
+	s.followerCluster = cluster.NewFollowerCluster(ctx, s.clusterID, s.persistOptions, s.storage, s.basicCluster)
 	keyspaceIDAllocator := id.NewAllocator(&id.AllocatorParams{
 		Client:    s.client,
 		RootPath:  s.rootPath,
@@ -390,6 +543,20 @@ func (s *Server) startServer(ctx context.Context) error {
 		Step:      keyspace.AllocStep,
 	})
 	s.keyspaceManager = keyspace.NewKeyspaceManager(s.storage, s.cluster, keyspaceIDAllocator, s.cfg.Keyspace)
+	s.AddLeaderCallback(s.keyspaceManager.StartQuotaChecker)
+	s.AddLeaderCallback(s.keyspaceManager.StartUsageReporter)
+	s.keyspaceWatcher = keyspace.NewWatcher(s.client, s.rootPath)
+	s.globalConfigStore = globalconfig.NewStore(s.client)
+	tlsConfig, err := s.cfg.Security.ToTLSConfig()
+	if err != nil {
+		return err
+	}
+	s.standbyReplicator = standby.NewReplicator(s.client, s.rootPath, s.cfg.Standby, tlsConfig)
+	s.AddLeaderCallback(s.standbyReplicator.StartReplicate)
+	s.jobManager = job.NewManager(maxFinishedJobs)
+	s.AddLeaderCallback(s.jobManager.OnLeader)
+	s.webhookManager = webhook.NewManager(s.storage, s.getEventHub)
+	s.AddLeaderCallback(s.webhookManager.OnLeader)
 	s.hbStreams = hbstream.NewHeartbeatStreams(ctx, s.clusterID, s.cluster)
 	// initial hot_region_storage in here.
 	s.hotRegionStorage, err = storage.NewHotRegionsStorage(
@@ -422,6 +589,7 @@ func (s *Server) Close() {
 
 	log.Info("closing server")
 
+	s.healthServer.Shutdown()
 	s.stopServerLoop()
 
 	if s.client != nil {
@@ -437,6 +605,7 @@ func (s *Server) Close() {
 	if s.member.Etcd() != nil {
 		s.member.Close()
 	}
+	s.stopExternalServe()
 
 	if s.hbStreams != nil {
 		s.hbStreams.Close()
@@ -469,7 +638,11 @@ func (s *Server) Run() error {
 		log.Error("system time jumps backward", errs.ZapError(errs.ErrIncorrectSystemTime))
 		timeJumpBackCounter.Inc()
 	})
-	if err := s.startEtcd(s.ctx); err != nil {
+	if s.cfg.ExternalEtcd.IsEnabled() {
+		if err := s.startExternalEtcd(); err != nil {
+			return err
+		}
+	} else if err := s.startEtcd(s.ctx); err != nil {
 		return err
 	}
 	if err := s.startServer(s.ctx); err != nil {
@@ -477,6 +650,7 @@ func (s *Server) Run() error {
 	}
 
 	s.startServerLoop(s.ctx)
+	s.healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
 
 	return nil
 }
@@ -509,6 +683,7 @@ func (s *Server) startServerLoop(ctx context.Context) {
 	go s.serverMetricsLoop()
 	go s.tsoAllocatorLoop()
 	go s.encryptionKeyManagerLoop()
+	s.certMonitor.StartMonitor(s.serverLoopCtx)
 }
 
 func (s *Server) stopServerLoop() {
@@ -556,6 +731,9 @@ func (s *Server) encryptionKeyManagerLoop() {
 }
 
 func (s *Server) collectEtcdStateMetrics() {
+	if s.member.IsExternalEtcd() {
+		return
+	}
 	etcdTermGauge.Set(float64(s.member.Etcd().Server.Term()))
 	etcdAppliedIndexGauge.Set(float64(s.member.Etcd().Server.AppliedIndex()))
 	etcdCommittedIndexGauge.Set(float64(s.member.Etcd().Server.CommittedIndex()))
@@ -760,6 +938,82 @@ func (s *Server) GetKeyspaceManager() *keyspace.Manager {
 	return s.keyspaceManager
 }
 
+// GetRuleManager returns the placement rule manager, or nil if the cluster is
+// not running or the placement rules are disabled.
+func (s *Server) GetRuleManager() *placement.RuleManager {
+	rc := s.GetRaftCluster()
+	if rc == nil || !s.persistOptions.IsPlacementRulesEnabled() {
+		return nil
+	}
+	return rc.GetRuleManager()
+}
+
+// GetKeyspaceWatcher returns the watcher of the keyspace metadata changes.
+func (s *Server) GetKeyspaceWatcher() *keyspace.Watcher {
+	return s.keyspaceWatcher
+}
+
+// GetEncryptionKeyManager returns the manager of the encryption keys.
+func (s *Server) GetEncryptionKeyManager() *encryption.Manager {
+	return s.encryptionKeyManager
+}
+
+// GetGCSafePointManager returns the manager of the GC safepoints.
+func (s *Server) GetGCSafePointManager() *gc.SafePointManager {
+	return s.gcSafePointManager
+}
+
+// GetServiceSafePointChecker returns the checker of the service safepoints.
+func (s *Server) GetServiceSafePointChecker() *gc.ServiceSafePointChecker {
+	return s.serviceSafePointChecker
+}
+
+// GetGCController returns the GC controller.
+func (s *Server) GetGCController() *gc.Controller {
+	return s.gcController
+}
+
+// GetKeyspaceSafePointManager returns the manager of the keyspaces' GC safepoints.
+func (s *Server) GetKeyspaceSafePointManager() *gc.KeyspaceSafePointManager {
+	return s.keyspaceSafePointManager
+}
+
+// GetStandbyReplicator returns the replicator of the standby cluster.
+func (s *Server) GetStandbyReplicator() *standby.Replicator {
+	return s.standbyReplicator
+}
+
+// GetGlobalConfigStore returns the store of the global config.
+func (s *Server) GetGlobalConfigStore() *globalconfig.Store {
+	return s.globalConfigStore
+}
+
+// GetJobManager returns the manager of the asynchronous admin jobs.
+func (s *Server) GetJobManager() *job.Manager {
+	return s.jobManager
+}
+
+// GetWebhookManager returns the manager of the webhooks.
+func (s *Server) GetWebhookManager() *webhook.Manager {
+	return s.webhookManager
+}
+
+// getEventHub returns the event hub of the running cluster, nil if the cluster
+// is not running.
+func (s *Server) getEventHub() *event.Hub {
+	rc := s.GetRaftCluster()
+	if rc == nil {
+		return nil
+	}
+	return rc.GetEventHub()
+}
+
+// publishEvent publishes the cluster event, which is discarded if the cluster
+// is not running.
+func (s *Server) publishEvent(e *event.Event) {
+	s.getEventHub().Publish(e)
+}
+
 // Name returns the unique etcd Name for this server in etcd cluster.
 func (s *Server) Name() string {
 	return s.cfg.Name
@@ -780,6 +1034,9 @@ func (s *Server) GetMembers() ([]*pdpb.Member, error) {
 	if s.IsClosed() {
 		return nil, errs.ErrServerNotStarted.FastGenByArgs()
 	}
+	if s.member.IsExternalEtcd() {
+		return s.member.GetExternalMembers()
+	}
 	members, err := cluster.GetMembers(s.GetClient())
 	return members, err
 }
@@ -965,11 +1222,16 @@ func (s *Server) SetAuditConfig(cfg config.AuditConfig) error {
 	return nil
 }
 
-// UpdateRateLimitConfig is used to update rate-limit config which will reserve old limiter-config
+// UpdateRateLimitConfig is used to update rate-limit config which will reserve the other limiters
+// of limiter-config or identity-limiter-config.
 func (s *Server) UpdateRateLimitConfig(key, label string, value ratelimit.DimensionConfig) error {
 	cfg := s.GetServiceMiddlewareConfig()
+	limiterCfg := cfg.LimiterConfig
+	if key == "identity-limiter-config" {
+		limiterCfg = cfg.IdentityLimiterConfig
+	}
 	rateLimitCfg := make(map[string]ratelimit.DimensionConfig)
-	for label, item := range cfg.LimiterConfig {
+	for label, item := range limiterCfg {
 		rateLimitCfg[label] = item
 	}
 	rateLimitCfg[label] = value
@@ -1135,7 +1397,63 @@ func (s *Server) GetClusterVersion() semver.Version {
 	return *s.persistOptions.GetClusterVersion()
 }
 
-// GetTLSConfig get the security config.
+// IsRBACEnabled returns whether the role-based access control is enabled.
+func (s *Server) IsRBACEnabled() bool {
+	return s.cfg.Security.RBAC.Enable
+}
+
+// GetRBACManager returns the manager of the role bindings, nil if the server
+// is not started.
+func (s *Server) GetRBACManager() *rbac.Manager {
+	return s.rbacManager
+}
+
+// GetSecurityLogger returns the security audit logger, nil if it is disabled.
+func (s *Server) GetSecurityLogger() *audit.SecurityLogger {
+	return s.securityLogger
+}
+
+// GetCertMonitor returns the monitor of the TLS certificates.
+func (s *Server) GetCertMonitor() *certmonitor.Monitor {
+	return s.certMonitor
+}
+
+// Authorize checks whether the credential has the required role if the
+// role-based access control is enabled. The identity is nil if it is disabled.
+func (s *Server) Authorize(cred rbac.Credential, required rbac.Role) (*rbac.Identity, error) {
+	if !s.IsRBACEnabled() {
+		return nil, nil
+	}
+	if s.rbacManager == nil {
+		return nil, errs.ErrServerNotStarted.FastGenByArgs()
+	}
+	return s.rbacManager.Authorize(cred, required, time.Now())
+}
+
+// AuthorizeGRPCPolicy checks whether the client certificate of the credential
+// is allowed to call the gRPC method if the gRPC policies are enabled.
+func (s *Server) AuthorizeGRPCPolicy(cred rbac.Credential) error {
+	if !s.cfg.Security.GRPCPolicy.Enable {
+		return nil
+	}
+	if s.rbacManager == nil {
+		return errs.ErrServerNotStarted.FastGenByArgs()
+	}
+	return s.rbacManager.AuthorizeGRPC(cred, s.cfg.Security.GRPCPolicy.DefaultDeny, time.Now())
+}
+
+// CheckNetworkACL checks whether the source address of the request to the
+// path on the listener is allowed if the network ACLs are enabled.
+func (s *Server) CheckNetworkACL(listener, path, remoteAddr string) error {
+	if !s.cfg.Security.NetworkACL.Enable {
+		return nil
+	}
+	if s.rbacManager == nil {
+		return errs.ErrServerNotStarted.FastGenByArgs()
+	}
+	return s.rbacManager.CheckNetworkACL(listener, path, remoteAddr, time.Now())
+}
+
 func (s *Server) GetTLSConfig() *grpcutil.TLSConfig {
 	return &s.cfg.Security.TLSConfig
 }
@@ -1236,6 +1554,16 @@ func (s *Server) GetServiceRateLimiter() *ratelimit.Limiter {
 	return s.serviceRateLimiter
 }
 
+// GetIdentityRateLimiter returns the rate limiter keyed by the caller identities.
+func (s *Server) GetIdentityRateLimiter() *ratelimit.Limiter {
+	return s.identityRateLimiter
+}
+
+// UpdateIdentityRateLimiter is used to update the rate limiter of the caller identity.
+func (s *Server) UpdateIdentityRateLimiter(identity string, opts ...ratelimit.Option) ratelimit.UpdateStatus {
+	return s.identityRateLimiter.Update(identity, opts...)
+}
+
 // IsInRateLimitAllowList returns whethis given service label is in allow lost
 func (s *Server) IsInRateLimitAllowList(serviceLabel string) bool {
 	return s.serviceRateLimiter.IsInAllowList(serviceLabel)
@@ -1323,6 +1651,26 @@ func (s *Server) AddLeaderCallback(callbacks ...func(context.Context)) {
 	s.leaderCallbacks = append(s.leaderCallbacks, callbacks...)
 }
 
+// AddElectionHook adds hooks called with the PD leader election events of the server.
+func (s *Server) AddElectionHook(hooks ...member.ElectionHook) {
+	s.electionHooks = append(s.electionHooks, hooks...)
+}
+
+// GetElectionHistory returns the election history of the PD leader.
+func (s *Server) GetElectionHistory() *member.ElectionHistory {
+	return s.electionHistory
+}
+
+func (s *Server) recordElectionEvent(event *member.ElectionEvent) {
+	event.Service = "pd"
+	event.Name = s.Name()
+	event.MemberID = s.member.ID()
+	s.electionHistory.Record(event)
+	for _, hook := range s.electionHooks {
+		hook(event)
+	}
+}
+
 func (s *Server) leaderLoop() {
 	defer logutil.LogPanic()
 	defer s.serverLoopWg.Done()
@@ -1337,7 +1685,11 @@ func (s *Server) leaderLoop() {
 		if checkAgain {
 			continue
 		}
+		if leader == nil && s.noLeaderSince.IsZero() {
+			s.noLeaderSince = time.Now()
+		}
 		if leader != nil {
+			s.noLeaderSince = time.Time{}
 			err := s.reloadConfigFromKV()
 			if err != nil {
 				log.Error("reload config failed", errs.ZapError(err))
@@ -1349,13 +1701,41 @@ func (s *Server) leaderLoop() {
 			if s.persistOptions.IsUseRegionStorage() {
 				syncer.StartSyncWithLeader(leader.GetClientUrls()[0])
 			}
+			stopFollowerRefresh := s.startFollowerRefresh()
 			log.Info("start to watch pd leader", zap.Stringer("pd-leader", leader))
 			// WatchLeader will keep looping and never return unless the PD leader has changed.
 			s.member.WatchLeader(s.serverLoopCtx, leader, rev)
+			stopFollowerRefresh()
 			syncer.StopSyncWithLeader()
+			s.noLeaderSince = time.Now()
 			log.Info("pd leader has changed, try to re-campaign a pd leader")
 		}
 
+		// A witness never campaigns for the PD leader.
+		if s.cfg.Witness {
+			s.refreshWitnessEndpoints()
+			select {
+			case <-s.serverLoopCtx.Done():
+				log.Info("server is closed, return pd leader loop")
+				return
+			case <-time.After(s.persistOptions.GetPDServerConfig().GetElectionRetryInterval()):
+			}
+			continue
+		}
+
+		// A flapping member stays away from the campaign for a while, unless no
+		// other member becomes the leader in time, e.g. all of them are flapping.
+		cfg := s.persistOptions.GetPDServerConfig()
+		if remaining := s.campaignBackoff.CooldownRemaining(time.Now()); remaining > 0 &&
+			time.Since(s.noLeaderSince) < cfg.GetElectionMaxBackoff() && s.yieldEtcdLeader() {
+			retryInterval := cfg.GetElectionRetryInterval()
+			if remaining > retryInterval {
+				remaining = retryInterval
+			}
+			time.Sleep(remaining)
+			continue
+		}
+
 		// To make sure the etcd leader and PD leader are on the same server.
 		etcdLeader := s.member.GetEtcdLeader()
 		if etcdLeader != s.member.ID() {
@@ -1363,16 +1743,58 @@ func (s *Server) leaderLoop() {
 				zap.String("server-name", s.Name()),
 				zap.Uint64("etcd-leader-id", etcdLeader),
 				zap.Uint64("member-id", s.member.ID()))
-			time.Sleep(200 * time.Millisecond)
+			time.Sleep(s.persistOptions.GetPDServerConfig().GetElectionRetryInterval())
 			continue
 		}
 		s.campaignLeader()
 	}
 }
 
+// getLeaderLease returns the lease of the PD leader, the one set by the
+// config API takes precedence over the one in the config file.
+func (s *Server) getLeaderLease() int64 {
+	if lease := s.persistOptions.GetPDServerConfig().LeaderLease; lease > 0 {
+		return lease
+	}
+	return s.cfg.LeaderLease
+}
+
+// yieldEtcdLeader transfers the etcd leader to another voter, so that it can
+// campaign for the PD leader. It returns false if there is no other voter.
+func (s *Server) yieldEtcdLeader() bool {
+	// Any member can campaign with the external etcd.
+	if s.member.IsExternalEtcd() {
+		members, err := s.GetMembers()
+		return err != nil || len(members) > 1
+	}
+	res, err := etcdutil.ListEtcdMembers(s.client)
+	if err != nil {
+		log.Error("failed to list etcd members", errs.ZapError(err))
+		return true
+	}
+	var candidates []uint64
+	for _, m := range res.Members {
+		if m.GetID() != s.member.ID() && !m.GetIsLearner() {
+			candidates = append(candidates, m.GetID())
+		}
+	}
+	if len(candidates) == 0 {
+		return false
+	}
+	if s.member.GetEtcdLeader() == s.member.ID() {
+		next := candidates[rand.Intn(len(candidates))]
+		if err := s.member.MoveEtcdLeader(s.serverLoopCtx, s.member.ID(), next); err != nil {
+			log.Error("failed to yield etcd leader", errs.ZapError(err))
+		}
+	}
+	return true
+}
+
 func (s *Server) campaignLeader() {
 	log.Info("start to campaign pd leader", zap.String("campaign-pd-leader-name", s.Name()))
-	if err := s.member.CampaignLeader(s.cfg.LeaderLease); err != nil {
+	electionCounter.WithLabelValues("campaign").Inc()
+	if err := s.member.CampaignLeader(s.getLeaderLease()); err != nil {
+		electionCounter.WithLabelValues("failure").Inc()
 		if err.Error() == errs.ErrEtcdTxnConflict.Error() {
 			log.Info("campaign pd leader meets error due to txn conflict, another PD server may campaign successfully",
 				zap.String("campaign-pd-leader-name", s.Name()))
@@ -1381,8 +1803,30 @@ func (s *Server) campaignLeader() {
 				zap.String("campaign-pd-leader-name", s.Name()),
 				errs.ZapError(err))
 		}
+		cfg := s.persistOptions.GetPDServerConfig()
+		backoff := s.campaignBackoff.NextBackoff(cfg.GetElectionRetryInterval(), cfg.GetElectionMaxBackoff())
+		select {
+		case <-time.After(backoff):
+		case <-s.serverLoopCtx.Done():
+		}
 		return
 	}
+	electionCounter.WithLabelValues("won").Inc()
+	s.campaignBackoff.Reset()
+	leaderSince := time.Now()
+	elected := &member.ElectionEvent{Type: member.ElectionEventElected, Time: leaderSince}
+	if !s.noLeaderSince.IsZero() {
+		elected.NoLeaderDuration = typeutil.NewDuration(leaderSince.Sub(s.noLeaderSince))
+	}
+	s.recordElectionEvent(elected)
+	// lostReason is why the leadership is lost, it is updated before returning.
+	// Only the involuntary losses count for the flapping detection, but not the
+	// transfers, e.g. resigning the leader or moving it by the priorities.
+	lostReason, involuntary := "failed to initialize the leader", true
+	defer func() {
+		s.noLeaderSince = time.Now()
+		s.onLeadershipLost(leaderSince, lostReason, involuntary)
+	}()
 
 	// Start keepalive the leadership and enable TSO service.
 	// TSO service is strictly enabled/disabled by PD leader lease for 2 reasons:
@@ -1450,6 +1894,10 @@ func (s *Server) campaignLeader() {
 	}
 	// EnableLeader to accept the remaining service, such as GetStore, GetRegion.
 	s.member.EnableLeader()
+	s.publishEvent(&event.Event{
+		Type:       event.MemberLeaderChanged,
+		Attributes: map[string]string{"name": s.Name(), "member_id": strconv.FormatUint(s.member.ID(), 10)},
+	})
 	// Check the cluster dc-location after the PD leader is elected.
 	go s.tsoAllocatorManager.ClusterDCLocationChecker()
 	defer resetLeaderOnce.Do(func() {
@@ -1461,6 +1909,8 @@ func (s *Server) campaignLeader() {
 
 	CheckPDVersion(s.persistOptions)
 	log.Info("PD cluster leader is ready to serve", zap.String("pd-leader-name", s.Name()))
+	s.setLeaderServingStatus(healthpb.HealthCheckResponse_SERVING)
+	defer s.setLeaderServingStatus(healthpb.HealthCheckResponse_NOT_SERVING)
 
 	leaderTicker := time.NewTicker(leaderTickInterval)
 	defer leaderTicker.Stop()
@@ -1470,21 +1920,69 @@ func (s *Server) campaignLeader() {
 		case <-leaderTicker.C:
 			if !s.member.IsLeader() {
 				log.Info("no longer a leader because lease has expired, pd leader will step down")
+				lostReason = "lease expired"
 				return
 			}
 			etcdLeader := s.member.GetEtcdLeader()
 			if etcdLeader != s.member.ID() {
 				log.Info("etcd leader changed, resigns pd leadership", zap.String("old-pd-leader-name", s.Name()))
+				lostReason, involuntary = "etcd leader changed", false
+				s.handOverScheduling()
 				return
 			}
 		case <-ctx.Done():
 			// Server is closed and it should return nil.
 			log.Info("server is closed")
+			s.handOverScheduling()
 			return
 		}
 	}
 }
 
+// setLeaderServingStatus sets the health status of the gRPC services which are
+// only served by the leader.
+func (s *Server) setLeaderServingStatus(status healthpb.HealthCheckResponse_ServingStatus) {
+	for _, service := range leaderGRPCServices {
+		s.healthServer.SetServingStatus(service, status)
+	}
+}
+
+// handOverScheduling saves the in-flight scheduling state for the next leader.
+// It is called when the leader resigns on purpose and still holds the
+// leadership, so the next leader can not load the state before it is saved.
+func (s *Server) handOverScheduling() {
+	if s.cluster == nil {
+		return
+	}
+	if err := s.cluster.SaveSchedulingHandoff(); err != nil {
+		log.Error("failed to hand over scheduling state", errs.ZapError(err))
+	}
+}
+
+// onLeadershipLost records the member loses the leadership, and starts the
+// cooldown if the member keeps losing it involuntarily.
+func (s *Server) onLeadershipLost(leaderSince time.Time, reason string, involuntary bool) {
+	if s.serverLoopCtx.Err() != nil {
+		return
+	}
+	now := time.Now()
+	electionCounter.WithLabelValues("lost").Inc()
+	s.recordElectionEvent(&member.ElectionEvent{Type: member.ElectionEventLost, Reason: reason, Time: now})
+	leaderTenureHistogram.Observe(now.Sub(leaderSince).Seconds())
+	if !involuntary {
+		return
+	}
+	cfg := s.persistOptions.GetPDServerConfig()
+	cooldown := cfg.GetLeaderFlappingCooldown()
+	if s.campaignBackoff.OnLeadershipLost(now, cfg.LeaderFlappingThreshold, cfg.GetLeaderFlappingWindow(), cooldown) {
+		electionCounter.WithLabelValues("cooldown").Inc()
+		log.Warn("pd leadership is flapping, stop campaigning for a while",
+			zap.String("server-name", s.Name()),
+			zap.Int("threshold", cfg.LeaderFlappingThreshold),
+			zap.Duration("cooldown", cooldown))
+	}
+}
+
 func (s *Server) etcdLeaderLoop() {
 	defer logutil.LogPanic()
 	defer s.serverLoopWg.Done()
@@ -1494,7 +1992,11 @@ func (s *Server) etcdLeaderLoop() {
 	for {
 		select {
 		case <-time.After(s.cfg.LeaderPriorityCheckInterval.Duration):
-			s.member.CheckPriority(ctx)
+			// The etcd leader can not be transferred to a learner, and it should
+			// not be pulled back to a member in the campaign cooldown.
+			if !s.cfg.Witness && s.campaignBackoff.CooldownRemaining(time.Now()) == 0 {
+				s.member.CheckPriority(ctx)
+			}
 		case <-ctx.Done():
 			log.Info("server is closed, exit etcd leader loop")
 			return
@@ -1530,6 +2032,11 @@ func (s *Server) loadRateLimitConfig() {
 		value := cfg[key]
 		s.serviceRateLimiter.Update(key, ratelimit.UpdateDimensionConfig(&value))
 	}
+	identityCfg := s.serviceMiddlewarePersistOptions.GetRateLimitConfig().IdentityLimiterConfig
+	for identity := range identityCfg {
+		value := identityCfg[identity]
+		s.identityRateLimiter.Update(identity, ratelimit.UpdateDimensionConfig(&value))
+	}
 }
 
 // ReplicateFileToMember is used to synchronize state to a member.
@@ -19,9 +19,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
+	"github.com/tikv/pd/pkg/member"
 	"github.com/tikv/pd/pkg/utils/assertutil"
 	"github.com/tikv/pd/pkg/utils/etcdutil"
 	"github.com/tikv/pd/pkg/utils/testutil"
@@ -83,7 +86,7 @@ func (suite *leaderServerTestSuite) TearDownSuite() {
 	}
 }
 
-func (suite *leaderServerTestSuite) newTestServersWithCfgs(ctx context.Context, cfgs []*config.Config) ([]*Server, CleanupFunc) {
+func (suite *leaderServerTestSuite) newTestServersWithCfgs(ctx context.Context, cfgs []*config.Config, beforeRun ...func(*Server)) ([]*Server, CleanupFunc) {
 	svrs := make([]*Server, 0, len(cfgs))
 
 	ch := make(chan *Server)
@@ -101,6 +104,9 @@ func (suite *leaderServerTestSuite) newTestServersWithCfgs(ctx context.Context,
 				}
 			}()
 			suite.NoError(err)
+			for _, f := range beforeRun {
+				f(svr)
+			}
 			err = svr.Run()
 			suite.NoError(err)
 			failed = false
@@ -287,3 +293,115 @@ func (suite *leaderServerTestSuite) TestSourceIpForHeaderBoth() {
 	bodyString := string(bodyBytes)
 	suite.Equal("Hello World\n", bodyString)
 }
+
+func (suite *leaderServerTestSuite) TestExternalEtcd() {
+	re := suite.Require()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	etcdCfg := etcdutil.NewTestSingleConfig(suite.T())
+	etcd, err := embed.StartEtcd(etcdCfg)
+	re.NoError(err)
+	defer etcd.Close()
+	<-etcd.Server.ReadyNotify()
+
+	cfgs := NewTestMultiConfig(assertutil.CheckerWithNilAssert(re), 2)
+	for _, cfg := range cfgs {
+		cfg.ExternalEtcd.Endpoints = []string{etcdCfg.LCUrls[0].String()}
+	}
+	svrs, cleanup := suite.newTestServersWithCfgs(ctx, cfgs)
+	defer cleanup()
+
+	var leader, follower *Server
+	for _, svr := range svrs {
+		re.True(svr.GetMember().IsExternalEtcd())
+		if svr.GetMember().IsLeader() {
+			leader = svr
+		} else {
+			follower = svr
+		}
+	}
+	re.NotNil(leader)
+	re.NotNil(follower)
+
+	// Both PD servers register themselves, while the etcd cluster keeps a single member.
+	testutil.Eventually(re, func() bool {
+		members, err := follower.GetMembers()
+		return err == nil && len(members) == 2
+	})
+	etcdMembers, err := etcdutil.ListEtcdMembers(leader.GetClient())
+	re.NoError(err)
+	re.Len(etcdMembers.Members, 1)
+
+	// The follower takes over once the leader is gone.
+	leader.Close()
+	testutil.Eventually(re, func() bool {
+		return follower.GetMember().IsLeader()
+	})
+}
+
+func (suite *leaderServerTestSuite) TestElectionHistory() {
+	re := suite.Require()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	hookedEvents := make(map[string][]*member.ElectionEvent)
+	cfgs := NewTestMultiConfig(assertutil.CheckerWithNilAssert(re), 3)
+	svrs, cleanup := suite.newTestServersWithCfgs(ctx, cfgs, func(svr *Server) {
+		svr.AddElectionHook(func(event *member.ElectionEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			hookedEvents[event.Name] = append(hookedEvents[event.Name], event)
+		})
+	})
+	defer cleanup()
+	leader := MustWaitLeader(re, svrs)
+
+	loadEvents := func() []*member.ElectionEvent {
+		events, err := leader.GetElectionHistory().Load(time.Unix(0, 0), time.Now().Add(time.Minute), 0)
+		re.NoError(err)
+		return events
+	}
+	events := loadEvents()
+	re.NotEmpty(events)
+	elected := events[len(events)-1]
+	re.Equal("pd", elected.Service)
+	re.Equal(member.ElectionEventElected, elected.Type)
+	re.Equal(leader.Name(), elected.Name)
+	re.Equal(leader.GetMember().ID(), elected.MemberID)
+	mu.Lock()
+	re.Equal(elected.Type, hookedEvents[leader.Name()][0].Type)
+	mu.Unlock()
+
+	// The resigned leader records why it loses the leadership.
+	re.NoError(leader.GetMember().ResignEtcdLeader(ctx, leader.Name(), ""))
+	var newLeader *Server
+	testutil.Eventually(re, func() bool {
+		newLeader = MustWaitLeader(re, svrs)
+		return newLeader.GetLeader().GetName() != leader.Name()
+	})
+	testutil.Eventually(re, func() bool {
+		events = loadEvents()
+		var lost, reelected bool
+		for _, event := range events {
+			if event.Name == leader.Name() && event.Type == member.ElectionEventLost {
+				re.Equal("etcd leader changed", event.Reason)
+				lost = true
+			}
+			if event.Name == newLeader.GetLeader().GetName() && event.Type == member.ElectionEventElected {
+				reelected = true
+			}
+		}
+		return lost && reelected
+	})
+	events, err := leader.GetElectionHistory().Load(time.Unix(0, 0), time.Now().Add(time.Minute), 1)
+	re.NoError(err)
+	re.Len(events, 1)
+	events, err = leader.GetElectionHistory().Load(time.Now().Add(time.Minute), time.Now().Add(2*time.Minute), 0)
+	re.NoError(err)
+	re.Empty(events)
+
+	mu.Lock()
+	defer mu.Unlock()
+	re.Equal(member.ElectionEventLost, hookedEvents[leader.Name()][len(hookedEvents[leader.Name()])-1].Type)
+}
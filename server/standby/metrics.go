@@ -0,0 +1,49 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standby
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	replicatedKeyCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "standby",
+			Name:      "replicated_keys_total",
+			Help:      "Counter of the metadata keys replicated to the standby cluster.",
+		}, []string{"type"})
+
+	replicationErrorCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "standby",
+			Name:      "replication_errors_total",
+			Help:      "Counter of the errors which break the replication to the standby cluster.",
+		})
+
+	replicatedRevisionGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "standby",
+			Name:      "replicated_revision",
+			Help:      "The etcd revision of the primary cluster which has been replicated to the standby cluster.",
+		})
+)
+
+func init() {
+	prometheus.MustRegister(replicatedKeyCounter)
+	prometheus.MustRegister(replicationErrorCounter)
+	prometheus.MustRegister(replicatedRevisionGauge)
+}
@@ -0,0 +1,414 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standby
+
+import (
+	"context"
+	"crypto/tls"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/utils/etcdutil"
+	"github.com/tikv/pd/pkg/utils/syncutil"
+	"github.com/tikv/pd/pkg/utils/typeutil"
+	"github.com/tikv/pd/server/config"
+	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/mvcc/mvccpb"
+	"go.uber.org/zap"
+)
+
+const (
+	clusterIDKey = "cluster_id"
+	// loadPageSize is the max number of keys loaded by one range request.
+	loadPageSize = 1000
+	// maxTxnOps is less than the default `max-txn-ops` of etcd.
+	maxTxnOps = 100
+)
+
+// replicatedPaths are the metadata paths replicated to the standby cluster,
+// which are relative to the root path of the cluster. The regions, the TSO
+// and the election keys are not replicated, because the standby cluster
+// maintains them by itself after taking over.
+var replicatedPaths = []string{
+	"config",
+	"service_middleware",
+	"schedule",
+	"scheduler_config",
+	"rules",
+	"rule_group",
+	"region_label",
+	"gc",
+	"keyspaces",
+	"raft/s",
+}
+
+func isReplicated(relativeKey string) bool {
+	for _, p := range replicatedPaths {
+		if relativeKey == p || strings.HasPrefix(relativeKey, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// State is the state of the replication.
+type State string
+
+const (
+	// StateDisabled means the standby cluster is not configured.
+	StateDisabled State = "disabled"
+	// StateIdle means the server is not the leader, so it does not replicate.
+	StateIdle State = "idle"
+	// StateSyncing means the replicator is copying the full metadata.
+	StateSyncing State = "syncing"
+	// StateWatching means the full metadata has been copied and the replicator
+	// is applying the incremental changes.
+	StateWatching State = "watching"
+	// StateBroken means the replication is broken and waiting to retry.
+	StateBroken State = "broken"
+)
+
+// Status is the status of the replication.
+// NOTE: This type is exported by HTTP API. Please pay more attention when modifying it.
+type Status struct {
+	State            State    `json:"state"`
+	Endpoints        []string `json:"endpoints,omitempty"`
+	StandbyClusterID uint64   `json:"standby_cluster_id,omitempty"`
+	// Revision is the etcd revision of the primary cluster which has been
+	// replicated to the standby cluster.
+	Revision       int64     `json:"revision"`
+	LastUpdateTime time.Time `json:"last_update_time"`
+	LastError      string    `json:"last_error,omitempty"`
+}
+
+// Replicator replicates the metadata of the PD cluster, such as the configs,
+// the placement rules, the stores and the safe points, to a standby PD
+// cluster. It keeps the control-plane state of the standby cluster warm, so
+// the standby cluster can take over when the primary one is lost. The
+// metadata is written through the etcd gRPC API of the standby cluster with
+// the key prefix rewritten to the standby cluster ID.
+type Replicator struct {
+	client    *clientv3.Client
+	rootPath  string
+	cfg       config.StandbyConfig
+	tlsConfig *tls.Config
+
+	mu     syncutil.RWMutex
+	status Status
+}
+
+// NewReplicator creates a new Replicator. The client and the rootPath are of
+// the primary cluster.
+func NewReplicator(client *clientv3.Client, rootPath string, cfg config.StandbyConfig, tlsConfig *tls.Config) *Replicator {
+	r := &Replicator{
+		client:    client,
+		rootPath:  rootPath,
+		cfg:       cfg,
+		tlsConfig: tlsConfig,
+	}
+	r.status.State = StateDisabled
+	if cfg.IsEnabled() {
+		r.status.State = StateIdle
+		r.status.Endpoints = cfg.Endpoints
+	}
+	return r
+}
+
+// GetStatus returns the status of the replication.
+func (r *Replicator) GetStatus() Status {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.status
+}
+
+func (r *Replicator) updateStatus(f func(*Status)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f(&r.status)
+	r.status.LastUpdateTime = time.Now()
+}
+
+// StartReplicate starts the replication in the background, which is stopped
+// once the context is canceled. It is called when the server becomes leader.
+func (r *Replicator) StartReplicate(ctx context.Context) {
+	if !r.cfg.IsEnabled() {
+		return
+	}
+	go r.run(ctx)
+}
+
+func (r *Replicator) run(ctx context.Context) {
+	log.Info("start replicating metadata to the standby cluster", zap.Strings("endpoints", r.cfg.Endpoints))
+	defer func() {
+		r.updateStatus(func(s *Status) { s.State = StateIdle })
+		log.Info("stop replicating metadata to the standby cluster")
+	}()
+	for {
+		err := r.replicate(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		replicationErrorCounter.Inc()
+		log.Warn("replication to the standby cluster is broken", errs.ZapError(err))
+		r.updateStatus(func(s *Status) {
+			s.State = StateBroken
+			s.LastError = err.Error()
+		})
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.cfg.RetryInterval.Duration):
+		}
+	}
+}
+
+func (r *Replicator) replicate(ctx context.Context) error {
+	standby, err := clientv3.New(clientv3.Config{
+		Endpoints:   r.cfg.Endpoints,
+		DialTimeout: etcdutil.DefaultDialTimeout,
+		TLS:         r.tlsConfig,
+		Context:     ctx,
+	})
+	if err != nil {
+		return errs.ErrNewEtcdClient.Wrap(err).GenWithStackByCause()
+	}
+	defer standby.Close()
+
+	standbyRootPath, standbyClusterID, err := r.getStandbyRootPath(standby)
+	if err != nil {
+		return err
+	}
+	r.updateStatus(func(s *Status) {
+		s.State = StateSyncing
+		s.StandbyClusterID = standbyClusterID
+	})
+	rev, err := r.fullSync(ctx, standby, standbyRootPath)
+	if err != nil {
+		return err
+	}
+	r.updateRevision(rev, StateWatching)
+	log.Info("metadata is synced to the standby cluster",
+		zap.Uint64("standby-cluster-id", standbyClusterID), zap.Int64("revision", rev))
+	return r.watch(ctx, standby, standbyRootPath, rev+1)
+}
+
+// getStandbyRootPath returns the root path of the standby cluster. The
+// cluster ID is never initialized here, the standby cluster must be started
+// before the replication.
+func (r *Replicator) getStandbyRootPath(standby *clientv3.Client) (string, uint64, error) {
+	pdRootPath := path.Dir(r.rootPath)
+	resp, err := etcdutil.EtcdKVGet(standby, path.Join(pdRootPath, clusterIDKey))
+	if err != nil {
+		return "", 0, err
+	}
+	if len(resp.Kvs) == 0 {
+		return "", 0, errors.New("the standby cluster is not initialized")
+	}
+	clusterID, err := typeutil.BytesToUint64(resp.Kvs[0].Value)
+	if err != nil {
+		return "", 0, err
+	}
+	standbyRootPath := path.Join(pdRootPath, strconv.FormatUint(clusterID, 10))
+	if standbyRootPath == r.rootPath {
+		return "", 0, errors.Errorf("the standby cluster %d is the primary cluster itself", clusterID)
+	}
+	return standbyRootPath, clusterID, nil
+}
+
+func (r *Replicator) updateRevision(rev int64, state State) {
+	replicatedRevisionGauge.Set(float64(rev))
+	r.updateStatus(func(s *Status) {
+		s.State = state
+		s.Revision = rev
+		s.LastError = ""
+	})
+}
+
+// fullSync copies all the replicated metadata at the same revision to the
+// standby cluster, and removes the keys which do not exist in the primary
+// cluster. It returns the revision of the copied metadata.
+func (r *Replicator) fullSync(ctx context.Context, standby *clientv3.Client, standbyRootPath string) (int64, error) {
+	resp, err := etcdutil.EtcdKVGet(r.client, r.rootPath)
+	if err != nil {
+		return 0, err
+	}
+	rev := resp.Header.GetRevision()
+	for _, p := range replicatedPaths {
+		prefix := path.Join(r.rootPath, p)
+		existed := make(map[string]struct{})
+		var ops []clientv3.Op
+		err := loadRange(ctx, r.client, prefix, rev, false, func(kv *mvccpb.KeyValue) error {
+			key := strings.TrimPrefix(string(kv.Key), r.rootPath+"/")
+			if !isReplicated(key) {
+				return nil
+			}
+			existed[key] = struct{}{}
+			ops = append(ops, clientv3.OpPut(path.Join(standbyRootPath, key), string(kv.Value)))
+			if len(ops) >= maxTxnOps {
+				err := applyOps(ctx, standby, ops)
+				ops = ops[:0]
+				return err
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+		if err := applyOps(ctx, standby, ops); err != nil {
+			return 0, err
+		}
+		replicatedKeyCounter.WithLabelValues("put").Add(float64(len(existed)))
+
+		ops = ops[:0]
+		standbyPrefix := path.Join(standbyRootPath, p)
+		var deleted int
+		err = loadRange(ctx, standby, standbyPrefix, 0, true, func(kv *mvccpb.KeyValue) error {
+			key := strings.TrimPrefix(string(kv.Key), standbyRootPath+"/")
+			if _, ok := existed[key]; ok || !isReplicated(key) {
+				return nil
+			}
+			deleted++
+			ops = append(ops, clientv3.OpDelete(string(kv.Key)))
+			if len(ops) >= maxTxnOps {
+				err := applyOps(ctx, standby, ops)
+				ops = ops[:0]
+				return err
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+		if err := applyOps(ctx, standby, ops); err != nil {
+			return 0, err
+		}
+		replicatedKeyCounter.WithLabelValues("delete").Add(float64(deleted))
+	}
+	return rev, nil
+}
+
+// watch applies the changes of the replicated metadata since the given
+// revision to the standby cluster. It returns when the context is canceled or
+// the watch is broken, e.g. the revision is compacted, then a full sync is
+// required.
+func (r *Replicator) watch(ctx context.Context, standby *clientv3.Client, standbyRootPath string, rev int64) error {
+	watchCtx, cancel := context.WithCancel(clientv3.WithRequireLeader(ctx))
+	defer cancel()
+	watchChan := r.client.Watch(watchCtx, r.rootPath+"/", clientv3.WithPrefix(), clientv3.WithRev(rev))
+	for resp := range watchChan {
+		if err := resp.Err(); err != nil {
+			return errs.ErrEtcdWatcherCancel.Wrap(err).GenWithStackByCause()
+		}
+		var ops []clientv3.Op
+		for _, ev := range resp.Events {
+			key := strings.TrimPrefix(string(ev.Kv.Key), r.rootPath+"/")
+			if !isReplicated(key) {
+				continue
+			}
+			standbyKey := path.Join(standbyRootPath, key)
+			switch ev.Type {
+			case clientv3.EventTypePut:
+				ops = append(ops, clientv3.OpPut(standbyKey, string(ev.Kv.Value)))
+				replicatedKeyCounter.WithLabelValues("put").Inc()
+			case clientv3.EventTypeDelete:
+				ops = append(ops, clientv3.OpDelete(standbyKey))
+				replicatedKeyCounter.WithLabelValues("delete").Inc()
+			}
+		}
+		// The ops are applied in batches to bound the transaction size.
+		for len(ops) > 0 {
+			n := len(ops)
+			if n > maxTxnOps {
+				n = maxTxnOps
+			}
+			if err := applyOpsInOrder(ctx, standby, ops[:n]); err != nil {
+				return err
+			}
+			ops = ops[n:]
+		}
+		r.updateRevision(resp.Header.GetRevision(), StateWatching)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return errs.ErrEtcdWatcherCancel.FastGenByArgs()
+}
+
+// loadRange loads the keys with the given prefix page by page. If rev is not
+// zero, the keys are loaded at the revision.
+func loadRange(ctx context.Context, client *clientv3.Client, prefix string, rev int64, keysOnly bool, f func(*mvccpb.KeyValue) error) error {
+	end := clientv3.GetPrefixRangeEnd(prefix)
+	start := prefix
+	for {
+		opts := []clientv3.OpOption{clientv3.WithRange(end), clientv3.WithLimit(loadPageSize)}
+		if rev > 0 {
+			opts = append(opts, clientv3.WithRev(rev))
+		}
+		if keysOnly {
+			opts = append(opts, clientv3.WithKeysOnly())
+		}
+		reqCtx, cancel := context.WithTimeout(ctx, etcdutil.DefaultRequestTimeout)
+		resp, err := client.Get(reqCtx, start, opts...)
+		cancel()
+		if err != nil {
+			return errs.ErrEtcdKVGet.Wrap(err).GenWithStackByCause()
+		}
+		for _, kv := range resp.Kvs {
+			if err := f(kv); err != nil {
+				return err
+			}
+		}
+		if !resp.More || len(resp.Kvs) == 0 {
+			return nil
+		}
+		start = string(resp.Kvs[len(resp.Kvs)-1].Key) + "\x00"
+	}
+}
+
+// applyOps applies the ops on distinct keys in one transaction.
+func applyOps(ctx context.Context, client *clientv3.Client, ops []clientv3.Op) error {
+	if len(ops) == 0 {
+		return nil
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, etcdutil.DefaultRequestTimeout)
+	defer cancel()
+	if _, err := client.Txn(reqCtx).Then(ops...).Commit(); err != nil {
+		return errs.ErrEtcdTxnInternal.Wrap(err).GenWithStackByCause()
+	}
+	return nil
+}
+
+// applyOpsInOrder applies the ops one by one, because etcd rejects a
+// transaction which puts a key more than once.
+func applyOpsInOrder(ctx context.Context, client *clientv3.Client, ops []clientv3.Op) error {
+	keys := make(map[string]struct{}, len(ops))
+	for _, op := range ops {
+		if _, ok := keys[string(op.KeyBytes())]; ok {
+			for _, op := range ops {
+				if err := applyOps(ctx, client, []clientv3.Op{op}); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		keys[string(op.KeyBytes())] = struct{}{}
+	}
+	return applyOps(ctx, client, ops)
+}
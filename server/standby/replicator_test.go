@@ -0,0 +1,179 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standby
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/utils/etcdutil"
+	"github.com/tikv/pd/pkg/utils/testutil"
+	"github.com/tikv/pd/pkg/utils/typeutil"
+	"github.com/tikv/pd/server/config"
+	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/embed"
+)
+
+func startEtcd(t *testing.T, re *require.Assertions) (*embed.Etcd, *clientv3.Client) {
+	cfg := etcdutil.NewTestSingleConfig(t)
+	etcd, err := embed.StartEtcd(cfg)
+	re.NoError(err)
+	<-etcd.Server.ReadyNotify()
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints: []string{cfg.LCUrls[0].String()},
+	})
+	re.NoError(err)
+	return etcd, client
+}
+
+func TestIsReplicated(t *testing.T) {
+	re := require.New(t)
+	re.True(isReplicated("config"))
+	re.True(isReplicated("rules/pd/default"))
+	re.True(isReplicated("raft/s/00000000000000000001"))
+	re.True(isReplicated("gc/safe_point/service/gc_worker"))
+	re.False(isReplicated("raft"))
+	re.False(isReplicated("raft/r/00000000000000000001"))
+	re.False(isReplicated("timestamp"))
+	re.False(isReplicated("leader"))
+	re.False(isReplicated("configs"))
+}
+
+func TestReplicate(t *testing.T) {
+	re := require.New(t)
+	primaryEtcd, primary := startEtcd(t, re)
+	defer func() {
+		primary.Close()
+		primaryEtcd.Close()
+	}()
+	standbyEtcd, standby := startEtcd(t, re)
+	defer func() {
+		standby.Close()
+		standbyEtcd.Close()
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	put := func(client *clientv3.Client, key, value string) {
+		_, err := client.Put(ctx, key, value)
+		re.NoError(err)
+	}
+	get := func(client *clientv3.Client, key string) (string, bool) {
+		resp, err := client.Get(ctx, key)
+		re.NoError(err)
+		if len(resp.Kvs) == 0 {
+			return "", false
+		}
+		return string(resp.Kvs[0].Value), true
+	}
+
+	put(standby, "/pd/cluster_id", string(typeutil.Uint64ToBytes(2)))
+	put(standby, "/pd/2/rules/stale", "stale")
+	put(standby, "/pd/2/timestamp", "standby-ts")
+	put(primary, "/pd/1/config", "config")
+	put(primary, "/pd/1/rules/pd/default", "rule")
+	put(primary, "/pd/1/raft/s/00000000000000000001", "store")
+	put(primary, "/pd/1/raft/r/00000000000000000002", "region")
+	put(primary, "/pd/1/timestamp", "primary-ts")
+
+	cfg := config.StandbyConfig{
+		Endpoints:     standby.Endpoints(),
+		RetryInterval: typeutil.NewDuration(100 * time.Millisecond),
+	}
+	r := NewReplicator(primary, "/pd/1", cfg, nil)
+	re.Equal(StateIdle, r.GetStatus().State)
+	r.StartReplicate(ctx)
+
+	// Full sync.
+	testutil.Eventually(re, func() bool {
+		return r.GetStatus().State == StateWatching
+	})
+	status := r.GetStatus()
+	re.Equal(uint64(2), status.StandbyClusterID)
+	re.Empty(status.LastError)
+	for key, expected := range map[string]string{
+		"config":                      "config",
+		"rules/pd/default":            "rule",
+		"raft/s/00000000000000000001": "store",
+		"timestamp":                   "standby-ts",
+	} {
+		value, ok := get(standby, "/pd/2/"+key)
+		re.True(ok, key)
+		re.Equal(expected, value, key)
+	}
+	_, ok := get(standby, "/pd/2/rules/stale")
+	re.False(ok)
+	_, ok = get(standby, "/pd/2/raft/r/00000000000000000002")
+	re.False(ok)
+
+	// Incremental changes.
+	put(primary, "/pd/1/config", "config-v2")
+	put(primary, "/pd/1/gc/safe_point", "100")
+	put(primary, "/pd/1/gc/safe_point", "200")
+	_, err := primary.Delete(ctx, "/pd/1/rules/pd/default")
+	re.NoError(err)
+	put(primary, "/pd/1/timestamp", "primary-ts-v2")
+	testutil.Eventually(re, func() bool {
+		value, _ := get(standby, "/pd/2/gc/safe_point")
+		_, ruleExists := get(standby, "/pd/2/rules/pd/default")
+		return value == "200" && !ruleExists
+	})
+	value, _ := get(standby, "/pd/2/config")
+	re.Equal("config-v2", value)
+	value, _ = get(standby, "/pd/2/timestamp")
+	re.Equal("standby-ts", value)
+	re.Greater(r.GetStatus().Revision, status.Revision)
+
+	cancel()
+	testutil.Eventually(re, func() bool {
+		return r.GetStatus().State == StateIdle
+	})
+}
+
+func TestReplicateToItself(t *testing.T) {
+	re := require.New(t)
+	etcd, client := startEtcd(t, re)
+	defer func() {
+		client.Close()
+		etcd.Close()
+	}()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := config.StandbyConfig{
+		Endpoints:     client.Endpoints(),
+		RetryInterval: typeutil.NewDuration(100 * time.Millisecond),
+	}
+	r := NewReplicator(client, "/pd/1", cfg, nil)
+	r.StartReplicate(ctx)
+	testutil.Eventually(re, func() bool {
+		status := r.GetStatus()
+		return status.State == StateBroken && status.LastError != ""
+	})
+
+	_, err := client.Put(ctx, "/pd/cluster_id", string(typeutil.Uint64ToBytes(1)))
+	re.NoError(err)
+	testutil.Eventually(re, func() bool {
+		status := r.GetStatus()
+		return status.State == StateBroken && strings.Contains(status.LastError, "primary cluster itself")
+	})
+
+	disabled := NewReplicator(client, "/pd/1", config.StandbyConfig{}, nil)
+	disabled.StartReplicate(ctx)
+	re.Equal(StateDisabled, disabled.GetStatus().State)
+}
@@ -47,6 +47,12 @@ func NewHotCache(ctx context.Context) *HotCache {
 	return w
 }
 
+// SetFlowSmoothingConfig sets the config of the windows which smooth the hot peer flow.
+func (w *HotCache) SetFlowSmoothingConfig(cfg FlowSmoothingConfig) {
+	w.writeCache.smoothing.set(cfg)
+	w.readCache.smoothing.set(cfg)
+}
+
 // CheckWriteAsync puts the flowItem into queue, and check it asynchronously
 func (w *HotCache) CheckWriteAsync(task FlowItemTask) bool {
 	if w.writeCache.taskQueue.Len() > chanMaxLength {
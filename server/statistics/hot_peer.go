@@ -30,13 +30,15 @@ type dimStat struct {
 	rolling         *movingaverage.TimeMedian // it's used to statistic hot degree and average speed.
 	lastIntervalSum int                       // lastIntervalSum and lastDelta are used to calculate the average speed of the last interval.
 	lastDelta       float64
+	smoothing       FlowSmoothingConfig // smoothing is the config which the rolling is created with.
 }
 
-func newDimStat(reportInterval time.Duration) *dimStat {
+func newDimStat(reportInterval time.Duration, smoothing FlowSmoothingConfig) *dimStat {
 	return &dimStat{
-		rolling:         movingaverage.NewTimeMedian(DefaultAotSize, rollingWindowsSize, reportInterval),
+		rolling:         smoothing.newTimeMedian(smoothing.HotPeerWindowSize, reportInterval),
 		lastIntervalSum: 0,
 		lastDelta:       0,
+		smoothing:       smoothing,
 	}
 }
 
@@ -85,6 +87,7 @@ func (d *dimStat) Clone() *dimStat {
 	return &dimStat{
 		rolling:         d.rolling.Clone(),
 		lastIntervalSum: d.lastIntervalSum,
+		smoothing:       d.smoothing,
 	}
 }
 
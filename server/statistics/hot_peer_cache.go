@@ -83,6 +83,7 @@ type hotPeerCache struct {
 	taskQueue         *chanx.UnboundedChan[FlowItemTask]
 	thresholdsOfStore map[uint64]*thresholds                     // storeID -> thresholds
 	metrics           map[uint64][ActionTypeLen]prometheus.Gauge // storeID -> metrics
+	smoothing         *flowSmoothing
 	// TODO: consider to remove store info when store is offline.
 }
 
@@ -97,6 +98,7 @@ func NewHotPeerCache(ctx context.Context, kind RWType) *hotPeerCache {
 		thresholdsOfStore: make(map[uint64]*thresholds),
 		topNTTL:           time.Duration(3*kind.ReportInterval()) * time.Second,
 		metrics:           make(map[uint64][ActionTypeLen]prometheus.Gauge),
+		smoothing:         newFlowSmoothing(),
 	}
 }
 
@@ -460,6 +462,15 @@ func (f *hotPeerCache) updateHotPeerStat(region *core.RegionInfo, newItem, oldIt
 		newItem.allowInherited = oldItem.allowInherited
 	}
 
+	if smoothing := f.smoothing.get(); len(newItem.rollingLoads) > 0 && newItem.rollingLoads[0].smoothing != smoothing {
+		// The smoothing windows are changed, so the rolling loads are rebuilt with the new windows.
+		rollingLoads := make([]*dimStat, len(newItem.rollingLoads))
+		for i := range rollingLoads {
+			rollingLoads[i] = newDimStat(f.interval(), smoothing)
+		}
+		newItem.rollingLoads = rollingLoads
+	}
+
 	if f.justTransferLeader(region, oldItem) {
 		newItem.lastTransferLeaderTime = time.Now()
 		// skip the first heartbeat flow statistic after transfer leader, because its statistics are calculated by the last leader in this store and are inaccurate
@@ -516,8 +527,9 @@ func (f *hotPeerCache) updateNewHotPeerStat(newItem *HotPeerStat, deltaLoads []f
 	}
 	newItem.actionType = Add
 	newItem.rollingLoads = make([]*dimStat, len(regionStats))
+	smoothing := f.smoothing.get()
 	for i, k := range regionStats {
-		ds := newDimStat(f.interval())
+		ds := newDimStat(f.interval(), smoothing)
 		ds.Add(deltaLoads[k], interval)
 		if ds.isFull(f.interval()) {
 			ds.clearLastAverage()
@@ -31,3 +31,10 @@ func NewHotStat(ctx context.Context) *HotStat {
 		StoresStats: NewStoresStats(),
 	}
 }
+
+// SetFlowSmoothingConfig sets the config of the windows which smooth the flow
+// statistics of the cluster.
+func (s *HotStat) SetFlowSmoothingConfig(cfg FlowSmoothingConfig) {
+	s.HotCache.SetFlowSmoothingConfig(cfg)
+	s.StoresStats.SetFlowSmoothingConfig(cfg)
+}
@@ -38,15 +38,22 @@ const (
 type StoresStats struct {
 	syncutil.RWMutex
 	rollingStoresStats map[uint64]*RollingStoreStats
+	smoothing          *flowSmoothing
 }
 
 // NewStoresStats creates a new hot spot cache.
 func NewStoresStats() *StoresStats {
 	return &StoresStats{
 		rollingStoresStats: make(map[uint64]*RollingStoreStats),
+		smoothing:          newFlowSmoothing(),
 	}
 }
 
+// SetFlowSmoothingConfig sets the config of the windows which smooth the store flow.
+func (s *StoresStats) SetFlowSmoothingConfig(cfg FlowSmoothingConfig) {
+	s.smoothing.set(cfg)
+}
+
 // RemoveRollingStoreStats removes RollingStoreStats with a given store ID.
 func (s *StoresStats) RemoveRollingStoreStats(storeID uint64) {
 	s.Lock()
@@ -67,7 +74,7 @@ func (s *StoresStats) GetOrCreateRollingStoreStats(storeID uint64) *RollingStore
 	defer s.Unlock()
 	ret, ok := s.rollingStoresStats[storeID]
 	if !ok {
-		ret = newRollingStoreStats()
+		ret = newRollingStoreStats(s.smoothing)
 		s.rollingStoresStats[storeID] = ret
 	}
 	return ret
@@ -136,21 +143,20 @@ type RollingStoreStats struct {
 	syncutil.RWMutex
 	timeMedians []*movingaverage.TimeMedian
 	movingAvgs  []movingaverage.MovingAvg
+	// smoothing is the config which the timeMedians are created with, and
+	// smoothingSource is where the current config is loaded from.
+	smoothing       FlowSmoothingConfig
+	smoothingSource *flowSmoothing
 }
 
 // NewRollingStoreStats creates a RollingStoreStats.
-func newRollingStoreStats() *RollingStoreStats {
+func newRollingStoreStats(smoothingSource *flowSmoothing) *RollingStoreStats {
 	timeMedians := make([]*movingaverage.TimeMedian, StoreStatCount)
 	movingAvgs := make([]movingaverage.MovingAvg, StoreStatCount)
 
 	// from StoreHeartbeat
-	interval := StoreHeartBeatReportInterval * time.Second
-	timeMedians[StoreReadBytes] = movingaverage.NewTimeMedian(DefaultAotSize, DefaultReadMfSize, interval)
-	timeMedians[StoreReadKeys] = movingaverage.NewTimeMedian(DefaultAotSize, DefaultReadMfSize, interval)
-	timeMedians[StoreReadQuery] = movingaverage.NewTimeMedian(DefaultAotSize, DefaultReadMfSize, interval)
-	timeMedians[StoreWriteBytes] = movingaverage.NewTimeMedian(DefaultAotSize, DefaultWriteMfSize, interval)
-	timeMedians[StoreWriteKeys] = movingaverage.NewTimeMedian(DefaultAotSize, DefaultWriteMfSize, interval)
-	timeMedians[StoreWriteQuery] = movingaverage.NewTimeMedian(DefaultAotSize, DefaultWriteMfSize, interval)
+	smoothing := smoothingSource.get()
+	resetStoreTimeMedians(timeMedians, smoothing)
 	movingAvgs[StoreCPUUsage] = movingaverage.NewMedianFilter(storeStatsRollingWindowsSize)
 	movingAvgs[StoreDiskReadRate] = movingaverage.NewMedianFilter(storeStatsRollingWindowsSize)
 	movingAvgs[StoreDiskWriteRate] = movingaverage.NewMedianFilter(storeStatsRollingWindowsSize)
@@ -161,8 +167,17 @@ func newRollingStoreStats() *RollingStoreStats {
 	movingAvgs[StoreRegionsWriteKeys] = movingaverage.NewMedianFilter(RegionsStatsRollingWindowsSize)
 
 	return &RollingStoreStats{
-		timeMedians: timeMedians,
-		movingAvgs:  movingAvgs,
+		timeMedians:     timeMedians,
+		movingAvgs:      movingAvgs,
+		smoothing:       smoothing,
+		smoothingSource: smoothingSource,
+	}
+}
+
+func resetStoreTimeMedians(timeMedians []*movingaverage.TimeMedian, smoothing FlowSmoothingConfig) {
+	interval := StoreHeartBeatReportInterval * time.Second
+	for _, k := range []StoreStatKind{StoreReadBytes, StoreReadKeys, StoreReadQuery, StoreWriteBytes, StoreWriteKeys, StoreWriteQuery} {
+		timeMedians[k] = smoothing.newTimeMedian(smoothing.StoreWindowSize, interval)
 	}
 }
 
@@ -189,6 +204,11 @@ func (r *RollingStoreStats) Observe(stats *pdpb.StoreStats) {
 		zap.Uint64("store-id", stats.GetStoreId()))
 	r.Lock()
 	defer r.Unlock()
+	if smoothing := r.smoothingSource.get(); r.smoothing != smoothing {
+		// The smoothing windows are changed, so the history is dropped.
+		resetStoreTimeMedians(r.timeMedians, smoothing)
+		r.smoothing = smoothing
+	}
 	readQueryNum, writeQueryNum := core.GetReadQueryNum(stats.QueryStats), core.GetWriteQueryNum(stats.QueryStats)
 	r.timeMedians[StoreWriteBytes].Add(float64(stats.BytesWritten), interval)
 	r.timeMedians[StoreWriteKeys].Add(float64(stats.KeysWritten), interval)
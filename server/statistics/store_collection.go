@@ -122,6 +122,7 @@ func (s *storeStatistics) Observe(store *core.StoreInfo, stats *StoresStats) {
 	storeStatusGauge.WithLabelValues(storeAddress, id, "store_available").Set(float64(store.GetAvailable()))
 	storeStatusGauge.WithLabelValues(storeAddress, id, "store_used").Set(float64(store.GetUsedSize()))
 	storeStatusGauge.WithLabelValues(storeAddress, id, "store_capacity").Set(float64(store.GetCapacity()))
+	storeStatusGauge.WithLabelValues(storeAddress, id, "store_slow_score").Set(float64(store.GetSlowScore()))
 	slowTrend := store.GetSlowTrend()
 	if slowTrend != nil {
 		storeStatusGauge.WithLabelValues(storeAddress, id, "store_slow_trend_cause_value").Set(slowTrend.CauseValue)
@@ -251,6 +252,7 @@ func (s *storeStatistics) resetStoreStatistics(storeAddress string, id string) {
 		"store_available",
 		"store_used",
 		"store_capacity",
+		"store_slow_score",
 		"store_write_rate_bytes",
 		"store_read_rate_bytes",
 		"store_write_rate_keys",
@@ -44,3 +44,33 @@ func TestFilterUnhealtyStore(t *testing.T) {
 	re.NotNil(loads[4])
 	re.NotNil(loads[5])
 }
+
+func TestFlowSmoothingConfig(t *testing.T) {
+	re := require.New(t)
+	stats := NewStoresStats()
+	observe := func(bytesWritten uint64) {
+		stats.Observe(1, &pdpb.StoreStats{
+			StoreId:      1,
+			BytesWritten: bytesWritten,
+			Interval:     &pdpb.TimeInterval{StartTimestamp: 0, EndTimestamp: StoreHeartBeatReportInterval},
+		})
+	}
+	for i := 0; i < 3; i++ {
+		observe(100 * StoreHeartBeatReportInterval)
+	}
+	// The median filter ignores the burst.
+	observe(10000 * StoreHeartBeatReportInterval)
+	re.Equal(100.0, stats.GetRollingStoreStats(1).GetLoad(StoreWriteBytes))
+
+	// The history is dropped after the windows are changed, and the EMA reacts to the burst.
+	stats.SetFlowSmoothingConfig(FlowSmoothingConfig{AotSize: 1, StoreWindowSize: 5, HotPeerWindowSize: 5, Decay: 0.5})
+	observe(100 * StoreHeartBeatReportInterval)
+	re.Equal(100.0, stats.GetRollingStoreStats(1).GetLoad(StoreWriteBytes))
+	observe(100 * StoreHeartBeatReportInterval)
+	observe(10000 * StoreHeartBeatReportInterval)
+	re.Greater(stats.GetRollingStoreStats(1).GetLoad(StoreWriteBytes), 1000.0)
+
+	// The config of another cluster doesn't affect it.
+	NewStoresStats().SetFlowSmoothingConfig(DefaultFlowSmoothingConfig)
+	re.Equal(0.5, stats.smoothing.get().Decay)
+}
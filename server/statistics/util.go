@@ -16,6 +16,10 @@ package statistics
 
 import (
 	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/tikv/pd/pkg/movingaverage"
 )
 
 const (
@@ -31,6 +35,55 @@ const (
 	DefaultReadMfSize = 5
 )
 
+// FlowSmoothingConfig is the config of the windows which smooth the flow statistics.
+type FlowSmoothingConfig struct {
+	// AotSize is the number of report intervals averaged over time before being smoothed.
+	AotSize int
+	// StoreWindowSize is the size of the median filter which smooths the store flow.
+	StoreWindowSize int
+	// HotPeerWindowSize is the size of the median filter which smooths the hot peer flow.
+	HotPeerWindowSize int
+	// Decay is the decay of the EMA which replaces the median filters if it is in (0, 1).
+	Decay float64
+}
+
+// DefaultFlowSmoothingConfig is the default config of the flow smoothing windows.
+var DefaultFlowSmoothingConfig = FlowSmoothingConfig{
+	AotSize:           DefaultAotSize,
+	StoreWindowSize:   DefaultWriteMfSize,
+	HotPeerWindowSize: rollingWindowsSize,
+}
+
+// flowSmoothing holds the config of the flow smoothing windows of a cluster.
+// The existing statistics are reset when they are observed next time if the
+// windows are changed.
+type flowSmoothing struct {
+	cfg atomic.Value
+}
+
+func newFlowSmoothing() *flowSmoothing {
+	s := &flowSmoothing{}
+	s.cfg.Store(DefaultFlowSmoothingConfig)
+	return s
+}
+
+func (s *flowSmoothing) get() FlowSmoothingConfig {
+	return s.cfg.Load().(FlowSmoothingConfig)
+}
+
+func (s *flowSmoothing) set(cfg FlowSmoothingConfig) {
+	if s.get() != cfg {
+		s.cfg.Store(cfg)
+	}
+}
+
+func (c FlowSmoothingConfig) newTimeMedian(mfSize int, reportInterval time.Duration) *movingaverage.TimeMedian {
+	if c.Decay > 0 && c.Decay < 1 {
+		return movingaverage.NewTimeEMA(c.AotSize, c.Decay, reportInterval)
+	}
+	return movingaverage.NewTimeMedian(c.AotSize, mfSize, reportInterval)
+}
+
 func storeTag(id uint64) string {
 	return fmt.Sprintf("store-%d", id)
 }
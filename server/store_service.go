@@ -0,0 +1,94 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"google.golang.org/grpc"
+)
+
+// StoreServiceName is the name of the gRPC service getting the stores in
+// batches. Like the rule service, kvproto doesn't define the service, so its
+// messages are the Go structs below encoded in JSON.
+const StoreServiceName = "pd.Store"
+
+// BatchGetStoresRequest is the request of BatchGetStores.
+type BatchGetStoresRequest struct {
+	Header   *pdpb.RequestHeader `json:"header"`
+	StoreIDs []uint64            `json:"store_ids"`
+}
+
+// BatchGetStoresResponse is the response of BatchGetStores.
+type BatchGetStoresResponse struct {
+	Header *pdpb.ResponseHeader `json:"header"`
+	// Stores are in the order of the IDs in the request, and the ones not
+	// found are nil.
+	Stores []*metapb.Store `json:"stores"`
+}
+
+// StoreServer wraps GrpcServer to provide the store service.
+type StoreServer struct {
+	*GrpcServer
+}
+
+// RegisterStoreServer registers the store service to the gRPC server.
+func RegisterStoreServer(gs *grpc.Server, srv *StoreServer) {
+	gs.RegisterService(&storeServiceDesc, srv)
+}
+
+var storeServiceDesc = grpc.ServiceDesc{
+	ServiceName: StoreServiceName,
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "BatchGetStores",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				request := &BatchGetStoresRequest{}
+				if err := dec(request); err != nil {
+					return nil, err
+				}
+				return srv.(*StoreServer).BatchGetStores(ctx, request)
+			},
+		},
+	},
+}
+
+// BatchGetStores returns the stores of the IDs, which saves the round trips of
+// getting a large number of stores one by one.
+func (s *StoreServer) BatchGetStores(ctx context.Context, request *BatchGetStoresRequest) (*BatchGetStoresResponse, error) {
+	rc, header, err := s.checkClusterRequest(ctx, request.Header)
+	if err != nil {
+		return nil, err
+	}
+	if header != nil {
+		return &BatchGetStoresResponse{Header: header}, nil
+	}
+	if len(request.StoreIDs) > maxBatchGetSize {
+		return &BatchGetStoresResponse{Header: s.invalidValue(fmt.Sprintf("too many store IDs, the max is %d", maxBatchGetSize))}, nil
+	}
+	resp := &BatchGetStoresResponse{Header: s.header(), Stores: make([]*metapb.Store, 0, len(request.StoreIDs))}
+	for _, id := range request.StoreIDs {
+		var meta *metapb.Store
+		if store := rc.GetStore(id); store != nil {
+			meta = store.GetMeta()
+		}
+		resp.Stores = append(resp.Stores, meta)
+	}
+	return resp, nil
+}
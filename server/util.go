@@ -24,7 +24,9 @@ import (
 	"github.com/pingcap/errors"
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/log"
+	"github.com/tikv/pd/pkg/audit"
 	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/pkg/rbac"
 	"github.com/tikv/pd/pkg/utils/apiutil"
 	"github.com/tikv/pd/pkg/versioninfo"
 	"github.com/tikv/pd/server/config"
@@ -122,6 +124,7 @@ func combineBuilderServerHTTPService(ctx context.Context, svr *Server, serviceBu
 	apiService := negroni.New()
 	recovery := negroni.NewRecovery()
 	apiService.Use(recovery)
+	apiService.Use(negroni.HandlerFunc(svr.securityMiddleware))
 	router := mux.NewRouter()
 
 	for _, build := range serviceBuilders {
@@ -149,10 +152,82 @@ func combineBuilderServerHTTPService(ctx context.Context, svr *Server, serviceBu
 				router.Path("/pd/ping").Handler(handler)
 			}
 		} else {
-			userHandlers[pathPrefix] = handler
+			// The extension services, e.g. the API v2 and the resource manager,
+			// are checked by the same security middleware as the core API.
+			service := negroni.New(negroni.HandlerFunc(svr.securityMiddleware))
+			service.UseHandler(handler)
+			userHandlers[pathPrefix] = service
 		}
 	}
 	apiService.UseHandler(router)
 	userHandlers[pdAPIPrefix] = apiService
 	return userHandlers, nil
 }
+
+// securityMiddleware checks the source address of the HTTP request by the
+// network ACLs and its role if the role-based access control is enabled, and
+// records the decision and the mutating call into the security audit log.
+func (s *Server) securityMiddleware(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if err := s.CheckNetworkACL(rbac.ListenerHTTP, r.URL.Path, r.RemoteAddr); err != nil {
+		event := newSecurityEvent(audit.SecurityEventNetwork, "HTTP", r.Method, rbac.Credential{}, nil, err)
+		event.Path, event.RemoteAddr = r.URL.Path, r.RemoteAddr
+		s.securityLogger.Log(event)
+		log.Warn("HTTP request is denied by network ACL",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+			zap.String("remote-addr", r.RemoteAddr),
+			errs.ZapError(err))
+		http.Error(w, err.Error(), rbac.HTTPStatus(err))
+		return
+	}
+	if rbac.IsPublicHTTP(r) {
+		next(w, r)
+		return
+	}
+	cred := rbac.HTTPCredential(r)
+	var identity *rbac.Identity
+	if s.IsRBACEnabled() {
+		var err error
+		identity, err = s.Authorize(cred, rbac.RequiredRoleForHTTP(r))
+		event := newSecurityEvent(audit.SecurityEventAuthentication, "HTTP", r.Method, cred, identity, err)
+		event.Path, event.RemoteAddr = r.URL.Path, r.RemoteAddr
+		s.securityLogger.Log(event)
+		if err != nil {
+			log.Warn("HTTP request is denied",
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.String("remote-addr", r.RemoteAddr),
+				errs.ZapError(err))
+			http.Error(w, err.Error(), rbac.HTTPStatus(err))
+			return
+		}
+	}
+	next(w, r)
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return
+	}
+	event := newSecurityEvent(audit.SecurityEventMutation, "HTTP", r.Method, cred, identity, nil)
+	event.Path, event.RemoteAddr = r.URL.Path, r.RemoteAddr
+	if rw, ok := w.(negroni.ResponseWriter); ok {
+		event.Status = rw.Status()
+	}
+	s.securityLogger.Log(event)
+}
+
+func newSecurityEvent(typ, protocol, method string, cred rbac.Credential, identity *rbac.Identity, err error) *audit.SecurityEvent {
+	event := &audit.SecurityEvent{
+		Type:     typ,
+		Protocol: protocol,
+		Method:   method,
+		Identity: cred.CN,
+		Allowed:  err == nil,
+	}
+	if identity != nil {
+		event.Identity, event.Role = identity.Name, string(identity.Role)
+	}
+	if err != nil {
+		event.Reason = err.Error()
+	}
+	return event
+}
@@ -0,0 +1,75 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/tikv/pd/pkg/core"
+)
+
+func (suite *clientTestSuite) TestWatchBuckets() {
+	re := suite.Require()
+	rc := suite.srv.GetRaftCluster()
+	keys := [][]byte{[]byte("\xfebucket1"), []byte("\xfebucket2"), []byte("\xfebucket3"), []byte("\xfebucket4")}
+	regions := make([]*metapb.Region, 0, len(keys)-1)
+	for i := 0; i < len(keys)-1; i++ {
+		region := &metapb.Region{
+			Id:          regionIDAllocator.alloc(),
+			RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 10},
+			StartKey:    keys[i],
+			EndKey:      keys[i+1],
+			Peers:       peers,
+		}
+		re.NoError(rc.HandleRegionHeartbeat(core.NewRegionInfo(region, peers[0])))
+		regions = append(regions, region)
+	}
+	reportBuckets := func(region *metapb.Region, version uint64) {
+		re.NoError(rc.HandleReportBuckets(&metapb.Buckets{
+			RegionId: region.GetId(),
+			Version:  version,
+			Keys:     [][]byte{region.GetStartKey(), region.GetEndKey()},
+		}))
+	}
+	reportBuckets(regions[0], 1)
+
+	ctx, cancel := context.WithCancel(suite.ctx)
+	defer cancel()
+	// Watch the first two regions.
+	watchChan, err := suite.client.WatchBuckets(ctx, keys[0], keys[2])
+	re.NoError(err)
+	changes := <-watchChan
+	re.True(changes.Snapshot)
+	re.Len(changes.Buckets, 1)
+	re.Equal(regions[0].GetId(), changes.Buckets[0].GetRegionId())
+	re.Equal(uint64(1), changes.Buckets[0].GetVersion())
+
+	reportBuckets(regions[1], 1)
+	changes = <-watchChan
+	re.False(changes.Snapshot)
+	re.Len(changes.Buckets, 1)
+	re.Equal(regions[1].GetId(), changes.Buckets[0].GetRegionId())
+
+	// The stale buckets and the ones out of the range are not sent.
+	reportBuckets(regions[0], 1)
+	reportBuckets(regions[2], 1)
+	reportBuckets(regions[0], 2)
+	changes = <-watchChan
+	re.Len(changes.Buckets, 1)
+	re.Equal(regions[0].GetId(), changes.Buckets[0].GetRegionId())
+	re.Equal(uint64(2), changes.Buckets[0].GetVersion())
+	re.Equal([][]byte{keys[0], keys[1]}, changes.Buckets[0].GetKeys())
+}
@@ -19,8 +19,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math"
+	"os"
 	"path"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"sync"
@@ -30,6 +33,7 @@ import (
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	pd "github.com/tikv/pd/client"
@@ -531,6 +535,73 @@ func TestCustomTimeout(t *testing.T) {
 	re.Less(time.Since(start), 2*time.Second)
 }
 
+func TestDiscoveryCache(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	re.NoError(err)
+	defer cluster.Destroy()
+
+	endpoints := runServer(re, cluster)
+	cachePath := filepath.Join(t.TempDir(), "pd-members.json")
+	cli := setupCli(re, ctx, endpoints, pd.WithDiscoveryCache(cachePath))
+	cli.Close()
+	_, err = os.Stat(cachePath)
+	re.NoError(err)
+
+	// The client bootstraps from the cache if the configured endpoints are
+	// unreachable.
+	unreachable := []string{"127.0.0.1:1"}
+	cli = setupCli(re, ctx, unreachable, pd.WithDiscoveryCache(cachePath), pd.WithMaxErrorRetry(1))
+	defer cli.Close()
+	re.Equal(cluster.GetServer(cluster.GetLeader()).GetConfig().ClientUrls, cli.GetLeaderAddr())
+	_, _, err = cli.GetTS(ctx)
+	re.NoError(err)
+
+	// It fails without the cache.
+	_, err = pd.NewClientWithContext(ctx, unreachable, pd.SecurityOption{}, pd.WithMaxErrorRetry(1))
+	re.Error(err)
+}
+
+func TestTSOFailover(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	primary, err := tests.NewTestCluster(ctx, 1)
+	re.NoError(err)
+	defer primary.Destroy()
+	secondary, err := tests.NewTestCluster(ctx, 1)
+	re.NoError(err)
+	defer secondary.Destroy()
+
+	endpoints := runServer(re, primary)
+	secondaryEndpoints := runServer(re, secondary)
+	cli := setupCli(re, ctx, endpoints, pd.WithTSOFailover(secondaryEndpoints, pd.SecurityOption{}, 100*time.Millisecond))
+	defer cli.Close()
+	physical, logical, err := cli.GetTS(ctx)
+	re.NoError(err)
+	lastTS := tsoutil.ComposeTS(physical, logical)
+
+	// The timestamps come from the secondary cluster once the primary one is
+	// unavailable, and they are still increasing. The requests may fail before
+	// the secondary TSO catches up with the returned timestamps.
+	re.NoError(primary.StopAll())
+	testutil.Eventually(re, func() bool {
+		physical, logical, err := cli.GetTS(ctx)
+		if err != nil {
+			return false
+		}
+		ts := tsoutil.ComposeTS(physical, logical)
+		re.Greater(ts, lastTS)
+		lastTS = ts
+		return true
+	})
+	physical, logical, err = cli.GetTS(ctx)
+	re.NoError(err)
+	re.Greater(tsoutil.ComposeTS(physical, logical), lastTS)
+}
+
 func TestGetRegionFromFollowerClient(t *testing.T) {
 	re := require.New(t)
 	ctx, cancel := context.WithCancel(context.Background())
@@ -556,6 +627,190 @@ func TestGetRegionFromFollowerClient(t *testing.T) {
 	re.NotNil(r)
 }
 
+func TestFollowerRead(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 2, func(conf *config.Config, serverName string) { conf.PDServerCfg.UseRegionStorage = true })
+	re.NoError(err)
+	defer cluster.Destroy()
+	endpoints := runServer(re, cluster)
+	leaderServer := cluster.GetServer(cluster.GetLeader())
+	region := core.NewRegionInfo(&metapb.Region{
+		Id:          100,
+		StartKey:    []byte("a"),
+		EndKey:      []byte("b"),
+		RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+		Peers:       []*metapb.Peer{{Id: 101, StoreId: 1}},
+	}, &metapb.Peer{Id: 101, StoreId: 1})
+	re.NoError(leaderServer.GetRaftCluster().HandleRegionHeartbeat(region))
+	followerServer := cluster.GetServer(cluster.GetFollower())
+	testutil.Eventually(re, func() bool {
+		_, err := followerServer.GetServer().CheckFollowerRead(time.Minute)
+		basicCluster := followerServer.GetServer().GetBasicCluster()
+		return err == nil && basicCluster.GetRegion(100) != nil && basicCluster.GetStore(1) != nil
+	})
+
+	checkRead := func(cli pd.Client) {
+		r, err := cli.GetRegionByID(ctx, 100)
+		re.NoError(err)
+		re.Equal(region.GetMeta(), r.Meta)
+		r, err = cli.GetRegion(ctx, []byte("a"))
+		re.NoError(err)
+		re.Equal(region.GetMeta(), r.Meta)
+		regions, err := cli.ScanRegions(ctx, []byte("a"), []byte("b"), 10)
+		re.NoError(err)
+		re.Len(regions, 1)
+		store, err := cli.GetStore(ctx, 1)
+		re.NoError(err)
+		re.Equal(uint64(1), store.GetId())
+	}
+
+	cli := setupCli(re, ctx, endpoints, pd.WithFollowerRead(time.Minute))
+	defer cli.Close()
+	served, fallback := followerReadCount(re, "served"), followerReadCount(re, "fallback")
+	checkRead(cli)
+	re.Equal(served+4, followerReadCount(re, "served"))
+	re.Equal(fallback, followerReadCount(re, "fallback"))
+
+	// No follower can catch up with the staleness, so the requests fall back to the leader.
+	re.NoError(cli.UpdateOption(pd.MaxFollowerReadStaleness, time.Nanosecond))
+	checkRead(cli)
+	re.Equal(served+4, followerReadCount(re, "served"))
+	re.Equal(fallback+4, followerReadCount(re, "fallback"))
+
+	// The requests are sent to the leader directly if the follower read is disabled.
+	re.NoError(cli.UpdateOption(pd.MaxFollowerReadStaleness, time.Duration(0)))
+	checkRead(cli)
+	re.Equal(served+4, followerReadCount(re, "served"))
+	re.Equal(fallback+4, followerReadCount(re, "fallback"))
+	re.Error(cli.UpdateOption(pd.MaxFollowerReadStaleness, 1))
+	re.Error(cli.UpdateOption(pd.MaxFollowerReadStaleness, -time.Second))
+}
+
+// followerReadCount returns the number of the follower reads with the result.
+func followerReadCount(re *require.Assertions, result string) float64 {
+	return clientCounterValue(re, "pd_client_request_follower_read_total", "result", result)
+}
+
+// clientCounterValue returns the value of the client counter with the label.
+func clientCounterValue(re *require.Assertions, name, labelName, labelValue string) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	re.NoError(err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == labelName && label.GetValue() == labelValue {
+					return metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func TestCircuitBreakerAndHedging(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 3, func(conf *config.Config, serverName string) { conf.PDServerCfg.UseRegionStorage = true })
+	re.NoError(err)
+	defer cluster.Destroy()
+	endpoints := runServer(re, cluster)
+	leader := cluster.GetLeader()
+	var followers []*tests.TestServer
+	for name, s := range cluster.GetServers() {
+		if name != leader {
+			followers = append(followers, s)
+		}
+	}
+	re.Len(followers, 2)
+	testutil.Eventually(re, func() bool {
+		_, err := followers[0].GetServer().CheckFollowerRead(time.Minute)
+		return err == nil && followers[0].GetServer().GetBasicCluster().GetStore(1) != nil
+	})
+
+	// The hedged requests are sent if there is no response after the delay.
+	cli := setupCli(re, ctx, endpoints, pd.WithHedging(time.Nanosecond))
+	defer cli.Close()
+	sent := clientCounterValue(re, "pd_client_request_hedged_requests_total", "result", "sent")
+	for i := 0; i < 10; i++ {
+		_, err := cli.GetStore(ctx, 1)
+		re.NoError(err)
+	}
+	re.Greater(clientCounterValue(re, "pd_client_request_hedged_requests_total", "result", "sent"), sent)
+
+	// The unavailable follower is not tried once its circuit is open.
+	cli2 := setupCli(re, ctx, endpoints, pd.WithFollowerRead(time.Minute), pd.WithCircuitBreaker(1, time.Minute))
+	defer cli2.Close()
+	stopped := followers[1].GetConfig().AdvertiseClientUrls
+	re.NoError(followers[1].Stop())
+	for i := 0; i < 20; i++ {
+		_, err := cli2.GetStore(ctx, 1)
+		re.NoError(err)
+	}
+	re.Equal(float64(1), clientCounterValue(re, "pd_client_request_circuit_breaker_trips_total", "host", stopped))
+	served := followerReadCount(re, "served")
+	for i := 0; i < 10; i++ {
+		_, err := cli2.GetStore(ctx, 1)
+		re.NoError(err)
+	}
+	re.Equal(served+10, followerReadCount(re, "served"))
+}
+
+type recordingInstrumentation struct {
+	pd.NoopInstrumentation
+	sync.Mutex
+	rpcs     map[string]int
+	tsoWaits int
+}
+
+func (i *recordingInstrumentation) StartRPC(ctx context.Context, method string) (context.Context, func(err error)) {
+	i.Lock()
+	defer i.Unlock()
+	i.rpcs[method]++
+	return ctx, func(error) {}
+}
+
+func (i *recordingInstrumentation) InjectTraceContext(context.Context) map[string]string {
+	return map[string]string{"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"}
+}
+
+func (i *recordingInstrumentation) ObserveTSOWait(_ context.Context, dcLocation string, _ time.Duration, err error) {
+	i.Lock()
+	defer i.Unlock()
+	if dcLocation == tso.GlobalDCLocation && err == nil {
+		i.tsoWaits++
+	}
+}
+
+func TestInstrumentation(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	re.NoError(err)
+	defer cluster.Destroy()
+	endpoints := runServer(re, cluster)
+
+	instrumentation := &recordingInstrumentation{rpcs: make(map[string]int)}
+	cli := setupCli(re, ctx, endpoints, pd.WithInstrumentation(instrumentation))
+	defer cli.Close()
+	_, err = cli.GetStore(ctx, 1)
+	re.NoError(err)
+	_, _, err = cli.GetTS(ctx)
+	re.NoError(err)
+
+	instrumentation.Lock()
+	defer instrumentation.Unlock()
+	re.Positive(instrumentation.rpcs["/pdpb.PD/GetMembers"])
+	re.Equal(1, instrumentation.rpcs["/pdpb.PD/GetStore"])
+	re.Equal(1, instrumentation.tsoWaits)
+}
+
 // case 1: unreachable -> normal
 func TestGetTsoFromFollowerClient1(t *testing.T) {
 	re := require.New(t)
@@ -1080,6 +1335,52 @@ func (suite *clientTestSuite) TestScanRegions() {
 	check([]byte{1}, []byte{6}, 2, regions[1:3])
 }
 
+func (suite *clientTestSuite) TestScanRegionsStream() {
+	re := suite.Require()
+	regionLen := 5
+	regions := make([]*metapb.Region, 0, regionLen)
+	for i := 0; i < regionLen; i++ {
+		r := &metapb.Region{
+			Id: regionIDAllocator.alloc(),
+			RegionEpoch: &metapb.RegionEpoch{
+				ConfVer: 1,
+				Version: 1,
+			},
+			StartKey: []byte{byte(0x40 + i)},
+			EndKey:   []byte{byte(0x40 + i + 1)},
+			Peers:    peers,
+		}
+		re.NoError(suite.srv.GetRaftCluster().HandleRegionHeartbeat(core.NewRegionInfo(r, peers[0])))
+		regions = append(regions, r)
+	}
+
+	check := func(start, end []byte, limit, batchSize int, expect ...[]*metapb.Region) {
+		stream, err := suite.client.ScanRegionsStream(context.Background(), start, end, limit, batchSize)
+		re.NoError(err)
+		defer stream.Close()
+		for _, batch := range expect {
+			scanRegions, err := stream.Recv()
+			re.NoError(err)
+			re.Len(scanRegions, len(batch))
+			for i := range batch {
+				re.Equal(batch[i], scanRegions[i].Meta)
+				re.Equal(batch[i].Peers[0], scanRegions[i].Leader)
+			}
+		}
+		_, err = stream.Recv()
+		re.Equal(io.EOF, err)
+	}
+
+	check([]byte{0x40}, []byte{0x45}, 0, 2, regions[0:2], regions[2:4], regions[4:5])
+	check([]byte{0x41}, []byte{0x45}, 3, 2, regions[1:3], regions[3:4])
+	check([]byte{0x40}, []byte{0x44}, 0, 0, regions[0:4])
+	check([]byte{0x42}, []byte{0x42, 0x01}, 0, 2, regions[2:3])
+	check([]byte{0x40}, []byte{0x40}, 0, 2)
+
+	_, err := suite.client.ScanRegionsStream(context.Background(), nil, nil, -1, 0)
+	re.Error(err)
+}
+
 func (suite *clientTestSuite) TestGetRegionByID() {
 	regionID := regionIDAllocator.alloc()
 	region := &metapb.Region{
@@ -1109,6 +1410,43 @@ func (suite *clientTestSuite) TestGetRegionByID() {
 	})
 }
 
+func (suite *clientTestSuite) TestBatchGet() {
+	re := suite.Require()
+	regions := make([]*metapb.Region, 0, 2)
+	for i := 0; i < 2; i++ {
+		r := &metapb.Region{
+			Id: regionIDAllocator.alloc(),
+			RegionEpoch: &metapb.RegionEpoch{
+				ConfVer: 1,
+				Version: 1,
+			},
+			StartKey: []byte{byte(0x50 + i)},
+			EndKey:   []byte{byte(0x50 + i + 1)},
+			Peers:    peers,
+		}
+		re.NoError(suite.srv.GetRaftCluster().HandleRegionHeartbeat(core.NewRegionInfo(r, peers[0])))
+		regions = append(regions, r)
+	}
+	missingID := regionIDAllocator.alloc()
+	batchRegions, err := suite.client.BatchGetRegionsByID(context.Background(), []uint64{regions[1].GetId(), missingID, regions[0].GetId()})
+	re.NoError(err)
+	re.Len(batchRegions, 3)
+	re.Equal(regions[1], batchRegions[0].Meta)
+	re.Equal(peers[0], batchRegions[0].Leader)
+	re.Nil(batchRegions[1])
+	re.Equal(regions[0], batchRegions[2].Meta)
+	batchRegions, err = suite.client.BatchGetRegionsByID(context.Background(), nil)
+	re.NoError(err)
+	re.Empty(batchRegions)
+
+	batchStores, err := suite.client.BatchGetStores(context.Background(), []uint64{stores[1].GetId(), 1000, stores[0].GetId()})
+	re.NoError(err)
+	re.Len(batchStores, 3)
+	re.Equal(stores[1].GetId(), batchStores[0].GetId())
+	re.Nil(batchStores[1])
+	re.Equal(stores[0].GetId(), batchStores[2].GetId())
+}
+
 func (suite *clientTestSuite) TestGetStore() {
 	cluster := suite.srv.GetRaftCluster()
 	suite.NotNil(cluster)
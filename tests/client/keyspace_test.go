@@ -15,11 +15,16 @@
 package client_test
 
 import (
+	"encoding/binary"
 	"fmt"
 	"time"
 
 	"github.com/pingcap/kvproto/pkg/keyspacepb"
+	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/stretchr/testify/require"
+	pd "github.com/tikv/pd/client"
+	"github.com/tikv/pd/pkg/codec"
+	"github.com/tikv/pd/pkg/core"
 	"github.com/tikv/pd/pkg/slice"
 	"github.com/tikv/pd/server"
 	"github.com/tikv/pd/server/keyspace"
@@ -187,3 +192,99 @@ func (suite *clientTestSuite) TestUpdateKeyspaceState() {
 		}
 	}
 }
+
+func (suite *clientTestSuite) TestKeyspaceScopedClient() {
+	re := suite.Require()
+	meta := mustMakeTestKeyspaces(re, suite.srv, 50, 1)[0]
+	cli, err := pd.NewKeyspaceScopedClient(suite.ctx, suite.srv.GetEndpoints(), pd.SecurityOption{}, meta.GetName(), pd.KeyModeTxn)
+	re.NoError(err)
+	defer cli.Close()
+	re.Equal(meta, cli.GetKeyspace())
+
+	// The keyspace must exist and be enabled.
+	_, err = pd.NewKeyspaceScopedClient(suite.ctx, suite.srv.GetEndpoints(), pd.SecurityOption{}, "non-existing keyspace", pd.KeyModeTxn)
+	re.Error(err)
+	disabled := mustCreateKeyspaceAtState(re, suite.srv, 51, keyspacepb.KeyspaceState_DISABLED)
+	_, err = pd.NewKeyspaceScopedClient(suite.ctx, suite.srv.GetEndpoints(), pd.SecurityOption{}, disabled.GetName(), pd.KeyModeTxn)
+	re.Error(err)
+
+	physical, logical, err := cli.GetTS(suite.ctx)
+	re.NoError(err)
+	re.NotZero(physical + logical)
+
+	// The regions before, at the start and at the end of the keyspace.
+	encodeKey := func(id uint32, key string) []byte {
+		prefix := make([]byte, 4)
+		binary.BigEndian.PutUint32(prefix, id)
+		prefix[0] = 'x'
+		return codec.EncodeBytes(append(prefix, key...))
+	}
+	boundaries := [][]byte{encodeKey(meta.GetId()-1, ""), encodeKey(meta.GetId(), ""), encodeKey(meta.GetId(), "b"), encodeKey(meta.GetId()+1, "")}
+	regions := make([]*metapb.Region, 0, len(boundaries)-1)
+	for i := 0; i < len(boundaries)-1; i++ {
+		region := &metapb.Region{
+			Id:          regionIDAllocator.alloc(),
+			RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+			StartKey:    boundaries[i],
+			EndKey:      boundaries[i+1],
+			Peers:       peers,
+		}
+		regions = append(regions, region)
+		re.NoError(suite.srv.GetRaftCluster().HandleRegionHeartbeat(core.NewRegionInfo(region, peers[0])))
+	}
+
+	region, err := cli.GetRegion(suite.ctx, []byte("a"))
+	re.NoError(err)
+	re.Equal(regions[1].GetId(), region.Meta.GetId())
+	re.Empty(region.Meta.GetStartKey())
+	re.Equal([]byte("b"), region.Meta.GetEndKey())
+	region, err = cli.GetRegion(suite.ctx, []byte("c"))
+	re.NoError(err)
+	re.Equal(regions[2].GetId(), region.Meta.GetId())
+	re.Equal([]byte("b"), region.Meta.GetStartKey())
+	re.Empty(region.Meta.GetEndKey())
+	// The region isn't changed by the scoped client.
+	re.Equal(boundaries[2], regions[2].GetStartKey())
+
+	region, err = cli.GetPrevRegion(suite.ctx, []byte("c"))
+	re.NoError(err)
+	re.Equal(regions[1].GetId(), region.Meta.GetId())
+	// The regions out of the keyspace are invisible.
+	region, err = cli.GetPrevRegion(suite.ctx, []byte("a"))
+	re.NoError(err)
+	re.Nil(region)
+	region, err = cli.GetRegionByID(suite.ctx, regions[0].GetId())
+	re.NoError(err)
+	re.Nil(region)
+	region, err = cli.GetRegionByID(suite.ctx, regions[2].GetId())
+	re.NoError(err)
+	re.Equal([]byte("b"), region.Meta.GetStartKey())
+
+	scanned, err := cli.ScanRegions(suite.ctx, nil, nil, 10)
+	re.NoError(err)
+	re.Len(scanned, 2)
+	re.Equal(regions[1].GetId(), scanned[0].Meta.GetId())
+	re.Equal(regions[2].GetId(), scanned[1].Meta.GetId())
+	scanned, err = cli.ScanRegions(suite.ctx, []byte("c"), []byte("d"), 10)
+	re.NoError(err)
+	re.Len(scanned, 1)
+	re.Equal(regions[2].GetId(), scanned[0].Meta.GetId())
+
+	// The GC safepoints are isolated from the ones of the cluster.
+	minSafePoint, err := cli.UpdateServiceGCSafePoint(suite.ctx, "service", 100, 10)
+	re.NoError(err)
+	re.Equal(uint64(10), minSafePoint)
+	safePoint, err := cli.UpdateGCSafePoint(suite.ctx, 20)
+	re.NoError(err)
+	re.Equal(uint64(20), safePoint)
+	safePoint, err = cli.UpdateGCSafePoint(suite.ctx, 5)
+	re.NoError(err)
+	re.Equal(uint64(20), safePoint)
+	loaded, err := suite.srv.GetKeyspaceSafePointManager().LoadGCSafePoint(meta.GetId())
+	re.NoError(err)
+	re.Equal(uint64(20), loaded)
+	// Removing the service safepoint leaves the GC safepoint as the min one.
+	minSafePoint, err = cli.UpdateServiceGCSafePoint(suite.ctx, "service", 0, 0)
+	re.NoError(err)
+	re.Equal(uint64(20), minSafePoint)
+}
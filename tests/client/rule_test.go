@@ -0,0 +1,82 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"encoding/hex"
+
+	pd "github.com/tikv/pd/client"
+)
+
+func (suite *clientTestSuite) TestRules() {
+	re := suite.Require()
+	ctx, cancel := context.WithCancel(suite.ctx)
+	defer cancel()
+	watchChan, err := suite.client.WatchRules(ctx)
+	re.NoError(err)
+	changes := <-watchChan
+	re.True(changes.Snapshot)
+	re.NotEmpty(changes.PlacementRules)
+
+	rule := &pd.PlacementRule{
+		GroupID:     "test",
+		ID:          "rule",
+		StartKeyHex: hex.EncodeToString([]byte("a")),
+		EndKeyHex:   hex.EncodeToString([]byte("b")),
+		Role:        "voter",
+		Count:       1,
+	}
+	re.NoError(suite.client.SetPlacementRules(suite.ctx, []*pd.PlacementRule{rule}))
+	rules, err := suite.client.GetPlacementRules(suite.ctx, "test")
+	re.NoError(err)
+	re.Len(rules, 1)
+	re.Equal(rule.StartKeyHex, rules[0].StartKeyHex)
+	re.Equal(1, rules[0].Count)
+	changes = <-watchChan
+	re.False(changes.Snapshot)
+	re.Len(changes.PlacementRules, 1)
+	re.Equal("rule", changes.PlacementRules[0].ID)
+	// The invalid rule is rejected.
+	re.Error(suite.client.SetPlacementRules(suite.ctx, []*pd.PlacementRule{{GroupID: "test", ID: "invalid", Role: "voter", Count: -1}}))
+
+	re.NoError(suite.client.DeletePlacementRule(suite.ctx, "test", "rule"))
+	rules, err = suite.client.GetPlacementRules(suite.ctx, "test")
+	re.NoError(err)
+	re.Empty(rules)
+	changes = <-watchChan
+	re.Equal([]pd.PlacementRuleKey{{GroupID: "test", ID: "rule"}}, changes.DeletedPlacementRules)
+
+	labelRule := &pd.LabelRule{
+		ID:       "label",
+		Labels:   []pd.RegionLabel{{Key: "k", Value: "v"}},
+		RuleType: "key-range",
+		Data:     []map[string]string{{"start_key": hex.EncodeToString([]byte("a")), "end_key": hex.EncodeToString([]byte("b"))}},
+	}
+	re.NoError(suite.client.PatchLabelRules(suite.ctx, &pd.LabelRulePatch{SetRules: []*pd.LabelRule{labelRule}}))
+	labelRules, err := suite.client.GetLabelRules(suite.ctx, "label")
+	re.NoError(err)
+	re.Len(labelRules, 1)
+	re.Equal(labelRule.Labels, labelRules[0].Labels)
+	changes = <-watchChan
+	re.Len(changes.LabelRules, 1)
+	re.Equal("label", changes.LabelRules[0].ID)
+	re.NoError(suite.client.PatchLabelRules(suite.ctx, &pd.LabelRulePatch{DeleteRules: []string{"label"}}))
+	changes = <-watchChan
+	re.Equal([]string{"label"}, changes.DeletedLabelRules)
+	labelRules, err = suite.client.GetLabelRules(suite.ctx, "label")
+	re.NoError(err)
+	re.Empty(labelRules)
+}
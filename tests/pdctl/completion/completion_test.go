@@ -15,9 +15,13 @@
 package completion_test
 
 import (
+	"context"
+	"strings"
 	"testing"
 
+	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/tests"
 	"github.com/tikv/pd/tests/pdctl"
 	pdctlCmd "github.com/tikv/pd/tools/pd-ctl/pdctl"
 )
@@ -36,3 +40,46 @@ func TestCompletion(t *testing.T) {
 	_, err = pdctl.ExecuteCommand(cmd, args...)
 	re.NoError(err)
 }
+
+func TestCompletionWithClusterValues(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	re.NoError(err)
+	defer cluster.Destroy()
+	re.NoError(cluster.RunInitialServers())
+	re.NotEmpty(cluster.WaitLeader())
+	server := cluster.GetServer(cluster.GetLeader())
+	re.NoError(server.BootstrapCluster())
+	pdAddr := cluster.GetConfig().GetClientURL()
+	for _, id := range []uint64{1, 12, 2} {
+		pdctl.MustPutStore(re, server.GetServer(), &metapb.Store{
+			Id:        id,
+			State:     metapb.StoreState_Up,
+			NodeState: metapb.NodeState_Serving,
+		})
+	}
+
+	complete := func(args ...string) []string {
+		cmd := pdctlCmd.GetRootCmd()
+		output, err := pdctl.ExecuteCommand(cmd, append([]string{"__complete", "-u", pdAddr}, args...)...)
+		re.NoError(err)
+		// The completions are followed by the directive line starting with ":".
+		var completions []string
+		for _, line := range strings.Split(string(output), "\n") {
+			if strings.HasPrefix(line, ":") {
+				break
+			}
+			completions = append(completions, line)
+		}
+		return completions
+	}
+	re.ElementsMatch([]string{"1", "12"}, complete("store", "delete", "1"))
+	re.ElementsMatch([]string{"1", "12", "2"}, complete("store", "weight", ""))
+	re.Empty(complete("store", "weight", "1", ""))
+	re.ElementsMatch([]string{"12", "2"}, complete("job", "drain", "1", ""))
+	re.Contains(complete("scheduler", "remove", "balance-"), "balance-leader-scheduler")
+	re.Contains(complete("config", "placement-rules", "rule-group", "show", ""), "pd")
+	re.Contains(complete("config", "placement-rules", "show", "--group", ""), "pd")
+}
@@ -17,8 +17,12 @@ package config_test
 import (
 	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -780,6 +784,66 @@ func TestPDServerConfig(t *testing.T) {
 	re.Equal(int(3), conf.FlowRoundByDigit)
 }
 
+func TestConfigDiff(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	re.NoError(err)
+	err = cluster.RunInitialServers()
+	re.NoError(err)
+	cluster.WaitLeader()
+	pdAddr := cluster.GetConfig().GetClientURL()
+	cmd := pdctlCmd.GetRootCmd()
+
+	storeStatus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"coprocessor":{"region-max-size":"144MiB","region-split-size":"96MiB"}}`))
+	}))
+	defer storeStatus.Close()
+	store := &metapb.Store{
+		Id:            1,
+		State:         metapb.StoreState_Up,
+		StatusAddress: strings.TrimPrefix(storeStatus.URL, "http://"),
+		LastHeartbeat: time.Now().UnixNano(),
+	}
+	leaderServer := cluster.GetServer(cluster.GetLeader())
+	re.NoError(leaderServer.BootstrapCluster())
+	pdctl.MustPutStore(re, leaderServer.GetServer(), store)
+	defer cluster.Destroy()
+
+	dir := t.TempDir()
+	writeBaseline := func(name, content string) string {
+		fname := filepath.Join(dir, name)
+		re.NoError(os.WriteFile(fname, []byte(content), 0600))
+		return fname
+	}
+
+	// matched baseline, the durations and sizes are compared by value.
+	fname := writeBaseline("match.toml", `
+[schedule]
+leader-schedule-limit = 4
+max-store-down-time = "30m"
+[replication]
+max-replicas = 3
+[store.coprocessor]
+region-split-size = "96MB"
+`)
+	output, err := pdctl.ExecuteCommand(cmd, "-u", pdAddr, "config", "diff", fname)
+	re.NoError(err)
+	re.Contains(string(output), "No drift")
+
+	// drifted baseline
+	fname = writeBaseline("drift.json", `{"schedule":{"leader-schedule-limit":8,"unknown-item":1},"store":{"coprocessor":{"region-max-size":"256MiB"}}}`)
+	output, err = pdctl.ExecuteCommand(cmd, "-u", pdAddr, "config", "diff", fname)
+	re.Error(err)
+	re.Contains(string(output), "- schedule.leader-schedule-limit = 8")
+	re.Contains(string(output), "+ schedule.leader-schedule-limit = 4")
+	re.Contains(string(output), "+ schedule.unknown-item (not found)")
+	re.Contains(string(output), "+++ store 1")
+	re.Contains(string(output), "+ coprocessor.region-max-size = \"144MiB\"")
+	re.Contains(string(output), "3 item(s) drifted")
+}
+
 func assertBundles(re *require.Assertions, a, b []placement.GroupBundle) {
 	re.Len(b, len(a))
 	for i := 0; i < len(a); i++ {
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/pingcap/log"
@@ -67,3 +68,54 @@ func TestSendAndGetComponent(t *testing.T) {
 	re.NoError(err)
 	re.Equal("pdctl\n", string(output))
 }
+
+func TestOutputFormat(t *testing.T) {
+	re := require.New(t)
+	handler := func(ctx context.Context, s *server.Server) (http.Handler, apiutil.APIServiceGroup, error) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/pd/api/v1/health", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[{"name":"pd1","member_id":1,"client_urls":["http://a"],"health":true},{"name":"pd2","member_id":2,"client_urls":["http://b"],"health":false}]`)
+		})
+		info := apiutil.APIServiceGroup{
+			IsCore: true,
+		}
+		return mux, info, nil
+	}
+	cfg := server.NewTestSingleConfig(assertutil.CheckerWithNilAssert(re))
+	ctx, cancel := context.WithCancel(context.Background())
+	svr, err := server.CreateServer(ctx, cfg, handler)
+	re.NoError(err)
+	err = svr.Run()
+	re.NoError(err)
+	pdAddr := svr.GetAddr()
+	defer func() {
+		cancel()
+		svr.Close()
+		testutil.CleanServer(svr.GetConfig().DataDir)
+	}()
+
+	cmd := cmd.GetRootCmd()
+	output, err := ExecuteCommand(cmd, "-u", pdAddr, "health", "--output", "tsv")
+	re.NoError(err)
+	re.Equal("client_urls\thealth\tmember_id\tname\n"+
+		"[\"http://a\"]\ttrue\t1\tpd1\n"+
+		"[\"http://b\"]\tfalse\t2\tpd2\n", string(output))
+
+	output, err = ExecuteCommand(cmd, "-u", pdAddr, "health", "--output", "table")
+	re.NoError(err)
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	re.Len(lines, 3)
+	re.Equal([]string{"client_urls", "health", "member_id", "name"}, strings.Fields(lines[0]))
+	re.Equal([]string{`["http://b"]`, "false", "2", "pd2"}, strings.Fields(lines[2]))
+
+	output, err = ExecuteCommand(cmd, "-u", pdAddr, "health", "--output", "yaml")
+	re.NoError(err)
+	re.Contains(string(output), "- client_urls:\n    - http://a\n  health: true\n  member_id: 1\n  name: pd1\n")
+
+	_, err = ExecuteCommand(cmd, "-u", pdAddr, "health", "--output", "xml")
+	re.Error(err)
+
+	output, err = ExecuteCommand(cmd, "-u", pdAddr, "health", "--output", "json")
+	re.NoError(err)
+	re.Contains(string(output), `"member_id":2`)
+}
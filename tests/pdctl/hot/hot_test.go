@@ -16,6 +16,7 @@ package hot_test
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"strconv"
 	"testing"
@@ -360,7 +361,7 @@ func TestHistoryHotRegions(t *testing.T) {
 	re.NoError(err)
 	re.NoError(json.Unmarshal(output, &hotRegions))
 	re.Empty(hotRegions.HistoryHotRegion)
-	args = []string{"-u", pdAddr, "hot", "history"}
+	args = []string{"-u", pdAddr, "hot", "history", start}
 	output, err = pdctl.ExecuteCommand(cmd, args...)
 	re.NoError(err)
 	re.Error(json.Unmarshal(output, &hotRegions))
@@ -378,6 +379,42 @@ func TestHistoryHotRegions(t *testing.T) {
 	output, err = pdctl.ExecuteCommand(cmd, args...)
 	re.NoError(err)
 	re.Error(json.Unmarshal(output, &hotRegions))
+
+	// The time range is the last hour by default.
+	mustQuery := func(v interface{}, flags ...string) {
+		output, err := pdctl.ExecuteCommand(cmd, append([]string{"-u", pdAddr, "hot", "history"}, flags...)...)
+		re.NoError(err)
+		re.NoError(json.Unmarshal(output, v), string(output))
+	}
+	hotRegions = storage.HistoryHotRegions{}
+	mustQuery(&hotRegions, "--type", "write")
+	regionIDs := make(map[uint64]struct{})
+	for _, region := range hotRegions.HistoryHotRegion {
+		regionIDs[region.RegionID] = struct{}{}
+	}
+	re.Len(regionIDs, 4)
+	hotRegions = storage.HistoryHotRegions{}
+	mustQuery(&hotRegions, "--from", strconv.FormatInt(startTime, 10), "--type", "write", "--store", "1")
+	re.NotEmpty(hotRegions.HistoryHotRegion)
+	for _, region := range hotRegions.HistoryHotRegion {
+		re.Equal(uint64(1), region.StoreID)
+		re.Contains([]uint64{1, 3}, region.RegionID)
+	}
+	// Only the regions [a, b) and [c, d) overlap with [a, d).
+	hotRegions = storage.HistoryHotRegions{}
+	mustQuery(&hotRegions, "--type", "write", "--range", hex.EncodeToString([]byte("a"))+","+hex.EncodeToString([]byte("d")))
+	re.NotEmpty(hotRegions.HistoryHotRegion)
+	for _, region := range hotRegions.HistoryHotRegion {
+		re.Contains([]uint64{1, 2}, region.RegionID)
+	}
+	var summaries []map[string]interface{}
+	mustQuery(&summaries, "--type", "write", "--top", "2")
+	re.Len(summaries, 2)
+	re.ElementsMatch([]interface{}{3.0, 4.0}, []interface{}{summaries[0]["region_id"], summaries[1]["region_id"]})
+	re.GreaterOrEqual(summaries[0]["count"], 1.0)
+	output, err = pdctl.ExecuteCommand(cmd, "-u", pdAddr, "hot", "history", "--from", "1h", "--to", "2h")
+	re.NoError(err)
+	re.Contains(string(output), "the start of the time range should be before the end")
 }
 
 func TestHotWithoutHotPeer(t *testing.T) {
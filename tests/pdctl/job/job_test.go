@@ -0,0 +1,107 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job_test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/server/schedule/placement"
+	"github.com/tikv/pd/tests"
+	"github.com/tikv/pd/tests/pdctl"
+	pdctlCmd "github.com/tikv/pd/tools/pd-ctl/pdctl"
+)
+
+func TestJob(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	re.NoError(err)
+	defer cluster.Destroy()
+	re.NoError(cluster.RunInitialServers())
+	re.NotEmpty(cluster.WaitLeader())
+	server := cluster.GetServer(cluster.GetLeader())
+	re.NoError(server.BootstrapCluster())
+	pdAddr := cluster.GetConfig().GetClientURL()
+	cmd := pdctlCmd.GetRootCmd()
+	for id := uint64(2); id <= 4; id++ {
+		pdctl.MustPutStore(re, server.GetServer(), &metapb.Store{
+			Id:        id,
+			State:     metapb.StoreState_Up,
+			NodeState: metapb.NodeState_Serving,
+		})
+	}
+	for id := uint64(1); id <= 5; id++ {
+		pdctl.MustPutRegion(re, cluster, id, 2, []byte(fmt.Sprintf("k%d", id)), []byte(fmt.Sprintf("k%d", id+1)))
+	}
+
+	// The scatter job is waited until it finishes.
+	output, err := pdctl.ExecuteCommand(cmd, "-u", pdAddr, "job", "scatter", "--group", "test")
+	re.NoError(err)
+	re.Regexp(`Job \d+ \(scatter\) succeeded in`, string(output))
+	re.Contains(string(output), `"regions": 5`)
+
+	// A detached job can be watched later.
+	output, err = pdctl.ExecuteCommand(cmd, "-u", pdAddr, "job", "scatter", "--group", "test", "--detach")
+	re.NoError(err)
+	matches := regexp.MustCompile(`Job (\d+) is submitted`).FindStringSubmatch(string(output))
+	re.Len(matches, 2, string(output))
+	output, err = pdctl.ExecuteCommand(cmd, "-u", pdAddr, "job", "watch", matches[1])
+	re.NoError(err)
+	re.Contains(string(output), fmt.Sprintf("Job %s (scatter) succeeded", matches[1]))
+	output, err = pdctl.ExecuteCommand(cmd, "-u", pdAddr, "job", "show", matches[1])
+	re.NoError(err)
+	re.Contains(string(output), `"status": "succeeded"`)
+	output, err = pdctl.ExecuteCommand(cmd, "-u", pdAddr, "job", "list", "--type", "scatter")
+	re.NoError(err)
+	re.Contains(string(output), `"type": "scatter"`)
+
+	// The finished job can't be canceled.
+	output, err = pdctl.ExecuteCommand(cmd, "-u", pdAddr, "job", "cancel", matches[1])
+	re.NoError(err)
+	re.Contains(string(output), "Failed to cancel the job")
+
+	// The rule bundles are applied as a job with --wait.
+	bundles := []placement.GroupBundle{{
+		ID:       "test",
+		Index:    100,
+		Override: true,
+		Rules: []*placement.Rule{{
+			GroupID:     "test",
+			ID:          "single",
+			Role:        placement.Voter,
+			Count:       1,
+			StartKeyHex: hex.EncodeToString([]byte("k1")),
+			EndKeyHex:   hex.EncodeToString([]byte("k2")),
+		}},
+	}}
+	data, err := json.Marshal(bundles)
+	re.NoError(err)
+	fname := filepath.Join(t.TempDir(), "rules.json")
+	re.NoError(os.WriteFile(fname, data, 0o600))
+	output, err = pdctl.ExecuteCommand(cmd, "-u", pdAddr, "config", "placement-rules", "rule-bundle", "save", "--in", fname, "--partial", "--wait")
+	re.NoError(err)
+	re.Regexp(`Job \d+ \(rule-bundle\) succeeded in`, string(output))
+	re.NotNil(server.GetRaftCluster().GetRuleManager().GetRule("test", "single"))
+}
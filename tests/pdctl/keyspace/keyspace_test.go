@@ -0,0 +1,114 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyspace_test
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/server/apiv2/handlers"
+	"github.com/tikv/pd/server/keyspace"
+	"github.com/tikv/pd/tests"
+	"github.com/tikv/pd/tests/pdctl"
+	pdctlCmd "github.com/tikv/pd/tools/pd-ctl/pdctl"
+)
+
+func TestKeyspace(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	re.NoError(err)
+	defer cluster.Destroy()
+	re.NoError(cluster.RunInitialServers())
+	cluster.WaitLeader()
+	re.NoError(cluster.GetServer(cluster.GetLeader()).BootstrapCluster())
+	pdAddr := cluster.GetConfig().GetClientURL()
+	cmd := pdctlCmd.GetRootCmd()
+
+	mustExec := func(v interface{}, args ...string) {
+		output, err := pdctl.ExecuteCommand(cmd, append([]string{"-u", pdAddr, "keyspace"}, args...)...)
+		re.NoError(err)
+		re.NoError(json.Unmarshal(output, v), string(output))
+	}
+
+	// create and show
+	meta := &handlers.KeyspaceMeta{}
+	mustExec(meta, "create", "ks1", "--config", "k1=v1,k2=v2")
+	re.Equal("ks1", meta.Name)
+	re.Equal(map[string]string{"k1": "v1", "k2": "v2"}, meta.Config)
+	id := meta.Id
+	meta = &handlers.KeyspaceMeta{}
+	mustExec(meta, "show", "name", "ks1")
+	re.Equal(id, meta.Id)
+	meta = &handlers.KeyspaceMeta{}
+	mustExec(meta, "show", "id", strconv.FormatUint(uint64(id), 10))
+	re.Equal("ks1", meta.Name)
+	output, err := pdctl.ExecuteCommand(cmd, "-u", pdAddr, "keyspace", "show", "name", "unknown")
+	re.NoError(err)
+	re.Contains(string(output), "Failed to get the keyspace")
+
+	// list
+	list := &handlers.LoadAllKeyspacesResponse{}
+	mustExec(list, "list")
+	re.Len(list.Keyspaces, 2) // the default keyspace and ks1
+	list = &handlers.LoadAllKeyspacesResponse{}
+	mustExec(list, "list", "--limit", "1")
+	re.Len(list.Keyspaces, 1)
+	re.NotEmpty(list.NextPageToken)
+
+	// update config
+	meta = &handlers.KeyspaceMeta{}
+	mustExec(meta, "update-config", "ks1", "--set", "k1=v3", "--remove", "k2")
+	re.Equal(map[string]string{"k1": "v3"}, meta.Config)
+
+	// state changes
+	meta = &handlers.KeyspaceMeta{}
+	mustExec(meta, "update-state", "ks1", "disabled")
+	re.Equal("DISABLED", meta.State.String())
+	meta = &handlers.KeyspaceMeta{}
+	mustExec(meta, "archive", "ks1")
+	re.Equal("ARCHIVED", meta.State.String())
+	meta = &handlers.KeyspaceMeta{}
+	mustExec(meta, "restore", "ks1")
+	re.Equal("DISABLED", meta.State.String())
+	meta = &handlers.KeyspaceMeta{}
+	mustExec(meta, "update-state", "ks1", "enabled")
+	re.Equal("ENABLED", meta.State.String())
+
+	// rename
+	meta = &handlers.KeyspaceMeta{}
+	mustExec(meta, "rename", "ks1", "ks2")
+	re.Equal("ks2", meta.Name)
+	re.Equal(id, meta.Id)
+
+	// quota and usage
+	quota := &keyspace.QuotaUsage{}
+	mustExec(quota, "quota", "ks2", "--max-regions", "10")
+	re.Equal(uint64(10), quota.Quota.MaxRegions)
+	quota = &keyspace.QuotaUsage{}
+	mustExec(quota, "quota", "ks2", "--max-size", "1024")
+	re.Equal(uint64(10), quota.Quota.MaxRegions)
+	re.Equal(uint64(1024), quota.Quota.MaxApproximateSize)
+	quota = &keyspace.QuotaUsage{}
+	mustExec(quota, "quota", "ks2")
+	re.Equal(uint64(1024), quota.Quota.MaxApproximateSize)
+	usage := &keyspace.Usage{}
+	mustExec(usage, "usage", "ks2")
+	re.Equal(id, usage.KeyspaceID)
+}
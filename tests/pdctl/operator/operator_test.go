@@ -16,6 +16,7 @@ package operator_test
 
 import (
 	"context"
+	"encoding/json"
 	"strconv"
 	"strings"
 	"testing"
@@ -194,6 +195,22 @@ func TestOperator(t *testing.T) {
 	_, err = pdctl.ExecuteCommand(cmd, args...)
 	re.NoError(err)
 
+	// operator trace <region_id>
+	output, err = pdctl.ExecuteCommand(cmd, "-u", pdAddr, "operator", "trace", "1")
+	re.NoError(err)
+	re.Contains(string(output), "admin-merge-region")
+	re.Contains(string(output), "Canceled: removed manually")
+	re.Contains(string(output), "merge region 1 into region 3")
+	output, err = pdctl.ExecuteCommand(cmd, "-u", pdAddr, "operator", "trace", "1", "--json")
+	re.NoError(err)
+	var traces []map[string]interface{}
+	re.NoError(json.Unmarshal(output, &traces))
+	re.NotEmpty(traces)
+	re.Equal("admin-merge-region", traces[len(traces)-1]["desc"])
+	output, err = pdctl.ExecuteCommand(cmd, "-u", pdAddr, "operator", "trace", "100")
+	re.NoError(err)
+	re.Contains(string(output), "No recent operator of region 100")
+
 	_, err = pdctl.ExecuteCommand(cmd, "config", "set", "enable-placement-rules", "true")
 	re.NoError(err)
 	output, err = pdctl.ExecuteCommand(cmd, "operator", "add", "transfer-region", "1", "2", "3")
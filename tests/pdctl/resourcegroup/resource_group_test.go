@@ -0,0 +1,134 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcegroup_test
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	rmpb "github.com/pingcap/kvproto/pkg/resource_manager"
+	"github.com/stretchr/testify/require"
+	_ "github.com/tikv/pd/pkg/mcs/resource_manager/server/install"
+	"github.com/tikv/pd/tests"
+	"github.com/tikv/pd/tests/pdctl"
+	pdctlCmd "github.com/tikv/pd/tools/pd-ctl/pdctl"
+)
+
+func TestResourceGroup(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	re.NoError(err)
+	defer cluster.Destroy()
+	re.NoError(cluster.RunInitialServers())
+	re.NotEmpty(cluster.WaitLeader())
+	pdAddr := cluster.GetConfig().GetClientURL()
+	cmd := pdctlCmd.GetRootCmd()
+	exec := func(args ...string) string {
+		output, err := pdctl.ExecuteCommand(cmd, append([]string{"-u", pdAddr, "resource-group"}, args...)...)
+		re.NoError(err)
+		return string(output)
+	}
+	type group struct {
+		Name       string         `json:"name"`
+		Mode       rmpb.GroupMode `json:"mode"`
+		Priority   uint32         `json:"priority"`
+		RUSettings struct {
+			RU struct {
+				Settings rmpb.TokenLimitSettings `json:"settings"`
+			} `json:"ru"`
+		} `json:"r_u_settings"`
+	}
+	mustShow := func(name string) *group {
+		g := &group{}
+		output := exec("show", name)
+		re.NoError(json.Unmarshal([]byte(output), g), output)
+		return g
+	}
+
+	// The resource manager is started after the leader is elected.
+	re.Eventually(func() bool {
+		return !strings.Contains(exec("list"), "Failed")
+	}, 10*time.Second, 100*time.Millisecond)
+
+	// create, update and delete
+	re.Contains(exec("create", "rg1", "--ru-per-sec", "1000", "--burst-limit", "2000", "--priority", "8"), "Success!")
+	g := mustShow("rg1")
+	re.Equal(rmpb.GroupMode_RUMode, g.Mode)
+	re.Equal(uint64(1000), g.RUSettings.RU.Settings.FillRate)
+	re.Equal(int64(2000), g.RUSettings.RU.Settings.BurstLimit)
+	re.Equal(uint32(8), g.Priority)
+	re.Contains(exec("create", "rg1", "--ru-per-sec", "1000"), "Failed to create the resource group")
+	// The unspecified settings are kept.
+	re.Contains(exec("update", "rg1", "--ru-per-sec", "3000"), "Success!")
+	g = mustShow("rg1")
+	re.Equal(uint64(3000), g.RUSettings.RU.Settings.FillRate)
+	re.Equal(int64(2000), g.RUSettings.RU.Settings.BurstLimit)
+	re.Contains(exec("update", "rg1", "--priority", "1"), "Success!")
+	re.Equal(uint32(1), mustShow("rg1").Priority)
+	re.Contains(exec("create", "rg2", "--ru-per-sec", "500"), "Success!")
+	var groups []*group
+	output := exec("list")
+	re.NoError(json.Unmarshal([]byte(output), &groups), output)
+	var names []string
+	for _, g := range groups {
+		names = append(names, g.Name)
+	}
+	re.Subset(names, []string{"rg1", "rg2"})
+	re.Contains(exec("delete", "rg2"), "Success!")
+	re.Contains(exec("show", "rg2"), "Failed to get the resource group")
+
+	// consumption
+	var records []map[string]interface{}
+	output = exec("consumption", "--group", "rg1")
+	re.NoError(json.Unmarshal([]byte(output), &records), output)
+	output = exec("consumption", "history", "--from", "1h", "--sum")
+	re.NoError(json.Unmarshal([]byte(output), &records), output)
+	re.Contains(exec("consumption", "history", "--from", "yesterday"), "Invalid from")
+
+	// runaway settings
+	re.Contains(exec("runaway", "set", "rg1", "--exec-elapsed", "10s", "--action", "kill", "--watch", "exact", "--watch-duration", "10m"), "Success!")
+	settings := make(map[string]interface{})
+	output = exec("runaway", "show", "rg1")
+	re.NoError(json.Unmarshal([]byte(output), &settings), output)
+	re.Equal(10000.0, settings["exec_elapsed_time_ms"])
+	re.Equal("kill", settings["action"])
+	re.Equal(map[string]interface{}{"type": "exact", "lasting_duration_ms": 600000.0}, settings["watch"])
+	re.Contains(exec("runaway", "set", "rg1", "--exec-elapsed", "10s", "--action", "unknown"), "Failed to set the runaway settings")
+	re.Contains(exec("runaway", "delete", "rg1"), "Success!")
+	re.Contains(exec("runaway", "show", "rg1"), "Failed to get the runaway settings")
+
+	// runaway watch items
+	item := make(map[string]interface{})
+	output = exec("runaway", "watch", "add", "rg1", "select 1", "--type", "exact", "--action", "cooldown", "--duration", "1h")
+	re.NoError(json.Unmarshal([]byte(output), &item), output)
+	re.Equal("rg1", item["resource_group"])
+	re.Equal("manual", item["source"])
+	id := item["id"].(float64)
+	var items []map[string]interface{}
+	output = exec("runaway", "watch", "list", "--group", "rg1")
+	re.NoError(json.Unmarshal([]byte(output), &items), output)
+	re.Len(items, 1)
+	re.Equal(id, items[0]["id"])
+	re.Contains(exec("runaway", "watch", "delete", strconv.FormatFloat(id, 'f', -1, 64)), "Success!")
+	output = exec("runaway", "watch", "list")
+	re.NoError(json.Unmarshal([]byte(output), &items), output)
+	re.Empty(items)
+}
@@ -145,6 +145,14 @@ func TestScheduler(t *testing.T) {
 	echo := mustExec([]string{"-u", pdAddr, "config", "set", "enable-diagnostic", "true"}, nil)
 	re.Contains(echo, "Success!")
 	checkSchedulerDescribeCommand("balance-region-scheduler", "pending", "1 store(s) RegionNotMatchRule; ")
+	echo = mustExec([]string{"-u", pdAddr, "scheduler", "diagnose", "balance-region-scheduler"}, nil)
+	re.Contains(echo, "Status: pending")
+	re.Contains(echo, "RegionNotMatchRule")
+	re.Contains(echo, "region-schedule-limit: ")
+	echo = mustExec([]string{"-u", pdAddr, "scheduler", "diagnose", "balance-hot-region-scheduler"}, nil)
+	re.Contains(echo, "Status: enabled")
+	re.Contains(echo, "hot-region-schedule-limit: ")
+	re.Contains(echo, "Note: only balance-region-scheduler and balance-leader-scheduler")
 
 	// scheduler delete command
 	args := []string{"-u", pdAddr, "scheduler", "remove", "balance-region-scheduler"}
@@ -158,6 +166,8 @@ func TestScheduler(t *testing.T) {
 	checkSchedulerCommand(args, expected)
 
 	checkSchedulerDescribeCommand("balance-region-scheduler", "disabled", "")
+	echo = mustExec([]string{"-u", pdAddr, "scheduler", "diagnose", "balance-region-scheduler"}, nil)
+	re.Contains(echo, "Status: disabled")
 
 	schedulers := []string{"evict-leader-scheduler", "grant-leader-scheduler"}
 
@@ -0,0 +1,95 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package script_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/tests"
+	"github.com/tikv/pd/tests/pdctl"
+	pdctlCmd "github.com/tikv/pd/tools/pd-ctl/pdctl"
+)
+
+func TestExecuteScript(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	re.NoError(err)
+	defer cluster.Destroy()
+	re.NoError(cluster.RunInitialServers())
+	cluster.WaitLeader()
+	leaderServer := cluster.GetServer(cluster.GetLeader())
+	re.NoError(leaderServer.BootstrapCluster())
+	pdctl.MustPutStore(re, leaderServer.GetServer(), &metapb.Store{
+		Id:            1,
+		State:         metapb.StoreState_Up,
+		LastHeartbeat: time.Now().UnixNano(),
+	})
+	rootCmd := pdctlCmd.GetRootCmd()
+	re.NoError(rootCmd.PersistentFlags().Set("pd", cluster.GetConfig().GetClientURL()))
+	flags := rootCmd.PersistentFlags()
+	leaderScheduleLimit := func() uint64 {
+		return leaderServer.GetServer().GetScheduleConfig().LeaderScheduleLimit
+	}
+
+	// An invalid command stops the whole script from being executed.
+	var out bytes.Buffer
+	script := `
+# set the limits
+config set leader-schedule-limit 16
+config sett region-schedule-limit 16
+`
+	err = pdctlCmd.ExecuteScript(strings.NewReader(script), &out, flags, true)
+	re.Error(err)
+	re.Contains(err.Error(), "line 4: config sett region-schedule-limit 16")
+	re.NotEqual(uint64(16), leaderScheduleLimit())
+
+	out.Reset()
+	script = `
+config set leader-schedule-limit 16
+store 1
+`
+	re.NoError(pdctlCmd.ExecuteScript(strings.NewReader(script), &out, flags, true))
+	re.Equal(uint64(16), leaderScheduleLimit())
+	re.Contains(out.String(), ">>> [1/2] config set leader-schedule-limit 16\nSuccess!")
+	re.Contains(out.String(), "<<< [2/2] ok")
+	re.Contains(out.String(), "2 command(s): 2 succeeded, 0 failed, 0 skipped")
+
+	// The commands after the failed one are skipped.
+	out.Reset()
+	script = `
+store 100
+config set leader-schedule-limit 32
+`
+	re.Error(pdctlCmd.ExecuteScript(strings.NewReader(script), &out, flags, true))
+	re.Contains(out.String(), "<<< [1/2] failed")
+	re.Contains(out.String(), "2 command(s): 0 succeeded, 1 failed, 1 skipped")
+	re.Equal(uint64(16), leaderScheduleLimit())
+
+	out.Reset()
+	re.Error(pdctlCmd.ExecuteScript(strings.NewReader(script), &out, flags, false))
+	re.Contains(out.String(), "2 command(s): 1 succeeded, 1 failed, 0 skipped")
+	re.Equal(uint64(32), leaderScheduleLimit())
+
+	re.True(pdctlCmd.IsScriptFromStdin([]string{"-u", "127.0.0.1:2379", "--file", "-"}))
+	re.False(pdctlCmd.IsScriptFromStdin([]string{"--file", "commands.txt"}))
+}
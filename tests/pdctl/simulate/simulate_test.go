@@ -0,0 +1,131 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulate_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/go-units"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/server/schedule/placement"
+	"github.com/tikv/pd/tests"
+	"github.com/tikv/pd/tests/pdctl"
+	pdctlCmd "github.com/tikv/pd/tools/pd-ctl/pdctl"
+)
+
+type simulationResult struct {
+	Summary   map[string]int `json:"summary"`
+	Operators []struct {
+		Source   string `json:"source"`
+		RegionID uint64 `json:"region_id"`
+		Desc     string `json:"desc"`
+	} `json:"operators"`
+}
+
+func TestSimulate(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	re.NoError(err)
+	defer cluster.Destroy()
+	re.NoError(cluster.RunInitialServers())
+	re.NotEmpty(cluster.WaitLeader())
+	server := cluster.GetServer(cluster.GetLeader())
+	re.NoError(server.BootstrapCluster())
+	pdAddr := cluster.GetConfig().GetClientURL()
+	cmd := pdctlCmd.GetRootCmd()
+	for id := uint64(1); id <= 4; id++ {
+		pdctl.MustPutStore(re, server.GetServer(), &metapb.Store{
+			Id:        id,
+			State:     metapb.StoreState_Up,
+			NodeState: metapb.NodeState_Serving,
+		})
+		re.NoError(server.GetServer().GetRaftCluster().HandleStoreHeartbeat(&pdpb.StoreHeartbeatRequest{
+			Stats: &pdpb.StoreStats{
+				StoreId:   id,
+				Capacity:  1000 * units.MiB,
+				Available: 1000 * units.MiB,
+			},
+		}, &pdpb.StoreHeartbeatResponse{}))
+	}
+	// The regions have only one replica.
+	for id := uint64(1); id <= 3; id++ {
+		pdctl.MustPutRegion(re, cluster, id, 1, []byte(fmt.Sprintf("k%d", id)), []byte(fmt.Sprintf("k%d", id+1)))
+	}
+
+	dir := t.TempDir()
+	snapshot := filepath.Join(dir, "snapshot.json")
+	output, err := pdctl.ExecuteCommand(cmd, "-u", pdAddr, "simulate", "export", "--out", snapshot)
+	re.NoError(err)
+	re.Contains(string(output), "The snapshot of 4 stores and 3 regions is exported")
+
+	run := func(args ...string) *simulationResult {
+		args = append([]string{"simulate", "run", "--snapshot", snapshot}, args...)
+		// Use a new command to avoid the flags set by the previous runs.
+		output, err := pdctl.ExecuteCommand(pdctlCmd.GetRootCmd(), args...)
+		re.NoError(err)
+		result := &simulationResult{}
+		re.NoError(json.Unmarshal(output, result), string(output))
+		return result
+	}
+
+	// The checkers add the missing replicas.
+	result := run("--skip-schedulers")
+	re.Equal(3, result.Summary["checker"])
+	for _, op := range result.Operators {
+		re.Equal("checker", op.Source)
+		re.Equal("add-rule-peer", op.Desc)
+	}
+
+	// No replica is added if the rules only require one.
+	bundles := []placement.GroupBundle{{
+		ID: "pd",
+		Rules: []*placement.Rule{{
+			GroupID: "pd",
+			ID:      "default",
+			Role:    placement.Voter,
+			Count:   1,
+		}},
+	}}
+	data, err := json.Marshal(bundles)
+	re.NoError(err)
+	rules := filepath.Join(dir, "rules.json")
+	re.NoError(os.WriteFile(rules, data, 0o600))
+	result = run("--skip-schedulers", "--rules", rules)
+	re.Empty(result.Operators)
+
+	// The operators are limited by the config.
+	cfg := filepath.Join(dir, "config.json")
+	re.NoError(os.WriteFile(cfg, []byte(`{"schedule": {"replica-schedule-limit": 0}}`), 0o600))
+	result = run("--skip-schedulers", "--config", cfg)
+	re.Empty(result.Operators)
+
+	// The schedulers run as well.
+	result = run("--schedulers", "balance-region-scheduler,balance-leader-scheduler")
+	re.Equal(3, result.Summary["checker"])
+
+	// The invalid snapshot.
+	output, err = pdctl.ExecuteCommand(pdctlCmd.GetRootCmd(), "simulate", "run", "--snapshot", rules)
+	re.NoError(err)
+	re.Contains(string(output), "Failed to simulate")
+}
@@ -17,9 +17,11 @@ package store_test
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/pingcap/failpoint"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/stretchr/testify/require"
 	"github.com/tikv/pd/pkg/core"
@@ -523,3 +525,116 @@ func TestTombstoneStore(t *testing.T) {
 	re.Contains(message, "2")
 	re.Contains(message, "3")
 }
+
+func TestStoreDrain(t *testing.T) {
+	re := require.New(t)
+	// Speed up burying the empty offline store.
+	re.NoError(failpoint.Enable("github.com/tikv/pd/server/cluster/highFrequencyClusterJobs", `return(true)`))
+	defer func() {
+		re.NoError(failpoint.Disable("github.com/tikv/pd/server/cluster/highFrequencyClusterJobs"))
+	}()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	re.NoError(err)
+	defer cluster.Destroy()
+	re.NoError(cluster.RunInitialServers())
+	re.NotEmpty(cluster.WaitLeader())
+	pdAddr := cluster.GetConfig().GetClientURL()
+	cmd := ctl.GetRootCmd()
+	leaderServer := cluster.GetServer(cluster.GetLeader())
+	re.NoError(leaderServer.BootstrapCluster())
+	for id := uint64(2); id <= 5; id++ {
+		pdctl.MustPutStore(re, leaderServer.GetServer(), &metapb.Store{
+			Id:            id,
+			State:         metapb.StoreState_Up,
+			NodeState:     metapb.NodeState_Serving,
+			LastHeartbeat: time.Now().UnixNano(),
+		})
+	}
+	for id := uint64(2); id <= 4; id++ {
+		pdctl.MustPutRegion(re, cluster, id, id, []byte(fmt.Sprintf("k%d", id)), []byte(fmt.Sprintf("k%d", id+1)))
+	}
+
+	// The empty store is drained until it's tombstone.
+	output, err := pdctl.ExecuteCommand(cmd, "-u", pdAddr, "store", "drain", "5")
+	re.NoError(err)
+	re.Contains(string(output), "[1/4] Checking the remaining stores")
+	re.Contains(string(output), "Skipped, the store has no leader")
+	re.Regexp(`Job \d+ \(drain\) succeeded in`, string(output))
+	re.Contains(string(output), "Store 5 is tombstone")
+	re.True(leaderServer.GetRaftCluster().GetStore(5).IsRemoved())
+
+	// Running it again only cleans up.
+	output, err = pdctl.ExecuteCommand(cmd, "-u", pdAddr, "store", "drain", "5")
+	re.NoError(err)
+	re.Contains(string(output), "Store 5 is already tombstone")
+	re.NotContains(string(output), "[1/4]")
+
+	// The pre-check fails if the remaining stores can't hold the replicas.
+	pdctl.MustPutStore(re, leaderServer.GetServer(), &metapb.Store{
+		Id:            4,
+		State:         metapb.StoreState_Offline,
+		NodeState:     metapb.NodeState_Removing,
+		LastHeartbeat: time.Now().UnixNano(),
+	})
+	output, err = pdctl.ExecuteCommand(cmd, "-u", pdAddr, "store", "drain", "3")
+	re.NoError(err)
+	re.Contains(string(output), "Warning: store 4 is being removed as well")
+	re.Contains(string(output), "Failed to drain store 3")
+	re.Contains(string(output), "less than max-replicas 3")
+	re.True(leaderServer.GetRaftCluster().GetStore(3).IsUp())
+
+	// The invalid store ID.
+	output, err = pdctl.ExecuteCommand(cmd, "-u", pdAddr, "store", "drain", "abc")
+	re.NoError(err)
+	re.Contains(string(output), "Usage")
+}
+
+func TestStoreTree(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	re.NoError(err)
+	err = cluster.RunInitialServers()
+	re.NoError(err)
+	cluster.WaitLeader()
+	pdAddr := cluster.GetConfig().GetClientURL()
+	cmd := ctl.GetRootCmd()
+
+	leaderServer := cluster.GetServer(cluster.GetLeader())
+	re.NoError(leaderServer.BootstrapCluster())
+	for id, labels := range map[uint64][][2]string{
+		1: {{"zone", "z1"}, {"host", "h1"}},
+		2: {{"zone", "z1"}, {"host", "h2"}},
+		3: {{"zone", "z2"}, {"host", "h3"}},
+		4: {{"host", "h4"}},
+	} {
+		store := &metapb.Store{
+			Id:            id,
+			State:         metapb.StoreState_Up,
+			LastHeartbeat: time.Now().UnixNano(),
+		}
+		for _, label := range labels {
+			store.Labels = append(store.Labels, &metapb.StoreLabel{Key: label[0], Value: label[1]})
+		}
+		pdctl.MustPutStore(re, leaderServer.GetServer(), store)
+	}
+	defer cluster.Destroy()
+
+	output, err := pdctl.ExecuteCommand(cmd, "-u", pdAddr, "store", "tree", "--levels", "zone,host")
+	re.NoError(err)
+	tree := string(output)
+	re.Contains(tree, "cluster (zone/host) stores: 4 (100.0%)")
+	re.Contains(tree, "├── zone=<unset> stores: 1 (25.0%)")
+	re.Contains(tree, "├── zone=z1 stores: 2 (50.0%)")
+	re.Contains(tree, "│   ├── host=h1 stores: 1 (25.0%)")
+	re.Contains(tree, "│   │   └── store 1 (")
+	re.Contains(tree, "└── zone=z2 stores: 1 (25.0%)")
+	re.Contains(tree, "        └── store 3 (")
+
+	output, err = pdctl.ExecuteCommand(cmd, "-u", pdAddr, "store", "tree", "--levels", "zone,,host")
+	re.NoError(err)
+	re.Contains(string(output), "Failed to get the topology")
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tui_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/tests"
+	"github.com/tikv/pd/tests/pdctl"
+	pdctlCmd "github.com/tikv/pd/tools/pd-ctl/pdctl"
+)
+
+func TestTUIOnce(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	re.NoError(err)
+	defer cluster.Destroy()
+	re.NoError(cluster.RunInitialServers())
+	cluster.WaitLeader()
+	leaderServer := cluster.GetServer(cluster.GetLeader())
+	re.NoError(leaderServer.BootstrapCluster())
+	pdAddr := cluster.GetConfig().GetClientURL()
+	cmd := pdctlCmd.GetRootCmd()
+
+	for id := uint64(1); id <= 2; id++ {
+		pdctl.MustPutStore(re, leaderServer.GetServer(), &metapb.Store{
+			Id:            id,
+			Address:       fmt.Sprintf("tikv%d", id),
+			State:         metapb.StoreState_Up,
+			NodeState:     metapb.NodeState_Serving,
+			LastHeartbeat: time.Now().UnixNano(),
+		})
+	}
+	pdctl.MustPutRegion(re, cluster, 1, 1, []byte("a"), []byte("b"))
+	_, err = pdctl.ExecuteCommand(cmd, "-u", pdAddr, "operator", "add", "add-peer", "1", "2")
+	re.NoError(err)
+
+	// The output is not a terminal, so the status is printed once.
+	output, err := pdctl.ExecuteCommand(cmd, "-u", pdAddr, "tui")
+	re.NoError(err)
+	sections := strings.Split(string(output), "\n\n")
+	re.Len(sections, 4)
+	re.Contains(sections[1], "Stores\n")
+	re.Contains(sections[1], "tikv1")
+	re.Contains(sections[1], "tikv2")
+	re.Contains(sections[2], "Hot regions\n")
+	re.Contains(sections[3], "Operators\n")
+	re.Contains(sections[3], "add-peer")
+
+	output, err = pdctl.ExecuteCommand(cmd, "-u", pdAddr, "tui", "--interval", "0s")
+	re.NoError(err)
+	re.Contains(string(output), "interval should be a positive duration")
+}
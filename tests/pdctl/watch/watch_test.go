@@ -0,0 +1,92 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watch_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/event"
+	"github.com/tikv/pd/tests"
+	"github.com/tikv/pd/tests/pdctl"
+	pdctlCmd "github.com/tikv/pd/tools/pd-ctl/pdctl"
+)
+
+func TestWatch(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	re.NoError(err)
+	defer cluster.Destroy()
+	re.NoError(cluster.RunInitialServers())
+	re.NotEmpty(cluster.WaitLeader())
+	server := cluster.GetServer(cluster.GetLeader())
+	re.NoError(server.BootstrapCluster())
+	pdAddr := cluster.GetConfig().GetClientURL()
+
+	outputCh := make(chan string, 1)
+	go func() {
+		output, err := pdctl.ExecuteCommand(pdctlCmd.GetRootCmd(), "-u", pdAddr, "watch",
+			"--filter", "category=store", "--count", "1", "--json")
+		re.NoError(err)
+		outputCh <- string(output)
+	}()
+	// Keep adding the stores until the watch starts and receives the event.
+	var output string
+	for id := uint64(10); len(output) == 0; id++ {
+		pdctl.MustPutStore(re, server.GetServer(), &metapb.Store{Id: id, State: metapb.StoreState_Up, NodeState: metapb.NodeState_Serving})
+		select {
+		case output = <-outputCh:
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	e := struct {
+		ResumeToken string `json:"resume_token"`
+		event.Event
+	}{}
+	re.NoError(json.Unmarshal([]byte(strings.TrimSpace(output)), &e), output)
+	re.NotEmpty(e.ResumeToken)
+	re.Equal(event.StoreStateChanged, e.Type)
+	re.Equal(metapb.StoreState_Up.String(), e.Attributes["state"])
+
+	// Resume from the token.
+	pdctl.MustPutStore(re, server.GetServer(), &metapb.Store{Id: 100, State: metapb.StoreState_Up, NodeState: metapb.NodeState_Serving})
+	args := []string{"-u", pdAddr, "watch", "--filter", "type=store-state-changed", "--filter", "store=100",
+		"--resume-token", e.ResumeToken, "--count", "1"}
+	output2, err := pdctl.ExecuteCommand(pdctlCmd.GetRootCmd(), args...)
+	re.NoError(err)
+	re.Contains(string(output2), "store-state-changed")
+	re.Contains(string(output2), "store=100")
+
+	// Invalid arguments.
+	args = []string{"-u", pdAddr, "watch", "--filter", "foo=1"}
+	output2, err = pdctl.ExecuteCommand(pdctlCmd.GetRootCmd(), args...)
+	re.NoError(err)
+	re.Contains(string(output2), "invalid filter")
+	args = []string{"-u", pdAddr, "watch", "--filter", "store=1,2"}
+	output2, err = pdctl.ExecuteCommand(pdctlCmd.GetRootCmd(), args...)
+	re.NoError(err)
+	re.Contains(string(output2), "only one store is allowed")
+	args = []string{"-u", pdAddr, "watch", "--resume-token", "invalid"}
+	output2, err = pdctl.ExecuteCommand(pdctlCmd.GetRootCmd(), args...)
+	re.NoError(err)
+	re.Contains(string(output2), "Failed to watch the events")
+}
@@ -0,0 +1,99 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/event"
+	"github.com/tikv/pd/tests"
+	"github.com/tikv/pd/tests/pdctl"
+)
+
+func TestWatchEvents(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	re.NoError(err)
+	defer cluster.Destroy()
+	re.NoError(cluster.RunInitialServers())
+	re.NotEmpty(cluster.WaitLeader())
+	server := cluster.GetServer(cluster.GetLeader())
+	re.NoError(server.BootstrapCluster())
+	eventsURL := server.GetAddr() + "/pd/api/v2/events?category=store"
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, eventsURL, nil)
+	re.NoError(err)
+	resp, err := dialClient.Do(httpReq)
+	re.NoError(err)
+	defer resp.Body.Close()
+	re.Equal(http.StatusOK, resp.StatusCode)
+	re.Equal("text/event-stream", resp.Header.Get("Content-Type"))
+	reader := bufio.NewReader(resp.Body)
+
+	pdctl.MustPutStore(re, server.GetServer(), &metapb.Store{Id: 10, State: metapb.StoreState_Up, NodeState: metapb.NodeState_Serving})
+	token, e := mustReadClusterEvent(re, reader)
+	re.NotEmpty(token)
+	re.Equal(event.StoreStateChanged, e.Type)
+	re.Equal(uint64(10), e.StoreID)
+	re.Equal(metapb.StoreState_Up.String(), e.Attributes["state"])
+	pdctl.MustPutStore(re, server.GetServer(), &metapb.Store{Id: 11, State: metapb.StoreState_Up, NodeState: metapb.NodeState_Serving})
+	_, e = mustReadClusterEvent(re, reader)
+	re.Equal(uint64(11), e.StoreID)
+
+	// Resuming from the token receives the events after it.
+	httpReq, err = http.NewRequestWithContext(ctx, http.MethodGet, eventsURL, nil)
+	re.NoError(err)
+	httpReq.Header.Set("Last-Event-ID", token)
+	resumed, err := dialClient.Do(httpReq)
+	re.NoError(err)
+	defer resumed.Body.Close()
+	re.Equal(http.StatusOK, resumed.StatusCode)
+	_, e = mustReadClusterEvent(re, bufio.NewReader(resumed.Body))
+	re.Equal(uint64(11), e.StoreID)
+
+	invalid, err := dialClient.Get(eventsURL + "&resume_token=invalid")
+	re.NoError(err)
+	invalid.Body.Close()
+	re.Equal(http.StatusBadRequest, invalid.StatusCode)
+}
+
+// mustReadClusterEvent reads a server-sent cluster event from the reader.
+func mustReadClusterEvent(re *require.Assertions, reader *bufio.Reader) (token string, e *event.Event) {
+	for {
+		line, err := reader.ReadString('\n')
+		re.NoError(err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			if e != nil {
+				return token, e
+			}
+		case strings.HasPrefix(line, "id: "):
+			token = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			e = &event.Event{}
+			re.NoError(json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), e))
+		}
+	}
+}
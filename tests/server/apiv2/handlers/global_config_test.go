@@ -0,0 +1,110 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/server/apiv2/handlers"
+	"github.com/tikv/pd/server/globalconfig"
+	"github.com/tikv/pd/tests"
+)
+
+func TestGlobalConfig(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	re.NoError(err)
+	defer cluster.Destroy()
+	re.NoError(cluster.RunInitialServers())
+	re.NotEmpty(cluster.WaitLeader())
+	server := cluster.GetServer(cluster.GetLeader())
+	addr := server.GetAddr() + "/pd/api/v2/global-config/br"
+
+	var items handlers.GlobalConfigItems
+	mustRequest(re, http.MethodGet, addr+"/items", nil, http.StatusOK, &items)
+	re.Empty(items.Items)
+
+	// Watch the changes since the load.
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/watch?revision=%d", addr, items.Revision+1), nil)
+	re.NoError(err)
+	resp, err := dialClient.Do(httpReq)
+	re.NoError(err)
+	defer resp.Body.Close()
+	re.Equal(http.StatusOK, resp.StatusCode)
+	reader := bufio.NewReader(resp.Body)
+
+	// Compare-and-swap.
+	zero := int64(0)
+	var item globalconfig.Item
+	mustRequest(re, http.MethodPut, addr+"/items/task", &handlers.PutGlobalConfigParams{Value: "v1", ExpectedRevision: &zero}, http.StatusOK, &item)
+	re.Equal("v1", item.Value)
+	first := item.Revision
+	mustRequest(re, http.MethodPut, addr+"/items/task", &handlers.PutGlobalConfigParams{Value: "v2", ExpectedRevision: &zero}, http.StatusConflict, nil)
+	mustRequest(re, http.MethodPut, addr+"/items/task", &handlers.PutGlobalConfigParams{Value: "v2", ExpectedRevision: &first}, http.StatusOK, &item)
+	re.Equal(int64(2), item.Version)
+	mustRequest(re, http.MethodPut, addr+"/items/task", &handlers.PutGlobalConfigParams{Value: "v3", ExpectedRevision: &first}, http.StatusConflict, nil)
+
+	// Revision history.
+	mustRequest(re, http.MethodGet, fmt.Sprintf("%s/items/task?revision=%d", addr, first), nil, http.StatusOK, &item)
+	re.Equal("v1", item.Value)
+	var history []*globalconfig.Item
+	mustRequest(re, http.MethodGet, addr+"/items/task/history", nil, http.StatusOK, &history)
+	re.Len(history, 2)
+	re.Equal("v2", history[0].Value)
+	re.Equal("v1", history[1].Value)
+
+	for _, expected := range []string{"v1", "v2"} {
+		event, item := mustReadGlobalConfigEvent(re, reader)
+		re.Equal("put", event)
+		re.Equal("task", item.Name)
+		re.Equal(expected, item.Value)
+	}
+	mustRequest(re, http.MethodDelete, fmt.Sprintf("%s/items/task?expected_revision=%d", addr, first), nil, http.StatusConflict, nil)
+	mustRequest(re, http.MethodDelete, addr+"/items/task", nil, http.StatusOK, nil)
+	event, deleted := mustReadGlobalConfigEvent(re, reader)
+	re.Equal("delete", event)
+	re.Equal("v2", deleted.Value)
+	mustRequest(re, http.MethodGet, addr+"/items/task", nil, http.StatusNotFound, nil)
+	mustRequest(re, http.MethodGet, server.GetAddr()+"/pd/api/v2/global-config/in$valid/items", nil, http.StatusBadRequest, nil)
+}
+
+// mustReadGlobalConfigEvent reads a server-sent global config event from the reader.
+func mustReadGlobalConfigEvent(re *require.Assertions, reader *bufio.Reader) (event string, item *globalconfig.Item) {
+	for {
+		line, err := reader.ReadString('\n')
+		re.NoError(err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			if item != nil {
+				return event, item
+			}
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			item = &globalconfig.Item{}
+			re.NoError(json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), item))
+		}
+	}
+}
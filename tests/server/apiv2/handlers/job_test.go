@@ -0,0 +1,159 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers_test
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/job"
+	"github.com/tikv/pd/server/apiv2/handlers"
+	"github.com/tikv/pd/server/schedule/placement"
+	"github.com/tikv/pd/tests"
+	"github.com/tikv/pd/tests/pdctl"
+)
+
+func TestJobs(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	re.NoError(err)
+	defer cluster.Destroy()
+	re.NoError(cluster.RunInitialServers())
+	re.NotEmpty(cluster.WaitLeader())
+	server := cluster.GetServer(cluster.GetLeader())
+	re.NoError(server.BootstrapCluster())
+	addr := server.GetAddr() + "/pd/api/v2"
+	for id := uint64(2); id <= 4; id++ {
+		pdctl.MustPutStore(re, server.GetServer(), &metapb.Store{
+			Id:        id,
+			State:     metapb.StoreState_Up,
+			NodeState: metapb.NodeState_Serving,
+		})
+	}
+	for id := uint64(1); id <= 5; id++ {
+		pdctl.MustPutRegion(re, cluster, id, 2, []byte(fmt.Sprintf("k%d", id)), []byte(fmt.Sprintf("k%d", id+1)))
+	}
+
+	// The scatter job finishes by itself.
+	var res job.Job
+	mustRequest(re, http.MethodPost, addr+"/jobs", map[string]interface{}{
+		"type":   "scatter",
+		"params": &handlers.ScatterJobParams{Group: "test"},
+	}, http.StatusAccepted, &res)
+	re.Equal("scatter", res.Type)
+	scatterID := res.ID
+	mustWaitJob(re, addr, scatterID, job.Succeeded, &res)
+	re.Equal(1.0, res.Progress)
+	re.Equal(5.0, res.Result.(map[string]interface{})["regions"])
+	mustRequest(re, http.MethodDelete, fmt.Sprintf("%s/jobs/%d", addr, scatterID), nil, http.StatusConflict, nil)
+
+	// The range scatter job doesn't split the range already at the region boundaries.
+	mustRequest(re, http.MethodPost, addr+"/jobs", map[string]interface{}{
+		"type":   "range-scatter",
+		"params": &handlers.RangeScatterJobParams{StartKey: hex.EncodeToString([]byte("k2")), EndKey: hex.EncodeToString([]byte("k4"))},
+	}, http.StatusAccepted, &res)
+	re.Equal("range-scatter", res.Type)
+	mustWaitJob(re, addr, res.ID, job.Succeeded, &res)
+	re.Equal(1.0, res.Progress)
+	re.Equal(100.0, res.Result.(map[string]interface{})["split_percentage"])
+	re.Equal(2.0, res.Result.(map[string]interface{})["regions"])
+	mustRequest(re, http.MethodPost, addr+"/jobs", map[string]interface{}{
+		"type": "range-scatter",
+		"params": &handlers.RangeScatterJobParams{
+			StartKey:  hex.EncodeToString([]byte("k2")),
+			EndKey:    hex.EncodeToString([]byte("k4")),
+			SplitKeys: []string{hex.EncodeToString([]byte("k5"))},
+		},
+	}, http.StatusBadRequest, nil)
+	mustRequest(re, http.MethodPost, addr+"/jobs", map[string]interface{}{
+		"type":   "range-scatter",
+		"params": &handlers.RangeScatterJobParams{StartKey: hex.EncodeToString([]byte("k4")), EndKey: hex.EncodeToString([]byte("k2"))},
+	}, http.StatusBadRequest, nil)
+
+	// The drain job is canceled, and the store is up again.
+	mustRequest(re, http.MethodPost, addr+"/jobs", map[string]interface{}{
+		"type":   "drain",
+		"params": &handlers.DrainJobParams{StoreIDs: []uint64{2}},
+	}, http.StatusAccepted, &res)
+	drainID := res.ID
+	re.True(res.Cancelable)
+	re.Equal(metapb.NodeState_Removing, server.GetRaftCluster().GetStore(2).GetNodeState())
+	var jobs []*job.Job
+	mustRequest(re, http.MethodGet, addr+"/jobs?status=running", nil, http.StatusOK, &jobs)
+	re.Len(jobs, 1)
+	re.Equal(drainID, jobs[0].ID)
+	mustRequest(re, http.MethodDelete, fmt.Sprintf("%s/jobs/%d", addr, drainID), nil, http.StatusAccepted, nil)
+	mustWaitJob(re, addr, drainID, job.Canceled, &res)
+	re.Equal(metapb.NodeState_Serving, server.GetRaftCluster().GetStore(2).GetNodeState())
+	mustRequest(re, http.MethodGet, addr+"/jobs?type=scatter", nil, http.StatusOK, &jobs)
+	re.Len(jobs, 1)
+	re.Equal(scatterID, jobs[0].ID)
+
+	// The rule bundle job waits for the regions to satisfy the rules.
+	mustRequest(re, http.MethodPost, addr+"/jobs", map[string]interface{}{
+		"type": "rule-bundle",
+		"params": &handlers.RuleBundleJobParams{
+			Bundles: []placement.GroupBundle{{
+				ID:       "test",
+				Index:    100,
+				Override: true,
+				Rules: []*placement.Rule{{
+					GroupID:     "test",
+					ID:          "single",
+					Role:        placement.Voter,
+					Count:       1,
+					StartKeyHex: hex.EncodeToString([]byte("k1")),
+					EndKeyHex:   hex.EncodeToString([]byte("k2")),
+				}},
+			}},
+			Partial: true,
+		},
+	}, http.StatusAccepted, &res)
+	re.Equal("rule-bundle", res.Type)
+	mustWaitJob(re, addr, res.ID, job.Succeeded, &res)
+	re.Equal(1.0, res.Result.(map[string]interface{})["regions"])
+	re.NotNil(server.GetRaftCluster().GetRuleManager().GetRule("test", "single"))
+	mustRequest(re, http.MethodPost, addr+"/jobs", map[string]interface{}{
+		"type": "rule-bundle",
+		"params": &handlers.RuleBundleJobParams{
+			Bundles: []placement.GroupBundle{{ID: "test", Rules: []*placement.Rule{{GroupID: "test", ID: "invalid", StartKeyHex: "xx"}}}},
+			Partial: true,
+		},
+	}, http.StatusBadRequest, nil)
+
+	// The invalid requests.
+	mustRequest(re, http.MethodPost, addr+"/jobs", map[string]interface{}{"type": "unknown"}, http.StatusBadRequest, nil)
+	mustRequest(re, http.MethodPost, addr+"/jobs", map[string]interface{}{
+		"type":   "drain",
+		"params": &handlers.DrainJobParams{StoreIDs: []uint64{100}},
+	}, http.StatusBadRequest, nil)
+	mustRequest(re, http.MethodGet, addr+"/jobs/100", nil, http.StatusNotFound, nil)
+	mustRequest(re, http.MethodDelete, addr+"/jobs/abc", nil, http.StatusBadRequest, nil)
+}
+
+func mustWaitJob(re *require.Assertions, addr string, id uint64, status job.Status, res *job.Job) {
+	re.Eventually(func() bool {
+		mustRequest(re, http.MethodGet, fmt.Sprintf("%s/jobs/%d", addr, id), nil, http.StatusOK, res)
+		return res.Status == status
+	}, 10*time.Second, 100*time.Millisecond)
+}
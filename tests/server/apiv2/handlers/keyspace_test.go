@@ -15,21 +15,26 @@
 package handlers_test
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/pingcap/kvproto/pkg/keyspacepb"
+	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"github.com/tikv/pd/pkg/utils/testutil"
 	"github.com/tikv/pd/server/apiv2/handlers"
 	"github.com/tikv/pd/server/keyspace"
+	"github.com/tikv/pd/server/schedule/placement"
 	"github.com/tikv/pd/tests"
+	"github.com/tikv/pd/tests/pdctl"
 	"go.uber.org/goleak"
 )
 
@@ -86,6 +91,145 @@ func (suite *keyspaceTestSuite) TestCreateLoadKeyspace() {
 	re.Equal(keyspacepb.KeyspaceState_ENABLED, defaultKeyspace.State)
 }
 
+func (suite *keyspaceTestSuite) TestCreateKeyspaceWithPreSplit() {
+	re := suite.Require()
+	for _, request := range []*handlers.CreateKeyspaceParams{
+		{Name: "too_many_splits", PreSplit: 1000},
+		{Name: "negative_splits", PreSplit: -1},
+		{Name: "unknown_policy", PreSplit: 4, ScatterPolicy: "unknown"},
+	} {
+		data, err := json.Marshal(request)
+		re.NoError(err)
+		resp, err := dialClient.Post(suite.server.GetAddr()+keyspacesPrefix, "application/json", bytes.NewBuffer(data))
+		re.NoError(err)
+		resp.Body.Close()
+		re.Equal(http.StatusInternalServerError, resp.StatusCode)
+		resp, err = dialClient.Get(suite.server.GetAddr() + keyspacesPrefix + "/" + request.Name)
+		re.NoError(err)
+		resp.Body.Close()
+		re.Equal(http.StatusInternalServerError, resp.StatusCode)
+	}
+}
+
+func (suite *keyspaceTestSuite) TestBatchKeyspaces() {
+	re := suite.Require()
+	existing := mustMakeTestKeyspaces(re, suite.server, 1)[0]
+	resp := mustSendBatchRequest(re, suite.server, http.MethodPost, "", &handlers.BatchCreateKeyspaceParams{
+		Keyspaces: []*handlers.CreateKeyspaceParams{
+			{Name: "batch_1", Config: map[string]string{"config1": "100"}},
+			{Name: existing.Name},
+			{Name: "illegal name"},
+			{Name: "batch_2"},
+		},
+	})
+	re.Len(resp.Results, 4)
+	re.Empty(resp.Results[0].Error)
+	re.Equal(map[string]string{"config1": "100"}, resp.Results[0].Keyspace.Config)
+	re.Equal(resp.Results[0].Keyspace.KeyspaceMeta, mustLoadKeyspaces(re, suite.server, "batch_1"))
+	re.NotEmpty(resp.Results[1].Error)
+	re.Nil(resp.Results[1].Keyspace)
+	re.NotEmpty(resp.Results[2].Error)
+	re.Empty(resp.Results[3].Error)
+	re.Equal("batch_2", resp.Results[3].Keyspace.Name)
+
+	config1 := "200"
+	resp = mustSendBatchRequest(re, suite.server, http.MethodPatch, "/config", &handlers.BatchUpdateConfigParams{
+		Keyspaces: []*handlers.BatchUpdateConfigItem{
+			{Name: "batch_1", Config: map[string]*string{"config1": &config1}},
+			{Name: "unknown", Config: map[string]*string{"config1": &config1}},
+			{Name: "batch_2", Config: map[string]*string{"config1": &config1, "config2": nil}},
+		},
+	})
+	re.Len(resp.Results, 3)
+	re.Empty(resp.Results[0].Error)
+	re.Equal(map[string]string{"config1": "200"}, mustLoadKeyspaces(re, suite.server, "batch_1").Config)
+	re.NotEmpty(resp.Results[1].Error)
+	re.Empty(resp.Results[2].Error)
+	re.Equal(map[string]string{"config1": "200"}, mustLoadKeyspaces(re, suite.server, "batch_2").Config)
+
+	resp = mustSendBatchRequest(re, suite.server, http.MethodPut, "/state", &handlers.BatchUpdateStateParams{
+		Names: []string{"batch_1", keyspace.DefaultKeyspaceName, "batch_2"},
+		State: "disabled",
+	})
+	re.Len(resp.Results, 3)
+	re.Equal(keyspacepb.KeyspaceState_DISABLED, resp.Results[0].Keyspace.State)
+	re.NotEmpty(resp.Results[1].Error)
+	re.Equal(keyspacepb.KeyspaceState_DISABLED, resp.Results[2].Keyspace.State)
+	re.Equal(keyspacepb.KeyspaceState_DISABLED, mustLoadKeyspaces(re, suite.server, "batch_2").State)
+	re.Equal(keyspacepb.KeyspaceState_ENABLED, mustLoadKeyspaces(re, suite.server, keyspace.DefaultKeyspaceName).State)
+
+	// Unknown state and oversized batch are rejected as a whole.
+	data, err := json.Marshal(&handlers.BatchUpdateStateParams{Names: []string{"batch_1"}, State: "unknown"})
+	re.NoError(err)
+	httpReq, err := http.NewRequest(http.MethodPut, suite.server.GetAddr()+keyspacesPrefix+"/batch/state", bytes.NewBuffer(data))
+	re.NoError(err)
+	httpResp, err := dialClient.Do(httpReq)
+	re.NoError(err)
+	httpResp.Body.Close()
+	re.Equal(http.StatusBadRequest, httpResp.StatusCode)
+	tooMany := &handlers.BatchCreateKeyspaceParams{}
+	for i := 0; i <= keyspace.MaxBatchSize; i++ {
+		tooMany.Keyspaces = append(tooMany.Keyspaces, &handlers.CreateKeyspaceParams{Name: fmt.Sprintf("too_many_%d", i)})
+	}
+	data, err = json.Marshal(tooMany)
+	re.NoError(err)
+	httpResp, err = dialClient.Post(suite.server.GetAddr()+keyspacesPrefix+"/batch", "application/json", bytes.NewBuffer(data))
+	re.NoError(err)
+	httpResp.Body.Close()
+	re.Equal(http.StatusInternalServerError, httpResp.StatusCode)
+}
+
+func (suite *keyspaceTestSuite) TestWatchKeyspaces() {
+	re := suite.Require()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, suite.server.GetAddr()+keyspacesPrefix+"?watch=true", nil)
+	re.NoError(err)
+	resp, err := dialClient.Do(httpReq)
+	re.NoError(err)
+	defer resp.Body.Close()
+	re.Equal(http.StatusOK, resp.StatusCode)
+	re.Equal("text/event-stream", resp.Header.Get("Content-Type"))
+	reader := bufio.NewReader(resp.Body)
+	// The existing keyspaces are sent first.
+	id, event, meta := mustReadKeyspaceEvent(re, reader)
+	re.NotEmpty(id)
+	re.Equal("snapshot", event)
+	re.Equal(keyspace.DefaultKeyspaceName, meta.Name)
+
+	created := mustMakeTestKeyspaces(re, suite.server, 1)[0]
+	id, event, meta = mustReadKeyspaceEvent(re, reader)
+	re.NotEmpty(id)
+	re.Equal("create", event)
+	re.Equal(created, meta)
+	success, _ := sendUpdateStateRequest(re, suite.server, created.Name, &handlers.UpdateStateParam{State: "disabled"})
+	re.True(success)
+	lastID, event, meta := mustReadKeyspaceEvent(re, reader)
+	re.Equal("state", event)
+	re.Equal(keyspacepb.KeyspaceState_DISABLED, meta.State)
+
+	// Resuming from the last event id receives the changes since that revision.
+	httpReq, err = http.NewRequestWithContext(ctx, http.MethodGet, suite.server.GetAddr()+keyspacesPrefix+"?watch=true", nil)
+	re.NoError(err)
+	httpReq.Header.Set("Last-Event-ID", lastID)
+	resumed, err := dialClient.Do(httpReq)
+	re.NoError(err)
+	defer resumed.Body.Close()
+	re.Equal(http.StatusOK, resumed.StatusCode)
+	id, event, meta = mustReadKeyspaceEvent(re, bufio.NewReader(resumed.Body))
+	re.Equal(lastID, id)
+	re.Equal("state", event)
+	re.Equal(created.Name, meta.Name)
+
+	httpReq, err = http.NewRequest(http.MethodGet, suite.server.GetAddr()+keyspacesPrefix+"?watch=true", nil)
+	re.NoError(err)
+	httpReq.Header.Set("Last-Event-ID", "invalid")
+	invalid, err := dialClient.Do(httpReq)
+	re.NoError(err)
+	invalid.Body.Close()
+	re.Equal(http.StatusBadRequest, invalid.StatusCode)
+}
+
 func (suite *keyspaceTestSuite) TestUpdateKeyspaceConfig() {
 	re := suite.Require()
 	keyspaces := mustMakeTestKeyspaces(re, suite.server, 10)
@@ -137,6 +281,62 @@ func (suite *keyspaceTestSuite) TestUpdateKeyspaceState() {
 	re.False(success)
 }
 
+func (suite *keyspaceTestSuite) TestRenameKeyspace() {
+	re := suite.Require()
+	keyspaces := mustMakeTestKeyspaces(re, suite.server, 2)
+	created, other := keyspaces[0], keyspaces[1]
+	newName := created.Name + "_renamed"
+	renamed := mustRenameKeyspace(re, suite.server, created.Name, newName)
+	re.Equal(created.Id, renamed.Id)
+	re.Equal(newName, renamed.Name)
+	// The old name is kept as an alias.
+	re.Equal(renamed, mustLoadKeyspaces(re, suite.server, created.Name))
+	re.Equal(renamed, mustLoadKeyspaces(re, suite.server, newName))
+
+	// Renaming to the name or the alias of another keyspace is not allowed.
+	for _, name := range []string{newName, created.Name} {
+		data, err := json.Marshal(&handlers.RenameParams{NewName: name})
+		re.NoError(err)
+		resp, err := dialClient.Post(suite.server.GetAddr()+keyspacesPrefix+"/"+other.Name+"/rename", "application/json", bytes.NewBuffer(data))
+		re.NoError(err)
+		resp.Body.Close()
+		re.Equal(http.StatusInternalServerError, resp.StatusCode)
+	}
+}
+
+func (suite *keyspaceTestSuite) TestArchiveRestoreKeyspace() {
+	re := suite.Require()
+	labeler := suite.server.GetRaftCluster().GetRegionLabeler()
+	keyspaces := mustMakeTestKeyspaces(re, suite.server, 3)
+	for _, created := range keyspaces {
+		archiveRuleID := fmt.Sprintf("keyspaces/%d/archive", created.Id)
+		// Should NOT allow archiving ENABLED keyspace.
+		success, _ := sendArchiveRequest(re, suite.server, created.Name, "archive")
+		re.False(success)
+		re.Nil(labeler.GetLabelRule(archiveRuleID))
+		success, _ = sendUpdateStateRequest(re, suite.server, created.Name, &handlers.UpdateStateParam{State: "disabled"})
+		re.True(success)
+		// Archiving a DISABLED keyspace deprioritizes the scheduling of its regions.
+		success, archived := sendArchiveRequest(re, suite.server, created.Name, "archive")
+		re.True(success)
+		re.Equal(keyspacepb.KeyspaceState_ARCHIVED, archived.State)
+		rule := labeler.GetLabelRule(archiveRuleID)
+		re.NotNil(rule)
+		re.Equal("low", rule.Labels[0].Value)
+		// Restoring an ARCHIVED keyspace makes it DISABLED and removes the archive policy.
+		success, restored := sendArchiveRequest(re, suite.server, created.Name, "restore")
+		re.True(success)
+		re.Equal(keyspacepb.KeyspaceState_DISABLED, restored.State)
+		re.Nil(labeler.GetLabelRule(archiveRuleID))
+		// Restoring a DISABLED keyspace is allowed and changes nothing.
+		success, restoredAgain := sendArchiveRequest(re, suite.server, created.Name, "restore")
+		re.True(success)
+		re.Equal(restored, restoredAgain)
+	}
+	success, _ := sendArchiveRequest(re, suite.server, "unknown", "archive")
+	re.False(success)
+}
+
 func (suite *keyspaceTestSuite) TestLoadRangeKeyspace() {
 	re := suite.Require()
 	keyspaces := mustMakeTestKeyspaces(re, suite.server, 50)
@@ -151,6 +351,138 @@ func (suite *keyspaceTestSuite) TestLoadRangeKeyspace() {
 	re.Equal(keyspacepb.KeyspaceState_ENABLED, loadResponse.Keyspaces[0].State)
 }
 
+func (suite *keyspaceTestSuite) TestKeyspaceQuota() {
+	re := suite.Require()
+	created := mustMakeTestKeyspaces(re, suite.server, 1)[0]
+	usage := mustSetKeyspaceQuota(re, suite.server, created.Name, &keyspace.Quota{MaxRegions: 10, MaxApproximateSize: 1024})
+	re.Equal(created.Id, usage.KeyspaceID)
+	re.Equal(created.Name, usage.Name)
+	re.Equal(keyspace.Quota{MaxRegions: 10, MaxApproximateSize: 1024}, usage.Quota)
+	// The only region of the bootstrapped cluster covers the keyspace.
+	re.NotZero(usage.Regions)
+	re.False(usage.Exceeded)
+	re.Equal(usage, mustLoadKeyspaceQuota(re, suite.server, created.Name))
+
+	usage = mustSetKeyspaceQuota(re, suite.server, created.Name, &keyspace.Quota{})
+	re.True(usage.Quota.IsUnlimited())
+	re.False(usage.Exceeded)
+
+	resp, err := dialClient.Get(suite.server.GetAddr() + keyspacesPrefix + "/unknown/quota")
+	re.NoError(err)
+	defer resp.Body.Close()
+	re.Equal(http.StatusInternalServerError, resp.StatusCode)
+}
+
+func (suite *keyspaceTestSuite) TestKeyspacePlacement() {
+	re := suite.Require()
+	ruleManager := suite.server.GetRaftCluster().GetRuleManager()
+	// The rule manager rejects rules that can not match any store.
+	pdctl.MustPutStore(re, suite.server.GetServer(), &metapb.Store{
+		Id:     2,
+		State:  metapb.StoreState_Up,
+		Labels: []*metapb.StoreLabel{{Key: "tenant", Value: "a"}},
+	})
+	created := mustMakeTestKeyspaces(re, suite.server, 1)[0]
+	groupID := fmt.Sprintf("keyspace-placement-%d", created.Id)
+	re.Equal(http.StatusNotFound, sendPlacementRequest(re, suite.server, http.MethodGet, created.Name, nil))
+
+	template := &keyspace.PlacementTemplate{Rules: []*keyspace.PlacementRule{{
+		ID:               "voters",
+		Role:             placement.Voter,
+		Count:            3,
+		LabelConstraints: []placement.LabelConstraint{{Key: "tenant", Op: placement.In, Values: []string{"a"}}},
+	}}}
+	re.Equal(http.StatusOK, sendPlacementRequest(re, suite.server, http.MethodPut, created.Name, template))
+	re.Equal(template, mustLoadKeyspacePlacement(re, suite.server, created.Name))
+	bundle := ruleManager.GetGroupBundle(groupID)
+	re.True(bundle.Override)
+	re.Len(bundle.Rules, 2)
+	// Updating the template replaces the rules of the keyspace.
+	template.Rules = append(template.Rules, &keyspace.PlacementRule{ID: "learners", Role: placement.Learner, Count: 1})
+	re.Equal(http.StatusOK, sendPlacementRequest(re, suite.server, http.MethodPut, created.Name, template))
+	re.Equal(template, mustLoadKeyspacePlacement(re, suite.server, created.Name))
+	re.Len(ruleManager.GetGroupBundle(groupID).Rules, 4)
+	// Invalid templates are rejected and the current one is kept.
+	invalid := &keyspace.PlacementTemplate{Rules: []*keyspace.PlacementRule{{ID: "learners", Role: placement.Learner, Count: 1}}}
+	re.Equal(http.StatusInternalServerError, sendPlacementRequest(re, suite.server, http.MethodPut, created.Name, invalid))
+	re.Equal(http.StatusInternalServerError, sendPlacementRequest(re, suite.server, http.MethodPut, created.Name, &keyspace.PlacementTemplate{}))
+	re.Equal(template, mustLoadKeyspacePlacement(re, suite.server, created.Name))
+	re.Len(ruleManager.GetGroupBundle(groupID).Rules, 4)
+
+	re.Equal(http.StatusOK, sendPlacementRequest(re, suite.server, http.MethodDelete, created.Name, nil))
+	re.Equal(http.StatusNotFound, sendPlacementRequest(re, suite.server, http.MethodGet, created.Name, nil))
+	re.Empty(ruleManager.GetGroupBundle(groupID).Rules)
+	re.Equal(http.StatusInternalServerError, sendPlacementRequest(re, suite.server, http.MethodPut, "unknown", template))
+}
+
+func (suite *keyspaceTestSuite) TestKeyspaceUsage() {
+	re := suite.Require()
+	created := mustMakeTestKeyspaces(re, suite.server, 1)[0]
+	resp, err := dialClient.Get(suite.server.GetAddr() + keyspacesPrefix + "/" + created.Name + "/usage")
+	re.NoError(err)
+	defer resp.Body.Close()
+	re.Equal(http.StatusOK, resp.StatusCode)
+	data, err := io.ReadAll(resp.Body)
+	re.NoError(err)
+	usage := &keyspace.Usage{}
+	re.NoError(json.Unmarshal(data, usage))
+	re.Equal(created.Id, usage.KeyspaceID)
+	re.Equal(created.Name, usage.Name)
+	// The only region of the bootstrapped cluster covers the keyspace.
+	re.NotZero(usage.Regions)
+
+	resp2, err := dialClient.Get(suite.server.GetAddr() + keyspacesPrefix + "/unknown/usage")
+	re.NoError(err)
+	defer resp2.Body.Close()
+	re.Equal(http.StatusInternalServerError, resp2.StatusCode)
+}
+
+func (suite *keyspaceTestSuite) TestKeyspaceGCSafePoint() {
+	re := suite.Require()
+	keyspaces := mustMakeTestKeyspaces(re, suite.server, 2)
+	blocked, other := keyspaces[0], keyspaces[1]
+	// A stale service safepoint of one keyspace does not block the GC of the other.
+	resp := mustUpdateKeyspaceServiceGCSafePoint(re, suite.server, blocked.Name,
+		&handlers.UpdateServiceGCSafePointParams{ServiceID: "br", SafePoint: 10, TTL: 3600})
+	re.True(resp.Updated)
+	re.Equal(uint64(10), resp.MinServiceGCSafePoint.SafePoint)
+	resp = mustUpdateKeyspaceServiceGCSafePoint(re, suite.server, other.Name,
+		&handlers.UpdateServiceGCSafePointParams{ServiceID: "cdc", SafePoint: 100, TTL: 3600})
+	re.True(resp.Updated)
+	re.Equal(uint64(100), resp.MinServiceGCSafePoint.SafePoint)
+
+	safePoint := mustUpdateKeyspaceGCSafePoint(re, suite.server, other.Name, 100)
+	re.Equal(other.Id, safePoint.KeyspaceID)
+	re.Equal(uint64(100), safePoint.GCSafePoint)
+	re.Len(safePoint.ServiceGCSafePoints, 1)
+	re.Equal("cdc", safePoint.ServiceGCSafePoints[0].ServiceID)
+	// The GC safepoint can not be moved backward.
+	safePoint = mustUpdateKeyspaceGCSafePoint(re, suite.server, other.Name, 50)
+	re.Equal(uint64(100), safePoint.GCSafePoint)
+	// A service safepoint less than the GC safepoint is rejected.
+	resp = mustUpdateKeyspaceServiceGCSafePoint(re, suite.server, other.Name,
+		&handlers.UpdateServiceGCSafePointParams{ServiceID: "br", SafePoint: 80, TTL: 3600})
+	re.False(resp.Updated)
+
+	safePoint = mustLoadKeyspaceGCSafePoint(re, suite.server, blocked.Name)
+	re.Zero(safePoint.GCSafePoint)
+	re.Len(safePoint.ServiceGCSafePoints, 1)
+	re.Equal(uint64(10), safePoint.ServiceGCSafePoints[0].SafePoint)
+
+	httpReq, err := http.NewRequest(http.MethodDelete, suite.server.GetAddr()+keyspacesPrefix+"/"+blocked.Name+"/gc/service_safepoint/br", nil)
+	re.NoError(err)
+	httpResp, err := dialClient.Do(httpReq)
+	re.NoError(err)
+	httpResp.Body.Close()
+	re.Equal(http.StatusOK, httpResp.StatusCode)
+	re.Empty(mustLoadKeyspaceGCSafePoint(re, suite.server, blocked.Name).ServiceGCSafePoints)
+
+	httpResp, err = dialClient.Get(suite.server.GetAddr() + keyspacesPrefix + "/unknown/gc/safepoint")
+	re.NoError(err)
+	httpResp.Body.Close()
+	re.Equal(http.StatusInternalServerError, httpResp.StatusCode)
+}
+
 func sendLoadRangeRequest(re *require.Assertions, server *tests.TestServer, token, limit string) *handlers.LoadAllKeyspacesResponse {
 	// Construct load range request.
 	httpReq, err := http.NewRequest(http.MethodGet, server.GetAddr()+keyspacesPrefix, nil)
@@ -172,6 +504,44 @@ func sendLoadRangeRequest(re *require.Assertions, server *tests.TestServer, toke
 	return resp
 }
 
+func mustSendBatchRequest(re *require.Assertions, server *tests.TestServer, method, path string, params interface{}) *handlers.BatchResponse {
+	data, err := json.Marshal(params)
+	re.NoError(err)
+	httpReq, err := http.NewRequest(method, server.GetAddr()+keyspacesPrefix+"/batch"+path, bytes.NewBuffer(data))
+	re.NoError(err)
+	resp, err := dialClient.Do(httpReq)
+	re.NoError(err)
+	defer resp.Body.Close()
+	re.Equal(http.StatusOK, resp.StatusCode)
+	data, err = io.ReadAll(resp.Body)
+	re.NoError(err)
+	batchResp := &handlers.BatchResponse{}
+	re.NoError(json.Unmarshal(data, batchResp))
+	return batchResp
+}
+
+// mustReadKeyspaceEvent reads a server-sent event of keyspace from the reader.
+func mustReadKeyspaceEvent(re *require.Assertions, reader *bufio.Reader) (id, event string, meta *keyspacepb.KeyspaceMeta) {
+	for {
+		line, err := reader.ReadString('\n')
+		re.NoError(err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			re.NotNil(meta)
+			return id, event, meta
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data := &handlers.KeyspaceMeta{}
+			re.NoError(json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), data))
+			meta = data.KeyspaceMeta
+		}
+	}
+}
+
 func sendUpdateStateRequest(re *require.Assertions, server *tests.TestServer, name string, request *handlers.UpdateStateParam) (bool, *keyspacepb.KeyspaceMeta) {
 	data, err := json.Marshal(request)
 	re.NoError(err)
@@ -189,6 +559,22 @@ func sendUpdateStateRequest(re *require.Assertions, server *tests.TestServer, na
 	re.NoError(json.Unmarshal(data, meta))
 	return true, meta.KeyspaceMeta
 }
+
+// sendArchiveRequest sends the archive or restore request of the keyspace.
+func sendArchiveRequest(re *require.Assertions, server *tests.TestServer, name, action string) (bool, *keyspacepb.KeyspaceMeta) {
+	httpResp, err := dialClient.Post(server.GetAddr()+keyspacesPrefix+"/"+name+"/"+action, "application/json", nil)
+	re.NoError(err)
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return false, nil
+	}
+	data, err := io.ReadAll(httpResp.Body)
+	re.NoError(err)
+	meta := &handlers.KeyspaceMeta{}
+	re.NoError(json.Unmarshal(data, meta))
+	return true, meta.KeyspaceMeta
+}
+
 func mustMakeTestKeyspaces(re *require.Assertions, server *tests.TestServer, count int) []*keyspacepb.KeyspaceMeta {
 	testConfig := map[string]string{
 		"config1": "100",
@@ -250,6 +636,119 @@ func mustLoadKeyspaces(re *require.Assertions, server *tests.TestServer, name st
 	return meta.KeyspaceMeta
 }
 
+func mustSetKeyspaceQuota(re *require.Assertions, server *tests.TestServer, name string, quota *keyspace.Quota) *keyspace.QuotaUsage {
+	data, err := json.Marshal(quota)
+	re.NoError(err)
+	httpReq, err := http.NewRequest(http.MethodPut, server.GetAddr()+keyspacesPrefix+"/"+name+"/quota", bytes.NewBuffer(data))
+	re.NoError(err)
+	resp, err := dialClient.Do(httpReq)
+	re.NoError(err)
+	defer resp.Body.Close()
+	re.Equal(http.StatusOK, resp.StatusCode)
+	data, err = io.ReadAll(resp.Body)
+	re.NoError(err)
+	usage := &keyspace.QuotaUsage{}
+	re.NoError(json.Unmarshal(data, usage))
+	return usage
+}
+
+func mustLoadKeyspaceQuota(re *require.Assertions, server *tests.TestServer, name string) *keyspace.QuotaUsage {
+	resp, err := dialClient.Get(server.GetAddr() + keyspacesPrefix + "/" + name + "/quota")
+	re.NoError(err)
+	defer resp.Body.Close()
+	re.Equal(http.StatusOK, resp.StatusCode)
+	data, err := io.ReadAll(resp.Body)
+	re.NoError(err)
+	usage := &keyspace.QuotaUsage{}
+	re.NoError(json.Unmarshal(data, usage))
+	return usage
+}
+
+func sendPlacementRequest(re *require.Assertions, server *tests.TestServer, method, name string, template *keyspace.PlacementTemplate) int {
+	var body io.Reader
+	if template != nil {
+		data, err := json.Marshal(template)
+		re.NoError(err)
+		body = bytes.NewBuffer(data)
+	}
+	httpReq, err := http.NewRequest(method, server.GetAddr()+keyspacesPrefix+"/"+name+"/placement", body)
+	re.NoError(err)
+	resp, err := dialClient.Do(httpReq)
+	re.NoError(err)
+	defer resp.Body.Close()
+	return resp.StatusCode
+}
+
+func mustLoadKeyspacePlacement(re *require.Assertions, server *tests.TestServer, name string) *keyspace.PlacementTemplate {
+	resp, err := dialClient.Get(server.GetAddr() + keyspacesPrefix + "/" + name + "/placement")
+	re.NoError(err)
+	defer resp.Body.Close()
+	re.Equal(http.StatusOK, resp.StatusCode)
+	data, err := io.ReadAll(resp.Body)
+	re.NoError(err)
+	template := &keyspace.PlacementTemplate{}
+	re.NoError(json.Unmarshal(data, template))
+	return template
+}
+
+func mustRenameKeyspace(re *require.Assertions, server *tests.TestServer, name, newName string) *keyspacepb.KeyspaceMeta {
+	data, err := json.Marshal(&handlers.RenameParams{NewName: newName})
+	re.NoError(err)
+	resp, err := dialClient.Post(server.GetAddr()+keyspacesPrefix+"/"+name+"/rename", "application/json", bytes.NewBuffer(data))
+	re.NoError(err)
+	defer resp.Body.Close()
+	re.Equal(http.StatusOK, resp.StatusCode)
+	data, err = io.ReadAll(resp.Body)
+	re.NoError(err)
+	meta := &handlers.KeyspaceMeta{}
+	re.NoError(json.Unmarshal(data, meta))
+	return meta.KeyspaceMeta
+}
+
+func mustLoadKeyspaceGCSafePoint(re *require.Assertions, server *tests.TestServer, name string) *handlers.KeyspaceGCSafePoint {
+	resp, err := dialClient.Get(server.GetAddr() + keyspacesPrefix + "/" + name + "/gc/safepoint")
+	re.NoError(err)
+	defer resp.Body.Close()
+	re.Equal(http.StatusOK, resp.StatusCode)
+	data, err := io.ReadAll(resp.Body)
+	re.NoError(err)
+	safePoint := &handlers.KeyspaceGCSafePoint{}
+	re.NoError(json.Unmarshal(data, safePoint))
+	return safePoint
+}
+
+func mustUpdateKeyspaceGCSafePoint(re *require.Assertions, server *tests.TestServer, name string, safePoint uint64) *handlers.KeyspaceGCSafePoint {
+	data, err := json.Marshal(&handlers.UpdateGCSafePointParams{SafePoint: safePoint})
+	re.NoError(err)
+	httpReq, err := http.NewRequest(http.MethodPut, server.GetAddr()+keyspacesPrefix+"/"+name+"/gc/safepoint", bytes.NewBuffer(data))
+	re.NoError(err)
+	resp, err := dialClient.Do(httpReq)
+	re.NoError(err)
+	defer resp.Body.Close()
+	re.Equal(http.StatusOK, resp.StatusCode)
+	data, err = io.ReadAll(resp.Body)
+	re.NoError(err)
+	updated := &handlers.KeyspaceGCSafePoint{}
+	re.NoError(json.Unmarshal(data, updated))
+	return updated
+}
+
+func mustUpdateKeyspaceServiceGCSafePoint(re *require.Assertions, server *tests.TestServer, name string, request *handlers.UpdateServiceGCSafePointParams) *handlers.UpdateServiceGCSafePointResponse {
+	data, err := json.Marshal(request)
+	re.NoError(err)
+	httpReq, err := http.NewRequest(http.MethodPut, server.GetAddr()+keyspacesPrefix+"/"+name+"/gc/service_safepoint", bytes.NewBuffer(data))
+	re.NoError(err)
+	resp, err := dialClient.Do(httpReq)
+	re.NoError(err)
+	defer resp.Body.Close()
+	re.Equal(http.StatusOK, resp.StatusCode)
+	data, err = io.ReadAll(resp.Body)
+	re.NoError(err)
+	updated := &handlers.UpdateServiceGCSafePointResponse{}
+	re.NoError(json.Unmarshal(data, updated))
+	return updated
+}
+
 // checkCreateRequest verifies a keyspace meta matches a create request.
 func checkCreateRequest(re *require.Assertions, request *handlers.CreateKeyspaceParams, meta *keyspacepb.KeyspaceMeta) {
 	re.Equal(request.Name, meta.Name)
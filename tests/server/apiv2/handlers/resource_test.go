@@ -0,0 +1,307 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/core"
+	"github.com/tikv/pd/pkg/errs"
+	"github.com/tikv/pd/server/apiv2/handlers"
+	"github.com/tikv/pd/server/apiv2/middlewares"
+	"github.com/tikv/pd/tests"
+	"github.com/tikv/pd/tests/pdctl"
+)
+
+func TestResources(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	re.NoError(err)
+	defer cluster.Destroy()
+	re.NoError(cluster.RunInitialServers())
+	re.NotEmpty(cluster.WaitLeader())
+	server := cluster.GetServer(cluster.GetLeader())
+	re.NoError(server.BootstrapCluster())
+	addr := server.GetAddr() + "/pd/api/v2"
+	for id := uint64(2); id <= 4; id++ {
+		pdctl.MustPutStore(re, server.GetServer(), &metapb.Store{
+			Id:        id,
+			State:     metapb.StoreState_Up,
+			NodeState: metapb.NodeState_Serving,
+			Labels:    []*metapb.StoreLabel{{Key: "zone", Value: fmt.Sprintf("z%d", id%2)}},
+		})
+	}
+	for id := uint64(1); id <= 5; id++ {
+		pdctl.MustPutRegion(re, cluster, id, 2, []byte(fmt.Sprintf("k%d", id)), []byte(fmt.Sprintf("k%d", id+1)), core.SetApproximateSize(int64(id)))
+	}
+
+	// The stores.
+	var stores handlers.StoresResponse
+	mustRequest(re, http.MethodGet, addr+"/stores?label=zone:z0", nil, http.StatusOK, &stores)
+	re.Equal(2, stores.Count)
+	re.Equal(uint64(2), stores.Stores[0].ID)
+	re.Equal(uint64(4), stores.Stores[1].ID)
+	mustRequest(re, http.MethodGet, addr+"/stores?label=zone:z1&label=zone:z0", nil, http.StatusOK, &stores)
+	re.Zero(stores.Count)
+	mustRequest(re, http.MethodGet, addr+"/stores?sort=id&order=desc&limit=2", nil, http.StatusOK, &stores)
+	re.Len(stores.Stores, 2)
+	re.Equal(uint64(4), stores.Stores[0].ID)
+	re.Equal(uint64(3), stores.Stores[1].ID)
+	re.NotEmpty(stores.NextCursor)
+	mustRequest(re, http.MethodGet, addr+"/stores?sort=id&order=desc&limit=2&cursor="+stores.NextCursor, nil, http.StatusOK, &stores)
+	re.Len(stores.Stores, 2)
+	re.Equal(uint64(2), stores.Stores[0].ID)
+	re.Equal(uint64(1), stores.Stores[1].ID)
+	re.Empty(stores.NextCursor)
+	mustRequest(re, http.MethodGet, addr+"/stores?sort=region_count&cursor=abc", nil, http.StatusBadRequest, nil)
+	var store handlers.Store
+	mustRequest(re, http.MethodGet, addr+"/stores/2", nil, http.StatusOK, &store)
+	re.Equal(uint64(2), store.ID)
+
+	// The topology.
+	var topology handlers.Topology
+	mustRequest(re, http.MethodGet, addr+"/topology?levels=zone", nil, http.StatusOK, &topology)
+	re.Equal([]string{"zone"}, topology.Levels)
+	re.Equal(4, topology.Root.StoreCount)
+	re.Len(topology.Root.Children, 3)
+	re.Empty(topology.Root.Children[0].Value)
+	re.Equal(uint64(1), topology.Root.Children[0].Stores[0].ID)
+	re.Equal("z0", topology.Root.Children[1].Value)
+	re.Equal(2, topology.Root.Children[1].StoreCount)
+	re.Equal(uint64(2), topology.Root.Children[1].Stores[0].ID)
+	re.Equal(uint64(4), topology.Root.Children[1].Stores[1].ID)
+	re.Equal(topology.Root.Children[1].Stores[0].RegionCount+topology.Root.Children[1].Stores[1].RegionCount, topology.Root.Children[1].RegionCount)
+	re.Equal(topology.Root.Children[1].Stores[0].LeaderCount+topology.Root.Children[1].Stores[1].LeaderCount, topology.Root.Children[1].LeaderCount)
+	mustRequest(re, http.MethodGet, addr+"/topology", nil, http.StatusOK, &topology)
+	re.Equal([]string{"zone", "rack", "host"}, topology.Levels)
+	re.Equal("zone", topology.Root.Children[1].Label)
+	re.Equal("rack", topology.Root.Children[1].Children[0].Label)
+	mustRequest(re, http.MethodGet, addr+"/topology?levels=zone,,host", nil, http.StatusBadRequest, nil)
+
+	// The slow stores.
+	var slowStores handlers.SlowStoresResponse
+	mustRequest(re, http.MethodGet, addr+"/stores/slow", nil, http.StatusOK, &slowStores)
+	re.Empty(slowStores.Detectors)
+	re.Len(slowStores.Stores, 4)
+	re.Equal("normal", slowStores.Stores[0].State)
+	re.Empty(slowStores.Evictions)
+	mustRequest(re, http.MethodGet, addr+"/stores/slow?store_id=2", nil, http.StatusOK, &slowStores)
+	re.Len(slowStores.Stores, 1)
+	re.Equal(uint64(2), slowStores.Stores[0].StoreID)
+	mustRequest(re, http.MethodGet, addr+"/stores/slow?store_id=abc", nil, http.StatusBadRequest, nil)
+
+	// The batch operations on the stores.
+	var batch handlers.StoreBatchResponse
+	mustRequest(re, http.MethodPost, addr+"/stores/batch", &handlers.BatchStoreParams{
+		Selector:  &handlers.StoreSelector{Labels: map[string]string{"zone": "z0"}},
+		Operation: "set-labels",
+		Labels:    map[string]string{"rack": "r1"},
+	}, http.StatusOK, &batch)
+	re.Len(batch.Results, 2)
+	for _, result := range batch.Results {
+		re.Empty(result.Error)
+		re.Equal("r1", result.Store.Labels["rack"])
+		re.Equal("z0", result.Store.Labels["zone"])
+	}
+	mustRequest(re, http.MethodGet, addr+"/stores?label=rack:r1", nil, http.StatusOK, &stores)
+	re.Equal(2, stores.Count)
+	weight := 2.0
+	mustRequest(re, http.MethodPost, addr+"/stores/batch", &handlers.BatchStoreParams{
+		Selector:     &handlers.StoreSelector{IDs: []uint64{3, 100}},
+		Operation:    "set-weight",
+		LeaderWeight: &weight,
+	}, http.StatusOK, &batch)
+	re.Len(batch.Results, 1)
+	re.Equal(2.0, batch.Results[0].Store.LeaderWeight)
+	re.Equal(1.0, batch.Results[0].Store.RegionWeight)
+	mustRequest(re, http.MethodPost, addr+"/stores/batch", &handlers.BatchStoreParams{
+		Selector:  &handlers.StoreSelector{All: true},
+		Operation: "drain",
+		DryRun:    true,
+	}, http.StatusOK, &batch)
+	re.Len(batch.Results, 4)
+	re.Equal(metapb.StoreState_Up.String(), batch.Results[0].Store.State)
+	mustRequest(re, http.MethodPost, addr+"/stores/batch", &handlers.BatchStoreParams{
+		Selector:  &handlers.StoreSelector{},
+		Operation: "drain",
+	}, http.StatusBadRequest, nil)
+	mustRequest(re, http.MethodPost, addr+"/stores/batch", &handlers.BatchStoreParams{
+		Selector:  &handlers.StoreSelector{All: true},
+		Operation: "unknown",
+	}, http.StatusBadRequest, nil)
+
+	// The error envelope.
+	var errResp middlewares.ErrorResponse
+	mustRequest(re, http.MethodGet, addr+"/stores/100", nil, http.StatusNotFound, &errResp)
+	re.Equal("PD:core:ErrStoreNotFound", errResp.Code)
+	re.Equal("core", errResp.Component)
+	re.False(errResp.Retryable)
+	re.Equal("100", errResp.Details["store-id"])
+	re.NotEmpty(errResp.Message)
+	errResp = middlewares.ErrorResponse{}
+	mustRequest(re, http.MethodGet, addr+"/stores/abc", nil, http.StatusBadRequest, &errResp)
+	re.Equal(http.StatusText(http.StatusBadRequest), errResp.Code)
+	re.Equal(errs.UnknownComponent, errResp.Component)
+	re.Empty(errResp.Details)
+
+	// The regions.
+	var regions handlers.ScanRegionsResponse
+	mustRequest(re, http.MethodGet, addr+"/regions?store_id=2&limit=3", nil, http.StatusOK, &regions)
+	re.Len(regions.Regions, 3)
+	re.Equal(uint64(1), regions.Regions[0].ID)
+	re.NotEmpty(regions.NextCursor)
+	mustRequest(re, http.MethodGet, addr+"/regions?store_id=2&limit=3&cursor="+regions.NextCursor, nil, http.StatusOK, &regions)
+	re.Len(regions.Regions, 2)
+	re.Equal(uint64(4), regions.Regions[0].ID)
+	re.Empty(regions.NextCursor)
+	mustRequest(re, http.MethodGet, addr+"/regions?sort=size&order=desc&min_size=2&limit=2", nil, http.StatusOK, &regions)
+	re.Len(regions.Regions, 2)
+	re.Equal(uint64(5), regions.Regions[0].ID)
+	re.Equal(uint64(4), regions.Regions[1].ID)
+	mustRequest(re, http.MethodGet, addr+"/regions?sort=size&order=desc&min_size=2&limit=2&cursor="+regions.NextCursor, nil, http.StatusOK, &regions)
+	re.Len(regions.Regions, 2)
+	re.Equal(uint64(3), regions.Regions[0].ID)
+	re.Equal(uint64(2), regions.Regions[1].ID)
+	re.Empty(regions.NextCursor)
+	mustRequest(re, http.MethodGet, addr+"/regions?store_id=3", nil, http.StatusOK, &regions)
+	re.Empty(regions.Regions)
+	var problems handlers.RegionProblemsReport
+	mustRequest(re, http.MethodGet, addr+"/regions/problems?sample=2", nil, http.StatusOK, &problems)
+	re.Equal(5, problems.RegionCount)
+	re.Equal(5, problems.Total)
+	re.Equal(5, problems.Problems["miss-peer"].Count)
+	re.Len(problems.Problems["miss-peer"].Samples, 2)
+	re.Equal(uint64(1), problems.Problems["miss-peer"].Samples[0].ID)
+	re.Equal(uint64(2), problems.Problems["miss-peer"].Samples[1].ID)
+	re.Equal(1, problems.Problems["empty-region"].Count)
+	re.Equal(uint64(1), problems.Problems["empty-region"].Samples[0].ID)
+	re.Zero(problems.Problems["down-peer"].Count)
+	re.Empty(problems.Problems["down-peer"].Samples)
+	mustRequest(re, http.MethodGet, addr+"/regions/problems?sample=-1", nil, http.StatusBadRequest, nil)
+	getRegions := func(etag string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, addr+"/regions", http.NoBody)
+		re.NoError(err)
+		req.Header.Set("If-None-Match", etag)
+		r, err := dialClient.Do(req)
+		re.NoError(err)
+		r.Body.Close()
+		return r
+	}
+	regionsResp := getRegions("")
+	re.Equal(http.StatusOK, regionsResp.StatusCode)
+	etag := regionsResp.Header.Get("ETag")
+	re.NotEmpty(etag)
+	re.Equal(http.StatusNotModified, getRegions(etag).StatusCode)
+
+	// The schedulers.
+	var schedulers []*handlers.Scheduler
+	mustRequest(re, http.MethodPost, addr+"/schedulers",
+		&handlers.CreateSchedulerParams{Type: "evict-leader", Args: []string{"2"}}, http.StatusOK, &schedulers)
+	re.True(hasScheduler(schedulers, "evict-leader-scheduler"))
+	var scheduler handlers.Scheduler
+	mustRequest(re, http.MethodPut, addr+"/schedulers/evict-leader-scheduler/pause",
+		&handlers.PauseSchedulerParams{Delay: 100}, http.StatusOK, &scheduler)
+	re.True(scheduler.Paused)
+	mustRequest(re, http.MethodPut, addr+"/schedulers/evict-leader-scheduler/pause",
+		&handlers.PauseSchedulerParams{}, http.StatusOK, &scheduler)
+	re.False(scheduler.Paused)
+	mustRequest(re, http.MethodDelete, addr+"/schedulers/evict-leader-scheduler", nil, http.StatusOK, nil)
+	mustRequest(re, http.MethodGet, addr+"/schedulers", nil, http.StatusOK, &schedulers)
+	re.False(hasScheduler(schedulers, "evict-leader-scheduler"))
+	mustRequest(re, http.MethodDelete, addr+"/schedulers/evict-leader-scheduler", nil, http.StatusNotFound, &errResp)
+
+	// The operators.
+	var operators []*handlers.Operator
+	mustRequest(re, http.MethodGet, addr+"/operators", nil, http.StatusOK, &operators)
+	re.Empty(operators)
+	mustRequest(re, http.MethodGet, addr+"/operators?kind=unknown", nil, http.StatusBadRequest, &errResp)
+	mustRequest(re, http.MethodDelete, addr+"/operators/1", nil, http.StatusNotFound, &errResp)
+
+	// The retried request with the same idempotency key is not applied again.
+	mustRequest(re, http.MethodPost, addr+"/schedulers",
+		&handlers.CreateSchedulerParams{Type: "evict-leader", Args: []string{"2"}}, http.StatusOK, nil)
+	deleteWithKey := func(url, key string, expectStatus int) *http.Response {
+		req, err := http.NewRequest(http.MethodDelete, url, nil)
+		re.NoError(err)
+		req.Header.Set(middlewares.IdempotencyKeyHeader, key)
+		resp, err := dialClient.Do(req)
+		re.NoError(err)
+		resp.Body.Close()
+		re.Equal(expectStatus, resp.StatusCode)
+		return resp
+	}
+	resp := deleteWithKey(addr+"/schedulers/evict-leader-scheduler", "delete-evict-leader", http.StatusOK)
+	re.Empty(resp.Header.Get(middlewares.IdempotentReplayedHeader))
+	resp = deleteWithKey(addr+"/schedulers/evict-leader-scheduler", "delete-evict-leader", http.StatusOK)
+	re.Equal("true", resp.Header.Get(middlewares.IdempotentReplayedHeader))
+	deleteWithKey(addr+"/schedulers/balance-leader-scheduler", "delete-evict-leader", http.StatusUnprocessableEntity)
+	deleteWithKey(addr+"/schedulers/evict-leader-scheduler", "another-key", http.StatusNotFound)
+	// The same key sent by another caller is not replayed.
+	req, err := http.NewRequest(http.MethodDelete, addr+"/schedulers/evict-leader-scheduler", nil)
+	re.NoError(err)
+	req.Header.Set(middlewares.IdempotencyKeyHeader, "delete-evict-leader")
+	req.Header.Set("Authorization", "Bearer another-caller")
+	resp, err = dialClient.Do(req)
+	re.NoError(err)
+	resp.Body.Close()
+	re.Equal(http.StatusNotFound, resp.StatusCode)
+	re.Empty(resp.Header.Get(middlewares.IdempotentReplayedHeader))
+
+	// The v1 API is deprecated in favor of the v2 API.
+	resp, err = dialClient.Get(server.GetAddr() + "/pd/api/v1/stores")
+	re.NoError(err)
+	resp.Body.Close()
+	re.Equal("true", resp.Header.Get("Deprecation"))
+	re.Equal(`</pd/api/v2/stores>; rel="successor-version"`, resp.Header.Get("Link"))
+}
+
+func hasScheduler(schedulers []*handlers.Scheduler, name string) bool {
+	for _, s := range schedulers {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func mustRequest(re *require.Assertions, method, url string, body interface{}, expectStatus int, res interface{}) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		re.NoError(err)
+		reader = bytes.NewBuffer(data)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	re.NoError(err)
+	resp, err := dialClient.Do(req)
+	re.NoError(err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	re.NoError(err)
+	re.Equal(expectStatus, resp.StatusCode, string(data))
+	if res != nil {
+		re.NoError(json.Unmarshal(data, res))
+	}
+}
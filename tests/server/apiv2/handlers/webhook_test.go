@@ -0,0 +1,95 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/event"
+	"github.com/tikv/pd/pkg/utils/testutil"
+	"github.com/tikv/pd/pkg/webhook"
+	"github.com/tikv/pd/server/apiv2/handlers"
+	"github.com/tikv/pd/tests"
+	"github.com/tikv/pd/tests/pdctl"
+)
+
+func TestWebhooks(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	re.NoError(err)
+	defer cluster.Destroy()
+	re.NoError(cluster.RunInitialServers())
+	re.NotEmpty(cluster.WaitLeader())
+	server := cluster.GetServer(cluster.GetLeader())
+	re.NoError(server.BootstrapCluster())
+	addr := server.GetAddr() + "/pd/api/v2"
+
+	type delivery struct {
+		header http.Header
+		body   []byte
+	}
+	deliveries := make(chan delivery, 16)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		deliveries <- delivery{header: r.Header.Clone(), body: body}
+	}))
+	defer receiver.Close()
+
+	mustRequest(re, http.MethodPut, addr+"/webhooks/hook", &handlers.PutWebhookParams{URL: "ftp://invalid"}, http.StatusBadRequest, nil)
+	var res handlers.Webhook
+	mustRequest(re, http.MethodPut, addr+"/webhooks/hook", &handlers.PutWebhookParams{
+		URL:        receiver.URL,
+		Secret:     "secret",
+		EventTypes: []string{string(event.StoreStateChanged)},
+		StoreIDs:   []uint64{20},
+	}, http.StatusOK, &res)
+	re.Equal("hook", res.ID)
+	re.True(res.Signed)
+	re.Empty(res.Secret)
+	re.Equal(webhook.DefaultMaxRetries, res.MaxRetries)
+
+	// Only the events of the matched store are delivered.
+	pdctl.MustPutStore(re, server.GetServer(), &metapb.Store{Id: 10, State: metapb.StoreState_Up, NodeState: metapb.NodeState_Serving})
+	pdctl.MustPutStore(re, server.GetServer(), &metapb.Store{Id: 20, State: metapb.StoreState_Up, NodeState: metapb.NodeState_Serving})
+	d := <-deliveries
+	re.Equal(string(event.StoreStateChanged), d.header.Get(webhook.EventHeader))
+	re.NotEmpty(d.header.Get(webhook.DeliveryHeader))
+	re.Equal("sha256="+webhook.Sign("secret", d.body), d.header.Get(webhook.SignatureHeader))
+	var e event.Event
+	re.NoError(json.Unmarshal(d.body, &e))
+	re.Equal(uint64(20), e.StoreID)
+
+	var hooks []*handlers.Webhook
+	mustRequest(re, http.MethodGet, addr+"/webhooks", nil, http.StatusOK, &hooks)
+	re.Len(hooks, 1)
+	re.Empty(hooks[0].Secret)
+	testutil.Eventually(re, func() bool {
+		mustRequest(re, http.MethodGet, addr+"/webhooks/hook", nil, http.StatusOK, &res)
+		return res.Status != nil && res.Status.Delivered == 1
+	})
+
+	mustRequest(re, http.MethodDelete, addr+"/webhooks/hook", nil, http.StatusOK, nil)
+	mustRequest(re, http.MethodGet, addr+"/webhooks/hook", nil, http.StatusNotFound, nil)
+	mustRequest(re, http.MethodDelete, addr+"/webhooks/hook", nil, http.StatusNotFound, nil)
+}
@@ -16,6 +16,7 @@ package join_test
 
 import (
 	"context"
+	"net/http"
 	"os"
 	"path"
 	"testing"
@@ -23,7 +24,9 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"github.com/tikv/pd/pkg/utils/etcdutil"
+	"github.com/tikv/pd/pkg/utils/testutil"
 	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/config"
 	"github.com/tikv/pd/server/join"
 	"github.com/tikv/pd/tests"
 )
@@ -33,6 +36,45 @@ import (
 // 	goleak.VerifyTestMain(m, testutil.LeakOptions...)
 // }
 
+func TestWitnessJoin(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	defer cluster.Destroy()
+	re.NoError(err)
+
+	err = cluster.RunInitialServers()
+	re.NoError(err)
+	re.Equal("pd1", cluster.WaitLeader())
+
+	pd2, err := cluster.Join(ctx, func(conf *config.Config, _ string) {
+		conf.Witness = true
+	})
+	re.NoError(err)
+	re.NoError(pd2.Run())
+	members, err := etcdutil.ListEtcdMembers(cluster.GetServer("pd1").GetEtcdClient())
+	re.NoError(err)
+	re.Len(members.Members, 2)
+	for _, m := range members.Members {
+		re.Equal(m.Name == pd2.GetConfig().Name, m.GetIsLearner())
+	}
+
+	// The witness knows the leader, but never campaigns even if the leader resigns.
+	testutil.Eventually(re, func() bool {
+		return pd2.GetLeader().GetName() == "pd1"
+	})
+	re.NoError(cluster.GetServer("pd1").ResignLeader())
+	time.Sleep(3 * time.Second)
+	re.False(pd2.IsLeader())
+
+	// The witness can't be promoted to a voter.
+	resp, err := http.Post(cluster.GetServer("pd1").GetAddr()+"/pd/api/v1/members/name/"+pd2.GetConfig().Name+"/promote", "application/json", nil)
+	re.NoError(err)
+	resp.Body.Close()
+	re.Equal(http.StatusBadRequest, resp.StatusCode)
+}
+
 func TestSimpleJoin(t *testing.T) {
 	re := require.New(t)
 	ctx, cancel := context.WithCancel(context.Background())
@@ -248,6 +248,59 @@ func TestLeaderResignWithBlock(t *testing.T) {
 	re.NoError(failpoint.Disable("github.com/tikv/pd/server/raftclusterIsBusy"))
 }
 
+func TestLeaderFlappingCooldown(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// Every member starts the cooldown once it loses the leadership.
+	cluster, err := tests.NewTestCluster(ctx, 2, func(conf *config.Config, _ string) {
+		conf.PDServerCfg.LeaderFlappingThreshold = 1
+	})
+	defer cluster.Destroy()
+	re.NoError(err)
+
+	err = cluster.RunInitialServers()
+	re.NoError(err)
+
+	leader1 := cluster.WaitLeader()
+	re.NoError(cluster.GetServer(leader1).ResignLeader())
+	leader2 := waitLeaderChange(re, cluster, leader1)
+	// A leader is still elected when all the members are in the cooldown.
+	re.NoError(cluster.GetServer(leader2).ResignLeader())
+	waitLeaderChange(re, cluster, leader2)
+}
+
+func TestLeaderTransferNotFlapping(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 2, func(conf *config.Config, _ string) {
+		conf.PDServerCfg.LeaderFlappingThreshold = 1
+	})
+	defer cluster.Destroy()
+	re.NoError(err)
+
+	err = cluster.RunInitialServers()
+	re.NoError(err)
+
+	// The transferred leader doesn't start the cooldown, so it can be the
+	// leader again right away.
+	leader1 := cluster.WaitLeader()
+	addr := cluster.GetServer(leader1).GetAddr()
+	resp, err := http.Post(addr+"/pd/api/v1/leader/resign", "application/json", nil)
+	re.NoError(err)
+	resp.Body.Close()
+	re.Equal(http.StatusOK, resp.StatusCode)
+	leader2 := waitLeaderChange(re, cluster, leader1)
+	resp, err = http.Post(cluster.GetServer(leader2).GetAddr()+"/pd/api/v1/leader/transfer/"+leader1, "application/json", nil)
+	re.NoError(err)
+	resp.Body.Close()
+	re.Equal(http.StatusOK, resp.StatusCode)
+	testutil.Eventually(re, func() bool {
+		return cluster.GetLeader() == leader1
+	})
+}
+
 func waitLeaderChange(re *require.Assertions, cluster *tests.TestCluster, old string) string {
 	var leader string
 	testutil.Eventually(re, func() bool {
@@ -0,0 +1,189 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/keyspacepb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/pd/pkg/rbac"
+	"github.com/tikv/pd/pkg/utils/grpcutil"
+	"github.com/tikv/pd/server"
+	"github.com/tikv/pd/server/config"
+	"github.com/tikv/pd/server/keyspace"
+	"github.com/tikv/pd/server/schedule/labeler"
+	"github.com/tikv/pd/server/schedule/placement"
+	"github.com/tikv/pd/tests"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	viewerToken   = "viewer-token"
+	operatorToken = "operator-token"
+)
+
+// newRBACCluster starts a bootstrapped cluster with the role-based access
+// control enabled, and binds viewerToken and operatorToken to their roles.
+func newRBACCluster(ctx context.Context, re *require.Assertions) (*tests.TestCluster, *grpc.ClientConn) {
+	cluster, err := tests.NewTestCluster(ctx, 1, func(conf *config.Config, serverName string) {
+		// The options are applied after the config is validated, so the
+		// client certificates are not required here.
+		conf.Security.RBAC.Enable = true
+		conf.Security.RBAC.AdminCN = []string{"pd-server"}
+	})
+	re.NoError(err)
+	re.NoError(cluster.RunInitialServers())
+	leader := cluster.GetServer(cluster.WaitLeader())
+	re.NotNil(leader)
+	re.NoError(leader.BootstrapCluster())
+	manager := leader.GetServer().GetRBACManager()
+	_, err = manager.SetRoleBinding("viewer", rbac.RoleViewer, "", viewerToken, time.Now())
+	re.NoError(err)
+	_, err = manager.SetRoleBinding("operator", rbac.RoleOperator, "", operatorToken, time.Now())
+	re.NoError(err)
+	conn, err := grpc.Dial(strings.TrimPrefix(leader.GetAddr(), "http://"), grpc.WithInsecure())
+	re.NoError(err)
+	return cluster, conn
+}
+
+func withToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+func TestRuleServiceRBAC(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, conn := newRBACCluster(ctx, re)
+	defer cluster.Destroy()
+	defer conn.Close()
+	header := &pdpb.RequestHeader{ClusterId: cluster.GetServer(cluster.GetLeader()).GetClusterID()}
+	invoke := func(ctx context.Context, method string, request, response interface{}) error {
+		return conn.Invoke(ctx, "/"+server.RuleServiceName+"/"+method, request, response, grpc.CallContentSubtype(grpcutil.JSONCodecName))
+	}
+	rule := &placement.Rule{
+		GroupID:     "test",
+		ID:          "rule",
+		StartKeyHex: hex.EncodeToString([]byte("a")),
+		EndKeyHex:   hex.EncodeToString([]byte("b")),
+		Role:        placement.Voter,
+		Count:       1,
+	}
+	setRules := &server.SetPlacementRulesRequest{Header: header, Rules: []*placement.Rule{rule}}
+	deleteRule := &server.DeletePlacementRuleRequest{Header: header, RuleKey: server.RuleKey{GroupID: "test", ID: "rule"}}
+	patchLabelRules := &server.PatchLabelRulesRequest{Header: header, LabelRulePatch: labeler.LabelRulePatch{DeleteRules: []string{"label"}}}
+
+	// The viewer can't change the rules.
+	viewerCtx := withToken(ctx, viewerToken)
+	err := invoke(viewerCtx, "SetPlacementRules", setRules, &server.SetPlacementRulesResponse{})
+	re.Equal(codes.PermissionDenied, status.Code(err))
+	err = invoke(viewerCtx, "DeletePlacementRule", deleteRule, &server.DeletePlacementRuleResponse{})
+	re.Equal(codes.PermissionDenied, status.Code(err))
+	err = invoke(viewerCtx, "PatchLabelRules", patchLabelRules, &server.PatchLabelRulesResponse{})
+	re.Equal(codes.PermissionDenied, status.Code(err))
+	// Nor can the request without any credential.
+	err = invoke(ctx, "SetPlacementRules", setRules, &server.SetPlacementRulesResponse{})
+	re.Equal(codes.Unauthenticated, status.Code(err))
+	// But the viewer can read them.
+	getRules := &server.GetPlacementRulesResponse{}
+	re.NoError(invoke(viewerCtx, "GetPlacementRules", &server.GetPlacementRulesRequest{Header: header, GroupID: "test"}, getRules))
+	re.Nil(getRules.Header.GetError())
+	re.Empty(getRules.Rules)
+
+	// The operator can change the rules.
+	operatorCtx := withToken(ctx, operatorToken)
+	setRulesResp := &server.SetPlacementRulesResponse{}
+	re.NoError(invoke(operatorCtx, "SetPlacementRules", setRules, setRulesResp))
+	re.Nil(setRulesResp.Header.GetError())
+	deleteRuleResp := &server.DeletePlacementRuleResponse{}
+	re.NoError(invoke(operatorCtx, "DeletePlacementRule", deleteRule, deleteRuleResp))
+	re.Nil(deleteRuleResp.Header.GetError())
+}
+
+func TestKeyspaceServiceRBAC(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, conn := newRBACCluster(ctx, re)
+	defer cluster.Destroy()
+	defer conn.Close()
+	leader := cluster.GetServer(cluster.GetLeader())
+	meta, err := leader.GetKeyspaceManager().CreateKeyspace(&keyspace.CreateKeyspaceRequest{Name: "rbac", Now: time.Now().Unix()})
+	re.NoError(err)
+	request := &keyspacepb.UpdateKeyspaceStateRequest{
+		Header: &pdpb.RequestHeader{ClusterId: leader.GetClusterID()},
+		Id:     meta.GetId(),
+		State:  keyspacepb.KeyspaceState_DISABLED,
+	}
+	client := keyspacepb.NewKeyspaceClient(conn)
+
+	// The viewer can't change the state of the keyspace.
+	_, err = client.UpdateKeyspaceState(withToken(ctx, viewerToken), request)
+	re.Equal(codes.PermissionDenied, status.Code(err))
+	meta, err = leader.GetKeyspaceManager().LoadKeyspace("rbac")
+	re.NoError(err)
+	re.Equal(keyspacepb.KeyspaceState_ENABLED, meta.GetState())
+
+	// The operator can.
+	resp, err := client.UpdateKeyspaceState(withToken(ctx, operatorToken), request)
+	re.NoError(err)
+	re.Nil(resp.GetHeader().GetError())
+	re.Equal(keyspacepb.KeyspaceState_DISABLED, resp.GetKeyspace().GetState())
+}
+
+func TestHTTPRBAC(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, conn := newRBACCluster(ctx, re)
+	defer cluster.Destroy()
+	defer conn.Close()
+	addr := cluster.GetServer(cluster.GetLeader()).GetAddr()
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	do := func(method, path, token string, body []byte) int {
+		req, err := http.NewRequest(method, addr+path, bytes.NewBuffer(body))
+		re.NoError(err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := client.Do(req)
+		re.NoError(err)
+		defer resp.Body.Close()
+		_, err = io.ReadAll(resp.Body)
+		re.NoError(err)
+		return resp.StatusCode
+	}
+	createKeyspace := []byte(`{"name":"rbac"}`)
+
+	// The viewer can't write through either API version.
+	re.Equal(http.StatusForbidden, do(http.MethodPost, "/pd/api/v1/operators", viewerToken, []byte(`{}`)))
+	re.Equal(http.StatusForbidden, do(http.MethodPost, "/pd/api/v2/keyspaces", viewerToken, createKeyspace))
+	re.Equal(http.StatusUnauthorized, do(http.MethodPost, "/pd/api/v2/keyspaces", "unknown", createKeyspace))
+	// But it can read.
+	re.Equal(http.StatusOK, do(http.MethodGet, "/pd/api/v2/keyspaces", viewerToken, nil))
+
+	// The operator can write.
+	re.Equal(http.StatusOK, do(http.MethodPost, "/pd/api/v2/keyspaces", operatorToken, createKeyspace))
+}
@@ -16,19 +16,26 @@ package syncer_test
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"testing"
 	"time"
 
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/tikv/pd/pkg/core"
 	"github.com/tikv/pd/pkg/mock/mockid"
+	"github.com/tikv/pd/pkg/utils/apiutil/serverapi"
+	"github.com/tikv/pd/pkg/utils/grpcutil"
 	"github.com/tikv/pd/pkg/utils/testutil"
 	"github.com/tikv/pd/server/config"
 	"github.com/tikv/pd/tests"
 	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
 func TestMain(m *testing.M) {
@@ -246,6 +253,77 @@ func TestPrepareChecker(t *testing.T) {
 	re.NoError(failpoint.Disable("github.com/tikv/pd/server/cluster/changeCoordinatorTicker"))
 }
 
+func TestFollowerRead(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 2, func(conf *config.Config, serverName string) { conf.PDServerCfg.UseRegionStorage = true })
+	defer cluster.Destroy()
+	re.NoError(err)
+
+	re.NoError(cluster.RunInitialServers())
+	cluster.WaitLeader()
+	leaderServer := cluster.GetServer(cluster.GetLeader())
+	re.NoError(leaderServer.BootstrapCluster())
+	rc := leaderServer.GetServer().GetRaftCluster()
+	re.NotNil(rc)
+	re.True(cluster.WaitRegionSyncerClientsReady(1))
+	regions := initRegions(10)
+	for _, region := range regions {
+		re.NoError(rc.HandleRegionHeartbeat(region))
+	}
+
+	followerServer := cluster.GetServer(cluster.GetFollower())
+	re.NotNil(followerServer)
+	// The stores are refreshed periodically, so the first refresh may happen before the bootstrap.
+	testutil.Eventually(re, func() bool {
+		_, err := followerServer.GetServer().CheckFollowerRead(time.Minute)
+		basicCluster := followerServer.GetServer().GetBasicCluster()
+		return err == nil && basicCluster.GetRegion(regions[9].GetID()) != nil && basicCluster.GetStore(1) != nil
+	})
+	// No follower can catch up with the zero staleness.
+	_, err = followerServer.GetServer().CheckFollowerRead(0)
+	re.Error(err)
+
+	// gRPC
+	grpcPDClient := testutil.MustNewGrpcClient(re, followerServer.GetAddr())
+	req := &pdpb.GetRegionByIDRequest{
+		Header:   &pdpb.RequestHeader{ClusterId: followerServer.GetClusterID()},
+		RegionId: regions[9].GetID(),
+	}
+	_, err = grpcPDClient.GetRegionByID(ctx, req)
+	re.Error(err)
+	var header metadata.MD
+	resp, err := grpcPDClient.GetRegionByID(grpcutil.BuildMaxStalenessContext(ctx, time.Minute), req, grpc.Header(&header))
+	re.NoError(err)
+	re.Nil(resp.GetHeader().GetError())
+	re.Equal(regions[9].GetMeta(), resp.GetRegion())
+	re.NotEmpty(header.Get(grpcutil.StalenessMetadataKey))
+	storesResp, err := grpcPDClient.GetAllStores(grpcutil.BuildMaxStalenessContext(ctx, time.Minute), &pdpb.GetAllStoresRequest{
+		Header: &pdpb.RequestHeader{ClusterId: followerServer.GetClusterID()},
+	})
+	re.NoError(err)
+	re.Len(storesResp.GetStores(), 1)
+
+	// HTTP
+	dialClient := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	httpReq, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/pd/api/v1/region/id/%d", followerServer.GetAddr(), regions[9].GetID()), nil)
+	re.NoError(err)
+	httpReq.Header.Set(serverapi.MaxStalenessHeader, "1m")
+	httpResp, err := dialClient.Do(httpReq)
+	re.NoError(err)
+	httpResp.Body.Close()
+	re.Equal(http.StatusOK, httpResp.StatusCode)
+	re.NotEmpty(httpResp.Header.Get(serverapi.StalenessHeader))
+	// A request without the header is served by the leader.
+	httpReq.Header.Del(serverapi.MaxStalenessHeader)
+	httpResp, err = dialClient.Do(httpReq)
+	re.NoError(err)
+	httpResp.Body.Close()
+	re.Equal(http.StatusOK, httpResp.StatusCode)
+	re.Empty(httpResp.Header.Get(serverapi.StalenessHeader))
+}
+
 func initRegions(regionLen int) []*core.RegionInfo {
 	allocator := &idAllocator{allocator: mockid.NewIDAllocator()}
 	regions := make([]*core.RegionInfo, 0, regionLen)
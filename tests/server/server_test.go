@@ -16,6 +16,7 @@ package server_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -24,6 +25,9 @@ import (
 	"github.com/tikv/pd/server/config"
 	"github.com/tikv/pd/tests"
 	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
 )
 
 func TestMain(m *testing.M) {
@@ -127,3 +131,36 @@ func TestLeader(t *testing.T) {
 		return cluster.GetLeader() != leader1
 	})
 }
+
+func TestGRPCHealthAndReflection(t *testing.T) {
+	re := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	defer cluster.Destroy()
+	re.NoError(err)
+	re.NoError(cluster.RunInitialServers())
+	leader := cluster.WaitLeader()
+	re.NotEmpty(leader)
+
+	conn, err := grpc.Dial(strings.TrimPrefix(cluster.GetServer(leader).GetAddr(), "http://"), grpc.WithInsecure())
+	re.NoError(err)
+	defer conn.Close()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	re.NoError(err)
+	re.Equal(healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	re.NoError(err)
+	re.NoError(stream.Send(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_ListServices{},
+	}))
+	reflectionResp, err := stream.Recv()
+	re.NoError(err)
+	var services []string
+	for _, service := range reflectionResp.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+	re.Contains(services, "pdpb.PD")
+	re.Contains(services, "keyspacepb.Keyspace")
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/tikv/pd/tools/pd-ctl/pdctl"
@@ -26,7 +27,12 @@ import (
 func main() {
 	pdAddr := os.Getenv("PD_ADDR")
 	if pdAddr != "" {
-		os.Args = append(os.Args, "-u", pdAddr)
+		if len(os.Args) > 1 && strings.HasPrefix(os.Args[1], "__complete") {
+			// The last argument is the word to complete for the shell completion.
+			os.Args = append([]string{os.Args[0], "-u", pdAddr}, os.Args[1:]...)
+		} else {
+			os.Args = append(os.Args, "-u", pdAddr)
+		}
 	}
 
 	sc := make(chan os.Signal, 1)
@@ -49,7 +55,7 @@ func main() {
 
 	var inputs []string
 	stat, _ := os.Stdin.Stat()
-	if (stat.Mode() & os.ModeCharDevice) == 0 {
+	if (stat.Mode()&os.ModeCharDevice) == 0 && !pdctl.IsScriptFromStdin(os.Args[1:]) {
 		in, err := pdctl.ReadStdin(os.Stdin)
 		if err != nil {
 			fmt.Println(err)
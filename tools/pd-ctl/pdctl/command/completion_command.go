@@ -16,8 +16,12 @@ package command
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -29,6 +33,10 @@ The shell code must be evaluated to provide interactive
 completion of pd-ctl commands.  This can be done by sourcing it from
 the .bash_profile.
 
+The arguments like store IDs, scheduler names and rule groups are completed
+with the live values of the cluster specified by the PD_ADDR environment
+variable or the -u flag on the command line.
+
 Note for zsh users: [1] zsh completions are only supported in versions of zsh >= 5.2
 `
 
@@ -288,3 +296,117 @@ _complete pd-ctl 2>/dev/null
 	}
 	return nil
 }
+
+// completionSource fetches the candidates of an argument from the cluster.
+type completionSource func(cmd *cobra.Command) ([]string, error)
+
+type completionFunc = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// completeFirstArg completes the first argument with the candidates from the
+// source, e.g. `store delete <store_id>`.
+func completeFirstArg(source completionSource) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return filterCandidates(cmd, source, nil, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeEachArg completes every argument with the candidates which are not
+// specified yet, e.g. `job drain <store_id> [<store_id>...]`.
+func completeEachArg(source completionSource) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return filterCandidates(cmd, source, args, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeFlag registers the completion of a flag value.
+func completeFlag(cmd *cobra.Command, flag string, source completionSource) {
+	cmd.RegisterFlagCompletionFunc(flag, completeEachArg(source))
+}
+
+func filterCandidates(cmd *cobra.Command, source completionSource, exclude []string, toComplete string) []string {
+	candidates, err := source(cmd)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		return nil
+	}
+	var res []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, toComplete) && !containsString(exclude, candidate) {
+			res = append(res, candidate)
+		}
+	}
+	return res
+}
+
+func storeIDCandidates(cmd *cobra.Command) ([]string, error) {
+	r, err := doRequest(cmd, storesPrefix, http.MethodGet, http.Header{})
+	if err != nil {
+		return nil, err
+	}
+	var stores struct {
+		Stores []struct {
+			Store struct {
+				ID uint64 `json:"id"`
+			} `json:"store"`
+		} `json:"stores"`
+	}
+	if err := json.Unmarshal([]byte(r), &stores); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(stores.Stores))
+	for _, s := range stores.Stores {
+		ids = append(ids, strconv.FormatUint(s.Store.ID, 10))
+	}
+	return ids, nil
+}
+
+func schedulerCandidates(cmd *cobra.Command) ([]string, error) {
+	r, err := doRequest(cmd, schedulersPrefix, http.MethodGet, http.Header{})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	if err := json.Unmarshal([]byte(r), &names); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+func ruleGroupCandidates(cmd *cobra.Command) ([]string, error) {
+	r, err := doRequest(cmd, ruleGroupsPrefix, http.MethodGet, http.Header{})
+	if err != nil {
+		return nil, err
+	}
+	var groups []struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(r), &groups); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(groups))
+	for _, g := range groups {
+		ids = append(ids, g.ID)
+	}
+	return ids, nil
+}
+
+func resourceGroupCandidates(cmd *cobra.Command) ([]string, error) {
+	r, err := doRequest(cmd, resourceGroupsPrefix, http.MethodGet, http.Header{})
+	if err != nil {
+		return nil, err
+	}
+	var groups []struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal([]byte(r), &groups); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(groups))
+	for _, g := range groups {
+		names = append(names, g.Name)
+	}
+	return names, nil
+}
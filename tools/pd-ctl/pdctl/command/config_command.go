@@ -57,6 +57,7 @@ func NewConfigCommand() *cobra.Command {
 	conf.AddCommand(NewSetConfigCommand())
 	conf.AddCommand(NewDeleteConfigCommand())
 	conf.AddCommand(NewPlacementRulesCommand())
+	conf.AddCommand(NewDiffConfigCommand())
 	return conf
 }
 
@@ -437,6 +438,7 @@ func NewPlacementRulesCommand() *cobra.Command {
 	show.Flags().String("id", "", "rule id")
 	show.Flags().String("region", "", "region id")
 	show.Flags().Bool("detail", false, "detailed match info for region")
+	completeFlag(show, "group", ruleGroupCandidates)
 	load := &cobra.Command{
 		Use:   "load",
 		Short: "load placement rules to a file",
@@ -446,6 +448,7 @@ func NewPlacementRulesCommand() *cobra.Command {
 	load.Flags().String("id", "", "rule id")
 	load.Flags().String("region", "", "region id")
 	load.Flags().String("out", "rules.json", "the filename contains rules")
+	completeFlag(load, "group", ruleGroupCandidates)
 	save := &cobra.Command{
 		Use:   "save",
 		Short: "save rules from file",
@@ -457,9 +460,10 @@ func NewPlacementRulesCommand() *cobra.Command {
 		Short: "rule group configurations",
 	}
 	ruleGroupShow := &cobra.Command{
-		Use:   "show [id]",
-		Short: "show rule group configuration(s)",
-		Run:   showRuleGroupFunc,
+		Use:               "show [id]",
+		Short:             "show rule group configuration(s)",
+		Run:               showRuleGroupFunc,
+		ValidArgsFunction: completeFirstArg(ruleGroupCandidates),
 	}
 	ruleGroupSet := &cobra.Command{
 		Use:   "set <id> <index> <override>",
@@ -467,9 +471,10 @@ func NewPlacementRulesCommand() *cobra.Command {
 		Run:   updateRuleGroupFunc,
 	}
 	ruleGroupDelete := &cobra.Command{
-		Use:   "delete <id>",
-		Short: "delete rule group configuration",
-		Run:   deleteRuleGroupFunc,
+		Use:               "delete <id>",
+		Short:             "delete rule group configuration",
+		Run:               deleteRuleGroupFunc,
+		ValidArgsFunction: completeFirstArg(ruleGroupCandidates),
 	}
 	ruleGroup.AddCommand(ruleGroupShow, ruleGroupSet, ruleGroupDelete)
 	ruleBundle := &cobra.Command{
@@ -477,9 +482,10 @@ func NewPlacementRulesCommand() *cobra.Command {
 		Short: "process rules in group(s), set/save perform in a replace fashion",
 	}
 	ruleBundleGet := &cobra.Command{
-		Use:   "get <id>",
-		Short: "get rule group config and its rules by group id",
-		Run:   getRuleBundle,
+		Use:               "get <id>",
+		Short:             "get rule group config and its rules by group id",
+		Run:               getRuleBundle,
+		ValidArgsFunction: completeFirstArg(ruleGroupCandidates),
 	}
 	ruleBundleGet.Flags().String("out", "", "the output file")
 	ruleBundleSet := &cobra.Command{
@@ -489,9 +495,10 @@ func NewPlacementRulesCommand() *cobra.Command {
 	}
 	ruleBundleSet.Flags().String("in", "group.json", "the file contains one group config and its rules")
 	ruleBundleDelete := &cobra.Command{
-		Use:   "delete <id>",
-		Short: "delete rule group config and its rules by group id",
-		Run:   delRuleBundle,
+		Use:               "delete <id>",
+		Short:             "delete rule group config and its rules by group id",
+		Run:               delRuleBundle,
+		ValidArgsFunction: completeFirstArg(ruleGroupCandidates),
 	}
 	ruleBundleDelete.Flags().Bool("regexp", false, "match group id by regular expression")
 	ruleBundleLoad := &cobra.Command{
@@ -507,6 +514,7 @@ func NewPlacementRulesCommand() *cobra.Command {
 	}
 	ruleBundleSave.Flags().String("in", "rules.json", "the file contains all group configs and all rules")
 	ruleBundleSave.Flags().Bool("partial", false, "do not drop all old configurations, partial update")
+	ruleBundleSave.Flags().Bool("wait", false, "show the progress until the regions satisfy the rules")
 	ruleBundle.AddCommand(ruleBundleGet, ruleBundleSet, ruleBundleDelete, ruleBundleLoad, ruleBundleSave)
 	c.AddCommand(enable, disable, show, load, save, ruleGroup, ruleBundle)
 	return c
@@ -793,8 +801,14 @@ func saveRuleBundle(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	partial, _ := cmd.Flags().GetBool("partial")
+	if wait, _ := cmd.Flags().GetBool("wait"); wait {
+		runJob(cmd, "rule-bundle", map[string]interface{}{"bundles": json.RawMessage(content), "partial": partial})
+		return
+	}
+
 	path := ruleBundlePrefix
-	if ok, _ := cmd.Flags().GetBool("partial"); ok {
+	if partial {
 		path += "?partial=true"
 	}
 
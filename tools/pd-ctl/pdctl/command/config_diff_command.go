@@ -0,0 +1,293 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/chzyer/readline"
+	"github.com/docker/go-units"
+	"github.com/pingcap/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// storeConfigBaselineKey is the key of the baseline section which is
+	// compared against the config of every store.
+	storeConfigBaselineKey = "store"
+
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+	colorReset = "\033[0m"
+)
+
+var errConfigDrift = errors.New("config drift detected")
+
+// NewDiffConfigCommand returns a diff subcommand of configCmd.
+func NewDiffConfigCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "diff <baseline_file>",
+		Short: "compare the running config against a TOML or JSON baseline, exit with non-zero code on drift",
+		Long: "Compare the running config of PD against a TOML or JSON baseline file. " +
+			"Only the items present in the baseline are compared. " +
+			"The optional `" + storeConfigBaselineKey + "` section is compared against the config of every store.",
+		RunE:         diffConfigCommandFunc,
+		SilenceUsage: true,
+	}
+	c.Flags().Bool("no-color", false, "disable the colored output")
+	return c
+}
+
+type configDrift struct {
+	key      string
+	baseline interface{}
+	actual   interface{}
+	missing  bool
+}
+
+func diffConfigCommandFunc(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return nil
+	}
+	baseline, err := loadConfigBaseline(args[0])
+	if err != nil {
+		return errors.Annotate(err, "failed to load the baseline")
+	}
+	storeBaseline, _ := baseline[storeConfigBaselineKey].(map[string]interface{})
+	delete(baseline, storeConfigBaselineKey)
+
+	noColor, _ := cmd.Flags().GetBool("no-color")
+	out, ok := outputFile(cmd)
+	colored := !noColor && ok && readline.IsTerminal(int(out.Fd()))
+
+	r, err := doRequest(cmd, configPrefix, http.MethodGet, http.Header{})
+	if err != nil {
+		return errors.Annotate(err, "failed to get config")
+	}
+	actual := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(r), &actual); err != nil {
+		return errors.WithStack(err)
+	}
+	drifts := 0
+	pdDrifts := diffConfig("", baseline, actual)
+	printConfigDrifts(cmd, "pd", pdDrifts, colored)
+	drifts += len(pdDrifts)
+
+	if storeBaseline != nil {
+		stores, err := getStoreStatusAddresses(cmd)
+		if err != nil {
+			return err
+		}
+		for _, store := range stores {
+			storeConfig, err := getStoreConfig(cmd, store.statusAddress)
+			if err != nil {
+				return errors.Annotatef(err, "failed to get the config of store %d", store.id)
+			}
+			storeDrifts := diffConfig("", storeBaseline, storeConfig)
+			printConfigDrifts(cmd, fmt.Sprintf("store %d (%s)", store.id, store.statusAddress), storeDrifts, colored)
+			drifts += len(storeDrifts)
+		}
+	}
+
+	if drifts > 0 {
+		cmd.Printf("%d item(s) drifted from the baseline\n", drifts)
+		return errConfigDrift
+	}
+	cmd.Println("No drift, the config matches the baseline.")
+	return nil
+}
+
+// loadConfigBaseline loads the baseline as a JSON-compatible map, so the
+// values can be compared with the ones returned by the API.
+func loadConfigBaseline(path string) (map[string]interface{}, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	baseline := make(map[string]interface{})
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(content, &baseline)
+	case ".toml":
+		err = toml.Unmarshal(content, &baseline)
+	default:
+		if err = json.Unmarshal(content, &baseline); err != nil {
+			baseline = make(map[string]interface{})
+			err = toml.Unmarshal(content, &baseline)
+		}
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// Round trip through JSON to turn the TOML values into JSON types.
+	data, err := json.Marshal(baseline)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	normalized := make(map[string]interface{})
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return normalized, nil
+}
+
+// diffConfig compares the items present in the baseline with the actual
+// config recursively.
+func diffConfig(prefix string, baseline, actual map[string]interface{}) []configDrift {
+	keys := make([]string, 0, len(baseline))
+	for k := range baseline {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var drifts []configDrift
+	for _, k := range keys {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		b := baseline[k]
+		a, ok := actual[k]
+		if !ok {
+			drifts = append(drifts, configDrift{key: key, baseline: b, missing: true})
+			continue
+		}
+		bm, bIsMap := b.(map[string]interface{})
+		am, aIsMap := a.(map[string]interface{})
+		if bIsMap && aIsMap {
+			drifts = append(drifts, diffConfig(key, bm, am)...)
+			continue
+		}
+		if !configValueEqual(b, a) {
+			drifts = append(drifts, configDrift{key: key, baseline: b, actual: a})
+		}
+	}
+	return drifts
+}
+
+// configValueEqual compares two config values. Durations and byte sizes
+// are compared by their values, e.g. "5m" equals to "5m0s" and "1GiB"
+// equals to "1024MiB".
+func configValueEqual(a, b interface{}) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	as, aok := a.(string)
+	bs, bok := b.(string)
+	if !aok || !bok {
+		return false
+	}
+	if ad, err := time.ParseDuration(as); err == nil {
+		if bd, err := time.ParseDuration(bs); err == nil {
+			return ad == bd
+		}
+	}
+	if asize, err := units.RAMInBytes(as); err == nil {
+		if bsize, err := units.RAMInBytes(bs); err == nil {
+			return asize == bsize
+		}
+	}
+	return false
+}
+
+func printConfigDrifts(cmd *cobra.Command, target string, drifts []configDrift, colored bool) {
+	if len(drifts) == 0 {
+		return
+	}
+	paint := func(color, s string) string {
+		if !colored {
+			return s
+		}
+		return color + s + colorReset
+	}
+	cmd.Printf("--- baseline\n+++ %s\n", target)
+	for _, d := range drifts {
+		cmd.Println(paint(colorRed, fmt.Sprintf("- %s = %s", d.key, formatConfigValue(d.baseline))))
+		if d.missing {
+			cmd.Println(paint(colorGreen, fmt.Sprintf("+ %s (not found)", d.key)))
+		} else {
+			cmd.Println(paint(colorGreen, fmt.Sprintf("+ %s = %s", d.key, formatConfigValue(d.actual))))
+		}
+	}
+}
+
+func formatConfigValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+type storeStatusAddress struct {
+	id            uint64
+	statusAddress string
+}
+
+func getStoreStatusAddresses(cmd *cobra.Command) ([]storeStatusAddress, error) {
+	r, err := doRequest(cmd, storesPrefix, http.MethodGet, http.Header{})
+	if err != nil {
+		return nil, errors.Annotate(err, "failed to get stores")
+	}
+	var storesInfo struct {
+		Stores []struct {
+			Store struct {
+				ID            uint64 `json:"id"`
+				StatusAddress string `json:"status_address"`
+			} `json:"store"`
+		} `json:"stores"`
+	}
+	if err := json.Unmarshal([]byte(r), &storesInfo); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var stores []storeStatusAddress
+	for _, s := range storesInfo.Stores {
+		if s.Store.StatusAddress == "" {
+			cmd.Printf("store %d has no status address, skipped\n", s.Store.ID)
+			continue
+		}
+		stores = append(stores, storeStatusAddress{id: s.Store.ID, statusAddress: s.Store.StatusAddress})
+	}
+	sort.Slice(stores, func(i, j int) bool { return stores[i].id < stores[j].id })
+	return stores, nil
+}
+
+// getStoreConfig gets the config from the status address of the store, with
+// the same scheme as the PD endpoint.
+func getStoreConfig(cmd *cobra.Command, statusAddress string) (map[string]interface{}, error) {
+	scheme := "http"
+	if endpoint, err := checkURL(getEndpoints(cmd)[0]); err == nil && strings.HasPrefix(endpoint, "https://") {
+		scheme = "https"
+	}
+	r, err := doRequestSingleEndpoint(cmd, scheme+"://"+statusAddress, "config", http.MethodGet, http.Header{})
+	if err != nil {
+		return nil, err
+	}
+	cfg := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(r), &cfg); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return cfg, nil
+}
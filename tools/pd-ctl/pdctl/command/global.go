@@ -22,6 +22,7 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"sync/atomic"
 
 	"github.com/pingcap/errors"
 	"github.com/spf13/cobra"
@@ -35,8 +36,17 @@ var (
 		Transport: apiutil.NewComponentSignatureRoundTripper(http.DefaultTransport, pdControllerComponentName),
 	}
 	pingPrefix = "pd/api/v1/ping"
+
+	// failedRequests counts the failed requests, so the caller can tell
+	// whether a command succeeded, e.g. when running a script.
+	failedRequests atomic.Int64
 )
 
+// FailedRequests returns the number of the failed requests sent by the commands.
+func FailedRequests() int64 {
+	return failedRequests.Load()
+}
+
 // InitHTTPSClient creates https client with ca file
 func InitHTTPSClient(caPath, certPath, keyPath string) error {
 	tlsInfo := transport.TLSInfo{
@@ -83,6 +93,9 @@ func doRequest(cmd *cobra.Command, prefix string, method string, customHeader ht
 	err := tryURLs(cmd, endpoints, func(endpoint string) error {
 		return do(endpoint, prefix, method, &resp, customHeader, b)
 	})
+	if err != nil {
+		failedRequests.Add(1)
+	}
 	return resp, err
 }
 
@@ -97,6 +110,9 @@ func doRequestSingleEndpoint(cmd *cobra.Command, endpoint, prefix, method string
 	err := requestURL(cmd, endpoint, func(endpoint string) error {
 		return do(endpoint, prefix, method, &resp, customHeader, b)
 	})
+	if err != nil {
+		failedRequests.Add(1)
+	}
 	return resp, err
 }
 
@@ -192,6 +208,7 @@ func postJSON(cmd *cobra.Command, prefix string, input map[string]interface{}) {
 		return nil
 	})
 	if err != nil {
+		failedRequests.Add(1)
 		cmd.Printf("Failed! %s", err)
 		return
 	}
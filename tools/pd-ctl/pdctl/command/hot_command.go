@@ -16,11 +16,13 @@ package command
 
 import (
 	"bytes"
+	"encoding/hex"
 	"encoding/json"
 	"net/http"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pingcap/errors"
 	"github.com/spf13/cobra"
@@ -117,25 +119,60 @@ func showHotStoresCommandFunc(cmd *cobra.Command, args []string) {
 // NewHotRegionsHistoryCommand return a hot history regions subcommand of hotSpotCmd
 func NewHotRegionsHistoryCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		// TODO
-		// Need a better description.
-		Use:   "history <start_time> <end_time> [<key> <value>]",
+		Use:   "history [<start_time> <end_time> [<key> <value>]] [--from <time>] [--to <time>] [--store <store_id>,...] [--range <start_key>,<end_key>] [--type read|write] [--top <n>]",
 		Short: "show the hot history regions",
-		Run:   showHotRegionsHistoryCommandFunc,
+		Long: "show the hot history regions persisted by PD.\n" +
+			"The time range is either the <start_time> and <end_time> in milliseconds, or the --from and --to flags, " +
+			"which can be a RFC3339 time, a unix timestamp in seconds, or a duration before now like 1h.\n" +
+			"With --top, the records are summarized by region, and the regions with the most flow in the time window are shown.",
+		Run: showHotRegionsHistoryCommandFunc,
 	}
+	cmd.Flags().String("from", "1h", "the start of the time range")
+	cmd.Flags().String("to", "", "the end of the time range, now by default")
+	cmd.Flags().String("store", "", "only show the hot peers on the stores, separated by comma")
+	cmd.Flags().String("range", "", "only show the hot regions overlapping with the hex encoded key range, separated by comma")
+	cmd.Flags().String("type", "", "only show the read or write hot regions")
+	cmd.Flags().Int("top", 0, "summarize the records by region and show the top n regions by flow")
+	completeFlag(cmd, "store", storeIDCandidates)
 	return cmd
 }
 
 func showHotRegionsHistoryCommandFunc(cmd *cobra.Command, args []string) {
-	if len(args) < 2 || len(args)%2 != 0 {
+	if len(args)%2 != 0 {
 		cmd.Println(cmd.UsageString())
 		return
 	}
-	input, err := parseHotRegionsHistoryArgs(args)
+	var (
+		input map[string]interface{}
+		err   error
+	)
+	if len(args) > 0 {
+		input, err = parseHotRegionsHistoryArgs(args)
+	} else {
+		input, err = parseHotRegionsHistoryFlags(cmd, time.Now())
+	}
 	if err != nil {
 		cmd.Printf("Failed to get history hotspot: %s\n", err)
 		return
 	}
+	if stores, _ := cmd.Flags().GetString("store"); stores != "" {
+		ids, err := parseStoreIDs([]string{stores})
+		if err != nil {
+			cmd.Printf("Failed to get history hotspot: invalid store %s\n", stores)
+			return
+		}
+		input["store_ids"] = ids
+	}
+	if typ, _ := cmd.Flags().GetString("type"); typ != "" {
+		input["hot_region_type"] = []string{typ}
+	}
+	var startKey, endKey string
+	if keyRange, _ := cmd.Flags().GetString("range"); keyRange != "" {
+		if startKey, endKey, err = parseHexKeyRange(keyRange); err != nil {
+			cmd.Printf("Failed to get history hotspot: %s\n", err)
+			return
+		}
+	}
 	data, _ := json.Marshal(input)
 	endpoints := getEndpoints(cmd)
 	hotRegions := &storage.HistoryHotRegions{}
@@ -154,7 +191,11 @@ func showHotRegionsHistoryCommandFunc(cmd *cobra.Command, args []string) {
 			cmd.Printf("Failed to get history hotspot: %s\n", err)
 			return
 		}
-		hotRegions.HistoryHotRegion = append(hotRegions.HistoryHotRegion, tempHotRegions.HistoryHotRegion...)
+		for _, region := range tempHotRegions.HistoryHotRegion {
+			if overlapKeyRange(region.StartKey, region.EndKey, startKey, endKey) {
+				hotRegions.HistoryHotRegion = append(hotRegions.HistoryHotRegion, region)
+			}
+		}
 	}
 	historyHotRegions := hotRegions.HistoryHotRegion
 	sort.SliceStable(historyHotRegions, func(i, j int) bool {
@@ -163,7 +204,12 @@ func showHotRegionsHistoryCommandFunc(cmd *cobra.Command, args []string) {
 		}
 		return historyHotRegions[i].RegionID < historyHotRegions[j].RegionID
 	})
-	resp, err := json.Marshal(hotRegions)
+	var resp []byte
+	if top, _ := cmd.Flags().GetInt("top"); top > 0 {
+		resp, err = json.Marshal(summarizeHistoryHotRegions(historyHotRegions, top))
+	} else {
+		resp, err = json.Marshal(hotRegions)
+	}
 	if err != nil {
 		cmd.Printf("Failed to get history hotspot: %s\n", err)
 		return
@@ -171,6 +217,162 @@ func showHotRegionsHistoryCommandFunc(cmd *cobra.Command, args []string) {
 	cmd.Println(string(resp))
 }
 
+func parseHotRegionsHistoryFlags(cmd *cobra.Command, now time.Time) (map[string]interface{}, error) {
+	from, _ := cmd.Flags().GetString("from")
+	startTime, err := parseTimeFlag(from, now)
+	if err != nil {
+		return nil, err
+	}
+	endTime := now
+	if to, _ := cmd.Flags().GetString("to"); to != "" {
+		if endTime, err = parseTimeFlag(to, now); err != nil {
+			return nil, err
+		}
+	}
+	if !startTime.Before(endTime) {
+		return nil, errors.New("the start of the time range should be before the end")
+	}
+	return map[string]interface{}{
+		"start_time":  startTime.UnixMilli(),
+		"end_time":    endTime.UnixMilli(),
+		"is_leaders":  []bool{true, false},
+		"is_learners": []bool{true, false},
+	}, nil
+}
+
+func parseHexKeyRange(keyRange string) (string, string, error) {
+	keys := strings.Split(keyRange, ",")
+	if len(keys) != 2 {
+		return "", "", errors.Errorf("the range should be <start_key>,<end_key>, but got %s", keyRange)
+	}
+	startKey, err := hex.DecodeString(keys[0])
+	if err != nil {
+		return "", "", errors.Errorf("the start key should be hex encoded, but got %s", keys[0])
+	}
+	endKey, err := hex.DecodeString(keys[1])
+	if err != nil {
+		return "", "", errors.Errorf("the end key should be hex encoded, but got %s", keys[1])
+	}
+	return string(startKey), string(endKey), nil
+}
+
+// overlapKeyRange returns whether the region overlaps with the key range,
+// empty keys mean no limit.
+func overlapKeyRange(regionStart, regionEnd, start, end string) bool {
+	return (end == "" || regionStart < end) && (regionEnd == "" || regionEnd > start)
+}
+
+// hotRegionSummary summarizes the history hot records of a region in the
+// time window.
+type hotRegionSummary struct {
+	RegionID      uint64   `json:"region_id"`
+	HotRegionType string   `json:"hot_region_type"`
+	StartKey      string   `json:"start_key"`
+	EndKey        string   `json:"end_key"`
+	StoreIDs      []uint64 `json:"store_ids"`
+	// Count is the times the region is recorded as hot.
+	Count        int     `json:"count"`
+	FirstSeen    int64   `json:"first_seen"`
+	LastSeen     int64   `json:"last_seen"`
+	MaxHotDegree int64   `json:"max_hot_degree"`
+	AvgFlowBytes float64 `json:"avg_flow_bytes"`
+	MaxFlowBytes float64 `json:"max_flow_bytes"`
+	AvgKeyRate   float64 `json:"avg_key_rate"`
+	AvgQueryRate float64 `json:"avg_query_rate"`
+
+	totalFlowBytes float64
+}
+
+// summarizeHistoryHotRegions summarizes the records by region and returns the
+// top n regions by the flow in the time window. The peers of a region recorded
+// at the same time are counted once with the largest flow.
+func summarizeHistoryHotRegions(records []*storage.HistoryHotRegion, n int) []*hotRegionSummary {
+	type regionKey struct {
+		id  uint64
+		typ string
+	}
+	type snapshotKey struct {
+		regionKey
+		updateTime int64
+	}
+	snapshots := make(map[snapshotKey]*storage.HistoryHotRegion)
+	for _, r := range records {
+		key := snapshotKey{regionKey{r.RegionID, r.HotRegionType}, r.UpdateTime}
+		if s, ok := snapshots[key]; !ok || r.FlowBytes > s.FlowBytes {
+			snapshots[key] = r
+		}
+	}
+	summaries := make(map[regionKey]*hotRegionSummary)
+	for _, r := range records {
+		key := regionKey{r.RegionID, r.HotRegionType}
+		s, ok := summaries[key]
+		if !ok {
+			s = &hotRegionSummary{
+				RegionID:      r.RegionID,
+				HotRegionType: r.HotRegionType,
+				StartKey:      r.StartKey,
+				EndKey:        r.EndKey,
+				FirstSeen:     r.UpdateTime,
+				LastSeen:      r.UpdateTime,
+			}
+			summaries[key] = s
+		}
+		if r.UpdateTime < s.FirstSeen {
+			s.FirstSeen = r.UpdateTime
+		}
+		if r.UpdateTime >= s.LastSeen {
+			// Show the latest range of the region.
+			s.LastSeen, s.StartKey, s.EndKey = r.UpdateTime, r.StartKey, r.EndKey
+		}
+		if r.HotDegree > s.MaxHotDegree {
+			s.MaxHotDegree = r.HotDegree
+		}
+		if !containsUint64(s.StoreIDs, r.StoreID) {
+			s.StoreIDs = append(s.StoreIDs, r.StoreID)
+		}
+	}
+	for key, r := range snapshots {
+		s := summaries[key.regionKey]
+		s.Count++
+		s.totalFlowBytes += r.FlowBytes
+		s.AvgKeyRate += r.KeyRate
+		s.AvgQueryRate += r.QueryRate
+		if r.FlowBytes > s.MaxFlowBytes {
+			s.MaxFlowBytes = r.FlowBytes
+		}
+	}
+	res := make([]*hotRegionSummary, 0, len(summaries))
+	for _, s := range summaries {
+		s.AvgFlowBytes = s.totalFlowBytes / float64(s.Count)
+		s.AvgKeyRate /= float64(s.Count)
+		s.AvgQueryRate /= float64(s.Count)
+		sort.Slice(s.StoreIDs, func(i, j int) bool { return s.StoreIDs[i] < s.StoreIDs[j] })
+		res = append(res, s)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].totalFlowBytes != res[j].totalFlowBytes {
+			return res[i].totalFlowBytes > res[j].totalFlowBytes
+		}
+		if res[i].RegionID != res[j].RegionID {
+			return res[i].RegionID < res[j].RegionID
+		}
+		return res[i].HotRegionType < res[j].HotRegionType
+	})
+	if len(res) > n {
+		res = res[:n]
+	}
+	return res
+}
+
+func containsUint64(ids []uint64, id uint64) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
 func parseOptionalArgs(prefix string, args []string) (string, error) {
 	argsLen := len(args)
 	if argsLen > 0 {
@@ -0,0 +1,322 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chzyer/readline"
+	"github.com/pingcap/errors"
+	"github.com/spf13/cobra"
+	"github.com/tikv/pd/pkg/job"
+)
+
+const (
+	jobsPrefix       = "pd/api/v2/jobs"
+	jobWatchInterval = time.Second
+	progressBarWidth = 30
+)
+
+// NewJobCommand returns a job subcommand of rootCmd.
+func NewJobCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "job <subcommand>",
+		Short: "manage the asynchronous admin jobs and show their progress",
+	}
+	list := &cobra.Command{
+		Use:   "list [--type <type>] [--status running|succeeded|failed|canceled]",
+		Short: "list the jobs",
+		Run:   listJobsCommandFunc,
+	}
+	list.Flags().String("type", "", "filter by the type of the jobs")
+	list.Flags().String("status", "", "filter by the status of the jobs")
+	scatter := &cobra.Command{
+		Use:   "scatter [--start-key <hex>] [--end-key <hex>] [--group <group>]",
+		Short: "scatter the regions in the key range and show the progress",
+		Run:   scatterJobCommandFunc,
+	}
+	scatter.Flags().String("start-key", "", "the hex encoded start key of the range")
+	scatter.Flags().String("end-key", "", "the hex encoded end key of the range")
+	scatter.Flags().String("group", "", "scatter the regions in the group level")
+	drain := &cobra.Command{
+		Use:               "drain <store_id> [<store_id>...] [--force]",
+		Short:             "remove the stores and show the progress until they become tombstone",
+		Run:               drainJobCommandFunc,
+		ValidArgsFunction: completeEachArg(storeIDCandidates),
+	}
+	drain.Flags().Bool("force", false, "remove the stores even if they are physically destroyed")
+	c.AddCommand(
+		list,
+		&cobra.Command{
+			Use:   "show <job_id>",
+			Short: "show a job",
+			Run:   showJobCommandFunc,
+		},
+		&cobra.Command{
+			Use:   "cancel <job_id>",
+			Short: "cancel a running job",
+			Run:   cancelJobCommandFunc,
+		},
+		&cobra.Command{
+			Use:   "watch <job_id>",
+			Short: "show the progress of a job until it finishes",
+			Run:   watchJobCommandFunc,
+		},
+		scatter,
+		drain,
+	)
+	for _, sub := range []*cobra.Command{scatter, drain} {
+		sub.Flags().Bool("detach", false, "submit the job without waiting for it")
+	}
+	return c
+}
+
+func listJobsCommandFunc(cmd *cobra.Command, args []string) {
+	query := make(url.Values)
+	for _, name := range []string{"type", "status"} {
+		if v, _ := cmd.Flags().GetString(name); v != "" {
+			query.Set(name, v)
+		}
+	}
+	prefix := jobsPrefix
+	if len(query) > 0 {
+		prefix += "?" + query.Encode()
+	}
+	r, err := doRequest(cmd, prefix, http.MethodGet, http.Header{})
+	if err != nil {
+		cmd.Printf("Failed to list jobs: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
+
+func showJobCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Usage()
+		return
+	}
+	r, err := doRequest(cmd, jobsPrefix+"/"+url.PathEscape(args[0]), http.MethodGet, http.Header{})
+	if err != nil {
+		cmd.Printf("Failed to get the job: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
+
+func cancelJobCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Usage()
+		return
+	}
+	if _, err := doRequest(cmd, jobsPrefix+"/"+url.PathEscape(args[0]), http.MethodDelete, http.Header{}); err != nil {
+		cmd.Printf("Failed to cancel the job: %s\n", err)
+		return
+	}
+	cmd.Println("Success!")
+}
+
+func watchJobCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Usage()
+		return
+	}
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		cmd.Usage()
+		return
+	}
+	if err := watchJob(cmd, id); err != nil {
+		failedRequests.Add(1)
+		cmd.Println(err)
+	}
+}
+
+func scatterJobCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Usage()
+		return
+	}
+	params := make(map[string]interface{})
+	for _, name := range []string{"start-key", "end-key", "group"} {
+		if v, _ := cmd.Flags().GetString(name); v != "" {
+			params[strings.ReplaceAll(name, "-", "_")] = v
+		}
+	}
+	runJob(cmd, "scatter", params)
+}
+
+func drainJobCommandFunc(cmd *cobra.Command, args []string) {
+	storeIDs, err := parseStoreIDs(args)
+	if err != nil || len(storeIDs) == 0 {
+		cmd.Usage()
+		return
+	}
+	force, _ := cmd.Flags().GetBool("force")
+	runJob(cmd, "drain", map[string]interface{}{"store_ids": storeIDs, "force": force})
+}
+
+func parseStoreIDs(args []string) ([]uint64, error) {
+	var ids []uint64
+	for _, arg := range args {
+		for _, s := range strings.Split(arg, ",") {
+			id, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// runJob submits the job, and shows its progress until it finishes unless
+// the `detach` flag is set.
+func runJob(cmd *cobra.Command, jobType string, params interface{}) {
+	j, err := submitJob(cmd, jobType, params)
+	if err != nil {
+		cmd.Printf("Failed to submit the %s job: %s\n", jobType, err)
+		return
+	}
+	if detach, _ := cmd.Flags().GetBool("detach"); detach {
+		cmd.Printf("Job %d is submitted, use `job watch %d` to show the progress\n", j.ID, j.ID)
+		return
+	}
+	if err := watchJob(cmd, j.ID); err != nil {
+		failedRequests.Add(1)
+		cmd.Println(err)
+	}
+}
+
+func submitJob(cmd *cobra.Command, jobType string, params interface{}) (*job.Job, error) {
+	data, err := json.Marshal(map[string]interface{}{"type": jobType, "params": params})
+	if err != nil {
+		return nil, err
+	}
+	r, err := doRequest(cmd, jobsPrefix, http.MethodPost, http.Header{"Content-Type": {"application/json"}}, WithBody(bytes.NewReader(data)))
+	if err != nil {
+		return nil, err
+	}
+	j := &job.Job{}
+	if err := json.Unmarshal([]byte(r), j); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// watchJob polls the job and shows the progress until it finishes.
+func watchJob(cmd *cobra.Command, id uint64) error {
+	printer := newProgressPrinter(cmd)
+	for {
+		r, err := doRequest(cmd, fmt.Sprintf("%s/%d", jobsPrefix, id), http.MethodGet, http.Header{})
+		if err != nil {
+			printer.finish()
+			return errors.Errorf("failed to get the job %d: %s", id, err)
+		}
+		j := &job.Job{}
+		if err := json.Unmarshal([]byte(r), j); err != nil {
+			printer.finish()
+			return err
+		}
+		if j.Status != job.Running {
+			printer.finish()
+			return printFinishedJob(cmd, j)
+		}
+		printer.update(formatProgress(j.Progress, j.CreateTime, time.Now(), j.Message))
+		time.Sleep(jobWatchInterval)
+	}
+}
+
+// progressPrinter shows the progress. It's refreshed in place if the output
+// is a terminal, otherwise a line is printed once the progress changes.
+type progressPrinter struct {
+	cmd     *cobra.Command
+	inPlace bool
+	last    string
+}
+
+func newProgressPrinter(cmd *cobra.Command) *progressPrinter {
+	out, ok := outputFile(cmd)
+	return &progressPrinter{cmd: cmd, inPlace: ok && readline.IsTerminal(int(out.Fd()))}
+}
+
+func (p *progressPrinter) update(line string) {
+	if line == p.last {
+		return
+	}
+	if p.inPlace {
+		// Clear the line before printing, which may be shorter.
+		p.cmd.Print("\r\033[K" + line)
+	} else {
+		p.cmd.Println(line)
+	}
+	p.last = line
+}
+
+// finish ends the progress, so the following output starts from a new line.
+func (p *progressPrinter) finish() {
+	if p.inPlace && p.last != "" {
+		p.cmd.Println()
+	}
+	p.last = ""
+}
+
+// formatProgress renders the progress bar with the ETA estimated from the
+// elapsed time since start.
+func formatProgress(progress float64, start, now time.Time, message string) string {
+	if progress < 0 {
+		progress = 0
+	} else if progress > 1 {
+		progress = 1
+	}
+	filled := int(progress * progressBarWidth)
+	eta := "-"
+	if progress > 0 && progress < 1 {
+		elapsed := now.Sub(start)
+		eta = time.Duration(float64(elapsed) * (1 - progress) / progress).Round(time.Second).String()
+	}
+	line := fmt.Sprintf("[%s%s] %5.1f%% ETA %s", strings.Repeat("#", filled), strings.Repeat(".", progressBarWidth-filled), progress*100, eta)
+	if message != "" {
+		line += " " + message
+	}
+	return line
+}
+
+func printFinishedJob(cmd *cobra.Command, j *job.Job) error {
+	elapsed := "-"
+	if j.FinishTime != nil {
+		elapsed = j.FinishTime.Sub(j.CreateTime).Round(time.Millisecond).String()
+	}
+	cmd.Printf("Job %d (%s) %s in %s\n", j.ID, j.Type, j.Status, elapsed)
+	if j.Result != nil {
+		result, err := json.MarshalIndent(j.Result, "", "  ")
+		if err == nil {
+			cmd.Println(string(result))
+		}
+	}
+	if j.Status != job.Succeeded {
+		if j.Error != "" {
+			return errors.Errorf("job %d %s: %s", j.ID, j.Status, j.Error)
+		}
+		return errors.Errorf("job %d %s", j.ID, j.Status)
+	}
+	return nil
+}
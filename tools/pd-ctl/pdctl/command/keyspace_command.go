@@ -0,0 +1,332 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	keyspacesPrefix    = "pd/api/v2/keyspaces"
+	keyspaceByIDPrefix = "pd/api/v2/keyspaces/id"
+)
+
+// NewKeyspaceCommand returns a keyspace subcommand of rootCmd.
+func NewKeyspaceCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "keyspace <subcommand>",
+		Short: "keyspace commands",
+	}
+	c.AddCommand(newListKeyspacesCommand())
+	c.AddCommand(newShowKeyspaceCommand())
+	c.AddCommand(newCreateKeyspaceCommand())
+	c.AddCommand(newUpdateKeyspaceConfigCommand())
+	c.AddCommand(newUpdateKeyspaceStateCommand())
+	c.AddCommand(newRenameKeyspaceCommand())
+	c.AddCommand(newArchiveKeyspaceCommand())
+	c.AddCommand(newRestoreKeyspaceCommand())
+	c.AddCommand(newKeyspaceQuotaCommand())
+	c.AddCommand(newKeyspaceUsageCommand())
+	return c
+}
+
+func newListKeyspacesCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "list [--limit <limit>] [--page-token <token>]",
+		Short: "list the keyspaces",
+		Run:   listKeyspacesCommandFunc,
+	}
+	c.Flags().Int("limit", 0, "the max number of the keyspaces to return, 0 means no limit")
+	c.Flags().String("page-token", "", "the page token returned by the last call")
+	return c
+}
+
+func newShowKeyspaceCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "show",
+		Short: "show a keyspace by the name or the id",
+	}
+	c.AddCommand(&cobra.Command{
+		Use:   "name <name>",
+		Short: "show the keyspace with the name",
+		Run:   showKeyspaceByNameCommandFunc,
+	})
+	c.AddCommand(&cobra.Command{
+		Use:   "id <id>",
+		Short: "show the keyspace with the id",
+		Run:   showKeyspaceByIDCommandFunc,
+	})
+	return c
+}
+
+func newCreateKeyspaceCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "create <name> [--config <key>=<value>,...] [--pre-split <count>] [--scatter-policy none|keyspace|global]",
+		Short: "create a keyspace",
+		Run:   createKeyspaceCommandFunc,
+	}
+	c.Flags().StringToString("config", nil, "the config of the keyspace")
+	c.Flags().Int("pre-split", 0, "the number of regions each key range of the keyspace is split into")
+	c.Flags().String("scatter-policy", "", "how to scatter the pre-split regions, one of none, keyspace and global")
+	return c
+}
+
+func newUpdateKeyspaceConfigCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "update-config <name> [--set <key>=<value>,...] [--remove <key>,...]",
+		Short: "update the config of a keyspace",
+		Run:   updateKeyspaceConfigCommandFunc,
+	}
+	c.Flags().StringToString("set", nil, "the config items to set")
+	c.Flags().StringSlice("remove", nil, "the config items to remove")
+	return c
+}
+
+func newUpdateKeyspaceStateCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "update-state <name> enabled|disabled|archived|tombstone",
+		Short: "update the state of a keyspace",
+		Run:   updateKeyspaceStateCommandFunc,
+	}
+}
+
+func newRenameKeyspaceCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rename <name> <new_name>",
+		Short: "rename a keyspace",
+		Run:   renameKeyspaceCommandFunc,
+	}
+}
+
+func newArchiveKeyspaceCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "archive <name>",
+		Short: "archive a disabled keyspace",
+		Run:   keyspaceActionCommandFunc("archive"),
+	}
+}
+
+func newRestoreKeyspaceCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <name>",
+		Short: "restore an archived keyspace to the disabled state",
+		Run:   keyspaceActionCommandFunc("restore"),
+	}
+}
+
+func newKeyspaceQuotaCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "quota <name> [--max-regions <count>] [--max-size <MiB>]",
+		Short: "show or set the quota of a keyspace, 0 means no limit",
+		Run:   keyspaceQuotaCommandFunc,
+	}
+	c.Flags().Uint64("max-regions", 0, "the max number of regions of the keyspace")
+	c.Flags().Uint64("max-size", 0, "the max approximate size of the keyspace in MiB")
+	return c
+}
+
+func newKeyspaceUsageCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "usage <name>",
+		Short: "show the resources used by a keyspace",
+		Run:   keyspaceActionCommandFunc("usage"),
+	}
+}
+
+func keyspacePath(name string, elems ...string) string {
+	return path.Join(append([]string{keyspacesPrefix, url.PathEscape(name)}, elems...)...)
+}
+
+func listKeyspacesCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Usage()
+		return
+	}
+	query := make(url.Values)
+	if limit, _ := cmd.Flags().GetInt("limit"); limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if token, _ := cmd.Flags().GetString("page-token"); token != "" {
+		query.Set("page_token", token)
+	}
+	prefix := keyspacesPrefix
+	if len(query) > 0 {
+		prefix += "?" + query.Encode()
+	}
+	r, err := doRequest(cmd, prefix, http.MethodGet, http.Header{})
+	if err != nil {
+		cmd.Printf("Failed to list keyspaces: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
+
+func showKeyspaceByNameCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Usage()
+		return
+	}
+	r, err := doRequest(cmd, keyspacePath(args[0]), http.MethodGet, http.Header{})
+	if err != nil {
+		cmd.Printf("Failed to get the keyspace: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
+
+func showKeyspaceByIDCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Usage()
+		return
+	}
+	r, err := doRequest(cmd, path.Join(keyspaceByIDPrefix, url.PathEscape(args[0])), http.MethodGet, http.Header{})
+	if err != nil {
+		cmd.Printf("Failed to get the keyspace: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
+
+func createKeyspaceCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Usage()
+		return
+	}
+	config, _ := cmd.Flags().GetStringToString("config")
+	preSplit, _ := cmd.Flags().GetInt("pre-split")
+	scatterPolicy, _ := cmd.Flags().GetString("scatter-policy")
+	input := map[string]interface{}{
+		"name":           args[0],
+		"config":         config,
+		"pre_split":      preSplit,
+		"scatter_policy": scatterPolicy,
+	}
+	sendKeyspaceRequest(cmd, http.MethodPost, keyspacesPrefix, input, "create the keyspace")
+}
+
+func updateKeyspaceConfigCommandFunc(cmd *cobra.Command, args []string) {
+	set, _ := cmd.Flags().GetStringToString("set")
+	remove, _ := cmd.Flags().GetStringSlice("remove")
+	if len(args) != 1 || len(set)+len(remove) == 0 {
+		cmd.Usage()
+		return
+	}
+	config := make(map[string]*string, len(set)+len(remove))
+	for k, v := range set {
+		v := v
+		config[k] = &v
+	}
+	for _, k := range remove {
+		config[k] = nil
+	}
+	input := map[string]interface{}{"config": config}
+	sendKeyspaceRequest(cmd, http.MethodPatch, keyspacePath(args[0], "config"), input, "update the keyspace config")
+}
+
+func updateKeyspaceStateCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		cmd.Usage()
+		return
+	}
+	input := map[string]interface{}{"state": strings.ToLower(args[1])}
+	sendKeyspaceRequest(cmd, http.MethodPut, keyspacePath(args[0], "state"), input, "update the keyspace state")
+}
+
+func renameKeyspaceCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		cmd.Usage()
+		return
+	}
+	input := map[string]interface{}{"new_name": args[1]}
+	sendKeyspaceRequest(cmd, http.MethodPost, keyspacePath(args[0], "rename"), input, "rename the keyspace")
+}
+
+// keyspaceActionCommandFunc returns a command function which sends a request
+// without body to the given sub path of the keyspace.
+func keyspaceActionCommandFunc(action string) func(*cobra.Command, []string) {
+	method := http.MethodPost
+	if action == "usage" {
+		method = http.MethodGet
+	}
+	return func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			cmd.Usage()
+			return
+		}
+		r, err := doRequest(cmd, keyspacePath(args[0], action), method, http.Header{})
+		if err != nil {
+			cmd.Printf("Failed to %s the keyspace: %s\n", action, err)
+			return
+		}
+		cmd.Println(r)
+	}
+}
+
+func keyspaceQuotaCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Usage()
+		return
+	}
+	r, err := doRequest(cmd, keyspacePath(args[0], "quota"), http.MethodGet, http.Header{})
+	if err != nil {
+		cmd.Printf("Failed to get the keyspace quota: %s\n", err)
+		return
+	}
+	if !cmd.Flags().Changed("max-regions") && !cmd.Flags().Changed("max-size") {
+		cmd.Println(r)
+		return
+	}
+	// Keep the limits which are not specified unchanged.
+	var usage struct {
+		Quota map[string]uint64 `json:"quota"`
+	}
+	if err := json.Unmarshal([]byte(r), &usage); err != nil {
+		cmd.Println(err)
+		return
+	}
+	input := make(map[string]interface{}, len(usage.Quota))
+	for k, v := range usage.Quota {
+		input[k] = v
+	}
+	if cmd.Flags().Changed("max-regions") {
+		input["max_regions"], _ = cmd.Flags().GetUint64("max-regions")
+	}
+	if cmd.Flags().Changed("max-size") {
+		input["max_approximate_size"], _ = cmd.Flags().GetUint64("max-size")
+	}
+	sendKeyspaceRequest(cmd, http.MethodPut, keyspacePath(args[0], "quota"), input, "set the keyspace quota")
+}
+
+func sendKeyspaceRequest(cmd *cobra.Command, method, prefix string, input map[string]interface{}, action string) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	r, err := doRequest(cmd, prefix, method, http.Header{"Content-Type": {"application/json"}}, WithBody(bytes.NewReader(data)))
+	if err != nil {
+		cmd.Printf("Failed to %s: %s\n", action, err)
+		return
+	}
+	cmd.Println(r)
+}
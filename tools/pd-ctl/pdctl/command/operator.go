@@ -15,12 +15,17 @@
 package command
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/pingcap/errors"
 	"github.com/spf13/cobra"
+	"github.com/tikv/pd/server/schedule/operator"
 )
 
 var (
@@ -57,6 +62,7 @@ func NewOperatorCommand() *cobra.Command {
 	c.AddCommand(NewAddOperatorCommand())
 	c.AddCommand(NewRemoveOperatorCommand())
 	c.AddCommand(NewHistoryOperatorCommand())
+	c.AddCommand(NewTraceOperatorCommand())
 	return c
 }
 
@@ -465,6 +471,98 @@ func historyOperatorCommandFunc(cmd *cobra.Command, args []string) {
 	cmd.Println(records)
 }
 
+// NewTraceOperatorCommand returns a command to show the lifecycle of the recent operators of a region.
+func NewTraceOperatorCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "trace <region_id>",
+		Short: "show the lifecycle of the recent operators of the region, from the oldest to the running one",
+		Run:   traceOperatorCommandFunc,
+	}
+	c.Flags().Bool("json", false, "print the traces in the json format")
+	return c
+}
+
+func traceOperatorCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	regionID, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	r, err := doRequest(cmd, fmt.Sprintf("%s/%d/traces", operatorsPrefix, regionID), http.MethodGet, http.Header{})
+	if err != nil {
+		cmd.Printf("Failed to get the operator traces: %s\n", err)
+		return
+	}
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		cmd.Println(r)
+		return
+	}
+	var traces []*operator.OpTrace
+	if err := json.Unmarshal([]byte(r), &traces); err != nil {
+		cmd.Printf("Failed to parse the operator traces: %s\n", err)
+		return
+	}
+	if len(traces) == 0 {
+		cmd.Printf("No recent operator of region %d\n", regionID)
+		return
+	}
+	for i, trace := range traces {
+		if i > 0 {
+			cmd.Println()
+		}
+		cmd.Print(formatOperatorTrace(trace, time.Now()))
+	}
+}
+
+// formatOperatorTrace renders the trace as a timeline. The durations of the
+// running operator and step are counted until now.
+func formatOperatorTrace(trace *operator.OpTrace, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s {%s} (kind: %s)\n", trace.Desc, trace.Brief, trace.Kind)
+	status := trace.Status
+	if trace.Reason != "" {
+		status += ": " + trace.Reason
+	}
+	fmt.Fprintf(&b, "  status:   %s\n", status)
+	fmt.Fprintf(&b, "  created:  %s\n", trace.CreateTime.Format(time.RFC3339Nano))
+	if trace.StartTime != nil {
+		fmt.Fprintf(&b, "  started:  %s (waited %s)\n", trace.StartTime.Format(time.RFC3339Nano), formatTraceDuration(trace.CreateTime, trace.StartTime, now))
+	}
+	if trace.FinishTime != nil {
+		start := trace.CreateTime
+		if trace.StartTime != nil {
+			start = *trace.StartTime
+		}
+		fmt.Fprintf(&b, "  finished: %s (took %s)\n", trace.FinishTime.Format(time.RFC3339Nano), formatTraceDuration(start, trace.FinishTime, now))
+	}
+	if len(trace.AdditionalInfos) > 0 {
+		infos, _ := json.Marshal(trace.AdditionalInfos)
+		fmt.Fprintf(&b, "  info:     %s\n", infos)
+	}
+	b.WriteString("  steps:\n")
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	for i, step := range trace.Steps {
+		duration := "-"
+		if step.StartTime != nil {
+			duration = formatTraceDuration(*step.StartTime, step.FinishTime, now)
+		}
+		fmt.Fprintf(w, "    %d. %s\t%s\t%s\n", i+1, step.Step, step.Status, duration)
+	}
+	w.Flush()
+	return b.String()
+}
+
+func formatTraceDuration(start time.Time, finish *time.Time, now time.Time) string {
+	if finish == nil {
+		return now.Sub(start).Round(time.Millisecond).String() + " so far"
+	}
+	return finish.Sub(start).Round(time.Millisecond).String()
+}
+
 func parseUint64s(args []string) ([]uint64, error) {
 	results := make([]uint64, 0, len(args))
 	for _, arg := range args {
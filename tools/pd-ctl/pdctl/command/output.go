@@ -0,0 +1,254 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pingcap/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// The output formats of pd-ctl. The JSON results are printed as they are
+// returned by PD in the json format, and converted in the other formats with
+// the same field names.
+const (
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+	OutputTable = "table"
+	OutputTSV   = "tsv"
+)
+
+// SetOutputFormat makes the JSON results printed by the subcommands of the
+// root command in the given format. The other outputs, e.g. the error
+// messages, are printed as they are.
+func SetOutputFormat(root *cobra.Command, format string) error {
+	out := root.OutOrStdout()
+	if f, ok := out.(*outputFormatter); ok {
+		out = f.w
+	}
+	switch format {
+	case OutputJSON:
+		root.SetOut(out)
+	case OutputYAML, OutputTable, OutputTSV:
+		root.SetOut(&outputFormatter{w: out, format: format})
+	default:
+		return errors.Errorf("unsupported output format %q, should be one of json, yaml, table and tsv", format)
+	}
+	return nil
+}
+
+// outputFile returns the file which the command prints to, if any.
+func outputFile(cmd *cobra.Command) (*os.File, bool) {
+	out := cmd.OutOrStdout()
+	if f, ok := out.(*outputFormatter); ok {
+		out = f.w
+	}
+	file, ok := out.(*os.File)
+	return file, ok
+}
+
+// outputFormatter converts each write which is a complete JSON value to the
+// format. The commands print a result with a single write, so there is no
+// need to buffer the output.
+type outputFormatter struct {
+	w      io.Writer
+	format string
+}
+
+func (f *outputFormatter) Write(p []byte) (int, error) {
+	trimmed := bytes.TrimSpace(p)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return f.w.Write(p)
+	}
+	d := json.NewDecoder(bytes.NewReader(trimmed))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil || d.More() {
+		return f.w.Write(p)
+	}
+	var out []byte
+	switch f.format {
+	case OutputYAML:
+		var b bytes.Buffer
+		e := yaml.NewEncoder(&b)
+		e.SetIndent(2)
+		if err := e.Encode(yamlValue(v)); err != nil {
+			return f.w.Write(p)
+		}
+		e.Close()
+		out = b.Bytes()
+	case OutputTable, OutputTSV:
+		out = formatRows(v, f.format == OutputTSV)
+	default:
+		return f.w.Write(p)
+	}
+	if _, err := f.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// yamlValue converts the JSON numbers to the integers or floats, so they are
+// not quoted in YAML.
+func yamlValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if u, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+			return u
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = yamlValue(e)
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = yamlValue(e)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// formatRows prints the JSON value as rows, with the columns sorted by the
+// field names. The nested objects are flattened with the dotted field names,
+// e.g. `store.id`.
+func formatRows(v interface{}, tsv bool) []byte {
+	items, ok := v.([]interface{})
+	if !ok {
+		items = []interface{}{v}
+		if list, found := listField(v); found {
+			items = list
+		}
+	}
+	columnSet := make(map[string]struct{})
+	rows := make([]map[string]string, 0, len(items))
+	for _, item := range items {
+		row := make(map[string]string)
+		if obj, ok := item.(map[string]interface{}); ok {
+			flattenObject("", obj, row)
+		} else {
+			row["value"] = formatCell(item)
+		}
+		for k := range row {
+			columnSet[k] = struct{}{}
+		}
+		rows = append(rows, row)
+	}
+	if len(columnSet) == 0 {
+		return nil
+	}
+	columns := make([]string, 0, len(columnSet))
+	for k := range columnSet {
+		columns = append(columns, k)
+	}
+	sort.Strings(columns)
+
+	var b bytes.Buffer
+	if tsv {
+		b.WriteString(strings.Join(columns, "\t") + "\n")
+		for _, row := range rows {
+			cells := make([]string, len(columns))
+			for i, column := range columns {
+				cells[i] = strings.NewReplacer("\t", `\t`, "\n", `\n`).Replace(row[column])
+			}
+			b.WriteString(strings.Join(cells, "\t") + "\n")
+		}
+		return b.Bytes()
+	}
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(columns, "\t"))
+	for _, row := range rows {
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			cells[i] = strings.ReplaceAll(row[column], "\t", " ")
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	w.Flush()
+	return b.Bytes()
+}
+
+// listField returns the only field which is a list of objects, e.g. the
+// `stores` of `{"count": 1, "stores": [...]}`.
+func listField(v interface{}) ([]interface{}, bool) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	var list []interface{}
+	found := 0
+	for _, field := range obj {
+		items, ok := field.([]interface{})
+		if !ok || len(items) == 0 {
+			continue
+		}
+		if _, ok := items[0].(map[string]interface{}); ok {
+			list = items
+			found++
+		}
+	}
+	return list, found == 1
+}
+
+func flattenObject(prefix string, obj map[string]interface{}, row map[string]string) {
+	for k, v := range obj {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+		if nested, ok := v.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenObject(k, nested, row)
+			continue
+		}
+		row[k] = formatCell(v)
+	}
+}
+
+func formatCell(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
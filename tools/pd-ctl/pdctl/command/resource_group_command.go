@@ -0,0 +1,597 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pingcap/errors"
+	rmpb "github.com/pingcap/kvproto/pkg/resource_manager"
+	"github.com/spf13/cobra"
+)
+
+var (
+	resourceGroupsPrefix       = "resource-manager/api/v1/config/groups"
+	resourceGroupPrefix        = "resource-manager/api/v1/config/group"
+	runawayWatchesPrefix       = "resource-manager/api/v1/config/runaway-watches"
+	runawayWatchPrefix         = "resource-manager/api/v1/config/runaway-watch"
+	ruConsumptionPrefix        = "resource-manager/api/v1/consumption"
+	ruConsumptionHistoryPrefix = "resource-manager/api/v1/consumption/history"
+)
+
+// NewResourceGroupCommand return a resource group subcommand of rootCmd
+func NewResourceGroupCommand() *cobra.Command {
+	r := &cobra.Command{
+		Use:   "resource-group <subcommand>",
+		Short: "resource group commands",
+	}
+	r.AddCommand(NewListResourceGroupsCommand())
+	r.AddCommand(NewShowResourceGroupCommand())
+	r.AddCommand(NewCreateResourceGroupCommand())
+	r.AddCommand(NewUpdateResourceGroupCommand())
+	r.AddCommand(NewDeleteResourceGroupCommand())
+	r.AddCommand(NewResourceGroupConsumptionCommand())
+	r.AddCommand(NewResourceGroupRunawayCommand())
+	r.AddCommand(NewExportResourceGroupsCommand())
+	r.AddCommand(NewImportResourceGroupsCommand())
+	return r
+}
+
+// NewListResourceGroupsCommand return a subcommand to list the resource groups
+func NewListResourceGroupsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "list all the resource groups",
+		Run:   listResourceGroupsCommandFunc,
+	}
+}
+
+// NewShowResourceGroupCommand return a subcommand to show a resource group
+func NewShowResourceGroupCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:               "show <name>",
+		Short:             "show a resource group",
+		Run:               showResourceGroupCommandFunc,
+		ValidArgsFunction: completeFirstArg(resourceGroupCandidates),
+	}
+}
+
+// NewCreateResourceGroupCommand return a subcommand to create a resource group
+func NewCreateResourceGroupCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "create <name> --ru-per-sec <ru> [--burst-limit <limit>] [--priority <priority>]",
+		Short: "create a resource group in the RU mode",
+		Run:   createResourceGroupCommandFunc,
+	}
+	addResourceGroupSettingsFlags(c)
+	return c
+}
+
+// NewUpdateResourceGroupCommand return a subcommand to update a resource group
+func NewUpdateResourceGroupCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:               "update <name> [--ru-per-sec <ru>] [--burst-limit <limit>] [--priority <priority>]",
+		Short:             "update the settings of a resource group in the RU mode, the unspecified ones are kept",
+		Run:               updateResourceGroupCommandFunc,
+		ValidArgsFunction: completeFirstArg(resourceGroupCandidates),
+	}
+	addResourceGroupSettingsFlags(c)
+	return c
+}
+
+func addResourceGroupSettingsFlags(c *cobra.Command) {
+	c.Flags().Uint64("ru-per-sec", 0, "the RU filled per second")
+	c.Flags().Int64("burst-limit", 0, "the tokens can be accumulated, 0 means no limit on the accumulation, -1 means no limit on the consumption")
+	c.Flags().Uint32("priority", 0, "the priority to admit the token requests")
+}
+
+// NewDeleteResourceGroupCommand return a subcommand to delete a resource group
+func NewDeleteResourceGroupCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:               "delete <name>",
+		Short:             "delete a resource group",
+		Run:               deleteResourceGroupCommandFunc,
+		ValidArgsFunction: completeFirstArg(resourceGroupCandidates),
+	}
+}
+
+// NewResourceGroupConsumptionCommand return a subcommand to show the RU consumption
+func NewResourceGroupConsumptionCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "consumption [--group <name>] [--store <store_id>]",
+		Short: "show the aggregated RU consumption of the resource groups on the stores",
+		Run:   showRUConsumptionCommandFunc,
+	}
+	c.Flags().String("group", "", "only show the consumption of the resource group")
+	c.Flags().Uint64("store", 0, "only show the consumption on the store")
+	completeFlag(c, "group", resourceGroupCandidates)
+	completeFlag(c, "store", storeIDCandidates)
+	history := &cobra.Command{
+		Use:   "history [--group <name>] [--from <time>] [--to <time>] [--sum]",
+		Short: "show the RU consumption history by minute, or summed up by resource group",
+		Long: "show the RU consumption history by minute, or summed up by resource group.\n" +
+			"The time can be a RFC3339 time, a unix timestamp in seconds, or a duration before now like 1h.",
+		Run: showRUConsumptionHistoryCommandFunc,
+	}
+	history.Flags().String("group", "", "only show the consumption history of the resource group")
+	history.Flags().String("from", "", "the start of the time range, the earliest by default")
+	history.Flags().String("to", "", "the end of the time range, now by default")
+	history.Flags().Bool("sum", false, "sum up the RU by resource group and sort by the total RU")
+	completeFlag(history, "group", resourceGroupCandidates)
+	c.AddCommand(history)
+	return c
+}
+
+// NewResourceGroupRunawayCommand return a subcommand to manage the runaway rules
+func NewResourceGroupRunawayCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "runaway <subcommand>",
+		Short: "manage the runaway settings and the watch items of the resource groups",
+	}
+	set := &cobra.Command{
+		Use:               "set <name> --exec-elapsed <duration> --action dryrun|cooldown|kill [--watch exact|similar|plan] [--watch-duration <duration>]",
+		Short:             "set the runaway settings of a resource group",
+		Run:               setRunawaySettingsCommandFunc,
+		ValidArgsFunction: completeFirstArg(resourceGroupCandidates),
+	}
+	set.Flags().Duration("exec-elapsed", 0, "the execution time for a query to be identified as runaway")
+	set.Flags().String("action", "", "the action to the runaway queries, one of dryrun, cooldown and kill")
+	set.Flags().String("watch", "", "watch the identified queries by exact, similar or plan")
+	set.Flags().Duration("watch-duration", 0, "how long the identified queries are watched, 0 means forever")
+	watch := &cobra.Command{
+		Use:   "watch <subcommand>",
+		Short: "manage the runaway watch items",
+	}
+	watchList := &cobra.Command{
+		Use:   "list [--group <name>]",
+		Short: "list the unexpired runaway watch items",
+		Run:   listRunawayWatchesCommandFunc,
+	}
+	watchList.Flags().String("group", "", "only list the watch items of the resource group")
+	completeFlag(watchList, "group", resourceGroupCandidates)
+	watchAdd := &cobra.Command{
+		Use:               "add <name> <key> --type exact|similar|plan --action dryrun|cooldown|kill [--duration <duration>]",
+		Short:             "watch the queries of a resource group, the key is the SQL text, SQL digest or plan digest by the type",
+		Run:               addRunawayWatchCommandFunc,
+		ValidArgsFunction: completeFirstArg(resourceGroupCandidates),
+	}
+	watchAdd.Flags().String("type", "", "the type of the watch item, one of exact, similar and plan")
+	watchAdd.Flags().String("action", "", "the action to the watched queries, one of dryrun, cooldown and kill")
+	watchAdd.Flags().Duration("duration", 0, "how long the queries are watched, 0 means forever")
+	watch.AddCommand(watchList, watchAdd, &cobra.Command{
+		Use:   "delete <id>",
+		Short: "delete a runaway watch item",
+		Run:   deleteRunawayWatchCommandFunc,
+	})
+	c.AddCommand(
+		&cobra.Command{
+			Use:               "show <name>",
+			Short:             "show the runaway settings of a resource group",
+			Run:               showRunawaySettingsCommandFunc,
+			ValidArgsFunction: completeFirstArg(resourceGroupCandidates),
+		},
+		set,
+		&cobra.Command{
+			Use:               "delete <name>",
+			Short:             "remove the runaway settings of a resource group",
+			Run:               deleteRunawaySettingsCommandFunc,
+			ValidArgsFunction: completeFirstArg(resourceGroupCandidates),
+		},
+		watch,
+	)
+	return c
+}
+
+// NewExportResourceGroupsCommand return a subcommand to export the resource groups
+func NewExportResourceGroupsCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "export",
+		Short: "export the definitions of all the resource groups and the templates",
+		Run:   exportResourceGroupsCommandFunc,
+	}
+	c.Flags().String("out", "", "the output file")
+	return c
+}
+
+// NewImportResourceGroupsCommand return a subcommand to import the resource groups
+func NewImportResourceGroupsCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "import",
+		Short: "import the resource groups from the exported file and show the changes",
+		Run:   importResourceGroupsCommandFunc,
+	}
+	c.Flags().String("in", "resource_groups.json", "the file contains the exported resource groups")
+	c.Flags().Bool("dry-run", false, "only show the changes without applying them")
+	c.Flags().Bool("prune", false, "remove the resource groups not in the file")
+	return c
+}
+
+func exportResourceGroupsCommandFunc(cmd *cobra.Command, args []string) {
+	res, err := doRequest(cmd, resourceGroupsPrefix+"/export", http.MethodGet, http.Header{})
+	if err != nil {
+		cmd.Printf("Failed to export resource groups: %s\n", err)
+		return
+	}
+	file, _ := cmd.Flags().GetString("out")
+	if file == "" {
+		cmd.Println(res)
+		return
+	}
+	if err := os.WriteFile(file, []byte(res), 0644); err != nil { // #nosec
+		cmd.Println(err)
+		return
+	}
+	cmd.Printf("resource groups saved to file %s\n", file)
+}
+
+func importResourceGroupsCommandFunc(cmd *cobra.Command, args []string) {
+	file, _ := cmd.Flags().GetString("in")
+	content, err := os.ReadFile(file)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	query := make(url.Values)
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		query.Set("dry_run", "true")
+	}
+	if prune, _ := cmd.Flags().GetBool("prune"); prune {
+		query.Set("prune", "true")
+	}
+	path := resourceGroupsPrefix + "/import"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	res, err := doRequest(cmd, path, http.MethodPost, http.Header{"Content-Type": {"application/json"}}, WithBody(bytes.NewReader(content)))
+	if err != nil {
+		cmd.Printf("Failed to import resource groups: %s\n", err)
+		return
+	}
+	cmd.Println(res)
+}
+
+func listResourceGroupsCommandFunc(cmd *cobra.Command, args []string) {
+	res, err := doRequest(cmd, resourceGroupsPrefix, http.MethodGet, http.Header{})
+	if err != nil {
+		cmd.Printf("Failed to list resource groups: %s\n", err)
+		return
+	}
+	cmd.Println(res)
+}
+
+func showResourceGroupCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Usage()
+		return
+	}
+	res, err := doRequest(cmd, resourceGroupPrefix+"/"+url.PathEscape(args[0]), http.MethodGet, http.Header{})
+	if err != nil {
+		cmd.Printf("Failed to get the resource group: %s\n", err)
+		return
+	}
+	cmd.Println(res)
+}
+
+func createResourceGroupCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 || !cmd.Flags().Changed("ru-per-sec") {
+		cmd.Usage()
+		return
+	}
+	settings := &rmpb.TokenLimitSettings{}
+	settings.FillRate, _ = cmd.Flags().GetUint64("ru-per-sec")
+	settings.BurstLimit, _ = cmd.Flags().GetInt64("burst-limit")
+	group := newRUModeGroup(args[0], settings)
+	if err := sendResourceGroup(cmd, http.MethodPost, group); err != nil {
+		cmd.Printf("Failed to create the resource group: %s\n", err)
+		return
+	}
+	if err := setResourceGroupPriority(cmd, args[0]); err != nil {
+		cmd.Printf("Failed to set the priority of the resource group: %s\n", err)
+		return
+	}
+	cmd.Println("Success!")
+}
+
+func updateResourceGroupCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Usage()
+		return
+	}
+	if cmd.Flags().Changed("ru-per-sec") || cmd.Flags().Changed("burst-limit") {
+		// The settings are replaced as a whole, so keep the unspecified ones.
+		res, err := doRequest(cmd, resourceGroupPrefix+"/"+url.PathEscape(args[0]), http.MethodGet, http.Header{})
+		if err != nil {
+			cmd.Printf("Failed to get the resource group: %s\n", err)
+			return
+		}
+		var current struct {
+			Mode       rmpb.GroupMode `json:"mode"`
+			RUSettings *struct {
+				RU struct {
+					Settings *rmpb.TokenLimitSettings `json:"settings"`
+				} `json:"ru"`
+			} `json:"r_u_settings"`
+		}
+		if err := json.Unmarshal([]byte(res), &current); err != nil {
+			cmd.Printf("Failed to parse the resource group: %s\n", err)
+			return
+		}
+		if current.Mode != rmpb.GroupMode_RUMode || current.RUSettings == nil {
+			cmd.Println("Only the resource groups in the RU mode can be updated")
+			return
+		}
+		settings := current.RUSettings.RU.Settings
+		if settings == nil {
+			settings = &rmpb.TokenLimitSettings{}
+		}
+		if cmd.Flags().Changed("ru-per-sec") {
+			settings.FillRate, _ = cmd.Flags().GetUint64("ru-per-sec")
+		}
+		if cmd.Flags().Changed("burst-limit") {
+			settings.BurstLimit, _ = cmd.Flags().GetInt64("burst-limit")
+		}
+		group := newRUModeGroup(args[0], settings)
+		if err := sendResourceGroup(cmd, http.MethodPut, group); err != nil {
+			cmd.Printf("Failed to update the resource group: %s\n", err)
+			return
+		}
+	}
+	if err := setResourceGroupPriority(cmd, args[0]); err != nil {
+		cmd.Printf("Failed to set the priority of the resource group: %s\n", err)
+		return
+	}
+	cmd.Println("Success!")
+}
+
+func newRUModeGroup(name string, settings *rmpb.TokenLimitSettings) *rmpb.ResourceGroup {
+	return &rmpb.ResourceGroup{
+		Name: name,
+		Mode: rmpb.GroupMode_RUMode,
+		RUSettings: &rmpb.GroupRequestUnitSettings{
+			RU: &rmpb.TokenBucket{Settings: settings},
+		},
+	}
+}
+
+func sendResourceGroup(cmd *cobra.Command, method string, group *rmpb.ResourceGroup) error {
+	data, err := json.Marshal(group)
+	if err != nil {
+		return err
+	}
+	_, err = doRequest(cmd, resourceGroupPrefix, method, http.Header{"Content-Type": {"application/json"}}, WithBody(bytes.NewReader(data)))
+	return err
+}
+
+// setResourceGroupPriority sets the priority of the resource group if the
+// `priority` flag is specified.
+func setResourceGroupPriority(cmd *cobra.Command, name string) error {
+	if !cmd.Flags().Changed("priority") {
+		return nil
+	}
+	priority, _ := cmd.Flags().GetUint32("priority")
+	data, err := json.Marshal(map[string]uint32{"priority": priority})
+	if err != nil {
+		return err
+	}
+	_, err = doRequest(cmd, resourceGroupPrefix+"/"+url.PathEscape(name)+"/priority", http.MethodPut,
+		http.Header{"Content-Type": {"application/json"}}, WithBody(bytes.NewReader(data)))
+	return err
+}
+
+func deleteResourceGroupCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Usage()
+		return
+	}
+	if _, err := doRequest(cmd, resourceGroupPrefix+"/"+url.PathEscape(args[0]), http.MethodDelete, http.Header{}); err != nil {
+		cmd.Printf("Failed to delete the resource group: %s\n", err)
+		return
+	}
+	cmd.Println("Success!")
+}
+
+func showRUConsumptionCommandFunc(cmd *cobra.Command, args []string) {
+	query := make(url.Values)
+	if group, _ := cmd.Flags().GetString("group"); group != "" {
+		query.Set("group", group)
+	}
+	if store, _ := cmd.Flags().GetUint64("store"); store != 0 {
+		query.Set("store_id", strconv.FormatUint(store, 10))
+	}
+	path := ruConsumptionPrefix
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	res, err := doRequest(cmd, path, http.MethodGet, http.Header{})
+	if err != nil {
+		cmd.Printf("Failed to get the RU consumption: %s\n", err)
+		return
+	}
+	cmd.Println(res)
+}
+
+func showRUConsumptionHistoryCommandFunc(cmd *cobra.Command, args []string) {
+	query := make(url.Values)
+	if group, _ := cmd.Flags().GetString("group"); group != "" {
+		query.Set("group", group)
+	}
+	now := time.Now()
+	for flag, key := range map[string]string{"from": "start", "to": "end"} {
+		str, _ := cmd.Flags().GetString(flag)
+		if str == "" {
+			continue
+		}
+		t, err := parseTimeFlag(str, now)
+		if err != nil {
+			cmd.Printf("Invalid %s: %s\n", flag, err)
+			return
+		}
+		query.Set(key, strconv.FormatInt(t.Unix(), 10))
+	}
+	if sum, _ := cmd.Flags().GetBool("sum"); sum {
+		query.Set("sum", "true")
+	}
+	path := ruConsumptionHistoryPrefix
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	res, err := doRequest(cmd, path, http.MethodGet, http.Header{})
+	if err != nil {
+		cmd.Printf("Failed to get the RU consumption history: %s\n", err)
+		return
+	}
+	cmd.Println(res)
+}
+
+// parseTimeFlag parses a time in the RFC3339 format, a unix timestamp in
+// seconds, or a duration before now, e.g. 1h.
+func parseTimeFlag(str string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, str); err == nil {
+		return t, nil
+	}
+	if sec, err := strconv.ParseInt(str, 10, 64); err == nil && sec >= 0 {
+		return time.Unix(sec, 0), nil
+	}
+	if d, err := time.ParseDuration(str); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, errors.Errorf("%q is neither a RFC3339 time, a unix timestamp nor a duration", str)
+}
+
+func showRunawaySettingsCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Usage()
+		return
+	}
+	res, err := doRequest(cmd, fmt.Sprintf("%s/%s/runaway", resourceGroupPrefix, url.PathEscape(args[0])), http.MethodGet, http.Header{})
+	if err != nil {
+		cmd.Printf("Failed to get the runaway settings: %s\n", err)
+		return
+	}
+	cmd.Println(res)
+}
+
+func setRunawaySettingsCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Usage()
+		return
+	}
+	execElapsed, _ := cmd.Flags().GetDuration("exec-elapsed")
+	action, _ := cmd.Flags().GetString("action")
+	settings := map[string]interface{}{
+		"exec_elapsed_time_ms": execElapsed.Milliseconds(),
+		"action":               action,
+	}
+	if watch, _ := cmd.Flags().GetString("watch"); watch != "" {
+		duration, _ := cmd.Flags().GetDuration("watch-duration")
+		settings["watch"] = map[string]interface{}{
+			"type":                watch,
+			"lasting_duration_ms": duration.Milliseconds(),
+		}
+	}
+	data, err := json.Marshal(settings)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	_, err = doRequest(cmd, fmt.Sprintf("%s/%s/runaway", resourceGroupPrefix, url.PathEscape(args[0])), http.MethodPut,
+		http.Header{"Content-Type": {"application/json"}}, WithBody(bytes.NewReader(data)))
+	if err != nil {
+		cmd.Printf("Failed to set the runaway settings: %s\n", err)
+		return
+	}
+	cmd.Println("Success!")
+}
+
+func deleteRunawaySettingsCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Usage()
+		return
+	}
+	_, err := doRequest(cmd, fmt.Sprintf("%s/%s/runaway", resourceGroupPrefix, url.PathEscape(args[0])), http.MethodDelete, http.Header{})
+	if err != nil {
+		cmd.Printf("Failed to remove the runaway settings: %s\n", err)
+		return
+	}
+	cmd.Println("Success!")
+}
+
+func listRunawayWatchesCommandFunc(cmd *cobra.Command, args []string) {
+	path := runawayWatchesPrefix
+	if group, _ := cmd.Flags().GetString("group"); group != "" {
+		path += "?group=" + url.QueryEscape(group)
+	}
+	res, err := doRequest(cmd, path, http.MethodGet, http.Header{})
+	if err != nil {
+		cmd.Printf("Failed to list the runaway watch items: %s\n", err)
+		return
+	}
+	cmd.Println(res)
+}
+
+func addRunawayWatchCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		cmd.Usage()
+		return
+	}
+	typ, _ := cmd.Flags().GetString("type")
+	action, _ := cmd.Flags().GetString("action")
+	item := map[string]interface{}{
+		"resource_group": args[0],
+		"key":            args[1],
+		"type":           typ,
+		"action":         action,
+		"source":         "manual",
+	}
+	if duration, _ := cmd.Flags().GetDuration("duration"); duration > 0 {
+		now := time.Now()
+		item["start_time"] = now.UnixMilli()
+		item["end_time"] = now.Add(duration).UnixMilli()
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	res, err := doRequest(cmd, runawayWatchPrefix, http.MethodPost, http.Header{"Content-Type": {"application/json"}}, WithBody(bytes.NewReader(data)))
+	if err != nil {
+		cmd.Printf("Failed to add the runaway watch item: %s\n", err)
+		return
+	}
+	cmd.Println(res)
+}
+
+func deleteRunawayWatchCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Usage()
+		return
+	}
+	if _, err := strconv.ParseUint(args[0], 10, 64); err != nil {
+		cmd.Usage()
+		return
+	}
+	if _, err := doRequest(cmd, runawayWatchPrefix+"/"+args[0], http.MethodDelete, http.Header{}); err != nil {
+		cmd.Printf("Failed to delete the runaway watch item: %s\n", err)
+		return
+	}
+	cmd.Println("Success!")
+}
@@ -21,8 +21,10 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pingcap/errors"
 	"github.com/spf13/cobra"
@@ -50,15 +52,17 @@ func NewSchedulerCommand() *cobra.Command {
 	c.AddCommand(NewResumeSchedulerCommand())
 	c.AddCommand(NewConfigSchedulerCommand())
 	c.AddCommand(NewDescribeSchedulerCommand())
+	c.AddCommand(NewDiagnoseSchedulerCommand())
 	return c
 }
 
 // NewPauseSchedulerCommand returns a command to pause a scheduler.
 func NewPauseSchedulerCommand() *cobra.Command {
 	c := &cobra.Command{
-		Use:   "pause <scheduler> <delay_seconds>",
-		Short: "pause a scheduler",
-		Run:   pauseSchedulerCommandFunc,
+		Use:               "pause <scheduler> <delay_seconds>",
+		Short:             "pause a scheduler",
+		Run:               pauseSchedulerCommandFunc,
+		ValidArgsFunction: completeFirstArg(schedulerCandidates),
 	}
 	return c
 }
@@ -81,9 +85,10 @@ func pauseSchedulerCommandFunc(cmd *cobra.Command, args []string) {
 // NewResumeSchedulerCommand returns a command to resume a scheduler.
 func NewResumeSchedulerCommand() *cobra.Command {
 	c := &cobra.Command{
-		Use:   "resume <scheduler>",
-		Short: "resume a scheduler",
-		Run:   resumeSchedulerCommandFunc,
+		Use:               "resume <scheduler>",
+		Short:             "resume a scheduler",
+		Run:               resumeSchedulerCommandFunc,
+		ValidArgsFunction: completeFirstArg(schedulerCandidates),
 	}
 	return c
 }
@@ -160,9 +165,10 @@ func NewAddSchedulerCommand() *cobra.Command {
 // NewGrantLeaderSchedulerCommand returns a command to add a grant-leader-scheduler.
 func NewGrantLeaderSchedulerCommand() *cobra.Command {
 	c := &cobra.Command{
-		Use:   "grant-leader-scheduler <store_id>",
-		Short: "add a scheduler to grant leader to a store",
-		Run:   addSchedulerForStoreCommandFunc,
+		Use:               "grant-leader-scheduler <store_id>",
+		Short:             "add a scheduler to grant leader to a store",
+		Run:               addSchedulerForStoreCommandFunc,
+		ValidArgsFunction: completeFirstArg(storeIDCandidates),
 	}
 	return c
 }
@@ -170,9 +176,10 @@ func NewGrantLeaderSchedulerCommand() *cobra.Command {
 // NewEvictLeaderSchedulerCommand returns a command to add a evict-leader-scheduler.
 func NewEvictLeaderSchedulerCommand() *cobra.Command {
 	c := &cobra.Command{
-		Use:   "evict-leader-scheduler <store_id>",
-		Short: "add a scheduler to evict leader from a store",
-		Run:   addSchedulerForStoreCommandFunc,
+		Use:               "evict-leader-scheduler <store_id>",
+		Short:             "add a scheduler to evict leader from a store",
+		Run:               addSchedulerForStoreCommandFunc,
+		ValidArgsFunction: completeFirstArg(storeIDCandidates),
 	}
 	return c
 }
@@ -451,9 +458,10 @@ func addSchedulerForScatterRangeCommandFunc(cmd *cobra.Command, args []string) {
 // NewRemoveSchedulerCommand returns a command to remove scheduler.
 func NewRemoveSchedulerCommand() *cobra.Command {
 	c := &cobra.Command{
-		Use:   "remove <scheduler>",
-		Short: "remove a scheduler",
-		Run:   removeSchedulerCommandFunc,
+		Use:               "remove <scheduler>",
+		Short:             "remove a scheduler",
+		Run:               removeSchedulerCommandFunc,
+		ValidArgsFunction: completeFirstArg(schedulerCandidates),
 	}
 	return c
 }
@@ -847,3 +855,212 @@ func describeSchedulerCommandFunc(cmd *cobra.Command, args []string) {
 	}
 	cmd.Println(r)
 }
+
+// NewDiagnoseSchedulerCommand returns a command to explain why a scheduler
+// doesn't generate operators.
+func NewDiagnoseSchedulerCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:               "diagnose <scheduler>",
+		Short:             "explain why a scheduler is idle, with the rejecting reasons and the relevant limits",
+		Run:               diagnoseSchedulerCommandFunc,
+		ValidArgsFunction: completeFirstArg(schedulerCandidates),
+	}
+	return c
+}
+
+var schedulerStatusExplanations = map[string]string{
+	"disabled":   "the scheduler is not added or has been disabled",
+	"paused":     "the scheduler is paused, no operator will be generated until it is resumed",
+	"scheduling": "the scheduler is generating operators",
+	"pending":    "the scheduler cannot generate any operator, see the reasons below",
+	"normal":     "the stores are balanced, no operator is needed",
+	"enabled":    "the scheduler is running",
+}
+
+type schedulerDiagnosis struct {
+	Name      string            `json:"name"`
+	Status    string            `json:"status"`
+	Summary   string            `json:"summary"`
+	Timestamp int64             `json:"timestamp"`
+	Stores    map[uint64]string `json:"stores"`
+	// note explains why the detailed diagnosis is not available.
+	note string
+}
+
+func diagnoseSchedulerCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	name := args[0]
+	d, err := getSchedulerDiagnosis(cmd, name)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Scheduler: %s\n", name)
+	if explanation, ok := schedulerStatusExplanations[d.Status]; ok {
+		fmt.Fprintf(&b, "Status: %s (%s)\n", d.Status, explanation)
+	} else {
+		fmt.Fprintf(&b, "Status: %s\n", d.Status)
+	}
+	if d.Timestamp > 0 {
+		fmt.Fprintf(&b, "Diagnosed at: %s\n", time.Unix(d.Timestamp, 0).Format(time.RFC3339))
+	}
+	if reasons := splitDiagnosticSummary(d.Summary); len(reasons) > 0 {
+		b.WriteString("Reasons:\n")
+		for _, reason := range reasons {
+			fmt.Fprintf(&b, "  %s\n", reason)
+		}
+	}
+	if len(d.Stores) > 0 {
+		b.WriteString("Stores:\n")
+		storeIDs := make([]uint64, 0, len(d.Stores))
+		for id := range d.Stores {
+			storeIDs = append(storeIDs, id)
+		}
+		sort.Slice(storeIDs, func(i, j int) bool { return storeIDs[i] < storeIDs[j] })
+		for _, id := range storeIDs {
+			fmt.Fprintf(&b, "  store %d: %s\n", id, d.Stores[id])
+		}
+	}
+	b.WriteString("Limits:\n")
+	writeSchedulerLimits(cmd, &b, name)
+	if d.note != "" {
+		fmt.Fprintf(&b, "Note: %s\n", d.note)
+	}
+	cmd.Print(b.String())
+}
+
+// getSchedulerDiagnosis gets the diagnostic result of the scheduler. If the
+// scheduler can't be diagnosed, only the status is filled in.
+func getSchedulerDiagnosis(cmd *cobra.Command, name string) (*schedulerDiagnosis, error) {
+	r, err := doRequest(cmd, path.Join(schedulerDiagnosticPrefix, name), http.MethodGet, http.Header{})
+	if err == nil {
+		d := &schedulerDiagnosis{}
+		if err := json.Unmarshal([]byte(r), d); err != nil {
+			return nil, err
+		}
+		return d, nil
+	}
+
+	var note string
+	switch msg := err.Error(); {
+	case strings.Contains(msg, "hasn't supported diagnostic"):
+		note = "only balance-region-scheduler and balance-leader-scheduler support the detailed diagnosis"
+	case strings.Contains(msg, "diagnostic is disabled"):
+		note = "the diagnostic is disabled, enable it with `config set enable-diagnostic true` for the detailed diagnosis"
+	case strings.Contains(msg, "has no diagnostic result"):
+		note = "the scheduler has no diagnostic result yet, please retry later"
+	default:
+		return nil, err
+	}
+	status, err := getSchedulerStatus(cmd, name)
+	if err != nil {
+		return nil, err
+	}
+	return &schedulerDiagnosis{Name: name, Status: status, note: note}, nil
+}
+
+func getSchedulerStatus(cmd *cobra.Command, name string) (string, error) {
+	for _, s := range []struct {
+		query  string
+		status string
+		listed bool
+	}{
+		{query: "", status: "disabled", listed: false},
+		{query: "?status=disabled", status: "disabled", listed: true},
+		{query: "?status=paused", status: "paused", listed: true},
+	} {
+		r, err := doRequest(cmd, schedulersPrefix+s.query, http.MethodGet, http.Header{})
+		if err != nil {
+			return "", err
+		}
+		var names []string
+		if err := json.Unmarshal([]byte(r), &names); err != nil {
+			return "", err
+		}
+		if containsString(names, name) == s.listed {
+			return s.status, nil
+		}
+	}
+	return "enabled", nil
+}
+
+func containsString(strs []string, s string) bool {
+	for _, str := range strs {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
+
+func splitDiagnosticSummary(summary string) []string {
+	var reasons []string
+	for _, reason := range strings.Split(summary, ";") {
+		if reason = strings.TrimSpace(reason); reason != "" {
+			reasons = append(reasons, reason)
+		}
+	}
+	sort.Strings(reasons)
+	return reasons
+}
+
+// writeSchedulerLimits writes the schedule limits which may stop the
+// scheduler from generating operators.
+func writeSchedulerLimits(cmd *cobra.Command, b *strings.Builder, name string) {
+	limitKey, opKind := "region-schedule-limit", "region"
+	switch {
+	case strings.Contains(name, "hot"):
+		limitKey, opKind = "hot-region-schedule-limit", ""
+	case strings.Contains(name, "leader"):
+		limitKey, opKind = "leader-schedule-limit", "leader"
+	}
+
+	r, err := doRequest(cmd, schedulePrefix, http.MethodGet, http.Header{})
+	if err != nil {
+		fmt.Fprintf(b, "  unavailable: %v\n", err)
+		return
+	}
+	cfg := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(r), &cfg); err != nil {
+		fmt.Fprintf(b, "  unavailable: %v\n", err)
+		return
+	}
+	fmt.Fprintf(b, "  %s: %v", limitKey, cfg[limitKey])
+	if opKind != "" {
+		r, err = doRequest(cmd, operatorsPrefix+"?kind="+opKind, http.MethodGet, http.Header{})
+		var ops []json.RawMessage
+		if err == nil && json.Unmarshal([]byte(r), &ops) == nil {
+			fmt.Fprintf(b, " (%d %s operator(s) running)", len(ops), opKind)
+		}
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(b, "  scheduler-max-waiting-operator: %v\n", cfg["scheduler-max-waiting-operator"])
+
+	if opKind != "region" {
+		return
+	}
+	r, err = doRequest(cmd, storesLimitPrefix, http.MethodGet, http.Header{})
+	if err != nil {
+		return
+	}
+	limits := make(map[uint64]struct {
+		AddPeer    float64 `json:"add-peer"`
+		RemovePeer float64 `json:"remove-peer"`
+	})
+	if err := json.Unmarshal([]byte(r), &limits); err != nil {
+		return
+	}
+	storeIDs := make([]uint64, 0, len(limits))
+	for id := range limits {
+		storeIDs = append(storeIDs, id)
+	}
+	sort.Slice(storeIDs, func(i, j int) bool { return storeIDs[i] < storeIDs[j] })
+	for _, id := range storeIDs {
+		fmt.Fprintf(b, "  store %d limit: add-peer %v, remove-peer %v\n", id, limits[id].AddPeer, limits[id].RemovePeer)
+	}
+}
@@ -0,0 +1,433 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/spf13/cobra"
+	"github.com/tikv/pd/pkg/core"
+	"github.com/tikv/pd/pkg/mock/mockcluster"
+	"github.com/tikv/pd/pkg/storage"
+	"github.com/tikv/pd/server/api"
+	"github.com/tikv/pd/server/config"
+	"github.com/tikv/pd/server/schedule"
+	"github.com/tikv/pd/server/schedule/checker"
+	"github.com/tikv/pd/server/schedule/hbstream"
+	"github.com/tikv/pd/server/schedule/operator"
+	"github.com/tikv/pd/server/schedule/placement"
+	_ "github.com/tikv/pd/server/schedulers" // register the schedulers
+)
+
+// clusterSnapshot is the metadata of a cluster, which the scheduling can be
+// simulated against offline.
+type clusterSnapshot struct {
+	ExportTime  time.Time               `json:"export_time"`
+	Config      *config.Config          `json:"config"`
+	Stores      []*api.StoreInfo        `json:"stores"`
+	Regions     []api.RegionInfo        `json:"regions"`
+	RuleBundles []placement.GroupBundle `json:"rule_bundles,omitempty"`
+	Schedulers  []string                `json:"schedulers"`
+	// SchedulerConfigs are the configs of the schedulers which are configured
+	// independently, e.g. the evict-leader-scheduler.
+	SchedulerConfigs map[string]json.RawMessage `json:"scheduler_configs,omitempty"`
+}
+
+// simulatedOperator is an operator generated in the simulation.
+type simulatedOperator struct {
+	// Source is the scheduler or "checker" which generates the operator.
+	Source   string   `json:"source"`
+	RegionID uint64   `json:"region_id"`
+	Desc     string   `json:"desc"`
+	Brief    string   `json:"brief"`
+	Kind     string   `json:"kind"`
+	Steps    []string `json:"steps"`
+}
+
+type simulationResult struct {
+	// Summary is the count of the operators by source.
+	Summary   map[string]int       `json:"summary"`
+	Operators []*simulatedOperator `json:"operators"`
+}
+
+// NewSimulateCommand returns a command to preview the scheduling offline.
+func NewSimulateCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "simulate <command>",
+		Short: "preview the operators generated against a snapshot of the cluster offline",
+		Long: `Preview the operators generated against a snapshot of the cluster offline, so the changes of the config or
+the placement rules can be checked safely before they are applied to the cluster.
+
+  simulate export --out snapshot.json
+  simulate run --snapshot snapshot.json --config changes.toml --rules rules.json`,
+	}
+	c.AddCommand(NewSimulateExportCommand())
+	c.AddCommand(NewSimulateRunCommand())
+	return c
+}
+
+// NewSimulateExportCommand returns a command to export the snapshot of the cluster.
+func NewSimulateExportCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "export [--out <file>]",
+		Short: "export the stores, regions, config, placement rules and schedulers of the cluster",
+		Run:   simulateExportCommandFunc,
+	}
+	c.Flags().String("out", "", "the file to write the snapshot to, print it if not set")
+	return c
+}
+
+// NewSimulateRunCommand returns a command to run the schedulers and checkers against the snapshot.
+func NewSimulateRunCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "run --snapshot <file>",
+		Short: "run the schedulers and checkers once against the snapshot, and print the operators generated",
+		Long: `Run the schedulers and checkers once against the snapshot, and print the operators generated. No request is
+sent to PD. The operators are not applied, so only the first operators of the balance schedulers are shown.`,
+		Run: simulateRunCommandFunc,
+	}
+	c.Flags().String("snapshot", "", "the snapshot exported by `simulate export`")
+	c.Flags().String("config", "", "the config in json or toml to override the one in the snapshot, e.g. {\"schedule\": {\"leader-schedule-limit\": 8}}")
+	c.Flags().String("rules", "", "the placement rule bundles in json to replace the groups with the same IDs in the snapshot")
+	c.Flags().StringSlice("schedulers", nil, "the schedulers to run instead of the ones in the snapshot")
+	c.Flags().Bool("skip-schedulers", false, "don't run the schedulers")
+	c.Flags().Bool("skip-checkers", false, "don't run the checkers")
+	return c
+}
+
+func simulateExportCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Usage()
+		return
+	}
+	snapshot, err := exportClusterSnapshot(cmd)
+	if err != nil {
+		failedRequests.Add(1)
+		cmd.Printf("Failed to export the snapshot: %s\n", err)
+		return
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		cmd.Printf("Failed to export the snapshot: %s\n", err)
+		return
+	}
+	out, _ := cmd.Flags().GetString("out")
+	if out == "" {
+		cmd.Println(string(data))
+		return
+	}
+	if err := os.WriteFile(out, data, 0o600); err != nil {
+		cmd.Printf("Failed to export the snapshot: %s\n", err)
+		return
+	}
+	cmd.Printf("The snapshot of %d stores and %d regions is exported to %s\n", len(snapshot.Stores), len(snapshot.Regions), out)
+}
+
+func exportClusterSnapshot(cmd *cobra.Command) (*clusterSnapshot, error) {
+	snapshot := &clusterSnapshot{
+		ExportTime:       time.Now(),
+		SchedulerConfigs: make(map[string]json.RawMessage),
+	}
+	if err := getJSON(cmd, configPrefix, &snapshot.Config); err != nil {
+		return nil, err
+	}
+	var stores api.StoresInfo
+	if err := getJSON(cmd, storesPrefix, &stores); err != nil {
+		return nil, err
+	}
+	snapshot.Stores = stores.Stores
+	var regions api.RegionsInfo
+	if err := getJSON(cmd, regionsPrefix, &regions); err != nil {
+		return nil, err
+	}
+	snapshot.Regions = regions.Regions
+	if snapshot.Config.Replication.EnablePlacementRules {
+		if err := getJSON(cmd, ruleBundlePrefix, &snapshot.RuleBundles); err != nil {
+			return nil, err
+		}
+	}
+	if err := getJSON(cmd, schedulersPrefix, &snapshot.Schedulers); err != nil {
+		return nil, err
+	}
+	for _, name := range snapshot.Schedulers {
+		// Only the schedulers configured independently have the config.
+		r, err := doRequest(cmd, schedulerConfigPrefix+"/"+name+"/list", http.MethodGet, http.Header{})
+		if err == nil && json.Valid([]byte(r)) {
+			snapshot.SchedulerConfigs[name] = json.RawMessage(r)
+		}
+	}
+	return snapshot, nil
+}
+
+func simulateRunCommandFunc(cmd *cobra.Command, args []string) {
+	path, _ := cmd.Flags().GetString("snapshot")
+	if len(args) != 0 || path == "" {
+		cmd.Usage()
+		return
+	}
+	result, err := simulateScheduling(cmd, path)
+	if err != nil {
+		failedRequests.Add(1)
+		cmd.Printf("Failed to simulate: %s\n", err)
+		return
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		cmd.Printf("Failed to simulate: %s\n", err)
+		return
+	}
+	cmd.Println(string(data))
+}
+
+func simulateScheduling(cmd *cobra.Command, path string) (*simulationResult, error) {
+	snapshot, err := loadClusterSnapshot(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := snapshot.Config
+	if file, _ := cmd.Flags().GetString("config"); file != "" {
+		// Only the items in the file are overridden.
+		overrides, err := loadConfigBaseline(file)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(overrides)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return nil, errors.Annotate(err, "invalid config")
+		}
+	}
+	bundles := snapshot.RuleBundles
+	if file, _ := cmd.Flags().GetString("rules"); file != "" {
+		if !cfg.Replication.EnablePlacementRules {
+			return nil, errors.New("the placement rules are disabled, enable them with --config first")
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		var changes []placement.GroupBundle
+		if err := json.Unmarshal(content, &changes); err != nil {
+			return nil, errors.Annotate(err, "invalid rule bundles")
+		}
+		bundles = mergeRuleBundles(bundles, changes)
+	}
+	schedulers := snapshot.Schedulers
+	if cmd.Flags().Changed("schedulers") {
+		schedulers, _ = cmd.Flags().GetStringSlice("schedulers")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := newSimulatedCluster(ctx, cfg, snapshot, bundles)
+	if err != nil {
+		return nil, err
+	}
+	oc := schedule.NewOperatorController(ctx, cluster, hbstream.NewTestHeartbeatStreams(ctx, cluster.ID, cluster, false))
+	result := &simulationResult{Summary: make(map[string]int), Operators: []*simulatedOperator{}}
+	collect := func(source string, ops []*operator.Operator) {
+		for _, op := range ops {
+			result.Summary[source]++
+			result.Operators = append(result.Operators, newSimulatedOperator(source, op))
+		}
+	}
+	if skip, _ := cmd.Flags().GetBool("skip-schedulers"); !skip {
+		configStorage := storage.NewStorageWithMemoryBackend()
+		for _, name := range schedulers {
+			s, err := newSimulatedScheduler(oc, configStorage, cfg, snapshot, name)
+			if err != nil {
+				return nil, errors.Annotatef(err, "failed to create scheduler %s", name)
+			}
+			if err := s.Prepare(cluster); err != nil {
+				return nil, errors.Annotatef(err, "failed to prepare scheduler %s", name)
+			}
+			if s.IsScheduleAllowed(cluster) {
+				ops, _ := s.Schedule(cluster, false)
+				collect(s.GetName(), ops)
+			}
+			s.Cleanup(cluster)
+		}
+	}
+	if skip, _ := cmd.Flags().GetBool("skip-checkers"); !skip {
+		checkers := checker.NewController(ctx, cluster, cluster.GetRuleManager(), cluster.GetRegionLabeler(), oc)
+		regions := cluster.GetRegions()
+		sort.Slice(regions, func(i, j int) bool { return regions[i].GetID() < regions[j].GetID() })
+		for _, region := range regions {
+			collect("checker", checkers.CheckRegion(region))
+		}
+	}
+	return result, nil
+}
+
+func loadClusterSnapshot(path string) (*clusterSnapshot, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	snapshot := &clusterSnapshot{}
+	if err := json.Unmarshal(content, snapshot); err != nil {
+		return nil, errors.Annotate(err, "invalid snapshot")
+	}
+	if snapshot.Config == nil {
+		return nil, errors.New("invalid snapshot: no config")
+	}
+	return snapshot, nil
+}
+
+// mergeRuleBundles replaces the bundles with the changed ones of the same IDs.
+func mergeRuleBundles(bundles, changes []placement.GroupBundle) []placement.GroupBundle {
+	merged := make([]placement.GroupBundle, 0, len(bundles)+len(changes))
+	for _, bundle := range bundles {
+		replaced := false
+		for _, change := range changes {
+			if change.ID == bundle.ID {
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, bundle)
+		}
+	}
+	return append(merged, changes...)
+}
+
+// newSimulatedCluster builds the cluster from the snapshot. The stores are as
+// alive as they were when the snapshot was exported.
+func newSimulatedCluster(ctx context.Context, cfg *config.Config, snapshot *clusterSnapshot, bundles []placement.GroupBundle) (*mockcluster.Cluster, error) {
+	cluster := mockcluster.NewCluster(ctx, config.NewPersistOptions(cfg))
+	now := time.Now()
+	for _, s := range snapshot.Stores {
+		if s.Store == nil || s.Store.Store == nil || s.Status == nil {
+			continue
+		}
+		lastHeartbeat := now
+		if s.Status.LastHeartbeatTS != nil {
+			lastHeartbeat = now.Add(-snapshot.ExportTime.Sub(*s.Status.LastHeartbeatTS))
+		}
+		cluster.PutStore(core.NewStoreInfo(
+			s.Store.Store,
+			core.SetStoreStats(&pdpb.StoreStats{
+				StoreId:   s.Store.GetId(),
+				Capacity:  uint64(s.Status.Capacity),
+				Available: uint64(s.Status.Available),
+				UsedSize:  uint64(s.Status.UsedSize),
+			}),
+			core.SetLeaderWeight(s.Status.LeaderWeight),
+			core.SetRegionWeight(s.Status.RegionWeight),
+			core.SetLastHeartbeatTS(lastHeartbeat),
+		))
+	}
+	for i := range snapshot.Regions {
+		region, err := newSimulatedRegion(&snapshot.Regions[i])
+		if err != nil {
+			return nil, err
+		}
+		cluster.PutRegion(region)
+	}
+	for _, store := range cluster.GetStores() {
+		id := store.GetID()
+		cluster.PutStore(store.Clone(
+			core.SetLeaderCount(cluster.GetStoreLeaderCount(id)),
+			core.SetRegionCount(cluster.GetStoreRegionCount(id)),
+			core.SetPendingPeerCount(cluster.GetStorePendingPeerCount(id)),
+			core.SetLeaderSize(cluster.GetStoreLeaderRegionSize(id)),
+			core.SetRegionSize(cluster.GetStoreRegionSize(id)),
+		))
+	}
+	if cfg.Replication.EnablePlacementRules && len(bundles) > 0 {
+		if err := cluster.GetRuleManager().SetAllGroupBundles(bundles, true); err != nil {
+			return nil, errors.Annotate(err, "invalid rule bundles")
+		}
+	}
+	return cluster, nil
+}
+
+func newSimulatedRegion(r *api.RegionInfo) (*core.RegionInfo, error) {
+	startKey, err := hex.DecodeString(r.StartKey)
+	if err != nil {
+		return nil, errors.Annotatef(err, "invalid start key of region %d", r.ID)
+	}
+	endKey, err := hex.DecodeString(r.EndKey)
+	if err != nil {
+		return nil, errors.Annotatef(err, "invalid end key of region %d", r.ID)
+	}
+	meta := &metapb.Region{
+		Id:          r.ID,
+		StartKey:    startKey,
+		EndKey:      endKey,
+		RegionEpoch: r.RegionEpoch,
+	}
+	for _, peer := range r.Peers {
+		meta.Peers = append(meta.Peers, peer.Peer)
+	}
+	pendingPeers := make([]*metapb.Peer, 0, len(r.PendingPeers))
+	for _, peer := range r.PendingPeers {
+		pendingPeers = append(pendingPeers, peer.Peer)
+	}
+	downPeers := make([]*pdpb.PeerStats, 0, len(r.DownPeers))
+	for _, peer := range r.DownPeers {
+		downPeers = append(downPeers, &pdpb.PeerStats{Peer: peer.Peer.Peer, DownSeconds: peer.GetDownSeconds()})
+	}
+	return core.NewRegionInfo(meta, r.Leader.Peer,
+		core.SetApproximateSize(r.ApproximateSize),
+		core.SetApproximateKeys(r.ApproximateKeys),
+		core.WithPendingPeers(pendingPeers),
+		core.WithDownPeers(downPeers),
+	), nil
+}
+
+// newSimulatedScheduler creates the scheduler in the same way as the coordinator.
+func newSimulatedScheduler(oc *schedule.OperatorController, configStorage storage.Storage, cfg *config.Config, snapshot *clusterSnapshot, name string) (schedule.Scheduler, error) {
+	typ := schedule.FindSchedulerTypeByName(name)
+	if data, ok := snapshot.SchedulerConfigs[name]; ok {
+		return schedule.CreateScheduler(typ, oc, configStorage, schedule.ConfigJSONDecoder(data))
+	}
+	var args []string
+	for _, s := range cfg.Schedule.Schedulers {
+		if s.Type == typ {
+			args = s.Args
+			break
+		}
+	}
+	return schedule.CreateScheduler(typ, oc, configStorage, schedule.ConfigSliceDecoder(typ, args))
+}
+
+func newSimulatedOperator(source string, op *operator.Operator) *simulatedOperator {
+	trace := op.Trace("")
+	steps := make([]string, 0, len(trace.Steps))
+	for _, step := range trace.Steps {
+		steps = append(steps, step.Step)
+	}
+	return &simulatedOperator{
+		Source:   source,
+		RegionID: trace.RegionID,
+		Desc:     trace.Desc,
+		Brief:    trace.Brief,
+		Kind:     trace.Kind,
+		Steps:    steps,
+	}
+}
@@ -41,9 +41,10 @@ var (
 // NewStoreCommand return a stores subcommand of rootCmd
 func NewStoreCommand() *cobra.Command {
 	s := &cobra.Command{
-		Use:   `store [command] [flags]`,
-		Short: "manipulate or query stores",
-		Run:   showStoreCommandFunc,
+		Use:               `store [command] [flags]`,
+		Short:             "manipulate or query stores",
+		Run:               showStoreCommandFunc,
+		ValidArgsFunction: completeFirstArg(storeIDCandidates),
 	}
 	s.AddCommand(NewDeleteStoreCommand())
 	s.AddCommand(NewCancelDeleteStoreCommand())
@@ -53,6 +54,8 @@ func NewStoreCommand() *cobra.Command {
 	s.AddCommand(NewRemoveTombStoneCommand())
 	s.AddCommand(NewStoreLimitSceneCommand())
 	s.AddCommand(NewStoreCheckCommand())
+	s.AddCommand(NewStoreTreeCommand())
+	s.AddCommand(NewDrainStoreCommand())
 	s.Flags().String("jq", "", "jq query")
 	s.Flags().StringSlice("state", nil, "state filter")
 	return s
@@ -71,9 +74,10 @@ func NewDeleteStoreByAddrCommand() *cobra.Command {
 // NewDeleteStoreCommand return a delete subcommand of storeCmd
 func NewDeleteStoreCommand() *cobra.Command {
 	d := &cobra.Command{
-		Use:   "delete <store_id>",
-		Short: "delete the store",
-		Run:   deleteStoreCommandFunc,
+		Use:               "delete <store_id>",
+		Short:             "delete the store",
+		Run:               deleteStoreCommandFunc,
+		ValidArgsFunction: completeFirstArg(storeIDCandidates),
 	}
 	d.AddCommand(NewDeleteStoreByAddrCommand())
 	return d
@@ -92,9 +96,10 @@ func NewCancelDeleteStoreByAddrCommand() *cobra.Command {
 // NewCancelDeleteStoreCommand return a cancel delete subcommand of storeCmd
 func NewCancelDeleteStoreCommand() *cobra.Command {
 	d := &cobra.Command{
-		Use:   "cancel-delete <store_id>",
-		Short: "cancel delete the store",
-		Run:   cancelDeleteStoreCommandFunc,
+		Use:               "cancel-delete <store_id>",
+		Short:             "cancel delete the store",
+		Run:               cancelDeleteStoreCommandFunc,
+		ValidArgsFunction: completeFirstArg(storeIDCandidates),
 	}
 	d.AddCommand(NewCancelDeleteStoreByAddrCommand())
 	return d
@@ -111,8 +116,9 @@ func NewLabelStoreCommand() *cobra.Command {
 	label <store_id> <key> --delete
   # Rewrite all labels for the store
 	label <store_id> <key>=<value> [<key>=<value>]... --rewrite`,
-		Short: "Set a store's labels",
-		Run:   labelStoreCommandFunc,
+		Short:             "Set a store's labels",
+		Run:               labelStoreCommandFunc,
+		ValidArgsFunction: completeFirstArg(storeIDCandidates),
 	}
 	l.Flags().BoolP("force", "f", false, "[Deprecated] rewrite all labels for the store, same as rewrite")
 	l.Flags().BoolP("rewrite", "r", false, "rewrite all labels for the store")
@@ -123,19 +129,21 @@ func NewLabelStoreCommand() *cobra.Command {
 // NewSetStoreWeightCommand returns a weight subcommand of storeCmd.
 func NewSetStoreWeightCommand() *cobra.Command {
 	return &cobra.Command{
-		Use:   "weight <store_id> <leader_weight> <region_weight>",
-		Short: "set a store's leader and region balance weight",
-		Run:   setStoreWeightCommandFunc,
+		Use:               "weight <store_id> <leader_weight> <region_weight>",
+		Short:             "set a store's leader and region balance weight",
+		Run:               setStoreWeightCommandFunc,
+		ValidArgsFunction: completeFirstArg(storeIDCandidates),
 	}
 }
 
 // NewStoreLimitCommand returns a limit subcommand of storeCmd.
 func NewStoreLimitCommand() *cobra.Command {
 	c := &cobra.Command{
-		Use:   "limit [<store_id>|<all> [<key> <value>]... <limit> <type>]",
-		Short: "show or set a store's rate limit",
-		Long:  "show or set a store's rate limit, <type> can be 'add-peer'(default) or 'remove-peer'",
-		Run:   storeLimitCommandFunc,
+		Use:               "limit [<store_id>|<all> [<key> <value>]... <limit> <type>]",
+		Short:             "show or set a store's rate limit",
+		Long:              "show or set a store's rate limit, <type> can be 'add-peer'(default) or 'remove-peer'",
+		Run:               storeLimitCommandFunc,
+		ValidArgsFunction: completeFirstArg(storeIDCandidates),
 	}
 	return c
 }
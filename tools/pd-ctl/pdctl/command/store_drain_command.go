@@ -0,0 +1,275 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/spf13/cobra"
+	"github.com/tikv/pd/pkg/job"
+	"github.com/tikv/pd/server/api"
+)
+
+const (
+	evictLeaderSchedulerName = "evict-leader-scheduler"
+	storeDrainPollInterval   = time.Second
+)
+
+// NewDrainStoreCommand returns a subcommand of storeCmd to decommission a store safely.
+func NewDrainStoreCommand() *cobra.Command {
+	d := &cobra.Command{
+		Use:   "drain <store_id>",
+		Short: "decommission a store safely",
+		Long: `Decommission a store in the following steps:
+  1. check that the remaining stores can hold the replicas and the data of the store
+  2. evict the leaders from the store, and wait until there is no leader left
+  3. remove the store, and wait until all its peers are moved away
+  4. remove the leader eviction once the store is tombstone
+
+The steps already done are skipped, so it's safe to run it again to resume an interrupted drain.`,
+		Run:               drainStoreCommandFunc,
+		ValidArgsFunction: completeFirstArg(storeIDCandidates),
+	}
+	d.Flags().Bool("skip-check", false, "skip the pre-check of the remaining stores")
+	d.Flags().Duration("leader-timeout", 10*time.Minute, "the time to wait for evicting the leaders")
+	d.Flags().Bool("force", false, "the store is physically destroyed, so the leader eviction is skipped and the store can't be brought back")
+	return d
+}
+
+func drainStoreCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Usage()
+		return
+	}
+	storeID, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		cmd.Usage()
+		return
+	}
+	if err := drainStore(cmd, storeID); err != nil {
+		failedRequests.Add(1)
+		cmd.Printf("Failed to drain store %d: %s\n", storeID, err)
+	}
+}
+
+func drainStore(cmd *cobra.Command, storeID uint64) error {
+	store, err := getStoreInfo(cmd, storeID)
+	if err != nil {
+		return err
+	}
+	force, _ := cmd.Flags().GetBool("force")
+	if store.Store.GetState() == metapb.StoreState_Tombstone {
+		cmd.Printf("Store %d is already tombstone\n", storeID)
+		return cleanUpDrainedStore(cmd, storeID)
+	}
+
+	cmd.Println("[1/4] Checking the remaining stores")
+	if skip, _ := cmd.Flags().GetBool("skip-check"); skip {
+		cmd.Println("Skipped")
+	} else if store.Store.GetState() == metapb.StoreState_Offline {
+		cmd.Println("Skipped, the store is already offline")
+	} else if err := checkStoreDrainable(cmd, store); err != nil {
+		return err
+	}
+
+	cmd.Println("[2/4] Evicting the leaders")
+	switch {
+	case force:
+		cmd.Println("Skipped, the store is physically destroyed")
+	case store.Status.LeaderCount == 0:
+		cmd.Println("Skipped, the store has no leader")
+	default:
+		timeout, _ := cmd.Flags().GetDuration("leader-timeout")
+		if err := evictStoreLeaders(cmd, store, timeout); err != nil {
+			return err
+		}
+	}
+
+	cmd.Println("[3/4] Removing the peers")
+	id, err := findDrainJob(cmd, storeID)
+	if err != nil {
+		return err
+	}
+	if id != 0 {
+		cmd.Printf("Resuming job %d\n", id)
+	} else {
+		j, err := submitJob(cmd, "drain", map[string]interface{}{"store_ids": []uint64{storeID}, "force": force})
+		if err != nil {
+			return err
+		}
+		id = j.ID
+	}
+	if err := watchJob(cmd, id); err != nil {
+		return err
+	}
+
+	cmd.Println("[4/4] Cleaning up")
+	return cleanUpDrainedStore(cmd, storeID)
+}
+
+func getStoreInfo(cmd *cobra.Command, storeID uint64) (*api.StoreInfo, error) {
+	r, err := doRequest(cmd, fmt.Sprintf(storePrefix, storeID), http.MethodGet, http.Header{})
+	if err != nil {
+		return nil, err
+	}
+	store := &api.StoreInfo{}
+	if err := json.Unmarshal([]byte(r), store); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// checkStoreDrainable checks that the other up stores are enough to hold the
+// replicas, and have enough space for the data of the store.
+func checkStoreDrainable(cmd *cobra.Command, target *api.StoreInfo) error {
+	r, err := doRequest(cmd, storesPrefix, http.MethodGet, http.Header{})
+	if err != nil {
+		return err
+	}
+	stores := &api.StoresInfo{}
+	if err := json.Unmarshal([]byte(r), stores); err != nil {
+		return err
+	}
+	r, err = doRequest(cmd, replicatePrefix, http.MethodGet, http.Header{})
+	if err != nil {
+		return err
+	}
+	var replication struct {
+		MaxReplicas int `json:"max-replicas"`
+	}
+	if err := json.Unmarshal([]byte(r), &replication); err != nil {
+		return err
+	}
+	var upStores int
+	var available uint64
+	for _, s := range stores.Stores {
+		if s.Store.GetId() == target.Store.GetId() {
+			continue
+		}
+		switch s.Store.GetState() {
+		case metapb.StoreState_Up:
+			upStores++
+			available += uint64(s.Status.Available)
+		case metapb.StoreState_Offline:
+			cmd.Printf("Warning: store %d is being removed as well\n", s.Store.GetId())
+		}
+	}
+	if upStores < replication.MaxReplicas {
+		return errors.Errorf("only %d stores remain up, which is less than max-replicas %d", upStores, replication.MaxReplicas)
+	}
+	if used := uint64(target.Status.UsedSize); available < used {
+		return errors.Errorf("the remaining stores have %d bytes available, which is less than %d bytes used by the store", available, used)
+	}
+	cmd.Printf("%d stores remain up with %d bytes available\n", upStores, available)
+	return nil
+}
+
+// evictStoreLeaders adds the store to the evict-leader-scheduler, and waits
+// until there is no leader on the store.
+func evictStoreLeaders(cmd *cobra.Command, store *api.StoreInfo, timeout time.Duration) error {
+	storeID := store.Store.GetId()
+	evicting, err := isEvictingLeaders(cmd, storeID)
+	if err != nil {
+		return err
+	}
+	if !evicting {
+		data, err := json.Marshal(map[string]interface{}{"name": evictLeaderSchedulerName, "store_id": storeID})
+		if err != nil {
+			return err
+		}
+		if _, err := doRequest(cmd, schedulersPrefix, http.MethodPost, http.Header{"Content-Type": {"application/json"}}, WithBody(bytes.NewReader(data))); err != nil {
+			return err
+		}
+	}
+	printer := newProgressPrinter(cmd)
+	defer printer.finish()
+	start, total := time.Now(), store.Status.LeaderCount
+	for {
+		if store.Status.LeaderCount == 0 {
+			return nil
+		}
+		if time.Since(start) > timeout {
+			return errors.Errorf("%d leaders remain after %s, run it again to continue", store.Status.LeaderCount, timeout)
+		}
+		progress := float64(total-store.Status.LeaderCount) / float64(total)
+		printer.update(formatProgress(progress, start, time.Now(), fmt.Sprintf("%d leaders remaining", store.Status.LeaderCount)))
+		time.Sleep(storeDrainPollInterval)
+		if store, err = getStoreInfo(cmd, storeID); err != nil {
+			return err
+		}
+	}
+}
+
+func isEvictingLeaders(cmd *cobra.Command, storeID uint64) (bool, error) {
+	r, err := doRequest(cmd, schedulerConfigPrefix+"/"+evictLeaderSchedulerName+"/list", http.MethodGet, http.Header{})
+	if err != nil {
+		// The scheduler doesn't exist.
+		return false, nil
+	}
+	var config struct {
+		StoreIDWithRanges map[uint64]interface{} `json:"store-id-ranges"`
+	}
+	if err := json.Unmarshal([]byte(r), &config); err != nil {
+		return false, err
+	}
+	_, ok := config.StoreIDWithRanges[storeID]
+	return ok, nil
+}
+
+// findDrainJob returns the running drain job of the store, or 0 if there is none.
+func findDrainJob(cmd *cobra.Command, storeID uint64) (uint64, error) {
+	r, err := doRequest(cmd, jobsPrefix+"?type=drain&status="+string(job.Running), http.MethodGet, http.Header{})
+	if err != nil {
+		return 0, err
+	}
+	var jobs []struct {
+		ID     uint64 `json:"id"`
+		Params struct {
+			StoreIDs []uint64 `json:"store_ids"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal([]byte(r), &jobs); err != nil {
+		return 0, err
+	}
+	for _, j := range jobs {
+		if containsUint64(j.Params.StoreIDs, storeID) {
+			return j.ID, nil
+		}
+	}
+	return 0, nil
+}
+
+// cleanUpDrainedStore removes the leader eviction of the tombstone store.
+func cleanUpDrainedStore(cmd *cobra.Command, storeID uint64) error {
+	evicting, err := isEvictingLeaders(cmd, storeID)
+	if err != nil {
+		return err
+	}
+	if evicting {
+		path := fmt.Sprintf("%s/%s-%d", schedulersPrefix, evictLeaderSchedulerName, storeID)
+		if _, err := doRequest(cmd, path, http.MethodDelete, http.Header{}); err != nil {
+			return err
+		}
+	}
+	cmd.Printf("Store %d is tombstone, use `store remove-tombstone` to clean it up\n", storeID)
+	return nil
+}
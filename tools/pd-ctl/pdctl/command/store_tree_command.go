@@ -0,0 +1,116 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+	"github.com/tikv/pd/server/apiv2/handlers"
+)
+
+const topologyV2Prefix = "pd/api/v2/topology"
+
+// NewStoreTreeCommand returns a tree subcommand of storeCmd.
+func NewStoreTreeCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "tree [--levels <label>,...]",
+		Short: "show the stores in the hierarchy of the location labels",
+		Long: "Show the stores in the hierarchy of the location labels, e.g. zone/rack/host, with the store count, " +
+			"capacity and leader/region distribution of each node. The percentages are the shares of the whole cluster.",
+		Run: showStoreTreeCommandFunc,
+	}
+	c.Flags().StringSlice("levels", nil, "the location labels from the top level to the bottom level, default to the location-labels config")
+	return c
+}
+
+func showStoreTreeCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Usage()
+		return
+	}
+	prefix := topologyV2Prefix
+	if levels, _ := cmd.Flags().GetStringSlice("levels"); len(levels) > 0 {
+		prefix += "?levels=" + url.QueryEscape(strings.Join(levels, ","))
+	}
+	r, err := doRequest(cmd, prefix, http.MethodGet, http.Header{})
+	if err != nil {
+		cmd.Printf("Failed to get the topology: %s\n", err)
+		return
+	}
+	var topology handlers.Topology
+	if err := json.Unmarshal([]byte(r), &topology); err != nil {
+		cmd.Printf("Failed to parse the topology: %s\n", err)
+		return
+	}
+	if topology.Root == nil {
+		cmd.Println("No store found")
+		return
+	}
+
+	var b strings.Builder
+	root := topology.Root
+	fmt.Fprintf(&b, "cluster (%s) %s\n", strings.Join(topology.Levels, "/"), topologyNodeSummary(root, root))
+	for i, child := range root.Children {
+		writeTopologyNode(&b, root, child, "", i == len(root.Children)-1)
+	}
+	cmd.Print(b.String())
+}
+
+func writeTopologyNode(b *strings.Builder, root, node *handlers.TopologyNode, indent string, last bool) {
+	branch, childIndent := "├── ", indent+"│   "
+	if last {
+		branch, childIndent = "└── ", indent+"    "
+	}
+	value := node.Value
+	if value == "" {
+		value = "<unset>"
+	}
+	fmt.Fprintf(b, "%s%s%s=%s %s\n", indent, branch, node.Label, value, topologyNodeSummary(root, node))
+	for i, child := range node.Children {
+		writeTopologyNode(b, root, child, childIndent, i == len(node.Children)-1 && len(node.Stores) == 0)
+	}
+	for i, store := range node.Stores {
+		storeBranch := "├── "
+		if i == len(node.Stores)-1 {
+			storeBranch = "└── "
+		}
+		fmt.Fprintf(b, "%s%sstore %d (%s, %s) available: %s/%s, leaders: %d (%s), regions: %d (%s)\n",
+			childIndent, storeBranch, store.ID, store.Address, store.State,
+			units.BytesSize(float64(store.Available)), units.BytesSize(float64(store.Capacity)),
+			store.LeaderCount, sharePercent(store.LeaderCount, root.LeaderCount),
+			store.RegionCount, sharePercent(store.RegionCount, root.RegionCount))
+	}
+}
+
+func topologyNodeSummary(root, node *handlers.TopologyNode) string {
+	return fmt.Sprintf("stores: %d (%s), available: %s/%s, leaders: %d (%s), regions: %d (%s)",
+		node.StoreCount, sharePercent(node.StoreCount, root.StoreCount),
+		units.BytesSize(float64(node.Available)), units.BytesSize(float64(node.Capacity)),
+		node.LeaderCount, sharePercent(node.LeaderCount, root.LeaderCount),
+		node.RegionCount, sharePercent(node.RegionCount, root.RegionCount))
+}
+
+func sharePercent(count, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(count)*100/float64(total))
+}
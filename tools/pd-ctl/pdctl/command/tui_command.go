@@ -0,0 +1,589 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chzyer/readline"
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+	"github.com/tikv/pd/server/apiv2/handlers"
+	"github.com/tikv/pd/server/statistics"
+)
+
+const (
+	storesV2Prefix    = "pd/api/v2/stores"
+	operatorsV2Prefix = "pd/api/v2/operators"
+
+	// The ANSI escape sequences used by the terminal UI.
+	ansiEnterScreen = "\x1b[?1049h\x1b[?25l"
+	ansiExitScreen  = "\x1b[?25h\x1b[?1049l"
+	ansiClear       = "\x1b[H\x1b[2J"
+	ansiReverse     = "\x1b[7m"
+	ansiBold        = "\x1b[1m"
+	ansiReset       = "\x1b[0m"
+
+	leaderBarWidth = 20
+	// slowStoreScore is the slow score from which the store is evicted by the
+	// evict-slow-store scheduler.
+	slowStoreScore = 100
+)
+
+type tuiView int
+
+const (
+	tuiStoresView tuiView = iota
+	tuiHotRegionsView
+	tuiOperatorsView
+	tuiViewCount
+)
+
+var tuiViewNames = [tuiViewCount]string{"Stores", "Hot regions", "Operators"}
+
+type tuiKey int
+
+const (
+	tuiKeyUnknown tuiKey = iota
+	tuiKeyUp
+	tuiKeyDown
+	tuiKeyEnter
+	tuiKeyBack
+	tuiKeyNextView
+	tuiKeyRefresh
+	tuiKeyQuit
+	tuiKeyView1
+	tuiKeyView2
+	tuiKeyView3
+)
+
+// NewTUICommand returns the terminal UI command.
+func NewTUICommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "tui [--interval <duration>]",
+		Short: "show the live status of the cluster in a terminal UI",
+		Long: `Show the live store health, leader distribution, hot regions and in-flight operators.
+
+Keys:
+  1/2/3, tab    switch between the stores, the hot regions and the operators
+  up/down, k/j  move the selection
+  enter         show the details of the selection
+  esc, b        go back from the details
+  r             refresh now
+  q, ctrl-c     quit
+
+If the output is not a terminal, it prints the status once and exits.`,
+		Run: tuiCommandFunc,
+	}
+	c.Flags().Duration("interval", 2*time.Second, "the interval to refresh the status")
+	return c
+}
+
+// hotPeer is a hot peer of the read or the write flow.
+type hotPeer struct {
+	statistics.HotPeerStatShow
+	kind string
+}
+
+type tuiSnapshot struct {
+	time      time.Time
+	stores    []*handlers.Store
+	hotPeers  []*hotPeer
+	operators []*handlers.Operator
+	errs      []string
+}
+
+// tui is the state of the terminal UI.
+type tui struct {
+	addr     string
+	snapshot *tuiSnapshot
+	view     tuiView
+	cursors  [tuiViewCount]int
+	// detail is the drill-down of the selection, nil if it is not shown.
+	detail []string
+	// fetchRegion fetches the details of a region.
+	fetchRegion func(id uint64) (string, error)
+}
+
+func tuiCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	interval, err := cmd.Flags().GetDuration("interval")
+	if err != nil || interval <= 0 {
+		cmd.Println("interval should be a positive duration")
+		return
+	}
+	t := &tui{
+		addr: getEndpoints(cmd)[0],
+		fetchRegion: func(id uint64) (string, error) {
+			return doRequest(cmd, fmt.Sprintf("%s/%d", regionIDPrefix, id), http.MethodGet, http.Header{})
+		},
+	}
+	out, ok := outputFile(cmd)
+	if !ok || !readline.IsTerminal(int(out.Fd())) || !readline.IsTerminal(int(os.Stdin.Fd())) {
+		t.snapshot = fetchTUISnapshot(cmd)
+		cmd.Print(t.renderOnce())
+		return
+	}
+	if err := t.run(cmd, out, interval); err != nil {
+		cmd.Println(err)
+	}
+}
+
+// run runs the terminal UI until the user quits.
+func (t *tui) run(cmd *cobra.Command, out *os.File, interval time.Duration) error {
+	state, err := readline.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return err
+	}
+	defer readline.Restore(int(os.Stdin.Fd()), state)
+	fmt.Fprint(out, ansiEnterScreen)
+	defer fmt.Fprint(out, ansiExitScreen)
+
+	keys := make(chan tuiKey)
+	go readTUIKeys(os.Stdin, keys)
+	snapshots := make(chan *tuiSnapshot, 1)
+	fetch := func() { snapshots <- fetchTUISnapshot(cmd) }
+	go fetch()
+	fetching := true
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		width, height, err := readline.GetSize(int(out.Fd()))
+		if err != nil {
+			width, height = 120, 40
+		}
+		fmt.Fprint(out, ansiClear+strings.Join(t.render(width, height), "\r\n"))
+		select {
+		case key, ok := <-keys:
+			if !ok || key == tuiKeyQuit {
+				return nil
+			}
+			if key == tuiKeyRefresh && !fetching {
+				fetching = true
+				go fetch()
+			}
+			t.handleKey(key)
+		case <-ticker.C:
+			if !fetching {
+				fetching = true
+				go fetch()
+			}
+		case t.snapshot = <-snapshots:
+			fetching = false
+		}
+	}
+}
+
+// readTUIKeys reads the keys from the terminal in the raw mode.
+func readTUIKeys(r io.Reader, keys chan<- tuiKey) {
+	defer close(keys)
+	buf := make([]byte, 16)
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, key := range parseTUIKeys(buf[:n]) {
+			keys <- key
+		}
+	}
+}
+
+func parseTUIKeys(input []byte) []tuiKey {
+	var keys []tuiKey
+	for len(input) > 0 {
+		switch {
+		case bytes.HasPrefix(input, []byte("\x1b[A")), bytes.HasPrefix(input, []byte("\x1bOA")):
+			keys, input = append(keys, tuiKeyUp), input[3:]
+			continue
+		case bytes.HasPrefix(input, []byte("\x1b[B")), bytes.HasPrefix(input, []byte("\x1bOB")):
+			keys, input = append(keys, tuiKeyDown), input[3:]
+			continue
+		case bytes.HasPrefix(input, []byte("\x1b[")):
+			// Ignore the other escape sequences.
+			return keys
+		}
+		key := tuiKeyUnknown
+		switch input[0] {
+		case 'k':
+			key = tuiKeyUp
+		case 'j':
+			key = tuiKeyDown
+		case '\r', '\n':
+			key = tuiKeyEnter
+		case '\x1b', 'b', '\x7f':
+			key = tuiKeyBack
+		case '\t':
+			key = tuiKeyNextView
+		case 'r':
+			key = tuiKeyRefresh
+		case 'q', '\x03':
+			key = tuiKeyQuit
+		case '1':
+			key = tuiKeyView1
+		case '2':
+			key = tuiKeyView2
+		case '3':
+			key = tuiKeyView3
+		}
+		keys, input = append(keys, key), input[1:]
+	}
+	return keys
+}
+
+func fetchTUISnapshot(cmd *cobra.Command) *tuiSnapshot {
+	s := &tuiSnapshot{time: time.Now()}
+	var stores handlers.StoresResponse
+	if err := getJSON(cmd, storesV2Prefix, &stores); err != nil {
+		s.errs = append(s.errs, fmt.Sprintf("failed to get the stores: %s", err))
+	}
+	s.stores = stores.Stores
+	for _, hot := range []struct {
+		kind, prefix string
+		leader       bool
+	}{
+		{"write", hotWriteRegionsPrefix, false},
+		{"read", hotReadRegionsPrefix, true},
+	} {
+		var infos statistics.StoreHotPeersInfos
+		if err := getJSON(cmd, hot.prefix, &infos); err != nil {
+			s.errs = append(s.errs, fmt.Sprintf("failed to get the hot %s regions: %s", hot.kind, err))
+			continue
+		}
+		stats := infos.AsPeer
+		if hot.leader {
+			stats = infos.AsLeader
+		}
+		for _, stat := range stats {
+			for _, peer := range stat.Stats {
+				s.hotPeers = append(s.hotPeers, &hotPeer{HotPeerStatShow: peer, kind: hot.kind})
+			}
+		}
+	}
+	sort.Slice(s.hotPeers, func(i, j int) bool {
+		if s.hotPeers[i].ByteRate != s.hotPeers[j].ByteRate {
+			return s.hotPeers[i].ByteRate > s.hotPeers[j].ByteRate
+		}
+		return s.hotPeers[i].RegionID < s.hotPeers[j].RegionID
+	})
+	if err := getJSON(cmd, operatorsV2Prefix, &s.operators); err != nil {
+		s.errs = append(s.errs, fmt.Sprintf("failed to get the operators: %s", err))
+	}
+	sort.Slice(s.operators, func(i, j int) bool { return s.operators[i].RegionID < s.operators[j].RegionID })
+	return s
+}
+
+func getJSON(cmd *cobra.Command, prefix string, v interface{}) error {
+	r, err := doRequest(cmd, prefix, http.MethodGet, http.Header{})
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(r), v)
+}
+
+func (t *tui) rowCount(view tuiView) int {
+	if t.snapshot == nil {
+		return 0
+	}
+	switch view {
+	case tuiStoresView:
+		return len(t.snapshot.stores)
+	case tuiHotRegionsView:
+		return len(t.snapshot.hotPeers)
+	default:
+		return len(t.snapshot.operators)
+	}
+}
+
+func (t *tui) handleKey(key tuiKey) {
+	switch key {
+	case tuiKeyUp:
+		if t.detail == nil && t.cursors[t.view] > 0 {
+			t.cursors[t.view]--
+		}
+	case tuiKeyDown:
+		if t.detail == nil && t.cursors[t.view] < t.rowCount(t.view)-1 {
+			t.cursors[t.view]++
+		}
+	case tuiKeyEnter:
+		if t.detail == nil {
+			t.detail = t.drillDown()
+		}
+	case tuiKeyBack:
+		t.detail = nil
+	case tuiKeyNextView:
+		t.switchView((t.view + 1) % tuiViewCount)
+	case tuiKeyView1:
+		t.switchView(tuiStoresView)
+	case tuiKeyView2:
+		t.switchView(tuiHotRegionsView)
+	case tuiKeyView3:
+		t.switchView(tuiOperatorsView)
+	}
+}
+
+func (t *tui) switchView(view tuiView) {
+	t.view, t.detail = view, nil
+}
+
+// cursor returns the selected row of the current view.
+func (t *tui) cursor() int {
+	if n := t.rowCount(t.view); t.cursors[t.view] >= n {
+		t.cursors[t.view] = n - 1
+	}
+	if t.cursors[t.view] < 0 {
+		t.cursors[t.view] = 0
+	}
+	return t.cursors[t.view]
+}
+
+// render renders the screen of the size.
+func (t *tui) render(width, height int) []string {
+	var tabs []string
+	for view, name := range tuiViewNames {
+		tab := fmt.Sprintf("[%d] %s", view+1, name)
+		if tuiView(view) == t.view {
+			tab = ansiReverse + tab + ansiReset
+		}
+		tabs = append(tabs, tab)
+	}
+	lines := []string{ansiBold + "PD " + t.addr + ansiReset + "  " + strings.Join(tabs, " ")}
+	if t.snapshot == nil {
+		return append(lines, "", "Loading...")
+	}
+	lines = append(lines, fmt.Sprintf("Updated at %s", t.snapshot.time.Format("15:04:05")))
+	for _, err := range t.snapshot.errs {
+		lines = append(lines, truncate(err, width))
+	}
+	lines = append(lines, "")
+	footer := "1/2/3/tab: switch  up/down: move  enter: details  esc: back  r: refresh  q: quit"
+	body := maxInt(height-len(lines)-2, 1)
+	if t.detail != nil {
+		lines = append(lines, t.detail[:minInt(len(t.detail), body)]...)
+	} else {
+		header, rows := t.table(t.view)
+		lines = append(lines, ansiBold+truncate(header, width)+ansiReset)
+		body--
+		// Scroll to keep the selected row visible.
+		cursor, first := t.cursor(), 0
+		if cursor >= body {
+			first = cursor - body + 1
+		}
+		for i := first; i < len(rows) && i < first+body; i++ {
+			row := truncate(rows[i], width)
+			if i == cursor {
+				row = ansiReverse + row + ansiReset
+			}
+			lines = append(lines, row)
+		}
+	}
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+	return append(lines, truncate(footer, width))
+}
+
+// renderOnce renders all the views without the terminal control sequences.
+func (t *tui) renderOnce() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "PD %s, updated at %s\n", t.addr, t.snapshot.time.Format("15:04:05"))
+	for _, err := range t.snapshot.errs {
+		b.WriteString(err + "\n")
+	}
+	for view := tuiStoresView; view < tuiViewCount; view++ {
+		header, rows := t.table(view)
+		fmt.Fprintf(&b, "\n%s\n%s\n", tuiViewNames[view], header)
+		for _, row := range rows {
+			b.WriteString(row + "\n")
+		}
+	}
+	return b.String()
+}
+
+// table returns the header and the rows of the view.
+func (t *tui) table(view tuiView) (header string, rows []string) {
+	switch view {
+	case tuiStoresView:
+		header = fmt.Sprintf("%-8s %-24s %-18s %-12s %-*s %8s %6s %10s %6s", "ID", "ADDRESS", "STATE", "HEALTH", leaderBarWidth+8, "LEADERS", "REGIONS", "USED", "AVAIL", "SLOW")
+		maxLeaders := 0
+		for _, s := range t.snapshot.stores {
+			maxLeaders = maxInt(maxLeaders, s.LeaderCount)
+		}
+		for _, s := range t.snapshot.stores {
+			rows = append(rows, fmt.Sprintf("%-8d %-24s %-18s %-12s %-*s %8d %6s %10s %6d",
+				s.ID, s.Address, s.State+"/"+s.NodeState, storeHealth(s), leaderBarWidth+8,
+				leaderBar(s.LeaderCount, maxLeaders), s.RegionCount, usedRatio(s),
+				units.BytesSize(float64(s.Available)), s.SlowScore))
+		}
+	case tuiHotRegionsView:
+		header = fmt.Sprintf("%-6s %-10s %-8s %-7s %-7s %12s %12s %12s", "FLOW", "REGION", "STORE", "LEADER", "DEGREE", "BYTES/S", "KEYS/S", "QUERIES/S")
+		for _, p := range t.snapshot.hotPeers {
+			rows = append(rows, fmt.Sprintf("%-6s %-10d %-8d %-7t %-7d %12s %12.1f %12.1f",
+				p.kind, p.RegionID, p.StoreID, p.IsLeader, p.HotDegree,
+				units.BytesSize(p.ByteRate), p.KeyRate, p.QueryRate))
+		}
+	default:
+		header = fmt.Sprintf("%-10s %-30s %-16s %-10s %-8s %s", "REGION", "DESC", "KIND", "STATUS", "AGE", "STEPS")
+		for _, op := range t.snapshot.operators {
+			rows = append(rows, fmt.Sprintf("%-10d %-30s %-16s %-10s %-8s %d",
+				op.RegionID, op.Desc, op.Kind, op.Status,
+				time.Since(op.CreateTime).Truncate(time.Second), len(op.Steps)))
+		}
+	}
+	return header, rows
+}
+
+// drillDown returns the details of the selected row.
+func (t *tui) drillDown() []string {
+	if t.rowCount(t.view) == 0 {
+		return nil
+	}
+	cursor := t.cursor()
+	switch t.view {
+	case tuiStoresView:
+		return t.storeDetail(t.snapshot.stores[cursor])
+	case tuiHotRegionsView:
+		p := t.snapshot.hotPeers[cursor]
+		lines := []string{fmt.Sprintf("Hot %s peer of region %d on store %d", p.kind, p.RegionID, p.StoreID), ""}
+		region, err := t.fetchRegion(p.RegionID)
+		if err != nil {
+			return append(lines, fmt.Sprintf("failed to get the region: %s", err))
+		}
+		return append(lines, strings.Split(strings.TrimSpace(region), "\n")...)
+	default:
+		op := t.snapshot.operators[cursor]
+		lines := []string{
+			fmt.Sprintf("Operator of region %d", op.RegionID),
+			"",
+			"Desc:    " + op.Desc,
+			"Kind:    " + op.Kind,
+			"Status:  " + op.Status,
+			"Created: " + op.CreateTime.Format(time.RFC3339),
+			"Steps:",
+		}
+		for i, step := range op.Steps {
+			lines = append(lines, fmt.Sprintf("  %d. %s", i+1, step))
+		}
+		return lines
+	}
+}
+
+func (t *tui) storeDetail(s *handlers.Store) []string {
+	lines := []string{
+		fmt.Sprintf("Store %d", s.ID),
+		"",
+		"Address:        " + s.Address,
+		"Status address: " + s.StatusAddress,
+		"Version:        " + s.Version,
+		"State:          " + s.State + "/" + s.NodeState,
+		"Health:         " + storeHealth(s),
+		"Last heartbeat: " + time.Unix(s.LastHeartbeat, 0).Format(time.RFC3339),
+		fmt.Sprintf("Capacity:       %s (used %s, available %s)", units.BytesSize(float64(s.Capacity)), units.BytesSize(float64(s.UsedSize)), units.BytesSize(float64(s.Available))),
+		fmt.Sprintf("Leaders:        %d (weight %g, score %.2f)", s.LeaderCount, s.LeaderWeight, s.LeaderScore),
+		fmt.Sprintf("Regions:        %d (weight %g, score %.2f)", s.RegionCount, s.RegionWeight, s.RegionScore),
+		fmt.Sprintf("Slow score:     %d", s.SlowScore),
+	}
+	if len(s.Labels) > 0 {
+		keys := make([]string, 0, len(s.Labels))
+		for key := range s.Labels {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		labels := make([]string, 0, len(keys))
+		for _, key := range keys {
+			labels = append(labels, key+"="+s.Labels[key])
+		}
+		lines = append(lines, "Labels:         "+strings.Join(labels, ", "))
+	}
+	lines = append(lines, "", "Hot peers:")
+	for _, p := range t.snapshot.hotPeers {
+		if p.StoreID == s.ID {
+			lines = append(lines, fmt.Sprintf("  %s region %d, %s/s, %.1f keys/s", p.kind, p.RegionID, units.BytesSize(p.ByteRate), p.KeyRate))
+		}
+	}
+	lines = append(lines, "", "Operators:")
+	storePattern := regexp.MustCompile(`\bstore ` + strconv.FormatUint(s.ID, 10) + `\b`)
+	for _, op := range t.snapshot.operators {
+		for _, step := range op.Steps {
+			if storePattern.MatchString(step) {
+				lines = append(lines, fmt.Sprintf("  region %d %s: %s", op.RegionID, op.Desc, step))
+				break
+			}
+		}
+	}
+	return lines
+}
+
+func storeHealth(s *handlers.Store) string {
+	switch {
+	case s.State == "Tombstone":
+		return "-"
+	case s.Disconnected:
+		return "Disconnected"
+	case s.SlowScore >= slowStoreScore:
+		return "Slow"
+	default:
+		return "OK"
+	}
+}
+
+func leaderBar(count, maxCount int) string {
+	n := 0
+	if maxCount > 0 {
+		n = count * leaderBarWidth / maxCount
+	}
+	return fmt.Sprintf("%-*s %d", leaderBarWidth, strings.Repeat("#", n), count)
+}
+
+func usedRatio(s *handlers.Store) string {
+	if s.Capacity == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", float64(s.Capacity-s.Available)*100/float64(s.Capacity))
+}
+
+func truncate(s string, width int) string {
+	if len(s) > width {
+		return s[:width]
+	}
+	return s
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
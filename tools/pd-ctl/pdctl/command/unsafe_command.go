@@ -46,6 +46,7 @@ func NewRemoveFailedStoresCommand() *cobra.Command {
 	cmd.PersistentFlags().Bool("auto-detect", false, `detect failed stores automatically without needing to pass failed store ids, and all stores not in PD stores list are regarded as failed; 
 Note: DO NOT RECOMMEND to use this flag for general use, it's used only for case that PD doesn't have the store information of failed stores after pd-recover;
 Note: Do it with caution to make sure all live stores's heartbeats has been reported PD already, otherwise it may regarded some stores as failed mistakenly.`)
+	cmd.Flags().Bool("wait", false, "run the recovery as a job and show the progress until it finishes")
 	cmd.AddCommand(NewRemoveFailedStoresShowCommand())
 	return cmd
 }
@@ -62,6 +63,7 @@ func NewRemoveFailedStoresShowCommand() *cobra.Command {
 func removeFailedStoresCommandFunc(cmd *cobra.Command, args []string) {
 	prefix := fmt.Sprintf("%s/remove-failed-stores", unsafePrefix)
 	postInput := make(map[string]interface{}, 3)
+	jobParams := make(map[string]interface{}, 3)
 
 	autoDetect, err := cmd.Flags().GetBool("auto-detect")
 	if err != nil {
@@ -75,6 +77,7 @@ func removeFailedStoresCommandFunc(cmd *cobra.Command, args []string) {
 			return
 		}
 		postInput["auto-detect"] = autoDetect
+		jobParams["auto_detect"] = autoDetect
 	} else {
 		if len(args) < 1 {
 			cmd.Println("Failed store ids are not specified")
@@ -93,6 +96,7 @@ func removeFailedStoresCommandFunc(cmd *cobra.Command, args []string) {
 			stores = append(stores, store)
 		}
 		postInput["stores"] = stores
+		jobParams["store_ids"] = stores
 	}
 
 	timeout, err := cmd.Flags().GetFloat64("timeout")
@@ -101,8 +105,13 @@ func removeFailedStoresCommandFunc(cmd *cobra.Command, args []string) {
 		return
 	} else if timeout != 300 {
 		postInput["timeout"] = timeout
+		jobParams["timeout"] = uint64(timeout)
 	}
 
+	if wait, _ := cmd.Flags().GetBool("wait"); wait {
+		runJob(cmd, "unsafe-recovery", jobParams)
+		return
+	}
 	postJSON(cmd, prefix, postInput)
 }
 
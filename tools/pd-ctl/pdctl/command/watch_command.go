@@ -0,0 +1,283 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/spf13/cobra"
+	"github.com/tikv/pd/pkg/event"
+)
+
+const (
+	eventsPrefix = "pd/api/v2/events"
+	// watchRetryInterval is the interval to reconnect the event stream.
+	watchRetryInterval = time.Second
+	// watchMaxRetries is the max times to retry each endpoint in a row.
+	watchMaxRetries = 3
+)
+
+// watchFilterKeys maps the keys of the filters to the query parameters.
+var watchFilterKeys = map[string]string{
+	"type":     "type",
+	"category": "category",
+	"store":    "store_id",
+	"region":   "region_id",
+}
+
+var (
+	// errEventsLost means the events after the resume token are not kept.
+	errEventsLost = errors.New("events lost")
+	// errInvalidResumeToken means the resume token is not recognized, e.g.
+	// it's from the previous PD leader.
+	errInvalidResumeToken = errors.New("invalid resume token")
+	// errStreamRefused means the server refuses the stream, which is not
+	// retried.
+	errStreamRefused = errors.New("stream refused")
+)
+
+// NewWatchCommand returns the watch command.
+func NewWatchCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "watch [--filter <key>=<value>[,<value>...]]... [--count <n>] [--json]",
+		Short: "watch the cluster events as they happen",
+		Long: `Watch the cluster events as they happen until it's interrupted.
+
+The filters are ANDed, while the values of a filter are ORed:
+  type      the event types, e.g. store-down, leader-changed, operator-finished
+  category  the event categories, i.e. store, leader, operator, rule, label, member, gc and buckets
+  store     the store ID
+  region    the region ID
+
+For example, watch the state changes of store 1:
+  watch --filter type=store-state-changed,store-up,store-down --filter store=1
+
+The stream is resumed from the last event if it's broken, e.g. when the PD
+leader changes. The events may be lost if they are too many to be kept, and
+a warning is printed in that case.`,
+		Run: watchCommandFunc,
+	}
+	c.Flags().StringArray("filter", nil, "the filter of the events in the form of <key>=<value>[,<value>...]")
+	c.Flags().Int("count", 0, "exit after receiving the number of the events, 0 means never")
+	c.Flags().String("resume-token", "", "watch the events after the resume token printed in the json output")
+	c.Flags().Bool("json", false, "print the events in json, one per line")
+	return c
+}
+
+func watchCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	filters, _ := cmd.Flags().GetStringArray("filter")
+	query, err := parseWatchFilters(filters)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	count, _ := cmd.Flags().GetInt("count")
+	token, _ := cmd.Flags().GetString("resume-token")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	received := 0
+	handle := func(token string, e *event.Event) bool {
+		if jsonOutput {
+			data, _ := json.Marshal(struct {
+				ResumeToken string `json:"resume_token"`
+				*event.Event
+			}{token, e})
+			cmd.Println(string(data))
+		} else {
+			cmd.Println(formatClusterEvent(e))
+		}
+		received++
+		return count > 0 && received >= count
+	}
+	if err := watchEvents(ctx, cmd, query, token, handle); err != nil {
+		failedRequests.Add(1)
+		cmd.Printf("Failed to watch the events: %s\n", err)
+	}
+}
+
+// parseWatchFilters converts the filters to the query of the event stream.
+func parseWatchFilters(filters []string) (url.Values, error) {
+	query := url.Values{}
+	for _, filter := range filters {
+		kv := strings.SplitN(filter, "=", 2)
+		key, ok := watchFilterKeys[kv[0]]
+		if len(kv) != 2 || !ok || len(kv[1]) == 0 {
+			return nil, errors.Errorf("invalid filter %q, should be one of type=, category=, store= and region=", filter)
+		}
+		values := strings.Split(kv[1], ",")
+		if (key == "store_id" || key == "region_id") && len(values) > 1 {
+			return nil, errors.Errorf("invalid filter %q, only one %s is allowed", filter, kv[0])
+		}
+		for _, value := range values {
+			query.Add(key, strings.TrimSpace(value))
+		}
+	}
+	return query, nil
+}
+
+// watchEvents streams the events until the context is done or the handler
+// returns true. It reconnects with the token of the last event if the stream
+// is broken.
+func watchEvents(ctx context.Context, cmd *cobra.Command, query url.Values, token string,
+	handle func(token string, e *event.Event) bool) error {
+	endpoints := getEndpoints(cmd)
+	// resumed is whether the token is from the received events rather than
+	// specified by the user.
+	resumed := false
+	for next, failures := 0, 0; ; next++ {
+		endpoint, err := checkURL(endpoints[next%len(endpoints)])
+		if err != nil {
+			return err
+		}
+		lastToken, done, err := streamEvents(ctx, endpoint, query, token, handle)
+		if done || ctx.Err() != nil {
+			return nil
+		}
+		if lastToken != token {
+			// Some events are received, so the stream is broken rather than
+			// unavailable.
+			token, resumed, failures = lastToken, true, 0
+		} else {
+			failures++
+		}
+		switch cause := errors.Cause(err); {
+		case cause == errEventsLost || (cause == errInvalidResumeToken && resumed):
+			cmd.Printf("Warning: some events may be lost (%s), watching the new ones\n", err)
+			token, resumed = "", false
+			continue
+		case cause == errInvalidResumeToken || cause == errStreamRefused:
+			return err
+		case failures >= len(endpoints)*watchMaxRetries:
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(watchRetryInterval):
+		}
+	}
+}
+
+// streamEvents reads the server-sent events from the endpoint after the token.
+// It returns the token of the last handled event, and whether the handler asks
+// to stop.
+func streamEvents(ctx context.Context, endpoint string, query url.Values, token string,
+	handle func(token string, e *event.Event) bool) (string, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"/"+eventsPrefix+"?"+query.Encode(), nil)
+	if err != nil {
+		return token, false, err
+	}
+	if len(token) > 0 {
+		req.Header.Set("Last-Event-ID", token)
+	}
+	resp, err := dialClient.Do(req)
+	if err != nil {
+		return token, false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		msg = []byte(fmt.Sprintf("[%d] %s", resp.StatusCode, msg))
+		switch {
+		case resp.StatusCode == http.StatusGone:
+			return token, false, errors.WithMessage(errEventsLost, string(msg))
+		case resp.StatusCode == http.StatusBadRequest && len(token) > 0:
+			return token, false, errors.WithMessage(errInvalidResumeToken, string(msg))
+		case resp.StatusCode < http.StatusInternalServerError:
+			return token, false, errors.WithMessage(errStreamRefused, string(msg))
+		}
+		return token, false, errors.New(string(msg))
+	}
+
+	var name, id, data string
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return token, false, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case len(line) == 0:
+			if name == "error" {
+				return token, false, errors.WithMessage(errEventsLost, data)
+			}
+			if len(data) > 0 {
+				e := &event.Event{}
+				if err := json.Unmarshal([]byte(data), e); err != nil {
+					return token, false, err
+				}
+				token = id
+				if handle(token, e) {
+					return token, true, nil
+				}
+			}
+			name, id, data = "", "", ""
+		case strings.HasPrefix(line, ":"):
+			// The keepalive comments.
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+// formatClusterEvent formats the event in one line, e.g.
+// `2023-01-02 15:04:05.000  leader-changed  region=2 from=1 to=3`.
+func formatClusterEvent(e *event.Event) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s  %-20s", e.Time.Local().Format("2006-01-02 15:04:05.000"), e.Type)
+	if e.StoreID != 0 {
+		fmt.Fprintf(&b, "  store=%d", e.StoreID)
+	}
+	if e.RegionID != 0 {
+		fmt.Fprintf(&b, "  region=%d", e.RegionID)
+	}
+	keys := make([]string, 0, len(e.Attributes))
+	for key := range e.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := e.Attributes[key]
+		if strings.ContainsAny(value, " \t") {
+			value = fmt.Sprintf("%q", value)
+		}
+		fmt.Fprintf(&b, "  %s=%s", key, value)
+	}
+	return b.String()
+}
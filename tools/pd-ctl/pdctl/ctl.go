@@ -18,7 +18,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
+	"unicode"
 
 	"github.com/chzyer/readline"
 	"github.com/mattn/go-shellwords"
@@ -43,6 +45,7 @@ func GetRootCmd() *cobra.Command {
 	rootCmd.PersistentFlags().String("cacert", "", "path of file that contains list of trusted SSL CAs")
 	rootCmd.PersistentFlags().String("cert", "", "path of file that contains X509 certificate in PEM format")
 	rootCmd.PersistentFlags().String("key", "", "path of file that contains X509 key in PEM format")
+	rootCmd.PersistentFlags().String("output", command.OutputJSON, "output format of the results, one of json, yaml, table and tsv")
 
 	rootCmd.AddCommand(
 		command.NewConfigCommand(),
@@ -65,12 +68,23 @@ func GetRootCmd() *cobra.Command {
 		command.NewMinResolvedTSCommand(),
 		command.NewCompletionCommand(),
 		command.NewUnsafeCommand(),
+		command.NewResourceGroupCommand(),
+		command.NewKeyspaceCommand(),
+		command.NewJobCommand(),
+		command.NewTUICommand(),
+		command.NewSimulateCommand(),
+		command.NewWatchCommand(),
 	)
 
 	rootCmd.Flags().ParseErrorsWhitelist.UnknownFlags = true
 	rootCmd.SilenceErrors = true
 
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("output")
+		if err := command.SetOutputFormat(rootCmd, format); err != nil {
+			rootCmd.Println(err)
+			return err
+		}
 		CAPath, err := cmd.Flags().GetString("cacert")
 		if err == nil && len(CAPath) != 0 {
 			certPath, err := cmd.Flags().GetString("cert")
@@ -102,15 +116,23 @@ func MainStart(args []string) {
 	rootCmd.Flags().BoolP("version", "V", false, "Print version information and exit.")
 	// TODO: deprecated
 	rootCmd.Flags().BoolP("detach", "d", true, "Run pdctl without readline.")
+	rootCmd.Flags().String("file", "", "Run the commands in the file, one command per line, \"-\" means reading from stdin.")
+	rootCmd.Flags().Bool("stop-on-error", true, "Skip the remaining commands in the file once a command fails.")
 
 	rootCmd.Run = func(cmd *cobra.Command, args []string) {
 		if v, err := cmd.Flags().GetBool("version"); err == nil && v {
 			server.PrintPDInfo()
 			return
 		}
+		if file, err := cmd.Flags().GetString("file"); err == nil && file != "" {
+			if err := executeScriptFile(cmd, file); err != nil {
+				cmd.Println(err)
+				os.Exit(1)
+			}
+			return
+		}
 		if v, err := cmd.Flags().GetBool("interact"); err == nil && v {
-			readlineCompleter := readline.NewPrefixCompleter(genCompleter(cmd)...)
-			loop(cmd.PersistentFlags(), readlineCompleter)
+			loop(cmd.PersistentFlags(), &completer{persistentFlags: cmd.PersistentFlags()})
 		}
 	}
 
@@ -124,10 +146,35 @@ func MainStart(args []string) {
 	}
 }
 
+func executeScriptFile(cmd *cobra.Command, file string) error {
+	in := io.Reader(os.Stdin)
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	stopOnError, _ := cmd.Flags().GetBool("stop-on-error")
+	return ExecuteScript(in, os.Stdout, cmd.PersistentFlags(), stopOnError)
+}
+
+// IsScriptFromStdin returns whether the commands are read from stdin, in
+// which case stdin should not be read as the arguments.
+func IsScriptFromStdin(args []string) bool {
+	for i, arg := range args {
+		if arg == "--file=-" || (arg == "--file" && i+1 < len(args) && args[i+1] == "-") {
+			return true
+		}
+	}
+	return false
+}
+
 func loop(persistentFlags *pflag.FlagSet, readlineCompleter readline.AutoCompleter) {
 	l, err := readline.NewEx(&readline.Config{
 		Prompt:            "\033[31m»\033[0m ",
-		HistoryFile:       "/tmp/readline.tmp",
+		HistoryFile:       historyFile(),
 		AutoComplete:      readlineCompleter,
 		InterruptPrompt:   "^C",
 		EOFPrompt:         "^D",
@@ -138,25 +185,14 @@ func loop(persistentFlags *pflag.FlagSet, readlineCompleter readline.AutoComplet
 	}
 	defer l.Close()
 
-	getREPLCmd := func() *cobra.Command {
-		rootCmd := GetRootCmd()
-		persistentFlags.VisitAll(func(flag *pflag.Flag) {
-			if flag.Changed {
-				rootCmd.PersistentFlags().Set(flag.Name, flag.Value.String())
-			}
-		})
-		rootCmd.LocalFlags().MarkHidden("pd")
-		rootCmd.LocalFlags().MarkHidden("cacert")
-		rootCmd.LocalFlags().MarkHidden("cert")
-		rootCmd.LocalFlags().MarkHidden("key")
-		rootCmd.SetOutput(os.Stdout)
-		return rootCmd
-	}
-
 	for {
 		line, err := l.Readline()
 		if err != nil {
 			if err == readline.ErrInterrupt {
+				// Discard the current line, and exit only if it's empty.
+				if len(line) > 0 {
+					continue
+				}
 				break
 			} else if err == io.EOF {
 				break
@@ -172,7 +208,7 @@ func loop(persistentFlags *pflag.FlagSet, readlineCompleter readline.AutoComplet
 			continue
 		}
 
-		rootCmd := getREPLCmd()
+		rootCmd := newSubRootCmd(persistentFlags, os.Stdout)
 		rootCmd.SetArgs(args)
 		rootCmd.ParseFlags(args)
 		if err := rootCmd.Execute(); err != nil {
@@ -181,23 +217,69 @@ func loop(persistentFlags *pflag.FlagSet, readlineCompleter readline.AutoComplet
 	}
 }
 
-func genCompleter(cmd *cobra.Command) []readline.PrefixCompleterInterface {
-	pc := []readline.PrefixCompleterInterface{}
+// historyFile returns the file to keep the history of the interactive mode,
+// so it's available across the sessions.
+func historyFile() string {
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".pd_ctl_history")
+	}
+	return filepath.Join(os.TempDir(), "pd_ctl_history")
+}
+
+// completer completes the commands in the interactive mode. Besides the
+// subcommands and the flags, the arguments are completed with the live values
+// from the cluster, e.g. the store IDs and the scheduler names.
+type completer struct {
+	persistentFlags *pflag.FlagSet
+}
+
+// Do implements readline.AutoCompleter.
+func (c *completer) Do(line []rune, pos int) ([][]rune, int) {
+	words := strings.Fields(string(line[:pos]))
+	var toComplete string
+	if len(words) > 0 && pos > 0 && !unicode.IsSpace(line[pos-1]) {
+		toComplete, words = words[len(words)-1], words[:len(words)-1]
+	}
+	rootCmd := newSubRootCmd(c.persistentFlags, io.Discard)
+	candidates := completeCommand(rootCmd, words, toComplete)
+	res := make([][]rune, 0, len(candidates))
+	for _, candidate := range candidates {
+		res = append(res, []rune(strings.TrimPrefix(candidate, toComplete)+" "))
+	}
+	return res, len([]rune(toComplete))
+}
 
-	for _, v := range cmd.Commands() {
-		if v.HasFlags() {
-			flagsPc := []readline.PrefixCompleterInterface{}
-			flagUsages := strings.Split(strings.Trim(v.Flags().FlagUsages(), " "), "\n")
-			for i := 0; i < len(flagUsages)-1; i++ {
-				flagsPc = append(flagsPc, readline.PcItem(strings.Split(strings.Trim(flagUsages[i], " "), " ")[0]))
+func completeCommand(rootCmd *cobra.Command, words []string, toComplete string) []string {
+	cmd, args, err := rootCmd.Find(words)
+	if err != nil {
+		return nil
+	}
+	var candidates []string
+	if strings.HasPrefix(toComplete, "-") {
+		addFlag := func(flag *pflag.Flag) {
+			if name := "--" + flag.Name; !flag.Hidden && strings.HasPrefix(name, toComplete) {
+				candidates = append(candidates, name)
 			}
-			flagsPc = append(flagsPc, genCompleter(v)...)
-			pc = append(pc, readline.PcItem(strings.Split(v.Use, " ")[0], flagsPc...))
-		} else {
-			pc = append(pc, readline.PcItem(strings.Split(v.Use, " ")[0], genCompleter(v)...))
 		}
+		cmd.NonInheritedFlags().VisitAll(addFlag)
+		cmd.InheritedFlags().VisitAll(addFlag)
+		return candidates
+	}
+	for _, sub := range cmd.Commands() {
+		if sub.IsAvailableCommand() && strings.HasPrefix(sub.Name(), toComplete) {
+			candidates = append(candidates, sub.Name())
+		}
+	}
+	for _, arg := range cmd.ValidArgs {
+		if strings.HasPrefix(arg, toComplete) {
+			candidates = append(candidates, arg)
+		}
+	}
+	if cmd.ValidArgsFunction != nil && cmd.ParseFlags(args) == nil {
+		comps, _ := cmd.ValidArgsFunction(cmd, cmd.Flags().Args(), toComplete)
+		candidates = append(candidates, comps...)
 	}
-	return pc
+	return candidates
 }
 
 // ReadStdin convert stdin to string array
@@ -23,51 +23,46 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func newCommand(usage, short string) *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   usage,
-		Short: short,
-	}
-	return cmd
-}
-
-func TestGenCompleter(t *testing.T) {
+func TestCompleter(t *testing.T) {
 	re := require.New(t)
-	var subCommand = []string{"testa", "testb", "testc", "testdef"}
-
-	rootCmd := &cobra.Command{
-		Use:   "roottest",
-		Short: "test root cmd",
-	}
-
-	cmdA := newCommand("testa", "test a command")
-	cmdB := newCommand("testb", "test b command")
-	cmdC := newCommand("testc", "test c command")
-	cmdDEF := newCommand("testdef", "test def command")
-
-	rootCmd.AddCommand(cmdA, cmdB, cmdC, cmdDEF)
-
-	pc := genCompleter(rootCmd)
-
-	for _, cmd := range subCommand {
-		runArray := []rune(cmd)
-		inPrefixArray := true
-		for _, v := range pc {
-			inPrefixArray = true
-			if len(runArray) != len(v.GetName())-1 {
-				continue
+	run := func(cmd *cobra.Command, args []string) {}
+	rootCmd := &cobra.Command{Use: "roottest"}
+	rootCmd.PersistentFlags().String("pd", "", "address of pd")
+	store := &cobra.Command{
+		Use: "store",
+		Run: run,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
 			}
-			for i := 0; i < len(runArray); i++ {
-				if runArray[i] != v.GetName()[i] {
-					inPrefixArray = false
+			var ids []string
+			for _, id := range []string{"1", "12", "2"} {
+				if strings.HasPrefix(id, toComplete) {
+					ids = append(ids, id)
 				}
 			}
-			if inPrefixArray == true {
-				break
-			}
-		}
+			return ids, cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	store.Flags().String("state", "", "state filter")
+	rootCmd.AddCommand(store, &cobra.Command{Use: "scheduler", Run: run}, &cobra.Command{Use: "hidden", Run: run, Hidden: true})
 
-		re.True(inPrefixArray)
+	testCases := []struct {
+		words      []string
+		toComplete string
+		expect     []string
+	}{
+		{nil, "", []string{"scheduler", "store"}},
+		{nil, "s", []string{"scheduler", "store"}},
+		{nil, "st", []string{"store"}},
+		{[]string{"store"}, "", []string{"1", "12", "2"}},
+		{[]string{"store"}, "1", []string{"1", "12"}},
+		{[]string{"store", "1"}, "", nil},
+		{[]string{"store"}, "--", []string{"--state", "--pd"}},
+		{[]string{"unknown"}, "", nil},
+	}
+	for _, tc := range testCases {
+		re.Equal(tc.expect, completeCommand(rootCmd, tc.words, tc.toComplete), "%v %q", tc.words, tc.toComplete)
 	}
 }
 
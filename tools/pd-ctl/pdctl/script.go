@@ -0,0 +1,134 @@
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pdctl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mattn/go-shellwords"
+	"github.com/pingcap/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/tikv/pd/tools/pd-ctl/pdctl/command"
+)
+
+type scriptCommand struct {
+	line int
+	text string
+	args []string
+}
+
+// ExecuteScript executes the commands read from r in order, one command per
+// line. The blank lines and the lines starting with `#` are ignored, and the
+// changed persistent flags, e.g. the PD address, are applied to every command.
+//
+// PD can't apply several commands atomically, so all the commands are
+// validated before any of them is executed, which makes a typo in the script
+// not leave the cluster half changed. If stopOnError is set, the remaining
+// commands are skipped once a command fails.
+func ExecuteScript(r io.Reader, out io.Writer, persistentFlags *pflag.FlagSet, stopOnError bool) error {
+	commands, err := parseScript(r)
+	if err != nil {
+		return err
+	}
+	for _, c := range commands {
+		if err := validateScriptCommand(newSubRootCmd(persistentFlags, out), c.args); err != nil {
+			return errors.Errorf("line %d: %s: %s, nothing is executed", c.line, c.text, err)
+		}
+	}
+
+	var failed, skipped int
+	for i, c := range commands {
+		if failed > 0 && stopOnError {
+			skipped = len(commands) - i
+			break
+		}
+		fmt.Fprintf(out, ">>> [%d/%d] %s\n", i+1, len(commands), c.text)
+		rootCmd := newSubRootCmd(persistentFlags, out)
+		rootCmd.SetArgs(c.args)
+		failedRequests := command.FailedRequests()
+		err := rootCmd.Execute()
+		switch {
+		case err != nil:
+			failed++
+			fmt.Fprintf(out, "<<< [%d/%d] failed: %s\n", i+1, len(commands), err)
+		case command.FailedRequests() > failedRequests:
+			failed++
+			fmt.Fprintf(out, "<<< [%d/%d] failed\n", i+1, len(commands))
+		default:
+			fmt.Fprintf(out, "<<< [%d/%d] ok\n", i+1, len(commands))
+		}
+	}
+	fmt.Fprintf(out, "%d command(s): %d succeeded, %d failed, %d skipped\n",
+		len(commands), len(commands)-failed-skipped, failed, skipped)
+	if failed > 0 {
+		return errors.Errorf("%d command(s) failed", failed)
+	}
+	return nil
+}
+
+func parseScript(r io.Reader) ([]scriptCommand, error) {
+	var commands []scriptCommand
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		args, err := shellwords.Parse(text)
+		if err != nil {
+			return nil, errors.Errorf("line %d: %s: %s", line, text, err)
+		}
+		commands = append(commands, scriptCommand{line: line, text: text, args: args})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return commands, nil
+}
+
+// validateScriptCommand checks the command exists and the flags are valid
+// without executing it.
+func validateScriptCommand(rootCmd *cobra.Command, args []string) error {
+	c, flags, err := rootCmd.Find(args)
+	if err != nil {
+		return err
+	}
+	if c == rootCmd || !c.Runnable() {
+		return errors.New("incomplete command")
+	}
+	return c.ParseFlags(flags)
+}
+
+// newSubRootCmd returns a root command with the changed persistent flags of
+// the outer command, which is used to execute a command in the REPL or the
+// script.
+func newSubRootCmd(persistentFlags *pflag.FlagSet, out io.Writer) *cobra.Command {
+	rootCmd := GetRootCmd()
+	persistentFlags.VisitAll(func(flag *pflag.Flag) {
+		if flag.Changed {
+			rootCmd.PersistentFlags().Set(flag.Name, flag.Value.String())
+		}
+	})
+	rootCmd.LocalFlags().MarkHidden("pd")
+	rootCmd.LocalFlags().MarkHidden("cacert")
+	rootCmd.LocalFlags().MarkHidden("cert")
+	rootCmd.LocalFlags().MarkHidden("key")
+	rootCmd.SetOutput(out)
+	return rootCmd
+}
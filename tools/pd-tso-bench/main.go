@@ -49,6 +49,8 @@ var (
 	keyPath                = flag.String("key", "", "path of file that contains X509 key in PEM format")
 	maxBatchWaitInterval   = flag.Duration("batch-interval", 0, "the max batch wait interval")
 	enableTSOFollowerProxy = flag.Bool("enable-tso-follower-proxy", false, "whether enable the TSO Follower Proxy")
+	maxBatchSize           = flag.Int("batch-size", 10000, "the max batch size")
+	enableAdaptiveBatching = flag.Bool("enable-adaptive-batching", true, "whether adjust the target batch size with the load")
 	wg                     sync.WaitGroup
 )
 
@@ -97,6 +99,8 @@ func bench(mainCtx context.Context) {
 		})
 		pdCli.UpdateOption(pd.MaxTSOBatchWaitInterval, *maxBatchWaitInterval)
 		pdCli.UpdateOption(pd.EnableTSOFollowerProxy, *enableTSOFollowerProxy)
+		pdCli.UpdateOption(pd.MaxTSOBatchSize, *maxBatchSize)
+		pdCli.UpdateOption(pd.EnableTSOAdaptiveBatching, *enableAdaptiveBatching)
 		if err != nil {
 			log.Fatal(fmt.Sprintf("create pd client #%d failed: %v", idx, err))
 		}